@@ -0,0 +1,77 @@
+// Команда backfill переносит строки verification_data, сохраненные с данными, на ссылки
+// по хэшу в verification_data_cache и выводит экономию от дедупликации.
+//
+//	backfill [-batch-size 500] [-dry-run]
+//
+// На больших таблицах ее запускают до обновления шлюза, чтобы миграции 004 и 005 при запуске
+// не переносили все строки в одной транзакции.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/logger"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/service"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+func main() {
+	batchSize := flag.Int("batch-size", 500, "rows per batch")
+	dryRun := flag.Bool("dry-run", false, "compute hashes and savings without writing")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	log, err := logger.New(cfg.Log.Level, cfg.Log.JSON)
+	if err != nil {
+		fmt.Printf("Failed to create logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer log.Sync()
+
+	db, err := pgxpool.New(context.Background(), cfg.DatabaseDSN())
+	if err != nil {
+		log.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer db.Close()
+
+	backfill := service.NewDataBackfillService(repository.NewDataBackfillRepository(db, log), log)
+	report, err := backfill.Backfill(context.Background(), *batchSize, *dryRun)
+	if err != nil {
+		log.Fatal("Backfill failed", zap.Error(err))
+	}
+
+	printReport(report)
+}
+
+func printReport(report *service.BackfillReport) {
+	if !report.LegacyColumn {
+		fmt.Println("verification_data has no legacy data column, nothing to backfill")
+	} else {
+		mode := "backfilled"
+		if report.DryRun {
+			mode = "would backfill"
+		}
+		fmt.Printf("%s %d rows: %d new payloads, %d duplicates\n", mode, report.Rows, report.NewPayloads, report.DuplicateRows)
+		fmt.Printf("raw %d bytes, stored %d bytes, saved %d bytes\n", report.RawBytes, report.NewBytes, report.SavedBytes())
+	}
+
+	if stats := report.Storage; stats != nil {
+		fmt.Printf("storage: %d rows reference %d unique payloads, %d bytes logical, %d bytes stored, %d bytes saved\n",
+			stats.ReferencingRows, stats.UniquePayloads, stats.LogicalBytes, stats.StoredBytes, stats.LogicalBytes-stats.StoredBytes)
+		if stats.RowsWithoutHash > 0 {
+			fmt.Printf("warning: %d rows have no data hash and cannot be read by the gateway\n", stats.RowsWithoutHash)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Команда enummigrate сравнивает перечисления Go с перечислениями Postgres, которые создают
+// миграции, и создает миграцию ALTER TYPE ... ADD VALUE для каждого перечисления с новыми значениями.
+//
+//	enummigrate [-dir migrations] [-check]
+//
+// С -check команда только выводит недостающие значения и завершается с ошибкой, если они есть.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"scoring_api_gateway/internal/migrations"
+	"scoring_api_gateway/internal/repository"
+)
+
+func main() {
+	dir := flag.String("dir", "migrations", "path to the migrations directory")
+	check := flag.Bool("check", false, "report missing enum values without writing migrations")
+	flag.Parse()
+
+	all, err := migrations.Load(os.DirFS(*dir))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	version := 1
+	if len(all) > 0 {
+		version = all[len(all)-1].Version + 1
+	}
+
+	enums := repository.PostgresEnums()
+	names := make([]string, 0, len(enums))
+	for name := range enums {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	outdated := 0
+	for _, name := range names {
+		if migrations.EnumValues(all, name) == nil {
+			fmt.Fprintf(os.Stderr, "enum %s is not created by any migration\n", name)
+			os.Exit(1)
+		}
+
+		missing := migrations.MissingEnumValues(all, name, enums[name])
+		if len(missing) == 0 {
+			continue
+		}
+		outdated++
+		fmt.Printf("%s: missing values %v\n", name, missing)
+		if *check {
+			continue
+		}
+
+		migration := migrations.EnumMigration(version, name, missing)
+		version++
+		for suffix, content := range map[string]string{".up.sql": migration.Up, ".down.sql": migration.Down} {
+			path := filepath.Join(*dir, migration.ID()+suffix)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to write migration: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("written %s\n", path)
+		}
+	}
+
+	if outdated == 0 {
+		fmt.Println("enums are in sync with migrations")
+		return
+	}
+	if *check {
+		fmt.Fprintf(os.Stderr, "%d enums need a migration, run enummigrate\n", outdated)
+		os.Exit(1)
+	}
+}
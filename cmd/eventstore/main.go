@@ -0,0 +1,57 @@
+// Команда eventstore обслуживает режим хранения событий.
+//
+//	eventstore rebuild - перестроить таблицу verifications по потокам событий
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/logger"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/service"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "rebuild" {
+		fmt.Fprintln(os.Stderr, "usage: eventstore rebuild")
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	log, err := logger.New(cfg.Log.Level, cfg.Log.JSON)
+	if err != nil {
+		fmt.Printf("Failed to create logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer log.Sync()
+
+	db, err := pgxpool.New(context.Background(), cfg.DatabaseDSN())
+	if err != nil {
+		log.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer db.Close()
+
+	cacheRepo := repository.NewDataCacheRepository(db, log)
+	eventSourcing := service.NewEventSourcingService(
+		repository.NewEventStoreRepository(db, log),
+		repository.NewVerificationRepository(db, cacheRepo, log),
+		log,
+	)
+
+	rebuilt, err := eventSourcing.Rebuild(context.Background())
+	if err != nil {
+		log.Fatal("Failed to rebuild read model", zap.Error(err), zap.Int("rebuilt", rebuilt))
+	}
+	log.Info("Read model rebuilt", zap.Int("verifications", rebuilt))
+}
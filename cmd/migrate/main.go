@@ -0,0 +1,144 @@
+// Команда migrate применяет и откатывает миграции базы.
+//
+//	migrate [-dry-run] [-force] [-dir migrations] status
+//	migrate [-dry-run] up
+//	migrate [-dry-run] [-force] down N
+//	migrate [-dry-run] [-force] to VERSION
+//
+// С -dry-run команда только выводит SQL, который был бы выполнен. В production
+// (GATEWAY_ENVIRONMENT=production, по умолчанию) откат миграций, удаляющих таблицы, столбцы
+// или строки, выполняется только с -force.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/logger"
+	"scoring_api_gateway/internal/migrations"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: migrate [-dry-run] [-force] [-dir migrations] status | up | down N | to VERSION")
+	os.Exit(2)
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "print the SQL to be executed without running it")
+	force := flag.Bool("force", false, "allow down-migrations that drop data in production")
+	dir := flag.String("dir", "migrations", "path to the migrations directory")
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		usage()
+	}
+
+	all, err := migrations.Load(os.DirFS(*dir))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	log, err := logger.New(cfg.Log.Level, cfg.Log.JSON)
+	if err != nil {
+		fmt.Printf("Failed to create logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer log.Sync()
+
+	db, err := pgxpool.New(context.Background(), cfg.DatabaseDSN())
+	if err != nil {
+		log.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer db.Close()
+
+	migrator := migrations.NewMigrator(db, log)
+	applied, err := migrator.Applied(context.Background())
+	if err != nil {
+		log.Fatal("Failed to read applied migrations", zap.Error(err))
+	}
+
+	var steps []migrations.Step
+	switch {
+	case args[0] == "status" && len(args) == 1:
+		printStatus(all, applied)
+		return
+	case args[0] == "up" && len(args) == 1:
+		steps = migrations.PlanUp(all, applied)
+	case args[0] == "down" && len(args) == 2:
+		n, err := strconv.Atoi(args[1])
+		if err != nil {
+			usage()
+		}
+		steps, err = migrations.PlanDown(all, applied, n)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	case args[0] == "to" && len(args) == 2:
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			usage()
+		}
+		steps, err = migrations.PlanTo(all, applied, version)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	default:
+		usage()
+	}
+
+	if len(steps) == 0 {
+		fmt.Println("nothing to migrate")
+		return
+	}
+
+	if *dryRun {
+		printPlan(steps)
+	}
+
+	if err := migrations.Guard(steps, cfg.Gateway.Production(), *force); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if *dryRun {
+		return
+	}
+
+	if err := migrator.Apply(context.Background(), steps); err != nil {
+		log.Fatal("Migration failed", zap.Error(err))
+	}
+	fmt.Printf("%d migrations executed\n", len(steps))
+}
+
+func printStatus(all []*migrations.Migration, applied map[int]bool) {
+	for _, migration := range all {
+		state := "pending"
+		if applied[migration.Version] {
+			state = "applied"
+		}
+		fmt.Printf("%-8s %s\n", state, migration.ID())
+	}
+}
+
+func printPlan(steps []migrations.Step) {
+	for _, step := range steps {
+		fmt.Printf("-- %s.%s.sql\n", step.Migration.ID(), step.Direction)
+		fmt.Println(step.SQL())
+	}
+}
@@ -0,0 +1,46 @@
+// Команда schemacheck сравнивает GraphQL-схему с опубликованным снимком
+// и завершается с ошибкой при ломающих изменениях без повышения graph.SchemaVersion.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"scoring_api_gateway/graph"
+	"scoring_api_gateway/internal/schemaguard"
+)
+
+func main() {
+	update := flag.Bool("update", false, "overwrite the snapshot with the current schema")
+	snapshotPath := flag.String("snapshot", "graph/schema.snapshot.graphql", "path to the schema snapshot")
+	flag.Parse()
+
+	current := graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{}}).Schema()
+
+	if *update {
+		if err := os.WriteFile(*snapshotPath, []byte(schemaguard.FormatSnapshot(graph.SchemaVersion, current)), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write snapshot: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("snapshot updated to schema version %d\n", graph.SchemaVersion)
+		return
+	}
+
+	snapshot, err := schemaguard.ParseSnapshot(graph.PublishedSchemaSnapshot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	result := schemaguard.Check(snapshot, graph.SchemaVersion, current)
+	for _, change := range result.Changes {
+		fmt.Println(change)
+	}
+
+	if result.Blocking() {
+		fmt.Fprintf(os.Stderr, "%d breaking changes without schema version bump (version %d)\n", len(result.Changes), graph.SchemaVersion)
+		os.Exit(1)
+	}
+	fmt.Println("schema is compatible with the published snapshot")
+}
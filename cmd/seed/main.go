@@ -0,0 +1,84 @@
+// Команда seed создает демонстрационную организацию для playground тестового окружения:
+// организацию песочницы, ключ API только на чтение и проверки с синтетическими данными.
+//
+//	seed [-force]
+//
+// Ключ берется из DEMO_API_KEY; если он не задан, команда создает новый и выводит его.
+// Повторный запуск обновляет проверки, а ключ с новым значением заменяет прежний.
+// В production команда выполняется только с -force.
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/demo"
+	"scoring_api_gateway/internal/logger"
+	"scoring_api_gateway/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+func main() {
+	force := flag.Bool("force", false, "seed the demo organization in production")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	if cfg.Gateway.Production() && !*force {
+		fmt.Println("refusing to seed demo data in production, use -force")
+		os.Exit(1)
+	}
+
+	log, err := logger.New(cfg.Log.Level, cfg.Log.JSON)
+	if err != nil {
+		fmt.Printf("Failed to create logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer log.Sync()
+
+	db, err := pgxpool.New(context.Background(), cfg.DatabaseDSN())
+	if err != nil {
+		log.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer db.Close()
+
+	apiKey := cfg.Demo.APIKey
+	generated := apiKey == ""
+	if generated {
+		apiKey = newAPIKey()
+	}
+
+	seeder := demo.NewSeeder(repository.NewDemoRepository(db, log), cfg.Demo, log)
+	if err := seeder.EnsureAccount(context.Background(), apiKey); err != nil {
+		log.Fatal("Failed to create demo account", zap.Error(err))
+	}
+	count, err := seeder.Refresh(context.Background())
+	if err != nil {
+		log.Fatal("Failed to seed demo verifications", zap.Error(err))
+	}
+
+	fmt.Printf("seeded %d demo verifications for organization %s\n", count, cfg.Demo.Organization)
+	if generated {
+		fmt.Printf("demo api key: %s\n", apiKey)
+		fmt.Println("set DEMO_API_KEY to this value to prefill it in the playground")
+	}
+}
+
+func newAPIKey() string {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		fmt.Printf("Failed to generate api key: %v\n", err)
+		os.Exit(1)
+	}
+	return base64.RawURLEncoding.EncodeToString(key)
+}
@@ -0,0 +1,28 @@
+package graph
+
+import (
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/catalog"
+)
+
+// Устаревшие значения в схеме и в каталоге должны совпадать: иначе клиенты увидят
+// @deprecated без замены или шлюз будет подменять значение, не помеченное в схеме
+func TestSchemaDeprecationsMatchCatalog(t *testing.T) {
+	schema := NewExecutableSchema(Config{Resolvers: &Resolver{}}).Schema()
+	deprecations := catalog.DefaultDeprecations()
+
+	for _, value := range schema.Types["VerificationStatus"].EnumValues {
+		_, inCatalog := deprecations.Status(model.VerificationStatus(value.Name))
+		if inSchema := value.Directives.ForName("deprecated") != nil; inSchema != inCatalog {
+			t.Errorf("VerificationStatus.%s: deprecated in schema %t, in catalog %t", value.Name, inSchema, inCatalog)
+		}
+	}
+	for _, value := range schema.Types["VerificationDataType"].EnumValues {
+		_, inCatalog := deprecations.DataType(model.VerificationDataType(value.Name))
+		if inSchema := value.Directives.ForName("deprecated") != nil; inSchema != inCatalog {
+			t.Errorf("VerificationDataType.%s: deprecated in schema %t, in catalog %t", value.Name, inSchema, inCatalog)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package graph
+
+import (
+	"context"
+	"errors"
+
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/service"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Коды ошибок в extensions.code, по которым клиенты различают ошибки без разбора текста
+const (
+	ErrorCodeNotFound      = "NOT_FOUND"
+	ErrorCodeConflict      = "CONFLICT"
+	ErrorCodeSerialization = "SERIALIZATION_FAILURE"
+	// ErrorCodeUpstreamUnavailable запрос не выполнен: нет подключения к NATS или база недоступна
+	ErrorCodeUpstreamUnavailable = "UPSTREAM_UNAVAILABLE"
+	// ErrorCodeDataUnavailable данные одного типа не удалось прочитать, остальные возвращены
+	ErrorCodeDataUnavailable = "DATA_UNAVAILABLE"
+	// ErrorCodeCrossRegion проверка хранится в регионе, отличном от региона арендатора
+	ErrorCodeCrossRegion = "CROSS_REGION_ACCESS"
+	// ErrorCodeInvalidInput аргументы запроса не прошли проверку сервиса
+	ErrorCodeInvalidInput = "INVALID_INPUT"
+)
+
+// ErrorPresenter добавляет к ошибке код по виду ошибки хранилища или проверки аргументов
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	if _, ok := gqlErr.Extensions["code"]; ok {
+		return gqlErr
+	}
+
+	var code string
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		code = ErrorCodeNotFound
+	case errors.Is(err, repository.ErrConflict):
+		code = ErrorCodeConflict
+	case errors.Is(err, repository.ErrSerialization):
+		code = ErrorCodeSerialization
+	case errors.Is(err, messaging.ErrUpstreamUnavailable), errors.Is(err, repository.ErrUnavailable):
+		code = ErrorCodeUpstreamUnavailable
+	case errors.Is(err, repository.ErrCrossRegion):
+		code = ErrorCodeCrossRegion
+	case errors.Is(err, service.ErrInvalidInput):
+		code = ErrorCodeInvalidInput
+	default:
+		return gqlErr
+	}
+
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = map[string]any{}
+	}
+	gqlErr.Extensions["code"] = code
+	return gqlErr
+}
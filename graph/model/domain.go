@@ -0,0 +1,297 @@
+package model
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+)
+
+// Конвертеры между типами GraphQL и internal/domain. Время в GraphQL - строки RFC3339,
+// нулевое время несохраненной проверки отдается пустой строкой.
+
+// VerificationFromDomain возвращает проверку в представлении GraphQL
+func VerificationFromDomain(v *domain.Verification) *Verification {
+	if v == nil {
+		return nil
+	}
+
+	verification := &Verification{
+		ID:                    v.ID,
+		Inn:                   v.INN,
+		Status:                VerificationStatus(v.Status),
+		ErrorMessage:          v.ErrorMessage,
+		AuthorEmail:           v.AuthorEmail,
+		CompanyID:             v.CompanyID,
+		RulesetID:             v.RulesetID,
+		LegalHold:             v.LegalHold,
+		Sandbox:               v.Sandbox,
+		Assignee:              v.Assignee,
+		ReviewState:           ReviewState(v.ReviewState),
+		ReviewComment:         v.ReviewComment,
+		ReviewedAt:            formatTimePtr(v.ReviewedAt),
+		ExpectedStartAt:       formatTimePtr(v.ExpectedStartAt),
+		EstimatedCompletionAt: formatTimePtr(v.EstimatedCompletionAt),
+		CreatedAt:             formatTime(v.CreatedAt),
+		UpdatedAt:             formatTime(v.UpdatedAt),
+	}
+	if v.RiskLevel != nil {
+		riskLevel := RiskLevel(*v.RiskLevel)
+		verification.RiskLevel = &riskLevel
+	}
+	if v.ExternalRef != nil {
+		verification.ExternalRef = &ExternalRef{System: v.ExternalRef.System, Ref: v.ExternalRef.Ref}
+	}
+	verification.Metadata = MetadataFromDomain(v.Metadata)
+	// Типы, объявленные в конфигурации, не входят в перечисление VerificationDataType и
+	// отдаются отдельно от встроенных
+	verification.RequestedDataTypes, verification.CustomDataTypes = splitDataTypes(v.RequestedDataTypes)
+	verification.MissingDataTypes, _ = splitDataTypes(v.MissingDataTypes)
+	if v.QueuePosition != nil {
+		position := int32(*v.QueuePosition)
+		verification.QueuePosition = &position
+	}
+	if v.Data != nil {
+		verification.Data = make([]*VerificationData, 0, len(v.Data))
+		for _, data := range v.Data {
+			if !data.DataType.IsValid() {
+				verification.CustomData = append(verification.CustomData, customDataFromDomain(data, v.DataQuality))
+				continue
+			}
+			verification.Data = append(verification.Data, &VerificationData{
+				DataType:      VerificationDataType(data.DataType),
+				Data:          data.Payload,
+				SchemaVersion: int32(data.SchemaVersion),
+				CreatedAt:     formatTime(data.CreatedAt),
+				Freshness:     freshnessFromDomain(data.Freshness),
+			})
+		}
+	}
+	if v.DataQuality != nil {
+		verification.DataQuality = make([]*DataQuality, 0, len(v.DataQuality))
+		for _, quality := range v.DataQuality {
+			if !quality.DataType.IsValid() {
+				continue
+			}
+			verification.DataQuality = append(verification.DataQuality, &DataQuality{
+				DataType: VerificationDataType(quality.DataType),
+				Valid:    quality.Valid,
+				Errors:   quality.Errors,
+			})
+		}
+	}
+	return verification
+}
+
+// VerificationToDomain возвращает проверку в представлении предметной области. Время,
+// которое не разбирается как RFC3339, становится нулевым.
+func VerificationToDomain(v *Verification) *domain.Verification {
+	if v == nil {
+		return nil
+	}
+
+	verification := &domain.Verification{
+		ID:                    v.ID,
+		INN:                   v.Inn,
+		Status:                domain.Status(v.Status),
+		ErrorMessage:          v.ErrorMessage,
+		AuthorEmail:           v.AuthorEmail,
+		CompanyID:             v.CompanyID,
+		RulesetID:             v.RulesetID,
+		LegalHold:             v.LegalHold,
+		Sandbox:               v.Sandbox,
+		RequestedDataTypes:    DataTypesToDomain(v.RequestedDataTypes),
+		MissingDataTypes:      DataTypesToDomain(v.MissingDataTypes),
+		Assignee:              v.Assignee,
+		ReviewState:           domain.ReviewState(v.ReviewState),
+		ReviewComment:         v.ReviewComment,
+		ReviewedAt:            parseTimePtr(v.ReviewedAt),
+		ExpectedStartAt:       parseTimePtr(v.ExpectedStartAt),
+		EstimatedCompletionAt: parseTimePtr(v.EstimatedCompletionAt),
+		CreatedAt:             parseTime(v.CreatedAt),
+		UpdatedAt:             parseTime(v.UpdatedAt),
+	}
+	if v.RiskLevel != nil {
+		riskLevel := domain.RiskLevel(*v.RiskLevel)
+		verification.RiskLevel = &riskLevel
+	}
+	if v.ExternalRef != nil {
+		verification.ExternalRef = &domain.ExternalRef{System: v.ExternalRef.System, Ref: v.ExternalRef.Ref}
+	}
+	verification.Metadata = MetadataToDomain(v.Metadata)
+	for _, dataType := range v.CustomDataTypes {
+		verification.RequestedDataTypes = append(verification.RequestedDataTypes, domain.DataType(dataType))
+	}
+	if v.QueuePosition != nil {
+		position := int(*v.QueuePosition)
+		verification.QueuePosition = &position
+	}
+	if v.Data != nil {
+		verification.Data = make([]*domain.Data, 0, len(v.Data))
+		for _, data := range v.Data {
+			verification.Data = append(verification.Data, &domain.Data{
+				DataType:      domain.DataType(data.DataType),
+				Payload:       data.Data,
+				SchemaVersion: int(data.SchemaVersion),
+				CreatedAt:     parseTime(data.CreatedAt),
+				Freshness:     freshnessToDomain(data.Freshness),
+			})
+		}
+	}
+	if v.DataQuality != nil {
+		verification.DataQuality = make([]*domain.DataQuality, 0, len(v.DataQuality))
+		for _, quality := range v.DataQuality {
+			verification.DataQuality = append(verification.DataQuality, &domain.DataQuality{
+				DataType: domain.DataType(quality.DataType),
+				Valid:    quality.Valid,
+				Errors:   quality.Errors,
+			})
+		}
+	}
+	for _, data := range v.CustomData {
+		verification.Data = append(verification.Data, &domain.Data{
+			DataType:      domain.DataType(data.DataType),
+			Payload:       data.Data,
+			SchemaVersion: int(data.SchemaVersion),
+			CreatedAt:     parseTime(data.CreatedAt),
+			Freshness:     freshnessToDomain(data.Freshness),
+		})
+		if data.ValidationErrors != nil {
+			verification.DataQuality = append(verification.DataQuality, &domain.DataQuality{
+				DataType: domain.DataType(data.DataType),
+				Valid:    len(data.ValidationErrors) == 0,
+				Errors:   data.ValidationErrors,
+			})
+		}
+	}
+	return verification
+}
+
+// splitDataTypes делит типы данных на встроенные и объявленные в конфигурации шлюза, сохраняя nil
+func splitDataTypes(dataTypes []domain.DataType) ([]VerificationDataType, []string) {
+	if !slices.ContainsFunc(dataTypes, func(dataType domain.DataType) bool { return !dataType.IsValid() }) {
+		return DataTypesFromDomain(dataTypes), nil
+	}
+
+	builtIn := make([]VerificationDataType, 0, len(dataTypes))
+	var custom []string
+	for _, dataType := range dataTypes {
+		if dataType.IsValid() {
+			builtIn = append(builtIn, VerificationDataType(dataType))
+		} else {
+			custom = append(custom, string(dataType))
+		}
+	}
+	return builtIn, custom
+}
+
+// customDataFromDomain возвращает данные объявленного типа с результатом проверки по схеме из quality
+func customDataFromDomain(data *domain.Data, quality []*domain.DataQuality) *GenericVerificationData {
+	generic := &GenericVerificationData{
+		DataType:      string(data.DataType),
+		Data:          data.Payload,
+		SchemaVersion: int32(data.SchemaVersion),
+		CreatedAt:     formatTime(data.CreatedAt),
+		Freshness:     freshnessFromDomain(data.Freshness),
+	}
+	for _, result := range quality {
+		if result.DataType == data.DataType {
+			generic.ValidationErrors = append([]string{}, result.Errors...)
+		}
+	}
+	return generic
+}
+
+// freshnessFromDomain возвращает свежесть данных из кэша, nil - данные доставлены для проверки
+func freshnessFromDomain(freshness domain.Freshness) *DataFreshness {
+	if freshness == "" {
+		return nil
+	}
+	value := DataFreshness(freshness)
+	return &value
+}
+
+func freshnessToDomain(freshness *DataFreshness) domain.Freshness {
+	if freshness == nil {
+		return ""
+	}
+	return domain.Freshness(*freshness)
+}
+
+// DataTypesFromDomain возвращает типы данных в представлении GraphQL, сохраняя nil
+func DataTypesFromDomain(dataTypes []domain.DataType) []VerificationDataType {
+	if dataTypes == nil {
+		return nil
+	}
+	result := make([]VerificationDataType, len(dataTypes))
+	for i, dataType := range dataTypes {
+		result[i] = VerificationDataType(dataType)
+	}
+	return result
+}
+
+// DataTypesToDomain возвращает типы данных в представлении предметной области, сохраняя nil
+func DataTypesToDomain(dataTypes []VerificationDataType) []domain.DataType {
+	if dataTypes == nil {
+		return nil
+	}
+	result := make([]domain.DataType, len(dataTypes))
+	for i, dataType := range dataTypes {
+		result[i] = domain.DataType(dataType)
+	}
+	return result
+}
+
+// MetadataFromDomain возвращает метаданные в представлении GraphQL, отсортированные по ключу
+func MetadataFromDomain(metadata map[string]string) []*MetadataEntry {
+	if metadata == nil {
+		return nil
+	}
+	result := make([]*MetadataEntry, 0, len(metadata))
+	for _, key := range slices.Sorted(maps.Keys(metadata)) {
+		result = append(result, &MetadataEntry{Key: key, Value: metadata[key]})
+	}
+	return result
+}
+
+// MetadataToDomain возвращает метаданные в представлении предметной области, сохраняя nil.
+// Из повторяющихся ключей остается последний.
+func MetadataToDomain(entries []*MetadataEntry) map[string]string {
+	if entries == nil {
+		return nil
+	}
+	result := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		result[entry.Key] = entry.Value
+	}
+	return result
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatTimePtr(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
+
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
+
+func parseTimePtr(s *string) *time.Time {
+	if s == nil {
+		return nil
+	}
+	t := parseTime(*s)
+	return &t
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+)
+
+func TestVerificationDomainRoundTrip(t *testing.T) {
+	riskLevel := RiskLevelHigh
+	system, ruleset := "crm", "2024-06"
+	reviewedAt, estimatedAt := "2024-01-01T11:00:00Z", "2024-01-01T10:20:00Z"
+	position := int32(3)
+	verification := &Verification{
+		ID:                    "v-1",
+		Inn:                   "7707083893",
+		Status:                VerificationStatusCompleted,
+		AuthorEmail:           "analyst@example.com",
+		RiskLevel:             &riskLevel,
+		RulesetID:             &ruleset,
+		ExternalRef:           &ExternalRef{System: &system, Ref: "DEAL-1"},
+		RequestedDataTypes:    []VerificationDataType{VerificationDataTypeBasicInformation, VerificationDataTypeActivities},
+		MissingDataTypes:      []VerificationDataType{VerificationDataTypeActivities},
+		ReviewState:           ReviewStateApproved,
+		ReviewedAt:            &reviewedAt,
+		QueuePosition:         &position,
+		EstimatedCompletionAt: &estimatedAt,
+		Data: []*VerificationData{
+			{DataType: VerificationDataTypeBasicInformation, Data: `{"inn": "7707083893"}`, SchemaVersion: 2, CreatedAt: "2024-01-01T10:01:00Z"},
+		},
+		DataQuality: []*DataQuality{
+			{DataType: VerificationDataTypeBasicInformation, Valid: false, Errors: []string{"missing name"}},
+		},
+		CreatedAt: "2024-01-01T10:00:00+03:00",
+		UpdatedAt: "2024-01-01T10:05:00+03:00",
+	}
+
+	converted := VerificationToDomain(verification)
+	if converted.INN != verification.Inn || *converted.RiskLevel != domain.RiskLevelHigh || *converted.QueuePosition != 3 ||
+		!converted.ReviewedAt.Equal(time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected domain verification %+v", converted)
+	}
+	if back := VerificationFromDomain(converted); !reflect.DeepEqual(back, verification) {
+		t.Errorf("expected %+v after round trip, but got %+v", verification, back)
+	}
+}
+
+func TestVerificationFromDomainCustomDataTypes(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 10, 1, 0, 0, time.UTC)
+	converted := &domain.Verification{
+		ID:                 "v-1",
+		RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation, "CREDIT_RATING"},
+		MissingDataTypes:   []domain.DataType{"CREDIT_RATING"},
+		Data: []*domain.Data{
+			{DataType: domain.DataTypeBasicInformation, Payload: `{}`, SchemaVersion: 1, CreatedAt: createdAt},
+			{DataType: "CREDIT_RATING", Payload: `{"rating": "ruAA"}`, SchemaVersion: 1, CreatedAt: createdAt},
+		},
+		DataQuality: []*domain.DataQuality{{DataType: "CREDIT_RATING", Valid: false, Errors: []string{"/: missing outlook"}}},
+	}
+
+	verification := VerificationFromDomain(converted)
+	if !reflect.DeepEqual(verification.RequestedDataTypes, []VerificationDataType{VerificationDataTypeBasicInformation}) ||
+		!reflect.DeepEqual(verification.CustomDataTypes, []string{"CREDIT_RATING"}) || len(verification.MissingDataTypes) != 0 {
+		t.Errorf("expected custom data types apart from built-in ones, but got %+v", verification)
+	}
+	if len(verification.Data) != 1 || len(verification.DataQuality) != 0 || len(verification.CustomData) != 1 {
+		t.Fatalf("expected custom data apart from built-in data, but got %+v", verification)
+	}
+	if data := verification.CustomData[0]; data.DataType != "CREDIT_RATING" || data.Data != `{"rating": "ruAA"}` ||
+		!reflect.DeepEqual(data.ValidationErrors, []string{"/: missing outlook"}) {
+		t.Errorf("unexpected custom data %+v", data)
+	}
+
+	back := VerificationToDomain(verification)
+	if !reflect.DeepEqual(back.RequestedDataTypes, converted.RequestedDataTypes) || len(back.Data) != 2 || len(back.DataQuality) != 1 {
+		t.Errorf("expected custom data types to be kept in the domain verification, but got %+v", back)
+	}
+}
+
+func TestVerificationFromDomainUnsaved(t *testing.T) {
+	verification := VerificationFromDomain(&domain.Verification{ID: "v-1", Status: domain.StatusInProcess})
+	if verification.CreatedAt != "" || verification.UpdatedAt != "" || verification.RequestedDataTypes != nil {
+		t.Errorf("expected unsaved verification without timestamps, but got %+v", verification)
+	}
+	if VerificationFromDomain(nil) != nil || VerificationToDomain(nil) != nil {
+		t.Error("expected nil verification to stay nil")
+	}
+}
+
+// Перечисления предметной области должны совпадать с перечислениями схемы GraphQL, иначе
+// конвертеры пропустят значение, которое gqlgen не сможет отдать клиенту
+func TestDomainEnumsMatchSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		domain []string
+		schema []string
+	}{
+		{name: "status", domain: enumValues(domain.AllStatuses), schema: enumValues(AllVerificationStatus)},
+		{name: "data_type", domain: enumValues(domain.AllDataTypes), schema: enumValues(AllVerificationDataType)},
+		{name: "risk_level", domain: enumValues(domain.AllRiskLevels), schema: enumValues(AllRiskLevel)},
+		{name: "review_state", domain: enumValues(domain.AllReviewStates), schema: enumValues(AllReviewState)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.domain, tt.schema) {
+				t.Errorf("expected domain values %v to match schema values %v", tt.domain, tt.schema)
+			}
+		})
+	}
+}
+
+func enumValues[T ~string](values []T) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = string(value)
+	}
+	return result
+}
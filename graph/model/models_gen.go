@@ -9,41 +9,1748 @@ import (
 	"strconv"
 )
 
+// Effective access of a user account or an API key that is not revoked
+type AccessGrant struct {
+	ClientKind ClientKind `json:"clientKind"`
+	// User email, or API key name
+	Principal string `json:"principal"`
+	// Service account email of the API key
+	ServiceEmail *string `json:"serviceEmail,omitempty"`
+	// Organization of the user account
+	Organization *string            `json:"organization,omitempty"`
+	Status       *UserStatus        `json:"status,omitempty"`
+	Roles        []OrganizationRole `json:"roles"`
+	Operations   []AccessOperation  `json:"operations"`
+	// Data types returned by reads. Null when all data types are returned
+	DataTypes []VerificationDataType `json:"dataTypes,omitempty"`
+	// Organizations whose users the principal manages: the own organization and its subsidiaries for ORG_ADMIN
+	AdministeredOrganizations []string `json:"administeredOrganizations"`
+	// Created verifications are sandbox verifications with synthetic data
+	Sandbox bool `json:"sandbox"`
+}
+
+type AccessReview struct {
+	GeneratedAt string `json:"generatedAt"`
+	// Users with accounts that are not deactivated, then API keys
+	Grants []*AccessGrant `json:"grants"`
+	// Data types whose latest company data every organization reads, including data delivered for other organizations
+	SharedDataTypes []VerificationDataType `json:"sharedDataTypes"`
+}
+
+// Restrictions of the API key a service account is authenticated with
+type APIKeyScope struct {
+	KeyID string `json:"keyId"`
+	// Operations the key allows, every operation when empty
+	Operations []AccessOperation `json:"operations"`
+	// Data types the key allows, every data type when empty
+	DataTypes []string `json:"dataTypes"`
+}
+
+type AuditTrailEntry struct {
+	EventType  AuditEventType `json:"eventType"`
+	Actor      *string        `json:"actor,omitempty"`
+	Details    *string        `json:"details,omitempty"`
+	OccurredAt string         `json:"occurredAt"`
+}
+
+// Sharing of cached company data of the data type across tenants
+type CacheSharingPolicy struct {
+	DataType VerificationDataType `json:"dataType"`
+	// Sharing is contractually allowed for the data type (CACHE_SHAREABLE_DATA_TYPES)
+	Shareable bool `json:"shareable"`
+	// Payloads delivered to any tenant are served to every tenant
+	Shared    bool    `json:"shared"`
+	UpdatedBy *string `json:"updatedBy,omitempty"`
+	UpdatedAt *string `json:"updatedAt,omitempty"`
+}
+
+// Verifications of related parties grouped for a joint review. Visible to the organization of its author
+type Case struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	CreatedBy string       `json:"createdBy"`
+	Parties   []*CaseParty `json:"parties"`
+	Risk      *CaseRisk    `json:"risk"`
+	CreatedAt string       `json:"createdAt"`
+	UpdatedAt string       `json:"updatedAt"`
+}
+
+// Verification grouped into a case
+type CaseParty struct {
+	Verification *Verification `json:"verification"`
+	Role         CasePartyRole `json:"role"`
+	AddedAt      string        `json:"addedAt"`
+}
+
+type CasePartyInput struct {
+	VerificationID string        `json:"verificationId"`
+	Role           CasePartyRole `json:"role"`
+}
+
+// Risk of the verifications in a case taken together
+type CaseRisk struct {
+	// Highest risk level among scored verifications, null while none is scored
+	Level  *RiskLevel `json:"level,omitempty"`
+	Low    int32      `json:"low"`
+	Medium int32      `json:"medium"`
+	High   int32      `json:"high"`
+	// Verifications without a risk level yet
+	Unscored int32 `json:"unscored"`
+	// INNs of the companies at the highest risk level
+	HighestRiskInns []string `json:"highestRiskInns"`
+}
+
+// API usage of a user or an API key over a rolling window
+type ClientUsage struct {
+	ClientKind ClientKind `json:"clientKind"`
+	// API key id for service accounts, email for users
+	ClientID string `json:"clientId"`
+	Email    string `json:"email"`
+	// Start of the window
+	Since    string `json:"since"`
+	Requests int32  `json:"requests"`
+	Errors   int32  `json:"errors"`
+	// Share of requests answered with errors, 0 without requests
+	ErrorRate float64 `json:"errorRate"`
+	// Size of returned data in bytes
+	ResponseBytes float64 `json:"responseBytes"`
+	// Most frequent operations, by request count
+	TopOperations []*OperationUsage `json:"topOperations"`
+}
+
+// What was known about a company at a point in time
+type CompanySnapshot struct {
+	Inn  string `json:"inn"`
+	AsOf string `json:"asOf"`
+	// Most recent verification completed before asOf
+	Verification *Verification `json:"verification"`
+	// Data delivered before asOf
+	Data []*VerificationData `json:"data"`
+}
+
+// Status of the latest verification of a company
+type CompanyVerificationStatus struct {
+	Inn string `json:"inn"`
+	// Null fields mean the company has never been verified
+	VerificationID *string             `json:"verificationId,omitempty"`
+	Status         *VerificationStatus `json:"status,omitempty"`
+	RiskLevel      *RiskLevel          `json:"riskLevel,omitempty"`
+	CreatedAt      *string             `json:"createdAt,omitempty"`
+	UpdatedAt      *string             `json:"updatedAt,omitempty"`
+}
+
+// Data type declared in gateway configuration that has no VerificationDataType value yet
+type CustomDataTypeInfo struct {
+	Type                  string   `json:"type"`
+	Name                  string   `json:"name"`
+	Description           string   `json:"description"`
+	Provider              string   `json:"provider"`
+	SchemaVersion         int32    `json:"schemaVersion"`
+	TypicalLatencySeconds int32    `json:"typicalLatencySeconds"`
+	CostTier              CostTier `json:"costTier"`
+	Available             bool     `json:"available"`
+	Allowed               bool     `json:"allowed"`
+	// JSON Schema the delivered data is validated against
+	Schema string `json:"schema"`
+}
+
+type DailyVerificationStats struct {
+	// Day in YYYY-MM-DD format in the requested timezone (UTC by default)
+	Day                  string             `json:"day"`
+	Status               VerificationStatus `json:"status"`
+	Organization         string             `json:"organization"`
+	Count                int32              `json:"count"`
+	AvgCompletionSeconds *float64           `json:"avgCompletionSeconds,omitempty"`
+}
+
+type DataQuality struct {
+	DataType VerificationDataType `json:"dataType"`
+	Valid    bool                 `json:"valid"`
+	// Schema violations as JSON Pointer to the value and the reason
+	Errors []string `json:"errors"`
+}
+
+// Signed record of an admin change of a stored data payload. The original payload is kept by the gateway and is not served
+type DataRedaction struct {
+	ID             string                 `json:"id"`
+	VerificationID string                 `json:"verificationId"`
+	DataType       VerificationDataType   `json:"dataType"`
+	Operation      DataRedactionOperation `json:"operation"`
+	// Keys and array indexes from the payload root to the changed value
+	Path []string `json:"path"`
+	// SHA-256 of the payload before the change
+	PreviousDataHash string `json:"previousDataHash"`
+	// SHA-256 of the payload after the change
+	DataHash  string `json:"dataHash"`
+	Reason    string `json:"reason"`
+	Actor     string `json:"actor"`
+	CreatedAt string `json:"createdAt"`
+	// Fingerprint of the public key served at /signing-key
+	KeyID string `json:"keyId"`
+	// Signed text: the change id, verification, data type, operation, path, payload hashes, actor, reason and time
+	Content string `json:"content"`
+	// Signature of content, base64
+	Signature string `json:"signature"`
+}
+
+type DataTypeInfo struct {
+	Type        VerificationDataType `json:"type"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Provider    string               `json:"provider"`
+	// Version of the provider payload format described by the gateway JSON Schema
+	SchemaVersion         int32    `json:"schemaVersion"`
+	TypicalLatencySeconds int32    `json:"typicalLatencySeconds"`
+	CostTier              CostTier `json:"costTier"`
+	// Provider is currently accepting requests for this data type
+	Available bool `json:"available"`
+	// Caller is allowed to order this data type
+	Allowed bool `json:"allowed"`
+}
+
+// Data of a verification obtained outside the workers
+type DataTypePayloadInput struct {
+	DataType VerificationDataType `json:"dataType"`
+	// JSON document in the provider format
+	Payload string `json:"payload"`
+}
+
+// Requests of one data type in a spend report
+type DataTypeSpend struct {
+	DataType VerificationDataType `json:"dataType"`
+	Count    int32                `json:"count"`
+}
+
+type EnumInfo struct {
+	Name   string           `json:"name"`
+	Values []*EnumValueInfo `json:"values"`
+}
+
+// Value of an API enum and whether it is being phased out
+type EnumValueInfo struct {
+	Value string `json:"value"`
+	// Value is still accepted but will be removed; the gateway substitutes replacement for it
+	Deprecated        bool    `json:"deprecated"`
+	DeprecationReason *string `json:"deprecationReason,omitempty"`
+	// Value the gateway uses instead of the deprecated one
+	Replacement *string `json:"replacement,omitempty"`
+}
+
+// Identifier of the verification in an external case management system
+type ExternalRef struct {
+	System *string `json:"system,omitempty"`
+	Ref    string  `json:"ref"`
+}
+
+type ExternalRefInput struct {
+	System *string `json:"system,omitempty"`
+	Ref    string  `json:"ref"`
+}
+
+// Delivered data of any type, including types declared in gateway configuration
+type GenericVerificationData struct {
+	DataType      string         `json:"dataType"`
+	Data          string         `json:"data"`
+	SchemaVersion int32          `json:"schemaVersion"`
+	CreatedAt     string         `json:"createdAt"`
+	Freshness     *DataFreshness `json:"freshness,omitempty"`
+	// Schema violations, null until the data is validated
+	ValidationErrors []string `json:"validationErrors,omitempty"`
+}
+
+// Time from verification creation to data of one type, over verifications completed in the period
+type LatencyReport struct {
+	DataType VerificationDataType `json:"dataType"`
+	// Completed verifications that requested the data type
+	Samples int32 `json:"samples"`
+	// Verifications completed without data of the type
+	Undelivered int32 `json:"undelivered"`
+	// Median time from creation to the first data of the type, null if none was delivered
+	FirstDataP50Seconds *float64 `json:"firstDataP50Seconds,omitempty"`
+	FirstDataP95Seconds *float64 `json:"firstDataP95Seconds,omitempty"`
+	// Median time from creation to completion of the verification
+	CompletedP50Seconds float64 `json:"completedP50Seconds"`
+	CompletedP95Seconds float64 `json:"completedP95Seconds"`
+	CompletedMaxSeconds float64 `json:"completedMaxSeconds"`
+}
+
+type MaintenanceStatus struct {
+	Enabled bool    `json:"enabled"`
+	Reason  *string `json:"reason,omitempty"`
+	// Who switched maintenance mode on
+	EnabledBy *string `json:"enabledBy,omitempty"`
+	Since     *string `json:"since,omitempty"`
+	// Publishes to NATS still in flight. Zero once maintenance mode has drained them
+	InFlightPublishes int32 `json:"inFlightPublishes"`
+}
+
+// Integrator key/value pair stored with the verification
+type MetadataEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Key starts with a letter and contains only letters, digits, '_', '.' and '-'. A verification has at most 50 keys and 8 KB of metadata
+type MetadataEntryInput struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
 type Mutation struct {
 }
 
-type Query struct {
+type Notification struct {
+	ID             string           `json:"id"`
+	Kind           NotificationKind `json:"kind"`
+	VerificationID *string          `json:"verificationId,omitempty"`
+	Message        string           `json:"message"`
+	Read           bool             `json:"read"`
+	CreatedAt      string           `json:"createdAt"`
+}
+
+// One delivery attempt of a notification job
+type NotificationAttempt struct {
+	Attempt    int32  `json:"attempt"`
+	StartedAt  string `json:"startedAt"`
+	FinishedAt string `json:"finishedAt"`
+	// Null for a successful attempt
+	Error *string `json:"error,omitempty"`
+}
+
+// Delivery of a verification completion to one channel, enqueued together with the final status
+type NotificationJob struct {
+	ID      string              `json:"id"`
+	Channel NotificationChannel `json:"channel"`
+	// Webhook of the WEBHOOK and COMPANY_CHANGE channels, null once the webhook is deleted
+	WebhookID *string               `json:"webhookId,omitempty"`
+	Status    NotificationJobStatus `json:"status"`
+	Attempts  int32                 `json:"attempts"`
+	// Earliest time of the next attempt of a PENDING job
+	NextAttemptAt *string                `json:"nextAttemptAt,omitempty"`
+	LastError     *string                `json:"lastError,omitempty"`
+	CreatedAt     string                 `json:"createdAt"`
+	FinishedAt    *string                `json:"finishedAt,omitempty"`
+	History       []*NotificationAttempt `json:"history"`
+}
+
+type OperationUsage struct {
+	// Operation name, or the operation type and root fields for anonymous operations
+	Operation string `json:"operation"`
+	Requests  int32  `json:"requests"`
+	Errors    int32  `json:"errors"`
+}
+
+// A user registered in an organization
+type OrganizationMember struct {
+	Email        string             `json:"email"`
+	Name         *string            `json:"name,omitempty"`
+	Organization string             `json:"organization"`
+	Status       UserStatus         `json:"status"`
+	Roles        []OrganizationRole `json:"roles"`
+	InvitedBy    string             `json:"invitedBy"`
+	CreatedAt    string             `json:"createdAt"`
+	LastSeenAt   *string            `json:"lastSeenAt,omitempty"`
+	// Verifications created by the user in the last 30 days
+	VerificationsLast30Days int32   `json:"verificationsLast30Days"`
+	LastVerificationAt      *string `json:"lastVerificationAt,omitempty"`
+}
+
+// Verifications of one organization created outside the sandbox in the period
+type OrganizationSpend struct {
+	Organization  string `json:"organization"`
+	Verifications int32  `json:"verifications"`
+	// Data types requested in those verifications, each billed by the providers separately
+	DataTypes []*DataTypeSpend `json:"dataTypes"`
+}
+
+// GraphQL operation an API key is allowed to run
+type PersistedOperation struct {
+	// SHA-256 of document, hex. Clients may send it in extensions.persistedQuery.sha256Hash instead of the document
+	Hash      string  `json:"hash"`
+	Name      *string `json:"name,omitempty"`
+	Document  string  `json:"document"`
+	CreatedBy string  `json:"createdBy"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+// Caller as resolved by the gateway: the identity access checks are made against
+type Principal struct {
+	ClientKind ClientKind `json:"clientKind"`
+	// User email, or service account email of the API key
+	Email string `json:"email"`
+	// Roles from the authentication proxy or the token, followed by the roles of the user account
+	Roles []string `json:"roles"`
+	// Organization of the user account
+	Organization *string `json:"organization,omitempty"`
+	// Tenant whose budgets and cached data requests use
+	Tenant string `json:"tenant"`
+	// Created verifications are sandbox verifications with synthetic data
+	Sandbox  bool         `json:"sandbox"`
+	KeyScope *APIKeyScope `json:"keyScope,omitempty"`
+	// Expiry of the bearer token of the connection, null for callers not authenticated by a token
+	TokenExpiresAt *string      `json:"tokenExpiresAt,omitempty"`
+	Quota          *TenantQuota `json:"quota"`
+}
+
+type Query struct {
+}
+
+// Latest verification of a company created within the requested age
+type RecentVerification struct {
+	// Whether the company has a verification created in the last maxAgeHours hours. The other fields are null when it has not
+	Exists         bool                `json:"exists"`
+	VerificationID *string             `json:"verificationId,omitempty"`
+	Status         *VerificationStatus `json:"status,omitempty"`
+	CreatedAt      *string             `json:"createdAt,omitempty"`
+}
+
+// Provider payload rejected on ingest and removed from the verification data
+type RejectedPayload struct {
+	ID             string               `json:"id"`
+	VerificationID string               `json:"verificationId"`
+	DataType       VerificationDataType `json:"dataType"`
+	// SHA-256 of the rejected payload
+	DataHash string                `json:"dataHash"`
+	Reason   RejectedPayloadReason `json:"reason"`
+	// Problems found in the payload, with the path to each string
+	Diagnostics []string `json:"diagnostics"`
+	SizeBytes   int32    `json:"sizeBytes"`
+	// First 4 KB of the payload
+	Sample     string `json:"sample"`
+	RejectedAt string `json:"rejectedAt"`
+}
+
+// Asynchronous generation of a case report. Visible to the organization that requested it
+type ReportJob struct {
+	ID     string          `json:"id"`
+	CaseID string          `json:"caseId"`
+	Format ReportFormat    `json:"format"`
+	Status ReportJobStatus `json:"status"`
+	// Percent of the report generated, 100 when succeeded
+	Progress    int32   `json:"progress"`
+	RequestedBy string  `json:"requestedBy"`
+	Error       *string `json:"error,omitempty"`
+	SizeBytes   *int32  `json:"sizeBytes,omitempty"`
+	// Signature of the file by the gateway key, base64. Null if the gateway has no signing key
+	Signature *string `json:"signature,omitempty"`
+	KeyID     *string `json:"keyId,omitempty"`
+	// Link to download the report, set while the report is SUCCEEDED. Expires at downloadUrlExpiresAt
+	DownloadURL          *string `json:"downloadUrl,omitempty"`
+	DownloadURLExpiresAt *string `json:"downloadUrlExpiresAt,omitempty"`
+	CreatedAt            string  `json:"createdAt"`
+	StartedAt            *string `json:"startedAt,omitempty"`
+	FinishedAt           *string `json:"finishedAt,omitempty"`
+	// When the report is deleted from storage
+	ExpiresAt *string `json:"expiresAt,omitempty"`
+}
+
+// Re-evaluation of stored verification data against the current scoring rules
+type ScoreRecalculation struct {
+	ID          string                   `json:"id"`
+	Status      ScoreRecalculationStatus `json:"status"`
+	RequestedBy string                   `json:"requestedBy"`
+	// Completed verifications matching the filter when the recalculation was requested
+	Total int32 `json:"total"`
+	// Verifications sent to the scoring engine
+	Published int32 `json:"published"`
+	// Verifications the scoring engine has returned a new score for
+	Rescored    int32   `json:"rescored"`
+	CreatedAt   string  `json:"createdAt"`
+	CompletedAt *string `json:"completedAt,omitempty"`
+}
+
+// Build of the running gateway
+type ServerInfo struct {
+	Version string `json:"version"`
+	// Commit the gateway was built from, empty if unknown
+	GitSha string `json:"gitSha"`
+	// Build time in RFC3339, empty if unknown
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+	// Version of this GraphQL schema, bumped on breaking changes
+	SchemaVersion int32 `json:"schemaVersion"`
+	// Gateway mode: all, api or consumer
+	Mode string `json:"mode"`
+}
+
+// Billable volume of an organization over an inclusive range of days (UTC)
+type SpendReport struct {
+	Organization string `json:"organization"`
+	From         string `json:"from"`
+	To           string `json:"to"`
+	// Totals of the organization and, when subsidiaries are included, of all its subsidiaries
+	Verifications int32            `json:"verifications"`
+	DataTypes     []*DataTypeSpend `json:"dataTypes"`
+	// Spend of each organization in the report, the requested one first. Organizations without verifications are omitted
+	Organizations []*OrganizationSpend `json:"organizations"`
+}
+
+type Subscription struct {
+}
+
+// Limits of the tenant that verification requests of the caller count against
+type TenantQuota struct {
+	// Verification requests per second, null without a limit
+	PublishRate  *float64 `json:"publishRate,omitempty"`
+	PublishBurst *int32   `json:"publishBurst,omitempty"`
+	// Verifications at workers at once, null without a limit
+	MaxInFlight *int32 `json:"maxInFlight,omitempty"`
+	// Verifications at workers now
+	InFlight int32 `json:"inFlight"`
+	// Verification requests held until workers have room
+	Held int32 `json:"held"`
+}
+
+// Whether the gateway accepts a bearer token or an API key, and as whom
+type TokenIntrospection struct {
+	Active bool `json:"active"`
+	// Why the token is rejected, null for active tokens
+	Reason *string `json:"reason,omitempty"`
+	// Null for rejected tokens
+	Principal *Principal `json:"principal,omitempty"`
+}
+
+type Verification struct {
+	ID     string             `json:"id"`
+	Inn    string             `json:"inn"`
+	Status VerificationStatus `json:"status"`
+	// Error reported by the scoring worker in the verification.completed message
+	ErrorMessage *string    `json:"errorMessage,omitempty"`
+	AuthorEmail  string     `json:"authorEmail"`
+	CompanyID    *string    `json:"companyId,omitempty"`
+	RiskLevel    *RiskLevel `json:"riskLevel,omitempty"`
+	// Scoring ruleset that produced riskLevel
+	RulesetID   *string      `json:"rulesetId,omitempty"`
+	ExternalRef *ExternalRef `json:"externalRef,omitempty"`
+	// Integrator metadata, sorted by key
+	Metadata []*MetadataEntry `json:"metadata"`
+	// Exempts the verification from author email anonymization
+	LegalHold bool `json:"legalHold"`
+	// Created by a sandbox API key or organization: data is synthetic and no provider was called
+	Sandbox            bool                   `json:"sandbox"`
+	RequestedDataTypes []VerificationDataType `json:"requestedDataTypes"`
+	// Requested data types declared in gateway configuration (DATA_TYPES_CUSTOM_DIR)
+	CustomDataTypes []string `json:"customDataTypes"`
+	// Requested data types the providers did not deliver
+	MissingDataTypes []VerificationDataType `json:"missingDataTypes"`
+	// Analyst reviewing the verification
+	Assignee      *string     `json:"assignee,omitempty"`
+	ReviewState   ReviewState `json:"reviewState"`
+	ReviewComment *string     `json:"reviewComment,omitempty"`
+	ReviewedAt    *string     `json:"reviewedAt,omitempty"`
+	// When a throttled request is expected to be sent to workers (set in the createVerification response and while PENDING)
+	ExpectedStartAt *string `json:"expectedStartAt,omitempty"`
+	// Position in the publish queue of the author's organization, 1 for the next request to be sent. Set only while PENDING
+	QueuePosition *int32 `json:"queuePosition,omitempty"`
+	// When the verification is expected to complete, from recent latencies of the requested data types and worker queues. Updated as data arrives, set only until the verification is final
+	EstimatedCompletionAt *string             `json:"estimatedCompletionAt,omitempty"`
+	Data                  []*VerificationData `json:"data,omitempty"`
+	// Validation of delivered data against the JSON Schema of its type. Loaded together with data
+	DataQuality []*DataQuality `json:"dataQuality,omitempty"`
+	// Delivered data of customDataTypes. Loaded together with data
+	CustomData []*GenericVerificationData `json:"customData,omitempty"`
+	CreatedAt  string                     `json:"createdAt"`
+	UpdatedAt  string                     `json:"updatedAt"`
+}
+
+// Data payload a worker delivered after the verification was completed
+type VerificationAmendment struct {
+	ID             string               `json:"id"`
+	VerificationID string               `json:"verificationId"`
+	DataType       VerificationDataType `json:"dataType"`
+	// SHA-256 of the amended payload
+	DataHash *string `json:"dataHash,omitempty"`
+	// Hash of the payload the amendment replaced, null when the data type was delivered for the first time
+	PreviousDataHash *string `json:"previousDataHash,omitempty"`
+	SchemaVersion    int32   `json:"schemaVersion"`
+	// Status of the verification when the payload arrived
+	StatusAtArrival VerificationStatus `json:"statusAtArrival"`
+	ReceivedAt      string             `json:"receivedAt"`
+	// When verification.amended was published, null while pending
+	PublishedAt *string `json:"publishedAt,omitempty"`
+}
+
+type VerificationAuditTrail struct {
+	Verification *Verification      `json:"verification"`
+	Entries      []*AuditTrailEntry `json:"entries"`
+}
+
+type VerificationData struct {
+	DataType VerificationDataType `json:"dataType"`
+	Data     string               `json:"data"`
+	// Version of the provider payload format
+	SchemaVersion int32  `json:"schemaVersion"`
+	CreatedAt     string `json:"createdAt"`
+	// Age of data served from the company data cache, null for data delivered for this verification
+	Freshness *DataFreshness `json:"freshness,omitempty"`
+}
+
+type VerificationDataResult struct {
+	Verification                    *Verification `json:"verification"`
+	BasicInformation                *string       `json:"basicInformation,omitempty"`
+	Activities                      *string       `json:"activities,omitempty"`
+	AddressesByCredinform           *string       `json:"addressesByCredinform,omitempty"`
+	AddressesByUnifiedStateRegister *string       `json:"addressesByUnifiedStateRegister,omitempty"`
+	AffiliatedCompanies             *string       `json:"affiliatedCompanies,omitempty"`
+	ArbitrageStatistics             *string       `json:"arbitrageStatistics,omitempty"`
+}
+
+// A verification in an export stream
+type VerificationEdge struct {
+	// Position of the verification in the filtered list counting from 1; pass the last cursor as offset to continue
+	Cursor int32         `json:"cursor"`
+	Node   *Verification `json:"node"`
+}
+
+type VerificationFilter struct {
+	Status      *VerificationStatus `json:"status,omitempty"`
+	Inn         *string             `json:"inn,omitempty"`
+	AuthorEmail *string             `json:"authorEmail,omitempty"`
+	// RFC3339 timestamp, or YYYY-MM-DD for the start of the day in timezone
+	CreatedFrom *string `json:"createdFrom,omitempty"`
+	// RFC3339 timestamp (exclusive), or YYYY-MM-DD for the end of the day in timezone (inclusive)
+	CreatedTo *string `json:"createdTo,omitempty"`
+	// IANA timezone for date-only bounds, e.g. Europe/Moscow. Defaults to UTC
+	Timezone       *string      `json:"timezone,omitempty"`
+	ExternalSystem *string      `json:"externalSystem,omitempty"`
+	ExternalRef    *string      `json:"externalRef,omitempty"`
+	Assignee       *string      `json:"assignee,omitempty"`
+	ReviewState    *ReviewState `json:"reviewState,omitempty"`
+	// Verifications whose metadata contains all the entries
+	Metadata []*MetadataEntryInput `json:"metadata,omitempty"`
+}
+
+// A risk level assigned to a verification by one version of the scoring rules
+type VerificationScore struct {
+	RiskLevel RiskLevel `json:"riskLevel"`
+	RulesetID *string   `json:"rulesetId,omitempty"`
+	// Set when the score was produced by recalculateScores
+	RecalculationID *string `json:"recalculationId,omitempty"`
+	ScoredAt        string  `json:"scoredAt"`
+}
+
+// Gateway signature over the outcome of a completed verification
+type VerificationSignature struct {
+	Algorithm string `json:"algorithm"`
+	// Fingerprint of the public key served at /signing-key
+	KeyID string `json:"keyId"`
+	// Signed text: verification id, status, completion time and SHA-256 of each data payload
+	Content string `json:"content"`
+	// SHA-256 of content, hex
+	Digest string `json:"digest"`
+	// Signature of content, base64
+	Signature string `json:"signature"`
+	SignedAt  string `json:"signedAt"`
+}
+
+// Endpoint notified when verifications complete
+type Webhook struct {
+	ID       string          `json:"id"`
+	URL      string          `json:"url"`
+	Template WebhookTemplate `json:"template"`
+	Events   []WebhookEvent  `json:"events"`
+	// Go text/template over the JSON payload {event, occurredAt, verification}, plus previousVerificationId and changes for company.changed. Only for CUSTOM
+	CustomTemplate *string `json:"customTemplate,omitempty"`
+	// Extra request headers, values are rendered like customTemplate
+	Headers   []*WebhookHeader `json:"headers"`
+	CreatedBy string           `json:"createdBy"`
+	CreatedAt string           `json:"createdAt"`
+}
+
+type WebhookHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type WebhookHeaderInput struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type WebhookInput struct {
+	URL      string          `json:"url"`
+	Template WebhookTemplate `json:"template"`
+	// Defaults to VERIFICATION_COMPLETED
+	Events         []WebhookEvent        `json:"events,omitempty"`
+	CustomTemplate *string               `json:"customTemplate,omitempty"`
+	Headers        []*WebhookHeaderInput `json:"headers,omitempty"`
+}
+
+// Request the webhook would receive
+type WebhookPreview struct {
+	ContentType string           `json:"contentType"`
+	Headers     []*WebhookHeader `json:"headers"`
+	Body        string           `json:"body"`
+}
+
+type AccessOperation string
+
+const (
+	AccessOperationRead   AccessOperation = "READ"
+	AccessOperationCreate AccessOperation = "CREATE"
+)
+
+var AllAccessOperation = []AccessOperation{
+	AccessOperationRead,
+	AccessOperationCreate,
+}
+
+func (e AccessOperation) IsValid() bool {
+	switch e {
+	case AccessOperationRead, AccessOperationCreate:
+		return true
+	}
+	return false
+}
+
+func (e AccessOperation) String() string {
+	return string(e)
+}
+
+func (e *AccessOperation) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AccessOperation(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AccessOperation", str)
+	}
+	return nil
+}
+
+func (e AccessOperation) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *AccessOperation) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e AccessOperation) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type AuditEventType string
+
+const (
+	AuditEventTypeCreated               AuditEventType = "CREATED"
+	AuditEventTypeDataReceived          AuditEventType = "DATA_RECEIVED"
+	AuditEventTypeStatusChanged         AuditEventType = "STATUS_CHANGED"
+	AuditEventTypeNotificationDelivered AuditEventType = "NOTIFICATION_DELIVERED"
+	AuditEventTypeLegalHoldChanged      AuditEventType = "LEGAL_HOLD_CHANGED"
+	AuditEventTypeAnonymized            AuditEventType = "ANONYMIZED"
+	AuditEventTypeReviewAssigned        AuditEventType = "REVIEW_ASSIGNED"
+	AuditEventTypeReviewCompleted       AuditEventType = "REVIEW_COMPLETED"
+	// Data delivered after the verification was completed
+	AuditEventTypeDataAmended AuditEventType = "DATA_AMENDED"
+	// A value of a stored payload corrected by an admin
+	AuditEventTypeDataPatched AuditEventType = "DATA_PATCHED"
+	// A value of a stored payload redacted by an admin
+	AuditEventTypeDataRedacted AuditEventType = "DATA_REDACTED"
+	// A completion event rejected as stale, replayed or superseded
+	AuditEventTypeEventRejected AuditEventType = "EVENT_REJECTED"
+	// A completion submitted by an admin in place of the workers
+	AuditEventTypeCompletionSimulated AuditEventType = "COMPLETION_SIMULATED"
+)
+
+var AllAuditEventType = []AuditEventType{
+	AuditEventTypeCreated,
+	AuditEventTypeDataReceived,
+	AuditEventTypeStatusChanged,
+	AuditEventTypeNotificationDelivered,
+	AuditEventTypeLegalHoldChanged,
+	AuditEventTypeAnonymized,
+	AuditEventTypeReviewAssigned,
+	AuditEventTypeReviewCompleted,
+	AuditEventTypeDataAmended,
+	AuditEventTypeDataPatched,
+	AuditEventTypeDataRedacted,
+	AuditEventTypeEventRejected,
+	AuditEventTypeCompletionSimulated,
+}
+
+func (e AuditEventType) IsValid() bool {
+	switch e {
+	case AuditEventTypeCreated, AuditEventTypeDataReceived, AuditEventTypeStatusChanged, AuditEventTypeNotificationDelivered, AuditEventTypeLegalHoldChanged, AuditEventTypeAnonymized, AuditEventTypeReviewAssigned, AuditEventTypeReviewCompleted, AuditEventTypeDataAmended, AuditEventTypeDataPatched, AuditEventTypeDataRedacted, AuditEventTypeEventRejected, AuditEventTypeCompletionSimulated:
+		return true
+	}
+	return false
+}
+
+func (e AuditEventType) String() string {
+	return string(e)
+}
+
+func (e *AuditEventType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AuditEventType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AuditEventType", str)
+	}
+	return nil
+}
+
+func (e AuditEventType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *AuditEventType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e AuditEventType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// Relation of a verified company to the subject of a case
+type CasePartyRole string
+
+const (
+	// Company the case is opened for
+	CasePartyRoleSubject CasePartyRole = "SUBJECT"
+	// Other company of the subject's founders
+	CasePartyRoleFounderCompany CasePartyRole = "FOUNDER_COMPANY"
+	CasePartyRoleAffiliate      CasePartyRole = "AFFILIATE"
+)
+
+var AllCasePartyRole = []CasePartyRole{
+	CasePartyRoleSubject,
+	CasePartyRoleFounderCompany,
+	CasePartyRoleAffiliate,
+}
+
+func (e CasePartyRole) IsValid() bool {
+	switch e {
+	case CasePartyRoleSubject, CasePartyRoleFounderCompany, CasePartyRoleAffiliate:
+		return true
+	}
+	return false
+}
+
+func (e CasePartyRole) String() string {
+	return string(e)
+}
+
+func (e *CasePartyRole) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CasePartyRole(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CasePartyRole", str)
+	}
+	return nil
+}
+
+func (e CasePartyRole) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CasePartyRole) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CasePartyRole) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ClientKind string
+
+const (
+	ClientKindUser   ClientKind = "USER"
+	ClientKindAPIKey ClientKind = "API_KEY"
+)
+
+var AllClientKind = []ClientKind{
+	ClientKindUser,
+	ClientKindAPIKey,
+}
+
+func (e ClientKind) IsValid() bool {
+	switch e {
+	case ClientKindUser, ClientKindAPIKey:
+		return true
+	}
+	return false
+}
+
+func (e ClientKind) String() string {
+	return string(e)
+}
+
+func (e *ClientKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ClientKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ClientKind", str)
+	}
+	return nil
+}
+
+func (e ClientKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ClientKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ClientKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type CostTier string
+
+const (
+	CostTierLow    CostTier = "LOW"
+	CostTierMedium CostTier = "MEDIUM"
+	CostTierHigh   CostTier = "HIGH"
+)
+
+var AllCostTier = []CostTier{
+	CostTierLow,
+	CostTierMedium,
+	CostTierHigh,
+}
+
+func (e CostTier) IsValid() bool {
+	switch e {
+	case CostTierLow, CostTierMedium, CostTierHigh:
+		return true
+	}
+	return false
+}
+
+func (e CostTier) String() string {
+	return string(e)
+}
+
+func (e *CostTier) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CostTier(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CostTier", str)
+	}
+	return nil
+}
+
+func (e CostTier) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CostTier) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CostTier) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// Freshness of data served from the company data cache
+type DataFreshness string
+
+const (
+	// Within the fresh period of the data type
+	DataFreshnessFresh DataFreshness = "FRESH"
+	// Past the fresh period, served while the data is refreshed in the background
+	DataFreshnessStale DataFreshness = "STALE"
+)
+
+var AllDataFreshness = []DataFreshness{
+	DataFreshnessFresh,
+	DataFreshnessStale,
+}
+
+func (e DataFreshness) IsValid() bool {
+	switch e {
+	case DataFreshnessFresh, DataFreshnessStale:
+		return true
+	}
+	return false
+}
+
+func (e DataFreshness) String() string {
+	return string(e)
+}
+
+func (e *DataFreshness) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DataFreshness(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DataFreshness", str)
+	}
+	return nil
+}
+
+func (e DataFreshness) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *DataFreshness) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e DataFreshness) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// Admin change of a stored data payload
+type DataRedactionOperation string
+
+const (
+	// Replaces the value at the path
+	DataRedactionOperationPatch DataRedactionOperation = "PATCH"
+	// Replaces the value at the path with "[REDACTED]"
+	DataRedactionOperationRedact DataRedactionOperation = "REDACT"
+)
+
+var AllDataRedactionOperation = []DataRedactionOperation{
+	DataRedactionOperationPatch,
+	DataRedactionOperationRedact,
+}
+
+func (e DataRedactionOperation) IsValid() bool {
+	switch e {
+	case DataRedactionOperationPatch, DataRedactionOperationRedact:
+		return true
+	}
+	return false
+}
+
+func (e DataRedactionOperation) String() string {
+	return string(e)
+}
+
+func (e *DataRedactionOperation) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DataRedactionOperation(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DataRedactionOperation", str)
+	}
+	return nil
+}
+
+func (e DataRedactionOperation) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *DataRedactionOperation) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e DataRedactionOperation) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type NotificationChannel string
+
+const (
+	// Notification in the author's inbox and to everyone who verified the company before, if its risk level changed
+	NotificationChannelInApp   NotificationChannel = "IN_APP"
+	NotificationChannelWebhook NotificationChannel = "WEBHOOK"
+	// Company change webhook of a monitoring re-verification, delivered only if the data changed materially
+	NotificationChannelCompanyChange NotificationChannel = "COMPANY_CHANGE"
+)
+
+var AllNotificationChannel = []NotificationChannel{
+	NotificationChannelInApp,
+	NotificationChannelWebhook,
+	NotificationChannelCompanyChange,
+}
+
+func (e NotificationChannel) IsValid() bool {
+	switch e {
+	case NotificationChannelInApp, NotificationChannelWebhook, NotificationChannelCompanyChange:
+		return true
+	}
+	return false
+}
+
+func (e NotificationChannel) String() string {
+	return string(e)
+}
+
+func (e *NotificationChannel) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationChannel(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationChannel", str)
+	}
+	return nil
+}
+
+func (e NotificationChannel) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *NotificationChannel) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e NotificationChannel) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type NotificationJobStatus string
+
+const (
+	NotificationJobStatusPending   NotificationJobStatus = "PENDING"
+	NotificationJobStatusDelivered NotificationJobStatus = "DELIVERED"
+	// Not delivered within the attempt limit, or the webhook was deleted
+	NotificationJobStatusFailed NotificationJobStatus = "FAILED"
+)
+
+var AllNotificationJobStatus = []NotificationJobStatus{
+	NotificationJobStatusPending,
+	NotificationJobStatusDelivered,
+	NotificationJobStatusFailed,
+}
+
+func (e NotificationJobStatus) IsValid() bool {
+	switch e {
+	case NotificationJobStatusPending, NotificationJobStatusDelivered, NotificationJobStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e NotificationJobStatus) String() string {
+	return string(e)
+}
+
+func (e *NotificationJobStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationJobStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationJobStatus", str)
+	}
+	return nil
+}
+
+func (e NotificationJobStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *NotificationJobStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e NotificationJobStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type NotificationKind string
+
+const (
+	NotificationKindVerificationCompleted   NotificationKind = "VERIFICATION_COMPLETED"
+	NotificationKindSLABreached             NotificationKind = "SLA_BREACHED"
+	NotificationKindMonitoredCompanyChanged NotificationKind = "MONITORED_COMPANY_CHANGED"
+	NotificationKindReviewAssigned          NotificationKind = "REVIEW_ASSIGNED"
+	NotificationKindReviewCompleted         NotificationKind = "REVIEW_COMPLETED"
+)
+
+var AllNotificationKind = []NotificationKind{
+	NotificationKindVerificationCompleted,
+	NotificationKindSLABreached,
+	NotificationKindMonitoredCompanyChanged,
+	NotificationKindReviewAssigned,
+	NotificationKindReviewCompleted,
+}
+
+func (e NotificationKind) IsValid() bool {
+	switch e {
+	case NotificationKindVerificationCompleted, NotificationKindSLABreached, NotificationKindMonitoredCompanyChanged, NotificationKindReviewAssigned, NotificationKindReviewCompleted:
+		return true
+	}
+	return false
+}
+
+func (e NotificationKind) String() string {
+	return string(e)
+}
+
+func (e *NotificationKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationKind", str)
+	}
+	return nil
+}
+
+func (e NotificationKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *NotificationKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e NotificationKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type OrganizationRole string
+
+const (
+	// Manages users of the organization and of its subsidiaries
+	OrganizationRoleOrgAdmin OrganizationRole = "ORG_ADMIN"
+	OrganizationRoleAnalyst  OrganizationRole = "ANALYST"
+	// Read-only access: cannot create verifications
+	OrganizationRoleViewer OrganizationRole = "VIEWER"
+)
+
+var AllOrganizationRole = []OrganizationRole{
+	OrganizationRoleOrgAdmin,
+	OrganizationRoleAnalyst,
+	OrganizationRoleViewer,
+}
+
+func (e OrganizationRole) IsValid() bool {
+	switch e {
+	case OrganizationRoleOrgAdmin, OrganizationRoleAnalyst, OrganizationRoleViewer:
+		return true
+	}
+	return false
+}
+
+func (e OrganizationRole) String() string {
+	return string(e)
+}
+
+func (e *OrganizationRole) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OrganizationRole(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OrganizationRole", str)
+	}
+	return nil
+}
+
+func (e OrganizationRole) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *OrganizationRole) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e OrganizationRole) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// Why a provider payload was rejected on ingest
+type RejectedPayloadReason string
+
+const (
+	// Not JSON, text lost by a lossy decoding or binary data in strings
+	RejectedPayloadReasonInvalid RejectedPayloadReason = "INVALID"
+	// Over the size limit of the data type with no object storage configured
+	RejectedPayloadReasonOversized RejectedPayloadReason = "OVERSIZED"
+)
+
+var AllRejectedPayloadReason = []RejectedPayloadReason{
+	RejectedPayloadReasonInvalid,
+	RejectedPayloadReasonOversized,
+}
+
+func (e RejectedPayloadReason) IsValid() bool {
+	switch e {
+	case RejectedPayloadReasonInvalid, RejectedPayloadReasonOversized:
+		return true
+	}
+	return false
+}
+
+func (e RejectedPayloadReason) String() string {
+	return string(e)
+}
+
+func (e *RejectedPayloadReason) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RejectedPayloadReason(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RejectedPayloadReason", str)
+	}
+	return nil
+}
+
+func (e RejectedPayloadReason) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *RejectedPayloadReason) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e RejectedPayloadReason) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ReportFormat string
+
+const (
+	ReportFormatPDF  ReportFormat = "PDF"
+	ReportFormatXlsx ReportFormat = "XLSX"
+)
+
+var AllReportFormat = []ReportFormat{
+	ReportFormatPDF,
+	ReportFormatXlsx,
+}
+
+func (e ReportFormat) IsValid() bool {
+	switch e {
+	case ReportFormatPDF, ReportFormatXlsx:
+		return true
+	}
+	return false
+}
+
+func (e ReportFormat) String() string {
+	return string(e)
+}
+
+func (e *ReportFormat) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportFormat", str)
+	}
+	return nil
+}
+
+func (e ReportFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ReportFormat) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ReportFormat) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ReportJobStatus string
+
+const (
+	ReportJobStatusQueued    ReportJobStatus = "QUEUED"
+	ReportJobStatusRunning   ReportJobStatus = "RUNNING"
+	ReportJobStatusSucceeded ReportJobStatus = "SUCCEEDED"
+	ReportJobStatusFailed    ReportJobStatus = "FAILED"
+	// The retention period of the report ended and the file was deleted
+	ReportJobStatusExpired ReportJobStatus = "EXPIRED"
+)
+
+var AllReportJobStatus = []ReportJobStatus{
+	ReportJobStatusQueued,
+	ReportJobStatusRunning,
+	ReportJobStatusSucceeded,
+	ReportJobStatusFailed,
+	ReportJobStatusExpired,
+}
+
+func (e ReportJobStatus) IsValid() bool {
+	switch e {
+	case ReportJobStatusQueued, ReportJobStatusRunning, ReportJobStatusSucceeded, ReportJobStatusFailed, ReportJobStatusExpired:
+		return true
+	}
+	return false
+}
+
+func (e ReportJobStatus) String() string {
+	return string(e)
+}
+
+func (e *ReportJobStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportJobStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportJobStatus", str)
+	}
+	return nil
+}
+
+func (e ReportJobStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ReportJobStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ReportJobStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// Manual review step after scoring
+type ReviewState string
+
+const (
+	ReviewStateUnreviewed ReviewState = "UNREVIEWED"
+	// Assigned to an analyst who has not decided yet
+	ReviewStateInReview ReviewState = "IN_REVIEW"
+	ReviewStateApproved ReviewState = "APPROVED"
+	ReviewStateRejected ReviewState = "REJECTED"
+)
+
+var AllReviewState = []ReviewState{
+	ReviewStateUnreviewed,
+	ReviewStateInReview,
+	ReviewStateApproved,
+	ReviewStateRejected,
+}
+
+func (e ReviewState) IsValid() bool {
+	switch e {
+	case ReviewStateUnreviewed, ReviewStateInReview, ReviewStateApproved, ReviewStateRejected:
+		return true
+	}
+	return false
+}
+
+func (e ReviewState) String() string {
+	return string(e)
+}
+
+func (e *ReviewState) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReviewState(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReviewState", str)
+	}
+	return nil
+}
+
+func (e ReviewState) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ReviewState) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
 }
 
-type Subscription struct {
+func (e ReviewState) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
 }
 
-type Verification struct {
-	ID                 string                 `json:"id"`
-	Inn                string                 `json:"inn"`
-	Status             VerificationStatus     `json:"status"`
-	AuthorEmail        string                 `json:"authorEmail"`
-	CompanyID          *string                `json:"companyId,omitempty"`
-	RequestedDataTypes []VerificationDataType `json:"requestedDataTypes"`
-	Data               []*VerificationData    `json:"data,omitempty"`
-	CreatedAt          string                 `json:"createdAt"`
-	UpdatedAt          string                 `json:"updatedAt"`
+type RiskLevel string
+
+const (
+	RiskLevelLow    RiskLevel = "LOW"
+	RiskLevelMedium RiskLevel = "MEDIUM"
+	RiskLevelHigh   RiskLevel = "HIGH"
+)
+
+var AllRiskLevel = []RiskLevel{
+	RiskLevelLow,
+	RiskLevelMedium,
+	RiskLevelHigh,
 }
 
-type VerificationData struct {
-	DataType  VerificationDataType `json:"dataType"`
-	Data      string               `json:"data"`
-	CreatedAt string               `json:"createdAt"`
+func (e RiskLevel) IsValid() bool {
+	switch e {
+	case RiskLevelLow, RiskLevelMedium, RiskLevelHigh:
+		return true
+	}
+	return false
 }
 
-type VerificationDataResult struct {
-	Verification                    *Verification `json:"verification"`
-	BasicInformation                *string       `json:"basicInformation,omitempty"`
-	Activities                      *string       `json:"activities,omitempty"`
-	AddressesByCredinform           *string       `json:"addressesByCredinform,omitempty"`
-	AddressesByUnifiedStateRegister *string       `json:"addressesByUnifiedStateRegister,omitempty"`
-	AffiliatedCompanies             *string       `json:"affiliatedCompanies,omitempty"`
-	ArbitrageStatistics             *string       `json:"arbitrageStatistics,omitempty"`
+func (e RiskLevel) String() string {
+	return string(e)
+}
+
+func (e *RiskLevel) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RiskLevel(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RiskLevel", str)
+	}
+	return nil
+}
+
+func (e RiskLevel) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *RiskLevel) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e RiskLevel) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ScoreRecalculationStatus string
+
+const (
+	ScoreRecalculationStatusPending   ScoreRecalculationStatus = "PENDING"
+	ScoreRecalculationStatusRunning   ScoreRecalculationStatus = "RUNNING"
+	ScoreRecalculationStatusCompleted ScoreRecalculationStatus = "COMPLETED"
+)
+
+var AllScoreRecalculationStatus = []ScoreRecalculationStatus{
+	ScoreRecalculationStatusPending,
+	ScoreRecalculationStatusRunning,
+	ScoreRecalculationStatusCompleted,
+}
+
+func (e ScoreRecalculationStatus) IsValid() bool {
+	switch e {
+	case ScoreRecalculationStatusPending, ScoreRecalculationStatusRunning, ScoreRecalculationStatusCompleted:
+		return true
+	}
+	return false
+}
+
+func (e ScoreRecalculationStatus) String() string {
+	return string(e)
+}
+
+func (e *ScoreRecalculationStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ScoreRecalculationStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ScoreRecalculationStatus", str)
+	}
+	return nil
+}
+
+func (e ScoreRecalculationStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ScoreRecalculationStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ScoreRecalculationStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type UserStatus string
+
+const (
+	// Invited, has not made a request yet
+	UserStatusInvited     UserStatus = "INVITED"
+	UserStatusActive      UserStatus = "ACTIVE"
+	UserStatusDeactivated UserStatus = "DEACTIVATED"
+)
+
+var AllUserStatus = []UserStatus{
+	UserStatusInvited,
+	UserStatusActive,
+	UserStatusDeactivated,
+}
+
+func (e UserStatus) IsValid() bool {
+	switch e {
+	case UserStatusInvited, UserStatusActive, UserStatusDeactivated:
+		return true
+	}
+	return false
+}
+
+func (e UserStatus) String() string {
+	return string(e)
+}
+
+func (e *UserStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = UserStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid UserStatus", str)
+	}
+	return nil
+}
+
+func (e UserStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *UserStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e UserStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
 }
 
 type VerificationDataType string
@@ -109,27 +1816,96 @@ func (e VerificationDataType) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// Which verifications the verifications query lists
+type VerificationScope string
+
+const (
+	// Verifications created by the caller
+	VerificationScopeMine VerificationScope = "MINE"
+	// Verifications created by members of the caller's organization
+	VerificationScopeMyOrg VerificationScope = "MY_ORG"
+	// All verifications. Requires the admin role
+	VerificationScopeAll VerificationScope = "ALL"
+)
+
+var AllVerificationScope = []VerificationScope{
+	VerificationScopeMine,
+	VerificationScopeMyOrg,
+	VerificationScopeAll,
+}
+
+func (e VerificationScope) IsValid() bool {
+	switch e {
+	case VerificationScopeMine, VerificationScopeMyOrg, VerificationScopeAll:
+		return true
+	}
+	return false
+}
+
+func (e VerificationScope) String() string {
+	return string(e)
+}
+
+func (e *VerificationScope) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = VerificationScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid VerificationScope", str)
+	}
+	return nil
+}
+
+func (e VerificationScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *VerificationScope) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e VerificationScope) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type VerificationStatus string
 
 const (
-	VerificationStatusInProcess       VerificationStatus = "IN_PROCESS"
-	VerificationStatusProcessing      VerificationStatus = "PROCESSING"
-	VerificationStatusCompleted       VerificationStatus = "COMPLETED"
-	VerificationStatusError           VerificationStatus = "ERROR"
-	VerificationStatusCompanyNotFound VerificationStatus = "COMPANY_NOT_FOUND"
+	// Accepted by queued admission and waiting in the publish queue; not yet sent to workers
+	VerificationStatusPending            VerificationStatus = "PENDING"
+	VerificationStatusInProcess          VerificationStatus = "IN_PROCESS"
+	VerificationStatusProcessing         VerificationStatus = "PROCESSING"
+	VerificationStatusCompleted          VerificationStatus = "COMPLETED"
+	VerificationStatusPartiallyCompleted VerificationStatus = "PARTIALLY_COMPLETED"
+	VerificationStatusError              VerificationStatus = "ERROR"
+	VerificationStatusCompanyNotFound    VerificationStatus = "COMPANY_NOT_FOUND"
+	// Held until the author confirms their email by following the link sent to it; not yet sent to workers
+	VerificationStatusPendingEmailConfirmation VerificationStatus = "PENDING_EMAIL_CONFIRMATION"
 )
 
 var AllVerificationStatus = []VerificationStatus{
+	VerificationStatusPending,
 	VerificationStatusInProcess,
 	VerificationStatusProcessing,
 	VerificationStatusCompleted,
+	VerificationStatusPartiallyCompleted,
 	VerificationStatusError,
 	VerificationStatusCompanyNotFound,
+	VerificationStatusPendingEmailConfirmation,
 }
 
 func (e VerificationStatus) IsValid() bool {
 	switch e {
-	case VerificationStatusInProcess, VerificationStatusProcessing, VerificationStatusCompleted, VerificationStatusError, VerificationStatusCompanyNotFound:
+	case VerificationStatusPending, VerificationStatusInProcess, VerificationStatusProcessing, VerificationStatusCompleted, VerificationStatusPartiallyCompleted, VerificationStatusError, VerificationStatusCompanyNotFound, VerificationStatusPendingEmailConfirmation:
 		return true
 	}
 	return false
@@ -169,3 +1945,125 @@ func (e VerificationStatus) MarshalJSON() ([]byte, error) {
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
+
+// Event a webhook is notified about
+type WebhookEvent string
+
+const (
+	// Verification reached a final status
+	WebhookEventVerificationCompleted WebhookEvent = "VERIFICATION_COMPLETED"
+	// Monitoring re-verification found material changes in the company data since the previous verification
+	WebhookEventCompanyChanged WebhookEvent = "COMPANY_CHANGED"
+)
+
+var AllWebhookEvent = []WebhookEvent{
+	WebhookEventVerificationCompleted,
+	WebhookEventCompanyChanged,
+}
+
+func (e WebhookEvent) IsValid() bool {
+	switch e {
+	case WebhookEventVerificationCompleted, WebhookEventCompanyChanged:
+		return true
+	}
+	return false
+}
+
+func (e WebhookEvent) String() string {
+	return string(e)
+}
+
+func (e *WebhookEvent) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookEvent(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookEvent", str)
+	}
+	return nil
+}
+
+func (e WebhookEvent) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *WebhookEvent) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e WebhookEvent) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// Shape of the webhook request body
+type WebhookTemplate string
+
+const (
+	// Verification JSON with the delivered data
+	WebhookTemplateFull WebhookTemplate = "FULL"
+	// Identifier, INN, status and risk level
+	WebhookTemplateSummary WebhookTemplate = "SUMMARY"
+	// CloudEvents 1.0 structured JSON envelope with the full verification in data
+	WebhookTemplateCloudevents WebhookTemplate = "CLOUDEVENTS"
+	// Body rendered from customTemplate
+	WebhookTemplateCustom WebhookTemplate = "CUSTOM"
+)
+
+var AllWebhookTemplate = []WebhookTemplate{
+	WebhookTemplateFull,
+	WebhookTemplateSummary,
+	WebhookTemplateCloudevents,
+	WebhookTemplateCustom,
+}
+
+func (e WebhookTemplate) IsValid() bool {
+	switch e {
+	case WebhookTemplateFull, WebhookTemplateSummary, WebhookTemplateCloudevents, WebhookTemplateCustom:
+		return true
+	}
+	return false
+}
+
+func (e WebhookTemplate) String() string {
+	return string(e)
+}
+
+func (e *WebhookTemplate) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookTemplate(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookTemplate", str)
+	}
+	return nil
+}
+
+func (e WebhookTemplate) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *WebhookTemplate) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e WebhookTemplate) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
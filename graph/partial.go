@@ -0,0 +1,43 @@
+package graph
+
+import (
+	"context"
+
+	"scoring_api_gateway/internal/repository"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// PartialErrors расширение gqlgen, которое сообщает клиенту о строках, пропущенных репозиториями
+// из-за ошибок чтения. Ответ возвращается без них, а в extensions.partialErrors перечисляются
+// пропущенные записи, чтобы отсутствие строк не выглядело как отсутствие данных.
+type PartialErrors struct{}
+
+var (
+	_ graphql.HandlerExtension    = PartialErrors{}
+	_ graphql.ResponseInterceptor = PartialErrors{}
+)
+
+func (PartialErrors) ExtensionName() string {
+	return "PartialErrors"
+}
+
+func (PartialErrors) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (PartialErrors) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	ctx = repository.WithScanFailures(ctx)
+	resp := next(ctx)
+	if resp == nil {
+		return resp
+	}
+
+	if failures := repository.ScanFailuresFromContext(ctx); len(failures) > 0 {
+		if resp.Extensions == nil {
+			resp.Extensions = map[string]any{}
+		}
+		resp.Extensions["partialErrors"] = failures
+	}
+	return resp
+}
@@ -0,0 +1,53 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/repository"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPartialErrorsExtension(t *testing.T) {
+	resolver := &Resolver{
+		VerificationService: &mockVerificationService{
+			getFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+				if id == "corrupted" {
+					repository.AddScanFailure(ctx, repository.ScanFailure{Entity: "verification data", ID: id, Error: "cannot scan NULL into *string"})
+				}
+				return &model.Verification{ID: id, Inn: "7707083893", Status: model.VerificationStatusCompleted}, nil
+			},
+		},
+		Logger: zaptest.NewLogger(t),
+	}
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: resolver}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(PartialErrors{})
+	c := client.New(srv)
+
+	raw, err := c.RawPost(`query { verification(id: "v-1") { id } }`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := raw.Extensions["partialErrors"]; ok {
+		t.Errorf("expected no partial errors, but got %v", raw.Extensions["partialErrors"])
+	}
+
+	raw, err = c.RawPost(`query { verification(id: "corrupted") { id } }`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	failures, ok := raw.Extensions["partialErrors"].([]any)
+	if !ok || len(failures) != 1 {
+		t.Fatalf("expected one partial error, but got %v", raw.Extensions["partialErrors"])
+	}
+	failure := failures[0].(map[string]any)
+	if failure["entity"] != "verification data" || failure["id"] != "corrupted" {
+		t.Errorf("unexpected partial error: %v", failure)
+	}
+}
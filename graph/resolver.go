@@ -1,6 +1,11 @@
 package graph
 
 import (
+	"context"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/maintenance"
 	"scoring_api_gateway/internal/service"
 
 	"go.uber.org/zap"
@@ -11,6 +16,49 @@ import (
 // It serves as dependency injection for your app, add any dependencies you require here.
 
 type Resolver struct {
-	VerificationService service.VerificationService
-	Logger              *zap.Logger
+	VerificationService         service.VerificationService
+	AuditService                service.AuditService
+	NotificationService         service.NotificationService
+	CatalogService              service.CatalogService
+	StatisticsService           service.StatisticsService
+	PrivacyService              service.PrivacyService
+	ScoringService              service.ScoringService
+	SignatureService            service.SignatureService
+	PersistedOperationService   service.PersistedOperationService
+	UserService                 service.UserService
+	AccessReviewService         service.AccessReviewService
+	CompanyDataService          service.CompanyDataService
+	LatencyService              service.LatencyService
+	ReviewService               service.ReviewService
+	WebhookService              service.WebhookService
+	NotificationJobService      service.NotificationJobService
+	UsageService                service.UsageService
+	AmendmentService            service.AmendmentService
+	DataRedactionService        service.DataRedactionService
+	PayloadService              service.PayloadService
+	ExportService               service.ExportService
+	CaseService                 service.CaseService
+	ReportService               service.ReportService
+	CloneService                service.CloneService
+	CompletionWaiter            service.CompletionWaiter
+	IdentityService             service.IdentityService
+	CompletionSimulationService service.CompletionSimulationService
+	Maintenance                 *maintenance.Mode
+	Build                       *model.ServerInfo
+	Logger                      *zap.Logger
+}
+
+// requestAuthor возвращает автора создаваемой проверки. Анонимные запросы пока создаются от имени заглушки
+func requestAuthor(ctx context.Context) string {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && principal.Email != "" {
+		return principal.Email
+	}
+	return "test@example.com"
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
 }
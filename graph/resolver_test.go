@@ -0,0 +1,380 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/maintenance"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/service"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для VerificationService: методы, не заданные в тесте, паникуют через nil интерфейс
+type mockVerificationService struct {
+	service.VerificationService
+	createFunc func(ctx context.Context, inn string, requestedDataTypes []model.VerificationDataType, authorEmail string, opts service.CreateOptions) (*model.Verification, error)
+	getFunc    func(ctx context.Context, id string) (*model.Verification, error)
+	dataTypes  []model.VerificationDataType
+	// unavailable типы, данные которых репозиторий не смог прочитать
+	unavailable []model.VerificationDataType
+}
+
+func (m *mockVerificationService) GetVerificationWithData(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.VerificationDataResult, error) {
+	m.dataTypes = dataTypes
+	activities := `{"activities": []}`
+	for _, dataType := range m.unavailable {
+		repository.AddUnavailableData(ctx, repository.UnavailableData{VerificationID: id, DataType: dataType, Hash: "deadbeef"})
+	}
+	verification := &model.Verification{ID: id, Status: model.VerificationStatusCompleted, Data: []*model.VerificationData{
+		{DataType: model.VerificationDataTypeActivities, Data: activities},
+	}}
+	return &model.VerificationDataResult{Verification: verification, Activities: &activities}, nil
+}
+
+func (m *mockVerificationService) CreateVerificationWithOptions(ctx context.Context, inn string, requestedDataTypes []model.VerificationDataType, authorEmail string, opts service.CreateOptions) (*model.Verification, error) {
+	return m.createFunc(ctx, inn, requestedDataTypes, authorEmail, opts)
+}
+
+func (m *mockVerificationService) GetVerification(ctx context.Context, id string) (*model.Verification, error) {
+	return m.getFunc(ctx, id)
+}
+
+// Mock для NotificationService
+type mockNotificationService struct {
+	service.NotificationService
+	listFunc func(ctx context.Context, userEmail string, unreadOnly bool) ([]*model.Notification, error)
+}
+
+func (m *mockNotificationService) ListForUser(ctx context.Context, userEmail string, unreadOnly bool) ([]*model.Notification, error) {
+	return m.listFunc(ctx, userEmail, unreadOnly)
+}
+
+func containsError(err error, prefix string) bool {
+	return err != nil && strings.Contains(err.Error(), prefix)
+}
+
+func TestCreateVerificationAuthor(t *testing.T) {
+	tests := []struct {
+		name           string
+		principal      *auth.Principal
+		expectedAuthor string
+	}{
+		{
+			name:           "authenticated_user",
+			principal:      &auth.Principal{Email: "user@company.ru"},
+			expectedAuthor: "user@company.ru",
+		},
+		{
+			name:           "anonymous_request",
+			expectedAuthor: "test@example.com",
+		},
+		{
+			name:           "principal_without_email",
+			principal:      &auth.Principal{},
+			expectedAuthor: "test@example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var author string
+			resolver := &Resolver{
+				VerificationService: &mockVerificationService{
+					createFunc: func(ctx context.Context, inn string, requestedDataTypes []model.VerificationDataType, authorEmail string, opts service.CreateOptions) (*model.Verification, error) {
+						author = authorEmail
+						return &model.Verification{Inn: inn, AuthorEmail: authorEmail}, nil
+					},
+				},
+				Logger: zaptest.NewLogger(t),
+			}
+
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = auth.WithPrincipal(ctx, tt.principal)
+			}
+
+			ref := &model.ExternalRefInput{Ref: "CRM-1"}
+			_, err := resolver.Mutation().CreateVerification(ctx, "7707083893", []model.VerificationDataType{model.VerificationDataTypeBasicInformation}, nil, ref, nil, nil, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if author != tt.expectedAuthor {
+				t.Errorf("expected author %q, got %q", tt.expectedAuthor, author)
+			}
+		})
+	}
+}
+
+func TestSetMaintenanceModeRequiresAdmin(t *testing.T) {
+	resolver := &Resolver{Maintenance: maintenance.NewMode(false, time.Second), Logger: zaptest.NewLogger(t)}
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "user@company.ru"})
+	if _, err := resolver.Mutation().SetMaintenanceMode(ctx, true, nil); !containsError(err, "access denied") {
+		t.Fatalf("expected access denied, got %v", err)
+	}
+	if resolver.Maintenance.Enabled() {
+		t.Fatal("maintenance mode must not be enabled by a non-admin")
+	}
+
+	ctx = auth.WithPrincipal(context.Background(), &auth.Principal{Email: "admin@company.ru", Roles: []string{auth.RoleAdmin}})
+	reason := "migration"
+	status, err := resolver.Mutation().SetMaintenanceMode(ctx, true, &reason)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !status.Enabled {
+		t.Error("expected maintenance mode to be enabled")
+	}
+}
+
+func TestMyNotifications(t *testing.T) {
+	tests := []struct {
+		name           string
+		principal      *auth.Principal
+		unreadOnly     *bool
+		expectedUnread bool
+		expectedError  string
+	}{
+		{
+			name:           "defaults_to_all",
+			principal:      &auth.Principal{Email: "user@company.ru"},
+			expectedUnread: false,
+		},
+		{
+			name:           "unread_only",
+			principal:      &auth.Principal{Email: "user@company.ru"},
+			unreadOnly:     func() *bool { b := true; return &b }(),
+			expectedUnread: true,
+		},
+		{
+			name:          "unauthenticated",
+			expectedError: "unauthenticated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var unread bool
+			resolver := &Resolver{
+				NotificationService: &mockNotificationService{
+					listFunc: func(ctx context.Context, userEmail string, unreadOnly bool) ([]*model.Notification, error) {
+						unread = unreadOnly
+						return nil, nil
+					},
+				},
+				Logger: zaptest.NewLogger(t),
+			}
+
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = auth.WithPrincipal(ctx, tt.principal)
+			}
+
+			_, err := resolver.Query().MyNotifications(ctx, tt.unreadOnly)
+			if tt.expectedError != "" {
+				if !containsError(err, tt.expectedError) {
+					t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if unread != tt.expectedUnread {
+				t.Errorf("expected unreadOnly %v, got %v", tt.expectedUnread, unread)
+			}
+		})
+	}
+}
+
+// TestExecutableSchema проверяет, что сгенерированная схема вызывает резолверы пакета graph
+func TestExecutableSchema(t *testing.T) {
+	resolver := &Resolver{
+		VerificationService: &mockVerificationService{
+			getFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+				if id != "v-1" {
+					return nil, &repository.Error{Kind: repository.ErrNotFound, Err: errors.New("verification not found: " + id)}
+				}
+				return &model.Verification{ID: id, Inn: "7707083893", Status: model.VerificationStatusInProcess}, nil
+			},
+		},
+		Logger: zaptest.NewLogger(t),
+	}
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: resolver}))
+	srv.AddTransport(transport.POST{})
+	srv.SetErrorPresenter(ErrorPresenter)
+	c := client.New(srv)
+
+	var resp struct {
+		Verification struct {
+			ID     string
+			Inn    string
+			Status string
+		}
+	}
+	c.MustPost(`query { verification(id: "v-1") { id inn status } }`, &resp)
+
+	if resp.Verification.Inn != "7707083893" || resp.Verification.Status != string(model.VerificationStatusInProcess) {
+		t.Errorf("unexpected verification: %+v", resp.Verification)
+	}
+
+	err := c.Post(`query { verification(id: "missing") { id } }`, &resp)
+	if !containsError(err, "verification not found") {
+		t.Errorf("expected resolver error, got %v", err)
+	}
+
+	raw, err := c.RawPost(`query { verification(id: "missing") { id } }`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(raw.Errors), `"code":"`+ErrorCodeNotFound+`"`) {
+		t.Errorf("expected %s error code, got %s", ErrorCodeNotFound, raw.Errors)
+	}
+}
+
+func TestVerificationWithDataSelectedTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		vars     map[string]any
+		expected []model.VerificationDataType
+	}{
+		{
+			name:     "single_field",
+			query:    `query { verificationWithData(id: "v-1") { verification { id } basicInformation } }`,
+			expected: []model.VerificationDataType{model.VerificationDataTypeBasicInformation},
+		},
+		{
+			name:     "fragment",
+			query:    `query { verificationWithData(id: "v-1") { ...courts } } fragment courts on VerificationDataResult { arbitrageStatistics affiliatedCompanies }`,
+			expected: []model.VerificationDataType{model.VerificationDataTypeArbitrageStatistics, model.VerificationDataTypeAffiliatedCompanies},
+		},
+		{
+			name:     "no_data_fields",
+			query:    `query { verificationWithData(id: "v-1") { verification { status } } }`,
+			expected: []model.VerificationDataType{},
+		},
+		{
+			name:  "nested_data",
+			query: `query { verificationWithData(id: "v-1") { verification { data { dataType } } basicInformation } }`,
+		},
+		{
+			name:  "nested_data_quality",
+			query: `query { verificationWithData(id: "v-1") { verification { dataQuality { dataType valid } } basicInformation } }`,
+		},
+		{
+			name:     "documents",
+			query:    `query { verificationWithData(id: "v-1") { basicInformation documents(dataTypes: [ACTIVITIES]) { data } } }`,
+			expected: []model.VerificationDataType{model.VerificationDataTypeBasicInformation, model.VerificationDataTypeActivities},
+		},
+		{
+			name:     "documents_variable",
+			query:    `query($types: [VerificationDataType!]) { verificationWithData(id: "v-1") { documents(dataTypes: $types) { data } } }`,
+			vars:     map[string]any{"types": []string{"AFFILIATED_COMPANIES"}},
+			expected: []model.VerificationDataType{model.VerificationDataTypeAffiliatedCompanies},
+		},
+		{
+			name:  "all_documents",
+			query: `query { verificationWithData(id: "v-1") { documents { dataType } } }`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifications := &mockVerificationService{}
+			srv := handler.New(NewExecutableSchema(Config{Resolvers: &Resolver{VerificationService: verifications, Logger: zaptest.NewLogger(t)}}))
+			srv.AddTransport(transport.POST{})
+			c := client.New(srv)
+
+			var options []client.Option
+			for name, value := range tt.vars {
+				options = append(options, client.Var(name, value))
+			}
+			var resp map[string]any
+			c.MustPost(tt.query, &resp, options...)
+
+			if tt.expected == nil {
+				if verifications.dataTypes != nil {
+					t.Errorf("expected all data types, but got %v", verifications.dataTypes)
+				}
+				return
+			}
+			if verifications.dataTypes == nil || !slices.Equal(verifications.dataTypes, tt.expected) {
+				t.Errorf("expected data types %v, but got %v", tt.expected, verifications.dataTypes)
+			}
+		})
+	}
+}
+
+func TestVerificationWithDataUnavailableType(t *testing.T) {
+	verifications := &mockVerificationService{unavailable: []model.VerificationDataType{model.VerificationDataTypeArbitrageStatistics}}
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: &Resolver{VerificationService: verifications, Logger: zaptest.NewLogger(t)}}))
+	srv.AddTransport(transport.POST{})
+	srv.SetErrorPresenter(ErrorPresenter)
+	c := client.New(srv)
+
+	raw, err := c.RawPost(`query { verificationWithData(id: "v-1") { courts: arbitrageStatistics activities } }`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := raw.Data.(map[string]any)["verificationWithData"].(map[string]any)
+	if data["courts"] != nil || data["activities"] != `{"activities": []}` {
+		t.Errorf("expected other data types to be returned, but got %v", data)
+	}
+
+	var errs []map[string]any
+	if err := json.Unmarshal(raw.Errors, &errs); err != nil {
+		t.Fatalf("failed to decode errors: %v", err)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("expected one error, but got %v", errs)
+	}
+	path, _ := errs[0]["path"].([]any)
+	extensions, _ := errs[0]["extensions"].(map[string]any)
+	if len(path) != 2 || path[0] != "verificationWithData" || path[1] != "courts" {
+		t.Errorf("expected error on the aliased field, but got path %v", path)
+	}
+	if extensions["code"] != ErrorCodeDataUnavailable || extensions["dataType"] != "ARBITRAGE_STATISTICS" {
+		t.Errorf("unexpected extensions %v", extensions)
+	}
+}
+
+func TestVerificationWithDataDocuments(t *testing.T) {
+	verifications := &mockVerificationService{}
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: &Resolver{VerificationService: verifications, Logger: zaptest.NewLogger(t)}}))
+	srv.AddTransport(transport.POST{})
+	c := client.New(srv)
+
+	raw, err := c.RawPost(`query {
+		verificationWithData(id: "v-1") {
+			all: documents { dataType data }
+			courts: documents(dataTypes: [ARBITRAGE_STATISTICS]) { dataType }
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Недоставленные типы не попадают в ответ даже как null
+	expected := `{"verificationWithData":{"all":[{"data":"{\"activities\": []}","dataType":"ACTIVITIES"}],"courts":[]}}`
+	data, err := json.Marshal(raw.Data)
+	if err != nil {
+		t.Fatalf("failed to encode data: %v", err)
+	}
+	if string(data) != expected {
+		t.Errorf("expected %s, but got %s", expected, data)
+	}
+}
@@ -6,14 +6,173 @@ package graph
 
 import (
 	"context"
-	"fmt"
 	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/service"
+	"slices"
+	"time"
 )
 
 // CreateVerification is the resolver for the createVerification field.
-func (r *mutationResolver) CreateVerification(ctx context.Context, inn string, requestedDataTypes []model.VerificationDataType) (*model.Verification, error) {
-	// Здесь можно получить email пользователя из контекста, пока используем заглушку
-	return r.Resolver.VerificationService.CreateVerification(ctx, inn, requestedDataTypes, "test@example.com")
+func (r *mutationResolver) CreateVerification(ctx context.Context, inn string, requestedDataTypes []model.VerificationDataType, customDataTypes []string, externalRef *model.ExternalRefInput, metadata []*model.MetadataEntryInput, waitForCompletion *bool, timeout *int32) (*model.Verification, error) {
+	authorEmail := requestAuthor(ctx)
+	wait := waitForCompletion != nil && *waitForCompletion
+	var waitTimeout time.Duration
+	if wait {
+		var err error
+		if waitTimeout, err = r.Resolver.CompletionWaiter.Timeout(timeout); err != nil {
+			return nil, err
+		}
+	}
+
+	verification, err := r.Resolver.VerificationService.CreateVerificationWithOptions(ctx, inn, requestedDataTypes, authorEmail, service.CreateOptions{ExternalRef: externalRef, Metadata: metadata, CustomDataTypes: customDataTypes})
+	if err != nil || !wait {
+		return verification, err
+	}
+	return r.Resolver.CompletionWaiter.Wait(ctx, verification, waitTimeout)
+}
+
+// CloneVerification is the resolver for the cloneVerification field.
+func (r *mutationResolver) CloneVerification(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.Verification, error) {
+	return r.Resolver.CloneService.CloneVerification(ctx, id, dataTypes, requestAuthor(ctx))
+}
+
+// MarkNotificationRead is the resolver for the markNotificationRead field.
+func (r *mutationResolver) MarkNotificationRead(ctx context.Context, id string) (*model.Notification, error) {
+	email, err := auth.RequireEmail(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Resolver.NotificationService.MarkRead(ctx, email, id)
+}
+
+// AssignVerification is the resolver for the assignVerification field.
+func (r *mutationResolver) AssignVerification(ctx context.Context, id string, assignee string) (*model.Verification, error) {
+	return r.Resolver.ReviewService.Assign(ctx, id, assignee)
+}
+
+// ClaimVerification is the resolver for the claimVerification field.
+func (r *mutationResolver) ClaimVerification(ctx context.Context, id string) (*model.Verification, error) {
+	return r.Resolver.ReviewService.Claim(ctx, id)
+}
+
+// ReviewVerification is the resolver for the reviewVerification field.
+func (r *mutationResolver) ReviewVerification(ctx context.Context, id string, decision model.ReviewState, comment *string) (*model.Verification, error) {
+	return r.Resolver.ReviewService.Review(ctx, id, decision, comment)
+}
+
+// IntrospectToken is the resolver for the introspectToken field.
+func (r *mutationResolver) IntrospectToken(ctx context.Context, token string) (*model.TokenIntrospection, error) {
+	return r.Resolver.IdentityService.IntrospectToken(ctx, token)
+}
+
+// SetMaintenanceMode is the resolver for the setMaintenanceMode field.
+func (r *mutationResolver) SetMaintenanceMode(ctx context.Context, enabled bool, reason *string) (*model.MaintenanceStatus, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return r.Resolver.Maintenance.Disable(), nil
+	}
+	principal, _ := auth.PrincipalFromContext(ctx)
+	return r.Resolver.Maintenance.Enable(ctx, derefString(reason), principal.Email), nil
+}
+
+// UpdateVerificationMetadata is the resolver for the updateVerificationMetadata field.
+func (r *mutationResolver) UpdateVerificationMetadata(ctx context.Context, id string, set []*model.MetadataEntryInput, remove []string) (*model.Verification, error) {
+	return r.Resolver.VerificationService.UpdateMetadata(ctx, id, set, remove)
+}
+
+// SetLegalHold is the resolver for the setLegalHold field.
+func (r *mutationResolver) SetLegalHold(ctx context.Context, id string, hold bool) (*model.Verification, error) {
+	return r.Resolver.PrivacyService.SetLegalHold(ctx, id, hold)
+}
+
+// RecalculateScores is the resolver for the recalculateScores field.
+func (r *mutationResolver) RecalculateScores(ctx context.Context, filter *model.VerificationFilter) (*model.ScoreRecalculation, error) {
+	return r.Resolver.ScoringService.RecalculateScores(ctx, filter)
+}
+
+// RegisterPersistedOperation is the resolver for the registerPersistedOperation field.
+func (r *mutationResolver) RegisterPersistedOperation(ctx context.Context, apiKey string, document string, name *string) (*model.PersistedOperation, error) {
+	return r.Resolver.PersistedOperationService.RegisterOperation(ctx, apiKey, document, name)
+}
+
+// DeletePersistedOperation is the resolver for the deletePersistedOperation field.
+func (r *mutationResolver) DeletePersistedOperation(ctx context.Context, apiKey string, hash string) (bool, error) {
+	if err := r.Resolver.PersistedOperationService.DeleteOperation(ctx, apiKey, hash); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RegisterWebhook is the resolver for the registerWebhook field.
+func (r *mutationResolver) RegisterWebhook(ctx context.Context, input model.WebhookInput) (*model.Webhook, error) {
+	return r.Resolver.WebhookService.RegisterWebhook(ctx, input)
+}
+
+// DeleteWebhook is the resolver for the deleteWebhook field.
+func (r *mutationResolver) DeleteWebhook(ctx context.Context, id string) (bool, error) {
+	if err := r.Resolver.WebhookService.DeleteWebhook(ctx, id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// InviteUser is the resolver for the inviteUser field.
+func (r *mutationResolver) InviteUser(ctx context.Context, email string, roles []model.OrganizationRole, name *string) (*model.OrganizationMember, error) {
+	return r.Resolver.UserService.InviteUser(ctx, email, roles, name)
+}
+
+// SetUserRoles is the resolver for the setUserRoles field.
+func (r *mutationResolver) SetUserRoles(ctx context.Context, email string, roles []model.OrganizationRole) (*model.OrganizationMember, error) {
+	return r.Resolver.UserService.SetUserRoles(ctx, email, roles)
+}
+
+// DeactivateUser is the resolver for the deactivateUser field.
+func (r *mutationResolver) DeactivateUser(ctx context.Context, email string) (*model.OrganizationMember, error) {
+	return r.Resolver.UserService.DeactivateUser(ctx, email)
+}
+
+// SetCacheSharing is the resolver for the setCacheSharing field.
+func (r *mutationResolver) SetCacheSharing(ctx context.Context, dataType model.VerificationDataType, shared bool) (*model.CacheSharingPolicy, error) {
+	return r.Resolver.CompanyDataService.SetCacheSharing(ctx, dataType, shared)
+}
+
+// CreateCase is the resolver for the createCase field.
+func (r *mutationResolver) CreateCase(ctx context.Context, name string, parties []*model.CasePartyInput) (*model.Case, error) {
+	return r.Resolver.CaseService.CreateCase(ctx, name, parties)
+}
+
+// AddVerificationToCase is the resolver for the addVerificationToCase field.
+func (r *mutationResolver) AddVerificationToCase(ctx context.Context, caseID string, verificationID string, role model.CasePartyRole) (*model.Case, error) {
+	return r.Resolver.CaseService.AddVerification(ctx, caseID, verificationID, role)
+}
+
+// RemoveVerificationFromCase is the resolver for the removeVerificationFromCase field.
+func (r *mutationResolver) RemoveVerificationFromCase(ctx context.Context, caseID string, verificationID string) (*model.Case, error) {
+	return r.Resolver.CaseService.RemoveVerification(ctx, caseID, verificationID)
+}
+
+// RequestCaseReport is the resolver for the requestCaseReport field.
+func (r *mutationResolver) RequestCaseReport(ctx context.Context, caseID string, format model.ReportFormat) (*model.ReportJob, error) {
+	return r.Resolver.ReportService.RequestCaseReport(ctx, caseID, format)
+}
+
+// PatchVerificationData is the resolver for the patchVerificationData field.
+func (r *mutationResolver) PatchVerificationData(ctx context.Context, verificationID string, dataType model.VerificationDataType, path []string, value string, reason string) (*model.DataRedaction, error) {
+	return r.Resolver.DataRedactionService.PatchData(ctx, verificationID, dataType, path, value, reason)
+}
+
+// SimulateCompletion is the resolver for the simulateCompletion field.
+func (r *mutationResolver) SimulateCompletion(ctx context.Context, verificationID string, status model.VerificationStatus, dataTypePayloads []*model.DataTypePayloadInput, reason string) (*model.Verification, error) {
+	return r.Resolver.CompletionSimulationService.SimulateCompletion(ctx, verificationID, status, dataTypePayloads, reason)
+}
+
+// RedactVerificationData is the resolver for the redactVerificationData field.
+func (r *mutationResolver) RedactVerificationData(ctx context.Context, verificationID string, dataType model.VerificationDataType, path []string, reason string) (*model.DataRedaction, error) {
+	return r.Resolver.DataRedactionService.RedactData(ctx, verificationID, dataType, path, reason)
 }
 
 // Verification is the resolver for the verification field.
@@ -22,18 +181,277 @@ func (r *queryResolver) Verification(ctx context.Context, id string) (*model.Ver
 }
 
 // Verifications is the resolver for the verifications field.
-func (r *queryResolver) Verifications(ctx context.Context, limit *int32, offset *int32) ([]*model.Verification, error) {
-	return r.Resolver.VerificationService.GetAllVerifications(ctx, limit, offset)
+func (r *queryResolver) Verifications(ctx context.Context, filter *model.VerificationFilter, scope *model.VerificationScope, limit *int32, offset *int32) ([]*model.Verification, error) {
+	return r.Resolver.VerificationService.GetAllVerifications(ctx, filter, scope, limit, offset)
 }
 
 // VerificationWithData is the resolver for the verificationWithData field.
 func (r *queryResolver) VerificationWithData(ctx context.Context, id string) (*model.VerificationDataResult, error) {
-	return r.Resolver.VerificationService.GetVerificationWithData(ctx, id)
+	// Данные, которые не удалось прочитать, возвращаются пустыми с ошибкой на своем поле
+	ctx = repository.WithUnavailableData(ctx)
+	result, err := r.Resolver.VerificationService.GetVerificationWithData(ctx, id, selectedDataTypes(ctx))
+	if err != nil {
+		return nil, err
+	}
+	addUnavailableDataErrors(ctx, repository.UnavailableDataFromContext(ctx))
+	return result, nil
+}
+
+// VerificationByExternalRef is the resolver for the verificationByExternalRef field.
+func (r *queryResolver) VerificationByExternalRef(ctx context.Context, system *string, ref string) (*model.Verification, error) {
+	return r.Resolver.VerificationService.GetVerificationByExternalRef(ctx, system, ref)
+}
+
+// VerificationAuditTrail is the resolver for the verificationAuditTrail field.
+func (r *queryResolver) VerificationAuditTrail(ctx context.Context, id string) (*model.VerificationAuditTrail, error) {
+	return r.Resolver.AuditService.GetAuditTrail(ctx, id)
+}
+
+// CompanySnapshot is the resolver for the companySnapshot field.
+func (r *queryResolver) CompanySnapshot(ctx context.Context, inn string, asOf string) (*model.CompanySnapshot, error) {
+	return r.Resolver.VerificationService.GetCompanySnapshot(ctx, inn, asOf)
+}
+
+// VerificationStatuses is the resolver for the verificationStatuses field.
+func (r *queryResolver) VerificationStatuses(ctx context.Context, inns []string) ([]*model.CompanyVerificationStatus, error) {
+	return r.Resolver.VerificationService.GetVerificationStatuses(ctx, inns)
+}
+
+// HasRecentVerification is the resolver for the hasRecentVerification field.
+func (r *queryResolver) HasRecentVerification(ctx context.Context, inn string, maxAgeHours int32) (*model.RecentVerification, error) {
+	return r.Resolver.VerificationService.HasRecentVerification(ctx, inn, maxAgeHours)
+}
+
+// MyNotifications is the resolver for the myNotifications field.
+func (r *queryResolver) MyNotifications(ctx context.Context, unreadOnly *bool) ([]*model.Notification, error) {
+	email, err := auth.RequireEmail(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Resolver.NotificationService.ListForUser(ctx, email, unreadOnly != nil && *unreadOnly)
+}
+
+// DataTypes is the resolver for the dataTypes field.
+func (r *queryResolver) DataTypes(ctx context.Context) ([]*model.DataTypeInfo, error) {
+	return r.Resolver.CatalogService.ListDataTypes(ctx), nil
+}
+
+// CustomDataTypes is the resolver for the customDataTypes field.
+func (r *queryResolver) CustomDataTypes(ctx context.Context) ([]*model.CustomDataTypeInfo, error) {
+	return r.Resolver.CatalogService.ListCustomDataTypes(ctx), nil
+}
+
+// EnumCatalog is the resolver for the enumCatalog field.
+func (r *queryResolver) EnumCatalog(ctx context.Context) ([]*model.EnumInfo, error) {
+	return r.Resolver.CatalogService.ListEnums(ctx), nil
+}
+
+// VerificationStatistics is the resolver for the verificationStatistics field.
+func (r *queryResolver) VerificationStatistics(ctx context.Context, from string, to string, organization *string, timezone *string, includeSubsidiaries *bool) ([]*model.DailyVerificationStats, error) {
+	return r.Resolver.StatisticsService.GetDailyStatistics(ctx, from, to, organization, timezone, includeSubsidiaries != nil && *includeSubsidiaries)
+}
+
+// LatencyReport is the resolver for the latencyReport field.
+func (r *queryResolver) LatencyReport(ctx context.Context, dataType *model.VerificationDataType, from string, to string) ([]*model.LatencyReport, error) {
+	return r.Resolver.LatencyService.GetLatencyReport(ctx, dataType, from, to)
+}
+
+// MaintenanceStatus is the resolver for the maintenanceStatus field.
+func (r *queryResolver) MaintenanceStatus(ctx context.Context) (*model.MaintenanceStatus, error) {
+	return r.Resolver.Maintenance.Status(), nil
+}
+
+// Case is the resolver for the case field.
+func (r *queryResolver) Case(ctx context.Context, id string) (*model.Case, error) {
+	return r.Resolver.CaseService.GetCase(ctx, id)
+}
+
+// Cases is the resolver for the cases field.
+func (r *queryResolver) Cases(ctx context.Context, limit *int32, offset *int32) ([]*model.Case, error) {
+	return r.Resolver.CaseService.ListCases(ctx, limit, offset)
+}
+
+// ReportJob is the resolver for the reportJob field.
+func (r *queryResolver) ReportJob(ctx context.Context, id string) (*model.ReportJob, error) {
+	return r.Resolver.ReportService.GetReportJob(ctx, id)
+}
+
+// ReportJobs is the resolver for the reportJobs field.
+func (r *queryResolver) ReportJobs(ctx context.Context, limit *int32) ([]*model.ReportJob, error) {
+	return r.Resolver.ReportService.ListReportJobs(ctx, limit)
+}
+
+// MyReviewQueue is the resolver for the myReviewQueue field.
+func (r *queryResolver) MyReviewQueue(ctx context.Context, reviewState *model.ReviewState, limit *int32, offset *int32) ([]*model.Verification, error) {
+	return r.Resolver.ReviewService.GetReviewQueue(ctx, reviewState, limit, offset)
+}
+
+// ServerInfo is the resolver for the serverInfo field.
+func (r *queryResolver) ServerInfo(ctx context.Context) (*model.ServerInfo, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+	return r.Resolver.Build, nil
+}
+
+// ScoreHistory is the resolver for the scoreHistory field.
+func (r *queryResolver) ScoreHistory(ctx context.Context, verificationID string) ([]*model.VerificationScore, error) {
+	return r.Resolver.ScoringService.GetScoreHistory(ctx, verificationID)
+}
+
+// ScoreRecalculation is the resolver for the scoreRecalculation field.
+func (r *queryResolver) ScoreRecalculation(ctx context.Context, id string) (*model.ScoreRecalculation, error) {
+	return r.Resolver.ScoringService.GetRecalculation(ctx, id)
+}
+
+// VerificationAmendments is the resolver for the verificationAmendments field.
+func (r *queryResolver) VerificationAmendments(ctx context.Context, verificationID string) ([]*model.VerificationAmendment, error) {
+	return r.Resolver.AmendmentService.ListAmendments(ctx, verificationID)
+}
+
+// VerificationSignature is the resolver for the verificationSignature field.
+func (r *queryResolver) VerificationSignature(ctx context.Context, verificationID string) (*model.VerificationSignature, error) {
+	return r.Resolver.SignatureService.GetSignature(ctx, verificationID)
+}
+
+// DataRedactions is the resolver for the dataRedactions field.
+func (r *queryResolver) DataRedactions(ctx context.Context, verificationID string) ([]*model.DataRedaction, error) {
+	return r.Resolver.DataRedactionService.ListRedactions(ctx, verificationID)
+}
+
+// PersistedOperations is the resolver for the persistedOperations field.
+func (r *queryResolver) PersistedOperations(ctx context.Context, apiKey string) ([]*model.PersistedOperation, error) {
+	return r.Resolver.PersistedOperationService.ListOperations(ctx, apiKey)
+}
+
+// Webhooks is the resolver for the webhooks field.
+func (r *queryResolver) Webhooks(ctx context.Context) ([]*model.Webhook, error) {
+	return r.Resolver.WebhookService.ListWebhooks(ctx)
+}
+
+// NotificationJobs is the resolver for the notificationJobs field.
+func (r *queryResolver) NotificationJobs(ctx context.Context, verificationID string) ([]*model.NotificationJob, error) {
+	return r.Resolver.NotificationJobService.ListJobs(ctx, verificationID)
+}
+
+// PreviewWebhook is the resolver for the previewWebhook field.
+func (r *queryResolver) PreviewWebhook(ctx context.Context, input model.WebhookInput, verificationID string) (*model.WebhookPreview, error) {
+	return r.Resolver.WebhookService.PreviewWebhook(ctx, input, verificationID)
+}
+
+// OrganizationMembers is the resolver for the organizationMembers field.
+func (r *queryResolver) OrganizationMembers(ctx context.Context, organization *string) ([]*model.OrganizationMember, error) {
+	return r.Resolver.UserService.ListMembers(ctx, organization)
+}
+
+// LatestCompanyData is the resolver for the latestCompanyData field.
+func (r *queryResolver) LatestCompanyData(ctx context.Context, inn string, dataType model.VerificationDataType, schemaVersion *int32) (*model.VerificationData, error) {
+	return r.Resolver.CompanyDataService.GetLatestCompanyData(ctx, inn, dataType, schemaVersion)
+}
+
+// CacheSharing is the resolver for the cacheSharing field.
+func (r *queryResolver) CacheSharing(ctx context.Context) ([]*model.CacheSharingPolicy, error) {
+	return r.Resolver.CompanyDataService.CacheSharing(ctx)
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*model.Principal, error) {
+	return r.Resolver.IdentityService.Me(ctx)
+}
+
+// MyUsage is the resolver for the myUsage field.
+func (r *queryResolver) MyUsage(ctx context.Context, hours *int32) (*model.ClientUsage, error) {
+	return r.Resolver.UsageService.MyUsage(ctx, hours)
+}
+
+// ClientUsage is the resolver for the clientUsage field.
+func (r *queryResolver) ClientUsage(ctx context.Context, hours *int32, limit *int32) ([]*model.ClientUsage, error) {
+	return r.Resolver.UsageService.ClientUsage(ctx, hours, limit)
+}
+
+// SpendReport is the resolver for the spendReport field.
+func (r *queryResolver) SpendReport(ctx context.Context, from string, to string, organization *string, includeSubsidiaries *bool) (*model.SpendReport, error) {
+	return r.Resolver.StatisticsService.GetSpendReport(ctx, from, to, organization, includeSubsidiaries != nil && *includeSubsidiaries)
+}
+
+// AccessReview is the resolver for the accessReview field.
+func (r *queryResolver) AccessReview(ctx context.Context) (*model.AccessReview, error) {
+	return r.Resolver.AccessReviewService.GetAccessReview(ctx)
+}
+
+// WhoCanAccess is the resolver for the whoCanAccess field.
+func (r *queryResolver) WhoCanAccess(ctx context.Context, verificationID string) ([]*model.AccessGrant, error) {
+	return r.Resolver.AccessReviewService.WhoCanAccess(ctx, verificationID)
+}
+
+// RejectedPayloads is the resolver for the rejectedPayloads field.
+func (r *queryResolver) RejectedPayloads(ctx context.Context, verificationID *string, limit *int32) ([]*model.RejectedPayload, error) {
+	return r.Resolver.PayloadService.ListRejectedPayloads(ctx, verificationID, limit)
 }
 
 // VerificationCompleted is the resolver for the verificationCompleted field.
 func (r *subscriptionResolver) VerificationCompleted(ctx context.Context, id string) (<-chan *model.Verification, error) {
-	return nil, fmt.Errorf("not implemented")
+	verification, err := r.Resolver.VerificationService.GetVerification(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.Resolver.CompletionWaiter.Subscribe(ctx, verification), nil
+}
+
+// UnreadCount is the resolver for the unreadCount field.
+func (r *subscriptionResolver) UnreadCount(ctx context.Context) (<-chan int32, error) {
+	email, err := auth.RequireEmail(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Resolver.NotificationService.SubscribeUnreadCount(ctx, email)
+}
+
+// VerificationExport is the resolver for the verificationExport field.
+func (r *subscriptionResolver) VerificationExport(ctx context.Context, filter *model.VerificationFilter, limit *int32, offset *int32) (<-chan *model.VerificationEdge, error) {
+	return r.Resolver.ExportService.StreamVerifications(ctx, filter, limit, offset)
+}
+
+// DataByType is the resolver for the dataByType field.
+func (r *verificationResolver) DataByType(ctx context.Context, obj *model.Verification, typeArg string) (*model.GenericVerificationData, error) {
+	// Тип, не разрешенный ключом, не возвращается, как и в documents
+	if !auth.DataTypeAllowed(ctx, typeArg) {
+		return nil, nil
+	}
+	for _, data := range obj.CustomData {
+		if data.DataType == typeArg {
+			return data, nil
+		}
+	}
+	for _, data := range obj.Data {
+		if string(data.DataType) != typeArg {
+			continue
+		}
+		generic := &model.GenericVerificationData{DataType: typeArg, Data: data.Data, SchemaVersion: data.SchemaVersion, CreatedAt: data.CreatedAt}
+		for _, quality := range obj.DataQuality {
+			if quality.DataType == data.DataType {
+				generic.ValidationErrors = quality.Errors
+			}
+		}
+		return generic, nil
+	}
+	return nil, nil
+}
+
+// Documents is the resolver for the documents field.
+func (r *verificationDataResultResolver) Documents(ctx context.Context, obj *model.VerificationDataResult, dataTypes []model.VerificationDataType) ([]*model.VerificationData, error) {
+	// Типы, не разрешенные ключом, не возвращаются, как и в полях отдельных типов
+	documents := make([]*model.VerificationData, 0, len(obj.Verification.Data))
+	for _, data := range obj.Verification.Data {
+		if !auth.DataTypeAllowed(ctx, string(data.DataType)) {
+			continue
+		}
+		if dataTypes != nil && !slices.Contains(dataTypes, data.DataType) {
+			continue
+		}
+		documents = append(documents, data)
+	}
+	return documents, nil
 }
 
 // Mutation returns MutationResolver implementation.
@@ -45,6 +463,16 @@ func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 // Subscription returns SubscriptionResolver implementation.
 func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
 
+// Verification returns VerificationResolver implementation.
+func (r *Resolver) Verification() VerificationResolver { return &verificationResolver{r} }
+
+// VerificationDataResult returns VerificationDataResultResolver implementation.
+func (r *Resolver) VerificationDataResult() VerificationDataResultResolver {
+	return &verificationDataResultResolver{r}
+}
+
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
 type subscriptionResolver struct{ *Resolver }
+type verificationResolver struct{ *Resolver }
+type verificationDataResultResolver struct{ *Resolver }
@@ -0,0 +1,118 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// dataResultFields поля VerificationDataResult с данными соответствующих типов
+var dataResultFields = map[string]model.VerificationDataType{
+	"basicInformation":                model.VerificationDataTypeBasicInformation,
+	"activities":                      model.VerificationDataTypeActivities,
+	"addressesByCredinform":           model.VerificationDataTypeAddressesByCredinform,
+	"addressesByUnifiedStateRegister": model.VerificationDataTypeAddressesByUnifiedStateRegister,
+	"affiliatedCompanies":             model.VerificationDataTypeAffiliatedCompanies,
+	"arbitrageStatistics":             model.VerificationDataTypeArbitrageStatistics,
+}
+
+// selectedDataTypes возвращает типы данных, поля которых выбраны в текущем поле VerificationDataResult,
+// с учетом фрагментов и аргумента documents. nil - нужны данные всех типов: выбрано verification.data,
+// verification.dataQuality или documents без dataTypes.
+func selectedDataTypes(ctx context.Context) []model.VerificationDataType {
+	opCtx := graphql.GetOperationContext(ctx)
+
+	dataTypes := []model.VerificationDataType{}
+	for _, field := range graphql.CollectFieldsCtx(ctx, nil) {
+		switch field.Name {
+		case "verification":
+			for _, nested := range graphql.CollectFields(opCtx, field.Selections, nil) {
+				if nested.Name == "data" || nested.Name == "dataQuality" {
+					return nil
+				}
+			}
+		case "documents":
+			documentTypes := documentsDataTypes(opCtx, field)
+			if documentTypes == nil {
+				return nil
+			}
+			dataTypes = append(dataTypes, documentTypes...)
+		default:
+			if dataType, ok := dataResultFields[field.Name]; ok {
+				dataTypes = append(dataTypes, dataType)
+			}
+		}
+	}
+	return dataTypes
+}
+
+// documentsDataTypes возвращает типы из аргумента dataTypes поля documents или nil, если аргумент не задан
+func documentsDataTypes(opCtx *graphql.OperationContext, field graphql.CollectedField) []model.VerificationDataType {
+	values, ok := field.ArgumentMap(opCtx.Variables)["dataTypes"].([]any)
+	if !ok {
+		return nil
+	}
+	dataTypes := make([]model.VerificationDataType, 0, len(values))
+	for _, value := range values {
+		if dataType, ok := value.(string); ok {
+			dataTypes = append(dataTypes, model.VerificationDataType(dataType))
+		}
+	}
+	return dataTypes
+}
+
+// addUnavailableDataErrors добавляет ошибку к каждому выбранному полю VerificationDataResult, данные
+// которого не удалось прочитать: поле возвращается пустым, а documents - без этого типа, остальные
+// поля - как обычно. Если данные
+// выбраны через verification.data, ошибка относится к самому полю с результатом.
+func addUnavailableDataErrors(ctx context.Context, unavailable []repository.UnavailableData) {
+	if len(unavailable) == 0 {
+		return
+	}
+
+	opCtx := graphql.GetOperationContext(ctx)
+	path := graphql.GetFieldContext(ctx).Path()
+	fields := graphql.CollectFieldsCtx(ctx, nil)
+	for _, data := range unavailable {
+		// Типы, не разрешенные ключом, не возвращаются, и ошибка о них тоже не нужна
+		if !auth.DataTypeAllowed(ctx, string(data.DataType)) {
+			continue
+		}
+
+		reported := false
+		for _, field := range fields {
+			dataType, ok := dataResultFields[field.Name]
+			selected := ok && dataType == data.DataType
+			if field.Name == "documents" {
+				documentTypes := documentsDataTypes(opCtx, field)
+				selected = documentTypes == nil || slices.Contains(documentTypes, data.DataType)
+			}
+			if selected {
+				graphql.AddError(ctx, unavailableDataError(append(slices.Clone(path), ast.PathName(field.Alias)), data.DataType))
+				reported = true
+			}
+		}
+		if !reported {
+			graphql.AddError(ctx, unavailableDataError(path, data.DataType))
+		}
+	}
+}
+
+func unavailableDataError(path ast.Path, dataType model.VerificationDataType) *gqlerror.Error {
+	return &gqlerror.Error{
+		Message: fmt.Sprintf("data of type %s is unavailable", dataType),
+		Path:    path,
+		Extensions: map[string]any{
+			"code":     ErrorCodeDataUnavailable,
+			"dataType": dataType,
+		},
+	}
+}
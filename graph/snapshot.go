@@ -0,0 +1,12 @@
+package graph
+
+import _ "embed"
+
+// SchemaVersion версия опубликованного API. Повышается вместе с ломающими изменениями схемы.
+const SchemaVersion = 1
+
+// PublishedSchemaSnapshot снимок схемы, опубликованной для клиентов.
+// Обновляется командой go run ./cmd/schemacheck -update.
+//
+//go:embed schema.snapshot.graphql
+var PublishedSchemaSnapshot string
@@ -0,0 +1,89 @@
+package abuse
+
+import (
+	"context"
+	"errors"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/inputlimits"
+	"scoring_api_gateway/internal/persisted"
+	"scoring_api_gateway/internal/querylimits"
+	"scoring_api_gateway/internal/service"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+type callerKey struct{}
+
+// WithCaller возвращает контекст с идентификатором клиента, которому засчитываются ошибки запроса
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext возвращает идентификатор клиента запроса
+func CallerFromContext(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+// Extension расширение gqlgen, засчитывающее клиенту ответы с ошибками проверки
+// аргументов и доступа. Одна операция засчитывается как одна ошибка.
+type Extension struct {
+	limiter *Limiter
+}
+
+var (
+	_ graphql.HandlerExtension    = (*Extension)(nil)
+	_ graphql.ResponseInterceptor = (*Extension)(nil)
+)
+
+func NewExtension(limiter *Limiter) *Extension {
+	return &Extension{limiter: limiter}
+}
+
+func (e *Extension) ExtensionName() string {
+	return "AbuseProtection"
+}
+
+func (e *Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (e *Extension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+	if resp == nil || len(resp.Errors) == 0 {
+		return resp
+	}
+
+	for _, err := range resp.Errors {
+		if kind := classify(err); kind != "" {
+			e.limiter.RecordFailure(ctx, CallerFromContext(ctx), kind)
+			break
+		}
+	}
+	return resp
+}
+
+// classify определяет вид ошибки клиента. Ошибки шлюза и хранилища не учитываются.
+func classify(err *gqlerror.Error) string {
+	if code, ok := err.Extensions["code"].(string); ok {
+		switch code {
+		case errcode.ParseFailed, errcode.ValidationFailed,
+			querylimits.CodeTooManyAliases, querylimits.CodeTooManyRootFields,
+			querylimits.CodeTooManyOperations, querylimits.CodeVariablesTooLarge, inputlimits.CodeInputTooLarge:
+			return KindValidation
+		case persisted.CodeHashMismatch, persisted.CodeNotAllowed:
+			return KindForbidden
+		}
+	}
+
+	if errors.Is(err, auth.ErrUnauthenticated) || errors.Is(err, auth.ErrForbidden) {
+		return KindForbidden
+	}
+	if errors.Is(err, service.ErrInvalidInput) {
+		return KindValidation
+	}
+	return ""
+}
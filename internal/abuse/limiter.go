@@ -0,0 +1,207 @@
+// Package abuse временно блокирует клиентов, которые раз за разом присылают некорректные запросы.
+package abuse
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// Виды ошибок клиента, которые учитываются при блокировке
+const (
+	// KindValidation некорректные аргументы: неверный ИНН, неизвестный тип данных, ошибка разбора запроса
+	KindValidation = "validation"
+	// KindForbidden запрос операции или типов данных, не разрешенных клиенту
+	KindForbidden = "forbidden"
+	// KindAuth неизвестный или отозванный ключ API
+	KindAuth = "auth"
+)
+
+type failure struct {
+	at   time.Time
+	kind string
+}
+
+type callerState struct {
+	failures     []failure
+	strikes      int
+	blockedUntil time.Time
+}
+
+// Limiter считает ошибки клиентов в скользящем окне. Клиент, допустивший Threshold ошибок
+// за Window, блокируется на BaseBlock; каждая следующая блокировка вдвое длиннее предыдущей
+// (не более MaxBlock). Счетчик блокировок сбрасывается, если клиент не блокировался ResetAfter.
+// Состояние хранится в памяти экземпляра шлюза.
+type Limiter struct {
+	cfg    config.AbuseConfig
+	repo   repository.LockoutRepository
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	callers map[string]*callerState
+}
+
+func NewLimiter(cfg config.AbuseConfig, repo repository.LockoutRepository, logger *zap.Logger) *Limiter {
+	return &Limiter{
+		cfg:     cfg,
+		repo:    repo,
+		logger:  logger,
+		now:     time.Now,
+		callers: make(map[string]*callerState),
+	}
+}
+
+// RetryAfter возвращает оставшееся время блокировки клиента
+func (l *Limiter) RetryAfter(caller string) (time.Duration, bool) {
+	if !l.cfg.Enabled {
+		return 0, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state, ok := l.callers[caller]
+	if !ok {
+		return 0, false
+	}
+	remaining := state.blockedUntil.Sub(l.now())
+	return remaining, remaining > 0
+}
+
+// RecordFailure учитывает ошибку клиента и блокирует его при превышении порога.
+// Блокировка записывается в журнал caller_lockouts.
+func (l *Limiter) RecordFailure(ctx context.Context, caller, kind string) {
+	if !l.cfg.Enabled || caller == "" {
+		return
+	}
+
+	lockout := l.recordFailure(caller, kind)
+	if lockout == nil {
+		return
+	}
+
+	metrics.CallerLockouts.WithLabelValues(lockout.Reason).Inc()
+	l.logger.Warn("caller blocked after repeated invalid requests",
+		zap.String("caller", caller),
+		zap.String("reason", lockout.Reason),
+		zap.Int("strike", lockout.Strike),
+		zap.Time("blocked_until", lockout.BlockedUntil))
+
+	// Запись в журнал не должна зависеть от того, что клиент уже закрыл соединение
+	if err := l.repo.AddLockout(context.WithoutCancel(ctx), lockout); err != nil {
+		l.logger.Error("failed to record caller lockout", zap.Error(err), zap.String("caller", caller))
+	}
+}
+
+func (l *Limiter) recordFailure(caller, kind string) *repository.CallerLockout {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	state, ok := l.callers[caller]
+	if !ok {
+		state = &callerState{}
+		l.callers[caller] = state
+	}
+	if now.Before(state.blockedUntil) {
+		return nil
+	}
+
+	state.failures = append(pruneFailures(state.failures, now.Add(-l.cfg.Window)), failure{at: now, kind: kind})
+	if len(state.failures) < l.cfg.Threshold {
+		return nil
+	}
+
+	if !state.blockedUntil.IsZero() && now.Sub(state.blockedUntil) > l.cfg.ResetAfter {
+		state.strikes = 0
+	}
+	state.strikes++
+	state.blockedUntil = now.Add(blockDuration(l.cfg.BaseBlock, l.cfg.MaxBlock, state.strikes))
+
+	counts := make(map[string]int)
+	for _, f := range state.failures {
+		counts[f.kind]++
+	}
+	state.failures = nil
+
+	return &repository.CallerLockout{
+		Caller:       caller,
+		Reason:       mostFrequent(counts),
+		Failures:     counts,
+		Strike:       state.strikes,
+		BlockedUntil: state.blockedUntil,
+	}
+}
+
+// Prune удаляет клиентов без недавних ошибок и блокировок. Возвращает количество удаленных.
+func (l *Limiter) Prune() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	removed := 0
+	for caller, state := range l.callers {
+		state.failures = pruneFailures(state.failures, now.Add(-l.cfg.Window))
+		if len(state.failures) == 0 && now.Sub(state.blockedUntil) > l.cfg.ResetAfter {
+			delete(l.callers, caller)
+			removed++
+		}
+	}
+	return removed
+}
+
+// pruneFailures отбрасывает ошибки, произошедшие раньше since
+func pruneFailures(failures []failure, since time.Time) []failure {
+	i := 0
+	for i < len(failures) && failures[i].at.Before(since) {
+		i++
+	}
+	return failures[i:]
+}
+
+// blockDuration длительность блокировки с номером strike: base, 2*base, 4*base ... не более max
+func blockDuration(base, max time.Duration, strike int) time.Duration {
+	duration := base
+	for i := 1; i < strike && duration < max; i++ {
+		duration *= 2
+	}
+	if max > 0 && duration > max {
+		return max
+	}
+	return duration
+}
+
+func mostFrequent(counts map[string]int) string {
+	reason := ""
+	for _, kind := range []string{KindValidation, KindForbidden, KindAuth} {
+		if counts[kind] > counts[reason] {
+			reason = kind
+		}
+	}
+	return reason
+}
+
+// CleanupJob удаляет из памяти клиентов, которые давно не ошибались
+type CleanupJob struct {
+	limiter *Limiter
+}
+
+func NewCleanupJob(limiter *Limiter) *CleanupJob {
+	return &CleanupJob{limiter: limiter}
+}
+
+func (j *CleanupJob) Name() string {
+	return "abuse_cleanup"
+}
+
+func (j *CleanupJob) Run(ctx context.Context) error {
+	j.limiter.Prune()
+	return nil
+}
@@ -0,0 +1,200 @@
+package abuse
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/service"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap/zaptest"
+)
+
+type mockLockoutRepository struct {
+	lockouts []*repository.CallerLockout
+}
+
+func (m *mockLockoutRepository) AddLockout(ctx context.Context, lockout *repository.CallerLockout) error {
+	m.lockouts = append(m.lockouts, lockout)
+	return nil
+}
+
+func newTestLimiter(t *testing.T, repo *mockLockoutRepository, now *time.Time) *Limiter {
+	limiter := NewLimiter(config.AbuseConfig{
+		Enabled:    true,
+		Threshold:  3,
+		Window:     time.Minute,
+		BaseBlock:  time.Minute,
+		MaxBlock:   3 * time.Minute,
+		ResetAfter: time.Hour,
+	}, repo, zaptest.NewLogger(t))
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+func TestLimiterEscalation(t *testing.T) {
+	repo := &mockLockoutRepository{}
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newTestLimiter(t, repo, &now)
+	ctx := context.Background()
+	caller := "user:client@company.ru"
+
+	fail := func(n int) {
+		for i := 0; i < n; i++ {
+			limiter.RecordFailure(ctx, caller, KindValidation)
+		}
+	}
+
+	fail(2)
+	if _, blocked := limiter.RetryAfter(caller); blocked {
+		t.Fatal("caller must not be blocked below the threshold")
+	}
+
+	fail(1)
+	remaining, blocked := limiter.RetryAfter(caller)
+	if !blocked || remaining != time.Minute {
+		t.Fatalf("expected first block of 1m, got %v (blocked %v)", remaining, blocked)
+	}
+
+	// Ошибки во время блокировки не продлевают ее
+	fail(5)
+	if remaining, _ := limiter.RetryAfter(caller); remaining != time.Minute {
+		t.Errorf("expected block to stay 1m, got %v", remaining)
+	}
+
+	expected := []time.Duration{2 * time.Minute, 3 * time.Minute, 3 * time.Minute}
+	for i, duration := range expected {
+		now = now.Add(4 * time.Minute)
+		fail(3)
+		if remaining, _ := limiter.RetryAfter(caller); remaining != duration {
+			t.Errorf("strike %d: expected block of %v, got %v", i+2, duration, remaining)
+		}
+	}
+
+	// После долгого перерыва блокировка снова минимальная
+	now = now.Add(2 * time.Hour)
+	fail(3)
+	if remaining, _ := limiter.RetryAfter(caller); remaining != time.Minute {
+		t.Errorf("expected strikes to reset, got block of %v", remaining)
+	}
+
+	if len(repo.lockouts) != 5 {
+		t.Fatalf("expected 5 recorded lockouts, got %d", len(repo.lockouts))
+	}
+	first := repo.lockouts[0]
+	if first.Reason != KindValidation || first.Failures[KindValidation] != 3 || first.Strike != 1 {
+		t.Errorf("unexpected lockout record: %+v", first)
+	}
+	if repo.lockouts[4].Strike != 1 {
+		t.Errorf("expected strike 1 after reset, got %d", repo.lockouts[4].Strike)
+	}
+}
+
+func TestLimiterWindow(t *testing.T) {
+	repo := &mockLockoutRepository{}
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newTestLimiter(t, repo, &now)
+	ctx := context.Background()
+
+	limiter.RecordFailure(ctx, "ip:10.0.0.1", KindAuth)
+	limiter.RecordFailure(ctx, "ip:10.0.0.1", KindAuth)
+	now = now.Add(2 * time.Minute)
+	limiter.RecordFailure(ctx, "ip:10.0.0.1", KindAuth)
+
+	if _, blocked := limiter.RetryAfter("ip:10.0.0.1"); blocked {
+		t.Error("failures outside the window must not count")
+	}
+	if _, blocked := limiter.RetryAfter("ip:10.0.0.2"); blocked {
+		t.Error("unknown caller must not be blocked")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if removed := limiter.Prune(); removed != 1 {
+		t.Errorf("expected idle caller to be pruned, removed %d", removed)
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	repo := &mockLockoutRepository{}
+	limiter := NewLimiter(config.AbuseConfig{Threshold: 1, BaseBlock: time.Minute}, repo, zaptest.NewLogger(t))
+
+	limiter.RecordFailure(context.Background(), "ip:10.0.0.1", KindValidation)
+	if _, blocked := limiter.RetryAfter("ip:10.0.0.1"); blocked {
+		t.Error("disabled limiter must not block")
+	}
+	if len(repo.lockouts) != 0 {
+		t.Errorf("expected no lockouts, got %d", len(repo.lockouts))
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *gqlerror.Error
+		expected string
+	}{
+		{
+			name:     "bad_inn",
+			err:      gqlerror.Wrap(fmt.Errorf("inn must be 10 or 12 digits, got 3: %w", service.ErrInvalidInput)),
+			expected: KindValidation,
+		},
+		{
+			name:     "wrapped_validation",
+			err:      gqlerror.Wrap(fmt.Errorf("failed to get verification: %w", service.ErrInvalidInput)),
+			expected: KindValidation,
+		},
+		{
+			name:     "validation_text_not_counted",
+			err:      &gqlerror.Error{Message: "verification id cannot be empty"},
+			expected: "",
+		},
+		{
+			name:     "unknown_enum_value",
+			err:      &gqlerror.Error{Message: "Value \"FOO\" does not exist", Extensions: map[string]any{"code": "GRAPHQL_VALIDATION_FAILED"}},
+			expected: KindValidation,
+		},
+		{
+			name:     "query_limit",
+			err:      &gqlerror.Error{Message: "operation uses 40 aliases", Extensions: map[string]any{"code": "TOO_MANY_ALIASES"}},
+			expected: KindValidation,
+		},
+		{
+			name:     "forbidden_data_type",
+			err:      gqlerror.Wrap(fmt.Errorf("%w: api key does not allow data types ARBITRAGE_STATISTICS", auth.ErrForbidden)),
+			expected: KindForbidden,
+		},
+		{
+			name:     "unauthenticated",
+			err:      gqlerror.Wrap(auth.ErrUnauthenticated),
+			expected: KindForbidden,
+		},
+		{
+			name:     "access_denied_text_not_counted",
+			err:      &gqlerror.Error{Message: "access denied: copied from another error"},
+			expected: "",
+		},
+		{
+			name:     "gateway_failure_not_counted",
+			err:      &gqlerror.Error{Message: "failed to get verification: connection refused"},
+			expected: "",
+		},
+		{
+			name:     "not_found_not_counted",
+			err:      &gqlerror.Error{Message: "verification not found: 123"},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classify(tt.err); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
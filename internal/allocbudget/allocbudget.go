@@ -0,0 +1,21 @@
+// Package allocbudget ограничивает в тестах число выделений памяти на горячем пути,
+// чтобы рефакторинг ради производительности не ухудшал его незаметно.
+package allocbudget
+
+import "testing"
+
+// runs число вызовов, по которому усредняется количество выделений
+const runs = 100
+
+// Check проваливает тест, если f в среднем выделяет больше budget объектов за вызов.
+// Под детектором гонок проверка пропускается: инструментирование добавляет выделения.
+func Check(t testing.TB, budget float64, f func()) {
+	t.Helper()
+	if raceEnabled {
+		t.Skip("allocation budgets are not checked with the race detector")
+	}
+
+	if allocs := testing.AllocsPerRun(runs, f); allocs > budget {
+		t.Errorf("%.0f allocations per call exceed the budget of %.0f", allocs, budget)
+	}
+}
@@ -0,0 +1,36 @@
+// Package amendments публикует поправки завершенных проверок - данные, доставленные воркерами
+// после завершения.
+package amendments
+
+import (
+	"context"
+	"time"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/service"
+)
+
+// RelayJob публикует verification.amended для новых поправок
+type RelayJob struct {
+	service service.AmendmentService
+	client  messaging.NATSClient
+	cfg     config.AmendmentsConfig
+}
+
+func NewRelayJob(service service.AmendmentService, client messaging.NATSClient, cfg config.AmendmentsConfig) *RelayJob {
+	return &RelayJob{service: service, client: client, cfg: cfg}
+}
+
+func (j *RelayJob) Name() string {
+	return "amendment_relay"
+}
+
+func (j *RelayJob) Run(ctx context.Context) error {
+	// Без подключения к NATS поправки остаются неопубликованными до восстановления связи
+	if !j.client.Status().Connected {
+		return nil
+	}
+	_, err := j.service.PublishPending(ctx, time.Now().Add(j.cfg.ClaimTimeout), j.cfg.BatchSize)
+	return err
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// UserEmailHeader заголовок, в котором прокси аутентификации передает email пользователя
+	UserEmailHeader = "X-User-Email"
+	// UserRolesHeader заголовок со списком ролей пользователя через запятую
+	UserRolesHeader = "X-User-Roles"
+)
+
+const RoleAdmin = "admin"
+
+// Ошибки доступа. Подробности добавляются через %w, поэтому вызывающие проверяют их errors.Is,
+// а не по тексту ошибки.
+var (
+	// ErrUnauthenticated пользователь запроса неизвестен
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrForbidden пользователю или ключу запроса операция не разрешена
+	ErrForbidden = errors.New("access denied")
+)
+
+// Principal аутентифицированный пользователь или сервисный аккаунт запроса
+type Principal struct {
+	Email string
+	Roles []string
+	// Scope ограничения ключа сервисного аккаунта, nil для пользователей
+	Scope *Scope
+	// Sandbox запросы обслуживаются синтетическими данными без обращения к поставщикам
+	Sandbox bool
+	// Organization организация, участником которой пользователь зарегистрирован в шлюзе
+	Organization string
+	// Verified пользователя подтвердил прокси аутентификации: заголовками с доверенного адреса
+	// или подписанным токеном. Роли участника организации из базы получают только такие пользователи.
+	Verified bool
+	// TokenExpiresAt срок токена, которым аутентифицировано подключение; нулевой без токена
+	TokenExpiresAt time.Time
+}
+
+// HasRole проверяет, есть ли у пользователя роль
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type contextKey struct{}
+
+// WithPrincipal возвращает контекст с пользователем
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// PrincipalFromContext возвращает пользователя запроса, если он известен
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(contextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// RequireEmail возвращает email пользователя или ошибку, если запрос анонимный
+func RequireEmail(ctx context.Context) (string, error) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || principal.Email == "" {
+		return "", ErrUnauthenticated
+	}
+	return principal.Email, nil
+}
+
+// RequireRole возвращает ошибку, если у пользователя запроса нет роли
+func RequireRole(ctx context.Context, role string) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	if !principal.HasRole(role) {
+		return fmt.Errorf("%w: role %s required", ErrForbidden, role)
+	}
+	return nil
+}
+
+// Middleware определяет пользователя по заголовкам, выставленным прокси аутентификации.
+// Запрос с UserEmailHeader или UserRolesHeader не от proxy отклоняется с кодом 403,
+// иначе любой клиент мог бы выдать себя за другого пользователя.
+func Middleware(proxy *TrustedProxy, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		email := strings.TrimSpace(r.Header.Get(UserEmailHeader))
+		roles := r.Header.Get(UserRolesHeader)
+		if (email != "" || roles != "") && !proxy.Trusts(r) {
+			http.Error(w, "identity headers are accepted only from the authentication proxy", http.StatusForbidden)
+			return
+		}
+		if email != "" {
+			r = r.WithContext(WithPrincipal(r.Context(), &Principal{
+				Email:    email,
+				Roles:    parseRoles(roles),
+				Verified: true,
+			}))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireRoleMiddleware пропускает только пользователей с указанной ролью
+func RequireRoleMiddleware(proxy *TrustedProxy, role string, next http.Handler) http.Handler {
+	return Middleware(proxy, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := RequireRole(r.Context(), role); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}))
+}
+
+func parseRoles(header string) []string {
+	var roles []string
+	for _, role := range strings.Split(header, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	proxy, err := NewTrustedProxy([]string{"10.0.0.0/8"}, "proxy-secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		proxy          *TrustedProxy
+		remoteAddr     string
+		headers        map[string]string
+		expectedStatus int
+		expectedEmail  string
+		expectedAdmin  bool
+	}{
+		{
+			name:           "anonymous",
+			proxy:          proxy,
+			headers:        map[string]string{},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:       "user_without_roles",
+			proxy:      proxy,
+			remoteAddr: "10.1.2.3:41000",
+			headers: map[string]string{
+				UserEmailHeader: "user@example.com",
+			},
+			expectedStatus: http.StatusOK,
+			expectedEmail:  "user@example.com",
+		},
+		{
+			name:  "email_from_client",
+			proxy: proxy,
+			headers: map[string]string{
+				UserEmailHeader: "user@example.com",
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:       "email_without_trusted_proxy",
+			remoteAddr: "10.1.2.3:41000",
+			headers: map[string]string{
+				UserEmailHeader: "user@example.com",
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:       "admin_from_proxy_network",
+			proxy:      proxy,
+			remoteAddr: "10.1.2.3:41000",
+			headers: map[string]string{
+				UserEmailHeader: "admin@example.com",
+				UserRolesHeader: "analyst, admin",
+			},
+			expectedStatus: http.StatusOK,
+			expectedEmail:  "admin@example.com",
+			expectedAdmin:  true,
+		},
+		{
+			name:  "admin_with_proxy_secret",
+			proxy: proxy,
+			headers: map[string]string{
+				UserEmailHeader:   "admin@example.com",
+				UserRolesHeader:   "admin",
+				ProxySecretHeader: "proxy-secret",
+			},
+			expectedStatus: http.StatusOK,
+			expectedEmail:  "admin@example.com",
+			expectedAdmin:  true,
+		},
+		{
+			name:  "roles_from_client",
+			proxy: proxy,
+			headers: map[string]string{
+				UserEmailHeader: "user@example.com",
+				UserRolesHeader: "admin",
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:  "roles_with_wrong_secret",
+			proxy: proxy,
+			headers: map[string]string{
+				UserEmailHeader:   "user@example.com",
+				UserRolesHeader:   "admin",
+				ProxySecretHeader: "guess",
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:       "roles_without_trusted_proxy",
+			remoteAddr: "10.1.2.3:41000",
+			headers: map[string]string{
+				UserEmailHeader: "user@example.com",
+				UserRolesHeader: "admin",
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ctx context.Context
+			handler := Middleware(tt.proxy, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx = r.Context()
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/query", nil)
+			if tt.remoteAddr != "" {
+				req.RemoteAddr = tt.remoteAddr
+			}
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, but got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				if ctx != nil {
+					t.Error("expected rejected request not to reach the handler")
+				}
+				return
+			}
+
+			email, err := RequireEmail(ctx)
+			if tt.expectedEmail == "" {
+				if err == nil {
+					t.Errorf("expected unauthenticated error, but got email '%s'", email)
+				}
+				return
+			}
+			if email != tt.expectedEmail {
+				t.Errorf("expected email '%s', but got '%s'", tt.expectedEmail, email)
+			}
+
+			isAdmin := RequireRole(ctx, RoleAdmin) == nil
+			if isAdmin != tt.expectedAdmin {
+				t.Errorf("expected admin %t, but got %t", tt.expectedAdmin, isAdmin)
+			}
+		})
+	}
+}
+
+func TestNewTrustedProxyRejectsInvalidNetwork(t *testing.T) {
+	if _, err := NewTrustedProxy([]string{"10.0.0.1"}, ""); err == nil {
+		t.Error("expected error for address without prefix length")
+	}
+}
+
+func TestRequireRoleMiddleware(t *testing.T) {
+	proxy, err := NewTrustedProxy(nil, "proxy-secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := RequireRoleMiddleware(proxy, RoleAdmin, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set(UserEmailHeader, "user@example.com")
+	req.Header.Set(ProxySecretHeader, "proxy-secret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status %d for non-admin, but got %d", http.StatusForbidden, rec.Code)
+	}
+
+	req.Header.Set(UserRolesHeader, RoleAdmin)
+	req.Header.Del(ProxySecretHeader)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status %d for admin role set by the client, but got %d", http.StatusForbidden, rec.Code)
+	}
+
+	req.Header.Set(ProxySecretHeader, "proxy-secret")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("expected status %d for admin, but got %d", http.StatusNoContent, rec.Code)
+	}
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrTokenExpired срок действия токена истек
+var ErrTokenExpired = errors.New("token expired")
+
+// JWTVerifier проверяет токены JWT, подписанные HS256 общим секретом с прокси аутентификации
+type JWTVerifier struct {
+	secret []byte
+	now    func() time.Time
+}
+
+// NewJWTVerifier возвращает проверку токенов или nil, если секрет не задан
+func NewJWTVerifier(secret string) *JWTVerifier {
+	if secret == "" {
+		return nil
+	}
+	return &JWTVerifier{secret: []byte(secret), now: time.Now}
+}
+
+// Token проверенный токен: пользователь и момент, после которого токен недействителен
+type Token struct {
+	Principal *Principal
+	ExpiresAt time.Time
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Email     string   `json:"email"`
+	Roles     []string `json:"roles"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+}
+
+// Verify проверяет подпись и сроки токена. Токены без exp не принимаются: по истечении
+// срока подключения, открытые с токеном, закрываются.
+func (v *JWTVerifier) Verify(token string) (*Token, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+	if header.Alg != "HS256" {
+		return nil, fmt.Errorf("unsupported token algorithm %q", header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, fmt.Errorf("invalid token signature")
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	if claims.ExpiresAt == 0 {
+		return nil, fmt.Errorf("token has no expiration")
+	}
+	now := v.now()
+	expiresAt := time.Unix(claims.ExpiresAt, 0)
+	if !now.Before(expiresAt) {
+		return nil, ErrTokenExpired
+	}
+	if claims.NotBefore != 0 && now.Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, fmt.Errorf("token is not valid yet")
+	}
+
+	email := strings.TrimSpace(claims.Email)
+	if email == "" {
+		email = strings.TrimSpace(claims.Subject)
+	}
+	if email == "" {
+		return nil, fmt.Errorf("token has no subject")
+	}
+
+	return &Token{
+		Principal: &Principal{Email: email, Roles: claims.Roles, Verified: true, TokenExpiresAt: expiresAt},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
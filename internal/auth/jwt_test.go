@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signTestToken(secret, header, claims string) string {
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." + base64.RawURLEncoding.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestJWTVerifier(t *testing.T) {
+	if NewJWTVerifier("") != nil {
+		t.Fatal("expected no verifier without a secret")
+	}
+	verifier := NewJWTVerifier("secret")
+	verifier.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	const hs256 = `{"alg":"HS256","typ":"JWT"}`
+	tests := []struct {
+		name          string
+		token         string
+		expectedEmail string
+		expectedError string
+	}{
+		{name: "valid", token: signTestToken("secret", hs256, `{"email":"user@example.com","roles":["admin"],"exp":1700000600}`), expectedEmail: "user@example.com"},
+		{name: "subject", token: signTestToken("secret", hs256, `{"sub":"svc@example.com","exp":1700000600}`), expectedEmail: "svc@example.com"},
+		{name: "expired", token: signTestToken("secret", hs256, `{"email":"user@example.com","exp":1700000000}`), expectedError: "token expired"},
+		{name: "no_expiration", token: signTestToken("secret", hs256, `{"email":"user@example.com"}`), expectedError: "token has no expiration"},
+		{name: "not_yet_valid", token: signTestToken("secret", hs256, `{"email":"user@example.com","nbf":1700000100,"exp":1700000600}`), expectedError: "token is not valid yet"},
+		{name: "wrong_secret", token: signTestToken("other", hs256, `{"email":"user@example.com","exp":1700000600}`), expectedError: "invalid token signature"},
+		{name: "alg_none", token: signTestToken("secret", `{"alg":"none"}`, `{"email":"user@example.com","exp":1700000600}`), expectedError: "unsupported token algorithm"},
+		{name: "no_subject", token: signTestToken("secret", hs256, `{"exp":1700000600}`), expectedError: "token has no subject"},
+		{name: "malformed", token: "not-a-token", expectedError: "malformed token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := verifier.Verify(tt.token)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if token.Principal.Email != tt.expectedEmail || !token.ExpiresAt.Equal(time.Unix(1700000600, 0)) {
+				t.Errorf("unexpected token %+v", token)
+			}
+		})
+	}
+
+	_, err := verifier.Verify(signTestToken("secret", hs256, `{"email":"user@example.com","exp":1}`))
+	if !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("expected ErrTokenExpired, but got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+)
+
+// Роли пользователей в организации, назначаемые ее администратором
+const (
+	RoleOrgAdmin = "org_admin"
+	RoleAnalyst  = "analyst"
+	// RoleViewer только чтение: создавать проверки нельзя
+	RoleViewer = "viewer"
+)
+
+// MembershipResolver дополняет пользователя, определенного по заголовкам прокси, данными участника
+// организации. Возвращает nil, если учетная запись отключена.
+type MembershipResolver interface {
+	ResolveMembership(ctx context.Context, principal *Principal) (*Principal, error)
+}
+
+// MembershipMiddleware подставляет роли и организацию пользователя из базы. Пользователи без учетной
+// записи проходят с ролями из заголовков, сервисные аккаунты и пользователи, не подтвержденные
+// прокси аутентификации, не проверяются.
+func MembershipMiddleware(resolver MembershipResolver, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := PrincipalFromContext(r.Context())
+		if !ok || principal.Scope != nil || !principal.Verified {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		resolved, err := resolver.ResolveMembership(r.Context(), principal)
+		if err != nil {
+			http.Error(w, "failed to verify user", http.StatusServiceUnavailable)
+			return
+		}
+		if resolved == nil {
+			http.Error(w, "account deactivated", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), resolved)))
+	})
+}
+
+// readOnly сообщает, что у пользователя есть только роль наблюдателя
+func (p *Principal) readOnly() bool {
+	if !p.HasRole(RoleViewer) {
+		return false
+	}
+	for _, role := range p.Roles {
+		if role != RoleViewer {
+			return false
+		}
+	}
+	return true
+}
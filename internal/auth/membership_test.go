@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockMembershipResolver struct {
+	principal *Principal
+	err       error
+	calls     int
+}
+
+func (m *mockMembershipResolver) ResolveMembership(ctx context.Context, principal *Principal) (*Principal, error) {
+	m.calls++
+	return m.principal, m.err
+}
+
+func TestMembershipMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		principal      *Principal
+		resolver       *mockMembershipResolver
+		expectedStatus int
+		expectedRoles  []string
+		expectedCalls  int
+	}{
+		{
+			name:           "member",
+			principal:      &Principal{Email: "user@example.com", Verified: true},
+			resolver:       &mockMembershipResolver{principal: &Principal{Email: "user@example.com", Roles: []string{RoleOrgAdmin}, Organization: "example.com"}},
+			expectedStatus: http.StatusOK,
+			expectedRoles:  []string{RoleOrgAdmin},
+			expectedCalls:  1,
+		},
+		{
+			name:           "deactivated",
+			principal:      &Principal{Email: "user@example.com", Verified: true},
+			resolver:       &mockMembershipResolver{},
+			expectedStatus: http.StatusForbidden,
+			expectedCalls:  1,
+		},
+		{
+			name:           "resolver_error",
+			principal:      &Principal{Email: "user@example.com", Verified: true},
+			resolver:       &mockMembershipResolver{err: errors.New("database connection failed")},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedCalls:  1,
+		},
+		{
+			name:           "service_account_skipped",
+			principal:      &Principal{Email: "partner@example.com", Scope: &Scope{}},
+			resolver:       &mockMembershipResolver{},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unverified_skipped",
+			principal:      &Principal{Email: "user@example.com"},
+			resolver:       &mockMembershipResolver{principal: &Principal{Email: "user@example.com", Roles: []string{RoleOrgAdmin}}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "anonymous_skipped",
+			resolver:       &mockMembershipResolver{},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var roles []string
+			handler := MembershipMiddleware(tt.resolver, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if principal, ok := PrincipalFromContext(r.Context()); ok {
+					roles = principal.Roles
+				}
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/query", nil)
+			if tt.principal != nil {
+				req = req.WithContext(WithPrincipal(req.Context(), tt.principal))
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, but got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.resolver.calls != tt.expectedCalls {
+				t.Errorf("expected %d resolver calls, but got %d", tt.expectedCalls, tt.resolver.calls)
+			}
+			if len(roles) != len(tt.expectedRoles) {
+				t.Errorf("expected roles %v, but got %v", tt.expectedRoles, roles)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ProxySecretHeader заголовок с общим секретом, которым прокси аутентификации подтверждает,
+// что заголовки пользователя выставил он
+const ProxySecretHeader = "X-Auth-Proxy-Secret"
+
+// TrustedProxy определяет запросы, прошедшие через прокси аутентификации: с адресов из списка
+// сетей или с общим секретом в ProxySecretHeader. UserEmailHeader и UserRolesHeader принимаются
+// только от него, иначе любой клиент мог бы выдать себя за другого пользователя или назначить себе роль admin.
+type TrustedProxy struct {
+	networks []*net.IPNet
+	secret   []byte
+}
+
+// NewTrustedProxy разбирает сети прокси в нотации CIDR. Без сетей и секрета прокси не доверяется
+// ни один запрос.
+func NewTrustedProxy(cidrs []string, secret string) (*TrustedProxy, error) {
+	proxy := &TrustedProxy{secret: []byte(secret)}
+	for _, cidr := range cidrs {
+		if cidr = strings.TrimSpace(cidr); cidr == "" {
+			continue
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy network %q: %w", cidr, err)
+		}
+		proxy.networks = append(proxy.networks, network)
+	}
+	return proxy, nil
+}
+
+// Trusts сообщает, пришел ли запрос от прокси аутентификации
+func (p *TrustedProxy) Trusts(r *http.Request) bool {
+	if p == nil {
+		return false
+	}
+	if len(p.secret) > 0 {
+		if secret := r.Header.Get(ProxySecretHeader); secret != "" && subtle.ConstantTimeCompare([]byte(secret), p.secret) == 1 {
+			return true
+		}
+	}
+	if len(p.networks) == 0 {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range p.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader заголовок с ключом сервисного аккаунта
+const APIKeyHeader = "X-API-Key"
+
+// Operation операция, которую может выполнять сервисный аккаунт
+type Operation string
+
+const (
+	OperationCreate Operation = "create"
+	OperationRead   Operation = "read"
+)
+
+// Scope ограничения ключа сервисного аккаунта. Пустые списки означают отсутствие ограничений.
+type Scope struct {
+	KeyID      string
+	Operations []Operation
+	DataTypes  []string
+}
+
+// AllowsOperation проверяет, разрешена ли операция
+func (s *Scope) AllowsOperation(op Operation) bool {
+	if len(s.Operations) == 0 {
+		return true
+	}
+	for _, allowed := range s.Operations {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsDataType проверяет, разрешен ли тип данных
+func (s *Scope) AllowsDataType(dataType string) bool {
+	if len(s.DataTypes) == 0 {
+		return true
+	}
+	for _, allowed := range s.DataTypes {
+		if allowed == dataType {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckOperation возвращает ошибку, если ключ запроса не разрешает операцию.
+// Пользователям с одной ролью наблюдателя доступно только чтение, запросы внутренних задач не ограничиваются.
+func CheckOperation(ctx context.Context, op Operation) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if principal.Scope == nil {
+		if op != OperationRead && principal.readOnly() {
+			return fmt.Errorf("%w: role %s allows only %s operation", ErrForbidden, RoleViewer, OperationRead)
+		}
+		return nil
+	}
+	if !principal.Scope.AllowsOperation(op) {
+		return fmt.Errorf("%w: api key does not allow %s operation", ErrForbidden, op)
+	}
+	return nil
+}
+
+// CheckDataTypes возвращает ошибку, если ключ запроса не разрешает хотя бы один из типов данных
+func CheckDataTypes(ctx context.Context, dataTypes []string) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || principal.Scope == nil {
+		return nil
+	}
+	var denied []string
+	for _, dataType := range dataTypes {
+		if !principal.Scope.AllowsDataType(dataType) {
+			denied = append(denied, dataType)
+		}
+	}
+	if len(denied) > 0 {
+		return fmt.Errorf("%w: api key does not allow data types %s", ErrForbidden, strings.Join(denied, ", "))
+	}
+	return nil
+}
+
+// DataTypeAllowed сообщает, можно ли отдавать данные указанного типа в ответе на запрос
+func DataTypeAllowed(ctx context.Context, dataType string) bool {
+	principal, ok := PrincipalFromContext(ctx)
+	return !ok || principal.Scope == nil || principal.Scope.AllowsDataType(dataType)
+}
+
+// HashAPIKey возвращает хэш ключа, под которым он хранится в базе
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyResolver находит сервисный аккаунт по ключу. Возвращает nil, если ключ неизвестен или отозван.
+type APIKeyResolver interface {
+	ResolveAPIKey(ctx context.Context, key string) (*Principal, error)
+}
+
+// APIKeyMiddleware аутентифицирует запросы с заголовком X-API-Key,
+// остальные запросы обрабатываются как запросы пользователей через Middleware
+func APIKeyMiddleware(resolver APIKeyResolver, proxy *TrustedProxy, next http.Handler) http.Handler {
+	userHandler := Middleware(proxy, next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimSpace(r.Header.Get(APIKeyHeader))
+		if key == "" {
+			userHandler.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := resolver.ResolveAPIKey(r.Context(), key)
+		if err != nil {
+			http.Error(w, "failed to verify api key", http.StatusServiceUnavailable)
+			return
+		}
+		if principal == nil {
+			http.Error(w, "invalid api key", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
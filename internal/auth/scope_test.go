@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockAPIKeyResolver struct {
+	principal *Principal
+	err       error
+}
+
+func (m *mockAPIKeyResolver) ResolveAPIKey(ctx context.Context, key string) (*Principal, error) {
+	return m.principal, m.err
+}
+
+func TestCheckScope(t *testing.T) {
+	readOnly := WithPrincipal(context.Background(), &Principal{
+		Email: "partner@example.com",
+		Scope: &Scope{Operations: []Operation{OperationRead}},
+	})
+	basicOnly := WithPrincipal(context.Background(), &Principal{
+		Email: "partner@example.com",
+		Scope: &Scope{Operations: []Operation{OperationCreate}, DataTypes: []string{"BASIC_INFORMATION"}},
+	})
+	user := WithPrincipal(context.Background(), &Principal{Email: "user@example.com"})
+
+	if err := CheckOperation(readOnly, OperationCreate); err == nil {
+		t.Error("expected read-only key to be denied create, but got nil")
+	}
+	if err := CheckOperation(readOnly, OperationRead); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := CheckDataTypes(basicOnly, []string{"BASIC_INFORMATION", "ARBITRAGE_STATISTICS"}); err == nil {
+		t.Error("expected uncontracted data type to be denied, but got nil")
+	}
+	if err := CheckDataTypes(basicOnly, []string{"BASIC_INFORMATION"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := CheckOperation(user, OperationCreate); err != nil {
+		t.Errorf("expected users to be unrestricted, but got %v", err)
+	}
+	viewer := WithPrincipal(context.Background(), &Principal{Email: "viewer@example.com", Roles: []string{RoleViewer}})
+	if err := CheckOperation(viewer, OperationCreate); err == nil {
+		t.Error("expected viewer to be denied create, but got nil")
+	}
+	if err := CheckOperation(viewer, OperationRead); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	analyst := WithPrincipal(context.Background(), &Principal{Email: "analyst@example.com", Roles: []string{RoleViewer, RoleAnalyst}})
+	if err := CheckOperation(analyst, OperationCreate); err != nil {
+		t.Errorf("expected viewer with another role to be unrestricted, but got %v", err)
+	}
+	if !DataTypeAllowed(context.Background(), "ARBITRAGE_STATISTICS") {
+		t.Error("expected internal calls to be unrestricted")
+	}
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		key            string
+		roles          string
+		fromProxy      bool
+		resolver       *mockAPIKeyResolver
+		expectedStatus int
+		expectedEmail  string
+	}{
+		{
+			name:           "no_key_falls_back_to_user",
+			fromProxy:      true,
+			resolver:       &mockAPIKeyResolver{},
+			expectedStatus: http.StatusOK,
+			expectedEmail:  "user@example.com",
+		},
+		{
+			name:           "no_key_email_from_client",
+			resolver:       &mockAPIKeyResolver{},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "no_key_roles_from_client",
+			roles:          RoleAdmin,
+			resolver:       &mockAPIKeyResolver{},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "valid_key",
+			key:            "secret",
+			resolver:       &mockAPIKeyResolver{principal: &Principal{Email: "partner@example.com", Scope: &Scope{}}},
+			expectedStatus: http.StatusOK,
+			expectedEmail:  "partner@example.com",
+		},
+		{
+			name:           "unknown_key",
+			key:            "secret",
+			resolver:       &mockAPIKeyResolver{},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "resolver_error",
+			key:            "secret",
+			resolver:       &mockAPIKeyResolver{err: errors.New("database connection failed")},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	proxy, err := NewTrustedProxy(nil, "proxy-secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var email string
+			handler := APIKeyMiddleware(tt.resolver, proxy, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				email, _ = RequireEmail(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/query", nil)
+			req.Header.Set(UserEmailHeader, "user@example.com")
+			if tt.roles != "" {
+				req.Header.Set(UserRolesHeader, tt.roles)
+			}
+			if tt.fromProxy {
+				req.Header.Set(ProxySecretHeader, "proxy-secret")
+			}
+			if tt.key != "" {
+				req.Header.Set(APIKeyHeader, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, but got %d", tt.expectedStatus, rec.Code)
+			}
+			if email != tt.expectedEmail {
+				t.Errorf("expected email '%s', but got '%s'", tt.expectedEmail, email)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Package buildinfo хранит сведения о сборке, которые задаются при компиляции:
+//
+//	go build -ldflags "-X scoring_api_gateway/internal/buildinfo.Version=1.4.0 \
+//	  -X scoring_api_gateway/internal/buildinfo.GitSHA=$(git rev-parse HEAD) \
+//	  -X scoring_api_gateway/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Значения подставляются через -ldflags -X. Без них коммит и время берутся из VCS-сведений Go.
+var (
+	Version   = "dev"
+	GitSHA    = ""
+	BuildTime = ""
+)
+
+// Info сведения о сборке и версии схемы GraphQL
+type Info struct {
+	Version       string `json:"version"`
+	GitSHA        string `json:"git_sha"`
+	BuildTime     string `json:"build_time"`
+	GoVersion     string `json:"go_version"`
+	SchemaVersion int    `json:"schema_version"`
+	// Modified сборка из рабочей копии с незакоммиченными изменениями
+	Modified bool `json:"modified"`
+}
+
+// Get собирает сведения о сборке. Версия схемы передается вызывающим, чтобы пакет не зависел от graph.
+func Get(schemaVersion int) Info {
+	info := Info{
+		Version:       Version,
+		GitSHA:        GitSHA,
+		BuildTime:     BuildTime,
+		GoVersion:     runtime.Version(),
+		SchemaVersion: schemaVersion,
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.GitSHA == "" {
+					info.GitSHA = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+
+	return info
+}
@@ -0,0 +1,19 @@
+package buildinfo
+
+import "testing"
+
+func TestGetUsesLinkerValues(t *testing.T) {
+	Version, GitSHA, BuildTime = "1.4.0", "abc123", "2024-01-15T10:00:00Z"
+	defer func() { Version, GitSHA, BuildTime = "dev", "", "" }()
+
+	info := Get(3)
+	if info.Version != "1.4.0" || info.GitSHA != "abc123" || info.BuildTime != "2024-01-15T10:00:00Z" {
+		t.Errorf("expected linker values, but got %+v", info)
+	}
+	if info.SchemaVersion != 3 {
+		t.Errorf("expected schema version 3, but got %d", info.SchemaVersion)
+	}
+	if info.GoVersion == "" {
+		t.Error("expected go version")
+	}
+}
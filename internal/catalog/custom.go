@@ -0,0 +1,141 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+)
+
+// customTypePattern имя объявленного типа в стиле значений VerificationDataType
+var customTypePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// maxCustomTypeLength длина столбца data_type в verification_data
+const maxCustomTypeLength = 50
+
+// reservedSubjects subject запросов и уведомлений, которые используют встроенные типы данных
+var reservedSubjects = []string{"verification.create", "verification.create.high", "verification.completed"}
+
+// customDefinition описание типа данных в файле каталога объявленных типов
+type customDefinition struct {
+	Type           string          `json:"type"`
+	Name           string          `json:"name"`
+	Description    string          `json:"description"`
+	Provider       string          `json:"provider"`
+	Subject        string          `json:"subject"`
+	SchemaVersion  int             `json:"schema_version"`
+	TypicalLatency string          `json:"typical_latency"`
+	CostTier       model.CostTier  `json:"cost_tier"`
+	Available      *bool           `json:"available"`
+	Schema         json.RawMessage `json:"schema"`
+}
+
+// LoadCustom читает типы данных, объявленные в файлах *.json каталога dir, в порядке имен файлов.
+// Новый тип данных подключается без изменения кода: запросы уходят воркерам в его subject,
+// доставленные данные проверяются по его JSON Schema и доступны через dataByType.
+func LoadCustom(dir string) ([]DataType, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom data types: %w", err)
+	}
+	slices.Sort(paths)
+
+	entries := make([]DataType, 0, len(paths))
+	seenTypes := make(map[model.VerificationDataType]string, len(paths))
+	seenSubjects := make(map[string]string, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read custom data type: %w", err)
+		}
+
+		var definition customDefinition
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&definition); err != nil {
+			return nil, fmt.Errorf("invalid custom data type %s: %w", path, err)
+		}
+		entry, err := definition.dataType()
+		if err != nil {
+			return nil, fmt.Errorf("invalid custom data type %s: %w", path, err)
+		}
+
+		if other, ok := seenTypes[entry.Type]; ok {
+			return nil, fmt.Errorf("custom data type %s is declared in both %s and %s", entry.Type, other, path)
+		}
+		if other, ok := seenSubjects[entry.Subject]; ok {
+			return nil, fmt.Errorf("subject %s is used by both %s and %s", entry.Subject, other, path)
+		}
+		seenTypes[entry.Type], seenSubjects[entry.Subject] = path, path
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (d *customDefinition) dataType() (DataType, error) {
+	dataType := model.VerificationDataType(d.Type)
+	if !customTypePattern.MatchString(d.Type) || len(d.Type) > maxCustomTypeLength {
+		return DataType{}, fmt.Errorf("type %q must be up to %d upper-case letters, digits and '_', starting with a letter", d.Type, maxCustomTypeLength)
+	}
+	if dataType.IsValid() {
+		return DataType{}, fmt.Errorf("type %s is a built-in data type", d.Type)
+	}
+	if strings.TrimSpace(d.Name) == "" {
+		return DataType{}, fmt.Errorf("name is required")
+	}
+	if d.Subject == "" || strings.ContainsAny(d.Subject, " \t*>") {
+		return DataType{}, fmt.Errorf("subject %q must be a NATS subject without wildcards", d.Subject)
+	}
+	if slices.Contains(reservedSubjects, d.Subject) {
+		return DataType{}, fmt.Errorf("subject %s is reserved for built-in data types", d.Subject)
+	}
+	if !d.CostTier.IsValid() {
+		return DataType{}, fmt.Errorf("unknown cost tier %q", d.CostTier)
+	}
+	if len(bytes.TrimSpace(d.Schema)) == 0 || bytes.TrimSpace(d.Schema)[0] != '{' {
+		return DataType{}, fmt.Errorf("schema must be a JSON Schema object")
+	}
+
+	schemaVersion := d.SchemaVersion
+	if schemaVersion == 0 {
+		schemaVersion = 1
+	}
+	if schemaVersion < 0 {
+		return DataType{}, fmt.Errorf("schema version must be positive")
+	}
+
+	var latency time.Duration
+	if d.TypicalLatency != "" {
+		parsed, err := time.ParseDuration(d.TypicalLatency)
+		if err != nil || parsed < 0 {
+			return DataType{}, fmt.Errorf("invalid typical latency %q", d.TypicalLatency)
+		}
+		latency = parsed
+	}
+
+	available := true
+	if d.Available != nil {
+		available = *d.Available
+	}
+
+	return DataType{
+		Type:           dataType,
+		Name:           d.Name,
+		Description:    d.Description,
+		Provider:       d.Provider,
+		SchemaVersion:  schemaVersion,
+		TypicalLatency: latency,
+		CostTier:       d.CostTier,
+		Available:      available,
+		Custom:         true,
+		Subject:        d.Subject,
+		Schema:         d.Schema,
+	}, nil
+}
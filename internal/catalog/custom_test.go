@@ -0,0 +1,100 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+)
+
+const creditRatingDefinition = `{
+	"type": "CREDIT_RATING",
+	"name": "Кредитный рейтинг",
+	"provider": "Эксперт РА",
+	"subject": "verification.create.credit_rating",
+	"typical_latency": "45s",
+	"cost_tier": "MEDIUM",
+	"schema": {"type": "object", "required": ["rating"]}
+}`
+
+func writeDefinitions(t *testing.T, definitions map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range definitions {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write definition: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestLoadCustom(t *testing.T) {
+	dir := writeDefinitions(t, map[string]string{"credit_rating.json": creditRatingDefinition, "README.md": "ignored"})
+
+	custom, err := LoadCustom(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(custom) != 1 {
+		t.Fatalf("expected 1 custom data type, but got %d", len(custom))
+	}
+	entry := custom[0]
+	if entry.Type != "CREDIT_RATING" || !entry.Custom || !entry.Available || entry.SchemaVersion != 1 ||
+		entry.TypicalLatency != 45*time.Second || entry.Subject != "verification.create.credit_rating" {
+		t.Errorf("unexpected custom data type %+v", entry)
+	}
+
+	registry := DefaultRegistry().With(custom)
+	if _, ok := registry.Lookup("CREDIT_RATING"); !ok {
+		t.Error("expected the custom data type in the registry")
+	}
+	if len(registry.Custom()) != 1 || len(DefaultRegistry().Custom()) != 0 {
+		t.Error("expected only the extended registry to contain the custom data type")
+	}
+}
+
+func TestLoadCustomRejectsInvalidDefinitions(t *testing.T) {
+	tests := []struct {
+		name        string
+		definitions map[string]string
+		expected    string
+	}{
+		{
+			name:        "built_in_type",
+			definitions: map[string]string{"a.json": strings.Replace(creditRatingDefinition, "CREDIT_RATING", string(model.VerificationDataTypeActivities), 1)},
+			expected:    "is a built-in data type",
+		},
+		{
+			name:        "reserved_subject",
+			definitions: map[string]string{"a.json": strings.Replace(creditRatingDefinition, "verification.create.credit_rating", "verification.create", 1)},
+			expected:    "is reserved",
+		},
+		{
+			name:        "missing_schema",
+			definitions: map[string]string{"a.json": `{"type": "CREDIT_RATING", "name": "Рейтинг", "subject": "credit.rating", "cost_tier": "LOW"}`},
+			expected:    "schema must be a JSON Schema object",
+		},
+		{
+			name:        "unknown_field",
+			definitions: map[string]string{"a.json": `{"type": "CREDIT_RATING", "price": 10}`},
+			expected:    "unknown field",
+		},
+		{
+			name:        "duplicate_type",
+			definitions: map[string]string{"a.json": creditRatingDefinition, "b.json": strings.Replace(creditRatingDefinition, "credit_rating\"", "rating\"", 1)},
+			expected:    "is declared in both",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadCustom(writeDefinitions(t, tt.definitions))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected an error containing %q, but got %v", tt.expected, err)
+			}
+		})
+	}
+}
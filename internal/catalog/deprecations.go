@@ -0,0 +1,114 @@
+package catalog
+
+import (
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/metrics"
+)
+
+// Deprecation устаревшее значение перечисления API и значение, которым шлюз его заменяет
+type Deprecation struct {
+	Replacement string
+	Reason      string
+}
+
+// Deprecations устаревшие значения перечислений. Значение выводится из употребления так:
+//  1. в schema.graphqls значение помечается @deprecated(reason: ...), но остается в схеме,
+//     чтобы старые клиенты могли его передавать;
+//  2. значение и замена добавляются в DefaultDeprecations - шлюз принимает старое значение
+//     и работает с новым;
+//  3. когда метрика deprecated_enum_values_total перестает расти, значение удаляется из схемы.
+type Deprecations struct {
+	statuses  map[model.VerificationStatus]Deprecation
+	dataTypes map[model.VerificationDataType]Deprecation
+}
+
+func NewDeprecations(statuses map[model.VerificationStatus]Deprecation, dataTypes map[model.VerificationDataType]Deprecation) *Deprecations {
+	return &Deprecations{
+		statuses:  statuses,
+		dataTypes: dataTypes,
+	}
+}
+
+// defaultDeprecations устаревшие значения текущей схемы
+var defaultDeprecations = NewDeprecations(
+	map[model.VerificationStatus]Deprecation{},
+	map[model.VerificationDataType]Deprecation{},
+)
+
+// DefaultDeprecations возвращает устаревшие значения перечислений текущей схемы
+func DefaultDeprecations() *Deprecations {
+	return defaultDeprecations
+}
+
+// Status возвращает описание устаревшего статуса
+func (d *Deprecations) Status(status model.VerificationStatus) (Deprecation, bool) {
+	deprecation, ok := d.statuses[status]
+	return deprecation, ok
+}
+
+// DataType возвращает описание устаревшего типа данных
+func (d *Deprecations) DataType(dataType model.VerificationDataType) (Deprecation, bool) {
+	deprecation, ok := d.dataTypes[dataType]
+	return deprecation, ok
+}
+
+// CanonicalStatus заменяет устаревший статус актуальным
+func (d *Deprecations) CanonicalStatus(status model.VerificationStatus) model.VerificationStatus {
+	deprecation, ok := d.statuses[status]
+	if !ok {
+		return status
+	}
+	metrics.DeprecatedEnumValues.WithLabelValues("VerificationStatus", string(status)).Inc()
+	return model.VerificationStatus(deprecation.Replacement)
+}
+
+// CanonicalDataTypes заменяет устаревшие типы данных актуальными. Если клиент передал и старое,
+// и новое значение, тип остается один раз на месте первого упоминания.
+func (d *Deprecations) CanonicalDataTypes(dataTypes []model.VerificationDataType) []model.VerificationDataType {
+	if len(d.dataTypes) == 0 {
+		return dataTypes
+	}
+
+	result := make([]model.VerificationDataType, 0, len(dataTypes))
+	seen := make(map[model.VerificationDataType]bool, len(dataTypes))
+	for _, dataType := range dataTypes {
+		if deprecation, ok := d.dataTypes[dataType]; ok {
+			metrics.DeprecatedEnumValues.WithLabelValues("VerificationDataType", string(dataType)).Inc()
+			dataType = model.VerificationDataType(deprecation.Replacement)
+		}
+		if !seen[dataType] {
+			seen[dataType] = true
+			result = append(result, dataType)
+		}
+	}
+	return result
+}
+
+// EnumCatalog возвращает значения перечислений, которые клиенты передают в запросах, с пометками об устаревании
+func (d *Deprecations) EnumCatalog() []*model.EnumInfo {
+	statuses := make([]*model.EnumValueInfo, 0, len(model.AllVerificationStatus))
+	for _, status := range model.AllVerificationStatus {
+		deprecation, ok := d.statuses[status]
+		statuses = append(statuses, enumValueInfo(string(status), deprecation, ok))
+	}
+
+	dataTypes := make([]*model.EnumValueInfo, 0, len(model.AllVerificationDataType))
+	for _, dataType := range model.AllVerificationDataType {
+		deprecation, ok := d.dataTypes[dataType]
+		dataTypes = append(dataTypes, enumValueInfo(string(dataType), deprecation, ok))
+	}
+
+	return []*model.EnumInfo{
+		{Name: "VerificationStatus", Values: statuses},
+		{Name: "VerificationDataType", Values: dataTypes},
+	}
+}
+
+func enumValueInfo(value string, deprecation Deprecation, deprecated bool) *model.EnumValueInfo {
+	info := &model.EnumValueInfo{Value: value, Deprecated: deprecated}
+	if deprecated {
+		info.DeprecationReason = &deprecation.Reason
+		info.Replacement = &deprecation.Replacement
+	}
+	return info
+}
@@ -0,0 +1,69 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+)
+
+func TestDeprecations(t *testing.T) {
+	deprecations := NewDeprecations(
+		map[model.VerificationStatus]Deprecation{
+			model.VerificationStatusProcessing: {Replacement: string(model.VerificationStatusInProcess), Reason: "merged into IN_PROCESS"},
+		},
+		map[model.VerificationDataType]Deprecation{
+			model.VerificationDataTypeAddressesByCredinform: {Replacement: string(model.VerificationDataTypeAddressesByUnifiedStateRegister), Reason: "provider retired"},
+		},
+	)
+
+	t.Run("canonical_status", func(t *testing.T) {
+		if got := deprecations.CanonicalStatus(model.VerificationStatusProcessing); got != model.VerificationStatusInProcess {
+			t.Errorf("expected IN_PROCESS, but got %s", got)
+		}
+		if got := deprecations.CanonicalStatus(model.VerificationStatusCompleted); got != model.VerificationStatusCompleted {
+			t.Errorf("expected COMPLETED to be kept, but got %s", got)
+		}
+	})
+
+	t.Run("canonical_data_types", func(t *testing.T) {
+		got := deprecations.CanonicalDataTypes([]model.VerificationDataType{
+			model.VerificationDataTypeAddressesByCredinform,
+			model.VerificationDataTypeBasicInformation,
+			model.VerificationDataTypeAddressesByUnifiedStateRegister,
+		})
+		expected := []model.VerificationDataType{
+			model.VerificationDataTypeAddressesByUnifiedStateRegister,
+			model.VerificationDataTypeBasicInformation,
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected %v, but got %v", expected, got)
+		}
+	})
+
+	t.Run("enum_catalog", func(t *testing.T) {
+		enums := deprecations.EnumCatalog()
+		if len(enums) != 2 || enums[0].Name != "VerificationStatus" || enums[1].Name != "VerificationDataType" {
+			t.Fatalf("unexpected enums: %+v", enums)
+		}
+		if len(enums[1].Values) != len(model.AllVerificationDataType) {
+			t.Errorf("expected %d data types, but got %d", len(model.AllVerificationDataType), len(enums[1].Values))
+		}
+
+		var deprecated []string
+		for _, enum := range enums {
+			for _, value := range enum.Values {
+				if !value.Deprecated {
+					continue
+				}
+				deprecated = append(deprecated, value.Value)
+				if value.Replacement == nil || value.DeprecationReason == nil {
+					t.Errorf("%s: expected replacement and reason", value.Value)
+				}
+			}
+		}
+		if !reflect.DeepEqual(deprecated, []string{"PROCESSING", "ADDRESSES_BY_CREDINFORM"}) {
+			t.Errorf("unexpected deprecated values %v", deprecated)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+// Package catalog описывает типы данных, которые шлюз может заказать у поставщиков.
+package catalog
+
+import (
+	"slices"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+)
+
+// DataType описание типа данных и поставщика, который его предоставляет
+type DataType struct {
+	Type        model.VerificationDataType
+	Name        string
+	Description string
+	Provider    string
+	// SchemaVersion версия формата данных поставщика, которую описывает JSON Schema шлюза
+	SchemaVersion  int
+	TypicalLatency time.Duration
+	CostTier       model.CostTier
+	Available      bool
+	// Custom тип объявлен в конфигурации шлюза и еще не имеет значения VerificationDataType
+	Custom bool
+	// Subject subject NATS, в который публикуются запросы данных объявленного типа
+	Subject string
+	// Schema JSON Schema данных объявленного типа
+	Schema []byte
+}
+
+// Registry реестр поставщиков данных
+type Registry struct {
+	entries []DataType
+	byType  map[model.VerificationDataType]DataType
+}
+
+// NewRegistry создает реестр из описаний типов данных
+func NewRegistry(entries []DataType) *Registry {
+	registry := &Registry{
+		entries: entries,
+		byType:  make(map[model.VerificationDataType]DataType, len(entries)),
+	}
+	for _, entry := range entries {
+		registry.byType[entry.Type] = entry
+	}
+	return registry
+}
+
+// All возвращает все типы данных в порядке объявления
+func (r *Registry) All() []DataType {
+	return r.entries
+}
+
+// Lookup возвращает описание типа данных
+func (r *Registry) Lookup(dataType model.VerificationDataType) (DataType, bool) {
+	entry, ok := r.byType[dataType]
+	return entry, ok
+}
+
+// Custom возвращает типы данных, объявленные в конфигурации, в порядке объявления
+func (r *Registry) Custom() []DataType {
+	var custom []DataType
+	for _, entry := range r.entries {
+		if entry.Custom {
+			custom = append(custom, entry)
+		}
+	}
+	return custom
+}
+
+// With возвращает реестр с типами данных реестра и добавленными entries
+func (r *Registry) With(entries []DataType) *Registry {
+	return NewRegistry(append(slices.Clone(r.entries), entries...))
+}
+
+// SchemaVersion возвращает текущую версию формата данных типа. Для типа вне каталога - 1.
+func (r *Registry) SchemaVersion(dataType model.VerificationDataType) int {
+	if entry, ok := r.byType[dataType]; ok && entry.SchemaVersion > 0 {
+		return entry.SchemaVersion
+	}
+	return 1
+}
+
+// DefaultRegistry реестр поставщиков, с которыми работает сервис скоринга
+func DefaultRegistry() *Registry {
+	return NewRegistry([]DataType{
+		{
+			Type:           model.VerificationDataTypeBasicInformation,
+			Name:           "Основные сведения",
+			Description:    "Наименование, ОГРН, дата регистрации, руководитель и уставный капитал",
+			Provider:       "Credinform",
+			SchemaVersion:  1,
+			TypicalLatency: 5 * time.Second,
+			CostTier:       model.CostTierLow,
+			Available:      true,
+		},
+		{
+			Type:           model.VerificationDataTypeActivities,
+			Name:           "Виды деятельности",
+			Description:    "Основной и дополнительные коды ОКВЭД",
+			Provider:       "Credinform",
+			SchemaVersion:  1,
+			TypicalLatency: 5 * time.Second,
+			CostTier:       model.CostTierLow,
+			Available:      true,
+		},
+		{
+			Type:           model.VerificationDataTypeAddressesByCredinform,
+			Name:           "Адреса (Credinform)",
+			Description:    "Юридический и фактические адреса по данным Credinform",
+			Provider:       "Credinform",
+			SchemaVersion:  1,
+			TypicalLatency: 10 * time.Second,
+			CostTier:       model.CostTierMedium,
+			Available:      true,
+		},
+		{
+			Type:           model.VerificationDataTypeAddressesByUnifiedStateRegister,
+			Name:           "Адреса (ЕГРЮЛ)",
+			Description:    "Адрес регистрации и признаки массового адреса по выписке ЕГРЮЛ",
+			Provider:       "ФНС ЕГРЮЛ",
+			SchemaVersion:  1,
+			TypicalLatency: 30 * time.Second,
+			CostTier:       model.CostTierLow,
+			Available:      true,
+		},
+		{
+			Type:           model.VerificationDataTypeAffiliatedCompanies,
+			Name:           "Аффилированные компании",
+			Description:    "Связанные компании через учредителей и руководителей",
+			Provider:       "Credinform",
+			SchemaVersion:  1,
+			TypicalLatency: 2 * time.Minute,
+			CostTier:       model.CostTierHigh,
+			Available:      true,
+		},
+		{
+			Type:           model.VerificationDataTypeArbitrageStatistics,
+			Name:           "Арбитражная статистика",
+			Description:    "Количество и суммы арбитражных дел в роли истца и ответчика",
+			Provider:       "Картотека арбитражных дел",
+			SchemaVersion:  1,
+			TypicalLatency: 5 * time.Minute,
+			CostTier:       model.CostTierHigh,
+			Available:      true,
+		},
+	})
+}
@@ -0,0 +1,32 @@
+package catalog
+
+import (
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+)
+
+func TestDefaultRegistryCoversAllDataTypes(t *testing.T) {
+	registry := DefaultRegistry()
+
+	for _, dataType := range model.AllVerificationDataType {
+		entry, ok := registry.Lookup(dataType)
+		if !ok {
+			t.Errorf("data type %s is missing from the registry", dataType)
+			continue
+		}
+		if entry.Name == "" || entry.Provider == "" {
+			t.Errorf("data type %s must have a name and a provider", dataType)
+		}
+		if entry.SchemaVersion < 1 {
+			t.Errorf("data type %s must have a provider schema version", dataType)
+		}
+		if !entry.CostTier.IsValid() {
+			t.Errorf("data type %s has invalid cost tier %s", dataType, entry.CostTier)
+		}
+	}
+
+	if len(registry.All()) != len(model.AllVerificationDataType) {
+		t.Errorf("expected %d entries, but got %d", len(model.AllVerificationDataType), len(registry.All()))
+	}
+}
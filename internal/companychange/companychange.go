@@ -0,0 +1,248 @@
+// Package companychange находит существенные изменения данных компании между двумя проверками.
+// Документы поставщиков сравниваются по листовым полям JSON; какие поля существенны и насколько
+// должно измениться число, задают правила вида ТИП_ДАННЫХ[.путь.к.полю][:порог].
+package companychange
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/companyname"
+)
+
+// truncatedSuffix дописывается к фрагменту значения, обрезанному до лимита
+const truncatedSuffix = "..."
+
+// Rule существенное поле. Пустой Path - любое поле типа данных, иначе поле Path и вложенные в него.
+// Threshold - минимальное относительное изменение числа, 0 - любое изменение.
+type Rule struct {
+	DataType  model.VerificationDataType
+	Path      string
+	Threshold float64
+}
+
+// Change изменение поля документа. Before и After - JSON значения, null для появившегося или
+// исчезнувшего поля; значения длиннее лимита заменяются обрезанной строкой.
+type Change struct {
+	DataType model.VerificationDataType `json:"dataType"`
+	Field    string                     `json:"field"`
+	Before   json.RawMessage            `json:"before"`
+	After    json.RawMessage            `json:"after"`
+}
+
+// ParseRules разбирает правила вида BASIC_INFORMATION.address или ARBITRAGE_STATISTICS.totalAmount:0.1
+func ParseRules(specs []string) ([]Rule, error) {
+	var rules []Rule
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		var rule Rule
+		field, threshold, hasThreshold := strings.Cut(spec, ":")
+		if hasThreshold {
+			value, err := strconv.ParseFloat(threshold, 64)
+			if err != nil || value < 0 || math.IsInf(value, 0) {
+				return nil, fmt.Errorf("invalid change threshold in %q", spec)
+			}
+			rule.Threshold = value
+		}
+		dataType, path, _ := strings.Cut(field, ".")
+		rule.DataType = model.VerificationDataType(dataType)
+		if !rule.DataType.IsValid() {
+			return nil, fmt.Errorf("unknown data type %q in change rule %q", dataType, spec)
+		}
+		rule.Path = path
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Detector сравнивает данные проверок по правилам
+type Detector struct {
+	rules        []Rule
+	snippetBytes int
+}
+
+// NewDetector создает детектор. Без правил существенно любое изменение. snippetBytes ограничивает
+// длину значений до и после изменения, 0 - без ограничения.
+func NewDetector(rules []Rule, snippetBytes int) *Detector {
+	return &Detector{rules: rules, snippetBytes: snippetBytes}
+}
+
+// Compare возвращает существенные изменения данных current относительно previous. Сравниваются только
+// типы данных, доставленные в обеих проверках: недоставленный тип - отсутствие сведений, а не изменение.
+func (d *Detector) Compare(previous, current []*model.VerificationData) []Change {
+	before := make(map[model.VerificationDataType]string, len(previous))
+	for _, item := range previous {
+		before[item.DataType] = item.Data
+	}
+
+	var changes []Change
+	for _, item := range current {
+		old, ok := before[item.DataType]
+		if !ok {
+			continue
+		}
+		changes = append(changes, d.compareDocument(item.DataType, old, item.Data)...)
+	}
+	return changes
+}
+
+func (d *Detector) compareDocument(dataType model.VerificationDataType, previous, current string) []Change {
+	oldFields, newFields := map[string]any{}, map[string]any{}
+	if err := flatten(previous, oldFields); err != nil {
+		return d.wholeDocument(dataType, previous, current)
+	}
+	if err := flatten(current, newFields); err != nil {
+		return d.wholeDocument(dataType, previous, current)
+	}
+
+	paths := make([]string, 0, len(newFields))
+	for path := range newFields {
+		paths = append(paths, path)
+	}
+	for path := range oldFields {
+		if _, ok := newFields[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+
+	var changes []Change
+	for _, path := range paths {
+		before, after := oldFields[path], newFields[path]
+		rule, ok := d.match(dataType, path)
+		if !ok || !changed(dataType, path, before, after, rule.Threshold) {
+			continue
+		}
+		changes = append(changes, Change{DataType: dataType, Field: path, Before: d.snippet(before), After: d.snippet(after)})
+	}
+	return changes
+}
+
+// wholeDocument сравнивает документы, которые не удалось разобрать, как строки
+func (d *Detector) wholeDocument(dataType model.VerificationDataType, previous, current string) []Change {
+	if _, ok := d.match(dataType, ""); !ok || previous == current {
+		return nil
+	}
+	return []Change{{DataType: dataType, Before: d.snippet(previous), After: d.snippet(current)}}
+}
+
+// match возвращает самое точное правило поля: правило вложенного поля уточняет порог правила
+// для всего документа
+func (d *Detector) match(dataType model.VerificationDataType, path string) (Rule, bool) {
+	if len(d.rules) == 0 {
+		return Rule{}, true
+	}
+
+	var best Rule
+	found := false
+	for _, rule := range d.rules {
+		if rule.DataType != dataType || !coversPath(rule.Path, path) {
+			continue
+		}
+		if !found || len(rule.Path) > len(best.Path) {
+			best, found = rule, true
+		}
+	}
+	return best, found
+}
+
+func coversPath(rule, path string) bool {
+	if rule == "" || rule == path {
+		return true
+	}
+	return strings.HasPrefix(path, rule+".")
+}
+
+// changed сообщает о существенном изменении значения. Числа сравниваются с порогом относительно
+// прежнего значения, наименование компании - без учета написания.
+func changed(dataType model.VerificationDataType, path string, before, after any, threshold float64) bool {
+	if a, ok := before.(float64); ok {
+		if b, ok := after.(float64); ok {
+			if a == b {
+				return false
+			}
+			if a == 0 || threshold == 0 {
+				return true
+			}
+			return math.Abs(b-a)/math.Abs(a) >= threshold
+		}
+	}
+	if a, ok := before.(string); ok && dataType == model.VerificationDataTypeBasicInformation && path == "name" {
+		if b, ok := after.(string); ok {
+			return !companyname.Equal(a, b)
+		}
+	}
+	return !equalJSON(before, after)
+}
+
+func equalJSON(a, b any) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
+
+// snippet кодирует значение в JSON и обрезает его до лимита, обрезанное значение становится строкой
+func (d *Detector) snippet(value any) json.RawMessage {
+	if value == nil {
+		return json.RawMessage("null")
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	if d.snippetBytes <= 0 || len(encoded) <= d.snippetBytes {
+		return encoded
+	}
+
+	text := string(encoded)
+	if s, ok := value.(string); ok {
+		text = s
+	}
+	if len(text) <= d.snippetBytes {
+		return encoded
+	}
+	cut := d.snippetBytes
+	// Не разрезаем многобайтовый символ
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	truncated, _ := json.Marshal(text[:cut] + truncatedSuffix)
+	return truncated
+}
+
+// flatten раскладывает документ на листовые поля: объекты - через точку, массивы - целиком,
+// потому что у элементов списков поставщиков нет устойчивых индексов
+func flatten(document string, fields map[string]any) error {
+	var root any
+	if err := json.Unmarshal([]byte(document), &root); err != nil {
+		return err
+	}
+	walk("", root, fields)
+	return nil
+}
+
+func walk(prefix string, value any, fields map[string]any) {
+	object, ok := value.(map[string]any)
+	if !ok || len(object) == 0 {
+		fields[prefix] = value
+		return
+	}
+	for key, nested := range object {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		walk(path, nested, fields)
+	}
+}
@@ -0,0 +1,122 @@
+package companychange
+
+import (
+	"strings"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules([]string{"BASIC_INFORMATION.address", " ARBITRAGE_STATISTICS.totalAmount:0.1 ", "", "ACTIVITIES"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Rule{
+		{DataType: model.VerificationDataTypeBasicInformation, Path: "address"},
+		{DataType: model.VerificationDataTypeArbitrageStatistics, Path: "totalAmount", Threshold: 0.1},
+		{DataType: model.VerificationDataTypeActivities},
+	}
+	if len(rules) != len(expected) {
+		t.Fatalf("expected %d rules, but got %+v", len(expected), rules)
+	}
+	for i := range expected {
+		if rules[i] != expected[i] {
+			t.Errorf("expected rule %+v, but got %+v", expected[i], rules[i])
+		}
+	}
+
+	for _, spec := range []string{"UNKNOWN.field", "ACTIVITIES:-1", "ACTIVITIES:many"} {
+		if _, err := ParseRules([]string{spec}); err == nil {
+			t.Errorf("expected error for rule %q", spec)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	previous := []*model.VerificationData{
+		{DataType: model.VerificationDataTypeBasicInformation, Data: `{"name": "ООО \"Ромашка\"", "address": {"city": "Москва", "street": "Ленина"}, "capital": 10000, "okved": ["62.01"]}`},
+		{DataType: model.VerificationDataTypeArbitrageStatistics, Data: `{"totalAmount": 1000000, "cases": 3}`},
+		{DataType: model.VerificationDataTypeActivities, Data: `{"main": "62.01"}`},
+	}
+	current := []*model.VerificationData{
+		{DataType: model.VerificationDataTypeBasicInformation, Data: `{"name": "ООО «Ромашка»", "address": {"city": "Казань", "street": "Ленина"}, "capital": 10000.0, "okved": ["62.01", "62.02"], "director": "Иванов"}`},
+		{DataType: model.VerificationDataTypeArbitrageStatistics, Data: `{"totalAmount": 1050000, "cases": 4}`},
+		// Тип, которого нет в предыдущей проверке, не сравнивается
+		{DataType: model.VerificationDataTypeAffiliatedCompanies, Data: `{"count": 2}`},
+	}
+
+	tests := []struct {
+		name     string
+		rules    []string
+		expected []string
+	}{
+		{
+			name:     "all_fields",
+			expected: []string{"BASIC_INFORMATION address.city", "BASIC_INFORMATION director", "BASIC_INFORMATION okved", "ARBITRAGE_STATISTICS cases", "ARBITRAGE_STATISTICS totalAmount"},
+		},
+		{
+			name:     "sensitive_fields",
+			rules:    []string{"BASIC_INFORMATION.address", "BASIC_INFORMATION.name", "ARBITRAGE_STATISTICS.totalAmount:0.1"},
+			expected: []string{"BASIC_INFORMATION address.city"},
+		},
+		{
+			name:     "field_threshold_refines_document",
+			rules:    []string{"ARBITRAGE_STATISTICS", "ARBITRAGE_STATISTICS.totalAmount:0.05"},
+			expected: []string{"ARBITRAGE_STATISTICS cases", "ARBITRAGE_STATISTICS totalAmount"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ParseRules(tt.rules)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			changes := NewDetector(rules, 0).Compare(previous, current)
+			var got []string
+			for _, change := range changes {
+				got = append(got, string(change.DataType)+" "+change.Field)
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.expected, ", ") {
+				t.Errorf("expected changes %v, but got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompareSnippets(t *testing.T) {
+	previous := []*model.VerificationData{{DataType: model.VerificationDataTypeBasicInformation, Data: `{"address": "г. Москва, ул. Ленина", "director": "Петров"}`}}
+	current := []*model.VerificationData{{DataType: model.VerificationDataTypeBasicInformation, Data: `{"address": "г. Казань, ул. Баумана", "head": "Иванов"}`}}
+
+	changes := NewDetector(nil, 12).Compare(previous, current)
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, but got %+v", changes)
+	}
+
+	expected := []struct{ field, before, after string }{
+		{field: "address", before: `"г. Моск..."`, after: `"г. Каза..."`},
+		{field: "director", before: `"Петров"`, after: `null`},
+		{field: "head", before: `null`, after: `"Иванов"`},
+	}
+	for i, e := range expected {
+		change := changes[i]
+		if change.Field != e.field || string(change.Before) != e.before || string(change.After) != e.after {
+			t.Errorf("expected %s: %s -> %s, but got %s: %s -> %s", e.field, e.before, e.after, change.Field, change.Before, change.After)
+		}
+	}
+}
+
+func TestCompareInvalidDocument(t *testing.T) {
+	previous := []*model.VerificationData{{DataType: model.VerificationDataTypeActivities, Data: `not json`}}
+	current := []*model.VerificationData{{DataType: model.VerificationDataTypeActivities, Data: `still not json`}}
+
+	changes := NewDetector(nil, 0).Compare(previous, current)
+	if len(changes) != 1 || changes[0].Field != "" || string(changes[0].After) != `"still not json"` {
+		t.Errorf("expected the whole document to change, but got %+v", changes)
+	}
+	if changes := NewDetector(nil, 0).Compare(previous, previous); len(changes) != 0 {
+		t.Errorf("expected no changes, but got %+v", changes)
+	}
+}
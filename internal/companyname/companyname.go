@@ -0,0 +1,179 @@
+// Package companyname нормализует наименования компаний из BASIC_INFORMATION: убирает кавычки,
+// выделяет организационно-правовую форму и строит ключ сравнения, одинаковый для русского и
+// транслитерированного написания. Поиск по наименованию находит «ООО "Ромашка"» по запросу
+// "Romashka LLC", а сравнение данных не считает изменением замену кавычек или формы записи.
+package companyname
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+// LegalForm организационно-правовая форма в каноническом сокращении
+type LegalForm string
+
+const (
+	LegalFormLLC  LegalForm = "ООО"
+	LegalFormJSC  LegalForm = "АО"
+	LegalFormPJSC LegalForm = "ПАО"
+	LegalFormCJSC LegalForm = "ЗАО"
+	LegalFormOJSC LegalForm = "ОАО"
+	LegalFormIE   LegalForm = "ИП"
+)
+
+// legalForms написания форм в нижнем регистре: сокращения и полные названия на русском,
+// английские эквиваленты и транслитерация сокращений
+var legalForms = []struct {
+	form     LegalForm
+	spelling []string
+}{
+	{LegalFormLLC, []string{"ооо", "общество с ограниченной ответственностью", "llc", "ltd", "ooo", "limited liability company"}},
+	{LegalFormPJSC, []string{"пао", "публичное акционерное общество", "pjsc", "pao", "public joint stock company"}},
+	{LegalFormCJSC, []string{"зао", "закрытое акционерное общество", "cjsc", "zao", "closed joint stock company"}},
+	{LegalFormOJSC, []string{"оао", "открытое акционерное общество", "ojsc", "oao", "open joint stock company"}},
+	{LegalFormJSC, []string{"ао", "акционерное общество", "jsc", "ao", "joint stock company"}},
+	{LegalFormIE, []string{"ип", "индивидуальный предприниматель", "ie", "ip", "individual entrepreneur"}},
+}
+
+// quotes кавычки, которыми в реестрах и у поставщиков выделяют собственное наименование
+const quotes = `"'«»„“”‟‘’‚‛‹›` + "`"
+
+// Name разобранное наименование компании
+type Name struct {
+	// Form организационно-правовая форма, пустая, если в наименовании ее нет
+	Form LegalForm
+	// Title собственное наименование без кавычек и формы, с исходным регистром
+	Title string
+}
+
+// Parse выделяет в наименовании организационно-правовую форму в начале или в конце
+// и убирает кавычки и лишние пробелы
+func Parse(name string) Name {
+	words := strings.Fields(strings.Map(func(r rune) rune {
+		if strings.ContainsRune(quotes, r) {
+			return ' '
+		}
+		return r
+	}, name))
+
+	form, words := cutLegalForm(words)
+	return Name{Form: form, Title: strings.Trim(strings.Join(words, " "), " ,.;")}
+}
+
+// String возвращает наименование в каноническом виде: ООО «Ромашка»
+func (n Name) String() string {
+	if n.Form == "" {
+		return n.Title
+	}
+	if n.Title == "" {
+		return string(n.Form)
+	}
+	return string(n.Form) + " «" + n.Title + "»"
+}
+
+// Key возвращает ключ сравнения наименования: форма и собственное наименование латиницей в нижнем
+// регистре без знаков препинания. Написания, которые различаются только кавычками, регистром,
+// формой записи организационно-правовой формы или транслитерацией, дают один ключ.
+func (n Name) Key() string {
+	title := strings.Join(strings.FieldsFunc(fold(Transliterate(strings.ToLower(n.Title))), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+	if n.Form == "" {
+		return title
+	}
+	return strings.TrimSpace(Transliterate(strings.ToLower(string(n.Form))) + " " + title)
+}
+
+// Key возвращает ключ сравнения наименования name
+func Key(name string) string {
+	return Parse(name).Key()
+}
+
+// Equal сообщает, что наименования различаются только написанием
+func Equal(a, b string) bool {
+	return Key(a) == Key(b)
+}
+
+// FromBasicInformation возвращает наименование из документа BASIC_INFORMATION
+func FromBasicInformation(payload string) (string, bool) {
+	var document struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(payload), &document); err != nil || document.Name == "" {
+		return "", false
+	}
+	return document.Name, true
+}
+
+// cutLegalForm отделяет от слов наименования форму в начале, а если ее там нет - в конце.
+// Сначала проверяются более длинные написания, поэтому ПАО не принимается за АО.
+func cutLegalForm(words []string) (LegalForm, []string) {
+	lower := make([]string, len(words))
+	for i, word := range words {
+		lower[i] = strings.Trim(strings.ToLower(word), ",.;")
+	}
+
+	for _, atEnd := range []bool{false, true} {
+		for _, entry := range legalForms {
+			for _, spelling := range entry.spelling {
+				phrase := strings.Fields(spelling)
+				if len(phrase) >= len(words) {
+					continue
+				}
+				start := 0
+				if atEnd {
+					start = len(words) - len(phrase)
+				}
+				if equalWords(lower[start:start+len(phrase)], phrase) {
+					return entry.form, append(words[:start:start], words[start+len(phrase):]...)
+				}
+			}
+		}
+	}
+	return "", words
+}
+
+func equalWords(a, b []string) bool {
+	for i := range b {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// transliteration таблица транслитерации ICAO Doc 9303, по которой пишутся имена в загранпаспортах
+var transliteration = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "ie", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu", 'я': "ia",
+}
+
+// Transliterate записывает кириллицу латиницей по ICAO Doc 9303, остальные символы не меняются.
+// Заглавная буква остается заглавной: Щука - Shchuka.
+func Transliterate(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		latin, ok := transliteration[unicode.ToLower(r)]
+		switch {
+		case !ok:
+			b.WriteRune(r)
+		case unicode.IsUpper(r) && latin != "":
+			b.WriteString(strings.ToUpper(latin[:1]) + latin[1:])
+		default:
+			b.WriteString(latin)
+		}
+	}
+	return b.String()
+}
+
+// folding сводит распространенные варианты латинского написания к транслитерации ICAO:
+// Yandex и Яндекс, Khimprom и Himprom дают один ключ
+var folding = strings.NewReplacer("kh", "h", "x", "ks", "y", "i", "j", "i", "w", "v", "q", "k")
+
+func fold(s string) string {
+	return folding.Replace(s)
+}
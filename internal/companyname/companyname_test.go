@@ -0,0 +1,73 @@
+package companyname
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Name
+		display  string
+	}{
+		{name: "guillemets", input: "ООО «Ромашка»", expected: Name{Form: LegalFormLLC, Title: "Ромашка"}, display: "ООО «Ромашка»"},
+		{name: "straight_quotes", input: `ООО "Ромашка"`, expected: Name{Form: LegalFormLLC, Title: "Ромашка"}, display: "ООО «Ромашка»"},
+		{name: "nested_quotes", input: `«ООО "Ромашка"»`, expected: Name{Form: LegalFormLLC, Title: "Ромашка"}, display: "ООО «Ромашка»"},
+		{name: "full_form", input: "Общество с ограниченной ответственностью  „Ромашка-Плюс“", expected: Name{Form: LegalFormLLC, Title: "Ромашка-Плюс"}, display: "ООО «Ромашка-Плюс»"},
+		{name: "english_suffix", input: "Romashka LLC", expected: Name{Form: LegalFormLLC, Title: "Romashka"}, display: "ООО «Romashka»"},
+		{name: "suffix_after_comma", input: "Romashka, Ltd.", expected: Name{Form: LegalFormLLC, Title: "Romashka"}, display: "ООО «Romashka»"},
+		// ПАО не принимается за АО
+		{name: "longest_form", input: "ПАО «Сбербанк»", expected: Name{Form: LegalFormPJSC, Title: "Сбербанк"}, display: "ПАО «Сбербанк»"},
+		{name: "no_form", input: " Ромашка ", expected: Name{Title: "Ромашка"}, display: "Ромашка"},
+		{name: "form_only", input: "ООО", expected: Name{Title: "ООО"}, display: "ООО"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.input)
+			if got != tt.expected {
+				t.Errorf("expected %+v, but got %+v", tt.expected, got)
+			}
+			if got.String() != tt.display {
+				t.Errorf("expected display name %q, but got %q", tt.display, got.String())
+			}
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{a: `ООО "Ромашка"`, b: "ООО «Ромашка»", expected: true},
+		{a: "Romashka LLC", b: `«ООО "Ромашка"»`, expected: true},
+		{a: "ооо ромашка", b: "Общество с ограниченной ответственностью «РОМАШКА»", expected: true},
+		{a: "Yandex LLC", b: "ООО «Яндекс»", expected: true},
+		{a: "АО «Химпром»", b: "Khimprom JSC", expected: true},
+		{a: "ООО «Ёлка»", b: "ООО «Елка»", expected: true},
+		{a: "ООО «Ромашка»", b: "АО «Ромашка»", expected: false},
+		{a: "ООО «Ромашка»", b: "ООО «Ромашка-2»", expected: false},
+	}
+
+	for _, tt := range tests {
+		if got := Equal(tt.a, tt.b); got != tt.expected {
+			t.Errorf("Equal(%q, %q): expected %v, but got %v (keys %q and %q)", tt.a, tt.b, tt.expected, got, Key(tt.a), Key(tt.b))
+		}
+	}
+}
+
+func TestTransliterate(t *testing.T) {
+	if got := Transliterate("Щука и Ёж, ООО"); got != "Shchuka i Ezh, OOO" {
+		t.Errorf("unexpected transliteration %q", got)
+	}
+}
+
+func TestFromBasicInformation(t *testing.T) {
+	name, ok := FromBasicInformation(`{"name": "ООО \"Ромашка\"", "inn": "7707083893"}`)
+	if !ok || name != `ООО "Ромашка"` {
+		t.Errorf("expected name from payload, but got %q, %v", name, ok)
+	}
+	if _, ok := FromBasicInformation(`{"inn": "7707083893"}`); ok {
+		t.Error("expected no name in payload without name")
+	}
+}
@@ -1,22 +1,112 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	NATS     NATSConfig     `mapstructure:"nats"`
-	Log      LogConfig      `mapstructure:"log"`
+	Gateway           GatewayConfig           `mapstructure:"gateway"`
+	Server            ServerConfig            `mapstructure:"server"`
+	Database          DatabaseConfig          `mapstructure:"database"`
+	NATS              NATSConfig              `mapstructure:"nats"`
+	Log               LogConfig               `mapstructure:"log"`
+	Monitoring        MonitoringConfig        `mapstructure:"monitoring"`
+	Signing           SigningConfig           `mapstructure:"signing"`
+	Notifications     NotificationsConfig     `mapstructure:"notifications"`
+	Faults            FaultsConfig            `mapstructure:"faults"`
+	Reconciliation    ReconciliationConfig    `mapstructure:"reconciliation"`
+	SchemaGuard       SchemaGuardConfig       `mapstructure:"schema_guard"`
+	Statistics        StatisticsConfig        `mapstructure:"statistics"`
+	EventStore        EventStoreConfig        `mapstructure:"event_store"`
+	Outbox            OutboxConfig            `mapstructure:"outbox"`
+	Maintenance       MaintenanceConfig       `mapstructure:"maintenance"`
+	GraphQL           GraphQLConfig           `mapstructure:"graphql"`
+	Privacy           PrivacyConfig           `mapstructure:"privacy"`
+	Prefetch          PrefetchConfig          `mapstructure:"prefetch"`
+	Abuse             AbuseConfig             `mapstructure:"abuse"`
+	SLO               SLOConfig               `mapstructure:"slo"`
+	Sandbox           SandboxConfig           `mapstructure:"sandbox"`
+	NegativeCache     NegativeCacheConfig     `mapstructure:"negative_cache"`
+	Scoring           ScoringConfig           `mapstructure:"scoring"`
+	Users             UsersConfig             `mapstructure:"users"`
+	Vault             VaultConfig             `mapstructure:"vault"`
+	Warehouse         WarehouseConfig         `mapstructure:"warehouse"`
+	Webhooks          WebhooksConfig          `mapstructure:"webhooks"`
+	Cache             CacheConfig             `mapstructure:"cache"`
+	Subscriptions     SubscriptionsConfig     `mapstructure:"subscriptions"`
+	Usage             UsageConfig             `mapstructure:"usage"`
+	Amendments        AmendmentsConfig        `mapstructure:"amendments"`
+	Demo              DemoConfig              `mapstructure:"demo"`
+	Health            HealthConfig            `mapstructure:"health"`
+	Fixtures          FixturesConfig          `mapstructure:"provider_fixtures"`
+	Estimates         EstimatesConfig         `mapstructure:"estimates"`
+	EmailConfirmation EmailConfirmationConfig `mapstructure:"email_confirmation"`
+	SMTP              SMTPConfig              `mapstructure:"smtp"`
+	Payloads          PayloadsConfig          `mapstructure:"payloads"`
+	Reports           ReportsConfig           `mapstructure:"reports"`
+	DataTypes         DataTypesConfig         `mapstructure:"data_types"`
+	SchemaMigration   SchemaMigrationConfig   `mapstructure:"schema_migration"`
+	Freshness         FreshnessConfig         `mapstructure:"freshness"`
+	InboundEvents     InboundEventsConfig     `mapstructure:"inbound_events"`
+	Residency         ResidencyConfig         `mapstructure:"residency"`
+
+	vault *VaultClient
+}
+
+// VaultClient возвращает клиент Vault, через который получены секреты, или nil, если Vault не настроен
+func (c *Config) VaultClient() *VaultClient {
+	return c.vault
+}
+
+// Режимы запуска шлюза
+const (
+	// GatewayModeAll HTTP API и обработка событий NATS в одном процессе
+	GatewayModeAll = "all"
+	// GatewayModeAPI только HTTP API
+	GatewayModeAPI = "api"
+	// GatewayModeConsumer только обработка событий NATS и фоновые задачи, без HTTP-сервера
+	GatewayModeConsumer = "consumer"
+)
+
+type GatewayConfig struct {
+	Mode string `mapstructure:"mode"`
+	// Environment окружение экземпляра; в production команда migrate не откатывает миграции,
+	// удаляющие данные, без флага -force
+	Environment string `mapstructure:"environment"`
+}
+
+// EnvironmentProduction окружение по умолчанию
+const EnvironmentProduction = "production"
+
+// Production сообщает, работает ли экземпляр с production-базой
+func (c GatewayConfig) Production() bool {
+	return c.Environment == EnvironmentProduction
+}
+
+// ServesAPI сообщает, запускается ли HTTP-сервер
+func (c GatewayConfig) ServesAPI() bool {
+	return c.Mode != GatewayModeConsumer
+}
+
+// ConsumesEvents сообщает, обрабатываются ли уведомления NATS и фоновые задачи
+func (c GatewayConfig) ConsumesEvents() bool {
+	return c.Mode != GatewayModeAPI
 }
 
 type ServerConfig struct {
 	Port int    `mapstructure:"port"`
 	Host string `mapstructure:"host"`
+	// CORSAllowedOrigins источники браузерных клиентов, которым разрешены запросы; пусто - CORS выключен
+	CORSAllowedOrigins []string `mapstructure:"cors_allowed_origins"`
+	// TrustedProxyCIDRs сети прокси аутентификации: только с этих адресов принимаются заголовки X-User-Email и X-User-Roles
+	TrustedProxyCIDRs []string `mapstructure:"trusted_proxy_cidrs"`
+	// TrustedProxySecret общий секрет, которым прокси подтверждает заголовки в X-Auth-Proxy-Secret
+	TrustedProxySecret string `mapstructure:"trusted_proxy_secret"`
 }
 
 type DatabaseConfig struct {
@@ -26,17 +116,615 @@ type DatabaseConfig struct {
 	Password string `mapstructure:"password"`
 	DBName   string `mapstructure:"dbname"`
 	SSLMode  string `mapstructure:"sslmode"`
+	// StatementCacheCapacity размер кэша подготовленных запросов на соединение
+	StatementCacheCapacity int `mapstructure:"statement_cache_capacity"`
+	// ReplicaHost реплика, из которой читаются проверки, пока основная база недоступна.
+	// Пользователь, пароль и имя базы те же, порт без ReplicaPort тоже.
+	ReplicaHost string `mapstructure:"replica_host"`
+	ReplicaPort int    `mapstructure:"replica_port"`
+	// RetryAttempts сколько раз повторяются запросы после временных ошибок, 0 - без повторов
+	RetryAttempts  int           `mapstructure:"retry_attempts"`
+	RetryBaseDelay time.Duration `mapstructure:"retry_base_delay"`
+	RetryMaxDelay  time.Duration `mapstructure:"retry_max_delay"`
+	// BreakerEnabled прекращает обращения к базе, пока доля ошибок подключения за BreakerWindow
+	// не ниже BreakerFailureRate (при хотя бы BreakerMinRequests запросах), на BreakerOpenFor
+	BreakerEnabled     bool          `mapstructure:"breaker_enabled"`
+	BreakerWindow      time.Duration `mapstructure:"breaker_window"`
+	BreakerMinRequests int           `mapstructure:"breaker_min_requests"`
+	BreakerFailureRate float64       `mapstructure:"breaker_failure_rate"`
+	BreakerOpenFor     time.Duration `mapstructure:"breaker_open_for"`
+	// ReadCacheSize сколько последних прочитанных проверок хранится в памяти для ответа, пока
+	// недоступны и база, и реплика; 0 - без кэша
+	ReadCacheSize int           `mapstructure:"read_cache_size"`
+	ReadCacheTTL  time.Duration `mapstructure:"read_cache_ttl"`
 }
 
 type NATSConfig struct {
-	URL      string `mapstructure:"url"`
-	Cluster  string `mapstructure:"cluster"`
-	ClientID string `mapstructure:"client_id"`
+	// URL один адрес или список адресов серверов кластера через запятую
+	URL           string        `mapstructure:"url"`
+	Cluster       string        `mapstructure:"cluster"`
+	ClientID      string        `mapstructure:"client_id"`
+	Randomize     bool          `mapstructure:"randomize"`
+	NoEcho        bool          `mapstructure:"no_echo"`
+	InboxPrefix   string        `mapstructure:"inbox_prefix"`
+	MaxReconnects int           `mapstructure:"max_reconnects"`
+	ReconnectWait time.Duration `mapstructure:"reconnect_wait"`
+	// CompletedQueueGroup группа подписчиков на уведомления о завершении: каждое уведомление
+	// обрабатывает один экземпляр группы. Пусто - каждый экземпляр получает все уведомления.
+	CompletedQueueGroup string `mapstructure:"completed_queue_group"`
+	// PublishRate ограничение публикаций запросов на проверку в секунду на арендатора, 0 - без ограничения
+	PublishRate  float64 `mapstructure:"publish_rate"`
+	PublishBurst int     `mapstructure:"publish_burst"`
+	// TenantMaxInFlight лимит проверок арендатора, одновременно находящихся у воркеров; 0 - без ограничения.
+	// organizations.max_in_flight переопределяет его для отдельной организации.
+	TenantMaxInFlight          int           `mapstructure:"tenant_max_in_flight"`
+	ConcurrencyReleaseInterval time.Duration `mapstructure:"concurrency_release_interval"`
+	// CompletedDrainRate ограничение обработки уведомлений о завершении в секунду на экземпляр,
+	// 0 - без ограничения. Уведомления сверх него откладываются в поток JetStream CompletedParkingStream
+	// и обрабатываются с той же скоростью.
+	CompletedDrainRate     float64 `mapstructure:"completed_drain_rate"`
+	CompletedDrainBurst    int     `mapstructure:"completed_drain_burst"`
+	CompletedParkingStream string  `mapstructure:"completed_parking_stream"`
+	// CompletedShards число обработчиков уведомлений о завершении: уведомления одной проверки
+	// применяются одним обработчиком по порядку. 0 - уведомления обрабатываются при получении.
+	CompletedShards     int `mapstructure:"completed_shards"`
+	CompletedShardQueue int `mapstructure:"completed_shard_queue"`
+	// QueuedAdmission показывает отложенные запросы со статусом PENDING и позицией в очереди
+	QueuedAdmission bool `mapstructure:"queued_admission"`
+	// MultiTenant включает отдельные subject и учетные данные арендаторов из таблицы organizations
+	MultiTenant          bool          `mapstructure:"multi_tenant"`
+	RouteRefreshInterval time.Duration `mapstructure:"route_refresh_interval"`
+	// WorkerDispatch распределяет фоновые запросы по персональным subject воркеров с учетом их очереди
+	WorkerDispatch     bool          `mapstructure:"worker_dispatch"`
+	WorkerHeartbeatTTL time.Duration `mapstructure:"worker_heartbeat_ttl"`
+	// DegradedMode поведение при недоступном брокере: off, queue или fail
+	DegradedMode string `mapstructure:"degraded_mode"`
+	// Envelope формат публикуемых сообщений: none или cloudevents
+	Envelope string `mapstructure:"envelope"`
+	// EventSource атрибут source конвертов CloudEvents
+	EventSource string `mapstructure:"event_source"`
+}
+
+// Форматы публикуемых сообщений NATS. Полученные сообщения принимаются в обоих форматах.
+const (
+	// NATSEnvelopeNone тело сообщения - JSON сообщения без конверта
+	NATSEnvelopeNone = "none"
+	// NATSEnvelopeCloudEvents сообщение оборачивается в конверт CloudEvents 1.0 в структурированном режиме JSON
+	NATSEnvelopeCloudEvents = "cloudevents"
+)
+
+// Режимы работы без подключения к NATS
+const (
+	// NATSDegradedOff без подключения при запуске шлюз не стартует
+	NATSDegradedOff = "off"
+	// NATSDegradedQueue запросы на проверку сохраняются в outbox до подключения
+	NATSDegradedQueue = "queue"
+	// NATSDegradedFail запросы на проверку отклоняются с кодом UPSTREAM_UNAVAILABLE
+	NATSDegradedFail = "fail"
+)
+
+// Servers возвращает список адресов серверов NATS
+func (c NATSConfig) Servers() []string {
+	var servers []string
+	for _, server := range strings.Split(c.URL, ",") {
+		if server = strings.TrimSpace(server); server != "" {
+			servers = append(servers, server)
+		}
+	}
+	return servers
 }
 
 type LogConfig struct {
-	Level string `mapstructure:"level"`
-	JSON  bool   `mapstructure:"json"`
+	Level  string          `mapstructure:"level"`
+	JSON   bool            `mapstructure:"json"`
+	Access AccessLogConfig `mapstructure:"access"`
+}
+
+// AccessLogConfig настройки журнала HTTP-запросов
+type AccessLogConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Format формат записей: json (поля zap) или common (строка в стиле Common Log Format)
+	Format        string `mapstructure:"format"`
+	ExcludeHealth bool   `mapstructure:"exclude_health"`
+}
+
+// MonitoringConfig настройки периодических повторных проверок компаний
+type MonitoringConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Interval     time.Duration `mapstructure:"interval"`
+	RecheckAfter time.Duration `mapstructure:"recheck_after"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	// MaxHighPriorityShare ограничивает долю проверок в пачке, отправляемых в приоритетную очередь
+	MaxHighPriorityShare float64 `mapstructure:"max_high_priority_share"`
+	AuthorEmail          string  `mapstructure:"author_email"`
+	// ChangeFields существенные для вебхуков company.changed поля: ТИП_ДАННЫХ[.путь][:порог],
+	// пустой список - любое изменение данных
+	ChangeFields []string `mapstructure:"change_fields"`
+	// ChangeSnippetBytes ограничение длины значений до и после изменения в вебхуке
+	ChangeSnippetBytes int `mapstructure:"change_snippet_bytes"`
+}
+
+// NotificationsConfig настройки уведомлений пользователей
+type NotificationsConfig struct {
+	// SLA время, за которое проверка должна завершиться, иначе автор получает уведомление
+	SLA              time.Duration `mapstructure:"sla"`
+	SLACheckInterval time.Duration `mapstructure:"sla_check_interval"`
+	// DeliveryInterval как часто доставляются задания уведомлений о завершении проверок
+	DeliveryInterval  time.Duration `mapstructure:"delivery_interval"`
+	DeliveryBatchSize int           `mapstructure:"delivery_batch_size"`
+	// DeliveryClaimTimeout время, через которое задание может забрать другой экземпляр; больше таймаута вебхуков
+	DeliveryClaimTimeout time.Duration `mapstructure:"delivery_claim_timeout"`
+	// ReadyTimeout через сколько задания доставляются без обработки завершения шлюзом,
+	// например если сообщение о завершении потеряно или экземпляр упал
+	ReadyTimeout time.Duration `mapstructure:"ready_timeout"`
+	// MaxAttempts после стольких неудачных попыток задание считается недоставленным
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// RetryBackoff пауза перед второй попыткой, дальше она удваивается до MaxRetryBackoff
+	RetryBackoff    time.Duration `mapstructure:"retry_backoff"`
+	MaxRetryBackoff time.Duration `mapstructure:"max_retry_backoff"`
+}
+
+// VaultConfig подключение к HashiCorp Vault для секретов вида vault:<путь>#<поле>
+type VaultConfig struct {
+	Addr    string        `mapstructure:"addr"`
+	Token   string        `mapstructure:"token"`
+	Timeout time.Duration `mapstructure:"timeout"`
+	// RenewInterval как часто продлевать токен и аренды секретов, должен быть меньше их срока
+	RenewInterval time.Duration `mapstructure:"renew_interval"`
+}
+
+// Приемники выгрузки в аналитическое хранилище
+const (
+	WarehouseSinkKafka = "kafka"
+	WarehouseSinkS3    = "s3"
+)
+
+// WarehouseConfig выгрузка потока изменений проверок в аналитическое хранилище
+type WarehouseConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Sink приемник: kafka (через Kafka REST Proxy) или s3 (пачки JSON Lines в gzip)
+	Sink      string        `mapstructure:"sink"`
+	Interval  time.Duration `mapstructure:"interval"`
+	BatchSize int           `mapstructure:"batch_size"`
+	// SettleDelay возраст изменения, после которого оно выгружается: к этому времени
+	// зафиксированы транзакции с меньшими номерами
+	SettleDelay time.Duration        `mapstructure:"settle_delay"`
+	Kafka       WarehouseKafkaConfig `mapstructure:"kafka"`
+	S3          S3Config             `mapstructure:"s3"`
+}
+
+type WarehouseKafkaConfig struct {
+	RestURL string        `mapstructure:"rest_url"`
+	Topic   string        `mapstructure:"topic"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// S3Config подключение к S3-совместимому объектному хранилищу
+type S3Config struct {
+	Endpoint        string        `mapstructure:"endpoint"`
+	Region          string        `mapstructure:"region"`
+	Bucket          string        `mapstructure:"bucket"`
+	Prefix          string        `mapstructure:"prefix"`
+	AccessKeyID     string        `mapstructure:"access_key_id"`
+	SecretAccessKey string        `mapstructure:"secret_access_key"`
+	Timeout         time.Duration `mapstructure:"timeout"`
+}
+
+// WebhooksConfig доставка уведомлений о завершении проверок на зарегистрированные вебхуки
+type WebhooksConfig struct {
+	Timeout time.Duration `mapstructure:"timeout"`
+	// Source атрибут source конвертов CloudEvents
+	Source string `mapstructure:"source"`
+}
+
+// CacheConfig кэш данных компаний, разделенный по арендаторам
+type CacheConfig struct {
+	// ShareableDataTypes типы данных публичных реестров, которые договоры позволяют отдавать
+	// всем арендаторам; совместное использование включает администратор
+	ShareableDataTypes []string `mapstructure:"shareable_data_types"`
+}
+
+// SigningConfig ключ Ed25519 (seed в base64), которым шлюз подписывает выгружаемые документы
+type SigningConfig struct {
+	Key string `mapstructure:"key"`
+	// Verifications подписывать итоги завершенных проверок, требует Key
+	Verifications bool `mapstructure:"verifications"`
+}
+
+// ReconciliationConfig настройки повторного запроса недоставленных типов данных
+type ReconciliationConfig struct {
+	AutoRetry bool `mapstructure:"auto_retry"`
+	// RetryDelay пауза после частичного завершения перед повторным запросом
+	RetryDelay    time.Duration `mapstructure:"retry_delay"`
+	MaxRetries    int           `mapstructure:"max_retries"`
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+	BatchSize     int           `mapstructure:"batch_size"`
+}
+
+// EventStoreConfig включает режим хранения событий: каждое изменение проверки записывается
+// неизменяемым событием, а таблица verifications поддерживается как модель чтения
+type EventStoreConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// OutboxConfig настройки отправки публикаций, отложенных ограничением скорости
+type OutboxConfig struct {
+	RelayInterval time.Duration `mapstructure:"relay_interval"`
+	BatchSize     int           `mapstructure:"batch_size"`
+	// ClaimTimeout время, через которое неотправленное сообщение может забрать другой экземпляр
+	ClaimTimeout time.Duration `mapstructure:"claim_timeout"`
+}
+
+// AmendmentsConfig публикация поправок - данных, доставленных после завершения проверки
+type AmendmentsConfig struct {
+	RelayInterval time.Duration `mapstructure:"relay_interval"`
+	BatchSize     int           `mapstructure:"batch_size"`
+	// ClaimTimeout время, через которое неопубликованную поправку может забрать другой экземпляр
+	ClaimTimeout time.Duration `mapstructure:"claim_timeout"`
+}
+
+// StatisticsConfig настройки предварительно агрегированной статистики
+type StatisticsConfig struct {
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+}
+
+// SchemaGuardConfig поведение при ломающих изменениях GraphQL-схемы без повышения версии:
+// fail - не запускаться, warn - только предупредить, off - не проверять
+type SchemaGuardConfig struct {
+	Mode string `mapstructure:"mode"`
+}
+
+// MaintenanceConfig режим обслуживания: мутации отклоняются, запросы на чтение работают
+type MaintenanceConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// DrainTimeout сколько ждать завершения начатых публикаций при включении режима
+	DrainTimeout time.Duration `mapstructure:"drain_timeout"`
+}
+
+// PrivacyConfig обезличивание email авторов проверок после срока хранения
+type PrivacyConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	AnonymizeAfter time.Duration `mapstructure:"anonymize_after"`
+	// Salt соль хэша email. Должна быть одинаковой на всех экземплярах и не меняться,
+	// иначе один и тот же автор получит разные псевдонимы.
+	Salt      string        `mapstructure:"salt"`
+	Interval  time.Duration `mapstructure:"interval"`
+	BatchSize int           `mapstructure:"batch_size"`
+}
+
+// PrefetchConfig заблаговременное обновление данных часто запрашиваемых компаний
+type PrefetchConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+	TopN     int           `mapstructure:"top_n"`
+	// Window период, за который считаются чтения компаний
+	Window time.Duration `mapstructure:"window"`
+	// RefreshAfter возраст последней проверки, после которого данные обновляются
+	RefreshAfter time.Duration `mapstructure:"refresh_after"`
+	// MinLatency компании, данные которых приходят быстрее, не обновляются заранее
+	MinLatency time.Duration `mapstructure:"min_latency"`
+	// OffPeakStartHour и OffPeakEndHour часы низкой нагрузки [start, end) в TimeZone
+	OffPeakStartHour int           `mapstructure:"off_peak_start_hour"`
+	OffPeakEndHour   int           `mapstructure:"off_peak_end_hour"`
+	TimeZone         string        `mapstructure:"time_zone"`
+	AuthorEmail      string        `mapstructure:"author_email"`
+	FlushInterval    time.Duration `mapstructure:"flush_interval"`
+}
+
+// AbuseConfig временная блокировка клиентов, присылающих некорректные запросы
+type AbuseConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Threshold количество ошибок за Window, после которого клиент блокируется
+	Threshold int           `mapstructure:"threshold"`
+	Window    time.Duration `mapstructure:"window"`
+	// BaseBlock длительность первой блокировки, каждая следующая вдвое длиннее (не более MaxBlock)
+	BaseBlock time.Duration `mapstructure:"base_block"`
+	MaxBlock  time.Duration `mapstructure:"max_block"`
+	// ResetAfter время без блокировок, после которого длительность блокировки снова равна BaseBlock
+	ResetAfter      time.Duration `mapstructure:"reset_after"`
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+}
+
+// SLOConfig показатели уровня обслуживания за скользящие окна
+type SLOConfig struct {
+	Windows        []time.Duration `mapstructure:"windows"`
+	UpdateInterval time.Duration   `mapstructure:"update_interval"`
+}
+
+// SandboxConfig режим песочницы: ключи и организации с флагом sandbox получают синтетические данные
+type SandboxConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// FixturesConfig запись и воспроизведение ответов поставщиков для интеграционных тестов
+type FixturesConfig struct {
+	// Mode off, record - обезличенные данные завершенных проверок сохраняются в Dir, или replay -
+	// запросы на проверку завершаются данными из Dir без обращения к воркерам
+	Mode string `mapstructure:"mode"`
+	Dir  string `mapstructure:"dir"`
+	// Salt соль псевдонимов записи. Без нее соль случайная, и одна компания в разных запусках
+	// получает разные псевдонимы
+	Salt string `mapstructure:"salt"`
+}
+
+// Режимы записи и воспроизведения ответов поставщиков
+const (
+	FixturesModeOff    = "off"
+	FixturesModeRecord = "record"
+	FixturesModeReplay = "replay"
+)
+
+// NegativeCacheConfig кэш ИНН, которые поставщики не нашли. Пока запись не истекла,
+// новые проверки такого ИНН сразу завершаются статусом COMPANY_NOT_FOUND без обращения к поставщикам.
+type NegativeCacheConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	TTL             time.Duration `mapstructure:"ttl"`
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+}
+
+// EstimatesConfig оценка времени завершения проверок по задержкам типов данных и очередям воркеров
+type EstimatesConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Window период, за который учитываются задержки завершенных проверок
+	Window time.Duration `mapstructure:"window"`
+	// Percentile доля проверок, которые должны уложиться в оценку: 0.9 - девять из десяти
+	Percentile      float64       `mapstructure:"percentile"`
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+	BatchSize       int           `mapstructure:"batch_size"`
+}
+
+// EmailConfirmationConfig подтверждение email автора перед первой проверкой. Запросы пользователей,
+// от имени которых еще не было проверок, удерживаются, пока автор не перейдет по ссылке из письма.
+type EmailConfirmationConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ConfirmURL адрес подтверждения в письме, к нему добавляется параметр token
+	ConfirmURL string        `mapstructure:"confirm_url"`
+	TokenTTL   time.Duration `mapstructure:"token_ttl"`
+	// ResendAfter не отправлять автору новое письмо раньше этого срока
+	ResendAfter time.Duration `mapstructure:"resend_after"`
+	// HoldTTL через сколько удаляются запросы, автор которых так и не подтвердил email
+	HoldTTL         time.Duration `mapstructure:"hold_ttl"`
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+}
+
+// PayloadsConfig проверка данных поставщиков при поступлении: кодировка и размер
+type PayloadsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxBytes предел размера данных типа, для которого не задан свой, 0 - без предела
+	MaxBytes int `mapstructure:"max_bytes"`
+	// MaxBytesByType пределы по типам данных вида ТИП_ДАННЫХ=байт
+	MaxBytesByType []string `mapstructure:"max_bytes_by_type"`
+	// Storage хранилище данных больше предела. Без endpoint такие данные отклоняются.
+	Storage S3Config `mapstructure:"storage"`
+}
+
+// ReportsConfig асинхронное формирование отчетов по делам: задания в базе, результаты в объектном
+// хранилище, скачивание по ссылкам с ограниченным сроком действия
+type ReportsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Workers сколько отчетов экземпляр формирует одновременно
+	Workers  int           `mapstructure:"workers"`
+	Interval time.Duration `mapstructure:"interval"`
+	// MaxRunningPerTenant сколько отчетов организации формируется одновременно на всех экземплярах
+	MaxRunningPerTenant int `mapstructure:"max_running_per_tenant"`
+	// MaxQueuedPerTenant сколько незавершенных заданий может быть у организации
+	MaxQueuedPerTenant int `mapstructure:"max_queued_per_tenant"`
+	// ClaimTimeout через сколько задание экземпляра, переставшего сообщать о ходе, забирается снова
+	ClaimTimeout time.Duration `mapstructure:"claim_timeout"`
+	// ResultTTL сколько хранится готовый отчет
+	ResultTTL       time.Duration `mapstructure:"result_ttl"`
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+	// LinkTTL срок действия ссылки на скачивание, не больше 7 суток
+	LinkTTL time.Duration `mapstructure:"link_ttl"`
+	Storage S3Config      `mapstructure:"storage"`
+}
+
+// DataTypesConfig типы данных, объявленные без изменения кода шлюза
+type DataTypesConfig struct {
+	// CustomDir каталог с описаниями типов данных в файлах *.json: имя, subject воркеров,
+	// JSON Schema и ценовая категория. Пусто - только встроенные типы.
+	CustomDir string `mapstructure:"custom_dir"`
+}
+
+// Этапы переноса проверок в следующую версию схемы (verifications_v2)
+const (
+	// SchemaMigrationOff новая схема не используется
+	SchemaMigrationOff = "off"
+	// SchemaMigrationDualWrite записи повторяются в новой схеме, чтение из старой
+	SchemaMigrationDualWrite = "dual_write"
+	// SchemaMigrationShadowRead как dual_write, и часть чтений сравнивается с новой схемой
+	SchemaMigrationShadowRead = "shadow_read"
+	// SchemaMigrationCutover проверки по id читаются из новой схемы, записи по-прежнему в обе
+	SchemaMigrationCutover = "cutover"
+)
+
+// SchemaMigrationConfig перенос проверок в новую версию схемы без остановки шлюза
+type SchemaMigrationConfig struct {
+	Mode string `mapstructure:"mode"`
+	// SyncInterval и BatchSize задача, копирующая изменения воркеров и исправляющая расхождения
+	SyncInterval time.Duration `mapstructure:"sync_interval"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	// SettleDelay возраст изменения, после которого оно копируется
+	SettleDelay time.Duration `mapstructure:"settle_delay"`
+	// CompareRate доля чтений, которые в режиме shadow_read сравниваются с новой схемой
+	CompareRate float64 `mapstructure:"compare_rate"`
+}
+
+// FreshnessConfig выдача данных компаний из кэша по срокам свежести типов данных
+type FreshnessConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// FreshFor и StaleFor сроки свежести типов без отдельной настройки: свежие данные отдаются
+	// сразу, устаревшие - сразу с фоновым обновлением
+	FreshFor time.Duration `mapstructure:"fresh_for"`
+	StaleFor time.Duration `mapstructure:"stale_for"`
+	// ByType сроки отдельных типов в формате ТИП=свежие/устаревшие, например ARBITRAGE_STATISTICS=15m/6h
+	ByType []string `mapstructure:"by_type"`
+	// RefreshCooldown минимальный промежуток между фоновыми обновлениями данных одной компании
+	RefreshCooldown time.Duration `mapstructure:"refresh_cooldown"`
+	// RefreshAuthor имя автора фоновых проверок, домен берется у арендатора
+	RefreshAuthor string `mapstructure:"refresh_author"`
+}
+
+// Действия с отклоненными уведомлениями о завершении
+const (
+	InboundEventsDiscard = "discard"
+	InboundEventsPark    = "park"
+)
+
+// InboundEventsConfig проверка уведомлений о завершении перед обработкой: повторы, устаревшие и
+// обогнанные более поздним статусом уведомления отклоняются
+type InboundEventsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxAge возраст уведомления, после которого оно отклоняется; столько же хранятся отметки
+	// о полученных уведомлениях для распознавания повторов
+	MaxAge time.Duration `mapstructure:"max_age"`
+	// MaxClockSkew насколько время уведомления может опережать часы шлюза
+	MaxClockSkew time.Duration `mapstructure:"max_clock_skew"`
+	// Action что делать с отклоненным уведомлением: discard - отбросить, park - сохранить для разбора
+	Action string `mapstructure:"action"`
+	// ParkedRetention срок хранения сохраненных уведомлений
+	ParkedRetention time.Duration `mapstructure:"parked_retention"`
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+}
+
+// SMTPConfig почтовый сервер для писем шлюза. Без Host письма только пишутся в журнал.
+// ResidencyConfig хранение проверок арендаторов, закрепленных за регионом в organizations.storage_region,
+// в базе и бакете этого региона
+type ResidencyConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// HomeRegion регион основной базы и хранилища payloads.storage
+	HomeRegion string `mapstructure:"home_region"`
+	// Databases базы остальных регионов в формате РЕГИОН=DSN
+	Databases []string `mapstructure:"databases"`
+	// PayloadBuckets бакеты данных больше предела в формате РЕГИОН=бакет на endpoint payloads.storage;
+	// в регионе без бакета такие данные отклоняются
+	PayloadBuckets []string `mapstructure:"payload_buckets"`
+	// RefreshInterval интервал перечитывания регионов организаций
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+}
+
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+}
+
+// DemoConfig демонстрационная организация для playground тестового окружения
+type DemoConfig struct {
+	// Enabled включает ежедневное обновление демонстрационных проверок и подставляет ключ в playground
+	Enabled      bool   `mapstructure:"enabled"`
+	Organization string `mapstructure:"organization"`
+	// APIKey ключ только на чтение, который команда seed регистрирует за организацией
+	APIKey          string        `mapstructure:"api_key"`
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+}
+
+// ScoringConfig пересчет оценок проверок по текущим правилам скоринга
+type ScoringConfig struct {
+	// RecalculationInterval интервал отправки очередной пачки запросов на пересчет
+	RecalculationInterval  time.Duration `mapstructure:"recalculation_interval"`
+	RecalculationBatchSize int           `mapstructure:"recalculation_batch_size"`
+}
+
+// UsersConfig учетные записи пользователей организаций: роли из базы и отключение доступа
+type UsersConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// SubscriptionsConfig подключения WebSocket для GraphQL-подписок
+type SubscriptionsConfig struct {
+	// JWTSecret секрет HS256 токенов, передаваемых в Authorization при открытии подключения.
+	// Пустой - токены не принимаются, остаются ключ API и заголовки прокси.
+	JWTSecret string `mapstructure:"jwt_secret"`
+	// InitTimeout сколько ждать connection_init после открытия подключения
+	InitTimeout time.Duration `mapstructure:"init_timeout"`
+	// KeepAliveInterval период сообщений keepalive протокола graphql-ws и комментариев keep-alive
+	// потока событий проверки
+	KeepAliveInterval time.Duration `mapstructure:"keepalive_interval"`
+	// PingInterval период ping протокола graphql-transport-ws; клиент, не ответивший
+	// за два периода, отключается. 0 - ping не отправляется.
+	PingInterval time.Duration `mapstructure:"ping_interval"`
+	// MaxPerUser одновременных подписок одного пользователя, 0 - без ограничения
+	MaxPerUser int `mapstructure:"max_per_user"`
+	// Broadcast как экземпляры шлюза сообщают друг другу о событиях подписок
+	Broadcast string `mapstructure:"broadcast"`
+	// NotifyChannel канал LISTEN/NOTIFY при Broadcast = postgres
+	NotifyChannel string `mapstructure:"notify_channel"`
+	// EventsPollInterval как часто поток событий проверки /verifications/{id}/events перечитывает
+	// ее хронологию
+	EventsPollInterval time.Duration `mapstructure:"events_poll_interval"`
+}
+
+const (
+	// SubscriptionsBroadcastNone события подписок доходят только до подписчиков экземпляра, который их обработал
+	SubscriptionsBroadcastNone = "none"
+	// SubscriptionsBroadcastPostgres события подписок рассылаются всем экземплярам через LISTEN/NOTIFY общей базы
+	SubscriptionsBroadcastPostgres = "postgres"
+)
+
+// UsageConfig учет использования API клиентами
+type UsageConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// FlushInterval период сохранения накопленных счетчиков в базу
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	// Retention срок хранения почасовых счетчиков и наибольший период отчетов
+	Retention time.Duration `mapstructure:"retention"`
+	// TopOperations сколько самых частых операций показывать для клиента
+	TopOperations int `mapstructure:"top_operations"`
+}
+
+// GraphQLConfig настройки обработки GraphQL-запросов
+type GraphQLConfig struct {
+	Limits GraphQLLimitsConfig `mapstructure:"limits"`
+	// PersistedOperations проверка операций сервисных аккаунтов по списку разрешенных: off, report или enforce
+	PersistedOperations string `mapstructure:"persisted_operations"`
+	// ExportMaxRows наибольшее число проверок в одной подписке verificationExport
+	ExportMaxRows int `mapstructure:"export_max_rows"`
+	// ExportSendTimeout сколько выгрузка ждет, пока клиент примет очередную проверку
+	ExportSendTimeout time.Duration `mapstructure:"export_send_timeout"`
+	// MaxCompletionWait наибольшее время, которое createVerification(waitForCompletion: true) ждет завершения
+	MaxCompletionWait time.Duration `mapstructure:"max_completion_wait"`
+}
+
+// Режимы проверки операций сервисных аккаунтов
+const (
+	PersistedOperationsOff = "off"
+	// PersistedOperationsReport незарегистрированные операции выполняются, но попадают в журнал и метрики
+	PersistedOperationsReport = "report"
+	// PersistedOperationsEnforce незарегистрированные операции отклоняются
+	PersistedOperationsEnforce = "enforce"
+)
+
+// GraphQLLimitsConfig ограничения запросов к /query, 0 - без ограничения
+type GraphQLLimitsConfig struct {
+	MaxAliases    int `mapstructure:"max_aliases"`
+	MaxRootFields int `mapstructure:"max_root_fields"`
+	// MaxOperations количество операций в одном документе
+	MaxOperations     int `mapstructure:"max_operations"`
+	MaxVariablesBytes int `mapstructure:"max_variables_bytes"`
+}
+
+// HealthConfig настройки ответов /livez и /readyz в формате application/health+json
+type HealthConfig struct {
+	// Verbosity подробность ответа: status, checks или full
+	Verbosity string `mapstructure:"verbosity"`
+}
+
+// Подробность ответов проверок состояния
+const (
+	// HealthVerbosityStatus только общий статус и версия
+	HealthVerbosityStatus = "status"
+	// HealthVerbosityChecks статус и наблюдаемые значения каждой проверки
+	HealthVerbosityChecks = "checks"
+	// HealthVerbosityFull дополнительно адреса компонентов и тексты ошибок
+	HealthVerbosityFull = "full"
+)
+
+// FaultsConfig включает внедрение сбоев через /admin/faults. Не включать в production.
+type FaultsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
 }
 
 func Load() (*Config, error) {
@@ -44,23 +732,416 @@ func Load() (*Config, error) {
 	viper.AutomaticEnv()
 
 	// Set default values
+	viper.SetDefault("gateway.mode", GatewayModeAll)
+	viper.SetDefault("gateway.environment", EnvironmentProduction)
 	viper.SetDefault("server.host", "0.0.0.0")
 	viper.SetDefault("server.port", 8080)
+	viper.SetDefault("server.cors_allowed_origins", "")
+	viper.SetDefault("server.trusted_proxy_cidrs", "")
+	viper.SetDefault("server.trusted_proxy_secret", "")
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
 	viper.SetDefault("database.user", "postgres")
 	viper.SetDefault("database.password", "postgres")
 	viper.SetDefault("database.dbname", "scoring")
 	viper.SetDefault("database.sslmode", "disable")
+	viper.SetDefault("database.statement_cache_capacity", 512)
+	viper.SetDefault("database.replica_host", "")
+	viper.SetDefault("database.replica_port", 0)
+	viper.SetDefault("database.retry_attempts", 2)
+	viper.SetDefault("database.retry_base_delay", "20ms")
+	viper.SetDefault("database.retry_max_delay", "500ms")
+	viper.SetDefault("database.breaker_enabled", false)
+	viper.SetDefault("database.breaker_window", "10s")
+	viper.SetDefault("database.breaker_min_requests", 20)
+	viper.SetDefault("database.breaker_failure_rate", 0.5)
+	viper.SetDefault("database.breaker_open_for", "5s")
+	viper.SetDefault("database.read_cache_size", 0)
+	viper.SetDefault("database.read_cache_ttl", "5m")
 	viper.SetDefault("nats.url", "nats://localhost:4222")
+	viper.SetDefault("nats.randomize", true)
+	viper.SetDefault("nats.no_echo", true)
+	viper.SetDefault("nats.inbox_prefix", "")
+	viper.SetDefault("nats.completed_queue_group", "")
+	viper.SetDefault("nats.max_reconnects", -1)
+	viper.SetDefault("nats.reconnect_wait", "2s")
+	viper.SetDefault("nats.publish_rate", 0)
+	viper.SetDefault("nats.publish_burst", 50)
+	viper.SetDefault("nats.tenant_max_in_flight", 0)
+	viper.SetDefault("nats.concurrency_release_interval", "5s")
+	viper.SetDefault("nats.queued_admission", false)
+	viper.SetDefault("nats.completed_drain_rate", 0)
+	viper.SetDefault("nats.completed_drain_burst", 50)
+	viper.SetDefault("nats.completed_parking_stream", "VERIFICATION_COMPLETED_PARKED")
+	viper.SetDefault("nats.completed_shards", 0)
+	viper.SetDefault("nats.completed_shard_queue", 100)
+	viper.SetDefault("nats.multi_tenant", false)
+	viper.SetDefault("nats.route_refresh_interval", "1m")
+	viper.SetDefault("nats.worker_dispatch", false)
+	viper.SetDefault("nats.worker_heartbeat_ttl", "30s")
+	viper.SetDefault("nats.degraded_mode", NATSDegradedOff)
+	viper.SetDefault("nats.envelope", NATSEnvelopeNone)
+	viper.SetDefault("nats.event_source", "/scoring-api-gateway")
 	viper.SetDefault("log.level", "info")
 	viper.SetDefault("log.json", false)
+	viper.SetDefault("log.access.enabled", true)
+	viper.SetDefault("log.access.format", "json")
+	viper.SetDefault("log.access.exclude_health", false)
+	viper.SetDefault("monitoring.enabled", false)
+	viper.SetDefault("monitoring.interval", "1h")
+	viper.SetDefault("monitoring.recheck_after", "720h")
+	viper.SetDefault("monitoring.batch_size", 100)
+	viper.SetDefault("monitoring.max_high_priority_share", 0.3)
+	viper.SetDefault("monitoring.author_email", "monitoring@scoring.local")
+	viper.SetDefault("monitoring.change_fields", "")
+	viper.SetDefault("monitoring.change_snippet_bytes", 512)
+	viper.SetDefault("signing.key", "")
+	viper.SetDefault("signing.verifications", false)
+	viper.SetDefault("notifications.sla", "30m")
+	viper.SetDefault("notifications.sla_check_interval", "5m")
+	viper.SetDefault("notifications.delivery_interval", "2s")
+	viper.SetDefault("notifications.delivery_batch_size", 100)
+	viper.SetDefault("notifications.delivery_claim_timeout", "1m")
+	viper.SetDefault("notifications.ready_timeout", "1m")
+	viper.SetDefault("notifications.max_attempts", 10)
+	viper.SetDefault("notifications.retry_backoff", "30s")
+	viper.SetDefault("notifications.max_retry_backoff", "1h")
+	viper.SetDefault("faults.enabled", false)
+	viper.SetDefault("maintenance.enabled", false)
+	viper.SetDefault("maintenance.drain_timeout", "30s")
+	viper.SetDefault("schema_guard.mode", "fail")
+	viper.SetDefault("graphql.limits.max_aliases", 30)
+	viper.SetDefault("graphql.limits.max_root_fields", 20)
+	viper.SetDefault("graphql.limits.max_operations", 5)
+	viper.SetDefault("graphql.limits.max_variables_bytes", 65536)
+	viper.SetDefault("graphql.persisted_operations", PersistedOperationsOff)
+	viper.SetDefault("graphql.export_max_rows", 10000)
+	viper.SetDefault("graphql.export_send_timeout", "1m")
+	viper.SetDefault("graphql.max_completion_wait", "60s")
+	viper.SetDefault("statistics.refresh_interval", "15m")
+	viper.SetDefault("event_store.enabled", false)
+	viper.SetDefault("outbox.relay_interval", "1s")
+	viper.SetDefault("outbox.batch_size", 100)
+	viper.SetDefault("outbox.claim_timeout", "30s")
+	viper.SetDefault("amendments.relay_interval", "10s")
+	viper.SetDefault("amendments.batch_size", 100)
+	viper.SetDefault("amendments.claim_timeout", "30s")
+	viper.SetDefault("privacy.enabled", false)
+	viper.SetDefault("privacy.anonymize_after", "8760h")
+	viper.SetDefault("privacy.salt", "")
+	viper.SetDefault("privacy.interval", "1h")
+	viper.SetDefault("privacy.batch_size", 500)
+	viper.SetDefault("abuse.enabled", false)
+	viper.SetDefault("abuse.threshold", 20)
+	viper.SetDefault("abuse.window", "1m")
+	viper.SetDefault("abuse.base_block", "1m")
+	viper.SetDefault("abuse.max_block", "1h")
+	viper.SetDefault("abuse.reset_after", "24h")
+	viper.SetDefault("abuse.cleanup_interval", "5m")
+	viper.SetDefault("slo.windows", "5m,1h,24h")
+	viper.SetDefault("slo.update_interval", "30s")
+	viper.SetDefault("sandbox.enabled", false)
+	viper.SetDefault("negative_cache.enabled", false)
+	viper.SetDefault("negative_cache.ttl", "1h")
+	viper.SetDefault("negative_cache.cleanup_interval", "1h")
+	viper.SetDefault("estimates.enabled", true)
+	viper.SetDefault("estimates.window", "168h")
+	viper.SetDefault("estimates.percentile", 0.9)
+	viper.SetDefault("estimates.refresh_interval", "30s")
+	viper.SetDefault("estimates.batch_size", 500)
+	viper.SetDefault("email_confirmation.enabled", false)
+	viper.SetDefault("email_confirmation.confirm_url", "http://localhost:8080/confirm-email")
+	viper.SetDefault("email_confirmation.token_ttl", "24h")
+	viper.SetDefault("email_confirmation.resend_after", "10m")
+	viper.SetDefault("email_confirmation.hold_ttl", "168h")
+	viper.SetDefault("email_confirmation.cleanup_interval", "1h")
+	viper.SetDefault("payloads.enabled", true)
+	viper.SetDefault("payloads.max_bytes", 5<<20)
+	viper.SetDefault("payloads.max_bytes_by_type", "")
+	viper.SetDefault("payloads.storage.endpoint", "")
+	viper.SetDefault("payloads.storage.region", "us-east-1")
+	viper.SetDefault("payloads.storage.bucket", "")
+	viper.SetDefault("payloads.storage.prefix", "payloads")
+	viper.SetDefault("payloads.storage.access_key_id", "")
+	viper.SetDefault("payloads.storage.secret_access_key", "")
+	viper.SetDefault("payloads.storage.timeout", "30s")
+	viper.SetDefault("reports.enabled", false)
+	viper.SetDefault("reports.workers", 2)
+	viper.SetDefault("reports.interval", "5s")
+	viper.SetDefault("reports.max_running_per_tenant", 2)
+	viper.SetDefault("reports.max_queued_per_tenant", 20)
+	viper.SetDefault("reports.claim_timeout", "5m")
+	viper.SetDefault("reports.result_ttl", "72h")
+	viper.SetDefault("reports.cleanup_interval", "1h")
+	viper.SetDefault("reports.link_ttl", "15m")
+	viper.SetDefault("reports.storage.endpoint", "")
+	viper.SetDefault("reports.storage.region", "us-east-1")
+	viper.SetDefault("reports.storage.bucket", "")
+	viper.SetDefault("reports.storage.prefix", "reports")
+	viper.SetDefault("reports.storage.access_key_id", "")
+	viper.SetDefault("reports.storage.secret_access_key", "")
+	viper.SetDefault("reports.storage.timeout", "30s")
+	viper.SetDefault("data_types.custom_dir", "")
+	viper.SetDefault("schema_migration.mode", SchemaMigrationOff)
+	viper.SetDefault("schema_migration.sync_interval", "30s")
+	viper.SetDefault("schema_migration.batch_size", 500)
+	viper.SetDefault("schema_migration.settle_delay", "5s")
+	viper.SetDefault("schema_migration.compare_rate", 0.01)
+	viper.SetDefault("freshness.enabled", false)
+	viper.SetDefault("freshness.fresh_for", "1h")
+	viper.SetDefault("freshness.stale_for", "24h")
+	viper.SetDefault("freshness.by_type", "")
+	viper.SetDefault("freshness.refresh_cooldown", "10m")
+	viper.SetDefault("freshness.refresh_author", "cache-refresh")
+	viper.SetDefault("inbound_events.enabled", false)
+	viper.SetDefault("inbound_events.max_age", "24h")
+	viper.SetDefault("inbound_events.max_clock_skew", "1m")
+	viper.SetDefault("inbound_events.action", InboundEventsPark)
+	viper.SetDefault("inbound_events.parked_retention", "720h")
+	viper.SetDefault("inbound_events.cleanup_interval", "1h")
+	viper.SetDefault("residency.enabled", false)
+	viper.SetDefault("residency.home_region", "")
+	viper.SetDefault("residency.databases", "")
+	viper.SetDefault("residency.payload_buckets", "")
+	viper.SetDefault("residency.refresh_interval", "1m")
+	viper.SetDefault("smtp.host", "")
+	viper.SetDefault("smtp.port", 587)
+	viper.SetDefault("smtp.username", "")
+	viper.SetDefault("smtp.password", "")
+	viper.SetDefault("smtp.from", "scoring-gateway@localhost")
+	viper.SetDefault("demo.enabled", false)
+	viper.SetDefault("demo.organization", "demo.scoring.local")
+	viper.SetDefault("demo.api_key", "")
+	viper.SetDefault("demo.refresh_interval", "24h")
+	viper.SetDefault("provider_fixtures.mode", FixturesModeOff)
+	viper.SetDefault("provider_fixtures.dir", "testdata/provider_fixtures")
+	viper.SetDefault("provider_fixtures.salt", "")
+	viper.SetDefault("health.verbosity", HealthVerbosityChecks)
+	viper.SetDefault("scoring.recalculation_interval", "10s")
+	viper.SetDefault("scoring.recalculation_batch_size", 500)
+	viper.SetDefault("users.enabled", false)
+	viper.SetDefault("prefetch.enabled", false)
+	viper.SetDefault("prefetch.interval", "15m")
+	viper.SetDefault("prefetch.top_n", 50)
+	viper.SetDefault("prefetch.window", "168h")
+	viper.SetDefault("prefetch.refresh_after", "24h")
+	viper.SetDefault("prefetch.min_latency", "30s")
+	viper.SetDefault("prefetch.off_peak_start_hour", 1)
+	viper.SetDefault("prefetch.off_peak_end_hour", 6)
+	viper.SetDefault("prefetch.time_zone", "UTC")
+	viper.SetDefault("prefetch.author_email", "prefetch@scoring.local")
+	viper.SetDefault("prefetch.flush_interval", "1m")
+	viper.SetDefault("reconciliation.auto_retry", false)
+	viper.SetDefault("reconciliation.retry_delay", "15m")
+	viper.SetDefault("reconciliation.max_retries", 1)
+	viper.SetDefault("reconciliation.check_interval", "1m")
+	viper.SetDefault("reconciliation.batch_size", 100)
+	viper.SetDefault("vault.addr", "")
+	viper.SetDefault("vault.token", "")
+	viper.SetDefault("vault.timeout", "10s")
+	viper.SetDefault("vault.renew_interval", "1h")
+	viper.SetDefault("warehouse.enabled", false)
+	viper.SetDefault("warehouse.sink", WarehouseSinkKafka)
+	viper.SetDefault("warehouse.interval", "30s")
+	viper.SetDefault("warehouse.batch_size", 1000)
+	viper.SetDefault("warehouse.settle_delay", "5s")
+	viper.SetDefault("warehouse.kafka.rest_url", "")
+	viper.SetDefault("warehouse.kafka.topic", "scoring.verifications")
+	viper.SetDefault("warehouse.kafka.timeout", "10s")
+	viper.SetDefault("warehouse.s3.endpoint", "")
+	viper.SetDefault("warehouse.s3.region", "us-east-1")
+	viper.SetDefault("warehouse.s3.bucket", "")
+	viper.SetDefault("warehouse.s3.prefix", "verifications")
+	viper.SetDefault("warehouse.s3.access_key_id", "")
+	viper.SetDefault("warehouse.s3.secret_access_key", "")
+	viper.SetDefault("warehouse.s3.timeout", "30s")
+	viper.SetDefault("webhooks.timeout", "10s")
+	viper.SetDefault("webhooks.source", "/scoring-api-gateway")
+	viper.SetDefault("cache.shareable_data_types", "")
+	viper.SetDefault("subscriptions.jwt_secret", "")
+	viper.SetDefault("subscriptions.init_timeout", "10s")
+	viper.SetDefault("subscriptions.keepalive_interval", "10s")
+	viper.SetDefault("subscriptions.ping_interval", "0s")
+	viper.SetDefault("subscriptions.max_per_user", 10)
+	viper.SetDefault("subscriptions.broadcast", SubscriptionsBroadcastNone)
+	viper.SetDefault("subscriptions.notify_channel", "scoring_gateway_subscriptions")
+	viper.SetDefault("subscriptions.events_poll_interval", "2s")
+	viper.SetDefault("usage.enabled", true)
+	viper.SetDefault("usage.flush_interval", "1m")
+	viper.SetDefault("usage.retention", "720h")
+	viper.SetDefault("usage.top_operations", 5)
 
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if err := config.resolveSecrets(context.Background()); err != nil {
+		return nil, err
+	}
+
+	switch config.Gateway.Mode {
+	case GatewayModeAll, GatewayModeAPI, GatewayModeConsumer:
+	default:
+		return nil, fmt.Errorf("unknown gateway mode %q", config.Gateway.Mode)
+	}
+
+	if config.Notifications.MaxAttempts < 1 {
+		return nil, fmt.Errorf("notifications max_attempts must be at least 1, got %d", config.Notifications.MaxAttempts)
+	}
+
+	switch config.GraphQL.PersistedOperations {
+	case PersistedOperationsOff, PersistedOperationsReport, PersistedOperationsEnforce:
+	default:
+		return nil, fmt.Errorf("unknown persisted operations mode %q", config.GraphQL.PersistedOperations)
+	}
+
+	switch config.NATS.DegradedMode {
+	case NATSDegradedOff, NATSDegradedQueue, NATSDegradedFail:
+	default:
+		return nil, fmt.Errorf("unknown nats degraded mode %q", config.NATS.DegradedMode)
+	}
+
+	switch config.Health.Verbosity {
+	case HealthVerbosityStatus, HealthVerbosityChecks, HealthVerbosityFull:
+	default:
+		return nil, fmt.Errorf("unknown health verbosity %q", config.Health.Verbosity)
+	}
+
+	switch config.NATS.Envelope {
+	case NATSEnvelopeNone, NATSEnvelopeCloudEvents:
+	default:
+		return nil, fmt.Errorf("unknown nats envelope %q", config.NATS.Envelope)
+	}
+
+	switch config.Subscriptions.Broadcast {
+	case SubscriptionsBroadcastNone:
+	case SubscriptionsBroadcastPostgres:
+		if config.Subscriptions.NotifyChannel == "" {
+			return nil, fmt.Errorf("subscriptions postgres broadcast requires a notify channel")
+		}
+	default:
+		return nil, fmt.Errorf("unknown subscriptions broadcast %q", config.Subscriptions.Broadcast)
+	}
+	if config.Subscriptions.EventsPollInterval <= 0 {
+		return nil, fmt.Errorf("subscriptions events poll interval must be positive")
+	}
+
+	switch config.Fixtures.Mode {
+	case FixturesModeOff, FixturesModeRecord:
+	case FixturesModeReplay:
+		// Воспроизведение завершает проверки записанными данными вместо данных поставщиков
+		if config.Gateway.Production() {
+			return nil, fmt.Errorf("provider fixtures replay cannot run in production")
+		}
+	default:
+		return nil, fmt.Errorf("unknown provider fixtures mode %q", config.Fixtures.Mode)
+	}
+
+	if config.Payloads.MaxBytes < 0 {
+		return nil, fmt.Errorf("payloads max bytes must not be negative")
+	}
+	if config.Payloads.Storage.Endpoint != "" && config.Payloads.Storage.Bucket == "" {
+		return nil, fmt.Errorf("payloads storage requires a bucket")
+	}
+
+	if config.Reports.Enabled {
+		if config.Reports.Storage.Endpoint == "" || config.Reports.Storage.Bucket == "" {
+			return nil, fmt.Errorf("reports storage requires endpoint and bucket")
+		}
+		if config.Reports.Workers <= 0 || config.Reports.MaxRunningPerTenant <= 0 || config.Reports.MaxQueuedPerTenant <= 0 {
+			return nil, fmt.Errorf("reports workers and per-tenant limits must be positive")
+		}
+		if config.Reports.LinkTTL <= 0 || config.Reports.LinkTTL > 7*24*time.Hour {
+			return nil, fmt.Errorf("reports link ttl must be between 1s and 168h, got %s", config.Reports.LinkTTL)
+		}
+	}
+
+	if config.Warehouse.Enabled {
+		switch config.Warehouse.Sink {
+		case WarehouseSinkKafka:
+			if config.Warehouse.Kafka.RestURL == "" || config.Warehouse.Kafka.Topic == "" {
+				return nil, fmt.Errorf("warehouse kafka sink requires rest_url and topic")
+			}
+		case WarehouseSinkS3:
+			if config.Warehouse.S3.Endpoint == "" || config.Warehouse.S3.Bucket == "" {
+				return nil, fmt.Errorf("warehouse s3 sink requires endpoint and bucket")
+			}
+		default:
+			return nil, fmt.Errorf("unknown warehouse sink %q", config.Warehouse.Sink)
+		}
+	}
+
+	switch config.SchemaMigration.Mode {
+	case SchemaMigrationOff:
+	case SchemaMigrationDualWrite, SchemaMigrationShadowRead, SchemaMigrationCutover:
+		if config.SchemaMigration.BatchSize <= 0 || config.SchemaMigration.SyncInterval <= 0 {
+			return nil, fmt.Errorf("schema migration requires a positive batch_size and sync_interval")
+		}
+		if config.SchemaMigration.CompareRate < 0 || config.SchemaMigration.CompareRate > 1 {
+			return nil, fmt.Errorf("schema migration compare_rate must be between 0 and 1")
+		}
+	default:
+		return nil, fmt.Errorf("unknown schema migration mode %q", config.SchemaMigration.Mode)
+	}
+
+	if config.Freshness.Enabled {
+		if config.Freshness.FreshFor < 0 || config.Freshness.StaleFor < config.Freshness.FreshFor {
+			return nil, fmt.Errorf("freshness stale_for must not be shorter than fresh_for")
+		}
+		if config.Freshness.RefreshCooldown <= 0 || config.Freshness.RefreshAuthor == "" {
+			return nil, fmt.Errorf("freshness requires a positive refresh_cooldown and a refresh_author")
+		}
+	}
+
+	if config.Database.RetryAttempts < 0 || config.Database.RetryBaseDelay <= 0 || config.Database.RetryMaxDelay < config.Database.RetryBaseDelay {
+		return nil, fmt.Errorf("database retries require a non-negative retry_attempts and 0 < retry_base_delay <= retry_max_delay")
+	}
+	if config.Database.BreakerEnabled {
+		if config.Database.BreakerWindow <= 0 || config.Database.BreakerOpenFor <= 0 || config.Database.BreakerMinRequests <= 0 {
+			return nil, fmt.Errorf("database breaker requires a positive breaker_window, breaker_open_for and breaker_min_requests")
+		}
+		if config.Database.BreakerFailureRate <= 0 || config.Database.BreakerFailureRate > 1 {
+			return nil, fmt.Errorf("database breaker_failure_rate must be in (0, 1]")
+		}
+	}
+	if config.Database.ReadCacheSize < 0 || (config.Database.ReadCacheSize > 0 && config.Database.ReadCacheTTL <= 0) {
+		return nil, fmt.Errorf("database read cache requires a non-negative read_cache_size and a positive read_cache_ttl")
+	}
+
+	if config.InboundEvents.Enabled {
+		if config.InboundEvents.MaxAge <= 0 || config.InboundEvents.MaxClockSkew < 0 {
+			return nil, fmt.Errorf("inbound events require a positive max_age and a non-negative max_clock_skew")
+		}
+		switch config.InboundEvents.Action {
+		case InboundEventsDiscard, InboundEventsPark:
+		default:
+			return nil, fmt.Errorf("unknown inbound events action %q", config.InboundEvents.Action)
+		}
+		if config.InboundEvents.ParkedRetention <= 0 || config.InboundEvents.CleanupInterval <= 0 {
+			return nil, fmt.Errorf("inbound events require a positive parked_retention and cleanup_interval")
+		}
+	}
+
+	if config.NATS.CompletedShards < 0 || config.NATS.CompletedShardQueue < 0 {
+		return nil, fmt.Errorf("nats completed_shards and completed_shard_queue must not be negative")
+	}
+
+	if config.Residency.Enabled {
+		if config.Residency.HomeRegion == "" || config.Residency.RefreshInterval <= 0 {
+			return nil, fmt.Errorf("residency requires a home_region and a positive refresh_interval")
+		}
+		// Эти режимы сами сохраняют проверки и данные компаний в основной базе
+		if config.Sandbox.Enabled || config.Freshness.Enabled || config.NegativeCache.Enabled {
+			return nil, fmt.Errorf("residency cannot be combined with sandbox, freshness or negative cache")
+		}
+	}
+
+	// Распределение по воркерам публикует в общие subject и несовместимо с маршрутами арендаторов
+	if config.NATS.WorkerDispatch && config.NATS.MultiTenant {
+		return nil, fmt.Errorf("nats worker dispatch cannot be combined with multi-tenant routing")
+	}
+
 	return &config, nil
 }
 
@@ -68,3 +1149,16 @@ func (c *Config) DatabaseDSN() string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		c.Database.Host, c.Database.Port, c.Database.User, c.Database.Password, c.Database.DBName, c.Database.SSLMode)
 }
+
+// ReplicaDSN строка подключения к реплике или пустая строка, если реплика не задана
+func (c *Config) ReplicaDSN() string {
+	if c.Database.ReplicaHost == "" {
+		return ""
+	}
+	port := c.Database.ReplicaPort
+	if port == 0 {
+		port = c.Database.Port
+	}
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		c.Database.ReplicaHost, port, c.Database.User, c.Database.Password, c.Database.DBName, c.Database.SSLMode)
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestLoad(t *testing.T) {
@@ -452,3 +454,214 @@ func TestBooleanConfiguration(t *testing.T) {
 		})
 	}
 }
+
+func TestNATSServers(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected []string
+	}{
+		{
+			name:     "single_server",
+			url:      "nats://localhost:4222",
+			expected: []string{"nats://localhost:4222"},
+		},
+		{
+			name:     "cluster",
+			url:      "nats://nats-1:4222, nats://nats-2:4222,nats://nats-3:4222",
+			expected: []string{"nats://nats-1:4222", "nats://nats-2:4222", "nats://nats-3:4222"},
+		},
+		{
+			name:     "empty_entries_ignored",
+			url:      "nats://nats-1:4222,,",
+			expected: []string{"nats://nats-1:4222"},
+		},
+		{
+			name:     "empty",
+			url:      "",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			servers := NATSConfig{URL: tt.url}.Servers()
+
+			if len(servers) != len(tt.expected) {
+				t.Fatalf("expected servers %v, but got %v", tt.expected, servers)
+			}
+			for i := range servers {
+				if servers[i] != tt.expected[i] {
+					t.Errorf("expected servers %v, but got %v", tt.expected, servers)
+				}
+			}
+		})
+	}
+}
+
+func TestGatewayMode(t *testing.T) {
+	originalMode := os.Getenv("GATEWAY_MODE")
+	defer func() {
+		if originalMode == "" {
+			os.Unsetenv("GATEWAY_MODE")
+		} else {
+			os.Setenv("GATEWAY_MODE", originalMode)
+		}
+	}()
+
+	tests := []struct {
+		mode           string
+		expectedAPI    bool
+		expectedEvents bool
+		expectedError  bool
+	}{
+		{mode: "", expectedAPI: true, expectedEvents: true},
+		{mode: GatewayModeAPI, expectedAPI: true, expectedEvents: false},
+		{mode: GatewayModeConsumer, expectedAPI: false, expectedEvents: true},
+		{mode: "worker", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run("mode_"+tt.mode, func(t *testing.T) {
+			if tt.mode == "" {
+				os.Unsetenv("GATEWAY_MODE")
+			} else {
+				os.Setenv("GATEWAY_MODE", tt.mode)
+			}
+
+			config, err := Load()
+			if tt.expectedError {
+				if err == nil {
+					t.Error("expected error for unknown gateway mode, but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if config.Gateway.ServesAPI() != tt.expectedAPI {
+				t.Errorf("expected ServesAPI %t, but got %t", tt.expectedAPI, config.Gateway.ServesAPI())
+			}
+			if config.Gateway.ConsumesEvents() != tt.expectedEvents {
+				t.Errorf("expected ConsumesEvents %t, but got %t", tt.expectedEvents, config.Gateway.ConsumesEvents())
+			}
+		})
+	}
+}
+
+func TestSLOWindows(t *testing.T) {
+	original := os.Getenv("SLO_WINDOWS")
+	defer func() {
+		if original == "" {
+			os.Unsetenv("SLO_WINDOWS")
+		} else {
+			os.Setenv("SLO_WINDOWS", original)
+		}
+	}()
+
+	tests := []struct {
+		env      string
+		expected []time.Duration
+	}{
+		{env: "", expected: []time.Duration{5 * time.Minute, time.Hour, 24 * time.Hour}},
+		{env: "10m,6h", expected: []time.Duration{10 * time.Minute, 6 * time.Hour}},
+	}
+
+	for _, tt := range tests {
+		t.Run("windows_"+tt.env, func(t *testing.T) {
+			if tt.env == "" {
+				os.Unsetenv("SLO_WINDOWS")
+			} else {
+				os.Setenv("SLO_WINDOWS", tt.env)
+			}
+
+			config, err := Load()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(config.SLO.Windows, tt.expected) {
+				t.Errorf("expected windows %v, but got %v", tt.expected, config.SLO.Windows)
+			}
+		})
+	}
+}
+
+func TestSubscriptionsBroadcast(t *testing.T) {
+	tests := []struct {
+		name            string
+		broadcast       string
+		channel         string
+		expectedChannel string
+		expectedError   string
+	}{
+		{name: "default", expectedChannel: "scoring_gateway_subscriptions"},
+		{name: "postgres", broadcast: SubscriptionsBroadcastPostgres, channel: "gateway_events", expectedChannel: "gateway_events"},
+		{name: "unknown", broadcast: "redis", expectedError: `unknown subscriptions broadcast "redis"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.broadcast != "" {
+				t.Setenv("SUBSCRIPTIONS_BROADCAST", tt.broadcast)
+			}
+			if tt.channel != "" {
+				t.Setenv("SUBSCRIPTIONS_NOTIFY_CHANNEL", tt.channel)
+			}
+
+			config, err := Load()
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Errorf("expected error %q, but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if config.Subscriptions.NotifyChannel != tt.expectedChannel {
+				t.Errorf("expected channel %q, but got %q", tt.expectedChannel, config.Subscriptions.NotifyChannel)
+			}
+		})
+	}
+}
+
+func TestFixturesMode(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          string
+		environment   string
+		expectedError string
+	}{
+		{name: "default"},
+		{name: "record_in_production", mode: FixturesModeRecord},
+		{name: "replay_in_staging", mode: FixturesModeReplay, environment: "staging"},
+		{name: "replay_in_production", mode: FixturesModeReplay, expectedError: "provider fixtures replay cannot run in production"},
+		{name: "unknown", mode: "proxy", expectedError: `unknown provider fixtures mode "proxy"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mode != "" {
+				t.Setenv("PROVIDER_FIXTURES_MODE", tt.mode)
+			}
+			if tt.environment != "" {
+				t.Setenv("GATEWAY_ENVIRONMENT", tt.environment)
+			}
+
+			config, err := Load()
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Errorf("expected error %q, but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if config.Fixtures.Dir != "testdata/provider_fixtures" {
+				t.Errorf("expected default fixtures dir, but got %q", config.Fixtures.Dir)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// secretFields поля конфигурации с секретами по ключам. Значение каждого можно передать:
+//   - напрямую в переменной окружения (DATABASE_PASSWORD);
+//   - путем к файлу в переменной с суффиксом _FILE (DATABASE_PASSWORD_FILE), как монтируются
+//     секреты Docker и Kubernetes;
+//   - ссылкой на секрет Vault вида vault:<путь>#<поле> (DATABASE_PASSWORD=vault:secret/data/gateway#db_password).
+//
+// Новые секреты (S3, подписи вебхуков) достаточно добавить сюда.
+func (c *Config) secretFields() map[string]*string {
+	return map[string]*string{
+		"database.password":                  &c.Database.Password,
+		"demo.api_key":                       &c.Demo.APIKey,
+		"payloads.storage.secret_access_key": &c.Payloads.Storage.SecretAccessKey,
+		"reports.storage.secret_access_key":  &c.Reports.Storage.SecretAccessKey,
+		"server.trusted_proxy_secret":        &c.Server.TrustedProxySecret,
+		"signing.key":                        &c.Signing.Key,
+		"smtp.password":                      &c.SMTP.Password,
+		"subscriptions.jwt_secret":           &c.Subscriptions.JWTSecret,
+		"warehouse.s3.secret_access_key":     &c.Warehouse.S3.SecretAccessKey,
+	}
+}
+
+// vaultRefPrefix префикс значения, которое нужно прочитать из Vault
+const vaultRefPrefix = "vault:"
+
+// envName возвращает переменную окружения ключа конфигурации
+func envName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// resolveFileSecret подставляет значение из файла, указанного в <ENV>_FILE.
+// Значение секрета не попадает в текст ошибок.
+func resolveFileSecret(key string, field *string) error {
+	env := envName(key)
+	path := os.Getenv(env + "_FILE")
+	if path == "" {
+		return nil
+	}
+	if os.Getenv(env) != "" {
+		return fmt.Errorf("both %s and %s_FILE are set", env, env)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s_FILE: %w", env, err)
+	}
+	*field = strings.TrimRight(string(content), "\r\n")
+	return nil
+}
+
+// resolveSecrets подставляет значения секретов из файлов и Vault. Клиент Vault создается,
+// только если задан VAULT_ADDR, и сохраняется для продления аренды секретов.
+func (c *Config) resolveSecrets(ctx context.Context) error {
+	if err := resolveFileSecret("vault.token", &c.Vault.Token); err != nil {
+		return err
+	}
+
+	fields := c.secretFields()
+	for key, field := range fields {
+		if err := resolveFileSecret(key, field); err != nil {
+			return err
+		}
+	}
+
+	if c.Vault.Addr != "" {
+		c.vault = NewVaultClient(c.Vault.Addr, c.Vault.Token, c.Vault.Timeout)
+	}
+
+	for key, field := range fields {
+		ref, ok := strings.CutPrefix(*field, vaultRefPrefix)
+		if !ok {
+			continue
+		}
+		if c.vault == nil {
+			return fmt.Errorf("%s refers to vault, but VAULT_ADDR is not set", envName(key))
+		}
+
+		value, err := c.vault.Secret(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", envName(key), err)
+		}
+		*field = value
+	}
+
+	return nil
+}
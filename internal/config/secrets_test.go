@@ -0,0 +1,137 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveSecretsFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db_password")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("file", func(t *testing.T) {
+		t.Setenv("DATABASE_PASSWORD", "")
+		t.Setenv("DATABASE_PASSWORD_FILE", path)
+
+		config := &Config{Database: DatabaseConfig{Password: "postgres"}}
+		if err := config.resolveSecrets(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if config.Database.Password != "s3cret" {
+			t.Errorf("expected password from file, but got %q", config.Database.Password)
+		}
+	})
+
+	t.Run("both_set", func(t *testing.T) {
+		t.Setenv("DATABASE_PASSWORD", "inline")
+		t.Setenv("DATABASE_PASSWORD_FILE", path)
+
+		config := &Config{}
+		err := config.resolveSecrets(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "both DATABASE_PASSWORD and DATABASE_PASSWORD_FILE") {
+			t.Errorf("expected conflict error, but got %v", err)
+		}
+	})
+
+	t.Run("missing_file", func(t *testing.T) {
+		t.Setenv("DATABASE_PASSWORD", "")
+		t.Setenv("DATABASE_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+
+		if err := (&Config{}).resolveSecrets(context.Background()); err == nil {
+			t.Error("expected error for missing file")
+		}
+	})
+}
+
+func TestResolveSecretsFromVault(t *testing.T) {
+	var renewedLeases []string
+	var tokenRenewed bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/gateway":
+			json.NewEncoder(w).Encode(map[string]any{
+				"data": map[string]any{
+					"data":     map[string]any{"signing_key": "c2VlZA=="},
+					"metadata": map[string]any{"version": 3},
+				},
+			})
+		case "/v1/database/creds/gateway":
+			json.NewEncoder(w).Encode(map[string]any{
+				"lease_id":       "database/creds/gateway/abc",
+				"renewable":      true,
+				"lease_duration": 3600,
+				"data":           map[string]any{"username": "v-gateway", "password": "dynamic"},
+			})
+		case "/v1/auth/token/lookup-self":
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"renewable": true}})
+		case "/v1/auth/token/renew-self":
+			tokenRenewed = true
+			w.WriteHeader(http.StatusOK)
+		case "/v1/sys/leases/renew":
+			var body struct {
+				LeaseID string `json:"lease_id"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			renewedLeases = append(renewedLeases, body.LeaseID)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Database: DatabaseConfig{Password: "vault:database/creds/gateway#password"},
+		Signing:  SigningConfig{Key: "vault:secret/data/gateway#signing_key"},
+		Vault:    VaultConfig{Addr: server.URL, Token: "root-token", Timeout: time.Second},
+	}
+	if err := config.resolveSecrets(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Database.Password != "dynamic" {
+		t.Errorf("expected dynamic password, but got %q", config.Database.Password)
+	}
+	if config.Signing.Key != "c2VlZA==" {
+		t.Errorf("expected signing key from kv v2, but got %q", config.Signing.Key)
+	}
+
+	if err := config.VaultClient().Renew(context.Background()); err != nil {
+		t.Fatalf("unexpected renew error: %v", err)
+	}
+	if !tokenRenewed {
+		t.Error("expected token to be renewed")
+	}
+	if len(renewedLeases) != 1 || renewedLeases[0] != "database/creds/gateway/abc" {
+		t.Errorf("expected database lease to be renewed, but got %v", renewedLeases)
+	}
+
+	t.Run("unknown_field", func(t *testing.T) {
+		config := &Config{
+			Signing: SigningConfig{Key: "vault:secret/data/gateway#missing"},
+			Vault:   VaultConfig{Addr: server.URL, Token: "root-token", Timeout: time.Second},
+		}
+		if err := config.resolveSecrets(context.Background()); err == nil || strings.Contains(err.Error(), "c2VlZA==") {
+			t.Errorf("expected error without secret values, but got %v", err)
+		}
+	})
+
+	t.Run("without_vault", func(t *testing.T) {
+		config := &Config{Signing: SigningConfig{Key: "vault:secret/data/gateway#signing_key"}}
+		if err := config.resolveSecrets(context.Background()); err == nil {
+			t.Error("expected error without VAULT_ADDR")
+		}
+	})
+}
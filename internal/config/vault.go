@@ -0,0 +1,153 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VaultClient читает секреты HashiCorp Vault через HTTP API и продлевает их аренду.
+// Токен и значения секретов не попадают в ошибки.
+type VaultClient struct {
+	addr   string
+	token  string
+	client *http.Client
+
+	mu sync.Mutex
+	// leases продлеваемые аренды прочитанных секретов (например, динамических учетных данных БД)
+	leases []string
+}
+
+// NewVaultClient создает клиент для сервера addr с токеном token
+func NewVaultClient(addr, token string, timeout time.Duration) *VaultClient {
+	return &VaultClient{
+		addr:   strings.TrimRight(addr, "/"),
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+type vaultSecret struct {
+	LeaseID       string         `json:"lease_id"`
+	Renewable     bool           `json:"renewable"`
+	LeaseDuration int            `json:"lease_duration"`
+	Data          map[string]any `json:"data"`
+}
+
+// Secret читает поле секрета по ссылке <путь>#<поле>. Для KV v2 поле ищется в data.data.
+func (c *VaultClient) Secret(ctx context.Context, ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("vault reference must look like <path>#<field>")
+	}
+
+	var secret vaultSecret
+	if err := c.do(ctx, http.MethodGet, strings.TrimLeft(path, "/"), nil, &secret); err != nil {
+		return "", fmt.Errorf("failed to read vault secret %s: %w", path, err)
+	}
+
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nested
+		}
+	}
+
+	value, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no string field %s", path, field)
+	}
+
+	if secret.Renewable && secret.LeaseID != "" {
+		c.mu.Lock()
+		c.leases = append(c.leases, secret.LeaseID)
+		c.mu.Unlock()
+	}
+	return value, nil
+}
+
+// Renew продлевает токен и аренды прочитанных секретов. Непродлеваемый токен (например,
+// выданный с фиксированным сроком) не считается ошибкой.
+func (c *VaultClient) Renew(ctx context.Context) error {
+	var token struct {
+		Data struct {
+			Renewable bool `json:"renewable"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, "auth/token/lookup-self", nil, &token); err != nil {
+		return fmt.Errorf("failed to look up vault token: %w", err)
+	}
+	if token.Data.Renewable {
+		if err := c.do(ctx, http.MethodPost, "auth/token/renew-self", map[string]any{}, nil); err != nil {
+			return fmt.Errorf("failed to renew vault token: %w", err)
+		}
+	}
+
+	c.mu.Lock()
+	leases := append([]string(nil), c.leases...)
+	c.mu.Unlock()
+
+	for _, lease := range leases {
+		if err := c.do(ctx, http.MethodPut, "sys/leases/renew", map[string]any{"lease_id": lease}, nil); err != nil {
+			return fmt.Errorf("failed to renew vault lease: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *VaultClient) do(ctx context.Context, method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.addr+"/v1/"+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("vault responded with status %d", resp.StatusCode)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// VaultRenewJob периодически продлевает токен и аренды секретов Vault
+type VaultRenewJob struct {
+	client *VaultClient
+}
+
+func NewVaultRenewJob(client *VaultClient) *VaultRenewJob {
+	return &VaultRenewJob{client: client}
+}
+
+func (j *VaultRenewJob) Name() string {
+	return "vault_renew"
+}
+
+func (j *VaultRenewJob) Run(ctx context.Context) error {
+	return j.client.Renew(ctx)
+}
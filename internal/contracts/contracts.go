@@ -0,0 +1,215 @@
+// Package contracts собирает машиночитаемые контракты шлюза: схему GraphQL, JSON Schema сообщений
+// NATS и данных поставщиков, описание REST-обработчиков в OpenAPI и перечисления API.
+// По набору смежные команды генерируют клиентов и проверяют интеграции.
+package contracts
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/buildinfo"
+	"scoring_api_gateway/internal/messaging"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+)
+
+// MessagesVersion версия контрактов сообщений NATS. Повышается вместе с ломающими изменениями сообщений.
+const MessagesVersion = 1
+
+// Схемы сообщений лежат в messages/<subject>.json
+//
+//go:embed messages/*.json openapi.json
+var files embed.FS
+
+// Направления сообщений с точки зрения шлюза
+const (
+	DirectionPublish   = "publish"
+	DirectionSubscribe = "subscribe"
+)
+
+// message описание сообщения NATS; схема читается из messages/<Subject>.json
+type message struct {
+	Subject string
+	// Aliases subject, в которые публикуется то же сообщение
+	Aliases     []string
+	Direction   string
+	Description string
+}
+
+var messages = []message{
+	{
+		Subject:     messaging.SubjectVerificationCreate,
+		Aliases:     []string{messaging.SubjectVerificationCreateHighPriority, messaging.SubjectVerificationCreateWorkerPrefix + "<worker_id>"},
+		Direction:   DirectionPublish,
+		Description: "Request to collect data of a company",
+	},
+	{
+		Subject:     messaging.SubjectVerificationCompleted,
+		Direction:   DirectionSubscribe,
+		Description: "Final status of a verification. The gateway also publishes it for verifications completed by an admin",
+	},
+	{
+		Subject:     messaging.SubjectVerificationRescore,
+		Direction:   DirectionPublish,
+		Description: "Request to recompute the score from stored data",
+	},
+	{
+		Subject:     messaging.SubjectVerificationRescored,
+		Direction:   DirectionSubscribe,
+		Description: "Score recomputed by the current scoring rules",
+	},
+	{
+		Subject:     messaging.SubjectVerificationAmended,
+		Direction:   DirectionPublish,
+		Description: "Data of a completed verification delivered or changed after completion",
+	},
+	{
+		Subject:     messaging.SubjectWorkerHeartbeat,
+		Direction:   DirectionSubscribe,
+		Description: "Queue depth and capacity of a worker",
+	},
+}
+
+// Versions версии контрактов набора
+type Versions struct {
+	Build  string `json:"build"`
+	GitSHA string `json:"git_sha,omitempty"`
+	// Schema версия схемы GraphQL, graph.SchemaVersion
+	Schema   int `json:"schema"`
+	Messages int `json:"messages"`
+	// Digest sha256 содержимого набора, меняется при любом изменении контрактов
+	Digest string `json:"digest"`
+}
+
+// Message контракт сообщения NATS
+type Message struct {
+	Subject     string          `json:"subject"`
+	Aliases     []string        `json:"aliases,omitempty"`
+	Direction   string          `json:"direction"`
+	Description string          `json:"description"`
+	Schema      json.RawMessage `json:"schema"`
+}
+
+// EnumValue значение перечисления API
+type EnumValue struct {
+	Name              string `json:"name"`
+	Description       string `json:"description,omitempty"`
+	Deprecated        bool   `json:"deprecated"`
+	DeprecationReason string `json:"deprecation_reason,omitempty"`
+}
+
+// Enum перечисление API
+type Enum struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Values      []EnumValue `json:"values"`
+}
+
+// Bundle набор контрактов шлюза
+type Bundle struct {
+	Versions Versions `json:"versions"`
+	// GraphQL схема API в SDL
+	GraphQL  string    `json:"graphql"`
+	Messages []Message `json:"messages"`
+	// DataTypes JSON Schema данных поставщиков по типам данных
+	DataTypes map[string]json.RawMessage `json:"data_types"`
+	OpenAPI   json.RawMessage            `json:"openapi"`
+	Enums     []Enum                     `json:"enums"`
+}
+
+// Build собирает набор контрактов. Схемы данных передаются вызывающим, чтобы в набор попали
+// и типы, объявленные в конфигурации шлюза.
+func Build(info buildinfo.Info, schema *ast.Schema, dataTypes map[model.VerificationDataType]json.RawMessage) (*Bundle, error) {
+	var sdl bytes.Buffer
+	formatter.NewFormatter(&sdl).FormatSchema(schema)
+
+	bundle := &Bundle{
+		Versions: Versions{
+			Build:    info.Version,
+			GitSHA:   info.GitSHA,
+			Schema:   info.SchemaVersion,
+			Messages: MessagesVersion,
+		},
+		GraphQL:   sdl.String(),
+		Messages:  make([]Message, 0, len(messages)),
+		DataTypes: make(map[string]json.RawMessage, len(dataTypes)),
+		Enums:     enums(schema),
+	}
+
+	for _, m := range messages {
+		content, err := files.ReadFile("messages/" + m.Subject + ".json")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema of %s: %w", m.Subject, err)
+		}
+		bundle.Messages = append(bundle.Messages, Message{
+			Subject:     m.Subject,
+			Aliases:     m.Aliases,
+			Direction:   m.Direction,
+			Description: m.Description,
+			Schema:      content,
+		})
+	}
+	for dataType, content := range dataTypes {
+		bundle.DataTypes[string(dataType)] = content
+	}
+
+	openAPI, err := files.ReadFile("openapi.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read openapi document: %w", err)
+	}
+	bundle.OpenAPI = openAPI
+
+	// Digest считается по набору без него самого; ключи DataTypes сериализуются по порядку
+	content, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal contracts: %w", err)
+	}
+	sum := sha256.Sum256(content)
+	bundle.Versions.Digest = hex.EncodeToString(sum[:])
+
+	return bundle, nil
+}
+
+// Message возвращает контракт сообщения по subject
+func (b *Bundle) Message(subject string) (Message, bool) {
+	for _, m := range b.Messages {
+		if m.Subject == subject {
+			return m, true
+		}
+	}
+	return Message{}, false
+}
+
+// enums собирает перечисления схемы без служебных перечислений интроспекции
+func enums(schema *ast.Schema) []Enum {
+	var result []Enum
+	for name, definition := range schema.Types {
+		if definition.Kind != ast.Enum || definition.BuiltIn || strings.HasPrefix(name, "__") {
+			continue
+		}
+
+		enum := Enum{Name: name, Description: definition.Description}
+		for _, value := range definition.EnumValues {
+			enumValue := EnumValue{Name: value.Name, Description: value.Description}
+			if deprecated := value.Directives.ForName("deprecated"); deprecated != nil {
+				enumValue.Deprecated = true
+				if reason := deprecated.Arguments.ForName("reason"); reason != nil && reason.Value != nil {
+					enumValue.DeprecationReason = reason.Value.Raw
+				}
+			}
+			enum.Values = append(enum.Values, enumValue)
+		}
+		result = append(result, enum)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
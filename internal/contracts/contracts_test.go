@@ -0,0 +1,128 @@
+package contracts
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/buildinfo"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const testSchema = `
+type Query { status: Status }
+"Verification status"
+enum Status {
+  DONE
+  FINISHED @deprecated(reason: "Use DONE")
+}
+`
+
+func buildTestBundle(t *testing.T) *Bundle {
+	t.Helper()
+	schema := gqlparser.MustLoadSchema(&ast.Source{Name: "schema.graphqls", Input: testSchema})
+	bundle, err := Build(buildinfo.Info{Version: "1.2.0", SchemaVersion: 3}, schema,
+		map[model.VerificationDataType]json.RawMessage{model.VerificationDataTypeBasicInformation: json.RawMessage(`{"type":"object"}`)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return bundle
+}
+
+func TestBuild(t *testing.T) {
+	bundle := buildTestBundle(t)
+
+	if bundle.Versions.Schema != 3 || bundle.Versions.Messages != MessagesVersion || len(bundle.Versions.Digest) != 64 {
+		t.Errorf("unexpected versions %+v", bundle.Versions)
+	}
+	if len(bundle.Enums) != 1 || bundle.Enums[0].Name != "Status" || len(bundle.Enums[0].Values) != 2 {
+		t.Fatalf("expected only the Status enum, but got %+v", bundle.Enums)
+	}
+	if deprecated := bundle.Enums[0].Values[1]; !deprecated.Deprecated || deprecated.DeprecationReason != "Use DONE" {
+		t.Errorf("expected FINISHED to be deprecated, but got %+v", deprecated)
+	}
+	if _, ok := bundle.DataTypes["BASIC_INFORMATION"]; !ok {
+		t.Error("expected the data type schema in the bundle")
+	}
+	if !bytes.Contains([]byte(bundle.GraphQL), []byte("enum Status")) {
+		t.Errorf("expected the SDL of the schema, but got %q", bundle.GraphQL)
+	}
+
+	if again := buildTestBundle(t); again.Versions.Digest != bundle.Versions.Digest {
+		t.Error("expected the digest of the same contracts to be stable")
+	}
+}
+
+// Схемы сообщений должны принимать сообщения, которые шлюз и воркеры действительно отправляют
+func TestMessageSchemasMatchMessages(t *testing.T) {
+	system := "crm"
+	samples := map[string]any{
+		messaging.SubjectVerificationCreate: messaging.CreateVerificationMessage{
+			VerificationID: "0f8fad5b-d9cb-469f-a165-70867728950e",
+			INN:            "7707083893",
+			RequestedTypes: []domain.DataType{"BASIC_INFORMATION"},
+			AuthorEmail:    "analyst@bank.ru",
+			Priority:       messaging.PriorityHigh,
+			ExternalRef:    &messaging.ExternalRefMessage{System: &system, Ref: "CASE-1"},
+		},
+		messaging.SubjectVerificationCompleted: messaging.VerificationCompletedMessage{
+			VerificationID: "0f8fad5b-d9cb-469f-a165-70867728950e",
+			Status:         "COMPLETED",
+			RiskLevel:      "LOW",
+			CompletedAt:    "2024-01-01T10:00:00Z",
+		},
+		messaging.SubjectVerificationRescore: messaging.RescoreVerificationMessage{
+			VerificationID:  "0f8fad5b-d9cb-469f-a165-70867728950e",
+			INN:             "7707083893",
+			RecalculationID: "r-1",
+		},
+		messaging.SubjectVerificationRescored: messaging.VerificationRescoredMessage{
+			VerificationID: "0f8fad5b-d9cb-469f-a165-70867728950e",
+			RiskLevel:      "HIGH",
+		},
+		messaging.SubjectVerificationAmended: messaging.VerificationAmendedMessage{
+			VerificationID: "0f8fad5b-d9cb-469f-a165-70867728950e",
+			AmendmentID:    "a-1",
+			DataType:       "BASIC_INFORMATION",
+			DataHash:       "abc",
+			SchemaVersion:  1,
+			ReceivedAt:     "2024-01-01T10:00:00Z",
+		},
+		messaging.SubjectWorkerHeartbeat: messaging.WorkerHeartbeatMessage{WorkerID: "w-1", QueueDepth: 3, Capacity: 10},
+	}
+
+	bundle := buildTestBundle(t)
+	if len(bundle.Messages) != len(samples) {
+		t.Fatalf("expected a sample for each of %d messages", len(bundle.Messages))
+	}
+	for _, message := range bundle.Messages {
+		t.Run(message.Subject, func(t *testing.T) {
+			compiler := jsonschema.NewCompiler()
+			compiler.Draft = jsonschema.Draft2020
+			if err := compiler.AddResource(message.Subject, bytes.NewReader(message.Schema)); err != nil {
+				t.Fatalf("failed to load schema: %v", err)
+			}
+			schema, err := compiler.Compile(message.Subject)
+			if err != nil {
+				t.Fatalf("failed to compile schema: %v", err)
+			}
+
+			sample, ok := samples[message.Subject]
+			if !ok {
+				t.Fatal("no sample message")
+			}
+			content, _ := json.Marshal(sample)
+			var doc any
+			json.Unmarshal(content, &doc)
+			if err := schema.Validate(doc); err != nil {
+				t.Errorf("sample message does not match the schema: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Package creation записывает проверку в таблицу verifications вместе с запросом воркерам
+// в outbox_messages, до отправки запроса.
+package creation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// Store хранилище, в которое проверка и ее запрос записываются перед публикацией
+type Store interface {
+	CreateWithRequest(ctx context.Context, verification *domain.Verification, msg *messaging.OutboxMessage, claimedUntil time.Time) (bool, error)
+}
+
+type persistClient struct {
+	messaging.NATSClient
+	store        Store
+	outbox       repository.OutboxRepository
+	claimTimeout time.Duration
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+// NewClient записывает проверку со статусом IN_PROCESS, запрошенными типами и автором одной
+// транзакцией с запросом в outbox_messages, прежде чем передать запрос client. Запрос захвачен
+// на claimTimeout: после отправки он отмечается отправленным, а если шлюз упал до отправки,
+// его отправит outbox_relay, когда захват истечет. Если записать проверку не удалось, запрос
+// не публикуется и ошибка возвращается вызывающему: воркер не может завершить проверку, которой
+// нет в базе. Если отправить не удалось, запрос удаляется из outbox, а ошибка возвращается.
+//
+// Клиент ставится под перехватчиками, которые сами сохраняют готовую проверку (песочница, свежие
+// данные, кэш ненайденных компаний), и под outbox: у запроса, который отправляет outbox_relay,
+// уже есть неотправленное сообщение, поэтому клиент его не меняет и не отмечает. Повторная
+// публикация существующей проверки строку не меняет.
+func NewClient(client messaging.NATSClient, store Store, outbox repository.OutboxRepository, claimTimeout time.Duration, logger *zap.Logger) messaging.NATSClient {
+	return &persistClient{
+		NATSClient:   client,
+		store:        store,
+		outbox:       outbox,
+		claimTimeout: claimTimeout,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+func (c *persistClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
+	return c.PublishVerificationRequestWithPriority(ctx, verification, messaging.PriorityNormal)
+}
+
+func (c *persistClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	created := &domain.Verification{
+		ID:                 verification.ID,
+		INN:                verification.INN,
+		Status:             domain.StatusInProcess,
+		AuthorEmail:        verification.AuthorEmail,
+		RequestedDataTypes: verification.RequestedDataTypes,
+	}
+	now := c.now()
+	msg, err := messaging.NewOutboxMessage(verification, priority, now)
+	if err != nil {
+		return err
+	}
+	owned, err := c.store.CreateWithRequest(ctx, created, msg, now.Add(c.claimTimeout))
+	if err != nil {
+		c.logger.Error("failed to persist verification before publishing", zap.Error(err), zap.String("verification_id", verification.ID))
+		return fmt.Errorf("failed to persist verification: %w", err)
+	}
+
+	if err := c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority); err != nil {
+		if owned {
+			if discardErr := c.outbox.Discard(ctx, verification.ID); discardErr != nil {
+				c.logger.Warn("failed to discard unpublished verification request", zap.Error(discardErr), zap.String("verification_id", verification.ID))
+			}
+		}
+		return err
+	}
+	if owned {
+		// Без отметки outbox_relay отправит запрос повторно, когда истечет захват
+		if err := c.outbox.MarkPublished(ctx, verification.ID); err != nil {
+			c.logger.Warn("failed to mark verification request published", zap.Error(err), zap.String("verification_id", verification.ID))
+		}
+	}
+	return nil
+}
+
+type creatingOutbox struct {
+	repository.OutboxRepository
+	store  Store
+	logger *zap.Logger
+}
+
+// WrapOutbox записывает строку отложенной проверки вместе с сообщением в outbox, чтобы проверка
+// была видна по идентификатору до отправки воркерам. Нужен, когда отложенные запросы не
+// показываются сервисом как PENDING: без строки запрос проверки вернул бы "не найдена".
+// Если у проверки уже есть неотправленное сообщение, оно заменяется, как в Enqueue.
+func WrapOutbox(outbox repository.OutboxRepository, store Store, logger *zap.Logger) repository.OutboxRepository {
+	return &creatingOutbox{
+		OutboxRepository: outbox,
+		store:            store,
+		logger:           logger,
+	}
+}
+
+func (o *creatingOutbox) Enqueue(ctx context.Context, msg *messaging.OutboxMessage) (int, error) {
+	verification, err := msg.Verification()
+	if err != nil {
+		return 0, err
+	}
+	written, err := o.store.CreateWithRequest(ctx, verification, msg, time.Time{})
+	if err != nil {
+		o.logger.Error("failed to persist deferred verification", zap.Error(err), zap.String("verification_id", msg.ID))
+		return 0, fmt.Errorf("failed to persist verification: %w", err)
+	}
+	if written {
+		return 0, nil
+	}
+	return o.OutboxRepository.Enqueue(ctx, msg)
+}
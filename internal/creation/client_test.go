@@ -0,0 +1,169 @@
+package creation
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type recordingClient struct {
+	messaging.NATSClient
+	steps *[]string
+	err   error
+}
+
+func (c *recordingClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	*c.steps = append(*c.steps, "publish")
+	return c.err
+}
+
+type recordingStore struct {
+	steps        *[]string
+	saved        *domain.Verification
+	msg          *messaging.OutboxMessage
+	claimedUntil time.Time
+	pending      bool
+	err          error
+}
+
+func (s *recordingStore) CreateWithRequest(ctx context.Context, verification *domain.Verification, msg *messaging.OutboxMessage, claimedUntil time.Time) (bool, error) {
+	*s.steps = append(*s.steps, "create")
+	s.saved, s.msg, s.claimedUntil = verification, msg, claimedUntil
+	return !s.pending, s.err
+}
+
+type recordingOutbox struct {
+	repository.OutboxRepository
+	steps *[]string
+}
+
+func (o *recordingOutbox) Enqueue(ctx context.Context, msg *messaging.OutboxMessage) (int, error) {
+	*o.steps = append(*o.steps, "enqueue")
+	return 3, nil
+}
+
+func (o *recordingOutbox) MarkPublished(ctx context.Context, id string) error {
+	*o.steps = append(*o.steps, "mark_published")
+	return nil
+}
+
+func (o *recordingOutbox) Discard(ctx context.Context, id string) error {
+	*o.steps = append(*o.steps, "discard")
+	return nil
+}
+
+func TestClientPersistsBeforePublishing(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		pending       bool
+		createErr     error
+		publishErr    error
+		expectedSteps []string
+		expectedError string
+	}{
+		{name: "persisted_then_published", expectedSteps: []string{"create", "publish", "mark_published"}},
+		{name: "create_failure_skips_publish", createErr: errors.New("connection refused"),
+			expectedSteps: []string{"create"}, expectedError: "failed to persist verification"},
+		{name: "publish_failure_discards", publishErr: errors.New("nats: connection closed"),
+			expectedSteps: []string{"create", "publish", "discard"}, expectedError: "nats: connection closed"},
+		{name: "relayed_message_not_marked", pending: true, expectedSteps: []string{"create", "publish"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var steps []string
+			store := &recordingStore{steps: &steps, pending: tt.pending, err: tt.createErr}
+			client := NewClient(&recordingClient{steps: &steps, err: tt.publishErr}, store, &recordingOutbox{steps: &steps}, time.Minute, zaptest.NewLogger(t))
+			client.(*persistClient).now = func() time.Time { return now }
+
+			verification := &domain.Verification{
+				ID:                 "v-1",
+				INN:                "7700000001",
+				Status:             domain.StatusPending,
+				AuthorEmail:        "analyst@example.com",
+				RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation},
+			}
+			err := client.PublishVerificationRequest(context.Background(), verification)
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(steps, tt.expectedSteps) {
+				t.Errorf("expected steps %v, got %v", tt.expectedSteps, steps)
+			}
+			saved := store.saved
+			if saved == nil || saved.ID != "v-1" || saved.Status != domain.StatusInProcess ||
+				saved.AuthorEmail != "analyst@example.com" || !slices.Equal(saved.RequestedDataTypes, verification.RequestedDataTypes) {
+				t.Errorf("unexpected persisted verification: %+v", saved)
+			}
+			if msg := store.msg; msg == nil || msg.ID != "v-1" || msg.Tenant != "example.com" || !msg.AvailableAt.Equal(now) {
+				t.Errorf("unexpected persisted request: %+v", msg)
+			}
+			if expected := now.Add(time.Minute); !store.claimedUntil.Equal(expected) {
+				t.Errorf("expected request claimed until %s, got %s", expected, store.claimedUntil)
+			}
+		})
+	}
+}
+
+func TestWrapOutboxPersistsDeferredVerification(t *testing.T) {
+	tests := []struct {
+		name             string
+		pending          bool
+		expectedSteps    []string
+		expectedPosition int
+	}{
+		{name: "created", expectedSteps: []string{"create"}},
+		{name: "pending_replaced", pending: true, expectedSteps: []string{"create", "enqueue"}, expectedPosition: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var steps []string
+			store := &recordingStore{steps: &steps, pending: tt.pending}
+			outbox := WrapOutbox(&recordingOutbox{steps: &steps}, store, zaptest.NewLogger(t))
+
+			msg, err := messaging.NewOutboxMessage(&domain.Verification{
+				ID:          "v-1",
+				INN:         "7700000001",
+				AuthorEmail: "analyst@example.com",
+			}, messaging.PriorityNormal, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg.Held = true
+
+			position, err := outbox.Enqueue(context.Background(), msg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if position != tt.expectedPosition {
+				t.Errorf("expected position %d, got %d", tt.expectedPosition, position)
+			}
+			if !slices.Equal(steps, tt.expectedSteps) {
+				t.Errorf("expected steps %v, got %v", tt.expectedSteps, steps)
+			}
+			if saved := store.saved; saved == nil || saved.ID != "v-1" || saved.Status != domain.StatusInProcess {
+				t.Errorf("unexpected persisted verification: %+v", saved)
+			}
+			if store.msg != msg || !store.claimedUntil.IsZero() {
+				t.Errorf("expected held request stored unclaimed, got %+v until %s", store.msg, store.claimedUntil)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// Package demo наполняет демонстрационную организацию проверками с синтетическими данными,
+// чтобы в /playground тестового окружения всегда было что запросить.
+package demo
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/sandbox"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// KeyName имя ключа API демонстрационной организации
+const KeyName = "demo-playground"
+
+// company демонстрационная компания и то, чем закончилась ее проверка
+type company struct {
+	inn     string
+	status  domain.Status
+	request []domain.DataType
+	missing []domain.DataType
+}
+
+// companies набор, покрывающий типовые запросы: полная проверка юрлица и ИП, проверка
+// части данных и частично завершенная проверка
+var companies = []company{
+	{inn: "7707083893", status: domain.StatusCompleted, request: domain.AllDataTypes},
+	{inn: "7736050003", status: domain.StatusCompleted, request: domain.AllDataTypes},
+	{inn: "500100732259", status: domain.StatusCompleted, request: domain.AllDataTypes},
+	{inn: "7728168971", status: domain.StatusCompleted, request: []domain.DataType{
+		domain.DataTypeBasicInformation,
+		domain.DataTypeActivities,
+	}},
+	{inn: "7702070139", status: domain.StatusPartiallyCompleted, request: domain.AllDataTypes, missing: []domain.DataType{
+		domain.DataTypeArbitrageStatistics,
+	}},
+}
+
+// Seeder создает демонстрационную организацию и обновляет ее проверки
+type Seeder struct {
+	store     repository.DemoRepository
+	generator *sandbox.Generator
+	cfg       config.DemoConfig
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+func NewSeeder(store repository.DemoRepository, cfg config.DemoConfig, logger *zap.Logger) *Seeder {
+	return &Seeder{
+		store:     store,
+		generator: sandbox.NewGenerator(),
+		cfg:       cfg,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// AuthorEmail автор демонстрационных проверок и сервисный аккаунт ключа
+func (s *Seeder) AuthorEmail() string {
+	return "demo@" + s.cfg.Organization
+}
+
+// EnsureAccount создает организацию песочницы и ключ apiKey только на чтение
+func (s *Seeder) EnsureAccount(ctx context.Context, apiKey string) error {
+	return s.store.EnsureAccount(ctx, &repository.DemoAccount{
+		Organization: s.cfg.Organization,
+		Name:         "Демо",
+		ServiceEmail: s.AuthorEmail(),
+		KeyName:      KeyName,
+		KeyHash:      auth.HashAPIKey(apiKey),
+	})
+}
+
+// Refresh заменяет демонстрационные проверки свежими и возвращает их число.
+// Идентификаторы проверок не меняются, поэтому примеры запросов остаются рабочими.
+func (s *Seeder) Refresh(ctx context.Context) (int, error) {
+	verifications, err := s.Verifications()
+	if err != nil {
+		return 0, err
+	}
+	if err := s.store.ReplaceVerifications(ctx, s.AuthorEmail(), verifications); err != nil {
+		return 0, err
+	}
+
+	s.logger.Info("demo verifications refreshed",
+		zap.String("organization", s.cfg.Organization),
+		zap.Int("verifications", len(verifications)))
+	return len(verifications), nil
+}
+
+// Verifications строит демонстрационные проверки: по одной на компанию, созданные
+// с интервалом в несколько часов до текущего момента
+func (s *Seeder) Verifications() ([]*domain.Verification, error) {
+	now := s.now().UTC().Truncate(time.Minute)
+	author := s.AuthorEmail()
+
+	verifications := make([]*domain.Verification, 0, len(companies))
+	for i, c := range companies {
+		createdAt := now.Add(-time.Duration(i+1) * 5 * time.Hour)
+		completedAt := createdAt.Add(time.Duration(2+i) * time.Minute)
+
+		data := make([]*domain.Data, 0, len(c.request))
+		for _, dataType := range c.request {
+			if slices.Contains(c.missing, dataType) {
+				continue
+			}
+			payload, err := s.generator.Generate(c.inn, dataType)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, &domain.Data{DataType: dataType, Payload: payload, CreatedAt: completedAt})
+		}
+
+		riskLevel := s.generator.RiskLevel(c.inn)
+		verifications = append(verifications, &domain.Verification{
+			ID:                 uuid.NewSHA1(uuid.NameSpaceURL, []byte("demo:"+s.cfg.Organization+":"+c.inn)).String(),
+			INN:                c.inn,
+			Status:             c.status,
+			AuthorEmail:        author,
+			RiskLevel:          &riskLevel,
+			Sandbox:            true,
+			RequestedDataTypes: c.request,
+			MissingDataTypes:   append([]domain.DataType{}, c.missing...),
+			Data:               data,
+			CreatedAt:          createdAt,
+			UpdatedAt:          completedAt,
+		})
+	}
+	return verifications, nil
+}
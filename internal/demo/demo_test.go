@@ -0,0 +1,106 @@
+package demo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/validation"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type recordingStore struct {
+	account       *repository.DemoAccount
+	authorEmail   string
+	verifications []*domain.Verification
+}
+
+func (s *recordingStore) EnsureAccount(ctx context.Context, account *repository.DemoAccount) error {
+	s.account = account
+	return nil
+}
+
+func (s *recordingStore) ReplaceVerifications(ctx context.Context, authorEmail string, verifications []*domain.Verification) error {
+	s.authorEmail, s.verifications = authorEmail, verifications
+	return nil
+}
+
+func newTestSeeder(t *testing.T, store *recordingStore, now time.Time) *Seeder {
+	seeder := NewSeeder(store, config.DemoConfig{Organization: "demo.scoring.local"}, zaptest.NewLogger(t))
+	seeder.now = func() time.Time { return now }
+	return seeder
+}
+
+func TestRefresh(t *testing.T) {
+	validator, err := validation.NewValidator()
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	store := &recordingStore{}
+	now := time.Date(2024, 3, 1, 10, 42, 0, 0, time.UTC)
+	count, err := newTestSeeder(t, store, now).Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != len(companies) || len(store.verifications) != count {
+		t.Fatalf("expected %d demo verifications, but got %d", len(companies), len(store.verifications))
+	}
+	if store.authorEmail != "demo@demo.scoring.local" {
+		t.Errorf("expected demo author, but got %s", store.authorEmail)
+	}
+
+	partial := 0
+	for _, v := range store.verifications {
+		if v.AuthorEmail != store.authorEmail || !v.Sandbox || v.RiskLevel == nil {
+			t.Errorf("unexpected verification %+v", v)
+		}
+		if !v.CreatedAt.Before(now) || v.CreatedAt.Before(now.Add(-48*time.Hour)) {
+			t.Errorf("expected %s to be created within the last two days, but got %s", v.INN, v.CreatedAt)
+		}
+		if len(v.Data)+len(v.MissingDataTypes) != len(v.RequestedDataTypes) {
+			t.Errorf("expected %s to have data for every delivered type", v.INN)
+		}
+		for _, data := range v.Data {
+			if result := validator.Validate(model.VerificationDataType(data.DataType), data.Payload); !result.Valid {
+				t.Errorf("expected %s for %s to match schema, but got %v", data.DataType, v.INN, result.Errors)
+			}
+		}
+		if v.Status == domain.StatusPartiallyCompleted {
+			partial++
+		}
+	}
+	if partial != 1 {
+		t.Errorf("expected one partially completed verification, but got %d", partial)
+	}
+
+	first := store.verifications
+	if _, err := newTestSeeder(t, store, now.Add(24*time.Hour)).Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, v := range store.verifications {
+		if v.ID != first[i].ID {
+			t.Errorf("expected demo verification ids to survive refresh, but %s changed to %s", first[i].ID, v.ID)
+		}
+		if v.CreatedAt == first[i].CreatedAt {
+			t.Errorf("expected refresh to move %s forward", v.INN)
+		}
+	}
+}
+
+func TestEnsureAccount(t *testing.T) {
+	store := &recordingStore{}
+	if err := newTestSeeder(t, store, time.Now()).EnsureAccount(context.Background(), "demo-key"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.account.Organization != "demo.scoring.local" || store.account.ServiceEmail != "demo@demo.scoring.local" ||
+		store.account.KeyName != KeyName || store.account.KeyHash != auth.HashAPIKey("demo-key") {
+		t.Errorf("unexpected account %+v", store.account)
+	}
+}
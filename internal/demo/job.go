@@ -0,0 +1,21 @@
+package demo
+
+import "context"
+
+// RefreshJob обновляет демонстрационные проверки, чтобы их даты оставались недавними
+type RefreshJob struct {
+	seeder *Seeder
+}
+
+func NewRefreshJob(seeder *Seeder) *RefreshJob {
+	return &RefreshJob{seeder: seeder}
+}
+
+func (j *RefreshJob) Name() string {
+	return "demo_refresh"
+}
+
+func (j *RefreshJob) Run(ctx context.Context) error {
+	_, err := j.seeder.Refresh(ctx)
+	return err
+}
@@ -0,0 +1,176 @@
+// Package domain модель предметной области шлюза, не зависящая от кода, который gqlgen создает
+// по схеме GraphQL. Хранилище и обмен сообщениями NATS работают с этими типами, а представление
+// GraphQL строится из них конвертерами graph/model, поэтому изменения схемы не затрагивают
+// чтение строк из базы и формат сообщений.
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// Status статус проверки
+type Status string
+
+const (
+	StatusPending            Status = "PENDING"
+	StatusInProcess          Status = "IN_PROCESS"
+	StatusProcessing         Status = "PROCESSING"
+	StatusCompleted          Status = "COMPLETED"
+	StatusPartiallyCompleted Status = "PARTIALLY_COMPLETED"
+	StatusError              Status = "ERROR"
+	StatusCompanyNotFound    Status = "COMPANY_NOT_FOUND"
+	// StatusPendingEmailConfirmation запрос удержан до подтверждения email автора
+	StatusPendingEmailConfirmation Status = "PENDING_EMAIL_CONFIRMATION"
+)
+
+// AllStatuses все статусы проверки
+var AllStatuses = []Status{
+	StatusPending,
+	StatusInProcess,
+	StatusProcessing,
+	StatusCompleted,
+	StatusPartiallyCompleted,
+	StatusError,
+	StatusCompanyNotFound,
+	StatusPendingEmailConfirmation,
+}
+
+// IsValid сообщает, известен ли статус шлюзу
+func (s Status) IsValid() bool {
+	return slices.Contains(AllStatuses, s)
+}
+
+// DataType тип данных о компании, которые доставляют поставщики
+type DataType string
+
+const (
+	DataTypeBasicInformation                DataType = "BASIC_INFORMATION"
+	DataTypeActivities                      DataType = "ACTIVITIES"
+	DataTypeAddressesByCredinform           DataType = "ADDRESSES_BY_CREDINFORM"
+	DataTypeAddressesByUnifiedStateRegister DataType = "ADDRESSES_BY_UNIFIED_STATE_REGISTER"
+	DataTypeAffiliatedCompanies             DataType = "AFFILIATED_COMPANIES"
+	DataTypeArbitrageStatistics             DataType = "ARBITRAGE_STATISTICS"
+)
+
+// AllDataTypes все типы данных
+var AllDataTypes = []DataType{
+	DataTypeBasicInformation,
+	DataTypeActivities,
+	DataTypeAddressesByCredinform,
+	DataTypeAddressesByUnifiedStateRegister,
+	DataTypeAffiliatedCompanies,
+	DataTypeArbitrageStatistics,
+}
+
+// IsValid сообщает, известен ли тип данных шлюзу
+func (t DataType) IsValid() bool {
+	return slices.Contains(AllDataTypes, t)
+}
+
+// RiskLevel уровень риска по итогам скоринга
+type RiskLevel string
+
+const (
+	RiskLevelLow    RiskLevel = "LOW"
+	RiskLevelMedium RiskLevel = "MEDIUM"
+	RiskLevelHigh   RiskLevel = "HIGH"
+)
+
+// AllRiskLevels все уровни риска
+var AllRiskLevels = []RiskLevel{RiskLevelLow, RiskLevelMedium, RiskLevelHigh}
+
+// IsValid сообщает, известен ли уровень риска шлюзу
+func (l RiskLevel) IsValid() bool {
+	return slices.Contains(AllRiskLevels, l)
+}
+
+// ReviewState этап ручного ревью проверки
+type ReviewState string
+
+const (
+	ReviewStateUnreviewed ReviewState = "UNREVIEWED"
+	ReviewStateInReview   ReviewState = "IN_REVIEW"
+	ReviewStateApproved   ReviewState = "APPROVED"
+	ReviewStateRejected   ReviewState = "REJECTED"
+)
+
+// AllReviewStates все этапы ревью
+var AllReviewStates = []ReviewState{ReviewStateUnreviewed, ReviewStateInReview, ReviewStateApproved, ReviewStateRejected}
+
+// IsValid сообщает, известен ли этап ревью шлюзу
+func (s ReviewState) IsValid() bool {
+	return slices.Contains(AllReviewStates, s)
+}
+
+// ExternalRef идентификатор проверки во внешней системе
+type ExternalRef struct {
+	System *string
+	Ref    string
+}
+
+// Data данные одного типа, доставленные поставщиком
+type Data struct {
+	DataType DataType
+	// Payload документ JSON в формате поставщика
+	Payload       string
+	SchemaVersion int
+	CreatedAt     time.Time
+	// Freshness свежесть данных, выданных из кэша, пусто - данные доставлены для этой проверки
+	Freshness Freshness
+}
+
+// Freshness свежесть данных, выданных из кэша компании вместо запроса у поставщиков
+type Freshness string
+
+const (
+	// FreshnessFresh данные моложе срока свежести своего типа
+	FreshnessFresh Freshness = "FRESH"
+	// FreshnessStale устаревшие данные, выданные сразу и обновляемые в фоне
+	FreshnessStale Freshness = "STALE"
+)
+
+// DataQuality результат проверки доставленных данных по JSON Schema их типа
+type DataQuality struct {
+	DataType DataType
+	Valid    bool
+	Errors   []string
+}
+
+// Verification проверка компании по ИНН
+type Verification struct {
+	ID          string
+	INN         string
+	Status      Status
+	AuthorEmail string
+	CompanyID   *string
+	RiskLevel   *RiskLevel
+	// RulesetID набор правил скоринга, по которому получен RiskLevel
+	RulesetID   *string
+	ExternalRef *ExternalRef
+	// Metadata пары ключ-значение интегратора, nil - метаданных нет
+	Metadata           map[string]string
+	LegalHold          bool
+	Sandbox            bool
+	RequestedDataTypes []DataType
+	MissingDataTypes   []DataType
+	Assignee           *string
+	ReviewState        ReviewState
+	ReviewComment      *string
+	ReviewedAt         *time.Time
+	// ExpectedStartAt и QueuePosition заполняет публикация запроса, отложенная ограничениями арендатора
+	ExpectedStartAt *time.Time
+	QueuePosition   *int
+	// EstimatedCompletionAt ожидаемое время завершения, известно только для незавершенных проверок
+	EstimatedCompletionAt *time.Time
+	Data                  []*Data
+	DataQuality           []*DataQuality
+	// ErrorMessage причина ошибки, переданная воркером в уведомлении о завершении
+	ErrorMessage *string
+	// EventID идентификатор уведомления о завершении, из которого прочитана проверка
+	EventID string
+	// CreatedAt и UpdatedAt нулевые, пока проверка не сохранена. У проверки из уведомления
+	// о завершении UpdatedAt - время смены статуса, если воркер его передал.
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,21 @@
+package emailconfirm
+
+import "context"
+
+// CleanupJob удаляет запросы, автор которых так и не подтвердил email
+type CleanupJob struct {
+	confirmer *Confirmer
+}
+
+func NewCleanupJob(confirmer *Confirmer) *CleanupJob {
+	return &CleanupJob{confirmer: confirmer}
+}
+
+func (j *CleanupJob) Name() string {
+	return "email_confirmation_cleanup"
+}
+
+func (j *CleanupJob) Run(ctx context.Context) error {
+	_, err := j.confirmer.Prune(ctx)
+	return err
+}
@@ -0,0 +1,72 @@
+package emailconfirm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+type confirmationClient struct {
+	messaging.NATSClient
+	confirmer *Confirmer
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewClient удерживает запросы пользователей с неподтвержденным email: проверка получает статус
+// PENDING_EMAIL_CONFIRMATION, а автору отправляется ссылка подтверждения. Запросы ключей сервисных
+// аккаунтов, песочницы и фоновых задач передаются client без проверки.
+func NewClient(client messaging.NATSClient, confirmer *Confirmer, logger *zap.Logger) messaging.NATSClient {
+	return &confirmationClient{
+		NATSClient: client,
+		confirmer:  confirmer,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+func (c *confirmationClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
+	return c.PublishVerificationRequestWithPriority(ctx, verification, messaging.PriorityNormal)
+}
+
+func (c *confirmationClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || principal.Scope != nil || principal.Sandbox || principal.Email != verification.AuthorEmail {
+		return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+
+	email := verification.AuthorEmail
+	confirmed, err := c.confirmer.store.IsEmailConfirmed(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to check author email confirmation: %w", err)
+	}
+	if confirmed {
+		return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+
+	msg, err := messaging.NewOutboxMessage(verification, priority, c.now())
+	if err != nil {
+		return err
+	}
+	if err := c.confirmer.store.HoldUntilConfirmed(ctx, email, msg); err != nil {
+		return err
+	}
+	verification.Status = domain.StatusPendingEmailConfirmation
+	metrics.EmailConfirmationHolds.Inc()
+
+	// Запрос уже сохранен: если письмо не ушло, оно отправится при следующей проверке автора
+	if err := c.confirmer.RequestConfirmation(ctx, email); err != nil {
+		c.logger.Warn("failed to send email confirmation", zap.Error(err), zap.String("email", email))
+	}
+	c.logger.Info("verification request held until author email is confirmed",
+		zap.String("verification_id", verification.ID),
+		zap.String("email", email))
+	return nil
+}
@@ -0,0 +1,133 @@
+// Package emailconfirm удерживает запросы на проверку от имени email, от которого еще не было
+// проверок, пока автор не подтвердит email по ссылке из письма. Так опечатка в email не отправит
+// уведомления о завершении проверки постороннему человеку.
+package emailconfirm
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/mailer"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+// Store хранилище подтверждений и удержанных запросов
+type Store interface {
+	IsEmailConfirmed(ctx context.Context, email string) (bool, error)
+	HoldUntilConfirmed(ctx context.Context, email string, msg *messaging.OutboxMessage) error
+	IssueConfirmationToken(ctx context.Context, email, tokenHash string, expiresAt, resendBefore time.Time) (bool, error)
+	ConfirmEmail(ctx context.Context, tokenHash string) (string, int, error)
+	PruneHolds(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Confirmer отправляет ссылки подтверждения и подтверждает email по токену из ссылки
+type Confirmer struct {
+	store  Store
+	sender mailer.Sender
+	cfg    config.EmailConfirmationConfig
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+func NewConfirmer(store Store, sender mailer.Sender, cfg config.EmailConfirmationConfig, logger *zap.Logger) *Confirmer {
+	return &Confirmer{
+		store:  store,
+		sender: sender,
+		cfg:    cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// RequestConfirmation отправляет на email ссылку подтверждения. Пока не прошло ResendAfter
+// с предыдущего письма, новое не отправляется, и старая ссылка остается действительной.
+func (c *Confirmer) RequestConfirmation(ctx context.Context, email string) error {
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	now := c.now()
+	expiresAt := now.Add(c.cfg.TokenTTL)
+	issued, err := c.store.IssueConfirmationToken(ctx, email, HashToken(token), expiresAt, now.Add(-c.cfg.ResendAfter))
+	if err != nil || !issued {
+		return err
+	}
+
+	link, err := confirmLink(c.cfg.ConfirmURL, token)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("От имени %s запрошена проверка компании в шлюзе скоринга.\n\n"+
+		"Чтобы запустить ее, подтвердите email по ссылке:\n%s\n\n"+
+		"Ссылка действительна до %s. Если вы не запрашивали проверку, просто не отвечайте на это письмо.\n",
+		email, link, expiresAt.UTC().Format("02.01.2006 15:04 MST"))
+	if err := c.sender.Send(ctx, email, "Подтвердите email для проверок компаний", body); err != nil {
+		return err
+	}
+
+	c.logger.Info("email confirmation requested", zap.String("email", email))
+	return nil
+}
+
+// Confirm подтверждает email по токену и возвращает его вместе с числом отпущенных запросов
+func (c *Confirmer) Confirm(ctx context.Context, token string) (string, int, error) {
+	if token == "" {
+		return "", 0, fmt.Errorf("confirmation token cannot be empty")
+	}
+
+	email, released, err := c.store.ConfirmEmail(ctx, HashToken(token))
+	if err != nil {
+		return "", 0, err
+	}
+	metrics.EmailConfirmationsConfirmed.Inc()
+	c.logger.Info("email confirmed", zap.String("email", email), zap.Int("released", released))
+	return email, released, nil
+}
+
+// Prune удаляет запросы, автор которых не подтвердил email за HoldTTL
+func (c *Confirmer) Prune(ctx context.Context) (int64, error) {
+	pruned, err := c.store.PruneHolds(ctx, c.now().Add(-c.cfg.HoldTTL))
+	if err != nil {
+		return 0, err
+	}
+	if pruned > 0 {
+		c.logger.Info("unconfirmed verification requests dropped", zap.Int64("count", pruned))
+	}
+	return pruned, nil
+}
+
+// HashToken возвращает хэш токена, под которым он хранится в базе
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// confirmLink добавляет токен к адресу подтверждения, сохраняя его параметры
+func confirmLink(confirmURL, token string) (string, error) {
+	u, err := url.Parse(confirmURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid confirmation url: %w", err)
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
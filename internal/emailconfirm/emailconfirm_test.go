@@ -0,0 +1,170 @@
+package emailconfirm
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type recordingClient struct {
+	messaging.NATSClient
+	published []string
+}
+
+func (c *recordingClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	c.published = append(c.published, verification.ID)
+	return nil
+}
+
+type memoryStore struct {
+	confirmed map[string]bool
+	tokens    map[string]string
+	sentAt    map[string]time.Time
+	held      map[string][]*messaging.OutboxMessage
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{confirmed: map[string]bool{}, tokens: map[string]string{}, sentAt: map[string]time.Time{}, held: map[string][]*messaging.OutboxMessage{}}
+}
+
+func (s *memoryStore) IsEmailConfirmed(ctx context.Context, email string) (bool, error) {
+	return s.confirmed[email], nil
+}
+
+func (s *memoryStore) HoldUntilConfirmed(ctx context.Context, email string, msg *messaging.OutboxMessage) error {
+	s.held[email] = append(s.held[email], msg)
+	return nil
+}
+
+func (s *memoryStore) IssueConfirmationToken(ctx context.Context, email, tokenHash string, expiresAt, resendBefore time.Time) (bool, error) {
+	if sentAt, ok := s.sentAt[email]; ok && !sentAt.Before(resendBefore) {
+		return false, nil
+	}
+	s.tokens[tokenHash] = email
+	s.sentAt[email] = resendBefore
+	return true, nil
+}
+
+func (s *memoryStore) ConfirmEmail(ctx context.Context, tokenHash string) (string, int, error) {
+	email := s.tokens[tokenHash]
+	s.confirmed[email] = true
+	released := len(s.held[email])
+	delete(s.held, email)
+	return email, released, nil
+}
+
+func (s *memoryStore) PruneHolds(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+type mail struct {
+	to, body string
+}
+
+type recordingSender struct {
+	sent []mail
+}
+
+func (s *recordingSender) Send(ctx context.Context, to, subject, body string) error {
+	s.sent = append(s.sent, mail{to: to, body: body})
+	return nil
+}
+
+func newConfirmer(t *testing.T, store *memoryStore, sender *recordingSender) *Confirmer {
+	cfg := config.EmailConfirmationConfig{
+		Enabled:     true,
+		ConfirmURL:  "https://gateway.example.com/confirm-email?lang=ru",
+		TokenTTL:    24 * time.Hour,
+		ResendAfter: 10 * time.Minute,
+		HoldTTL:     7 * 24 * time.Hour,
+	}
+	confirmer := NewConfirmer(store, sender, cfg, zaptest.NewLogger(t))
+	confirmer.now = func() time.Time { return time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC) }
+	return confirmer
+}
+
+func TestClientHoldsUntilConfirmed(t *testing.T) {
+	store, sender, inner := newMemoryStore(), &recordingSender{}, &recordingClient{}
+	confirmer := newConfirmer(t, store, sender)
+	client := NewClient(inner, confirmer, zaptest.NewLogger(t))
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "new@bank.ru"})
+
+	first := &domain.Verification{ID: "v-1", INN: "7707083893", Status: domain.StatusInProcess, AuthorEmail: "new@bank.ru"}
+	second := &domain.Verification{ID: "v-2", INN: "7707083893", Status: domain.StatusInProcess, AuthorEmail: "new@bank.ru"}
+	for _, verification := range []*domain.Verification{first, second} {
+		if err := client.PublishVerificationRequest(ctx, verification); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if verification.Status != domain.StatusPendingEmailConfirmation {
+			t.Errorf("expected %s to await email confirmation, but got %s", verification.ID, verification.Status)
+		}
+	}
+	if len(inner.published) != 0 || len(store.held["new@bank.ru"]) != 2 {
+		t.Fatalf("expected requests to be held, but published %v", inner.published)
+	}
+
+	// Второй запрос не отправляет еще одно письмо
+	if len(sender.sent) != 1 || sender.sent[0].to != "new@bank.ru" || !strings.Contains(sender.sent[0].body, "16.01.2024 10:00 UTC") {
+		t.Fatalf("expected one confirmation email, but got %+v", sender.sent)
+	}
+	link := sender.sent[0].body[strings.Index(sender.sent[0].body, "https://"):]
+	link = link[:strings.Index(link, "\n")]
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Query().Get("lang") != "ru" || parsed.Query().Get("token") == "" {
+		t.Fatalf("expected confirmation link with token, but got %q", link)
+	}
+
+	email, released, err := confirmer.Confirm(context.Background(), parsed.Query().Get("token"))
+	if err != nil || email != "new@bank.ru" || released != 2 {
+		t.Fatalf("expected 2 released requests of new@bank.ru, but got %q %d %v", email, released, err)
+	}
+
+	third := &domain.Verification{ID: "v-3", INN: "7707083893", Status: domain.StatusInProcess, AuthorEmail: "new@bank.ru"}
+	client.PublishVerificationRequest(ctx, third)
+	if third.Status != domain.StatusInProcess || len(inner.published) != 1 {
+		t.Errorf("expected confirmed author to be published, but got %s", third.Status)
+	}
+}
+
+func TestClientSkipsConfirmation(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{name: "background_job", ctx: context.Background()},
+		{name: "api_key", ctx: auth.WithPrincipal(context.Background(), &auth.Principal{Email: "new@bank.ru", Scope: &auth.Scope{KeyID: "key-1"}})},
+		{name: "sandbox", ctx: auth.WithPrincipal(context.Background(), &auth.Principal{Email: "new@bank.ru", Sandbox: true})},
+		{name: "other_author", ctx: auth.WithPrincipal(context.Background(), &auth.Principal{Email: "admin@bank.ru"})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, sender, inner := newMemoryStore(), &recordingSender{}, &recordingClient{}
+			client := NewClient(inner, newConfirmer(t, store, sender), zaptest.NewLogger(t))
+
+			verification := &domain.Verification{ID: "v-1", Status: domain.StatusInProcess, AuthorEmail: "new@bank.ru"}
+			if err := client.PublishVerificationRequest(tt.ctx, verification); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(inner.published) != 1 || len(sender.sent) != 0 || verification.Status != domain.StatusInProcess {
+				t.Errorf("expected request to be published without confirmation, but got %s", verification.Status)
+			}
+		})
+	}
+}
+
+func TestConfirmEmptyToken(t *testing.T) {
+	confirmer := newConfirmer(t, newMemoryStore(), &recordingSender{})
+	if _, _, err := confirmer.Confirm(context.Background(), ""); err == nil {
+		t.Error("expected error for empty token")
+	}
+}
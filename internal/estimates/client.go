@@ -0,0 +1,52 @@
+package estimates
+
+import (
+	"context"
+
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+
+	"go.uber.org/zap"
+)
+
+type estimatingClient struct {
+	messaging.NATSClient
+	estimator *Estimator
+	logger    *zap.Logger
+}
+
+// NewClient оценивает время завершения каждой опубликованной проверки и возвращает его в
+// EstimatedCompletionAt. Проверки, которые публикация сразу завершила (песочница, кэш ненайденных
+// ИНН), не оцениваются. Ошибка оценки не мешает созданию проверки.
+func NewClient(client messaging.NATSClient, estimator *Estimator, logger *zap.Logger) messaging.NATSClient {
+	return &estimatingClient{
+		NATSClient: client,
+		estimator:  estimator,
+		logger:     logger,
+	}
+}
+
+func (c *estimatingClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
+	return c.PublishVerificationRequestWithPriority(ctx, verification, messaging.PriorityNormal)
+}
+
+func (c *estimatingClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	if err := c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority); err != nil {
+		return err
+	}
+	if verification.Sandbox || !inProgress(verification.Status) {
+		return nil
+	}
+
+	completion, err := c.estimator.Estimate(ctx, verification)
+	if err != nil {
+		c.logger.Warn("failed to estimate verification completion", zap.Error(err), zap.String("verification_id", verification.ID))
+		return nil
+	}
+	verification.EstimatedCompletionAt = &completion
+	return nil
+}
+
+func inProgress(status domain.Status) bool {
+	return status == domain.StatusPending || status == domain.StatusInProcess || status == domain.StatusProcessing
+}
@@ -0,0 +1,242 @@
+package estimates
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/catalog"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type memoryStore struct {
+	saved   map[string]*repository.CompletionEstimate
+	pending []*repository.PendingEstimate
+	pruned  int
+	err     error
+}
+
+func (s *memoryStore) SaveEstimate(ctx context.Context, estimate *repository.CompletionEstimate) error {
+	if s.err != nil {
+		return s.err
+	}
+	saved := *estimate
+	s.saved[estimate.VerificationID] = &saved
+	return nil
+}
+
+func (s *memoryStore) ListPendingEstimates(ctx context.Context, limit int) ([]*repository.PendingEstimate, error) {
+	return s.pending, nil
+}
+
+func (s *memoryStore) PruneEstimates(ctx context.Context) (int64, error) {
+	s.pruned++
+	return 0, nil
+}
+
+type staticLatencies struct {
+	latencies map[string]*repository.CompletionLatency
+	calls     int
+}
+
+func (l *staticLatencies) GetCompletionLatencies(ctx context.Context, percentile float64, since time.Time) (map[string]*repository.CompletionLatency, error) {
+	l.calls++
+	return l.latencies, nil
+}
+
+type staticBacklog struct {
+	queued, capacity int
+}
+
+func (b staticBacklog) Backlog() (int, int) {
+	return b.queued, b.capacity
+}
+
+var now = time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+func newEstimator(t *testing.T, store *memoryStore, backlog Backlog) (*Estimator, *staticLatencies) {
+	latencies := &staticLatencies{latencies: map[string]*repository.CompletionLatency{
+		"BASIC_INFORMATION":    {MedianSeconds: 4, PercentileSeconds: 10},
+		"ARBITRAGE_STATISTICS": {MedianSeconds: 60, PercentileSeconds: 180},
+	}}
+	cfg := config.EstimatesConfig{Enabled: true, Window: 7 * 24 * time.Hour, Percentile: 0.9, BatchSize: 100}
+	estimator := NewEstimator(store, latencies, catalog.DefaultRegistry(), backlog, cfg, zaptest.NewLogger(t))
+	estimator.now = func() time.Time { return now }
+	return estimator, latencies
+}
+
+func TestEstimate(t *testing.T) {
+	deferred := now.Add(5 * time.Minute)
+	tests := []struct {
+		name         string
+		verification *domain.Verification
+		backlog      Backlog
+		expected     time.Time
+	}{
+		{
+			name:         "slowest_type",
+			verification: &domain.Verification{ID: "v-1", RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation, domain.DataTypeArbitrageStatistics}},
+			expected:     now.Add(3 * time.Minute),
+		},
+		{
+			// Без завершенных проверок типа используется типичная задержка из каталога
+			name:         "catalog_fallback",
+			verification: &domain.Verification{ID: "v-1", RequestedDataTypes: []domain.DataType{domain.DataTypeActivities}},
+			expected:     now.Add(5 * time.Second),
+		},
+		{
+			name:         "deferred_publish",
+			verification: &domain.Verification{ID: "v-1", RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation}, ExpectedStartAt: &deferred},
+			expected:     deferred.Add(10 * time.Second),
+		},
+		{
+			// 20 запросов на 10 мест - две партии по медиане 60 секунд
+			name:         "worker_queue",
+			verification: &domain.Verification{ID: "v-1", RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation, domain.DataTypeArbitrageStatistics}},
+			backlog:      staticBacklog{queued: 20, capacity: 10},
+			expected:     now.Add(2*time.Minute + 3*time.Minute),
+		},
+		{
+			name:         "empty_pool",
+			verification: &domain.Verification{ID: "v-1", RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation}},
+			backlog:      staticBacklog{},
+			expected:     now.Add(10 * time.Second),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryStore{saved: map[string]*repository.CompletionEstimate{}}
+			estimator, _ := newEstimator(t, store, tt.backlog)
+
+			got, err := estimator.Estimate(context.Background(), tt.verification)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tt.expected) {
+				t.Errorf("expected %s, but got %s", tt.expected, got)
+			}
+			if saved := store.saved["v-1"]; saved == nil || !saved.EstimatedCompletionAt.Equal(tt.expected) {
+				t.Errorf("expected estimate to be saved, but got %+v", saved)
+			}
+		})
+	}
+}
+
+func TestEstimateCachesLatencies(t *testing.T) {
+	estimator, latencies := newEstimator(t, &memoryStore{saved: map[string]*repository.CompletionEstimate{}}, nil)
+	verification := &domain.Verification{ID: "v-1", RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation}}
+
+	estimator.Estimate(context.Background(), verification)
+	estimator.Estimate(context.Background(), verification)
+	if latencies.calls != 1 {
+		t.Errorf("expected latencies to be read once, but got %d reads", latencies.calls)
+	}
+
+	now = now.Add(latencyCacheTTL)
+	defer func() { now = now.Add(-latencyCacheTTL) }()
+	estimator.Estimate(context.Background(), verification)
+	if latencies.calls != 2 {
+		t.Errorf("expected latencies to be reread after %s, but got %d reads", latencyCacheTTL, latencies.calls)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	store := &memoryStore{
+		saved: map[string]*repository.CompletionEstimate{},
+		pending: []*repository.PendingEstimate{
+			{
+				// Арбитраж уже доставлен, ждем только основные сведения
+				CompletionEstimate: repository.CompletionEstimate{VerificationID: "v-1", ProcessingStartAt: now.Add(-5 * time.Second), EstimatedCompletionAt: now.Add(3 * time.Minute)},
+				Undelivered:        []domain.DataType{domain.DataTypeBasicInformation},
+			},
+			{
+				CompletionEstimate: repository.CompletionEstimate{VerificationID: "v-2", ProcessingStartAt: now.Add(-time.Hour), EstimatedCompletionAt: now.Add(-57 * time.Minute)},
+				Undelivered:        []domain.DataType{domain.DataTypeArbitrageStatistics},
+			},
+		},
+	}
+	estimator, _ := newEstimator(t, store, nil)
+
+	count, err := estimator.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 || store.pruned != 1 {
+		t.Fatalf("expected 2 refreshed estimates after pruning, but got %d (pruned %d)", count, store.pruned)
+	}
+	if got := store.saved["v-1"].EstimatedCompletionAt; !got.Equal(now.Add(5 * time.Second)) {
+		t.Errorf("expected estimate from undelivered types, but got %s", got)
+	}
+	// Задержавшаяся проверка ожидается сейчас, а не в прошлом
+	if got := store.saved["v-2"].EstimatedCompletionAt; !got.Equal(now) {
+		t.Errorf("expected overdue estimate to be now, but got %s", got)
+	}
+}
+
+type recordingClient struct {
+	messaging.NATSClient
+	complete bool
+	err      error
+}
+
+func (c *recordingClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	if c.complete {
+		verification.Status = domain.StatusCompleted
+		verification.Sandbox = true
+	}
+	return c.err
+}
+
+func TestClient(t *testing.T) {
+	newVerification := func() *domain.Verification {
+		return &domain.Verification{ID: "v-1", Status: domain.StatusInProcess, RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation}}
+	}
+
+	t.Run("estimated", func(t *testing.T) {
+		estimator, _ := newEstimator(t, &memoryStore{saved: map[string]*repository.CompletionEstimate{}}, nil)
+		verification := newVerification()
+		if err := NewClient(&recordingClient{}, estimator, zaptest.NewLogger(t)).PublishVerificationRequest(context.Background(), verification); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if verification.EstimatedCompletionAt == nil || !verification.EstimatedCompletionAt.Equal(now.Add(10*time.Second)) {
+			t.Errorf("expected estimated completion, but got %v", verification.EstimatedCompletionAt)
+		}
+	})
+
+	t.Run("completed_on_publish", func(t *testing.T) {
+		store := &memoryStore{saved: map[string]*repository.CompletionEstimate{}}
+		estimator, _ := newEstimator(t, store, nil)
+		verification := newVerification()
+		NewClient(&recordingClient{complete: true}, estimator, zaptest.NewLogger(t)).PublishVerificationRequest(context.Background(), verification)
+		if verification.EstimatedCompletionAt != nil || len(store.saved) != 0 {
+			t.Errorf("expected no estimate for completed verification, but got %v", verification.EstimatedCompletionAt)
+		}
+	})
+
+	t.Run("publish_failed", func(t *testing.T) {
+		estimator, _ := newEstimator(t, &memoryStore{saved: map[string]*repository.CompletionEstimate{}}, nil)
+		publishErr := errors.New("nats down")
+		err := NewClient(&recordingClient{err: publishErr}, estimator, zaptest.NewLogger(t)).PublishVerificationRequest(context.Background(), newVerification())
+		if !errors.Is(err, publishErr) {
+			t.Errorf("expected publish error, but got %v", err)
+		}
+	})
+
+	t.Run("estimate_failed", func(t *testing.T) {
+		estimator, _ := newEstimator(t, &memoryStore{saved: map[string]*repository.CompletionEstimate{}, err: errors.New("db down")}, nil)
+		verification := newVerification()
+		if err := NewClient(&recordingClient{}, estimator, zaptest.NewLogger(t)).PublishVerificationRequest(context.Background(), verification); err != nil {
+			t.Fatalf("expected verification to be created without estimate, but got %v", err)
+		}
+		if verification.EstimatedCompletionAt != nil {
+			t.Errorf("expected no estimate, but got %v", verification.EstimatedCompletionAt)
+		}
+	})
+}
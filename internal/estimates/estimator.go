@@ -0,0 +1,197 @@
+// Package estimates оценивает, когда завершится проверка: по задержкам запрошенных типов данных
+// у недавно завершенных проверок и очередям воркеров. Оценка сохраняется при публикации запроса
+// и пересчитывается задачей completion_estimates по мере доставки данных.
+package estimates
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/catalog"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// latencyCacheTTL как долго используются прочитанные задержки типов данных. Они считаются
+// по завершенным проверкам за Window и за минуту почти не меняются.
+const latencyCacheTTL = time.Minute
+
+// Store хранилище оценок
+type Store interface {
+	SaveEstimate(ctx context.Context, estimate *repository.CompletionEstimate) error
+	ListPendingEstimates(ctx context.Context, limit int) ([]*repository.PendingEstimate, error)
+	PruneEstimates(ctx context.Context) (int64, error)
+}
+
+// Latencies статистика времени выполнения типов данных
+type Latencies interface {
+	GetCompletionLatencies(ctx context.Context, percentile float64, since time.Time) (map[string]*repository.CompletionLatency, error)
+}
+
+// Backlog очереди воркеров, messaging.WorkerPool
+type Backlog interface {
+	Backlog() (queued, capacity int)
+}
+
+// Estimator рассчитывает ожидаемое время завершения проверок
+type Estimator struct {
+	store     Store
+	latencies Latencies
+	registry  *catalog.Registry
+	backlog   Backlog
+	cfg       config.EstimatesConfig
+	logger    *zap.Logger
+	now       func() time.Time
+
+	mu       sync.Mutex
+	cached   map[string]*repository.CompletionLatency
+	cachedAt time.Time
+}
+
+// NewEstimator создает оценщик. Типы данных без завершенных проверок за cfg.Window оцениваются
+// по типичной задержке из registry. backlog может быть nil, если запросы не распределяются по воркерам.
+func NewEstimator(store Store, latencies Latencies, registry *catalog.Registry, backlog Backlog, cfg config.EstimatesConfig, logger *zap.Logger) *Estimator {
+	return &Estimator{
+		store:     store,
+		latencies: latencies,
+		registry:  registry,
+		backlog:   backlog,
+		cfg:       cfg,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Estimate рассчитывает и сохраняет время завершения опубликованной проверки. Обработка
+// начинается в ExpectedStartAt отложенной публикации или сейчас и ждет, пока воркеры разберут
+// свою очередь, а завершается, когда придут данные самого медленного запрошенного типа.
+func (e *Estimator) Estimate(ctx context.Context, verification *domain.Verification) (time.Time, error) {
+	latencies, err := e.loadLatencies(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	start := e.now().UTC()
+	if verification.ExpectedStartAt != nil && verification.ExpectedStartAt.After(start) {
+		start = verification.ExpectedStartAt.UTC()
+	}
+	start = start.Add(e.queueWait(latencies, verification.RequestedDataTypes))
+
+	estimate := &repository.CompletionEstimate{
+		VerificationID:        verification.ID,
+		ProcessingStartAt:     start,
+		EstimatedCompletionAt: start.Add(e.slowest(latencies, verification.RequestedDataTypes)).Truncate(time.Second),
+	}
+	if err := e.store.SaveEstimate(ctx, estimate); err != nil {
+		return time.Time{}, err
+	}
+	return estimate.EstimatedCompletionAt, nil
+}
+
+// Refresh пересчитывает оценки незавершенных проверок по типам данных, которые еще не доставлены,
+// и удаляет оценки завершенных. Оценка не бывает раньше текущего момента: проверка, которая
+// задерживается, ожидается с минуты на минуту. Возвращает число пересчитанных оценок.
+func (e *Estimator) Refresh(ctx context.Context) (int, error) {
+	if _, err := e.store.PruneEstimates(ctx); err != nil {
+		return 0, err
+	}
+
+	pending, err := e.store.ListPendingEstimates(ctx, e.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	latencies, err := e.loadLatencies(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := e.now().UTC().Truncate(time.Second)
+	for _, estimate := range pending {
+		completion := estimate.ProcessingStartAt.Add(e.slowest(latencies, estimate.Undelivered)).Truncate(time.Second)
+		if completion.Before(now) {
+			completion = now
+		}
+		estimate.EstimatedCompletionAt = completion
+		if err := e.store.SaveEstimate(ctx, &estimate.CompletionEstimate); err != nil {
+			return 0, err
+		}
+	}
+
+	e.logger.Debug("completion estimates refreshed", zap.Int("count", len(pending)))
+	return len(pending), nil
+}
+
+// loadLatencies возвращает задержки типов данных, перечитывая их не чаще latencyCacheTTL
+func (e *Estimator) loadLatencies(ctx context.Context) (map[string]*repository.CompletionLatency, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	if e.cached != nil && now.Sub(e.cachedAt) < latencyCacheTTL {
+		return e.cached, nil
+	}
+
+	latencies, err := e.latencies.GetCompletionLatencies(ctx, e.cfg.Percentile, now.Add(-e.cfg.Window))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load latencies for completion estimate: %w", err)
+	}
+	e.cached, e.cachedAt = latencies, now
+	return latencies, nil
+}
+
+// slowest возвращает время выполнения самого медленного из типов данных по перцентилю
+func (e *Estimator) slowest(latencies map[string]*repository.CompletionLatency, dataTypes []domain.DataType) time.Duration {
+	var slowest time.Duration
+	for _, dataType := range dataTypes {
+		duration := e.typicalLatency(dataType)
+		if latency, ok := latencies[string(dataType)]; ok {
+			duration = seconds(latency.PercentileSeconds)
+		}
+		slowest = max(slowest, duration)
+	}
+	return slowest
+}
+
+// queueWait оценивает, сколько проверка простоит в очереди воркеров: очередь разбирается
+// партиями по их емкости, каждая партия - за медианное время самого медленного типа
+func (e *Estimator) queueWait(latencies map[string]*repository.CompletionLatency, dataTypes []domain.DataType) time.Duration {
+	if e.backlog == nil {
+		return 0
+	}
+	queued, capacity := e.backlog.Backlog()
+	if queued == 0 || capacity == 0 {
+		return 0
+	}
+
+	var median time.Duration
+	for _, dataType := range dataTypes {
+		duration := e.typicalLatency(dataType)
+		if latency, ok := latencies[string(dataType)]; ok {
+			duration = seconds(latency.MedianSeconds)
+		}
+		median = max(median, duration)
+	}
+	return median * time.Duration(queued) / time.Duration(capacity)
+}
+
+func (e *Estimator) typicalLatency(dataType domain.DataType) time.Duration {
+	entry, ok := e.registry.Lookup(model.VerificationDataType(dataType))
+	if !ok {
+		return 0
+	}
+	return entry.TypicalLatency
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,21 @@
+package estimates
+
+import "context"
+
+// RefreshJob пересчитывает оценки незавершенных проверок по мере доставки данных
+type RefreshJob struct {
+	estimator *Estimator
+}
+
+func NewRefreshJob(estimator *Estimator) *RefreshJob {
+	return &RefreshJob{estimator: estimator}
+}
+
+func (j *RefreshJob) Name() string {
+	return "completion_estimates"
+}
+
+func (j *RefreshJob) Run(ctx context.Context) error {
+	_, err := j.estimator.Refresh(ctx)
+	return err
+}
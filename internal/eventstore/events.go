@@ -0,0 +1,146 @@
+// Package eventstore описывает события жизненного цикла проверки и проекцию текущего состояния из них.
+// Используется в режиме хранения событий, когда таблица verifications становится моделью чтения.
+package eventstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+)
+
+// EventType тип события проверки
+type EventType string
+
+const (
+	EventCreated       EventType = "created"
+	EventDataReceived  EventType = "data_received"
+	EventStatusChanged EventType = "status_changed"
+)
+
+// ErrVersionConflict поток событий изменился с момента чтения
+var ErrVersionConflict = errors.New("event stream version conflict")
+
+// Event неизменяемое событие в потоке проверки. Version начинается с 1.
+type Event struct {
+	VerificationID string
+	Version        int
+	Type           EventType
+	Payload        json.RawMessage
+	OccurredAt     time.Time
+}
+
+type CreatedPayload struct {
+	INN                string                       `json:"inn"`
+	AuthorEmail        string                       `json:"author_email"`
+	RequestedDataTypes []model.VerificationDataType `json:"requested_data_types"`
+}
+
+type DataReceivedPayload struct {
+	DataType model.VerificationDataType `json:"data_type"`
+}
+
+type StatusChangedPayload struct {
+	Status           model.VerificationStatus     `json:"status"`
+	RiskLevel        *model.RiskLevel             `json:"risk_level,omitempty"`
+	MissingDataTypes []model.VerificationDataType `json:"missing_data_types,omitempty"`
+}
+
+// NewEvent создает событие с сериализованными данными. Версию назначает хранилище.
+func NewEvent(verificationID string, eventType EventType, payload any) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+	return Event{
+		VerificationID: verificationID,
+		Type:           eventType,
+		Payload:        data,
+		OccurredAt:     time.Now().UTC(),
+	}, nil
+}
+
+// State текущее состояние проверки, восстановленное из событий
+type State struct {
+	ID                 string
+	INN                string
+	AuthorEmail        string
+	Status             model.VerificationStatus
+	RequestedDataTypes []model.VerificationDataType
+	DeliveredDataTypes []model.VerificationDataType
+	MissingDataTypes   []model.VerificationDataType
+	RiskLevel          *model.RiskLevel
+	Version            int
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// HasDelivered сообщает, поступали ли данные указанного типа
+func (s *State) HasDelivered(dataType model.VerificationDataType) bool {
+	for _, delivered := range s.DeliveredDataTypes {
+		if delivered == dataType {
+			return true
+		}
+	}
+	return false
+}
+
+// Project восстанавливает состояние проверки из упорядоченного потока событий.
+// Возвращает nil для пустого потока.
+func Project(events []Event) (*State, error) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+	if events[0].Type != EventCreated {
+		return nil, fmt.Errorf("event stream %s must start with %s event, got %s", events[0].VerificationID, EventCreated, events[0].Type)
+	}
+
+	state := &State{ID: events[0].VerificationID}
+	for _, event := range events {
+		if err := state.apply(event); err != nil {
+			return nil, fmt.Errorf("failed to apply event %d of stream %s: %w", event.Version, event.VerificationID, err)
+		}
+	}
+	return state, nil
+}
+
+func (s *State) apply(event Event) error {
+	switch event.Type {
+	case EventCreated:
+		var payload CreatedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		s.INN = payload.INN
+		s.AuthorEmail = payload.AuthorEmail
+		s.RequestedDataTypes = payload.RequestedDataTypes
+		s.Status = model.VerificationStatusInProcess
+		s.CreatedAt = event.OccurredAt
+	case EventDataReceived:
+		var payload DataReceivedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		if !s.HasDelivered(payload.DataType) {
+			s.DeliveredDataTypes = append(s.DeliveredDataTypes, payload.DataType)
+		}
+	case EventStatusChanged:
+		var payload StatusChangedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		s.Status = payload.Status
+		if payload.RiskLevel != nil {
+			s.RiskLevel = payload.RiskLevel
+		}
+		s.MissingDataTypes = payload.MissingDataTypes
+	default:
+		return fmt.Errorf("unknown event type %s", event.Type)
+	}
+
+	s.Version = event.Version
+	s.UpdatedAt = event.OccurredAt
+	return nil
+}
@@ -0,0 +1,90 @@
+package eventstore
+
+import (
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+)
+
+func mustEvent(t *testing.T, version int, eventType EventType, payload any) Event {
+	t.Helper()
+	event, err := NewEvent("test-id", eventType, payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	event.Version = version
+	return event
+}
+
+func TestProject(t *testing.T) {
+	high := model.RiskLevelHigh
+
+	events := []Event{
+		mustEvent(t, 1, EventCreated, CreatedPayload{
+			INN:         "1234567890",
+			AuthorEmail: "test@example.com",
+			RequestedDataTypes: []model.VerificationDataType{
+				model.VerificationDataTypeBasicInformation,
+				model.VerificationDataTypeArbitrageStatistics,
+			},
+		}),
+		mustEvent(t, 2, EventDataReceived, DataReceivedPayload{DataType: model.VerificationDataTypeBasicInformation}),
+		mustEvent(t, 3, EventDataReceived, DataReceivedPayload{DataType: model.VerificationDataTypeBasicInformation}),
+		mustEvent(t, 4, EventStatusChanged, StatusChangedPayload{
+			Status:           model.VerificationStatusPartiallyCompleted,
+			RiskLevel:        &high,
+			MissingDataTypes: []model.VerificationDataType{model.VerificationDataTypeArbitrageStatistics},
+		}),
+	}
+
+	state, err := Project(events)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if state.INN != "1234567890" || state.AuthorEmail != "test@example.com" {
+		t.Errorf("unexpected created fields: %+v", state)
+	}
+	if state.Status != model.VerificationStatusPartiallyCompleted {
+		t.Errorf("expected status '%s', but got '%s'", model.VerificationStatusPartiallyCompleted, state.Status)
+	}
+	if len(state.DeliveredDataTypes) != 1 {
+		t.Errorf("expected duplicate delivery to be ignored, got %v", state.DeliveredDataTypes)
+	}
+	if state.RiskLevel == nil || *state.RiskLevel != model.RiskLevelHigh {
+		t.Errorf("expected risk level HIGH, but got %v", state.RiskLevel)
+	}
+	if state.Version != 4 {
+		t.Errorf("expected version 4, but got %d", state.Version)
+	}
+
+	// Повторная смена статуса без уровня риска не сбрасывает его
+	events = append(events, mustEvent(t, 5, EventStatusChanged, StatusChangedPayload{Status: model.VerificationStatusInProcess}))
+	state, err = Project(events)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.RiskLevel == nil {
+		t.Error("expected risk level to be kept")
+	}
+	if state.Status != model.VerificationStatusInProcess {
+		t.Errorf("expected status '%s', but got '%s'", model.VerificationStatusInProcess, state.Status)
+	}
+}
+
+func TestProjectInvalidStreams(t *testing.T) {
+	if state, err := Project(nil); state != nil || err != nil {
+		t.Errorf("expected nil state for empty stream, got %v, %v", state, err)
+	}
+
+	_, err := Project([]Event{mustEvent(t, 1, EventDataReceived, DataReceivedPayload{DataType: model.VerificationDataTypeActivities})})
+	if err == nil {
+		t.Error("expected error for stream without created event, but got nil")
+	}
+
+	created := mustEvent(t, 1, EventCreated, CreatedPayload{INN: "1234567890"})
+	_, err = Project([]Event{created, {VerificationID: "test-id", Version: 2, Type: "archived", Payload: []byte(`{}`)}})
+	if err == nil {
+		t.Error("expected error for unknown event type, but got nil")
+	}
+}
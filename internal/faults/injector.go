@@ -0,0 +1,139 @@
+// Package faults внедряет искусственные задержки и ошибки в вызовы внешних зависимостей.
+// Используется на стендах для проверки ретраев, таймаутов и размыкателей цепи.
+package faults
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Target зависимость, в вызовы которой внедряются сбои
+type Target string
+
+const (
+	TargetRepository Target = "repository"
+	TargetNATS       Target = "nats"
+	TargetWebhook    Target = "webhook"
+)
+
+// ErrInjected ошибка, возвращаемая при внедренном сбое
+var ErrInjected = errors.New("injected fault")
+
+// Rule правило внедрения сбоев для одной зависимости.
+// Проценты задаются в диапазоне 0..100.
+type Rule struct {
+	ErrorPercent   float64       `json:"error_percent"`
+	LatencyPercent float64       `json:"latency_percent"`
+	Latency        time.Duration `json:"latency"`
+}
+
+// Validate проверяет корректность правила
+func (r Rule) Validate() error {
+	if r.ErrorPercent < 0 || r.ErrorPercent > 100 {
+		return fmt.Errorf("error_percent must be between 0 and 100, got %v", r.ErrorPercent)
+	}
+	if r.LatencyPercent < 0 || r.LatencyPercent > 100 {
+		return fmt.Errorf("latency_percent must be between 0 and 100, got %v", r.LatencyPercent)
+	}
+	if r.Latency < 0 {
+		return fmt.Errorf("latency must be non-negative, got %s", r.Latency)
+	}
+	return nil
+}
+
+// Injector хранит правила и решает, внедрять ли сбой в очередной вызов
+type Injector struct {
+	mu      sync.RWMutex
+	enabled bool
+	rules   map[Target]Rule
+	roll    func() float64
+}
+
+// NewInjector создает инжектор. Выключенный инжектор никогда не внедряет сбои.
+func NewInjector(enabled bool) *Injector {
+	return &Injector{
+		enabled: enabled,
+		rules:   make(map[Target]Rule),
+		roll:    func() float64 { return rand.Float64() * 100 },
+	}
+}
+
+// Enabled сообщает, включено ли внедрение сбоев
+func (i *Injector) Enabled() bool {
+	return i != nil && i.enabled
+}
+
+// SetRule задает правило для зависимости
+func (i *Injector) SetRule(target Target, rule Rule) error {
+	if !validTarget(target) {
+		return fmt.Errorf("unknown fault target: %s", target)
+	}
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.rules[target] = rule
+	return nil
+}
+
+// Rules возвращает копию действующих правил
+func (i *Injector) Rules() map[Target]Rule {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	rules := make(map[Target]Rule, len(i.rules))
+	for target, rule := range i.rules {
+		rules[target] = rule
+	}
+	return rules
+}
+
+// Reset удаляет все правила
+func (i *Injector) Reset() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.rules = make(map[Target]Rule)
+}
+
+// Inject применяет правило зависимости к вызову: при необходимости ждет и/или возвращает ErrInjected
+func (i *Injector) Inject(ctx context.Context, target Target) error {
+	if !i.Enabled() {
+		return nil
+	}
+
+	i.mu.RLock()
+	rule, ok := i.rules[target]
+	i.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	if rule.Latency > 0 && rule.LatencyPercent > 0 && i.roll() < rule.LatencyPercent {
+		timer := time.NewTimer(rule.Latency)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if rule.ErrorPercent > 0 && i.roll() < rule.ErrorPercent {
+		return fmt.Errorf("%w: %s", ErrInjected, target)
+	}
+	return nil
+}
+
+func validTarget(target Target) bool {
+	switch target {
+	case TargetRepository, TargetNATS, TargetWebhook:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package faults
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInject(t *testing.T) {
+	tests := []struct {
+		name          string
+		enabled       bool
+		rule          *Rule
+		roll          float64
+		expectedError bool
+	}{
+		{
+			name:    "disabled_injector",
+			enabled: false,
+			rule:    &Rule{ErrorPercent: 100},
+			roll:    0,
+		},
+		{
+			name:    "no_rule",
+			enabled: true,
+			roll:    0,
+		},
+		{
+			name:          "error_injected",
+			enabled:       true,
+			rule:          &Rule{ErrorPercent: 50},
+			roll:          10,
+			expectedError: true,
+		},
+		{
+			name:    "error_not_injected",
+			enabled: true,
+			rule:    &Rule{ErrorPercent: 50},
+			roll:    60,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			injector := NewInjector(tt.enabled)
+			injector.roll = func() float64 { return tt.roll }
+			if tt.rule != nil {
+				if err := injector.SetRule(TargetRepository, *tt.rule); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			err := injector.Inject(context.Background(), TargetRepository)
+			if tt.expectedError {
+				if !errors.Is(err, ErrInjected) {
+					t.Errorf("expected injected error, but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestInjectLatencyRespectsContext(t *testing.T) {
+	injector := NewInjector(true)
+	injector.roll = func() float64 { return 0 }
+	if err := injector.SetRule(TargetNATS, Rule{LatencyPercent: 100, Latency: time.Minute}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := injector.Inject(ctx, TargetNATS)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, but got %v", err)
+	}
+}
+
+func TestSetRuleValidation(t *testing.T) {
+	injector := NewInjector(true)
+
+	if err := injector.SetRule("database", Rule{}); err == nil {
+		t.Error("expected error for unknown target, but got nil")
+	}
+	if err := injector.SetRule(TargetNATS, Rule{ErrorPercent: 150}); err == nil {
+		t.Error("expected error for percent above 100, but got nil")
+	}
+	if err := injector.SetRule(TargetNATS, Rule{Latency: -time.Second}); err == nil {
+		t.Error("expected error for negative latency, but got nil")
+	}
+
+	if err := injector.SetRule(TargetWebhook, Rule{ErrorPercent: 5}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rules := injector.Rules(); len(rules) != 1 {
+		t.Errorf("expected 1 rule, but got %d", len(rules))
+	}
+
+	injector.Reset()
+	if rules := injector.Rules(); len(rules) != 0 {
+		t.Errorf("expected no rules after reset, but got %d", len(rules))
+	}
+}
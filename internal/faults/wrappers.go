@@ -0,0 +1,157 @@
+package faults
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/webhook"
+)
+
+// WrapVerificationRepository добавляет внедрение сбоев в вызовы репозитория проверок
+func WrapVerificationRepository(repo repository.VerificationRepository, injector *Injector) repository.VerificationRepository {
+	if !injector.Enabled() {
+		return repo
+	}
+	return &faultyVerificationRepository{VerificationRepository: repo, injector: injector}
+}
+
+type faultyVerificationRepository struct {
+	repository.VerificationRepository
+	injector *Injector
+}
+
+// injectRepositoryFault внедряет сбой репозитория. Внедренная ошибка выглядит как недоступность
+// базы, чтобы на нее реагировали повторы и размыкатель цепи.
+func injectRepositoryFault(ctx context.Context, injector *Injector) error {
+	err := injector.Inject(ctx, TargetRepository)
+	if errors.Is(err, ErrInjected) {
+		return &repository.Error{Kind: repository.ErrUnavailable, Err: err}
+	}
+	return err
+}
+
+func (r *faultyVerificationRepository) GetByID(ctx context.Context, id string) (*model.Verification, error) {
+	if err := injectRepositoryFault(ctx, r.injector); err != nil {
+		return nil, err
+	}
+	return r.VerificationRepository.GetByID(ctx, id)
+}
+
+func (r *faultyVerificationRepository) GetByIDWithData(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.Verification, error) {
+	if err := injectRepositoryFault(ctx, r.injector); err != nil {
+		return nil, err
+	}
+	return r.VerificationRepository.GetByIDWithData(ctx, id, dataTypes)
+}
+
+func (r *faultyVerificationRepository) GetAll(ctx context.Context, filter repository.VerificationFilter, limit *int32, offset *int32) ([]*model.Verification, error) {
+	if err := injectRepositoryFault(ctx, r.injector); err != nil {
+		return nil, err
+	}
+	return r.VerificationRepository.GetAll(ctx, filter, limit, offset)
+}
+
+func (r *faultyVerificationRepository) StreamAll(ctx context.Context, filter repository.VerificationFilter, limit *int32, offset *int32, fn func(*model.Verification) error) error {
+	if err := injectRepositoryFault(ctx, r.injector); err != nil {
+		return err
+	}
+	return r.VerificationRepository.StreamAll(ctx, filter, limit, offset, fn)
+}
+
+func (r *faultyVerificationRepository) GetPrevious(ctx context.Context, id string) (*model.Verification, error) {
+	if err := injectRepositoryFault(ctx, r.injector); err != nil {
+		return nil, err
+	}
+	return r.VerificationRepository.GetPrevious(ctx, id)
+}
+
+func (r *faultyVerificationRepository) GetAuthorsByINN(ctx context.Context, inn string) ([]string, error) {
+	if err := injectRepositoryFault(ctx, r.injector); err != nil {
+		return nil, err
+	}
+	return r.VerificationRepository.GetAuthorsByINN(ctx, inn)
+}
+
+func (r *faultyVerificationRepository) Create(ctx context.Context, verification *domain.Verification) error {
+	if err := injectRepositoryFault(ctx, r.injector); err != nil {
+		return err
+	}
+	return r.VerificationRepository.Create(ctx, verification)
+}
+
+func (r *faultyVerificationRepository) CreateWithRequest(ctx context.Context, verification *domain.Verification, msg *messaging.OutboxMessage, claimedUntil time.Time) (bool, error) {
+	if err := injectRepositoryFault(ctx, r.injector); err != nil {
+		return false, err
+	}
+	return r.VerificationRepository.CreateWithRequest(ctx, verification, msg, claimedUntil)
+}
+
+func (r *faultyVerificationRepository) UpdateRiskLevel(ctx context.Context, id string, riskLevel model.RiskLevel) error {
+	if err := injectRepositoryFault(ctx, r.injector); err != nil {
+		return err
+	}
+	return r.VerificationRepository.UpdateRiskLevel(ctx, id, riskLevel)
+}
+
+func (r *faultyVerificationRepository) UpdateStatus(ctx context.Context, id string, status model.VerificationStatus, errorMessage *string, changedAt time.Time) error {
+	if err := injectRepositoryFault(ctx, r.injector); err != nil {
+		return err
+	}
+	return r.VerificationRepository.UpdateStatus(ctx, id, status, errorMessage, changedAt)
+}
+
+func (r *faultyVerificationRepository) GetMonitoringCandidates(ctx context.Context, olderThan time.Time, limit int) ([]*repository.MonitoringCandidate, error) {
+	if err := injectRepositoryFault(ctx, r.injector); err != nil {
+		return nil, err
+	}
+	return r.VerificationRepository.GetMonitoringCandidates(ctx, olderThan, limit)
+}
+
+// WrapNATSClient добавляет внедрение сбоев в публикацию запросов в NATS
+func WrapNATSClient(client messaging.NATSClient, injector *Injector) messaging.NATSClient {
+	if !injector.Enabled() {
+		return client
+	}
+	return &faultyNATSClient{NATSClient: client, injector: injector}
+}
+
+type faultyNATSClient struct {
+	messaging.NATSClient
+	injector *Injector
+}
+
+func (c *faultyNATSClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
+	return c.PublishVerificationRequestWithPriority(ctx, verification, messaging.PriorityNormal)
+}
+
+func (c *faultyNATSClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	if err := c.injector.Inject(ctx, TargetNATS); err != nil {
+		return err
+	}
+	return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+}
+
+// WrapWebhookSender добавляет внедрение сбоев в доставку вебхуков
+func WrapWebhookSender(sender webhook.Sender, injector *Injector) webhook.Sender {
+	if !injector.Enabled() {
+		return sender
+	}
+	return &faultyWebhookSender{Sender: sender, injector: injector}
+}
+
+type faultyWebhookSender struct {
+	webhook.Sender
+	injector *Injector
+}
+
+func (s *faultyWebhookSender) Send(ctx context.Context, url string, request *model.WebhookPreview) error {
+	if err := s.injector.Inject(ctx, TargetWebhook); err != nil {
+		return err
+	}
+	return s.Sender.Send(ctx, url, request)
+}
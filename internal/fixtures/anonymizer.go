@@ -0,0 +1,146 @@
+package fixtures
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// fieldKind способ обезличивания значений поля документа
+type fieldKind int
+
+const (
+	fieldPublic fieldKind = iota
+	// fieldDigits цифры заменяются псевдослучайными той же длины: значение проходит шаблоны
+	// JSON Schema, а одно значение всегда получает один псевдоним
+	fieldDigits
+	// fieldText строки заменяются псевдонимом anon-<hash>
+	fieldText
+)
+
+// personalFields поля документов поставщиков с идентификаторами и персональными данными.
+// Значения обезличиваются на любой глубине, вложенные объекты и списки - целиком.
+var personalFields = map[string]fieldKind{
+	"inn":       fieldDigits,
+	"ogrn":      fieldDigits,
+	"ogrnip":    fieldDigits,
+	"kpp":       fieldDigits,
+	"snils":     fieldDigits,
+	"phone":     fieldDigits,
+	"passport":  fieldDigits,
+	"director":  fieldText,
+	"fio":       fieldText,
+	"fullName":  fieldText,
+	"full_name": fieldText,
+	"email":     fieldText,
+	"address":   fieldText,
+	"addresses": fieldText,
+}
+
+// Anonymizer строит псевдонимы по HMAC-SHA256 с солью
+type Anonymizer struct {
+	key []byte
+}
+
+// NewAnonymizer создает Anonymizer с солью salt, а без нее - со случайной солью
+func NewAnonymizer(salt string) *Anonymizer {
+	key := []byte(salt)
+	if salt == "" {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+	return &Anonymizer{key: key}
+}
+
+func (a *Anonymizer) sum(value string) []byte {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// Digits заменяет цифры значения псевдослучайными, остальные символы сохраняются
+func (a *Anonymizer) Digits(value string) string {
+	stream := a.sum(value)
+	next := 0
+	var b strings.Builder
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			b.WriteRune(r)
+			continue
+		}
+		if next == len(stream) {
+			stream = a.sum(string(stream))
+			next = 0
+		}
+		b.WriteByte('0' + stream[next]%10)
+		next++
+	}
+	return b.String()
+}
+
+// Text возвращает псевдоним строки. У email сохраняется вид адреса.
+func (a *Anonymizer) Text(value string) string {
+	alias := "anon-" + hex.EncodeToString(a.sum(value))[:12]
+	if strings.Contains(value, "@") {
+		return alias + "@example.com"
+	}
+	return alias
+}
+
+// Payload обезличивает документ поставщика проверки компании inn: значения personalFields и
+// любые строки, совпадающие с inn. У индивидуального предпринимателя (ИНН из 12 цифр)
+// наименование - это ФИО, поэтому поля name тоже обезличиваются.
+func (a *Anonymizer) Payload(payload string, inn string) (string, error) {
+	decoder := json.NewDecoder(strings.NewReader(payload))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return "", err
+	}
+
+	fields := personalFields
+	if len(inn) == 12 {
+		fields = make(map[string]fieldKind, len(personalFields)+1)
+		for key, kind := range personalFields {
+			fields[key] = kind
+		}
+		fields["name"] = fieldText
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(a.anonymize(document, fieldPublic, fields, inn)); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func (a *Anonymizer) anonymize(value any, kind fieldKind, fields map[string]fieldKind, inn string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			fieldKind := kind
+			if kind == fieldPublic {
+				fieldKind = fields[key]
+			}
+			v[key] = a.anonymize(field, fieldKind, fields, inn)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = a.anonymize(item, kind, fields, inn)
+		}
+	case string:
+		switch {
+		case kind == fieldDigits, kind == fieldPublic && v == inn:
+			return a.Digits(v)
+		case kind == fieldText:
+			return a.Text(v)
+		}
+	}
+	return value
+}
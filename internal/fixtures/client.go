@@ -0,0 +1,175 @@
+package fixtures
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+
+	"go.uber.org/zap"
+)
+
+// Source читает завершенную проверку вместе с данными
+type Source interface {
+	GetByIDWithData(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.Verification, error)
+}
+
+type recordingClient struct {
+	messaging.NATSClient
+	source     Source
+	dir        string
+	anonymizer *Anonymizer
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewRecordingClient записывает в dir каждую проверку, о завершении которой сообщили воркеры.
+// Проверка читается после обработки уведомления, когда шлюз уже определил недостающие типы.
+// Проверки песочницы не записываются: их данные синтетические.
+func NewRecordingClient(client messaging.NATSClient, source Source, dir string, anonymizer *Anonymizer, logger *zap.Logger) messaging.NATSClient {
+	return &recordingClient{
+		NATSClient: client,
+		source:     source,
+		dir:        dir,
+		anonymizer: anonymizer,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+func (c *recordingClient) SubscribeToVerificationCompleted(ctx context.Context, handler func(*domain.Verification)) error {
+	return c.NATSClient.SubscribeToVerificationCompleted(ctx, func(verification *domain.Verification) {
+		handler(verification)
+		c.record(ctx, verification.ID)
+	})
+}
+
+func (c *recordingClient) record(ctx context.Context, id string) {
+	verification, err := c.source.GetByIDWithData(ctx, id, nil)
+	if err != nil {
+		c.logger.Warn("failed to read verification for provider fixture", zap.Error(err), zap.String("verification_id", id))
+		return
+	}
+	if verification.Sandbox {
+		return
+	}
+
+	fixture, err := NewFixture(model.VerificationToDomain(verification), c.anonymizer, c.now())
+	if err == nil {
+		var path string
+		path, err = Save(c.dir, fixture)
+		if err == nil {
+			c.logger.Info("provider fixture recorded", zap.String("verification_id", id), zap.String("path", path))
+			return
+		}
+	}
+	c.logger.Warn("failed to record provider fixture", zap.Error(err), zap.String("verification_id", id))
+}
+
+// Store сохраняет проверку, завершенную записанным результатом
+type Store interface {
+	SaveCompleted(ctx context.Context, verification *domain.Verification) error
+}
+
+type replayClient struct {
+	messaging.NATSClient
+	store    Store
+	fixtures []*Fixture
+	byINN    map[string]*Fixture
+	logger   *zap.Logger
+	now      func() time.Time
+
+	mu       sync.RWMutex
+	handlers []func(*domain.Verification)
+}
+
+// NewReplayClient завершает запросы на проверку записанными результатами вместо публикации
+// воркерам: проверка сохраняется так же, как ее сохранил бы воркер, а уведомление о завершении
+// передается подписчикам SubscribeToVerificationCompleted. Запрос ИНН записи получает именно эту
+// запись, остальные - запись с данными всех запрошенных типов, одну и ту же для одного ИНН.
+func NewReplayClient(client messaging.NATSClient, store Store, fixtures []*Fixture, logger *zap.Logger) messaging.NATSClient {
+	byINN := make(map[string]*Fixture, len(fixtures))
+	for _, fixture := range fixtures {
+		if _, ok := byINN[fixture.INN]; !ok {
+			byINN[fixture.INN] = fixture
+		}
+	}
+	return &replayClient{
+		NATSClient: client,
+		store:      store,
+		fixtures:   fixtures,
+		byINN:      byINN,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+func (c *replayClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
+	return c.PublishVerificationRequestWithPriority(ctx, verification, messaging.PriorityNormal)
+}
+
+func (c *replayClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	fixture := c.pick(verification)
+	completed := fixture.Replay(verification, c.now())
+	if err := c.store.SaveCompleted(ctx, completed); err != nil {
+		return err
+	}
+
+	c.logger.Info("verification completed from provider fixture",
+		zap.String("verification_id", verification.ID),
+		zap.String("fixture", fixture.Name))
+	// Как и от воркера, уведомление приходит после ответа на запрос
+	go c.notify(&domain.Verification{
+		ID:        completed.ID,
+		Status:    completed.Status,
+		RiskLevel: completed.RiskLevel,
+		RulesetID: completed.RulesetID,
+	})
+	return nil
+}
+
+// SubscribeToVerificationCompleted подписывает handler на воспроизведенные завершения и на
+// уведомления настоящих воркеров, если они есть
+func (c *replayClient) SubscribeToVerificationCompleted(ctx context.Context, handler func(*domain.Verification)) error {
+	c.mu.Lock()
+	c.handlers = append(c.handlers, handler)
+	c.mu.Unlock()
+	return c.NATSClient.SubscribeToVerificationCompleted(ctx, handler)
+}
+
+func (c *replayClient) notify(verification *domain.Verification) {
+	c.mu.RLock()
+	handlers := c.handlers
+	c.mu.RUnlock()
+	for _, handler := range handlers {
+		handler(verification)
+	}
+}
+
+// pick выбирает запись для запроса: по ИНН, а иначе среди записей с наибольшим числом
+// запрошенных типов по хэшу ИНН
+func (c *replayClient) pick(verification *domain.Verification) *Fixture {
+	if fixture, ok := c.byINN[verification.INN]; ok {
+		return fixture
+	}
+
+	var candidates []*Fixture
+	best := -1
+	for _, fixture := range c.fixtures {
+		switch coverage := fixture.coverage(verification.RequestedDataTypes); {
+		case coverage > best:
+			best = coverage
+			candidates = []*Fixture{fixture}
+		case coverage == best:
+			candidates = append(candidates, fixture)
+		}
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(verification.INN))
+	return candidates[hash.Sum32()%uint32(len(candidates))]
+}
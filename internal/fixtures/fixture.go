@@ -0,0 +1,205 @@
+// Package fixtures записывает данные завершенных проверок в обезличенные файлы и воспроизводит их
+// вместо воркеров, чтобы интеграционные тесты скоринга, сравнения и отчетов не зависели от поставщиков.
+package fixtures
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+)
+
+// Fixture записанный результат проверки: итоговый статус, оценка и данные каждого типа.
+// ИНН и персональные данные в файле заменены псевдонимами.
+type Fixture struct {
+	// Name имя файла без расширения
+	Name               string            `json:"-"`
+	RecordedAt         time.Time         `json:"recorded_at"`
+	INN                string            `json:"inn"`
+	Status             domain.Status     `json:"status"`
+	RiskLevel          *domain.RiskLevel `json:"risk_level,omitempty"`
+	RulesetID          *string           `json:"ruleset_id,omitempty"`
+	RequestedDataTypes []domain.DataType `json:"requested_data_types"`
+	Data               []*Data           `json:"data"`
+}
+
+// Data записанный документ поставщика
+type Data struct {
+	DataType      domain.DataType `json:"data_type"`
+	SchemaVersion int             `json:"schema_version"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// NewFixture обезличивает завершенную проверку с данными
+func NewFixture(verification *domain.Verification, anonymizer *Anonymizer, recordedAt time.Time) (*Fixture, error) {
+	fixture := &Fixture{
+		RecordedAt:         recordedAt.UTC().Truncate(time.Second),
+		INN:                anonymizer.Digits(verification.INN),
+		Status:             verification.Status,
+		RiskLevel:          verification.RiskLevel,
+		RulesetID:          verification.RulesetID,
+		RequestedDataTypes: verification.RequestedDataTypes,
+		Data:               make([]*Data, 0, len(verification.Data)),
+	}
+
+	for _, data := range verification.Data {
+		payload, err := anonymizer.Payload(data.Payload, verification.INN)
+		if err != nil {
+			return nil, fmt.Errorf("failed to anonymize %s payload: %w", data.DataType, err)
+		}
+		fixture.Data = append(fixture.Data, &Data{
+			DataType:      data.DataType,
+			SchemaVersion: max(data.SchemaVersion, 1),
+			Payload:       json.RawMessage(payload),
+		})
+	}
+	return fixture, nil
+}
+
+// data возвращает документ типа dataType или nil
+func (f *Fixture) data(dataType domain.DataType) *Data {
+	for _, data := range f.Data {
+		if data.DataType == dataType {
+			return data
+		}
+	}
+	return nil
+}
+
+// coverage число типов из dataTypes, для которых записаны данные
+func (f *Fixture) coverage(dataTypes []domain.DataType) int {
+	count := 0
+	for _, dataType := range dataTypes {
+		if f.data(dataType) != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// Replay завершает запрос на проверку записанным результатом. Данные возвращаются только для
+// запрошенных типов, псевдоним ИНН в них заменяется ИНН запроса. Частично завершенная запись
+// воспроизводится как COMPLETED: недостающие типы определяет шлюз, как и для ответа воркера.
+func (f *Fixture) Replay(request *domain.Verification, now time.Time) *domain.Verification {
+	now = now.UTC().Truncate(time.Second)
+	completed := *request
+	completed.Status = f.Status
+	completed.RiskLevel = f.RiskLevel
+	completed.RulesetID = f.RulesetID
+	completed.MissingDataTypes = []domain.DataType{}
+	completed.Data = nil
+	completed.CreatedAt = now
+	completed.UpdatedAt = now
+
+	if f.Status == domain.StatusPartiallyCompleted {
+		completed.Status = domain.StatusCompleted
+	}
+	if completed.Status != domain.StatusCompleted {
+		return &completed
+	}
+
+	for _, dataType := range request.RequestedDataTypes {
+		data := f.data(dataType)
+		if data == nil {
+			continue
+		}
+		// В файле документ отформатирован вместе с записью
+		var payload bytes.Buffer
+		if err := json.Compact(&payload, data.Payload); err != nil {
+			payload.Reset()
+			payload.Write(data.Payload)
+		}
+		completed.Data = append(completed.Data, &domain.Data{
+			DataType:      dataType,
+			Payload:       strings.ReplaceAll(payload.String(), `"`+f.INN+`"`, `"`+request.INN+`"`),
+			SchemaVersion: data.SchemaVersion,
+			CreatedAt:     now,
+		})
+	}
+	return &completed
+}
+
+// Save записывает fixture в каталог dir и возвращает путь файла. Имя файла строится из псевдонима
+// ИНН и содержимого, поэтому одинаковые ответы записываются один раз.
+func Save(dir string, fixture *Fixture) (string, error) {
+	content, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode fixture: %w", err)
+	}
+
+	recordedAt := fixture.RecordedAt
+	fixture.RecordedAt = time.Time{}
+	identity, err := json.Marshal(fixture)
+	fixture.RecordedAt = recordedAt
+	if err != nil {
+		return "", fmt.Errorf("failed to encode fixture: %w", err)
+	}
+	sum := sha256.Sum256(identity)
+	fixture.Name = fmt.Sprintf("%s-%s", fixture.INN, hex.EncodeToString(sum[:])[:12])
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create fixtures dir: %w", err)
+	}
+	path := filepath.Join(dir, fixture.Name+".json")
+	if err := os.WriteFile(path, append(content, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return path, nil
+}
+
+// Load читает записи каталога dir в порядке имен файлов
+func Load(dir string) ([]*Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fixtures: %w", err)
+	}
+	slices.Sort(paths)
+
+	fixtures := make([]*Fixture, 0, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture: %w", err)
+		}
+
+		var fixture Fixture
+		if err := json.Unmarshal(content, &fixture); err != nil {
+			return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+		}
+		if err := fixture.validate(); err != nil {
+			return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+		}
+		fixture.Name = strings.TrimSuffix(filepath.Base(path), ".json")
+		fixtures = append(fixtures, &fixture)
+	}
+	return fixtures, nil
+}
+
+func (f *Fixture) validate() error {
+	if len(f.INN) != 10 && len(f.INN) != 12 {
+		return fmt.Errorf("inn must be 10 or 12 digits, got %d", len(f.INN))
+	}
+	if !f.Status.IsValid() {
+		return fmt.Errorf("unknown status %q", f.Status)
+	}
+	if f.RiskLevel != nil && !f.RiskLevel.IsValid() {
+		return fmt.Errorf("unknown risk level %q", *f.RiskLevel)
+	}
+	for _, data := range f.Data {
+		if !data.DataType.IsValid() {
+			return fmt.Errorf("unknown data type %q", data.DataType)
+		}
+		if !json.Valid(data.Payload) {
+			return fmt.Errorf("%s payload is not JSON", data.DataType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+package fixtures
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/validation"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAnonymizerPayload(t *testing.T) {
+	anonymizer := NewAnonymizer("test-salt")
+	validator, err := validation.NewValidator()
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		inn       string
+		dataType  domain.DataType
+		payload   string
+		kept      []string
+		removed   []string
+		schemaErr bool
+	}{
+		{
+			name:     "company",
+			inn:      "7707083893",
+			dataType: domain.DataTypeBasicInformation,
+			payload:  `{"name": "ПАО Сбербанк", "inn": "7707083893", "ogrn": "1027700132195", "director": {"name": "Греф Герман Оскарович", "inn": "770303580308"}, "authorizedCapital": 67760844000}`,
+			kept:     []string{`"name":"ПАО Сбербанк"`, `"authorizedCapital":67760844000`},
+			removed:  []string{"7707083893", "1027700132195", "Греф", "770303580308"},
+		},
+		{
+			name:     "individual_entrepreneur",
+			inn:      "500100732259",
+			dataType: domain.DataTypeBasicInformation,
+			payload:  `{"name": "ИП Иванов Иван Иванович", "inn": "500100732259"}`,
+			removed:  []string{"Иванов", "500100732259"},
+		},
+		{
+			name:     "affiliated_companies",
+			inn:      "7707083893",
+			dataType: domain.DataTypeAffiliatedCompanies,
+			payload:  `{"companies": [{"inn": "7736050003", "name": "ООО Ромашка", "share": 50}], "parent": "7707083893"}`,
+			kept:     []string{`"name":"ООО Ромашка"`, `"share":50`},
+			removed:  []string{"7736050003", "7707083893"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anonymized, err := anonymizer.Payload(tt.payload, tt.inn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, value := range tt.kept {
+				if !strings.Contains(anonymized, value) {
+					t.Errorf("expected %s to be kept in %s", value, anonymized)
+				}
+			}
+			for _, value := range tt.removed {
+				if strings.Contains(anonymized, value) {
+					t.Errorf("expected %s to be anonymized in %s", value, anonymized)
+				}
+			}
+			if result := validator.Validate(model.VerificationDataType(tt.dataType), anonymized); !result.Valid {
+				t.Errorf("expected anonymized payload to match schema, but got %v", result.Errors)
+			}
+
+			again, _ := anonymizer.Payload(tt.payload, tt.inn)
+			if again != anonymized {
+				t.Errorf("expected anonymization to be deterministic, got %s and %s", anonymized, again)
+			}
+		})
+	}
+
+	if digits := anonymizer.Digits("+7 (999) 123-45-67"); len(digits) != len("+7 (999) 123-45-67") || !strings.HasPrefix(digits, "+") || strings.Count(digits, "-") != 2 {
+		t.Errorf("expected phone format to be kept, but got %s", digits)
+	}
+	if NewAnonymizer("other-salt").Digits("7707083893") == anonymizer.Digits("7707083893") {
+		t.Error("expected different salts to give different aliases")
+	}
+}
+
+func TestFixtureSaveLoadReplay(t *testing.T) {
+	anonymizer := NewAnonymizer("test-salt")
+	riskLevel := domain.RiskLevelLow
+	recorded := &domain.Verification{
+		ID:                 "v-1",
+		INN:                "7707083893",
+		Status:             domain.StatusPartiallyCompleted,
+		AuthorEmail:        "analyst@bank.ru",
+		RiskLevel:          &riskLevel,
+		RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation, domain.DataTypeActivities},
+		MissingDataTypes:   []domain.DataType{domain.DataTypeActivities},
+		Data: []*domain.Data{
+			{DataType: domain.DataTypeBasicInformation, Payload: `{"name": "ПАО Сбербанк", "inn": "7707083893"}`, SchemaVersion: 2},
+		},
+	}
+
+	fixture, err := NewFixture(recorded, anonymizer, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dir := t.TempDir()
+	path, err := Save(dir, fixture)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if strings.Contains(string(content), "7707083893") || strings.Contains(string(content), "analyst@bank.ru") {
+		t.Errorf("expected recorded fixture to be anonymized, but got %s", content)
+	}
+
+	// Повторная запись того же ответа попадает в тот же файл
+	fixture.RecordedAt = fixture.RecordedAt.Add(time.Hour)
+	if again, _ := Save(dir, fixture); again != path {
+		t.Errorf("expected the same response to be saved to %s, but got %s", path, again)
+	}
+
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].Name != strings.TrimSuffix(filepath.Base(path), ".json") || loaded[0].INN != fixture.INN {
+		t.Fatalf("expected the saved fixture to be loaded, but got %+v", loaded)
+	}
+
+	now := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	request := &domain.Verification{
+		ID:                 "v-2",
+		INN:                "7736050003",
+		Status:             domain.StatusInProcess,
+		AuthorEmail:        "tester@bank.ru",
+		RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation, domain.DataTypeActivities},
+	}
+	replayed := loaded[0].Replay(request, now)
+	if replayed.ID != "v-2" || replayed.AuthorEmail != "tester@bank.ru" || replayed.Status != domain.StatusCompleted ||
+		replayed.RiskLevel == nil || *replayed.RiskLevel != riskLevel || !replayed.CreatedAt.Equal(now) {
+		t.Errorf("unexpected replayed verification %+v", replayed)
+	}
+	if len(replayed.Data) != 1 || replayed.Data[0].SchemaVersion != 2 || !strings.Contains(replayed.Data[0].Payload, `"inn":"7736050003"`) {
+		t.Errorf("expected recorded payload with the requested inn, but got %+v", replayed.Data)
+	}
+	if request.Status != domain.StatusInProcess || request.Data != nil {
+		t.Errorf("expected the request to stay unchanged, but got %+v", request)
+	}
+}
+
+func TestLoadRejectsInvalidFixture(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{"inn": "7707083893", "status": "DONE"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), `unknown status "DONE"`) {
+		t.Errorf("expected unknown status error, but got %v", err)
+	}
+}
+
+type stubClient struct {
+	messaging.NATSClient
+	published []string
+	handler   func(*domain.Verification)
+}
+
+func (c *stubClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	c.published = append(c.published, verification.ID)
+	return nil
+}
+
+func (c *stubClient) SubscribeToVerificationCompleted(ctx context.Context, handler func(*domain.Verification)) error {
+	c.handler = handler
+	return nil
+}
+
+type memoryStore struct {
+	saved []*domain.Verification
+}
+
+func (s *memoryStore) SaveCompleted(ctx context.Context, verification *domain.Verification) error {
+	s.saved = append(s.saved, verification)
+	return nil
+}
+
+func TestReplayClient(t *testing.T) {
+	basic := &Fixture{Name: "basic", INN: "1111111111", Status: domain.StatusCompleted, Data: []*Data{
+		{DataType: domain.DataTypeBasicInformation, SchemaVersion: 1, Payload: json.RawMessage(`{"inn": "1111111111"}`)},
+	}}
+	notFound := &Fixture{Name: "not_found", INN: "2222222222", Status: domain.StatusCompanyNotFound}
+
+	inner := &stubClient{}
+	store := &memoryStore{}
+	client := NewReplayClient(inner, store, []*Fixture{basic, notFound}, zaptest.NewLogger(t))
+
+	completed := make(chan *domain.Verification, 2)
+	if err := client.SubscribeToVerificationCompleted(context.Background(), func(verification *domain.Verification) {
+		completed <- verification
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inner.handler == nil {
+		t.Error("expected worker completions to stay subscribed")
+	}
+
+	tests := []struct {
+		name           string
+		inn            string
+		expectedStatus domain.Status
+		expectedData   int
+	}{
+		{name: "covering_fixture", inn: "7707083893", expectedStatus: domain.StatusCompleted, expectedData: 1},
+		{name: "fixture_by_inn", inn: notFound.INN, expectedStatus: domain.StatusCompanyNotFound},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &domain.Verification{ID: tt.name, INN: tt.inn, Status: domain.StatusInProcess,
+				RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation}}
+			if err := client.PublishVerificationRequest(context.Background(), request); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			saved := store.saved[i]
+			if saved.ID != tt.name || saved.Status != tt.expectedStatus || len(saved.Data) != tt.expectedData {
+				t.Errorf("unexpected saved verification %+v", saved)
+			}
+			select {
+			case message := <-completed:
+				if message.ID != tt.name || message.Status != tt.expectedStatus {
+					t.Errorf("unexpected completion %+v", message)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("expected completion to be delivered")
+			}
+		})
+	}
+
+	if len(inner.published) != 0 {
+		t.Errorf("expected no requests to reach workers, but got %v", inner.published)
+	}
+}
+
+type memorySource struct {
+	verification *model.Verification
+}
+
+func (s *memorySource) GetByIDWithData(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.Verification, error) {
+	return s.verification, nil
+}
+
+func TestRecordingClient(t *testing.T) {
+	riskLevel := model.RiskLevelMedium
+	verification := &model.Verification{
+		ID:                 "v-1",
+		Inn:                "7707083893",
+		Status:             model.VerificationStatusCompleted,
+		RiskLevel:          &riskLevel,
+		RequestedDataTypes: []model.VerificationDataType{model.VerificationDataTypeBasicInformation},
+		Data: []*model.VerificationData{
+			{DataType: model.VerificationDataTypeBasicInformation, Data: `{"inn": "7707083893"}`, SchemaVersion: 1, CreatedAt: "2024-03-01T10:00:00Z"},
+		},
+		CreatedAt: "2024-03-01T09:00:00Z",
+		UpdatedAt: "2024-03-01T10:00:00Z",
+	}
+
+	for _, sandbox := range []bool{false, true} {
+		verification.Sandbox = sandbox
+		inner := &stubClient{}
+		dir := t.TempDir()
+		client := NewRecordingClient(inner, &memorySource{verification: verification}, dir, NewAnonymizer("test-salt"), zaptest.NewLogger(t))
+
+		handled := false
+		if err := client.SubscribeToVerificationCompleted(context.Background(), func(*domain.Verification) { handled = true }); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		inner.handler(&domain.Verification{ID: "v-1", Status: domain.StatusCompleted})
+
+		recorded, err := Load(dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !handled {
+			t.Error("expected completion to be handled")
+		}
+		if expected := map[bool]int{false: 1, true: 0}[sandbox]; len(recorded) != expected {
+			t.Fatalf("sandbox %v: expected %d recorded fixtures, but got %d", sandbox, expected, len(recorded))
+		}
+		if !sandbox && (recorded[0].Status != domain.StatusCompleted || *recorded[0].RiskLevel != domain.RiskLevelMedium || len(recorded[0].Data) != 1) {
+			t.Errorf("unexpected recorded fixture %+v", recorded[0])
+		}
+	}
+}
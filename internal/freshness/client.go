@@ -0,0 +1,192 @@
+package freshness
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/catalog"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Store хранилище данных компаний, отдаваемых из кэша
+type Store interface {
+	GetCached(ctx context.Context, tenant, inn string, versions map[domain.DataType]int) (map[domain.DataType]*repository.CachedData, error)
+	SaveFromCache(ctx context.Context, verification *domain.Verification, served []*repository.ServedData) (bool, error)
+	ClaimRefresh(ctx context.Context, tenant, inn string, dataTypes []domain.DataType, requestedBefore time.Time) ([]domain.DataType, error)
+}
+
+type freshnessClient struct {
+	messaging.NATSClient
+	store    Store
+	policies Policies
+	registry *catalog.Registry
+	cfg      config.FreshnessConfig
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewClient отдает проверке данные из кэша по срокам свежести policies. У поставщиков
+// запрашиваются только отсутствующие в кэше данные, устаревшие данные обновляются отдельной
+// фоновой проверкой не чаще раза в cfg.RefreshCooldown.
+func NewClient(client messaging.NATSClient, store Store, policies Policies, registry *catalog.Registry, cfg config.FreshnessConfig, logger *zap.Logger) messaging.NATSClient {
+	return &freshnessClient{
+		NATSClient: client,
+		store:      store,
+		policies:   policies,
+		registry:   registry,
+		cfg:        cfg,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+func (c *freshnessClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
+	return c.PublishVerificationRequestWithPriority(ctx, verification, messaging.PriorityNormal)
+}
+
+func (c *freshnessClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	if verification.Sandbox {
+		return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+
+	versions := make(map[domain.DataType]int, len(verification.RequestedDataTypes))
+	for _, dataType := range verification.RequestedDataTypes {
+		if c.policies.For(model.VerificationDataType(dataType)).StaleFor > 0 {
+			versions[dataType] = c.registry.SchemaVersion(model.VerificationDataType(dataType))
+		}
+	}
+	if len(versions) == 0 {
+		return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+
+	tenant := messaging.TenantOf(verification.AuthorEmail)
+	cached, err := c.store.GetCached(ctx, tenant, verification.INN, versions)
+	if err != nil {
+		// Кэш только экономит обращения к поставщикам, поэтому его недоступность не мешает проверке
+		c.logger.Warn("data cache unavailable, publishing verification", zap.Error(err), zap.String("inn", verification.INN))
+		return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+
+	now := c.now().UTC().Truncate(time.Second)
+	var served []*repository.ServedData
+	var stale, missing []domain.DataType
+	for _, dataType := range verification.RequestedDataTypes {
+		level := LevelMissing
+		if data, ok := cached[dataType]; ok {
+			level = c.policies.For(model.VerificationDataType(dataType)).Classify(now.Sub(data.DeliveredAt))
+		}
+		switch level {
+		case LevelFresh:
+			served = append(served, &repository.ServedData{Cached: cached[dataType], Freshness: domain.FreshnessFresh})
+		case LevelStale:
+			served = append(served, &repository.ServedData{Cached: cached[dataType], Freshness: domain.FreshnessStale})
+			stale = append(stale, dataType)
+		default:
+			missing = append(missing, dataType)
+		}
+	}
+	if len(served) == 0 {
+		recordLookups(verification.RequestedDataTypes, nil)
+		return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+
+	saved := *verification
+	saved.Status = domain.StatusCompleted
+	if len(missing) > 0 {
+		saved.Status = domain.StatusInProcess
+	}
+	saved.MissingDataTypes = []domain.DataType{}
+	saved.CreatedAt = now
+	saved.UpdatedAt = now
+
+	ok, err := c.store.SaveFromCache(ctx, &saved, served)
+	if err != nil {
+		c.logger.Warn("failed to save verification from data cache, publishing verification", zap.Error(err), zap.String("inn", verification.INN))
+		return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+	// Повторный запрос данных уже существующей проверки кэш не обслуживает
+	if !ok {
+		return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+	recordLookups(verification.RequestedDataTypes, served)
+
+	if len(missing) > 0 {
+		request := saved
+		request.RequestedDataTypes = missing
+		if err := c.NATSClient.PublishVerificationRequestWithPriority(ctx, &request, priority); err != nil {
+			return fmt.Errorf("failed to publish missing data request: %w", err)
+		}
+		saved.ExpectedStartAt = request.ExpectedStartAt
+		saved.QueuePosition = request.QueuePosition
+	}
+	c.refresh(ctx, tenant, verification.INN, stale, now)
+
+	*verification = saved
+	c.logger.Info("verification served from data cache",
+		zap.String("verification_id", verification.ID),
+		zap.String("inn", verification.INN),
+		zap.Int("served", len(served)),
+		zap.Int("stale", len(stale)),
+		zap.Int("missing", len(missing)))
+	return nil
+}
+
+// refresh запрашивает у поставщиков устаревшие данные отдельной проверкой с низким приоритетом.
+// Ошибка обновления не влияет на проверку: данные обновятся при следующем обращении.
+func (c *freshnessClient) refresh(ctx context.Context, tenant, inn string, stale []domain.DataType, now time.Time) {
+	if len(stale) == 0 {
+		return
+	}
+
+	claimed, err := c.store.ClaimRefresh(ctx, tenant, inn, stale, now.Add(-c.cfg.RefreshCooldown))
+	if err != nil {
+		c.logger.Warn("failed to claim data cache refresh", zap.Error(err), zap.String("inn", inn))
+		return
+	}
+	if len(claimed) == 0 {
+		return
+	}
+
+	refresh := &domain.Verification{
+		ID:                 uuid.New().String(),
+		INN:                inn,
+		Status:             domain.StatusInProcess,
+		AuthorEmail:        c.cfg.RefreshAuthor + "@" + tenant,
+		RequestedDataTypes: claimed,
+		ReviewState:        domain.ReviewStateUnreviewed,
+	}
+	if err := c.NATSClient.PublishVerificationRequestWithPriority(ctx, refresh, messaging.PriorityBatch); err != nil {
+		c.logger.Warn("failed to publish data cache refresh", zap.Error(err), zap.String("inn", inn))
+		return
+	}
+	for _, dataType := range claimed {
+		metrics.CacheRefreshes.WithLabelValues(string(dataType)).Inc()
+	}
+	c.logger.Info("data cache refresh requested",
+		zap.String("verification_id", refresh.ID),
+		zap.String("inn", inn),
+		zap.Any("data_types", claimed))
+}
+
+func recordLookups(requested []domain.DataType, served []*repository.ServedData) {
+	levels := make(map[domain.DataType]Level, len(served))
+	for _, data := range served {
+		levels[data.Cached.DataType] = Level(data.Freshness)
+	}
+	for _, dataType := range requested {
+		level, ok := levels[dataType]
+		if !ok {
+			level = LevelMissing
+		}
+		metrics.CacheFreshnessLookups.WithLabelValues(string(dataType), string(level)).Inc()
+	}
+}
@@ -0,0 +1,194 @@
+package freshness
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/catalog"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type recordingClient struct {
+	messaging.NATSClient
+	published []*domain.Verification
+}
+
+func (c *recordingClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	copied := *verification
+	c.published = append(c.published, &copied)
+	return nil
+}
+
+type memoryStore struct {
+	cached    map[domain.DataType]*repository.CachedData
+	saved     map[string][]*repository.ServedData
+	refreshed map[domain.DataType]time.Time
+	err       error
+}
+
+func (s *memoryStore) GetCached(ctx context.Context, tenant, inn string, versions map[domain.DataType]int) (map[domain.DataType]*repository.CachedData, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	cached := make(map[domain.DataType]*repository.CachedData)
+	for dataType := range versions {
+		if data, ok := s.cached[dataType]; ok {
+			cached[dataType] = data
+		}
+	}
+	return cached, nil
+}
+
+func (s *memoryStore) SaveFromCache(ctx context.Context, verification *domain.Verification, served []*repository.ServedData) (bool, error) {
+	if _, ok := s.saved[verification.ID]; ok {
+		return false, nil
+	}
+	s.saved[verification.ID] = served
+	return true, nil
+}
+
+func (s *memoryStore) ClaimRefresh(ctx context.Context, tenant, inn string, dataTypes []domain.DataType, requestedBefore time.Time) ([]domain.DataType, error) {
+	var claimed []domain.DataType
+	for _, dataType := range dataTypes {
+		if requestedAt, ok := s.refreshed[dataType]; !ok || requestedAt.Before(requestedBefore) {
+			s.refreshed[dataType] = requestedBefore
+			claimed = append(claimed, dataType)
+		}
+	}
+	return claimed, nil
+}
+
+func TestParsePolicies(t *testing.T) {
+	registry := catalog.DefaultRegistry()
+	defaults := Policy{FreshFor: time.Hour, StaleFor: 24 * time.Hour}
+
+	policies, err := ParsePolicies(defaults, []string{"ARBITRAGE_STATISTICS=15m/6h", " ", "ACTIVITIES=0s/0s"}, registry)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if policy := policies.For(model.VerificationDataTypeArbitrageStatistics); policy != (Policy{FreshFor: 15 * time.Minute, StaleFor: 6 * time.Hour}) {
+		t.Errorf("unexpected arbitrage policy %+v", policy)
+	}
+	if policy := policies.For(model.VerificationDataTypeBasicInformation); policy != defaults {
+		t.Errorf("expected the default policy, but got %+v", policy)
+	}
+	if level := policies.For(model.VerificationDataTypeActivities).Classify(0); level != LevelMissing {
+		t.Errorf("expected a disabled type to be missing, but got %s", level)
+	}
+
+	for _, spec := range []string{"ARBITRAGE_STATISTICS", "UNKNOWN=1h/2h", "ACTIVITIES=1h", "ACTIVITIES=2h/1h", "ACTIVITIES=soon/1h"} {
+		if _, err := ParsePolicies(defaults, []string{spec}, registry); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestPolicyClassify(t *testing.T) {
+	policy := Policy{FreshFor: time.Hour, StaleFor: 24 * time.Hour}
+	tests := map[time.Duration]Level{
+		time.Minute:    LevelFresh,
+		time.Hour:      LevelStale,
+		23 * time.Hour: LevelStale,
+		24 * time.Hour: LevelMissing,
+	}
+	for age, expected := range tests {
+		if level := policy.Classify(age); level != expected {
+			t.Errorf("expected %s for age %s, but got %s", expected, age, level)
+		}
+	}
+}
+
+func newTestClient(t *testing.T, now time.Time) (*freshnessClient, *memoryStore, *recordingClient) {
+	store := &memoryStore{
+		cached: map[domain.DataType]*repository.CachedData{
+			domain.DataTypeBasicInformation:    {DataType: domain.DataTypeBasicInformation, DataHash: "a", SchemaVersion: 1, DeliveredAt: now.Add(-time.Minute)},
+			domain.DataTypeArbitrageStatistics: {DataType: domain.DataTypeArbitrageStatistics, DataHash: "b", SchemaVersion: 1, DeliveredAt: now.Add(-2 * time.Hour)},
+		},
+		saved:     map[string][]*repository.ServedData{},
+		refreshed: map[domain.DataType]time.Time{},
+	}
+	policies, err := ParsePolicies(Policy{FreshFor: time.Hour, StaleFor: 24 * time.Hour}, nil, catalog.DefaultRegistry())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	next := &recordingClient{}
+	cfg := config.FreshnessConfig{RefreshCooldown: 10 * time.Minute, RefreshAuthor: "cache-refresh"}
+	client := NewClient(next, store, policies, catalog.DefaultRegistry(), cfg, zaptest.NewLogger(t)).(*freshnessClient)
+	client.now = func() time.Time { return now }
+	return client, store, next
+}
+
+func TestClientServesCachedData(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	client, store, next := newTestClient(t, now)
+
+	verification := &domain.Verification{
+		ID:                 "v-1",
+		INN:                "7707083893",
+		Status:             domain.StatusInProcess,
+		AuthorEmail:        "analyst@bank.ru",
+		RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation, domain.DataTypeArbitrageStatistics, domain.DataTypeActivities},
+	}
+	if err := client.PublishVerificationRequest(context.Background(), verification); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	served := store.saved["v-1"]
+	if len(served) != 2 || served[0].Freshness != domain.FreshnessFresh || served[1].Freshness != domain.FreshnessStale {
+		t.Fatalf("expected fresh and stale data to be served, but got %+v", served)
+	}
+	if verification.Status != domain.StatusInProcess || verification.CreatedAt.IsZero() {
+		t.Errorf("expected the verification to wait for missing data, but got %+v", verification)
+	}
+	if len(next.published) != 2 {
+		t.Fatalf("expected a missing data request and a refresh, but got %d publications", len(next.published))
+	}
+	if request := next.published[0]; request.ID != "v-1" || !slices.Equal(request.RequestedDataTypes, []domain.DataType{domain.DataTypeActivities}) {
+		t.Errorf("expected only missing data to be requested, but got %+v", request)
+	}
+	refresh := next.published[1]
+	if refresh.ID == "v-1" || refresh.AuthorEmail != "cache-refresh@bank.ru" ||
+		!slices.Equal(refresh.RequestedDataTypes, []domain.DataType{domain.DataTypeArbitrageStatistics}) {
+		t.Errorf("expected a background refresh of stale data, but got %+v", refresh)
+	}
+
+	// Обновление уже запрошено, повторно в пределах RefreshCooldown не публикуется
+	stale := &domain.Verification{ID: "v-2", INN: "7707083893", AuthorEmail: "analyst@bank.ru", RequestedDataTypes: []domain.DataType{domain.DataTypeArbitrageStatistics}}
+	if err := client.PublishVerificationRequest(context.Background(), stale); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stale.Status != domain.StatusCompleted || len(next.published) != 2 {
+		t.Errorf("expected the verification to complete from cache without a second refresh, but got %+v and %d publications", stale, len(next.published))
+	}
+
+	// Повторный запрос недостающих данных сохраненной проверки доходит до воркеров без изменений
+	retry := &domain.Verification{ID: "v-1", INN: "7707083893", RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation}}
+	if err := client.PublishVerificationRequest(context.Background(), retry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(next.published) != 3 || retry.CreatedAt != (time.Time{}) {
+		t.Errorf("expected the retry to be published as is, but got %+v", retry)
+	}
+}
+
+func TestClientPublishesWhenStoreFails(t *testing.T) {
+	client, store, next := newTestClient(t, time.Now())
+	store.err = errors.New("connection refused")
+
+	verification := &domain.Verification{ID: "v-1", INN: "7707083893", Status: domain.StatusInProcess, RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation}}
+	if err := client.PublishVerificationRequest(context.Background(), verification); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(next.published) != 1 || verification.Status != domain.StatusInProcess {
+		t.Errorf("expected the verification to be published, but got %d publications", len(next.published))
+	}
+}
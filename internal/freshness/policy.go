@@ -0,0 +1,102 @@
+// Package freshness отдает данные компании из кэша с учетом их возраста: свежие данные - сразу,
+// устаревшие - сразу с фоновым обновлением, и только за отсутствующими проверка ждет поставщиков.
+package freshness
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/catalog"
+)
+
+// Level уровень свежести данных в кэше
+type Level string
+
+const (
+	LevelFresh   Level = "FRESH"
+	LevelStale   Level = "STALE"
+	LevelMissing Level = "MISSING"
+)
+
+// Policy сроки свежести данных одного типа. Данные моложе FreshFor свежие, моложе StaleFor -
+// устаревшие, остальные запрашиваются у поставщиков. Нулевые сроки отключают кэш для типа.
+type Policy struct {
+	FreshFor time.Duration
+	StaleFor time.Duration
+}
+
+// Classify возвращает уровень свежести данных возраста age
+func (p Policy) Classify(age time.Duration) Level {
+	switch {
+	case age < p.FreshFor:
+		return LevelFresh
+	case age < p.StaleFor:
+		return LevelStale
+	default:
+		return LevelMissing
+	}
+}
+
+// Policies сроки свежести по типам данных
+type Policies struct {
+	defaults Policy
+	byType   map[model.VerificationDataType]Policy
+}
+
+// ParsePolicies разбирает сроки вида ARBITRAGE_STATISTICS=15m/6h. defaults действуют для
+// остальных типов.
+func ParsePolicies(defaults Policy, specs []string, registry *catalog.Registry) (Policies, error) {
+	if err := defaults.validate(); err != nil {
+		return Policies{}, err
+	}
+
+	policies := Policies{defaults: defaults, byType: make(map[model.VerificationDataType]Policy)}
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		dataType, value, ok := strings.Cut(spec, "=")
+		if !ok {
+			return Policies{}, fmt.Errorf("invalid freshness policy %q, expected DATA_TYPE=fresh/stale", spec)
+		}
+		if _, ok := registry.Lookup(model.VerificationDataType(dataType)); !ok {
+			return Policies{}, fmt.Errorf("unknown data type %q in freshness policy %q", dataType, spec)
+		}
+		freshFor, staleFor, ok := strings.Cut(value, "/")
+		if !ok {
+			return Policies{}, fmt.Errorf("invalid freshness policy %q, expected DATA_TYPE=fresh/stale", spec)
+		}
+
+		var policy Policy
+		var err error
+		if policy.FreshFor, err = time.ParseDuration(freshFor); err != nil {
+			return Policies{}, fmt.Errorf("invalid fresh period in freshness policy %q: %w", spec, err)
+		}
+		if policy.StaleFor, err = time.ParseDuration(staleFor); err != nil {
+			return Policies{}, fmt.Errorf("invalid stale period in freshness policy %q: %w", spec, err)
+		}
+		if err := policy.validate(); err != nil {
+			return Policies{}, fmt.Errorf("invalid freshness policy %q: %w", spec, err)
+		}
+		policies.byType[model.VerificationDataType(dataType)] = policy
+	}
+	return policies, nil
+}
+
+func (p Policy) validate() error {
+	if p.FreshFor < 0 || p.StaleFor < p.FreshFor {
+		return fmt.Errorf("stale period %s must not be shorter than fresh period %s", p.StaleFor, p.FreshFor)
+	}
+	return nil
+}
+
+// For возвращает сроки свежести типа данных
+func (p Policies) For(dataType model.VerificationDataType) Policy {
+	if policy, ok := p.byType[dataType]; ok {
+		return policy
+	}
+	return p.defaults
+}
@@ -0,0 +1,64 @@
+package httpapi
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"scoring_api_gateway/internal/abuse"
+	"scoring_api_gateway/internal/auth"
+)
+
+// BlockAbusiveClients отклоняет запросы с заблокированных адресов и засчитывает адресу
+// ответы 401/403. Должен стоять перед middleware аутентификации.
+func BlockAbusiveClients(limiter *abuse.Limiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := "ip:" + clientHost(r)
+		if rejectBlocked(w, limiter, client) {
+			return
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+
+		if recorder.status == http.StatusUnauthorized || recorder.status == http.StatusForbidden {
+			limiter.RecordFailure(r.Context(), client, abuse.KindAuth)
+		}
+	})
+}
+
+// BlockAbusiveCallers отклоняет запросы заблокированных пользователей и сервисных аккаунтов
+// и передает идентификатор клиента расширению abuse. Должен стоять после middleware аутентификации.
+func BlockAbusiveCallers(limiter *abuse.Limiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller := "ip:" + clientHost(r)
+		if principal, ok := auth.PrincipalFromContext(r.Context()); ok && principal.Email != "" {
+			caller = "user:" + principal.Email
+		}
+		if rejectBlocked(w, limiter, caller) {
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(abuse.WithCaller(r.Context(), caller)))
+	})
+}
+
+// rejectBlocked отвечает 429 с подсказкой Retry-After, если клиент заблокирован
+func rejectBlocked(w http.ResponseWriter, limiter *abuse.Limiter, caller string) bool {
+	remaining, blocked := limiter.RetryAfter(caller)
+	if !blocked {
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+	http.Error(w, "too many invalid requests, retry after "+remaining.Round(time.Second).String(), http.StatusTooManyRequests)
+	return true
+}
+
+func clientHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,93 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/abuse"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type nopLockoutRepository struct{}
+
+func (nopLockoutRepository) AddLockout(ctx context.Context, lockout *repository.CallerLockout) error {
+	return nil
+}
+
+func TestBlockAbusiveClients(t *testing.T) {
+	limiter := abuse.NewLimiter(config.AbuseConfig{
+		Enabled:   true,
+		Threshold: 2,
+		Window:    time.Minute,
+		BaseBlock: 90 * time.Second,
+		MaxBlock:  time.Hour,
+	}, nopLockoutRepository{}, zaptest.NewLogger(t))
+
+	proxy, err := auth.NewTrustedProxy([]string{"10.0.0.0/8"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var caller string
+	handler := BlockAbusiveClients(limiter, auth.Middleware(proxy, BlockAbusiveCallers(limiter, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller = abuse.CallerFromContext(r.Context())
+		w.WriteHeader(http.StatusUnauthorized)
+	}))))
+
+	request := func(email string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/query", nil)
+		req.RemoteAddr = "10.0.0.1:51234"
+		if email != "" {
+			req.Header.Set(auth.UserEmailHeader, email)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	request("")
+	if caller != "ip:10.0.0.1" {
+		t.Errorf("expected anonymous caller to be identified by address, got %q", caller)
+	}
+	request("")
+
+	rec := request("")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 after repeated auth failures, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "90" {
+		t.Errorf("expected Retry-After 90, got %q", got)
+	}
+}
+
+// Ошибки проверки засчитываются пользователю, а не адресу
+func TestBlockAbusiveCallers(t *testing.T) {
+	limiter := abuse.NewLimiter(config.AbuseConfig{Enabled: true, Threshold: 1, Window: time.Minute, BaseBlock: time.Minute}, nopLockoutRepository{}, zaptest.NewLogger(t))
+	handler := BlockAbusiveCallers(limiter, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limiter.RecordFailure(r.Context(), abuse.CallerFromContext(r.Context()), abuse.KindValidation)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/query", nil)
+	req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Email: "client@company.ru"}))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if _, blocked := limiter.RetryAfter("user:client@company.ru"); !blocked {
+		t.Error("expected user to be blocked")
+	}
+	if _, blocked := limiter.RetryAfter("ip:192.0.2.1"); blocked {
+		t.Error("address must not be blocked for user failures")
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 for blocked user, got %d", rec.Code)
+	}
+}
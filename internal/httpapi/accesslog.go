@@ -0,0 +1,181 @@
+package httpapi
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader заголовок с идентификатором запроса. Если клиент его не передал, шлюз генерирует новый.
+const RequestIDHeader = "X-Request-Id"
+
+const commonLogTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+type requestInfoKey struct{}
+
+// requestInfo данные запроса, которые заполняются обработчиками и попадают в журнал
+type requestInfo struct {
+	id     string
+	caller string
+}
+
+// RequestIDFromContext возвращает идентификатор текущего HTTP-запроса
+func RequestIDFromContext(ctx context.Context) string {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		return info.id
+	}
+	return ""
+}
+
+// RecordCaller запоминает аутентифицированного пользователя для журнала запросов.
+// Должен стоять после middleware аутентификации.
+func RecordCaller(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+			if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+				info.caller = principal.Email
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder запоминает код ответа и размер тела
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Flush нужен SSE и подпискам GraphQL
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack нужен websocket-транспорту подписок GraphQL
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	if r.status == 0 {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap позволяет http.ResponseController добраться до исходного ResponseWriter (websocket hijack)
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// RequestID присваивает запросу идентификатор из X-Request-Id или новый и возвращает его в ответе
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, withRequestInfo(w, r))
+	})
+}
+
+// withRequestInfo добавляет в контекст данные запроса, если RequestID еще не сделал этого
+func withRequestInfo(w http.ResponseWriter, r *http.Request) *http.Request {
+	if _, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+		return r
+	}
+
+	info := &requestInfo{id: r.Header.Get(RequestIDHeader)}
+	if info.id == "" {
+		info.id = uuid.New().String()
+	}
+	w.Header().Set(RequestIDHeader, info.id)
+	return r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info))
+}
+
+// AccessLog пишет в журнал каждый HTTP-запрос: метод, путь, статус, размер ответа, длительность,
+// пользователя и идентификатор запроса. Пользователь, аутентифицированный до журнала, записывается
+// сразу; для аутентификации внутри обработчика нужен RecordCaller.
+func AccessLog(next http.Handler, cfg config.AccessLogConfig, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = withRequestInfo(w, r)
+		info := r.Context().Value(requestInfoKey{}).(*requestInfo)
+		if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+			info.caller = principal.Email
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		started := time.Now()
+		next.ServeHTTP(recorder, r)
+
+		if !cfg.Enabled || (cfg.ExcludeHealth && isHealthCheck(r.URL.Path)) {
+			return
+		}
+
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		duration := time.Since(started)
+
+		if cfg.Format == "common" {
+			logger.Info(commonLogLine(r, info, status, recorder.bytes, started, duration))
+			return
+		}
+
+		logger.Info("http request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+			zap.Int("status", status),
+			zap.Int("bytes", recorder.bytes),
+			zap.Duration("duration", duration),
+			zap.String("caller", info.caller),
+			zap.String("request_id", info.id),
+			zap.String("remote_addr", r.RemoteAddr),
+			zap.String("user_agent", r.UserAgent()))
+	})
+}
+
+// commonLogLine формирует строку в формате Common Log Format с длительностью и идентификатором запроса
+func commonLogLine(r *http.Request, info *requestInfo, status, bytes int, started time.Time, duration time.Duration) string {
+	host := r.RemoteAddr
+	if i := strings.LastIndex(host, ":"); i > 0 {
+		host = host[:i]
+	}
+	caller := info.caller
+	if caller == "" {
+		caller = "-"
+	}
+	return fmt.Sprintf(`%s - %s [%s] "%s %s %s" %d %d %dms %s`,
+		host, caller, started.Format(commonLogTimeFormat), r.Method, r.URL.RequestURI(), r.Proto,
+		status, bytes, duration.Milliseconds(), info.id)
+}
+
+func isHealthCheck(path string) bool {
+	return path == "/health" || path == "/healthz" || path == "/livez" || path == "/readyz"
+}
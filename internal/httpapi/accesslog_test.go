@@ -0,0 +1,116 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAccessLog(t *testing.T) {
+	tests := []struct {
+		name            string
+		cfg             config.AccessLogConfig
+		path            string
+		headers         map[string]string
+		expectedEntries int
+		expectedCaller  string
+	}{
+		{
+			name:            "json_format",
+			cfg:             config.AccessLogConfig{Enabled: true, Format: "json"},
+			path:            "/query",
+			headers:         map[string]string{auth.UserEmailHeader: "user@example.com", auth.ProxySecretHeader: "proxy-secret"},
+			expectedEntries: 1,
+			expectedCaller:  "user@example.com",
+		},
+		{
+			name:            "health_excluded",
+			cfg:             config.AccessLogConfig{Enabled: true, Format: "json", ExcludeHealth: true},
+			path:            "/health",
+			expectedEntries: 0,
+		},
+		{
+			name:            "health_logged_by_default",
+			cfg:             config.AccessLogConfig{Enabled: true, Format: "json"},
+			path:            "/health",
+			expectedEntries: 1,
+		},
+		{
+			name:            "disabled",
+			cfg:             config.AccessLogConfig{Enabled: false},
+			path:            "/query",
+			expectedEntries: 0,
+		},
+	}
+
+	proxy, err := auth.NewTrustedProxy(nil, "proxy-secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+			inner := auth.Middleware(proxy, RecordCaller(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if RequestIDFromContext(r.Context()) == "" {
+					t.Error("expected request id in context")
+				}
+				w.WriteHeader(http.StatusAccepted)
+				w.Write([]byte("ok"))
+			})))
+			handler := AccessLog(inner, tt.cfg, zap.New(core))
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Header().Get(RequestIDHeader) == "" {
+				t.Error("expected request id response header")
+			}
+			if logs.Len() != tt.expectedEntries {
+				t.Fatalf("expected %d log entries, but got %d", tt.expectedEntries, logs.Len())
+			}
+			if tt.expectedEntries == 0 {
+				return
+			}
+
+			fields := logs.All()[0].ContextMap()
+			if fields["status"] != int64(http.StatusAccepted) {
+				t.Errorf("expected status %d, but got %v", http.StatusAccepted, fields["status"])
+			}
+			if fields["bytes"] != int64(2) {
+				t.Errorf("expected 2 bytes, but got %v", fields["bytes"])
+			}
+			if fields["caller"] != tt.expectedCaller {
+				t.Errorf("expected caller '%s', but got '%v'", tt.expectedCaller, fields["caller"])
+			}
+		})
+	}
+}
+
+func TestAccessLogCommonFormat(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	handler := AccessLog(http.NotFoundHandler(), config.AccessLogConfig{Enabled: true, Format: "common"}, zap.New(core))
+
+	req := httptest.NewRequest(http.MethodGet, "/missing?x=1", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if logs.Len() != 1 {
+		t.Fatalf("expected 1 log entry, but got %d", logs.Len())
+	}
+	line := logs.All()[0].Message
+	if !strings.Contains(line, `"GET /missing?x=1 HTTP/1.1" 404`) || !strings.HasSuffix(line, "req-1") {
+		t.Errorf("unexpected common log line: %s", line)
+	}
+}
@@ -0,0 +1,32 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// NewAccessReviewHandler отдает отчет о доступе к проверкам в CSV для аудиторов администратору шлюза
+func NewAccessReviewHandler(accessReviewService service.AccessReviewService, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := auth.RequireRole(r.Context(), auth.RoleAdmin); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		export, err := accessReviewService.ExportAccessReviewCSV(r.Context())
+		if err != nil {
+			logger.Error("failed to export access review", zap.Error(err))
+			http.Error(w, "failed to export access review", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName))
+		w.Write(export.Content)
+	})
+}
@@ -0,0 +1,59 @@
+package httpapi
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/service"
+	"scoring_api_gateway/internal/signing"
+
+	"go.uber.org/zap"
+)
+
+// NewAuditTrailHandler отдает подписанный PDF с хронологией проверки.
+// Подпись передается в заголовках, чтобы файл можно было приложить к делу без изменений.
+func NewAuditTrailHandler(auditService service.AuditService, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+
+		export, err := auditService.ExportAuditTrailPDF(r.Context(), id)
+		if err != nil {
+			status := auditErrorStatus(err)
+			if status == http.StatusInternalServerError {
+				logger.Error("failed to export audit trail", zap.Error(err), zap.String("verification_id", id))
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		writeSignedPDF(w, export)
+	})
+}
+
+// auditErrorStatus код ответа на ошибку чтения журнала: неизвестная проверка - 404, запрос без
+// пользователя - 401, без доступа к проверке или к проверке другого региона - 403, остальное - ошибка шлюза
+func auditErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, auth.ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, auth.ErrForbidden), errors.Is(err, repository.ErrCrossRegion):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeSignedPDF(w http.ResponseWriter, export *service.SignedPDF) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName))
+	w.Header().Set("X-Signature", base64.StdEncoding.EncodeToString(export.Signature))
+	w.Header().Set("X-Signature-Algorithm", signing.Algorithm)
+	w.Header().Set("X-Signature-Key-Id", export.KeyID)
+	w.Write(export.Content)
+}
@@ -0,0 +1,57 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/service"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type exportAuditService struct {
+	service.AuditService
+	err error
+}
+
+func (s *exportAuditService) ExportAuditTrailPDF(ctx context.Context, verificationID string) (*service.SignedPDF, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &service.SignedPDF{FileName: "trail.pdf", Content: []byte("%PDF"), Signature: []byte("sig"), KeyID: "key-1"}, nil
+}
+
+func TestAuditTrailHandlerStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "exported", expectedStatus: http.StatusOK},
+		{name: "unknown_verification", err: fmt.Errorf("failed to get verification: %w", repository.ErrNotFound), expectedStatus: http.StatusNotFound},
+		{name: "unauthenticated", err: auth.ErrUnauthenticated, expectedStatus: http.StatusUnauthorized},
+		{name: "access_denied", err: fmt.Errorf("%w: api key does not allow read operation", auth.ErrForbidden), expectedStatus: http.StatusForbidden},
+		{name: "cross_region", err: fmt.Errorf("failed to get verification: %w", &repository.Error{Kind: repository.ErrCrossRegion, Err: errors.New("verification v-1 is stored in region kz")}), expectedStatus: http.StatusForbidden},
+		{name: "signing_not_configured", err: errors.New("signing key is not configured"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.Handle("GET /verifications/{id}/audit-trail.pdf", NewAuditTrailHandler(&exportAuditService{err: tt.err}, zaptest.NewLogger(t)))
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/verifications/v-1/audit-trail.pdf", nil))
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
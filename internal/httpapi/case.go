@@ -0,0 +1,32 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// NewCaseReportHandler отдает подписанный PDF со всеми проверками дела.
+// Дело другой организации не отличается от отсутствующего.
+func NewCaseReportHandler(caseService service.CaseService, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+
+		export, err := caseService.ExportCaseReportPDF(r.Context(), id)
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("failed to export case report", zap.Error(err), zap.String("case_id", id))
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		writeSignedPDF(w, export)
+	})
+}
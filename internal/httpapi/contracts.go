@@ -0,0 +1,66 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/contracts"
+)
+
+// NewContractsHandler отдает набор контрактов шлюза и его части:
+//
+//	/contracts                        - весь набор в JSON
+//	/contracts/schema.graphql         - схема GraphQL
+//	/contracts/openapi.json           - описание REST-обработчиков
+//	/contracts/messages/{subject}.json - JSON Schema сообщения NATS
+//
+// Набор неизменен до перезапуска, поэтому ETag - его digest и клиенты могут опрашивать
+// обработчик с If-None-Match. Доступен только аутентифицированным клиентам.
+func NewContractsHandler(bundle *contracts.Bundle) http.Handler {
+	etag := `"` + bundle.Versions.Digest + `"`
+	document, _ := json.Marshal(bundle)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /contracts", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(document)
+	})
+	mux.HandleFunc("GET /contracts/schema.graphql", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(bundle.GraphQL))
+	})
+	mux.HandleFunc("GET /contracts/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(bundle.OpenAPI)
+	})
+	mux.HandleFunc("GET /contracts/messages/{file}", func(w http.ResponseWriter, r *http.Request) {
+		subject, ok := strings.CutSuffix(r.PathValue("file"), ".json")
+		message, found := bundle.Message(subject)
+		if !ok || !found {
+			http.Error(w, "unknown message subject", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/schema+json")
+		w.Write(message.Schema)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := auth.RequireEmail(r.Context()); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "private, no-cache")
+		w.Header().Set("X-Schema-Version", strconv.Itoa(bundle.Versions.Schema))
+		w.Header().Set("X-Messages-Version", strconv.Itoa(bundle.Versions.Messages))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
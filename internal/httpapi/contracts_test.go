@@ -0,0 +1,55 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/contracts"
+)
+
+func TestContractsHandler(t *testing.T) {
+	bundle := &contracts.Bundle{
+		Versions: contracts.Versions{Schema: 2, Messages: 1, Digest: "abc"},
+		GraphQL:  "type Query { ok: Boolean }",
+		Messages: []contracts.Message{{Subject: "verification.completed", Schema: []byte(`{"type":"object"}`)}},
+		OpenAPI:  []byte(`{"openapi":"3.0.3"}`),
+	}
+	handler := NewContractsHandler(bundle)
+
+	tests := []struct {
+		name        string
+		target      string
+		anonymous   bool
+		ifNoneMatch string
+		code        int
+		body        string
+	}{
+		{name: "bundle", target: "/contracts", code: http.StatusOK, body: `"digest":"abc"`},
+		{name: "sdl", target: "/contracts/schema.graphql", code: http.StatusOK, body: "type Query"},
+		{name: "openapi", target: "/contracts/openapi.json", code: http.StatusOK, body: `"openapi":"3.0.3"`},
+		{name: "message", target: "/contracts/messages/verification.completed.json", code: http.StatusOK, body: `"type":"object"`},
+		{name: "unknown_message", target: "/contracts/messages/verification.unknown.json", code: http.StatusNotFound},
+		{name: "not_modified", target: "/contracts", ifNoneMatch: `"abc"`, code: http.StatusNotModified},
+		{name: "anonymous", target: "/contracts", anonymous: true, code: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if !tt.anonymous {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Email: "dev@bank.ru"}))
+			}
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.code || !strings.Contains(rec.Body.String(), tt.body) {
+				t.Errorf("expected %d %q, but got %d %q", tt.code, tt.body, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// EmailConfirmer подтверждает email автора по токену из ссылки в письме
+type EmailConfirmer interface {
+	Confirm(ctx context.Context, token string) (string, int, error)
+}
+
+// NewEmailConfirmationHandler подтверждает email по ссылке из письма и отправляет воркерам
+// удержанные запросы автора. Аутентификация не нужна: доступ дает токен из ссылки.
+func NewEmailConfirmationHandler(confirmer EmailConfirmer, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			http.Error(w, "confirmation token is required", http.StatusBadRequest)
+			return
+		}
+
+		email, released, err := confirmer.Confirm(r.Context(), token)
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "confirmation link is invalid or expired", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("failed to confirm email", zap.Error(err))
+			http.Error(w, "failed to confirm email", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintf(w, "Email %s confirmed, %d verification requests sent for processing\n", email, released)
+	})
+}
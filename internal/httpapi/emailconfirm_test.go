@@ -0,0 +1,51 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type stubConfirmer struct {
+	tokens map[string]string
+}
+
+func (c *stubConfirmer) Confirm(ctx context.Context, token string) (string, int, error) {
+	email, ok := c.tokens[token]
+	if !ok {
+		return "", 0, &repository.Error{Kind: repository.ErrNotFound, Err: errors.New("confirmation token is invalid or expired")}
+	}
+	return email, 2, nil
+}
+
+func TestEmailConfirmationHandler(t *testing.T) {
+	handler := NewEmailConfirmationHandler(&stubConfirmer{tokens: map[string]string{"valid": "new@bank.ru"}}, zaptest.NewLogger(t))
+
+	tests := []struct {
+		name   string
+		target string
+		code   int
+		body   string
+	}{
+		{name: "confirmed", target: "/confirm-email?token=valid", code: http.StatusOK, body: "Email new@bank.ru confirmed, 2 verification requests"},
+		{name: "expired", target: "/confirm-email?token=stale", code: http.StatusNotFound, body: "invalid or expired"},
+		{name: "missing_token", target: "/confirm-email", code: http.StatusBadRequest, body: "token is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.code || !strings.Contains(rec.Body.String(), tt.body) {
+				t.Errorf("expected %d %q, but got %d %q", tt.code, tt.body, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// NewVerificationEventsHandler отдает хронологию проверки потоком server-sent events: изменения
+// статуса, поступление данных и комментарии по мере появления. Нужен клиентам, которым прокси не дает
+// открыть WebSocket для подписок GraphQL.
+//
+// Идентификатор события - его номер в хронологии, поэтому клиент, переподключаясь с Last-Event-ID,
+// получает только пропущенные события. Поток не заканчивается с завершением проверки: после него
+// возможны дозагрузка данных и комментарии.
+func NewVerificationEventsHandler(auditService service.AuditService, cfg config.SubscriptionsConfig, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id := r.PathValue("id")
+
+		if _, err := auth.RequireEmail(ctx); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		sent := 0
+		if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+			n, err := strconv.Atoi(lastEventID)
+			if err != nil || n < 0 {
+				http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+				return
+			}
+			sent = n
+		}
+
+		trail, err := auditService.GetAuditTrail(ctx, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("failed to get verification events", zap.Error(err), zap.String("verification_id", id))
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		controller := http.NewResponseController(w)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		// Буферизующие прокси иначе задерживают события до конца ответа
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		poll := time.NewTicker(cfg.EventsPollInterval)
+		defer poll.Stop()
+		var keepAlive <-chan time.Time
+		if cfg.KeepAliveInterval > 0 {
+			ticker := time.NewTicker(cfg.KeepAliveInterval)
+			defer ticker.Stop()
+			keepAlive = ticker.C
+		}
+
+		for {
+			for ; sent < len(trail.Entries); sent++ {
+				if err := writeEvent(w, sent+1, trail.Entries[sent]); err != nil {
+					return
+				}
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-keepAlive:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+				continue
+			case <-poll.C:
+			}
+
+			current, err := auditService.GetAuditTrail(ctx, id)
+			switch {
+			case errors.Is(err, repository.ErrNotFound):
+				// Проверка удалена, новых событий не будет
+				return
+			case err != nil:
+				if ctx.Err() == nil {
+					logger.Warn("failed to poll verification events", zap.Error(err), zap.String("verification_id", id))
+				}
+				continue
+			}
+			trail = current
+		}
+	})
+}
+
+// writeEvent пишет запись хронологии событием SSE, тип события - тип записи аудита
+func writeEvent(w http.ResponseWriter, id int, entry *model.AuditTrailEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, entry.EventType, data)
+	return err
+}
@@ -0,0 +1,117 @@
+package httpapi
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/service"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type stubAuditService struct {
+	service.AuditService
+
+	mu      sync.Mutex
+	entries []*model.AuditTrailEntry
+}
+
+func (s *stubAuditService) GetAuditTrail(ctx context.Context, verificationID string) (*model.VerificationAuditTrail, error) {
+	if verificationID != "v-1" {
+		return nil, repository.ErrNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &model.VerificationAuditTrail{Entries: append([]*model.AuditTrailEntry(nil), s.entries...)}, nil
+}
+
+func (s *stubAuditService) add(entry *model.AuditTrailEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+}
+
+func TestVerificationEventsHandler(t *testing.T) {
+	audit := &stubAuditService{entries: []*model.AuditTrailEntry{
+		{EventType: model.AuditEventTypeCreated, OccurredAt: "2024-01-01T10:00:00Z"},
+		{EventType: model.AuditEventTypeDataReceived, OccurredAt: "2024-01-01T10:01:00Z"},
+	}}
+	cfg := config.SubscriptionsConfig{EventsPollInterval: 10 * time.Millisecond}
+	mux := http.NewServeMux()
+	mux.Handle("GET /verifications/{id}/events", NewVerificationEventsHandler(audit, cfg, zaptest.NewLogger(t)))
+	proxy, err := auth.NewTrustedProxy([]string{"127.0.0.0/8", "::1/128"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := httptest.NewServer(auth.Middleware(proxy, mux))
+	t.Cleanup(server.Close)
+
+	request := func(id, lastEventID, email string) *http.Response {
+		t.Helper()
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/verifications/"+id+"/events", nil)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		if email != "" {
+			req.Header.Set(auth.UserEmailHeader, email)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	if resp := request("v-1", "", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected anonymous request to be rejected, but got %d", resp.StatusCode)
+	}
+	if resp := request("v-2", "", "analyst@bank.ru"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected unknown verification to be not found, but got %d", resp.StatusCode)
+	}
+	if resp := request("v-1", "last", "analyst@bank.ru"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected invalid Last-Event-ID to be rejected, but got %d", resp.StatusCode)
+	}
+
+	resp := request("v-1", "1", "analyst@bank.ru")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	events := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		t.Helper()
+		var lines []string
+		for {
+			line, err := events.ReadString('\n')
+			if err != nil {
+				t.Fatalf("stream ended: %v", err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				return strings.Join(lines, "\n")
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	// Событие 1 клиент уже получил до переподключения
+	if event := readEvent(); !strings.HasPrefix(event, "id: 2\nevent: DATA_RECEIVED\ndata: {") {
+		t.Errorf("unexpected first event %q", event)
+	}
+	audit.add(&model.AuditTrailEntry{EventType: model.AuditEventTypeStatusChanged, OccurredAt: "2024-01-01T10:02:00Z"})
+	if event := readEvent(); !strings.HasPrefix(event, "id: 3\nevent: STATUS_CHANGED\ndata: {") {
+		t.Errorf("unexpected polled event %q", event)
+	}
+}
@@ -0,0 +1,74 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"scoring_api_gateway/internal/faults"
+
+	"go.uber.org/zap"
+)
+
+// faultRuleJSON правило внедрения сбоев в формате API, задержка задается строкой вида "250ms"
+type faultRuleJSON struct {
+	Target         faults.Target `json:"target"`
+	ErrorPercent   float64       `json:"error_percent"`
+	LatencyPercent float64       `json:"latency_percent"`
+	Latency        string        `json:"latency,omitempty"`
+}
+
+// NewFaultsHandler управляет правилами внедрения сбоев:
+// GET возвращает действующие правила, PUT задает правило для зависимости, DELETE снимает все правила.
+func NewFaultsHandler(injector *faults.Injector, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var body faultRuleJSON
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			rule := faults.Rule{ErrorPercent: body.ErrorPercent, LatencyPercent: body.LatencyPercent}
+			if body.Latency != "" {
+				latency, err := time.ParseDuration(body.Latency)
+				if err != nil {
+					http.Error(w, "invalid latency: "+err.Error(), http.StatusBadRequest)
+					return
+				}
+				rule.Latency = latency
+			}
+
+			if err := injector.SetRule(body.Target, rule); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			logger.Warn("fault injection rule set",
+				zap.String("target", string(body.Target)),
+				zap.Float64("error_percent", rule.ErrorPercent),
+				zap.Float64("latency_percent", rule.LatencyPercent),
+				zap.Duration("latency", rule.Latency))
+		case http.MethodDelete:
+			injector.Reset()
+			logger.Warn("fault injection rules reset")
+		default:
+			w.Header().Set("Allow", "GET, PUT, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		rules := []faultRuleJSON{}
+		for target, rule := range injector.Rules() {
+			item := faultRuleJSON{Target: target, ErrorPercent: rule.ErrorPercent, LatencyPercent: rule.LatencyPercent}
+			if rule.Latency > 0 {
+				item.Latency = rule.Latency.String()
+			}
+			rules = append(rules, item)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rules)
+	})
+}
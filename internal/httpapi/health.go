@@ -0,0 +1,215 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"time"
+
+	"scoring_api_gateway/internal/buildinfo"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/maintenance"
+	"scoring_api_gateway/internal/messaging"
+)
+
+type healthResponse struct {
+	Status string                     `json:"status"`
+	NATS   messaging.ConnectionStatus `json:"nats"`
+}
+
+// NewHealthHandler отдает состояние шлюза и активный сервер NATS.
+// Потеря брокера не делает шлюз нездоровым: клиент NATS переподключается в фоне.
+func NewHealthHandler(natsClient messaging.NATSClient) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := healthResponse{
+			Status: "ok",
+			NATS:   natsClient.Status(),
+		}
+		if !response.NATS.Connected {
+			response.Status = "degraded"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	})
+}
+
+// Pinger проверка доступности базы данных
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Статусы проверок в формате application/health+json
+const (
+	healthPass = "pass"
+	healthWarn = "warn"
+	healthFail = "fail"
+)
+
+// healthCheck результат одной проверки компонента
+type healthCheck struct {
+	ComponentID   string `json:"componentId,omitempty"`
+	ComponentType string `json:"componentType"`
+	ObservedValue any    `json:"observedValue"`
+	ObservedUnit  string `json:"observedUnit,omitempty"`
+	Status        string `json:"status"`
+	Time          string `json:"time"`
+	Output        string `json:"output,omitempty"`
+}
+
+// healthDocument ответ по черновику draft-inadarei-api-health-check
+type healthDocument struct {
+	Status    string                   `json:"status"`
+	Version   string                   `json:"version,omitempty"`
+	ReleaseID string                   `json:"releaseId,omitempty"`
+	ServiceID string                   `json:"serviceId"`
+	Notes     []string                 `json:"notes,omitempty"`
+	Checks    map[string][]healthCheck `json:"checks,omitempty"`
+}
+
+// healthReport собирает ответ с учетом подробности: при status проверки не выводятся,
+// при checks из них убираются адреса компонентов и тексты ошибок
+type healthReport struct {
+	response  healthDocument
+	verbosity string
+	now       time.Time
+}
+
+func newHealthReport(build buildinfo.Info, verbosity string, now time.Time) *healthReport {
+	return &healthReport{
+		response: healthDocument{
+			Status:    healthPass,
+			Version:   build.Version,
+			ReleaseID: build.GitSHA,
+			ServiceID: "scoring_api_gateway",
+			Checks:    map[string][]healthCheck{},
+		},
+		verbosity: verbosity,
+		now:       now,
+	}
+}
+
+// add добавляет проверку; итоговый статус равен худшему из статусов проверок
+func (h *healthReport) add(name string, check healthCheck) {
+	if severity(check.Status) > severity(h.response.Status) {
+		h.response.Status = check.Status
+	}
+	if h.verbosity != config.HealthVerbosityFull {
+		check.ComponentID, check.Output = "", ""
+	}
+	check.Time = h.now.UTC().Format(time.RFC3339)
+	h.response.Checks[name] = append(h.response.Checks[name], check)
+}
+
+func (h *healthReport) note(note string) {
+	if h.verbosity == config.HealthVerbosityFull {
+		h.response.Notes = append(h.response.Notes, note)
+	}
+}
+
+func (h *healthReport) write(w http.ResponseWriter) {
+	if h.verbosity == config.HealthVerbosityStatus {
+		h.response.Checks = nil
+	}
+
+	code := http.StatusOK
+	if h.response.Status == healthFail {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/health+json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(h.response)
+}
+
+func severity(status string) int {
+	switch status {
+	case healthWarn:
+		return 1
+	case healthFail:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// NewLiveHandler сообщает, что процесс жив, в формате application/health+json. Внешние
+// зависимости не проверяются: их недоступность не повод перезапускать экземпляр.
+func NewLiveHandler(build buildinfo.Info, started time.Time, verbosity string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		report := newHealthReport(build, verbosity, now)
+		report.add("uptime", healthCheck{
+			ComponentType: "system",
+			ObservedValue: int64(now.Sub(started).Seconds()),
+			ObservedUnit:  "s",
+			Status:        healthPass,
+		})
+		report.add("goroutines:count", healthCheck{
+			ComponentType: "system",
+			ObservedValue: runtime.NumGoroutine(),
+			Status:        healthPass,
+		})
+		report.write(w)
+	})
+}
+
+// NewReadyHandler сообщает о готовности принимать запросы в формате application/health+json.
+// В режиме обслуживания экземпляр остается готовым (запросы на чтение обслуживаются) со
+// статусом "warn". Без подключения к NATS статус тоже "warn": чтение продолжает работать.
+// Недоступная база дает статус "fail" и код 503.
+func NewReadyHandler(db Pinger, natsClient messaging.NATSClient, mode *maintenance.Mode, build buildinfo.Info, verbosity string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+
+		started := time.Now()
+		pingErr := db.Ping(ctx)
+		elapsed := time.Since(started)
+
+		report := newHealthReport(build, verbosity, started)
+
+		database := healthCheck{
+			ComponentType: "datastore",
+			ObservedValue: float64(elapsed.Microseconds()) / 1000,
+			ObservedUnit:  "ms",
+			Status:        healthPass,
+		}
+		if pingErr != nil {
+			database.Status = healthFail
+			database.Output = pingErr.Error()
+		}
+		report.add("postgres:responseTime", database)
+
+		nats := natsClient.Status()
+		broker := healthCheck{
+			ComponentID:   nats.Server,
+			ComponentType: "component",
+			ObservedValue: nats.Connected,
+			Status:        healthPass,
+		}
+		if !nats.Connected {
+			broker.Status = healthWarn
+		}
+		report.add("nats:connected", broker)
+
+		maintenanceStatus := mode.Status()
+		maintenanceCheck := healthCheck{
+			ComponentType: "system",
+			ObservedValue: maintenanceStatus.Enabled,
+			Status:        healthPass,
+		}
+		if maintenanceStatus.Enabled {
+			maintenanceCheck.Status = healthWarn
+			if maintenanceStatus.Reason != nil {
+				maintenanceCheck.Output = *maintenanceStatus.Reason
+			}
+			report.note("maintenance mode: verification requests are refused")
+		}
+		report.add("maintenance:enabled", maintenanceCheck)
+
+		report.write(w)
+	})
+}
@@ -0,0 +1,131 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/buildinfo"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/maintenance"
+	"scoring_api_gateway/internal/messaging"
+)
+
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) Ping(ctx context.Context) error {
+	return f(ctx)
+}
+
+type statusNATSClient struct {
+	messaging.NATSClient
+	status messaging.ConnectionStatus
+}
+
+func (c *statusNATSClient) Status() messaging.ConnectionStatus {
+	return c.status
+}
+
+func getHealth(t *testing.T, handler http.Handler, path string) (int, healthDocument) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	if contentType := rec.Header().Get("Content-Type"); contentType != "application/health+json" {
+		t.Errorf("expected application/health+json, but got %q", contentType)
+	}
+	var document healthDocument
+	if err := json.Unmarshal(rec.Body.Bytes(), &document); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return rec.Code, document
+}
+
+func TestReadyHandler(t *testing.T) {
+	build := buildinfo.Info{Version: "1.4.0", GitSHA: "abc123"}
+	connected := &statusNATSClient{status: messaging.ConnectionStatus{Connected: true, Server: "nats://nats-1:4222"}}
+	healthy := pingerFunc(func(ctx context.Context) error { return nil })
+
+	tests := []struct {
+		name        string
+		db          Pinger
+		nats        messaging.NATSClient
+		maintenance bool
+		wantCode    int
+		wantStatus  string
+	}{
+		{name: "ready", db: healthy, nats: connected, wantCode: http.StatusOK, wantStatus: healthPass},
+		{name: "nats disconnected", db: healthy, nats: &statusNATSClient{}, wantCode: http.StatusOK, wantStatus: healthWarn},
+		{name: "maintenance", db: healthy, nats: connected, maintenance: true, wantCode: http.StatusOK, wantStatus: healthWarn},
+		{
+			name:       "database down",
+			db:         pingerFunc(func(ctx context.Context) error { return errors.New("connection refused") }),
+			nats:       &statusNATSClient{},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: healthFail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode := maintenance.NewMode(tt.maintenance, time.Second)
+			code, document := getHealth(t, NewReadyHandler(tt.db, tt.nats, mode, build, config.HealthVerbosityChecks), "/readyz")
+
+			if code != tt.wantCode || document.Status != tt.wantStatus {
+				t.Errorf("expected %d %s, but got %d %s", tt.wantCode, tt.wantStatus, code, document.Status)
+			}
+			if document.Version != "1.4.0" || document.ReleaseID != "abc123" {
+				t.Errorf("unexpected version %q and release %q", document.Version, document.ReleaseID)
+			}
+			for _, name := range []string{"postgres:responseTime", "nats:connected", "maintenance:enabled"} {
+				checks := document.Checks[name]
+				if len(checks) != 1 || checks[0].ComponentType == "" || checks[0].Time == "" || checks[0].Status == "" {
+					t.Errorf("expected a complete %s check, but got %+v", name, checks)
+				}
+			}
+		})
+	}
+}
+
+func TestReadyHandlerVerbosity(t *testing.T) {
+	failing := pingerFunc(func(ctx context.Context) error { return errors.New("connection refused") })
+	nats := &statusNATSClient{status: messaging.ConnectionStatus{Connected: true, Server: "nats://nats-1:4222"}}
+	mode := maintenance.NewMode(false, time.Second)
+
+	_, document := getHealth(t, NewReadyHandler(failing, nats, mode, buildinfo.Info{}, config.HealthVerbosityStatus), "/readyz")
+	if document.Status != healthFail || document.Checks != nil {
+		t.Errorf("expected status only, but got %+v", document)
+	}
+
+	_, document = getHealth(t, NewReadyHandler(failing, nats, mode, buildinfo.Info{}, config.HealthVerbosityChecks), "/readyz")
+	if check := document.Checks["postgres:responseTime"][0]; check.Output != "" {
+		t.Errorf("expected error text to be hidden, but got %q", check.Output)
+	}
+	if check := document.Checks["nats:connected"][0]; check.ComponentID != "" {
+		t.Errorf("expected nats server to be hidden, but got %q", check.ComponentID)
+	}
+
+	_, document = getHealth(t, NewReadyHandler(failing, nats, mode, buildinfo.Info{}, config.HealthVerbosityFull), "/readyz")
+	if check := document.Checks["postgres:responseTime"][0]; check.Output != "connection refused" {
+		t.Errorf("expected error text, but got %q", check.Output)
+	}
+	if check := document.Checks["nats:connected"][0]; check.ComponentID != "nats://nats-1:4222" {
+		t.Errorf("expected nats server, but got %q", check.ComponentID)
+	}
+}
+
+func TestLiveHandler(t *testing.T) {
+	started := time.Now().Add(-90 * time.Second)
+	code, document := getHealth(t, NewLiveHandler(buildinfo.Info{}, started, config.HealthVerbosityChecks), "/livez")
+
+	if code != http.StatusOK || document.Status != healthPass {
+		t.Errorf("expected live instance to pass, but got %d %s", code, document.Status)
+	}
+	if uptime := document.Checks["uptime"]; len(uptime) != 1 || uptime[0].ObservedValue.(float64) < 90 || uptime[0].ObservedUnit != "s" {
+		t.Errorf("unexpected uptime check %+v", uptime)
+	}
+}
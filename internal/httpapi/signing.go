@@ -0,0 +1,32 @@
+package httpapi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"scoring_api_gateway/internal/signing"
+)
+
+type signingKeyResponse struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id"`
+	// PublicKey открытый ключ Ed25519 (32 байта) в base64
+	PublicKey string `json:"public_key"`
+}
+
+// NewSigningKeyHandler отдает открытый ключ, которым проверяются подписи итогов проверок и выгрузок.
+// Ключ публичный, поэтому обработчик доступен без аутентификации.
+func NewSigningKeyHandler(signer *signing.Signer) http.Handler {
+	response := signingKeyResponse{
+		Algorithm: signing.Algorithm,
+		KeyID:     signer.KeyID(),
+		PublicKey: base64.StdEncoding.EncodeToString(signer.PublicKey()),
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		json.NewEncoder(w).Encode(response)
+	})
+}
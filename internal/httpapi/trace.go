@@ -0,0 +1,21 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"scoring_api_gateway/internal/messaging"
+)
+
+// TraceContext передает заголовки W3C Trace Context запроса в публикации NATS,
+// чтобы обработка проверки воркерами попадала в ту же трассировку
+func TraceContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if traceParent := r.Header.Get(messaging.HeaderTraceParent); traceParent != "" {
+			r = r.WithContext(messaging.WithTraceContext(r.Context(), messaging.TraceContext{
+				TraceParent: traceParent,
+				TraceState:  r.Header.Get(messaging.HeaderTraceState),
+			}))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,18 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"scoring_api_gateway/internal/buildinfo"
+)
+
+// NewVersionHandler отдает версию сборки, коммит и версию схемы GraphQL.
+// Как и /health, доступен без аутентификации: секретов в ответе нет.
+func NewVersionHandler(info buildinfo.Info) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		json.NewEncoder(w).Encode(info)
+	})
+}
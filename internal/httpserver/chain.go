@@ -0,0 +1,42 @@
+// Package httpserver собирает общую цепочку middleware HTTP-сервера шлюза, чтобы все маршруты,
+// включая новые, получали одинаковую обработку паник, идентификаторы запросов, аутентификацию,
+// CORS, блокировку клиентов, журнал и метрики.
+package httpserver
+
+import "net/http"
+
+// Middleware оборачивает обработчик HTTP
+type Middleware func(http.Handler) http.Handler
+
+// Chain объединяет middleware в порядке выполнения: первый получает запрос первым
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			if middlewares[i] != nil {
+				next = middlewares[i](next)
+			}
+		}
+		return next
+	}
+}
+
+// Stack стандартная цепочка шлюза. Порядок этапов фиксирован, пустые этапы пропускаются:
+//
+//	recover → request ID → auth → CORS → rate limit → access log → metrics
+//
+// Журнал и метрики стоят после аутентификации, чтобы видеть пользователя запроса, а восстановление
+// после паники - снаружи всех, чтобы паника в любом этапе не обрывала соединение.
+type Stack struct {
+	Recover   Middleware
+	RequestID Middleware
+	Auth      Middleware
+	CORS      Middleware
+	RateLimit Middleware
+	AccessLog Middleware
+	Metrics   Middleware
+}
+
+// Handler оборачивает обработчик маршрутов всеми этапами цепочки
+func (s Stack) Handler(next http.Handler) http.Handler {
+	return Chain(s.Recover, s.RequestID, s.Auth, s.CORS, s.RateLimit, s.AccessLog, s.Metrics)(next)
+}
@@ -0,0 +1,125 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func recording(name string, calls *[]string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*calls = append(*calls, name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestStackOrder(t *testing.T) {
+	var calls []string
+	stack := Stack{
+		Metrics:   recording("metrics", &calls),
+		AccessLog: recording("access_log", &calls),
+		RateLimit: recording("rate_limit", &calls),
+		CORS:      recording("cors", &calls),
+		Auth:      recording("auth", &calls),
+		RequestID: recording("request_id", &calls),
+		Recover:   recording("recover", &calls),
+	}
+	handler := stack.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "handler")
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/query", nil))
+
+	expected := []string{"recover", "request_id", "auth", "cors", "rate_limit", "access_log", "metrics", "handler"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected order %v, but got %v", expected, calls)
+	}
+}
+
+func TestStackSkipsEmptyStages(t *testing.T) {
+	var calls []string
+	stack := Stack{
+		Auth:    recording("auth", &calls),
+		Metrics: recording("metrics", &calls),
+		CORS:    CORS(nil),
+	}
+	handler := stack.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "handler")
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	expected := []string{"auth", "metrics", "handler"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected order %v, but got %v", expected, calls)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	handler := Recover(zap.New(core))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/query", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, but got %d", rec.Code)
+	}
+	if logs.Len() != 1 || logs.All()[0].ContextMap()["panic"] != "boom" {
+		t.Errorf("expected panic to be logged, but got %v", logs.All())
+	}
+}
+
+func TestCORS(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := CORS([]string{"https://app.example.com"})(next)
+
+	tests := []struct {
+		name           string
+		method         string
+		origin         string
+		preflight      bool
+		expectedStatus int
+		expectedOrigin string
+	}{
+		{name: "allowed", method: http.MethodPost, origin: "https://app.example.com", expectedStatus: http.StatusOK, expectedOrigin: "https://app.example.com"},
+		{name: "foreign", method: http.MethodPost, origin: "https://evil.example.com", expectedStatus: http.StatusOK},
+		{name: "no_origin", method: http.MethodGet, expectedStatus: http.StatusOK},
+		{name: "preflight", method: http.MethodOptions, origin: "https://app.example.com", preflight: true, expectedStatus: http.StatusNoContent, expectedOrigin: "https://app.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/query", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, but got %d", tt.expectedStatus, rec.Code)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.expectedOrigin {
+				t.Errorf("expected allowed origin %q, but got %q", tt.expectedOrigin, got)
+			}
+			if tt.preflight && !strings.Contains(rec.Header().Get("Access-Control-Allow-Headers"), "X-API-Key") {
+				t.Errorf("expected X-API-Key in allowed headers, but got %q", rec.Header().Get("Access-Control-Allow-Headers"))
+			}
+		})
+	}
+}
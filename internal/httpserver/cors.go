@@ -0,0 +1,44 @@
+package httpserver
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// corsAllowedHeaders заголовки, которые браузерные клиенты передают шлюзу
+const corsAllowedHeaders = "Content-Type, Authorization, X-API-Key, X-Request-Id, traceparent, tracestate"
+
+// CORS разрешает запросы браузеров с перечисленных источников ("*" - с любого).
+// Без источников возвращает nil, и этап пропускается.
+func CORS(allowedOrigins []string) Middleware {
+	if len(allowedOrigins) == 0 {
+		return nil
+	}
+	anyOrigin := slices.Contains(allowedOrigins, "*")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" || !(anyOrigin || slices.Contains(allowedOrigins, origin)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Add("Vary", "Origin")
+			header.Set("Access-Control-Expose-Headers", "X-Request-Id, Retry-After")
+
+			// Предварительный запрос браузера не доходит до обработчиков
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				header.Set("Access-Control-Allow-Methods", strings.Join([]string{http.MethodGet, http.MethodPost, http.MethodOptions}, ", "))
+				header.Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+				header.Set("Access-Control-Max-Age", "600")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
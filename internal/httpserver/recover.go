@@ -0,0 +1,35 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+)
+
+// Recover отвечает 500 на панику обработчика и пишет ее в журнал со стеком.
+// http.ErrAbortHandler пробрасывается дальше: им обработчик намеренно обрывает ответ.
+func Recover(logger *zap.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(recovered)
+				}
+
+				logger.Error("http handler panicked",
+					zap.Any("panic", recovered),
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+					zap.ByteString("stack", debug.Stack()))
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package inbound
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Причины отклонения уведомлений о завершении
+const (
+	ReasonStale      = "stale"
+	ReasonFuture     = "future"
+	ReasonUnknown    = "unknown_verification"
+	ReasonSuperseded = "superseded"
+	ReasonReplayed   = "replayed"
+)
+
+// Store хранилище отметок о полученных уведомлениях и отклоненных уведомлений
+type Store interface {
+	GetVerificationState(ctx context.Context, id string) (*repository.VerificationState, error)
+	MarkSeen(ctx context.Context, consumer, eventID, verificationID string) (bool, error)
+	Park(ctx context.Context, event *repository.ParkedEvent) error
+	Prune(ctx context.Context, seenBefore, parkedBefore time.Time) (int64, error)
+}
+
+// Auditor журнал событий проверок
+type Auditor interface {
+	RecordEvent(ctx context.Context, verificationID string, eventType model.AuditEventType, actor string, details map[string]any) error
+}
+
+type guardClient struct {
+	messaging.NATSClient
+	store    Store
+	auditor  Auditor
+	consumer string
+	cfg      config.InboundEventsConfig
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewClient проверяет уведомления о завершении перед обработкой: проверка должна существовать,
+// уведомление - быть не старше cfg.MaxAge, не обогнанным более поздним статусом и не полученным
+// получателем consumer ранее. Отклоненные уведомления не доходят до обработчика.
+func NewClient(client messaging.NATSClient, store Store, auditor Auditor, consumer string, cfg config.InboundEventsConfig, logger *zap.Logger) messaging.NATSClient {
+	return &guardClient{
+		NATSClient: client,
+		store:      store,
+		auditor:    auditor,
+		consumer:   consumer,
+		cfg:        cfg,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+func (c *guardClient) SubscribeToVerificationCompleted(ctx context.Context, handler func(*domain.Verification)) error {
+	return c.NATSClient.SubscribeToVerificationCompleted(ctx, func(verification *domain.Verification) {
+		if reason := c.check(ctx, verification); reason != "" {
+			c.reject(ctx, verification, reason)
+			return
+		}
+		handler(verification)
+	})
+}
+
+// check возвращает причину отклонения уведомления или пустую строку. Недоступность базы не
+// повод терять завершение: такие уведомления обрабатываются без проверки.
+func (c *guardClient) check(ctx context.Context, verification *domain.Verification) string {
+	// Время есть только у уведомлений воркеров, публикующих completed_at или время CloudEvent
+	if completedAt := verification.UpdatedAt; !completedAt.IsZero() {
+		now := c.now()
+		if now.Sub(completedAt) > c.cfg.MaxAge {
+			return ReasonStale
+		}
+		if completedAt.Sub(now) > c.cfg.MaxClockSkew {
+			return ReasonFuture
+		}
+	}
+
+	if _, err := uuid.Parse(verification.ID); err != nil {
+		return ReasonUnknown
+	}
+	state, err := c.store.GetVerificationState(ctx, verification.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ReasonUnknown
+		}
+		c.logger.Warn("failed to check verification completed message, applying it", zap.Error(err), zap.String("verification_id", verification.ID))
+		return ""
+	}
+	// Проверка уже изменена позже уведомления: оно пришло не по порядку. Только что созданную
+	// проверку уведомление обогнать не может.
+	if state.Status != verification.Status && state.ChangedAt != nil && (verification.UpdatedAt.IsZero() || state.ChangedAt.After(verification.UpdatedAt)) {
+		return ReasonSuperseded
+	}
+
+	// Отметка ставится последней, чтобы отклоненное уведомление не считалось полученным
+	if verification.EventID == "" {
+		return ""
+	}
+	first, err := c.store.MarkSeen(ctx, c.consumer, verification.EventID, verification.ID)
+	if err != nil {
+		c.logger.Warn("failed to check verification completed message for replay, applying it", zap.Error(err), zap.String("verification_id", verification.ID))
+		return ""
+	}
+	if !first {
+		return ReasonReplayed
+	}
+	return ""
+}
+
+func (c *guardClient) reject(ctx context.Context, verification *domain.Verification, reason string) {
+	metrics.InboundEventsRejected.WithLabelValues(reason, c.cfg.Action).Inc()
+	c.logger.Warn("verification completed message rejected",
+		zap.String("verification_id", verification.ID),
+		zap.String("event_id", verification.EventID),
+		zap.String("status", string(verification.Status)),
+		zap.String("reason", reason),
+		zap.String("action", c.cfg.Action))
+
+	// Журнал событий ведется только для существующих проверок
+	if reason != ReasonUnknown {
+		details := map[string]any{
+			"reason": reason,
+			"status": verification.Status,
+			"action": c.cfg.Action,
+		}
+		if verification.EventID != "" {
+			details["event_id"] = verification.EventID
+		}
+		if !verification.UpdatedAt.IsZero() {
+			details["completed_at"] = verification.UpdatedAt.UTC().Format(time.RFC3339Nano)
+		}
+		if err := c.auditor.RecordEvent(ctx, verification.ID, model.AuditEventTypeEventRejected, "", details); err != nil {
+			c.logger.Error("failed to record rejected event", zap.Error(err), zap.String("verification_id", verification.ID))
+		}
+	}
+
+	if c.cfg.Action != config.InboundEventsPark {
+		return
+	}
+	if err := c.store.Park(ctx, &repository.ParkedEvent{Verification: verification, Reason: reason}); err != nil {
+		c.logger.Error("failed to park rejected event", zap.Error(err), zap.String("verification_id", verification.ID))
+	}
+}
+
+// CleanupJob удаляет отметки о полученных уведомлениях старше MaxAge: такие уведомления
+// отклоняются по возрасту и без отметки. Отклоненные уведомления хранятся ParkedRetention.
+type CleanupJob struct {
+	store Store
+	cfg   config.InboundEventsConfig
+	now   func() time.Time
+}
+
+func NewCleanupJob(store Store, cfg config.InboundEventsConfig) *CleanupJob {
+	return &CleanupJob{store: store, cfg: cfg, now: time.Now}
+}
+
+func (j *CleanupJob) Name() string {
+	return "inbound_events_cleanup"
+}
+
+func (j *CleanupJob) Run(ctx context.Context) error {
+	now := j.now()
+	_, err := j.store.Prune(ctx, now.Add(-j.cfg.MaxAge), now.Add(-j.cfg.ParkedRetention))
+	return err
+}
@@ -0,0 +1,184 @@
+package inbound
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type subscribedClient struct {
+	messaging.NATSClient
+	handler func(*domain.Verification)
+}
+
+func (c *subscribedClient) SubscribeToVerificationCompleted(ctx context.Context, handler func(*domain.Verification)) error {
+	c.handler = handler
+	return nil
+}
+
+type memoryStore struct {
+	states map[string]*repository.VerificationState
+	seen   map[string]bool
+	parked []*repository.ParkedEvent
+	err    error
+	pruned [2]time.Time
+}
+
+func (s *memoryStore) GetVerificationState(ctx context.Context, id string) (*repository.VerificationState, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	state, ok := s.states[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return state, nil
+}
+
+func (s *memoryStore) MarkSeen(ctx context.Context, consumer, eventID, verificationID string) (bool, error) {
+	key := consumer + "/" + eventID
+	if s.seen[key] {
+		return false, nil
+	}
+	s.seen[key] = true
+	return true, nil
+}
+
+func (s *memoryStore) Park(ctx context.Context, event *repository.ParkedEvent) error {
+	s.parked = append(s.parked, event)
+	return nil
+}
+
+func (s *memoryStore) Prune(ctx context.Context, seenBefore, parkedBefore time.Time) (int64, error) {
+	s.pruned = [2]time.Time{seenBefore, parkedBefore}
+	return 0, nil
+}
+
+type recordingAuditor struct {
+	details []map[string]any
+}
+
+func (a *recordingAuditor) RecordEvent(ctx context.Context, verificationID string, eventType model.AuditEventType, actor string, details map[string]any) error {
+	if eventType == model.AuditEventTypeEventRejected {
+		a.details = append(a.details, details)
+	}
+	return nil
+}
+
+const (
+	completedID = "0f8fad5b-d9cb-469f-a165-70867728950e"
+	createdID   = "1b4e28ba-2fa1-41d2-883f-0016d3cca427"
+	unknownID   = "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+)
+
+func TestClientRejectsSuspiciousEvents(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	changedAt := now.Add(-time.Minute)
+	store := &memoryStore{
+		states: map[string]*repository.VerificationState{
+			completedID: {Status: domain.StatusCompleted, ChangedAt: &changedAt},
+			createdID:   {Status: domain.StatusInProcess},
+		},
+		seen: map[string]bool{},
+	}
+	auditor := &recordingAuditor{}
+	cfg := config.InboundEventsConfig{MaxAge: 24 * time.Hour, MaxClockSkew: time.Minute, Action: config.InboundEventsPark}
+	next := &subscribedClient{}
+	client := NewClient(next, store, auditor, "gateway", cfg, zaptest.NewLogger(t)).(*guardClient)
+	client.now = func() time.Time { return now }
+
+	var applied []string
+	if err := client.SubscribeToVerificationCompleted(context.Background(), func(verification *domain.Verification) {
+		applied = append(applied, verification.EventID)
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		verification domain.Verification
+		reason       string
+	}{
+		{name: "accepted", verification: domain.Verification{ID: completedID, EventID: "e-1", Status: domain.StatusCompleted, UpdatedAt: now.Add(-time.Minute)}},
+		{name: "replayed", verification: domain.Verification{ID: completedID, EventID: "e-1", Status: domain.StatusCompleted, UpdatedAt: now.Add(-time.Minute)}, reason: ReasonReplayed},
+		{name: "stale", verification: domain.Verification{ID: completedID, EventID: "e-2", Status: domain.StatusCompleted, UpdatedAt: now.Add(-25 * time.Hour)}, reason: ReasonStale},
+		{name: "future", verification: domain.Verification{ID: completedID, EventID: "e-3", Status: domain.StatusCompleted, UpdatedAt: now.Add(time.Hour)}, reason: ReasonFuture},
+		{name: "unknown", verification: domain.Verification{ID: unknownID, EventID: "e-4", Status: domain.StatusCompleted}, reason: ReasonUnknown},
+		{name: "malformed_id", verification: domain.Verification{ID: "v-1", EventID: "e-5", Status: domain.StatusCompleted}, reason: ReasonUnknown},
+		{name: "superseded", verification: domain.Verification{ID: completedID, EventID: "e-6", Status: domain.StatusPartiallyCompleted, UpdatedAt: now.Add(-time.Hour)}, reason: ReasonSuperseded},
+		{name: "without_time", verification: domain.Verification{ID: completedID, EventID: "e-7", Status: domain.StatusError}, reason: ReasonSuperseded},
+		{name: "first_completion", verification: domain.Verification{ID: createdID, EventID: "e-8", Status: domain.StatusCompleted, UpdatedAt: now.Add(-time.Hour)}},
+		{name: "first_completion_without_time", verification: domain.Verification{ID: createdID, EventID: "e-9", Status: domain.StatusError}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applied, store.parked = nil, nil
+			verification := tt.verification
+			next.handler(&verification)
+
+			if tt.reason == "" {
+				if len(applied) != 1 || len(store.parked) != 0 {
+					t.Errorf("expected the event to be applied, but got %v applied and %d parked", applied, len(store.parked))
+				}
+				return
+			}
+			if len(applied) != 0 {
+				t.Fatalf("expected the event to be rejected as %s, but it was applied", tt.reason)
+			}
+			if len(store.parked) != 1 || store.parked[0].Reason != tt.reason {
+				t.Errorf("expected the event to be parked as %s, but got %+v", tt.reason, store.parked)
+			}
+		})
+	}
+
+	// Примененные уведомления в журнал не пишутся, а для неизвестных проверок запись невозможна
+	if len(auditor.details) != 5 {
+		t.Errorf("expected audit entries for rejected known verifications, but got %v", auditor.details)
+	}
+	if details := auditor.details[0]; details["reason"] != ReasonReplayed || details["event_id"] != "e-1" || details["action"] != config.InboundEventsPark {
+		t.Errorf("unexpected audit details %v", details)
+	}
+	// Отклоненное уведомление не отмечается полученным
+	if store.seen["gateway/e-6"] {
+		t.Error("expected the superseded event not to be marked as seen")
+	}
+}
+
+func TestClientAppliesEventsWhenStoreFails(t *testing.T) {
+	store := &memoryStore{err: errors.New("connection refused"), seen: map[string]bool{}}
+	cfg := config.InboundEventsConfig{MaxAge: time.Hour, Action: config.InboundEventsDiscard}
+	next := &subscribedClient{}
+	client := NewClient(next, store, &recordingAuditor{}, "gateway", cfg, zaptest.NewLogger(t))
+
+	applied := 0
+	if err := client.SubscribeToVerificationCompleted(context.Background(), func(*domain.Verification) { applied++ }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	next.handler(&domain.Verification{ID: completedID, Status: domain.StatusCompleted, UpdatedAt: time.Now()})
+	if applied != 1 {
+		t.Errorf("expected the event to be applied without the check, but got %d", applied)
+	}
+}
+
+func TestCleanupJob(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	store := &memoryStore{}
+	job := NewCleanupJob(store, config.InboundEventsConfig{MaxAge: 24 * time.Hour, ParkedRetention: 720 * time.Hour})
+	job.now = func() time.Time { return now }
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.pruned != [2]time.Time{now.Add(-24 * time.Hour), now.Add(-720 * time.Hour)} {
+		t.Errorf("unexpected prune bounds %v", store.pruned)
+	}
+}
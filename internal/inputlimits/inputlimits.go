@@ -0,0 +1,241 @@
+// Package inputlimits ограничивает размер аргументов GraphQL-запросов по директивам @constraint
+// схемы, чтобы слишком большие значения не попадали в базу и сообщения NATS.
+package inputlimits
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"scoring_api_gateway/internal/metrics"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// CodeInputTooLarge код ошибки в extensions.code
+const CodeInputTooLarge = "INPUT_TOO_LARGE"
+
+// directiveName директива схемы с ограничениями аргумента или поля входного типа
+const directiveName = "constraint"
+
+// Виды ограничений, они же аргументы директивы
+const (
+	MaxLength = "maxLength"
+	MaxItems  = "maxItems"
+)
+
+// Constraint ограничения одного аргумента или поля входного типа, 0 - без ограничения
+type Constraint struct {
+	// MaxLength наибольшее число символов строки или каждой строки списка
+	MaxLength int
+	// MaxItems наибольшее число элементов списка
+	MaxItems int
+}
+
+// Violation нарушенное ограничение. Path - путь к значению от поля запроса: createVerification.inn,
+// verificationStatuses.inns[3], createVerification.externalRef.ref.
+type Violation struct {
+	Path       string `json:"path"`
+	Constraint string `json:"constraint"`
+	Limit      int    `json:"limit"`
+	Actual     int    `json:"actual"`
+}
+
+// Rules ограничения схемы: аргументов по координате Type.field(arg:) и полей входных типов по
+// координате Input.field
+type Rules struct {
+	schema      *ast.Schema
+	arguments   map[string]Constraint
+	inputFields map[string]Constraint
+}
+
+// NewRules собирает ограничения из директив схемы. Ограничение, неприменимое к типу значения
+// (maxItems у строки, maxLength у числа), считается ошибкой схемы.
+func NewRules(schema *ast.Schema) (*Rules, error) {
+	rules := &Rules{
+		schema:      schema,
+		arguments:   make(map[string]Constraint),
+		inputFields: make(map[string]Constraint),
+	}
+
+	for _, definition := range schema.Types {
+		switch definition.Kind {
+		case ast.Object:
+			for _, field := range definition.Fields {
+				for _, argument := range field.Arguments {
+					coordinate := fmt.Sprintf("%s.%s(%s:)", definition.Name, field.Name, argument.Name)
+					if err := rules.add(rules.arguments, coordinate, argument.Type, argument.Directives); err != nil {
+						return nil, err
+					}
+				}
+			}
+		case ast.InputObject:
+			for _, field := range definition.Fields {
+				coordinate := definition.Name + "." + field.Name
+				if err := rules.add(rules.inputFields, coordinate, field.Type, field.Directives); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return rules, nil
+}
+
+func (r *Rules) add(target map[string]Constraint, coordinate string, typ *ast.Type, directives ast.DirectiveList) error {
+	directive := directives.ForName(directiveName)
+	if directive == nil {
+		return nil
+	}
+
+	var constraint Constraint
+	for _, argument := range directive.Arguments {
+		limit, err := strconv.Atoi(argument.Value.Raw)
+		if err != nil || limit <= 0 {
+			return fmt.Errorf("%s: @%s(%s) must be a positive integer, got %q", coordinate, directiveName, argument.Name, argument.Value.Raw)
+		}
+		switch argument.Name {
+		case MaxLength:
+			if baseType(typ) != "String" && baseType(typ) != "ID" {
+				return fmt.Errorf("%s: @%s(%s) requires a string type, got %s", coordinate, directiveName, MaxLength, typ)
+			}
+			constraint.MaxLength = limit
+		case MaxItems:
+			if typ.Elem == nil {
+				return fmt.Errorf("%s: @%s(%s) requires a list type, got %s", coordinate, directiveName, MaxItems, typ)
+			}
+			constraint.MaxItems = limit
+		}
+	}
+	target[coordinate] = constraint
+	return nil
+}
+
+// Check проверяет аргументы всех полей операции, включая поля фрагментов и вложенные поля, и
+// возвращает все нарушения
+func (r *Rules) Check(operation *ast.OperationDefinition, variables map[string]any) []Violation {
+	var violations []Violation
+	r.checkSelections(operation.SelectionSet, "", variables, map[string]bool{}, &violations)
+	return violations
+}
+
+func (r *Rules) checkSelections(selections ast.SelectionSet, prefix string, variables map[string]any, visited map[string]bool, violations *[]Violation) {
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *ast.Field:
+			path := prefix + s.Alias
+			if s.Definition != nil && s.ObjectDefinition != nil && len(s.Arguments) > 0 {
+				values := s.ArgumentMap(variables)
+				for _, argument := range s.Definition.Arguments {
+					constraint := r.arguments[fmt.Sprintf("%s.%s(%s:)", s.ObjectDefinition.Name, s.Name, argument.Name)]
+					r.checkValue(path+"."+argument.Name, argument.Type, values[argument.Name], constraint, violations)
+				}
+			}
+			r.checkSelections(s.SelectionSet, path+".", variables, visited, violations)
+		case *ast.InlineFragment:
+			r.checkSelections(s.SelectionSet, prefix, variables, visited, violations)
+		case *ast.FragmentSpread:
+			// Фрагмент, использованный несколько раз, дал бы повторяющиеся нарушения
+			if s.Definition != nil && !visited[s.Name] {
+				visited[s.Name] = true
+				r.checkSelections(s.Definition.SelectionSet, prefix, variables, visited, violations)
+			}
+		}
+	}
+}
+
+func (r *Rules) checkValue(path string, typ *ast.Type, value any, constraint Constraint, violations *[]Violation) {
+	if value == nil {
+		return
+	}
+
+	if typ.Elem != nil {
+		items, ok := value.([]any)
+		if !ok {
+			// Одиночное значение на месте списка GraphQL считает списком из одного элемента
+			items = []any{value}
+		}
+		if constraint.MaxItems > 0 && len(items) > constraint.MaxItems {
+			*violations = append(*violations, Violation{Path: path, Constraint: MaxItems, Limit: constraint.MaxItems, Actual: len(items)})
+			return
+		}
+		for i, item := range items {
+			r.checkValue(fmt.Sprintf("%s[%d]", path, i), typ.Elem, item, Constraint{MaxLength: constraint.MaxLength}, violations)
+		}
+		return
+	}
+
+	switch v := value.(type) {
+	case string:
+		if length := utf8.RuneCountInString(v); constraint.MaxLength > 0 && length > constraint.MaxLength {
+			*violations = append(*violations, Violation{Path: path, Constraint: MaxLength, Limit: constraint.MaxLength, Actual: length})
+		}
+	case map[string]any:
+		definition := r.schema.Types[typ.NamedType]
+		if definition == nil || definition.Kind != ast.InputObject {
+			return
+		}
+		for _, field := range definition.Fields {
+			r.checkValue(path+"."+field.Name, field.Type, v[field.Name], r.inputFields[definition.Name+"."+field.Name], violations)
+		}
+	}
+}
+
+func baseType(typ *ast.Type) string {
+	for typ.Elem != nil {
+		typ = typ.Elem
+	}
+	return typ.NamedType
+}
+
+// Extension расширение gqlgen, отклоняющее операцию, аргументы которой нарушают ограничения
+// схемы. Клиент получает одну ошибку со всеми нарушениями в extensions.violations.
+type Extension struct {
+	rules *Rules
+}
+
+var (
+	_ graphql.HandlerExtension        = (*Extension)(nil)
+	_ graphql.OperationContextMutator = (*Extension)(nil)
+)
+
+func NewExtension() *Extension {
+	return &Extension{}
+}
+
+func (e *Extension) ExtensionName() string {
+	return "InputLimits"
+}
+
+// Validate собирает ограничения из схемы при подключении расширения
+func (e *Extension) Validate(schema graphql.ExecutableSchema) error {
+	rules, err := NewRules(schema.Schema())
+	if err != nil {
+		return err
+	}
+	e.rules = rules
+	return nil
+}
+
+func (e *Extension) MutateOperationContext(ctx context.Context, opCtx *graphql.OperationContext) *gqlerror.Error {
+	if opCtx.Operation == nil {
+		return nil
+	}
+
+	violations := e.rules.Check(opCtx.Operation, opCtx.Variables)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	metrics.GraphQLLimitRejections.WithLabelValues(CodeInputTooLarge).Inc()
+	message := fmt.Sprintf("%s exceeds %s of %d", violations[0].Path, violations[0].Constraint, violations[0].Limit)
+	if len(violations) > 1 {
+		message = fmt.Sprintf("%s and %d more input size violations", message, len(violations)-1)
+	}
+	return &gqlerror.Error{
+		Message:    message,
+		Extensions: map[string]any{"code": CodeInputTooLarge, "violations": violations},
+	}
+}
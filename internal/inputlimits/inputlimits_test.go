@@ -0,0 +1,164 @@
+package inputlimits_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"scoring_api_gateway/graph"
+	"scoring_api_gateway/internal/inputlimits"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const testSchema = `
+directive @constraint(maxLength: Int, maxItems: Int) on ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION
+
+input RefInput {
+  system: String @constraint(maxLength: 3)
+  ref: String! @constraint(maxLength: 5)
+}
+
+type Item {
+  name(locale: String @constraint(maxLength: 2)): String!
+}
+
+type Query {
+  items(inns: [String!]! @constraint(maxItems: 2, maxLength: 4), ref: RefInput, refs: [RefInput!]): [Item!]!
+}
+`
+
+func TestRulesCheck(t *testing.T) {
+	schema := gqlparser.MustLoadSchema(&ast.Source{Name: "test.graphqls", Input: testSchema})
+	rules, err := inputlimits.NewRules(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		variables  map[string]any
+		violations []inputlimits.Violation
+	}{
+		{
+			name:  "within_limits",
+			query: `{ items(inns: ["1234", "5678"], ref: {system: "crm", ref: "A-1"}) { name(locale: "ru") } }`,
+		},
+		{
+			name:  "too_many_items",
+			query: `{ items(inns: ["1", "2", "3"]) { name } }`,
+			violations: []inputlimits.Violation{
+				{Path: "items.inns", Constraint: inputlimits.MaxItems, Limit: 2, Actual: 3},
+			},
+		},
+		{
+			name:      "all_violations_at_once",
+			query:     `query($ref: RefInput) { list: items(inns: ["12345", "ИНН"], ref: $ref, refs: [{ref: "123456"}]) { ...names } } fragment names on Item { name(locale: "rus") }`,
+			variables: map[string]any{"ref": map[string]any{"system": "long", "ref": "ok"}},
+			violations: []inputlimits.Violation{
+				{Path: "list.inns[0]", Constraint: inputlimits.MaxLength, Limit: 4, Actual: 5},
+				{Path: "list.ref.system", Constraint: inputlimits.MaxLength, Limit: 3, Actual: 4},
+				{Path: "list.refs[0].ref", Constraint: inputlimits.MaxLength, Limit: 5, Actual: 6},
+				{Path: "list.name.locale", Constraint: inputlimits.MaxLength, Limit: 2, Actual: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document, errs := gqlparser.LoadQuery(schema, tt.query)
+			if errs != nil {
+				t.Fatalf("failed to parse query: %v", errs)
+			}
+
+			violations := rules.Check(document.Operations[0], tt.variables)
+			if !reflect.DeepEqual(violations, tt.violations) {
+				t.Errorf("expected violations %+v, but got %+v", tt.violations, violations)
+			}
+		})
+	}
+}
+
+func TestNewRulesRejectsMisplacedConstraints(t *testing.T) {
+	tests := []struct {
+		name          string
+		argument      string
+		expectedError string
+	}{
+		{name: "max_items_on_string", argument: `inn: String @constraint(maxItems: 2)`, expectedError: "Query.items(inn:): @constraint(maxItems) requires a list type"},
+		{name: "max_length_on_int", argument: `limit: Int @constraint(maxLength: 2)`, expectedError: "Query.items(limit:): @constraint(maxLength) requires a string type"},
+		{name: "zero_limit", argument: `inn: String @constraint(maxLength: 0)`, expectedError: "Query.items(inn:): @constraint(maxLength) must be a positive integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := gqlparser.MustLoadSchema(&ast.Source{Input: `
+directive @constraint(maxLength: Int, maxItems: Int) on ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION
+type Query { items(` + tt.argument + `): [String!]! }`})
+
+			if _, err := inputlimits.NewRules(schema); err == nil || !strings.HasPrefix(err.Error(), tt.expectedError) {
+				t.Errorf("expected error starting with %q, but got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+type graphQLResponse struct {
+	Errors []struct {
+		Message    string `json:"message"`
+		Extensions struct {
+			Code       string                  `json:"code"`
+			Violations []inputlimits.Violation `json:"violations"`
+		} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func TestExtension(t *testing.T) {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{}}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(inputlimits.NewExtension())
+
+	path := make([]string, 33)
+	for i := range path {
+		path[i] = "founders"
+	}
+	body, _ := json.Marshal(map[string]any{
+		"query": `mutation($comment: String, $path: [String!]!) {
+  reviewVerification(id: "v-1", decision: APPROVED, comment: $comment) { id }
+  createVerification(inn: "77070838930000", requestedDataTypes: [BASIC_INFORMATION], externalRef: {ref: "CASE-42"}) { id }
+  redaction: redactVerificationData(verificationId: "v-1", dataType: BASIC_INFORMATION, path: $path, reason: "GDPR") { id }
+}`,
+		"variables": map[string]any{"comment": strings.Repeat("я", 2001), "path": path},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	var response graphQLResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	if len(response.Errors) != 1 || response.Errors[0].Extensions.Code != inputlimits.CodeInputTooLarge {
+		t.Fatalf("expected one %s error, but got %s", inputlimits.CodeInputTooLarge, rec.Body.String())
+	}
+
+	expected := []inputlimits.Violation{
+		{Path: "reviewVerification.comment", Constraint: inputlimits.MaxLength, Limit: 2000, Actual: 2001},
+		{Path: "createVerification.inn", Constraint: inputlimits.MaxLength, Limit: 12, Actual: 14},
+		{Path: "redaction.path", Constraint: inputlimits.MaxItems, Limit: 32, Actual: 33},
+	}
+	if got := response.Errors[0].Extensions.Violations; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected violations %+v, but got %+v", expected, got)
+	}
+	if message := response.Errors[0].Message; message != "reviewVerification.comment exceeds maxLength of 2000 and 2 more input size violations" {
+		t.Errorf("unexpected message %q", message)
+	}
+}
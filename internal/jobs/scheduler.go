@@ -0,0 +1,102 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Job периодическая фоновая задача
+type Job interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+type entry struct {
+	job      Job
+	interval time.Duration
+}
+
+// Scheduler запускает зарегистрированные задачи с заданным интервалом
+type Scheduler struct {
+	entries []entry
+	logger  *zap.Logger
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	paused  func() bool
+}
+
+func NewScheduler(logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		logger: logger,
+	}
+}
+
+// Register добавляет задачу в расписание. Вызывается до Start. Неположительный интервал -
+// ошибка: с ним задача не смогла бы запуститься.
+func (s *Scheduler) Register(job Job, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("job %s interval must be positive, got %s", job.Name(), interval)
+	}
+	s.entries = append(s.entries, entry{job: job, interval: interval})
+	s.logger.Info("job registered", zap.String("job", job.Name()), zap.Duration("interval", interval))
+	return nil
+}
+
+// PauseWhile пропускает запуски задач, пока paused возвращает true (например, в режиме обслуживания).
+// Вызывается до Start.
+func (s *Scheduler) PauseWhile(paused func() bool) {
+	s.paused = paused
+}
+
+// Start запускает все зарегистрированные задачи в отдельных горутинах
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go func(e entry) {
+			defer s.wg.Done()
+			s.loop(ctx, e)
+		}(e)
+	}
+}
+
+// Stop останавливает задачи и дожидается завершения текущих запусков
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx, e.job)
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	if s.paused != nil && s.paused() {
+		s.logger.Debug("job skipped while paused", zap.String("job", job.Name()))
+		return
+	}
+
+	start := time.Now()
+	if err := job.Run(ctx); err != nil {
+		s.logger.Error("job failed", zap.String("job", job.Name()), zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return
+	}
+	s.logger.Debug("job completed", zap.String("job", job.Name()), zap.Duration("duration", time.Since(start)))
+}
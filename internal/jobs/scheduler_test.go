@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type namedJob struct{}
+
+func (namedJob) Name() string                  { return "cleanup" }
+func (namedJob) Run(ctx context.Context) error { return nil }
+
+func TestSchedulerRegister(t *testing.T) {
+	tests := []struct {
+		name        string
+		interval    time.Duration
+		expectedErr bool
+	}{
+		{name: "positive", interval: time.Minute},
+		{name: "zero", interval: 0, expectedErr: true},
+		{name: "negative", interval: -time.Second, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduler := NewScheduler(zaptest.NewLogger(t))
+			err := scheduler.Register(namedJob{}, tt.interval)
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("expected error: %v, got %v", tt.expectedErr, err)
+			}
+			if registered := len(scheduler.entries) == 1; registered == tt.expectedErr {
+				t.Errorf("expected job registered: %v, got %d entries", !tt.expectedErr, len(scheduler.entries))
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Package mailer отправляет письма шлюза: ссылки подтверждения email авторов проверок.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"scoring_api_gateway/internal/config"
+
+	"go.uber.org/zap"
+)
+
+// Sender отправляет письмо
+type Sender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// NewSender отправляет письма через SMTP-сервер из cfg. Без cfg.Host письма только пишутся
+// в журнал, чтобы стенды без почтового сервера можно было проверить по журналу.
+func NewSender(cfg config.SMTPConfig, logger *zap.Logger) Sender {
+	if cfg.Host == "" {
+		return &logSender{logger: logger}
+	}
+	return &smtpSender{cfg: cfg}
+}
+
+type smtpSender struct {
+	cfg config.SMTPConfig
+}
+
+func (s *smtpSender) Send(ctx context.Context, to, subject, body string) error {
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		return fmt.Errorf("invalid email header")
+	}
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+	message := Message(s.cfg.From, to, subject, body)
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	if err := smtp.SendMail(addr, auth, s.cfg.From, []string{to}, message); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// Message собирает текстовое письмо в UTF-8
+func Message(from, to, subject, body string) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + to + "\r\n")
+	b.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", subject) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+type logSender struct {
+	logger *zap.Logger
+}
+
+func (s *logSender) Send(ctx context.Context, to, subject, body string) error {
+	s.logger.Info("email not sent: SMTP is not configured",
+		zap.String("to", to),
+		zap.String("subject", subject),
+		zap.String("body", body))
+	return nil
+}
@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"scoring_api_gateway/internal/config"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestMessage(t *testing.T) {
+	message := string(Message("gateway@example.com", "new@bank.ru", "Подтвердите email", "Строка 1\nСтрока 2"))
+
+	for _, expected := range []string{
+		"From: gateway@example.com\r\n",
+		"To: new@bank.ru\r\n",
+		"Subject: =?UTF-8?b?",
+		"Content-Type: text/plain; charset=UTF-8\r\n",
+		"\r\n\r\nСтрока 1\r\nСтрока 2",
+	} {
+		if !strings.Contains(message, expected) {
+			t.Errorf("expected message to contain %q, but got:\n%s", expected, message)
+		}
+	}
+}
+
+func TestSMTPSenderRejectsHeaderInjection(t *testing.T) {
+	sender := NewSender(config.SMTPConfig{Host: "smtp.example.com", Port: 587}, zaptest.NewLogger(t))
+	if err := sender.Send(context.Background(), "new@bank.ru\r\nBcc: other@example.com", "subject", "body"); err == nil {
+		t.Error("expected error for recipient with line break")
+	}
+}
@@ -0,0 +1,60 @@
+package maintenance
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Guard расширение gqlgen, отклоняющее мутации в режиме обслуживания. Запросы и подписки
+// продолжают работать, мутации из allowed (выключение режима) выполняются всегда.
+type Guard struct {
+	mode    *Mode
+	allowed map[string]bool
+}
+
+var (
+	_ graphql.HandlerExtension     = (*Guard)(nil)
+	_ graphql.RootFieldInterceptor = (*Guard)(nil)
+)
+
+func NewGuard(mode *Mode, allowed ...string) *Guard {
+	g := &Guard{mode: mode, allowed: make(map[string]bool, len(allowed))}
+	for _, field := range allowed {
+		g.allowed[field] = true
+	}
+	return g
+}
+
+func (g *Guard) ExtensionName() string {
+	return "MaintenanceGuard"
+}
+
+func (g *Guard) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (g *Guard) InterceptRootField(ctx context.Context, next graphql.RootResolver) graphql.Marshaler {
+	rc := graphql.GetRootFieldContext(ctx)
+	if rc == nil || !isMutation(ctx) || g.allowed[rc.Field.Name] || !g.mode.Enabled() {
+		return next(ctx)
+	}
+
+	graphql.AddError(ctx, &gqlerror.Error{
+		Err:        ErrMaintenance,
+		Message:    ErrMaintenance.Error(),
+		Path:       ast.Path{ast.PathName(rc.Field.Alias)},
+		Extensions: map[string]any{"code": ErrorCode},
+	})
+	return graphql.Null
+}
+
+func isMutation(ctx context.Context) bool {
+	if !graphql.HasOperationContext(ctx) {
+		return false
+	}
+	op := graphql.GetOperationContext(ctx).Operation
+	return op != nil && op.Operation == ast.Mutation
+}
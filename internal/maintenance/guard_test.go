@@ -0,0 +1,69 @@
+package maintenance_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph"
+	"scoring_api_gateway/internal/maintenance"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+)
+
+type graphQLResponse struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func execute(t *testing.T, srv http.Handler, query string) graphQLResponse {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]string{"query": query})
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	var response graphQLResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	return response
+}
+
+func TestGuard(t *testing.T) {
+	mode := maintenance.NewMode(true, time.Second)
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{Maintenance: mode}}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(maintenance.NewGuard(mode, "setMaintenanceMode"))
+
+	response := execute(t, srv, `mutation { createVerification(inn: "7707083893", requestedDataTypes: [BASIC_INFORMATION]) { id } }`)
+	if len(response.Errors) != 1 {
+		t.Fatalf("expected 1 error, but got %+v", response.Errors)
+	}
+	if code := response.Errors[0].Extensions["code"]; code != maintenance.ErrorCode {
+		t.Errorf("expected error code %s, but got %v", maintenance.ErrorCode, code)
+	}
+
+	response = execute(t, srv, `query { maintenanceStatus { enabled } }`)
+	if len(response.Errors) != 0 {
+		t.Fatalf("expected query to succeed in maintenance mode, but got %+v", response.Errors)
+	}
+	status, _ := response.Data["maintenanceStatus"].(map[string]any)
+	if status["enabled"] != true {
+		t.Errorf("expected maintenance status enabled, but got %v", response.Data)
+	}
+
+	response = execute(t, srv, `mutation { setMaintenanceMode(enabled: false) { enabled } }`)
+	if len(response.Errors) != 1 || response.Errors[0].Extensions["code"] == maintenance.ErrorCode {
+		t.Errorf("expected setMaintenanceMode to pass the guard and require the admin role, but got %+v", response.Errors)
+	}
+}
@@ -0,0 +1,161 @@
+// Package maintenance переводит шлюз в режим только для чтения на время работ с базой или NATS.
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+)
+
+// ErrorCode код ошибки GraphQL для мутаций, отклоненных в режиме обслуживания
+const ErrorCode = "MAINTENANCE"
+
+var ErrMaintenance = errors.New("service is in maintenance mode")
+
+// Mode состояние режима обслуживания экземпляра шлюза
+type Mode struct {
+	drainTimeout time.Duration
+
+	mu        sync.Mutex
+	enabled   bool
+	reason    string
+	enabledBy string
+	since     time.Time
+	inFlight  int
+}
+
+func NewMode(enabled bool, drainTimeout time.Duration) *Mode {
+	m := &Mode{drainTimeout: drainTimeout}
+	if enabled {
+		m.enabled = true
+		m.reason = "enabled by configuration"
+		m.since = time.Now()
+	}
+	return m
+}
+
+// Enabled сообщает, включен ли режим обслуживания
+func (m *Mode) Enabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.enabled
+}
+
+// Enable включает режим и ждет завершения начатых публикаций, но не дольше drainTimeout.
+// Если публикации не успели завершиться, их количество видно в статусе.
+func (m *Mode) Enable(ctx context.Context, reason, actor string) *model.MaintenanceStatus {
+	m.mu.Lock()
+	if !m.enabled {
+		m.enabled = true
+		m.since = time.Now()
+	}
+	m.reason = reason
+	m.enabledBy = actor
+	m.mu.Unlock()
+
+	m.drain(ctx)
+	return m.Status()
+}
+
+// Disable возвращает шлюз в обычный режим
+func (m *Mode) Disable() *model.MaintenanceStatus {
+	m.mu.Lock()
+	m.enabled = false
+	m.reason = ""
+	m.enabledBy = ""
+	m.since = time.Time{}
+	m.mu.Unlock()
+
+	return m.Status()
+}
+
+// Status возвращает текущее состояние режима
+func (m *Mode) Status() *model.MaintenanceStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := &model.MaintenanceStatus{
+		Enabled:           m.enabled,
+		InFlightPublishes: int32(m.inFlight),
+	}
+	if m.enabled {
+		since := m.since.UTC().Format(time.RFC3339)
+		status.Since = &since
+		if m.reason != "" {
+			status.Reason = &m.reason
+		}
+		if m.enabledBy != "" {
+			status.EnabledBy = &m.enabledBy
+		}
+	}
+	return status
+}
+
+// track отмечает начало публикации. В режиме обслуживания новые публикации отклоняются.
+func (m *Mode) track() (func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.enabled {
+		return nil, ErrMaintenance
+	}
+	m.inFlight++
+
+	return func() {
+		m.mu.Lock()
+		m.inFlight--
+		m.mu.Unlock()
+	}, nil
+}
+
+func (m *Mode) drain(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, m.drainTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		m.mu.Lock()
+		inFlight := m.inFlight
+		m.mu.Unlock()
+		if inFlight == 0 {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// TrackPublishes учитывает публикации клиента, чтобы режим обслуживания мог дождаться их
+// завершения, и отклоняет новые публикации, пока режим включен
+func TrackPublishes(client messaging.NATSClient, mode *Mode) messaging.NATSClient {
+	return &trackedNATSClient{NATSClient: client, mode: mode}
+}
+
+type trackedNATSClient struct {
+	messaging.NATSClient
+	mode *Mode
+}
+
+func (c *trackedNATSClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
+	return c.PublishVerificationRequestWithPriority(ctx, verification, messaging.PriorityNormal)
+}
+
+func (c *trackedNATSClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	done, err := c.mode.track()
+	if err != nil {
+		return err
+	}
+	defer done()
+	return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+}
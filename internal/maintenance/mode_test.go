@@ -0,0 +1,82 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+)
+
+type blockingClient struct {
+	messaging.NATSClient
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	close(c.started)
+	<-c.release
+	return nil
+}
+
+func TestTrackPublishesRejectedInMaintenance(t *testing.T) {
+	mode := NewMode(true, time.Second)
+	client := TrackPublishes(&blockingClient{}, mode)
+
+	err := client.PublishVerificationRequest(context.Background(), &domain.Verification{ID: "v1"})
+	if !errors.Is(err, ErrMaintenance) {
+		t.Errorf("expected ErrMaintenance, but got %v", err)
+	}
+}
+
+func TestEnableDrainsInFlightPublishes(t *testing.T) {
+	mode := NewMode(false, time.Second)
+	inner := &blockingClient{started: make(chan struct{}), release: make(chan struct{})}
+	client := TrackPublishes(inner, mode)
+
+	published := make(chan error, 1)
+	go func() {
+		published <- client.PublishVerificationRequest(context.Background(), &domain.Verification{ID: "v1"})
+	}()
+	<-inner.started
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(inner.release)
+	}()
+
+	status := mode.Enable(context.Background(), "database migration", "ops@example.com")
+
+	if err := <-published; err != nil {
+		t.Errorf("expected in-flight publish to complete, but got %v", err)
+	}
+	if !status.Enabled || status.InFlightPublishes != 0 {
+		t.Errorf("expected drained maintenance mode, but got %+v", status)
+	}
+	if status.EnabledBy == nil || *status.EnabledBy != "ops@example.com" {
+		t.Errorf("expected enabledBy ops@example.com, but got %v", status.EnabledBy)
+	}
+}
+
+func TestEnableReportsUndrainedPublishes(t *testing.T) {
+	mode := NewMode(false, 20*time.Millisecond)
+	inner := &blockingClient{started: make(chan struct{}), release: make(chan struct{})}
+	client := TrackPublishes(inner, mode)
+	defer close(inner.release)
+
+	go client.PublishVerificationRequest(context.Background(), &domain.Verification{ID: "v1"})
+	<-inner.started
+
+	status := mode.Enable(context.Background(), "", "ops@example.com")
+	if status.InFlightPublishes != 1 {
+		t.Errorf("expected 1 in-flight publish after drain timeout, but got %d", status.InFlightPublishes)
+	}
+
+	status = mode.Disable()
+	if status.Enabled || status.Since != nil {
+		t.Errorf("expected disabled maintenance mode, but got %+v", status)
+	}
+}
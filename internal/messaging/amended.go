@@ -0,0 +1,57 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// SubjectVerificationAmended данные, доставленные после завершения проверки
+const SubjectVerificationAmended = "verification.amended"
+
+// EventTypeVerificationAmended тип события поправки в конверте CloudEvents
+const EventTypeVerificationAmended = "ru.scoring.verification.amended"
+
+// VerificationAmendedMessage поправка завершенной проверки: тип данных и хэши новых и замененных данных
+type VerificationAmendedMessage struct {
+	VerificationID   string `json:"verification_id"`
+	AmendmentID      string `json:"amendment_id"`
+	DataType         string `json:"data_type"`
+	DataHash         string `json:"data_hash,omitempty"`
+	PreviousDataHash string `json:"previous_data_hash,omitempty"`
+	SchemaVersion    int    `json:"schema_version"`
+	ReceivedAt       string `json:"received_at"`
+}
+
+// PublishVerificationAmended публикует поправку. Повторная публикация той же поправки
+// получает тот же идентификатор сообщения.
+func (c *natsClient) PublishVerificationAmended(ctx context.Context, amended *VerificationAmendedMessage) error {
+	data, err := json.Marshal(amended)
+	if err != nil {
+		return fmt.Errorf("failed to marshal verification amended message: %w", err)
+	}
+
+	msg := nats.NewMsg(SubjectVerificationAmended)
+	msg.Data = data
+	msg.Header.Set(HeaderMsgID, amended.VerificationID+":amendment:"+amended.AmendmentID)
+	msg.Header.Set(HeaderSchemaVersion, strconv.Itoa(MessageSchemaVersion))
+	trace, _ := TraceContextFromContext(ctx)
+	msg.Header.Set(HeaderTraceParent, childTraceParent(trace.TraceParent))
+	if err := c.envelope.wrap(msg, EventTypeVerificationAmended, amended.VerificationID); err != nil {
+		return err
+	}
+
+	started := time.Now()
+	if err := c.conn.PublishMsg(msg); err != nil {
+		c.logger.Error("failed to publish verification amended", zap.Error(err), zap.String("verification_id", amended.VerificationID))
+		return fmt.Errorf("failed to publish verification amended: %w", err)
+	}
+	logMessage(c.logger, zap.InfoLevel, logMessagePublished, msg, time.Since(started),
+		zap.String("verification_id", amended.VerificationID), zap.String("amendment_id", amended.AmendmentID))
+	return nil
+}
@@ -0,0 +1,115 @@
+package messaging
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/internal/config"
+
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	// HeaderContentType тип тела сообщения; у конвертов CloudEvents - ContentTypeCloudEvents
+	HeaderContentType = "Content-Type"
+	// ContentTypeCloudEvents тип тела сообщения в конверте CloudEvents в структурированном режиме
+	ContentTypeCloudEvents = "application/cloudevents+json"
+
+	cloudEventsSpecVersion = "1.0"
+	contentTypeJSON        = "application/json"
+)
+
+// Типы событий, которые публикует шлюз
+const (
+	EventTypeVerificationRequested = "ru.scoring.verification.requested"
+	EventTypeRescoreRequested      = "ru.scoring.verification.rescore_requested"
+)
+
+// CloudEvent конверт CloudEvents 1.0 в структурированном режиме JSON
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            string          `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	DataBase64      string          `json:"data_base64,omitempty"`
+}
+
+// Envelope оборачивает публикуемые сообщения в конверт CloudEvents.
+// nil - сообщения публикуются без конверта, как до его появления.
+type Envelope struct {
+	source string
+	now    func() time.Time
+}
+
+// NewEnvelope возвращает конверт для NATS_ENVELOPE=cloudevents и nil для остальных форматов
+func NewEnvelope(cfg config.NATSConfig) *Envelope {
+	if cfg.Envelope != config.NATSEnvelopeCloudEvents {
+		return nil
+	}
+	return &Envelope{source: cfg.EventSource, now: time.Now}
+}
+
+// wrap заменяет тело сообщения конвертом. Идентификатор события - идентификатор сообщения,
+// поэтому повторная публикация того же запроса остается тем же событием.
+func (e *Envelope) wrap(msg *nats.Msg, eventType, subject string) error {
+	if e == nil {
+		return nil
+	}
+
+	id := msg.Header.Get(HeaderMsgID)
+	if id == "" {
+		id = randomHex(16)
+	}
+	data, err := json.Marshal(CloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              id,
+		Source:          e.source,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            e.now().UTC().Format(time.RFC3339Nano),
+		DataContentType: contentTypeJSON,
+		Data:            msg.Data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cloudevents envelope: %w", err)
+	}
+
+	msg.Data = data
+	msg.Header.Set(HeaderContentType, ContentTypeCloudEvents)
+	return nil
+}
+
+// unwrapEvent возвращает данные события из конверта CloudEvents независимо от NATS_ENVELOPE.
+// Сообщения без конверта (воркеры, не перешедшие на CloudEvents) возвращаются как есть с nil вместо конверта.
+func unwrapEvent(data []byte) ([]byte, *CloudEvent, error) {
+	var event CloudEvent
+	if err := json.Unmarshal(data, &event); err != nil || event.SpecVersion == "" {
+		return data, nil, nil
+	}
+	if event.SpecVersion != cloudEventsSpecVersion {
+		return nil, nil, fmt.Errorf("unsupported cloudevents spec version %q", event.SpecVersion)
+	}
+	if event.DataContentType != "" {
+		mediaType, _, err := mime.ParseMediaType(event.DataContentType)
+		if err != nil || (mediaType != contentTypeJSON && !strings.HasSuffix(mediaType, "+json")) {
+			return nil, nil, fmt.Errorf("unsupported cloudevents data content type %q", event.DataContentType)
+		}
+	}
+
+	if event.DataBase64 != "" {
+		decoded, err := base64.StdEncoding.DecodeString(event.DataBase64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid cloudevents data_base64: %w", err)
+		}
+		return decoded, &event, nil
+	}
+	return event.Data, &event, nil
+}
@@ -0,0 +1,129 @@
+package messaging
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/config"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestNewEnvelope(t *testing.T) {
+	if envelope := NewEnvelope(config.NATSConfig{Envelope: config.NATSEnvelopeNone}); envelope != nil {
+		t.Error("expected no envelope for legacy format")
+	}
+	if envelope := NewEnvelope(config.NATSConfig{Envelope: config.NATSEnvelopeCloudEvents, EventSource: "/gw"}); envelope == nil || envelope.source != "/gw" {
+		t.Errorf("unexpected envelope %+v", envelope)
+	}
+}
+
+func TestEnvelopeWrap(t *testing.T) {
+	payload := []byte(`{"verification_id":"v-1"}`)
+
+	msg := nats.NewMsg(SubjectVerificationCreate)
+	msg.Data = payload
+	var legacy *Envelope
+	if err := legacy.wrap(msg, EventTypeVerificationRequested, "v-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(msg.Data) != string(payload) || msg.Header.Get(HeaderContentType) != "" {
+		t.Errorf("expected legacy message to be unchanged, but got %s", msg.Data)
+	}
+
+	envelope := &Envelope{source: "/scoring", now: func() time.Time { return time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC) }}
+	msg.Header.Set(HeaderMsgID, "v-1:abc")
+	if err := envelope.wrap(msg, EventTypeVerificationRequested, "v-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Header.Get(HeaderContentType) != ContentTypeCloudEvents {
+		t.Errorf("unexpected content type %q", msg.Header.Get(HeaderContentType))
+	}
+
+	var event CloudEvent
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.SpecVersion != "1.0" || event.ID != "v-1:abc" || event.Source != "/scoring" || event.Type != EventTypeVerificationRequested ||
+		event.Subject != "v-1" || event.Time != "2024-01-01T10:00:00Z" || event.DataContentType != "application/json" {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	data, unwrapped, err := unwrapEvent(msg.Data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != string(payload) || unwrapped == nil || unwrapped.ID != "v-1:abc" {
+		t.Errorf("expected the original payload, but got %s", data)
+	}
+}
+
+func TestUnwrapEvent(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		expectedData  string
+		expectedEvent bool
+		expectedError string
+	}{
+		{name: "legacy", data: `{"verification_id":"v-1","status":"COMPLETED"}`, expectedData: `{"verification_id":"v-1","status":"COMPLETED"}`},
+		{name: "not_json", data: `not json`, expectedData: `not json`},
+		{
+			name:          "structured",
+			data:          `{"specversion":"1.0","id":"e-1","source":"/worker","type":"ru.scoring.verification.completed","data":{"verification_id":"v-1"}}`,
+			expectedData:  `{"verification_id":"v-1"}`,
+			expectedEvent: true,
+		},
+		{
+			name:          "base64",
+			data:          `{"specversion":"1.0","id":"e-1","source":"/worker","type":"t","data_base64":"eyJ2ZXJpZmljYXRpb25faWQiOiJ2LTEifQ=="}`,
+			expectedData:  `{"verification_id":"v-1"}`,
+			expectedEvent: true,
+		},
+		{name: "unsupported_version", data: `{"specversion":"0.3","id":"e-1","data":{}}`, expectedError: "unsupported cloudevents spec version"},
+		{name: "not_json_data", data: `{"specversion":"1.0","id":"e-1","datacontenttype":"text/plain","data":"hi"}`, expectedError: "unsupported cloudevents data content type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, event, err := unwrapEvent([]byte(tt.data))
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(data) != tt.expectedData || (event != nil) != tt.expectedEvent {
+				t.Errorf("unexpected data %s, event %+v", data, event)
+			}
+		})
+	}
+}
+
+func TestDecodeVerificationCompletedTime(t *testing.T) {
+	var metadata MessageMetadata
+	verification, err := decodeVerificationCompleted(benchCompletedCloudEvent, &metadata)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verification.EventID != "e-1" || !verification.UpdatedAt.Equal(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the event id and time of the envelope, but got %q and %s", verification.EventID, verification.UpdatedAt)
+	}
+
+	metadata = MessageMetadata{MsgID: "m-1"}
+	verification, err = decodeVerificationCompleted([]byte(`{"verification_id":"v-1","status":"ERROR","error":"provider timeout","completed_at":"2024-01-01T13:00:00.5+03:00"}`), &metadata)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verification.EventID != "m-1" || !verification.UpdatedAt.Equal(time.Date(2024, 1, 1, 10, 0, 0, 500000000, time.UTC)) {
+		t.Errorf("expected the message id and completion time, but got %q and %s", verification.EventID, verification.UpdatedAt)
+	}
+	if verification.ErrorMessage == nil || *verification.ErrorMessage != "provider timeout" {
+		t.Errorf("expected the error of the message, but got %v", verification.ErrorMessage)
+	}
+}
@@ -0,0 +1,44 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// EventTypeVerificationCompleted тип события завершения в конверте CloudEvents
+const EventTypeVerificationCompleted = "ru.scoring.verification.completed"
+
+// PublishVerificationCompleted публикует уведомление о завершении от имени воркера, чтобы его
+// обработал обычный конвейер экземпляров-подписчиков. Идентификатор сообщения включает время
+// завершения: повторная публикация того же завершения отбрасывается как повтор.
+func (c *natsClient) PublishVerificationCompleted(ctx context.Context, completed *VerificationCompletedMessage) error {
+	data, err := json.Marshal(completed)
+	if err != nil {
+		return fmt.Errorf("failed to marshal verification completed message: %w", err)
+	}
+
+	msg := nats.NewMsg(SubjectVerificationCompleted)
+	msg.Data = data
+	msg.Header.Set(HeaderMsgID, completed.VerificationID+":completed:"+completed.CompletedAt)
+	msg.Header.Set(HeaderSchemaVersion, strconv.Itoa(MessageSchemaVersion))
+	trace, _ := TraceContextFromContext(ctx)
+	msg.Header.Set(HeaderTraceParent, childTraceParent(trace.TraceParent))
+	if err := c.envelope.wrap(msg, EventTypeVerificationCompleted, completed.VerificationID); err != nil {
+		return err
+	}
+
+	started := time.Now()
+	if err := c.conn.PublishMsg(msg); err != nil {
+		c.logger.Error("failed to publish verification completed", zap.Error(err), zap.String("verification_id", completed.VerificationID))
+		return fmt.Errorf("failed to publish verification completed: %w", err)
+	}
+	logMessage(c.logger, zap.InfoLevel, logMessagePublished, msg, time.Since(started),
+		zap.String("verification_id", completed.VerificationID), zap.String("status", completed.Status))
+	return nil
+}
@@ -0,0 +1,256 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/metrics"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/zap"
+)
+
+// SubjectVerificationCompletedParked префикс subject, в которые экземпляры откладывают уведомления
+// о завершении сверх скорости обработки
+const SubjectVerificationCompletedParked = "verification.completed.parked"
+
+// completionsBucket ключ бюджета обработки уведомлений в Throttle
+const completionsBucket = "completions"
+
+// ParkedCompletion отложенное уведомление о завершении
+type ParkedCompletion struct {
+	Verification *domain.Verification
+	ParkedAt     time.Time
+	// Pending сколько отложенных уведомлений осталось после этого
+	Pending uint64
+	// Ack подтверждает обработку: неподтвержденное уведомление будет получено снова
+	Ack func() error
+	// Msg сообщение, из которого прочитано уведомление, для журнала; nil у хранилищ без NATS
+	Msg *nats.Msg
+}
+
+// CompletionParking хранилище уведомлений о завершении, отложенных ограничителем скорости
+type CompletionParking interface {
+	Park(ctx context.Context, verification *domain.Verification) error
+	// Next блокируется до следующего отложенного уведомления или отмены ctx
+	Next(ctx context.Context) (*ParkedCompletion, error)
+}
+
+type rateLimitedCompletionsClient struct {
+	NATSClient
+	parking  CompletionParking
+	throttle *Throttle
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewRateLimitedCompletionsClient ограничивает скорость обработки уведомлений о завершении, чтобы
+// поток завершений после разбора очереди воркеров не перегружал базу. Уведомления сверх бюджета
+// откладываются в parking и обрабатываются по мере его пополнения раньше новых.
+func NewRateLimitedCompletionsClient(client NATSClient, parking CompletionParking, throttle *Throttle, logger *zap.Logger) NATSClient {
+	if !throttle.Enabled() {
+		return client
+	}
+	return &rateLimitedCompletionsClient{
+		NATSClient: client,
+		parking:    parking,
+		throttle:   throttle,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+func (c *rateLimitedCompletionsClient) SubscribeToVerificationCompleted(ctx context.Context, handler func(*domain.Verification)) error {
+	return c.subscribeToVerificationCompletedAcked(ctx, func(verification *domain.Verification, done func()) {
+		handler(verification)
+		done()
+	})
+}
+
+// subscribeToVerificationCompletedAcked передает handler вместе с уведомлением функцию done, которую
+// нужно вызвать после его применения: только тогда отложенное уведомление подтверждается в parking
+func (c *rateLimitedCompletionsClient) subscribeToVerificationCompletedAcked(ctx context.Context, handler func(*domain.Verification, func())) error {
+	err := c.NATSClient.SubscribeToVerificationCompleted(ctx, func(verification *domain.Verification) {
+		if c.throttle.Allow(completionsBucket, c.now()) {
+			handler(verification, func() {})
+			metrics.CompletionsApplied.WithLabelValues("live").Inc()
+			return
+		}
+
+		if err := c.parking.Park(ctx, verification); err != nil {
+			// Потерять завершение хуже, чем нагрузить базу: уведомление обрабатывается сразу
+			c.logger.Error("failed to park verification completed message, applying it now",
+				zap.Error(err), zap.String("verification_id", verification.ID))
+			handler(verification, func() {})
+			metrics.CompletionsApplied.WithLabelValues("live").Inc()
+			return
+		}
+		metrics.CompletionsParked.Inc()
+	})
+	if err != nil {
+		return err
+	}
+
+	go c.drain(ctx, handler)
+	return nil
+}
+
+// drain обрабатывает отложенные уведомления. Reserve уводит бюджет в минус, поэтому, пока
+// отложенные уведомления ждут своей очереди, новые тоже откладываются. Уведомление подтверждается,
+// когда handler сообщит о его применении, а не после передачи handler.
+func (c *rateLimitedCompletionsClient) drain(ctx context.Context, handler func(*domain.Verification, func())) {
+	for {
+		parked, err := c.parking.Next(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			c.logger.Error("failed to read parked verification completed message", zap.Error(err))
+			select {
+			case <-time.After(time.Second):
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if delay := c.throttle.Reserve(completionsBucket, c.now()); delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		started := time.Now()
+		handler(parked.Verification, func() { c.ack(parked, started) })
+		metrics.CompletionsApplied.WithLabelValues("drained").Inc()
+		metrics.CompletionsParkedPending.Set(float64(parked.Pending))
+		metrics.CompletionsDrainLagSeconds.Set(c.now().Sub(parked.ParkedAt).Seconds())
+	}
+}
+
+// ack подтверждает примененное отложенное уведомление
+func (c *rateLimitedCompletionsClient) ack(parked *ParkedCompletion, started time.Time) {
+	if parked.Msg != nil {
+		logMessage(c.logger, zap.InfoLevel, logMessageConsumed, parked.Msg, time.Since(started),
+			zap.String("verification_id", parked.Verification.ID), zap.String("status", string(parked.Verification.Status)))
+	}
+	if err := parked.Ack(); err != nil {
+		c.logger.Warn("failed to ack parked verification completed message",
+			zap.Error(err), zap.String("verification_id", parked.Verification.ID))
+	}
+}
+
+var invalidToken = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+type jetStreamParking struct {
+	js       jetstream.JetStream
+	consumer jetstream.Consumer
+	subject  string
+	logger   *zap.Logger
+}
+
+// NewJetStreamParking откладывает уведомления в поток JetStream stream. Экземпляры одной группы
+// подписчиков name делят отложенные уведомления, без группы у каждого экземпляра свои.
+func NewJetStreamParking(ctx context.Context, client NATSClient, stream, name string) (CompletionParking, error) {
+	base, ok := client.(*natsClient)
+	if !ok {
+		return nil, fmt.Errorf("completion parking requires a direct NATS connection")
+	}
+	js, err := jetstream.New(base.conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      stream,
+		Subjects:  []string{SubjectVerificationCompletedParked + ".>"},
+		Retention: jetstream.WorkQueuePolicy,
+		Storage:   jetstream.FileStorage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parking stream %s: %w", stream, err)
+	}
+
+	name = invalidToken.ReplaceAllString(name, "_")
+	subject := SubjectVerificationCompletedParked + "." + name
+	consumer, err := js.CreateOrUpdateConsumer(ctx, stream, jetstream.ConsumerConfig{
+		Durable:       name,
+		FilterSubject: subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		MaxAckPending: 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parking consumer %s: %w", name, err)
+	}
+
+	return &jetStreamParking{js: js, consumer: consumer, subject: subject, logger: base.logger}, nil
+}
+
+func (p *jetStreamParking) Park(ctx context.Context, verification *domain.Verification) error {
+	completed := VerificationCompletedMessage{VerificationID: verification.ID, Status: string(verification.Status)}
+	if verification.RiskLevel != nil {
+		completed.RiskLevel = string(*verification.RiskLevel)
+	}
+	if verification.RulesetID != nil {
+		completed.RulesetID = *verification.RulesetID
+	}
+	if !verification.UpdatedAt.IsZero() {
+		completed.CompletedAt = verification.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	data, err := json.Marshal(completed)
+	if err != nil {
+		return fmt.Errorf("failed to marshal parked completion: %w", err)
+	}
+
+	msg := nats.NewMsg(p.subject)
+	msg.Data = data
+	msg.Header.Set(HeaderMsgID, verification.ID+":"+string(verification.Status))
+	started := time.Now()
+	if _, err := p.js.PublishMsg(ctx, msg); err != nil {
+		return fmt.Errorf("failed to park completion: %w", err)
+	}
+	logMessage(p.logger, zap.InfoLevel, logMessagePublished, msg, time.Since(started), zap.String("verification_id", verification.ID))
+	return nil
+}
+
+func (p *jetStreamParking) Next(ctx context.Context) (*ParkedCompletion, error) {
+	for {
+		msg, err := p.consumer.Next(jetstream.FetchMaxWait(5 * time.Second))
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if errors.Is(err, nats.ErrTimeout) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		meta, err := msg.Metadata()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parked completion metadata: %w", err)
+		}
+		var metadata MessageMetadata
+		verification, err := decodeVerificationCompleted(msg.Data(), &metadata)
+		if err != nil {
+			// Нечитаемое сообщение повторная доставка не исправит
+			_ = msg.Term()
+			return nil, err
+		}
+		return &ParkedCompletion{
+			Verification: verification,
+			ParkedAt:     meta.Timestamp,
+			Pending:      meta.NumPending,
+			Ack:          msg.Ack,
+			Msg:          &nats.Msg{Subject: msg.Subject(), Header: msg.Headers(), Data: msg.Data()},
+		}, nil
+	}
+}
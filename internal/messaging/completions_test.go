@@ -0,0 +1,125 @@
+package messaging
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// subscribedClient передает уведомления, отправленные тестом, обработчику подписки
+type subscribedClient struct {
+	NATSClient
+	handler func(*domain.Verification)
+}
+
+func (c *subscribedClient) SubscribeToVerificationCompleted(ctx context.Context, handler func(*domain.Verification)) error {
+	c.handler = handler
+	return nil
+}
+
+type memoryParking struct {
+	parked chan *ParkedCompletion
+	acked  chan string
+}
+
+func (p *memoryParking) Park(ctx context.Context, verification *domain.Verification) error {
+	p.parked <- &ParkedCompletion{
+		Verification: verification,
+		ParkedAt:     time.Now(),
+		Ack: func() error {
+			p.acked <- verification.ID
+			return nil
+		},
+	}
+	return nil
+}
+
+func (p *memoryParking) Next(ctx context.Context) (*ParkedCompletion, error) {
+	select {
+	case parked := <-p.parked:
+		return parked, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestThrottleAllow(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	throttle := NewThrottle(2, 2)
+
+	if !throttle.Allow("completions", now) || !throttle.Allow("completions", now) {
+		t.Fatal("expected burst to be allowed")
+	}
+	if throttle.Allow("completions", now) {
+		t.Error("expected message over burst to be refused")
+	}
+	if !throttle.Allow("completions", now.Add(500*time.Millisecond)) {
+		t.Error("expected budget to refill at the rate")
+	}
+
+	// Зарезервированные сообщения идут раньше новых
+	throttle.Reserve("completions", now.Add(time.Second))
+	throttle.Reserve("completions", now.Add(time.Second))
+	if throttle.Allow("completions", now.Add(1500*time.Millisecond)) {
+		t.Error("expected new message to wait while reservations are in debt")
+	}
+}
+
+func TestRateLimitedCompletionsClient(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	parking := &memoryParking{parked: make(chan *ParkedCompletion, 10), acked: make(chan string, 10)}
+	subscribed := &subscribedClient{}
+	client := NewRateLimitedCompletionsClient(subscribed, parking, NewThrottle(5, 2), zaptest.NewLogger(t))
+
+	var mu sync.Mutex
+	var applied []string
+	err := client.SubscribeToVerificationCompleted(ctx, func(verification *domain.Verification) {
+		mu.Lock()
+		defer mu.Unlock()
+		applied = append(applied, verification.ID)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, id := range []string{"v-1", "v-2", "v-3", "v-4"} {
+		subscribed.handler(&domain.Verification{ID: id, Status: domain.StatusCompleted})
+	}
+
+	mu.Lock()
+	if len(applied) != 2 || applied[0] != "v-1" || applied[1] != "v-2" {
+		t.Errorf("expected burst to be applied at once, but got %v", applied)
+	}
+	mu.Unlock()
+
+	for _, want := range []string{"v-3", "v-4"} {
+		select {
+		case id := <-parking.acked:
+			if id != want {
+				t.Errorf("expected %s to be drained, but got %s", want, id)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected parked %s to be drained", want)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(applied) != 4 {
+		t.Errorf("expected all completions to be applied, but got %v", applied)
+	}
+}
+
+func TestNewRateLimitedCompletionsClientDisabled(t *testing.T) {
+	client := &subscribedClient{}
+	if NewRateLimitedCompletionsClient(client, nil, NewThrottle(0, 1), zaptest.NewLogger(t)) != NATSClient(client) {
+		t.Error("expected client without drain rate to be returned unchanged")
+	}
+}
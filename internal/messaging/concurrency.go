@@ -0,0 +1,116 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+// TenantLoad проверки арендатора у воркеров и удержанные лимитом. Limit 0 - без ограничения.
+type TenantLoad struct {
+	Tenant   string
+	InFlight int
+	Held     int
+	Limit    int
+}
+
+// Admits сообщает, можно ли сразу отправить воркерам запрос с приоритетом priority.
+// Пока у арендатора есть удержанные запросы, новые встают за ними, кроме приоритетных:
+// они занимают освободившееся место первыми.
+func (l *TenantLoad) Admits(priority Priority) bool {
+	if l.Limit <= 0 {
+		return true
+	}
+	if l.InFlight >= l.Limit {
+		return false
+	}
+	return l.Held == 0 || priority == PriorityHigh
+}
+
+// ConcurrencyStore хранилище удержанных запросов
+type ConcurrencyStore interface {
+	Outbox
+	// TenantLoad возвращает нагрузку арендатора; defaultLimit применяется, если у организации нет своего лимита
+	TenantLoad(ctx context.Context, tenant string, defaultLimit int) (*TenantLoad, error)
+}
+
+type concurrencyLimitedClient struct {
+	NATSClient
+	store        ConcurrencyStore
+	defaultLimit int
+	queued       bool
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+// NewConcurrencyLimitedClient ограничивает число проверок арендатора, одновременно находящихся
+// у воркеров. Запрос сверх лимита сохраняется в outbox удержанным; задача concurrency_release
+// отпускает удержанные запросы по мере завершения проверок арендатора. defaultLimit <= 0
+// отключает ограничение для организаций без собственного лимита.
+func NewConcurrencyLimitedClient(client NATSClient, store ConcurrencyStore, defaultLimit int, queuedAdmission bool, logger *zap.Logger) NATSClient {
+	return &concurrencyLimitedClient{
+		NATSClient:   client,
+		store:        store,
+		defaultLimit: defaultLimit,
+		queued:       queuedAdmission,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+func (c *concurrencyLimitedClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
+	return c.PublishVerificationRequestWithPriority(ctx, verification, PriorityNormal)
+}
+
+func (c *concurrencyLimitedClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority Priority) error {
+	tenant := TenantOf(verification.AuthorEmail)
+
+	load, err := c.store.TenantLoad(ctx, tenant, c.defaultLimit)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant load: %w", err)
+	}
+	if load.Admits(priority) {
+		return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+
+	payload, err := json.Marshal(newCreateVerificationMessage(verification, priority))
+	if err != nil {
+		return fmt.Errorf("failed to marshal verification for outbox: %w", err)
+	}
+
+	msg := &OutboxMessage{
+		ID:          verification.ID,
+		Tenant:      tenant,
+		Priority:    priority,
+		Payload:     payload,
+		AvailableAt: c.now(),
+		Held:        true,
+	}
+	position, err := c.store.Enqueue(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("failed to hold verification request: %w", err)
+	}
+
+	// Время отправки удержанного запроса зависит от воркеров, поэтому expectedStartAt не заполняется
+	if c.queued {
+		verification.Status = domain.StatusPending
+		if position > 0 {
+			verification.QueuePosition = &position
+		}
+	}
+
+	metrics.TenantPublishesHeld.WithLabelValues(tenant).Inc()
+	c.logger.Info("verification request held by tenant concurrency limit",
+		zap.String("verification_id", verification.ID),
+		zap.String("tenant", tenant),
+		zap.Int("in_flight", load.InFlight),
+		zap.Int("limit", load.Limit),
+		zap.Int("queue_position", position))
+	return nil
+}
@@ -0,0 +1,86 @@
+package messaging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTenantLoadAdmits(t *testing.T) {
+	tests := []struct {
+		name     string
+		load     TenantLoad
+		priority Priority
+		expected bool
+	}{
+		{name: "unlimited", load: TenantLoad{InFlight: 500, Held: 3}, priority: PriorityBatch, expected: true},
+		{name: "free_slot", load: TenantLoad{InFlight: 49, Limit: 50}, priority: PriorityNormal, expected: true},
+		{name: "at_limit", load: TenantLoad{InFlight: 50, Limit: 50}, priority: PriorityNormal, expected: false},
+		{name: "at_limit_high", load: TenantLoad{InFlight: 50, Limit: 50}, priority: PriorityHigh, expected: false},
+		{name: "behind_held", load: TenantLoad{InFlight: 10, Held: 2, Limit: 50}, priority: PriorityNormal, expected: false},
+		{name: "high_ahead_of_held", load: TenantLoad{InFlight: 10, Held: 2, Limit: 50}, priority: PriorityHigh, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.load.Admits(tt.priority); got != tt.expected {
+				t.Errorf("Admits(%s) = %v, expected %v", tt.priority, got, tt.expected)
+			}
+		})
+	}
+}
+
+type memoryConcurrencyStore struct {
+	memoryOutbox
+	loads        map[string]*TenantLoad
+	defaultLimit int
+}
+
+func (s *memoryConcurrencyStore) TenantLoad(ctx context.Context, tenant string, defaultLimit int) (*TenantLoad, error) {
+	s.defaultLimit = defaultLimit
+	if load, ok := s.loads[tenant]; ok {
+		return load, nil
+	}
+	return &TenantLoad{Tenant: tenant, Limit: defaultLimit}, nil
+}
+
+func TestConcurrencyLimitedClient(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	inner := &recordingClient{}
+	store := &memoryConcurrencyStore{loads: map[string]*TenantLoad{
+		"bulk.ru": {Tenant: "bulk.ru", InFlight: 50, Limit: 50},
+	}}
+
+	client := NewConcurrencyLimitedClient(inner, store, 50, true, zaptest.NewLogger(t)).(*concurrencyLimitedClient)
+	client.now = func() time.Time { return now }
+
+	bulk := &domain.Verification{ID: "v-bulk", INN: "7707083893", Status: domain.StatusInProcess, AuthorEmail: "import@bulk.ru"}
+	single := &domain.Verification{ID: "v-single", INN: "7736050003", Status: domain.StatusInProcess, AuthorEmail: "analyst@bank.ru"}
+	if err := client.PublishVerificationRequestWithPriority(context.Background(), bulk, PriorityBatch); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.PublishVerificationRequest(context.Background(), single); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(inner.published) != 1 || inner.published[0] != "v-single" {
+		t.Errorf("expected only the other tenant's verification to be published, but got %v", inner.published)
+	}
+	if store.defaultLimit != 50 {
+		t.Errorf("expected default limit 50, but got %d", store.defaultLimit)
+	}
+	if len(store.messages) != 1 {
+		t.Fatalf("expected 1 held message, but got %d", len(store.messages))
+	}
+	msg := store.messages[0]
+	if msg.ID != "v-bulk" || !msg.Held || msg.Tenant != "bulk.ru" || msg.Priority != PriorityBatch || !msg.AvailableAt.Equal(now) {
+		t.Errorf("unexpected held message: %+v", msg)
+	}
+	if bulk.Status != domain.StatusPending || bulk.QueuePosition == nil || *bulk.QueuePosition != 1 || bulk.ExpectedStartAt != nil {
+		t.Errorf("expected held verification to be PENDING without expected start, but got %s, %v, %v", bulk.Status, bulk.QueuePosition, bulk.ExpectedStartAt)
+	}
+}
@@ -0,0 +1,83 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+
+	"scoring_api_gateway/internal/domain"
+)
+
+// routedRequest запрос данных одного объявленного типа и subject его воркеров
+type routedRequest struct {
+	subject      string
+	verification *domain.Verification
+}
+
+type dataTypeRoutedClient struct {
+	NATSClient
+	direct *natsClient
+	routes map[domain.DataType]string
+}
+
+// NewDataTypeRoutedClient публикует запросы типов данных, объявленных в конфигурации, в subject
+// из routes: каждый тип отдельным сообщением через прямое соединение direct. Встроенные типы
+// проверки публикуются через client, как и без маршрутов.
+func NewDataTypeRoutedClient(client, direct NATSClient, routes map[domain.DataType]string) (NATSClient, error) {
+	base, ok := direct.(*natsClient)
+	if !ok {
+		return nil, fmt.Errorf("data type routing requires a direct NATS connection")
+	}
+
+	return &dataTypeRoutedClient{
+		NATSClient: client,
+		direct:     base,
+		routes:     routes,
+	}, nil
+}
+
+func (c *dataTypeRoutedClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
+	return c.PublishVerificationRequestWithPriority(ctx, verification, PriorityNormal)
+}
+
+func (c *dataTypeRoutedClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority Priority) error {
+	shared, routed := splitByRoute(verification, c.routes)
+	if len(routed) == 0 {
+		return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+
+	if shared != nil {
+		if err := c.NATSClient.PublishVerificationRequestWithPriority(ctx, shared, priority); err != nil {
+			return err
+		}
+	}
+	for _, request := range routed {
+		if err := publishVerificationRequest(ctx, c.direct.conn, c.direct.envelope, request.subject, request.verification, priority, c.direct.logger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitByRoute делит запрошенные типы проверки на типы без маршрута, которые публикуются в общие
+// subject (nil, если таких нет), и запросы типов с маршрутом - по одному на тип
+func splitByRoute(verification *domain.Verification, routes map[domain.DataType]string) (*domain.Verification, []routedRequest) {
+	var sharedTypes []domain.DataType
+	var routed []routedRequest
+	for _, dataType := range verification.RequestedDataTypes {
+		subject, ok := routes[dataType]
+		if !ok {
+			sharedTypes = append(sharedTypes, dataType)
+			continue
+		}
+		request := *verification
+		request.RequestedDataTypes = []domain.DataType{dataType}
+		routed = append(routed, routedRequest{subject: subject, verification: &request})
+	}
+	if len(sharedTypes) == 0 {
+		return nil, routed
+	}
+
+	shared := *verification
+	shared.RequestedDataTypes = sharedTypes
+	return &shared, routed
+}
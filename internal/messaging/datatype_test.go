@@ -0,0 +1,48 @@
+package messaging
+
+import (
+	"slices"
+	"testing"
+
+	"scoring_api_gateway/internal/domain"
+)
+
+func TestSplitByRoute(t *testing.T) {
+	routes := map[domain.DataType]string{
+		"CREDIT_RATING": "verification.create.credit_rating",
+		"LICENSES":      "verification.create.licenses",
+	}
+
+	verification := &domain.Verification{
+		ID:                 "v-1",
+		RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation, "CREDIT_RATING", "LICENSES"},
+	}
+	shared, routed := splitByRoute(verification, routes)
+	if shared == nil || !slices.Equal(shared.RequestedDataTypes, []domain.DataType{domain.DataTypeBasicInformation}) {
+		t.Fatalf("expected built-in types in the shared request, but got %+v", shared)
+	}
+	if len(routed) != 2 || routed[0].subject != "verification.create.credit_rating" || routed[1].subject != "verification.create.licenses" {
+		t.Fatalf("unexpected routed requests %+v", routed)
+	}
+	if !slices.Equal(routed[1].verification.RequestedDataTypes, []domain.DataType{"LICENSES"}) || routed[1].verification.ID != "v-1" {
+		t.Errorf("expected a request of a single data type, but got %+v", routed[1].verification)
+	}
+	if len(verification.RequestedDataTypes) != 3 {
+		t.Error("expected the verification to stay unchanged")
+	}
+	if requestMessageID(routed[0].verification) == requestMessageID(routed[1].verification) {
+		t.Error("expected requests of different data types to have different message ids")
+	}
+
+	shared, routed = splitByRoute(&domain.Verification{RequestedDataTypes: []domain.DataType{"CREDIT_RATING"}}, routes)
+	if shared != nil || len(routed) != 1 {
+		t.Errorf("expected only a routed request, but got %+v and %+v", shared, routed)
+	}
+}
+
+func TestNewDataTypeRoutedClientRequiresDirectConnection(t *testing.T) {
+	_, err := NewDataTypeRoutedClient(&throttledClient{}, &throttledClient{}, nil)
+	if err == nil || !containsError(err.Error(), "data type routing requires a direct NATS connection") {
+		t.Errorf("expected direct connection error, but got %v", err)
+	}
+}
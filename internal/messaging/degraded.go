@@ -0,0 +1,91 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+// ErrUpstreamUnavailable запрос на проверку не может быть отправлен: нет подключения к NATS
+var ErrUpstreamUnavailable = errors.New("upstream unavailable: not connected to NATS")
+
+type degradedClient struct {
+	NATSClient
+	mode   string
+	outbox Outbox
+	queued bool
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewDegradedClient обрабатывает запросы на проверку, пока нет подключения к NATS. В режиме
+// queue они сохраняются в outbox и отправляются задачей outbox_relay после подключения,
+// в режиме fail отклоняются с ErrUpstreamUnavailable. При queuedAdmission сохраненная
+// проверка получает статус PENDING и позицию в очереди арендатора.
+func NewDegradedClient(client NATSClient, mode string, outbox Outbox, queuedAdmission bool, logger *zap.Logger) NATSClient {
+	if mode == config.NATSDegradedOff {
+		return client
+	}
+	return &degradedClient{
+		NATSClient: client,
+		mode:       mode,
+		outbox:     outbox,
+		queued:     queuedAdmission,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+func (c *degradedClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
+	return c.PublishVerificationRequestWithPriority(ctx, verification, PriorityNormal)
+}
+
+func (c *degradedClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority Priority) error {
+	if c.NATSClient.Status().Connected {
+		return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+
+	if c.mode == config.NATSDegradedFail {
+		metrics.NATSDegradedPublishes.WithLabelValues("rejected").Inc()
+		return ErrUpstreamUnavailable
+	}
+
+	payload, err := json.Marshal(newCreateVerificationMessage(verification, priority))
+	if err != nil {
+		return fmt.Errorf("failed to marshal verification for outbox: %w", err)
+	}
+
+	tenant := TenantOf(verification.AuthorEmail)
+	position, err := c.outbox.Enqueue(ctx, &OutboxMessage{
+		ID:          verification.ID,
+		Tenant:      tenant,
+		Priority:    priority,
+		Payload:     payload,
+		AvailableAt: c.now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enqueue publish while NATS is unavailable: %w", err)
+	}
+
+	if c.queued {
+		verification.Status = domain.StatusPending
+		if position > 0 {
+			verification.QueuePosition = &position
+		}
+	}
+
+	metrics.NATSDegradedPublishes.WithLabelValues("queued").Inc()
+	c.logger.Warn("verification request queued while NATS is unavailable",
+		zap.String("verification_id", verification.ID),
+		zap.String("tenant", tenant),
+		zap.Int("queue_position", position))
+	return nil
+}
@@ -0,0 +1,107 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/domain"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type statusClient struct {
+	recordingClient
+	connected bool
+}
+
+func (c *statusClient) Status() ConnectionStatus {
+	return ConnectionStatus{Connected: c.connected}
+}
+
+func TestDegradedClient(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name              string
+		mode              string
+		connected         bool
+		queuedAdmission   bool
+		expectedPublished int
+		expectedOutbox    int
+		expectedError     error
+		expectedStatus    domain.Status
+	}{
+		{name: "connected", mode: config.NATSDegradedQueue, connected: true, expectedPublished: 1, expectedStatus: domain.StatusInProcess},
+		{name: "queue", mode: config.NATSDegradedQueue, expectedOutbox: 1, expectedStatus: domain.StatusInProcess},
+		{name: "queue_admission", mode: config.NATSDegradedQueue, queuedAdmission: true, expectedOutbox: 1, expectedStatus: domain.StatusPending},
+		{name: "fail", mode: config.NATSDegradedFail, expectedError: ErrUpstreamUnavailable, expectedStatus: domain.StatusInProcess},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &statusClient{connected: tt.connected}
+			outbox := &memoryOutbox{}
+
+			client := NewDegradedClient(inner, tt.mode, outbox, tt.queuedAdmission, zaptest.NewLogger(t)).(*degradedClient)
+			client.now = func() time.Time { return now }
+
+			verification := &domain.Verification{ID: "v1", INN: "7707083893", Status: domain.StatusInProcess, AuthorEmail: "analyst@bank.ru"}
+			err := client.PublishVerificationRequestWithPriority(context.Background(), verification, PriorityHigh)
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, but got %v", tt.expectedError, err)
+			}
+
+			if len(inner.published) != tt.expectedPublished {
+				t.Errorf("expected %d published, but got %v", tt.expectedPublished, inner.published)
+			}
+			if len(outbox.messages) != tt.expectedOutbox {
+				t.Fatalf("expected %d outbox messages, but got %d", tt.expectedOutbox, len(outbox.messages))
+			}
+			if tt.expectedOutbox > 0 {
+				msg := outbox.messages[0]
+				if msg.ID != "v1" || msg.Tenant != "bank.ru" || msg.Priority != PriorityHigh || !msg.AvailableAt.Equal(now) {
+					t.Errorf("unexpected outbox message: %+v", msg)
+				}
+			}
+			if verification.Status != tt.expectedStatus {
+				t.Errorf("expected status %s, but got %s", tt.expectedStatus, verification.Status)
+			}
+		})
+	}
+}
+
+func TestNewDegradedClientOff(t *testing.T) {
+	inner := &statusClient{}
+	if client := NewDegradedClient(inner, config.NATSDegradedOff, &memoryOutbox{}, false, zaptest.NewLogger(t)); client != inner {
+		t.Error("expected the client to be returned unchanged when degraded mode is off")
+	}
+}
+
+func TestNewNATSClientDegradedStart(t *testing.T) {
+	cfg := config.NATSConfig{
+		URL:           "nats://127.0.0.1:1",
+		MaxReconnects: -1,
+		ReconnectWait: time.Hour,
+	}
+
+	if _, err := NewNATSClient(cfg, zaptest.NewLogger(t)); err == nil {
+		t.Fatal("expected connection error without degraded mode, but got nil")
+	}
+
+	cfg.DegradedMode = config.NATSDegradedQueue
+	client, err := NewNATSClient(cfg, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer client.Close()
+
+	if client.Status().Connected {
+		t.Error("expected client to start disconnected")
+	}
+	if err := client.SubscribeToVerificationCompleted(context.Background(), func(*domain.Verification) {}); err != nil {
+		t.Errorf("expected subscription to be accepted while disconnected, but got %v", err)
+	}
+}
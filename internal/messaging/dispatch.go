@@ -0,0 +1,196 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/metrics"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+const (
+	// SubjectWorkerHeartbeat сообщения воркеров о своей очереди
+	SubjectWorkerHeartbeat = "worker.heartbeat"
+	// SubjectVerificationCreateWorkerPrefix префикс персональных subject воркеров для фоновых запросов
+	SubjectVerificationCreateWorkerPrefix = "verification.create.worker."
+)
+
+// WorkerHeartbeatMessage состояние воркера: сколько запросов ждут обработки и сколько он вмещает
+type WorkerHeartbeatMessage struct {
+	WorkerID   string `json:"worker_id"`
+	QueueDepth int    `json:"queue_depth"`
+	Capacity   int    `json:"capacity"`
+}
+
+// workerSubject возвращает персональный subject воркера
+func workerSubject(workerID string) string {
+	return SubjectVerificationCreateWorkerPrefix + workerID
+}
+
+type workerState struct {
+	queueDepth int
+	capacity   int
+	// assigned запросы, отправленные воркеру после его последнего heartbeat
+	assigned int
+	seenAt   time.Time
+}
+
+// WorkerPool отслеживает очереди воркеров по heartbeat и выбирает воркер для фонового запроса
+type WorkerPool struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	workers map[string]*workerState
+}
+
+// NewWorkerPool создает пул. Воркер, не присылавший heartbeat дольше ttl, не получает запросов.
+func NewWorkerPool(ttl time.Duration) *WorkerPool {
+	return &WorkerPool{
+		ttl:     ttl,
+		now:     time.Now,
+		workers: make(map[string]*workerState),
+	}
+}
+
+// Observe обновляет состояние воркера. Очередь из heartbeat уже учитывает отправленные ранее запросы.
+func (p *WorkerPool) Observe(heartbeat *WorkerHeartbeatMessage) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.workers[heartbeat.WorkerID] = &workerState{
+		queueDepth: heartbeat.QueueDepth,
+		capacity:   heartbeat.Capacity,
+		seenAt:     p.now(),
+	}
+	metrics.WorkerQueueDepth.WithLabelValues(heartbeat.WorkerID).Set(float64(heartbeat.QueueDepth))
+}
+
+// Assign выбирает живой воркер с наибольшим свободным местом и резервирует в нем место.
+// Так запросы распределяются пропорционально свободной емкости воркеров. false - все воркеры заняты
+// или неизвестны, и запрос нужно отправить в общую очередь.
+func (p *WorkerPool) Assign() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ids := make([]string, 0, len(p.workers))
+	for id := range p.workers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	deadline := p.now().Add(-p.ttl)
+	best, bestFree := "", 0
+	for _, id := range ids {
+		state := p.workers[id]
+		if state.seenAt.Before(deadline) {
+			delete(p.workers, id)
+			metrics.WorkerQueueDepth.DeleteLabelValues(id)
+			continue
+		}
+		if free := state.capacity - state.queueDepth - state.assigned; free > bestFree {
+			best, bestFree = id, free
+		}
+	}
+	if best == "" {
+		return "", false
+	}
+
+	p.workers[best].assigned++
+	return best, true
+}
+
+// Backlog возвращает, сколько запросов ждут обработки у живых воркеров и сколько они вмещают
+// одновременно. Для пула без heartbeat и для nil возвращает нули.
+func (p *WorkerPool) Backlog() (queued, capacity int) {
+	if p == nil {
+		return 0, 0
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	deadline := p.now().Add(-p.ttl)
+	for _, state := range p.workers {
+		if state.seenAt.Before(deadline) {
+			continue
+		}
+		queued += state.queueDepth + state.assigned
+		capacity += state.capacity
+	}
+	return queued, capacity
+}
+
+type dispatchClient struct {
+	*natsClient
+	pool *WorkerPool
+}
+
+// NewDispatchClient отправляет фоновые запросы (PriorityBatch) в персональные subject воркеров
+// с учетом их очереди, чтобы один медленный воркер не задерживал всю пачку. Остальные запросы и
+// фоновые запросы при отсутствии свободных воркеров публикуются в общие subject.
+func NewDispatchClient(client NATSClient, pool *WorkerPool) (NATSClient, error) {
+	base, ok := client.(*natsClient)
+	if !ok {
+		return nil, fmt.Errorf("worker dispatch requires a direct NATS connection")
+	}
+
+	c := &dispatchClient{natsClient: base, pool: pool}
+	if err := c.subscribeToHeartbeats(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *dispatchClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
+	return c.PublishVerificationRequestWithPriority(ctx, verification, PriorityNormal)
+}
+
+func (c *dispatchClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority Priority) error {
+	if priority != PriorityBatch {
+		return c.natsClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+
+	workerID, ok := c.pool.Assign()
+	if !ok {
+		metrics.BatchDispatches.WithLabelValues("shared").Inc()
+		return c.natsClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+
+	metrics.BatchDispatches.WithLabelValues("worker").Inc()
+	return publishVerificationRequest(ctx, c.conn, c.envelope, workerSubject(workerID), verification, priority, c.logger)
+}
+
+// subscribeToHeartbeats подписывается на heartbeat воркеров без группы: каждому экземпляру шлюза
+// нужно полное состояние всех воркеров
+func (c *dispatchClient) subscribeToHeartbeats() error {
+	_, err := c.conn.Subscribe(SubjectWorkerHeartbeat, func(msg *nats.Msg) {
+		var heartbeat WorkerHeartbeatMessage
+		data, _, err := unwrapEvent(msg.Data)
+		if err == nil {
+			err = json.Unmarshal(data, &heartbeat)
+		}
+		if err != nil || heartbeat.WorkerID == "" {
+			c.logger.Warn("invalid worker heartbeat", zap.Error(err))
+			return
+		}
+		started := time.Now()
+		c.pool.Observe(&heartbeat)
+		// Heartbeat приходят от каждого воркера несколько раз в минуту и нужны в журнале только при отладке
+		logMessage(c.logger, zap.DebugLevel, logMessageConsumed, msg, time.Since(started), zap.String("worker_id", heartbeat.WorkerID))
+	})
+	if err != nil {
+		c.logger.Error("failed to subscribe to worker heartbeats", zap.Error(err))
+		return fmt.Errorf("failed to subscribe to worker heartbeats: %w", err)
+	}
+
+	c.logger.Info("subscribed to worker heartbeats", zap.String("subject", SubjectWorkerHeartbeat))
+	return nil
+}
@@ -0,0 +1,95 @@
+package messaging
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolAssign(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	newPool := func() *WorkerPool {
+		pool := NewWorkerPool(30 * time.Second)
+		pool.now = func() time.Time { return now }
+		return pool
+	}
+
+	t.Run("proportional_to_free_capacity", func(t *testing.T) {
+		pool := newPool()
+		pool.Observe(&WorkerHeartbeatMessage{WorkerID: "fast", QueueDepth: 0, Capacity: 4})
+		pool.Observe(&WorkerHeartbeatMessage{WorkerID: "slow", QueueDepth: 8, Capacity: 10})
+
+		counts := map[string]int{}
+		for i := 0; i < 6; i++ {
+			id, ok := pool.Assign()
+			if !ok {
+				t.Fatalf("assignment %d: expected a worker", i)
+			}
+			counts[id]++
+		}
+		if counts["fast"] != 4 || counts["slow"] != 2 {
+			t.Errorf("expected 4 fast and 2 slow assignments, but got %v", counts)
+		}
+
+		if id, ok := pool.Assign(); ok {
+			t.Errorf("expected fallback to the shared queue when workers are full, but got %q", id)
+		}
+	})
+
+	t.Run("heartbeat_resets_reservations", func(t *testing.T) {
+		pool := newPool()
+		pool.Observe(&WorkerHeartbeatMessage{WorkerID: "w1", QueueDepth: 0, Capacity: 1})
+		if _, ok := pool.Assign(); !ok {
+			t.Fatal("expected a worker")
+		}
+		if _, ok := pool.Assign(); ok {
+			t.Fatal("expected the worker to be full")
+		}
+
+		pool.Observe(&WorkerHeartbeatMessage{WorkerID: "w1", QueueDepth: 0, Capacity: 1})
+		if id, ok := pool.Assign(); !ok || id != "w1" {
+			t.Errorf("expected w1 after heartbeat, but got %q (%v)", id, ok)
+		}
+	})
+
+	t.Run("stale_worker_skipped", func(t *testing.T) {
+		pool := newPool()
+		pool.Observe(&WorkerHeartbeatMessage{WorkerID: "stale", QueueDepth: 0, Capacity: 100})
+		now = now.Add(time.Minute)
+		pool.Observe(&WorkerHeartbeatMessage{WorkerID: "live", QueueDepth: 9, Capacity: 10})
+
+		if id, ok := pool.Assign(); !ok || id != "live" {
+			t.Errorf("expected live worker, but got %q (%v)", id, ok)
+		}
+		if _, ok := pool.Assign(); ok {
+			t.Error("expected no free workers")
+		}
+	})
+
+	t.Run("no_workers", func(t *testing.T) {
+		if _, ok := newPool().Assign(); ok {
+			t.Error("expected fallback without heartbeats")
+		}
+	})
+}
+
+func TestWorkerPoolBacklog(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	pool := NewWorkerPool(30 * time.Second)
+	pool.now = func() time.Time { return now }
+
+	pool.Observe(&WorkerHeartbeatMessage{WorkerID: "stale", QueueDepth: 50, Capacity: 100})
+	now = now.Add(time.Minute)
+	pool.Observe(&WorkerHeartbeatMessage{WorkerID: "w1", QueueDepth: 3, Capacity: 4})
+	pool.Observe(&WorkerHeartbeatMessage{WorkerID: "w2", QueueDepth: 5, Capacity: 6})
+	pool.Assign()
+
+	if queued, capacity := pool.Backlog(); queued != 9 || capacity != 10 {
+		t.Errorf("expected backlog of live workers 9/10 with the reservation, but got %d/%d", queued, capacity)
+	}
+
+	var missing *WorkerPool
+	if queued, capacity := missing.Backlog(); queued != 0 || capacity != 0 {
+		t.Errorf("expected empty backlog without worker dispatch, but got %d/%d", queued, capacity)
+	}
+}
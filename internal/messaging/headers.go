@@ -0,0 +1,123 @@
+package messaging
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"scoring_api_gateway/internal/domain"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Заголовки сообщений NATS
+const (
+	// HeaderMsgID идентификатор сообщения, по которому JetStream отбрасывает повторные публикации
+	HeaderMsgID = nats.MsgIdHdr
+	// HeaderTraceParent и HeaderTraceState контекст трассировки W3C Trace Context
+	HeaderTraceParent = "traceparent"
+	HeaderTraceState  = "tracestate"
+	// HeaderTenant арендатор, от имени которого отправлен запрос
+	HeaderTenant = "Scoring-Tenant"
+	// HeaderSchemaVersion версия формата тела сообщения
+	HeaderSchemaVersion = "Scoring-Schema-Version"
+)
+
+// MessageSchemaVersion версия формата CreateVerificationMessage и VerificationCompletedMessage,
+// которую понимает шлюз. Повышается при несовместимых изменениях тела сообщений.
+const MessageSchemaVersion = 1
+
+// TraceContext контекст трассировки W3C, пришедший с запросом к шлюзу
+type TraceContext struct {
+	TraceParent string
+	TraceState  string
+}
+
+type traceContextKey struct{}
+
+// WithTraceContext возвращает контекст, публикации из которого продолжают трассировку
+func WithTraceContext(ctx context.Context, trace TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, trace)
+}
+
+// TraceContextFromContext возвращает контекст трассировки запроса
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	trace, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	return trace, ok
+}
+
+var traceParentPattern = regexp.MustCompile(`^00-([0-9a-f]{32})-[0-9a-f]{16}-([0-9a-f]{2})$`)
+
+// childTraceParent возвращает traceparent исходящего сообщения: тот же trace-id, что у входящего
+// запроса, и новый span-id шлюза. Без корректного входящего traceparent начинается новая трассировка.
+func childTraceParent(incoming string) string {
+	traceID, flags := "", "01"
+	if m := traceParentPattern.FindStringSubmatch(incoming); m != nil && m[1] != strings.Repeat("0", 32) {
+		traceID, flags = m[1], m[2]
+	} else {
+		traceID = randomHex(16)
+	}
+	return "00-" + traceID + "-" + randomHex(8) + "-" + flags
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// requestMessageID идентификатор запроса на проверку: проверка и набор типов данных.
+// Повторная отправка того же запроса (например, outbox после сбоя) получает тот же
+// идентификатор, а дозапрос недоставленных типов - другой.
+func requestMessageID(verification *domain.Verification) string {
+	types := make([]string, 0, len(verification.RequestedDataTypes))
+	for _, dataType := range verification.RequestedDataTypes {
+		types = append(types, string(dataType))
+	}
+	sort.Strings(types)
+	sum := sha256.Sum256([]byte(strings.Join(types, ",")))
+	return verification.ID + ":" + hex.EncodeToString(sum[:6])
+}
+
+// newRequestMsg собирает сообщение запроса на проверку с заголовками
+func newRequestMsg(ctx context.Context, subject string, verification *domain.Verification, data []byte) *nats.Msg {
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	msg.Header.Set(HeaderMsgID, requestMessageID(verification))
+	msg.Header.Set(HeaderTenant, TenantOf(verification.AuthorEmail))
+	msg.Header.Set(HeaderSchemaVersion, strconv.Itoa(MessageSchemaVersion))
+
+	trace, _ := TraceContextFromContext(ctx)
+	msg.Header.Set(HeaderTraceParent, childTraceParent(trace.TraceParent))
+	if trace.TraceState != "" {
+		msg.Header.Set(HeaderTraceState, trace.TraceState)
+	}
+	return msg
+}
+
+// MessageMetadata заголовки полученного сообщения. Поля пустые, если отправитель их не передал.
+type MessageMetadata struct {
+	MsgID       string
+	TraceParent string
+	Tenant      string
+	// SchemaVersion 0, если заголовка нет (воркеры, не передающие заголовки)
+	SchemaVersion int
+}
+
+func readMetadata(msg *nats.Msg) MessageMetadata {
+	if msg.Header == nil {
+		return MessageMetadata{}
+	}
+	version, _ := strconv.Atoi(msg.Header.Get(HeaderSchemaVersion))
+	return MessageMetadata{
+		MsgID:         msg.Header.Get(HeaderMsgID),
+		TraceParent:   msg.Header.Get(HeaderTraceParent),
+		Tenant:        msg.Header.Get(HeaderTenant),
+		SchemaVersion: version,
+	}
+}
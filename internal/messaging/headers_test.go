@@ -0,0 +1,114 @@
+package messaging
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"scoring_api_gateway/internal/domain"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestChildTraceParent(t *testing.T) {
+	incoming := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	child := childTraceParent(incoming)
+	parts := strings.Split(child, "-")
+	if len(parts) != 4 || parts[1] != "4bf92f3577b34da6a3ce929d0e0e4736" || parts[3] != "01" {
+		t.Fatalf("expected child of incoming trace, got %q", child)
+	}
+	if parts[2] == "00f067aa0ba902b7" {
+		t.Error("expected a new span id for the published message")
+	}
+
+	for _, invalid := range []string{"", "garbage", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"} {
+		generated := childTraceParent(invalid)
+		if !traceParentPattern.MatchString(generated) {
+			t.Errorf("expected valid traceparent for %q, got %q", invalid, generated)
+		}
+		if strings.Contains(generated, "00000000000000000000000000000000") {
+			t.Errorf("expected a new trace id for %q, got %q", invalid, generated)
+		}
+	}
+}
+
+func TestRequestMessageID(t *testing.T) {
+	verification := &domain.Verification{
+		ID: "550e8400-e29b-41d4-a716-446655440000",
+		RequestedDataTypes: []domain.DataType{
+			domain.DataTypeBasicInformation,
+			domain.DataTypeArbitrageStatistics,
+		},
+	}
+	reordered := &domain.Verification{
+		ID: verification.ID,
+		RequestedDataTypes: []domain.DataType{
+			domain.DataTypeArbitrageStatistics,
+			domain.DataTypeBasicInformation,
+		},
+	}
+	retry := &domain.Verification{
+		ID:                 verification.ID,
+		RequestedDataTypes: []domain.DataType{domain.DataTypeArbitrageStatistics},
+	}
+
+	id := requestMessageID(verification)
+	if !strings.HasPrefix(id, verification.ID+":") {
+		t.Errorf("expected message id to start with verification id, got %q", id)
+	}
+	if requestMessageID(reordered) != id {
+		t.Error("expected message id not to depend on data type order")
+	}
+	if requestMessageID(retry) == id {
+		t.Error("expected retry of missing data to get a different message id")
+	}
+}
+
+func TestRequestMsgHeaders(t *testing.T) {
+	verification := &domain.Verification{
+		ID:                 "550e8400-e29b-41d4-a716-446655440000",
+		AuthorEmail:        "analyst@acme.ru",
+		RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation},
+	}
+	ctx := WithTraceContext(context.Background(), TraceContext{
+		TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		TraceState:  "vendor=value",
+	})
+
+	msg := newRequestMsg(ctx, SubjectVerificationCreate, verification, []byte(`{}`))
+
+	if msg.Subject != SubjectVerificationCreate {
+		t.Errorf("expected subject %s, got %s", SubjectVerificationCreate, msg.Subject)
+	}
+	metadata := readMetadata(msg)
+	if metadata.MsgID != requestMessageID(verification) {
+		t.Errorf("unexpected message id %q", metadata.MsgID)
+	}
+	if metadata.Tenant != "acme.ru" {
+		t.Errorf("expected tenant acme.ru, got %q", metadata.Tenant)
+	}
+	if metadata.SchemaVersion != MessageSchemaVersion {
+		t.Errorf("expected schema version %d, got %d", MessageSchemaVersion, metadata.SchemaVersion)
+	}
+	if !strings.Contains(metadata.TraceParent, "4bf92f3577b34da6a3ce929d0e0e4736") {
+		t.Errorf("expected trace to be continued, got %q", metadata.TraceParent)
+	}
+	if got := msg.Header.Get(HeaderTraceState); got != "vendor=value" {
+		t.Errorf("expected tracestate to be propagated, got %q", got)
+	}
+}
+
+func TestReadMetadataWithoutHeaders(t *testing.T) {
+	metadata := readMetadata(&nats.Msg{Subject: SubjectVerificationCompleted, Data: []byte(`{}`)})
+	if metadata != (MessageMetadata{}) {
+		t.Errorf("expected empty metadata, got %+v", metadata)
+	}
+
+	msg := nats.NewMsg(SubjectVerificationCompleted)
+	msg.Header.Set(HeaderSchemaVersion, strconv.Itoa(MessageSchemaVersion+1))
+	if got := readMetadata(msg).SchemaVersion; got != MessageSchemaVersion+1 {
+		t.Errorf("expected schema version %d, got %d", MessageSchemaVersion+1, got)
+	}
+}
@@ -0,0 +1,90 @@
+package messaging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Записи журнала о сообщениях NATS
+const (
+	logMessagePublished = "nats message published"
+	logMessageConsumed  = "nats message consumed"
+)
+
+// redactedBodyValue значение, которым в журнале заменяются персональные данные тела сообщения
+const redactedBodyValue = "[REDACTED]"
+
+// personalBodyFields поля тела сообщения (и конверта CloudEvents), значения которых не попадают
+// в журнал. data_base64 конверта скрывается целиком: его содержимое нельзя разобрать по полям.
+var personalBodyFields = map[string]bool{
+	"inn":          true,
+	"author_email": true,
+	"email":        true,
+	"data_base64":  true,
+}
+
+// logMessage пишет в журнал служебные данные сообщения: subject, размер тела, идентификатор,
+// арендатора, версию формата и длительность публикации или обработки. Тело сообщения добавляется
+// только при включенном уровне debug и только после замены персональных данных.
+func logMessage(logger *zap.Logger, level zapcore.Level, event string, msg *nats.Msg, duration time.Duration, fields ...zap.Field) {
+	entry := logger.Check(level, event)
+	if entry == nil {
+		return
+	}
+
+	metadata := readMetadata(msg)
+	all := make([]zap.Field, 0, len(fields)+7)
+	all = append(all,
+		zap.String("subject", msg.Subject),
+		zap.Int("size", len(msg.Data)),
+		zap.String("msg_id", metadata.MsgID),
+		zap.String("tenant", metadata.Tenant),
+		zap.Int("schema_version", metadata.SchemaVersion),
+		zap.Duration("duration", duration))
+	all = append(all, fields...)
+	if logger.Core().Enabled(zapcore.DebugLevel) {
+		all = append(all, zap.String("body", redactBody(msg.Data)))
+	}
+	entry.Write(all...)
+}
+
+// redactBody возвращает тело сообщения для журнала с замененными значениями personalBodyFields
+// на любой глубине вложенности. Тело, которое не является JSON, в журнал не попадает.
+func redactBody(data []byte) string {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var body any
+	if err := decoder.Decode(&body); err != nil || decoder.More() {
+		return fmt.Sprintf("[%d bytes, not JSON]", len(data))
+	}
+
+	redacted, err := json.Marshal(redactValue(body))
+	if err != nil {
+		return fmt.Sprintf("[%d bytes, not JSON]", len(data))
+	}
+	return string(redacted)
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if personalBodyFields[key] {
+				v[key] = redactedBodyValue
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
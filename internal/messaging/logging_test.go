@@ -0,0 +1,87 @@
+package messaging
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{name: "request", body: `{"verification_id":"v-1","inn":"500100732259","author_email":"analyst@example.com","requested_types":["BASIC_INFORMATION"]}`,
+			expected: `{"author_email":"[REDACTED]","inn":"[REDACTED]","requested_types":["BASIC_INFORMATION"],"verification_id":"v-1"}`},
+		{name: "cloudevents", body: `{"specversion":"1.0","id":"v-1:abc","data":{"verification_id":"v-1","inn":"7707083893"}}`,
+			expected: `{"data":{"inn":"[REDACTED]","verification_id":"v-1"},"id":"v-1:abc","specversion":"1.0"}`},
+		{name: "cloudevents_base64", body: `{"specversion":"1.0","data_base64":"eyJpbm4iOiI3NzA3MDgzODkzIn0="}`,
+			expected: `{"data_base64":"[REDACTED]","specversion":"1.0"}`},
+		{name: "nested_array", body: `{"founders":[{"email":"founder@example.com","share":0.5}]}`,
+			expected: `{"founders":[{"email":"[REDACTED]","share":0.5}]}`},
+		{name: "not_json", body: `inn=7707083893`, expected: `[14 bytes, not JSON]`},
+		{name: "trailing_data", body: `{"id":"v-1"} {"inn":"7707083893"}`, expected: `[33 bytes, not JSON]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactBody([]byte(tt.body)); got != tt.expected {
+				t.Errorf("expected %s, but got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLogMessage(t *testing.T) {
+	msg := nats.NewMsg(SubjectVerificationCreate)
+	msg.Data = []byte(`{"verification_id":"v-1","inn":"7707083893"}`)
+	msg.Header.Set(HeaderMsgID, "v-1:abc")
+	msg.Header.Set(HeaderTenant, "example.com")
+	msg.Header.Set(HeaderSchemaVersion, "1")
+
+	tests := []struct {
+		name            string
+		loggerLevel     zapcore.Level
+		messageLevel    zapcore.Level
+		expectedEntries int
+		expectedBody    bool
+	}{
+		{name: "info", loggerLevel: zap.InfoLevel, messageLevel: zap.InfoLevel, expectedEntries: 1},
+		{name: "debug_with_body", loggerLevel: zap.DebugLevel, messageLevel: zap.InfoLevel, expectedEntries: 1, expectedBody: true},
+		{name: "debug_message_skipped", loggerLevel: zap.InfoLevel, messageLevel: zap.DebugLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(tt.loggerLevel)
+			logMessage(zap.New(core), tt.messageLevel, logMessagePublished, msg, 15*time.Millisecond, zap.String("verification_id", "v-1"))
+
+			if logs.Len() != tt.expectedEntries {
+				t.Fatalf("expected %d log entries, but got %d", tt.expectedEntries, logs.Len())
+			}
+			if tt.expectedEntries == 0 {
+				return
+			}
+
+			fields := logs.All()[0].ContextMap()
+			if fields["subject"] != SubjectVerificationCreate || fields["size"] != int64(len(msg.Data)) || fields["msg_id"] != "v-1:abc" ||
+				fields["tenant"] != "example.com" || fields["schema_version"] != int64(1) || fields["duration"] != 15*time.Millisecond ||
+				fields["verification_id"] != "v-1" {
+				t.Errorf("unexpected metadata %v", fields)
+			}
+			body, ok := fields["body"].(string)
+			if ok != tt.expectedBody {
+				t.Fatalf("expected body logged: %v, but got %v", tt.expectedBody, fields["body"])
+			}
+			if strings.Contains(body, "7707083893") {
+				t.Errorf("expected inn to be redacted, but got %s", body)
+			}
+		})
+	}
+}
@@ -4,98 +4,320 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
-	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/metrics"
 
 	"github.com/nats-io/nats.go"
 	"go.uber.org/zap"
 )
 
+const (
+	SubjectVerificationCreate             = "verification.create"
+	SubjectVerificationCreateHighPriority = "verification.create.high"
+	SubjectVerificationCompleted          = "verification.completed"
+)
+
+// Priority определяет очередь, в которую отправляется запрос на проверку
+type Priority string
+
+const (
+	PriorityNormal Priority = "normal"
+	PriorityHigh   Priority = "high"
+	// PriorityBatch фоновые запросы задач: публикуются в обычную очередь, а при распределении
+	// по воркерам - в персональные subject с учетом их очереди
+	PriorityBatch Priority = "batch"
+)
+
+// subjectForPriority возвращает subject NATS для запроса с указанным приоритетом
+func subjectForPriority(priority Priority) string {
+	if priority == PriorityHigh {
+		return SubjectVerificationCreateHighPriority
+	}
+	return SubjectVerificationCreate
+}
+
 type NATSClient interface {
-	PublishVerificationRequest(ctx context.Context, verification *model.Verification) error
-	SubscribeToVerificationCompleted(ctx context.Context, handler func(*model.Verification)) error
+	PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error
+	PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority Priority) error
+	SubscribeToVerificationCompleted(ctx context.Context, handler func(*domain.Verification)) error
+	PublishRescoreRequest(ctx context.Context, request *RescoreVerificationMessage) error
+	SubscribeToVerificationRescored(ctx context.Context, handler func(*VerificationRescoredMessage)) error
+	PublishVerificationAmended(ctx context.Context, amended *VerificationAmendedMessage) error
+	PublishVerificationCompleted(ctx context.Context, completed *VerificationCompletedMessage) error
+	Status() ConnectionStatus
 	Close()
 }
 
+// ConnectionStatus состояние подключения к кластеру NATS
+type ConnectionStatus struct {
+	Connected bool   `json:"connected"`
+	Server    string `json:"server,omitempty"`
+}
+
 type natsClient struct {
-	conn   *nats.Conn
-	logger *zap.Logger
+	conn       *nats.Conn
+	queueGroup string
+	envelope   *Envelope
+	logger     *zap.Logger
 }
 
-func NewNATSClient(url string, logger *zap.Logger) (NATSClient, error) {
-	conn, err := nats.Connect(url)
+func NewNATSClient(cfg config.NATSConfig, logger *zap.Logger) (NATSClient, error) {
+	servers := cfg.Servers()
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no NATS servers configured")
+	}
+
+	conn, err := nats.Connect(strings.Join(servers, ","), connectOptions(cfg, logger)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
 	}
 
-	logger.Info("connected to NATS", zap.String("url", url))
-	return &natsClient{
-		conn:   conn,
-		logger: logger,
-	}, nil
+	client := &natsClient{
+		conn:       conn,
+		queueGroup: cfg.CompletedQueueGroup,
+		envelope:   NewEnvelope(cfg),
+		logger:     logger,
+	}
+	if !conn.IsConnected() {
+		logger.Warn("NATS is unavailable, starting in degraded mode", zap.Strings("servers", servers), zap.String("mode", cfg.DegradedMode))
+		return client, nil
+	}
+
+	metrics.SetNATSActiveServer(conn.ConnectedUrlRedacted())
+	logger.Info("connected to NATS", zap.String("server", conn.ConnectedUrlRedacted()), zap.Strings("servers", servers))
+	return client, nil
+}
+
+// connectOptions настраивает пул серверов и переподключение, чтобы отказ одного брокера
+// не приводил к ошибкам публикации: сообщения буферизуются до подключения к другому серверу
+func connectOptions(cfg config.NATSConfig, logger *zap.Logger) []nats.Option {
+	opts := []nats.Option{
+		nats.MaxReconnects(cfg.MaxReconnects),
+		nats.ReconnectWait(cfg.ReconnectWait),
+		nats.DisconnectErrHandler(func(conn *nats.Conn, err error) {
+			metrics.SetNATSActiveServer("")
+			logger.Warn("disconnected from NATS", zap.Error(err))
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			metrics.NATSReconnects.Inc()
+			metrics.SetNATSActiveServer(conn.ConnectedUrlRedacted())
+			logger.Info("reconnected to NATS", zap.String("server", conn.ConnectedUrlRedacted()))
+		}),
+		nats.ClosedHandler(func(conn *nats.Conn) {
+			metrics.SetNATSActiveServer("")
+		}),
+	}
+
+	// В режиме деградации недоступный при запуске брокер не мешает старту: подключение
+	// повторяется в фоне, а подписки оформляются после него
+	if cfg.DegradedMode != "" && cfg.DegradedMode != config.NATSDegradedOff {
+		opts = append(opts,
+			nats.RetryOnFailedConnect(true),
+			nats.ConnectHandler(func(conn *nats.Conn) {
+				metrics.SetNATSActiveServer(conn.ConnectedUrlRedacted())
+				logger.Info("connected to NATS", zap.String("server", conn.ConnectedUrlRedacted()))
+			}),
+		)
+	}
+
+	if cfg.ClientID != "" {
+		opts = append(opts, nats.Name(cfg.ClientID))
+	}
+	if !cfg.Randomize {
+		opts = append(opts, nats.DontRandomize())
+	}
+	if cfg.NoEcho {
+		opts = append(opts, nats.NoEcho())
+	}
+	if cfg.InboxPrefix != "" {
+		opts = append(opts, nats.CustomInboxPrefix(cfg.InboxPrefix))
+	}
+
+	return opts
 }
 
 type CreateVerificationMessage struct {
-	VerificationID string                       `json:"verification_id"`
-	INN            string                       `json:"inn"`
-	RequestedTypes []model.VerificationDataType `json:"requested_types"`
-	AuthorEmail    string                       `json:"author_email"`
+	VerificationID string              `json:"verification_id"`
+	INN            string              `json:"inn"`
+	RequestedTypes []domain.DataType   `json:"requested_types"`
+	AuthorEmail    string              `json:"author_email"`
+	Priority       Priority            `json:"priority,omitempty"`
+	ExternalRef    *ExternalRefMessage `json:"external_ref,omitempty"`
 }
 
-type VerificationCompletedMessage struct {
-	VerificationID string `json:"verification_id"`
-	Status         string `json:"status"`
-	Error          string `json:"error,omitempty"`
+// ExternalRefMessage идентификатор проверки во внешней системе в запросе на проверку
+type ExternalRefMessage struct {
+	System *string `json:"system,omitempty"`
+	Ref    string  `json:"ref"`
 }
 
-func (c *natsClient) PublishVerificationRequest(ctx context.Context, verification *model.Verification) error {
+func newCreateVerificationMessage(verification *domain.Verification, priority Priority) CreateVerificationMessage {
 	msg := CreateVerificationMessage{
 		VerificationID: verification.ID,
-		INN:            verification.Inn,
+		INN:            verification.INN,
 		RequestedTypes: verification.RequestedDataTypes,
 		AuthorEmail:    verification.AuthorEmail,
+		Priority:       priority,
 	}
+	if verification.ExternalRef != nil {
+		msg.ExternalRef = &ExternalRefMessage{System: verification.ExternalRef.System, Ref: verification.ExternalRef.Ref}
+	}
+	return msg
+}
+
+type VerificationCompletedMessage struct {
+	VerificationID string `json:"verification_id"`
+	Status         string `json:"status"`
+	Error          string `json:"error,omitempty"`
+	RiskLevel      string `json:"risk_level,omitempty"`
+	// RulesetID версия правил скоринга, по которой получен RiskLevel
+	RulesetID string `json:"ruleset_id,omitempty"`
+	// CompletedAt время смены статуса в RFC 3339. Без него используется time конверта CloudEvents.
+	CompletedAt string `json:"completed_at,omitempty"`
+}
 
-	data, err := json.Marshal(msg)
+func (c *natsClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
+	return c.PublishVerificationRequestWithPriority(ctx, verification, PriorityNormal)
+}
+
+// PublishVerificationRequestWithPriority публикует запрос в очередь, соответствующую приоритету
+func (c *natsClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority Priority) error {
+	return publishVerificationRequest(ctx, c.conn, c.envelope, subjectForPriority(priority), verification, priority, c.logger)
+}
+
+// publishVerificationRequest публикует запрос на проверку в subject через соединение conn.
+// Служебные данные (идентификатор сообщения, трассировка, арендатор, версия формата) передаются в заголовках.
+// С envelope тело сообщения оборачивается в конверт CloudEvents.
+func publishVerificationRequest(ctx context.Context, conn *nats.Conn, envelope *Envelope, subject string, verification *domain.Verification, priority Priority, logger *zap.Logger) error {
+	msg, err := encodeVerificationRequest(ctx, envelope, subject, verification, priority)
 	if err != nil {
-		c.logger.Error("failed to marshal verification request", zap.Error(err))
-		return fmt.Errorf("failed to marshal verification request: %w", err)
+		logger.Error("failed to encode verification request", zap.Error(err))
+		return err
 	}
 
-	err = c.conn.Publish("verification.create", data)
+	started := time.Now()
+	err = conn.PublishMsg(msg)
 	if err != nil {
-		c.logger.Error("failed to publish verification request", zap.Error(err), zap.String("verification_id", verification.ID))
+		logger.Error("failed to publish verification request", zap.Error(err), zap.String("verification_id", verification.ID))
 		return fmt.Errorf("failed to publish verification request: %w", err)
 	}
 
-	c.logger.Info("verification request published", zap.String("verification_id", verification.ID))
+	logMessage(logger, zap.InfoLevel, logMessagePublished, msg, time.Since(started),
+		zap.String("verification_id", verification.ID), zap.String("priority", string(priority)))
 	return nil
 }
 
-func (c *natsClient) SubscribeToVerificationCompleted(ctx context.Context, handler func(*model.Verification)) error {
-	_, err := c.conn.Subscribe("verification.completed", func(msg *nats.Msg) {
-		var completedMsg VerificationCompletedMessage
-		if err := json.Unmarshal(msg.Data, &completedMsg); err != nil {
-			c.logger.Error("failed to unmarshal verification completed message", zap.Error(err))
-			return
+// encodeVerificationRequest собирает сообщение запроса на проверку с заголовками и, при envelope, в конверте CloudEvents
+func encodeVerificationRequest(ctx context.Context, envelope *Envelope, subject string, verification *domain.Verification, priority Priority) (*nats.Msg, error) {
+	data, err := json.Marshal(newCreateVerificationMessage(verification, priority))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal verification request: %w", err)
+	}
+
+	msg := newRequestMsg(ctx, subject, verification, data)
+	if err := envelope.wrap(msg, EventTypeVerificationRequested, verification.ID); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (c *natsClient) SubscribeToVerificationCompleted(ctx context.Context, handler func(*domain.Verification)) error {
+	_, err := subscribeToVerificationCompleted(c.conn, SubjectVerificationCompleted, c.queueGroup, handler, c.logger)
+	return err
+}
+
+// subscribeToVerificationCompleted подписывается на уведомления о завершении в subject через соединение conn.
+// С queueGroup уведомления распределяются между экземплярами группы.
+func subscribeToVerificationCompleted(conn *nats.Conn, subject, queueGroup string, handler func(*domain.Verification), logger *zap.Logger) (*nats.Subscription, error) {
+	sub, err := conn.QueueSubscribe(subject, queueGroup, func(msg *nats.Msg) {
+		metadata := readMetadata(msg)
+		if metadata.SchemaVersion > MessageSchemaVersion {
+			logger.Warn("verification completed message has newer schema version",
+				zap.Int("schema_version", metadata.SchemaVersion),
+				zap.Int("supported_version", MessageSchemaVersion),
+				zap.String("msg_id", metadata.MsgID))
 		}
 
-		verification := &model.Verification{
-			ID:     completedMsg.VerificationID,
-			Status: model.VerificationStatus(completedMsg.Status),
+		verification, err := decodeVerificationCompleted(msg.Data, &metadata)
+		if err != nil {
+			logger.Error("failed to decode verification completed message", zap.Error(err), zap.String("msg_id", metadata.MsgID))
+			return
 		}
 
+		started := time.Now()
 		handler(verification)
-		c.logger.Info("verification completed message processed", zap.String("verification_id", completedMsg.VerificationID), zap.String("status", completedMsg.Status))
+		logMessage(logger, zap.InfoLevel, logMessageConsumed, msg, time.Since(started),
+			zap.String("verification_id", verification.ID),
+			zap.String("status", string(verification.Status)),
+			zap.String("traceparent", metadata.TraceParent))
 	})
 
 	if err != nil {
-		c.logger.Error("failed to subscribe to verification completed", zap.Error(err))
-		return fmt.Errorf("failed to subscribe to verification completed: %w", err)
+		logger.Error("failed to subscribe to verification completed", zap.Error(err), zap.String("subject", subject))
+		return nil, fmt.Errorf("failed to subscribe to verification completed: %w", err)
 	}
 
-	c.logger.Info("subscribed to verification completed messages")
-	return nil
+	logger.Info("subscribed to verification completed messages", zap.String("subject", subject), zap.String("queue_group", queueGroup))
+	return sub, nil
+}
+
+// decodeVerificationCompleted читает уведомление о завершении в конверте CloudEvents или без него.
+// Если у сообщения нет заголовка с идентификатором, metadata получает идентификатор события.
+func decodeVerificationCompleted(payload []byte, metadata *MessageMetadata) (*domain.Verification, error) {
+	data, event, err := unwrapEvent(payload)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.MsgID == "" && event != nil {
+		metadata.MsgID = event.ID
+	}
+
+	var completedMsg VerificationCompletedMessage
+	if err := json.Unmarshal(data, &completedMsg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal verification completed message: %w", err)
+	}
+
+	verification := &domain.Verification{
+		ID:      completedMsg.VerificationID,
+		Status:  domain.Status(completedMsg.Status),
+		EventID: metadata.MsgID,
+	}
+	completedAt := completedMsg.CompletedAt
+	if completedAt == "" && event != nil {
+		completedAt = event.Time
+	}
+	if completedAt != "" {
+		// Время нужно только для проверки свежести уведомления, поэтому нечитаемое время не отклоняет его
+		if updatedAt, err := time.Parse(time.RFC3339Nano, completedAt); err == nil {
+			verification.UpdatedAt = updatedAt
+		}
+	}
+	if completedMsg.RiskLevel != "" {
+		riskLevel := domain.RiskLevel(completedMsg.RiskLevel)
+		verification.RiskLevel = &riskLevel
+	}
+	if completedMsg.Error != "" {
+		verification.ErrorMessage = &completedMsg.Error
+	}
+	if completedMsg.RulesetID != "" {
+		verification.RulesetID = &completedMsg.RulesetID
+	}
+	return verification, nil
+}
+
+// Status возвращает состояние подключения и адрес активного сервера
+func (c *natsClient) Status() ConnectionStatus {
+	if c.conn == nil || !c.conn.IsConnected() {
+		return ConnectionStatus{}
+	}
+	return ConnectionStatus{
+		Connected: true,
+		Server:    c.conn.ConnectedUrlRedacted(),
+	}
 }
 
 func (c *natsClient) Close() {
@@ -0,0 +1,110 @@
+package messaging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/allocbudget"
+	"scoring_api_gateway/internal/domain"
+)
+
+// Бюджеты выделений памяти на сообщение. Если изменение осознанно их превышает,
+// бюджет поднимается в том же изменении с объяснением в описании.
+const (
+	encodeRequestAllocs            = 36
+	encodeRequestCloudEventsAllocs = 42
+	decodeCompletedAllocs          = 6
+	decodeCompletedCloudEventAlloc = 8
+)
+
+var benchVerification = &domain.Verification{
+	ID:          "2f1c6f3e-8a52-4c55-9d2b-3c1f0e6b7a90",
+	INN:         "7707083893",
+	AuthorEmail: "analyst@example.com",
+	RequestedDataTypes: []domain.DataType{
+		domain.DataTypeBasicInformation,
+		domain.DataTypeActivities,
+		domain.DataTypeArbitrageStatistics,
+	},
+}
+
+var (
+	benchCompleted           = []byte(`{"verification_id":"2f1c6f3e-8a52-4c55-9d2b-3c1f0e6b7a90","status":"COMPLETED","risk_level":"LOW","ruleset_id":"2024-06"}`)
+	benchCompletedCloudEvent = []byte(`{"specversion":"1.0","id":"e-1","source":"/worker","type":"ru.scoring.verification.completed","time":"2024-01-01T10:00:00Z",` +
+		`"datacontenttype":"application/json","data":{"verification_id":"2f1c6f3e-8a52-4c55-9d2b-3c1f0e6b7a90","status":"COMPLETED","risk_level":"LOW","ruleset_id":"2024-06"}}`)
+)
+
+func benchEnvelope() *Envelope {
+	return &Envelope{source: "/scoring-api-gateway", now: time.Now}
+}
+
+func BenchmarkEncodeVerificationRequest(b *testing.B) {
+	for _, bc := range []struct {
+		name     string
+		envelope *Envelope
+	}{
+		{name: "legacy"},
+		{name: "cloudevents", envelope: benchEnvelope()},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				if _, err := encodeVerificationRequest(ctx, bc.envelope, SubjectVerificationCreate, benchVerification, PriorityNormal); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDecodeVerificationCompleted(b *testing.B) {
+	for _, bc := range []struct {
+		name    string
+		payload []byte
+	}{
+		{name: "legacy", payload: benchCompleted},
+		{name: "cloudevents", payload: benchCompletedCloudEvent},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				var metadata MessageMetadata
+				if _, err := decodeVerificationCompleted(bc.payload, &metadata); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestMessageAllocBudget(t *testing.T) {
+	ctx := context.Background()
+	envelope := benchEnvelope()
+
+	t.Run("encode_request", func(t *testing.T) {
+		allocbudget.Check(t, encodeRequestAllocs, func() {
+			_, _ = encodeVerificationRequest(ctx, nil, SubjectVerificationCreate, benchVerification, PriorityNormal)
+		})
+	})
+	t.Run("encode_request_cloudevents", func(t *testing.T) {
+		allocbudget.Check(t, encodeRequestCloudEventsAllocs, func() {
+			_, _ = encodeVerificationRequest(ctx, envelope, SubjectVerificationCreate, benchVerification, PriorityNormal)
+		})
+	})
+	t.Run("decode_completed", func(t *testing.T) {
+		allocbudget.Check(t, decodeCompletedAllocs, func() {
+			var metadata MessageMetadata
+			_, _ = decodeVerificationCompleted(benchCompleted, &metadata)
+		})
+	})
+	t.Run("decode_completed_cloudevents", func(t *testing.T) {
+		allocbudget.Check(t, decodeCompletedCloudEventAlloc, func() {
+			var metadata MessageMetadata
+			_, _ = decodeVerificationCompleted(benchCompletedCloudEvent, &metadata)
+		})
+	})
+}
@@ -7,7 +7,7 @@ import (
 	"fmt"
 	"testing"
 
-	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/domain"
 
 	"github.com/nats-io/nats.go"
 	"go.uber.org/zap"
@@ -54,10 +54,10 @@ type testNATSClient struct {
 	logger *zap.Logger
 }
 
-func (c *testNATSClient) PublishVerificationRequest(ctx context.Context, verification *model.Verification) error {
+func (c *testNATSClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
 	msg := CreateVerificationMessage{
 		VerificationID: verification.ID,
-		INN:            verification.Inn,
+		INN:            verification.INN,
 		RequestedTypes: verification.RequestedDataTypes,
 		AuthorEmail:    verification.AuthorEmail,
 	}
@@ -78,7 +78,7 @@ func (c *testNATSClient) PublishVerificationRequest(ctx context.Context, verific
 	return nil
 }
 
-func (c *testNATSClient) SubscribeToVerificationCompleted(ctx context.Context, handler func(*model.Verification)) error {
+func (c *testNATSClient) SubscribeToVerificationCompleted(ctx context.Context, handler func(*domain.Verification)) error {
 	_, err := c.conn.Subscribe("verification.completed", func(msg *nats.Msg) {
 		var completedMsg VerificationCompletedMessage
 		if err := json.Unmarshal(msg.Data, &completedMsg); err != nil {
@@ -86,9 +86,9 @@ func (c *testNATSClient) SubscribeToVerificationCompleted(ctx context.Context, h
 			return
 		}
 
-		verification := &model.Verification{
+		verification := &domain.Verification{
 			ID:     completedMsg.VerificationID,
-			Status: model.VerificationStatus(completedMsg.Status),
+			Status: domain.Status(completedMsg.Status),
 		}
 
 		handler(verification)
@@ -114,28 +114,28 @@ func (c *testNATSClient) Close() {
 func TestPublishVerificationRequest(t *testing.T) {
 	tests := []struct {
 		name          string
-		verification  *model.Verification
+		verification  *domain.Verification
 		publishError  error
 		expectedError string
 	}{
 		{
 			name: "successful_publish",
-			verification: &model.Verification{
+			verification: &domain.Verification{
 				ID:                 "test-id",
-				Inn:                "1234567890",
+				INN:                "1234567890",
 				AuthorEmail:        "test@example.com",
-				RequestedDataTypes: []model.VerificationDataType{model.VerificationDataTypeBasicInformation},
+				RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation},
 			},
 			publishError:  nil,
 			expectedError: "",
 		},
 		{
 			name: "publish_error",
-			verification: &model.Verification{
+			verification: &domain.Verification{
 				ID:                 "test-id",
-				Inn:                "1234567890",
+				INN:                "1234567890",
 				AuthorEmail:        "test@example.com",
-				RequestedDataTypes: []model.VerificationDataType{model.VerificationDataTypeBasicInformation},
+				RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation},
 			},
 			publishError:  errors.New("nats connection failed"),
 			expectedError: "failed to publish verification request",
@@ -196,8 +196,8 @@ func TestPublishVerificationRequest(t *testing.T) {
 					t.Errorf("expected verification ID '%s', but got '%s'", tt.verification.ID, msg.VerificationID)
 				}
 
-				if msg.INN != tt.verification.Inn {
-					t.Errorf("expected INN '%s', but got '%s'", tt.verification.Inn, msg.INN)
+				if msg.INN != tt.verification.INN {
+					t.Errorf("expected INN '%s', but got '%s'", tt.verification.INN, msg.INN)
 				}
 
 				if msg.AuthorEmail != tt.verification.AuthorEmail {
@@ -238,7 +238,7 @@ func TestSubscribeToVerificationCompleted(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var handlerCalled bool
-			var receivedVerification *model.Verification
+			var receivedVerification *domain.Verification
 			var subscribedSubject string
 			var messageHandler nats.MsgHandler
 
@@ -256,7 +256,7 @@ func TestSubscribeToVerificationCompleted(t *testing.T) {
 				logger: logger,
 			}
 
-			handler := func(verification *model.Verification) {
+			handler := func(verification *domain.Verification) {
 				handlerCalled = true
 				receivedVerification = verification
 			}
@@ -331,7 +331,7 @@ func TestSubscribeToVerificationCompletedInvalidMessage(t *testing.T) {
 	}
 
 	var handlerCalled bool
-	handler := func(verification *model.Verification) {
+	handler := func(verification *domain.Verification) {
 		handlerCalled = true
 	}
 
@@ -389,3 +389,20 @@ func containsError(got, want string) bool {
 	return len(got) > 0 && len(want) > 0 && (got == want ||
 		(len(got) >= len(want) && got[:len(want)] == want))
 }
+
+func TestSubjectForPriority(t *testing.T) {
+	tests := []struct {
+		priority Priority
+		expected string
+	}{
+		{priority: PriorityNormal, expected: "verification.create"},
+		{priority: PriorityHigh, expected: "verification.create.high"},
+		{priority: "", expected: "verification.create"},
+	}
+
+	for _, tt := range tests {
+		if subject := subjectForPriority(tt.priority); subject != tt.expected {
+			t.Errorf("expected subject '%s' for priority '%s', but got '%s'", tt.expected, tt.priority, subject)
+		}
+	}
+}
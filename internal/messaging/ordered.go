@@ -0,0 +1,119 @@
+package messaging
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+type orderedCompletionsClient struct {
+	NATSClient
+	shards    int
+	queueSize int
+	logger    *zap.Logger
+
+	mu     sync.RWMutex
+	closed bool
+	queues []chan orderedCompletion
+	wg     sync.WaitGroup
+}
+
+// orderedCompletion уведомление в очереди обработчика; done вызывается после его применения
+type orderedCompletion struct {
+	verification *domain.Verification
+	done         func()
+}
+
+// acknowledgedSubscriber подписка, которой нужно знать, когда уведомление применено: отложенное
+// уведомление подтверждается только после обработки, а не после постановки в очередь, иначе
+// остановка экземпляра теряет уведомления, ждущие в очередях обработчиков.
+type acknowledgedSubscriber interface {
+	subscribeToVerificationCompletedAcked(ctx context.Context, handler func(verification *domain.Verification, done func())) error
+}
+
+// NewOrderedCompletionsClient обрабатывает уведомления о завершении в shards обработчиках: уведомления
+// одной проверки всегда попадают в один обработчик и применяются в порядке получения, а разные
+// проверки обрабатываются параллельно. Без него живые и отложенные уведомления, а также подписки
+// арендаторов обрабатываются независимо, и запоздавший IN_PROCESS мог перезаписать COMPLETED.
+// Заполненная очередь обработчика задерживает получение новых уведомлений. Отложенные уведомления
+// подтверждаются после применения обработчиком.
+func NewOrderedCompletionsClient(client NATSClient, shards, queueSize int, logger *zap.Logger) NATSClient {
+	if shards <= 0 {
+		return client
+	}
+	return &orderedCompletionsClient{
+		NATSClient: client,
+		shards:     shards,
+		queueSize:  queueSize,
+		logger:     logger,
+	}
+}
+
+func (c *orderedCompletionsClient) SubscribeToVerificationCompleted(ctx context.Context, handler func(*domain.Verification)) error {
+	queues := make([]chan orderedCompletion, c.shards)
+	for i := range queues {
+		queues[i] = make(chan orderedCompletion, c.queueSize)
+		c.wg.Add(1)
+		go c.process(queues[i], handler)
+	}
+
+	c.mu.Lock()
+	c.queues = append(c.queues, queues...)
+	c.mu.Unlock()
+
+	enqueue := func(verification *domain.Verification, done func()) {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		if c.closed {
+			// После Close уведомление применяется сразу: очереди уже разобраны
+			handler(verification)
+			done()
+			return
+		}
+		metrics.CompletionsOrderedQueued.Inc()
+		queues[shardOf(verification.ID, len(queues))] <- orderedCompletion{verification: verification, done: done}
+	}
+	if acked, ok := c.NATSClient.(acknowledgedSubscriber); ok {
+		return acked.subscribeToVerificationCompletedAcked(ctx, enqueue)
+	}
+	return c.NATSClient.SubscribeToVerificationCompleted(ctx, func(verification *domain.Verification) {
+		enqueue(verification, func() {})
+	})
+}
+
+func (c *orderedCompletionsClient) process(queue <-chan orderedCompletion, handler func(*domain.Verification)) {
+	defer c.wg.Done()
+	for completion := range queue {
+		metrics.CompletionsOrderedQueued.Dec()
+		handler(completion.verification)
+		completion.done()
+	}
+}
+
+// Close дожидается обработки принятых уведомлений и закрывает клиент
+func (c *orderedCompletionsClient) Close() {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		for _, queue := range c.queues {
+			close(queue)
+		}
+	}
+	c.mu.Unlock()
+
+	c.wg.Wait()
+	c.logger.Info("Ordered completion processing stopped")
+	c.NATSClient.Close()
+}
+
+// shardOf номер обработчика проверки id
+func shardOf(id string, shards int) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(id))
+	return int(hash.Sum32() % uint32(shards))
+}
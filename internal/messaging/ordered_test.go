@@ -0,0 +1,136 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type closableClient struct {
+	subscribedClient
+	closed bool
+}
+
+func (c *closableClient) Close() {
+	c.closed = true
+}
+
+func TestOrderedCompletionsClient(t *testing.T) {
+	subscribed := &closableClient{}
+	client := NewOrderedCompletionsClient(subscribed, 4, 1, zaptest.NewLogger(t))
+
+	var mu sync.Mutex
+	applied := map[string][]domain.Status{}
+	err := client.SubscribeToVerificationCompleted(context.Background(), func(verification *domain.Verification) {
+		// Медленная обработка первого уведомления не должна пропустить вперед следующее
+		if verification.Status == domain.StatusInProcess {
+			time.Sleep(10 * time.Millisecond)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		applied[verification.ID] = append(applied[verification.ID], verification.Status)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ids := make([]string, 8)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("v-%d", i)
+	}
+	for _, id := range ids {
+		subscribed.handler(&domain.Verification{ID: id, Status: domain.StatusInProcess})
+		subscribed.handler(&domain.Verification{ID: id, Status: domain.StatusCompleted})
+	}
+	client.Close()
+
+	if !subscribed.closed {
+		t.Error("expected the underlying client to be closed")
+	}
+	for _, id := range ids {
+		statuses := applied[id]
+		if len(statuses) != 2 || statuses[0] != domain.StatusInProcess || statuses[1] != domain.StatusCompleted {
+			t.Errorf("expected %s completions to be applied in order, but got %v", id, statuses)
+		}
+	}
+
+	// После закрытия уведомления применяются сразу
+	subscribed.handler(&domain.Verification{ID: "v-late", Status: domain.StatusCompleted})
+	if len(applied["v-late"]) != 1 {
+		t.Error("expected a completion received after Close to be applied")
+	}
+}
+
+func TestOrderedCompletionsClientAcksParkedAfterApplying(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	parking := &memoryParking{parked: make(chan *ParkedCompletion, 10), acked: make(chan string, 10)}
+	subscribed := &closableClient{}
+	// Бюджет на одно уведомление: второе откладывается и выдается через 10ms
+	limited := NewRateLimitedCompletionsClient(subscribed, parking, NewThrottle(100, 1), zaptest.NewLogger(t))
+	client := NewOrderedCompletionsClient(limited, 2, 1, zaptest.NewLogger(t))
+
+	release := make(chan struct{})
+	applying := make(chan string, 1)
+	err := client.SubscribeToVerificationCompleted(ctx, func(verification *domain.Verification) {
+		if verification.ID == "v-parked" {
+			applying <- verification.ID
+			<-release
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	subscribed.handler(&domain.Verification{ID: "v-live", Status: domain.StatusCompleted})
+	subscribed.handler(&domain.Verification{ID: "v-parked", Status: domain.StatusCompleted})
+
+	select {
+	case <-applying:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the parked completion to reach the handler")
+	}
+	select {
+	case id := <-parking.acked:
+		t.Fatalf("expected %s not to be acked while the handler is still applying it", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case id := <-parking.acked:
+		if id != "v-parked" {
+			t.Errorf("expected v-parked to be acked, but got %s", id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the parked completion to be acked after it was applied")
+	}
+	client.Close()
+}
+
+func TestShardOf(t *testing.T) {
+	if shardOf("v-1", 8) != shardOf("v-1", 8) {
+		t.Error("expected a verification to always map to the same shard")
+	}
+	seen := map[int]bool{}
+	for i := 0; i < 100; i++ {
+		seen[shardOf(fmt.Sprintf("v-%d", i), 8)] = true
+	}
+	if len(seen) != 8 {
+		t.Errorf("expected verifications to spread over all shards, but got %d", len(seen))
+	}
+}
+
+func TestNewOrderedCompletionsClientDisabled(t *testing.T) {
+	client := &subscribedClient{}
+	if NewOrderedCompletionsClient(client, 0, 100, zaptest.NewLogger(t)) != NATSClient(client) {
+		t.Error("expected client without shards to be returned unchanged")
+	}
+}
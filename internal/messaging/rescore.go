@@ -0,0 +1,88 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+const (
+	// SubjectVerificationRescore запросы на пересчет оценки по сохраненным данным проверки
+	SubjectVerificationRescore = "verification.rescore"
+	// SubjectVerificationRescored новые оценки, посчитанные по текущим правилам скоринга
+	SubjectVerificationRescored = "verification.rescored"
+)
+
+// RescoreVerificationMessage запрос скоринговому движку на пересчет оценки без повторного сбора данных
+type RescoreVerificationMessage struct {
+	VerificationID  string `json:"verification_id"`
+	INN             string `json:"inn"`
+	RecalculationID string `json:"recalculation_id"`
+}
+
+// VerificationRescoredMessage новая оценка проверки и версия правил, по которым она получена
+type VerificationRescoredMessage struct {
+	VerificationID  string `json:"verification_id"`
+	RecalculationID string `json:"recalculation_id,omitempty"`
+	RiskLevel       string `json:"risk_level"`
+	RulesetID       string `json:"ruleset_id,omitempty"`
+}
+
+// PublishRescoreRequest отправляет запрос на пересчет оценки. Повторная отправка в рамках
+// того же пересчета получает тот же идентификатор сообщения.
+func (c *natsClient) PublishRescoreRequest(ctx context.Context, request *RescoreVerificationMessage) error {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rescore request: %w", err)
+	}
+
+	msg := nats.NewMsg(SubjectVerificationRescore)
+	msg.Data = data
+	msg.Header.Set(HeaderMsgID, request.VerificationID+":rescore:"+request.RecalculationID)
+	msg.Header.Set(HeaderSchemaVersion, strconv.Itoa(MessageSchemaVersion))
+	trace, _ := TraceContextFromContext(ctx)
+	msg.Header.Set(HeaderTraceParent, childTraceParent(trace.TraceParent))
+	if err := c.envelope.wrap(msg, EventTypeRescoreRequested, request.VerificationID); err != nil {
+		return err
+	}
+
+	started := time.Now()
+	if err := c.conn.PublishMsg(msg); err != nil {
+		c.logger.Error("failed to publish rescore request", zap.Error(err), zap.String("verification_id", request.VerificationID))
+		return fmt.Errorf("failed to publish rescore request: %w", err)
+	}
+	logMessage(c.logger, zap.InfoLevel, logMessagePublished, msg, time.Since(started),
+		zap.String("verification_id", request.VerificationID), zap.String("recalculation_id", request.RecalculationID))
+	return nil
+}
+
+// SubscribeToVerificationRescored подписывается на новые оценки проверок
+func (c *natsClient) SubscribeToVerificationRescored(ctx context.Context, handler func(*VerificationRescoredMessage)) error {
+	_, err := c.conn.QueueSubscribe(SubjectVerificationRescored, c.queueGroup, func(msg *nats.Msg) {
+		var rescored VerificationRescoredMessage
+		data, _, err := unwrapEvent(msg.Data)
+		if err == nil {
+			err = json.Unmarshal(data, &rescored)
+		}
+		if err != nil {
+			c.logger.Error("failed to unmarshal verification rescored message", zap.Error(err), zap.String("msg_id", readMetadata(msg).MsgID))
+			return
+		}
+		started := time.Now()
+		handler(&rescored)
+		logMessage(c.logger, zap.InfoLevel, logMessageConsumed, msg, time.Since(started),
+			zap.String("verification_id", rescored.VerificationID), zap.String("recalculation_id", rescored.RecalculationID))
+	})
+	if err != nil {
+		c.logger.Error("failed to subscribe to verification rescored", zap.Error(err))
+		return fmt.Errorf("failed to subscribe to verification rescored: %w", err)
+	}
+
+	c.logger.Info("subscribed to verification rescored messages", zap.String("subject", SubjectVerificationRescored), zap.String("queue_group", c.queueGroup))
+	return nil
+}
@@ -0,0 +1,253 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/domain"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// TenantRoute маршрут трафика арендатора, настроенный в таблице organizations
+type TenantRoute struct {
+	Tenant string
+	// SubjectPrefix префикс subject арендатора: запросы уходят в <prefix>.verification.create[.high],
+	// уведомления ожидаются в <prefix>.verification.completed
+	SubjectPrefix string
+	// CredentialsFile файл учетных данных NATS (.creds), пусто - общее соединение шлюза
+	CredentialsFile string
+}
+
+// TenantRouteSource источник маршрутов арендаторов
+type TenantRouteSource interface {
+	GetTenantRoutes(ctx context.Context) ([]*TenantRoute, error)
+}
+
+// tenantSubject возвращает subject арендатора для общего subject
+func tenantSubject(prefix, subject string) string {
+	return strings.TrimSuffix(prefix, ".") + "." + subject
+}
+
+// TenantRoutedClient клиент NATS, публикующий запросы арендаторов в их собственные subject
+type TenantRoutedClient interface {
+	NATSClient
+	RefreshRoutes(ctx context.Context) error
+}
+
+// tenantLink соединение и подписка арендатора с отдельным маршрутом
+type tenantLink struct {
+	route *TenantRoute
+	conn  *nats.Conn
+	sub   *nats.Subscription
+	// ownConn соединение открыто с учетными данными арендатора и закрывается вместе с маршрутом
+	ownConn bool
+}
+
+type tenantRoutedClient struct {
+	*natsClient
+	cfg    config.NATSConfig
+	source TenantRouteSource
+	logger *zap.Logger
+
+	mu      sync.RWMutex
+	links   map[string]*tenantLink
+	handler func(*domain.Verification)
+}
+
+// NewTenantRoutedClient изолирует трафик арендаторов: запросы арендатора с маршрутом публикуются
+// в subject с его префиксом (и, при наличии, через соединение с его учетными данными), поэтому
+// очередь одного арендатора не задерживает остальных. Арендаторы без маршрута используют общие subject.
+// Маршруты загружаются RefreshRoutes.
+func NewTenantRoutedClient(client NATSClient, source TenantRouteSource, cfg config.NATSConfig, logger *zap.Logger) (TenantRoutedClient, error) {
+	base, ok := client.(*natsClient)
+	if !ok {
+		return nil, fmt.Errorf("tenant routing requires a direct NATS connection")
+	}
+
+	return &tenantRoutedClient{
+		natsClient: base,
+		cfg:        cfg,
+		source:     source,
+		logger:     logger,
+		links:      make(map[string]*tenantLink),
+	}, nil
+}
+
+func (c *tenantRoutedClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
+	return c.PublishVerificationRequestWithPriority(ctx, verification, PriorityNormal)
+}
+
+func (c *tenantRoutedClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority Priority) error {
+	c.mu.RLock()
+	link, ok := c.links[TenantOf(verification.AuthorEmail)]
+	c.mu.RUnlock()
+
+	if !ok {
+		return c.natsClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+	return publishVerificationRequest(ctx, link.conn, c.envelope, tenantSubject(link.route.SubjectPrefix, subjectForPriority(priority)), verification, priority, c.logger)
+}
+
+// SubscribeToVerificationCompleted подписывается на общий subject и на subject всех арендаторов с маршрутом
+func (c *tenantRoutedClient) SubscribeToVerificationCompleted(ctx context.Context, handler func(*domain.Verification)) error {
+	if err := c.natsClient.SubscribeToVerificationCompleted(ctx, handler); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.handler = handler
+	for _, link := range c.links {
+		if err := c.subscribe(link); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *tenantRoutedClient) subscribe(link *tenantLink) error {
+	sub, err := subscribeToVerificationCompleted(link.conn, tenantSubject(link.route.SubjectPrefix, SubjectVerificationCompleted), c.queueGroup, c.handler, c.logger)
+	if err != nil {
+		return err
+	}
+	link.sub = sub
+	return nil
+}
+
+// RefreshRoutes перечитывает маршруты арендаторов. Соединения и подписки измененных и удаленных
+// маршрутов закрываются, для новых - открываются.
+func (c *tenantRoutedClient) RefreshRoutes(ctx context.Context) error {
+	routes, err := c.source.GetTenantRoutes(ctx)
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]*TenantRoute, len(routes))
+	for _, route := range routes {
+		if route.SubjectPrefix != "" {
+			wanted[route.Tenant] = route
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for tenant, link := range c.links {
+		if route, ok := wanted[tenant]; ok && *route == *link.route {
+			delete(wanted, tenant)
+			continue
+		}
+		c.unlink(link)
+		delete(c.links, tenant)
+	}
+
+	var errs []error
+	for tenant, route := range wanted {
+		link, err := c.link(route)
+		if err != nil {
+			// Без своего маршрута арендатор временно обслуживается через общие subject
+			c.logger.Error("failed to set up tenant route", zap.Error(err), zap.String("tenant", tenant))
+			errs = append(errs, err)
+			continue
+		}
+		c.links[tenant] = link
+		c.logger.Info("tenant route set up", zap.String("tenant", tenant), zap.String("subject_prefix", route.SubjectPrefix), zap.Bool("own_credentials", link.ownConn))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to set up %d tenant routes: %w", len(errs), errs[0])
+	}
+	return nil
+}
+
+func (c *tenantRoutedClient) link(route *TenantRoute) (*tenantLink, error) {
+	link := &tenantLink{route: route, conn: c.conn}
+	if route.CredentialsFile != "" {
+		conn, err := nats.Connect(strings.Join(c.cfg.Servers(), ","), c.tenantConnectOptions(route)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to NATS as tenant %s: %w", route.Tenant, err)
+		}
+		link.conn = conn
+		link.ownConn = true
+	}
+
+	if c.handler != nil {
+		if err := c.subscribe(link); err != nil {
+			c.unlink(link)
+			return nil, err
+		}
+	}
+	return link, nil
+}
+
+func (c *tenantRoutedClient) unlink(link *tenantLink) {
+	if link.sub != nil {
+		if err := link.sub.Unsubscribe(); err != nil {
+			c.logger.Warn("failed to unsubscribe tenant route", zap.Error(err), zap.String("tenant", link.route.Tenant))
+		}
+	}
+	if link.ownConn {
+		link.conn.Close()
+	}
+}
+
+// tenantConnectOptions параметры соединения арендатора. Метрики подключения относятся
+// к общему соединению, поэтому здесь только журналирование.
+func (c *tenantRoutedClient) tenantConnectOptions(route *TenantRoute) []nats.Option {
+	logger := c.logger.With(zap.String("tenant", route.Tenant))
+	opts := []nats.Option{
+		nats.UserCredentials(route.CredentialsFile),
+		nats.MaxReconnects(c.cfg.MaxReconnects),
+		nats.ReconnectWait(c.cfg.ReconnectWait),
+		nats.DisconnectErrHandler(func(conn *nats.Conn, err error) {
+			logger.Warn("tenant disconnected from NATS", zap.Error(err))
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			logger.Info("tenant reconnected to NATS", zap.String("server", conn.ConnectedUrlRedacted()))
+		}),
+	}
+	if c.cfg.ClientID != "" {
+		opts = append(opts, nats.Name(c.cfg.ClientID+"-"+route.Tenant))
+	}
+	if !c.cfg.Randomize {
+		opts = append(opts, nats.DontRandomize())
+	}
+	if c.cfg.NoEcho {
+		opts = append(opts, nats.NoEcho())
+	}
+	return opts
+}
+
+func (c *tenantRoutedClient) Close() {
+	c.mu.Lock()
+	for tenant, link := range c.links {
+		c.unlink(link)
+		delete(c.links, tenant)
+	}
+	c.mu.Unlock()
+
+	c.natsClient.Close()
+}
+
+// RouteRefreshJob перечитывает маршруты арендаторов из таблицы organizations
+type RouteRefreshJob struct {
+	client TenantRoutedClient
+}
+
+func NewRouteRefreshJob(client TenantRoutedClient) *RouteRefreshJob {
+	return &RouteRefreshJob{client: client}
+}
+
+func (j *RouteRefreshJob) Name() string {
+	return "tenant_routes_refresh"
+}
+
+func (j *RouteRefreshJob) Run(ctx context.Context) error {
+	return j.client.RefreshRoutes(ctx)
+}
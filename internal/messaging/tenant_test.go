@@ -0,0 +1,104 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"scoring_api_gateway/internal/config"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для TenantRouteSource
+type mockTenantRouteSource struct {
+	routes []*TenantRoute
+	err    error
+}
+
+func (m *mockTenantRouteSource) GetTenantRoutes(ctx context.Context) ([]*TenantRoute, error) {
+	return m.routes, m.err
+}
+
+func TestTenantSubject(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		subject  string
+		expected string
+	}{
+		{"tenant.bank", SubjectVerificationCreate, "tenant.bank.verification.create"},
+		{"tenant.bank.", SubjectVerificationCreateHighPriority, "tenant.bank.verification.create.high"},
+		{"acme", SubjectVerificationCompleted, "acme.verification.completed"},
+	}
+
+	for _, tt := range tests {
+		if got := tenantSubject(tt.prefix, tt.subject); got != tt.expected {
+			t.Errorf("tenantSubject(%q, %q) = %q, expected %q", tt.prefix, tt.subject, got, tt.expected)
+		}
+	}
+}
+
+func TestNewTenantRoutedClientRequiresDirectConnection(t *testing.T) {
+	_, err := NewTenantRoutedClient(&throttledClient{}, &mockTenantRouteSource{}, config.NATSConfig{}, zaptest.NewLogger(t))
+	if err == nil || !containsError(err.Error(), "tenant routing requires a direct NATS connection") {
+		t.Errorf("expected direct connection error, but got %v", err)
+	}
+}
+
+func TestRefreshRoutes(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	source := &mockTenantRouteSource{
+		routes: []*TenantRoute{
+			{Tenant: "bank.ru", SubjectPrefix: "tenant.bank"},
+			{Tenant: "shop.ru", SubjectPrefix: "tenant.shop"},
+			{Tenant: "plain.ru"},
+		},
+	}
+
+	client, err := NewTenantRoutedClient(&natsClient{logger: logger}, source, config.NATSConfig{URL: "nats://127.0.0.1:1"}, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	routed := client.(*tenantRoutedClient)
+
+	if err := routed.RefreshRoutes(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(routed.links) != 2 || routed.links["plain.ru"] != nil {
+		t.Fatalf("expected routes only for tenants with subject prefix, but got %v", routed.links)
+	}
+	bank := routed.links["bank.ru"]
+
+	// Неизмененный маршрут сохраняется, измененный пересоздается, удаленный закрывается
+	source.routes = []*TenantRoute{
+		{Tenant: "bank.ru", SubjectPrefix: "tenant.bank"},
+		{Tenant: "new.ru", SubjectPrefix: "tenant.new"},
+	}
+	if err := routed.RefreshRoutes(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if routed.links["bank.ru"] != bank {
+		t.Error("expected unchanged route to be kept")
+	}
+	if routed.links["shop.ru"] != nil || routed.links["new.ru"] == nil {
+		t.Errorf("expected shop.ru to be removed and new.ru to be added, but got %v", routed.links)
+	}
+
+	// Арендатор, к которому не удалось подключиться, остается на общих subject
+	source.routes = append(source.routes, &TenantRoute{Tenant: "broken.ru", SubjectPrefix: "tenant.broken", CredentialsFile: "/nonexistent.creds"})
+	err = routed.RefreshRoutes(context.Background())
+	if err == nil || !containsError(err.Error(), "failed to set up 1 tenant routes") {
+		t.Errorf("expected tenant route error, but got %v", err)
+	}
+	if routed.links["broken.ru"] != nil || len(routed.links) != 2 {
+		t.Errorf("expected broken route to be skipped, but got %v", routed.links)
+	}
+
+	source.err = errors.New("database unavailable")
+	if err := routed.RefreshRoutes(context.Background()); err == nil {
+		t.Error("expected source error")
+	}
+	if len(routed.links) != 2 {
+		t.Errorf("expected routes to be kept when source fails, but got %v", routed.links)
+	}
+}
@@ -0,0 +1,243 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+// DefaultTenant арендатор для проверок без email автора
+const DefaultTenant = "default"
+
+// TenantOf возвращает арендатора, к бюджету которого относится публикация, - домен email автора
+func TenantOf(authorEmail string) string {
+	if i := strings.LastIndex(authorEmail, "@"); i >= 0 && i < len(authorEmail)-1 {
+		return strings.ToLower(authorEmail[i+1:])
+	}
+	return DefaultTenant
+}
+
+// Throttle ограничивает скорость публикаций отдельно для каждого арендатора (token bucket).
+// Reserve всегда резервирует место и возвращает, через сколько публикацию можно выполнить,
+// поэтому отложенные сообщения получают равномерно распределенное время отправки.
+type Throttle struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewThrottle создает ограничитель на rate сообщений в секунду с допустимым всплеском burst.
+// rate <= 0 отключает ограничение.
+func NewThrottle(rate float64, burst int) *Throttle {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Throttle{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Enabled сообщает, ограничивается ли скорость публикаций
+func (t *Throttle) Enabled() bool {
+	return t != nil && t.rate > 0
+}
+
+// Reserve занимает одно место в бюджете арендатора и возвращает задержку до публикации
+func (t *Throttle) Reserve(tenant string, now time.Time) time.Duration {
+	if !t.Enabled() {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b := t.refill(tenant, now)
+
+	// Бюджет может уйти в минус: долг определяет, когда подойдет очередь следующего сообщения
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / t.rate * float64(time.Second))
+}
+
+// Allow занимает место в бюджете арендатора, только если оно есть сейчас. В отличие от Reserve
+// бюджет не уходит в минус, поэтому сообщения, зарезервированные раньше, не теряют очередь.
+func (t *Throttle) Allow(tenant string, now time.Time) bool {
+	if !t.Enabled() {
+		return true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b := t.refill(tenant, now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// refill пополняет бюджет арендатора за время с прошлого обращения. Вызывается под t.mu.
+func (t *Throttle) refill(tenant string, now time.Time) *bucket {
+	b, ok := t.buckets[tenant]
+	if !ok {
+		b = &bucket{tokens: t.burst, last: now}
+		t.buckets[tenant] = b
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(t.burst, b.tokens+elapsed.Seconds()*t.rate)
+		b.last = now
+	}
+	return b
+}
+
+// OutboxMessage отложенная публикация запроса на проверку. Payload - CreateVerificationMessage
+// в том виде, в котором он уйдет воркерам.
+type OutboxMessage struct {
+	ID          string
+	Tenant      string
+	Priority    Priority
+	Payload     json.RawMessage
+	AvailableAt time.Time
+	Attempts    int
+	// Held удержан лимитом одновременных проверок арендатора до освобождения места
+	Held bool
+	// CreatedAt заполняется только при чтении ожидающего сообщения
+	CreatedAt time.Time
+}
+
+// NewOutboxMessage готовит публикацию запроса на проверку, отложенную до availableAt
+func NewOutboxMessage(verification *domain.Verification, priority Priority, availableAt time.Time) (*OutboxMessage, error) {
+	payload, err := json.Marshal(newCreateVerificationMessage(verification, priority))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal verification for outbox: %w", err)
+	}
+	return &OutboxMessage{
+		ID:          verification.ID,
+		Tenant:      TenantOf(verification.AuthorEmail),
+		Priority:    priority,
+		Payload:     payload,
+		AvailableAt: availableAt,
+	}, nil
+}
+
+// Verification восстанавливает проверку из сохраненного сообщения
+func (m *OutboxMessage) Verification() (*domain.Verification, error) {
+	var msg CreateVerificationMessage
+	if err := json.Unmarshal(m.Payload, &msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal outbox payload: %w", err)
+	}
+	verification := &domain.Verification{
+		ID:                 msg.VerificationID,
+		INN:                msg.INN,
+		Status:             domain.StatusInProcess,
+		AuthorEmail:        msg.AuthorEmail,
+		RequestedDataTypes: msg.RequestedTypes,
+		ReviewState:        domain.ReviewStateUnreviewed,
+	}
+	if msg.ExternalRef != nil {
+		verification.ExternalRef = &domain.ExternalRef{System: msg.ExternalRef.System, Ref: msg.ExternalRef.Ref}
+	}
+	return verification, nil
+}
+
+// Outbox хранилище публикаций, превысивших бюджет арендатора
+type Outbox interface {
+	// Enqueue сохраняет сообщение и возвращает его позицию среди неотправленных сообщений арендатора
+	Enqueue(ctx context.Context, msg *OutboxMessage) (int, error)
+}
+
+type throttledClient struct {
+	NATSClient
+	throttle *Throttle
+	outbox   Outbox
+	queued   bool
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewThrottledClient ограничивает скорость публикации запросов на проверку. Сообщения сверх
+// бюджета арендатора сохраняются в outbox с ожидаемым временем отправки, которое
+// возвращается в поле expectedStartAt проверки. При queuedAdmission отложенная проверка
+// дополнительно получает статус PENDING и позицию в очереди арендатора.
+func NewThrottledClient(client NATSClient, throttle *Throttle, outbox Outbox, queuedAdmission bool, logger *zap.Logger) NATSClient {
+	if !throttle.Enabled() {
+		return client
+	}
+	return &throttledClient{
+		NATSClient: client,
+		throttle:   throttle,
+		outbox:     outbox,
+		queued:     queuedAdmission,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+func (c *throttledClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
+	return c.PublishVerificationRequestWithPriority(ctx, verification, PriorityNormal)
+}
+
+func (c *throttledClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority Priority) error {
+	tenant := TenantOf(verification.AuthorEmail)
+	now := c.now()
+
+	delay := c.throttle.Reserve(tenant, now)
+	if delay == 0 {
+		return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+
+	payload, err := json.Marshal(newCreateVerificationMessage(verification, priority))
+	if err != nil {
+		return fmt.Errorf("failed to marshal verification for outbox: %w", err)
+	}
+
+	availableAt := now.Add(delay)
+	msg := &OutboxMessage{
+		ID:          verification.ID,
+		Tenant:      tenant,
+		Priority:    priority,
+		Payload:     payload,
+		AvailableAt: availableAt,
+	}
+	position, err := c.outbox.Enqueue(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue throttled publish: %w", err)
+	}
+
+	expected := availableAt.UTC()
+	verification.ExpectedStartAt = &expected
+	if c.queued {
+		verification.Status = domain.StatusPending
+		if position > 0 {
+			verification.QueuePosition = &position
+		}
+	}
+
+	metrics.NATSPublishesDeferred.WithLabelValues(tenant).Inc()
+	c.logger.Info("verification request deferred by publish throttle",
+		zap.String("verification_id", verification.ID),
+		zap.String("tenant", tenant),
+		zap.Duration("delay", delay),
+		zap.Int("queue_position", position))
+	return nil
+}
@@ -0,0 +1,177 @@
+package messaging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTenantOf(t *testing.T) {
+	tests := []struct {
+		email    string
+		expected string
+	}{
+		{email: "analyst@Bank.RU", expected: "bank.ru"},
+		{email: "monitoring@scoring.local", expected: "scoring.local"},
+		{email: "", expected: DefaultTenant},
+		{email: "broken@", expected: DefaultTenant},
+	}
+
+	for _, tt := range tests {
+		if got := TenantOf(tt.email); got != tt.expected {
+			t.Errorf("TenantOf(%q) = %q, expected %q", tt.email, got, tt.expected)
+		}
+	}
+}
+
+func TestThrottleReserve(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	t.Run("burst_then_spaced", func(t *testing.T) {
+		throttle := NewThrottle(2, 2)
+
+		expected := []time.Duration{0, 0, 500 * time.Millisecond, time.Second, 1500 * time.Millisecond}
+		for i, want := range expected {
+			if got := throttle.Reserve("bank.ru", now); got != want {
+				t.Errorf("reservation %d: expected delay %v, but got %v", i, want, got)
+			}
+		}
+	})
+
+	t.Run("budget_is_per_tenant", func(t *testing.T) {
+		throttle := NewThrottle(1, 1)
+
+		if got := throttle.Reserve("bank.ru", now); got != 0 {
+			t.Errorf("expected no delay, but got %v", got)
+		}
+		if got := throttle.Reserve("bank.ru", now); got != time.Second {
+			t.Errorf("expected 1s delay, but got %v", got)
+		}
+		if got := throttle.Reserve("leasing.ru", now); got != 0 {
+			t.Errorf("expected other tenant not to be delayed, but got %v", got)
+		}
+	})
+
+	t.Run("refills_over_time", func(t *testing.T) {
+		throttle := NewThrottle(1, 1)
+
+		throttle.Reserve("bank.ru", now)
+		if got := throttle.Reserve("bank.ru", now.Add(time.Second)); got != 0 {
+			t.Errorf("expected budget to refill, but got delay %v", got)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		throttle := NewThrottle(0, 1)
+
+		for i := 0; i < 10; i++ {
+			if got := throttle.Reserve("bank.ru", now); got != 0 {
+				t.Fatalf("expected disabled throttle not to delay, but got %v", got)
+			}
+		}
+	})
+}
+
+type recordingClient struct {
+	NATSClient
+	published []string
+}
+
+func (c *recordingClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority Priority) error {
+	c.published = append(c.published, verification.ID)
+	return nil
+}
+
+type memoryOutbox struct {
+	messages []*OutboxMessage
+}
+
+func (o *memoryOutbox) Enqueue(ctx context.Context, msg *OutboxMessage) (int, error) {
+	o.messages = append(o.messages, msg)
+	return len(o.messages), nil
+}
+
+func TestThrottledClient(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	inner := &recordingClient{}
+	outbox := &memoryOutbox{}
+
+	client := NewThrottledClient(inner, NewThrottle(1, 1), outbox, false, zaptest.NewLogger(t)).(*throttledClient)
+	client.now = func() time.Time { return now }
+
+	first := &domain.Verification{ID: "v1", INN: "7707083893", AuthorEmail: "analyst@bank.ru"}
+	second := &domain.Verification{ID: "v2", INN: "7707083893", AuthorEmail: "analyst@bank.ru", RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation}}
+
+	if err := client.PublishVerificationRequest(context.Background(), first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.PublishVerificationRequestWithPriority(context.Background(), second, PriorityHigh); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(inner.published) != 1 || inner.published[0] != "v1" {
+		t.Errorf("expected only v1 to be published immediately, but got %v", inner.published)
+	}
+	if first.ExpectedStartAt != nil {
+		t.Errorf("expected no expected start for immediate publish, but got %v", *first.ExpectedStartAt)
+	}
+
+	if len(outbox.messages) != 1 {
+		t.Fatalf("expected 1 outbox message, but got %d", len(outbox.messages))
+	}
+	msg := outbox.messages[0]
+	if msg.ID != "v2" || msg.Tenant != "bank.ru" || msg.Priority != PriorityHigh {
+		t.Errorf("unexpected outbox message: %+v", msg)
+	}
+	if !msg.AvailableAt.Equal(now.Add(time.Second)) {
+		t.Errorf("expected available at %v, but got %v", now.Add(time.Second), msg.AvailableAt)
+	}
+	if second.ExpectedStartAt == nil || !second.ExpectedStartAt.Equal(now.Add(time.Second)) {
+		t.Errorf("expected start %v, but got %v", now.Add(time.Second), second.ExpectedStartAt)
+	}
+	if second.Status == domain.StatusPending || second.QueuePosition != nil {
+		t.Errorf("expected no queued admission fields without the mode, but got %s, %v", second.Status, second.QueuePosition)
+	}
+
+	restored, err := msg.Verification()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if restored.ID != "v2" || len(restored.RequestedDataTypes) != 1 {
+		t.Errorf("unexpected restored verification: %+v", restored)
+	}
+}
+
+func TestThrottledClientQueuedAdmission(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	inner := &recordingClient{}
+	outbox := &memoryOutbox{}
+
+	client := NewThrottledClient(inner, NewThrottle(1, 1), outbox, true, zaptest.NewLogger(t)).(*throttledClient)
+	client.now = func() time.Time { return now }
+
+	var verifications []*domain.Verification
+	for _, id := range []string{"v1", "v2", "v3"} {
+		verification := &domain.Verification{ID: id, INN: "7707083893", Status: domain.StatusInProcess, AuthorEmail: "analyst@bank.ru"}
+		if err := client.PublishVerificationRequest(context.Background(), verification); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		verifications = append(verifications, verification)
+	}
+
+	if verifications[0].Status != domain.StatusInProcess || verifications[0].QueuePosition != nil {
+		t.Errorf("expected v1 to be published immediately, but got %s, %v", verifications[0].Status, verifications[0].QueuePosition)
+	}
+	for i, verification := range verifications[1:] {
+		if verification.Status != domain.StatusPending {
+			t.Errorf("%s: expected PENDING, but got %s", verification.ID, verification.Status)
+		}
+		if verification.QueuePosition == nil || *verification.QueuePosition != i+1 {
+			t.Errorf("%s: expected queue position %d, but got %v", verification.ID, i+1, verification.QueuePosition)
+		}
+	}
+}
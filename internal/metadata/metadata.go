@@ -0,0 +1,79 @@
+// Package metadata проверяет метаданные проверок - пары ключ-значение, которые интеграторы
+// сохраняют вместе с проверкой: номера заявок, коды филиалов, признаки маршрута.
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"unicode/utf8"
+)
+
+const (
+	// MaxKeys наибольшее число ключей метаданных проверки
+	MaxKeys = 50
+	// MaxKeyLength наибольшая длина ключа в символах
+	MaxKeyLength = 64
+	// MaxValueLength наибольшая длина значения в символах
+	MaxValueLength = 512
+	// MaxBytes наибольший размер метаданных в виде JSON
+	MaxBytes = 8192
+)
+
+// keyPattern ключ начинается с буквы и состоит из латинских букв, цифр, '_', '.' и '-'
+var keyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+
+// ValidateKey проверяет ключ метаданных
+func ValidateKey(key string) error {
+	if !keyPattern.MatchString(key) {
+		return fmt.Errorf("invalid metadata key %q: must start with a letter and contain only letters, digits, '_', '.' and '-'", key)
+	}
+	if utf8.RuneCountInString(key) > MaxKeyLength {
+		return fmt.Errorf("metadata key %q must not exceed %d characters", key, MaxKeyLength)
+	}
+	return nil
+}
+
+// Validate проверяет ключи, длину значений, число ключей и размер метаданных
+func Validate(metadata map[string]string) error {
+	if len(metadata) > MaxKeys {
+		return fmt.Errorf("metadata must not contain more than %d keys, got %d", MaxKeys, len(metadata))
+	}
+	for _, key := range Keys(metadata) {
+		if err := ValidateKey(key); err != nil {
+			return err
+		}
+		if utf8.RuneCountInString(metadata[key]) > MaxValueLength {
+			return fmt.Errorf("value of metadata key %q must not exceed %d characters", key, MaxValueLength)
+		}
+	}
+	if size := Size(metadata); size > MaxBytes {
+		return fmt.Errorf("metadata must not exceed %d bytes, got %d", MaxBytes, size)
+	}
+	return nil
+}
+
+// Size размер метаданных в виде JSON в байтах
+func Size(metadata map[string]string) int {
+	encoded, _ := json.Marshal(metadata)
+	return len(encoded)
+}
+
+// Merge возвращает метаданные current с добавленными или замененными ключами set и без ключей
+// remove. current не меняется.
+func Merge(current, set map[string]string, remove []string) map[string]string {
+	merged := make(map[string]string, len(current)+len(set))
+	maps.Copy(merged, current)
+	maps.Copy(merged, set)
+	for _, key := range remove {
+		delete(merged, key)
+	}
+	return merged
+}
+
+// Keys ключи метаданных по возрастанию
+func Keys(metadata map[string]string) []string {
+	return slices.Sorted(maps.Keys(metadata))
+}
@@ -0,0 +1,63 @@
+package metadata
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tooMany := make(map[string]string)
+	for i := 0; i <= MaxKeys; i++ {
+		tooMany["key"+strconv.Itoa(i)] = "v"
+	}
+	tooLarge := make(map[string]string)
+	for i := 0; i < 20; i++ {
+		tooLarge["key"+strconv.Itoa(i)] = strings.Repeat("x", MaxValueLength)
+	}
+
+	tests := []struct {
+		name          string
+		metadata      map[string]string
+		expectedError string
+	}{
+		{name: "valid", metadata: map[string]string{"orderId": "A-42", "branch.code": "077", "workflow-hint": "fast_track"}},
+		{name: "empty", metadata: map[string]string{}},
+		{name: "key_starts_with_digit", metadata: map[string]string{"1c": "x"}, expectedError: `invalid metadata key "1c"`},
+		{name: "key_with_space", metadata: map[string]string{"order id": "x"}, expectedError: `invalid metadata key "order id"`},
+		{name: "long_key", metadata: map[string]string{"k" + strings.Repeat("e", MaxKeyLength): "x"}, expectedError: "must not exceed 64 characters"},
+		{name: "long_value", metadata: map[string]string{"comment": strings.Repeat("ж", MaxValueLength+1)}, expectedError: `value of metadata key "comment" must not exceed 512 characters`},
+		{name: "too_many_keys", metadata: tooMany, expectedError: "metadata must not contain more than 50 keys, got 51"},
+		{name: "too_large", metadata: tooLarge, expectedError: "metadata must not exceed 8192 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.metadata)
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Fatalf("expected error containing %q, but got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	current := map[string]string{"orderId": "A-1", "branch": "077"}
+	merged := Merge(current, map[string]string{"orderId": "A-2", "hint": "manual"}, []string{"branch", "missing"})
+
+	if len(merged) != 2 || merged["orderId"] != "A-2" || merged["hint"] != "manual" {
+		t.Errorf("unexpected merged metadata %v", merged)
+	}
+	if current["orderId"] != "A-1" || current["branch"] != "077" {
+		t.Errorf("expected current metadata to be kept, but got %v", current)
+	}
+	if keys := Keys(merged); strings.Join(keys, ",") != "hint,orderId" {
+		t.Errorf("unexpected keys %v", keys)
+	}
+}
@@ -0,0 +1,353 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "scoring_gateway"
+
+// latencyBuckets границы гистограмм времени выполнения проверок в секундах
+var latencyBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600, 7200, 21600, 86400}
+
+var (
+	NATSConnected = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "nats_connected",
+		Help:      "Whether the gateway is currently connected to a NATS server.",
+	})
+
+	NATSActiveServer = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "nats_active_server",
+		Help:      "NATS server the gateway is connected to (value is always 1).",
+	}, []string{"server"})
+
+	NATSReconnects = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "nats_reconnects_total",
+		Help:      "Number of reconnections to the NATS cluster.",
+	})
+
+	NATSPublishesDeferred = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "nats_publishes_deferred_total",
+		Help:      "Number of verification requests queued in the outbox by the publish throttle.",
+	}, []string{"tenant"})
+
+	TenantPublishesHeld = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tenant_publishes_held_total",
+		Help:      "Number of verification requests held in the outbox by the tenant concurrency limit.",
+	}, []string{"tenant"})
+
+	TenantPublishesReleased = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tenant_publishes_released_total",
+		Help:      "Number of held verification requests released as the tenant's verifications completed.",
+	}, []string{"tenant"})
+
+	NATSDegradedPublishes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "nats_degraded_publishes_total",
+		Help:      "Number of verification requests queued in the outbox or rejected while NATS was unavailable.",
+	}, []string{"outcome"})
+
+	WarehouseExportedChanges = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "warehouse_exported_changes_total",
+		Help:      "Number of change records accepted by the analytics warehouse sink.",
+	}, []string{"sink"})
+
+	WebhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Number of webhook requests about completed verifications and company changes, by payload template and outcome.",
+	}, []string{"template", "outcome"})
+
+	DeprecatedEnumValues = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "deprecated_enum_values_total",
+		Help:      "Number of deprecated enum values received from clients and replaced by the gateway.",
+	}, []string{"enum", "value"})
+
+	RowScanFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "row_scan_failures_total",
+		Help:      "Number of database rows skipped because they could not be scanned.",
+	}, []string{"entity"})
+
+	UnavailableVerificationData = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "unavailable_verification_data_total",
+		Help:      "Number of verification data payloads left out of a response because they could not be read from the cache.",
+	}, []string{"data_type"})
+
+	DataValidationFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "data_validation_failures_total",
+		Help:      "Number of delivered payloads that did not match the JSON Schema of their data type.",
+	}, []string{"data_type"})
+
+	DataSchemaVersionMismatches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "data_schema_version_mismatches_total",
+		Help:      "Number of delivered payloads in a provider schema version other than the one in the catalog, by data type and delivered version.",
+	}, []string{"data_type", "schema_version"})
+
+	// Границы от секунды до суток: поставщики вроде статистики арбитражных дел отвечают часами
+	VerificationFirstDataSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "verification_first_data_seconds",
+		Help:      "Time from verification creation to the first data of each requested type, observed on completion.",
+		Buckets:   latencyBuckets,
+	}, []string{"data_type"})
+
+	VerificationCompletionSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "verification_completion_seconds",
+		Help:      "Time from verification creation to completion, by requested data type.",
+		Buckets:   latencyBuckets,
+	}, []string{"data_type"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests by route pattern, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	WorkerQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "worker_queue_depth",
+		Help:      "Queue depth reported by the latest heartbeat of each worker.",
+	}, []string{"worker"})
+
+	BatchDispatches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "batch_dispatches_total",
+		Help:      "Number of background verification requests by destination: a worker subject or the shared queue.",
+	}, []string{"destination"})
+
+	GraphQLLimitRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "graphql_limit_rejections_total",
+		Help:      "Number of GraphQL requests rejected by query limits, by error code.",
+	}, []string{"code"})
+
+	UnregisteredOperations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "graphql_unregistered_operations_total",
+		Help:      "Number of API key requests with operations missing from the key's allow-list, by persisted operations mode.",
+	}, []string{"mode"})
+
+	GraphQLSubscriptionsActive = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "graphql_subscriptions_active",
+		Help:      "Number of GraphQL subscriptions currently served over WebSocket.",
+	})
+
+	GraphQLSubscriptionRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "graphql_subscription_rejections_total",
+		Help:      "Number of WebSocket connections and subscriptions refused, by reason: auth or limit.",
+	}, []string{"reason"})
+
+	CallerLockouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "caller_lockouts_total",
+		Help:      "Number of temporary blocks applied to callers sending repeated invalid requests, by prevailing failure kind.",
+	}, []string{"reason"})
+
+	SLOCreateAvailability = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "slo_create_availability_ratio",
+		Help:      "Share of createVerification calls without gateway errors over the rolling window.",
+	}, []string{"window"})
+
+	SLOCompletionP95Seconds = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "slo_completion_p95_seconds",
+		Help:      "95th percentile of time from verification creation to completion over the rolling window.",
+	}, []string{"window"})
+
+	SLODeliverySuccessRatio = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "slo_delivery_success_ratio",
+		Help:      "Share of successfully delivered completion notifications over the rolling window.",
+	}, []string{"window"})
+
+	VerificationExportRows = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "verification_export_rows_total",
+		Help:      "Number of verifications streamed to clients by verificationExport subscriptions.",
+	})
+
+	OutboxPending = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "outbox_pending",
+		Help:      "Number of deferred publishes waiting in the outbox.",
+	})
+
+	NegativeCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "negative_cache_lookups_total",
+		Help:      "Number of verification requests checked against the cache of INNs not found by providers.",
+	}, []string{"result"})
+
+	NegativeCacheRecorded = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "negative_cache_recorded_total",
+		Help:      "Number of INNs added to the cache after providers did not find the company.",
+	})
+
+	NegativeCacheEntries = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "negative_cache_entries",
+		Help:      "Number of unexpired INNs in the cache of companies not found by providers.",
+	})
+
+	CacheFreshnessLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_freshness_lookups_total",
+		Help:      "Number of requested data types checked against the company data cache, by freshness level.",
+	}, []string{"data_type", "level"})
+
+	CacheRefreshes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_refreshes_total",
+		Help:      "Number of background requests to providers for stale cached company data.",
+	}, []string{"data_type"})
+
+	DBRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_retries_total",
+		Help:      "Number of repository calls repeated after transient Postgres errors.",
+	}, []string{"operation"})
+
+	DBBreakerState = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "db_breaker_state",
+		Help:      "State of the Postgres circuit breaker: 0 - closed, 1 - half-open, 2 - open.",
+	})
+
+	DBBreakerRejections = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_breaker_rejections_total",
+		Help:      "Number of repository calls rejected without reaching Postgres while the circuit breaker is open.",
+	})
+
+	DBDegradedReads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_degraded_reads_total",
+		Help:      "Number of verification reads served while the primary database is unavailable, by source.",
+	}, []string{"source"})
+
+	InboundEventsRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "inbound_events_rejected_total",
+		Help:      "Number of verification completed events rejected before processing, by reason and action taken.",
+	}, []string{"reason", "action"})
+
+	EmailConfirmationHolds = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "email_confirmation_holds_total",
+		Help:      "Number of verification requests held until the author confirms their email.",
+	})
+
+	EmailConfirmationsConfirmed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "email_confirmations_confirmed_total",
+		Help:      "Number of author emails confirmed by following the emailed link.",
+	})
+
+	CompletionsApplied = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "completions_applied_total",
+		Help:      "Number of verification completed events applied, by path: live or drained from the parking stream.",
+	}, []string{"path"})
+
+	PayloadsInspected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "payloads_inspected_total",
+		Help:      "Number of provider payloads changed on ingest, by data type and outcome: normalized, oversized or rejected.",
+	}, []string{"data_type", "outcome"})
+
+	CompletionsParked = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "completions_parked_total",
+		Help:      "Number of verification completed events parked in JetStream over the drain rate.",
+	})
+
+	CompletionsParkedPending = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "completions_parked_pending",
+		Help:      "Number of parked verification completed events waiting to be drained.",
+	})
+
+	CompletionsDrainLagSeconds = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "completions_drain_lag_seconds",
+		Help:      "Time the last drained verification completed event spent parked.",
+	})
+
+	CompletionsOrderedQueued = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "completions_ordered_queued",
+		Help:      "Number of verification completed events waiting in per-verification ordered processing queues.",
+	})
+
+	ResidencyCrossRegionRefusals = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "residency_cross_region_refusals_total",
+		Help:      "Number of requests refused because the verification is stored outside the caller's region, by verification region.",
+	}, []string{"region"})
+
+	ReportJobsFinished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "report_jobs_finished_total",
+		Help:      "Number of report generation jobs finished, by format and status: SUCCEEDED or FAILED.",
+	}, []string{"format", "status"})
+
+	ReportGenerationSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "report_generation_seconds",
+		Help:      "Time from claiming a report job to storing the report.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"format"})
+
+	SchemaMigrationMirrorFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "schema_migration_mirror_failures_total",
+		Help:      "Number of verification writes not mirrored to the next schema version; the sync job repairs them.",
+	})
+
+	SchemaMigrationComparisons = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "schema_migration_comparisons_total",
+		Help:      "Number of verification reads compared with the next schema version, by result: match, mismatch, missing or error.",
+	}, []string{"result"})
+
+	SchemaMigrationFallbacks = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "schema_migration_fallbacks_total",
+		Help:      "Number of verifications read from the old schema after cutover because the next schema version did not have them yet.",
+	})
+
+	SchemaMigrationSynced = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "schema_migration_synced_total",
+		Help:      "Number of verifications copied to the next schema version by the sync job, by pass: changed or repaired.",
+	}, []string{"pass"})
+)
+
+// SetNATSActiveServer отмечает сервер NATS, к которому подключен шлюз
+func SetNATSActiveServer(server string) {
+	NATSActiveServer.Reset()
+	if server == "" {
+		NATSConnected.Set(0)
+		return
+	}
+	NATSConnected.Set(1)
+	NATSActiveServer.WithLabelValues(server).Set(1)
+}
@@ -0,0 +1,72 @@
+package migrations
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	createEnum   = regexp.MustCompile(`(?is)CREATE\s+TYPE\s+(\w+)\s+AS\s+ENUM\s*\(([^)]*)\)`)
+	addEnumValue = regexp.MustCompile(`(?i)ALTER\s+TYPE\s+(\w+)\s+ADD\s+VALUE\s+(?:IF\s+NOT\s+EXISTS\s+)?'([^']*)'`)
+	enumLabel    = regexp.MustCompile(`'([^']*)'`)
+)
+
+// EnumValues возвращает значения перечисления Postgres typeName после применения всех миграций:
+// созданные CREATE TYPE ... AS ENUM и добавленные ALTER TYPE ... ADD VALUE. nil - перечисление
+// миграциями не создается.
+func EnumValues(migrations []*Migration, typeName string) []string {
+	var values []string
+	for _, migration := range migrations {
+		sql := lineComment.ReplaceAllString(migration.Up, "")
+		for _, match := range createEnum.FindAllStringSubmatch(sql, -1) {
+			if !strings.EqualFold(match[1], typeName) {
+				continue
+			}
+			values = []string{}
+			for _, label := range enumLabel.FindAllStringSubmatch(match[2], -1) {
+				values = append(values, label[1])
+			}
+		}
+		for _, match := range addEnumValue.FindAllStringSubmatch(sql, -1) {
+			if strings.EqualFold(match[1], typeName) && values != nil && !slices.Contains(values, match[2]) {
+				values = append(values, match[2])
+			}
+		}
+	}
+	return values
+}
+
+// MissingEnumValues возвращает значения перечисления Go, которых нет в перечислении Postgres
+func MissingEnumValues(migrations []*Migration, typeName string, goValues []string) []string {
+	existing := EnumValues(migrations, typeName)
+	var missing []string
+	for _, value := range goValues {
+		if !slices.Contains(existing, value) {
+			missing = append(missing, value)
+		}
+	}
+	return missing
+}
+
+// EnumMigration возвращает миграцию version, добавляющую values в перечисление Postgres typeName.
+// Удалить значение из перечисления Postgres нельзя, поэтому откат ничего не делает.
+func EnumMigration(version int, typeName string, values []string) *Migration {
+	var up strings.Builder
+	fmt.Fprintf(&up, "-- Migration %03d: Add new values of the Go enum to %s\n", version, typeName)
+	fmt.Fprintf(&up, "-- Generated by cmd/enummigrate\n\n")
+	for _, value := range values {
+		fmt.Fprintf(&up, "ALTER TYPE %s ADD VALUE IF NOT EXISTS '%s';\n", typeName, value)
+	}
+
+	down := fmt.Sprintf("-- Migration %03d down: Nothing to do\n"+
+		"-- Postgres cannot drop enum values; rows must stop using a value before it is removed from Go\n", version)
+
+	return &Migration{
+		Version: version,
+		Name:    "add_" + strings.ToLower(typeName) + "_values",
+		Up:      up.String(),
+		Down:    down,
+	}
+}
@@ -0,0 +1,210 @@
+// Package migrations применяет и откатывает SQL-миграции базы.
+//
+// Каждая миграция - пара файлов NNN_name.up.sql и NNN_name.down.sql. Примененные версии
+// хранятся в таблице schema_migrations, поэтому при запуске выполняются только новые миграции.
+package migrations
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Migration пара скриптов одной версии схемы
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// ID имя миграции в виде NNN_name, как у файлов
+func (m *Migration) ID() string {
+	return fmt.Sprintf("%03d_%s", m.Version, m.Name)
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Load читает миграции из каталога и проверяет, что у каждой версии есть оба скрипта.
+// Миграции возвращаются по возрастанию версии.
+func Load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s must be named NNN_name.up.sql or NNN_name.down.sql", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration file %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has files with different names: %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %s has no up script", migration.ID())
+		}
+		if migration.Down == "" {
+			return nil, fmt.Errorf("migration %s has no down script", migration.ID())
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Direction направление выполнения миграции
+type Direction string
+
+const (
+	Up   Direction = "up"
+	Down Direction = "down"
+)
+
+// Step миграция и направление, в котором ее нужно выполнить
+type Step struct {
+	Migration *Migration
+	Direction Direction
+}
+
+// SQL текст скрипта шага
+func (s Step) SQL() string {
+	if s.Direction == Up {
+		return s.Migration.Up
+	}
+	return s.Migration.Down
+}
+
+// PlanUp шаги применения всех непримененных миграций
+func PlanUp(migrations []*Migration, applied map[int]bool) []Step {
+	var steps []Step
+	for _, migration := range migrations {
+		if !applied[migration.Version] {
+			steps = append(steps, Step{Migration: migration, Direction: Up})
+		}
+	}
+	return steps
+}
+
+// PlanDown шаги отката n последних примененных миграций, начиная с последней
+func PlanDown(migrations []*Migration, applied map[int]bool, n int) ([]Step, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("number of migrations to roll back must be positive, got %d", n)
+	}
+
+	var steps []Step
+	for i := len(migrations) - 1; i >= 0 && len(steps) < n; i-- {
+		if applied[migrations[i].Version] {
+			steps = append(steps, Step{Migration: migrations[i], Direction: Down})
+		}
+	}
+	if len(steps) < n {
+		return nil, fmt.Errorf("only %d applied migrations can be rolled back, requested %d", len(steps), n)
+	}
+	return steps, nil
+}
+
+// PlanTo шаги перехода к версии target: применение непримененных миграций до target включительно
+// и откат примененных миграций после нее. target 0 откатывает все миграции.
+func PlanTo(migrations []*Migration, applied map[int]bool, target int) ([]Step, error) {
+	if target != 0 && !hasVersion(migrations, target) {
+		return nil, fmt.Errorf("unknown migration version %d", target)
+	}
+
+	var steps []Step
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if migrations[i].Version > target && applied[migrations[i].Version] {
+			steps = append(steps, Step{Migration: migrations[i], Direction: Down})
+		}
+	}
+	for _, migration := range migrations {
+		if migration.Version <= target && !applied[migration.Version] {
+			steps = append(steps, Step{Migration: migration, Direction: Up})
+		}
+	}
+	return steps, nil
+}
+
+func hasVersion(migrations []*Migration, version int) bool {
+	for _, migration := range migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Операторы, после которых данные нельзя восстановить откатом в обратную сторону.
+// Удаление индексов, ограничений, функций, триггеров и материализованных представлений
+// к ним не относится: все это воссоздается повторным применением миграции.
+var destructiveStatement = regexp.MustCompile(`(?i)\b(DROP\s+(TABLE|COLUMN|SCHEMA|DATABASE)|TRUNCATE|DELETE\s+FROM)\b`)
+
+var lineComment = regexp.MustCompile(`--[^\n]*`)
+
+// Destructive возвращает операторы скрипта, удаляющие данные
+func Destructive(sql string) []string {
+	var statements []string
+	for _, statement := range strings.Split(lineComment.ReplaceAllString(sql, ""), ";") {
+		statement = strings.Join(strings.Fields(statement), " ")
+		if statement != "" && destructiveStatement.MatchString(statement) {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
+
+// Guard отказывает в откате, скрипты которого удаляют данные, если база production и
+// выполнение не подтверждено флагом force
+func Guard(steps []Step, production, force bool) error {
+	if !production || force {
+		return nil
+	}
+
+	var destructive []string
+	for _, step := range steps {
+		if step.Direction != Down {
+			continue
+		}
+		for _, statement := range Destructive(step.SQL()) {
+			destructive = append(destructive, step.Migration.ID()+": "+statement)
+		}
+	}
+	if len(destructive) > 0 {
+		return fmt.Errorf("refusing destructive down-migrations in production without force:\n  %s", strings.Join(destructive, "\n  "))
+	}
+	return nil
+}
+
+// empty сообщает, что в скрипте нет ничего, кроме комментариев
+func empty(sql string) bool {
+	return strings.TrimSpace(lineComment.ReplaceAllString(sql, "")) == ""
+}
@@ -0,0 +1,160 @@
+package migrations
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func testMigrations(t *testing.T) []*Migration {
+	t.Helper()
+	migrations, err := Load(fstest.MapFS{
+		"001_init.up.sql":         {Data: []byte("CREATE TABLE a (id INT);")},
+		"001_init.down.sql":       {Data: []byte("DROP TABLE a;")},
+		"002_add_index.up.sql":    {Data: []byte("CREATE INDEX idx_a ON a(id);")},
+		"002_add_index.down.sql":  {Data: []byte("DROP INDEX idx_a;")},
+		"003_add_column.up.sql":   {Data: []byte("ALTER TABLE a ADD COLUMN b INT;")},
+		"003_add_column.down.sql": {Data: []byte("-- data in b is lost\nALTER TABLE a DROP COLUMN IF EXISTS b;")},
+		"README.md":               {Data: []byte("not a migration")},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return migrations
+}
+
+func stepIDs(steps []Step) string {
+	ids := make([]string, 0, len(steps))
+	for _, step := range steps {
+		ids = append(ids, step.Migration.ID()+"."+string(step.Direction))
+	}
+	return strings.Join(ids, " ")
+}
+
+func TestLoad(t *testing.T) {
+	migrations := testMigrations(t)
+	if len(migrations) != 3 || migrations[0].ID() != "001_init" || migrations[2].Down == "" {
+		t.Fatalf("unexpected migrations %+v", migrations)
+	}
+
+	tests := []struct {
+		name          string
+		files         fstest.MapFS
+		expectedError string
+	}{
+		{name: "missing_down", files: fstest.MapFS{"001_init.up.sql": {Data: []byte("SELECT 1;")}}, expectedError: "001_init has no down script"},
+		{name: "unpaired_name", files: fstest.MapFS{"001_init.up.sql": {Data: []byte("SELECT 1;")}, "001_other.down.sql": {Data: []byte("SELECT 1;")}}, expectedError: "different names"},
+		{name: "legacy_name", files: fstest.MapFS{"001_init.sql": {Data: []byte("SELECT 1;")}}, expectedError: "must be named"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.files)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Fatalf("expected error containing %q, but got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	migrations := testMigrations(t)
+	applied := map[int]bool{1: true, 2: true}
+
+	if ids := stepIDs(PlanUp(migrations, applied)); ids != "003_add_column.up" {
+		t.Errorf("unexpected up plan %q", ids)
+	}
+
+	steps, err := PlanDown(migrations, applied, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids := stepIDs(steps); ids != "002_add_index.down 001_init.down" {
+		t.Errorf("unexpected down plan %q", ids)
+	}
+	if _, err := PlanDown(migrations, applied, 3); err == nil {
+		t.Error("expected error when rolling back more migrations than applied")
+	}
+
+	steps, err = PlanTo(migrations, map[int]bool{1: true, 3: true}, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids := stepIDs(steps); ids != "003_add_column.down 002_add_index.up" {
+		t.Errorf("unexpected plan to 2 %q", ids)
+	}
+	if _, err := PlanTo(migrations, applied, 7); err == nil {
+		t.Error("expected error for an unknown version")
+	}
+}
+
+func TestGuard(t *testing.T) {
+	migrations := testMigrations(t)
+	dropIndex := []Step{{Migration: migrations[1], Direction: Down}}
+	dropColumn := []Step{{Migration: migrations[2], Direction: Down}, {Migration: migrations[1], Direction: Down}}
+
+	if err := Guard(dropIndex, true, false); err != nil {
+		t.Errorf("expected index drop to be allowed, but got %v", err)
+	}
+	err := Guard(dropColumn, true, false)
+	if err == nil || !strings.Contains(err.Error(), "003_add_column: ALTER TABLE a DROP COLUMN IF EXISTS b") {
+		t.Errorf("expected column drop to be refused, but got %v", err)
+	}
+	if err := Guard(dropColumn, true, true); err != nil {
+		t.Errorf("expected force to allow the rollback, but got %v", err)
+	}
+	if err := Guard(dropColumn, false, false); err != nil {
+		t.Errorf("expected rollback outside production to be allowed, but got %v", err)
+	}
+}
+
+// Миграции репозитория должны загружаться: у каждой версии есть пара up и down
+func TestRepositoryMigrations(t *testing.T) {
+	migrations, err := Load(os.DirFS("../../migrations"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			t.Fatalf("expected migration versions without gaps, but %s follows version %d", migration.ID(), i)
+		}
+	}
+}
+
+func TestEnumValues(t *testing.T) {
+	migrations, err := Load(fstest.MapFS{
+		"001_init.up.sql":       {Data: []byte("CREATE TYPE color AS ENUM ('RED', 'GREEN');\n-- ALTER TYPE color ADD VALUE 'COMMENTED';")},
+		"001_init.down.sql":     {Data: []byte("DROP TYPE color;")},
+		"002_add_blue.up.sql":   {Data: []byte("ALTER TYPE color ADD VALUE IF NOT EXISTS 'BLUE';")},
+		"002_add_blue.down.sql": {Data: []byte("-- Nothing to do")},
+		"003_other.up.sql":      {Data: []byte("ALTER TYPE shape ADD VALUE 'SQUARE';")},
+		"003_other.down.sql":    {Data: []byte("-- Nothing to do")},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if values := strings.Join(EnumValues(migrations, "color"), ","); values != "RED,GREEN,BLUE" {
+		t.Errorf("expected RED,GREEN,BLUE, but got %s", values)
+	}
+	if values := EnumValues(migrations, "shape"); values != nil {
+		t.Errorf("expected enum without CREATE TYPE to be unknown, but got %v", values)
+	}
+
+	missing := MissingEnumValues(migrations, "color", []string{"RED", "BLUE", "BLACK"})
+	if len(missing) != 1 || missing[0] != "BLACK" {
+		t.Fatalf("expected BLACK to be missing, but got %v", missing)
+	}
+
+	generated := EnumMigration(4, "color", missing)
+	if generated.ID() != "004_add_color_values" || !strings.Contains(generated.Up, "ALTER TYPE color ADD VALUE IF NOT EXISTS 'BLACK';") {
+		t.Errorf("unexpected generated migration %s:\n%s", generated.ID(), generated.Up)
+	}
+	if len(Destructive(generated.Down)) != 0 {
+		t.Errorf("expected generated rollback to keep data, but got %s", generated.Down)
+	}
+	if missing := MissingEnumValues(append(migrations, generated), "color", []string{"RED", "BLUE", "BLACK"}); len(missing) != 0 {
+		t.Errorf("expected generated migration to add all values, but %v are missing", missing)
+	}
+}
@@ -0,0 +1,127 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// lockKey ключ advisory-блокировки, под которой экземпляры шлюза выполняют миграции по очереди
+const lockKey = 7412003
+
+// Migrator выполняет шаги миграций и ведет таблицу schema_migrations.
+// В базе, где миграции применялись до появления таблицы, при первом запуске все скрипты
+// выполняются повторно: скрипты up написаны так, чтобы это было безопасно.
+type Migrator struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewMigrator(db *pgxpool.Pool, logger *zap.Logger) *Migrator {
+	return &Migrator{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Applied возвращает версии примененных миграций
+func (m *Migrator) Applied(ctx context.Context) (map[int]bool, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.Query(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	versions, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	applied := make(map[int]bool, len(versions))
+	for _, version := range versions {
+		applied[version] = true
+	}
+	return applied, nil
+}
+
+// Up применяет все непримененные миграции. Экземпляры, запущенные одновременно,
+// дожидаются друг друга и не выполняют одну миграцию дважды.
+func (m *Migrator) Up(ctx context.Context, migrations []*Migration) error {
+	return m.locked(ctx, func() error {
+		applied, err := m.Applied(ctx)
+		if err != nil {
+			return err
+		}
+		return m.apply(ctx, PlanUp(migrations, applied))
+	})
+}
+
+// Apply выполняет шаги по порядку. Каждый шаг выполняется в отдельной транзакции вместе
+// с записью в schema_migrations, поэтому прерванный шаг не оставляет схему наполовину измененной.
+func (m *Migrator) Apply(ctx context.Context, steps []Step) error {
+	return m.locked(ctx, func() error {
+		return m.apply(ctx, steps)
+	})
+}
+
+func (m *Migrator) apply(ctx context.Context, steps []Step) error {
+	for _, step := range steps {
+		m.logger.Info("Running migration", zap.String("file", step.Migration.ID()), zap.String("direction", string(step.Direction)))
+
+		err := pgx.BeginFunc(ctx, m.db, func(tx pgx.Tx) error {
+			if !empty(step.SQL()) {
+				if _, err := tx.Exec(ctx, step.SQL()); err != nil {
+					return err
+				}
+			}
+			if step.Direction == Up {
+				_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2) ON CONFLICT (version) DO NOTHING`,
+					step.Migration.Version, step.Migration.Name)
+				return err
+			}
+			_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, step.Migration.Version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to execute migration %s %s: %w", step.Migration.ID(), step.Direction, err)
+		}
+
+		m.logger.Info("Migration completed", zap.String("file", step.Migration.ID()), zap.String("direction", string(step.Direction)))
+	}
+	return nil
+}
+
+// locked выполняет fn под advisory-блокировкой миграций
+func (m *Migrator) locked(ctx context.Context, fn func() error) error {
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+
+	return fn()
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)
+	`
+	if _, err := m.db.Exec(ctx, query); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// Job периодически повторяет проверки компаний, данные по которым устарели.
+// Компании с высоким уровнем риска отправляются в приоритетную очередь. Повторные проверки
+// отмечаются, чтобы по их завершении вебхуки получили изменения данных компании.
+type Job struct {
+	repo       repository.VerificationRepository
+	monitoring repository.MonitoringRepository
+	service    service.VerificationService
+	cfg        config.MonitoringConfig
+	logger     *zap.Logger
+}
+
+func NewJob(repo repository.VerificationRepository, monitoring repository.MonitoringRepository, service service.VerificationService, cfg config.MonitoringConfig, logger *zap.Logger) *Job {
+	return &Job{
+		repo:       repo,
+		monitoring: monitoring,
+		service:    service,
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+func (j *Job) Name() string {
+	return "monitoring"
+}
+
+func (j *Job) Run(ctx context.Context) error {
+	candidates, err := j.repo.GetMonitoringCandidates(ctx, time.Now().Add(-j.cfg.RecheckAfter), j.cfg.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get monitoring candidates: %w", err)
+	}
+
+	var published, highPriority, deferred int
+	var lastExpectedStart string
+	for _, check := range planBatch(candidates, j.cfg.MaxHighPriorityShare) {
+		verification, err := j.service.CreateVerificationWithPriority(ctx, check.candidate.INN, check.candidate.RequestedDataTypes, j.cfg.AuthorEmail, check.priority)
+		if err != nil {
+			j.logger.Error("failed to schedule re-verification", zap.Error(err), zap.String("inn", check.candidate.INN))
+			continue
+		}
+		published++
+		if err := j.monitoring.MarkMonitored(ctx, verification.ID); err != nil {
+			j.logger.Warn("failed to mark monitoring re-verification", zap.Error(err), zap.String("verification_id", verification.ID))
+		}
+		if check.priority == messaging.PriorityHigh {
+			highPriority++
+		}
+		// Запросы сверх бюджета публикаций уходят в outbox; RFC3339 в UTC сравнивается как строка
+		if verification.ExpectedStartAt != nil {
+			deferred++
+			if *verification.ExpectedStartAt > lastExpectedStart {
+				lastExpectedStart = *verification.ExpectedStartAt
+			}
+		}
+	}
+
+	fields := []zap.Field{
+		zap.Int("candidates", len(candidates)),
+		zap.Int("published", published),
+		zap.Int("high_priority", highPriority),
+	}
+	if deferred > 0 {
+		fields = append(fields, zap.Int("deferred", deferred), zap.String("expected_last_start_at", lastExpectedStart))
+	}
+	j.logger.Info("monitoring re-verifications scheduled", fields...)
+	return nil
+}
+
+type scheduledCheck struct {
+	candidate *repository.MonitoringCandidate
+	priority  messaging.Priority
+}
+
+// planBatch распределяет кандидатов по очередям. Компании с высоким риском идут первыми,
+// но в приоритетную очередь попадает не больше maxHighShare от размера пачки,
+// остальные компании отправляются фоновыми запросами (PriorityBatch). При ненулевой доле
+// в маленькой пачке приоритетной остается хотя бы одна проверка.
+func planBatch(candidates []*repository.MonitoringCandidate, maxHighShare float64) []scheduledCheck {
+	share := math.Max(0, math.Min(1, maxHighShare))
+	maxHigh := int(math.Floor(float64(len(candidates)) * share))
+	if maxHigh == 0 && share > 0 {
+		maxHigh = 1
+	}
+
+	var high, normal []scheduledCheck
+	for _, c := range candidates {
+		if isHighRisk(c) && len(high) < maxHigh {
+			high = append(high, scheduledCheck{candidate: c, priority: messaging.PriorityHigh})
+			continue
+		}
+		normal = append(normal, scheduledCheck{candidate: c, priority: messaging.PriorityBatch})
+	}
+
+	return append(high, normal...)
+}
+
+func isHighRisk(c *repository.MonitoringCandidate) bool {
+	return c.RiskLevel != nil && *c.RiskLevel == model.RiskLevelHigh
+}
@@ -0,0 +1,101 @@
+package monitoring
+
+import (
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+)
+
+func candidate(inn string, risk model.RiskLevel) *repository.MonitoringCandidate {
+	return &repository.MonitoringCandidate{INN: inn, RiskLevel: &risk}
+}
+
+func TestPlanBatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		candidates   []*repository.MonitoringCandidate
+		maxHighShare float64
+		expectedHigh []string
+	}{
+		{
+			name: "high_risk_within_share",
+			candidates: []*repository.MonitoringCandidate{
+				candidate("1111111111", model.RiskLevelHigh),
+				candidate("2222222222", model.RiskLevelLow),
+				candidate("3333333333", model.RiskLevelMedium),
+				candidate("4444444444", model.RiskLevelLow),
+			},
+			maxHighShare: 0.5,
+			expectedHigh: []string{"1111111111"},
+		},
+		{
+			name: "high_risk_capped_by_share",
+			candidates: []*repository.MonitoringCandidate{
+				candidate("1111111111", model.RiskLevelHigh),
+				candidate("2222222222", model.RiskLevelHigh),
+				candidate("3333333333", model.RiskLevelHigh),
+				candidate("4444444444", model.RiskLevelLow),
+			},
+			maxHighShare: 0.5,
+			expectedHigh: []string{"1111111111", "2222222222"},
+		},
+		{
+			name: "small_batch_keeps_one_high",
+			candidates: []*repository.MonitoringCandidate{
+				candidate("1111111111", model.RiskLevelLow),
+				candidate("2222222222", model.RiskLevelHigh),
+				candidate("3333333333", model.RiskLevelHigh),
+			},
+			maxHighShare: 0.3,
+			expectedHigh: []string{"2222222222"},
+		},
+		{
+			name: "zero_share_disables_priority_lane",
+			candidates: []*repository.MonitoringCandidate{
+				candidate("1111111111", model.RiskLevelHigh),
+			},
+			maxHighShare: 0,
+			expectedHigh: nil,
+		},
+		{
+			name: "unknown_risk_is_normal",
+			candidates: []*repository.MonitoringCandidate{
+				{INN: "1111111111"},
+			},
+			maxHighShare: 1,
+			expectedHigh: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planBatch(tt.candidates, tt.maxHighShare)
+
+			if len(plan) != len(tt.candidates) {
+				t.Fatalf("expected %d scheduled checks, but got %d", len(tt.candidates), len(plan))
+			}
+
+			var high []string
+			for i, check := range plan {
+				if check.priority != messaging.PriorityHigh {
+					continue
+				}
+				if i != len(high) {
+					t.Errorf("expected high priority checks to be scheduled first, got one at position %d", i)
+				}
+				high = append(high, check.candidate.INN)
+			}
+
+			if len(high) != len(tt.expectedHigh) {
+				t.Fatalf("expected high priority %v, but got %v", tt.expectedHigh, high)
+			}
+			for i := range high {
+				if high[i] != tt.expectedHigh[i] {
+					t.Errorf("expected high priority %v, but got %v", tt.expectedHigh, high)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package notfound
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+// Store хранилище ИНН, которые поставщики не нашли
+type Store interface {
+	LookupNotFound(ctx context.Context, inn string) (bool, error)
+	RememberNotFound(ctx context.Context, inn string, expiresAt time.Time) error
+	ForgetNotFound(ctx context.Context, inn string) error
+	SaveNotFound(ctx context.Context, verification *domain.Verification) (bool, error)
+	PruneNotFound(ctx context.Context) (int64, error)
+}
+
+type notFoundClient struct {
+	messaging.NATSClient
+	store  Store
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewClient перехватывает запросы проверок ИНН из кэша ненайденных: проверка сразу сохраняется
+// со статусом COMPANY_NOT_FOUND и не доходит до поставщиков. Остальные запросы передаются client
+// без изменений.
+func NewClient(client messaging.NATSClient, store Store, logger *zap.Logger) messaging.NATSClient {
+	return &notFoundClient{
+		NATSClient: client,
+		store:      store,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+func (c *notFoundClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
+	return c.PublishVerificationRequestWithPriority(ctx, verification, messaging.PriorityNormal)
+}
+
+func (c *notFoundClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	notFound, err := c.store.LookupNotFound(ctx, verification.INN)
+	if err != nil {
+		// Кэш только экономит обращения к поставщикам, поэтому его недоступность не мешает проверке
+		c.logger.Warn("negative cache unavailable, publishing verification", zap.Error(err), zap.String("inn", verification.INN))
+		notFound = false
+	}
+	if !notFound {
+		metrics.NegativeCacheLookups.WithLabelValues("miss").Inc()
+		return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+
+	now := c.now().UTC().Truncate(time.Second)
+	completed := *verification
+	completed.Status = domain.StatusCompanyNotFound
+	completed.MissingDataTypes = []domain.DataType{}
+	completed.CreatedAt = now
+	completed.UpdatedAt = now
+
+	saved, err := c.store.SaveNotFound(ctx, &completed)
+	if err != nil {
+		return fmt.Errorf("failed to save cached not found verification: %w", err)
+	}
+	// Повторный запрос данных уже существующей проверки кэш не завершает
+	if !saved {
+		metrics.NegativeCacheLookups.WithLabelValues("miss").Inc()
+		return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+
+	metrics.NegativeCacheLookups.WithLabelValues("hit").Inc()
+	*verification = completed
+	c.logger.Info("verification completed from negative cache",
+		zap.String("verification_id", verification.ID),
+		zap.String("inn", verification.INN))
+	return nil
+}
+
+// Recorder заполняет кэш по результатам проверок, выполненных поставщиками
+type Recorder struct {
+	store  Store
+	ttl    time.Duration
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+func NewRecorder(store Store, ttl time.Duration, logger *zap.Logger) *Recorder {
+	return &Recorder{store: store, ttl: ttl, logger: logger, now: time.Now}
+}
+
+// RecordCompletion запоминает ИНН проверки, завершенной статусом COMPANY_NOT_FOUND, на ttl.
+// Успешная проверка удаляет ИНН из кэша: компания могла появиться в реестрах раньше срока.
+func (r *Recorder) RecordCompletion(ctx context.Context, verification *domain.Verification) error {
+	if verification.Sandbox {
+		return nil
+	}
+
+	switch verification.Status {
+	case domain.StatusCompanyNotFound:
+		if err := r.store.RememberNotFound(ctx, verification.INN, r.now().Add(r.ttl)); err != nil {
+			return err
+		}
+		metrics.NegativeCacheRecorded.Inc()
+		r.logger.Info("inn added to negative cache",
+			zap.String("inn", verification.INN),
+			zap.Duration("ttl", r.ttl))
+	case domain.StatusCompleted, domain.StatusPartiallyCompleted:
+		return r.store.ForgetNotFound(ctx, verification.INN)
+	}
+	return nil
+}
+
+// CleanupJob удаляет истекшие записи кэша и обновляет число действующих
+type CleanupJob struct {
+	store Store
+}
+
+func NewCleanupJob(store Store) *CleanupJob {
+	return &CleanupJob{store: store}
+}
+
+func (j *CleanupJob) Name() string {
+	return "negative_cache_cleanup"
+}
+
+func (j *CleanupJob) Run(ctx context.Context) error {
+	active, err := j.store.PruneNotFound(ctx)
+	if err != nil {
+		return err
+	}
+	metrics.NegativeCacheEntries.Set(float64(active))
+	return nil
+}
@@ -0,0 +1,147 @@
+package notfound
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type recordingClient struct {
+	messaging.NATSClient
+	published []string
+}
+
+func (c *recordingClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	c.published = append(c.published, verification.ID)
+	return nil
+}
+
+type memoryStore struct {
+	expires map[string]time.Time
+	saved   map[string]*domain.Verification
+	err     error
+	now     time.Time
+}
+
+func newMemoryStore(now time.Time) *memoryStore {
+	return &memoryStore{expires: map[string]time.Time{}, saved: map[string]*domain.Verification{}, now: now}
+}
+
+func (s *memoryStore) LookupNotFound(ctx context.Context, inn string) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	expiresAt, ok := s.expires[inn]
+	return ok && expiresAt.After(s.now), nil
+}
+
+func (s *memoryStore) RememberNotFound(ctx context.Context, inn string, expiresAt time.Time) error {
+	s.expires[inn] = expiresAt
+	return nil
+}
+
+func (s *memoryStore) ForgetNotFound(ctx context.Context, inn string) error {
+	delete(s.expires, inn)
+	return nil
+}
+
+func (s *memoryStore) SaveNotFound(ctx context.Context, verification *domain.Verification) (bool, error) {
+	if _, ok := s.saved[verification.ID]; ok {
+		return false, nil
+	}
+	s.saved[verification.ID] = verification
+	return true, nil
+}
+
+func (s *memoryStore) PruneNotFound(ctx context.Context) (int64, error) {
+	for inn, expiresAt := range s.expires {
+		if !expiresAt.After(s.now) {
+			delete(s.expires, inn)
+		}
+	}
+	return int64(len(s.expires)), nil
+}
+
+func TestClientCompletesCachedINN(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	store := newMemoryStore(now)
+	next := &recordingClient{}
+	client := NewClient(next, store, zaptest.NewLogger(t))
+
+	recorder := NewRecorder(store, time.Hour, zaptest.NewLogger(t))
+	recorder.now = func() time.Time { return now }
+	if err := recorder.RecordCompletion(context.Background(), &domain.Verification{INN: "7700000001", Status: domain.StatusCompanyNotFound}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cached := &domain.Verification{ID: "v-1", INN: "7700000001", Status: domain.StatusInProcess}
+	if err := client.PublishVerificationRequest(context.Background(), cached); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cached.Status != domain.StatusCompanyNotFound || cached.CreatedAt.IsZero() {
+		t.Errorf("expected verification to finish as COMPANY_NOT_FOUND, but got %+v", cached)
+	}
+	if store.saved["v-1"] == nil || len(next.published) != 0 {
+		t.Errorf("expected cached verification to be saved without publishing, but published %v", next.published)
+	}
+
+	// Повторный запрос недостающих данных уже сохраненной проверки доходит до воркеров
+	if err := client.PublishVerificationRequest(context.Background(), &domain.Verification{ID: "v-1", INN: "7700000001"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.PublishVerificationRequest(context.Background(), &domain.Verification{ID: "v-2", INN: "7707083893"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(next.published) != 2 {
+		t.Errorf("expected retry and unknown INN to be published, but got %v", next.published)
+	}
+}
+
+func TestClientPublishesWhenStoreFails(t *testing.T) {
+	store := newMemoryStore(time.Now())
+	store.err = errors.New("connection refused")
+	next := &recordingClient{}
+
+	verification := &domain.Verification{ID: "v-1", INN: "7700000001", Status: domain.StatusInProcess}
+	if err := NewClient(next, store, zaptest.NewLogger(t)).PublishVerificationRequest(context.Background(), verification); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(next.published) != 1 || verification.Status != domain.StatusInProcess {
+		t.Errorf("expected verification to be published, but got %v", next.published)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	store := newMemoryStore(now)
+	recorder := NewRecorder(store, time.Hour, zaptest.NewLogger(t))
+	recorder.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	recorder.RecordCompletion(ctx, &domain.Verification{INN: "7700000001", Status: domain.StatusCompanyNotFound})
+	recorder.RecordCompletion(ctx, &domain.Verification{INN: "7700000002", Status: domain.StatusCompanyNotFound, Sandbox: true})
+	recorder.RecordCompletion(ctx, &domain.Verification{INN: "7700000003", Status: domain.StatusError})
+	if len(store.expires) != 1 || !store.expires["7700000001"].Equal(now.Add(time.Hour)) {
+		t.Errorf("expected only the real not found INN to be cached for an hour, but got %v", store.expires)
+	}
+
+	recorder.RecordCompletion(ctx, &domain.Verification{INN: "7700000001", Status: domain.StatusCompleted})
+	if len(store.expires) != 0 {
+		t.Errorf("expected found company to leave the cache, but got %v", store.expires)
+	}
+
+	store.expires["7700000004"] = now.Add(-time.Minute)
+	store.expires["7700000005"] = now.Add(time.Minute)
+	if err := NewCleanupJob(store).Run(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := store.expires["7700000004"]; ok || len(store.expires) != 1 {
+		t.Errorf("expected expired entry to be pruned, but got %v", store.expires)
+	}
+}
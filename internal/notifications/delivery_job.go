@@ -0,0 +1,25 @@
+package notifications
+
+import (
+	"context"
+
+	"scoring_api_gateway/internal/service"
+)
+
+// DeliveryJob доставляет уведомления о завершении проверок из заданий в базе
+type DeliveryJob struct {
+	service service.NotificationJobService
+}
+
+func NewDeliveryJob(service service.NotificationJobService) *DeliveryJob {
+	return &DeliveryJob{service: service}
+}
+
+func (j *DeliveryJob) Name() string {
+	return "notifications_delivery"
+}
+
+func (j *DeliveryJob) Run(ctx context.Context) error {
+	_, err := j.service.DeliverPending(ctx)
+	return err
+}
@@ -0,0 +1,29 @@
+package notifications
+
+import (
+	"context"
+	"time"
+
+	"scoring_api_gateway/internal/service"
+)
+
+// SLAJob периодически уведомляет авторов о проверках, не завершившихся вовремя
+type SLAJob struct {
+	service service.NotificationService
+	sla     time.Duration
+}
+
+func NewSLAJob(service service.NotificationService, sla time.Duration) *SLAJob {
+	return &SLAJob{
+		service: service,
+		sla:     sla,
+	}
+}
+
+func (j *SLAJob) Name() string {
+	return "notifications_sla"
+}
+
+func (j *SLAJob) Run(ctx context.Context) error {
+	return j.service.NotifySLABreaches(ctx, j.sla)
+}
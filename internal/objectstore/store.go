@@ -0,0 +1,103 @@
+// Package objectstore сохраняет объекты в S3-совместимое хранилище запросами, подписанными
+// AWS Signature Version 4.
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/internal/config"
+)
+
+// Store бакет объектного хранилища
+type Store struct {
+	cfg    config.S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewStore(cfg config.S3Config) *Store {
+	return &Store{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		now:    time.Now,
+	}
+}
+
+// Key имя объекта с префиксом из конфигурации
+func (s *Store) Key(name string) string {
+	if prefix := strings.Trim(s.cfg.Prefix, "/"); prefix != "" {
+		return prefix + "/" + name
+	}
+	return name
+}
+
+// Location адрес объекта вида s3://бакет/ключ
+func (s *Store) Location(key string) string {
+	return "s3://" + s.cfg.Bucket + "/" + key
+}
+
+// Put сохраняет объект под ключом key, существующий объект перезаписывается
+func (s *Store) Put(ctx context.Context, key, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.url(key), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create s3 request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	if err := s.do(req, body, http.StatusOK); err != nil {
+		return fmt.Errorf("failed to upload object %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete удаляет объект; отсутствующий объект не считается ошибкой
+func (s *Store) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.url(key), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create s3 request: %w", err)
+	}
+
+	if err := s.do(req, nil, http.StatusNoContent, http.StatusOK, http.StatusNotFound); err != nil {
+		return fmt.Errorf("failed to delete object %s: %w", key, err)
+	}
+	return nil
+}
+
+// PresignGet возвращает ссылку на скачивание объекта, действующую expires. Без ключа доступа
+// возвращается адрес объекта без подписи: бакет должен быть доступен на чтение.
+func (s *Store) PresignGet(key string, expires time.Duration) (string, error) {
+	if s.cfg.AccessKeyID == "" {
+		return s.url(key), nil
+	}
+	return PresignV4(http.MethodGet, s.url(key), s.cfg, s.now().UTC(), expires)
+}
+
+func (s *Store) url(key string) string {
+	return strings.TrimRight(s.cfg.Endpoint, "/") + "/" + s.cfg.Bucket + "/" + key
+}
+
+// do подписывает и выполняет запрос, ожидая один из статусов expected
+func (s *Store) do(req *http.Request, body []byte, expected ...int) error {
+	if s.cfg.AccessKeyID != "" {
+		SignV4(req, body, s.cfg, s.now().UTC())
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if !slices.Contains(expected, resp.StatusCode) {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("s3 returned %d: %s", resp.StatusCode, strings.TrimSpace(string(text)))
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Package outbox отправляет в NATS запросы, отложенные ограничением скорости публикации
+// или удержанные лимитом одновременных проверок арендатора.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// RelayJob публикует сообщения outbox, время отправки которых наступило.
+// Публикация идет напрямую в NATS: бюджет арендатора уже учтен при постановке в очередь.
+type RelayJob struct {
+	repo   repository.OutboxRepository
+	client messaging.NATSClient
+	cfg    config.OutboxConfig
+	logger *zap.Logger
+}
+
+func NewRelayJob(repo repository.OutboxRepository, client messaging.NATSClient, cfg config.OutboxConfig, logger *zap.Logger) *RelayJob {
+	return &RelayJob{
+		repo:   repo,
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (j *RelayJob) Name() string {
+	return "outbox_relay"
+}
+
+func (j *RelayJob) Run(ctx context.Context) error {
+	// Без подключения к NATS сообщения остаются в outbox до восстановления связи
+	if !j.client.Status().Connected {
+		return nil
+	}
+
+	messages, err := j.repo.ClaimDue(ctx, time.Now().Add(j.cfg.ClaimTimeout), j.cfg.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	var published int
+	for _, msg := range messages {
+		if err := j.publish(ctx, msg); err != nil {
+			j.logger.Error("failed to relay outbox message", zap.Error(err), zap.String("verification_id", msg.ID), zap.Int("attempts", msg.Attempts))
+			if err := j.repo.MarkFailed(ctx, msg.ID, err); err != nil {
+				j.logger.Error("failed to release outbox message", zap.Error(err), zap.String("verification_id", msg.ID))
+			}
+			continue
+		}
+		published++
+	}
+
+	if pending, err := j.repo.CountPending(ctx); err == nil {
+		metrics.OutboxPending.Set(float64(pending))
+	}
+
+	if len(messages) > 0 {
+		j.logger.Info("outbox messages relayed", zap.Int("claimed", len(messages)), zap.Int("published", published))
+	}
+	return nil
+}
+
+func (j *RelayJob) publish(ctx context.Context, msg *messaging.OutboxMessage) error {
+	verification, err := msg.Verification()
+	if err != nil {
+		return err
+	}
+	if err := j.client.PublishVerificationRequestWithPriority(ctx, verification, msg.Priority); err != nil {
+		return err
+	}
+	return j.repo.MarkPublished(ctx, msg.ID)
+}
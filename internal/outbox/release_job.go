@@ -0,0 +1,45 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// ReleaseJob отпускает запросы, удержанные лимитом одновременных проверок арендатора, когда
+// его проверки завершаются. Отпущенные сообщения отправляет задача outbox_relay.
+type ReleaseJob struct {
+	repo         repository.OutboxRepository
+	defaultLimit int
+	batchSize    int
+	logger       *zap.Logger
+}
+
+func NewReleaseJob(repo repository.OutboxRepository, defaultLimit, batchSize int, logger *zap.Logger) *ReleaseJob {
+	return &ReleaseJob{
+		repo:         repo,
+		defaultLimit: defaultLimit,
+		batchSize:    batchSize,
+		logger:       logger,
+	}
+}
+
+func (j *ReleaseJob) Name() string {
+	return "concurrency_release"
+}
+
+func (j *ReleaseJob) Run(ctx context.Context) error {
+	released, err := j.repo.ReleaseHeld(ctx, j.defaultLimit, j.batchSize)
+	for tenant, count := range released {
+		metrics.TenantPublishesReleased.WithLabelValues(tenant).Add(float64(count))
+		j.logger.Info("held verification requests released", zap.String("tenant", tenant), zap.Int("released", count))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to release held verification requests: %w", err)
+	}
+	return nil
+}
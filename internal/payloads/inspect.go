@@ -0,0 +1,203 @@
+// Package payloads проверяет данные поставщиков при поступлении: кодировку строк и размер документа.
+// Исправимые искажения кодировки исправляются, данные с потерянным текстом или двоичным мусором
+// отклоняются с описанием найденных проблем.
+package payloads
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Outcome результат проверки данных
+type Outcome string
+
+const (
+	// OutcomeValid данные сохраняются без изменений
+	OutcomeValid Outcome = "valid"
+	// OutcomeNormalized кодировка строк исправлена, сохраняется Result.Data
+	OutcomeNormalized Outcome = "normalized"
+	// OutcomeOversized данные больше предела типа данных
+	OutcomeOversized Outcome = "oversized"
+	// OutcomeRejected данные нельзя сохранить: это не JSON, текст потерян при декодировании
+	// или в строках двоичные данные
+	OutcomeRejected Outcome = "rejected"
+)
+
+// maxDiagnostics сколько проблем описывается для одного документа
+const maxDiagnostics = 20
+
+// mojibakeCharmaps кодировки, в которых UTF-8 чаще всего ошибочно декодируют поставщики:
+// "Ромашка" превращается в "Р РѕРјР°С€РєР°", "café" - в "cafÃ©"
+var mojibakeCharmaps = []*charmap.Charmap{charmap.Windows1251, charmap.Windows1252}
+
+// Result итог проверки. Data - данные для сохранения (исправленные при OutcomeNormalized),
+// Size - их размер в байтах, Diagnostics - исправленные и найденные проблемы с путем к строке.
+type Result struct {
+	Outcome     Outcome
+	Data        string
+	Size        int
+	Diagnostics []string
+}
+
+// Inspect проверяет документ и исправляет кодировку строк. maxBytes - предел размера
+// исправленного документа, 0 - без предела.
+func Inspect(data string, maxBytes int) Result {
+	if !utf8.ValidString(data) {
+		return rejected(data, fmt.Sprintf("invalid UTF-8 at byte %d", invalidOffset(data)))
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(data))
+	// Числа сохраняются как есть, без округления до float64
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return rejected(data, fmt.Sprintf("invalid JSON: %v", err))
+	}
+
+	inspection := &inspection{}
+	document = inspection.walk("$", document)
+	if len(inspection.problems) > 0 {
+		return rejected(data, inspection.problems...)
+	}
+
+	result := Result{Outcome: OutcomeValid, Data: data, Size: len(data)}
+	if len(inspection.fixes) > 0 {
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(document); err != nil {
+			return rejected(data, fmt.Sprintf("failed to encode normalized JSON: %v", err))
+		}
+		result.Outcome = OutcomeNormalized
+		result.Data = strings.TrimSuffix(buf.String(), "\n")
+		result.Size = len(result.Data)
+		result.Diagnostics = inspection.fixes
+	}
+
+	if maxBytes > 0 && result.Size > maxBytes {
+		result.Outcome = OutcomeOversized
+		result.Diagnostics = append(result.Diagnostics, fmt.Sprintf("payload of %d bytes exceeds the limit of %d bytes", result.Size, maxBytes))
+	}
+	return result
+}
+
+func rejected(data string, problems ...string) Result {
+	return Result{Outcome: OutcomeRejected, Data: data, Size: len(data), Diagnostics: problems}
+}
+
+// invalidOffset смещение первого байта, который не образует символ UTF-8
+func invalidOffset(s string) int {
+	for i, r := range s {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+// inspection обход документа: исправления и неисправимые проблемы строк
+type inspection struct {
+	fixes    []string
+	problems []string
+}
+
+func (in *inspection) walk(path string, value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, nested := range v {
+			fixedKey := in.text(path+"."+key+" (key)", key)
+			result[fixedKey] = in.walk(path+"."+key, nested)
+		}
+		return result
+	case []any:
+		for i, nested := range v {
+			v[i] = in.walk(path+"["+strconv.Itoa(i)+"]", nested)
+		}
+		return v
+	case string:
+		return in.text(path, v)
+	}
+	return value
+}
+
+// text проверяет строку и возвращает ее исправленный вариант
+func (in *inspection) text(path, s string) string {
+	if strings.ContainsRune(s, utf8.RuneError) {
+		in.problem(path + ": replacement character U+FFFD, the text was lost by a lossy decoding")
+		return s
+	}
+	for _, r := range s {
+		if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+			in.problem(fmt.Sprintf("%s: control character %U, the value looks like binary data", path, r))
+			return s
+		}
+	}
+
+	fixed := s
+	var applied []string
+	if trimmed := strings.TrimPrefix(fixed, "\uFEFF"); trimmed != fixed {
+		fixed = trimmed
+		applied = append(applied, "removed byte order mark")
+	}
+	if repaired, ok := repairMojibake(fixed); ok {
+		fixed = repaired
+		applied = append(applied, "repaired text decoded in a single-byte encoding")
+	}
+	if !norm.NFC.IsNormalString(fixed) {
+		fixed = norm.NFC.String(fixed)
+		applied = append(applied, "normalized to NFC")
+	}
+	if len(applied) > 0 {
+		in.fix(path + ": " + strings.Join(applied, ", "))
+	}
+	return fixed
+}
+
+func (in *inspection) fix(diagnostic string) {
+	if len(in.fixes) < maxDiagnostics {
+		in.fixes = append(in.fixes, diagnostic)
+	}
+}
+
+func (in *inspection) problem(diagnostic string) {
+	if len(in.problems) < maxDiagnostics {
+		in.problems = append(in.problems, diagnostic)
+	}
+}
+
+// repairMojibake восстанавливает текст UTF-8, который декодировали как однобайтовую кодировку.
+// Строка считается искаженной, только если ее байты в этой кодировке образуют корректный UTF-8
+// с многобайтовыми символами: в обычном тексте на русском или европейских языках так не бывает.
+func repairMojibake(s string) (string, bool) {
+	if isASCII(s) {
+		return s, false
+	}
+	for _, cm := range mojibakeCharmaps {
+		encoded, err := cm.NewEncoder().String(s)
+		if err != nil || encoded == s || !utf8.ValidString(encoded) {
+			continue
+		}
+		return encoded, true
+	}
+	return s, false
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
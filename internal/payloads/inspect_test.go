@@ -0,0 +1,136 @@
+package payloads
+
+import (
+	"strings"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+)
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		maxBytes    int
+		outcome     Outcome
+		expected    string
+		diagnostics []string
+	}{
+		{
+			name:     "valid",
+			data:     `{"name": "ООО «Ромашка»", "capital": 10000.50, "okved": ["62.01"]}`,
+			maxBytes: 1024,
+			outcome:  OutcomeValid,
+		},
+		{
+			name:        "cp1251_mojibake",
+			data:        "{\"name\": \"Р\u00a0РѕРјР°С€РєР°\", \"inn\": \"7707083893\", \"capital\": 12345678901234567890}",
+			outcome:     OutcomeNormalized,
+			expected:    `{"capital":12345678901234567890,"inn":"7707083893","name":"Ромашка"}`,
+			diagnostics: []string{"$.name: repaired text decoded in a single-byte encoding"},
+		},
+		{
+			name:        "latin1_mojibake_in_key",
+			data:        `{"cafÃ©": true}`,
+			outcome:     OutcomeNormalized,
+			expected:    `{"café":true}`,
+			diagnostics: []string{"$.cafÃ© (key): repaired text decoded in a single-byte encoding"},
+		},
+		{
+			name:        "decomposed_and_bom",
+			data:        "{\"address\": [\"\uFEFFул. Ёлочная\", \"Е\u0308жики\"]}",
+			outcome:     OutcomeNormalized,
+			expected:    `{"address":["ул. Ёлочная","Ёжики"]}`,
+			diagnostics: []string{"$.address[0]: removed byte order mark", "$.address[1]: normalized to NFC"},
+		},
+		{
+			name:        "invalid_utf8",
+			data:        "{\"name\": \"\xcf\xee\xeb\"}",
+			outcome:     OutcomeRejected,
+			diagnostics: []string{"invalid UTF-8 at byte 10"},
+		},
+		{
+			name:        "replacement_character",
+			data:        `{"director": {"name": "Иван�"}}`,
+			outcome:     OutcomeRejected,
+			diagnostics: []string{"$.director.name: replacement character U+FFFD, the text was lost by a lossy decoding"},
+		},
+		{
+			name:        "binary",
+			data:        `{"scan": "PK\u0003\u0004"}`,
+			outcome:     OutcomeRejected,
+			diagnostics: []string{"$.scan: control character U+0003, the value looks like binary data"},
+		},
+		{
+			name:        "not_json",
+			data:        `<html>`,
+			outcome:     OutcomeRejected,
+			diagnostics: []string{"invalid JSON: invalid character '<' looking for beginning of value"},
+		},
+		{
+			name:        "oversized",
+			data:        `{"cases": "` + strings.Repeat("x", 100) + `"}`,
+			maxBytes:    64,
+			outcome:     OutcomeOversized,
+			diagnostics: []string{"payload of 113 bytes exceeds the limit of 64 bytes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Inspect(tt.data, tt.maxBytes)
+			if result.Outcome != tt.outcome {
+				t.Fatalf("expected outcome %s, but got %s: %v", tt.outcome, result.Outcome, result.Diagnostics)
+			}
+			expected := tt.expected
+			if expected == "" {
+				expected = tt.data
+			}
+			if result.Data != expected || result.Size != len(expected) {
+				t.Errorf("expected data %s, but got %s (%d bytes)", expected, result.Data, result.Size)
+			}
+			if strings.Join(result.Diagnostics, "\n") != strings.Join(tt.diagnostics, "\n") {
+				t.Errorf("expected diagnostics %q, but got %q", tt.diagnostics, result.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestRepairMojibakeKeepsText(t *testing.T) {
+	for _, text := range []string{"Ромашка", "Café de Paris", "ЗАО «Ёлка» №1", "Müller & Söhne", "日本"} {
+		if repaired, ok := repairMojibake(text); ok {
+			t.Errorf("expected %q to be kept, but got %q", text, repaired)
+		}
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits(1024, []string{"ARBITRAGE_STATISTICS=4096", " AFFILIATED_COMPANIES=0 ", ""})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if limits.MaxBytes(model.VerificationDataTypeArbitrageStatistics) != 4096 ||
+		limits.MaxBytes(model.VerificationDataTypeAffiliatedCompanies) != 0 ||
+		limits.MaxBytes(model.VerificationDataTypeBasicInformation) != 1024 {
+		t.Errorf("unexpected limits %+v", limits)
+	}
+
+	for _, spec := range []string{"ARBITRAGE_STATISTICS", "UNKNOWN=1", "ACTIVITIES=-1", "ACTIVITIES=1MB"} {
+		if _, err := ParseLimits(0, []string{spec}); err == nil {
+			t.Errorf("expected error for limit %q", spec)
+		}
+	}
+}
+
+func TestReference(t *testing.T) {
+	reference := Reference(Offloaded{Location: "s3://payloads/ARBITRAGE_STATISTICS/ab.json", SizeBytes: 10 << 20, SHA256: "ab"})
+	if reference != `{"$offloaded":{"location":"s3://payloads/ARBITRAGE_STATISTICS/ab.json","sizeBytes":10485760,"sha256":"ab"}}` {
+		t.Errorf("unexpected reference %s", reference)
+	}
+	if !IsReference(reference) {
+		t.Error("expected reference to be recognized")
+	}
+	if IsReference(`{"$offloaded": {}, "cases": 3}`) || IsReference(`{"cases": 3}`) {
+		t.Error("expected payloads not to be references")
+	}
+}
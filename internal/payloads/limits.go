@@ -0,0 +1,78 @@
+package payloads
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"scoring_api_gateway/graph/model"
+)
+
+// Limits пределы размера данных по типам
+type Limits struct {
+	defaultBytes int
+	byType       map[model.VerificationDataType]int
+}
+
+// ParseLimits разбирает пределы вида ARBITRAGE_STATISTICS=10485760. defaultBytes действует для
+// остальных типов, 0 - без предела.
+func ParseLimits(defaultBytes int, specs []string) (Limits, error) {
+	limits := Limits{defaultBytes: defaultBytes, byType: make(map[model.VerificationDataType]int)}
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		dataType, value, ok := strings.Cut(spec, "=")
+		if !ok {
+			return Limits{}, fmt.Errorf("invalid payload limit %q, expected DATA_TYPE=bytes", spec)
+		}
+		if !model.VerificationDataType(dataType).IsValid() {
+			return Limits{}, fmt.Errorf("unknown data type %q in payload limit %q", dataType, spec)
+		}
+		bytes, err := strconv.Atoi(value)
+		if err != nil || bytes < 0 {
+			return Limits{}, fmt.Errorf("invalid size in payload limit %q", spec)
+		}
+		limits.byType[model.VerificationDataType(dataType)] = bytes
+	}
+	return limits, nil
+}
+
+// MaxBytes предел размера данных типа, 0 - без предела
+func (l Limits) MaxBytes(dataType model.VerificationDataType) int {
+	if bytes, ok := l.byType[dataType]; ok {
+		return bytes
+	}
+	return l.defaultBytes
+}
+
+// offloadedKey единственный ключ документа, заменяющего данные, перенесенные в объектное хранилище
+const offloadedKey = "$offloaded"
+
+// Offloaded расположение данных, перенесенных в объектное хранилище
+type Offloaded struct {
+	Location  string `json:"location"`
+	SizeBytes int    `json:"sizeBytes"`
+	SHA256    string `json:"sha256"`
+}
+
+// Reference документ, который сохраняется вместо перенесенных данных
+func Reference(offloaded Offloaded) string {
+	encoded, _ := json.Marshal(map[string]Offloaded{offloadedKey: offloaded})
+	return string(encoded)
+}
+
+// IsReference сообщает, что данные перенесены в объектное хранилище и сохранена только ссылка
+func IsReference(data string) bool {
+	if !strings.Contains(data, offloadedKey) {
+		return false
+	}
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &document); err != nil {
+		return false
+	}
+	_, ok := document[offloadedKey]
+	return ok && len(document) == 1
+}
@@ -0,0 +1,130 @@
+// Package persisted ограничивает запросы сервисных аккаунтов к /query зарегистрированными операциями.
+package persisted
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/repository"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+)
+
+// Коды ошибок в extensions.code
+const (
+	// CodeNotFound клиент передал только хэш операции, не зарегистрированной для его ключа
+	CodeNotFound = "PERSISTED_QUERY_NOT_FOUND"
+	// CodeHashMismatch хэш в extensions.persistedQuery не совпадает с текстом запроса
+	CodeHashMismatch = "PERSISTED_QUERY_HASH_MISMATCH"
+	// CodeNotAllowed операция не зарегистрирована для ключа, а режим enforce запрещает произвольные запросы
+	CodeNotAllowed = "OPERATION_NOT_ALLOWED"
+)
+
+// Store источник операций, разрешенных ключам
+type Store interface {
+	GetDocument(ctx context.Context, apiKeyID, hash string) (string, error)
+}
+
+// Hash возвращает SHA-256 текста операции в hex, как в extensions.persistedQuery.sha256Hash
+func Hash(document string) string {
+	sum := sha256.Sum256([]byte(document))
+	return hex.EncodeToString(sum[:])
+}
+
+// Extension расширение gqlgen, подставляющее зарегистрированные операции по хэшу и проверяющее,
+// что ключ сервисного аккаунта выполняет только разрешенные ему операции. Запросы пользователей не проверяются.
+type Extension struct {
+	store  Store
+	mode   string
+	logger *zap.Logger
+}
+
+var (
+	_ graphql.HandlerExtension          = (*Extension)(nil)
+	_ graphql.OperationParameterMutator = (*Extension)(nil)
+)
+
+func NewExtension(store Store, mode string, logger *zap.Logger) *Extension {
+	return &Extension{store: store, mode: mode, logger: logger}
+}
+
+func (e *Extension) ExtensionName() string {
+	return "PersistedOperations"
+}
+
+func (e *Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (e *Extension) MutateOperationParameters(ctx context.Context, request *graphql.RawParams) *gqlerror.Error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || principal.Scope == nil {
+		return nil
+	}
+	keyID := principal.Scope.KeyID
+
+	hash := requestedHash(request)
+	if request.Query == "" {
+		if hash == "" {
+			return nil
+		}
+		document, err := e.store.GetDocument(ctx, keyID, hash)
+		if errors.Is(err, repository.ErrNotFound) {
+			return reject(CodeNotFound, "PersistedQueryNotFound")
+		}
+		if err != nil {
+			return gqlerror.Errorf("failed to load persisted operation")
+		}
+		request.Query = document
+		return nil
+	}
+
+	actual := Hash(request.Query)
+	if hash != "" && hash != actual {
+		return reject(CodeHashMismatch, "provided sha256Hash does not match query")
+	}
+
+	_, err := e.store.GetDocument(ctx, keyID, actual)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return gqlerror.Errorf("failed to load persisted operation")
+	}
+
+	metrics.UnregisteredOperations.WithLabelValues(e.mode).Inc()
+	e.logger.Warn("unregistered operation",
+		zap.String("mode", e.mode),
+		zap.String("api_key_id", keyID),
+		zap.String("operation_name", request.OperationName),
+		zap.String("hash", actual))
+	if e.mode == config.PersistedOperationsEnforce {
+		return reject(CodeNotAllowed, "operation %s is not registered for this api key", actual)
+	}
+	return nil
+}
+
+// requestedHash возвращает хэш из extensions.persistedQuery в формате Apollo
+func requestedHash(request *graphql.RawParams) string {
+	persistedQuery, ok := request.Extensions["persistedQuery"].(map[string]any)
+	if !ok {
+		return ""
+	}
+	hash, _ := persistedQuery["sha256Hash"].(string)
+	return hash
+}
+
+func reject(code, format string, args ...any) *gqlerror.Error {
+	return &gqlerror.Error{
+		Message:    fmt.Sprintf(format, args...),
+		Extensions: map[string]any{"code": code},
+	}
+}
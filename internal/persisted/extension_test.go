@@ -0,0 +1,135 @@
+package persisted_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"scoring_api_gateway/graph"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/persisted"
+	"scoring_api_gateway/internal/repository"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"go.uber.org/zap/zaptest"
+)
+
+const registered = `{ __typename }`
+
+type fakeStore map[string]string
+
+func (s fakeStore) GetDocument(ctx context.Context, apiKeyID, hash string) (string, error) {
+	document, ok := s[apiKeyID+":"+hash]
+	if !ok {
+		return "", repository.ErrNotFound
+	}
+	return document, nil
+}
+
+type graphQLResponse struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func TestExtension(t *testing.T) {
+	store := fakeStore{"key-1:" + persisted.Hash(registered): registered}
+	partner := &auth.Principal{Email: "partner@example.com", Scope: &auth.Scope{KeyID: "key-1"}}
+	user := &auth.Principal{Email: "analyst@example.com"}
+	hashOnly := map[string]any{"persistedQuery": map[string]any{"version": 1, "sha256Hash": persisted.Hash(registered)}}
+
+	tests := []struct {
+		name         string
+		mode         string
+		principal    *auth.Principal
+		request      map[string]any
+		expectedCode string
+	}{
+		{
+			name:      "registered_operation",
+			mode:      config.PersistedOperationsEnforce,
+			principal: partner,
+			request:   map[string]any{"query": registered},
+		},
+		{
+			name:      "hash_only",
+			mode:      config.PersistedOperationsEnforce,
+			principal: partner,
+			request:   map[string]any{"extensions": hashOnly},
+		},
+		{
+			name:         "unknown_hash",
+			mode:         config.PersistedOperationsReport,
+			principal:    partner,
+			request:      map[string]any{"extensions": map[string]any{"persistedQuery": map[string]any{"sha256Hash": persisted.Hash("{ unknown }")}}},
+			expectedCode: persisted.CodeNotFound,
+		},
+		{
+			name:         "hash_mismatch",
+			mode:         config.PersistedOperationsEnforce,
+			principal:    partner,
+			request:      map[string]any{"query": `{ a: __typename }`, "extensions": hashOnly},
+			expectedCode: persisted.CodeHashMismatch,
+		},
+		{
+			name:         "unregistered_operation_enforced",
+			mode:         config.PersistedOperationsEnforce,
+			principal:    partner,
+			request:      map[string]any{"query": `{ a: __typename }`},
+			expectedCode: persisted.CodeNotAllowed,
+		},
+		{
+			name:      "unregistered_operation_reported",
+			mode:      config.PersistedOperationsReport,
+			principal: partner,
+			request:   map[string]any{"query": `{ a: __typename }`},
+		},
+		{
+			name:      "user_not_checked",
+			mode:      config.PersistedOperationsEnforce,
+			principal: user,
+			request:   map[string]any{"query": `{ a: __typename }`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{}}))
+			srv.AddTransport(transport.POST{})
+			srv.Use(persisted.NewExtension(store, tt.mode, zaptest.NewLogger(t)))
+
+			body, _ := json.Marshal(tt.request)
+			req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(string(body)))
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(auth.WithPrincipal(req.Context(), tt.principal))
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+
+			var response graphQLResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+			}
+
+			if tt.expectedCode == "" {
+				if len(response.Errors) != 0 {
+					t.Fatalf("unexpected errors: %+v", response.Errors)
+				}
+				if response.Data["__typename"] == nil && response.Data["a"] == nil {
+					t.Errorf("expected operation to be executed, but got %+v", response.Data)
+				}
+				return
+			}
+
+			if len(response.Errors) != 1 || response.Errors[0].Extensions["code"] != tt.expectedCode {
+				t.Errorf("expected error code %s, but got %+v", tt.expectedCode, response.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package prefetch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"scoring_api_gateway/internal/catalog"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// candidateFactor во сколько раз больше кандидатов запрашивается из базы, чтобы после
+// отсева компаний с быстрыми источниками данных осталось TopN
+const candidateFactor = 4
+
+// Job в часы низкой нагрузки повторяет проверки популярных компаний, данные которых устарели.
+// Первыми обновляются компании, ожидание данных по которым для пользователя было бы самым долгим.
+type Job struct {
+	repo     repository.PopularityRepository
+	service  service.VerificationService
+	registry *catalog.Registry
+	cfg      config.PrefetchConfig
+	location *time.Location
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+func NewJob(repo repository.PopularityRepository, service service.VerificationService, registry *catalog.Registry, cfg config.PrefetchConfig, logger *zap.Logger) (*Job, error) {
+	location, err := time.LoadLocation(cfg.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prefetch time zone: %w", err)
+	}
+
+	return &Job{
+		repo:     repo,
+		service:  service,
+		registry: registry,
+		cfg:      cfg,
+		location: location,
+		logger:   logger,
+		now:      time.Now,
+	}, nil
+}
+
+func (j *Job) Name() string {
+	return "prefetch"
+}
+
+func (j *Job) Run(ctx context.Context) error {
+	now := j.now()
+	if !inOffPeak(now.In(j.location).Hour(), j.cfg.OffPeakStartHour, j.cfg.OffPeakEndHour) {
+		return nil
+	}
+
+	candidates, err := j.repo.GetPopularCompanies(ctx, now.Add(-j.cfg.Window), now.Add(-j.cfg.RefreshAfter), j.cfg.TopN*candidateFactor)
+	if err != nil {
+		return fmt.Errorf("failed to get popular companies: %w", err)
+	}
+
+	planned := planPrefetch(candidates, j.registry, j.cfg.MinLatency, j.cfg.TopN)
+
+	var published int
+	for _, company := range planned {
+		_, err := j.service.CreateVerificationWithPriority(ctx, company.INN, company.RequestedDataTypes, j.cfg.AuthorEmail, messaging.PriorityBatch)
+		if err != nil {
+			j.logger.Error("failed to schedule prefetch", zap.Error(err), zap.String("inn", company.INN))
+			continue
+		}
+		published++
+	}
+
+	j.logger.Info("prefetch verifications scheduled",
+		zap.Int("candidates", len(candidates)),
+		zap.Int("planned", len(planned)),
+		zap.Int("published", published))
+	return nil
+}
+
+// inOffPeak сообщает, попадает ли час в окно [start, end). Окно может переходить через полночь.
+func inOffPeak(hour, start, end int) bool {
+	if start == end {
+		return true
+	}
+	if start < end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
+
+// expectedLatency время ожидания данных компании: самый медленный из запрошенных источников
+func expectedLatency(company *repository.PopularCompany, registry *catalog.Registry) time.Duration {
+	var latency time.Duration
+	for _, dataType := range company.RequestedDataTypes {
+		if entry, ok := registry.Lookup(dataType); ok && entry.TypicalLatency > latency {
+			latency = entry.TypicalLatency
+		}
+	}
+	return latency
+}
+
+// planPrefetch выбирает до limit компаний с наибольшим суммарным ожиданием пользователей
+// (чтения × время получения данных). Компании, данные которых приходят быстрее minLatency,
+// пропускаются: их дешевле запросить при обращении.
+func planPrefetch(candidates []*repository.PopularCompany, registry *catalog.Registry, minLatency time.Duration, limit int) []*repository.PopularCompany {
+	type scored struct {
+		company *repository.PopularCompany
+		score   float64
+	}
+
+	var ranked []scored
+	for _, company := range candidates {
+		latency := expectedLatency(company, registry)
+		if latency < minLatency {
+			continue
+		}
+		ranked = append(ranked, scored{company: company, score: float64(company.Reads) * latency.Seconds()})
+	}
+
+	sort.SliceStable(ranked, func(i, k int) bool {
+		return ranked[i].score > ranked[k].score
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	planned := make([]*repository.PopularCompany, 0, len(ranked))
+	for _, r := range ranked {
+		planned = append(planned, r.company)
+	}
+	return planned
+}
@@ -0,0 +1,165 @@
+package prefetch
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/catalog"
+	"scoring_api_gateway/internal/repository"
+)
+
+func popular(inn string, reads int, dataTypes ...model.VerificationDataType) *repository.PopularCompany {
+	return &repository.PopularCompany{INN: inn, Reads: reads, RequestedDataTypes: dataTypes}
+}
+
+func TestPlanPrefetch(t *testing.T) {
+	registry := catalog.NewRegistry([]catalog.DataType{
+		{Type: model.VerificationDataTypeBasicInformation, TypicalLatency: 5 * time.Second},
+		{Type: model.VerificationDataTypeArbitrageStatistics, TypicalLatency: 5 * time.Minute},
+		{Type: model.VerificationDataTypeAffiliatedCompanies, TypicalLatency: time.Minute},
+	})
+
+	tests := []struct {
+		name       string
+		candidates []*repository.PopularCompany
+		minLatency time.Duration
+		limit      int
+		expected   []string
+	}{
+		{
+			name: "slow_sources_ranked_first",
+			candidates: []*repository.PopularCompany{
+				popular("1111111111", 100, model.VerificationDataTypeAffiliatedCompanies),
+				popular("2222222222", 30, model.VerificationDataTypeBasicInformation, model.VerificationDataTypeArbitrageStatistics),
+			},
+			limit:    10,
+			expected: []string{"2222222222", "1111111111"},
+		},
+		{
+			name: "fast_sources_skipped",
+			candidates: []*repository.PopularCompany{
+				popular("1111111111", 500, model.VerificationDataTypeBasicInformation),
+				popular("2222222222", 1, model.VerificationDataTypeAffiliatedCompanies),
+			},
+			minLatency: 30 * time.Second,
+			limit:      10,
+			expected:   []string{"2222222222"},
+		},
+		{
+			name: "limited_to_top_n",
+			candidates: []*repository.PopularCompany{
+				popular("1111111111", 1, model.VerificationDataTypeAffiliatedCompanies),
+				popular("2222222222", 3, model.VerificationDataTypeAffiliatedCompanies),
+				popular("3333333333", 2, model.VerificationDataTypeAffiliatedCompanies),
+			},
+			limit:    2,
+			expected: []string{"2222222222", "3333333333"},
+		},
+		{
+			name: "unknown_data_type_has_no_latency",
+			candidates: []*repository.PopularCompany{
+				popular("1111111111", 10, model.VerificationDataType("UNKNOWN")),
+			},
+			minLatency: time.Second,
+			limit:      10,
+			expected:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planned := planPrefetch(tt.candidates, registry, tt.minLatency, tt.limit)
+			inns := make([]string, 0, len(planned))
+			for _, company := range planned {
+				inns = append(inns, company.INN)
+			}
+			if !reflect.DeepEqual(inns, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, inns)
+			}
+		})
+	}
+}
+
+func TestInOffPeak(t *testing.T) {
+	tests := []struct {
+		name     string
+		hour     int
+		start    int
+		end      int
+		expected bool
+	}{
+		{name: "inside_window", hour: 3, start: 1, end: 6, expected: true},
+		{name: "end_is_exclusive", hour: 6, start: 1, end: 6, expected: false},
+		{name: "before_window", hour: 0, start: 1, end: 6, expected: false},
+		{name: "wraps_midnight_late", hour: 23, start: 22, end: 5, expected: true},
+		{name: "wraps_midnight_early", hour: 2, start: 22, end: 5, expected: true},
+		{name: "wraps_midnight_outside", hour: 12, start: 22, end: 5, expected: false},
+		{name: "equal_bounds_always", hour: 12, start: 0, end: 0, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inOffPeak(tt.hour, tt.start, tt.end); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+type mockPopularityRepository struct {
+	addReadsFunc func(ctx context.Context, day time.Time, reads map[string]int) error
+}
+
+func (m *mockPopularityRepository) AddReads(ctx context.Context, day time.Time, reads map[string]int) error {
+	return m.addReadsFunc(ctx, day, reads)
+}
+
+func (m *mockPopularityRepository) GetPopularCompanies(ctx context.Context, since, staleBefore time.Time, limit int) ([]*repository.PopularCompany, error) {
+	return nil, nil
+}
+
+func TestTrackerFlush(t *testing.T) {
+	var saved []map[string]int
+	fail := true
+	repo := &mockPopularityRepository{
+		addReadsFunc: func(ctx context.Context, day time.Time, reads map[string]int) error {
+			if fail {
+				return errors.New("database unavailable")
+			}
+			saved = append(saved, reads)
+			return nil
+		},
+	}
+	tracker := NewTracker(repo)
+
+	tracker.RecordRead("1111111111")
+	tracker.RecordRead("1111111111")
+	tracker.RecordRead("")
+
+	if err := tracker.Flush(context.Background()); err == nil {
+		t.Fatal("expected flush error")
+	}
+
+	// Чтения, не сохраненные из-за ошибки, сохраняются следующим вызовом
+	fail = false
+	tracker.RecordRead("2222222222")
+	if err := tracker.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []map[string]int{{"1111111111": 2, "2222222222": 1}}
+	if !reflect.DeepEqual(saved, expected) {
+		t.Errorf("expected %v, got %v", expected, saved)
+	}
+
+	if err := tracker.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(saved) != 1 {
+		t.Errorf("expected empty flush to skip the repository, got %d calls", len(saved))
+	}
+}
@@ -0,0 +1,79 @@
+// Package prefetch заранее обновляет данные часто запрашиваемых компаний,
+// чтобы интерактивные запросы обслуживались из свежего кэша.
+package prefetch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"scoring_api_gateway/internal/repository"
+)
+
+// Tracker накапливает чтения по ИНН в памяти и периодически сохраняет их в базу,
+// чтобы запросы на чтение не ждали записи счетчиков
+type Tracker struct {
+	repo repository.PopularityRepository
+	now  func() time.Time
+
+	mu    sync.Mutex
+	reads map[string]int
+}
+
+func NewTracker(repo repository.PopularityRepository) *Tracker {
+	return &Tracker{
+		repo:  repo,
+		now:   time.Now,
+		reads: make(map[string]int),
+	}
+}
+
+// RecordRead учитывает чтение данных компании
+func (t *Tracker) RecordRead(inn string) {
+	if inn == "" {
+		return
+	}
+	t.mu.Lock()
+	t.reads[inn]++
+	t.mu.Unlock()
+}
+
+// Flush сохраняет накопленные чтения за текущий день (UTC). При ошибке чтения возвращаются
+// в буфер и будут сохранены следующим вызовом.
+func (t *Tracker) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	reads := t.reads
+	t.reads = make(map[string]int)
+	t.mu.Unlock()
+
+	if len(reads) == 0 {
+		return nil
+	}
+
+	if err := t.repo.AddReads(ctx, t.now().UTC(), reads); err != nil {
+		t.mu.Lock()
+		for inn, count := range reads {
+			t.reads[inn] += count
+		}
+		t.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// FlushJob сохраняет накопленные чтения
+type FlushJob struct {
+	tracker *Tracker
+}
+
+func NewFlushJob(tracker *Tracker) *FlushJob {
+	return &FlushJob{tracker: tracker}
+}
+
+func (j *FlushJob) Name() string {
+	return "inn_reads_flush"
+}
+
+func (j *FlushJob) Run(ctx context.Context) error {
+	return j.tracker.Flush(ctx)
+}
@@ -0,0 +1,26 @@
+// Package privacy обезличивает данные пользователей после окончания срока хранения.
+package privacy
+
+import (
+	"context"
+
+	"scoring_api_gateway/internal/service"
+)
+
+// Job заменяет email авторов старых проверок солеными хэшами
+type Job struct {
+	service service.PrivacyService
+}
+
+func NewJob(service service.PrivacyService) *Job {
+	return &Job{service: service}
+}
+
+func (j *Job) Name() string {
+	return "privacy_anonymization"
+}
+
+func (j *Job) Run(ctx context.Context) error {
+	_, err := j.service.AnonymizeExpired(ctx)
+	return err
+}
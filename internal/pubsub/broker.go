@@ -0,0 +1,70 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// Broker рассылает значения подписчикам внутри процесса, подписки группируются по ключу.
+// Медленный подписчик получает только последнее значение: старые непрочитанные значения отбрасываются.
+type Broker[T any] struct {
+	mu   sync.Mutex
+	subs map[string]map[chan T]struct{}
+}
+
+func NewBroker[T any]() *Broker[T] {
+	return &Broker[T]{
+		subs: make(map[string]map[chan T]struct{}),
+	}
+}
+
+// Subscribe подписывается на значения по ключу. Канал закрывается при отмене контекста.
+func (b *Broker[T]) Subscribe(ctx context.Context, key string) <-chan T {
+	ch := make(chan T, 1)
+
+	b.mu.Lock()
+	if b.subs[key] == nil {
+		b.subs[key] = make(map[chan T]struct{})
+	}
+	b.subs[key][ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.subs[key], ch)
+		if len(b.subs[key]) == 0 {
+			delete(b.subs, key)
+		}
+		close(ch)
+		b.mu.Unlock()
+	}()
+
+	return ch
+}
+
+// Publish отправляет значение всем подписчикам ключа без блокировки
+func (b *Broker[T]) Publish(key string, value T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs[key] {
+		select {
+		case ch <- value:
+		default:
+			// Заменяем непрочитанное значение свежим
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- value
+		}
+	}
+}
+
+// Subscribers возвращает количество подписчиков ключа
+func (b *Broker[T]) Subscribers(key string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs[key])
+}
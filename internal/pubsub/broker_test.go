@@ -0,0 +1,69 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBrokerPublishSubscribe(t *testing.T) {
+	broker := NewBroker[int]()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := broker.Subscribe(ctx, "user@example.com")
+	other := broker.Subscribe(ctx, "other@example.com")
+
+	broker.Publish("user@example.com", 1)
+
+	select {
+	case v := <-ch:
+		if v != 1 {
+			t.Errorf("expected value 1, but got %d", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected value to be delivered")
+	}
+
+	select {
+	case v := <-other:
+		t.Errorf("expected no value for other key, but got %d", v)
+	default:
+	}
+}
+
+func TestBrokerKeepsLatestValue(t *testing.T) {
+	broker := NewBroker[int]()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := broker.Subscribe(ctx, "key")
+
+	broker.Publish("key", 1)
+	broker.Publish("key", 2)
+
+	if v := <-ch; v != 2 {
+		t.Errorf("expected latest value 2, but got %d", v)
+	}
+}
+
+func TestBrokerUnsubscribeOnCancel(t *testing.T) {
+	broker := NewBroker[int]()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := broker.Subscribe(ctx, "key")
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("expected channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected channel to be closed after cancel")
+	}
+
+	if n := broker.Subscribers("key"); n != 0 {
+		t.Errorf("expected no subscribers, but got %d", n)
+	}
+}
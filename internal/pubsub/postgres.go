@@ -0,0 +1,100 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// postgresReconnectDelay пауза перед повторным LISTEN после потери соединения
+const postgresReconnectDelay = time.Second
+
+// postgresEvent тело NOTIFY
+type postgresEvent struct {
+	Kind string `json:"kind"`
+	Key  string `json:"key"`
+}
+
+// PostgresRelay доставляет события всем экземплярам шлюза через LISTEN/NOTIFY общей базы. Нужен,
+// когда экземпляры не связаны ничем, кроме PostgreSQL: уведомление о завершении проверки обрабатывает
+// один экземпляр, а подписчик может быть подключен к другому.
+type PostgresRelay struct {
+	handlers
+	db      *pgxpool.Pool
+	channel string
+	logger  *zap.Logger
+}
+
+// NewPostgresRelay создает Relay на канале channel. События доставляются, пока работает Run.
+func NewPostgresRelay(db *pgxpool.Pool, channel string, logger *zap.Logger) *PostgresRelay {
+	return &PostgresRelay{
+		db:      db,
+		channel: channel,
+		logger:  logger,
+	}
+}
+
+// Publish отправляет NOTIFY. Событие, которое не удалось отправить, доставляется хотя бы
+// обработчикам текущего экземпляра.
+func (r *PostgresRelay) Publish(ctx context.Context, kind, key string) {
+	payload, err := json.Marshal(postgresEvent{Kind: kind, Key: key})
+	if err == nil {
+		_, err = r.db.Exec(ctx, `SELECT pg_notify($1, $2)`, r.channel, string(payload))
+	}
+	if err != nil {
+		r.logger.Warn("failed to notify other gateway instances", zap.Error(err), zap.String("kind", kind))
+		r.dispatch(kind, key)
+	}
+}
+
+// Run слушает канал до отмены ctx, переподключаясь после потери соединения. События, отправленные,
+// пока соединения нет, не доставляются: подписки получат следующее.
+func (r *PostgresRelay) Run(ctx context.Context) {
+	for {
+		err := r.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		r.logger.Warn("postgres notification listener stopped, reconnecting", zap.Error(err), zap.String("channel", r.channel))
+
+		select {
+		case <-time.After(postgresReconnectDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *PostgresRelay) listen(ctx context.Context) error {
+	pooled, err := r.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	// Соединение с LISTEN не возвращается в пул, иначе уведомления получал бы случайный запрос
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{r.channel}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", r.channel, err)
+	}
+	r.logger.Info("listening for postgres notifications", zap.String("channel", r.channel))
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var event postgresEvent
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil || event.Kind == "" {
+			r.logger.Warn("invalid postgres notification", zap.Error(err), zap.String("channel", r.channel))
+			continue
+		}
+		r.dispatch(event.Kind, event.Key)
+	}
+}
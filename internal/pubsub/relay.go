@@ -0,0 +1,52 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// Relay доставляет события подписок (о них узнает один экземпляр шлюза, а подписчики могут быть
+// подключены к любому) обработчикам экземпляров. Обработчики обычно публикуют значения в Broker.
+type Relay interface {
+	// Publish сообщает о событии kind с ключом key всем экземплярам, включая текущий
+	Publish(ctx context.Context, kind, key string)
+	// Handle добавляет обработчик событий kind
+	Handle(kind string, handler func(key string))
+}
+
+// handlers обработчики событий по виду
+type handlers struct {
+	mu     sync.RWMutex
+	byKind map[string][]func(key string)
+}
+
+func (h *handlers) Handle(kind string, handler func(key string)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.byKind == nil {
+		h.byKind = make(map[string][]func(key string))
+	}
+	h.byKind[kind] = append(h.byKind[kind], handler)
+}
+
+func (h *handlers) dispatch(kind, key string) {
+	h.mu.RLock()
+	registered := h.byKind[kind]
+	h.mu.RUnlock()
+	for _, handler := range registered {
+		handler(key)
+	}
+}
+
+type localRelay struct {
+	handlers
+}
+
+// NewLocalRelay возвращает Relay, доставляющий события только обработчикам текущего экземпляра
+func NewLocalRelay() Relay {
+	return &localRelay{}
+}
+
+func (r *localRelay) Publish(ctx context.Context, kind, key string) {
+	r.dispatch(kind, key)
+}
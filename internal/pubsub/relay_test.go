@@ -0,0 +1,24 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLocalRelay(t *testing.T) {
+	relay := NewLocalRelay()
+	var completed, changed []string
+	relay.Handle("completed", func(key string) { completed = append(completed, "first:"+key) })
+	relay.Handle("completed", func(key string) { completed = append(completed, "second:"+key) })
+	relay.Handle("changed", func(key string) { changed = append(changed, key) })
+
+	relay.Publish(context.Background(), "completed", "v-1")
+	relay.Publish(context.Background(), "unknown", "v-2")
+
+	if len(completed) != 2 || completed[0] != "first:v-1" || completed[1] != "second:v-1" {
+		t.Errorf("expected every handler of the kind to receive the event, but got %v", completed)
+	}
+	if len(changed) != 0 {
+		t.Errorf("expected handlers of other kinds to be skipped, but got %v", changed)
+	}
+}
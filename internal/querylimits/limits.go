@@ -0,0 +1,135 @@
+// Package querylimits ограничивает размер GraphQL-запросов к публичному /query.
+package querylimits
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/metrics"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Коды ошибок в extensions.code, по одному на каждое ограничение
+const (
+	CodeTooManyAliases    = "TOO_MANY_ALIASES"
+	CodeTooManyRootFields = "TOO_MANY_ROOT_FIELDS"
+	CodeTooManyOperations = "TOO_MANY_OPERATIONS"
+	CodeVariablesTooLarge = "VARIABLES_TOO_LARGE"
+)
+
+// Extension расширение gqlgen, отклоняющее запросы сверх настроенных ограничений.
+// Нулевое значение ограничения отключает его.
+type Extension struct {
+	limits config.GraphQLLimitsConfig
+}
+
+var (
+	_ graphql.HandlerExtension          = (*Extension)(nil)
+	_ graphql.OperationParameterMutator = (*Extension)(nil)
+	_ graphql.OperationContextMutator   = (*Extension)(nil)
+)
+
+func NewExtension(limits config.GraphQLLimitsConfig) *Extension {
+	return &Extension{limits: limits}
+}
+
+func (e *Extension) ExtensionName() string {
+	return "QueryLimits"
+}
+
+func (e *Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationParameters проверяет размер переменных до разбора запроса
+func (e *Extension) MutateOperationParameters(ctx context.Context, request *graphql.RawParams) *gqlerror.Error {
+	if e.limits.MaxVariablesBytes <= 0 || len(request.Variables) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(request.Variables)
+	if err != nil {
+		return nil
+	}
+	if len(payload) > e.limits.MaxVariablesBytes {
+		return reject(CodeVariablesTooLarge, "variables payload of %d bytes exceeds the limit of %d bytes", len(payload), e.limits.MaxVariablesBytes)
+	}
+	return nil
+}
+
+// MutateOperationContext проверяет разобранный документ: количество операций, корневых полей и псевдонимов
+func (e *Extension) MutateOperationContext(ctx context.Context, opCtx *graphql.OperationContext) *gqlerror.Error {
+	if opCtx.Doc != nil && e.limits.MaxOperations > 0 && len(opCtx.Doc.Operations) > e.limits.MaxOperations {
+		return reject(CodeTooManyOperations, "document contains %d operations, the limit is %d", len(opCtx.Doc.Operations), e.limits.MaxOperations)
+	}
+	if opCtx.Operation == nil {
+		return nil
+	}
+
+	if e.limits.MaxRootFields > 0 {
+		if count := countRootFields(opCtx.Operation.SelectionSet, map[string]bool{}); count > e.limits.MaxRootFields {
+			return reject(CodeTooManyRootFields, "operation selects %d root fields, the limit is %d", count, e.limits.MaxRootFields)
+		}
+	}
+	if e.limits.MaxAliases > 0 {
+		if count := countAliases(opCtx.Operation.SelectionSet, map[string]bool{}); count > e.limits.MaxAliases {
+			return reject(CodeTooManyAliases, "operation uses %d aliases, the limit is %d", count, e.limits.MaxAliases)
+		}
+	}
+	return nil
+}
+
+func reject(code, format string, args ...any) *gqlerror.Error {
+	metrics.GraphQLLimitRejections.WithLabelValues(code).Inc()
+	return &gqlerror.Error{
+		Message:    fmt.Sprintf(format, args...),
+		Extensions: map[string]any{"code": code},
+	}
+}
+
+// countRootFields считает поля верхнего уровня, раскрывая фрагменты
+func countRootFields(selections ast.SelectionSet, visited map[string]bool) int {
+	count := 0
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *ast.Field:
+			count++
+		case *ast.InlineFragment:
+			count += countRootFields(s.SelectionSet, visited)
+		case *ast.FragmentSpread:
+			if s.Definition != nil && !visited[s.Name] {
+				visited[s.Name] = true
+				count += countRootFields(s.Definition.SelectionSet, visited)
+			}
+		}
+	}
+	return count
+}
+
+// countAliases считает поля с псевдонимом, отличным от имени поля, на всех уровнях запроса.
+// Каждый фрагмент учитывается один раз.
+func countAliases(selections ast.SelectionSet, visited map[string]bool) int {
+	count := 0
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *ast.Field:
+			if s.Alias != "" && s.Alias != s.Name {
+				count++
+			}
+			count += countAliases(s.SelectionSet, visited)
+		case *ast.InlineFragment:
+			count += countAliases(s.SelectionSet, visited)
+		case *ast.FragmentSpread:
+			if s.Definition != nil && !visited[s.Name] {
+				visited[s.Name] = true
+				count += countAliases(s.Definition.SelectionSet, visited)
+			}
+		}
+	}
+	return count
+}
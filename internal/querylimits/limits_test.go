@@ -0,0 +1,123 @@
+package querylimits_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"scoring_api_gateway/graph"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/querylimits"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+)
+
+type graphQLResponse struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func execute(t *testing.T, srv http.Handler, request map[string]any) graphQLResponse {
+	t.Helper()
+
+	body, _ := json.Marshal(request)
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	var response graphQLResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	return response
+}
+
+func TestExtension(t *testing.T) {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{}}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(querylimits.NewExtension(config.GraphQLLimitsConfig{
+		MaxAliases:        2,
+		MaxRootFields:     3,
+		MaxOperations:     2,
+		MaxVariablesBytes: 64,
+	}))
+
+	tests := []struct {
+		name         string
+		request      map[string]any
+		expectedCode string
+	}{
+		{
+			name:    "within_limits",
+			request: map[string]any{"query": `{ a: __typename b: __typename }`},
+		},
+		{
+			name:         "too_many_aliases",
+			request:      map[string]any{"query": `{ a: __typename b: __typename c: __typename }`},
+			expectedCode: querylimits.CodeTooManyAliases,
+		},
+		{
+			name:         "aliases_in_fragments",
+			request:      map[string]any{"query": `query { ...F x: __typename } fragment F on Query { a: __typename b: __typename }`},
+			expectedCode: querylimits.CodeTooManyAliases,
+		},
+		{
+			name:         "too_many_root_fields",
+			request:      map[string]any{"query": `{ __typename ... on Query { __typename __typename } a: __typename }`},
+			expectedCode: querylimits.CodeTooManyRootFields,
+		},
+		{
+			name: "too_many_operations",
+			request: map[string]any{
+				"query":         `query A { __typename } query B { __typename } query C { __typename }`,
+				"operationName": "A",
+			},
+			expectedCode: querylimits.CodeTooManyOperations,
+		},
+		{
+			name: "variables_too_large",
+			request: map[string]any{
+				"query":     `query($inn: String) { __typename }`,
+				"variables": map[string]any{"inn": strings.Repeat("7", 100)},
+			},
+			expectedCode: querylimits.CodeVariablesTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := execute(t, srv, tt.request)
+
+			if tt.expectedCode == "" {
+				if len(response.Errors) != 0 {
+					t.Fatalf("expected no errors, but got %+v", response.Errors)
+				}
+				return
+			}
+			if len(response.Errors) != 1 {
+				t.Fatalf("expected 1 error, but got %+v", response.Errors)
+			}
+			if code := response.Errors[0].Extensions["code"]; code != tt.expectedCode {
+				t.Errorf("expected error code %s, but got %v (%s)", tt.expectedCode, code, response.Errors[0].Message)
+			}
+		})
+	}
+}
+
+func TestExtensionDisabledLimits(t *testing.T) {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{}}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(querylimits.NewExtension(config.GraphQLLimitsConfig{}))
+
+	response := execute(t, srv, map[string]any{"query": `{ a: __typename b: __typename c: __typename d: __typename }`})
+	if len(response.Errors) != 0 {
+		t.Errorf("expected no errors with disabled limits, but got %+v", response.Errors)
+	}
+}
@@ -0,0 +1,56 @@
+// Package reconciliation повторно запрашивает данные, которые поставщики не доставили при завершении проверки.
+package reconciliation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// RetryJob повторно запрашивает недоставленные типы данных частично завершенных проверок
+type RetryJob struct {
+	repo    repository.VerificationRepository
+	service service.VerificationService
+	cfg     config.ReconciliationConfig
+	logger  *zap.Logger
+}
+
+func NewRetryJob(repo repository.VerificationRepository, service service.VerificationService, cfg config.ReconciliationConfig, logger *zap.Logger) *RetryJob {
+	return &RetryJob{
+		repo:    repo,
+		service: service,
+		cfg:     cfg,
+		logger:  logger,
+	}
+}
+
+func (j *RetryJob) Name() string {
+	return "missing_data_retry"
+}
+
+func (j *RetryJob) Run(ctx context.Context) error {
+	candidates, err := j.repo.GetMissingDataRetryCandidates(ctx, time.Now().Add(-j.cfg.RetryDelay), j.cfg.MaxRetries, j.cfg.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get missing data retry candidates: %w", err)
+	}
+
+	var retried int
+	for _, verification := range candidates {
+		if err := j.service.RetryMissingData(ctx, verification); err != nil {
+			j.logger.Error("failed to retry missing data", zap.Error(err), zap.String("verification_id", verification.ID))
+			continue
+		}
+		retried++
+	}
+
+	if len(candidates) > 0 {
+		j.logger.Info("missing data retries scheduled", zap.Int("candidates", len(candidates)), zap.Int("retried", retried))
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/service"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// storedVerification строка verifications с полями, по которым отбираются кандидаты
+type storedVerification struct {
+	id        string
+	status    model.VerificationStatus
+	missing   []model.VerificationDataType
+	updatedAt time.Time
+	retries   int
+}
+
+// memoryRepository отбирает кандидатов по тем же условиям, что и запрос репозитория
+type memoryRepository struct {
+	repository.VerificationRepository
+	rows      []*storedVerification
+	err       error
+	marked    []string
+	requested struct {
+		updatedBefore time.Time
+		maxRetries    int
+		limit         int
+	}
+}
+
+func (r *memoryRepository) GetMissingDataRetryCandidates(ctx context.Context, updatedBefore time.Time, maxRetries int, limit int) ([]*model.Verification, error) {
+	r.requested.updatedBefore, r.requested.maxRetries, r.requested.limit = updatedBefore, maxRetries, limit
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	var selected []*storedVerification
+	for _, row := range r.rows {
+		if row.status == model.VerificationStatusPartiallyCompleted && row.updatedAt.Before(updatedBefore) && row.retries < maxRetries {
+			selected = append(selected, row)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].updatedAt.Before(selected[j].updatedAt) })
+	if len(selected) > limit {
+		selected = selected[:limit]
+	}
+
+	candidates := make([]*model.Verification, 0, len(selected))
+	for _, row := range selected {
+		candidates = append(candidates, &model.Verification{
+			ID:               row.id,
+			Inn:              "7700000001",
+			Status:           row.status,
+			AuthorEmail:      "analyst@bank.ru",
+			MissingDataTypes: row.missing,
+		})
+	}
+	return candidates, nil
+}
+
+func (r *memoryRepository) MarkMissingDataRetried(ctx context.Context, id string) error {
+	for _, row := range r.rows {
+		if row.id == id {
+			row.status = model.VerificationStatusInProcess
+			row.retries++
+		}
+	}
+	r.marked = append(r.marked, id)
+	return nil
+}
+
+type publishingClient struct {
+	messaging.NATSClient
+	err       error
+	published []string
+}
+
+func (c *publishingClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	c.published = append(c.published, verification.ID)
+	return c.err
+}
+
+func TestRetryJob(t *testing.T) {
+	now := time.Now()
+	missing := []model.VerificationDataType{model.VerificationDataTypeActivities}
+	cfg := config.ReconciliationConfig{RetryDelay: 10 * time.Minute, MaxRetries: 3, BatchSize: 2}
+
+	tests := []struct {
+		name              string
+		rows              []*storedVerification
+		repoErr           error
+		publishErr        error
+		expectedError     string
+		expectedPublished []string
+		expectedMarked    []string
+	}{
+		{
+			name: "partial_after_delay_selected",
+			rows: []*storedVerification{
+				{id: "v-old", status: model.VerificationStatusPartiallyCompleted, missing: missing, updatedAt: now.Add(-time.Hour)},
+				{id: "v-recent", status: model.VerificationStatusPartiallyCompleted, missing: missing, updatedAt: now.Add(-time.Minute)},
+				{id: "v-completed", status: model.VerificationStatusCompleted, updatedAt: now.Add(-time.Hour)},
+			},
+			expectedPublished: []string{"v-old"},
+			expectedMarked:    []string{"v-old"},
+		},
+		{
+			name: "batch_size_oldest_first",
+			rows: []*storedVerification{
+				{id: "v-2", status: model.VerificationStatusPartiallyCompleted, missing: missing, updatedAt: now.Add(-2 * time.Hour)},
+				{id: "v-3", status: model.VerificationStatusPartiallyCompleted, missing: missing, updatedAt: now.Add(-time.Hour)},
+				{id: "v-1", status: model.VerificationStatusPartiallyCompleted, missing: missing, updatedAt: now.Add(-3 * time.Hour)},
+			},
+			expectedPublished: []string{"v-1", "v-2"},
+			expectedMarked:    []string{"v-1", "v-2"},
+		},
+		{
+			name: "max_retries_exhausted",
+			rows: []*storedVerification{
+				{id: "v-exhausted", status: model.VerificationStatusPartiallyCompleted, missing: missing, updatedAt: now.Add(-time.Hour), retries: 3},
+				{id: "v-last-try", status: model.VerificationStatusPartiallyCompleted, missing: missing, updatedAt: now.Add(-time.Hour), retries: 2},
+			},
+			expectedPublished: []string{"v-last-try"},
+			expectedMarked:    []string{"v-last-try"},
+		},
+		{
+			name: "publish_failure_marks_nothing",
+			rows: []*storedVerification{
+				{id: "v-old", status: model.VerificationStatusPartiallyCompleted, missing: missing, updatedAt: now.Add(-time.Hour)},
+			},
+			publishErr:        errors.New("nats: connection closed"),
+			expectedPublished: []string{"v-old"},
+		},
+		{
+			name:          "candidates_unavailable",
+			repoErr:       errors.New("connection refused"),
+			expectedError: "failed to get missing data retry candidates",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryRepository{rows: tt.rows, err: tt.repoErr}
+			client := &publishingClient{err: tt.publishErr}
+			logger := zaptest.NewLogger(t)
+			job := NewRetryJob(repo, service.NewVerificationService(repo, client, nil, logger), cfg, logger)
+
+			err := job.Run(context.Background())
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if repo.requested.maxRetries != cfg.MaxRetries || repo.requested.limit != cfg.BatchSize {
+				t.Errorf("expected candidates below %d retries limited to %d, got %d and %d",
+					cfg.MaxRetries, cfg.BatchSize, repo.requested.maxRetries, repo.requested.limit)
+			}
+			if cutoff := now.Add(-cfg.RetryDelay); repo.requested.updatedBefore.Before(cutoff) {
+				t.Errorf("expected candidates updated before %s, got %s", cutoff, repo.requested.updatedBefore)
+			}
+			if !slices.Equal(client.published, tt.expectedPublished) {
+				t.Errorf("expected published %v, got %v", tt.expectedPublished, client.published)
+			}
+			if !slices.Equal(repo.marked, tt.expectedMarked) {
+				t.Errorf("expected marked retried %v, got %v", tt.expectedMarked, repo.marked)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRenderTextPDF(t *testing.T) {
+	pdf := RenderTextPDF("Audit trail", []string{"first line", "second (line)"})
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) {
+		t.Errorf("expected PDF header, but got '%s'", pdf[:10])
+	}
+
+	if !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Error("expected PDF to end with EOF marker")
+	}
+
+	if !bytes.Contains(pdf, []byte(`(second \(line\)) Tj`)) {
+		t.Error("expected parentheses to be escaped in text")
+	}
+
+	if !bytes.Contains(pdf, []byte("/Count 1")) {
+		t.Error("expected single page document")
+	}
+}
+
+func TestRenderTextPDFPagination(t *testing.T) {
+	lines := make([]string, 150)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i)
+	}
+
+	pdf := RenderTextPDF("Audit trail", lines)
+
+	if !bytes.Contains(pdf, []byte("/Count 3")) {
+		t.Error("expected three pages for 150 lines")
+	}
+
+	if !bytes.Contains(pdf, []byte("Audit trail - page 3 of 3")) {
+		t.Error("expected page numbers in page titles")
+	}
+}
+
+func TestWrapLine(t *testing.T) {
+	line := strings.Repeat("a", maxLineChars*2+10)
+
+	wrapped := wrapLine(line)
+
+	if len(wrapped) != 3 {
+		t.Fatalf("expected 3 wrapped lines, but got %d", len(wrapped))
+	}
+
+	for _, l := range wrapped {
+		if len([]rune(l)) > maxLineChars {
+			t.Errorf("expected line length at most %d, but got %d", maxLineChars, len([]rune(l)))
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "plain", expected: "plain"},
+		{input: `a\b`, expected: `a\\b`},
+		{input: "(x)", expected: `\(x\)`},
+		{input: "ООО", expected: "???"},
+	}
+
+	for _, tt := range tests {
+		if got := escape(tt.input); got != tt.expected {
+			t.Errorf("expected '%s', but got '%s'", tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRenderXLSX(t *testing.T) {
+	content, err := RenderXLSX("Case: Ромашка/2024", [][]string{
+		{"Role", "INN"},
+		{"SUBJECT", "7707083893 <main> & co"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("expected a zip archive: %v", err)
+	}
+	parts := map[string]string{}
+	for _, file := range archive.File {
+		r, err := file.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", file.Name, err)
+		}
+		data, _ := io.ReadAll(r)
+		r.Close()
+		parts[file.Name] = string(data)
+	}
+
+	if !strings.Contains(parts["xl/workbook.xml"], `<sheet name="Case_ Ромашка_2024"`) {
+		t.Errorf("expected invalid characters to be replaced in the sheet name, but got %s", parts["xl/workbook.xml"])
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(sheet, `<c r="B2" t="inlineStr"><is><t xml:space="preserve">7707083893 &lt;main&gt; &amp; co</t></is></c>`) {
+		t.Errorf("expected an escaped B2 cell, but got %s", sheet)
+	}
+	if _, ok := parts["[Content_Types].xml"]; !ok {
+		t.Error("expected content types part")
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for index, expected := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if name := columnName(index); name != expected {
+			t.Errorf("expected column %d to be %s, but got %s", index, expected, name)
+		}
+	}
+}
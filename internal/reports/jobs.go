@@ -0,0 +1,44 @@
+// Package reports запускает фоновое формирование отчетов по делам и удаление просроченных отчетов.
+package reports
+
+import (
+	"context"
+
+	"scoring_api_gateway/internal/service"
+)
+
+// GenerationJob формирует отчеты из очереди заданий
+type GenerationJob struct {
+	service service.ReportService
+}
+
+func NewGenerationJob(service service.ReportService) *GenerationJob {
+	return &GenerationJob{service: service}
+}
+
+func (j *GenerationJob) Name() string {
+	return "report_generation"
+}
+
+func (j *GenerationJob) Run(ctx context.Context) error {
+	_, err := j.service.GeneratePending(ctx)
+	return err
+}
+
+// CleanupJob удаляет из хранилища отчеты, срок хранения которых истек
+type CleanupJob struct {
+	service service.ReportService
+}
+
+func NewCleanupJob(service service.ReportService) *CleanupJob {
+	return &CleanupJob{service: service}
+}
+
+func (j *CleanupJob) Name() string {
+	return "report_cleanup"
+}
+
+func (j *CleanupJob) Run(ctx context.Context) error {
+	_, err := j.service.ExpireResults(ctx)
+	return err
+}
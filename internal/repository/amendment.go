@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// Amendment данные, доставленные воркером после завершения проверки. Строки создает
+// триггер verification_data, поэтому воркеры записывают данные как обычно.
+type Amendment struct {
+	ID               int64
+	VerificationID   string
+	DataType         model.VerificationDataType
+	DataHash         *string
+	PreviousDataHash *string
+	SchemaVersion    int
+	StatusAtArrival  model.VerificationStatus
+	ReceivedAt       time.Time
+	PublishedAt      *time.Time
+	Attempts         int
+}
+
+type AmendmentRepository interface {
+	// ClaimUnpublished забирает неопубликованные поправки до claimUntil, начиная с самых старых
+	ClaimUnpublished(ctx context.Context, claimUntil time.Time, limit int) ([]*Amendment, error)
+	// MarkPublished отмечает опубликованной поправку id проверки verificationID. Проверка нужна,
+	// чтобы найти базу региона, в которой хранится поправка.
+	MarkPublished(ctx context.Context, verificationID string, id int64) error
+	// ListByVerification возвращает поправки проверки в порядке поступления
+	ListByVerification(ctx context.Context, verificationID string) ([]*Amendment, error)
+}
+
+type amendmentRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewAmendmentRepository(db *pgxpool.Pool, logger *zap.Logger) AmendmentRepository {
+	return &amendmentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const amendmentColumns = `id, verification_id, data_type, data_hash, previous_data_hash, schema_version,
+	status_at_arrival, received_at, published_at, attempts`
+
+// ClaimUnpublished забирает поправки так же, как outbox: если экземпляр не успеет отметить
+// поправку опубликованной, после claimUntil ее заберет другой
+func (r *amendmentRepository) ClaimUnpublished(ctx context.Context, claimUntil time.Time, limit int) ([]*Amendment, error) {
+	query := `
+		UPDATE verification_amendments
+		SET claimed_until = $1, attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM verification_amendments
+			WHERE published_at IS NULL
+			  AND (claimed_until IS NULL OR claimed_until < NOW())
+			ORDER BY id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + amendmentColumns
+
+	return r.query(ctx, "claim verification amendments", query, claimUntil, limit)
+}
+
+func (r *amendmentRepository) MarkPublished(ctx context.Context, verificationID string, id int64) error {
+	tag, err := r.db.Exec(ctx, `UPDATE verification_amendments SET published_at = NOW(), claimed_until = NULL WHERE id = $1 AND verification_id = $2`, id, verificationID)
+	if err != nil {
+		r.logger.Error("failed to mark verification amendment published", zap.Error(err), zap.Int64("id", id))
+		return fmt.Errorf("failed to mark verification amendment published: %w", classify(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return notFoundf("verification amendment not found: %d", id)
+	}
+	return nil
+}
+
+func (r *amendmentRepository) ListByVerification(ctx context.Context, verificationID string) ([]*Amendment, error) {
+	query := `SELECT ` + amendmentColumns + `
+		FROM verification_amendments
+		WHERE verification_id = $1
+		ORDER BY received_at, id
+	`
+	return r.query(ctx, "get verification amendments", query, verificationID)
+}
+
+func (r *amendmentRepository) query(ctx context.Context, action, query string, args ...any) ([]*Amendment, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("failed to "+action, zap.Error(err))
+		return nil, fmt.Errorf("failed to %s: %w", action, classify(err))
+	}
+	defer rows.Close()
+
+	var amendments []*Amendment
+	for rows.Next() {
+		var a Amendment
+		err := rows.Scan(&a.ID, &a.VerificationID, &a.DataType, &a.DataHash, &a.PreviousDataHash, &a.SchemaVersion,
+			&a.StatusAtArrival, &a.ReceivedAt, &a.PublishedAt, &a.Attempts)
+		if err != nil {
+			reportScanFailure(ctx, r.logger, rows, "verification amendment", err)
+			continue
+		}
+		amendments = append(amendments, &a)
+	}
+	return amendments, nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// APIKey ключ сервисного аккаунта с ограничениями по операциям и типам данных
+type APIKey struct {
+	ID                string
+	Name              string
+	ServiceEmail      string
+	AllowedOperations []string
+	AllowedDataTypes  []string
+	Sandbox           bool
+}
+
+type APIKeyRepository interface {
+	GetActiveByHash(ctx context.Context, keyHash string) (*APIKey, error)
+	// ListActive возвращает неотозванные ключи по имени
+	ListActive(ctx context.Context) ([]*APIKey, error)
+}
+
+type apiKeyRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool, logger *zap.Logger) APIKeyRepository {
+	return &apiKeyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetActiveByHash находит неотозванный ключ по хэшу. Неизвестный или отозванный ключ - ErrNotFound.
+func (r *apiKeyRepository) GetActiveByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	query := `
+		SELECT id, name, service_email, allowed_operations, allowed_data_types, sandbox
+		FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL
+	`
+
+	var key APIKey
+	err := r.db.QueryRow(ctx, query, keyHash).Scan(&key.ID, &key.Name, &key.ServiceEmail, &key.AllowedOperations, &key.AllowedDataTypes, &key.Sandbox)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, notFoundf("api key not found")
+		}
+		r.logger.Error("failed to get api key", zap.Error(err))
+		return nil, fmt.Errorf("failed to get api key: %w", classify(err))
+	}
+
+	return &key, nil
+}
+
+func (r *apiKeyRepository) ListActive(ctx context.Context) ([]*APIKey, error) {
+	query := `
+		SELECT id, name, service_email, allowed_operations, allowed_data_types, sandbox
+		FROM api_keys
+		WHERE revoked_at IS NULL
+		ORDER BY name, created_at
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		r.logger.Error("failed to list api keys", zap.Error(err))
+		return nil, fmt.Errorf("failed to list api keys: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var keys []*APIKey
+	for rows.Next() {
+		var key APIKey
+		if err := rows.Scan(&key.ID, &key.Name, &key.ServiceEmail, &key.AllowedOperations, &key.AllowedDataTypes, &key.Sandbox); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "api key", err)
+			continue
+		}
+		keys = append(keys, &key)
+	}
+
+	return keys, rows.Err()
+}
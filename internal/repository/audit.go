@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type AuditRepository interface {
+	AddEvent(ctx context.Context, verificationID string, eventType model.AuditEventType, actor string, details map[string]any) error
+	GetTrail(ctx context.Context, verificationID string) ([]*model.AuditTrailEntry, error)
+}
+
+type auditRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewAuditRepository(db *pgxpool.Pool, logger *zap.Logger) AuditRepository {
+	return &auditRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// AddEvent сохраняет событие жизненного цикла проверки в журнал аудита
+func (r *auditRepository) AddEvent(ctx context.Context, verificationID string, eventType model.AuditEventType, actor string, details map[string]any) error {
+	query := `
+		INSERT INTO verification_events (verification_id, event_type, actor, details)
+		VALUES ($1, $2, NULLIF($3, ''), $4)
+	`
+
+	var detailsJSON []byte
+	if details != nil {
+		var err error
+		detailsJSON, err = json.Marshal(details)
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit event details: %w", classify(err))
+		}
+	}
+
+	_, err := r.db.Exec(ctx, query, verificationID, string(eventType), actor, detailsJSON)
+	if err != nil {
+		r.logger.Error("failed to add audit event", zap.Error(err), zap.String("verification_id", verificationID), zap.String("event_type", string(eventType)))
+		return fmt.Errorf("failed to add audit event: %w", classify(err))
+	}
+
+	return nil
+}
+
+// GetTrail собирает хронологию проверки: создание, поступление данных и события из журнала аудита
+func (r *auditRepository) GetTrail(ctx context.Context, verificationID string) ([]*model.AuditTrailEntry, error) {
+	query := `
+		SELECT 'CREATED' AS event_type, author_email AS actor,
+			jsonb_build_object('inn', inn, 'requested_data_types', requested_data_types)::text AS details,
+			created_at
+		FROM verifications
+		WHERE id = $1
+		UNION ALL
+		SELECT 'DATA_RECEIVED', NULL,
+			jsonb_build_object('data_type', data_type, 'data_hash', data_hash)::text,
+			created_at
+		FROM verification_data
+		WHERE verification_id = $1
+		UNION ALL
+		SELECT event_type, actor, details::text, created_at
+		FROM verification_events
+		WHERE verification_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, verificationID)
+	if err != nil {
+		r.logger.Error("failed to get audit trail", zap.Error(err), zap.String("verification_id", verificationID))
+		return nil, fmt.Errorf("failed to get audit trail: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var entries []*model.AuditTrailEntry
+	for rows.Next() {
+		var entry model.AuditTrailEntry
+		var occurredAt time.Time
+		if err := rows.Scan(&entry.EventType, &entry.Actor, &entry.Details, &occurredAt); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "audit trail entry", err)
+			continue
+		}
+		entry.OccurredAt = occurredAt.Format(time.RFC3339)
+		entries = append(entries, &entry)
+	}
+
+	return entries, nil
+}
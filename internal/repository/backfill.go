@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"scoring_api_gateway/types"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// DataStorageStats объем данных проверок до и после дедупликации по хэшу
+type DataStorageStats struct {
+	// ReferencingRows строки verification_data, ссылающиеся на кэш
+	ReferencingRows int64
+	// UniquePayloads записи verification_data_cache, на которые есть ссылки
+	UniquePayloads int64
+	// LogicalBytes объем данных, если бы каждая строка хранила свою копию
+	LogicalBytes int64
+	// StoredBytes объем данных в кэше
+	StoredBytes int64
+	// RowsWithoutHash строки без хэша, которые шлюз не может прочитать
+	RowsWithoutHash int64
+}
+
+// DataBackfillRepository доступ к строкам verification_data, сохраненным до перехода на кэш
+type DataBackfillRepository interface {
+	HasLegacyDataColumn(ctx context.Context) (bool, error)
+	GetLegacyBatch(ctx context.Context, afterID string, limit int) ([]*types.VerificationDataWithHash, error)
+	LinkToCache(ctx context.Context, rowID, hash, data string) (bool, error)
+	GetStorageStats(ctx context.Context) (*DataStorageStats, error)
+}
+
+type dataBackfillRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewDataBackfillRepository(db *pgxpool.Pool, logger *zap.Logger) DataBackfillRepository {
+	return &dataBackfillRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// HasLegacyDataColumn проверяет, сохранился ли столбец verification_data.data (до миграции 005)
+func (r *dataBackfillRepository) HasLegacyDataColumn(ctx context.Context) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_name = 'verification_data' AND column_name = 'data'
+		)
+	`
+
+	var exists bool
+	if err := r.db.QueryRow(ctx, query).Scan(&exists); err != nil {
+		r.logger.Error("failed to check legacy data column", zap.Error(err))
+		return false, fmt.Errorf("failed to check legacy data column: %w", classify(err))
+	}
+	return exists, nil
+}
+
+// GetLegacyBatch возвращает строки без хэша после afterID. Данные возвращаются в каноническом
+// текстовом виде JSONB, от которого считается хэш, как и в миграции 004.
+func (r *dataBackfillRepository) GetLegacyBatch(ctx context.Context, afterID string, limit int) ([]*types.VerificationDataWithHash, error) {
+	query := `
+		SELECT id, verification_id, data_type, data::text, created_at
+		FROM verification_data
+		WHERE data_hash IS NULL AND data IS NOT NULL AND ($1::uuid IS NULL OR id > $1::uuid)
+		ORDER BY id
+		LIMIT $2
+	`
+
+	var after *string
+	if afterID != "" {
+		after = &afterID
+	}
+
+	rows, err := r.db.Query(ctx, query, after, limit)
+	if err != nil {
+		r.logger.Error("failed to get legacy verification data", zap.Error(err))
+		return nil, fmt.Errorf("failed to get legacy verification data: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var batch []*types.VerificationDataWithHash
+	for rows.Next() {
+		var row types.VerificationDataWithHash
+		if err := rows.Scan(&row.ID, &row.VerificationID, &row.DataType, &row.Data, &row.CreatedAt); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "legacy verification data", err)
+			continue
+		}
+		batch = append(batch, &row)
+	}
+
+	return batch, nil
+}
+
+// LinkToCache сохраняет данные в кэш и переводит строку на ссылку по хэшу.
+// Возвращает true, если в кэше появилась новая запись.
+func (r *dataBackfillRepository) LinkToCache(ctx context.Context, rowID, hash, data string) (bool, error) {
+	var inserted bool
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO verification_data_cache (data_hash, data)
+			VALUES ($1, $2::jsonb)
+			ON CONFLICT (data_hash) DO NOTHING
+		`, hash, data)
+		if err != nil {
+			return err
+		}
+		inserted = tag.RowsAffected() > 0
+
+		_, err = tx.Exec(ctx, `UPDATE verification_data SET data_hash = $2 WHERE id = $1`, rowID, hash)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("failed to link verification data to cache", zap.Error(err), zap.String("id", rowID))
+		return false, fmt.Errorf("failed to link verification data to cache: %w", classify(err))
+	}
+
+	return inserted, nil
+}
+
+func (r *dataBackfillRepository) GetStorageStats(ctx context.Context) (*DataStorageStats, error) {
+	query := `
+		SELECT
+			COUNT(d.id),
+			COUNT(DISTINCT d.data_hash),
+			COALESCE(SUM(octet_length(c.data::text)), 0),
+			(SELECT COALESCE(SUM(octet_length(data::text)), 0) FROM verification_data_cache
+				WHERE data_hash IN (SELECT data_hash FROM verification_data)),
+			(SELECT COUNT(*) FROM verification_data WHERE data_hash IS NULL)
+		FROM verification_data d
+		JOIN verification_data_cache c ON c.data_hash = d.data_hash
+	`
+
+	var stats DataStorageStats
+	err := r.db.QueryRow(ctx, query).Scan(&stats.ReferencingRows, &stats.UniquePayloads, &stats.LogicalBytes, &stats.StoredBytes, &stats.RowsWithoutHash)
+	if err != nil {
+		r.logger.Error("failed to get data storage stats", zap.Error(err))
+		return nil, fmt.Errorf("failed to get data storage stats: %w", classify(err))
+	}
+
+	return &stats, nil
+}
@@ -2,14 +2,54 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"scoring_api_gateway/graph/model"
+
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
 
+// GlobalTenant арендатор общей копии данных компании. Она видна всем арендаторам,
+// пока для типа данных включено совместное использование кэша.
+const GlobalTenant = "*"
+
+type tenantContextKey struct{}
+
+// WithTenant возвращает контекст, запросы из которого видят только данные кэша арендатора
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext возвращает арендатора запроса, если он задан
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantContextKey{}).(string)
+	return tenant, ok && tenant != ""
+}
+
+// CacheKey составной ключ данных компании. Данные разных версий формата поставщика хранятся
+// под разными ключами, поэтому смена формата не возвращает клиентам данные в старой структуре.
+// Ключ начинается с арендатора: данные, доставленные одному арендатору, другим не видны.
+type CacheKey struct {
+	Tenant        string
+	INN           string
+	DataType      model.VerificationDataType
+	SchemaVersion int
+}
+
+func (k CacheKey) String() string {
+	return fmt.Sprintf("%s/%s:%s:v%d", k.Tenant, k.INN, k.DataType, k.SchemaVersion)
+}
+
 type DataCacheRepository interface {
 	GetDataByHash(ctx context.Context, hash string) (string, error)
+	GetByKey(ctx context.Context, key CacheKey) (*model.VerificationData, error)
+	// ListSharing возвращает настройки совместного использования, заданные администраторами
+	ListSharing(ctx context.Context) ([]*model.CacheSharingPolicy, error)
+	SetSharing(ctx context.Context, dataType model.VerificationDataType, shared bool, updatedBy string) (*model.CacheSharingPolicy, error)
 }
 
 type dataCacheRepository struct {
@@ -24,7 +64,8 @@ func NewDataCacheRepository(db *pgxpool.Pool, logger *zap.Logger) DataCacheRepos
 	}
 }
 
-// GetDataByHash получает данные из кэша по хэшу
+// GetDataByHash получает данные из кэша по хэшу. Данные адресуются содержимым и не привязаны
+// к арендатору: доступ к ним проверяется по проверке, которая ссылается на хэш.
 func (r *dataCacheRepository) GetDataByHash(ctx context.Context, hash string) (string, error) {
 	query := `SELECT data FROM verification_data_cache WHERE data_hash = $1`
 
@@ -32,9 +73,121 @@ func (r *dataCacheRepository) GetDataByHash(ctx context.Context, hash string) (s
 	err := r.db.QueryRow(ctx, query, hash).Scan(&data)
 	if err != nil {
 		r.logger.Error("data not found in cache", zap.String("hash", hash), zap.Error(err))
-		return "", fmt.Errorf("data not found in cache for hash %s: %w", hash, err)
+		return "", fmt.Errorf("data not found in cache for hash %s: %w", hash, classify(err))
 	}
 
 	r.logger.Debug("data retrieved from cache", zap.String("hash", hash))
 	return data, nil
 }
+
+// GetByKey возвращает последние доставленные данные компании по составному ключу арендатора
+// из контекста, а для типов данных с совместным использованием - и из общей копии.
+// Арендатор в контексте обязателен. Данных для ключа нет - ErrNotFound.
+func (r *dataCacheRepository) GetByKey(ctx context.Context, key CacheKey) (*model.VerificationData, error) {
+	tenant, ok := TenantFromContext(ctx)
+	if !ok || tenant == GlobalTenant {
+		return nil, fmt.Errorf("tenant is required to read company data cache")
+	}
+	if key.Tenant != "" && key.Tenant != tenant {
+		return nil, fmt.Errorf("cache key of tenant %s requested by tenant %s", key.Tenant, tenant)
+	}
+	key.Tenant = tenant
+
+	query := `
+		SELECT c.data, k.updated_at
+		FROM company_data_cache k
+		JOIN verification_data_cache c ON c.data_hash = k.data_hash
+		WHERE k.inn = $1 AND k.data_type = $2 AND k.schema_version = $3
+		  AND (k.tenant_id = $4 OR (k.tenant_id = $5 AND EXISTS (
+			SELECT 1 FROM cache_sharing s WHERE s.data_type = k.data_type AND s.shared
+		  )))
+		ORDER BY k.updated_at DESC
+		LIMIT 1
+	`
+
+	data := &model.VerificationData{DataType: key.DataType, SchemaVersion: int32(key.SchemaVersion)}
+	var updatedAt time.Time
+	err := r.db.QueryRow(ctx, query, key.INN, string(key.DataType), key.SchemaVersion, key.Tenant, GlobalTenant).Scan(&data.Data, &updatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, notFoundf("no cached data for %s", key)
+		}
+		r.logger.Error("failed to get cached company data", zap.Error(err), zap.Stringer("key", key))
+		return nil, fmt.Errorf("failed to get cached company data: %w", classify(err))
+	}
+
+	data.CreatedAt = updatedAt.Format(time.RFC3339)
+	return data, nil
+}
+
+func (r *dataCacheRepository) ListSharing(ctx context.Context) ([]*model.CacheSharingPolicy, error) {
+	query := `SELECT data_type, shared, updated_by, updated_at FROM cache_sharing ORDER BY data_type`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		r.logger.Error("failed to list cache sharing", zap.Error(err))
+		return nil, fmt.Errorf("failed to list cache sharing: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var policies []*model.CacheSharingPolicy
+	for rows.Next() {
+		var policy model.CacheSharingPolicy
+		var updatedAt time.Time
+		if err := rows.Scan(&policy.DataType, &policy.Shared, &policy.UpdatedBy, &updatedAt); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "cache sharing", err)
+			continue
+		}
+		formatted := updatedAt.Format(time.RFC3339)
+		policy.UpdatedAt = &formatted
+		policies = append(policies, &policy)
+	}
+
+	return policies, rows.Err()
+}
+
+// SetSharing сохраняет настройку типа данных. При включении общая копия сразу заполняется
+// последними данными арендаторов, при выключении - удаляется.
+func (r *dataCacheRepository) SetSharing(ctx context.Context, dataType model.VerificationDataType, shared bool, updatedBy string) (*model.CacheSharingPolicy, error) {
+	policy := &model.CacheSharingPolicy{DataType: dataType, Shared: shared, UpdatedBy: &updatedBy}
+	var updatedAt time.Time
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO cache_sharing (data_type, shared, updated_by, updated_at)
+			VALUES ($1, $2, $3, NOW())
+			ON CONFLICT (data_type) DO UPDATE
+				SET shared = EXCLUDED.shared, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+			RETURNING updated_at
+		`, string(dataType), shared, updatedBy).Scan(&updatedAt)
+		if err != nil {
+			return err
+		}
+
+		if !shared {
+			_, err = tx.Exec(ctx, `DELETE FROM company_data_cache WHERE data_type = $1 AND tenant_id = $2`, string(dataType), GlobalTenant)
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO company_data_cache (tenant_id, inn, data_type, schema_version, data_hash, verification_id, updated_at)
+			SELECT DISTINCT ON (inn, schema_version) $2, inn, data_type, schema_version, data_hash, verification_id, updated_at
+			FROM company_data_cache
+			WHERE data_type = $1 AND tenant_id <> $2
+			ORDER BY inn, schema_version, updated_at DESC
+			ON CONFLICT (tenant_id, inn, data_type, schema_version) DO UPDATE
+				SET data_hash = EXCLUDED.data_hash,
+					verification_id = EXCLUDED.verification_id,
+					updated_at = EXCLUDED.updated_at
+				WHERE company_data_cache.updated_at < EXCLUDED.updated_at
+		`, string(dataType), GlobalTenant)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("failed to set cache sharing", zap.Error(err), zap.String("data_type", string(dataType)))
+		return nil, fmt.Errorf("failed to set cache sharing: %w", classify(err))
+	}
+
+	formatted := updatedAt.Format(time.RFC3339)
+	policy.UpdatedAt = &formatted
+	return policy, nil
+}
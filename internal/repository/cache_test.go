@@ -151,3 +151,31 @@ func containsError(got, want string) bool {
 	return len(got) > 0 && len(want) > 0 && (got == want ||
 		(len(got) >= len(want) && got[:len(want)] == want))
 }
+
+func TestGetByKeyRequiresTenant(t *testing.T) {
+	repo := &dataCacheRepository{logger: zaptest.NewLogger(t)}
+	key := CacheKey{Tenant: "other.com", INN: "7707083893", DataType: "BASIC_INFORMATION", SchemaVersion: 1}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		expectedError string
+	}{
+		{name: "no_tenant", ctx: context.Background(), expectedError: "tenant is required"},
+		{name: "global_tenant", ctx: WithTenant(context.Background(), GlobalTenant), expectedError: "tenant is required"},
+		{name: "key_of_other_tenant", ctx: WithTenant(context.Background(), "example.com"), expectedError: "cache key of tenant other.com requested by tenant example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := repo.GetByKey(tt.ctx, key)
+			if err == nil || !containsError(err.Error(), tt.expectedError) {
+				t.Errorf("expected error %q, but got %v", tt.expectedError, err)
+			}
+		})
+	}
+
+	if got := key.String(); got != "other.com/7707083893:BASIC_INFORMATION:v1" {
+		t.Errorf("unexpected key %s", got)
+	}
+}
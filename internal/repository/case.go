@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// CaseParty проверка в деле и роль компании по отношению к субъекту дела
+type CaseParty struct {
+	VerificationID string
+	Role           model.CasePartyRole
+}
+
+// CaseRepository хранит дела, объединяющие проверки связанных компаний. Дело принадлежит
+// арендатору автора: дело другого арендатора считается ненайденным.
+type CaseRepository interface {
+	// Create сохраняет дело с проверками parties и заполняет его идентификатор и время создания
+	Create(ctx context.Context, tenant string, c *model.Case, parties []CaseParty) error
+	// GetByID возвращает дело с проверками
+	GetByID(ctx context.Context, tenant, id string) (*model.Case, error)
+	// List возвращает дела арендатора с проверками, последние измененные первыми
+	List(ctx context.Context, tenant string, limit *int32, offset *int32) ([]*model.Case, error)
+	// AddParty добавляет проверку в дело или меняет ее роль
+	AddParty(ctx context.Context, tenant, caseID string, party CaseParty) error
+	RemoveParty(ctx context.Context, tenant, caseID, verificationID string) error
+}
+
+type caseRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewCaseRepository(db *pgxpool.Pool, logger *zap.Logger) CaseRepository {
+	return &caseRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *caseRepository) Create(ctx context.Context, tenant string, c *model.Case, parties []CaseParty) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var createdAt, updatedAt time.Time
+		err := tx.QueryRow(ctx, `
+			INSERT INTO cases (tenant, name, created_by)
+			VALUES ($1, $2, $3)
+			RETURNING id, created_at, updated_at
+		`, tenant, c.Name, c.CreatedBy).Scan(&c.ID, &createdAt, &updatedAt)
+		if err != nil {
+			return err
+		}
+		c.CreatedAt = createdAt.Format(time.RFC3339)
+		c.UpdatedAt = updatedAt.Format(time.RFC3339)
+
+		for _, party := range parties {
+			if err := upsertCaseParty(ctx, tx, c.ID, party); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("failed to create case", zap.Error(err), zap.String("tenant", tenant))
+		return fmt.Errorf("failed to create case: %w", classify(err))
+	}
+	return nil
+}
+
+func (r *caseRepository) GetByID(ctx context.Context, tenant, id string) (*model.Case, error) {
+	query := `
+		SELECT id, name, created_by, created_at, updated_at
+		FROM cases
+		WHERE id = $1 AND tenant = $2
+	`
+
+	c, err := scanCase(r.db.QueryRow(ctx, query, id, tenant))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, notFoundf("case not found: %s", id)
+		}
+		r.logger.Error("failed to get case", zap.Error(err), zap.String("id", id))
+		return nil, fmt.Errorf("failed to get case: %w", classify(err))
+	}
+
+	if err := r.loadParties(ctx, []*model.Case{c}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (r *caseRepository) List(ctx context.Context, tenant string, limit *int32, offset *int32) ([]*model.Case, error) {
+	query, args := newSelect(`SELECT id, name, created_by, created_at, updated_at FROM cases`).
+		Where("tenant = ?", tenant).
+		OrderBy("updated_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Build()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("failed to list cases", zap.Error(err), zap.String("tenant", tenant))
+		return nil, fmt.Errorf("failed to list cases: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var cases []*model.Case
+	for rows.Next() {
+		c, err := scanCase(rows)
+		if err != nil {
+			reportScanFailure(ctx, r.logger, rows, "case", err)
+			continue
+		}
+		cases = append(cases, c)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("failed to read cases", zap.Error(err))
+		return nil, fmt.Errorf("failed to read cases: %w", classify(err))
+	}
+
+	if err := r.loadParties(ctx, cases); err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
+
+func (r *caseRepository) AddParty(ctx context.Context, tenant, caseID string, party CaseParty) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := touchCase(ctx, tx, tenant, caseID); err != nil {
+			return err
+		}
+		return upsertCaseParty(ctx, tx, caseID, party)
+	})
+	if err != nil {
+		r.logger.Error("failed to add verification to case", zap.Error(err),
+			zap.String("case_id", caseID), zap.String("verification_id", party.VerificationID))
+		return fmt.Errorf("failed to add verification to case: %w", classify(err))
+	}
+	return nil
+}
+
+func (r *caseRepository) RemoveParty(ctx context.Context, tenant, caseID, verificationID string) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := touchCase(ctx, tx, tenant, caseID); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `DELETE FROM case_verifications WHERE case_id = $1 AND verification_id = $2`, caseID, verificationID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return notFoundf("verification %s is not in case %s", verificationID, caseID)
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("failed to remove verification from case", zap.Error(err),
+			zap.String("case_id", caseID), zap.String("verification_id", verificationID))
+		return fmt.Errorf("failed to remove verification from case: %w", classify(err))
+	}
+	return nil
+}
+
+// touchCase отмечает изменение дела арендатора. Дела другого арендатора - ErrNotFound.
+func touchCase(ctx context.Context, tx pgx.Tx, tenant, caseID string) error {
+	tag, err := tx.Exec(ctx, `UPDATE cases SET updated_at = NOW() WHERE id = $1 AND tenant = $2`, caseID, tenant)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return notFoundf("case not found: %s", caseID)
+	}
+	return nil
+}
+
+func upsertCaseParty(ctx context.Context, tx pgx.Tx, caseID string, party CaseParty) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO case_verifications (case_id, verification_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (case_id, verification_id) DO UPDATE SET role = EXCLUDED.role
+	`, caseID, party.VerificationID, string(party.Role))
+	return err
+}
+
+// loadParties заполняет проверки дел двумя запросами на все дела сразу
+func (r *caseRepository) loadParties(ctx context.Context, cases []*model.Case) error {
+	if len(cases) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*model.Case, len(cases))
+	ids := make([]string, len(cases))
+	for i, c := range cases {
+		c.Parties = []*model.CaseParty{}
+		byID[c.ID] = c
+		ids[i] = c.ID
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT case_id, verification_id, role, added_at
+		FROM case_verifications
+		WHERE case_id = ANY($1)
+		ORDER BY added_at, verification_id
+	`, ids)
+	if err != nil {
+		r.logger.Error("failed to get case verifications", zap.Error(err))
+		return fmt.Errorf("failed to get case verifications: %w", classify(err))
+	}
+
+	type member struct {
+		caseID string
+		party  *model.CaseParty
+	}
+	var members []member
+	var verificationIDs []string
+	for rows.Next() {
+		var caseID, verificationID string
+		var party model.CaseParty
+		var addedAt time.Time
+		if err := rows.Scan(&caseID, &verificationID, &party.Role, &addedAt); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "case verification", err)
+			continue
+		}
+		party.AddedAt = addedAt.Format(time.RFC3339)
+		party.Verification = &model.Verification{ID: verificationID}
+		members = append(members, member{caseID: caseID, party: &party})
+		verificationIDs = append(verificationIDs, verificationID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		r.logger.Error("failed to read case verifications", zap.Error(err))
+		return fmt.Errorf("failed to read case verifications: %w", classify(err))
+	}
+	if len(members) == 0 {
+		return nil
+	}
+
+	rows, err = r.db.Query(ctx, `SELECT `+verificationColumns+`
+		FROM verifications
+		LEFT JOIN verification_external_refs ON verification_id = id
+		WHERE id = ANY($1)
+	`, verificationIDs)
+	if err != nil {
+		r.logger.Error("failed to get case verifications", zap.Error(err))
+		return fmt.Errorf("failed to get case verifications: %w", classify(err))
+	}
+	defer rows.Close()
+
+	verifications := make(map[string]*model.Verification, len(verificationIDs))
+	for rows.Next() {
+		v, err := scanVerification(rows)
+		if err != nil {
+			reportScanFailure(ctx, r.logger, rows, "verification", err)
+			continue
+		}
+		verifications[v.ID] = model.VerificationFromDomain(v)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("failed to read case verifications", zap.Error(err))
+		return fmt.Errorf("failed to read case verifications: %w", classify(err))
+	}
+
+	// Проверка, которую не удалось прочитать, уже учтена как пропущенная строка
+	for _, m := range members {
+		if v, ok := verifications[m.party.Verification.ID]; ok {
+			m.party.Verification = v
+			byID[m.caseID].Parties = append(byID[m.caseID].Parties, m.party)
+		}
+	}
+	return nil
+}
+
+func scanCase(row pgx.Row) (*model.Case, error) {
+	var c model.Case
+	var createdAt, updatedAt time.Time
+	if err := row.Scan(&c.ID, &c.Name, &c.CreatedBy, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	c.CreatedAt = createdAt.Format(time.RFC3339)
+	c.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return &c, nil
+}
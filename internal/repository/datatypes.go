@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"scoring_api_gateway/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// VerificationDataTypeEnum перечисление Postgres, которым ограничены значения столбцов
+// requested_data_types и missing_data_types
+const VerificationDataTypeEnum = "verification_data_type"
+
+// PostgresEnums значения перечислений Postgres, которые повторяют перечисления Go. Значения,
+// которых нет в миграциях, добавляет миграция, созданная командой enummigrate.
+func PostgresEnums() map[string][]string {
+	values := make([]string, len(domain.AllDataTypes))
+	for i, dataType := range domain.AllDataTypes {
+		values[i] = string(dataType)
+	}
+	return map[string][]string{VerificationDataTypeEnum: values}
+}
+
+// declaredDataTypes типы данных, объявленные в конфигурации шлюза, которые можно читать наравне
+// со значениями domain.DataType
+var declaredDataTypes atomic.Pointer[map[domain.DataType]bool]
+
+// DeclareDataTypes добавляет типы данных, объявленные в конфигурации, в перечисление
+// verification_data_type и разрешает читать их из столбцов типов данных проверки. Значения
+// перечисления Postgres не удаляются: тип, убранный из конфигурации, база по-прежнему принимает,
+// но строки с ним шлюз больше не читает.
+func DeclareDataTypes(ctx context.Context, db *pgxpool.Pool, dataTypes []domain.DataType) error {
+	for _, dataType := range dataTypes {
+		// ADD VALUE не принимает параметры; имена объявленных типов - только A-Z, 0-9 и '_'
+		query := fmt.Sprintf(`ALTER TYPE %s ADD VALUE IF NOT EXISTS '%s'`, VerificationDataTypeEnum, dataType)
+		if _, err := db.Exec(ctx, query); err != nil {
+			return fmt.Errorf("failed to declare data type %s: %w", dataType, classify(err))
+		}
+	}
+	declare(dataTypes)
+	return nil
+}
+
+func declare(dataTypes []domain.DataType) {
+	declared := make(map[domain.DataType]bool, len(dataTypes))
+	for _, dataType := range dataTypes {
+		declared[dataType] = true
+	}
+	declaredDataTypes.Store(&declared)
+}
+
+func isDeclared(dataType domain.DataType) bool {
+	declared := declaredDataTypes.Load()
+	return declared != nil && (*declared)[dataType]
+}
+
+// dataTypeArray читает столбец TEXT[] с типами данных проверки. Значение, которого нет
+// в domain.DataType и среди объявленных типов, - ошибка чтения строки, а не тип, о котором
+// шлюз ничего не знает.
+type dataTypeArray []domain.DataType
+
+func (a *dataTypeArray) SetDimensions(dimensions []pgtype.ArrayDimension) error {
+	if dimensions == nil {
+		*a = nil
+		return nil
+	}
+	if len(dimensions) > 1 {
+		return fmt.Errorf("data types must be a one-dimensional array, got %d dimensions", len(dimensions))
+	}
+
+	var count int32
+	if len(dimensions) == 1 {
+		count = dimensions[0].Length
+	}
+	*a = make(dataTypeArray, count)
+	return nil
+}
+
+func (a *dataTypeArray) ScanIndex(i int) any {
+	return &dataTypeElement{dst: &(*a)[i]}
+}
+
+func (a *dataTypeArray) ScanIndexType() any {
+	return &dataTypeElement{}
+}
+
+type dataTypeElement struct {
+	dst *domain.DataType
+}
+
+func (e *dataTypeElement) ScanText(v pgtype.Text) error {
+	if !v.Valid {
+		return fmt.Errorf("data type cannot be NULL")
+	}
+	dataType := domain.DataType(v.String)
+	if !dataType.IsValid() && !isDeclared(dataType) {
+		return fmt.Errorf("unknown verification data type %q", v.String)
+	}
+	*e.dst = dataType
+	return nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/migrations"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestDataTypeArray(t *testing.T) {
+	m := pgtype.NewMap()
+	binary, err := m.Encode(pgtype.TextArrayOID, pgtype.BinaryFormatCode, []string{"BASIC_INFORMATION", "ACTIVITIES"}, nil)
+	if err != nil {
+		t.Fatalf("failed to encode array: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		format        int16
+		src           []byte
+		expected      []domain.DataType
+		expectedError string
+	}{
+		{name: "text", format: pgtype.TextFormatCode, src: []byte("{ARBITRAGE_STATISTICS}"), expected: []domain.DataType{domain.DataTypeArbitrageStatistics}},
+		{name: "binary", format: pgtype.BinaryFormatCode, src: binary, expected: []domain.DataType{domain.DataTypeBasicInformation, domain.DataTypeActivities}},
+		{name: "empty", format: pgtype.TextFormatCode, src: []byte("{}"), expected: []domain.DataType{}},
+		{name: "unknown", format: pgtype.TextFormatCode, src: []byte("{ACTIVITIES,CREDIT_HISTORY}"), expectedError: `unknown verification data type "CREDIT_HISTORY"`},
+		{name: "null_element", format: pgtype.TextFormatCode, src: []byte("{NULL}"), expectedError: "cannot be NULL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dataTypes []domain.DataType
+			err := m.Scan(pgtype.TextArrayOID, tt.format, tt.src, (*dataTypeArray)(&dataTypes))
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(dataTypes, tt.expected) || dataTypes == nil {
+				t.Errorf("expected %v, but got %v", tt.expected, dataTypes)
+			}
+		})
+	}
+}
+
+func TestDataTypeArrayDeclaredTypes(t *testing.T) {
+	declare([]domain.DataType{"CREDIT_HISTORY"})
+	t.Cleanup(func() { declare(nil) })
+
+	var dataTypes []domain.DataType
+	err := pgtype.NewMap().Scan(pgtype.TextArrayOID, pgtype.TextFormatCode, []byte("{ACTIVITIES,CREDIT_HISTORY}"), (*dataTypeArray)(&dataTypes))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(dataTypes, []domain.DataType{domain.DataTypeActivities, "CREDIT_HISTORY"}) {
+		t.Errorf("expected the declared data type to be read, but got %v", dataTypes)
+	}
+}
+
+// Перечисления Postgres должны содержать все значения перечислений Go, иначе проверку
+// с новым типом данных не удастся сохранить. Недостающую миграцию создает go run ./cmd/enummigrate.
+func TestPostgresEnumsInSync(t *testing.T) {
+	all, err := migrations.Load(os.DirFS("../../migrations"))
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	for name, values := range PostgresEnums() {
+		if missing := migrations.MissingEnumValues(all, name, values); len(missing) > 0 {
+			t.Errorf("enum %s has no migration for values %v, run go run ./cmd/enummigrate", name, missing)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"scoring_api_gateway/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// DemoRepository демонстрационная организация для playground: ключ только на чтение
+// и проверки с синтетическими данными
+type DemoRepository interface {
+	// EnsureAccount создает организацию песочницы и ключ только на чтение. Прежние ключи
+	// с тем же именем отзываются, поэтому повторный запуск с новым ключом заменяет старый.
+	EnsureAccount(ctx context.Context, account *DemoAccount) error
+	// ReplaceVerifications заменяет проверки песочницы автора переданными
+	ReplaceVerifications(ctx context.Context, authorEmail string, verifications []*domain.Verification) error
+}
+
+// DemoAccount демонстрационная организация и ее ключ
+type DemoAccount struct {
+	Organization string
+	Name         string
+	ServiceEmail string
+	KeyName      string
+	KeyHash      string
+}
+
+type demoRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewDemoRepository(db *pgxpool.Pool, logger *zap.Logger) DemoRepository {
+	return &demoRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *demoRepository) EnsureAccount(ctx context.Context, account *DemoAccount) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO organizations (id, name, sandbox)
+			VALUES ($1, $2, true)
+			ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, sandbox = true, updated_at = NOW()
+		`, account.Organization, account.Name)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE api_keys SET revoked_at = NOW()
+			WHERE name = $1 AND key_hash <> $2 AND revoked_at IS NULL
+		`, account.KeyName, account.KeyHash)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO api_keys (name, key_hash, service_email, allowed_operations, sandbox)
+			VALUES ($1, $2, $3, '{read}', true)
+			ON CONFLICT (key_hash) DO UPDATE SET
+				name = EXCLUDED.name,
+				service_email = EXCLUDED.service_email,
+				allowed_operations = EXCLUDED.allowed_operations,
+				allowed_data_types = '{}',
+				sandbox = true,
+				revoked_at = NULL
+		`, account.KeyName, account.KeyHash, account.ServiceEmail)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("failed to ensure demo account", zap.Error(err), zap.String("organization", account.Organization))
+		return fmt.Errorf("failed to ensure demo account: %w", classify(err))
+	}
+
+	return nil
+}
+
+func (r *demoRepository) ReplaceVerifications(ctx context.Context, authorEmail string, verifications []*domain.Verification) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM verifications WHERE author_email = $1 AND sandbox`, authorEmail); err != nil {
+			return err
+		}
+		for _, verification := range verifications {
+			if err := insertVerificationWithData(ctx, tx, verification); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("failed to replace demo verifications", zap.Error(err), zap.String("author_email", authorEmail))
+		return fmt.Errorf("failed to replace demo verifications: %w", classify(err))
+	}
+
+	return nil
+}
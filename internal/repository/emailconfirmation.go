@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/internal/messaging"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// EmailConfirmationRepository хранит подтверждения email авторов и запросы на проверку,
+// удержанные до подтверждения
+type EmailConfirmationRepository interface {
+	// IsEmailConfirmed сообщает, что автор подтвердил email или от его имени уже есть проверки
+	IsEmailConfirmed(ctx context.Context, email string) (bool, error)
+	// HoldUntilConfirmed сохраняет запрос автора до подтверждения его email
+	HoldUntilConfirmed(ctx context.Context, email string, msg *messaging.OutboxMessage) error
+	// GetHeld возвращает удержанный запрос проверки, nil - запроса нет
+	GetHeld(ctx context.Context, id string) (*messaging.OutboxMessage, error)
+	// IssueConfirmationToken сохраняет новый токен подтверждения, если email не подтвержден и
+	// предыдущий токен отправлен раньше resendBefore. false - новое письмо отправлять не нужно.
+	IssueConfirmationToken(ctx context.Context, email, tokenHash string, expiresAt, resendBefore time.Time) (bool, error)
+	// ConfirmEmail подтверждает email по действующему токену и переносит удержанные запросы автора
+	// в outbox. Возвращает email и число перенесенных запросов, ErrNotFound - токен неизвестен или истек.
+	ConfirmEmail(ctx context.Context, tokenHash string) (string, int, error)
+	// PruneHolds удаляет запросы, удержанные раньше before
+	PruneHolds(ctx context.Context, before time.Time) (int64, error)
+}
+
+type emailConfirmationRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewEmailConfirmationRepository(db *pgxpool.Pool, logger *zap.Logger) EmailConfirmationRepository {
+	return &emailConfirmationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *emailConfirmationRepository) IsEmailConfirmed(ctx context.Context, email string) (bool, error) {
+	var confirmed bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM email_confirmations WHERE email = $1 AND confirmed_at IS NOT NULL)
+			OR EXISTS (SELECT 1 FROM verifications WHERE author_email = $1)
+	`, email).Scan(&confirmed)
+	if err != nil {
+		r.logger.Error("failed to check email confirmation", zap.Error(err), zap.String("email", email))
+		return false, fmt.Errorf("failed to check email confirmation: %w", classify(err))
+	}
+	return confirmed, nil
+}
+
+func (r *emailConfirmationRepository) HoldUntilConfirmed(ctx context.Context, email string, msg *messaging.OutboxMessage) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO email_confirmation_holds (id, author_email, tenant, priority, payload)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE SET priority = EXCLUDED.priority, payload = EXCLUDED.payload
+		RETURNING created_at
+	`, msg.ID, email, msg.Tenant, string(msg.Priority), []byte(msg.Payload)).Scan(&msg.CreatedAt)
+	if err != nil {
+		r.logger.Error("failed to hold verification request", zap.Error(err), zap.String("id", msg.ID))
+		return fmt.Errorf("failed to hold verification request: %w", classify(err))
+	}
+	return nil
+}
+
+func (r *emailConfirmationRepository) GetHeld(ctx context.Context, id string) (*messaging.OutboxMessage, error) {
+	var msg messaging.OutboxMessage
+	var priority string
+	var payload []byte
+	err := r.db.QueryRow(ctx, `
+		SELECT id, tenant, priority, payload, created_at
+		FROM email_confirmation_holds
+		WHERE id = $1
+	`, id).Scan(&msg.ID, &msg.Tenant, &priority, &payload, &msg.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error("failed to get held verification request", zap.Error(err), zap.String("id", id))
+		return nil, fmt.Errorf("failed to get held verification request: %w", classify(err))
+	}
+	msg.Priority = messaging.Priority(priority)
+	msg.Payload = payload
+	msg.AvailableAt = msg.CreatedAt
+	return &msg, nil
+}
+
+func (r *emailConfirmationRepository) IssueConfirmationToken(ctx context.Context, email, tokenHash string, expiresAt, resendBefore time.Time) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO email_confirmations (email, token_hash, sent_at, expires_at)
+		VALUES ($1, $2, NOW(), $3)
+		ON CONFLICT (email) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash, sent_at = EXCLUDED.sent_at, expires_at = EXCLUDED.expires_at
+		WHERE email_confirmations.confirmed_at IS NULL
+		  AND (email_confirmations.sent_at IS NULL OR email_confirmations.sent_at < $4)
+	`, email, tokenHash, expiresAt, resendBefore)
+	if err != nil {
+		r.logger.Error("failed to issue confirmation token", zap.Error(err), zap.String("email", email))
+		return false, fmt.Errorf("failed to issue confirmation token: %w", classify(err))
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *emailConfirmationRepository) ConfirmEmail(ctx context.Context, tokenHash string) (string, int, error) {
+	var email string
+	var released int
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			UPDATE email_confirmations
+			SET confirmed_at = NOW(), token_hash = NULL
+			WHERE token_hash = $1 AND expires_at > NOW() AND confirmed_at IS NULL
+			RETURNING email
+		`, tokenHash).Scan(&email)
+		if err != nil {
+			return err
+		}
+
+		// Удержанные запросы отправляет воркерам задача outbox_relay
+		tag, err := tx.Exec(ctx, `
+			WITH released AS (
+				DELETE FROM email_confirmation_holds WHERE author_email = $1
+				RETURNING id, tenant, priority, payload
+			)
+			INSERT INTO outbox_messages (id, tenant, priority, payload, available_at)
+			SELECT id, tenant, priority, payload, NOW() FROM released
+			ON CONFLICT (id) DO NOTHING
+		`, email)
+		if err != nil {
+			return err
+		}
+		released = int(tag.RowsAffected())
+		return nil
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", 0, notFoundf("confirmation token is invalid or expired")
+	}
+	if err != nil {
+		r.logger.Error("failed to confirm email", zap.Error(err))
+		return "", 0, fmt.Errorf("failed to confirm email: %w", classify(err))
+	}
+	return email, released, nil
+}
+
+func (r *emailConfirmationRepository) PruneHolds(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM email_confirmation_holds WHERE created_at < $1`, before)
+	if err != nil {
+		r.logger.Error("failed to prune held verification requests", zap.Error(err))
+		return 0, fmt.Errorf("failed to prune held verification requests: %w", classify(err))
+	}
+	return tag.RowsAffected(), nil
+}
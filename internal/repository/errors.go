@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Виды ошибок хранилища. Сервисы и резолверы проверяют их через errors.Is, а не по тексту ошибки.
+var (
+	// ErrNotFound запись не найдена
+	ErrNotFound = errors.New("not found")
+	// ErrConflict запись нарушает ограничение уникальности или ссылочной целостности
+	ErrConflict = errors.New("conflict")
+	// ErrSerialization транзакция прервана из-за конкурентного изменения, ее можно повторить
+	ErrSerialization = errors.New("serialization failure")
+	// ErrUnavailable база недоступна: соединение разорвано, не устанавливается или перегружено
+	ErrUnavailable = errors.New("database unavailable")
+	// ErrCrossRegion проверка хранится в регионе, отличном от региона арендатора вызывающего
+	ErrCrossRegion = errors.New("cross-region access")
+)
+
+// Коды ошибок PostgreSQL
+const (
+	uniqueViolation      = "23505"
+	foreignKeyViolation  = "23503"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+	// Класс 08 - ошибки соединения
+	connectionExceptionClass = "08"
+	adminShutdown            = "57P01"
+	crashShutdown            = "57P02"
+	cannotConnectNow         = "57P03"
+	tooManyConnections       = "53300"
+)
+
+// Error ошибка хранилища определенного вида. Текст ошибки остается прежним,
+// а errors.Is(err, ErrNotFound) и т.п. сравнивает ее с видом.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+func notFoundf(format string, args ...any) error {
+	return &Error{Kind: ErrNotFound, Err: fmt.Errorf(format, args...)}
+}
+
+func conflictf(format string, args ...any) error {
+	return &Error{Kind: ErrConflict, Err: fmt.Errorf(format, args...)}
+}
+
+// classify помечает ошибку драйвера видом, если он известен. Остальные ошибки возвращаются без изменений.
+func classify(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &Error{Kind: ErrNotFound, Err: err}
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		if connectionLost(err) {
+			return &Error{Kind: ErrUnavailable, Err: err}
+		}
+		return err
+	}
+	switch pgErr.Code {
+	case uniqueViolation, foreignKeyViolation:
+		return &Error{Kind: ErrConflict, Err: err}
+	case serializationFailure, deadlockDetected:
+		return &Error{Kind: ErrSerialization, Err: err}
+	case adminShutdown, crashShutdown, cannotConnectNow, tooManyConnections:
+		return &Error{Kind: ErrUnavailable, Err: err}
+	}
+	if strings.HasPrefix(pgErr.Code, connectionExceptionClass) {
+		return &Error{Kind: ErrUnavailable, Err: err}
+	}
+	return err
+}
+
+// Unavailable сообщает, что ошибка вызвана недоступностью базы, а не запросом. Проверяет
+// и ошибки, которые репозиторий вернул без classify.
+func Unavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrUnavailable) {
+		return true
+	}
+	return errors.Is(classify(err), ErrUnavailable)
+}
+
+// connectionLost распознает ошибки сети и пула соединений. Отмена запроса клиентом
+// недоступностью не считается, а истечение срока запроса - считается: база не успела ответить.
+func connectionLost(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || pgconn.Timeout(err)
+}
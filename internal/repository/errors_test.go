@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{name: "no_rows", err: pgx.ErrNoRows, expected: ErrNotFound},
+		{name: "unique_violation", err: &pgconn.PgError{Code: "23505"}, expected: ErrConflict},
+		{name: "foreign_key_violation", err: &pgconn.PgError{Code: "23503"}, expected: ErrConflict},
+		{name: "serialization_failure", err: &pgconn.PgError{Code: "40001"}, expected: ErrSerialization},
+		{name: "deadlock", err: fmt.Errorf("batch: %w", &pgconn.PgError{Code: "40P01"}), expected: ErrSerialization},
+		{name: "other_pg_error", err: &pgconn.PgError{Code: "42P01"}},
+		{name: "connection_error", err: errors.New("connection refused")},
+		{name: "connection_reset", err: fmt.Errorf("read: %w", syscall.ECONNRESET), expected: ErrUnavailable},
+		{name: "unexpected_eof", err: io.ErrUnexpectedEOF, expected: ErrUnavailable},
+		{name: "admin_shutdown", err: &pgconn.PgError{Code: "57P01"}, expected: ErrUnavailable},
+		{name: "connection_exception", err: &pgconn.PgError{Code: "08006"}, expected: ErrUnavailable},
+		{name: "canceled", err: context.Canceled},
+	}
+
+	kinds := []error{ErrNotFound, ErrConflict, ErrSerialization, ErrUnavailable}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("failed to get verification: %w", classify(tt.err))
+
+			for _, kind := range kinds {
+				if got := errors.Is(err, kind); got != (kind == tt.expected) {
+					t.Errorf("errors.Is(%v, %v) = %v", err, kind, got)
+				}
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected original error to be preserved in %v", err)
+			}
+			if err.Error() != "failed to get verification: "+tt.err.Error() {
+				t.Errorf("expected message to stay unchanged, got %q", err.Error())
+			}
+		})
+	}
+}
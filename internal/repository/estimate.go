@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// CompletionEstimate ожидаемое время завершения проверки
+type CompletionEstimate struct {
+	VerificationID string
+	// ProcessingStartAt когда воркер должен начать проверку с учетом отложенной публикации и очередей
+	ProcessingStartAt     time.Time
+	EstimatedCompletionAt time.Time
+}
+
+// PendingEstimate оценка незавершенной проверки с типами данных, которые еще не доставлены
+type PendingEstimate struct {
+	CompletionEstimate
+	Undelivered []domain.DataType
+}
+
+// EstimateRepository хранит ожидаемое время завершения проверок
+type EstimateRepository interface {
+	SaveEstimate(ctx context.Context, estimate *CompletionEstimate) error
+	// ListPendingEstimates возвращает оценки незавершенных проверок, начиная с давно не обновленных
+	ListPendingEstimates(ctx context.Context, limit int) ([]*PendingEstimate, error)
+	// PruneEstimates удаляет оценки проверок в финальном статусе
+	PruneEstimates(ctx context.Context) (int64, error)
+}
+
+type estimateRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewEstimateRepository(db *pgxpool.Pool, logger *zap.Logger) EstimateRepository {
+	return &estimateRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *estimateRepository) SaveEstimate(ctx context.Context, estimate *CompletionEstimate) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO verification_estimates (verification_id, processing_start_at, estimated_completion_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (verification_id) DO UPDATE SET
+			processing_start_at = EXCLUDED.processing_start_at,
+			estimated_completion_at = EXCLUDED.estimated_completion_at,
+			updated_at = NOW()
+	`, estimate.VerificationID, estimate.ProcessingStartAt, estimate.EstimatedCompletionAt)
+	if err != nil {
+		r.logger.Error("failed to save completion estimate", zap.Error(err), zap.String("verification_id", estimate.VerificationID))
+		return fmt.Errorf("failed to save completion estimate: %w", classify(err))
+	}
+	return nil
+}
+
+func (r *estimateRepository) ListPendingEstimates(ctx context.Context, limit int) ([]*PendingEstimate, error) {
+	query := `
+		SELECT e.verification_id, e.processing_start_at, e.estimated_completion_at,
+			ARRAY(
+				SELECT requested.data_type
+				FROM unnest(v.requested_data_types) AS requested(data_type)
+				WHERE NOT EXISTS (
+					SELECT 1 FROM verification_data d
+					WHERE d.verification_id = v.id AND d.data_type = requested.data_type
+				)
+			)
+		FROM verification_estimates e
+		JOIN verifications v ON v.id = e.verification_id
+		WHERE v.status IN ('PENDING', 'IN_PROCESS', 'PROCESSING')
+		ORDER BY e.updated_at
+		LIMIT $1
+	`
+
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		r.logger.Error("failed to list pending completion estimates", zap.Error(err))
+		return nil, fmt.Errorf("failed to list pending completion estimates: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var estimates []*PendingEstimate
+	for rows.Next() {
+		var estimate PendingEstimate
+		if err := rows.Scan(&estimate.VerificationID, &estimate.ProcessingStartAt, &estimate.EstimatedCompletionAt,
+			(*dataTypeArray)(&estimate.Undelivered)); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "completion estimate", err)
+			continue
+		}
+		estimates = append(estimates, &estimate)
+	}
+
+	return estimates, rows.Err()
+}
+
+func (r *estimateRepository) PruneEstimates(ctx context.Context) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM verification_estimates e
+		USING verifications v
+		WHERE v.id = e.verification_id AND v.status NOT IN ('PENDING', 'IN_PROCESS', 'PROCESSING')
+	`)
+	if err != nil {
+		r.logger.Error("failed to prune completion estimates", zap.Error(err))
+		return 0, fmt.Errorf("failed to prune completion estimates: %w", classify(err))
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"scoring_api_gateway/internal/eventstore"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type EventStoreRepository interface {
+	Append(ctx context.Context, verificationID string, expectedVersion int, events []eventstore.Event) error
+	Load(ctx context.Context, verificationID string) ([]eventstore.Event, error)
+	ListStreamIDs(ctx context.Context, afterID string, limit int) ([]string, error)
+	UpsertReadModel(ctx context.Context, state *eventstore.State) error
+}
+
+type eventStoreRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewEventStoreRepository(db *pgxpool.Pool, logger *zap.Logger) EventStoreRepository {
+	return &eventStoreRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Append дописывает события в поток проверки. Если поток уже продвинулся дальше expectedVersion,
+// возвращает eventstore.ErrVersionConflict.
+func (r *eventStoreRepository) Append(ctx context.Context, verificationID string, expectedVersion int, events []eventstore.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO verification_event_store (verification_id, version, event_type, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	batch := &pgx.Batch{}
+	for i, event := range events {
+		batch.Queue(query, verificationID, expectedVersion+i+1, string(event.Type), []byte(event.Payload), event.OccurredAt)
+	}
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return conflictf("failed to append events to %s: %w", verificationID, eventstore.ErrVersionConflict)
+		}
+		r.logger.Error("failed to append events", zap.Error(err), zap.String("verification_id", verificationID))
+		return fmt.Errorf("failed to append events: %w", classify(err))
+	}
+
+	return nil
+}
+
+// Load возвращает поток событий проверки в порядке версий
+func (r *eventStoreRepository) Load(ctx context.Context, verificationID string) ([]eventstore.Event, error) {
+	query := `
+		SELECT verification_id, version, event_type, payload, occurred_at
+		FROM verification_event_store
+		WHERE verification_id = $1
+		ORDER BY version
+	`
+
+	rows, err := r.db.Query(ctx, query, verificationID)
+	if err != nil {
+		r.logger.Error("failed to load events", zap.Error(err), zap.String("verification_id", verificationID))
+		return nil, fmt.Errorf("failed to load events: %w", classify(err))
+	}
+	defer rows.Close()
+
+	// Пропуск события исказил бы проекцию, поэтому ошибки сканирования не игнорируются
+	var events []eventstore.Event
+	for rows.Next() {
+		var event eventstore.Event
+		var eventType string
+		if err := rows.Scan(&event.VerificationID, &event.Version, &eventType, &event.Payload, &event.OccurredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", classify(err))
+		}
+		event.Type = eventstore.EventType(eventType)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// ListStreamIDs возвращает идентификаторы потоков после afterID для постраничного обхода
+func (r *eventStoreRepository) ListStreamIDs(ctx context.Context, afterID string, limit int) ([]string, error) {
+	query := `
+		SELECT DISTINCT verification_id::text
+		FROM verification_event_store
+		WHERE verification_id::text > $1
+		ORDER BY 1
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, afterID, limit)
+	if err != nil {
+		r.logger.Error("failed to list event streams", zap.Error(err))
+		return nil, fmt.Errorf("failed to list event streams: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan event stream id: %w", classify(err))
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// UpsertReadModel записывает проекцию в таблицу verifications
+func (r *eventStoreRepository) UpsertReadModel(ctx context.Context, state *eventstore.State) error {
+	query := `
+		INSERT INTO verifications (id, inn, status, author_email, requested_data_types, risk_level, missing_data_types, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			risk_level = EXCLUDED.risk_level,
+			missing_data_types = EXCLUDED.missing_data_types,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	requested := make([]string, 0, len(state.RequestedDataTypes))
+	for _, dataType := range state.RequestedDataTypes {
+		requested = append(requested, string(dataType))
+	}
+	missing := make([]string, 0, len(state.MissingDataTypes))
+	for _, dataType := range state.MissingDataTypes {
+		missing = append(missing, string(dataType))
+	}
+
+	var riskLevel *string
+	if state.RiskLevel != nil {
+		level := string(*state.RiskLevel)
+		riskLevel = &level
+	}
+
+	_, err := r.db.Exec(ctx, query, state.ID, state.INN, string(state.Status), state.AuthorEmail, requested, riskLevel, missing, state.CreatedAt, state.UpdatedAt)
+	if err != nil {
+		r.logger.Error("failed to upsert verification read model", zap.Error(err), zap.String("verification_id", state.ID))
+		return fmt.Errorf("failed to upsert verification read model: %w", classify(err))
+	}
+
+	return nil
+}
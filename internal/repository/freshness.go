@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// CachedData последние доставленные данные компании одного типа в кэше арендатора
+type CachedData struct {
+	DataType      domain.DataType
+	DataHash      string
+	SchemaVersion int
+	// DeliveredAt время доставки данных поставщиком
+	DeliveredAt time.Time
+}
+
+// ServedData данные из кэша, которые проверка получает вместо запроса у поставщиков
+type ServedData struct {
+	Cached    *CachedData
+	Freshness domain.Freshness
+}
+
+type FreshnessRepository interface {
+	// GetCached возвращает последние данные компании в кэше арендатора и в общей копии типов
+	// с совместным использованием. versions - версия формата поставщика каждого типа.
+	GetCached(ctx context.Context, tenant, inn string, versions map[domain.DataType]int) (map[domain.DataType]*CachedData, error)
+	// SaveFromCache сохраняет новую проверку вместе с данными из кэша. false - проверка с таким
+	// идентификатором уже есть, например при повторном запросе недостающих данных.
+	SaveFromCache(ctx context.Context, verification *domain.Verification, served []*ServedData) (bool, error)
+	// ClaimRefresh возвращает типы данных компании, фоновое обновление которых не запрашивалось
+	// после requestedBefore, и отмечает их обновление запрошенным
+	ClaimRefresh(ctx context.Context, tenant, inn string, dataTypes []domain.DataType, requestedBefore time.Time) ([]domain.DataType, error)
+}
+
+type freshnessRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewFreshnessRepository(db *pgxpool.Pool, logger *zap.Logger) FreshnessRepository {
+	return &freshnessRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *freshnessRepository) GetCached(ctx context.Context, tenant, inn string, versions map[domain.DataType]int) (map[domain.DataType]*CachedData, error) {
+	dataTypes := make([]string, 0, len(versions))
+	schemaVersions := make([]int32, 0, len(versions))
+	for dataType, version := range versions {
+		dataTypes = append(dataTypes, string(dataType))
+		schemaVersions = append(schemaVersions, int32(version))
+	}
+
+	query := `
+		SELECT DISTINCT ON (k.data_type) k.data_type, k.data_hash, k.schema_version, k.updated_at
+		FROM company_data_cache k
+		JOIN unnest($2::text[], $3::int[]) AS requested(data_type, schema_version)
+			ON requested.data_type = k.data_type AND requested.schema_version = k.schema_version
+		WHERE k.inn = $1
+		  AND (k.tenant_id = $4 OR (k.tenant_id = $5 AND EXISTS (
+			SELECT 1 FROM cache_sharing s WHERE s.data_type = k.data_type AND s.shared
+		  )))
+		ORDER BY k.data_type, k.updated_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, inn, dataTypes, schemaVersions, tenant, GlobalTenant)
+	if err != nil {
+		r.logger.Error("failed to get cached company data", zap.Error(err), zap.String("inn", inn))
+		return nil, fmt.Errorf("failed to get cached company data: %w", classify(err))
+	}
+	defer rows.Close()
+
+	cached := make(map[domain.DataType]*CachedData, len(versions))
+	for rows.Next() {
+		var data CachedData
+		if err := rows.Scan(&data.DataType, &data.DataHash, &data.SchemaVersion, &data.DeliveredAt); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "cached company data", err)
+			continue
+		}
+		cached[data.DataType] = &data
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("failed to read cached company data", zap.Error(err), zap.String("inn", inn))
+		return nil, fmt.Errorf("failed to read cached company data: %w", classify(err))
+	}
+	return cached, nil
+}
+
+// SaveFromCache сохраняет данные из кэша со временем их доставки: клиент видит возраст данных.
+// Отметка freshness не дает триггеру кэша считать эти строки новой доставкой.
+func (r *freshnessRepository) SaveFromCache(ctx context.Context, verification *domain.Verification, served []*ServedData) (bool, error) {
+	requested := make([]string, 0, len(verification.RequestedDataTypes))
+	for _, dataType := range verification.RequestedDataTypes {
+		requested = append(requested, string(dataType))
+	}
+
+	var saved bool
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO verifications (id, inn, status, author_email, requested_data_types, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (id) DO NOTHING
+		`, verification.ID, verification.INN, string(verification.Status), verification.AuthorEmail, requested,
+			verification.CreatedAt, verification.UpdatedAt)
+		if err != nil || tag.RowsAffected() == 0 {
+			return err
+		}
+
+		for _, data := range served {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO verification_data (verification_id, data_type, data_hash, schema_version, created_at, freshness)
+				VALUES ($1, $2, $3, $4, $5, $6)
+			`, verification.ID, string(data.Cached.DataType), data.Cached.DataHash, data.Cached.SchemaVersion,
+				data.Cached.DeliveredAt, string(data.Freshness))
+			if err != nil {
+				return err
+			}
+		}
+		saved = true
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("failed to save verification from cache", zap.Error(err), zap.String("id", verification.ID))
+		return false, fmt.Errorf("failed to save verification from cache: %w", classify(err))
+	}
+	return saved, nil
+}
+
+func (r *freshnessRepository) ClaimRefresh(ctx context.Context, tenant, inn string, dataTypes []domain.DataType, requestedBefore time.Time) ([]domain.DataType, error) {
+	names := make([]string, 0, len(dataTypes))
+	for _, dataType := range dataTypes {
+		names = append(names, string(dataType))
+	}
+
+	query := `
+		INSERT INTO cache_refreshes (tenant_id, inn, data_type, requested_at)
+		SELECT $1, $2, data_type, NOW() FROM unnest($3::text[]) AS data_type
+		ON CONFLICT (tenant_id, inn, data_type) DO UPDATE SET requested_at = NOW()
+		WHERE cache_refreshes.requested_at < $4
+		RETURNING data_type
+	`
+
+	rows, err := r.db.Query(ctx, query, tenant, inn, names, requestedBefore)
+	if err != nil {
+		r.logger.Error("failed to claim cache refresh", zap.Error(err), zap.String("inn", inn))
+		return nil, fmt.Errorf("failed to claim cache refresh: %w", classify(err))
+	}
+	claimed, err := pgx.CollectRows(rows, pgx.RowTo[domain.DataType])
+	if err != nil {
+		r.logger.Error("failed to read claimed cache refresh", zap.Error(err), zap.String("inn", inn))
+		return nil, fmt.Errorf("failed to read claimed cache refresh: %w", classify(err))
+	}
+	return claimed, nil
+}
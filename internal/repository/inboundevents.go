@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// VerificationState сохраненный статус проверки и время его изменения. ChangedAt nil - статус не менялся
+// с создания проверки, и любое уведомление о завершении новее него.
+type VerificationState struct {
+	Status    domain.Status
+	ChangedAt *time.Time
+}
+
+// ParkedEvent отклоненное уведомление о завершении, сохраненное для разбора
+type ParkedEvent struct {
+	Verification *domain.Verification
+	Reason       string
+}
+
+type InboundEventRepository interface {
+	// GetVerificationState возвращает статус проверки; неизвестная проверка - ErrNotFound
+	GetVerificationState(ctx context.Context, id string) (*VerificationState, error)
+	// MarkSeen запоминает уведомление получателя consumer. false - уведомление уже было.
+	MarkSeen(ctx context.Context, consumer, eventID, verificationID string) (bool, error)
+	Park(ctx context.Context, event *ParkedEvent) error
+	// Prune удаляет отметки уведомлений, полученных до seenBefore, и отложенные до parkedBefore
+	Prune(ctx context.Context, seenBefore, parkedBefore time.Time) (int64, error)
+}
+
+type inboundEventRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewInboundEventRepository(db *pgxpool.Pool, logger *zap.Logger) InboundEventRepository {
+	return &inboundEventRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *inboundEventRepository) GetVerificationState(ctx context.Context, id string) (*VerificationState, error) {
+	var state VerificationState
+	// Статус, сохраненный воркером, а не уведомлением, упорядочивается по updated_at, как до status_changed_at
+	err := r.db.QueryRow(ctx, `
+		SELECT status, COALESCE(status_changed_at, CASE WHEN status NOT IN ('PENDING', 'IN_PROCESS', 'PROCESSING') THEN updated_at END)
+		FROM verifications WHERE id = $1
+	`, id).Scan(&state.Status, &state.ChangedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, notFoundf("verification not found: %s", id)
+		}
+		r.logger.Error("failed to get verification state", zap.Error(err), zap.String("id", id))
+		return nil, fmt.Errorf("failed to get verification state: %w", classify(err))
+	}
+	return &state, nil
+}
+
+func (r *inboundEventRepository) MarkSeen(ctx context.Context, consumer, eventID, verificationID string) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO completion_events_seen (consumer, event_id, verification_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (consumer, event_id) DO NOTHING
+	`, consumer, eventID, verificationID)
+	if err != nil {
+		r.logger.Error("failed to mark completion event seen", zap.Error(err), zap.String("event_id", eventID))
+		return false, fmt.Errorf("failed to mark completion event seen: %w", classify(err))
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *inboundEventRepository) Park(ctx context.Context, event *ParkedEvent) error {
+	verification := event.Verification
+	var riskLevel *string
+	if verification.RiskLevel != nil {
+		level := string(*verification.RiskLevel)
+		riskLevel = &level
+	}
+	var completedAt *time.Time
+	if !verification.UpdatedAt.IsZero() {
+		completedAt = &verification.UpdatedAt
+	}
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO parked_completion_events (event_id, verification_id, status, risk_level, ruleset_id, completed_at, reason)
+		VALUES (NULLIF($1, ''), $2, $3, $4, $5, $6, $7)
+	`, verification.EventID, verification.ID, string(verification.Status), riskLevel, verification.RulesetID, completedAt, event.Reason)
+	if err != nil {
+		r.logger.Error("failed to park completion event", zap.Error(err), zap.String("verification_id", verification.ID))
+		return fmt.Errorf("failed to park completion event: %w", classify(err))
+	}
+	return nil
+}
+
+func (r *inboundEventRepository) Prune(ctx context.Context, seenBefore, parkedBefore time.Time) (int64, error) {
+	var pruned int64
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		seen, err := tx.Exec(ctx, `DELETE FROM completion_events_seen WHERE received_at < $1`, seenBefore)
+		if err != nil {
+			return err
+		}
+		parked, err := tx.Exec(ctx, `DELETE FROM parked_completion_events WHERE received_at < $1`, parkedBefore)
+		if err != nil {
+			return err
+		}
+		pruned = seen.RowsAffected() + parked.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("failed to prune completion events", zap.Error(err))
+		return 0, fmt.Errorf("failed to prune completion events: %w", classify(err))
+	}
+	return pruned, nil
+}
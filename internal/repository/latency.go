@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// LatencySample время выполнения одного запрошенного типа данных проверки
+type LatencySample struct {
+	DataType string
+	// FirstDataSeconds время от создания проверки до первых данных типа, nil - данные не доставлены
+	FirstDataSeconds *float64
+	CompletedSeconds float64
+}
+
+// CompletionLatency время выполнения типа данных по проверкам, завершенным за период
+type CompletionLatency struct {
+	MedianSeconds     float64
+	PercentileSeconds float64
+}
+
+type LatencyRepository interface {
+	RecordLatencies(ctx context.Context, verificationID string) ([]*LatencySample, error)
+	GetReport(ctx context.Context, dataType *string, from, to time.Time) ([]*model.LatencyReport, error)
+	// GetCompletionLatencies возвращает медиану и перцентиль percentile времени выполнения
+	// по типам данных для проверок, завершенных после since
+	GetCompletionLatencies(ctx context.Context, percentile float64, since time.Time) (map[string]*CompletionLatency, error)
+}
+
+type latencyRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewLatencyRepository(db *pgxpool.Pool, logger *zap.Logger) LatencyRepository {
+	return &latencyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// RecordLatencies сохраняет время выполнения каждого запрошенного типа данных завершенной проверки.
+// Возвращает только новые записи: повторное уведомление о завершении не учитывается дважды.
+// Проверки песочницы не записываются, их данные не приходят от поставщиков.
+func (r *latencyRepository) RecordLatencies(ctx context.Context, verificationID string) ([]*LatencySample, error) {
+	query := `
+		INSERT INTO verification_latencies (verification_id, data_type, first_data_seconds, completed_seconds, completed_at)
+		SELECT v.id, requested.data_type,
+			EXTRACT(EPOCH FROM delivered.first_at - v.created_at),
+			EXTRACT(EPOCH FROM v.updated_at - v.created_at),
+			v.updated_at
+		FROM verifications v
+		CROSS JOIN LATERAL unnest(v.requested_data_types) AS requested(data_type)
+		LEFT JOIN (
+			SELECT data_type, MIN(created_at) AS first_at
+			FROM verification_data
+			WHERE verification_id = $1
+			GROUP BY data_type
+		) delivered ON delivered.data_type = requested.data_type
+		WHERE v.id = $1 AND NOT v.sandbox
+		ON CONFLICT (verification_id, data_type) DO NOTHING
+		RETURNING data_type, first_data_seconds, completed_seconds
+	`
+
+	rows, err := r.db.Query(ctx, query, verificationID)
+	if err != nil {
+		r.logger.Error("failed to record verification latencies", zap.Error(err), zap.String("verification_id", verificationID))
+		return nil, fmt.Errorf("failed to record verification latencies: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var samples []*LatencySample
+	for rows.Next() {
+		var sample LatencySample
+		if err := rows.Scan(&sample.DataType, &sample.FirstDataSeconds, &sample.CompletedSeconds); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "verification latency", err)
+			continue
+		}
+		samples = append(samples, &sample)
+	}
+
+	return samples, nil
+}
+
+// GetReport возвращает перцентили времени выполнения по типам данных для проверок,
+// завершенных в [from, to)
+func (r *latencyRepository) GetReport(ctx context.Context, dataType *string, from, to time.Time) ([]*model.LatencyReport, error) {
+	query := `
+		SELECT data_type, COUNT(*), COUNT(first_data_seconds),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY first_data_seconds),
+			percentile_cont(0.95) WITHIN GROUP (ORDER BY first_data_seconds),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY completed_seconds),
+			percentile_cont(0.95) WITHIN GROUP (ORDER BY completed_seconds),
+			MAX(completed_seconds)
+		FROM verification_latencies
+		WHERE completed_at >= $1 AND completed_at < $2
+			AND ($3::text IS NULL OR data_type = $3)
+		GROUP BY data_type
+		ORDER BY data_type
+	`
+
+	rows, err := r.db.Query(ctx, query, from, to, dataType)
+	if err != nil {
+		r.logger.Error("failed to get latency report", zap.Error(err))
+		return nil, fmt.Errorf("failed to get latency report: %w", classify(err))
+	}
+	defer rows.Close()
+
+	reports := []*model.LatencyReport{}
+	for rows.Next() {
+		var report model.LatencyReport
+		var dataType string
+		var samples, delivered int
+		if err := rows.Scan(&dataType, &samples, &delivered,
+			&report.FirstDataP50Seconds, &report.FirstDataP95Seconds,
+			&report.CompletedP50Seconds, &report.CompletedP95Seconds, &report.CompletedMaxSeconds); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "latency report", err)
+			continue
+		}
+		report.DataType = model.VerificationDataType(dataType)
+		report.Samples = int32(samples)
+		report.Undelivered = int32(samples - delivered)
+		reports = append(reports, &report)
+	}
+
+	return reports, nil
+}
+
+func (r *latencyRepository) GetCompletionLatencies(ctx context.Context, percentile float64, since time.Time) (map[string]*CompletionLatency, error) {
+	query := `
+		SELECT data_type,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY completed_seconds),
+			percentile_cont($1) WITHIN GROUP (ORDER BY completed_seconds)
+		FROM verification_latencies
+		WHERE completed_at >= $2
+		GROUP BY data_type
+	`
+
+	rows, err := r.db.Query(ctx, query, percentile, since)
+	if err != nil {
+		r.logger.Error("failed to get completion latencies", zap.Error(err))
+		return nil, fmt.Errorf("failed to get completion latencies: %w", classify(err))
+	}
+	defer rows.Close()
+
+	latencies := make(map[string]*CompletionLatency)
+	for rows.Next() {
+		var dataType string
+		var latency CompletionLatency
+		if err := rows.Scan(&dataType, &latency.MedianSeconds, &latency.PercentileSeconds); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "completion latency", err)
+			continue
+		}
+		latencies[dataType] = &latency
+	}
+
+	return latencies, rows.Err()
+}
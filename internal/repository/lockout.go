@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// CallerLockout временная блокировка клиента, присылающего некорректные запросы
+type CallerLockout struct {
+	Caller string
+	// Reason вид ошибок, которых было больше всего
+	Reason string
+	// Failures количество ошибок по видам за окно, приведшее к блокировке
+	Failures     map[string]int
+	Strike       int
+	BlockedUntil time.Time
+}
+
+type LockoutRepository interface {
+	AddLockout(ctx context.Context, lockout *CallerLockout) error
+}
+
+type lockoutRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewLockoutRepository(db *pgxpool.Pool, logger *zap.Logger) LockoutRepository {
+	return &lockoutRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// AddLockout сохраняет блокировку клиента в журнал
+func (r *lockoutRepository) AddLockout(ctx context.Context, lockout *CallerLockout) error {
+	query := `
+		INSERT INTO caller_lockouts (caller, reason, failures, strike, blocked_until)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	failures, err := json.Marshal(lockout.Failures)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lockout failures: %w", classify(err))
+	}
+
+	if _, err := r.db.Exec(ctx, query, lockout.Caller, lockout.Reason, failures, lockout.Strike, lockout.BlockedUntil); err != nil {
+		r.logger.Error("failed to add caller lockout", zap.Error(err), zap.String("caller", lockout.Caller))
+		return fmt.Errorf("failed to add caller lockout: %w", classify(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// MonitoringRepository отмечает повторные проверки мониторинга: по их завершении вебхуки,
+// подписанные на изменения компаний, получают изменения данных
+type MonitoringRepository interface {
+	// MarkMonitored отмечает проверку как повторную проверку мониторинга. Если проверка уже
+	// завершилась и триггер не создал задания об изменении компании, они создаются здесь.
+	MarkMonitored(ctx context.Context, verificationID string) error
+}
+
+type monitoringRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewMonitoringRepository(db *pgxpool.Pool, logger *zap.Logger) MonitoringRepository {
+	return &monitoringRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *monitoringRepository) MarkMonitored(ctx context.Context, verificationID string) error {
+	query := `
+		WITH marked AS (
+			INSERT INTO monitoring_verifications (verification_id) VALUES ($1)
+			ON CONFLICT (verification_id) DO NOTHING
+			RETURNING verification_id
+		)
+		INSERT INTO notification_jobs (verification_id, channel, webhook_id, ready_at)
+		SELECT v.id, 'COMPANY_CHANGE', w.id, NOW()
+		FROM marked m
+		JOIN verifications v ON v.id = m.verification_id
+		CROSS JOIN webhooks w
+		WHERE 'COMPANY_CHANGED' = ANY(w.events)
+		  AND NOT v.sandbox
+		  AND v.status NOT IN ('PENDING', 'IN_PROCESS', 'PROCESSING')
+	`
+
+	if _, err := r.db.Exec(ctx, query, verificationID); err != nil {
+		r.logger.Error("failed to mark monitoring verification", zap.Error(err), zap.String("verification_id", verificationID))
+		return fmt.Errorf("failed to mark monitoring verification: %w", classify(err))
+	}
+	return nil
+}
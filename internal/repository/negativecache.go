@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// NegativeCacheRepository хранит ИНН, которые поставщики не нашли, до истечения срока записи
+type NegativeCacheRepository interface {
+	// LookupNotFound сообщает, отмечен ли ИНН ненайденным, и учитывает попадание
+	LookupNotFound(ctx context.Context, inn string) (bool, error)
+	RememberNotFound(ctx context.Context, inn string, expiresAt time.Time) error
+	ForgetNotFound(ctx context.Context, inn string) error
+	// SaveNotFound сохраняет новую проверку, завершенную по кэшу. false - проверка с таким
+	// идентификатором уже есть, например при повторном запросе недостающих данных.
+	SaveNotFound(ctx context.Context, verification *domain.Verification) (bool, error)
+	// PruneNotFound удаляет истекшие записи и возвращает число оставшихся
+	PruneNotFound(ctx context.Context) (int64, error)
+}
+
+type negativeCacheRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewNegativeCacheRepository(db *pgxpool.Pool, logger *zap.Logger) NegativeCacheRepository {
+	return &negativeCacheRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *negativeCacheRepository) LookupNotFound(ctx context.Context, inn string) (bool, error) {
+	var hits int64
+	err := r.db.QueryRow(ctx, `
+		UPDATE inn_not_found SET hits = hits + 1
+		WHERE inn = $1 AND expires_at > NOW()
+		RETURNING hits
+	`, inn).Scan(&hits)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		r.logger.Error("failed to look up negative cache", zap.Error(err), zap.String("inn", inn))
+		return false, fmt.Errorf("failed to look up negative cache: %w", classify(err))
+	}
+	return true, nil
+}
+
+func (r *negativeCacheRepository) RememberNotFound(ctx context.Context, inn string, expiresAt time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO inn_not_found (inn, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (inn) DO UPDATE SET expires_at = EXCLUDED.expires_at, recorded_at = NOW(), hits = 0
+	`, inn, expiresAt)
+	if err != nil {
+		r.logger.Error("failed to save negative cache entry", zap.Error(err), zap.String("inn", inn))
+		return fmt.Errorf("failed to save negative cache entry: %w", classify(err))
+	}
+	return nil
+}
+
+func (r *negativeCacheRepository) ForgetNotFound(ctx context.Context, inn string) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM inn_not_found WHERE inn = $1`, inn); err != nil {
+		r.logger.Error("failed to delete negative cache entry", zap.Error(err), zap.String("inn", inn))
+		return fmt.Errorf("failed to delete negative cache entry: %w", classify(err))
+	}
+	return nil
+}
+
+func (r *negativeCacheRepository) SaveNotFound(ctx context.Context, verification *domain.Verification) (bool, error) {
+	requested := make([]string, 0, len(verification.RequestedDataTypes))
+	for _, dataType := range verification.RequestedDataTypes {
+		requested = append(requested, string(dataType))
+	}
+
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO verifications (id, inn, status, author_email, requested_data_types, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO NOTHING
+	`, verification.ID, verification.INN, string(verification.Status), verification.AuthorEmail, requested,
+		verification.CreatedAt, verification.UpdatedAt)
+	if err != nil {
+		r.logger.Error("failed to save cached not found verification", zap.Error(err), zap.String("id", verification.ID))
+		return false, fmt.Errorf("failed to save cached not found verification: %w", classify(err))
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *negativeCacheRepository) PruneNotFound(ctx context.Context) (int64, error) {
+	if _, err := r.db.Exec(ctx, `DELETE FROM inn_not_found WHERE expires_at <= NOW()`); err != nil {
+		r.logger.Error("failed to prune negative cache", zap.Error(err))
+		return 0, fmt.Errorf("failed to prune negative cache: %w", classify(err))
+	}
+
+	var active int64
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM inn_not_found`).Scan(&active); err != nil {
+		r.logger.Error("failed to count negative cache entries", zap.Error(err))
+		return 0, fmt.Errorf("failed to count negative cache entries: %w", classify(err))
+	}
+	return active, nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, userEmail string, kind model.NotificationKind, verificationID *string, message string) (*model.Notification, error)
+	ListByUser(ctx context.Context, userEmail string, unreadOnly bool) ([]*model.Notification, error)
+	MarkRead(ctx context.Context, userEmail string, id string) (*model.Notification, error)
+	CountUnread(ctx context.Context, userEmail string) (int32, error)
+	GetSLABreaches(ctx context.Context, startedBefore time.Time, limit int) ([]*model.Verification, error)
+}
+
+type notificationRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewNotificationRepository(db *pgxpool.Pool, logger *zap.Logger) NotificationRepository {
+	return &notificationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const notificationColumns = `id, kind, verification_id, message, read_at IS NOT NULL, created_at`
+
+func scanNotification(row pgx.Row) (*model.Notification, error) {
+	var n model.Notification
+	var createdAt time.Time
+	if err := row.Scan(&n.ID, &n.Kind, &n.VerificationID, &n.Message, &n.Read, &createdAt); err != nil {
+		return nil, err
+	}
+	n.CreatedAt = createdAt.Format(time.RFC3339)
+	return &n, nil
+}
+
+// Create добавляет уведомление. Если такое уведомление по проверке уже есть, возвращает ErrConflict.
+func (r *notificationRepository) Create(ctx context.Context, userEmail string, kind model.NotificationKind, verificationID *string, message string) (*model.Notification, error) {
+	query := `
+		INSERT INTO notifications (user_email, kind, verification_id, message)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_email, kind, verification_id) DO NOTHING
+		RETURNING ` + notificationColumns
+
+	notification, err := scanNotification(r.db.QueryRow(ctx, query, userEmail, string(kind), verificationID, message))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, conflictf("notification %s already exists", kind)
+		}
+		r.logger.Error("failed to create notification", zap.Error(err), zap.String("kind", string(kind)))
+		return nil, fmt.Errorf("failed to create notification: %w", classify(err))
+	}
+
+	return notification, nil
+}
+
+func (r *notificationRepository) ListByUser(ctx context.Context, userEmail string, unreadOnly bool) ([]*model.Notification, error) {
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE user_email = $1 AND ($2 = false OR read_at IS NULL)
+		ORDER BY created_at DESC
+		LIMIT 100
+	`
+
+	rows, err := r.db.Query(ctx, query, userEmail, unreadOnly)
+	if err != nil {
+		r.logger.Error("failed to list notifications", zap.Error(err))
+		return nil, fmt.Errorf("failed to list notifications: %w", classify(err))
+	}
+	defer rows.Close()
+
+	notifications := []*model.Notification{}
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			reportScanFailure(ctx, r.logger, rows, "notification", err)
+			continue
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, nil
+}
+
+// MarkRead отмечает уведомление прочитанным. Пользователь может отметить только свои уведомления.
+func (r *notificationRepository) MarkRead(ctx context.Context, userEmail string, id string) (*model.Notification, error) {
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_email = $2
+		RETURNING ` + notificationColumns
+
+	notification, err := scanNotification(r.db.QueryRow(ctx, query, id, userEmail))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, notFoundf("notification not found: %s", id)
+		}
+		r.logger.Error("failed to mark notification read", zap.Error(err), zap.String("id", id))
+		return nil, fmt.Errorf("failed to mark notification read: %w", classify(err))
+	}
+
+	return notification, nil
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userEmail string) (int32, error) {
+	query := `SELECT COUNT(*) FROM notifications WHERE user_email = $1 AND read_at IS NULL`
+
+	var count int32
+	if err := r.db.QueryRow(ctx, query, userEmail).Scan(&count); err != nil {
+		r.logger.Error("failed to count unread notifications", zap.Error(err))
+		return 0, fmt.Errorf("failed to count unread notifications: %w", classify(err))
+	}
+
+	return count, nil
+}
+
+// GetSLABreaches возвращает незавершенные проверки, созданные раньше startedBefore,
+// по которым автор еще не получил уведомление о нарушении SLA
+func (r *notificationRepository) GetSLABreaches(ctx context.Context, startedBefore time.Time, limit int) ([]*model.Verification, error) {
+	query := `
+		SELECT v.id, v.inn, v.status, v.author_email, v.created_at
+		FROM verifications v
+		WHERE v.status IN ('IN_PROCESS', 'PROCESSING')
+			AND v.created_at < $1
+			AND NOT EXISTS (
+				SELECT 1 FROM notifications n
+				WHERE n.verification_id = v.id AND n.kind = 'SLA_BREACHED'
+			)
+		ORDER BY v.created_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, startedBefore, limit)
+	if err != nil {
+		r.logger.Error("failed to get SLA breaches", zap.Error(err))
+		return nil, fmt.Errorf("failed to get SLA breaches: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var verifications []*model.Verification
+	for rows.Next() {
+		var v model.Verification
+		var createdAt time.Time
+		if err := rows.Scan(&v.ID, &v.Inn, &v.Status, &v.AuthorEmail, &createdAt); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "SLA breach", err)
+			continue
+		}
+		v.CreatedAt = createdAt.Format(time.RFC3339)
+		verifications = append(verifications, &v)
+	}
+
+	return verifications, nil
+}
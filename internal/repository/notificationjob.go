@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// Каналы и статусы заданий уведомлений
+const (
+	NotificationChannelInApp   = "IN_APP"
+	NotificationChannelWebhook = "WEBHOOK"
+	// NotificationChannelCompanyChange вебхук об изменении данных компании на мониторинге
+	NotificationChannelCompanyChange = "COMPANY_CHANGE"
+
+	NotificationJobPending   = "PENDING"
+	NotificationJobDelivered = "DELIVERED"
+	NotificationJobFailed    = "FAILED"
+)
+
+// NotificationJob уведомление о завершении проверки по одному каналу. Задания создает триггер
+// verifications в той же транзакции, в которой воркер записывает итоговый статус.
+type NotificationJob struct {
+	ID             int64
+	VerificationID string
+	Channel        string
+	// WebhookID вебхук каналов WEBHOOK и COMPANY_CHANGE, nil после удаления вебхука
+	WebhookID     *string
+	Status        string
+	CreatedAt     time.Time
+	NextAttemptAt time.Time
+	Attempts      int
+	LastError     *string
+	FinishedAt    *time.Time
+	History       []*NotificationJobAttempt
+}
+
+// NotificationJobAttempt попытка доставки задания
+type NotificationJobAttempt struct {
+	Attempt    int
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Error      *string
+}
+
+type NotificationJobRepository interface {
+	// MarkReady разрешает доставку заданий проверки: шлюз обработал ее завершение
+	MarkReady(ctx context.Context, verificationID string) error
+	// ClaimPending забирает до claimUntil готовые к попытке задания, начиная с самых старых. Задание
+	// без отметки о готовности забирается, если создано раньше readyBefore.
+	ClaimPending(ctx context.Context, claimUntil, readyBefore time.Time, limit int) ([]*NotificationJob, error)
+	// RecordAttempt сохраняет попытку и переводит задание в status. Задание в статусе PENDING
+	// повторяется не раньше nextAttemptAt.
+	RecordAttempt(ctx context.Context, jobID int64, attempt *NotificationJobAttempt, status string, nextAttemptAt time.Time) error
+	// ListByVerification возвращает задания проверки с историей попыток
+	ListByVerification(ctx context.Context, verificationID string) ([]*NotificationJob, error)
+}
+
+type notificationJobRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewNotificationJobRepository(db *pgxpool.Pool, logger *zap.Logger) NotificationJobRepository {
+	return &notificationJobRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const notificationJobColumns = `id, verification_id, channel, webhook_id, status, created_at, next_attempt_at,
+	attempts, last_error, finished_at`
+
+func (r *notificationJobRepository) MarkReady(ctx context.Context, verificationID string) error {
+	query := `
+		UPDATE notification_jobs SET ready_at = NOW()
+		WHERE verification_id = $1 AND status = 'PENDING' AND ready_at IS NULL
+	`
+
+	if _, err := r.db.Exec(ctx, query, verificationID); err != nil {
+		r.logger.Error("failed to mark notification jobs ready", zap.Error(err), zap.String("verification_id", verificationID))
+		return fmt.Errorf("failed to mark notification jobs ready: %w", classify(err))
+	}
+	return nil
+}
+
+// ClaimPending забирает задания так же, как outbox: если экземпляр не успеет записать попытку,
+// после claimUntil задание заберет другой
+func (r *notificationJobRepository) ClaimPending(ctx context.Context, claimUntil, readyBefore time.Time, limit int) ([]*NotificationJob, error) {
+	query := `
+		UPDATE notification_jobs
+		SET claimed_until = $1, attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM notification_jobs
+			WHERE status = 'PENDING' AND next_attempt_at <= NOW()
+			  AND (ready_at IS NOT NULL OR created_at < $2)
+			  AND (claimed_until IS NULL OR claimed_until < NOW())
+			ORDER BY next_attempt_at, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + notificationJobColumns
+
+	return r.query(ctx, "claim notification jobs", query, claimUntil, readyBefore, limit)
+}
+
+func (r *notificationJobRepository) RecordAttempt(ctx context.Context, jobID int64, attempt *NotificationJobAttempt, status string, nextAttemptAt time.Time) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO notification_job_attempts (job_id, attempt, started_at, finished_at, error)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (job_id, attempt) DO NOTHING
+		`, jobID, attempt.Attempt, attempt.StartedAt, attempt.FinishedAt, attempt.Error)
+		if err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, `
+			UPDATE notification_jobs
+			SET status = $2, last_error = $3, next_attempt_at = $4, claimed_until = NULL,
+				finished_at = CASE WHEN $2 = 'PENDING' THEN NULL ELSE $5::timestamptz END
+			WHERE id = $1
+		`, jobID, status, attempt.Error, nextAttemptAt, attempt.FinishedAt)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return notFoundf("notification job not found: %d", jobID)
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("failed to record notification attempt", zap.Error(err), zap.Int64("job_id", jobID))
+		return fmt.Errorf("failed to record notification attempt: %w", classify(err))
+	}
+	return nil
+}
+
+func (r *notificationJobRepository) ListByVerification(ctx context.Context, verificationID string) ([]*NotificationJob, error) {
+	query := `SELECT ` + notificationJobColumns + `
+		FROM notification_jobs
+		WHERE verification_id = $1
+		ORDER BY id
+	`
+	jobs, err := r.query(ctx, "get notification jobs", query, verificationID)
+	if err != nil || len(jobs) == 0 {
+		return jobs, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT a.job_id, a.attempt, a.started_at, a.finished_at, a.error
+		FROM notification_job_attempts a
+		JOIN notification_jobs j ON j.id = a.job_id
+		WHERE j.verification_id = $1
+		ORDER BY a.job_id, a.attempt
+	`, verificationID)
+	if err != nil {
+		r.logger.Error("failed to get notification attempts", zap.Error(err), zap.String("verification_id", verificationID))
+		return nil, fmt.Errorf("failed to get notification attempts: %w", classify(err))
+	}
+	defer rows.Close()
+
+	byID := make(map[int64]*NotificationJob, len(jobs))
+	for _, job := range jobs {
+		job.History = []*NotificationJobAttempt{}
+		byID[job.ID] = job
+	}
+	for rows.Next() {
+		var jobID int64
+		var attempt NotificationJobAttempt
+		if err := rows.Scan(&jobID, &attempt.Attempt, &attempt.StartedAt, &attempt.FinishedAt, &attempt.Error); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "notification attempt", err)
+			continue
+		}
+		if job, ok := byID[jobID]; ok {
+			job.History = append(job.History, &attempt)
+		}
+	}
+	return jobs, nil
+}
+
+func (r *notificationJobRepository) query(ctx context.Context, action, query string, args ...any) ([]*NotificationJob, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("failed to "+action, zap.Error(err))
+		return nil, fmt.Errorf("failed to %s: %w", action, classify(err))
+	}
+	defer rows.Close()
+
+	var jobs []*NotificationJob
+	for rows.Next() {
+		var j NotificationJob
+		err := rows.Scan(&j.ID, &j.VerificationID, &j.Channel, &j.WebhookID, &j.Status, &j.CreatedAt, &j.NextAttemptAt,
+			&j.Attempts, &j.LastError, &j.FinishedAt)
+		if err != nil {
+			reportScanFailure(ctx, r.logger, rows, "notification job", err)
+			continue
+		}
+		jobs = append(jobs, &j)
+	}
+	return jobs, nil
+}
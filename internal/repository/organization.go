@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"scoring_api_gateway/internal/messaging"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type OrganizationRepository interface {
+	messaging.TenantRouteSource
+	// GetAncestors возвращает вышестоящие организации id, начиная с непосредственной
+	GetAncestors(ctx context.Context, id string) ([]string, error)
+	// GetSubsidiaries возвращает дочерние организации id на любой глубине
+	GetSubsidiaries(ctx context.Context, id string) ([]string, error)
+}
+
+type organizationRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewOrganizationRepository(db *pgxpool.Pool, logger *zap.Logger) OrganizationRepository {
+	return &organizationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetTenantRoutes возвращает маршруты NATS организаций с собственным префиксом subject
+func (r *organizationRepository) GetTenantRoutes(ctx context.Context) ([]*messaging.TenantRoute, error) {
+	query := `
+		SELECT id, nats_subject_prefix, COALESCE(nats_credentials_file, '')
+		FROM organizations
+		WHERE nats_subject_prefix IS NOT NULL AND nats_subject_prefix <> ''
+		ORDER BY id
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		r.logger.Error("failed to get tenant routes", zap.Error(err))
+		return nil, fmt.Errorf("failed to get tenant routes: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var routes []*messaging.TenantRoute
+	for rows.Next() {
+		var route messaging.TenantRoute
+		if err := rows.Scan(&route.Tenant, &route.SubjectPrefix, &route.CredentialsFile); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "tenant route", err)
+			continue
+		}
+		routes = append(routes, &route)
+	}
+
+	return routes, nil
+}
+
+func (r *organizationRepository) GetAncestors(ctx context.Context, id string) ([]string, error) {
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT parent_id, 1 AS depth FROM organizations WHERE id = $1
+			UNION ALL
+			SELECT o.parent_id, a.depth + 1
+			FROM organizations o JOIN ancestors a ON o.id = a.parent_id
+		)
+		SELECT parent_id FROM ancestors WHERE parent_id IS NOT NULL ORDER BY depth
+	`
+
+	return r.collectIDs(ctx, "organization ancestors", query, id)
+}
+
+func (r *organizationRepository) GetSubsidiaries(ctx context.Context, id string) ([]string, error) {
+	query := `
+		WITH RECURSIVE subsidiaries AS (
+			SELECT id FROM organizations WHERE parent_id = $1
+			UNION
+			SELECT o.id FROM organizations o JOIN subsidiaries s ON o.parent_id = s.id
+		)
+		SELECT id FROM subsidiaries ORDER BY id
+	`
+
+	return r.collectIDs(ctx, "organization subsidiaries", query, id)
+}
+
+func (r *organizationRepository) collectIDs(ctx context.Context, what, query string, id string) ([]string, error) {
+	rows, err := r.db.Query(ctx, query, id)
+	if err != nil {
+		r.logger.Error("failed to get "+what, zap.Error(err), zap.String("organization", id))
+		return nil, fmt.Errorf("failed to get %s: %w", what, classify(err))
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		r.logger.Error("failed to get "+what, zap.Error(err), zap.String("organization", id))
+		return nil, fmt.Errorf("failed to get %s: %w", what, classify(err))
+	}
+	return ids, nil
+}
@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/internal/messaging"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type OutboxRepository interface {
+	messaging.Outbox
+	ClaimDue(ctx context.Context, claimUntil time.Time, limit int) ([]*messaging.OutboxMessage, error)
+	MarkPublished(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string, cause error) error
+	// Discard удаляет неотправленное сообщение проверки
+	Discard(ctx context.Context, id string) error
+	CountPending(ctx context.Context) (int, error)
+	// GetPending возвращает неотправленное сообщение проверки и его позицию в очереди арендатора.
+	// nil, если сообщения нет или оно уже отправлено.
+	GetPending(ctx context.Context, id string) (*messaging.OutboxMessage, int, error)
+	// TenantLoad возвращает проверки арендатора у воркеров, удержанные запросы и его лимит
+	TenantLoad(ctx context.Context, tenant string, defaultLimit int) (*messaging.TenantLoad, error)
+	// ReleaseHeld отпускает удержанные запросы арендаторов, у которых освободилось место, не более
+	// batchSize на арендатора, и возвращает число отпущенных по арендаторам
+	ReleaseHeld(ctx context.Context, defaultLimit, batchSize int) (map[string]int, error)
+}
+
+type outboxRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewOutboxRepository(db *pgxpool.Pool, logger *zap.Logger) OutboxRepository {
+	return &outboxRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Enqueue сохраняет отложенную публикацию. Повторный запрос по той же проверке
+// (например, дозапрос недоставленных данных) заменяет уже отправленное сообщение.
+func (r *outboxRepository) Enqueue(ctx context.Context, msg *messaging.OutboxMessage) (int, error) {
+	query := `
+		INSERT INTO outbox_messages (id, tenant, priority, payload, available_at, held)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO UPDATE
+		SET tenant = EXCLUDED.tenant, priority = EXCLUDED.priority, payload = EXCLUDED.payload,
+		    available_at = EXCLUDED.available_at, held = EXCLUDED.held, released_at = NULL,
+		    claimed_until = NULL, attempts = 0, last_error = NULL, published_at = NULL
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRow(ctx, query, msg.ID, msg.Tenant, string(msg.Priority), []byte(msg.Payload), msg.AvailableAt, msg.Held).Scan(&msg.CreatedAt)
+	if err != nil {
+		r.logger.Error("failed to enqueue outbox message", zap.Error(err), zap.String("id", msg.ID))
+		return 0, fmt.Errorf("failed to enqueue outbox message: %w", classify(err))
+	}
+
+	// Сообщение уже сохранено, поэтому без позиции публикация все равно считается отложенной
+	position, err := r.queuePosition(ctx, msg)
+	if err != nil {
+		r.logger.Warn("failed to get outbox queue position", zap.Error(err), zap.String("id", msg.ID))
+		return 0, nil
+	}
+	return position, nil
+}
+
+// priorityRank порядок, в котором отпускаются удержанные сообщения: сначала приоритетные
+const priorityRank = `CASE priority WHEN 'high' THEN 0 WHEN 'normal' THEN 1 ELSE 2 END`
+
+// queuePosition возвращает позицию сообщения среди неотправленных сообщений арендатора.
+// Отложенные сообщения упорядочены по времени отправки, удержанные идут после них
+// в порядке, в котором их отпустит задача concurrency_release.
+func (r *outboxRepository) queuePosition(ctx context.Context, msg *messaging.OutboxMessage) (int, error) {
+	var position int
+	if !msg.Held {
+		err := r.db.QueryRow(ctx, `
+			SELECT COUNT(*) FROM outbox_messages
+			WHERE tenant = $1 AND published_at IS NULL AND NOT held AND available_at <= $2
+		`, msg.Tenant, msg.AvailableAt).Scan(&position)
+		return position, err
+	}
+
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM outbox_messages
+		WHERE tenant = $1 AND published_at IS NULL
+		  AND (NOT held OR (`+priorityRank+`, created_at) <= (
+		      CASE $2 WHEN 'high' THEN 0 WHEN 'normal' THEN 1 ELSE 2 END, $3::timestamptz))
+	`, msg.Tenant, string(msg.Priority), msg.CreatedAt).Scan(&position)
+	return position, err
+}
+
+func (r *outboxRepository) GetPending(ctx context.Context, id string) (*messaging.OutboxMessage, int, error) {
+	query := `
+		SELECT id, tenant, priority, payload, available_at, attempts, held, created_at
+		FROM outbox_messages
+		WHERE id = $1 AND published_at IS NULL
+	`
+
+	var msg messaging.OutboxMessage
+	var priority string
+	var payload []byte
+	err := r.db.QueryRow(ctx, query, id).Scan(&msg.ID, &msg.Tenant, &priority, &payload, &msg.AvailableAt, &msg.Attempts, &msg.Held, &msg.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		r.logger.Error("failed to get pending outbox message", zap.Error(err), zap.String("id", id))
+		return nil, 0, fmt.Errorf("failed to get pending outbox message: %w", classify(err))
+	}
+	msg.Priority = messaging.Priority(priority)
+	msg.Payload = payload
+
+	position, err := r.queuePosition(ctx, &msg)
+	if err != nil {
+		r.logger.Error("failed to get outbox queue position", zap.Error(err), zap.String("id", id))
+		return nil, 0, fmt.Errorf("failed to get outbox queue position: %w", classify(err))
+	}
+
+	return &msg, position, nil
+}
+
+// ClaimDue забирает сообщения, время отправки которых наступило, до claimUntil.
+// Если экземпляр не успеет отметить сообщение отправленным, после claimUntil его заберет другой.
+func (r *outboxRepository) ClaimDue(ctx context.Context, claimUntil time.Time, limit int) ([]*messaging.OutboxMessage, error) {
+	query := `
+		UPDATE outbox_messages
+		SET claimed_until = $1, attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM outbox_messages
+			WHERE published_at IS NULL
+			  AND NOT held
+			  AND available_at <= NOW()
+			  AND (claimed_until IS NULL OR claimed_until < NOW())
+			ORDER BY available_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, tenant, priority, payload, available_at, attempts
+	`
+
+	rows, err := r.db.Query(ctx, query, claimUntil, limit)
+	if err != nil {
+		r.logger.Error("failed to claim outbox messages", zap.Error(err))
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var messages []*messaging.OutboxMessage
+	for rows.Next() {
+		var msg messaging.OutboxMessage
+		var priority string
+		var payload []byte
+		if err := rows.Scan(&msg.ID, &msg.Tenant, &priority, &payload, &msg.AvailableAt, &msg.Attempts); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "outbox message", err)
+			continue
+		}
+		msg.Priority = messaging.Priority(priority)
+		msg.Payload = payload
+		messages = append(messages, &msg)
+	}
+
+	return messages, nil
+}
+
+func (r *outboxRepository) MarkPublished(ctx context.Context, id string) error {
+	query := `UPDATE outbox_messages SET published_at = NOW(), claimed_until = NULL, last_error = NULL WHERE id = $1`
+
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		r.logger.Error("failed to mark outbox message published", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to mark outbox message published: %w", classify(err))
+	}
+	return nil
+}
+
+// MarkFailed снимает захват, чтобы сообщение было отправлено при следующем запуске
+func (r *outboxRepository) MarkFailed(ctx context.Context, id string, cause error) error {
+	query := `UPDATE outbox_messages SET claimed_until = NULL, last_error = $2 WHERE id = $1`
+
+	if _, err := r.db.Exec(ctx, query, id, cause.Error()); err != nil {
+		r.logger.Error("failed to mark outbox message failed", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to mark outbox message failed: %w", classify(err))
+	}
+	return nil
+}
+
+func (r *outboxRepository) Discard(ctx context.Context, id string) error {
+	query := `DELETE FROM outbox_messages WHERE id = $1 AND published_at IS NULL`
+
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		r.logger.Error("failed to discard outbox message", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to discard outbox message: %w", classify(err))
+	}
+	return nil
+}
+
+func (r *outboxRepository) CountPending(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM outbox_messages WHERE published_at IS NULL`).Scan(&count)
+	if err != nil {
+		r.logger.Error("failed to count outbox messages", zap.Error(err))
+		return 0, fmt.Errorf("failed to count outbox messages: %w", classify(err))
+	}
+	return count, nil
+}
+
+// tenantExpr арендатор проверки, как его определяет messaging.TenantOf. Выражение
+// совпадает с индексами idx_verifications_in_flight_tenant и idx_verifications_tenant_created_at.
+const tenantExpr = `COALESCE(lower(substring(author_email FROM '@([^@]+)$')), 'default')`
+
+// tenantLoadQuery у воркеров - проверки в работе и отпущенные, но еще не отправленные сообщения.
+// Лимит организации без собственного max_in_flight наследуется от ближайшей вышестоящей организации.
+const tenantLoadQuery = `
+	SELECT
+		(SELECT COUNT(*) FROM verifications
+		 WHERE status IN ('IN_PROCESS', 'PROCESSING') AND NOT sandbox AND ` + tenantExpr + ` = $1)
+		+ (SELECT COUNT(*) FROM outbox_messages
+		   WHERE tenant = $1 AND released_at IS NOT NULL AND published_at IS NULL),
+		(SELECT COUNT(*) FROM outbox_messages WHERE tenant = $1 AND held AND published_at IS NULL),
+		COALESCE((
+			WITH RECURSIVE chain AS (
+				SELECT parent_id, max_in_flight, 0 AS depth FROM organizations WHERE id = $1
+				UNION ALL
+				SELECT o.parent_id, o.max_in_flight, c.depth + 1
+				FROM organizations o JOIN chain c ON o.id = c.parent_id
+			)
+			SELECT max_in_flight FROM chain WHERE max_in_flight IS NOT NULL ORDER BY depth LIMIT 1
+		), $2)
+`
+
+func (r *outboxRepository) TenantLoad(ctx context.Context, tenant string, defaultLimit int) (*messaging.TenantLoad, error) {
+	load := &messaging.TenantLoad{Tenant: tenant}
+	err := r.db.QueryRow(ctx, tenantLoadQuery, tenant, defaultLimit).Scan(&load.InFlight, &load.Held, &load.Limit)
+	if err != nil {
+		r.logger.Error("failed to get tenant load", zap.Error(err), zap.String("tenant", tenant))
+		return nil, fmt.Errorf("failed to get tenant load: %w", classify(err))
+	}
+	return load, nil
+}
+
+// ReleaseHeld отпускает удержанные сообщения каждого арендатора под advisory-блокировкой
+// арендатора, чтобы несколько экземпляров шлюза не превысили лимит вместе
+func (r *outboxRepository) ReleaseHeld(ctx context.Context, defaultLimit, batchSize int) (map[string]int, error) {
+	rows, err := r.db.Query(ctx, `SELECT DISTINCT tenant FROM outbox_messages WHERE held AND published_at IS NULL`)
+	if err != nil {
+		r.logger.Error("failed to get tenants with held messages", zap.Error(err))
+		return nil, fmt.Errorf("failed to get tenants with held messages: %w", classify(err))
+	}
+	tenants, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		r.logger.Error("failed to get tenants with held messages", zap.Error(err))
+		return nil, fmt.Errorf("failed to get tenants with held messages: %w", classify(err))
+	}
+
+	released := make(map[string]int)
+	for _, tenant := range tenants {
+		err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('outbox_release:' || $1))`, tenant); err != nil {
+				return err
+			}
+
+			var load messaging.TenantLoad
+			if err := tx.QueryRow(ctx, tenantLoadQuery, tenant, defaultLimit).Scan(&load.InFlight, &load.Held, &load.Limit); err != nil {
+				return err
+			}
+			free := batchSize
+			if load.Limit > 0 {
+				free = min(free, load.Limit-load.InFlight)
+			}
+			if free <= 0 {
+				return nil
+			}
+
+			tag, err := tx.Exec(ctx, `
+				UPDATE outbox_messages
+				SET held = false, released_at = NOW(), available_at = NOW()
+				WHERE id IN (
+					SELECT id FROM outbox_messages
+					WHERE tenant = $1 AND held AND published_at IS NULL
+					ORDER BY `+priorityRank+`, created_at
+					LIMIT $2
+					FOR UPDATE SKIP LOCKED
+				)
+			`, tenant, free)
+			if err != nil {
+				return err
+			}
+			if n := int(tag.RowsAffected()); n > 0 {
+				released[tenant] = n
+			}
+			return nil
+		})
+		if err != nil {
+			r.logger.Error("failed to release held outbox messages", zap.Error(err), zap.String("tenant", tenant))
+			return released, fmt.Errorf("failed to release held outbox messages: %w", classify(err))
+		}
+	}
+
+	return released, nil
+}
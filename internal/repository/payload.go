@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// Причины отклонения данных при поступлении
+const (
+	RejectedPayloadInvalid   = "INVALID"
+	RejectedPayloadOversized = "OVERSIZED"
+)
+
+// StoredPayload сохраненные данные проверки в том виде, в котором их записал воркер
+type StoredPayload struct {
+	DataType model.VerificationDataType
+	DataHash string
+	Data     string
+}
+
+// RejectedPayload данные, отклоненные при поступлении (миграция 045). Sample - начало данных
+// для разбора причины, сами данные удаляются.
+type RejectedPayload struct {
+	ID             int64
+	VerificationID string
+	DataType       model.VerificationDataType
+	DataHash       string
+	Reason         string
+	Diagnostics    []string
+	SizeBytes      int
+	Sample         string
+	RejectedAt     time.Time
+}
+
+type PayloadRepository interface {
+	// ListByVerification возвращает данные проверки. Данные проверок песочницы не возвращаются:
+	// их формирует сам шлюз.
+	ListByVerification(ctx context.Context, verificationID string) ([]*StoredPayload, error)
+	// Replace сохраняет data вместо данных с хэшем previousHash и возвращает новый хэш. Если воркер
+	// успел заменить данные, возвращается ErrConflict.
+	Replace(ctx context.Context, verificationID string, dataType model.VerificationDataType, previousHash, data string) (string, error)
+	// Reject записывает данные в отклоненные и удаляет их из данных проверки
+	Reject(ctx context.Context, rejected *RejectedPayload) error
+	// ListRejected возвращает отклоненные данные, начиная с последних; verificationID nil - всех проверок
+	ListRejected(ctx context.Context, verificationID *string, limit int) ([]*RejectedPayload, error)
+}
+
+type payloadRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewPayloadRepository(db *pgxpool.Pool, logger *zap.Logger) PayloadRepository {
+	return &payloadRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *payloadRepository) ListByVerification(ctx context.Context, verificationID string) ([]*StoredPayload, error) {
+	query := `
+		SELECT d.data_type, d.data_hash, c.data::text
+		FROM verification_data d
+		JOIN verifications v ON v.id = d.verification_id
+		JOIN verification_data_cache c ON c.data_hash = d.data_hash
+		WHERE d.verification_id = $1 AND NOT v.sandbox
+		ORDER BY d.data_type
+	`
+
+	rows, err := r.db.Query(ctx, query, verificationID)
+	if err != nil {
+		r.logger.Error("failed to get verification payloads", zap.Error(err), zap.String("verification_id", verificationID))
+		return nil, fmt.Errorf("failed to get verification payloads: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var payloads []*StoredPayload
+	for rows.Next() {
+		var payload StoredPayload
+		if err := rows.Scan(&payload.DataType, &payload.DataHash, &payload.Data); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "verification payload", err)
+			continue
+		}
+		payloads = append(payloads, &payload)
+	}
+	return payloads, nil
+}
+
+// Replace сохраняет данные в verification_data_cache под SHA-256 текста JSONB, как воркер, поэтому
+// триггеры verification_data обновляют кэш компании и записывают поправку завершенной проверки
+func (r *payloadRepository) Replace(ctx context.Context, verificationID string, dataType model.VerificationDataType, previousHash, data string) (string, error) {
+	var dataHash string
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO verification_data_cache (data_hash, data)
+			SELECT encode(digest($1::jsonb::text, 'sha256'), 'hex'), $1::jsonb
+			ON CONFLICT (data_hash) DO UPDATE SET data_hash = EXCLUDED.data_hash
+			RETURNING data_hash
+		`, data).Scan(&dataHash)
+		if err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, `
+			UPDATE verification_data SET data_hash = $4
+			WHERE verification_id = $1 AND data_type = $2 AND data_hash = $3
+		`, verificationID, string(dataType), previousHash, dataHash)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return conflictf("%s data of verification %s changed during inspection", dataType, verificationID)
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("failed to replace verification payload", zap.Error(err),
+			zap.String("verification_id", verificationID), zap.String("data_type", string(dataType)))
+		return "", fmt.Errorf("failed to replace verification payload: %w", classify(err))
+	}
+	return dataHash, nil
+}
+
+// Reject удаляет данные из проверки и кэша компании, а из verification_data_cache - если на них
+// больше никто не ссылается
+func (r *payloadRepository) Reject(ctx context.Context, rejected *RejectedPayload) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			DELETE FROM verification_data
+			WHERE verification_id = $1 AND data_type = $2 AND data_hash = $3
+		`, rejected.VerificationID, string(rejected.DataType), rejected.DataHash)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return conflictf("%s data of verification %s changed during inspection", rejected.DataType, rejected.VerificationID)
+		}
+
+		err = tx.QueryRow(ctx, `
+			INSERT INTO rejected_payloads (verification_id, data_type, data_hash, reason, diagnostics, size_bytes, sample)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, rejected_at
+		`, rejected.VerificationID, string(rejected.DataType), rejected.DataHash, rejected.Reason, rejected.Diagnostics,
+			rejected.SizeBytes, rejected.Sample).Scan(&rejected.ID, &rejected.RejectedAt)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			DELETE FROM company_data_cache
+			WHERE verification_id = $1 AND data_type = $2 AND data_hash = $3
+		`, rejected.VerificationID, string(rejected.DataType), rejected.DataHash)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			DELETE FROM verification_data_cache
+			WHERE data_hash = $1
+			  AND NOT EXISTS (SELECT 1 FROM verification_data WHERE data_hash = $1)
+			  AND NOT EXISTS (SELECT 1 FROM company_data_cache WHERE data_hash = $1)
+		`, rejected.DataHash)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("failed to reject verification payload", zap.Error(err),
+			zap.String("verification_id", rejected.VerificationID), zap.String("data_type", string(rejected.DataType)))
+		return fmt.Errorf("failed to reject verification payload: %w", classify(err))
+	}
+	return nil
+}
+
+func (r *payloadRepository) ListRejected(ctx context.Context, verificationID *string, limit int) ([]*RejectedPayload, error) {
+	query := `
+		SELECT id, verification_id, data_type, data_hash, reason, diagnostics, size_bytes, sample, rejected_at
+		FROM rejected_payloads
+		WHERE $1::uuid IS NULL OR verification_id = $1::uuid
+		ORDER BY rejected_at DESC, id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, verificationID, limit)
+	if err != nil {
+		r.logger.Error("failed to list rejected payloads", zap.Error(err))
+		return nil, fmt.Errorf("failed to list rejected payloads: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var rejected []*RejectedPayload
+	for rows.Next() {
+		var payload RejectedPayload
+		err := rows.Scan(&payload.ID, &payload.VerificationID, &payload.DataType, &payload.DataHash, &payload.Reason,
+			&payload.Diagnostics, &payload.SizeBytes, &payload.Sample, &payload.RejectedAt)
+		if err != nil {
+			reportScanFailure(ctx, r.logger, rows, "rejected payload", err)
+			continue
+		}
+		rejected = append(rejected, &payload)
+	}
+	return rejected, nil
+}
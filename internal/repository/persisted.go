@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type PersistedOperationRepository interface {
+	Register(ctx context.Context, apiKeyName string, operation *model.PersistedOperation) error
+	GetDocument(ctx context.Context, apiKeyID, hash string) (string, error)
+	List(ctx context.Context, apiKeyName string) ([]*model.PersistedOperation, error)
+	Delete(ctx context.Context, apiKeyName, hash string) error
+}
+
+type persistedOperationRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewPersistedOperationRepository(db *pgxpool.Pool, logger *zap.Logger) PersistedOperationRepository {
+	return &persistedOperationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Register разрешает операцию всем неотозванным ключам с именем apiKeyName, чтобы при ротации
+// ключа список операций не приходилось переносить. Нет таких ключей - ErrNotFound.
+func (r *persistedOperationRepository) Register(ctx context.Context, apiKeyName string, operation *model.PersistedOperation) error {
+	query := `
+		INSERT INTO persisted_operations (api_key_id, hash, name, document, created_by)
+		SELECT id, $2, $3, $4, $5
+		FROM api_keys
+		WHERE name = $1 AND revoked_at IS NULL
+		ON CONFLICT (api_key_id, hash) DO UPDATE SET name = EXCLUDED.name
+		RETURNING created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, apiKeyName, operation.Hash, operation.Name, operation.Document, operation.CreatedBy)
+	if err != nil {
+		r.logger.Error("failed to register persisted operation", zap.Error(err), zap.String("api_key", apiKeyName))
+		return fmt.Errorf("failed to register persisted operation: %w", classify(err))
+	}
+	defer rows.Close()
+
+	registered := 0
+	for rows.Next() {
+		var createdAt time.Time
+		if err := rows.Scan(&createdAt); err != nil {
+			return fmt.Errorf("failed to register persisted operation: %w", classify(err))
+		}
+		operation.CreatedAt = createdAt.Format(time.RFC3339)
+		registered++
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("failed to register persisted operation", zap.Error(err), zap.String("api_key", apiKeyName))
+		return fmt.Errorf("failed to register persisted operation: %w", classify(err))
+	}
+
+	if registered == 0 {
+		return notFoundf("api key not found: %s", apiKeyName)
+	}
+	return nil
+}
+
+// GetDocument возвращает текст операции, разрешенной ключу. Незарегистрированная операция - ErrNotFound.
+func (r *persistedOperationRepository) GetDocument(ctx context.Context, apiKeyID, hash string) (string, error) {
+	query := `SELECT document FROM persisted_operations WHERE api_key_id = $1 AND hash = $2`
+
+	var document string
+	err := r.db.QueryRow(ctx, query, apiKeyID, hash).Scan(&document)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", notFoundf("persisted operation not found: %s", hash)
+		}
+		r.logger.Error("failed to get persisted operation", zap.Error(err), zap.String("hash", hash))
+		return "", fmt.Errorf("failed to get persisted operation: %w", classify(err))
+	}
+
+	return document, nil
+}
+
+func (r *persistedOperationRepository) List(ctx context.Context, apiKeyName string) ([]*model.PersistedOperation, error) {
+	query := `
+		SELECT DISTINCT ON (o.hash) o.hash, o.name, o.document, o.created_by, o.created_at
+		FROM persisted_operations o
+		JOIN api_keys k ON k.id = o.api_key_id
+		WHERE k.name = $1 AND k.revoked_at IS NULL
+		ORDER BY o.hash, o.created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, apiKeyName)
+	if err != nil {
+		r.logger.Error("failed to list persisted operations", zap.Error(err), zap.String("api_key", apiKeyName))
+		return nil, fmt.Errorf("failed to list persisted operations: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var operations []*model.PersistedOperation
+	for rows.Next() {
+		var operation model.PersistedOperation
+		var createdAt time.Time
+		if err := rows.Scan(&operation.Hash, &operation.Name, &operation.Document, &operation.CreatedBy, &createdAt); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "persisted operation", err)
+			continue
+		}
+		operation.CreatedAt = createdAt.Format(time.RFC3339)
+		operations = append(operations, &operation)
+	}
+
+	return operations, rows.Err()
+}
+
+// Delete запрещает операцию всем неотозванным ключам с именем apiKeyName
+func (r *persistedOperationRepository) Delete(ctx context.Context, apiKeyName, hash string) error {
+	query := `
+		DELETE FROM persisted_operations o
+		USING api_keys k
+		WHERE k.id = o.api_key_id AND k.name = $1 AND k.revoked_at IS NULL AND o.hash = $2
+	`
+
+	tag, err := r.db.Exec(ctx, query, apiKeyName, hash)
+	if err != nil {
+		r.logger.Error("failed to delete persisted operation", zap.Error(err), zap.String("api_key", apiKeyName))
+		return fmt.Errorf("failed to delete persisted operation: %w", classify(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return notFoundf("persisted operation not found: %s", hash)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// PopularCompany часто запрашиваемая компания и ее последняя проверка
+type PopularCompany struct {
+	INN                string
+	RequestedDataTypes []model.VerificationDataType
+	Reads              int
+	LastVerifiedAt     time.Time
+}
+
+type PopularityRepository interface {
+	AddReads(ctx context.Context, day time.Time, reads map[string]int) error
+	GetPopularCompanies(ctx context.Context, since, staleBefore time.Time, limit int) ([]*PopularCompany, error)
+}
+
+type popularityRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewPopularityRepository(db *pgxpool.Pool, logger *zap.Logger) PopularityRepository {
+	return &popularityRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// AddReads прибавляет количество чтений компаний за день
+func (r *popularityRepository) AddReads(ctx context.Context, day time.Time, reads map[string]int) error {
+	if len(reads) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO inn_reads (inn, day, reads)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (inn, day) DO UPDATE SET reads = inn_reads.reads + EXCLUDED.reads
+	`
+
+	batch := &pgx.Batch{}
+	for inn, count := range reads {
+		batch.Queue(query, inn, day.Format(time.DateOnly), count)
+	}
+
+	if err := r.db.SendBatch(ctx, batch).Close(); err != nil {
+		r.logger.Error("failed to add inn reads", zap.Error(err), zap.Int("companies", len(reads)))
+		return fmt.Errorf("failed to add inn reads: %w", classify(err))
+	}
+
+	return nil
+}
+
+// GetPopularCompanies возвращает компании, чаще всего запрашиваемые с since, последняя проверка
+// которых старше staleBefore. Компании отсортированы по убыванию количества чтений.
+func (r *popularityRepository) GetPopularCompanies(ctx context.Context, since, staleBefore time.Time, limit int) ([]*PopularCompany, error) {
+	query := `
+		SELECT r.inn, latest.requested_data_types, r.reads, latest.created_at
+		FROM (
+			SELECT inn, SUM(reads) AS reads
+			FROM inn_reads
+			WHERE day >= $1::date
+			GROUP BY inn
+		) r
+		JOIN LATERAL (
+			SELECT requested_data_types, created_at
+			FROM verifications v
+			WHERE v.inn = r.inn AND NOT v.sandbox
+			ORDER BY created_at DESC
+			LIMIT 1
+		) latest ON true
+		WHERE latest.created_at < $2
+		ORDER BY r.reads DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(ctx, query, since.Format(time.DateOnly), staleBefore, limit)
+	if err != nil {
+		r.logger.Error("failed to get popular companies", zap.Error(err))
+		return nil, fmt.Errorf("failed to get popular companies: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var companies []*PopularCompany
+	for rows.Next() {
+		var c PopularCompany
+		var requested dataTypeArray
+		if err := rows.Scan(&c.INN, &requested, &c.Reads, &c.LastVerifiedAt); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "popular company", err)
+			continue
+		}
+		c.RequestedDataTypes = model.DataTypesFromDomain(requested)
+		companies = append(companies, &c)
+	}
+
+	return companies, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type PrivacyRepository interface {
+	GetAnonymizationCandidates(ctx context.Context, createdBefore time.Time, limit int) ([]string, error)
+	Anonymize(ctx context.Context, id, salt string) (bool, error)
+	SetLegalHold(ctx context.Context, id string, hold bool) error
+}
+
+type privacyRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewPrivacyRepository(db *pgxpool.Pool, logger *zap.Logger) PrivacyRepository {
+	return &privacyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetAnonymizationCandidates возвращает ID проверок старше createdBefore, которые еще не обезличены
+// и не находятся на удержании
+func (r *privacyRepository) GetAnonymizationCandidates(ctx context.Context, createdBefore time.Time, limit int) ([]string, error) {
+	query := `
+		SELECT id
+		FROM verifications
+		WHERE created_at < $1 AND anonymized_at IS NULL AND legal_hold = false
+		ORDER BY created_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, createdBefore, limit)
+	if err != nil {
+		r.logger.Error("failed to get anonymization candidates", zap.Error(err))
+		return nil, fmt.Errorf("failed to get anonymization candidates: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "anonymization candidate", err)
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Anonymize заменяет email автора и участников проверки солеными хэшами (anonymize_email из миграции 019)
+// и удаляет уведомления пользователей по проверке. Возвращает false, если проверка тем временем
+// поставлена на удержание или уже обезличена.
+func (r *privacyRepository) Anonymize(ctx context.Context, id, salt string) (bool, error) {
+	var anonymized bool
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			UPDATE verifications SET author_email = anonymize_email(author_email, $2), anonymized_at = NOW()
+			WHERE id = $1 AND anonymized_at IS NULL AND legal_hold = false
+		`, id, salt)
+		if err != nil || tag.RowsAffected() == 0 {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE verification_events SET actor = anonymize_email(actor, $2) WHERE verification_id = $1 AND actor IS NOT NULL`, id, salt); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM notifications WHERE verification_id = $1`, id); err != nil {
+			return err
+		}
+		// Иначе перестроение модели чтения по событиям вернет исходный email
+		if _, err := tx.Exec(ctx, `
+			UPDATE verification_event_store
+			SET payload = jsonb_set(payload, '{author_email}', to_jsonb(anonymize_email(payload->>'author_email', $2)))
+			WHERE verification_id = $1 AND payload ? 'author_email'
+		`, id, salt); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM outbox_messages WHERE id = $1 AND published_at IS NOT NULL`, id); err != nil {
+			return err
+		}
+
+		anonymized = true
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("failed to anonymize verification", zap.Error(err), zap.String("id", id))
+		return false, fmt.Errorf("failed to anonymize verification: %w", classify(err))
+	}
+
+	return anonymized, nil
+}
+
+// SetLegalHold ставит проверку на удержание или снимает его
+func (r *privacyRepository) SetLegalHold(ctx context.Context, id string, hold bool) error {
+	tag, err := r.db.Exec(ctx, `UPDATE verifications SET legal_hold = $2 WHERE id = $1`, id, hold)
+	if err != nil {
+		r.logger.Error("failed to set legal hold", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to set legal hold: %w", classify(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return notFoundf("verification not found: %s", id)
+	}
+
+	return nil
+}
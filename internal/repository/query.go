@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+// selectBuilder собирает SELECT-запрос с позиционными параметрами.
+// Значения никогда не подставляются в текст запроса, поэтому текст остается одинаковым
+// для одинакового набора условий и кэшируется pgx как подготовленный запрос.
+type selectBuilder struct {
+	base    string
+	where   []string
+	orderBy string
+	limit   *int32
+	offset  *int32
+	args    []any
+}
+
+func newSelect(base string) *selectBuilder {
+	return &selectBuilder{base: base}
+}
+
+// Where добавляет условие. Символы '?' в условии заменяются на позиционные параметры.
+func (b *selectBuilder) Where(condition string, args ...any) *selectBuilder {
+	for _, arg := range args {
+		b.args = append(b.args, arg)
+		condition = strings.Replace(condition, "?", fmt.Sprintf("$%d", len(b.args)), 1)
+	}
+	b.where = append(b.where, condition)
+	return b
+}
+
+func (b *selectBuilder) OrderBy(expr string) *selectBuilder {
+	b.orderBy = expr
+	return b
+}
+
+func (b *selectBuilder) Limit(limit *int32) *selectBuilder {
+	b.limit = limit
+	return b
+}
+
+func (b *selectBuilder) Offset(offset *int32) *selectBuilder {
+	b.offset = offset
+	return b
+}
+
+// Build возвращает текст запроса и аргументы
+func (b *selectBuilder) Build() (string, []any) {
+	var sb strings.Builder
+	sb.WriteString(b.base)
+
+	if len(b.where) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(b.where, " AND "))
+	}
+
+	if b.orderBy != "" {
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(b.orderBy)
+	}
+
+	args := append([]any(nil), b.args...)
+	if b.limit != nil {
+		args = append(args, *b.limit)
+		fmt.Fprintf(&sb, " LIMIT $%d", len(args))
+	}
+	if b.offset != nil {
+		args = append(args, *b.offset)
+		fmt.Fprintf(&sb, " OFFSET $%d", len(args))
+	}
+
+	return sb.String(), args
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"testing"
+)
+
+func TestSelectBuilder(t *testing.T) {
+	limit := int32(10)
+	offset := int32(20)
+	author := "analyst@bank.ru"
+	tenant := "bank.ru"
+
+	tests := []struct {
+		name          string
+		builder       *selectBuilder
+		expectedQuery string
+		expectedArgs  []any
+	}{
+		{
+			name:          "no_conditions",
+			builder:       newSelect("SELECT id FROM verifications").OrderBy("created_at DESC"),
+			expectedQuery: "SELECT id FROM verifications ORDER BY created_at DESC",
+			expectedArgs:  nil,
+		},
+		{
+			name: "conditions_with_limit_and_offset",
+			builder: newSelect("SELECT id FROM verifications").
+				Where("status = ?", "COMPLETED").
+				Where("created_at >= ? AND created_at < ?", "from", "to").
+				OrderBy("created_at DESC").
+				Limit(&limit).
+				Offset(&offset),
+			expectedQuery: "SELECT id FROM verifications WHERE status = $1 AND created_at >= $2 AND created_at < $3 ORDER BY created_at DESC LIMIT $4 OFFSET $5",
+			expectedArgs:  []any{"COMPLETED", "from", "to", int32(10), int32(20)},
+		},
+		{
+			name:          "offset_only",
+			builder:       newSelect("SELECT id FROM verifications").Offset(&offset),
+			expectedQuery: "SELECT id FROM verifications OFFSET $1",
+			expectedArgs:  []any{int32(20)},
+		},
+		{
+			name: "scoped_filter",
+			builder: VerificationFilter{AuthorEmail: &author, Tenant: &tenant}.
+				apply(newSelect("SELECT id FROM verifications")),
+			expectedQuery: "SELECT id FROM verifications WHERE author_email = $1 AND " + tenantExpr + " = $2",
+			expectedArgs:  []any{"analyst@bank.ru", "bank.ru"},
+		},
+		{
+			name: "organization_members",
+			builder: VerificationFilter{Organization: &tenant}.
+				apply(newSelect("SELECT id FROM verifications")),
+			expectedQuery: "SELECT id FROM verifications WHERE author_email IN (SELECT u.email FROM memberships m JOIN users u ON u.id = m.user_id WHERE m.organization_id = $1)",
+			expectedArgs:  []any{"bank.ru"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := tt.builder.Build()
+
+			if query != tt.expectedQuery {
+				t.Errorf("expected query '%s', but got '%s'", tt.expectedQuery, query)
+			}
+
+			if len(args) != len(tt.expectedArgs) {
+				t.Fatalf("expected args %v, but got %v", tt.expectedArgs, args)
+			}
+			for i := range args {
+				if args[i] != tt.expectedArgs[i] {
+					t.Errorf("expected args %v, but got %v", tt.expectedArgs, args)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// DataRedaction изменение сохраненных данных проверки администратором. Данные до изменения
+// остаются в verification_data_redactions (миграция 039), строки которой нельзя изменить.
+type DataRedaction struct {
+	ID               string
+	VerificationID   string
+	DataType         model.VerificationDataType
+	Operation        model.DataRedactionOperation
+	Path             []string
+	PreviousDataHash string
+	DataHash         string
+	Reason           string
+	Actor            string
+	CreatedAt        time.Time
+	KeyID            string
+	Signature        string
+}
+
+type DataRedactionRepository interface {
+	// Apply заменяет значение по пути change.Path на JSON value и записывает изменение в историю.
+	// Хэши данных до и после изменения заполняются в change, после чего в той же транзакции
+	// вызывается sign, который должен заполнить KeyID и Signature.
+	Apply(ctx context.Context, change *DataRedaction, value []byte, sign func(*DataRedaction)) error
+	// ListByVerification возвращает изменения данных проверки в порядке внесения
+	ListByVerification(ctx context.Context, verificationID string) ([]*DataRedaction, error)
+}
+
+type dataRedactionRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewDataRedactionRepository(db *pgxpool.Pool, logger *zap.Logger) DataRedactionRepository {
+	return &dataRedactionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Apply сохраняет измененные данные в verification_data_cache под SHA-256 текста JSONB, как воркер,
+// и переводит на них verification_data. Исходные данные удаляются из кэша при REDACT, если на них
+// больше никто не ссылается, и остаются только в истории.
+func (r *dataRedactionRepository) Apply(ctx context.Context, change *DataRedaction, value []byte, sign func(*DataRedaction)) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var (
+			rowID      string
+			sandbox    bool
+			pathExists bool
+		)
+		err := tx.QueryRow(ctx, `
+			SELECT d.id, d.data_hash, v.sandbox, c.data #> $3::text[] IS NOT NULL
+			FROM verification_data d
+			JOIN verifications v ON v.id = d.verification_id
+			JOIN verification_data_cache c ON c.data_hash = d.data_hash
+			WHERE d.verification_id = $1 AND d.data_type = $2
+			FOR UPDATE OF d
+		`, change.VerificationID, string(change.DataType), change.Path).Scan(&rowID, &change.PreviousDataHash, &sandbox, &pathExists)
+		if err == pgx.ErrNoRows {
+			return notFoundf("%s data of verification %s not found", change.DataType, change.VerificationID)
+		}
+		if err != nil {
+			return err
+		}
+		if sandbox {
+			return conflictf("data of sandbox verification %s cannot be changed", change.VerificationID)
+		}
+		if change.Operation == model.DataRedactionOperationRedact && !pathExists {
+			return notFoundf("path %v not found in %s data", change.Path, change.DataType)
+		}
+
+		err = tx.QueryRow(ctx, `
+			WITH changed AS (
+				SELECT jsonb_set(data, $2::text[], $3::jsonb) AS data
+				FROM verification_data_cache
+				WHERE data_hash = $1
+			)
+			INSERT INTO verification_data_cache (data_hash, data)
+			SELECT encode(digest(data::text, 'sha256'), 'hex'), data FROM changed
+			ON CONFLICT (data_hash) DO UPDATE SET data_hash = EXCLUDED.data_hash
+			RETURNING data_hash
+		`, change.PreviousDataHash, change.Path, string(value)).Scan(&change.DataHash)
+		if err != nil {
+			return err
+		}
+		if change.DataHash == change.PreviousDataHash {
+			// jsonb_set не создает промежуточные ключи, а то же значение не меняет хэш
+			return conflictf("%s data is unchanged: the parent of path %v does not exist or the value is the same", change.DataType, change.Path)
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE verification_data SET data_hash = $2 WHERE id = $1`, rowID, change.DataHash); err != nil {
+			return err
+		}
+
+		sign(change)
+		_, err = tx.Exec(ctx, `
+			INSERT INTO verification_data_redactions (id, verification_id, data_type, operation, path, original_data,
+				previous_data_hash, data_hash, reason, actor, created_at, key_id, signature)
+			SELECT $1, $2, $3, $4, $5, data, $6, $7, $8, $9, $10, $11, $12
+			FROM verification_data_cache
+			WHERE data_hash = $6
+		`, change.ID, change.VerificationID, string(change.DataType), string(change.Operation), change.Path,
+			change.PreviousDataHash, change.DataHash, change.Reason, change.Actor, change.CreatedAt, change.KeyID, change.Signature)
+		if err != nil {
+			return err
+		}
+
+		if change.Operation != model.DataRedactionOperationRedact {
+			return nil
+		}
+		_, err = tx.Exec(ctx, `
+			DELETE FROM verification_data_cache
+			WHERE data_hash = $1
+			  AND NOT EXISTS (SELECT 1 FROM verification_data WHERE data_hash = $1)
+			  AND NOT EXISTS (SELECT 1 FROM company_data_cache WHERE data_hash = $1)
+		`, change.PreviousDataHash)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("failed to change verification data", zap.Error(err),
+			zap.String("verification_id", change.VerificationID), zap.String("data_type", string(change.DataType)))
+		return fmt.Errorf("failed to change verification data: %w", classify(err))
+	}
+
+	return nil
+}
+
+func (r *dataRedactionRepository) ListByVerification(ctx context.Context, verificationID string) ([]*DataRedaction, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, verification_id, data_type, operation, path, previous_data_hash, data_hash,
+			reason, actor, created_at, key_id, signature
+		FROM verification_data_redactions
+		WHERE verification_id = $1
+		ORDER BY created_at, id
+	`, verificationID)
+	if err != nil {
+		r.logger.Error("failed to get verification data redactions", zap.Error(err), zap.String("verification_id", verificationID))
+		return nil, fmt.Errorf("failed to get verification data redactions: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var redactions []*DataRedaction
+	for rows.Next() {
+		var d DataRedaction
+		err := rows.Scan(&d.ID, &d.VerificationID, &d.DataType, &d.Operation, &d.Path, &d.PreviousDataHash, &d.DataHash,
+			&d.Reason, &d.Actor, &d.CreatedAt, &d.KeyID, &d.Signature)
+		if err != nil {
+			reportScanFailure(ctx, r.logger, rows, "verification data redaction", err)
+			continue
+		}
+		redactions = append(redactions, &d)
+	}
+	return redactions, nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"scoring_api_gateway/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// ReplayRepository сохраняет проверки, завершенные воспроизведением записанных ответов поставщиков
+type ReplayRepository interface {
+	SaveCompleted(ctx context.Context, verification *domain.Verification) error
+}
+
+type replayRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewReplayRepository(db *pgxpool.Pool, logger *zap.Logger) ReplayRepository {
+	return &replayRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SaveCompleted сохраняет проверку так же, как воркер: данные пишутся, пока проверка IN_PROCESS,
+// и только затем она получает итоговый статус. Иначе триггер принял бы данные за поправки.
+func (r *replayRepository) SaveCompleted(ctx context.Context, verification *domain.Verification) error {
+	inProcess := *verification
+	inProcess.Status = domain.StatusInProcess
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := insertVerificationWithData(ctx, tx, &inProcess); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `UPDATE verifications SET status = $2, updated_at = $3 WHERE id = $1`,
+			verification.ID, string(verification.Status), verification.UpdatedAt)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("failed to save replayed verification", zap.Error(err), zap.String("id", verification.ID))
+		return fmt.Errorf("failed to save replayed verification: %w", classify(err))
+	}
+
+	return nil
+}
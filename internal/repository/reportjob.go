@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// Статусы заданий формирования отчетов
+const (
+	ReportJobQueued    = "QUEUED"
+	ReportJobRunning   = "RUNNING"
+	ReportJobSucceeded = "SUCCEEDED"
+	ReportJobFailed    = "FAILED"
+	// ReportJobExpired срок хранения отчета истек, объект удален
+	ReportJobExpired = "EXPIRED"
+)
+
+// ReportJob задание формирования отчета по делу (миграция 048)
+type ReportJob struct {
+	ID          string
+	Tenant      string
+	RequestedBy string
+	CaseID      string
+	Format      string
+	Status      string
+	Progress    int
+	Attempts    int
+	ObjectKey   *string
+	SizeBytes   *int64
+	Signature   *string
+	KeyID       *string
+	Error       *string
+	CreatedAt   time.Time
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+	ExpiresAt   *time.Time
+}
+
+// ReportResult сохраненный в объектном хранилище отчет
+type ReportResult struct {
+	ObjectKey string
+	SizeBytes int64
+	Signature *string
+	KeyID     *string
+	ExpiresAt time.Time
+}
+
+type ReportJobRepository interface {
+	// Create ставит задание в очередь, если у организации меньше maxQueued незавершенных заданий.
+	// Иначе возвращает ErrConflict.
+	Create(ctx context.Context, job *ReportJob, maxQueued int) error
+	GetByID(ctx context.Context, tenant, id string) (*ReportJob, error)
+	// List возвращает задания организации, начиная с последних
+	List(ctx context.Context, tenant string, limit int) ([]*ReportJob, error)
+	// Claim забирает до claimUntil самое старое задание организации, у которой формируется меньше
+	// maxRunningPerTenant отчетов, или задание, захват которого истек. Без подходящих заданий
+	// возвращает nil.
+	Claim(ctx context.Context, claimUntil time.Time, maxRunningPerTenant int) (*ReportJob, error)
+	// Progress сохраняет ход формирования и продлевает захват
+	Progress(ctx context.Context, id string, progress int, claimUntil time.Time) error
+	Complete(ctx context.Context, id string, result *ReportResult) error
+	Fail(ctx context.Context, id, message string) error
+	// ListExpired возвращает готовые отчеты, срок хранения которых истек
+	ListExpired(ctx context.Context, limit int) ([]*ReportJob, error)
+	MarkExpired(ctx context.Context, id string) error
+}
+
+type reportJobRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewReportJobRepository(db *pgxpool.Pool, logger *zap.Logger) ReportJobRepository {
+	return &reportJobRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const reportJobColumns = `id, tenant, requested_by, case_id, format, status, progress, attempts, object_key, size_bytes,
+	signature, key_id, error, created_at, started_at, finished_at, expires_at`
+
+func (r *reportJobRepository) Create(ctx context.Context, job *ReportJob, maxQueued int) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		// Блокировка не дает одновременным запросам организации превысить предел очереди
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('report_jobs:' || $1))`, job.Tenant); err != nil {
+			return err
+		}
+
+		var pending int
+		err := tx.QueryRow(ctx, `
+			SELECT COUNT(*) FROM report_jobs WHERE tenant = $1 AND status IN ('QUEUED', 'RUNNING')
+		`, job.Tenant).Scan(&pending)
+		if err != nil {
+			return err
+		}
+		if pending >= maxQueued {
+			return conflictf("organization %s already has %d unfinished reports, the limit is %d", job.Tenant, pending, maxQueued)
+		}
+
+		return tx.QueryRow(ctx, `
+			INSERT INTO report_jobs (tenant, requested_by, case_id, format)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, status, progress, created_at
+		`, job.Tenant, job.RequestedBy, job.CaseID, job.Format).Scan(&job.ID, &job.Status, &job.Progress, &job.CreatedAt)
+	})
+	if err != nil {
+		r.logger.Error("failed to create report job", zap.Error(err), zap.String("tenant", job.Tenant))
+		return fmt.Errorf("failed to create report job: %w", classify(err))
+	}
+	return nil
+}
+
+func (r *reportJobRepository) GetByID(ctx context.Context, tenant, id string) (*ReportJob, error) {
+	query := `SELECT ` + reportJobColumns + ` FROM report_jobs WHERE id = $1 AND tenant = $2`
+
+	job, err := scanReportJob(r.db.QueryRow(ctx, query, id, tenant))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, notFoundf("report job not found: %s", id)
+		}
+		r.logger.Error("failed to get report job", zap.Error(err), zap.String("id", id))
+		return nil, fmt.Errorf("failed to get report job: %w", classify(err))
+	}
+	return job, nil
+}
+
+func (r *reportJobRepository) List(ctx context.Context, tenant string, limit int) ([]*ReportJob, error) {
+	query := `SELECT ` + reportJobColumns + `
+		FROM report_jobs
+		WHERE tenant = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	return r.query(ctx, "list report jobs", query, tenant, limit)
+}
+
+// Claim выполняется под общей блокировкой: иначе два экземпляра могут одновременно насчитать
+// свободное место у одной организации и превысить предел
+func (r *reportJobRepository) Claim(ctx context.Context, claimUntil time.Time, maxRunningPerTenant int) (*ReportJob, error) {
+	var job *ReportJob
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('report_jobs_claim'))`); err != nil {
+			return err
+		}
+
+		var err error
+		job, err = scanReportJob(tx.QueryRow(ctx, `
+			UPDATE report_jobs
+			SET status = 'RUNNING', claimed_until = $1, attempts = attempts + 1,
+				started_at = COALESCE(started_at, NOW())
+			WHERE id = (
+				SELECT j.id FROM report_jobs j
+				WHERE (j.status = 'QUEUED' AND (
+					SELECT COUNT(*) FROM report_jobs running
+					WHERE running.tenant = j.tenant AND running.status = 'RUNNING' AND running.claimed_until >= NOW()
+				) < $2)
+				   OR (j.status = 'RUNNING' AND j.claimed_until < NOW())
+				ORDER BY j.created_at
+				LIMIT 1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING `+reportJobColumns, claimUntil, maxRunningPerTenant))
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error("failed to claim report job", zap.Error(err))
+		return nil, fmt.Errorf("failed to claim report job: %w", classify(err))
+	}
+	return job, nil
+}
+
+func (r *reportJobRepository) Progress(ctx context.Context, id string, progress int, claimUntil time.Time) error {
+	query := `
+		UPDATE report_jobs SET progress = $2, claimed_until = $3
+		WHERE id = $1 AND status = 'RUNNING'
+	`
+	return r.update(ctx, "save report progress", id, query, id, progress, claimUntil)
+}
+
+func (r *reportJobRepository) Complete(ctx context.Context, id string, result *ReportResult) error {
+	query := `
+		UPDATE report_jobs
+		SET status = 'SUCCEEDED', progress = 100, claimed_until = NULL, object_key = $2, size_bytes = $3,
+			signature = $4, key_id = $5, expires_at = $6, finished_at = NOW()
+		WHERE id = $1 AND status = 'RUNNING'
+	`
+	return r.update(ctx, "complete report job", id, query, id, result.ObjectKey, result.SizeBytes, result.Signature,
+		result.KeyID, result.ExpiresAt)
+}
+
+func (r *reportJobRepository) Fail(ctx context.Context, id, message string) error {
+	query := `
+		UPDATE report_jobs
+		SET status = 'FAILED', claimed_until = NULL, error = $2, finished_at = NOW()
+		WHERE id = $1 AND status = 'RUNNING'
+	`
+	return r.update(ctx, "fail report job", id, query, id, message)
+}
+
+func (r *reportJobRepository) ListExpired(ctx context.Context, limit int) ([]*ReportJob, error) {
+	query := `SELECT ` + reportJobColumns + `
+		FROM report_jobs
+		WHERE status = 'SUCCEEDED' AND expires_at < NOW()
+		ORDER BY expires_at
+		LIMIT $1
+	`
+	return r.query(ctx, "list expired reports", query, limit)
+}
+
+func (r *reportJobRepository) MarkExpired(ctx context.Context, id string) error {
+	query := `UPDATE report_jobs SET status = 'EXPIRED' WHERE id = $1 AND status = 'SUCCEEDED'`
+	return r.update(ctx, "mark report expired", id, query, id)
+}
+
+// update меняет задание; если задание не в ожидаемом статусе (например, его забрал другой
+// экземпляр после истечения захвата), возвращает ErrConflict
+func (r *reportJobRepository) update(ctx context.Context, action, id, query string, args ...any) error {
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("failed to "+action, zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to %s: %w", action, classify(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return conflictf("report job %s changed its status", id)
+	}
+	return nil
+}
+
+func (r *reportJobRepository) query(ctx context.Context, action, query string, args ...any) ([]*ReportJob, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("failed to "+action, zap.Error(err))
+		return nil, fmt.Errorf("failed to %s: %w", action, classify(err))
+	}
+	defer rows.Close()
+
+	var jobs []*ReportJob
+	for rows.Next() {
+		job, err := scanReportJob(rows)
+		if err != nil {
+			reportScanFailure(ctx, r.logger, rows, "report job", err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func scanReportJob(row pgx.Row) (*ReportJob, error) {
+	var j ReportJob
+	err := row.Scan(&j.ID, &j.Tenant, &j.RequestedBy, &j.CaseID, &j.Format, &j.Status, &j.Progress, &j.Attempts,
+		&j.ObjectKey, &j.SizeBytes, &j.Signature, &j.KeyID, &j.Error, &j.CreatedAt, &j.StartedAt, &j.FinishedAt, &j.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
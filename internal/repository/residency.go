@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// ResidencyRepository регионы хранения арендаторов и каталог проверок, хранящихся вне домашней базы.
+// Таблицы находятся в домашней базе шлюза.
+type ResidencyRepository interface {
+	// GetTenantRegions возвращает регионы организаций с organizations.storage_region
+	GetTenantRegions(ctx context.Context) (map[string]string, error)
+	// AssignRegion записывает регион проверки. Регион уже записанной проверки не меняется.
+	AssignRegion(ctx context.Context, verificationID, region string) error
+	// GetRegion возвращает регион проверки; пустая строка - проверки нет в каталоге
+	GetRegion(ctx context.Context, verificationID string) (string, error)
+}
+
+type residencyRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewResidencyRepository(db *pgxpool.Pool, logger *zap.Logger) ResidencyRepository {
+	return &residencyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *residencyRepository) GetTenantRegions(ctx context.Context) (map[string]string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, storage_region
+		FROM organizations
+		WHERE storage_region IS NOT NULL AND storage_region <> ''
+	`)
+	if err != nil {
+		r.logger.Error("failed to get tenant regions", zap.Error(err))
+		return nil, fmt.Errorf("failed to get tenant regions: %w", classify(err))
+	}
+	defer rows.Close()
+
+	regions := make(map[string]string)
+	for rows.Next() {
+		var tenant, region string
+		if err := rows.Scan(&tenant, &region); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "tenant region", err)
+			continue
+		}
+		regions[tenant] = region
+	}
+
+	return regions, nil
+}
+
+func (r *residencyRepository) AssignRegion(ctx context.Context, verificationID, region string) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO verification_regions (verification_id, region)
+		VALUES ($1, $2)
+		ON CONFLICT (verification_id) DO NOTHING
+	`, verificationID, region)
+	if err != nil {
+		r.logger.Error("failed to assign verification region", zap.Error(err), zap.String("verification_id", verificationID))
+		return fmt.Errorf("failed to assign verification region: %w", classify(err))
+	}
+	return nil
+}
+
+func (r *residencyRepository) GetRegion(ctx context.Context, verificationID string) (string, error) {
+	var region string
+	err := r.db.QueryRow(ctx, `SELECT region FROM verification_regions WHERE verification_id = $1`, verificationID).Scan(&region)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		r.logger.Error("failed to get verification region", zap.Error(err), zap.String("verification_id", verificationID))
+		return "", fmt.Errorf("failed to get verification region: %w", classify(err))
+	}
+	return region, nil
+}
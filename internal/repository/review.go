@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"scoring_api_gateway/graph/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// ReviewRepository ручное ревью проверок аналитиками. Переходы выполняются одним условным UPDATE,
+// поэтому два аналитика не могут одновременно взять или закрыть одну проверку.
+type ReviewRepository interface {
+	// Assign назначает аналитика и переводит проверку в IN_REVIEW. onlyUnassigned - назначение
+	// допускается, только если проверку еще никто не взял.
+	Assign(ctx context.Context, id string, assignee string, onlyUnassigned bool) error
+	// Decide записывает решение назначенного аналитика по завершенной проверке
+	Decide(ctx context.Context, id string, reviewer string, decision model.ReviewState, comment *string) error
+}
+
+type reviewRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewReviewRepository(db *pgxpool.Pool, logger *zap.Logger) ReviewRepository {
+	return &reviewRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *reviewRepository) Assign(ctx context.Context, id string, assignee string, onlyUnassigned bool) error {
+	query := `
+		UPDATE verifications
+		SET assignee = $2, review_state = 'IN_REVIEW'
+		WHERE id = $1 AND review_state IN ('UNREVIEWED', 'IN_REVIEW') AND (NOT $3 OR assignee IS NULL)
+	`
+
+	tag, err := r.db.Exec(ctx, query, id, assignee, onlyUnassigned)
+	if err != nil {
+		r.logger.Error("failed to assign verification", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to assign verification: %w", classify(err))
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	state, current, err := r.getReviewState(ctx, id)
+	if err != nil {
+		return err
+	}
+	if state == model.ReviewStateApproved || state == model.ReviewStateRejected {
+		return conflictf("verification %s is already reviewed: %s", id, state)
+	}
+	if current != nil {
+		return conflictf("verification %s is already assigned to %s", id, *current)
+	}
+	return conflictf("verification %s cannot be assigned", id)
+}
+
+func (r *reviewRepository) Decide(ctx context.Context, id string, reviewer string, decision model.ReviewState, comment *string) error {
+	query := `
+		UPDATE verifications
+		SET review_state = $3, review_comment = $4, reviewed_at = NOW()
+		WHERE id = $1 AND assignee = $2 AND review_state = 'IN_REVIEW'
+			AND status IN ('COMPLETED', 'PARTIALLY_COMPLETED')
+	`
+
+	tag, err := r.db.Exec(ctx, query, id, reviewer, string(decision), comment)
+	if err != nil {
+		r.logger.Error("failed to record review decision", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to record review decision: %w", classify(err))
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	state, assignee, err := r.getReviewState(ctx, id)
+	if err != nil {
+		return err
+	}
+	if state != model.ReviewStateInReview {
+		return conflictf("verification %s is not in review: %s", id, state)
+	}
+	if assignee == nil || *assignee != reviewer {
+		return conflictf("verification %s is assigned to another analyst", id)
+	}
+	return conflictf("verification %s is not completed yet", id)
+}
+
+// getReviewState объясняет, почему переход не выполнен
+func (r *reviewRepository) getReviewState(ctx context.Context, id string) (model.ReviewState, *string, error) {
+	var state model.ReviewState
+	var assignee *string
+	err := r.db.QueryRow(ctx, `SELECT review_state, assignee FROM verifications WHERE id = $1`, id).Scan(&state, &assignee)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil, notFoundf("verification not found: %s", id)
+	}
+	if err != nil {
+		r.logger.Error("failed to get review state", zap.Error(err), zap.String("id", id))
+		return "", nil, fmt.Errorf("failed to get review state: %w", classify(err))
+	}
+	return state, assignee, nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"scoring_api_gateway/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type SandboxRepository interface {
+	IsSandboxTenant(ctx context.Context, tenant string) (bool, error)
+	SaveCompleted(ctx context.Context, verification *domain.Verification) error
+}
+
+type sandboxRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewSandboxRepository(db *pgxpool.Pool, logger *zap.Logger) SandboxRepository {
+	return &sandboxRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// IsSandboxTenant проверяет, переведена ли организация в режим песочницы
+func (r *sandboxRepository) IsSandboxTenant(ctx context.Context, tenant string) (bool, error) {
+	var sandbox bool
+	err := r.db.QueryRow(ctx, `SELECT sandbox FROM organizations WHERE id = $1`, tenant).Scan(&sandbox)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		r.logger.Error("failed to get organization sandbox flag", zap.Error(err), zap.String("tenant", tenant))
+		return false, fmt.Errorf("failed to get organization sandbox flag: %w", classify(err))
+	}
+	return sandbox, nil
+}
+
+// SaveCompleted сохраняет завершенную проверку песочницы вместе с данными. Данные попадают
+// в verification_data_cache под тем же хэшем, что и у воркера: SHA-256 текста JSONB.
+func (r *sandboxRepository) SaveCompleted(ctx context.Context, verification *domain.Verification) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return insertVerificationWithData(ctx, tx, verification)
+	})
+	if err != nil {
+		r.logger.Error("failed to save sandbox verification", zap.Error(err), zap.String("id", verification.ID))
+		return fmt.Errorf("failed to save sandbox verification: %w", classify(err))
+	}
+
+	return nil
+}
+
+// insertVerificationWithData сохраняет проверку, данные которой получены без участия воркеров
+// (песочницы, демонстрационную или воспроизведенную), вместе с данными в транзакции tx
+func insertVerificationWithData(ctx context.Context, tx pgx.Tx, verification *domain.Verification) error {
+	requested := make([]string, 0, len(verification.RequestedDataTypes))
+	for _, dataType := range verification.RequestedDataTypes {
+		requested = append(requested, string(dataType))
+	}
+	missing := make([]string, 0, len(verification.MissingDataTypes))
+	for _, dataType := range verification.MissingDataTypes {
+		missing = append(missing, string(dataType))
+	}
+
+	var riskLevel *string
+	if verification.RiskLevel != nil {
+		level := string(*verification.RiskLevel)
+		riskLevel = &level
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO verifications (id, inn, status, author_email, requested_data_types, missing_data_types, risk_level, risk_ruleset_id, sandbox, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, verification.ID, verification.INN, string(verification.Status), verification.AuthorEmail, requested, missing, riskLevel,
+		verification.RulesetID, verification.Sandbox, verification.CreatedAt, verification.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	return insertVerificationData(ctx, tx, verification.ID, verification.Data)
+}
+
+// insertVerificationData сохраняет данные проверки id в verification_data_cache под тем же хэшем,
+// что и у воркера, и ссылки на них в verification_data
+func insertVerificationData(ctx context.Context, tx pgx.Tx, id string, payloads []*domain.Data) error {
+	for _, data := range payloads {
+		_, err := tx.Exec(ctx, `
+			WITH cached AS (
+				INSERT INTO verification_data_cache (data_hash, data)
+				VALUES (encode(digest($3::jsonb::text, 'sha256'), 'hex'), $3::jsonb)
+				ON CONFLICT (data_hash) DO UPDATE SET data_hash = EXCLUDED.data_hash
+				RETURNING data_hash
+			)
+			INSERT INTO verification_data (verification_id, data_type, data_hash, schema_version, created_at)
+			SELECT $1, $2, data_hash, $5, $4 FROM cached
+		`, id, string(data.DataType), data.Payload, data.CreatedAt, max(data.SchemaVersion, 1))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"scoring_api_gateway/internal/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// ScanFailure строка результата запроса, которую не удалось прочитать. Такие строки пропускаются,
+// а ответ считается частичным.
+type ScanFailure struct {
+	Entity string `json:"entity"`
+	// ID идентификатор строки, если первая колонка запроса - идентификатор
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+}
+
+type scanFailuresKey struct{}
+
+type scanFailures struct {
+	mu       sync.Mutex
+	failures []ScanFailure
+}
+
+// WithScanFailures возвращает контекст, в котором репозитории собирают пропущенные строки
+func WithScanFailures(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scanFailuresKey{}, &scanFailures{})
+}
+
+// ScanFailuresFromContext возвращает строки, пропущенные при выполнении запросов с этим контекстом
+func ScanFailuresFromContext(ctx context.Context) []ScanFailure {
+	collected, ok := ctx.Value(scanFailuresKey{}).(*scanFailures)
+	if !ok {
+		return nil
+	}
+	collected.mu.Lock()
+	defer collected.mu.Unlock()
+	return append([]ScanFailure(nil), collected.failures...)
+}
+
+// reportScanFailure записывает в журнал и метрику строку, которую не удалось прочитать, и
+// добавляет ее в контекст запроса. Расхождение схемы базы и кода иначе выглядит как пропавшие строки.
+func reportScanFailure(ctx context.Context, logger *zap.Logger, rows pgx.Rows, entity string, err error) {
+	failure := ScanFailure{Entity: entity, ID: scanRowID(rows), Error: err.Error()}
+
+	metrics.RowScanFailures.WithLabelValues(entity).Inc()
+	logger.Error("failed to scan "+entity, zap.Error(err), zap.String("row_id", failure.ID))
+
+	AddScanFailure(ctx, failure)
+}
+
+// AddScanFailure добавляет пропущенную строку в контекст запроса, если он их собирает
+func AddScanFailure(ctx context.Context, failure ScanFailure) {
+	if collected, ok := ctx.Value(scanFailuresKey{}).(*scanFailures); ok {
+		collected.mu.Lock()
+		collected.failures = append(collected.failures, failure)
+		collected.mu.Unlock()
+	}
+}
+
+// scanRowID читает первую колонку строки, если это идентификатор (id или *_id).
+// Другие колонки могут содержать персональные данные и в отчет не попадают.
+func scanRowID(rows pgx.Rows) string {
+	fields := rows.FieldDescriptions()
+	if len(fields) == 0 || (fields[0].Name != "id" && !strings.HasSuffix(fields[0].Name, "_id")) {
+		return ""
+	}
+
+	var id string
+	dest := make([]any, len(fields))
+	dest[0] = &id
+	if err := rows.Scan(dest...); err != nil {
+		return ""
+	}
+	return id
+}
@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// Score оценка проверки, полученная от скорингового движка
+type Score struct {
+	VerificationID string
+	RiskLevel      model.RiskLevel
+	// RulesetID версия правил скоринга, nil - движок ее не сообщил
+	RulesetID *string
+	// RecalculationID пересчет, в рамках которого получена оценка, nil - первичная оценка
+	RecalculationID *string
+}
+
+// RescoreCandidate завершенная проверка, оценку которой нужно пересчитать
+type RescoreCandidate struct {
+	ID        string
+	INN       string
+	CreatedAt time.Time
+}
+
+// ScoreRecalculationState незавершенный пересчет с фильтром и позицией последней отправленной проверки
+type ScoreRecalculationState struct {
+	ID     string
+	Filter VerificationFilter
+	// Cursor последняя отправленная проверка, nil - пересчет еще не начат
+	Cursor *RescoreCandidate
+}
+
+type ScoreRepository interface {
+	RecordScore(ctx context.Context, score *Score) error
+	GetHistory(ctx context.Context, verificationID string) ([]*model.VerificationScore, error)
+	CreateRecalculation(ctx context.Context, filter VerificationFilter, requestedBy string) (*model.ScoreRecalculation, error)
+	GetRecalculation(ctx context.Context, id string) (*model.ScoreRecalculation, error)
+	GetActiveRecalculations(ctx context.Context) ([]*ScoreRecalculationState, error)
+	GetRescoreCandidates(ctx context.Context, filter VerificationFilter, after *RescoreCandidate, limit int) ([]*RescoreCandidate, error)
+	AdvanceRecalculation(ctx context.Context, id string, last *RescoreCandidate, published int, done bool) error
+}
+
+type scoreRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewScoreRepository(db *pgxpool.Pool, logger *zap.Logger) ScoreRepository {
+	return &scoreRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// RecordScore делает оценку текущей и добавляет ее в историю. Оценка, полученная до появления
+// истории, сначала переносится в историю, чтобы не потеряться при замене.
+func (r *scoreRepository) RecordScore(ctx context.Context, score *Score) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO verification_scores (verification_id, risk_level, ruleset_id, scored_at)
+			SELECT id, risk_level, risk_ruleset_id, updated_at
+			FROM verifications
+			WHERE id = $1 AND risk_level IS NOT NULL
+				AND NOT EXISTS (SELECT 1 FROM verification_scores WHERE verification_id = $1)
+		`, score.VerificationID)
+		if err != nil {
+			return err
+		}
+
+		// Пересчет не меняет updated_at, иначе исказится время обработки проверки в статистике
+		tag, err := tx.Exec(ctx, `
+			UPDATE verifications
+			SET risk_level = $2, risk_ruleset_id = $3, updated_at = CASE WHEN $4::uuid IS NULL THEN NOW() ELSE updated_at END
+			WHERE id = $1
+		`, score.VerificationID, string(score.RiskLevel), score.RulesetID, score.RecalculationID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return notFoundf("verification not found: %s", score.VerificationID)
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO verification_scores (verification_id, risk_level, ruleset_id, recalculation_id)
+			VALUES ($1, $2, $3, $4)
+		`, score.VerificationID, string(score.RiskLevel), score.RulesetID, score.RecalculationID)
+		if err != nil {
+			return err
+		}
+
+		if score.RecalculationID != nil {
+			_, err = tx.Exec(ctx, `UPDATE score_recalculations SET rescored = rescored + 1, updated_at = NOW() WHERE id = $1`, *score.RecalculationID)
+		}
+		return err
+	})
+	if err != nil {
+		r.logger.Error("failed to record score", zap.Error(err), zap.String("verification_id", score.VerificationID))
+		return fmt.Errorf("failed to record score: %w", classify(err))
+	}
+
+	return nil
+}
+
+// GetHistory возвращает оценки проверки от новых к старым
+func (r *scoreRepository) GetHistory(ctx context.Context, verificationID string) ([]*model.VerificationScore, error) {
+	query := `
+		SELECT risk_level, ruleset_id, recalculation_id, scored_at
+		FROM verification_scores
+		WHERE verification_id = $1
+		ORDER BY scored_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, verificationID)
+	if err != nil {
+		r.logger.Error("failed to get score history", zap.Error(err), zap.String("verification_id", verificationID))
+		return nil, fmt.Errorf("failed to get score history: %w", classify(err))
+	}
+	defer rows.Close()
+
+	history := []*model.VerificationScore{}
+	for rows.Next() {
+		var score model.VerificationScore
+		var scoredAt time.Time
+		if err := rows.Scan(&score.RiskLevel, &score.RulesetID, &score.RecalculationID, &scoredAt); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "score", err)
+			continue
+		}
+		score.ScoredAt = scoredAt.Format(time.RFC3339)
+		history = append(history, &score)
+	}
+
+	return history, nil
+}
+
+// rescoreSelect запрос по завершенным проверкам, подходящим под фильтр пересчета.
+// Проверки песочницы не пересчитываются: их оценка синтетическая.
+func rescoreSelect(columns string, filter VerificationFilter) *selectBuilder {
+	builder := newSelect(`
+		SELECT ` + columns + `
+		FROM verifications
+		LEFT JOIN verification_external_refs ON verification_id = id`)
+	builder.Where("status IN ('COMPLETED', 'PARTIALLY_COMPLETED')")
+	builder.Where("NOT sandbox")
+	return filter.apply(builder)
+}
+
+// CreateRecalculation сохраняет пересчет вместе с количеством подходящих под фильтр проверок
+func (r *scoreRepository) CreateRecalculation(ctx context.Context, filter VerificationFilter, requestedBy string) (*model.ScoreRecalculation, error) {
+	filterJSON, err := json.Marshal(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal recalculation filter: %w", err)
+	}
+
+	countQuery, args := rescoreSelect("COUNT(*)", filter).Build()
+	var total int32
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		r.logger.Error("failed to count verifications to rescore", zap.Error(err))
+		return nil, fmt.Errorf("failed to count verifications to rescore: %w", classify(err))
+	}
+
+	query := `
+		INSERT INTO score_recalculations (filter, requested_by, total, status, completed_at)
+		VALUES ($1, $2, $3, CASE WHEN $3 = 0 THEN 'COMPLETED' ELSE 'PENDING' END, CASE WHEN $3 = 0 THEN NOW() END)
+		RETURNING ` + recalculationColumns
+
+	recalculation, err := scanRecalculation(r.db.QueryRow(ctx, query, filterJSON, requestedBy, total))
+	if err != nil {
+		r.logger.Error("failed to create score recalculation", zap.Error(err))
+		return nil, fmt.Errorf("failed to create score recalculation: %w", classify(err))
+	}
+
+	return recalculation, nil
+}
+
+const recalculationColumns = `id, status, requested_by, total, published, rescored, created_at, completed_at`
+
+func scanRecalculation(row pgx.Row) (*model.ScoreRecalculation, error) {
+	var recalculation model.ScoreRecalculation
+	var createdAt time.Time
+	var completedAt *time.Time
+	err := row.Scan(&recalculation.ID, &recalculation.Status, &recalculation.RequestedBy, &recalculation.Total,
+		&recalculation.Published, &recalculation.Rescored, &createdAt, &completedAt)
+	if err != nil {
+		return nil, err
+	}
+	recalculation.CreatedAt = createdAt.Format(time.RFC3339)
+	if completedAt != nil {
+		formatted := completedAt.Format(time.RFC3339)
+		recalculation.CompletedAt = &formatted
+	}
+	return &recalculation, nil
+}
+
+func (r *scoreRepository) GetRecalculation(ctx context.Context, id string) (*model.ScoreRecalculation, error) {
+	query := `SELECT ` + recalculationColumns + ` FROM score_recalculations WHERE id = $1`
+
+	recalculation, err := scanRecalculation(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, notFoundf("score recalculation not found: %s", id)
+		}
+		r.logger.Error("failed to get score recalculation", zap.Error(err), zap.String("id", id))
+		return nil, fmt.Errorf("failed to get score recalculation: %w", classify(err))
+	}
+
+	return recalculation, nil
+}
+
+// GetActiveRecalculations возвращает незавершенные пересчеты в порядке создания
+func (r *scoreRepository) GetActiveRecalculations(ctx context.Context) ([]*ScoreRecalculationState, error) {
+	query := `
+		SELECT id, filter, cursor_id, cursor_created_at
+		FROM score_recalculations
+		WHERE status <> 'COMPLETED'
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		r.logger.Error("failed to get active score recalculations", zap.Error(err))
+		return nil, fmt.Errorf("failed to get active score recalculations: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var states []*ScoreRecalculationState
+	for rows.Next() {
+		var state ScoreRecalculationState
+		var filter []byte
+		var cursorID *string
+		var cursorCreatedAt *time.Time
+		if err := rows.Scan(&state.ID, &filter, &cursorID, &cursorCreatedAt); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "score recalculation", err)
+			continue
+		}
+		if err := json.Unmarshal(filter, &state.Filter); err != nil {
+			r.logger.Error("failed to unmarshal recalculation filter", zap.Error(err), zap.String("id", state.ID))
+			continue
+		}
+		if cursorID != nil && cursorCreatedAt != nil {
+			state.Cursor = &RescoreCandidate{ID: *cursorID, CreatedAt: *cursorCreatedAt}
+		}
+		states = append(states, &state)
+	}
+
+	return states, nil
+}
+
+// GetRescoreCandidates возвращает следующие после after проверки в порядке создания
+func (r *scoreRepository) GetRescoreCandidates(ctx context.Context, filter VerificationFilter, after *RescoreCandidate, limit int) ([]*RescoreCandidate, error) {
+	builder := rescoreSelect("id, inn, created_at", filter)
+	if after != nil {
+		builder.Where("(created_at, id) > (?, ?)", after.CreatedAt, after.ID)
+	}
+	pageSize := int32(limit)
+	query, args := builder.OrderBy("created_at, id").Limit(&pageSize).Build()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("failed to get verifications to rescore", zap.Error(err))
+		return nil, fmt.Errorf("failed to get verifications to rescore: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var candidates []*RescoreCandidate
+	for rows.Next() {
+		var c RescoreCandidate
+		if err := rows.Scan(&c.ID, &c.INN, &c.CreatedAt); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "verification to rescore", err)
+			continue
+		}
+		candidates = append(candidates, &c)
+	}
+
+	return candidates, nil
+}
+
+// AdvanceRecalculation сдвигает позицию пересчета на последнюю отправленную проверку.
+// done завершает пересчет: все подходящие проверки отправлены.
+func (r *scoreRepository) AdvanceRecalculation(ctx context.Context, id string, last *RescoreCandidate, published int, done bool) error {
+	var cursorID *string
+	var cursorCreatedAt *time.Time
+	if last != nil {
+		cursorID, cursorCreatedAt = &last.ID, &last.CreatedAt
+	}
+
+	query := `
+		UPDATE score_recalculations
+		SET published = published + $2,
+			cursor_id = COALESCE($3, cursor_id),
+			cursor_created_at = COALESCE($4, cursor_created_at),
+			status = CASE WHEN $5 THEN 'COMPLETED' ELSE 'RUNNING' END,
+			completed_at = CASE WHEN $5 THEN NOW() END,
+			updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Exec(ctx, query, id, published, cursorID, cursorCreatedAt, done); err != nil {
+		r.logger.Error("failed to advance score recalculation", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to advance score recalculation: %w", classify(err))
+	}
+
+	return nil
+}
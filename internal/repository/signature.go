@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/signing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type SignatureRepository interface {
+	GetSummary(ctx context.Context, verificationID string) (*signing.VerificationSummary, error)
+	SaveSignature(ctx context.Context, verificationID string, signature *model.VerificationSignature) error
+	GetLatestSignature(ctx context.Context, verificationID string) (*model.VerificationSignature, error)
+}
+
+type signatureRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewSignatureRepository(db *pgxpool.Pool, logger *zap.Logger) SignatureRepository {
+	return &signatureRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetSummary собирает итоги проверки для подписи. Время завершения - последнее изменение проверки,
+// хэши данных берутся из verification_data, куда их записывает воркер.
+func (r *signatureRepository) GetSummary(ctx context.Context, verificationID string) (*signing.VerificationSummary, error) {
+	summary := &signing.VerificationSummary{
+		VerificationID: verificationID,
+		PayloadHashes:  make(map[string]string),
+	}
+
+	err := r.db.QueryRow(ctx, `SELECT status, updated_at FROM verifications WHERE id = $1`, verificationID).
+		Scan(&summary.Status, &summary.CompletedAt)
+	if err != nil {
+		r.logger.Error("failed to get verification for signing", zap.Error(err), zap.String("verification_id", verificationID))
+		return nil, fmt.Errorf("failed to get verification for signing: %w", classify(err))
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT data_type, data_hash
+		FROM verification_data
+		WHERE verification_id = $1 AND data_hash IS NOT NULL
+	`, verificationID)
+	if err != nil {
+		r.logger.Error("failed to get payload hashes", zap.Error(err), zap.String("verification_id", verificationID))
+		return nil, fmt.Errorf("failed to get payload hashes: %w", classify(err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dataType, hash string
+		if err := rows.Scan(&dataType, &hash); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "payload hash", err)
+			continue
+		}
+		summary.PayloadHashes[dataType] = hash
+	}
+
+	return summary, rows.Err()
+}
+
+func (r *signatureRepository) SaveSignature(ctx context.Context, verificationID string, signature *model.VerificationSignature) error {
+	query := `
+		INSERT INTO verification_signatures (verification_id, algorithm, key_id, content, digest, signature)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING signed_at
+	`
+
+	var signedAt time.Time
+	err := r.db.QueryRow(ctx, query, verificationID, signature.Algorithm, signature.KeyID, signature.Content, signature.Digest, signature.Signature).Scan(&signedAt)
+	if err != nil {
+		r.logger.Error("failed to save signature", zap.Error(err), zap.String("verification_id", verificationID))
+		return fmt.Errorf("failed to save signature: %w", classify(err))
+	}
+
+	signature.SignedAt = signedAt.Format(time.RFC3339)
+	return nil
+}
+
+// GetLatestSignature возвращает последнюю подпись проверки, nil - проверка не подписана
+func (r *signatureRepository) GetLatestSignature(ctx context.Context, verificationID string) (*model.VerificationSignature, error) {
+	query := `
+		SELECT algorithm, key_id, content, digest, signature, signed_at
+		FROM verification_signatures
+		WHERE verification_id = $1
+		ORDER BY signed_at DESC
+		LIMIT 1
+	`
+
+	var signature model.VerificationSignature
+	var signedAt time.Time
+	err := r.db.QueryRow(ctx, query, verificationID).Scan(&signature.Algorithm, &signature.KeyID, &signature.Content, &signature.Digest, &signature.Signature, &signedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		r.logger.Error("failed to get signature", zap.Error(err), zap.String("verification_id", verificationID))
+		return nil, fmt.Errorf("failed to get signature: %w", classify(err))
+	}
+
+	signature.SignedAt = signedAt.Format(time.RFC3339)
+	return &signature, nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// CompletionSimulationRepository сохраняет завершения, внесенные администратором вместо воркера
+type CompletionSimulationRepository interface {
+	// Complete сохраняет данные и итоговый статус незавершенной проверки. Завершенная проверка и уже
+	// доставленный тип данных возвращают ошибку вида ErrConflict.
+	Complete(ctx context.Context, id string, status domain.Status, data []*domain.Data, completedAt time.Time) error
+}
+
+type completionSimulationRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewCompletionSimulationRepository(db *pgxpool.Pool, logger *zap.Logger) CompletionSimulationRepository {
+	return &completionSimulationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// completableStatuses статусы проверок, которые еще не завершил воркер: запрос в очереди, удержан
+// до подтверждения email или обрабатывается
+var completableStatuses = []domain.Status{
+	domain.StatusPending,
+	domain.StatusPendingEmailConfirmation,
+	domain.StatusInProcess,
+	domain.StatusProcessing,
+}
+
+// completable сообщает, можно ли завершить проверку в статусе status вместо воркера
+func completable(status domain.Status) bool {
+	return slices.Contains(completableStatuses, status)
+}
+
+// Complete пишет данные, пока проверка не завершена, и только затем меняет статус, как воркер
+// и ReplayRepository: иначе триггер принял бы данные за поправки. Еще не отправленный запрос
+// проверки удаляется из очереди, чтобы воркер не завершил ее повторно.
+func (r *completionSimulationRepository) Complete(ctx context.Context, id string, status domain.Status, data []*domain.Data, completedAt time.Time) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var (
+			current string
+			sandbox bool
+		)
+		err := tx.QueryRow(ctx, `SELECT status, sandbox FROM verifications WHERE id = $1 FOR UPDATE`, id).Scan(&current, &sandbox)
+		if err == pgx.ErrNoRows {
+			return notFoundf("verification %s not found", id)
+		}
+		if err != nil {
+			return err
+		}
+		if sandbox {
+			return conflictf("sandbox verification %s cannot be completed manually", id)
+		}
+		if !completable(domain.Status(current)) {
+			return conflictf("verification %s is already %s", id, current)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM outbox_messages WHERE id = $1 AND published_at IS NULL`, id); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM email_confirmation_holds WHERE id = $1`, id); err != nil {
+			return err
+		}
+
+		if err := insertVerificationData(ctx, tx, id, data); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `UPDATE verifications SET status = $2, updated_at = $3 WHERE id = $1`, id, string(status), completedAt)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("failed to save simulated completion", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to save simulated completion: %w", classify(err))
+	}
+
+	return nil
+}
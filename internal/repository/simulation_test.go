@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"testing"
+
+	"scoring_api_gateway/internal/domain"
+)
+
+func TestCompletable(t *testing.T) {
+	for _, status := range domain.AllStatuses {
+		t.Run(string(status), func(t *testing.T) {
+			expected := status == domain.StatusPending || status == domain.StatusPendingEmailConfirmation ||
+				status == domain.StatusInProcess || status == domain.StatusProcessing
+			if got := completable(status); got != expected {
+				t.Errorf("expected completable %v, but got %v", expected, got)
+			}
+		})
+	}
+}
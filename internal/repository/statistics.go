@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// DataTypeSpend число запросов данных одного типа
+type DataTypeSpend struct {
+	DataType string
+	Count    int64
+}
+
+// OrganizationSpend проверки организации вне песочницы за период и запрошенные в них типы данных
+type OrganizationSpend struct {
+	Organization  string
+	Verifications int64
+	DataTypes     []*DataTypeSpend
+}
+
+type StatisticsRepository interface {
+	// GetSpend возвращает объем проверок организаций organizations, созданных в [from, to)
+	GetSpend(ctx context.Context, from, to time.Time, organizations []string) ([]*OrganizationSpend, error)
+	GetDaily(ctx context.Context, from, to time.Time, organizations []string, timeZone string) ([]*model.DailyVerificationStats, error)
+	Refresh(ctx context.Context) error
+}
+
+type statisticsRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewStatisticsRepository(db *pgxpool.Pool, logger *zap.Logger) StatisticsRepository {
+	return &statisticsRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetDaily возвращает статистику за дни [from, to] по организациям organizations, nil - по всем. Для UTC завершенные дни читаются из материализованного
+// представления, текущий день считается по таблице, так как представление обновляется периодически.
+// Для других часовых поясов дни группируются по таблице: границы периода переводятся в моменты
+// времени в SQL, поэтому используется индекс по created_at, а переходы на летнее время учитываются.
+func (r *statisticsRepository) GetDaily(ctx context.Context, from, to time.Time, organizations []string, timeZone string) ([]*model.DailyVerificationStats, error) {
+	query := `
+		SELECT day, status, organization, total, avg_completion_seconds
+		FROM verification_daily_stats
+		WHERE day BETWEEN $1 AND $2 AND day < (NOW() AT TIME ZONE 'UTC')::date
+			AND ($3::text[] IS NULL OR organization = ANY($3))
+		UNION ALL
+		SELECT (created_at AT TIME ZONE 'UTC')::date, status, split_part(author_email, '@', 2), COUNT(*),
+			AVG(EXTRACT(EPOCH FROM updated_at - created_at)) FILTER (WHERE status = 'COMPLETED')
+		FROM verifications
+		WHERE created_at >= (NOW() AT TIME ZONE 'UTC')::date AT TIME ZONE 'UTC'
+			AND (NOW() AT TIME ZONE 'UTC')::date BETWEEN $1 AND $2
+			AND ($3::text[] IS NULL OR split_part(author_email, '@', 2) = ANY($3))
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3
+	`
+	args := []any{from, to, organizations}
+
+	if timeZone != "" && timeZone != "UTC" {
+		query = `
+			SELECT (created_at AT TIME ZONE $4)::date, status, split_part(author_email, '@', 2), COUNT(*),
+				AVG(EXTRACT(EPOCH FROM updated_at - created_at)) FILTER (WHERE status = 'COMPLETED')
+			FROM verifications
+			WHERE created_at >= $1::date::timestamp AT TIME ZONE $4
+				AND created_at < ($2::date + 1)::timestamp AT TIME ZONE $4
+				AND ($3::text[] IS NULL OR split_part(author_email, '@', 2) = ANY($3))
+			GROUP BY 1, 2, 3
+			ORDER BY 1, 2, 3
+		`
+		args = append(args, timeZone)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("failed to get daily statistics", zap.Error(err))
+		return nil, fmt.Errorf("failed to get daily statistics: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var stats []*model.DailyVerificationStats
+	for rows.Next() {
+		var s model.DailyVerificationStats
+		var day time.Time
+		var total int64
+		if err := rows.Scan(&day, &s.Status, &s.Organization, &total, &s.AvgCompletionSeconds); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "daily statistics", err)
+			continue
+		}
+		s.Day = day.Format(time.DateOnly)
+		s.Count = int32(total)
+		stats = append(stats, &s)
+	}
+
+	return stats, nil
+}
+
+func (r *statisticsRepository) GetSpend(ctx context.Context, from, to time.Time, organizations []string) ([]*OrganizationSpend, error) {
+	query := `
+		WITH billed AS (
+			SELECT split_part(author_email, '@', 2) AS organization, requested_data_types
+			FROM verifications
+			WHERE created_at >= $1 AND created_at < $2 AND NOT sandbox
+				AND split_part(author_email, '@', 2) = ANY($3)
+		)
+		SELECT organization, NULL, COUNT(*) FROM billed GROUP BY 1
+		UNION ALL
+		SELECT organization, data_type, COUNT(*) FROM billed, unnest(requested_data_types) AS data_type GROUP BY 1, 2
+		ORDER BY 1, 2 NULLS FIRST
+	`
+
+	rows, err := r.db.Query(ctx, query, from, to, organizations)
+	if err != nil {
+		r.logger.Error("failed to get spend", zap.Error(err))
+		return nil, fmt.Errorf("failed to get spend: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var spend []*OrganizationSpend
+	for rows.Next() {
+		var organization string
+		var dataType *string
+		var count int64
+		if err := rows.Scan(&organization, &dataType, &count); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "spend", err)
+			continue
+		}
+		// Строка организации без типа данных идет перед строками ее типов
+		if dataType == nil {
+			spend = append(spend, &OrganizationSpend{Organization: organization, Verifications: count})
+			continue
+		}
+		if len(spend) > 0 && spend[len(spend)-1].Organization == organization {
+			last := spend[len(spend)-1]
+			last.DataTypes = append(last.DataTypes, &DataTypeSpend{DataType: *dataType, Count: count})
+		}
+	}
+
+	return spend, nil
+}
+
+// Refresh пересчитывает материализованное представление без блокировки чтения
+func (r *statisticsRepository) Refresh(ctx context.Context) error {
+	if _, err := r.db.Exec(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY verification_daily_stats`); err != nil {
+		r.logger.Error("failed to refresh daily statistics", zap.Error(err))
+		return fmt.Errorf("failed to refresh daily statistics: %w", classify(err))
+	}
+	return nil
+}
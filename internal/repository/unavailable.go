@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+// UnavailableData данные проверки, которые не удалось прочитать: у записи нет хэша, в кэше нет
+// записи с этим хэшем или сохраненные данные повреждены. Остальные типы данных возвращаются.
+type UnavailableData struct {
+	VerificationID string
+	DataType       model.VerificationDataType
+	Hash           string
+	Err            error
+}
+
+type unavailableDataKey struct{}
+
+type unavailableData struct {
+	mu   sync.Mutex
+	data []UnavailableData
+}
+
+// WithUnavailableData возвращает контекст, в котором репозитории собирают непрочитанные данные
+func WithUnavailableData(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unavailableDataKey{}, &unavailableData{})
+}
+
+// UnavailableDataFromContext возвращает данные, которые не удалось прочитать в запросах с этим контекстом
+func UnavailableDataFromContext(ctx context.Context) []UnavailableData {
+	collected, ok := ctx.Value(unavailableDataKey{}).(*unavailableData)
+	if !ok {
+		return nil
+	}
+	collected.mu.Lock()
+	defer collected.mu.Unlock()
+	return append([]UnavailableData(nil), collected.data...)
+}
+
+// reportUnavailableData записывает в журнал хэш непрочитанных данных, чтобы запись кэша можно
+// было найти и исправить, и добавляет данные в контекст запроса
+func reportUnavailableData(ctx context.Context, logger *zap.Logger, unavailable UnavailableData) {
+	metrics.UnavailableVerificationData.WithLabelValues(string(unavailable.DataType)).Inc()
+	logger.Error("verification data is unavailable",
+		zap.Error(unavailable.Err),
+		zap.String("verification_id", unavailable.VerificationID),
+		zap.String("data_type", string(unavailable.DataType)),
+		zap.String("hash", unavailable.Hash))
+
+	AddUnavailableData(ctx, unavailable)
+}
+
+// AddUnavailableData добавляет непрочитанные данные в контекст запроса, если он их собирает
+func AddUnavailableData(ctx context.Context, unavailable UnavailableData) {
+	if collected, ok := ctx.Value(unavailableDataKey{}).(*unavailableData); ok {
+		collected.mu.Lock()
+		collected.data = append(collected.data, unavailable)
+		collected.mu.Unlock()
+	}
+}
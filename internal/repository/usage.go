@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// Виды клиентов в учете использования API
+const (
+	ClientKindUser   = "USER"
+	ClientKindAPIKey = "API_KEY"
+)
+
+// UsageRecord счетчики одной операции клиента за час
+type UsageRecord struct {
+	ClientKind string
+	// ClientID идентификатор ключа API для сервисных аккаунтов и email для пользователей
+	ClientID      string
+	Email         string
+	Hour          time.Time
+	Operation     string
+	Requests      int64
+	Errors        int64
+	ResponseBytes int64
+}
+
+// OperationUsage использование одной операции за период
+type OperationUsage struct {
+	Operation string
+	Requests  int64
+	Errors    int64
+}
+
+// ClientUsage использование API клиентом за период и его самые частые операции
+type ClientUsage struct {
+	ClientKind    string
+	ClientID      string
+	Email         string
+	Requests      int64
+	Errors        int64
+	ResponseBytes int64
+	TopOperations []*OperationUsage
+}
+
+type UsageRepository interface {
+	AddUsage(ctx context.Context, records []*UsageRecord) error
+	// GetClientUsage возвращает использование клиента с since; без запросов - нулевые счетчики
+	GetClientUsage(ctx context.Context, clientKind, clientID string, since time.Time, topOperations int) (*ClientUsage, error)
+	// ListClientUsage возвращает клиентов с since по убыванию числа запросов
+	ListClientUsage(ctx context.Context, since time.Time, limit, topOperations int) ([]*ClientUsage, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type usageRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewUsageRepository(db *pgxpool.Pool, logger *zap.Logger) UsageRepository {
+	return &usageRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// AddUsage прибавляет счетчики к часовым строкам клиентов
+func (r *usageRepository) AddUsage(ctx context.Context, records []*UsageRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO api_usage (client_kind, client_id, email, hour, operation, requests, errors, response_bytes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (client_kind, client_id, hour, operation) DO UPDATE SET
+			email = EXCLUDED.email,
+			requests = api_usage.requests + EXCLUDED.requests,
+			errors = api_usage.errors + EXCLUDED.errors,
+			response_bytes = api_usage.response_bytes + EXCLUDED.response_bytes
+	`
+
+	batch := &pgx.Batch{}
+	for _, record := range records {
+		batch.Queue(query, record.ClientKind, record.ClientID, record.Email, record.Hour,
+			record.Operation, record.Requests, record.Errors, record.ResponseBytes)
+	}
+
+	if err := r.db.SendBatch(ctx, batch).Close(); err != nil {
+		r.logger.Error("failed to add api usage", zap.Error(err), zap.Int("records", len(records)))
+		return fmt.Errorf("failed to add api usage: %w", classify(err))
+	}
+
+	return nil
+}
+
+func (r *usageRepository) GetClientUsage(ctx context.Context, clientKind, clientID string, since time.Time, topOperations int) (*ClientUsage, error) {
+	usage, err := r.listUsage(ctx, since, clientKind, clientID, 1, topOperations)
+	if err != nil {
+		return nil, err
+	}
+	if len(usage) == 0 {
+		return &ClientUsage{ClientKind: clientKind, ClientID: clientID}, nil
+	}
+	return usage[0], nil
+}
+
+func (r *usageRepository) ListClientUsage(ctx context.Context, since time.Time, limit, topOperations int) ([]*ClientUsage, error) {
+	return r.listUsage(ctx, since, "", "", limit, topOperations)
+}
+
+// listUsage суммирует часовые строки по клиентам; пустой clientKind - все клиенты
+func (r *usageRepository) listUsage(ctx context.Context, since time.Time, clientKind, clientID string, limit, topOperations int) ([]*ClientUsage, error) {
+	query := `
+		SELECT client_kind, client_id, MAX(email), SUM(requests), SUM(errors), SUM(response_bytes)
+		FROM api_usage
+		WHERE hour >= $1 AND ($2 = '' OR (client_kind = $2 AND client_id = $3))
+		GROUP BY client_kind, client_id
+		ORDER BY SUM(requests) DESC, client_kind, client_id
+		LIMIT $4
+	`
+
+	rows, err := r.db.Query(ctx, query, since, clientKind, clientID, limit)
+	if err != nil {
+		r.logger.Error("failed to get api usage", zap.Error(err))
+		return nil, fmt.Errorf("failed to get api usage: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var usage []*ClientUsage
+	byClient := make(map[[2]string]*ClientUsage)
+	var kinds, ids []string
+	for rows.Next() {
+		var u ClientUsage
+		if err := rows.Scan(&u.ClientKind, &u.ClientID, &u.Email, &u.Requests, &u.Errors, &u.ResponseBytes); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "api usage", err)
+			continue
+		}
+		usage = append(usage, &u)
+		byClient[[2]string{u.ClientKind, u.ClientID}] = &u
+		kinds = append(kinds, u.ClientKind)
+		ids = append(ids, u.ClientID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get api usage: %w", classify(err))
+	}
+	if len(usage) == 0 || topOperations <= 0 {
+		return usage, nil
+	}
+
+	operationsQuery := `
+		SELECT client_kind, client_id, operation, requests, errors
+		FROM (
+			SELECT u.client_kind, u.client_id, u.operation, SUM(u.requests) AS requests, SUM(u.errors) AS errors,
+				ROW_NUMBER() OVER (PARTITION BY u.client_kind, u.client_id ORDER BY SUM(u.requests) DESC, u.operation) AS rank
+			FROM api_usage u
+			JOIN unnest($2::text[], $3::text[]) AS c(client_kind, client_id)
+				ON c.client_kind = u.client_kind AND c.client_id = u.client_id
+			WHERE u.hour >= $1
+			GROUP BY u.client_kind, u.client_id, u.operation
+		) ranked
+		WHERE rank <= $4
+		ORDER BY client_kind, client_id, rank
+	`
+
+	operationRows, err := r.db.Query(ctx, operationsQuery, since, kinds, ids, topOperations)
+	if err != nil {
+		r.logger.Error("failed to get api usage operations", zap.Error(err))
+		return nil, fmt.Errorf("failed to get api usage operations: %w", classify(err))
+	}
+	defer operationRows.Close()
+
+	for operationRows.Next() {
+		var kind, id string
+		var op OperationUsage
+		if err := operationRows.Scan(&kind, &id, &op.Operation, &op.Requests, &op.Errors); err != nil {
+			reportScanFailure(ctx, r.logger, operationRows, "api usage operation", err)
+			continue
+		}
+		if client, ok := byClient[[2]string{kind, id}]; ok {
+			client.TopOperations = append(client.TopOperations, &op)
+		}
+	}
+
+	return usage, nil
+}
+
+// DeleteBefore удаляет часовые строки старше before
+func (r *usageRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM api_usage WHERE hour < $1`, before)
+	if err != nil {
+		r.logger.Error("failed to delete api usage", zap.Error(err))
+		return 0, fmt.Errorf("failed to delete api usage: %w", classify(err))
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// Membership учетная запись пользователя и его роли в организации
+type Membership struct {
+	UserID         string
+	Email          string
+	Status         model.UserStatus
+	OrganizationID string
+	// Roles роли в том виде, в котором они попадают в auth.Principal
+	Roles []string
+}
+
+type UserRepository interface {
+	GetMembership(ctx context.Context, email string) (*Membership, error)
+	Invite(ctx context.Context, organizationID, email string, name *string, roles []string, invitedBy string) (*model.OrganizationMember, error)
+	SetRoles(ctx context.Context, organizationID, email string, roles []string) (*model.OrganizationMember, error)
+	Deactivate(ctx context.Context, organizationID, email string) (*model.OrganizationMember, error)
+	MarkSeen(ctx context.Context, userID string) error
+	ListMembers(ctx context.Context, organizationID string) ([]*model.OrganizationMember, error)
+	// ListMemberships возвращает учетные записи всех организаций, в том числе отключенные
+	ListMemberships(ctx context.Context) ([]*Membership, error)
+}
+
+type userRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewUserRepository(db *pgxpool.Pool, logger *zap.Logger) UserRepository {
+	return &userRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// memberSelect участники организаций со сводкой активности за 30 дней
+const memberSelect = `
+	SELECT u.email, u.name, m.organization_id, u.status, m.roles, u.invited_by, u.created_at, u.last_seen_at,
+		COALESCE(a.recent, 0), a.last_created_at
+	FROM users u
+	JOIN memberships m ON m.user_id = u.id
+	LEFT JOIN LATERAL (
+		SELECT COUNT(*) FILTER (WHERE v.created_at >= NOW() - INTERVAL '30 days') AS recent, MAX(v.created_at) AS last_created_at
+		FROM verifications v
+		WHERE v.author_email = u.email
+	) a ON true
+`
+
+func scanMember(row pgx.Row) (*model.OrganizationMember, error) {
+	var member model.OrganizationMember
+	var status string
+	var roles []string
+	var createdAt time.Time
+	var lastSeenAt, lastVerificationAt *time.Time
+	err := row.Scan(&member.Email, &member.Name, &member.Organization, &status, &roles, &member.InvitedBy, &createdAt, &lastSeenAt,
+		&member.VerificationsLast30Days, &lastVerificationAt)
+	if err != nil {
+		return nil, err
+	}
+
+	member.Status = model.UserStatus(status)
+	member.Roles = []model.OrganizationRole{}
+	for _, role := range roles {
+		member.Roles = append(member.Roles, model.OrganizationRole(strings.ToUpper(role)))
+	}
+	member.CreatedAt = createdAt.Format(time.RFC3339)
+	if lastSeenAt != nil {
+		formatted := lastSeenAt.Format(time.RFC3339)
+		member.LastSeenAt = &formatted
+	}
+	if lastVerificationAt != nil {
+		formatted := lastVerificationAt.Format(time.RFC3339)
+		member.LastVerificationAt = &formatted
+	}
+	return &member, nil
+}
+
+// GetMembership находит пользователя по email. Пользователь без учетной записи - ErrNotFound.
+func (r *userRepository) GetMembership(ctx context.Context, email string) (*Membership, error) {
+	query := `
+		SELECT u.id, u.email, u.status, m.organization_id, m.roles
+		FROM users u
+		JOIN memberships m ON m.user_id = u.id
+		WHERE u.email = $1
+		ORDER BY m.created_at
+		LIMIT 1
+	`
+
+	var membership Membership
+	var status string
+	err := r.db.QueryRow(ctx, query, email).Scan(&membership.UserID, &membership.Email, &status, &membership.OrganizationID, &membership.Roles)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, notFoundf("user not found: %s", email)
+		}
+		r.logger.Error("failed to get membership", zap.Error(err))
+		return nil, fmt.Errorf("failed to get membership: %w", classify(err))
+	}
+
+	membership.Status = model.UserStatus(status)
+	return &membership, nil
+}
+
+// Invite создает пользователя и его членство в организации. Организация создается, если ее еще нет.
+// Уже зарегистрированный пользователь - ErrConflict.
+func (r *userRepository) Invite(ctx context.Context, organizationID, email string, name *string, roles []string, invitedBy string) (*model.OrganizationMember, error) {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `INSERT INTO organizations (id) VALUES ($1) ON CONFLICT (id) DO NOTHING`, organizationID)
+		if err != nil {
+			return err
+		}
+
+		var userID string
+		err = tx.QueryRow(ctx, `
+			INSERT INTO users (email, name, invited_by) VALUES ($1, $2, $3) RETURNING id
+		`, email, name, invitedBy).Scan(&userID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `INSERT INTO memberships (user_id, organization_id, roles) VALUES ($1, $2, $3)`, userID, organizationID, roles)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("failed to invite user", zap.Error(err), zap.String("organization_id", organizationID))
+		return nil, fmt.Errorf("failed to invite user: %w", classify(err))
+	}
+
+	return r.getMember(ctx, organizationID, email)
+}
+
+func (r *userRepository) SetRoles(ctx context.Context, organizationID, email string, roles []string) (*model.OrganizationMember, error) {
+	query := `
+		UPDATE memberships m
+		SET roles = $3, updated_at = NOW()
+		FROM users u
+		WHERE u.id = m.user_id AND m.organization_id = $1 AND u.email = $2
+	`
+
+	tag, err := r.db.Exec(ctx, query, organizationID, email, roles)
+	if err != nil {
+		r.logger.Error("failed to set user roles", zap.Error(err), zap.String("organization_id", organizationID))
+		return nil, fmt.Errorf("failed to set user roles: %w", classify(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, notFoundf("user not found: %s", email)
+	}
+
+	return r.getMember(ctx, organizationID, email)
+}
+
+func (r *userRepository) Deactivate(ctx context.Context, organizationID, email string) (*model.OrganizationMember, error) {
+	query := `
+		UPDATE users u
+		SET status = 'DEACTIVATED', deactivated_at = COALESCE(u.deactivated_at, NOW()), updated_at = NOW()
+		FROM memberships m
+		WHERE u.id = m.user_id AND m.organization_id = $1 AND u.email = $2
+	`
+
+	tag, err := r.db.Exec(ctx, query, organizationID, email)
+	if err != nil {
+		r.logger.Error("failed to deactivate user", zap.Error(err), zap.String("organization_id", organizationID))
+		return nil, fmt.Errorf("failed to deactivate user: %w", classify(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, notFoundf("user not found: %s", email)
+	}
+
+	return r.getMember(ctx, organizationID, email)
+}
+
+// MarkSeen активирует приглашенного пользователя и обновляет время последнего запроса
+// не чаще раза в минуту, чтобы не писать в базу на каждый запрос
+func (r *userRepository) MarkSeen(ctx context.Context, userID string) error {
+	query := `
+		UPDATE users
+		SET last_seen_at = NOW(),
+			status = CASE WHEN status = 'INVITED' THEN 'ACTIVE' ELSE status END
+		WHERE id = $1 AND status <> 'DEACTIVATED'
+			AND (last_seen_at IS NULL OR last_seen_at < NOW() - INTERVAL '1 minute')
+	`
+
+	if _, err := r.db.Exec(ctx, query, userID); err != nil {
+		r.logger.Error("failed to mark user seen", zap.Error(err), zap.String("user_id", userID))
+		return fmt.Errorf("failed to mark user seen: %w", classify(err))
+	}
+	return nil
+}
+
+func (r *userRepository) ListMembers(ctx context.Context, organizationID string) ([]*model.OrganizationMember, error) {
+	rows, err := r.db.Query(ctx, memberSelect+` WHERE m.organization_id = $1 ORDER BY u.email`, organizationID)
+	if err != nil {
+		r.logger.Error("failed to list organization members", zap.Error(err), zap.String("organization_id", organizationID))
+		return nil, fmt.Errorf("failed to list organization members: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var members []*model.OrganizationMember
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			reportScanFailure(ctx, r.logger, rows, "organization member", err)
+			continue
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+func (r *userRepository) ListMemberships(ctx context.Context) ([]*Membership, error) {
+	query := `
+		SELECT u.id, u.email, u.status, m.organization_id, m.roles
+		FROM users u
+		JOIN memberships m ON m.user_id = u.id
+		ORDER BY u.email, m.organization_id
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		r.logger.Error("failed to list memberships", zap.Error(err))
+		return nil, fmt.Errorf("failed to list memberships: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var memberships []*Membership
+	for rows.Next() {
+		var membership Membership
+		var status string
+		if err := rows.Scan(&membership.UserID, &membership.Email, &status, &membership.OrganizationID, &membership.Roles); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "membership", err)
+			continue
+		}
+		membership.Status = model.UserStatus(status)
+		memberships = append(memberships, &membership)
+	}
+
+	return memberships, rows.Err()
+}
+
+func (r *userRepository) getMember(ctx context.Context, organizationID, email string) (*model.OrganizationMember, error) {
+	member, err := scanMember(r.db.QueryRow(ctx, memberSelect+` WHERE m.organization_id = $1 AND u.email = $2`, organizationID, email))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, notFoundf("user not found: %s", email)
+		}
+		r.logger.Error("failed to get organization member", zap.Error(err), zap.String("organization_id", organizationID))
+		return nil, fmt.Errorf("failed to get organization member: %w", classify(err))
+	}
+	return member, nil
+}
@@ -2,10 +2,14 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -13,8 +17,138 @@ import (
 )
 
 type VerificationRepository interface {
+	// Create сохраняет новую проверку. Уже существующая строка с тем же идентификатором
+	// не меняется: ее мог записать воркер или перехватчик публикации.
+	Create(ctx context.Context, verification *domain.Verification) error
+	// CreateWithRequest сохраняет новую проверку, как Create, и в той же транзакции записывает запрос
+	// воркерам msg в outbox_messages, захваченный до claimedUntil (нулевое время - не захваченный).
+	// false - у проверки уже есть неотправленное сообщение, оно не меняется. Без verification
+	// записывается только сообщение: строка проверки уже записана в базу другого региона.
+	CreateWithRequest(ctx context.Context, verification *domain.Verification, msg *messaging.OutboxMessage, claimedUntil time.Time) (bool, error)
 	GetByID(ctx context.Context, id string) (*model.Verification, error)
-	GetAll(ctx context.Context, limit *int32, offset *int32) ([]*model.Verification, error)
+	GetByIDWithData(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.Verification, error)
+	GetAll(ctx context.Context, filter VerificationFilter, limit *int32, offset *int32) ([]*model.Verification, error)
+	// StreamAll читает те же проверки, что и GetAll, по одной строке и передает каждую в fn,
+	// не собирая список в памяти. Ошибка fn прекращает чтение и возвращается как есть.
+	StreamAll(ctx context.Context, filter VerificationFilter, limit *int32, offset *int32, fn func(*model.Verification) error) error
+	GetPrevious(ctx context.Context, id string) (*model.Verification, error)
+	GetAuthorsByINN(ctx context.Context, inn string) ([]string, error)
+	UpdateRiskLevel(ctx context.Context, id string, riskLevel model.RiskLevel) error
+	// UpdateStatus сохраняет статус и ошибку из уведомления о завершении. Время смены статуса
+	// changedAt не дает более старому уведомлению перезаписать более новый статус; нулевое время -
+	// время неизвестно, статус меняется без проверки порядка.
+	UpdateStatus(ctx context.Context, id string, status model.VerificationStatus, errorMessage *string, changedAt time.Time) error
+	GetMonitoringCandidates(ctx context.Context, olderThan time.Time, limit int) ([]*MonitoringCandidate, error)
+	SetExternalRef(ctx context.Context, id string, ref *model.ExternalRef) error
+	// SetMetadata сохраняет метаданные проверки, заменяя прежние
+	SetMetadata(ctx context.Context, id string, metadata map[string]string) error
+	// UpdateMetadata меняет метаданные проверки функцией update в транзакции, блокирующей
+	// метаданные от одновременных изменений, и возвращает новые метаданные
+	UpdateMetadata(ctx context.Context, id string, update func(current map[string]string) (map[string]string, error)) (map[string]string, error)
+	GetIDByExternalRef(ctx context.Context, system *string, ref string) (string, error)
+	GetSnapshot(ctx context.Context, inn string, asOf time.Time) (*model.Verification, error)
+	GetLatestStatuses(ctx context.Context, inns []string) ([]*model.CompanyVerificationStatus, error)
+	// GetLatestSince возвращает последнюю проверку компании, созданную не раньше since
+	GetLatestSince(ctx context.Context, inn string, since time.Time) (*model.RecentVerification, error)
+	SetMissingDataTypes(ctx context.Context, id string, missing []model.VerificationDataType) error
+	GetMissingDataRetryCandidates(ctx context.Context, updatedBefore time.Time, maxRetries int, limit int) ([]*model.Verification, error)
+	MarkMissingDataRetried(ctx context.Context, id string) error
+	SetDataValidation(ctx context.Context, id string, dataType model.VerificationDataType, validationErrors []string) error
+}
+
+// VerificationFilter условия отбора проверок, пустые поля не участвуют в фильтрации
+type VerificationFilter struct {
+	Status      *model.VerificationStatus
+	INN         *string
+	AuthorEmail *string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// CreatedFromDay и CreatedToDay включительные границы по календарным дням в часовом поясе TimeZone
+	CreatedFromDay *time.Time
+	CreatedToDay   *time.Time
+	TimeZone       string
+	// ExternalSystem и ExternalRef идентификатор проверки во внешней системе
+	ExternalSystem *string
+	ExternalRef    *string
+	// Assignee и ReviewState отбор по аналитику и этапу ревью
+	Assignee    *string
+	ReviewState *model.ReviewState
+	// OldestFirst выдает проверки начиная с самых старых, по умолчанию - с самых новых
+	OldestFirst bool
+	// Owner, Tenant и Organization область видимости списка: проверки автора, арендатора (домена email)
+	// автора или авторов - участников организации. В отличие от AuthorEmail задаются шлюзом, а не пользователем.
+	Owner        *string
+	Tenant       *string
+	Organization *string
+	// Metadata проверки, метаданные которых содержат все пары
+	Metadata map[string]string
+}
+
+func (f VerificationFilter) timeZone() string {
+	if f.TimeZone == "" {
+		return "UTC"
+	}
+	return f.TimeZone
+}
+
+// apply добавляет условия фильтра к запросу по verifications, соединенной с verification_external_refs
+func (f VerificationFilter) apply(builder *selectBuilder) *selectBuilder {
+	if f.Status != nil {
+		builder.Where("status = ?", string(*f.Status))
+	}
+	if f.INN != nil {
+		builder.Where("inn = ?", *f.INN)
+	}
+	if f.AuthorEmail != nil {
+		builder.Where("author_email = ?", *f.AuthorEmail)
+	}
+	if f.CreatedFrom != nil {
+		builder.Where("created_at >= ?", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		builder.Where("created_at < ?", *f.CreatedTo)
+	}
+	// Граница дня переводится в момент времени на стороне базы, столбец остается без преобразований
+	if f.CreatedFromDay != nil {
+		builder.Where("created_at >= ?::date::timestamp AT TIME ZONE ?", f.CreatedFromDay.Format(time.DateOnly), f.timeZone())
+	}
+	if f.CreatedToDay != nil {
+		builder.Where("created_at < (?::date + 1)::timestamp AT TIME ZONE ?", f.CreatedToDay.Format(time.DateOnly), f.timeZone())
+	}
+	if f.ExternalSystem != nil {
+		builder.Where("external_system = ?", *f.ExternalSystem)
+	}
+	if f.ExternalRef != nil {
+		builder.Where("external_ref = ?", *f.ExternalRef)
+	}
+	if f.Assignee != nil {
+		builder.Where("assignee = ?", *f.Assignee)
+	}
+	if f.ReviewState != nil {
+		builder.Where("review_state = ?", string(*f.ReviewState))
+	}
+	if f.Owner != nil {
+		builder.Where("author_email = ?", *f.Owner)
+	}
+	if f.Tenant != nil {
+		builder.Where(tenantExpr+" = ?", *f.Tenant)
+	}
+	if f.Organization != nil {
+		builder.Where("author_email IN (SELECT u.email FROM memberships m JOIN users u ON u.id = m.user_id WHERE m.organization_id = ?)", *f.Organization)
+	}
+	if len(f.Metadata) > 0 {
+		encoded, _ := json.Marshal(f.Metadata)
+		builder.Where("EXISTS (SELECT 1 FROM verification_metadata m WHERE m.verification_id = verifications.id AND m.metadata @> ?::jsonb)", string(encoded))
+	}
+	return builder
+}
+
+// MonitoringCandidate компания, последняя проверка которой устарела и требует повторения
+type MonitoringCandidate struct {
+	INN                string
+	RequestedDataTypes []model.VerificationDataType
+	RiskLevel          *model.RiskLevel
+	LastVerifiedAt     time.Time
 }
 
 type verificationRepository struct {
@@ -31,110 +165,738 @@ func NewVerificationRepository(db *pgxpool.Pool, cacheRepo DataCacheRepository,
 	}
 }
 
+// verificationColumns столбцы проверки в порядке, в котором их читает scanVerification.
+// Запрос должен соединять verifications с verification_external_refs.
+// Ожидаемое время завершения читается только для незавершенных проверок.
+const verificationColumns = `id, inn, status, author_email, company_id, risk_level, requested_data_types, missing_data_types, created_at, updated_at,
+			external_system, external_ref, legal_hold, sandbox, risk_ruleset_id,
+			assignee, review_state, review_comment, reviewed_at, error_message,
+			(SELECT e.estimated_completion_at FROM verification_estimates e
+				WHERE e.verification_id = verifications.id AND verifications.status IN ('PENDING', 'IN_PROCESS', 'PROCESSING')),
+			(SELECT m.metadata FROM verification_metadata m WHERE m.verification_id = verifications.id)`
+
+// scanVerification читает проверку из строки с verificationColumns
+func scanVerification(row pgx.Row) (*domain.Verification, error) {
+	var v domain.Verification
+	var externalSystem, externalRef *string
+	var metadata []byte
+	err := row.Scan(&v.ID, &v.INN, &v.Status, &v.AuthorEmail, &v.CompanyID, &v.RiskLevel, (*dataTypeArray)(&v.RequestedDataTypes), (*dataTypeArray)(&v.MissingDataTypes), &v.CreatedAt, &v.UpdatedAt,
+		&externalSystem, &externalRef, &v.LegalHold, &v.Sandbox, &v.RulesetID,
+		&v.Assignee, &v.ReviewState, &v.ReviewComment, &v.ReviewedAt, &v.ErrorMessage, &v.EstimatedCompletionAt, &metadata)
+	if err != nil {
+		return nil, err
+	}
+	if metadata != nil {
+		if err := json.Unmarshal(metadata, &v.Metadata); err != nil {
+			return nil, fmt.Errorf("invalid metadata: %w", err)
+		}
+	}
+
+	if externalRef != nil {
+		v.ExternalRef = &domain.ExternalRef{System: externalSystem, Ref: *externalRef}
+	}
+	return &v, nil
+}
+
 // GetByID получает проверку по ID с использованием системы кэширования
 func (r *verificationRepository) GetByID(ctx context.Context, id string) (*model.Verification, error) {
-	query := `
-		SELECT id, inn, status, author_email, company_id, requested_data_types, created_at, updated_at
+	return r.GetByIDWithData(ctx, id, nil)
+}
+
+// GetByIDWithData возвращает проверку, читая из кэша данные только типов dataTypes (nil - всех типов).
+// Результаты проверки по схеме возвращаются для тех же типов.
+func (r *verificationRepository) GetByIDWithData(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.Verification, error) {
+	query := `SELECT ` + verificationColumns + `
 		FROM verifications
+		LEFT JOIN verification_external_refs ON verification_id = id
 		WHERE id = $1
 	`
 
-	var verification model.Verification
-	var createdAt, updatedAt time.Time
-	err := r.db.QueryRow(ctx, query, id).
-		Scan(&verification.ID, &verification.Inn, &verification.Status, &verification.AuthorEmail, &verification.CompanyID, &verification.RequestedDataTypes, &createdAt, &updatedAt)
+	verification, err := scanVerification(r.db.QueryRow(ctx, query, id))
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, nil
+			return nil, notFoundf("verification not found: %s", id)
 		}
 		r.logger.Error("failed to get verification", zap.Error(err), zap.String("id", id))
-		return nil, fmt.Errorf("failed to get verification: %w", err)
+		return nil, fmt.Errorf("failed to get verification: %w", classify(err))
+	}
+
+	data, quality, err := loadVerificationData(ctx, r.db, r.cacheRepo, r.logger, id, dataTypes)
+	if err != nil {
+		return nil, err
 	}
-	verification.CreatedAt = createdAt.Format(time.RFC3339)
-	verification.UpdatedAt = updatedAt.Format(time.RFC3339)
 
+	verification.Data = data
+	verification.DataQuality = quality
+	return model.VerificationFromDomain(verification), nil
+}
+
+// loadVerificationData читает данные проверки id из кэша (только типов dataTypes, nil - всех типов)
+// и результаты их проверки по схеме. Типы отбираются в запросе, строки остальных типов не читаются.
+// Общее чтение для всех версий схемы проверок.
+func loadVerificationData(ctx context.Context, db *pgxpool.Pool, cacheRepo DataCacheRepository, logger *zap.Logger, id string, dataTypes []model.VerificationDataType) ([]*domain.Data, []*domain.DataQuality, error) {
 	dataQuery := `
-		SELECT data_type, data_hash, created_at
+		SELECT data_type, data_hash, schema_version, created_at, validation_errors, validated_at, freshness
 		FROM verification_data
-		WHERE verification_id = $1
+		WHERE verification_id = $1 AND ($2::text[] IS NULL OR data_type = ANY($2))
 		ORDER BY created_at
 	`
 
-	rows, err := r.db.Query(ctx, dataQuery, id)
+	var types []string
+	if dataTypes != nil {
+		types = make([]string, 0, len(dataTypes))
+		for _, dataType := range dataTypes {
+			types = append(types, string(dataType))
+		}
+	}
+
+	rows, err := db.Query(ctx, dataQuery, id, types)
 	if err != nil {
-		r.logger.Error("failed to get verification data", zap.Error(err), zap.String("id", id))
-		return nil, fmt.Errorf("failed to get verification data: %w", err)
+		logger.Error("failed to get verification data", zap.Error(err), zap.String("id", id))
+		return nil, nil, fmt.Errorf("failed to get verification data: %w", classify(err))
 	}
 	defer rows.Close()
 
-	var data []*model.VerificationData
+	var data []*domain.Data
+	var quality []*domain.DataQuality
 	for rows.Next() {
-		var vd model.VerificationData
-		var dataCreatedAt time.Time
+		var vd domain.Data
 		var dataHash *string
-		err := rows.Scan(&vd.DataType, &dataHash, &dataCreatedAt)
+		var validationErrors []string
+		var validatedAt *time.Time
+		var freshness *string
+		err := rows.Scan(&vd.DataType, &dataHash, &vd.SchemaVersion, &vd.CreatedAt, &validationErrors, &validatedAt, &freshness)
 		if err != nil {
-			r.logger.Error("failed to scan verification data", zap.Error(err))
+			reportScanFailure(ctx, logger, rows, "verification data", err)
 			continue
 		}
 
-		if dataHash != nil && *dataHash != "" {
-			cachedData, cacheErr := r.cacheRepo.GetDataByHash(ctx, *dataHash)
-			if cacheErr != nil {
-				r.logger.Warn("failed to get data from cache, skipping record", zap.Error(cacheErr), zap.String("hash", *dataHash))
-				continue
+		// Результат проверки по схеме возвращается, даже если сами данные прочитать не удалось
+		if validatedAt != nil {
+			quality = append(quality, &domain.DataQuality{
+				DataType: vd.DataType,
+				Valid:    len(validationErrors) == 0,
+				Errors:   append([]string{}, validationErrors...),
+			})
+		}
+
+		// Непрочитанные данные одного типа не мешают вернуть остальные
+		if dataHash == nil || *dataHash == "" {
+			reportUnavailableData(ctx, logger, UnavailableData{VerificationID: id, DataType: model.VerificationDataType(vd.DataType), Err: errors.New("verification data has no hash")})
+			continue
+		}
+		cachedData, cacheErr := cacheRepo.GetDataByHash(ctx, *dataHash)
+		if cacheErr != nil {
+			// Отмененный запрос не означает, что данные повреждены
+			if ctx.Err() != nil {
+				return nil, nil, fmt.Errorf("failed to get verification data: %w", ctx.Err())
 			}
-			vd.Data = cachedData
-		} else {
-			r.logger.Warn("verification data has no hash, skipping", zap.String("data_type", string(vd.DataType)))
+			reportUnavailableData(ctx, logger, UnavailableData{VerificationID: id, DataType: model.VerificationDataType(vd.DataType), Hash: *dataHash, Err: cacheErr})
 			continue
 		}
-
-		vd.CreatedAt = dataCreatedAt.Format(time.RFC3339)
+		vd.Payload = cachedData
+		if freshness != nil {
+			vd.Freshness = domain.Freshness(*freshness)
+		}
 		data = append(data, &vd)
 	}
+	return data, quality, nil
+}
 
-	verification.Data = data
-	return &verification, nil
+func (r *verificationRepository) GetAll(ctx context.Context, filter VerificationFilter, limit *int32, offset *int32) ([]*model.Verification, error) {
+	var verifications []*model.Verification
+	err := r.StreamAll(ctx, filter, limit, offset, func(v *model.Verification) error {
+		verifications = append(verifications, v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return verifications, nil
 }
 
-func (r *verificationRepository) GetAll(ctx context.Context, limit *int32, offset *int32) ([]*model.Verification, error) {
-	query := `
-		SELECT id, inn, status, author_email, company_id, requested_data_types, created_at, updated_at
+func (r *verificationRepository) StreamAll(ctx context.Context, filter VerificationFilter, limit *int32, offset *int32, fn func(*model.Verification) error) error {
+	builder := newSelect(`SELECT ` + verificationColumns + `
 		FROM verifications
-		ORDER BY created_at DESC
+		LEFT JOIN verification_external_refs ON verification_id = id`)
+	filter.apply(builder)
+
+	order := "created_at DESC"
+	if filter.OldestFirst {
+		order = "created_at"
+	}
+	query, args := builder.OrderBy(order).Limit(limit).Offset(offset).Build()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("failed to get all verifications", zap.Error(err))
+		return fmt.Errorf("failed to get all verifications: %w", classify(err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		v, err := scanVerification(rows)
+		if err != nil {
+			reportScanFailure(ctx, r.logger, rows, "verification", err)
+			continue
+		}
+		if err := fn(model.VerificationFromDomain(v)); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("failed to read verifications", zap.Error(err))
+		return fmt.Errorf("failed to read verifications: %w", classify(err))
+	}
+
+	return nil
+}
+
+// GetPrevious возвращает предыдущую проверку той же компании без загрузки данных
+func (r *verificationRepository) GetPrevious(ctx context.Context, id string) (*model.Verification, error) {
+	query := `
+		SELECT p.id, p.inn, p.status, p.author_email, p.company_id, p.risk_level, p.requested_data_types, p.missing_data_types, p.created_at, p.updated_at,
+			p.assignee, p.review_state
+		FROM verifications v
+		JOIN verifications p ON p.inn = v.inn AND p.created_at < v.created_at
+		WHERE v.id = $1
+		ORDER BY p.created_at DESC
+		LIMIT 1
 	`
 
-	if limit != nil || offset != nil {
-		if limit != nil && offset != nil {
-			query += fmt.Sprintf(" LIMIT %d OFFSET %d", *limit, *offset)
-		} else if limit != nil {
-			query += fmt.Sprintf(" LIMIT %d", *limit)
-		} else if offset != nil {
-			query += fmt.Sprintf(" OFFSET %d", *offset)
+	var verification domain.Verification
+	err := r.db.QueryRow(ctx, query, id).
+		Scan(&verification.ID, &verification.INN, &verification.Status, &verification.AuthorEmail, &verification.CompanyID, &verification.RiskLevel, (*dataTypeArray)(&verification.RequestedDataTypes), (*dataTypeArray)(&verification.MissingDataTypes), &verification.CreatedAt, &verification.UpdatedAt,
+			&verification.Assignee, &verification.ReviewState)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
 		}
+		r.logger.Error("failed to get previous verification", zap.Error(err), zap.String("id", id))
+		return nil, fmt.Errorf("failed to get previous verification: %w", classify(err))
 	}
+	return model.VerificationFromDomain(&verification), nil
+}
+
+// GetAuthorsByINN возвращает всех пользователей, когда-либо проверявших компанию
+func (r *verificationRepository) GetAuthorsByINN(ctx context.Context, inn string) ([]string, error) {
+	query := `SELECT DISTINCT author_email FROM verifications WHERE inn = $1`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, inn)
 	if err != nil {
-		r.logger.Error("failed to get all verifications", zap.Error(err))
-		return nil, fmt.Errorf("failed to get all verifications: %w", err)
+		r.logger.Error("failed to get authors by inn", zap.Error(err), zap.String("inn", inn))
+		return nil, fmt.Errorf("failed to get authors by inn: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var authors []string
+	for rows.Next() {
+		var author string
+		if err := rows.Scan(&author); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "author", err)
+			continue
+		}
+		authors = append(authors, author)
+	}
+
+	return authors, nil
+}
+
+// UpdateRiskLevel сохраняет уровень риска, присвоенный скоринговым движком
+func (r *verificationRepository) UpdateRiskLevel(ctx context.Context, id string, riskLevel model.RiskLevel) error {
+	query := `UPDATE verifications SET risk_level = $2, updated_at = NOW() WHERE id = $1`
+
+	tag, err := r.db.Exec(ctx, query, id, string(riskLevel))
+	if err != nil {
+		r.logger.Error("failed to update risk level", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to update risk level: %w", classify(err))
+	}
+
+	if tag.RowsAffected() == 0 {
+		return notFoundf("verification not found: %s", id)
+	}
+
+	return nil
+}
+
+// statusChangeApplies сообщает, применяется ли смена статуса, произошедшая в changedAt, к проверке,
+// статус которой последний раз менялся в current. Без одного из времен порядок неизвестен и смена
+// применяется: порядок уведомлений одной проверки обеспечивают обработчики NATS_COMPLETED_SHARDS.
+func statusChangeApplies(current *time.Time, changedAt time.Time) bool {
+	return current == nil || changedAt.IsZero() || !current.After(changedAt)
+}
+
+// UpdateStatus сохраняет статус проверки из уведомления о завершении. Смена статуса, более ранняя,
+// чем уже сохраненная, не применяется. Порядок определяет status_changed_at, а не updated_at:
+// updated_at меняют и другие записи шлюза.
+func (r *verificationRepository) UpdateStatus(ctx context.Context, id string, status model.VerificationStatus, errorMessage *string, changedAt time.Time) error {
+	var statusChangedAt *time.Time
+	if !changedAt.IsZero() {
+		statusChangedAt = &changedAt
+	}
+
+	var (
+		current *time.Time
+		applied bool
+	)
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, `SELECT status_changed_at FROM verifications WHERE id = $1 FOR UPDATE`, id).Scan(&current); err != nil {
+			return err
+		}
+		if applied = statusChangeApplies(current, changedAt); !applied {
+			return nil
+		}
+		_, err := tx.Exec(ctx, `
+			UPDATE verifications
+			SET status = $2, error_message = $3, status_changed_at = COALESCE($4, status_changed_at), updated_at = COALESCE($4, NOW())
+			WHERE id = $1
+		`, id, string(status), errorMessage, statusChangedAt)
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return notFoundf("verification not found: %s", id)
+	}
+	if err != nil {
+		r.logger.Error("failed to update verification status", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to update verification status: %w", classify(err))
+	}
+
+	if !applied {
+		r.logger.Warn("verification status change is older than the saved one, not applied",
+			zap.String("id", id),
+			zap.String("status", string(status)),
+			zap.Time("changed_at", changedAt),
+			zap.Timep("status_changed_at", current))
+	}
+
+	return nil
+}
+
+// GetMonitoringCandidates возвращает компании, последняя проверка которых старше olderThan.
+// Компании с высоким уровнем риска возвращаются первыми, внутри группы - от самых старых проверок.
+func (r *verificationRepository) GetMonitoringCandidates(ctx context.Context, olderThan time.Time, limit int) ([]*MonitoringCandidate, error) {
+	query := `
+		SELECT inn, requested_data_types, risk_level, created_at
+		FROM (
+			SELECT DISTINCT ON (inn) inn, requested_data_types, risk_level, created_at
+			FROM verifications
+			WHERE NOT sandbox
+			ORDER BY inn, created_at DESC
+		) latest
+		WHERE created_at < $1
+		ORDER BY (risk_level = 'HIGH') DESC NULLS LAST, created_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, olderThan, limit)
+	if err != nil {
+		r.logger.Error("failed to get monitoring candidates", zap.Error(err))
+		return nil, fmt.Errorf("failed to get monitoring candidates: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var candidates []*MonitoringCandidate
+	for rows.Next() {
+		var c MonitoringCandidate
+		var requested dataTypeArray
+		if err := rows.Scan(&c.INN, &requested, &c.RiskLevel, &c.LastVerifiedAt); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "monitoring candidate", err)
+			continue
+		}
+		c.RequestedDataTypes = model.DataTypesFromDomain(requested)
+		candidates = append(candidates, &c)
+	}
+
+	return candidates, nil
+}
+
+// SetMissingDataTypes сохраняет недоставленные типы данных.
+// Если список не пуст, проверка переводится в статус PARTIALLY_COMPLETED.
+func (r *verificationRepository) SetMissingDataTypes(ctx context.Context, id string, missing []model.VerificationDataType) error {
+	query := `
+		UPDATE verifications
+		SET missing_data_types = $2,
+			status = CASE WHEN cardinality($2::text[]) > 0 THEN 'PARTIALLY_COMPLETED' ELSE status END,
+			updated_at = NOW()
+		WHERE id = $1
+	`
+
+	types := make([]string, 0, len(missing))
+	for _, dataType := range missing {
+		types = append(types, string(dataType))
+	}
+
+	tag, err := r.db.Exec(ctx, query, id, types)
+	if err != nil {
+		r.logger.Error("failed to set missing data types", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to set missing data types: %w", classify(err))
+	}
+
+	if tag.RowsAffected() == 0 {
+		return notFoundf("verification not found: %s", id)
+	}
+
+	return nil
+}
+
+// GetMissingDataRetryCandidates возвращает частично завершенные проверки, которые не обновлялись
+// с updatedBefore и для которых еще не исчерпаны повторные запросы. Проверки песочницы
+// не запрашиваются повторно: их данные синтетические.
+func (r *verificationRepository) GetMissingDataRetryCandidates(ctx context.Context, updatedBefore time.Time, maxRetries int, limit int) ([]*model.Verification, error) {
+	query := `
+		SELECT id, inn, status, author_email, company_id, risk_level, requested_data_types, missing_data_types, created_at, updated_at
+		FROM verifications
+		WHERE status = 'PARTIALLY_COMPLETED' AND NOT sandbox AND updated_at < $1 AND missing_data_retries < $2
+		ORDER BY updated_at
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(ctx, query, updatedBefore, maxRetries, limit)
+	if err != nil {
+		r.logger.Error("failed to get missing data retry candidates", zap.Error(err))
+		return nil, fmt.Errorf("failed to get missing data retry candidates: %w", classify(err))
 	}
 	defer rows.Close()
 
 	var verifications []*model.Verification
 	for rows.Next() {
-		var v model.Verification
-		var createdAt, updatedAt time.Time
-		err := rows.Scan(&v.ID, &v.Inn, &v.Status, &v.AuthorEmail, &v.CompanyID, &v.RequestedDataTypes, &createdAt, &updatedAt)
+		var v domain.Verification
+		err := rows.Scan(&v.ID, &v.INN, &v.Status, &v.AuthorEmail, &v.CompanyID, &v.RiskLevel, (*dataTypeArray)(&v.RequestedDataTypes), (*dataTypeArray)(&v.MissingDataTypes), &v.CreatedAt, &v.UpdatedAt)
 		if err != nil {
-			r.logger.Error("failed to scan verification", zap.Error(err))
+			reportScanFailure(ctx, r.logger, rows, "verification", err)
 			continue
 		}
-		v.CreatedAt = createdAt.Format(time.RFC3339)
-		v.UpdatedAt = updatedAt.Format(time.RFC3339)
-		verifications = append(verifications, &v)
+		verifications = append(verifications, model.VerificationFromDomain(&v))
 	}
 
 	return verifications, nil
 }
+
+// MarkMissingDataRetried возвращает проверку в обработку после повторного запроса недостающих данных
+func (r *verificationRepository) MarkMissingDataRetried(ctx context.Context, id string) error {
+	query := `
+		UPDATE verifications
+		SET status = 'IN_PROCESS', missing_data_retries = missing_data_retries + 1, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	tag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		r.logger.Error("failed to mark missing data retried", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to mark missing data retried: %w", classify(err))
+	}
+
+	if tag.RowsAffected() == 0 {
+		return notFoundf("verification not found: %s", id)
+	}
+
+	return nil
+}
+
+// createVerificationQuery добавляет проверку, если ее еще нет
+const createVerificationQuery = `
+	INSERT INTO verifications (id, inn, status, author_email, requested_data_types, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+	ON CONFLICT (id) DO NOTHING
+`
+
+func createVerificationArgs(verification *domain.Verification) []any {
+	requested := make([]string, 0, len(verification.RequestedDataTypes))
+	for _, dataType := range verification.RequestedDataTypes {
+		requested = append(requested, string(dataType))
+	}
+	return []any{verification.ID, verification.INN, string(verification.Status), verification.AuthorEmail, requested}
+}
+
+// Create добавляет проверку, если ее еще нет: строку мог раньше записать воркер или перехватчик публикации
+func (r *verificationRepository) Create(ctx context.Context, verification *domain.Verification) error {
+	if _, err := r.db.Exec(ctx, createVerificationQuery, createVerificationArgs(verification)...); err != nil {
+		r.logger.Error("failed to create verification", zap.Error(err), zap.String("id", verification.ID))
+		return fmt.Errorf("failed to create verification: %w", classify(err))
+	}
+
+	return nil
+}
+
+// createRequestQuery записывает запрос воркерам новой проверки. Уже отправленное сообщение той же
+// проверки заменяется, как в Enqueue, а неотправленное остается: его отправит outbox_relay.
+const createRequestQuery = `
+	INSERT INTO outbox_messages (id, tenant, priority, payload, available_at, held, claimed_until)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (id) DO UPDATE
+	SET tenant = EXCLUDED.tenant, priority = EXCLUDED.priority, payload = EXCLUDED.payload,
+	    available_at = EXCLUDED.available_at, held = EXCLUDED.held, released_at = NULL,
+	    claimed_until = EXCLUDED.claimed_until, attempts = 0, last_error = NULL, published_at = NULL
+	WHERE outbox_messages.published_at IS NOT NULL
+	RETURNING created_at
+`
+
+// CreateWithRequest записывает строку и запрос одной транзакцией: сбой между ними не оставит
+// проверку без запроса или запрос без проверки
+func (r *verificationRepository) CreateWithRequest(ctx context.Context, verification *domain.Verification, msg *messaging.OutboxMessage, claimedUntil time.Time) (bool, error) {
+	var claimed *time.Time
+	if !claimedUntil.IsZero() {
+		claimed = &claimedUntil
+	}
+
+	written := true
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if verification != nil {
+			if _, err := tx.Exec(ctx, createVerificationQuery, createVerificationArgs(verification)...); err != nil {
+				return err
+			}
+		}
+		err := tx.QueryRow(ctx, createRequestQuery, msg.ID, msg.Tenant, string(msg.Priority), []byte(msg.Payload), msg.AvailableAt, msg.Held, claimed).Scan(&msg.CreatedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			written = false
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		r.logger.Error("failed to create verification with request", zap.Error(err), zap.String("id", msg.ID))
+		return false, fmt.Errorf("failed to create verification: %w", classify(err))
+	}
+
+	return written, nil
+}
+
+// SetExternalRef связывает проверку с идентификатором во внешней системе
+func (r *verificationRepository) SetExternalRef(ctx context.Context, id string, ref *model.ExternalRef) error {
+	query := `
+		INSERT INTO verification_external_refs (verification_id, external_system, external_ref)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (verification_id) DO UPDATE SET
+			external_system = EXCLUDED.external_system,
+			external_ref = EXCLUDED.external_ref,
+			linked_at = NOW()
+	`
+
+	if _, err := r.db.Exec(ctx, query, id, ref.System, ref.Ref); err != nil {
+		r.logger.Error("failed to set external ref", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to set external ref: %w", classify(err))
+	}
+
+	return nil
+}
+
+func (r *verificationRepository) SetMetadata(ctx context.Context, id string, metadata map[string]string) error {
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	query := `
+		INSERT INTO verification_metadata (verification_id, metadata)
+		VALUES ($1, $2)
+		ON CONFLICT (verification_id) DO UPDATE SET metadata = EXCLUDED.metadata, updated_at = NOW()
+	`
+	if _, err := r.db.Exec(ctx, query, id, encoded); err != nil {
+		r.logger.Error("failed to set metadata", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to set metadata: %w", classify(err))
+	}
+	return nil
+}
+
+func (r *verificationRepository) UpdateMetadata(ctx context.Context, id string, update func(current map[string]string) (map[string]string, error)) (map[string]string, error) {
+	var updated map[string]string
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		// Строка без метаданных создается, чтобы одновременные изменения блокировали одну строку
+		_, err := tx.Exec(ctx, `
+			INSERT INTO verification_metadata (verification_id) VALUES ($1)
+			ON CONFLICT (verification_id) DO NOTHING
+		`, id)
+		if err != nil {
+			return err
+		}
+
+		var encoded []byte
+		if err := tx.QueryRow(ctx, `SELECT metadata FROM verification_metadata WHERE verification_id = $1 FOR UPDATE`, id).Scan(&encoded); err != nil {
+			return err
+		}
+		current := map[string]string{}
+		if err := json.Unmarshal(encoded, &current); err != nil {
+			return fmt.Errorf("invalid metadata: %w", err)
+		}
+
+		if updated, err = update(current); err != nil {
+			return err
+		}
+		if encoded, err = json.Marshal(updated); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `UPDATE verification_metadata SET metadata = $2, updated_at = NOW() WHERE verification_id = $1`, id, encoded)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("failed to update metadata", zap.Error(err), zap.String("id", id))
+		return nil, fmt.Errorf("failed to update metadata: %w", classify(err))
+	}
+	return updated, nil
+}
+
+// GetIDByExternalRef возвращает ID последней проверки, связанной с идентификатором внешней системы.
+// Если проверка не найдена, возвращает ErrNotFound.
+func (r *verificationRepository) GetIDByExternalRef(ctx context.Context, system *string, ref string) (string, error) {
+	query := `
+		SELECT id
+		FROM verifications
+		JOIN verification_external_refs ON verification_id = id
+		WHERE external_ref = $1 AND external_system IS NOT DISTINCT FROM $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var id string
+	err := r.db.QueryRow(ctx, query, ref, system).Scan(&id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", notFoundf("verification not found by external ref: %s", ref)
+		}
+		r.logger.Error("failed to get verification by external ref", zap.Error(err), zap.String("ref", ref))
+		return "", fmt.Errorf("failed to get verification by external ref: %w", classify(err))
+	}
+
+	return id, nil
+}
+
+// GetSnapshot возвращает последнюю завершенную проверку компании, созданную до asOf, только с данными,
+// доставленными до asOf. nil означает, что на этот момент о компании ничего не было известно.
+func (r *verificationRepository) GetSnapshot(ctx context.Context, inn string, asOf time.Time) (*model.Verification, error) {
+	query := `
+		SELECT v.id, array_agg(d.data_type)
+		FROM verifications v
+		JOIN verification_data d ON d.verification_id = v.id AND d.created_at < $2
+		WHERE v.inn = $1 AND v.created_at < $2 AND v.status IN ('COMPLETED', 'PARTIALLY_COMPLETED')
+		GROUP BY v.id, v.created_at
+		ORDER BY v.created_at DESC
+		LIMIT 1
+	`
+
+	var id string
+	var delivered []model.VerificationDataType
+	err := r.db.QueryRow(ctx, query, inn, asOf).Scan(&id, &delivered)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		r.logger.Error("failed to get company snapshot", zap.Error(err), zap.String("inn", inn))
+		return nil, fmt.Errorf("failed to get company snapshot: %w", classify(err))
+	}
+
+	verification, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Данные, дополненные после asOf, на тот момент еще не были известны
+	known := make(map[model.VerificationDataType]bool, len(delivered))
+	for _, dataType := range delivered {
+		known[dataType] = true
+	}
+	var data []*model.VerificationData
+	for _, vd := range verification.Data {
+		if known[vd.DataType] {
+			data = append(data, vd)
+		}
+	}
+	var quality []*model.DataQuality
+	for _, q := range verification.DataQuality {
+		if known[q.DataType] {
+			quality = append(quality, q)
+		}
+	}
+	verification.Data = data
+	verification.DataQuality = quality
+
+	return verification, nil
+}
+
+// SetDataValidation сохраняет результат проверки данных по JSON Schema. Данные не изменяются:
+// некорректные данные остаются доступны, но помечаются ошибками валидации.
+func (r *verificationRepository) SetDataValidation(ctx context.Context, id string, dataType model.VerificationDataType, validationErrors []string) error {
+	query := `
+		UPDATE verification_data
+		SET validation_errors = $3, validated_at = NOW()
+		WHERE verification_id = $1 AND data_type = $2
+	`
+
+	if validationErrors == nil {
+		validationErrors = []string{}
+	}
+
+	_, err := r.db.Exec(ctx, query, id, string(dataType), validationErrors)
+	if err != nil {
+		r.logger.Error("failed to save data validation", zap.Error(err), zap.String("id", id), zap.String("data_type", string(dataType)))
+		return fmt.Errorf("failed to save data validation: %w", classify(err))
+	}
+
+	return nil
+}
+
+// GetLatestStatuses возвращает статус последней проверки каждой компании из списка.
+// Компании без проверок в результат не попадают.
+func (r *verificationRepository) GetLatestStatuses(ctx context.Context, inns []string) ([]*model.CompanyVerificationStatus, error) {
+	query := `
+		SELECT DISTINCT ON (inn) inn, id, status, risk_level, created_at, updated_at
+		FROM verifications
+		WHERE inn = ANY($1)
+		ORDER BY inn, created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, inns)
+	if err != nil {
+		r.logger.Error("failed to get latest verification statuses", zap.Error(err), zap.Int("inns", len(inns)))
+		return nil, fmt.Errorf("failed to get latest verification statuses: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var statuses []*model.CompanyVerificationStatus
+	for rows.Next() {
+		var status model.CompanyVerificationStatus
+		var createdAt, updatedAt time.Time
+		if err := rows.Scan(&status.Inn, &status.VerificationID, &status.Status, &status.RiskLevel, &createdAt, &updatedAt); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "verification status", err)
+			continue
+		}
+		created := createdAt.Format(time.RFC3339)
+		updated := updatedAt.Format(time.RFC3339)
+		status.CreatedAt = &created
+		status.UpdatedAt = &updated
+		statuses = append(statuses, &status)
+	}
+
+	return statuses, nil
+}
+
+// GetLatestSince ищет последнюю проверку по индексу idx_verifications_inn_created_at и читает только
+// столбцы, нужные для ответа. Без проверки за период возвращается Exists = false.
+func (r *verificationRepository) GetLatestSince(ctx context.Context, inn string, since time.Time) (*model.RecentVerification, error) {
+	query := `
+		SELECT id, status, created_at
+		FROM verifications
+		WHERE inn = $1 AND created_at >= $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var id string
+	var status model.VerificationStatus
+	var createdAt time.Time
+	err := r.db.QueryRow(ctx, query, inn, since).Scan(&id, &status, &createdAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &model.RecentVerification{Exists: false}, nil
+	}
+	if err != nil {
+		r.logger.Error("failed to get recent verification", zap.Error(err), zap.String("inn", inn))
+		return nil, fmt.Errorf("failed to get recent verification: %w", classify(err))
+	}
+
+	created := createdAt.Format(time.RFC3339)
+	return &model.RecentVerification{
+		Exists:         true,
+		VerificationID: &id,
+		Status:         &status,
+		CreatedAt:      &created,
+	}, nil
+}
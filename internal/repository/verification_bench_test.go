@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/allocbudget"
+	"scoring_api_gateway/internal/domain"
+)
+
+// scanVerificationAllocs бюджет выделений памяти на разбор строки проверки без учета декодирования pgx.
+// Если изменение осознанно его превышает, бюджет поднимается в том же изменении с объяснением в описании.
+const scanVerificationAllocs = 16
+
+// valuesRow строка с готовыми значениями столбцов: измеряется только разбор строки в проверку
+type valuesRow []any
+
+func (r valuesRow) Scan(dest ...any) error {
+	for i, value := range r {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+func benchVerificationRow() valuesRow {
+	createdAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	riskLevel := domain.RiskLevelLow
+	system, ref, ruleset, assignee := "crm", "DEAL-1", "2024-06", "reviewer@example.com"
+	reviewedAt := createdAt.Add(time.Hour)
+	return valuesRow{
+		"2f1c6f3e-8a52-4c55-9d2b-3c1f0e6b7a90", "7707083893", domain.StatusCompleted, "analyst@example.com", (*string)(nil), &riskLevel,
+		[]domain.DataType{domain.DataTypeBasicInformation, domain.DataTypeActivities}, []domain.DataType(nil),
+		createdAt, createdAt.Add(time.Minute),
+		&system, &ref, false, false, &ruleset,
+		&assignee, domain.ReviewStateApproved, (*string)(nil), &reviewedAt,
+	}
+}
+
+func TestScanVerification(t *testing.T) {
+	verification, err := scanVerification(benchVerificationRow())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	createdAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	if !verification.CreatedAt.Equal(createdAt) || verification.ExternalRef == nil || verification.ExternalRef.Ref != "DEAL-1" ||
+		verification.ReviewedAt == nil || !verification.ReviewedAt.Equal(createdAt.Add(time.Hour)) {
+		t.Errorf("unexpected verification %+v", verification)
+	}
+}
+
+func BenchmarkScanVerification(b *testing.B) {
+	row := benchVerificationRow()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if _, err := scanVerification(row); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestScanVerificationAllocBudget(t *testing.T) {
+	row := benchVerificationRow()
+	allocbudget.Check(t, scanVerificationAllocs, func() {
+		_, _ = scanVerification(row)
+	})
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatusChangeApplies(t *testing.T) {
+	saved := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		current   *time.Time
+		changedAt time.Time
+		expected  bool
+	}{
+		{name: "first_change", current: nil, changedAt: saved.Add(-time.Hour), expected: true},
+		{name: "later_change", current: &saved, changedAt: saved.Add(time.Minute), expected: true},
+		{name: "same_time", current: &saved, changedAt: saved, expected: true},
+		{name: "earlier_change", current: &saved, changedAt: saved.Add(-time.Minute), expected: false},
+		{name: "unknown_time", current: &saved, changedAt: time.Time{}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if applies := statusChangeApplies(tt.current, tt.changedAt); applies != tt.expected {
+				t.Errorf("expected %v, but got %v", tt.expected, applies)
+			}
+		})
+	}
+}
@@ -0,0 +1,438 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// VerificationV2Repository проверки в следующей версии схемы (verifications_v2, миграция 049).
+// Пока идет перенос, источником истины остается verifications: новая схема только повторяет ее.
+type VerificationV2Repository interface {
+	// Sync копирует проверку из verifications в новую схему. Проверка, которой нет в verifications,
+	// удаляется из новой схемы.
+	Sync(ctx context.Context, id string) error
+	// SyncChanged копирует до limit проверок, измененных после сохраненной позиции и не позже
+	// settledBefore, сдвигает позицию и возвращает число скопированных проверок
+	SyncChanged(ctx context.Context, settledBefore time.Time, limit int) (int, error)
+	// Verify сравнивает со старой схемой следующие limit проверок по порядку id, исправляет
+	// расхождения и возвращает число исправленных проверок. Дойдя до конца, начинает сначала.
+	Verify(ctx context.Context, limit int) (int, error)
+	GetByIDWithData(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.Verification, error)
+}
+
+// Проходы задачи синхронизации, позиции которых хранятся в verifications_v2_sync
+const (
+	syncPassChanged = "changed"
+	syncPassVerify  = "verify"
+)
+
+// zeroUUID позиция прохода, который еще не выполнялся
+const zeroUUID = "00000000-0000-0000-0000-000000000000"
+
+type verificationV2Repository struct {
+	db        *pgxpool.Pool
+	cacheRepo DataCacheRepository
+	logger    *zap.Logger
+}
+
+func NewVerificationV2Repository(db *pgxpool.Pool, cacheRepo DataCacheRepository, logger *zap.Logger) VerificationV2Repository {
+	return &verificationV2Repository{
+		db:        db,
+		cacheRepo: cacheRepo,
+		logger:    logger,
+	}
+}
+
+// verificationDocument проверка в столбце document таблицы verifications_v2. Данные проверки
+// остаются в verification_data, ожидаемое время завершения - в verification_estimates.
+// Пустые значения не опускаются: nil и пустой список должны читаться так же, как из verifications.
+type verificationDocument struct {
+	ID                 string             `json:"id"`
+	INN                string             `json:"inn"`
+	Status             domain.Status      `json:"status"`
+	ErrorMessage       *string            `json:"error_message"`
+	AuthorEmail        string             `json:"author_email"`
+	CompanyID          *string            `json:"company_id"`
+	RiskLevel          *domain.RiskLevel  `json:"risk_level"`
+	RulesetID          *string            `json:"ruleset_id"`
+	ExternalSystem     *string            `json:"external_system"`
+	ExternalRef        *string            `json:"external_ref"`
+	Metadata           map[string]string  `json:"metadata"`
+	LegalHold          bool               `json:"legal_hold"`
+	Sandbox            bool               `json:"sandbox"`
+	RequestedDataTypes []domain.DataType  `json:"requested_data_types"`
+	MissingDataTypes   []domain.DataType  `json:"missing_data_types"`
+	Assignee           *string            `json:"assignee"`
+	ReviewState        domain.ReviewState `json:"review_state"`
+	ReviewComment      *string            `json:"review_comment"`
+	ReviewedAt         *time.Time         `json:"reviewed_at"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+}
+
+// documentFromDomain строит документ проверки. Время хранится в UTC, чтобы документ не зависел
+// от часового пояса экземпляра, который его записал.
+func documentFromDomain(v *domain.Verification) *verificationDocument {
+	document := &verificationDocument{
+		ID:                 v.ID,
+		INN:                v.INN,
+		Status:             v.Status,
+		ErrorMessage:       v.ErrorMessage,
+		AuthorEmail:        v.AuthorEmail,
+		CompanyID:          v.CompanyID,
+		RiskLevel:          v.RiskLevel,
+		RulesetID:          v.RulesetID,
+		Metadata:           v.Metadata,
+		LegalHold:          v.LegalHold,
+		Sandbox:            v.Sandbox,
+		RequestedDataTypes: v.RequestedDataTypes,
+		MissingDataTypes:   v.MissingDataTypes,
+		Assignee:           v.Assignee,
+		ReviewState:        v.ReviewState,
+		ReviewComment:      v.ReviewComment,
+		CreatedAt:          v.CreatedAt.UTC(),
+		UpdatedAt:          v.UpdatedAt.UTC(),
+	}
+	if v.ExternalRef != nil {
+		document.ExternalSystem, document.ExternalRef = v.ExternalRef.System, &v.ExternalRef.Ref
+	}
+	if v.ReviewedAt != nil {
+		reviewedAt := v.ReviewedAt.UTC()
+		document.ReviewedAt = &reviewedAt
+	}
+	return document
+}
+
+// domain возвращает проверку с временем в местном часовом поясе, как его читает pgx
+func (d *verificationDocument) domain() *domain.Verification {
+	v := &domain.Verification{
+		ID:                 d.ID,
+		INN:                d.INN,
+		Status:             d.Status,
+		ErrorMessage:       d.ErrorMessage,
+		AuthorEmail:        d.AuthorEmail,
+		CompanyID:          d.CompanyID,
+		RiskLevel:          d.RiskLevel,
+		RulesetID:          d.RulesetID,
+		Metadata:           d.Metadata,
+		LegalHold:          d.LegalHold,
+		Sandbox:            d.Sandbox,
+		RequestedDataTypes: d.RequestedDataTypes,
+		MissingDataTypes:   d.MissingDataTypes,
+		Assignee:           d.Assignee,
+		ReviewState:        d.ReviewState,
+		ReviewComment:      d.ReviewComment,
+		CreatedAt:          d.CreatedAt.Local(),
+		UpdatedAt:          d.UpdatedAt.Local(),
+	}
+	if d.ExternalRef != nil {
+		v.ExternalRef = &domain.ExternalRef{System: d.ExternalSystem, Ref: *d.ExternalRef}
+	}
+	if d.ReviewedAt != nil {
+		reviewedAt := d.ReviewedAt.Local()
+		v.ReviewedAt = &reviewedAt
+	}
+	return v
+}
+
+// sameDocument сообщает, совпадает ли сохраненный документ с документом, построенным по verifications.
+// JSONB не сохраняет порядок ключей и форму чисел, поэтому сравниваются документы после повторного кодирования.
+func sameDocument(stored []byte, expected *verificationDocument) bool {
+	var decoded verificationDocument
+	if err := json.Unmarshal(stored, &decoded); err != nil {
+		return false
+	}
+	storedJSON, err := json.Marshal(&decoded)
+	if err != nil {
+		return false
+	}
+	expectedJSON, err := json.Marshal(expected)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(storedJSON, expectedJSON)
+}
+
+// Sync копирует проверку под блокировкой, чтобы одновременные копии одной проверки не записали
+// устаревший документ поверх нового
+func (r *verificationV2Repository) Sync(ctx context.Context, id string) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('verifications_v2:' || $1::text))`, id); err != nil {
+			return err
+		}
+
+		query := `SELECT ` + verificationColumns + `
+			FROM verifications
+			LEFT JOIN verification_external_refs ON verification_id = id
+			WHERE id = $1
+		`
+		verification, err := scanVerification(tx.QueryRow(ctx, query, id))
+		if errors.Is(err, pgx.ErrNoRows) {
+			_, err := tx.Exec(ctx, `DELETE FROM verifications_v2 WHERE id = $1`, id)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		return writeDocument(ctx, tx, documentFromDomain(verification))
+	})
+	if err != nil {
+		r.logger.Error("failed to sync verification to v2", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to sync verification to v2: %w", classify(err))
+	}
+	return nil
+}
+
+// writeDocument сохраняет документ проверки. Арендатор вычисляется так же, как в индексе
+// ограничений арендаторов (миграция 035).
+func writeDocument(ctx context.Context, tx pgx.Tx, document *verificationDocument) error {
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("failed to encode verification document: %w", err)
+	}
+
+	var updatedAt *time.Time
+	if !document.UpdatedAt.IsZero() {
+		updatedAt = &document.UpdatedAt
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO verifications_v2 (id, tenant, inn, status, document, created_at, updated_at, synced_at)
+		VALUES ($1, COALESCE(lower(substring($2::text FROM '@([^@]+)$')), 'default'), $3, $4, $5, $6, $7, NOW())
+		ON CONFLICT (id, created_at) DO UPDATE SET
+			tenant = EXCLUDED.tenant,
+			inn = EXCLUDED.inn,
+			status = EXCLUDED.status,
+			document = EXCLUDED.document,
+			updated_at = EXCLUDED.updated_at,
+			synced_at = NOW()
+	`, document.ID, document.AuthorEmail, document.INN, string(document.Status), encoded, document.CreatedAt, updatedAt)
+	return err
+}
+
+func (r *verificationV2Repository) getPosition(ctx context.Context, pass string) (*time.Time, string, error) {
+	var updatedAt *time.Time
+	var id string
+	err := r.db.QueryRow(ctx, `SELECT updated_at, verification_id FROM verifications_v2_sync WHERE pass = $1`, pass).Scan(&updatedAt, &id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, zeroUUID, nil
+	}
+	if err != nil {
+		r.logger.Error("failed to get v2 sync position", zap.Error(err), zap.String("pass", pass))
+		return nil, "", fmt.Errorf("failed to get v2 sync position: %w", classify(err))
+	}
+	return updatedAt, id, nil
+}
+
+func (r *verificationV2Repository) savePosition(ctx context.Context, pass string, updatedAt *time.Time, id string) error {
+	query := `
+		INSERT INTO verifications_v2_sync (pass, updated_at, verification_id, saved_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (pass) DO UPDATE SET
+			updated_at = EXCLUDED.updated_at,
+			verification_id = EXCLUDED.verification_id,
+			saved_at = NOW()
+	`
+	if _, err := r.db.Exec(ctx, query, pass, updatedAt, id); err != nil {
+		r.logger.Error("failed to save v2 sync position", zap.Error(err), zap.String("pass", pass))
+		return fmt.Errorf("failed to save v2 sync position: %w", classify(err))
+	}
+	return nil
+}
+
+// SyncChanged идет по verifications в порядке (updated_at, id). Последние изменения пропускаются
+// до settledBefore: транзакция, начатая раньше, может зафиксироваться позже и оказаться за позицией.
+// Проверки без updated_at и изменения, которые его не трогают, исправляет Verify.
+func (r *verificationV2Repository) SyncChanged(ctx context.Context, settledBefore time.Time, limit int) (int, error) {
+	positionAt, positionID, err := r.getPosition(ctx, syncPassChanged)
+	if err != nil {
+		return 0, err
+	}
+	if positionAt == nil {
+		positionAt = &time.Time{}
+	}
+
+	query := `
+		SELECT id, updated_at
+		FROM verifications
+		WHERE (updated_at, id) > ($1, $2) AND updated_at <= $3
+		ORDER BY updated_at, id
+		LIMIT $4
+	`
+	rows, err := r.db.Query(ctx, query, *positionAt, positionID, settledBefore, limit)
+	if err != nil {
+		r.logger.Error("failed to get changed verifications", zap.Error(err))
+		return 0, fmt.Errorf("failed to get changed verifications: %w", classify(err))
+	}
+	type change struct {
+		id        string
+		updatedAt time.Time
+	}
+	changes, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (change, error) {
+		var c change
+		err := row.Scan(&c.id, &c.updatedAt)
+		return c, err
+	})
+	if err != nil {
+		r.logger.Error("failed to read changed verifications", zap.Error(err))
+		return 0, fmt.Errorf("failed to read changed verifications: %w", classify(err))
+	}
+
+	for _, c := range changes {
+		if err := r.Sync(ctx, c.id); err != nil {
+			return 0, err
+		}
+	}
+	if len(changes) == 0 {
+		return 0, nil
+	}
+
+	last := changes[len(changes)-1]
+	if err := r.savePosition(ctx, syncPassChanged, &last.updatedAt, last.id); err != nil {
+		return 0, err
+	}
+	return len(changes), nil
+}
+
+// Verify сравнивает документы новой схемы с verifications в диапазоне id следующей пачки.
+// Проверки, которых нет в новой схеме или которые удалены из verifications, тоже исправляются.
+func (r *verificationV2Repository) Verify(ctx context.Context, limit int) (int, error) {
+	_, positionID, err := r.getPosition(ctx, syncPassVerify)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `SELECT ` + verificationColumns + `
+		FROM verifications
+		LEFT JOIN verification_external_refs ON verification_id = id
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+	rows, err := r.db.Query(ctx, query, positionID, limit)
+	if err != nil {
+		r.logger.Error("failed to get verifications to verify", zap.Error(err))
+		return 0, fmt.Errorf("failed to get verifications to verify: %w", classify(err))
+	}
+	expected := make(map[string]*verificationDocument, limit)
+	var ids []string
+	for rows.Next() {
+		v, err := scanVerification(rows)
+		if err != nil {
+			rows.Close()
+			r.logger.Error("failed to read verification to verify", zap.Error(err))
+			return 0, fmt.Errorf("failed to read verification to verify: %w", classify(err))
+		}
+		expected[v.ID] = documentFromDomain(v)
+		ids = append(ids, v.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		r.logger.Error("failed to read verifications to verify", zap.Error(err))
+		return 0, fmt.Errorf("failed to read verifications to verify: %w", classify(err))
+	}
+
+	// Последняя пачка прохода проверяет и документы за последним id verifications
+	var upTo *string
+	if len(ids) == limit {
+		upTo = &ids[len(ids)-1]
+	}
+	stored := make(map[string][]byte, len(ids))
+	storedRows, err := r.db.Query(ctx, `
+		SELECT id, document FROM verifications_v2
+		WHERE id > $1 AND ($2::uuid IS NULL OR id <= $2)
+	`, positionID, upTo)
+	if err != nil {
+		r.logger.Error("failed to get v2 verifications to verify", zap.Error(err))
+		return 0, fmt.Errorf("failed to get v2 verifications to verify: %w", classify(err))
+	}
+	for storedRows.Next() {
+		var id string
+		var document []byte
+		if err := storedRows.Scan(&id, &document); err != nil {
+			storedRows.Close()
+			r.logger.Error("failed to read v2 verification to verify", zap.Error(err))
+			return 0, fmt.Errorf("failed to read v2 verification to verify: %w", classify(err))
+		}
+		stored[id] = document
+	}
+	storedRows.Close()
+	if err := storedRows.Err(); err != nil {
+		r.logger.Error("failed to read v2 verifications to verify", zap.Error(err))
+		return 0, fmt.Errorf("failed to read v2 verifications to verify: %w", classify(err))
+	}
+
+	var drifted []string
+	for _, id := range ids {
+		document, ok := stored[id]
+		if !ok || !sameDocument(document, expected[id]) {
+			drifted = append(drifted, id)
+		}
+	}
+	for id := range stored {
+		if _, ok := expected[id]; !ok {
+			drifted = append(drifted, id)
+		}
+	}
+	for _, id := range drifted {
+		if err := r.Sync(ctx, id); err != nil {
+			return 0, err
+		}
+	}
+
+	next := zeroUUID
+	if upTo != nil {
+		next = *upTo
+	}
+	if err := r.savePosition(ctx, syncPassVerify, nil, next); err != nil {
+		return 0, err
+	}
+	return len(drifted), nil
+}
+
+// GetByIDWithData читает проверку из документа новой схемы, а данные - так же, как из verifications
+func (r *verificationV2Repository) GetByIDWithData(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.Verification, error) {
+	query := `
+		SELECT n.document,
+			(SELECT e.estimated_completion_at FROM verification_estimates e
+				WHERE e.verification_id = n.id AND n.status IN ('PENDING', 'IN_PROCESS', 'PROCESSING'))
+		FROM verifications_v2 n
+		WHERE n.id = $1
+	`
+
+	var encoded []byte
+	var estimatedCompletionAt *time.Time
+	if err := r.db.QueryRow(ctx, query, id).Scan(&encoded, &estimatedCompletionAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, notFoundf("verification not found in v2: %s", id)
+		}
+		r.logger.Error("failed to get v2 verification", zap.Error(err), zap.String("id", id))
+		return nil, fmt.Errorf("failed to get v2 verification: %w", classify(err))
+	}
+
+	var document verificationDocument
+	if err := json.Unmarshal(encoded, &document); err != nil {
+		r.logger.Error("invalid v2 verification document", zap.Error(err), zap.String("id", id))
+		return nil, fmt.Errorf("invalid v2 verification document: %w", err)
+	}
+	verification := document.domain()
+	verification.EstimatedCompletionAt = estimatedCompletionAt
+
+	data, quality, err := loadVerificationData(ctx, r.db, r.cacheRepo, r.logger, id, dataTypes)
+	if err != nil {
+		return nil, err
+	}
+	verification.Data = data
+	verification.DataQuality = quality
+	return model.VerificationFromDomain(verification), nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+)
+
+func TestVerificationDocumentRoundTrip(t *testing.T) {
+	system := "crm"
+	reviewedAt := time.Date(2026, 3, 2, 10, 30, 0, 123000, time.FixedZone("MSK", 3*60*60))
+	verification := &domain.Verification{
+		ID:                 "7d0c6c8e-8f0e-4c52-9a57-3c9f6f7c1a10",
+		INN:                "7707083893",
+		Status:             domain.StatusCompleted,
+		AuthorEmail:        "analyst@bank.ru",
+		ExternalRef:        &domain.ExternalRef{System: &system, Ref: "42"},
+		Metadata:           map[string]string{"branch": "077"},
+		RequestedDataTypes: []domain.DataType{domain.DataTypeBasicInformation},
+		MissingDataTypes:   []domain.DataType{},
+		ReviewState:        domain.ReviewStateApproved,
+		ReviewedAt:         &reviewedAt,
+		CreatedAt:          time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC).Local(),
+		UpdatedAt:          time.Date(2026, 3, 1, 9, 5, 0, 0, time.UTC).Local(),
+	}
+
+	encoded, err := json.Marshal(documentFromDomain(verification))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded verificationDocument
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	restored := decoded.domain()
+	if !restored.ReviewedAt.Equal(reviewedAt) {
+		t.Errorf("expected the review time %s, but got %s", reviewedAt, restored.ReviewedAt)
+	}
+	restored.ReviewedAt, verification.ReviewedAt = nil, nil
+	if !reflect.DeepEqual(restored, verification) {
+		t.Errorf("expected the verification to survive the document, but got %+v", restored)
+	}
+	if restored.MissingDataTypes == nil {
+		t.Error("expected an empty list of missing data types to stay empty, not nil")
+	}
+}
+
+func TestSameDocument(t *testing.T) {
+	document := documentFromDomain(&domain.Verification{
+		ID:          "v-1",
+		Status:      domain.StatusPending,
+		AuthorEmail: "analyst@bank.ru",
+		Metadata:    map[string]string{"a": "1", "b": "2"},
+	})
+	encoded, _ := json.Marshal(document)
+
+	// JSONB возвращает ключи в своем порядке
+	var reordered map[string]any
+	json.Unmarshal(encoded, &reordered)
+	stored, _ := json.Marshal(reordered)
+	if !sameDocument(stored, document) {
+		t.Error("expected documents with reordered keys to be the same")
+	}
+
+	changed := *document
+	changed.AuthorEmail = "anonymized"
+	if sameDocument(stored, &changed) {
+		t.Error("expected a changed author email to be detected")
+	}
+}
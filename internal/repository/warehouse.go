@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// WarehouseChange запись потока изменений для аналитического хранилища.
+// Payload - нормализованная запись проверки или поступления данных без email автора.
+type WarehouseChange struct {
+	Seq            int64           `json:"seq"`
+	Type           string          `json:"type"`
+	VerificationID string          `json:"verification_id"`
+	Payload        json.RawMessage `json:"payload"`
+	RecordedAt     time.Time       `json:"recorded_at"`
+}
+
+type WarehouseRepository interface {
+	// GetChanges возвращает изменения после позиции after, записанные не позже settledBefore
+	GetChanges(ctx context.Context, after int64, settledBefore time.Time, limit int) ([]*WarehouseChange, error)
+	// GetBookmark возвращает позицию экспортера, 0 - экспорт еще не выполнялся
+	GetBookmark(ctx context.Context, exporter string) (int64, error)
+	SaveBookmark(ctx context.Context, exporter string, position int64) error
+}
+
+type warehouseRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewWarehouseRepository(db *pgxpool.Pool, logger *zap.Logger) WarehouseRepository {
+	return &warehouseRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetChanges читает изменения по порядку последовательности. Последние записи пропускаются до
+// settledBefore: номер seq выдается до фиксации транзакции, и более поздний номер может стать
+// видимым раньше предыдущего, который иначе оказался бы за закладкой.
+func (r *warehouseRepository) GetChanges(ctx context.Context, after int64, settledBefore time.Time, limit int) ([]*WarehouseChange, error) {
+	query := `
+		SELECT seq, record_type, verification_id, payload, created_at
+		FROM warehouse_changes
+		WHERE seq > $1 AND created_at <= $2
+		ORDER BY seq
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(ctx, query, after, settledBefore, limit)
+	if err != nil {
+		r.logger.Error("failed to get warehouse changes", zap.Error(err), zap.Int64("after", after))
+		return nil, fmt.Errorf("failed to get warehouse changes: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var changes []*WarehouseChange
+	for rows.Next() {
+		var change WarehouseChange
+		var payload []byte
+		if err := rows.Scan(&change.Seq, &change.Type, &change.VerificationID, &payload, &change.RecordedAt); err != nil {
+			// Пропуск строки сдвинул бы закладку за нее, поэтому выгрузка останавливается
+			return nil, fmt.Errorf("failed to scan warehouse change: %w", classify(err))
+		}
+		change.Payload = payload
+		changes = append(changes, &change)
+	}
+
+	return changes, rows.Err()
+}
+
+func (r *warehouseRepository) GetBookmark(ctx context.Context, exporter string) (int64, error) {
+	var position int64
+	err := r.db.QueryRow(ctx, `SELECT position FROM export_bookmarks WHERE exporter = $1`, exporter).Scan(&position)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		r.logger.Error("failed to get export bookmark", zap.Error(err), zap.String("exporter", exporter))
+		return 0, fmt.Errorf("failed to get export bookmark: %w", classify(err))
+	}
+	return position, nil
+}
+
+// SaveBookmark сдвигает закладку вперед. Закладка не откатывается, если экспорт на другом
+// экземпляре уже ушел дальше.
+func (r *warehouseRepository) SaveBookmark(ctx context.Context, exporter string, position int64) error {
+	query := `
+		INSERT INTO export_bookmarks (exporter, position, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (exporter) DO UPDATE
+		SET position = GREATEST(export_bookmarks.position, EXCLUDED.position), updated_at = NOW()
+	`
+
+	if _, err := r.db.Exec(ctx, query, exporter, position); err != nil {
+		r.logger.Error("failed to save export bookmark", zap.Error(err), zap.String("exporter", exporter), zap.Int64("position", position))
+		return fmt.Errorf("failed to save export bookmark: %w", classify(err))
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *model.Webhook) error
+	List(ctx context.Context) ([]*model.Webhook, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type webhookRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewWebhookRepository(db *pgxpool.Pool, logger *zap.Logger) WebhookRepository {
+	return &webhookRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create сохраняет регистрацию и заполняет ее идентификатор и время создания
+func (r *webhookRepository) Create(ctx context.Context, webhook *model.Webhook) error {
+	query := `
+		INSERT INTO webhooks (url, template, custom_template, headers, events, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	headers, err := json.Marshal(webhook.Headers)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook headers: %w", err)
+	}
+
+	events := make([]string, 0, len(webhook.Events))
+	for _, event := range webhook.Events {
+		events = append(events, string(event))
+	}
+
+	var createdAt time.Time
+	err = r.db.QueryRow(ctx, query, webhook.URL, webhook.Template, webhook.CustomTemplate, headers, events, webhook.CreatedBy).
+		Scan(&webhook.ID, &createdAt)
+	if err != nil {
+		r.logger.Error("failed to create webhook", zap.Error(err), zap.String("url", webhook.URL))
+		return fmt.Errorf("failed to create webhook: %w", classify(err))
+	}
+	webhook.CreatedAt = createdAt.Format(time.RFC3339)
+
+	return nil
+}
+
+func (r *webhookRepository) List(ctx context.Context) ([]*model.Webhook, error) {
+	query := `
+		SELECT id, url, template, custom_template, headers, events, created_by, created_at
+		FROM webhooks
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		r.logger.Error("failed to list webhooks", zap.Error(err))
+		return nil, fmt.Errorf("failed to list webhooks: %w", classify(err))
+	}
+	defer rows.Close()
+
+	var webhooks []*model.Webhook
+	for rows.Next() {
+		var webhook model.Webhook
+		var headers []byte
+		var events []string
+		var createdAt time.Time
+		if err := rows.Scan(&webhook.ID, &webhook.URL, &webhook.Template, &webhook.CustomTemplate, &headers, &events, &webhook.CreatedBy, &createdAt); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "webhook", err)
+			continue
+		}
+		if err := json.Unmarshal(headers, &webhook.Headers); err != nil {
+			reportScanFailure(ctx, r.logger, rows, "webhook", err)
+			continue
+		}
+		webhook.Events = make([]model.WebhookEvent, 0, len(events))
+		for _, event := range events {
+			webhook.Events = append(webhook.Events, model.WebhookEvent(event))
+		}
+		webhook.CreatedAt = createdAt.Format(time.RFC3339)
+		webhooks = append(webhooks, &webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (r *webhookRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM webhooks WHERE id = $1`
+
+	tag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		r.logger.Error("failed to delete webhook", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to delete webhook: %w", classify(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return notFoundf("webhook not found: %s", id)
+	}
+	return nil
+}
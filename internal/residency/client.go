@@ -0,0 +1,62 @@
+package residency
+
+import (
+	"context"
+	"errors"
+
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+type residencyClient struct {
+	messaging.NATSClient
+	router *Router
+	home   repository.VerificationRepository
+	logger *zap.Logger
+}
+
+// NewClient записывает регион новой проверки арендатора, закрепленного за регионом, перед публикацией
+// запроса, чтобы уведомление о ее завершении и последующие запросы нашли проверку в базе региона.
+// Повторные запросы проверок, уже сохраненных в основной базе (например, недоставленных данных
+// проверок, созданных до закрепления арендатора), регион не получают. home - репозиторий основной базы.
+func NewClient(client messaging.NATSClient, router *Router, home repository.VerificationRepository, logger *zap.Logger) messaging.NATSClient {
+	return &residencyClient{
+		NATSClient: client,
+		router:     router,
+		home:       home,
+		logger:     logger,
+	}
+}
+
+func (c *residencyClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
+	return c.PublishVerificationRequestWithPriority(ctx, verification, messaging.PriorityNormal)
+}
+
+func (c *residencyClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	tenant := messaging.TenantOf(verification.AuthorEmail)
+	if c.router.TenantRegion(tenant) != c.router.Home() {
+		if err := c.assign(ctx, verification.ID, tenant); err != nil {
+			c.logger.Error("failed to assign verification region", zap.Error(err), zap.String("verification_id", verification.ID))
+			return err
+		}
+	}
+	return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+}
+
+func (c *residencyClient) assign(ctx context.Context, verificationID, tenant string) error {
+	region, err := c.router.regionOf(ctx, verificationID)
+	if err != nil || region != "" {
+		return err
+	}
+	_, err = c.home.GetByID(ctx, verificationID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	return c.router.assign(ctx, verificationID, tenant)
+}
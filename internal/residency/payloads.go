@@ -0,0 +1,34 @@
+package residency
+
+import (
+	"context"
+
+	"scoring_api_gateway/internal/service"
+)
+
+// payloadStores хранилища данных больше предела по регионам. Key, Location и Put без проверки
+// обращаются к хранилищу домашнего региона.
+type payloadStores struct {
+	service.PayloadStore
+	stores stores[service.PayloadStore]
+	router *Router
+}
+
+// NewPayloadStore выбирает хранилище данных по региону проверки. home - хранилище payloads.storage,
+// может быть nil, как и хранилища остальных регионов: данные больше предела в регионе без хранилища
+// отклоняются.
+func NewPayloadStore(home service.PayloadStore, regional map[string]service.PayloadStore, router *Router) service.PayloadStore {
+	all := stores[service.PayloadStore]{router.Home(): home}
+	for region, store := range regional {
+		all[region] = store
+	}
+	return &payloadStores{PayloadStore: home, stores: all, router: router}
+}
+
+func (s *payloadStores) StoreFor(ctx context.Context, verificationID string) (service.PayloadStore, error) {
+	region, err := s.router.resolve(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+	return s.stores[region], nil
+}
@@ -0,0 +1,209 @@
+package residency
+
+import (
+	"context"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/signing"
+)
+
+// regional хранилища по регионам и маршрутизатор, выбирающий регион проверки
+type regional[T any] struct {
+	stores stores[T]
+	router *Router
+}
+
+func newRegional[T any](home T, others map[string]T, router *Router) regional[T] {
+	all := stores[T]{router.Home(): home}
+	for region, store := range others {
+		all[region] = store
+	}
+	return regional[T]{stores: all, router: router}
+}
+
+// byID хранилище региона проверки verificationID
+func (r regional[T]) byID(ctx context.Context, verificationID string) (T, error) {
+	region, err := r.router.resolve(ctx, verificationID)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return r.stores.get(region)
+}
+
+// forList хранилище региона, в котором выполняются запросы пользователя не по проверке
+func (r regional[T]) forList(ctx context.Context) (T, error) {
+	return r.stores.get(r.router.regionForList(ctx))
+}
+
+// WrapCompletionSimulationRepository направляет ручные завершения в базу региона проверки
+func WrapCompletionSimulationRepository(home repository.CompletionSimulationRepository, others map[string]repository.CompletionSimulationRepository, router *Router) repository.CompletionSimulationRepository {
+	return &regionalCompletionSimulationRepository{newRegional(home, others, router)}
+}
+
+type regionalCompletionSimulationRepository struct {
+	regional[repository.CompletionSimulationRepository]
+}
+
+func (r *regionalCompletionSimulationRepository) Complete(ctx context.Context, id string, status domain.Status, data []*domain.Data, completedAt time.Time) error {
+	repo, err := r.byID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return repo.Complete(ctx, id, status, data, completedAt)
+}
+
+// WrapAmendmentRepository направляет поправки в базу региона проверки. Поправки создает триггер
+// базы, в которую воркер записал данные, поэтому публикация забирает их из всех регионов.
+func WrapAmendmentRepository(home repository.AmendmentRepository, others map[string]repository.AmendmentRepository, router *Router) repository.AmendmentRepository {
+	return &regionalAmendmentRepository{newRegional(home, others, router)}
+}
+
+type regionalAmendmentRepository struct {
+	regional[repository.AmendmentRepository]
+}
+
+// ClaimUnpublished забирает поправки всех регионов: публикация поправок - фоновая задача
+func (r *regionalAmendmentRepository) ClaimUnpublished(ctx context.Context, claimUntil time.Time, limit int) ([]*repository.Amendment, error) {
+	var amendments []*repository.Amendment
+	for _, region := range r.stores.regions() {
+		if len(amendments) >= limit {
+			break
+		}
+		claimed, err := r.stores[region].ClaimUnpublished(ctx, claimUntil, limit-len(amendments))
+		if err != nil {
+			return nil, err
+		}
+		amendments = append(amendments, claimed...)
+	}
+	return amendments, nil
+}
+
+func (r *regionalAmendmentRepository) MarkPublished(ctx context.Context, verificationID string, id int64) error {
+	repo, err := r.byID(ctx, verificationID)
+	if err != nil {
+		return err
+	}
+	return repo.MarkPublished(ctx, verificationID, id)
+}
+
+func (r *regionalAmendmentRepository) ListByVerification(ctx context.Context, verificationID string) ([]*repository.Amendment, error) {
+	repo, err := r.byID(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListByVerification(ctx, verificationID)
+}
+
+// WrapDataRedactionRepository направляет изменения данных в базу региона проверки
+func WrapDataRedactionRepository(home repository.DataRedactionRepository, others map[string]repository.DataRedactionRepository, router *Router) repository.DataRedactionRepository {
+	return &regionalDataRedactionRepository{newRegional(home, others, router)}
+}
+
+type regionalDataRedactionRepository struct {
+	regional[repository.DataRedactionRepository]
+}
+
+func (r *regionalDataRedactionRepository) Apply(ctx context.Context, change *repository.DataRedaction, value []byte, sign func(*repository.DataRedaction)) error {
+	repo, err := r.byID(ctx, change.VerificationID)
+	if err != nil {
+		return err
+	}
+	return repo.Apply(ctx, change, value, sign)
+}
+
+func (r *regionalDataRedactionRepository) ListByVerification(ctx context.Context, verificationID string) ([]*repository.DataRedaction, error) {
+	repo, err := r.byID(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListByVerification(ctx, verificationID)
+}
+
+// WrapAuditRepository направляет журнал аудита в базу региона проверки
+func WrapAuditRepository(home repository.AuditRepository, others map[string]repository.AuditRepository, router *Router) repository.AuditRepository {
+	return &regionalAuditRepository{newRegional(home, others, router)}
+}
+
+type regionalAuditRepository struct {
+	regional[repository.AuditRepository]
+}
+
+func (r *regionalAuditRepository) AddEvent(ctx context.Context, verificationID string, eventType model.AuditEventType, actor string, details map[string]any) error {
+	repo, err := r.byID(ctx, verificationID)
+	if err != nil {
+		return err
+	}
+	return repo.AddEvent(ctx, verificationID, eventType, actor, details)
+}
+
+func (r *regionalAuditRepository) GetTrail(ctx context.Context, verificationID string) ([]*model.AuditTrailEntry, error) {
+	repo, err := r.byID(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetTrail(ctx, verificationID)
+}
+
+// WrapSignatureRepository направляет подписи итогов в базу региона проверки
+func WrapSignatureRepository(home repository.SignatureRepository, others map[string]repository.SignatureRepository, router *Router) repository.SignatureRepository {
+	return &regionalSignatureRepository{newRegional(home, others, router)}
+}
+
+type regionalSignatureRepository struct {
+	regional[repository.SignatureRepository]
+}
+
+func (r *regionalSignatureRepository) GetSummary(ctx context.Context, verificationID string) (*signing.VerificationSummary, error) {
+	repo, err := r.byID(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetSummary(ctx, verificationID)
+}
+
+func (r *regionalSignatureRepository) SaveSignature(ctx context.Context, verificationID string, signature *model.VerificationSignature) error {
+	repo, err := r.byID(ctx, verificationID)
+	if err != nil {
+		return err
+	}
+	return repo.SaveSignature(ctx, verificationID, signature)
+}
+
+func (r *regionalSignatureRepository) GetLatestSignature(ctx context.Context, verificationID string) (*model.VerificationSignature, error) {
+	repo, err := r.byID(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetLatestSignature(ctx, verificationID)
+}
+
+// WrapDataCacheRepository читает кэш данных компаний в регионе пользователя запроса. Настройки
+// совместного использования относятся ко всему шлюзу и хранятся в основной базе.
+func WrapDataCacheRepository(home repository.DataCacheRepository, others map[string]repository.DataCacheRepository, router *Router) repository.DataCacheRepository {
+	return &regionalDataCacheRepository{DataCacheRepository: home, regional: newRegional(home, others, router)}
+}
+
+type regionalDataCacheRepository struct {
+	repository.DataCacheRepository
+	regional regional[repository.DataCacheRepository]
+}
+
+func (r *regionalDataCacheRepository) GetDataByHash(ctx context.Context, hash string) (string, error) {
+	repo, err := r.regional.forList(ctx)
+	if err != nil {
+		return "", err
+	}
+	return repo.GetDataByHash(ctx, hash)
+}
+
+func (r *regionalDataCacheRepository) GetByKey(ctx context.Context, key repository.CacheKey) (*model.VerificationData, error) {
+	repo, err := r.regional.forList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByKey(ctx, key)
+}
@@ -0,0 +1,332 @@
+package residency
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+)
+
+// stores хранилища по регионам, включая домашний
+type stores[T any] map[string]T
+
+func (s stores[T]) get(region string) (T, error) {
+	store, ok := s[region]
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("no storage configured for region %s", region)
+	}
+	return store, nil
+}
+
+// regions регионы по порядку, чтобы обход всех регионов был воспроизводимым
+func (s stores[T]) regions() []string {
+	regions := make([]string, 0, len(s))
+	for region := range s {
+		regions = append(regions, region)
+	}
+	slices.Sort(regions)
+	return regions
+}
+
+// WrapVerificationRepository направляет запросы проверок в базу их региона. home - репозиторий
+// основной базы, regional - базы остальных регионов.
+func WrapVerificationRepository(home repository.VerificationRepository, regional map[string]repository.VerificationRepository, router *Router) repository.VerificationRepository {
+	all := stores[repository.VerificationRepository]{router.Home(): home}
+	for region, repo := range regional {
+		all[region] = repo
+	}
+	return &regionalVerificationRepository{stores: all, router: router}
+}
+
+type regionalVerificationRepository struct {
+	stores stores[repository.VerificationRepository]
+	router *Router
+}
+
+// byID репозиторий региона проверки id
+func (r *regionalVerificationRepository) byID(ctx context.Context, id string) (repository.VerificationRepository, error) {
+	region, err := r.router.resolve(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.stores.get(region)
+}
+
+// forList репозиторий региона, в котором выполняются списки и поиск
+func (r *regionalVerificationRepository) forList(ctx context.Context) (repository.VerificationRepository, error) {
+	return r.stores.get(r.router.regionForList(ctx))
+}
+
+func (r *regionalVerificationRepository) Create(ctx context.Context, verification *domain.Verification) error {
+	repo, err := r.stores.get(r.router.TenantRegion(messaging.TenantOf(verification.AuthorEmail)))
+	if err != nil {
+		return err
+	}
+	return repo.Create(ctx, verification)
+}
+
+// CreateWithRequest записывает проверку в базу региона арендатора. Запросы воркерам хранятся в outbox
+// основной базы, поэтому проверка другого региона и ее запрос записываются двумя шагами: сначала строка.
+func (r *regionalVerificationRepository) CreateWithRequest(ctx context.Context, verification *domain.Verification, msg *messaging.OutboxMessage, claimedUntil time.Time) (bool, error) {
+	home, err := r.stores.get(r.router.Home())
+	if err != nil {
+		return false, err
+	}
+	region := r.router.TenantRegion(messaging.TenantOf(verification.AuthorEmail))
+	if region == r.router.Home() {
+		return home.CreateWithRequest(ctx, verification, msg, claimedUntil)
+	}
+
+	repo, err := r.stores.get(region)
+	if err != nil {
+		return false, err
+	}
+	if err := repo.Create(ctx, verification); err != nil {
+		return false, err
+	}
+	return home.CreateWithRequest(ctx, nil, msg, claimedUntil)
+}
+
+func (r *regionalVerificationRepository) GetByID(ctx context.Context, id string) (*model.Verification, error) {
+	repo, err := r.byID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByID(ctx, id)
+}
+
+func (r *regionalVerificationRepository) GetByIDWithData(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.Verification, error) {
+	repo, err := r.byID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByIDWithData(ctx, id, dataTypes)
+}
+
+func (r *regionalVerificationRepository) GetAll(ctx context.Context, filter repository.VerificationFilter, limit *int32, offset *int32) ([]*model.Verification, error) {
+	repo, err := r.forList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAll(ctx, filter, limit, offset)
+}
+
+func (r *regionalVerificationRepository) StreamAll(ctx context.Context, filter repository.VerificationFilter, limit *int32, offset *int32, fn func(*model.Verification) error) error {
+	repo, err := r.forList(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.StreamAll(ctx, filter, limit, offset, fn)
+}
+
+func (r *regionalVerificationRepository) GetPrevious(ctx context.Context, id string) (*model.Verification, error) {
+	repo, err := r.byID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetPrevious(ctx, id)
+}
+
+func (r *regionalVerificationRepository) GetAuthorsByINN(ctx context.Context, inn string) ([]string, error) {
+	repo, err := r.forList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAuthorsByINN(ctx, inn)
+}
+
+func (r *regionalVerificationRepository) UpdateRiskLevel(ctx context.Context, id string, riskLevel model.RiskLevel) error {
+	repo, err := r.byID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return repo.UpdateRiskLevel(ctx, id, riskLevel)
+}
+
+func (r *regionalVerificationRepository) UpdateStatus(ctx context.Context, id string, status model.VerificationStatus, errorMessage *string, changedAt time.Time) error {
+	repo, err := r.byID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return repo.UpdateStatus(ctx, id, status, errorMessage, changedAt)
+}
+
+// GetMonitoringCandidates собирает кандидатов всех регионов: мониторинг - фоновая задача
+func (r *regionalVerificationRepository) GetMonitoringCandidates(ctx context.Context, olderThan time.Time, limit int) ([]*repository.MonitoringCandidate, error) {
+	var candidates []*repository.MonitoringCandidate
+	for _, region := range r.stores.regions() {
+		found, err := r.stores[region].GetMonitoringCandidates(ctx, olderThan, limit)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, found...)
+	}
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates, nil
+}
+
+func (r *regionalVerificationRepository) SetExternalRef(ctx context.Context, id string, ref *model.ExternalRef) error {
+	repo, err := r.byID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return repo.SetExternalRef(ctx, id, ref)
+}
+
+func (r *regionalVerificationRepository) SetMetadata(ctx context.Context, id string, metadata map[string]string) error {
+	repo, err := r.byID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return repo.SetMetadata(ctx, id, metadata)
+}
+
+func (r *regionalVerificationRepository) UpdateMetadata(ctx context.Context, id string, update func(current map[string]string) (map[string]string, error)) (map[string]string, error) {
+	repo, err := r.byID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return repo.UpdateMetadata(ctx, id, update)
+}
+
+func (r *regionalVerificationRepository) GetIDByExternalRef(ctx context.Context, system *string, ref string) (string, error) {
+	repo, err := r.forList(ctx)
+	if err != nil {
+		return "", err
+	}
+	return repo.GetIDByExternalRef(ctx, system, ref)
+}
+
+func (r *regionalVerificationRepository) GetSnapshot(ctx context.Context, inn string, asOf time.Time) (*model.Verification, error) {
+	repo, err := r.forList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetSnapshot(ctx, inn, asOf)
+}
+
+func (r *regionalVerificationRepository) GetLatestStatuses(ctx context.Context, inns []string) ([]*model.CompanyVerificationStatus, error) {
+	repo, err := r.forList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetLatestStatuses(ctx, inns)
+}
+
+func (r *regionalVerificationRepository) GetLatestSince(ctx context.Context, inn string, since time.Time) (*model.RecentVerification, error) {
+	repo, err := r.forList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetLatestSince(ctx, inn, since)
+}
+
+func (r *regionalVerificationRepository) SetMissingDataTypes(ctx context.Context, id string, missing []model.VerificationDataType) error {
+	repo, err := r.byID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return repo.SetMissingDataTypes(ctx, id, missing)
+}
+
+// GetMissingDataRetryCandidates собирает кандидатов всех регионов: повторный запрос - фоновая задача
+func (r *regionalVerificationRepository) GetMissingDataRetryCandidates(ctx context.Context, updatedBefore time.Time, maxRetries int, limit int) ([]*model.Verification, error) {
+	var candidates []*model.Verification
+	for _, region := range r.stores.regions() {
+		found, err := r.stores[region].GetMissingDataRetryCandidates(ctx, updatedBefore, maxRetries, limit)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, found...)
+	}
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates, nil
+}
+
+func (r *regionalVerificationRepository) MarkMissingDataRetried(ctx context.Context, id string) error {
+	repo, err := r.byID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return repo.MarkMissingDataRetried(ctx, id)
+}
+
+func (r *regionalVerificationRepository) SetDataValidation(ctx context.Context, id string, dataType model.VerificationDataType, validationErrors []string) error {
+	repo, err := r.byID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return repo.SetDataValidation(ctx, id, dataType, validationErrors)
+}
+
+// WrapPayloadRepository направляет запросы данных проверок в базу их региона
+func WrapPayloadRepository(home repository.PayloadRepository, regional map[string]repository.PayloadRepository, router *Router) repository.PayloadRepository {
+	all := stores[repository.PayloadRepository]{router.Home(): home}
+	for region, repo := range regional {
+		all[region] = repo
+	}
+	return &regionalPayloadRepository{stores: all, router: router}
+}
+
+type regionalPayloadRepository struct {
+	stores stores[repository.PayloadRepository]
+	router *Router
+}
+
+func (r *regionalPayloadRepository) byID(ctx context.Context, verificationID string) (repository.PayloadRepository, error) {
+	region, err := r.router.resolve(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+	return r.stores.get(region)
+}
+
+func (r *regionalPayloadRepository) ListByVerification(ctx context.Context, verificationID string) ([]*repository.StoredPayload, error) {
+	repo, err := r.byID(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListByVerification(ctx, verificationID)
+}
+
+func (r *regionalPayloadRepository) Replace(ctx context.Context, verificationID string, dataType model.VerificationDataType, previousHash, data string) (string, error) {
+	repo, err := r.byID(ctx, verificationID)
+	if err != nil {
+		return "", err
+	}
+	return repo.Replace(ctx, verificationID, dataType, previousHash, data)
+}
+
+func (r *regionalPayloadRepository) Reject(ctx context.Context, rejected *repository.RejectedPayload) error {
+	repo, err := r.byID(ctx, rejected.VerificationID)
+	if err != nil {
+		return err
+	}
+	return repo.Reject(ctx, rejected)
+}
+
+func (r *regionalPayloadRepository) ListRejected(ctx context.Context, verificationID *string, limit int) ([]*repository.RejectedPayload, error) {
+	var (
+		repo repository.PayloadRepository
+		err  error
+	)
+	if verificationID != nil {
+		repo, err = r.byID(ctx, *verificationID)
+	} else {
+		repo, err = r.stores.get(r.router.regionForList(ctx))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListRejected(ctx, verificationID, limit)
+}
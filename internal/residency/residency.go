@@ -0,0 +1,208 @@
+// Package residency хранит проверки арендаторов, закрепленных за регионом (organizations.storage_region),
+// в базе и бакете этого региона. Регион новой проверки записывается в каталог verification_regions
+// основной базы перед публикацией запроса, и запросы по идентификатору проверки направляются в базу
+// ее региона. Пользователь может читать и менять только проверки региона своего арендатора, списки
+// и поиск выполняются в этом регионе. Фоновые задачи без пользователя обращаются к любому региону.
+// Вместе с проверкой в базе ее региона хранятся данные, журнал аудита, подписи итогов, поправки,
+// изменения данных и кэш данных компаний.
+package residency
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/repository"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"go.uber.org/zap"
+)
+
+// assignedCacheSize число запоминаемых регионов проверок. Регион проверки не меняется,
+// поэтому записи не устаревают.
+const assignedCacheSize = 10000
+
+// Directory регионы арендаторов и каталог проверок, хранящихся вне основной базы
+type Directory interface {
+	GetTenantRegions(ctx context.Context) (map[string]string, error)
+	AssignRegion(ctx context.Context, verificationID, region string) error
+	GetRegion(ctx context.Context, verificationID string) (string, error)
+}
+
+// Router определяет регион хранения проверок
+type Router struct {
+	directory Directory
+	home      string
+	logger    *zap.Logger
+
+	mu      sync.RWMutex
+	tenants map[string]string
+
+	assigned *lru.Cache[string, string]
+}
+
+// NewRouter создает маршрутизатор с домашним регионом home. Регионы арендаторов загружаются Refresh.
+func NewRouter(directory Directory, home string, logger *zap.Logger) *Router {
+	assigned, _ := lru.New[string, string](assignedCacheSize)
+	return &Router{
+		directory: directory,
+		home:      home,
+		logger:    logger,
+		tenants:   make(map[string]string),
+		assigned:  assigned,
+	}
+}
+
+// Home домашний регион - регион основной базы
+func (r *Router) Home() string {
+	return r.home
+}
+
+// Refresh перечитывает регионы арендаторов
+func (r *Router) Refresh(ctx context.Context) error {
+	tenants, err := r.directory.GetTenantRegions(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.tenants = tenants
+	r.mu.Unlock()
+	return nil
+}
+
+// TenantRegion регион хранения проверок арендатора
+func (r *Router) TenantRegion(tenant string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if region, ok := r.tenants[tenant]; ok {
+		return region
+	}
+	return r.home
+}
+
+// callerRegion регион арендатора пользователя запроса; false - запрос фоновой задачи. Регион
+// выбирается только по пользователю, подтвержденному прокси аутентификации, или сервисному аккаунту:
+// остальные пользователи работают в домашнем регионе.
+func (r *Router) callerRegion(ctx context.Context) (string, bool) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || principal.Email == "" {
+		return "", false
+	}
+	if !principal.Verified && principal.Scope == nil {
+		return r.home, true
+	}
+	return r.TenantRegion(messaging.TenantOf(principal.Email)), true
+}
+
+// regionForList регион, в котором выполняются списки и поиск запроса
+func (r *Router) regionForList(ctx context.Context) string {
+	if region, ok := r.callerRegion(ctx); ok {
+		return region
+	}
+	return r.home
+}
+
+// regionOf регион проверки по каталогу; пустая строка - проверки нет в каталоге
+func (r *Router) regionOf(ctx context.Context, verificationID string) (string, error) {
+	if region, ok := r.assigned.Get(verificationID); ok {
+		return region, nil
+	}
+	region, err := r.directory.GetRegion(ctx, verificationID)
+	if err != nil {
+		return "", err
+	}
+	if region != "" {
+		r.assigned.Add(verificationID, region)
+	}
+	return region, nil
+}
+
+// resolve возвращает регион проверки и отказывает пользователю другого региона. Проверки, которой
+// нет в каталоге, ищутся в регионе пользователя: ее либо нет, либо она создается этим запросом,
+// а фоновые задачи ищут ее в домашнем регионе.
+func (r *Router) resolve(ctx context.Context, verificationID string) (string, error) {
+	region, err := r.regionOf(ctx, verificationID)
+	if err != nil {
+		return "", err
+	}
+
+	caller, ok := r.callerRegion(ctx)
+	switch {
+	case region == "" && ok:
+		return caller, nil
+	case region == "":
+		return r.home, nil
+	case ok && caller != region:
+		metrics.ResidencyCrossRegionRefusals.WithLabelValues(region).Inc()
+		r.logger.Warn("cross-region access refused",
+			zap.String("verification_id", verificationID),
+			zap.String("region", region),
+			zap.String("caller_region", caller))
+		return "", &repository.Error{
+			Kind: repository.ErrCrossRegion,
+			Err:  fmt.Errorf("verification %s is stored in region %s and cannot be accessed from region %s", verificationID, region, caller),
+		}
+	}
+	return region, nil
+}
+
+// assign записывает регион новой проверки арендатора, если он отличается от домашнего
+func (r *Router) assign(ctx context.Context, verificationID, tenant string) error {
+	region := r.TenantRegion(tenant)
+	if region == r.home {
+		return nil
+	}
+	if err := r.directory.AssignRegion(ctx, verificationID, region); err != nil {
+		return err
+	}
+	r.assigned.Add(verificationID, region)
+	return nil
+}
+
+// ParseTargets разбирает хранилища регионов вида РЕГИОН=значение. Домашний регион home задается
+// основными настройками и в списке не допускается.
+func ParseTargets(home string, specs []string) (map[string]string, error) {
+	targets := make(map[string]string)
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		region, target, ok := strings.Cut(spec, "=")
+		region, target = strings.TrimSpace(region), strings.TrimSpace(target)
+		if !ok || region == "" || target == "" {
+			return nil, fmt.Errorf("invalid residency target %q, expected REGION=target", spec)
+		}
+		if region == home {
+			return nil, fmt.Errorf("residency target %q repeats the home region", region)
+		}
+		if _, ok := targets[region]; ok {
+			return nil, fmt.Errorf("duplicate residency target for region %q", region)
+		}
+		targets[region] = target
+	}
+	return targets, nil
+}
+
+// RefreshJob перечитывает регионы арендаторов
+type RefreshJob struct {
+	router *Router
+}
+
+func NewRefreshJob(router *Router) *RefreshJob {
+	return &RefreshJob{router: router}
+}
+
+func (j *RefreshJob) Name() string {
+	return "residency_refresh"
+}
+
+func (j *RefreshJob) Run(ctx context.Context) error {
+	return j.router.Refresh(ctx)
+}
@@ -0,0 +1,308 @@
+package residency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// memoryDirectory каталог регионов в памяти
+type memoryDirectory struct {
+	tenants  map[string]string
+	assigned map[string]string
+}
+
+func (d *memoryDirectory) GetTenantRegions(ctx context.Context) (map[string]string, error) {
+	return d.tenants, nil
+}
+
+func (d *memoryDirectory) AssignRegion(ctx context.Context, verificationID, region string) error {
+	if _, ok := d.assigned[verificationID]; !ok {
+		d.assigned[verificationID] = region
+	}
+	return nil
+}
+
+func (d *memoryDirectory) GetRegion(ctx context.Context, verificationID string) (string, error) {
+	return d.assigned[verificationID], nil
+}
+
+// memoryVerificationRepository база одного региона
+type memoryVerificationRepository struct {
+	repository.VerificationRepository
+	verifications map[string]*model.Verification
+	candidates    []*repository.MonitoringCandidate
+}
+
+func (r *memoryVerificationRepository) GetByID(ctx context.Context, id string) (*model.Verification, error) {
+	verification, ok := r.verifications[id]
+	if !ok {
+		return nil, &repository.Error{Kind: repository.ErrNotFound, Err: fmt.Errorf("verification not found: %s", id)}
+	}
+	return verification, nil
+}
+
+func (r *memoryVerificationRepository) GetMonitoringCandidates(ctx context.Context, olderThan time.Time, limit int) ([]*repository.MonitoringCandidate, error) {
+	return r.candidates, nil
+}
+
+// publishedClient запоминает опубликованные запросы
+type publishedClient struct {
+	messaging.NATSClient
+	published []string
+}
+
+func (c *publishedClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	c.published = append(c.published, verification.ID)
+	return nil
+}
+
+func newTestRouter(t *testing.T) (*Router, *memoryDirectory) {
+	directory := &memoryDirectory{
+		tenants:  map[string]string{"eu.example": "eu"},
+		assigned: map[string]string{"eu-verification": "eu"},
+	}
+	router := NewRouter(directory, "ru", zaptest.NewLogger(t))
+	if err := router.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return router, directory
+}
+
+func TestVerificationRepositoryRouting(t *testing.T) {
+	router, _ := newTestRouter(t)
+	home := &memoryVerificationRepository{verifications: map[string]*model.Verification{"ru-verification": {ID: "ru-verification"}}}
+	eu := &memoryVerificationRepository{verifications: map[string]*model.Verification{"eu-verification": {ID: "eu-verification"}}}
+	repo := WrapVerificationRepository(home, map[string]repository.VerificationRepository{"eu": eu}, router)
+
+	euUser := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@eu.example", Verified: true})
+	ruUser := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@ru.example", Verified: true})
+	euService := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "partner@eu.example", Scope: &auth.Scope{}})
+	// Email из заголовка клиента, не подтвержденный прокси аутентификации, регион не выбирает
+	euUnverified := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@eu.example"})
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		id            string
+		expectedError error
+	}{
+		{name: "same_region", ctx: euUser, id: "eu-verification"},
+		{name: "home_region", ctx: ruUser, id: "ru-verification"},
+		{name: "service_account", ctx: euService, id: "eu-verification"},
+		{name: "background_job", ctx: context.Background(), id: "eu-verification"},
+		{name: "unverified_caller", ctx: euUnverified, id: "eu-verification", expectedError: repository.ErrCrossRegion},
+		{name: "cross_region_to_pinned", ctx: ruUser, id: "eu-verification", expectedError: repository.ErrCrossRegion},
+		{name: "home_not_visible_to_pinned", ctx: euUser, id: "ru-verification", expectedError: repository.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verification, err := repo.GetByID(tt.ctx, tt.id)
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected error %v, but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if verification.ID != tt.id {
+				t.Errorf("expected verification %s, but got %s", tt.id, verification.ID)
+			}
+		})
+	}
+}
+
+func TestMonitoringCandidatesFromAllRegions(t *testing.T) {
+	router, _ := newTestRouter(t)
+	home := &memoryVerificationRepository{candidates: []*repository.MonitoringCandidate{{}, {}}}
+	eu := &memoryVerificationRepository{candidates: []*repository.MonitoringCandidate{{}}}
+	repo := WrapVerificationRepository(home, map[string]repository.VerificationRepository{"eu": eu}, router)
+
+	candidates, err := repo.GetMonitoringCandidates(context.Background(), time.Now(), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(candidates) != 3 {
+		t.Errorf("expected candidates of both regions, but got %d", len(candidates))
+	}
+
+	candidates, _ = repo.GetMonitoringCandidates(context.Background(), time.Now(), 2)
+	if len(candidates) != 2 {
+		t.Errorf("expected candidates limited to 2, but got %d", len(candidates))
+	}
+}
+
+func TestClientAssignsRegion(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		author         string
+		expectedRegion string
+	}{
+		{name: "pinned_tenant", id: "new-verification", author: "analyst@eu.example", expectedRegion: "eu"},
+		{name: "home_tenant", id: "new-verification", author: "analyst@ru.example"},
+		{name: "retry_of_home_verification", id: "ru-verification", author: "analyst@eu.example"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, directory := newTestRouter(t)
+			home := &memoryVerificationRepository{verifications: map[string]*model.Verification{"ru-verification": {ID: "ru-verification"}}}
+			next := &publishedClient{}
+			client := NewClient(next, router, home, zaptest.NewLogger(t))
+
+			err := client.PublishVerificationRequest(context.Background(), &domain.Verification{ID: tt.id, AuthorEmail: tt.author})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(next.published) != 1 {
+				t.Errorf("expected the request to be published, but got %v", next.published)
+			}
+			if directory.assigned[tt.id] != tt.expectedRegion {
+				t.Errorf("expected region %q, but got %q", tt.expectedRegion, directory.assigned[tt.id])
+			}
+		})
+	}
+}
+
+func TestParseTargets(t *testing.T) {
+	tests := []struct {
+		name          string
+		specs         []string
+		expected      map[string]string
+		expectedError string
+	}{
+		{name: "empty", specs: []string{""}, expected: map[string]string{}},
+		{name: "dsn_with_query", specs: []string{"eu=postgres://gateway@eu-db/scoring?sslmode=require"},
+			expected: map[string]string{"eu": "postgres://gateway@eu-db/scoring?sslmode=require"}},
+		{name: "missing_target", specs: []string{"eu"}, expectedError: `invalid residency target "eu", expected REGION=target`},
+		{name: "home_region", specs: []string{"ru=postgres://ru-db"}, expectedError: `residency target "ru" repeats the home region`},
+		{name: "duplicate", specs: []string{"eu=a", "eu=b"}, expectedError: `duplicate residency target for region "eu"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := ParseTargets("ru", tt.specs)
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Errorf("expected error %q, but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(targets) != len(tt.expected) {
+				t.Fatalf("expected %v, but got %v", tt.expected, targets)
+			}
+			for region, target := range tt.expected {
+				if targets[region] != target {
+					t.Errorf("expected %s=%s, but got %s", region, target, targets[region])
+				}
+			}
+		})
+	}
+}
+
+// memoryAuditRepository журнал аудита одного региона
+type memoryAuditRepository struct {
+	events map[string][]model.AuditEventType
+}
+
+func (r *memoryAuditRepository) AddEvent(ctx context.Context, verificationID string, eventType model.AuditEventType, actor string, details map[string]any) error {
+	r.events[verificationID] = append(r.events[verificationID], eventType)
+	return nil
+}
+
+func (r *memoryAuditRepository) GetTrail(ctx context.Context, verificationID string) ([]*model.AuditTrailEntry, error) {
+	var trail []*model.AuditTrailEntry
+	for _, eventType := range r.events[verificationID] {
+		trail = append(trail, &model.AuditTrailEntry{EventType: eventType})
+	}
+	return trail, nil
+}
+
+func TestAuditRepositoryRouting(t *testing.T) {
+	router, _ := newTestRouter(t)
+	home := &memoryAuditRepository{events: make(map[string][]model.AuditEventType)}
+	eu := &memoryAuditRepository{events: make(map[string][]model.AuditEventType)}
+	repo := WrapAuditRepository(home, map[string]repository.AuditRepository{"eu": eu}, router)
+
+	euUser := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@eu.example", Verified: true})
+	if err := repo.AddEvent(euUser, "eu-verification", model.AuditEventTypeCreated, "analyst@eu.example", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.AddEvent(context.Background(), "eu-verification", model.AuditEventTypeStatusChanged, "", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(eu.events["eu-verification"]) != 2 || len(home.events) != 0 {
+		t.Errorf("expected events of the pinned verification in its region only, but got eu %v and home %v", eu.events, home.events)
+	}
+
+	ruUser := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@ru.example", Verified: true})
+	if _, err := repo.GetTrail(ruUser, "eu-verification"); !errors.Is(err, repository.ErrCrossRegion) {
+		t.Errorf("expected cross-region refusal, but got %v", err)
+	}
+}
+
+// memoryAmendmentRepository поправки одного региона
+type memoryAmendmentRepository struct {
+	unpublished []*repository.Amendment
+	published   []int64
+}
+
+func (r *memoryAmendmentRepository) ClaimUnpublished(ctx context.Context, claimUntil time.Time, limit int) ([]*repository.Amendment, error) {
+	claimed := r.unpublished
+	if len(claimed) > limit {
+		claimed = claimed[:limit]
+	}
+	return claimed, nil
+}
+
+func (r *memoryAmendmentRepository) MarkPublished(ctx context.Context, verificationID string, id int64) error {
+	r.published = append(r.published, id)
+	return nil
+}
+
+func (r *memoryAmendmentRepository) ListByVerification(ctx context.Context, verificationID string) ([]*repository.Amendment, error) {
+	return nil, nil
+}
+
+func TestAmendmentRepositoryRouting(t *testing.T) {
+	router, _ := newTestRouter(t)
+	// Поправки нумеруются в каждой базе отдельно, поэтому идентификаторы регионов совпадают
+	home := &memoryAmendmentRepository{unpublished: []*repository.Amendment{{ID: 1, VerificationID: "ru-verification"}, {ID: 2, VerificationID: "ru-verification"}}}
+	eu := &memoryAmendmentRepository{unpublished: []*repository.Amendment{{ID: 1, VerificationID: "eu-verification"}}}
+	repo := WrapAmendmentRepository(home, map[string]repository.AmendmentRepository{"eu": eu}, router)
+
+	claimed, err := repo.ClaimUnpublished(context.Background(), time.Now(), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(claimed) != 3 {
+		t.Errorf("expected amendments of both regions, but got %d", len(claimed))
+	}
+	if claimed, _ = repo.ClaimUnpublished(context.Background(), time.Now(), 1); len(claimed) != 1 {
+		t.Errorf("expected amendments limited to 1, but got %d", len(claimed))
+	}
+
+	if err := repo.MarkPublished(context.Background(), "eu-verification", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(eu.published) != 1 || len(home.published) != 0 {
+		t.Errorf("expected the amendment to be marked in its region, but got eu %v and home %v", eu.published, home.published)
+	}
+}
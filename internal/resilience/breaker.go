@@ -0,0 +1,131 @@
+// Package resilience защищает шлюз от деградации Postgres: повторяет запросы после временных
+// ошибок, прекращает обращения к недоступной базе и читает проверки из реплики и кэша.
+package resilience
+
+import (
+	"sync"
+	"time"
+
+	"scoring_api_gateway/internal/metrics"
+)
+
+// BreakerState состояние размыкателя цепи
+type BreakerState int
+
+const (
+	// BreakerClosed запросы идут в базу
+	BreakerClosed BreakerState = iota
+	// BreakerHalfOpen после паузы в базу пропускается один пробный запрос
+	BreakerHalfOpen
+	// BreakerOpen запросы отклоняются без обращения к базе
+	BreakerOpen
+)
+
+// Breaker размыкается, когда доля ошибок подключения за окно достигает порога, и отклоняет
+// запросы openFor. Затем пропускает один пробный запрос: успех замыкает размыкатель цепи,
+// ошибка размыкает снова.
+type Breaker struct {
+	window      time.Duration
+	minRequests int
+	failureRate float64
+	openFor     time.Duration
+
+	mu          sync.Mutex
+	state       BreakerState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probing     bool
+	now         func() time.Time
+}
+
+func NewBreaker(window time.Duration, minRequests int, failureRate float64, openFor time.Duration) *Breaker {
+	return &Breaker{
+		window:      window,
+		minRequests: minRequests,
+		failureRate: failureRate,
+		openFor:     openFor,
+		now:         time.Now,
+	}
+}
+
+// Allow сообщает, можно ли обратиться к базе. В полуоткрытом состоянии разрешает только
+// один запрос, пока не известен его результат.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.openFor {
+			return false
+		}
+		b.setState(BreakerHalfOpen)
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+// Record учитывает результат разрешенного запроса; failed - база недоступна
+func (b *Breaker) Record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	switch b.state {
+	case BreakerHalfOpen:
+		b.probing = false
+		if failed {
+			b.open(now)
+		} else {
+			b.setState(BreakerClosed)
+			b.reset(now)
+		}
+		return
+	case BreakerOpen:
+		// Результат запроса, начатого до размыкания
+		return
+	}
+
+	if now.Sub(b.windowStart) >= b.window {
+		b.reset(now)
+	}
+	b.requests++
+	if failed {
+		b.failures++
+	}
+	if b.requests >= b.minRequests && float64(b.failures) >= b.failureRate*float64(b.requests) {
+		b.open(now)
+	}
+}
+
+// State возвращает текущее состояние
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *Breaker) open(now time.Time) {
+	b.setState(BreakerOpen)
+	b.openedAt = now
+	b.reset(now)
+}
+
+func (b *Breaker) reset(now time.Time) {
+	b.windowStart = now
+	b.requests, b.failures = 0, 0
+}
+
+func (b *Breaker) setState(state BreakerState) {
+	b.state = state
+	metrics.DBBreakerState.Set(float64(state))
+}
@@ -0,0 +1,115 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrBreakerOpen запрос не отправлен в базу: размыкатель цепи разомкнут
+var ErrBreakerOpen = errors.New("database circuit breaker is open")
+
+// RetryPolicy повторы после временных ошибок с экспоненциальной задержкой и случайным разбросом
+type RetryPolicy struct {
+	// Attempts число повторов после первой попытки
+	Attempts  int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// delay задержка перед повтором attempt (с нуля): случайная в пределах base*2^attempt, но не больше MaxDelay
+func (p RetryPolicy) delay(attempt int, jitter func(n int64) int64) time.Duration {
+	limit := p.MaxDelay
+	if attempt < 30 {
+		if backoff := p.BaseDelay << attempt; backoff > 0 && backoff < limit {
+			limit = backoff
+		}
+	}
+	return time.Duration(jitter(int64(limit)) + 1)
+}
+
+// Guard выполняет запросы к базе через размыкатель цепи и с повторами
+type Guard struct {
+	breaker *Breaker
+	retry   RetryPolicy
+	jitter  func(n int64) int64
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// NewGuard создает защиту запросов; breaker nil - без размыкателя цепи
+func NewGuard(breaker *Breaker, retry RetryPolicy) *Guard {
+	return &Guard{
+		breaker: breaker,
+		retry:   retry,
+		jitter:  rand.Int64N,
+		sleep:   sleep,
+	}
+}
+
+// Allow возвращает ошибку вида repository.ErrUnavailable, если размыкатель цепи разомкнут
+func (g *Guard) Allow() error {
+	if g.breaker == nil || g.breaker.Allow() {
+		return nil
+	}
+	metrics.DBBreakerRejections.Inc()
+	return &repository.Error{Kind: repository.ErrUnavailable, Err: ErrBreakerOpen}
+}
+
+// Record учитывает результат запроса, разрешенного Allow. Отмена запроса клиентом
+// ошибкой базы не считается.
+func (g *Guard) Record(ctx context.Context, err error) {
+	if g.breaker == nil {
+		return
+	}
+	g.breaker.Record(repository.Unavailable(err) && !errors.Is(ctx.Err(), context.Canceled))
+}
+
+// Do выполняет запрос fn и повторяет его после временных ошибок. Чтение повторяется и после
+// обрыва соединения, изменение - только после ошибки сериализации или если запрос точно не
+// дошел до базы: иначе изменение могло примениться дважды.
+func (g *Guard) Do(ctx context.Context, operation string, write bool, fn func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		if err := g.Allow(); err != nil {
+			return err
+		}
+		err := fn(ctx)
+		g.Record(ctx, err)
+
+		if err == nil || attempt >= g.retry.Attempts || ctx.Err() != nil || !retryable(err, write) {
+			return err
+		}
+		metrics.DBRetries.WithLabelValues(operation).Inc()
+		if g.sleep(ctx, g.retry.delay(attempt, g.jitter)) != nil {
+			return err
+		}
+	}
+}
+
+func retryable(err error, write bool) bool {
+	if errors.Is(err, repository.ErrSerialization) || pgconn.SafeToRetry(err) {
+		return true
+	}
+	// Соединение не установлено, запрос не отправлен
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+	return !write && repository.Unavailable(err)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
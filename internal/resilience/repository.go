@@ -0,0 +1,286 @@
+package resilience
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/repository"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"go.uber.org/zap"
+)
+
+// ReadCache последние прочитанные проверки для ответа, пока недоступны и база, и реплика
+type ReadCache = expirable.LRU[string, *model.Verification]
+
+// NewReadCache создает кэш на size проверок, хранящихся не дольше ttl
+func NewReadCache(size int, ttl time.Duration) *ReadCache {
+	return expirable.NewLRU[string, *model.Verification](size, nil, ttl)
+}
+
+type guardedVerificationRepository struct {
+	primary repository.VerificationRepository
+	// replica и cache nil, если не заданы
+	replica repository.VerificationRepository
+	cache   *ReadCache
+	guard   *Guard
+	logger  *zap.Logger
+}
+
+// WrapVerificationRepository выполняет запросы репозитория проверок через guard. Чтения, которые
+// не удались из-за недоступности базы, повторяются в реплике, а чтения проверки по id - еще и
+// в кэше. Изменения при недоступной базе возвращают ошибку вида repository.ErrUnavailable.
+func WrapVerificationRepository(primary, replica repository.VerificationRepository, cache *ReadCache, guard *Guard, logger *zap.Logger) repository.VerificationRepository {
+	return &guardedVerificationRepository{
+		primary: primary,
+		replica: replica,
+		cache:   cache,
+		guard:   guard,
+		logger:  logger,
+	}
+}
+
+// read читает из базы, а при ее недоступности - из реплики. Ответ реплики без проверки
+// (ErrNotFound) не возвращается: реплика могла отстать.
+func read[T any](r *guardedVerificationRepository, ctx context.Context, operation string, fn func(repo repository.VerificationRepository) (T, error)) (T, error) {
+	var result T
+	err := r.guard.Do(ctx, operation, false, func(ctx context.Context) error {
+		var err error
+		result, err = fn(r.primary)
+		return err
+	})
+	if err == nil || r.replica == nil || !repository.Unavailable(err) {
+		return result, err
+	}
+
+	replicaResult, replicaErr := fn(r.replica)
+	if replicaErr != nil {
+		r.logger.Warn("failed to read from replica", zap.Error(replicaErr), zap.String("operation", operation))
+		return result, err
+	}
+	metrics.DBDegradedReads.WithLabelValues("replica").Inc()
+	return replicaResult, nil
+}
+
+func (r *guardedVerificationRepository) write(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	return r.guard.Do(ctx, operation, true, fn)
+}
+
+// readVerification читает проверку по id и запоминает ее в кэше, а при недоступности базы
+// и реплики отдает запомненную копию
+func (r *guardedVerificationRepository) readVerification(ctx context.Context, key string, operation string, fn func(repo repository.VerificationRepository) (*model.Verification, error)) (*model.Verification, error) {
+	verification, err := read(r, ctx, operation, fn)
+	if r.cache == nil {
+		return verification, err
+	}
+	if err == nil {
+		copied := *verification
+		r.cache.Add(key, &copied)
+		return verification, nil
+	}
+	if !repository.Unavailable(err) {
+		return nil, err
+	}
+	cached, ok := r.cache.Get(key)
+	if !ok {
+		return nil, err
+	}
+	metrics.DBDegradedReads.WithLabelValues("cache").Inc()
+	copied := *cached
+	return &copied, nil
+}
+
+// cacheKey ключ проверки с данными: nil - все типы данных, пустой список - без данных
+func cacheKey(id string, dataTypes []model.VerificationDataType) string {
+	if dataTypes == nil {
+		return id + "|*"
+	}
+	types := make([]string, len(dataTypes))
+	for i, dataType := range dataTypes {
+		types[i] = string(dataType)
+	}
+	slices.Sort(types)
+	return id + "|" + strings.Join(types, ",")
+}
+
+func (r *guardedVerificationRepository) GetByID(ctx context.Context, id string) (*model.Verification, error) {
+	return r.readVerification(ctx, id, "GetByID", func(repo repository.VerificationRepository) (*model.Verification, error) {
+		return repo.GetByID(ctx, id)
+	})
+}
+
+func (r *guardedVerificationRepository) GetByIDWithData(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.Verification, error) {
+	return r.readVerification(ctx, cacheKey(id, dataTypes), "GetByIDWithData", func(repo repository.VerificationRepository) (*model.Verification, error) {
+		return repo.GetByIDWithData(ctx, id, dataTypes)
+	})
+}
+
+func (r *guardedVerificationRepository) GetAll(ctx context.Context, filter repository.VerificationFilter, limit *int32, offset *int32) ([]*model.Verification, error) {
+	return read(r, ctx, "GetAll", func(repo repository.VerificationRepository) ([]*model.Verification, error) {
+		return repo.GetAll(ctx, filter, limit, offset)
+	})
+}
+
+// StreamAll не повторяется: часть проверок уже могла быть передана в fn. В реплике чтение
+// повторяется, только если ни одна проверка еще не передана.
+func (r *guardedVerificationRepository) StreamAll(ctx context.Context, filter repository.VerificationFilter, limit *int32, offset *int32, fn func(*model.Verification) error) error {
+	emitted := false
+	var fnErr error
+	stream := func(verification *model.Verification) error {
+		if err := fn(verification); err != nil {
+			fnErr = err
+			return err
+		}
+		emitted = true
+		return nil
+	}
+
+	err := r.guard.Allow()
+	if err == nil {
+		err = r.primary.StreamAll(ctx, filter, limit, offset, stream)
+		if fnErr != nil {
+			// Ошибка получателя ничего не говорит о базе
+			r.guard.Record(ctx, nil)
+			return err
+		}
+		r.guard.Record(ctx, err)
+	}
+	if err == nil || emitted || r.replica == nil || !repository.Unavailable(err) {
+		return err
+	}
+
+	metrics.DBDegradedReads.WithLabelValues("replica").Inc()
+	return r.replica.StreamAll(ctx, filter, limit, offset, fn)
+}
+
+func (r *guardedVerificationRepository) GetPrevious(ctx context.Context, id string) (*model.Verification, error) {
+	return read(r, ctx, "GetPrevious", func(repo repository.VerificationRepository) (*model.Verification, error) {
+		return repo.GetPrevious(ctx, id)
+	})
+}
+
+func (r *guardedVerificationRepository) GetAuthorsByINN(ctx context.Context, inn string) ([]string, error) {
+	return read(r, ctx, "GetAuthorsByINN", func(repo repository.VerificationRepository) ([]string, error) {
+		return repo.GetAuthorsByINN(ctx, inn)
+	})
+}
+
+func (r *guardedVerificationRepository) Create(ctx context.Context, verification *domain.Verification) error {
+	return r.write(ctx, "Create", func(ctx context.Context) error {
+		return r.primary.Create(ctx, verification)
+	})
+}
+
+func (r *guardedVerificationRepository) CreateWithRequest(ctx context.Context, verification *domain.Verification, msg *messaging.OutboxMessage, claimedUntil time.Time) (bool, error) {
+	var written bool
+	err := r.write(ctx, "CreateWithRequest", func(ctx context.Context) error {
+		var err error
+		written, err = r.primary.CreateWithRequest(ctx, verification, msg, claimedUntil)
+		return err
+	})
+	return written, err
+}
+
+func (r *guardedVerificationRepository) UpdateRiskLevel(ctx context.Context, id string, riskLevel model.RiskLevel) error {
+	return r.write(ctx, "UpdateRiskLevel", func(ctx context.Context) error {
+		return r.primary.UpdateRiskLevel(ctx, id, riskLevel)
+	})
+}
+
+func (r *guardedVerificationRepository) UpdateStatus(ctx context.Context, id string, status model.VerificationStatus, errorMessage *string, changedAt time.Time) error {
+	return r.write(ctx, "UpdateStatus", func(ctx context.Context) error {
+		return r.primary.UpdateStatus(ctx, id, status, errorMessage, changedAt)
+	})
+}
+
+func (r *guardedVerificationRepository) GetMonitoringCandidates(ctx context.Context, olderThan time.Time, limit int) ([]*repository.MonitoringCandidate, error) {
+	// Кандидаты мониторинга нужны задаче, которая затем меняет проверки, поэтому реплика не используется
+	var candidates []*repository.MonitoringCandidate
+	err := r.guard.Do(ctx, "GetMonitoringCandidates", false, func(ctx context.Context) error {
+		var err error
+		candidates, err = r.primary.GetMonitoringCandidates(ctx, olderThan, limit)
+		return err
+	})
+	return candidates, err
+}
+
+func (r *guardedVerificationRepository) SetExternalRef(ctx context.Context, id string, ref *model.ExternalRef) error {
+	return r.write(ctx, "SetExternalRef", func(ctx context.Context) error {
+		return r.primary.SetExternalRef(ctx, id, ref)
+	})
+}
+
+func (r *guardedVerificationRepository) SetMetadata(ctx context.Context, id string, metadata map[string]string) error {
+	return r.write(ctx, "SetMetadata", func(ctx context.Context) error {
+		return r.primary.SetMetadata(ctx, id, metadata)
+	})
+}
+
+func (r *guardedVerificationRepository) UpdateMetadata(ctx context.Context, id string, update func(current map[string]string) (map[string]string, error)) (map[string]string, error) {
+	var metadata map[string]string
+	err := r.write(ctx, "UpdateMetadata", func(ctx context.Context) error {
+		var err error
+		metadata, err = r.primary.UpdateMetadata(ctx, id, update)
+		return err
+	})
+	return metadata, err
+}
+
+func (r *guardedVerificationRepository) GetIDByExternalRef(ctx context.Context, system *string, ref string) (string, error) {
+	return read(r, ctx, "GetIDByExternalRef", func(repo repository.VerificationRepository) (string, error) {
+		return repo.GetIDByExternalRef(ctx, system, ref)
+	})
+}
+
+func (r *guardedVerificationRepository) GetSnapshot(ctx context.Context, inn string, asOf time.Time) (*model.Verification, error) {
+	return read(r, ctx, "GetSnapshot", func(repo repository.VerificationRepository) (*model.Verification, error) {
+		return repo.GetSnapshot(ctx, inn, asOf)
+	})
+}
+
+func (r *guardedVerificationRepository) GetLatestStatuses(ctx context.Context, inns []string) ([]*model.CompanyVerificationStatus, error) {
+	return read(r, ctx, "GetLatestStatuses", func(repo repository.VerificationRepository) ([]*model.CompanyVerificationStatus, error) {
+		return repo.GetLatestStatuses(ctx, inns)
+	})
+}
+
+func (r *guardedVerificationRepository) GetLatestSince(ctx context.Context, inn string, since time.Time) (*model.RecentVerification, error) {
+	return read(r, ctx, "GetLatestSince", func(repo repository.VerificationRepository) (*model.RecentVerification, error) {
+		return repo.GetLatestSince(ctx, inn, since)
+	})
+}
+
+func (r *guardedVerificationRepository) SetMissingDataTypes(ctx context.Context, id string, missing []model.VerificationDataType) error {
+	return r.write(ctx, "SetMissingDataTypes", func(ctx context.Context) error {
+		return r.primary.SetMissingDataTypes(ctx, id, missing)
+	})
+}
+
+func (r *guardedVerificationRepository) GetMissingDataRetryCandidates(ctx context.Context, updatedBefore time.Time, maxRetries int, limit int) ([]*model.Verification, error) {
+	// Как и кандидаты мониторинга, читаются только из основной базы
+	var candidates []*model.Verification
+	err := r.guard.Do(ctx, "GetMissingDataRetryCandidates", false, func(ctx context.Context) error {
+		var err error
+		candidates, err = r.primary.GetMissingDataRetryCandidates(ctx, updatedBefore, maxRetries, limit)
+		return err
+	})
+	return candidates, err
+}
+
+func (r *guardedVerificationRepository) MarkMissingDataRetried(ctx context.Context, id string) error {
+	return r.write(ctx, "MarkMissingDataRetried", func(ctx context.Context) error {
+		return r.primary.MarkMissingDataRetried(ctx, id)
+	})
+}
+
+func (r *guardedVerificationRepository) SetDataValidation(ctx context.Context, id string, dataType model.VerificationDataType, validationErrors []string) error {
+	return r.write(ctx, "SetDataValidation", func(ctx context.Context) error {
+		return r.primary.SetDataValidation(ctx, id, dataType, validationErrors)
+	})
+}
@@ -0,0 +1,187 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap/zaptest"
+)
+
+var (
+	errConnectionLost = fmt.Errorf("failed to get verification: %w", io.ErrUnexpectedEOF)
+	errSerialization  = &repository.Error{Kind: repository.ErrSerialization, Err: &pgconn.PgError{Code: "40001"}}
+)
+
+// notSentError ошибка драйвера, после которой запрос точно не дошел до базы
+type notSentError struct{}
+
+func (notSentError) Error() string     { return "conn busy" }
+func (notSentError) SafeToRetry() bool { return true }
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	breaker := NewBreaker(10*time.Second, 4, 0.5, 5*time.Second)
+	breaker.now = func() time.Time { return now }
+
+	for _, failed := range []bool{false, true, false} {
+		breaker.Record(failed)
+	}
+	if breaker.State() != BreakerClosed {
+		t.Fatal("expected the breaker to stay closed below min requests")
+	}
+	breaker.Record(true)
+	if breaker.State() != BreakerOpen || breaker.Allow() {
+		t.Fatal("expected the breaker to open at the failure rate")
+	}
+
+	now = now.Add(5 * time.Second)
+	if !breaker.Allow() || breaker.State() != BreakerHalfOpen {
+		t.Fatal("expected a probe after the open period")
+	}
+	if breaker.Allow() {
+		t.Fatal("expected only one probe while half-open")
+	}
+	breaker.Record(true)
+	if breaker.State() != BreakerOpen {
+		t.Fatal("expected a failed probe to open the breaker again")
+	}
+
+	now = now.Add(5 * time.Second)
+	breaker.Allow()
+	breaker.Record(false)
+	if breaker.State() != BreakerClosed || !breaker.Allow() {
+		t.Fatal("expected a successful probe to close the breaker")
+	}
+
+	// Ошибки прошлого окна не учитываются
+	for i := 0; i < 3; i++ {
+		breaker.Record(true)
+	}
+	now = now.Add(10 * time.Second)
+	breaker.Record(true)
+	if breaker.State() != BreakerClosed {
+		t.Error("expected the failure count to reset with the window")
+	}
+}
+
+func newTestGuard(breaker *Breaker, attempts int) *Guard {
+	guard := NewGuard(breaker, RetryPolicy{Attempts: attempts, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond})
+	guard.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+	return guard
+}
+
+func TestGuardRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		write    bool
+		err      error
+		expected int
+	}{
+		{name: "read_connection_lost", err: errConnectionLost, expected: 3},
+		{name: "write_connection_lost", write: true, err: errConnectionLost, expected: 1},
+		{name: "write_serialization", write: true, err: errSerialization, expected: 3},
+		{name: "write_not_sent", write: true, err: notSentError{}, expected: 3},
+		{name: "not_found", err: &repository.Error{Kind: repository.ErrNotFound, Err: errors.New("verification not found")}, expected: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := newTestGuard(nil, 2).Do(context.Background(), "test", tt.write, func(ctx context.Context) error {
+				calls++
+				return tt.err
+			})
+			if calls != tt.expected || !errors.Is(err, tt.err) {
+				t.Errorf("expected %d calls and the original error, but got %d and %v", tt.expected, calls, err)
+			}
+		})
+	}
+
+	calls := 0
+	err := newTestGuard(nil, 2).Do(context.Background(), "test", false, func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return errConnectionLost
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("expected the retry to succeed, but got %v after %d calls", err, calls)
+	}
+}
+
+func TestGuardShedsLoadWhenOpen(t *testing.T) {
+	breaker := NewBreaker(time.Minute, 2, 0.5, time.Minute)
+	guard := newTestGuard(breaker, 0)
+
+	calls := 0
+	fail := func(ctx context.Context) error {
+		calls++
+		return errConnectionLost
+	}
+	guard.Do(context.Background(), "test", false, fail)
+	guard.Do(context.Background(), "test", false, fail)
+
+	err := guard.Do(context.Background(), "test", false, fail)
+	if calls != 2 || !errors.Is(err, ErrBreakerOpen) || !errors.Is(err, repository.ErrUnavailable) {
+		t.Errorf("expected the call to be rejected without reaching the database, but got %v after %d calls", err, calls)
+	}
+}
+
+type stubVerificationRepository struct {
+	repository.VerificationRepository
+	verification *model.Verification
+	err          error
+	calls        int
+}
+
+func (r *stubVerificationRepository) GetByID(ctx context.Context, id string) (*model.Verification, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.verification, nil
+}
+
+func (r *stubVerificationRepository) UpdateRiskLevel(ctx context.Context, id string, riskLevel model.RiskLevel) error {
+	r.calls++
+	return r.err
+}
+
+func TestRepositoryDegradedReads(t *testing.T) {
+	primary := &stubVerificationRepository{verification: &model.Verification{ID: "v-1", Status: model.VerificationStatusInProcess}}
+	replica := &stubVerificationRepository{verification: &model.Verification{ID: "v-1", Status: model.VerificationStatusCompleted}}
+	cache := NewReadCache(10, time.Minute)
+	repo := WrapVerificationRepository(primary, replica, cache, newTestGuard(nil, 0), zaptest.NewLogger(t))
+	ctx := context.Background()
+
+	if verification, err := repo.GetByID(ctx, "v-1"); err != nil || verification.Status != model.VerificationStatusInProcess {
+		t.Fatalf("expected the primary database to be read, but got %+v, %v", verification, err)
+	}
+
+	primary.err = errConnectionLost
+	if verification, err := repo.GetByID(ctx, "v-1"); err != nil || verification.Status != model.VerificationStatusCompleted {
+		t.Fatalf("expected the replica to be read, but got %+v, %v", verification, err)
+	}
+
+	replica.err = errConnectionLost
+	if verification, err := repo.GetByID(ctx, "v-1"); err != nil || verification.Status != model.VerificationStatusCompleted {
+		t.Fatalf("expected the cached verification, but got %+v, %v", verification, err)
+	}
+	if _, err := repo.GetByID(ctx, "v-2"); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected the primary error for an uncached verification, but got %v", err)
+	}
+
+	// Изменения не уходят в реплику
+	replica.calls = 0
+	if err := repo.UpdateRiskLevel(ctx, "v-1", model.RiskLevelHigh); !errors.Is(err, io.ErrUnexpectedEOF) || replica.calls != 0 {
+		t.Errorf("expected the write to fail without the replica, but got %v and %d replica calls", err, replica.calls)
+	}
+}
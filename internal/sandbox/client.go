@@ -0,0 +1,102 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+
+	"go.uber.org/zap"
+)
+
+// Store хранилище проверок песочницы
+type Store interface {
+	IsSandboxTenant(ctx context.Context, tenant string) (bool, error)
+	SaveCompleted(ctx context.Context, verification *domain.Verification) error
+}
+
+type sandboxClient struct {
+	messaging.NATSClient
+	store     Store
+	generator *Generator
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewClient перехватывает запросы ключей и организаций песочницы: вместо публикации воркерам
+// проверка сразу сохраняется завершенной с синтетическими данными. Остальные запросы
+// передаются client без изменений.
+func NewClient(client messaging.NATSClient, store Store, logger *zap.Logger) messaging.NATSClient {
+	return &sandboxClient{
+		NATSClient: client,
+		store:      store,
+		generator:  NewGenerator(),
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+func (c *sandboxClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
+	return c.PublishVerificationRequestWithPriority(ctx, verification, messaging.PriorityNormal)
+}
+
+func (c *sandboxClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	sandbox, err := c.isSandbox(ctx, verification)
+	if err != nil {
+		return err
+	}
+	if !sandbox {
+		return c.NATSClient.PublishVerificationRequestWithPriority(ctx, verification, priority)
+	}
+
+	if err := c.complete(verification); err != nil {
+		return err
+	}
+	if err := c.store.SaveCompleted(ctx, verification); err != nil {
+		return err
+	}
+
+	c.logger.Info("sandbox verification completed with synthetic data",
+		zap.String("verification_id", verification.ID),
+		zap.String("inn", verification.INN))
+	return nil
+}
+
+// isSandbox сообщает, обслуживается ли запрос песочницей: по ключу API запроса или по организации автора
+func (c *sandboxClient) isSandbox(ctx context.Context, verification *domain.Verification) (bool, error) {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && principal.Sandbox {
+		return true, nil
+	}
+	sandbox, err := c.store.IsSandboxTenant(ctx, messaging.TenantOf(verification.AuthorEmail))
+	if err != nil {
+		return false, fmt.Errorf("failed to check sandbox tenant: %w", err)
+	}
+	return sandbox, nil
+}
+
+// complete заполняет проверку синтетическими данными и переводит ее в COMPLETED
+func (c *sandboxClient) complete(verification *domain.Verification) error {
+	now := c.now().UTC().Truncate(time.Second)
+
+	data := make([]*domain.Data, 0, len(verification.RequestedDataTypes))
+	for _, dataType := range verification.RequestedDataTypes {
+		payload, err := c.generator.Generate(verification.INN, dataType)
+		if err != nil {
+			return err
+		}
+		data = append(data, &domain.Data{DataType: dataType, Payload: payload, CreatedAt: now})
+	}
+
+	riskLevel := c.generator.RiskLevel(verification.INN)
+	verification.Status = domain.StatusCompleted
+	verification.RiskLevel = &riskLevel
+	verification.Sandbox = true
+	verification.MissingDataTypes = []domain.DataType{}
+	verification.Data = data
+	verification.CreatedAt = now
+	verification.UpdatedAt = now
+	return nil
+}
@@ -0,0 +1,141 @@
+package sandbox
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/internal/domain"
+)
+
+// Синтетические данные строятся из генератора, инициализированного хэшем ИНН и типа данных,
+// поэтому один и тот же ИНН всегда получает одни и те же данные. Каждый документ помечен
+// полем "sandbox": true и проходит проверку JSON Schema своего типа.
+
+var (
+	companyNames = []string{"Ромашка", "Вектор", "Северный ветер", "Техностандарт", "Горизонт", "Альфа-Логистик", "Меридиан", "Светоч"}
+	legalForms   = []string{"ООО", "АО", "ПАО"}
+	lastNames    = []string{"Иванов", "Петров", "Сидоров", "Кузнецов", "Смирнов", "Попов"}
+	firstNames   = []string{"Иван", "Петр", "Алексей", "Сергей", "Дмитрий", "Андрей"}
+	middleNames  = []string{"Иванович", "Петрович", "Алексеевич", "Сергеевич", "Дмитриевич", "Андреевич"}
+	cities       = []string{"Москва", "Санкт-Петербург", "Казань", "Новосибирск", "Екатеринбург", "Нижний Новгород"}
+	streets      = []string{"Ленина", "Мира", "Садовая", "Центральная", "Молодежная", "Школьная"}
+)
+
+var activities = []struct {
+	code string
+	name string
+}{
+	{"62.01", "Разработка компьютерного программного обеспечения"},
+	{"46.90", "Торговля оптовая неспециализированная"},
+	{"49.41", "Деятельность автомобильного грузового транспорта"},
+	{"41.20", "Строительство жилых и нежилых зданий"},
+	{"70.22", "Консультирование по вопросам коммерческой деятельности и управления"},
+	{"47.11", "Торговля розничная в неспециализированных магазинах"},
+}
+
+// Generator строит синтетические данные проверки по ИНН
+type Generator struct{}
+
+func NewGenerator() *Generator {
+	return &Generator{}
+}
+
+// newRand возвращает генератор, детерминированный для пары ИНН и ключа
+func newRand(inn, key string) *rand.Rand {
+	sum := sha256.Sum256([]byte(inn + ":" + key))
+	return rand.New(rand.NewPCG(binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:16])))
+}
+
+func pick[T any](r *rand.Rand, items []T) T {
+	return items[r.IntN(len(items))]
+}
+
+func digits(r *rand.Rand, n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteByte(byte('0' + r.IntN(10)))
+	}
+	return b.String()
+}
+
+func companyName(r *rand.Rand) string {
+	return fmt.Sprintf("%s \"%s\"", pick(r, legalForms), pick(r, companyNames))
+}
+
+// RiskLevel уровень риска компании песочницы
+func (g *Generator) RiskLevel(inn string) domain.RiskLevel {
+	return pick(newRand(inn, "RISK_LEVEL"), domain.AllRiskLevels)
+}
+
+// Generate возвращает JSON-документ данных типа dataType для ИНН
+func (g *Generator) Generate(inn string, dataType domain.DataType) (string, error) {
+	r := newRand(inn, string(dataType))
+
+	var payload map[string]any
+	switch dataType {
+	case domain.DataTypeBasicInformation:
+		// ОГРНИП у индивидуальных предпринимателей на две цифры длиннее ОГРН
+		ogrn := digits(r, 13)
+		if len(inn) == 12 {
+			ogrn = digits(r, 15)
+		}
+		registered := time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, r.IntN(10000))
+		payload = map[string]any{
+			"name":              companyName(r),
+			"inn":               inn,
+			"ogrn":              ogrn,
+			"registrationDate":  registered.Format(time.DateOnly),
+			"director":          fmt.Sprintf("%s %s %s", pick(r, lastNames), pick(r, firstNames), pick(r, middleNames)),
+			"authorizedCapital": float64(10000 * (1 + r.IntN(1000))),
+		}
+	case domain.DataTypeActivities:
+		items := make([]map[string]any, 0, 3)
+		for i, j := range r.Perm(len(activities))[:1+r.IntN(3)] {
+			items = append(items, map[string]any{
+				"code": activities[j].code,
+				"name": activities[j].name,
+				"main": i == 0,
+			})
+		}
+		payload = map[string]any{"activities": items}
+	case domain.DataTypeAddressesByCredinform, domain.DataTypeAddressesByUnifiedStateRegister:
+		payload = map[string]any{"addresses": []map[string]any{{
+			"postalCode": digits(r, 6),
+			"city":       pick(r, cities),
+			"street":     pick(r, streets),
+			"house":      fmt.Sprint(1 + r.IntN(150)),
+		}}}
+	case domain.DataTypeAffiliatedCompanies:
+		companies := make([]map[string]any, 0, 3)
+		for i := r.IntN(4); i > 0; i-- {
+			companies = append(companies, map[string]any{
+				"inn":   digits(r, 10),
+				"name":  companyName(r),
+				"share": float64(1 + r.IntN(100)),
+			})
+		}
+		payload = map[string]any{"companies": companies}
+	case domain.DataTypeArbitrageStatistics:
+		plaintiff, defendant := r.IntN(30), r.IntN(30)
+		payload = map[string]any{
+			"totalCases":  plaintiff + defendant,
+			"asPlaintiff": plaintiff,
+			"asDefendant": defendant,
+			"totalAmount": float64(r.IntN(100000000)),
+		}
+	default:
+		return "", fmt.Errorf("unknown data type %s", dataType)
+	}
+	payload["sandbox"] = true
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal sandbox data: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,138 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/validation"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestGenerate(t *testing.T) {
+	validator, err := validation.NewValidator()
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+	generator := NewGenerator()
+
+	for _, inn := range []string{"7707083893", "500100732259"} {
+		for _, dataType := range domain.AllDataTypes {
+			payload, err := generator.Generate(inn, dataType)
+			if err != nil {
+				t.Fatalf("Generate(%s, %s) error: %v", inn, dataType, err)
+			}
+
+			again, _ := generator.Generate(inn, dataType)
+			if again != payload {
+				t.Errorf("expected %s for %s to be deterministic", dataType, inn)
+			}
+
+			var doc map[string]any
+			if err := json.Unmarshal([]byte(payload), &doc); err != nil {
+				t.Fatalf("invalid JSON for %s: %v", dataType, err)
+			}
+			if doc["sandbox"] != true {
+				t.Errorf("expected %s to be marked sandbox, but got %s", dataType, payload)
+			}
+			if result := validator.Validate(model.VerificationDataType(dataType), payload); !result.Valid {
+				t.Errorf("expected %s for %s to match schema, but got %v", dataType, inn, result.Errors)
+			}
+		}
+	}
+
+	first, _ := generator.Generate("7707083893", domain.DataTypeBasicInformation)
+	other, _ := generator.Generate("7736050003", domain.DataTypeBasicInformation)
+	if first == other {
+		t.Error("expected different INNs to get different data")
+	}
+	if generator.RiskLevel("7707083893") != generator.RiskLevel("7707083893") {
+		t.Error("expected risk level to be deterministic")
+	}
+}
+
+type recordingClient struct {
+	messaging.NATSClient
+	published []string
+}
+
+func (c *recordingClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	c.published = append(c.published, verification.ID)
+	return nil
+}
+
+type memoryStore struct {
+	tenants map[string]bool
+	saved   []*domain.Verification
+}
+
+func (s *memoryStore) IsSandboxTenant(ctx context.Context, tenant string) (bool, error) {
+	return s.tenants[tenant], nil
+}
+
+func (s *memoryStore) SaveCompleted(ctx context.Context, verification *domain.Verification) error {
+	s.saved = append(s.saved, verification)
+	return nil
+}
+
+func TestClient(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	types := []domain.DataType{domain.DataTypeBasicInformation, domain.DataTypeArbitrageStatistics}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		email     string
+		sandbox   bool
+	}{
+		{name: "regular_user", email: "analyst@bank.ru"},
+		{name: "sandbox_organization", email: "dev@sandbox.bank.ru", sandbox: true},
+		{name: "sandbox_api_key", principal: &auth.Principal{Email: "svc@bank.ru", Sandbox: true}, email: "svc@bank.ru", sandbox: true},
+		{name: "regular_api_key", principal: &auth.Principal{Email: "svc@bank.ru"}, email: "svc@bank.ru"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &recordingClient{}
+			store := &memoryStore{tenants: map[string]bool{"sandbox.bank.ru": true}}
+			client := NewClient(inner, store, zaptest.NewLogger(t)).(*sandboxClient)
+			client.now = func() time.Time { return now }
+
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = auth.WithPrincipal(ctx, tt.principal)
+			}
+
+			verification := &domain.Verification{ID: "v1", INN: "7707083893", Status: domain.StatusInProcess, AuthorEmail: tt.email, RequestedDataTypes: types}
+			if err := client.PublishVerificationRequest(ctx, verification); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !tt.sandbox {
+				if len(inner.published) != 1 || len(store.saved) != 0 || verification.Sandbox {
+					t.Errorf("expected request to be published to workers, published %v, saved %d", inner.published, len(store.saved))
+				}
+				return
+			}
+
+			if len(inner.published) != 0 {
+				t.Errorf("expected sandbox request not to reach workers, but published %v", inner.published)
+			}
+			if len(store.saved) != 1 {
+				t.Fatalf("expected sandbox verification to be saved, but got %d", len(store.saved))
+			}
+			if !verification.Sandbox || verification.Status != domain.StatusCompleted || verification.RiskLevel == nil {
+				t.Errorf("expected completed sandbox verification, but got %+v", verification)
+			}
+			if len(verification.Data) != len(types) || !verification.CreatedAt.Equal(now) {
+				t.Errorf("expected %d data items created at %s, but got %d at %s", len(types), now.Format(time.RFC3339), len(verification.Data), verification.CreatedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+// Package schemaguard сравнивает GraphQL-схему с опубликованным снимком и находит изменения,
+// ломающие существующих клиентов.
+package schemaguard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// BreakingChange изменение схемы, после которого существующие запросы клиентов могут перестать работать
+type BreakingChange struct {
+	Path    string
+	Message string
+}
+
+func (c BreakingChange) String() string {
+	return c.Path + ": " + c.Message
+}
+
+// Diff возвращает ломающие изменения схемы current относительно published
+func Diff(published, current *ast.Schema) []BreakingChange {
+	var changes []BreakingChange
+	add := func(path, format string, args ...any) {
+		changes = append(changes, BreakingChange{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, name := range sortedTypeNames(published) {
+		oldDef := published.Types[name]
+		if oldDef.BuiltIn {
+			continue
+		}
+
+		newDef, ok := current.Types[name]
+		if !ok {
+			add(name, "type removed")
+			continue
+		}
+		if newDef.Kind != oldDef.Kind {
+			add(name, "kind changed from %s to %s", oldDef.Kind, newDef.Kind)
+			continue
+		}
+
+		switch oldDef.Kind {
+		case ast.Object, ast.Interface:
+			diffOutputFields(name, oldDef, newDef, add)
+		case ast.InputObject:
+			diffInputFields(name, oldDef, newDef, add)
+		case ast.Enum:
+			for _, value := range oldDef.EnumValues {
+				if newDef.EnumValues.ForName(value.Name) == nil {
+					add(name+"."+value.Name, "enum value removed")
+				}
+			}
+		case ast.Union:
+			for _, member := range oldDef.Types {
+				if !contains(newDef.Types, member) {
+					add(name, "union member %s removed", member)
+				}
+			}
+		}
+	}
+
+	return changes
+}
+
+func diffOutputFields(typeName string, oldDef, newDef *ast.Definition, add func(path, format string, args ...any)) {
+	for _, oldField := range oldDef.Fields {
+		if strings.HasPrefix(oldField.Name, "__") {
+			continue
+		}
+		path := typeName + "." + oldField.Name
+
+		newField := newDef.Fields.ForName(oldField.Name)
+		if newField == nil {
+			add(path, "field removed")
+			continue
+		}
+		// Выходное поле может стать строже (nullable -> non-null), но не наоборот
+		if !outputTypeCompatible(oldField.Type, newField.Type) {
+			add(path, "type changed from %s to %s", oldField.Type, newField.Type)
+		}
+
+		for _, oldArg := range oldField.Arguments {
+			newArg := newField.Arguments.ForName(oldArg.Name)
+			if newArg == nil {
+				add(path+"("+oldArg.Name+")", "argument removed")
+				continue
+			}
+			if !inputTypeCompatible(oldArg.Type, newArg.Type) {
+				add(path+"("+oldArg.Name+")", "type changed from %s to %s", oldArg.Type, newArg.Type)
+			}
+		}
+		for _, newArg := range newField.Arguments {
+			if oldField.Arguments.ForName(newArg.Name) == nil && newArg.Type.NonNull && newArg.DefaultValue == nil {
+				add(path+"("+newArg.Name+")", "required argument added")
+			}
+		}
+	}
+}
+
+func diffInputFields(typeName string, oldDef, newDef *ast.Definition, add func(path, format string, args ...any)) {
+	for _, oldField := range oldDef.Fields {
+		path := typeName + "." + oldField.Name
+
+		newField := newDef.Fields.ForName(oldField.Name)
+		if newField == nil {
+			add(path, "input field removed")
+			continue
+		}
+		if !inputTypeCompatible(oldField.Type, newField.Type) {
+			add(path, "type changed from %s to %s", oldField.Type, newField.Type)
+		}
+	}
+	for _, newField := range newDef.Fields {
+		if oldDef.Fields.ForName(newField.Name) == nil && newField.Type.NonNull && newField.DefaultValue == nil {
+			add(typeName+"."+newField.Name, "required input field added")
+		}
+	}
+}
+
+// outputTypeCompatible разрешает только ужесточение nullability на любом уровне вложенности
+func outputTypeCompatible(oldType, newType *ast.Type) bool {
+	if oldType.NonNull && !newType.NonNull {
+		return false
+	}
+	if (oldType.Elem == nil) != (newType.Elem == nil) {
+		return false
+	}
+	if oldType.Elem != nil {
+		return outputTypeCompatible(oldType.Elem, newType.Elem)
+	}
+	return oldType.NamedType == newType.NamedType
+}
+
+// inputTypeCompatible разрешает только ослабление nullability на любом уровне вложенности
+func inputTypeCompatible(oldType, newType *ast.Type) bool {
+	return outputTypeCompatible(newType, oldType)
+}
+
+func sortedTypeNames(schema *ast.Schema) []string {
+	names := make([]string, 0, len(schema.Types))
+	for name := range schema.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package schemaguard
+
+import (
+	"testing"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const publishedSchema = `
+enum Status { NEW DONE }
+
+input Filter { status: Status, inn: String }
+
+type Item {
+  id: ID!
+  name: String
+  status: Status!
+}
+
+type Query {
+  item(id: ID!): Item
+  items(filter: Filter, limit: Int): [Item!]!
+}
+`
+
+func loadSchema(t *testing.T, input string) *ast.Schema {
+	t.Helper()
+	schema, err := gqlparser.LoadSchema(&ast.Source{Input: input})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+	return schema
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name          string
+		current       string
+		expectedPaths []string
+	}{
+		{
+			name:    "no_changes",
+			current: publishedSchema,
+		},
+		{
+			name: "additive_changes",
+			current: `
+enum Status { NEW DONE FAILED }
+input Filter { status: Status, inn: String, author: String }
+type Item { id: ID!, name: String!, status: Status!, createdAt: String }
+type Query {
+  item(id: ID!): Item
+  items(filter: Filter, limit: Int, offset: Int): [Item!]!
+  count: Int!
+}
+`,
+		},
+		{
+			name: "breaking_changes",
+			current: `
+enum Status { NEW }
+input Filter { status: Status!, author: String! }
+type Item { id: ID, status: Status! }
+type Query {
+  item(id: ID!, version: Int!): Item
+  items(filter: Filter): [Item!]!
+}
+`,
+			expectedPaths: []string{
+				"Filter.status",
+				"Filter.inn",
+				"Filter.author",
+				"Item.id",
+				"Item.name",
+				"Query.item(version)",
+				"Query.items(limit)",
+				"Status.DONE",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := Diff(loadSchema(t, publishedSchema), loadSchema(t, tt.current))
+
+			found := make(map[string]bool)
+			for _, change := range changes {
+				found[change.Path] = true
+			}
+			for _, path := range tt.expectedPaths {
+				if !found[path] {
+					t.Errorf("expected breaking change at %s, got %v", path, changes)
+				}
+			}
+			if len(changes) != len(tt.expectedPaths) {
+				t.Errorf("expected %d breaking changes, but got %d: %v", len(tt.expectedPaths), len(changes), changes)
+			}
+		})
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	schema := loadSchema(t, publishedSchema)
+
+	snapshot, err := ParseSnapshot(FormatSnapshot(3, schema))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snapshot.Version != 3 {
+		t.Errorf("expected version 3, but got %d", snapshot.Version)
+	}
+
+	result := Check(snapshot, 3, schema)
+	if len(result.Changes) != 0 || result.Blocking() {
+		t.Errorf("expected no changes after round trip, got %v", result.Changes)
+	}
+
+	if _, err := ParseSnapshot("type Query { a: Int }"); err == nil {
+		t.Error("expected error for snapshot without version header, but got nil")
+	}
+}
+
+func TestResultBlocking(t *testing.T) {
+	changes := []BreakingChange{{Path: "Query.item", Message: "field removed"}}
+
+	if !(Result{SnapshotVersion: 1, CurrentVersion: 1, Changes: changes}).Blocking() {
+		t.Error("expected breaking change without version bump to block")
+	}
+	if (Result{SnapshotVersion: 1, CurrentVersion: 2, Changes: changes}).Blocking() {
+		t.Error("expected breaking change with version bump not to block")
+	}
+}
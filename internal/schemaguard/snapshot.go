@@ -0,0 +1,70 @@
+package schemaguard
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+)
+
+// versionHeader первая строка снимка с версией опубликованной схемы
+const versionHeader = "# schema-version: "
+
+// Snapshot опубликованная схема и ее версия
+type Snapshot struct {
+	Version int
+	Schema  *ast.Schema
+}
+
+// ParseSnapshot разбирает снимок схемы с заголовком версии
+func ParseSnapshot(content string) (*Snapshot, error) {
+	firstLine, _, _ := strings.Cut(content, "\n")
+	if !strings.HasPrefix(firstLine, versionHeader) {
+		return nil, fmt.Errorf("schema snapshot must start with %q", versionHeader)
+	}
+
+	version, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(firstLine, versionHeader)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema snapshot version: %w", err)
+	}
+
+	schema, err := gqlparser.LoadSchema(&ast.Source{Name: "snapshot.graphql", Input: content})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema snapshot: %w", err)
+	}
+
+	return &Snapshot{Version: version, Schema: schema}, nil
+}
+
+// FormatSnapshot сериализует схему в снимок с заголовком версии
+func FormatSnapshot(version int, schema *ast.Schema) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s%d\n", versionHeader, version)
+	formatter.NewFormatter(&buf).FormatSchema(schema)
+	return buf.String()
+}
+
+// Result результат сравнения схемы с опубликованным снимком
+type Result struct {
+	SnapshotVersion int
+	CurrentVersion  int
+	Changes         []BreakingChange
+}
+
+// Blocking сообщает, что схема сломана без повышения версии
+func (r Result) Blocking() bool {
+	return len(r.Changes) > 0 && r.CurrentVersion <= r.SnapshotVersion
+}
+
+// Check сравнивает текущую схему с опубликованным снимком
+func Check(snapshot *Snapshot, currentVersion int, current *ast.Schema) Result {
+	return Result{
+		SnapshotVersion: snapshot.Version,
+		CurrentVersion:  currentVersion,
+		Changes:         Diff(snapshot.Schema, current),
+	}
+}
@@ -0,0 +1,61 @@
+package schemamigration
+
+import (
+	"context"
+	"time"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// SyncJob копирует в новую схему проверки, измененные воркерами, и сверяет одну пачку
+// проверок по порядку id, исправляя расхождения от записей в обход репозитория проверок
+type SyncJob struct {
+	repo   repository.VerificationV2Repository
+	cfg    config.SchemaMigrationConfig
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+func NewSyncJob(repo repository.VerificationV2Repository, cfg config.SchemaMigrationConfig, logger *zap.Logger) *SyncJob {
+	return &SyncJob{
+		repo:   repo,
+		cfg:    cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func (j *SyncJob) Name() string {
+	return "schema_migration_sync"
+}
+
+func (j *SyncJob) Run(ctx context.Context) error {
+	settledBefore := j.now().Add(-j.cfg.SettleDelay)
+	var synced int
+	for ctx.Err() == nil {
+		count, err := j.repo.SyncChanged(ctx, settledBefore, j.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		synced += count
+		metrics.SchemaMigrationSynced.WithLabelValues("changed").Add(float64(count))
+		if count < j.cfg.BatchSize {
+			break
+		}
+	}
+
+	repaired, err := j.repo.Verify(ctx, j.cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+	metrics.SchemaMigrationSynced.WithLabelValues("repaired").Add(float64(repaired))
+
+	if synced > 0 || repaired > 0 {
+		j.logger.Info("verifications synced to the next schema", zap.Int("changed", synced), zap.Int("repaired", repaired))
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+// Package schemamigration переносит проверки в следующую версию схемы хранилища без остановки
+// шлюза и одномоментного переключения. Этапы переключаются настройкой: dual_write повторяет
+// записи шлюза в новой схеме, shadow_read дополнительно сравнивает часть чтений со старой схемой
+// и пишет расхождения в журнал, cutover читает проверки из новой схемы. Изменения, сделанные
+// воркерами и в обход репозитория, копирует и сверяет задача SyncJob.
+package schemamigration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// WrapVerificationRepository подключает новую схему next к репозиторию проверок на этапе cfg.Mode.
+// Старая схема остается источником истины: сбой записи в новую схему не прерывает запрос.
+func WrapVerificationRepository(primary repository.VerificationRepository, next repository.VerificationV2Repository, cfg config.SchemaMigrationConfig, logger *zap.Logger) repository.VerificationRepository {
+	if cfg.Mode == config.SchemaMigrationOff {
+		return primary
+	}
+	return &dualVerificationRepository{
+		VerificationRepository: primary,
+		next:                   next,
+		mode:                   cfg.Mode,
+		logger:                 logger,
+		sample: func() bool {
+			return rand.Float64() < cfg.CompareRate
+		},
+	}
+}
+
+type dualVerificationRepository struct {
+	repository.VerificationRepository
+	next   repository.VerificationV2Repository
+	mode   string
+	logger *zap.Logger
+	// sample решает, сравнивать ли чтение со старой схемой
+	sample func() bool
+}
+
+// mirror повторяет успешную запись проверки id в новой схеме
+func (r *dualVerificationRepository) mirror(ctx context.Context, id string, err error) error {
+	if err != nil {
+		return err
+	}
+	if syncErr := r.next.Sync(ctx, id); syncErr != nil {
+		metrics.SchemaMigrationMirrorFailures.Inc()
+		r.logger.Warn("Failed to mirror verification write to the next schema", zap.Error(syncErr), zap.String("id", id))
+	}
+	return nil
+}
+
+func (r *dualVerificationRepository) Create(ctx context.Context, verification *domain.Verification) error {
+	return r.mirror(ctx, verification.ID, r.VerificationRepository.Create(ctx, verification))
+}
+
+func (r *dualVerificationRepository) CreateWithRequest(ctx context.Context, verification *domain.Verification, msg *messaging.OutboxMessage, claimedUntil time.Time) (bool, error) {
+	written, err := r.VerificationRepository.CreateWithRequest(ctx, verification, msg, claimedUntil)
+	return written, r.mirror(ctx, msg.ID, err)
+}
+
+func (r *dualVerificationRepository) UpdateRiskLevel(ctx context.Context, id string, riskLevel model.RiskLevel) error {
+	return r.mirror(ctx, id, r.VerificationRepository.UpdateRiskLevel(ctx, id, riskLevel))
+}
+
+func (r *dualVerificationRepository) UpdateStatus(ctx context.Context, id string, status model.VerificationStatus, errorMessage *string, changedAt time.Time) error {
+	return r.mirror(ctx, id, r.VerificationRepository.UpdateStatus(ctx, id, status, errorMessage, changedAt))
+}
+
+func (r *dualVerificationRepository) SetExternalRef(ctx context.Context, id string, ref *model.ExternalRef) error {
+	return r.mirror(ctx, id, r.VerificationRepository.SetExternalRef(ctx, id, ref))
+}
+
+func (r *dualVerificationRepository) SetMetadata(ctx context.Context, id string, metadata map[string]string) error {
+	return r.mirror(ctx, id, r.VerificationRepository.SetMetadata(ctx, id, metadata))
+}
+
+func (r *dualVerificationRepository) UpdateMetadata(ctx context.Context, id string, update func(current map[string]string) (map[string]string, error)) (map[string]string, error) {
+	metadata, err := r.VerificationRepository.UpdateMetadata(ctx, id, update)
+	return metadata, r.mirror(ctx, id, err)
+}
+
+func (r *dualVerificationRepository) SetMissingDataTypes(ctx context.Context, id string, missing []model.VerificationDataType) error {
+	return r.mirror(ctx, id, r.VerificationRepository.SetMissingDataTypes(ctx, id, missing))
+}
+
+func (r *dualVerificationRepository) MarkMissingDataRetried(ctx context.Context, id string) error {
+	return r.mirror(ctx, id, r.VerificationRepository.MarkMissingDataRetried(ctx, id))
+}
+
+func (r *dualVerificationRepository) GetByID(ctx context.Context, id string) (*model.Verification, error) {
+	return r.GetByIDWithData(ctx, id, nil)
+}
+
+// GetByIDWithData после переключения читает проверку из новой схемы. Проверка, которую задача
+// синхронизации еще не скопировала, читается из старой.
+func (r *dualVerificationRepository) GetByIDWithData(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.Verification, error) {
+	if r.mode == config.SchemaMigrationCutover {
+		verification, err := r.next.GetByIDWithData(ctx, id, dataTypes)
+		if !errors.Is(err, repository.ErrNotFound) {
+			return verification, err
+		}
+		metrics.SchemaMigrationFallbacks.Inc()
+		return r.VerificationRepository.GetByIDWithData(ctx, id, dataTypes)
+	}
+
+	verification, err := r.VerificationRepository.GetByIDWithData(ctx, id, dataTypes)
+	if err == nil && r.mode == config.SchemaMigrationShadowRead && r.sample() {
+		r.compare(ctx, verification, dataTypes)
+	}
+	return verification, err
+}
+
+// compare сравнивает проверку из старой схемы с новой. Расхождение исправляется сразу:
+// следующее чтение должно совпасть, иначе запись в новую схему теряет изменения.
+func (r *dualVerificationRepository) compare(ctx context.Context, expected *model.Verification, dataTypes []model.VerificationDataType) {
+	candidate, err := r.next.GetByIDWithData(ctx, expected.ID, dataTypes)
+	var result string
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		result = "missing"
+		r.logger.Warn("Verification is missing in the next schema", zap.String("id", expected.ID))
+	case err != nil:
+		metrics.SchemaMigrationComparisons.WithLabelValues("error").Inc()
+		r.logger.Warn("Failed to compare verification with the next schema", zap.Error(err), zap.String("id", expected.ID))
+		return
+	default:
+		fields := Diff(expected, candidate)
+		if len(fields) == 0 {
+			metrics.SchemaMigrationComparisons.WithLabelValues("match").Inc()
+			return
+		}
+		result = "mismatch"
+		r.logger.Warn("Verification differs in the next schema", zap.String("id", expected.ID), zap.Strings("fields", fields))
+	}
+
+	metrics.SchemaMigrationComparisons.WithLabelValues(result).Inc()
+	if err := r.next.Sync(ctx, expected.ID); err != nil {
+		r.logger.Warn("Failed to repair verification in the next schema", zap.Error(err), zap.String("id", expected.ID))
+	}
+}
+
+// Diff возвращает имена полей GraphQL, значения которых в проверках различаются
+func Diff(expected, actual *model.Verification) []string {
+	expectedValue, actualValue := reflect.ValueOf(expected).Elem(), reflect.ValueOf(actual).Elem()
+	var fields []string
+	for i := range expectedValue.NumField() {
+		field := expectedValue.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		// Значения сравниваются в представлении ответа, указатели на равные значения не различаются
+		left, leftErr := json.Marshal(expectedValue.Field(i).Interface())
+		right, rightErr := json.Marshal(actualValue.Field(i).Interface())
+		if leftErr != nil || rightErr != nil || string(left) != string(right) {
+			fields = append(fields, jsonName(field))
+		}
+	}
+	return fields
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
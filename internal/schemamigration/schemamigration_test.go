@@ -0,0 +1,197 @@
+package schemamigration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// memoryVerificationRepository старая схема: проверки в памяти
+type memoryVerificationRepository struct {
+	repository.VerificationRepository
+	verifications map[string]*model.Verification
+}
+
+func (r *memoryVerificationRepository) GetByIDWithData(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.Verification, error) {
+	verification, ok := r.verifications[id]
+	if !ok {
+		return nil, fmt.Errorf("verification not found: %s: %w", id, repository.ErrNotFound)
+	}
+	copied := *verification
+	return &copied, nil
+}
+
+func (r *memoryVerificationRepository) UpdateRiskLevel(ctx context.Context, id string, riskLevel model.RiskLevel) error {
+	verification, ok := r.verifications[id]
+	if !ok {
+		return fmt.Errorf("verification not found: %s: %w", id, repository.ErrNotFound)
+	}
+	verification.RiskLevel = &riskLevel
+	return nil
+}
+
+// memoryV2Repository новая схема: Sync копирует проверку из старой
+type memoryV2Repository struct {
+	source        *memoryVerificationRepository
+	verifications map[string]*model.Verification
+	synced        []string
+	syncErr       error
+	changed       []int
+	repaired      int
+}
+
+func (r *memoryV2Repository) Sync(ctx context.Context, id string) error {
+	if r.syncErr != nil {
+		return r.syncErr
+	}
+	r.synced = append(r.synced, id)
+	copied := *r.source.verifications[id]
+	r.verifications[id] = &copied
+	return nil
+}
+
+func (r *memoryV2Repository) SyncChanged(ctx context.Context, settledBefore time.Time, limit int) (int, error) {
+	if len(r.changed) == 0 {
+		return 0, nil
+	}
+	count := r.changed[0]
+	r.changed = r.changed[1:]
+	return count, nil
+}
+
+func (r *memoryV2Repository) Verify(ctx context.Context, limit int) (int, error) {
+	return r.repaired, nil
+}
+
+func (r *memoryV2Repository) GetByIDWithData(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.Verification, error) {
+	verification, ok := r.verifications[id]
+	if !ok {
+		return nil, fmt.Errorf("verification not found in v2: %s: %w", id, repository.ErrNotFound)
+	}
+	copied := *verification
+	return &copied, nil
+}
+
+func newTestRepositories(t *testing.T, mode string) (repository.VerificationRepository, *memoryVerificationRepository, *memoryV2Repository) {
+	primary := &memoryVerificationRepository{verifications: map[string]*model.Verification{
+		"v-1": {ID: "v-1", Inn: "7707083893", Status: model.VerificationStatusCompleted, CreatedAt: "2026-03-01T09:00:00Z"},
+	}}
+	next := &memoryV2Repository{source: primary, verifications: map[string]*model.Verification{}}
+	cfg := config.SchemaMigrationConfig{Mode: mode, CompareRate: 1}
+	return WrapVerificationRepository(primary, next, cfg, zaptest.NewLogger(t)), primary, next
+}
+
+func TestWrapVerificationRepositoryOff(t *testing.T) {
+	repo, primary, _ := newTestRepositories(t, config.SchemaMigrationOff)
+	if repo != repository.VerificationRepository(primary) {
+		t.Error("expected the repository to stay unwrapped when the migration is off")
+	}
+}
+
+func TestDualWrite(t *testing.T) {
+	repo, _, next := newTestRepositories(t, config.SchemaMigrationDualWrite)
+
+	if err := repo.UpdateRiskLevel(context.Background(), "v-1", model.RiskLevelHigh); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(next.synced, []string{"v-1"}) || *next.verifications["v-1"].RiskLevel != model.RiskLevelHigh {
+		t.Errorf("expected the write to be mirrored, but got %v", next.synced)
+	}
+
+	// Неудачная запись в старую схему не повторяется
+	if err := repo.UpdateRiskLevel(context.Background(), "v-2", model.RiskLevelHigh); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected the primary error, but got %v", err)
+	}
+	if len(next.synced) != 1 {
+		t.Errorf("expected a failed write not to be mirrored, but got %v", next.synced)
+	}
+
+	// Сбой новой схемы не прерывает запрос
+	next.syncErr = errors.New("connection refused")
+	if err := repo.UpdateRiskLevel(context.Background(), "v-1", model.RiskLevelLow); err != nil {
+		t.Errorf("expected a mirror failure not to fail the write, but got %v", err)
+	}
+}
+
+func TestShadowReadRepairsMismatch(t *testing.T) {
+	repo, primary, next := newTestRepositories(t, config.SchemaMigrationShadowRead)
+
+	// Проверки нет в новой схеме: чтение из старой, проверка копируется
+	verification, err := repo.GetByID(context.Background(), "v-1")
+	if err != nil || verification.ID != "v-1" {
+		t.Fatalf("expected the verification from the old schema, but got %+v and %v", verification, err)
+	}
+	if _, ok := next.verifications["v-1"]; !ok {
+		t.Fatal("expected a missing verification to be copied")
+	}
+
+	primary.verifications["v-1"].Status = model.VerificationStatusError
+	repo.GetByID(context.Background(), "v-1")
+	if next.verifications["v-1"].Status != model.VerificationStatusError {
+		t.Error("expected a differing verification to be repaired")
+	}
+
+	synced := len(next.synced)
+	repo.GetByID(context.Background(), "v-1")
+	if len(next.synced) != synced {
+		t.Error("expected a matching verification not to be copied again")
+	}
+}
+
+func TestCutover(t *testing.T) {
+	repo, _, next := newTestRepositories(t, config.SchemaMigrationCutover)
+
+	// Еще не скопированная проверка читается из старой схемы
+	verification, err := repo.GetByID(context.Background(), "v-1")
+	if err != nil || verification.Status != model.VerificationStatusCompleted {
+		t.Fatalf("expected a fallback to the old schema, but got %+v and %v", verification, err)
+	}
+
+	next.verifications["v-1"] = &model.Verification{ID: "v-1", Status: model.VerificationStatusPartiallyCompleted}
+	verification, err = repo.GetByID(context.Background(), "v-1")
+	if err != nil || verification.Status != model.VerificationStatusPartiallyCompleted {
+		t.Errorf("expected the verification from the next schema, but got %+v and %v", verification, err)
+	}
+
+	if _, err := repo.GetByID(context.Background(), "v-2"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected not found, but got %v", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	branch := func() []*model.MetadataEntry {
+		return []*model.MetadataEntry{{Key: "branch", Value: "077"}}
+	}
+	expected := &model.Verification{ID: "v-1", Status: model.VerificationStatusCompleted, CreatedAt: "2026-03-01T09:00:00Z", Metadata: branch()}
+	actual := &model.Verification{ID: "v-1", Status: model.VerificationStatusCompleted, CreatedAt: "2026-03-01T09:00:00Z", Metadata: branch()}
+	if fields := Diff(expected, actual); len(fields) != 0 {
+		t.Errorf("expected equal verifications, but got differences in %v", fields)
+	}
+
+	actual.Status = model.VerificationStatusError
+	actual.CreatedAt = "2026-03-01T09:00:01Z"
+	if fields := Diff(expected, actual); !slices.Equal(fields, []string{"status", "createdAt"}) {
+		t.Errorf("expected differences in status and createdAt, but got %v", fields)
+	}
+}
+
+func TestSyncJob(t *testing.T) {
+	next := &memoryV2Repository{changed: []int{2, 2, 1}, repaired: 1}
+	job := NewSyncJob(next, config.SchemaMigrationConfig{BatchSize: 2}, zaptest.NewLogger(t))
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(next.changed) != 0 {
+		t.Errorf("expected the job to copy changes until a short batch, but %v batches are left", next.changed)
+	}
+}
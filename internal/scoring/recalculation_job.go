@@ -0,0 +1,28 @@
+// Package scoring пересчитывает оценки проверок после смены правил скоринга.
+package scoring
+
+import (
+	"context"
+
+	"scoring_api_gateway/internal/service"
+)
+
+// RecalculationJob отправляет скоринговому движку проверки незавершенных пересчетов пачками,
+// чтобы пересчет большого периода не создавал всплеск нагрузки на движок
+type RecalculationJob struct {
+	service   service.ScoringService
+	batchSize int
+}
+
+func NewRecalculationJob(service service.ScoringService, batchSize int) *RecalculationJob {
+	return &RecalculationJob{service: service, batchSize: batchSize}
+}
+
+func (j *RecalculationJob) Name() string {
+	return "score_recalculation"
+}
+
+func (j *RecalculationJob) Run(ctx context.Context) error {
+	_, err := j.service.PublishRecalculations(ctx, j.batchSize)
+	return err
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// AccessReviewService перечисляет для аудита действующий доступ к проверкам: учетные записи
+// пользователей с ролями и неотозванные ключи сервисных аккаунтов с их ограничениями.
+// Пользователи без учетной записи и роль admin задаются прокси аутентификации и в отчет не попадают.
+type AccessReviewService interface {
+	GetAccessReview(ctx context.Context) (*model.AccessReview, error)
+	// WhoCanAccess возвращает доступ с правом чтения и типы данных проверки, которые он видит
+	WhoCanAccess(ctx context.Context, verificationID string) ([]*model.AccessGrant, error)
+	// ExportAccessReviewCSV возвращает отчет о доступе в CSV, по строке на пользователя или ключ
+	ExportAccessReviewCSV(ctx context.Context) (*CSVExport, error)
+}
+
+// CSVExport выгрузка отчета в CSV
+type CSVExport struct {
+	FileName string
+	Content  []byte
+}
+
+type accessReviewService struct {
+	users         repository.UserRepository
+	apiKeys       repository.APIKeyRepository
+	organizations repository.OrganizationRepository
+	sandbox       repository.SandboxRepository
+	cache         repository.DataCacheRepository
+	verifications repository.VerificationRepository
+	logger        *zap.Logger
+	now           func() time.Time
+}
+
+func NewAccessReviewService(users repository.UserRepository, apiKeys repository.APIKeyRepository, organizations repository.OrganizationRepository, sandbox repository.SandboxRepository, cache repository.DataCacheRepository, verifications repository.VerificationRepository, logger *zap.Logger) AccessReviewService {
+	return &accessReviewService{
+		users:         users,
+		apiKeys:       apiKeys,
+		organizations: organizations,
+		sandbox:       sandbox,
+		cache:         cache,
+		verifications: verifications,
+		logger:        logger,
+		now:           time.Now,
+	}
+}
+
+func (s *accessReviewService) GetAccessReview(ctx context.Context) (*model.AccessReview, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	grants, err := s.grants(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	policies, err := s.cache.ListSharing(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shared := []model.VerificationDataType{}
+	for _, policy := range policies {
+		if policy.Shared {
+			shared = append(shared, policy.DataType)
+		}
+	}
+
+	return &model.AccessReview{
+		GeneratedAt:     s.now().UTC().Format(time.RFC3339),
+		Grants:          grants,
+		SharedDataTypes: shared,
+	}, nil
+}
+
+// WhoCanAccess перечисляет доступ к проверке. Чтение проверок не ограничено организацией, поэтому
+// проверку видит любой доступ с правом чтения, а ключ с ограничением типов - только свои типы.
+func (s *accessReviewService) WhoCanAccess(ctx context.Context, verificationID string) ([]*model.AccessGrant, error) {
+	if verificationID == "" {
+		return nil, invalidInputf("verification id cannot be empty")
+	}
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	verification, err := s.verifications.GetByID(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := s.grants(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	readers := make([]*model.AccessGrant, 0, len(grants))
+	for _, grant := range grants {
+		if !slices.Contains(grant.Operations, model.AccessOperationRead) {
+			continue
+		}
+		dataTypes := make([]model.VerificationDataType, 0, len(verification.RequestedDataTypes))
+		for _, dataType := range verification.RequestedDataTypes {
+			if grant.DataTypes == nil || slices.Contains(grant.DataTypes, dataType) {
+				dataTypes = append(dataTypes, dataType)
+			}
+		}
+		grant.DataTypes = dataTypes
+		readers = append(readers, grant)
+	}
+	return readers, nil
+}
+
+func (s *accessReviewService) ExportAccessReviewCSV(ctx context.Context) (*CSVExport, error) {
+	review, err := s.GetAccessReview(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"client_kind", "principal", "service_email", "organization", "status", "roles", "operations", "data_types", "administered_organizations", "sandbox"})
+	for _, grant := range review.Grants {
+		dataTypes := "ALL"
+		if grant.DataTypes != nil {
+			dataTypes = joinValues(grant.DataTypes)
+		}
+		status := ""
+		if grant.Status != nil {
+			status = string(*grant.Status)
+		}
+		w.Write([]string{
+			string(grant.ClientKind),
+			grant.Principal,
+			valueOrEmpty(grant.ServiceEmail),
+			valueOrEmpty(grant.Organization),
+			status,
+			joinValues(grant.Roles),
+			joinValues(grant.Operations),
+			dataTypes,
+			strings.Join(grant.AdministeredOrganizations, ";"),
+			strconv.FormatBool(grant.Sandbox),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write access review: %w", err)
+	}
+
+	return &CSVExport{
+		FileName: fmt.Sprintf("access-review-%s.csv", s.now().UTC().Format(time.DateOnly)),
+		Content:  buf.Bytes(),
+	}, nil
+}
+
+// grants возвращает доступ учетных записей, кроме отключенных, и затем ключей
+func (s *accessReviewService) grants(ctx context.Context) ([]*model.AccessGrant, error) {
+	memberships, err := s.users.ListMemberships(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.apiKeys.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Организации повторяются у многих пользователей
+	subsidiaries := make(map[string][]string)
+	sandboxes := make(map[string]bool)
+	grants := make([]*model.AccessGrant, 0, len(memberships)+len(keys))
+	for _, membership := range memberships {
+		if membership.Status == model.UserStatusDeactivated {
+			continue
+		}
+
+		organization := membership.OrganizationID
+		if _, ok := sandboxes[organization]; !ok {
+			if sandboxes[organization], err = s.sandbox.IsSandboxTenant(ctx, organization); err != nil {
+				return nil, err
+			}
+		}
+		status := membership.Status
+		grant := &model.AccessGrant{
+			ClientKind:                model.ClientKindUser,
+			Principal:                 membership.Email,
+			Organization:              &organization,
+			Status:                    &status,
+			Roles:                     []model.OrganizationRole{},
+			Operations:                []model.AccessOperation{model.AccessOperationRead},
+			AdministeredOrganizations: []string{},
+			Sandbox:                   sandboxes[organization],
+		}
+		for _, role := range membership.Roles {
+			grant.Roles = append(grant.Roles, model.OrganizationRole(strings.ToUpper(role)))
+		}
+		principal := &auth.Principal{Email: membership.Email, Roles: membership.Roles}
+		if auth.CheckOperation(auth.WithPrincipal(ctx, principal), auth.OperationCreate) == nil {
+			grant.Operations = append(grant.Operations, model.AccessOperationCreate)
+		}
+
+		if principal.HasRole(auth.RoleOrgAdmin) {
+			administered, ok := subsidiaries[organization]
+			if !ok {
+				if administered, err = s.organizations.GetSubsidiaries(ctx, organization); err != nil {
+					return nil, err
+				}
+				subsidiaries[organization] = administered
+			}
+			grant.AdministeredOrganizations = append([]string{organization}, administered...)
+		}
+		grants = append(grants, grant)
+	}
+
+	for _, key := range keys {
+		serviceEmail := key.ServiceEmail
+		scope := &auth.Scope{KeyID: key.ID}
+		for _, op := range key.AllowedOperations {
+			scope.Operations = append(scope.Operations, auth.Operation(op))
+		}
+
+		grant := &model.AccessGrant{
+			ClientKind:                model.ClientKindAPIKey,
+			Principal:                 key.Name,
+			ServiceEmail:              &serviceEmail,
+			Roles:                     []model.OrganizationRole{},
+			Operations:                []model.AccessOperation{},
+			AdministeredOrganizations: []string{},
+			Sandbox:                   key.Sandbox,
+		}
+		if scope.AllowsOperation(auth.OperationRead) {
+			grant.Operations = append(grant.Operations, model.AccessOperationRead)
+		}
+		if scope.AllowsOperation(auth.OperationCreate) {
+			grant.Operations = append(grant.Operations, model.AccessOperationCreate)
+		}
+		if len(key.AllowedDataTypes) > 0 {
+			grant.DataTypes = make([]model.VerificationDataType, 0, len(key.AllowedDataTypes))
+			for _, dataType := range key.AllowedDataTypes {
+				grant.DataTypes = append(grant.DataTypes, model.VerificationDataType(dataType))
+			}
+		}
+		grants = append(grants, grant)
+	}
+	return grants, nil
+}
+
+func joinValues[T ~string](values []T) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		parts = append(parts, string(value))
+	}
+	return strings.Join(parts, ";")
+}
+
+func valueOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
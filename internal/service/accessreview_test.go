@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для APIKeyRepository
+type mockAPIKeyRepository struct {
+	repository.APIKeyRepository
+	keys []*repository.APIKey
+}
+
+func (m *mockAPIKeyRepository) ListActive(ctx context.Context) ([]*repository.APIKey, error) {
+	return m.keys, nil
+}
+
+// Mock для SandboxRepository
+type mockSandboxRepository struct {
+	repository.SandboxRepository
+	tenants []string
+}
+
+func (m *mockSandboxRepository) IsSandboxTenant(ctx context.Context, tenant string) (bool, error) {
+	return slices.Contains(m.tenants, tenant), nil
+}
+
+func newAccessReviewFixture(t *testing.T) *accessReviewService {
+	users := &mockUserRepository{memberships: []*repository.Membership{
+		{Email: "admin@example.com", Status: model.UserStatusActive, OrganizationID: "example.com", Roles: []string{auth.RoleOrgAdmin}},
+		{Email: "gone@retail.example.com", Status: model.UserStatusDeactivated, OrganizationID: "retail.example.com", Roles: []string{auth.RoleAnalyst}},
+		{Email: "viewer@dev.example.org", Status: model.UserStatusInvited, OrganizationID: "dev.example.org", Roles: []string{auth.RoleViewer}},
+	}}
+	keys := &mockAPIKeyRepository{keys: []*repository.APIKey{
+		{ID: "key-1", Name: "partner-x", ServiceEmail: "partner-x@partners.local", AllowedOperations: []string{"create"}},
+		{ID: "key-2", Name: "partner-y", ServiceEmail: "partner-y@partners.local", AllowedDataTypes: []string{"BASIC_INFORMATION"}, Sandbox: true},
+	}}
+	cache := &mockDataCacheRepository{sharing: []*model.CacheSharingPolicy{
+		{DataType: model.VerificationDataTypeBasicInformation, Shared: true},
+		{DataType: model.VerificationDataTypeActivities},
+	}}
+	verifications := &mockVerificationRepository{
+		getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+			return &model.Verification{ID: id, RequestedDataTypes: []model.VerificationDataType{
+				model.VerificationDataTypeBasicInformation, model.VerificationDataTypeArbitrageStatistics,
+			}}, nil
+		},
+	}
+
+	service := NewAccessReviewService(users, keys, holding, &mockSandboxRepository{tenants: []string{"dev.example.org"}}, cache, verifications, zaptest.NewLogger(t)).(*accessReviewService)
+	service.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+	return service
+}
+
+func TestGetAccessReview(t *testing.T) {
+	service := newAccessReviewFixture(t)
+
+	analyst := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@example.com", Roles: []string{auth.RoleOrgAdmin}})
+	if _, err := service.GetAccessReview(analyst); err == nil || !containsError(err.Error(), "access denied") {
+		t.Fatalf("expected access denied for non-admin, but got %v", err)
+	}
+
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "root@scoring.local", Roles: []string{auth.RoleAdmin}})
+	review, err := service.GetAccessReview(admin)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(review.SharedDataTypes, []model.VerificationDataType{model.VerificationDataTypeBasicInformation}) {
+		t.Errorf("unexpected shared data types %v", review.SharedDataTypes)
+	}
+
+	// Отключенный пользователь доступа не имеет
+	principals := make([]string, 0, len(review.Grants))
+	for _, grant := range review.Grants {
+		principals = append(principals, grant.Principal)
+	}
+	if !slices.Equal(principals, []string{"admin@example.com", "viewer@dev.example.org", "partner-x", "partner-y"}) {
+		t.Fatalf("unexpected grants %v", principals)
+	}
+
+	orgAdmin, viewer, writer, reader := review.Grants[0], review.Grants[1], review.Grants[2], review.Grants[3]
+	if !slices.Equal(orgAdmin.AdministeredOrganizations, []string{"example.com", "retail.example.com", "shop.example.com"}) {
+		t.Errorf("expected holding admin to manage subsidiaries, but got %v", orgAdmin.AdministeredOrganizations)
+	}
+	if !slices.Equal(orgAdmin.Operations, []model.AccessOperation{model.AccessOperationRead, model.AccessOperationCreate}) {
+		t.Errorf("unexpected org admin operations %v", orgAdmin.Operations)
+	}
+	if !slices.Equal(viewer.Operations, []model.AccessOperation{model.AccessOperationRead}) || !viewer.Sandbox {
+		t.Errorf("expected read-only sandbox viewer, but got %+v", viewer)
+	}
+	if !slices.Equal(writer.Operations, []model.AccessOperation{model.AccessOperationCreate}) || writer.DataTypes != nil {
+		t.Errorf("expected create-only key with all data types, but got %+v", writer)
+	}
+	if !slices.Equal(reader.DataTypes, []model.VerificationDataType{model.VerificationDataTypeBasicInformation}) {
+		t.Errorf("expected key restricted to its data types, but got %v", reader.DataTypes)
+	}
+}
+
+func TestWhoCanAccess(t *testing.T) {
+	service := newAccessReviewFixture(t)
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "root@scoring.local", Roles: []string{auth.RoleAdmin}})
+
+	grants, err := service.WhoCanAccess(admin, "v-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Ключ без права чтения проверку не видит
+	seen := make(map[string][]model.VerificationDataType)
+	for _, grant := range grants {
+		seen[grant.Principal] = grant.DataTypes
+	}
+	if len(seen) != 3 || seen["partner-x"] != nil {
+		t.Fatalf("expected readers only, but got %v", seen)
+	}
+	if len(seen["admin@example.com"]) != 2 {
+		t.Errorf("expected users to see all data types of the verification, but got %v", seen["admin@example.com"])
+	}
+	if !slices.Equal(seen["partner-y"], []model.VerificationDataType{model.VerificationDataTypeBasicInformation}) {
+		t.Errorf("expected key to see only its data types, but got %v", seen["partner-y"])
+	}
+}
+
+func TestExportAccessReviewCSV(t *testing.T) {
+	service := newAccessReviewFixture(t)
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "root@scoring.local", Roles: []string{auth.RoleAdmin}})
+
+	export, err := service.ExportAccessReviewCSV(admin)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if export.FileName != "access-review-2024-03-01.csv" {
+		t.Errorf("unexpected file name %s", export.FileName)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(export.Content)), "\n")
+	expected := []string{
+		"client_kind,principal,service_email,organization,status,roles,operations,data_types,administered_organizations,sandbox",
+		"USER,admin@example.com,,example.com,ACTIVE,ORG_ADMIN,READ;CREATE,ALL,example.com;retail.example.com;shop.example.com,false",
+		"USER,viewer@dev.example.org,,dev.example.org,INVITED,VIEWER,READ,ALL,,true",
+		"API_KEY,partner-x,partner-x@partners.local,,,,CREATE,ALL,,false",
+		"API_KEY,partner-y,partner-y@partners.local,,,,READ;CREATE,BASIC_INFORMATION,,true",
+	}
+	if !slices.Equal(lines, expected) {
+		t.Errorf("unexpected csv:\n%s", export.Content)
+	}
+}
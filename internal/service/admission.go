@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// queuedAdmissionVerificationService отдает проверки, отложенные ограничением скорости публикации
+// или удержанные лимитом одновременных проверок арендатора.
+// До отправки воркерам проверки нет в таблице verifications, но ее можно найти в outbox.
+type queuedAdmissionVerificationService struct {
+	VerificationService
+	outbox repository.OutboxRepository
+	logger *zap.Logger
+}
+
+// NewQueuedAdmissionVerificationService оборачивает сервис проверок: ожидающие отправки проверки
+// возвращаются со статусом PENDING, позицией в очереди и ожидаемым временем отправки
+func NewQueuedAdmissionVerificationService(inner VerificationService, outbox repository.OutboxRepository, logger *zap.Logger) VerificationService {
+	return &queuedAdmissionVerificationService{
+		VerificationService: inner,
+		outbox:              outbox,
+		logger:              logger,
+	}
+}
+
+func (s *queuedAdmissionVerificationService) GetVerification(ctx context.Context, id string) (*model.Verification, error) {
+	verification, err := s.VerificationService.GetVerification(ctx, id)
+	if !errors.Is(err, repository.ErrNotFound) {
+		return verification, err
+	}
+
+	msg, position, pendingErr := s.outbox.GetPending(ctx, id)
+	if pendingErr != nil {
+		s.logger.Error("failed to get pending verification", zap.Error(pendingErr), zap.String("id", id))
+		return nil, fmt.Errorf("failed to get verification: %w", pendingErr)
+	}
+	if msg == nil {
+		return nil, err
+	}
+
+	pending, convErr := msg.Verification()
+	if convErr != nil {
+		s.logger.Error("failed to restore pending verification", zap.Error(convErr), zap.String("id", id))
+		return nil, fmt.Errorf("failed to get verification: %w", convErr)
+	}
+
+	createdAt := msg.CreatedAt.UTC()
+
+	pending.Status = domain.StatusPending
+	pending.QueuePosition = &position
+	// Удержанная проверка будет отправлена, когда завершатся другие проверки арендатора
+	if !msg.Held {
+		expectedStartAt := msg.AvailableAt.UTC()
+		pending.ExpectedStartAt = &expectedStartAt
+	}
+	pending.MissingDataTypes = []domain.DataType{}
+	pending.CreatedAt = createdAt
+	pending.UpdatedAt = createdAt
+	return model.VerificationFromDomain(pending), nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type mockOutboxRepository struct {
+	repository.OutboxRepository
+	pending  map[string]*messaging.OutboxMessage
+	position int
+}
+
+func (m *mockOutboxRepository) GetPending(ctx context.Context, id string) (*messaging.OutboxMessage, int, error) {
+	msg, ok := m.pending[id]
+	if !ok {
+		return nil, 0, nil
+	}
+	return msg, m.position, nil
+}
+
+func TestQueuedAdmissionGetVerification(t *testing.T) {
+	payload, _ := json.Marshal(messaging.CreateVerificationMessage{
+		VerificationID: "v-pending",
+		INN:            "7707083893",
+		AuthorEmail:    "analyst@bank.ru",
+		RequestedTypes: []domain.DataType{domain.DataTypeBasicInformation},
+	})
+	outbox := &mockOutboxRepository{
+		pending: map[string]*messaging.OutboxMessage{
+			"v-pending": {
+				ID:          "v-pending",
+				Tenant:      "bank.ru",
+				Payload:     payload,
+				AvailableAt: time.Date(2024, 1, 15, 10, 5, 0, 0, time.UTC),
+				CreatedAt:   time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+			},
+			"v-held": {
+				ID:          "v-held",
+				Tenant:      "bank.ru",
+				Payload:     payload,
+				AvailableAt: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+				CreatedAt:   time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+				Held:        true,
+			},
+		},
+		position: 3,
+	}
+
+	repo := &mockVerificationRepository{
+		getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+			if id == "v-stored" {
+				return &model.Verification{ID: id, Status: model.VerificationStatusCompleted}, nil
+			}
+			return nil, errNotFound("verification not found: %s", id)
+		},
+	}
+	service := NewQueuedAdmissionVerificationService(NewVerificationService(repo, &mockNATSClient{}, nil, zaptest.NewLogger(t)), outbox, zaptest.NewLogger(t))
+
+	t.Run("stored", func(t *testing.T) {
+		verification, err := service.GetVerification(context.Background(), "v-stored")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if verification.Status != model.VerificationStatusCompleted {
+			t.Errorf("expected stored verification, but got %+v", verification)
+		}
+	})
+
+	t.Run("pending", func(t *testing.T) {
+		verification, err := service.GetVerification(context.Background(), "v-pending")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if verification.Status != model.VerificationStatusPending || verification.Inn != "7707083893" {
+			t.Errorf("expected PENDING verification of 7707083893, but got %+v", verification)
+		}
+		if verification.QueuePosition == nil || *verification.QueuePosition != 3 {
+			t.Errorf("expected queue position 3, but got %v", verification.QueuePosition)
+		}
+		if verification.ExpectedStartAt == nil || *verification.ExpectedStartAt != "2024-01-15T10:05:00Z" {
+			t.Errorf("expected start 2024-01-15T10:05:00Z, but got %v", verification.ExpectedStartAt)
+		}
+		if verification.CreatedAt != "2024-01-15T10:00:00Z" {
+			t.Errorf("expected created at 2024-01-15T10:00:00Z, but got %s", verification.CreatedAt)
+		}
+	})
+
+	t.Run("held", func(t *testing.T) {
+		verification, err := service.GetVerification(context.Background(), "v-held")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if verification.Status != model.VerificationStatusPending || verification.QueuePosition == nil {
+			t.Errorf("expected PENDING verification with queue position, but got %+v", verification)
+		}
+		if verification.ExpectedStartAt != nil {
+			t.Errorf("expected no expected start for held verification, but got %s", *verification.ExpectedStartAt)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := service.GetVerification(context.Background(), "v-unknown")
+		if !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected not found, but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// AmendmentService поправки завершенных проверок: данные, которые воркер доставил после завершения.
+// Поправку записывает триггер базы, сервис публикует verification.amended и отдает историю поправок.
+type AmendmentService interface {
+	ListAmendments(ctx context.Context, verificationID string) ([]*model.VerificationAmendment, error)
+	// PublishPending публикует неопубликованные поправки и возвращает число опубликованных
+	PublishPending(ctx context.Context, claimUntil time.Time, limit int) (int, error)
+}
+
+type amendmentService struct {
+	repo          repository.AmendmentRepository
+	verifications VerificationService
+	dataQuality   DataQualityService
+	payloads      PayloadService
+	audit         AuditService
+	nats          messaging.NATSClient
+	logger        *zap.Logger
+}
+
+// NewAmendmentService создает сервис поправок. payloads может быть nil, тогда данные поправок
+// не проверяются при поступлении.
+func NewAmendmentService(repo repository.AmendmentRepository, verifications VerificationService, dataQuality DataQualityService, payloads PayloadService, audit AuditService, nats messaging.NATSClient, logger *zap.Logger) AmendmentService {
+	return &amendmentService{
+		repo:          repo,
+		verifications: verifications,
+		dataQuality:   dataQuality,
+		payloads:      payloads,
+		audit:         audit,
+		nats:          nats,
+		logger:        logger,
+	}
+}
+
+func (s *amendmentService) ListAmendments(ctx context.Context, verificationID string) ([]*model.VerificationAmendment, error) {
+	if verificationID == "" {
+		return nil, invalidInputf("verification id cannot be empty")
+	}
+	if err := auth.CheckOperation(ctx, auth.OperationRead); err != nil {
+		return nil, err
+	}
+
+	amendments, err := s.repo.ListByVerification(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.VerificationAmendment, 0, len(amendments))
+	for _, amendment := range amendments {
+		result = append(result, toModelAmendment(amendment))
+	}
+	return result, nil
+}
+
+func (s *amendmentService) PublishPending(ctx context.Context, claimUntil time.Time, limit int) (int, error) {
+	amendments, err := s.repo.ClaimUnpublished(ctx, claimUntil, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim verification amendments: %w", err)
+	}
+
+	published := 0
+	for _, amendment := range amendments {
+		if err := s.publish(ctx, amendment); err != nil {
+			s.logger.Error("failed to publish verification amendment", zap.Error(err),
+				zap.String("verification_id", amendment.VerificationID), zap.Int64("amendment_id", amendment.ID), zap.Int("attempts", amendment.Attempts))
+			continue
+		}
+		published++
+		s.apply(ctx, amendment)
+	}
+	return published, nil
+}
+
+func (s *amendmentService) publish(ctx context.Context, amendment *repository.Amendment) error {
+	msg := &messaging.VerificationAmendedMessage{
+		VerificationID: amendment.VerificationID,
+		AmendmentID:    strconv.FormatInt(amendment.ID, 10),
+		DataType:       string(amendment.DataType),
+		SchemaVersion:  amendment.SchemaVersion,
+		ReceivedAt:     amendment.ReceivedAt.UTC().Format(time.RFC3339),
+	}
+	if amendment.DataHash != nil {
+		msg.DataHash = *amendment.DataHash
+	}
+	if amendment.PreviousDataHash != nil {
+		msg.PreviousDataHash = *amendment.PreviousDataHash
+	}
+
+	if err := s.nats.PublishVerificationAmended(ctx, msg); err != nil {
+		return err
+	}
+	return s.repo.MarkPublished(ctx, amendment.VerificationID, amendment.ID)
+}
+
+// apply обрабатывает опубликованную поправку так же, как данные при завершении: проверяет кодировку
+// и размер, проверяет данные по схеме, сверяет недоставленные типы и записывает событие в журнал аудита. Ошибки только пишутся в журнал,
+// чтобы поправка не публиковалась повторно.
+func (s *amendmentService) apply(ctx context.Context, amendment *repository.Amendment) {
+	log := s.logger.With(zap.String("verification_id", amendment.VerificationID), zap.Int64("amendment_id", amendment.ID))
+
+	if s.payloads != nil {
+		if err := s.payloads.Ingest(ctx, amendment.VerificationID); err != nil {
+			log.Error("failed to ingest amended data", zap.Error(err))
+		}
+	}
+	if _, err := s.dataQuality.ValidateDeliveredData(ctx, amendment.VerificationID); err != nil {
+		log.Error("failed to validate amended data", zap.Error(err))
+	}
+	if amendment.StatusAtArrival == model.VerificationStatusPartiallyCompleted {
+		if _, err := s.verifications.ReconcileDeliveredData(ctx, amendment.VerificationID); err != nil {
+			log.Error("failed to reconcile amended data", zap.Error(err))
+		}
+	}
+
+	details := map[string]any{
+		"amendment_id": amendment.ID,
+		"data_type":    amendment.DataType,
+		"data_hash":    amendment.DataHash,
+	}
+	if amendment.PreviousDataHash != nil {
+		details["previous_data_hash"] = *amendment.PreviousDataHash
+	}
+	if err := s.audit.RecordEvent(ctx, amendment.VerificationID, model.AuditEventTypeDataAmended, "", details); err != nil {
+		log.Error("failed to record data amendment", zap.Error(err))
+	}
+}
+
+func toModelAmendment(a *repository.Amendment) *model.VerificationAmendment {
+	amendment := &model.VerificationAmendment{
+		ID:               strconv.FormatInt(a.ID, 10),
+		VerificationID:   a.VerificationID,
+		DataType:         a.DataType,
+		DataHash:         a.DataHash,
+		PreviousDataHash: a.PreviousDataHash,
+		SchemaVersion:    int32(a.SchemaVersion),
+		StatusAtArrival:  a.StatusAtArrival,
+		ReceivedAt:       a.ReceivedAt.Format(time.RFC3339),
+	}
+	if a.PublishedAt != nil {
+		publishedAt := a.PublishedAt.Format(time.RFC3339)
+		amendment.PublishedAt = &publishedAt
+	}
+	return amendment
+}
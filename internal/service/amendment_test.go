@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/catalog"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/validation"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type mockAmendmentRepository struct {
+	amendments []*repository.Amendment
+	published  []int64
+}
+
+func (m *mockAmendmentRepository) ClaimUnpublished(ctx context.Context, claimUntil time.Time, limit int) ([]*repository.Amendment, error) {
+	return m.amendments, nil
+}
+
+func (m *mockAmendmentRepository) MarkPublished(ctx context.Context, verificationID string, id int64) error {
+	m.published = append(m.published, id)
+	return nil
+}
+
+func (m *mockAmendmentRepository) ListByVerification(ctx context.Context, verificationID string) ([]*repository.Amendment, error) {
+	return m.amendments, nil
+}
+
+func TestPublishPendingAmendments(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	validator, err := validation.NewValidator()
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	previous := "aaa"
+	current := "bbb"
+	repo := &mockAmendmentRepository{amendments: []*repository.Amendment{
+		{ID: 1, VerificationID: "v-1", DataType: model.VerificationDataTypeBasicInformation, DataHash: &current, PreviousDataHash: &previous,
+			SchemaVersion: 1, StatusAtArrival: model.VerificationStatusCompleted, ReceivedAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{ID: 2, VerificationID: "v-2", DataType: model.VerificationDataTypeActivities, DataHash: &current,
+			SchemaVersion: 1, StatusAtArrival: model.VerificationStatusPartiallyCompleted, ReceivedAt: time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)},
+		{ID: 3, VerificationID: "v-3", DataType: model.VerificationDataTypeActivities, DataHash: &current,
+			SchemaVersion: 1, StatusAtArrival: model.VerificationStatusCompleted, ReceivedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+	}}
+
+	var reconciled []string
+	verificationRepo := &mockVerificationRepository{
+		getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+			return &model.Verification{
+				ID:                 id,
+				Status:             model.VerificationStatusPartiallyCompleted,
+				RequestedDataTypes: []model.VerificationDataType{model.VerificationDataTypeActivities},
+				MissingDataTypes:   []model.VerificationDataType{model.VerificationDataTypeActivities},
+				Data:               []*model.VerificationData{{DataType: model.VerificationDataTypeActivities, Data: `{}`, SchemaVersion: 1}},
+			}, nil
+		},
+		setMissingFunc: func(ctx context.Context, id string, missing []model.VerificationDataType) error {
+			reconciled = append(reconciled, id)
+			return nil
+		},
+	}
+
+	var events []string
+	auditRepo := &mockAuditRepository{
+		addEventFunc: func(ctx context.Context, verificationID string, eventType model.AuditEventType, actor string, details map[string]any) error {
+			if eventType == model.AuditEventTypeDataAmended {
+				events = append(events, verificationID)
+			}
+			return nil
+		},
+	}
+
+	var sent []*messaging.VerificationAmendedMessage
+	nats := &mockNATSClient{publishAmendedFunc: func(ctx context.Context, amended *messaging.VerificationAmendedMessage) error {
+		if amended.VerificationID == "v-3" {
+			return errors.New("nats unavailable")
+		}
+		sent = append(sent, amended)
+		return nil
+	}}
+
+	verifications := NewVerificationService(verificationRepo, nats, nil, logger)
+	service := NewAmendmentService(repo, verifications,
+		NewDataQualityService(verificationRepo, validator, catalog.DefaultRegistry(), logger), nil,
+		NewAuditService(auditRepo, verifications, nil, logger), nats, logger)
+
+	published, err := service.PublishPending(context.Background(), time.Now().Add(time.Minute), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if published != 2 || len(repo.published) != 2 {
+		t.Fatalf("expected 2 published amendments, but got %d (%v)", published, repo.published)
+	}
+	if sent[0].AmendmentID != "1" || sent[0].PreviousDataHash != "aaa" || sent[0].DataHash != "bbb" || sent[0].ReceivedAt != "2024-03-01T10:00:00Z" {
+		t.Errorf("unexpected message %+v", sent[0])
+	}
+	if len(events) != 2 || events[0] != "v-1" || events[1] != "v-2" {
+		t.Errorf("expected audit events for published amendments, but got %v", events)
+	}
+	if len(reconciled) != 1 || reconciled[0] != "v-2" {
+		t.Errorf("expected only the partially completed verification to be reconciled, but got %v", reconciled)
+	}
+}
+
+func TestListAmendments(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	publishedAt := time.Date(2024, 3, 1, 10, 0, 5, 0, time.UTC)
+	repo := &mockAmendmentRepository{amendments: []*repository.Amendment{{
+		ID: 7, VerificationID: "v-1", DataType: model.VerificationDataTypeBasicInformation, SchemaVersion: 2,
+		StatusAtArrival: model.VerificationStatusCompleted, ReceivedAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), PublishedAt: &publishedAt,
+	}}}
+	service := NewAmendmentService(repo, nil, nil, nil, nil, &mockNATSClient{}, logger)
+
+	amendments, err := service.ListAmendments(context.Background(), "v-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(amendments) != 1 || amendments[0].ID != "7" || amendments[0].SchemaVersion != 2 ||
+		amendments[0].PublishedAt == nil || *amendments[0].PublishedAt != "2024-03-01T10:00:05Z" {
+		t.Errorf("unexpected amendments %+v", amendments)
+	}
+
+	if _, err := service.ListAmendments(context.Background(), ""); err == nil {
+		t.Error("expected error for empty verification id")
+	}
+	noRead := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "partner@bank.ru", Scope: &auth.Scope{Operations: []auth.Operation{auth.OperationCreate}}})
+	if _, err := service.ListAmendments(noRead, "v-1"); err == nil {
+		t.Error("expected key without read access to be denied")
+	}
+}
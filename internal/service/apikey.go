@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+type apiKeyService struct {
+	repo   repository.APIKeyRepository
+	logger *zap.Logger
+}
+
+// NewAPIKeyService создает сервис проверки ключей сервисных аккаунтов
+func NewAPIKeyService(repo repository.APIKeyRepository, logger *zap.Logger) auth.APIKeyResolver {
+	return &apiKeyService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// ResolveAPIKey возвращает сервисный аккаунт с ограничениями ключа
+func (s *apiKeyService) ResolveAPIKey(ctx context.Context, key string) (*auth.Principal, error) {
+	apiKey, err := s.repo.GetActiveByHash(ctx, auth.HashAPIKey(key))
+	if errors.Is(err, repository.ErrNotFound) {
+		s.logger.Warn("unknown or revoked api key used")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve api key: %w", err)
+	}
+
+	scope := &auth.Scope{
+		KeyID:     apiKey.ID,
+		DataTypes: apiKey.AllowedDataTypes,
+	}
+	for _, op := range apiKey.AllowedOperations {
+		scope.Operations = append(scope.Operations, auth.Operation(op))
+	}
+
+	return &auth.Principal{Email: apiKey.ServiceEmail, Scope: scope, Sandbox: apiKey.Sandbox}, nil
+}
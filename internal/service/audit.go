@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/report"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/signing"
+
+	"go.uber.org/zap"
+)
+
+type AuditService interface {
+	RecordEvent(ctx context.Context, verificationID string, eventType model.AuditEventType, actor string, details map[string]any) error
+	GetAuditTrail(ctx context.Context, verificationID string) (*model.VerificationAuditTrail, error)
+	ExportAuditTrailPDF(ctx context.Context, verificationID string) (*SignedPDF, error)
+}
+
+// SignedPDF подписанный ключом шлюза PDF-документ: хронология проверки или отчет по делу
+type SignedPDF struct {
+	FileName  string
+	Content   []byte
+	Signature []byte
+	KeyID     string
+}
+
+type auditService struct {
+	repo         repository.AuditRepository
+	verification VerificationService
+	signer       *signing.Signer
+	logger       *zap.Logger
+}
+
+// NewAuditService создает сервис журнала аудита. signer может быть nil, тогда экспорт в PDF недоступен.
+func NewAuditService(repo repository.AuditRepository, verification VerificationService, signer *signing.Signer, logger *zap.Logger) AuditService {
+	return &auditService{
+		repo:         repo,
+		verification: verification,
+		signer:       signer,
+		logger:       logger,
+	}
+}
+
+func (s *auditService) RecordEvent(ctx context.Context, verificationID string, eventType model.AuditEventType, actor string, details map[string]any) error {
+	if verificationID == "" {
+		return invalidInputf("verification id cannot be empty")
+	}
+
+	if !eventType.IsValid() {
+		return invalidInputf("invalid audit event type: %s", eventType)
+	}
+
+	return s.repo.AddEvent(ctx, verificationID, eventType, actor, details)
+}
+
+func (s *auditService) GetAuditTrail(ctx context.Context, verificationID string) (*model.VerificationAuditTrail, error) {
+	verification, err := s.verification.GetVerification(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.GetTrail(ctx, verificationID)
+	if err != nil {
+		s.logger.Error("failed to get audit trail", zap.Error(err), zap.String("verification_id", verificationID))
+		return nil, fmt.Errorf("failed to get audit trail: %w", err)
+	}
+
+	if entries == nil {
+		entries = []*model.AuditTrailEntry{}
+	}
+
+	return &model.VerificationAuditTrail{
+		Verification: verification,
+		Entries:      entries,
+	}, nil
+}
+
+// ExportAuditTrailPDF формирует PDF с хронологией проверки и подписывает его ключом шлюза
+func (s *auditService) ExportAuditTrailPDF(ctx context.Context, verificationID string) (*SignedPDF, error) {
+	if s.signer == nil {
+		return nil, fmt.Errorf("signing key is not configured")
+	}
+
+	trail, err := s.GetAuditTrail(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	content := report.RenderTextPDF("Verification audit trail", auditTrailLines(trail, time.Now().UTC()))
+
+	return &SignedPDF{
+		FileName:  fmt.Sprintf("verification-%s-audit-trail.pdf", verificationID),
+		Content:   content,
+		Signature: s.signer.Sign(content),
+		KeyID:     s.signer.KeyID(),
+	}, nil
+}
+
+func auditTrailLines(trail *model.VerificationAuditTrail, generatedAt time.Time) []string {
+	v := trail.Verification
+	types := make([]string, len(v.RequestedDataTypes))
+	for i, t := range v.RequestedDataTypes {
+		types[i] = string(t)
+	}
+
+	lines := []string{
+		"Verification ID: " + v.ID,
+		"INN:             " + v.Inn,
+		"Status:          " + string(v.Status),
+		"Author:          " + v.AuthorEmail,
+		"Requested data:  " + strings.Join(types, ", "),
+		"Generated at:    " + generatedAt.Format(time.RFC3339),
+		"",
+	}
+
+	for _, entry := range trail.Entries {
+		line := fmt.Sprintf("%s  %-22s", entry.OccurredAt, entry.EventType)
+		if entry.Actor != nil {
+			line += "  by " + *entry.Actor
+		}
+		if entry.Details != nil {
+			line += "  " + *entry.Details
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/signing"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для AuditRepository
+type mockAuditRepository struct {
+	addEventFunc func(ctx context.Context, verificationID string, eventType model.AuditEventType, actor string, details map[string]any) error
+	getTrailFunc func(ctx context.Context, verificationID string) ([]*model.AuditTrailEntry, error)
+}
+
+func (m *mockAuditRepository) AddEvent(ctx context.Context, verificationID string, eventType model.AuditEventType, actor string, details map[string]any) error {
+	if m.addEventFunc != nil {
+		return m.addEventFunc(ctx, verificationID, eventType, actor, details)
+	}
+	return nil
+}
+
+func (m *mockAuditRepository) GetTrail(ctx context.Context, verificationID string) ([]*model.AuditTrailEntry, error) {
+	if m.getTrailFunc != nil {
+		return m.getTrailFunc(ctx, verificationID)
+	}
+	return nil, nil
+}
+
+func newTestAuditService(t *testing.T, signer *signing.Signer) AuditService {
+	verificationRepo := &mockVerificationRepository{
+		getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+			return &model.Verification{ID: id, Inn: "1234567890", Status: model.VerificationStatusCompleted}, nil
+		},
+	}
+	actor := "analyst@example.com"
+	auditRepo := &mockAuditRepository{
+		getTrailFunc: func(ctx context.Context, verificationID string) ([]*model.AuditTrailEntry, error) {
+			return []*model.AuditTrailEntry{
+				{EventType: model.AuditEventTypeCreated, Actor: &actor, OccurredAt: "2024-01-01T10:00:00Z"},
+				{EventType: model.AuditEventTypeStatusChanged, OccurredAt: "2024-01-01T10:05:00Z"},
+			}, nil
+		},
+	}
+	logger := zaptest.NewLogger(t)
+	verificationService := NewVerificationService(verificationRepo, &mockNATSClient{}, nil, logger)
+
+	return NewAuditService(auditRepo, verificationService, signer, logger)
+}
+
+func TestGetAuditTrail(t *testing.T) {
+	service := newTestAuditService(t, nil)
+
+	trail, err := service.GetAuditTrail(context.Background(), "test-id")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if trail.Verification.ID != "test-id" {
+		t.Errorf("expected verification ID 'test-id', but got '%s'", trail.Verification.ID)
+	}
+
+	if len(trail.Entries) != 2 {
+		t.Errorf("expected 2 entries, but got %d", len(trail.Entries))
+	}
+}
+
+func TestRecordEventInvalidType(t *testing.T) {
+	service := newTestAuditService(t, nil)
+
+	err := service.RecordEvent(context.Background(), "test-id", model.AuditEventType("UNKNOWN"), "", nil)
+	if err == nil || !containsError(err.Error(), "invalid audit event type") {
+		t.Errorf("expected invalid audit event type error, but got %v", err)
+	}
+}
+
+func TestExportAuditTrailPDF(t *testing.T) {
+	t.Run("without_signing_key", func(t *testing.T) {
+		service := newTestAuditService(t, nil)
+
+		_, err := service.ExportAuditTrailPDF(context.Background(), "test-id")
+		if err == nil || err.Error() != "signing key is not configured" {
+			t.Errorf("expected signing key error, but got %v", err)
+		}
+	})
+
+	t.Run("signed_export", func(t *testing.T) {
+		signer, err := signing.NewSigner(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		service := newTestAuditService(t, signer)
+
+		export, err := service.ExportAuditTrailPDF(context.Background(), "test-id")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !bytes.HasPrefix(export.Content, []byte("%PDF")) {
+			t.Error("expected PDF content")
+		}
+
+		if !bytes.Contains(export.Content, []byte("by analyst@example.com")) {
+			t.Error("expected actor in exported trail")
+		}
+
+		if !signing.Verify(signer.PublicKey(), export.Content, export.Signature) {
+			t.Error("expected valid signature of exported content")
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// BackfillReport итог переноса строк verification_data на ссылки по хэшу
+type BackfillReport struct {
+	DryRun bool
+	// LegacyColumn false, если столбец data уже удален и переносить нечего
+	LegacyColumn bool
+	Rows         int
+	// NewPayloads уникальные данные, добавленные в кэш (при DryRun - найденные в обработанных строках)
+	NewPayloads int
+	// DuplicateRows строки, данные которых уже были в кэше или встретились раньше
+	DuplicateRows int
+	RawBytes      int64
+	NewBytes      int64
+	// Storage объем хранения после переноса, nil при DryRun
+	Storage *repository.DataStorageStats
+}
+
+// SavedBytes объем, сэкономленный дедупликацией в обработанных строках
+func (r *BackfillReport) SavedBytes() int64 {
+	return r.RawBytes - r.NewBytes
+}
+
+type DataBackfillService interface {
+	Backfill(ctx context.Context, batchSize int, dryRun bool) (*BackfillReport, error)
+}
+
+type dataBackfillService struct {
+	repo   repository.DataBackfillRepository
+	logger *zap.Logger
+}
+
+func NewDataBackfillService(repo repository.DataBackfillRepository, logger *zap.Logger) DataBackfillService {
+	return &dataBackfillService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// dataHash хэш данных в том же виде, что и в миграции 004 и у воркера: SHA-256 текста JSONB
+func dataHash(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// Backfill считает хэши строк, сохраненных до перехода на кэш, добавляет данные в
+// verification_data_cache и переводит строки на ссылку по хэшу. Строки обрабатываются пачками,
+// поэтому перенос можно выполнить до запуска миграции 005 на большой таблице.
+func (s *dataBackfillService) Backfill(ctx context.Context, batchSize int, dryRun bool) (*BackfillReport, error) {
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	report := &BackfillReport{DryRun: dryRun}
+
+	legacy, err := s.repo.HasLegacyDataColumn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.LegacyColumn = legacy
+
+	if legacy {
+		seen := make(map[string]bool)
+		afterID := ""
+		for {
+			batch, err := s.repo.GetLegacyBatch(ctx, afterID, batchSize)
+			if err != nil {
+				return report, err
+			}
+			if len(batch) == 0 {
+				break
+			}
+
+			for _, row := range batch {
+				afterID = row.ID
+				if row.Data == nil {
+					continue
+				}
+
+				hash := dataHash(*row.Data)
+				size := int64(len(*row.Data))
+				report.Rows++
+				report.RawBytes += size
+
+				isNew := !seen[hash]
+				if !dryRun {
+					isNew, err = s.repo.LinkToCache(ctx, row.ID, hash, *row.Data)
+					if err != nil {
+						return report, err
+					}
+				}
+				seen[hash] = true
+
+				if isNew {
+					report.NewPayloads++
+					report.NewBytes += size
+				} else {
+					report.DuplicateRows++
+				}
+			}
+
+			s.logger.Info("backfill batch processed", zap.Int("rows", report.Rows), zap.Int("new_payloads", report.NewPayloads))
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	report.Storage, err = s.repo.GetStorageStats(ctx)
+	if err != nil {
+		return report, err
+	}
+	return report, nil
+}
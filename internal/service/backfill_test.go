@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/types"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для DataBackfillRepository
+type mockDataBackfillRepository struct {
+	legacyColumn bool
+	rows         []*types.VerificationDataWithHash
+	cache        map[string]string
+	linked       map[string]string
+}
+
+func (m *mockDataBackfillRepository) HasLegacyDataColumn(ctx context.Context) (bool, error) {
+	return m.legacyColumn, nil
+}
+
+func (m *mockDataBackfillRepository) GetLegacyBatch(ctx context.Context, afterID string, limit int) ([]*types.VerificationDataWithHash, error) {
+	var batch []*types.VerificationDataWithHash
+	for _, row := range m.rows {
+		if row.ID > afterID && m.linked[row.ID] == "" && len(batch) < limit {
+			batch = append(batch, row)
+		}
+	}
+	return batch, nil
+}
+
+func (m *mockDataBackfillRepository) LinkToCache(ctx context.Context, rowID, hash, data string) (bool, error) {
+	m.linked[rowID] = hash
+	if _, ok := m.cache[hash]; ok {
+		return false, nil
+	}
+	m.cache[hash] = data
+	return true, nil
+}
+
+func (m *mockDataBackfillRepository) GetStorageStats(ctx context.Context) (*repository.DataStorageStats, error) {
+	return &repository.DataStorageStats{ReferencingRows: int64(len(m.linked)), UniquePayloads: int64(len(m.cache))}, nil
+}
+
+func legacyRow(id, data string) *types.VerificationDataWithHash {
+	return &types.VerificationDataWithHash{ID: id, DataType: "BASIC_INFORMATION", Data: &data}
+}
+
+func TestBackfill(t *testing.T) {
+	newRepo := func() *mockDataBackfillRepository {
+		return &mockDataBackfillRepository{
+			legacyColumn: true,
+			rows: []*types.VerificationDataWithHash{
+				legacyRow("1", `{"name": "Test Company"}`),
+				legacyRow("2", `{"name": "Test Company"}`),
+				legacyRow("3", `{"name": "Other Company"}`),
+			},
+			cache:  map[string]string{dataHash(`{"name": "Other Company"}`): `{"name": "Other Company"}`},
+			linked: map[string]string{},
+		}
+	}
+
+	t.Run("links_rows_and_reports_savings", func(t *testing.T) {
+		repo := newRepo()
+		service := NewDataBackfillService(repo, zaptest.NewLogger(t))
+
+		report, err := service.Backfill(context.Background(), 2, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if report.Rows != 3 || report.NewPayloads != 1 || report.DuplicateRows != 2 {
+			t.Errorf("expected 3 rows, 1 new payload and 2 duplicates, but got %+v", report)
+		}
+		if report.SavedBytes() != report.RawBytes-int64(len(`{"name": "Test Company"}`)) {
+			t.Errorf("unexpected saved bytes %d of %d", report.SavedBytes(), report.RawBytes)
+		}
+		if len(repo.linked) != 3 || repo.linked["1"] != repo.linked["2"] {
+			t.Errorf("expected identical payloads to share a hash, but got %v", repo.linked)
+		}
+		if report.Storage == nil || report.Storage.UniquePayloads != 2 {
+			t.Errorf("expected storage stats with 2 unique payloads, but got %+v", report.Storage)
+		}
+	})
+
+	t.Run("dry_run_does_not_write", func(t *testing.T) {
+		repo := newRepo()
+		service := NewDataBackfillService(repo, zaptest.NewLogger(t))
+
+		report, err := service.Backfill(context.Background(), 2, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(repo.linked) != 0 {
+			t.Errorf("expected no rows to be linked in dry run, but got %v", repo.linked)
+		}
+		if report.Rows != 3 || report.NewPayloads != 2 || report.Storage != nil {
+			t.Errorf("unexpected dry run report %+v", report)
+		}
+	})
+
+	t.Run("already_migrated", func(t *testing.T) {
+		repo := newRepo()
+		repo.legacyColumn = false
+		service := NewDataBackfillService(repo, zaptest.NewLogger(t))
+
+		report, err := service.Backfill(context.Background(), 2, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.LegacyColumn || report.Rows != 0 || report.Storage == nil {
+			t.Errorf("expected only storage stats, but got %+v", report)
+		}
+	})
+
+	t.Run("invalid_batch_size", func(t *testing.T) {
+		service := NewDataBackfillService(newRepo(), zaptest.NewLogger(t))
+
+		if _, err := service.Backfill(context.Background(), 0, false); err == nil || !containsError(err.Error(), "batch size must be positive") {
+			t.Errorf("expected batch size error, but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/report"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/signing"
+
+	"go.uber.org/zap"
+)
+
+// maxCaseParties ограничивает размер дела: отчет по делу формируется целиком
+const maxCaseParties = 100
+
+// CaseService управляет делами, объединяющими проверки связанных компаний: субъекта дела,
+// других компаний его учредителей и аффилированных лиц
+type CaseService interface {
+	CreateCase(ctx context.Context, name string, parties []*model.CasePartyInput) (*model.Case, error)
+	GetCase(ctx context.Context, id string) (*model.Case, error)
+	ListCases(ctx context.Context, limit *int32, offset *int32) ([]*model.Case, error)
+	AddVerification(ctx context.Context, caseID, verificationID string, role model.CasePartyRole) (*model.Case, error)
+	RemoveVerification(ctx context.Context, caseID, verificationID string) (*model.Case, error)
+	// ExportCaseReportPDF формирует один отчет по всем проверкам дела
+	ExportCaseReportPDF(ctx context.Context, id string) (*SignedPDF, error)
+}
+
+type caseService struct {
+	repo             repository.CaseRepository
+	verificationRepo repository.VerificationRepository
+	signer           *signing.Signer
+	logger           *zap.Logger
+	now              func() time.Time
+}
+
+// NewCaseService создает сервис дел. signer может быть nil, тогда отчет по делу недоступен.
+func NewCaseService(repo repository.CaseRepository, verificationRepo repository.VerificationRepository, signer *signing.Signer, logger *zap.Logger) CaseService {
+	return &caseService{
+		repo:             repo,
+		verificationRepo: verificationRepo,
+		signer:           signer,
+		logger:           logger,
+		now:              time.Now,
+	}
+}
+
+func (s *caseService) CreateCase(ctx context.Context, name string, parties []*model.CasePartyInput) (*model.Case, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, invalidInputf("case name cannot be empty")
+	}
+	if len([]rune(name)) > 255 {
+		return nil, invalidInputf("case name must not exceed 255 characters")
+	}
+	if len(parties) > maxCaseParties {
+		return nil, invalidInputf("case must not contain more than %d verifications", maxCaseParties)
+	}
+
+	author, err := caseAuthor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tenant := messaging.TenantOf(author)
+
+	records := make([]repository.CaseParty, 0, len(parties))
+	for _, party := range parties {
+		if err := s.checkParty(ctx, tenant, party.VerificationID, party.Role); err != nil {
+			return nil, err
+		}
+		records = append(records, repository.CaseParty{VerificationID: party.VerificationID, Role: party.Role})
+	}
+
+	c := &model.Case{Name: name, CreatedBy: author}
+	if err := s.repo.Create(ctx, tenant, c, records); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("case created",
+		zap.String("id", c.ID),
+		zap.String("tenant", tenant),
+		zap.Int("verifications", len(records)))
+	return s.GetCase(ctx, c.ID)
+}
+
+func (s *caseService) GetCase(ctx context.Context, id string) (*model.Case, error) {
+	if id == "" {
+		return nil, invalidInputf("case id cannot be empty")
+	}
+	if err := auth.CheckOperation(ctx, auth.OperationRead); err != nil {
+		return nil, err
+	}
+
+	c, err := s.repo.GetByID(ctx, requestTenant(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	c.Risk = caseRisk(c.Parties)
+	return c, nil
+}
+
+func (s *caseService) ListCases(ctx context.Context, limit *int32, offset *int32) ([]*model.Case, error) {
+	if err := auth.CheckOperation(ctx, auth.OperationRead); err != nil {
+		return nil, err
+	}
+
+	cases, err := s.repo.List(ctx, requestTenant(ctx), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cases {
+		c.Risk = caseRisk(c.Parties)
+	}
+	if cases == nil {
+		cases = []*model.Case{}
+	}
+	return cases, nil
+}
+
+func (s *caseService) AddVerification(ctx context.Context, caseID, verificationID string, role model.CasePartyRole) (*model.Case, error) {
+	if caseID == "" {
+		return nil, invalidInputf("case id cannot be empty")
+	}
+	author, err := caseAuthor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tenant := messaging.TenantOf(author)
+
+	if err := s.checkParty(ctx, tenant, verificationID, role); err != nil {
+		return nil, err
+	}
+
+	c, err := s.repo.GetByID(ctx, tenant, caseID)
+	if err != nil {
+		return nil, err
+	}
+	inCase := slices.ContainsFunc(c.Parties, func(p *model.CaseParty) bool { return p.Verification.ID == verificationID })
+	if !inCase && len(c.Parties) >= maxCaseParties {
+		return nil, invalidInputf("case must not contain more than %d verifications", maxCaseParties)
+	}
+
+	if err := s.repo.AddParty(ctx, tenant, caseID, repository.CaseParty{VerificationID: verificationID, Role: role}); err != nil {
+		return nil, err
+	}
+	return s.GetCase(ctx, caseID)
+}
+
+func (s *caseService) RemoveVerification(ctx context.Context, caseID, verificationID string) (*model.Case, error) {
+	if caseID == "" || verificationID == "" {
+		return nil, invalidInputf("case id and verification id cannot be empty")
+	}
+	author, err := caseAuthor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RemoveParty(ctx, messaging.TenantOf(author), caseID, verificationID); err != nil {
+		return nil, err
+	}
+	return s.GetCase(ctx, caseID)
+}
+
+// ExportCaseReportPDF формирует PDF со сводным риском и всеми проверками дела и подписывает его ключом шлюза
+func (s *caseService) ExportCaseReportPDF(ctx context.Context, id string) (*SignedPDF, error) {
+	if s.signer == nil {
+		return nil, fmt.Errorf("signing key is not configured")
+	}
+
+	c, err := s.GetCase(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	content := report.RenderTextPDF("Case report", caseReportLines(c, s.now().UTC()))
+
+	return &SignedPDF{
+		FileName:  fmt.Sprintf("case-%s-report.pdf", id),
+		Content:   content,
+		Signature: s.signer.Sign(content),
+		KeyID:     s.signer.KeyID(),
+	}, nil
+}
+
+// checkParty проверяет, что проверку можно добавить в дело арендатора tenant: дело объединяет
+// только проверки своей организации
+func (s *caseService) checkParty(ctx context.Context, tenant, verificationID string, role model.CasePartyRole) error {
+	if verificationID == "" {
+		return invalidInputf("verification id cannot be empty")
+	}
+	if !role.IsValid() {
+		return invalidInputf("invalid case party role: %s", role)
+	}
+
+	verification, err := s.verificationRepo.GetByID(ctx, verificationID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get verification: %w", err)
+	}
+	if messaging.TenantOf(verification.AuthorEmail) != tenant {
+		return fmt.Errorf("verification %s belongs to another organization", verificationID)
+	}
+	return nil
+}
+
+// caseAuthor возвращает автора изменения дела. Дела меняют только аутентифицированные клиенты
+// с правом создавать проверки.
+func caseAuthor(ctx context.Context) (string, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || principal.Email == "" {
+		return "", auth.ErrUnauthenticated
+	}
+	if err := auth.CheckOperation(ctx, auth.OperationCreate); err != nil {
+		return "", err
+	}
+	return principal.Email, nil
+}
+
+// caseRisk сводит риск проверок дела: худший уровень, число проверок каждого уровня
+// и компании с худшим уровнем
+func caseRisk(parties []*model.CaseParty) *model.CaseRisk {
+	risk := &model.CaseRisk{HighestRiskInns: []string{}}
+	for _, party := range parties {
+		v := party.Verification
+		if v.RiskLevel == nil {
+			risk.Unscored++
+			continue
+		}
+
+		switch *v.RiskLevel {
+		case model.RiskLevelLow:
+			risk.Low++
+		case model.RiskLevelMedium:
+			risk.Medium++
+		case model.RiskLevelHigh:
+			risk.High++
+		}
+
+		// Уровни в model.AllRiskLevel перечислены по возрастанию риска
+		if risk.Level == nil || slices.Index(model.AllRiskLevel, *v.RiskLevel) > slices.Index(model.AllRiskLevel, *risk.Level) {
+			level := *v.RiskLevel
+			risk.Level = &level
+			risk.HighestRiskInns = risk.HighestRiskInns[:0]
+		}
+		if *v.RiskLevel == *risk.Level && !slices.Contains(risk.HighestRiskInns, v.Inn) {
+			risk.HighestRiskInns = append(risk.HighestRiskInns, v.Inn)
+		}
+	}
+	return risk
+}
+
+func caseReportLines(c *model.Case, generatedAt time.Time) []string {
+	level := "not scored"
+	if c.Risk.Level != nil {
+		level = string(*c.Risk.Level)
+	}
+
+	lines := []string{
+		"Case ID:       " + c.ID,
+		"Name:          " + c.Name,
+		"Created by:    " + c.CreatedBy,
+		"Generated at:  " + generatedAt.Format(time.RFC3339),
+		fmt.Sprintf("Case risk:     %s (low %d, medium %d, high %d, not scored %d)",
+			level, c.Risk.Low, c.Risk.Medium, c.Risk.High, c.Risk.Unscored),
+		"Highest risk:  " + strings.Join(c.Risk.HighestRiskInns, ", "),
+		"",
+	}
+
+	for _, party := range c.Parties {
+		v := party.Verification
+		risk := "-"
+		if v.RiskLevel != nil {
+			risk = string(*v.RiskLevel)
+		}
+		lines = append(lines,
+			fmt.Sprintf("%-15s  INN %-12s  %-20s  risk %s", party.Role, v.Inn, v.Status, risk),
+			"  Verification:   "+v.ID+" created "+v.CreatedAt,
+			"  Requested data: "+joinDataTypes(v.RequestedDataTypes),
+		)
+		if len(v.MissingDataTypes) > 0 {
+			lines = append(lines, "  Missing data:   "+joinDataTypes(v.MissingDataTypes))
+		}
+		if v.ReviewState != "" {
+			lines = append(lines, "  Review:         "+string(v.ReviewState))
+		}
+		lines = append(lines, "")
+	}
+
+	return lines
+}
+
+// caseReportRows строки таблицы отчета по делу: заголовки и по строке на проверку
+func caseReportRows(c *model.Case) [][]string {
+	rows := [][]string{{"Role", "INN", "Status", "Risk level", "Review state", "Verification ID", "Created at",
+		"Requested data", "Missing data"}}
+	for _, party := range c.Parties {
+		v := party.Verification
+		risk := ""
+		if v.RiskLevel != nil {
+			risk = string(*v.RiskLevel)
+		}
+		rows = append(rows, []string{string(party.Role), v.Inn, string(v.Status), risk, string(v.ReviewState), v.ID,
+			v.CreatedAt, joinDataTypes(v.RequestedDataTypes), joinDataTypes(v.MissingDataTypes)})
+	}
+	return rows
+}
+
+func joinDataTypes(dataTypes []model.VerificationDataType) string {
+	names := make([]string, len(dataTypes))
+	for i, dataType := range dataTypes {
+		names[i] = string(dataType)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"slices"
+	"strings"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/signing"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// memoryCaseRepository хранит дела в памяти
+type memoryCaseRepository struct {
+	verifications map[string]*model.Verification
+	cases         map[string]*model.Case
+	tenants       map[string]string
+}
+
+func newMemoryCaseRepository(verifications ...*model.Verification) *memoryCaseRepository {
+	repo := &memoryCaseRepository{
+		verifications: map[string]*model.Verification{},
+		cases:         map[string]*model.Case{},
+		tenants:       map[string]string{},
+	}
+	for _, v := range verifications {
+		repo.verifications[v.ID] = v
+	}
+	return repo
+}
+
+func (r *memoryCaseRepository) Create(ctx context.Context, tenant string, c *model.Case, parties []repository.CaseParty) error {
+	c.ID = "case-1"
+	r.cases[c.ID], r.tenants[c.ID] = c, tenant
+	for _, party := range parties {
+		if err := r.AddParty(ctx, tenant, c.ID, party); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *memoryCaseRepository) GetByID(ctx context.Context, tenant, id string) (*model.Case, error) {
+	c, ok := r.cases[id]
+	if !ok || r.tenants[id] != tenant {
+		return nil, errNotFound("case not found: %s", id)
+	}
+	copied := *c
+	copied.Parties = slices.Clone(c.Parties)
+	return &copied, nil
+}
+
+func (r *memoryCaseRepository) List(ctx context.Context, tenant string, limit *int32, offset *int32) ([]*model.Case, error) {
+	var cases []*model.Case
+	for id := range r.cases {
+		if c, err := r.GetByID(ctx, tenant, id); err == nil {
+			cases = append(cases, c)
+		}
+	}
+	return cases, nil
+}
+
+func (r *memoryCaseRepository) AddParty(ctx context.Context, tenant, caseID string, party repository.CaseParty) error {
+	c, err := r.GetByID(ctx, tenant, caseID)
+	if err != nil {
+		return err
+	}
+	c.Parties = slices.DeleteFunc(c.Parties, func(p *model.CaseParty) bool { return p.Verification.ID == party.VerificationID })
+	c.Parties = append(c.Parties, &model.CaseParty{Verification: r.verifications[party.VerificationID], Role: party.Role})
+	r.cases[caseID] = c
+	return nil
+}
+
+func (r *memoryCaseRepository) RemoveParty(ctx context.Context, tenant, caseID, verificationID string) error {
+	c, err := r.GetByID(ctx, tenant, caseID)
+	if err != nil {
+		return err
+	}
+	c.Parties = slices.DeleteFunc(c.Parties, func(p *model.CaseParty) bool { return p.Verification.ID == verificationID })
+	r.cases[caseID] = c
+	return nil
+}
+
+func riskLevel(level model.RiskLevel) *model.RiskLevel {
+	return &level
+}
+
+func newTestCaseService(t *testing.T, signer *signing.Signer) (CaseService, context.Context) {
+	verifications := []*model.Verification{
+		{ID: "v-1", Inn: "7707083893", AuthorEmail: "analyst@bank.ru", Status: model.VerificationStatusCompleted, RiskLevel: riskLevel(model.RiskLevelMedium)},
+		{ID: "v-2", Inn: "7736050003", AuthorEmail: "other@bank.ru", Status: model.VerificationStatusCompleted, RiskLevel: riskLevel(model.RiskLevelHigh)},
+		{ID: "v-3", Inn: "500100732259", AuthorEmail: "analyst@bank.ru", Status: model.VerificationStatusInProcess},
+		{ID: "v-4", Inn: "7728168971", AuthorEmail: "partner@leasing.ru", Status: model.VerificationStatusCompleted},
+	}
+	verificationRepo := &mockVerificationRepository{
+		getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+			for _, v := range verifications {
+				if v.ID == id {
+					return v, nil
+				}
+			}
+			return nil, errNotFound("verification not found: %s", id)
+		},
+	}
+
+	service := NewCaseService(newMemoryCaseRepository(verifications...), verificationRepo, signer, zaptest.NewLogger(t))
+	return service, auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@bank.ru"})
+}
+
+func TestCreateCase(t *testing.T) {
+	service, ctx := newTestCaseService(t, nil)
+
+	c, err := service.CreateCase(ctx, " Holding ", []*model.CasePartyInput{
+		{VerificationID: "v-1", Role: model.CasePartyRoleSubject},
+		{VerificationID: "v-2", Role: model.CasePartyRoleFounderCompany},
+		{VerificationID: "v-3", Role: model.CasePartyRoleAffiliate},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Name != "Holding" || c.CreatedBy != "analyst@bank.ru" || len(c.Parties) != 3 {
+		t.Errorf("unexpected case %+v", c)
+	}
+
+	risk := c.Risk
+	if risk.Level == nil || *risk.Level != model.RiskLevelHigh {
+		t.Errorf("expected case risk HIGH, but got %v", risk.Level)
+	}
+	if risk.Low != 0 || risk.Medium != 1 || risk.High != 1 || risk.Unscored != 1 {
+		t.Errorf("unexpected risk counts %+v", risk)
+	}
+	if !slices.Equal(risk.HighestRiskInns, []string{"7736050003"}) {
+		t.Errorf("expected highest risk company 7736050003, but got %v", risk.HighestRiskInns)
+	}
+
+	if _, err := service.CreateCase(ctx, "   ", nil); err == nil {
+		t.Error("expected empty name to be rejected")
+	}
+	if _, err := service.CreateCase(context.Background(), "Holding", nil); err == nil {
+		t.Error("expected anonymous request to be rejected")
+	}
+}
+
+func TestCaseMembership(t *testing.T) {
+	service, ctx := newTestCaseService(t, nil)
+
+	c, err := service.CreateCase(ctx, "Holding", []*model.CasePartyInput{{VerificationID: "v-1", Role: model.CasePartyRoleSubject}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := service.AddVerification(ctx, c.ID, "v-4", model.CasePartyRoleAffiliate); err == nil || !strings.Contains(err.Error(), "another organization") {
+		t.Errorf("expected verification of another organization to be rejected, but got %v", err)
+	}
+
+	c, err = service.AddVerification(ctx, c.ID, "v-1", model.CasePartyRoleAffiliate)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.Parties) != 1 || c.Parties[0].Role != model.CasePartyRoleAffiliate {
+		t.Errorf("expected role of the verification to change, but got %+v", c.Parties)
+	}
+
+	c, err = service.RemoveVerification(ctx, c.ID, "v-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.Parties) != 0 || c.Risk.Level != nil {
+		t.Errorf("expected empty case without risk level, but got %+v", c)
+	}
+
+	otherTenant := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "partner@leasing.ru"})
+	if _, err := service.GetCase(otherTenant, c.ID); err == nil {
+		t.Error("expected case of another organization to be hidden")
+	}
+}
+
+func TestExportCaseReportPDF(t *testing.T) {
+	signer, err := signing.NewSigner(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	service, ctx := newTestCaseService(t, signer)
+
+	c, err := service.CreateCase(ctx, "Holding", []*model.CasePartyInput{
+		{VerificationID: "v-1", Role: model.CasePartyRoleSubject},
+		{VerificationID: "v-3", Role: model.CasePartyRoleAffiliate},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	export, err := service.ExportCaseReportPDF(ctx, c.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if export.FileName != "case-case-1-report.pdf" || !ed25519.Verify(signer.PublicKey(), export.Content, export.Signature) {
+		t.Errorf("expected signed report, but got %s", export.FileName)
+	}
+	for _, expected := range []string{"Case risk:     MEDIUM", "SUBJECT          INN 7707083893", "AFFILIATE        INN 500100732259"} {
+		if !bytes.Contains(export.Content, []byte(expected)) {
+			t.Errorf("expected report to contain %q", expected)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/catalog"
+)
+
+type CatalogService interface {
+	ListDataTypes(ctx context.Context) []*model.DataTypeInfo
+	ListCustomDataTypes(ctx context.Context) []*model.CustomDataTypeInfo
+	ListEnums(ctx context.Context) []*model.EnumInfo
+}
+
+type catalogService struct {
+	registry     *catalog.Registry
+	deprecations *catalog.Deprecations
+}
+
+func NewCatalogService(registry *catalog.Registry, deprecations *catalog.Deprecations) CatalogService {
+	return &catalogService{registry: registry, deprecations: deprecations}
+}
+
+// ListDataTypes возвращает каталог типов данных с учетом ограничений ключа вызывающего
+func (s *catalogService) ListDataTypes(ctx context.Context) []*model.DataTypeInfo {
+	entries := s.registry.All()
+	result := make([]*model.DataTypeInfo, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Custom {
+			continue
+		}
+		result = append(result, &model.DataTypeInfo{
+			Type:                  entry.Type,
+			Name:                  entry.Name,
+			Description:           entry.Description,
+			Provider:              entry.Provider,
+			SchemaVersion:         int32(s.registry.SchemaVersion(entry.Type)),
+			TypicalLatencySeconds: int32(entry.TypicalLatency / time.Second),
+			CostTier:              entry.CostTier,
+			Available:             entry.Available,
+			Allowed:               entry.Available && auth.DataTypeAllowed(ctx, string(entry.Type)),
+		})
+	}
+	return result
+}
+
+// ListCustomDataTypes возвращает типы данных, объявленные в конфигурации, с учетом ограничений ключа вызывающего
+func (s *catalogService) ListCustomDataTypes(ctx context.Context) []*model.CustomDataTypeInfo {
+	custom := s.registry.Custom()
+	result := make([]*model.CustomDataTypeInfo, 0, len(custom))
+	for _, entry := range custom {
+		result = append(result, &model.CustomDataTypeInfo{
+			Type:                  string(entry.Type),
+			Name:                  entry.Name,
+			Description:           entry.Description,
+			Provider:              entry.Provider,
+			SchemaVersion:         int32(entry.SchemaVersion),
+			TypicalLatencySeconds: int32(entry.TypicalLatency / time.Second),
+			CostTier:              entry.CostTier,
+			Available:             entry.Available,
+			Allowed:               entry.Available && auth.DataTypeAllowed(ctx, string(entry.Type)),
+			Schema:                string(entry.Schema),
+		})
+	}
+	return result
+}
+
+// ListEnums возвращает значения перечислений с пометками об устаревании
+func (s *catalogService) ListEnums(ctx context.Context) []*model.EnumInfo {
+	return s.deprecations.EnumCatalog()
+}
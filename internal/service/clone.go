@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"scoring_api_gateway/graph/model"
+
+	"go.uber.org/zap"
+)
+
+// CloneService повторяет проверку: создает новую проверку той же компании с теми же параметрами.
+// Тегов у проверок шлюза нет, а наблюдатели компании определяются по ИНН, поэтому копируются
+// типы данных и идентификатор внешней системы.
+type CloneService interface {
+	// CloneVerification создает проверку компании проверки id от имени authorEmail. dataTypes
+	// ограничивает новую проверку частью запрошенных исходной типов, nil повторяет все
+	CloneVerification(ctx context.Context, id string, dataTypes []model.VerificationDataType, authorEmail string) (*model.Verification, error)
+}
+
+type cloneService struct {
+	verifications VerificationService
+	logger        *zap.Logger
+}
+
+// NewCloneService создает сервис поверх verifications: клон проходит те же проверки доступа,
+// ограничения арендатора и журнал событий, что и проверка, созданная createVerification
+func NewCloneService(verifications VerificationService, logger *zap.Logger) CloneService {
+	return &cloneService{
+		verifications: verifications,
+		logger:        logger,
+	}
+}
+
+func (s *cloneService) CloneVerification(ctx context.Context, id string, dataTypes []model.VerificationDataType, authorEmail string) (*model.Verification, error) {
+	source, err := s.verifications.GetVerification(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	requested := source.RequestedDataTypes
+	// Типы, объявленные в конфигурации, повторяются только в полном клоне
+	customDataTypes := source.CustomDataTypes
+	if dataTypes != nil {
+		for _, dataType := range dataTypes {
+			if !slices.Contains(requested, dataType) {
+				return nil, invalidInputf("data type %s was not requested in verification %s", dataType, id)
+			}
+		}
+		requested, customDataTypes = dataTypes, nil
+	}
+
+	opts := CreateOptions{CustomDataTypes: customDataTypes}
+	if source.ExternalRef != nil {
+		opts.ExternalRef = &model.ExternalRefInput{System: source.ExternalRef.System, Ref: source.ExternalRef.Ref}
+	}
+
+	verification, err := s.verifications.CreateVerificationWithOptions(ctx, source.Inn, requested, authorEmail, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("verification cloned", zap.String("source_id", id), zap.String("verification_id", verification.ID))
+	return verification, nil
+}
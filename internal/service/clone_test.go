@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/domain"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCloneVerification(t *testing.T) {
+	system := "crm"
+	source := &model.Verification{
+		ID:     "v-1",
+		Inn:    "7707083893",
+		Status: model.VerificationStatusCompleted,
+		RequestedDataTypes: []model.VerificationDataType{
+			model.VerificationDataTypeBasicInformation, model.VerificationDataTypeActivities,
+		},
+		ExternalRef: &model.ExternalRef{System: &system, Ref: "DEAL-1"},
+	}
+
+	tests := []struct {
+		name          string
+		dataTypes     []model.VerificationDataType
+		ctx           context.Context
+		expectedTypes []domain.DataType
+		expectedError string
+	}{
+		{name: "all_types", expectedTypes: []domain.DataType{domain.DataTypeBasicInformation, domain.DataTypeActivities}},
+		{name: "subset", dataTypes: []model.VerificationDataType{model.VerificationDataTypeActivities},
+			expectedTypes: []domain.DataType{domain.DataTypeActivities}},
+		{name: "type_not_requested", dataTypes: []model.VerificationDataType{model.VerificationDataTypeAffiliatedCompanies},
+			expectedError: "data type AFFILIATED_COMPANIES was not requested in verification v-1"},
+		{name: "empty_subset", dataTypes: []model.VerificationDataType{}, expectedError: "at least one data type must be requested"},
+		{name: "no_create_scope", ctx: auth.WithPrincipal(context.Background(), &auth.Principal{
+			Email: "partner@bank.ru", Scope: &auth.Scope{Operations: []auth.Operation{auth.OperationRead}}}),
+			expectedError: "access denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *model.ExternalRef
+			var published *domain.Verification
+			repo := &mockVerificationRepository{
+				getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+					if id == source.ID {
+						return source, nil
+					}
+					return nil, errNotFound("verification not found: %s", id)
+				},
+				setExternalRefFunc: func(ctx context.Context, id string, ref *model.ExternalRef) error {
+					saved = ref
+					return nil
+				},
+			}
+			nats := &mockNATSClient{publishVerificationRequestFunc: func(ctx context.Context, verification *domain.Verification) error {
+				published = verification
+				return nil
+			}}
+			service := NewCloneService(NewVerificationService(repo, nats, nil, zaptest.NewLogger(t)), zaptest.NewLogger(t))
+
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			clone, err := service.CloneVerification(ctx, source.ID, tt.dataTypes, "analyst@example.com")
+
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', but got %v", tt.expectedError, err)
+				}
+				if published != nil {
+					t.Errorf("expected no verification to be published, but got %+v", published)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if clone.ID == source.ID || published == nil || published.ID != clone.ID {
+				t.Fatalf("expected a new verification to be published, but got %+v", published)
+			}
+			if published.INN != source.Inn || published.AuthorEmail != "analyst@example.com" || !reflect.DeepEqual(published.RequestedDataTypes, tt.expectedTypes) {
+				t.Errorf("unexpected clone %+v", published)
+			}
+			if saved == nil || saved.Ref != "DEAL-1" || saved.System == nil || *saved.System != system {
+				t.Errorf("expected external ref to be copied, but got %+v", saved)
+			}
+		})
+	}
+}
+
+func TestCloneVerificationNotFound(t *testing.T) {
+	service := NewCloneService(NewVerificationService(&mockVerificationRepository{}, &mockNATSClient{}, nil, zaptest.NewLogger(t)), zaptest.NewLogger(t))
+
+	if _, err := service.CloneVerification(context.Background(), "missing", nil, "analyst@example.com"); err == nil || !containsError(err.Error(), "verification not found") {
+		t.Errorf("expected not found error, but got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/catalog"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// CompanyDataService отдает последние доставленные данные компании из кэша по составному ключу.
+// Кэш разделен по арендаторам - доменам email авторов проверок. Данные типов, для которых
+// администратор включил совместное использование, видны всем арендаторам.
+type CompanyDataService interface {
+	GetLatestCompanyData(ctx context.Context, inn string, dataType model.VerificationDataType, schemaVersion *int32) (*model.VerificationData, error)
+	CacheSharing(ctx context.Context) ([]*model.CacheSharingPolicy, error)
+	SetCacheSharing(ctx context.Context, dataType model.VerificationDataType, shared bool) (*model.CacheSharingPolicy, error)
+}
+
+type companyDataService struct {
+	cache    repository.DataCacheRepository
+	registry *catalog.Registry
+	// shareable типы данных, совместное использование которых разрешено договорами с поставщиками
+	shareable map[model.VerificationDataType]bool
+	logger    *zap.Logger
+}
+
+func NewCompanyDataService(cache repository.DataCacheRepository, registry *catalog.Registry, shareableDataTypes []string, logger *zap.Logger) CompanyDataService {
+	shareable := make(map[model.VerificationDataType]bool, len(shareableDataTypes))
+	for _, dataType := range shareableDataTypes {
+		shareable[model.VerificationDataType(dataType)] = true
+	}
+
+	return &companyDataService{
+		cache:     cache,
+		registry:  registry,
+		shareable: shareable,
+		logger:    logger,
+	}
+}
+
+// GetLatestCompanyData возвращает данные в запрошенной версии формата поставщика, по умолчанию - в текущей
+// версии каталога. Данные, доставленные только в другой версии, не возвращаются: nil.
+func (s *companyDataService) GetLatestCompanyData(ctx context.Context, inn string, dataType model.VerificationDataType, schemaVersion *int32) (*model.VerificationData, error) {
+	if len(inn) != 10 && len(inn) != 12 {
+		return nil, invalidInputf("inn must be 10 or 12 digits, got %d", len(inn))
+	}
+	if !dataType.IsValid() {
+		return nil, invalidInputf("invalid data type: %s", dataType)
+	}
+
+	version := s.registry.SchemaVersion(dataType)
+	if schemaVersion != nil {
+		if *schemaVersion < 1 {
+			return nil, invalidInputf("invalid schema version: %d", *schemaVersion)
+		}
+		version = int(*schemaVersion)
+	}
+
+	if err := auth.CheckOperation(ctx, auth.OperationRead); err != nil {
+		return nil, err
+	}
+	if err := auth.CheckDataTypes(ctx, []string{string(dataType)}); err != nil {
+		return nil, err
+	}
+
+	tenant := requestTenant(ctx)
+	ctx = repository.WithTenant(ctx, tenant)
+	data, err := s.cache.GetByKey(ctx, repository.CacheKey{Tenant: tenant, INN: inn, DataType: dataType, SchemaVersion: version})
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// CacheSharing возвращает настройку каждого типа данных, в том числе ни разу не менявшуюся
+func (s *companyDataService) CacheSharing(ctx context.Context) ([]*model.CacheSharingPolicy, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	stored, err := s.cache.ListSharing(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byType := make(map[model.VerificationDataType]*model.CacheSharingPolicy, len(stored))
+	for _, policy := range stored {
+		byType[policy.DataType] = policy
+	}
+
+	policies := make([]*model.CacheSharingPolicy, 0, len(model.AllVerificationDataType))
+	for _, dataType := range model.AllVerificationDataType {
+		policy, ok := byType[dataType]
+		if !ok {
+			policy = &model.CacheSharingPolicy{DataType: dataType}
+		}
+		policy.Shareable = s.shareable[dataType]
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// SetCacheSharing включает или выключает совместное использование данных типа. Включить его можно
+// только для типов из CACHE_SHAREABLE_DATA_TYPES, выключить - для любого.
+func (s *companyDataService) SetCacheSharing(ctx context.Context, dataType model.VerificationDataType, shared bool) (*model.CacheSharingPolicy, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if !dataType.IsValid() {
+		return nil, invalidInputf("invalid data type: %s", dataType)
+	}
+	if shared && !s.shareable[dataType] {
+		return nil, fmt.Errorf("sharing of %s is not allowed: the data type is not in CACHE_SHAREABLE_DATA_TYPES", dataType)
+	}
+
+	principal, _ := auth.PrincipalFromContext(ctx)
+	policy, err := s.cache.SetSharing(ctx, dataType, shared, principal.Email)
+	if err != nil {
+		return nil, err
+	}
+	policy.Shareable = s.shareable[dataType]
+
+	s.logger.Info("cache sharing changed", zap.String("data_type", string(dataType)), zap.Bool("shared", shared), zap.String("updated_by", principal.Email))
+	return policy, nil
+}
+
+// requestTenant арендатор запроса - домен email пользователя, как у проверок, которые он создает
+func requestTenant(ctx context.Context) string {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return messaging.DefaultTenant
+	}
+	return messaging.TenantOf(principal.Email)
+}
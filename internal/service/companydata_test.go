@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/catalog"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для DataCacheRepository
+type mockDataCacheRepository struct {
+	repository.DataCacheRepository
+	entries map[string]string
+	keys    []repository.CacheKey
+	sharing []*model.CacheSharingPolicy
+}
+
+func (m *mockDataCacheRepository) GetByKey(ctx context.Context, key repository.CacheKey) (*model.VerificationData, error) {
+	if tenant, _ := repository.TenantFromContext(ctx); tenant != key.Tenant {
+		return nil, errors.New("tenant of the key does not match the context")
+	}
+	m.keys = append(m.keys, key)
+	data, ok := m.entries[key.String()]
+	if !ok {
+		return nil, errNotFound("no cached data for %s", key)
+	}
+	return &model.VerificationData{DataType: key.DataType, Data: data, SchemaVersion: int32(key.SchemaVersion)}, nil
+}
+
+func (m *mockDataCacheRepository) ListSharing(ctx context.Context) ([]*model.CacheSharingPolicy, error) {
+	return m.sharing, nil
+}
+
+func (m *mockDataCacheRepository) SetSharing(ctx context.Context, dataType model.VerificationDataType, shared bool, updatedBy string) (*model.CacheSharingPolicy, error) {
+	policy := &model.CacheSharingPolicy{DataType: dataType, Shared: shared, UpdatedBy: &updatedBy}
+	m.sharing = append(m.sharing, policy)
+	return policy, nil
+}
+
+func TestGetLatestCompanyData(t *testing.T) {
+	version := func(v int32) *int32 { return &v }
+
+	tests := []struct {
+		name          string
+		inn           string
+		schemaVersion *int32
+		expectedData  string
+		expectedError string
+	}{
+		{name: "current_version", inn: "7707083893", expectedData: `{"format": 1}`},
+		{name: "requested_version", inn: "7707083893", schemaVersion: version(2), expectedData: `{"format": 2}`},
+		{name: "only_old_version_cached", inn: "7736050003"},
+		{name: "invalid_version", inn: "7707083893", schemaVersion: version(0), expectedError: "invalid schema version: 0"},
+		{name: "invalid_inn", inn: "123", expectedError: "inn must be 10 or 12 digits"},
+		{name: "other_tenant", inn: "7702070139"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &mockDataCacheRepository{entries: map[string]string{
+				"example.com/7707083893:BASIC_INFORMATION:v1": `{"format": 1}`,
+				"example.com/7707083893:BASIC_INFORMATION:v2": `{"format": 2}`,
+				"example.com/7736050003:BASIC_INFORMATION:v0": `{"format": 0}`,
+				"other.com/7702070139:BASIC_INFORMATION:v1":   `{"format": 1}`,
+			}}
+			service := NewCompanyDataService(cache, catalog.DefaultRegistry(), nil, zaptest.NewLogger(t))
+			ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@Example.com"})
+
+			data, err := service.GetLatestCompanyData(ctx, tt.inn, model.VerificationDataTypeBasicInformation, tt.schemaVersion)
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error %q, but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.expectedData == "" {
+				if data != nil {
+					t.Errorf("expected no data, but got %+v", data)
+				}
+				return
+			}
+			if data == nil || data.Data != tt.expectedData {
+				t.Errorf("expected data %s, but got %+v", tt.expectedData, data)
+			}
+		})
+	}
+}
+
+func TestSetCacheSharing(t *testing.T) {
+	admin := &auth.Principal{Email: "admin@scoring.local", Roles: []string{auth.RoleAdmin}}
+
+	tests := []struct {
+		name          string
+		principal     *auth.Principal
+		dataType      model.VerificationDataType
+		shared        bool
+		expectedError string
+	}{
+		{name: "share_allowed_type", principal: admin, dataType: model.VerificationDataTypeBasicInformation, shared: true},
+		{name: "share_not_allowed_type", principal: admin, dataType: model.VerificationDataTypeArbitrageStatistics, shared: true, expectedError: "sharing of ARBITRAGE_STATISTICS is not allowed"},
+		{name: "unshare_any_type", principal: admin, dataType: model.VerificationDataTypeArbitrageStatistics},
+		{name: "not_admin", principal: &auth.Principal{Email: "analyst@example.com"}, dataType: model.VerificationDataTypeBasicInformation, shared: true, expectedError: "access denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &mockDataCacheRepository{}
+			service := NewCompanyDataService(cache, catalog.DefaultRegistry(), []string{"BASIC_INFORMATION"}, zaptest.NewLogger(t))
+
+			policy, err := service.SetCacheSharing(auth.WithPrincipal(context.Background(), tt.principal), tt.dataType, tt.shared)
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error %q, but got %v", tt.expectedError, err)
+				}
+				if len(cache.sharing) != 0 {
+					t.Error("expected sharing not to be saved")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if policy.Shared != tt.shared || *policy.UpdatedBy != admin.Email {
+				t.Errorf("unexpected policy %+v", policy)
+			}
+		})
+	}
+}
+
+func TestCacheSharing(t *testing.T) {
+	updatedBy := "admin@scoring.local"
+	cache := &mockDataCacheRepository{sharing: []*model.CacheSharingPolicy{
+		{DataType: model.VerificationDataTypeBasicInformation, Shared: true, UpdatedBy: &updatedBy},
+	}}
+	service := NewCompanyDataService(cache, catalog.DefaultRegistry(), []string{"BASIC_INFORMATION"}, zaptest.NewLogger(t))
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Email: updatedBy, Roles: []string{auth.RoleAdmin}})
+	policies, err := service.CacheSharing(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(policies) != len(model.AllVerificationDataType) {
+		t.Fatalf("expected a policy for every data type, but got %d", len(policies))
+	}
+	for _, policy := range policies {
+		basic := policy.DataType == model.VerificationDataTypeBasicInformation
+		if policy.Shared != basic || policy.Shareable != basic {
+			t.Errorf("unexpected policy %+v", policy)
+		}
+	}
+}
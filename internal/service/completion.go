@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/pubsub"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// completionPollInterval как часто ожидание перечитывает проверку: уведомление о завершении
+// может обработать другой экземпляр шлюза, о котором Relay без общей шины не сообщит
+const completionPollInterval = time.Second
+
+// eventVerificationCompleted событие Relay о завершении проверки, ключ - идентификатор проверки
+const eventVerificationCompleted = "verification_completed"
+
+// CompletionWaiter ждет завершения проверки для createVerification(waitForCompletion: true)
+// и подписки verificationCompleted
+type CompletionWaiter interface {
+	// Completed сообщает ожидающим запросам всех экземпляров, до которых доходит Relay, что проверка завершилась
+	Completed(verificationID string)
+	// Timeout проверяет время ожидания в секундах, заданное клиентом; nil - наибольшее допустимое
+	Timeout(seconds *int32) (time.Duration, error)
+	// Wait возвращает проверку с данными, если она завершилась за timeout, иначе verification без изменений
+	Wait(ctx context.Context, verification *model.Verification, timeout time.Duration) (*model.Verification, error)
+	// Subscribe отдает проверку с данными, когда она завершится, и закрывает канал. При отмене
+	// ctx канал закрывается без значения.
+	Subscribe(ctx context.Context, verification *model.Verification) <-chan *model.Verification
+}
+
+type completionWaiter struct {
+	repo         repository.VerificationRepository
+	completed    *pubsub.Broker[struct{}]
+	relay        pubsub.Relay
+	maxTimeout   time.Duration
+	pollInterval time.Duration
+	logger       *zap.Logger
+}
+
+// NewCompletionWaiter создает ожидание завершения не дольше maxTimeout. О завершениях, которые
+// обработали другие экземпляры, ожидание узнает через relay.
+func NewCompletionWaiter(repo repository.VerificationRepository, relay pubsub.Relay, maxTimeout time.Duration, logger *zap.Logger) CompletionWaiter {
+	w := &completionWaiter{
+		repo:         repo,
+		completed:    pubsub.NewBroker[struct{}](),
+		relay:        relay,
+		maxTimeout:   maxTimeout,
+		pollInterval: completionPollInterval,
+		logger:       logger,
+	}
+	relay.Handle(eventVerificationCompleted, func(verificationID string) {
+		w.completed.Publish(verificationID, struct{}{})
+	})
+	return w
+}
+
+func (w *completionWaiter) Completed(verificationID string) {
+	w.relay.Publish(context.Background(), eventVerificationCompleted, verificationID)
+}
+
+func (w *completionWaiter) Timeout(seconds *int32) (time.Duration, error) {
+	if seconds == nil {
+		return w.maxTimeout, nil
+	}
+	timeout := time.Duration(*seconds) * time.Second
+	if timeout <= 0 || timeout > w.maxTimeout {
+		return 0, invalidInputf("timeout must be between 1 and %d seconds", int(w.maxTimeout.Seconds()))
+	}
+	return timeout, nil
+}
+
+func (w *completionWaiter) Wait(ctx context.Context, verification *model.Verification, timeout time.Duration) (*model.Verification, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if current, ok := w.await(ctx, verification); ok {
+		return current, nil
+	}
+	// Проверка продолжается, клиент получит ее в статусе, с которым она была создана
+	return verification, nil
+}
+
+func (w *completionWaiter) Subscribe(ctx context.Context, verification *model.Verification) <-chan *model.Verification {
+	out := make(chan *model.Verification, 1)
+	go func() {
+		defer close(out)
+		if current, ok := w.await(ctx, verification); ok {
+			out <- current
+		}
+	}()
+	return out
+}
+
+// await возвращает завершенную проверку или false, если ctx отменен раньше
+func (w *completionWaiter) await(ctx context.Context, verification *model.Verification) (*model.Verification, bool) {
+	completed := w.completed.Subscribe(ctx, verification.ID)
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	// Проверки песочницы и ИНН из кэша ненайденных завершаются уже при создании
+	check := finalStatus(verification.Status)
+	for {
+		if check {
+			current, err := w.repo.GetByID(ctx, verification.ID)
+			switch {
+			case err == nil && finalStatus(current.Status):
+				return restrictData(ctx, current), true
+			case err != nil && ctx.Err() == nil && !errors.Is(err, repository.ErrNotFound):
+				w.logger.Warn("failed to read awaited verification", zap.Error(err), zap.String("verification_id", verification.ID))
+			}
+		}
+
+		select {
+		case <-completed:
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, false
+		}
+		check = true
+	}
+}
+
+// finalStatus сообщает, что проверка больше не изменит статус
+func finalStatus(status model.VerificationStatus) bool {
+	switch status {
+	case model.VerificationStatusPending, model.VerificationStatusPendingEmailConfirmation, model.VerificationStatusInProcess, model.VerificationStatusProcessing:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/pubsub"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCompletionWaiterTimeout(t *testing.T) {
+	waiter := NewCompletionWaiter(&mockVerificationRepository{}, pubsub.NewLocalRelay(), time.Minute, zaptest.NewLogger(t))
+
+	if timeout, err := waiter.Timeout(nil); err != nil || timeout != time.Minute {
+		t.Errorf("expected the largest timeout by default, but got %v, %v", timeout, err)
+	}
+	for _, seconds := range []int32{0, 61} {
+		if _, err := waiter.Timeout(&seconds); err == nil {
+			t.Errorf("expected timeout %d to be refused", seconds)
+		}
+	}
+}
+
+func TestCompletionWaiterWait(t *testing.T) {
+	var completed atomic.Bool
+	repo := &mockVerificationRepository{
+		getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+			if !completed.Load() {
+				return &model.Verification{ID: id, Status: model.VerificationStatusInProcess}, nil
+			}
+			return &model.Verification{ID: id, Status: model.VerificationStatusCompleted, Data: []*model.VerificationData{{DataType: model.VerificationDataTypeBasicInformation}}}, nil
+		},
+	}
+	waiter := NewCompletionWaiter(repo, pubsub.NewLocalRelay(), time.Minute, zaptest.NewLogger(t))
+	created := &model.Verification{ID: "v-1", Status: model.VerificationStatusInProcess}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		completed.Store(true)
+		waiter.Completed("v-1")
+	}()
+
+	started := time.Now()
+	verification, err := waiter.Wait(context.Background(), created, 5*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verification.Status != model.VerificationStatusCompleted || len(verification.Data) != 1 {
+		t.Errorf("expected completed verification with data, but got %+v", verification)
+	}
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Errorf("expected completion event to wake the waiter before the next poll, but it took %v", elapsed)
+	}
+}
+
+func TestCompletionWaiterWaitTimeout(t *testing.T) {
+	repo := &mockVerificationRepository{
+		getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+			return &model.Verification{ID: id, Status: model.VerificationStatusInProcess}, nil
+		},
+	}
+	waiter := NewCompletionWaiter(repo, pubsub.NewLocalRelay(), time.Minute, zaptest.NewLogger(t))
+	created := &model.Verification{ID: "v-1", Status: model.VerificationStatusInProcess}
+
+	verification, err := waiter.Wait(context.Background(), created, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verification != created {
+		t.Errorf("expected the created IN_PROCESS verification after timeout, but got %+v", verification)
+	}
+}
+
+func TestCompletionWaiterSubscribe(t *testing.T) {
+	var completed atomic.Bool
+	repo := &mockVerificationRepository{
+		getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+			if !completed.Load() {
+				return &model.Verification{ID: id, Status: model.VerificationStatusInProcess}, nil
+			}
+			return &model.Verification{ID: id, Status: model.VerificationStatusCompleted}, nil
+		},
+	}
+	relay := pubsub.NewLocalRelay()
+	// Подписчик подключен к одному экземпляру, уведомление о завершении обрабатывает другой
+	subscribed := NewCompletionWaiter(repo, relay, time.Minute, zaptest.NewLogger(t))
+	handling := NewCompletionWaiter(repo, relay, time.Minute, zaptest.NewLogger(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := subscribed.Subscribe(ctx, &model.Verification{ID: "v-1", Status: model.VerificationStatusInProcess})
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		completed.Store(true)
+		handling.Completed("v-1")
+	}()
+
+	select {
+	case verification, ok := <-updates:
+		if !ok || verification.Status != model.VerificationStatusCompleted {
+			t.Fatalf("expected completed verification, but got %+v", verification)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("expected completion event to reach the subscriber before the next poll")
+	}
+	if _, ok := <-updates; ok {
+		t.Error("expected subscription to finish after the completed verification")
+	}
+
+	cancelled, cancelSubscription := context.WithCancel(context.Background())
+	pending := subscribed.Subscribe(cancelled, &model.Verification{ID: "v-2", Status: model.VerificationStatusInProcess})
+	cancelSubscription()
+	if verification, ok := <-pending; ok {
+		t.Errorf("expected cancelled subscription to close without a value, but got %+v", verification)
+	}
+}
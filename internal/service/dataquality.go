@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/catalog"
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/payloads"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/validation"
+
+	"go.uber.org/zap"
+)
+
+type DataQualityService interface {
+	ValidateDeliveredData(ctx context.Context, id string) ([]*model.DataQuality, error)
+}
+
+type dataQualityService struct {
+	repo      repository.VerificationRepository
+	validator *validation.Validator
+	registry  *catalog.Registry
+	logger    *zap.Logger
+}
+
+func NewDataQualityService(repo repository.VerificationRepository, validator *validation.Validator, registry *catalog.Registry, logger *zap.Logger) DataQualityService {
+	return &dataQualityService{
+		repo:      repo,
+		validator: validator,
+		registry:  registry,
+		logger:    logger,
+	}
+}
+
+// ValidateDeliveredData проверяет доставленные данные по JSON Schema их типа и сохраняет результат.
+// Некорректные данные не отбрасываются, а помечаются ошибками, которые видны в dataQuality.
+// Данные в версии формата поставщика, отличной от каталога, по схеме не проверяются: схема описывает другую структуру.
+func (s *dataQualityService) ValidateDeliveredData(ctx context.Context, id string) ([]*model.DataQuality, error) {
+	verification, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verification: %w", err)
+	}
+
+	quality := make([]*model.DataQuality, 0, len(verification.Data))
+	for _, data := range verification.Data {
+		result, err := s.validate(ctx, id, data.DataType, data.SchemaVersion, data.Data)
+		if err != nil {
+			return nil, err
+		}
+		if result != nil {
+			quality = append(quality, &model.DataQuality{
+				DataType: data.DataType,
+				Valid:    result.Valid,
+				Errors:   append([]string{}, result.Errors...),
+			})
+		}
+	}
+	// Данные типов, объявленных в конфигурации, проверяются по их схемам, а результат виден
+	// в validationErrors этих данных
+	for _, data := range verification.CustomData {
+		if _, err := s.validate(ctx, id, model.VerificationDataType(data.DataType), data.SchemaVersion, data.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	return quality, nil
+}
+
+// validate проверяет и сохраняет результат проверки данных одного типа. Для данных, которые
+// по схеме не проверяются, возвращает nil.
+func (s *dataQualityService) validate(ctx context.Context, id string, dataType model.VerificationDataType, schemaVersion int32, payload string) (*validation.Result, error) {
+	if expected := s.registry.SchemaVersion(dataType); int(schemaVersion) != expected {
+		metrics.DataSchemaVersionMismatches.WithLabelValues(string(dataType), strconv.Itoa(int(schemaVersion))).Inc()
+		s.logger.Warn("delivered data has unexpected provider schema version",
+			zap.String("verification_id", id),
+			zap.String("data_type", string(dataType)),
+			zap.Int32("schema_version", schemaVersion),
+			zap.Int("expected_schema_version", expected))
+		return nil, nil
+	}
+
+	// Вместо данных больше предела хранится ссылка на объектное хранилище, схеме она не соответствует
+	if payloads.IsReference(payload) {
+		return nil, nil
+	}
+
+	result := s.validator.Validate(dataType, payload)
+	if err := s.repo.SetDataValidation(ctx, id, dataType, result.Errors); err != nil {
+		return nil, err
+	}
+
+	if !result.Valid {
+		metrics.DataValidationFailures.WithLabelValues(string(dataType)).Inc()
+		s.logger.Warn("delivered data does not match schema",
+			zap.String("verification_id", id),
+			zap.String("data_type", string(dataType)),
+			zap.Strings("errors", result.Errors))
+	}
+	return &result, nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/catalog"
+	"scoring_api_gateway/internal/validation"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestValidateDeliveredData(t *testing.T) {
+	validator, err := validation.NewValidator()
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	saved := make(map[model.VerificationDataType][]string)
+	repo := &mockVerificationRepository{
+		getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+			return &model.Verification{
+				ID: id,
+				Data: []*model.VerificationData{
+					{DataType: model.VerificationDataTypeBasicInformation, Data: `{"name": "Test Company", "inn": "7707083893"}`, SchemaVersion: 1},
+					{DataType: model.VerificationDataTypeArbitrageStatistics, Data: `{"totalCases": -1}`, SchemaVersion: 1},
+					{DataType: model.VerificationDataTypeActivities, Data: `{"okved": {"code": "62.01"}}`, SchemaVersion: 2},
+				},
+			}, nil
+		},
+		setValidationFunc: func(ctx context.Context, id string, dataType model.VerificationDataType, validationErrors []string) error {
+			saved[dataType] = validationErrors
+			return nil
+		},
+	}
+
+	service := NewDataQualityService(repo, validator, catalog.DefaultRegistry(), zaptest.NewLogger(t))
+	quality, err := service.ValidateDeliveredData(context.Background(), "test-id")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(quality) != 2 {
+		t.Fatalf("expected 2 quality entries, but got %d", len(quality))
+	}
+	if !quality[0].Valid || len(quality[0].Errors) != 0 {
+		t.Errorf("expected basic information to be valid, but got %+v", quality[0])
+	}
+	if quality[1].Valid || len(quality[1].Errors) != 1 {
+		t.Errorf("expected arbitrage statistics to be invalid, but got %+v", quality[1])
+	}
+
+	if len(saved) != 2 {
+		t.Errorf("expected validation to be saved for 2 data types, but got %v", saved)
+	}
+	if _, ok := saved[model.VerificationDataTypeActivities]; ok {
+		t.Error("expected payload in another provider schema version not to be validated")
+	}
+	if len(saved[model.VerificationDataTypeArbitrageStatistics]) != 1 {
+		t.Errorf("expected invalid payload to be stored with errors, but got %v", saved[model.VerificationDataTypeArbitrageStatistics])
+	}
+}
+
+func TestValidateDeliveredDataNotFound(t *testing.T) {
+	validator, err := validation.NewValidator()
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	service := NewDataQualityService(&mockVerificationRepository{}, validator, catalog.DefaultRegistry(), zaptest.NewLogger(t))
+	_, err = service.ValidateDeliveredData(context.Background(), "missing")
+	if err == nil || !containsError(err.Error(), "verification not found") {
+		t.Errorf("expected not found error, but got %v", err)
+	}
+}
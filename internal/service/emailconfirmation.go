@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// emailConfirmationVerificationService отдает проверки, удержанные до подтверждения email автора.
+// До подтверждения проверки нет ни в таблице verifications, ни в outbox.
+type emailConfirmationVerificationService struct {
+	VerificationService
+	holds  repository.EmailConfirmationRepository
+	logger *zap.Logger
+}
+
+// NewEmailConfirmationVerificationService оборачивает сервис проверок: удержанные проверки
+// возвращаются со статусом PENDING_EMAIL_CONFIRMATION
+func NewEmailConfirmationVerificationService(inner VerificationService, holds repository.EmailConfirmationRepository, logger *zap.Logger) VerificationService {
+	return &emailConfirmationVerificationService{
+		VerificationService: inner,
+		holds:               holds,
+		logger:              logger,
+	}
+}
+
+func (s *emailConfirmationVerificationService) GetVerification(ctx context.Context, id string) (*model.Verification, error) {
+	verification, err := s.VerificationService.GetVerification(ctx, id)
+	if !errors.Is(err, repository.ErrNotFound) {
+		return verification, err
+	}
+
+	msg, heldErr := s.holds.GetHeld(ctx, id)
+	if heldErr != nil {
+		s.logger.Error("failed to get held verification", zap.Error(heldErr), zap.String("id", id))
+		return nil, fmt.Errorf("failed to get verification: %w", heldErr)
+	}
+	if msg == nil {
+		return nil, err
+	}
+
+	held, convErr := msg.Verification()
+	if convErr != nil {
+		s.logger.Error("failed to restore held verification", zap.Error(convErr), zap.String("id", id))
+		return nil, fmt.Errorf("failed to get verification: %w", convErr)
+	}
+
+	createdAt := msg.CreatedAt.UTC()
+	held.Status = domain.StatusPendingEmailConfirmation
+	held.MissingDataTypes = []domain.DataType{}
+	held.CreatedAt = createdAt
+	held.UpdatedAt = createdAt
+	return model.VerificationFromDomain(held), nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type mockEmailConfirmationRepository struct {
+	repository.EmailConfirmationRepository
+	held map[string]*messaging.OutboxMessage
+}
+
+func (m *mockEmailConfirmationRepository) GetHeld(ctx context.Context, id string) (*messaging.OutboxMessage, error) {
+	return m.held[id], nil
+}
+
+func TestEmailConfirmationGetVerification(t *testing.T) {
+	payload, _ := json.Marshal(messaging.CreateVerificationMessage{
+		VerificationID: "v-held",
+		INN:            "7707083893",
+		AuthorEmail:    "new@bank.ru",
+		RequestedTypes: []domain.DataType{domain.DataTypeBasicInformation},
+	})
+	holds := &mockEmailConfirmationRepository{held: map[string]*messaging.OutboxMessage{
+		"v-held": {ID: "v-held", Tenant: "bank.ru", Payload: payload, CreatedAt: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)},
+	}}
+	repo := &mockVerificationRepository{
+		getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+			return nil, errNotFound("verification not found: %s", id)
+		},
+	}
+	service := NewEmailConfirmationVerificationService(NewVerificationService(repo, &mockNATSClient{}, nil, zaptest.NewLogger(t)), holds, zaptest.NewLogger(t))
+
+	verification, err := service.GetVerification(context.Background(), "v-held")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verification.Status != model.VerificationStatusPendingEmailConfirmation || verification.AuthorEmail != "new@bank.ru" ||
+		verification.CreatedAt != "2024-01-15T10:00:00Z" {
+		t.Errorf("expected held verification awaiting confirmation, but got %+v", verification)
+	}
+
+	if _, err := service.GetVerification(context.Background(), "v-missing"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected not found, but got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidInput аргументы запроса не прошли проверку. Резолверы и защита от перебора
+// проверяют его через errors.Is, а не по тексту ошибки.
+var ErrInvalidInput = errors.New("invalid input")
+
+// inputError ошибка проверки аргументов. Текст ошибки остается прежним,
+// а errors.Is(err, ErrInvalidInput) сравнивает ее с видом.
+type inputError struct {
+	err error
+}
+
+func (e *inputError) Error() string {
+	return e.err.Error()
+}
+
+func (e *inputError) Unwrap() []error {
+	return []error{ErrInvalidInput, e.err}
+}
+
+func invalidInputf(format string, args ...any) error {
+	return &inputError{err: fmt.Errorf(format, args...)}
+}
+
+// invalidInput помечает ошибку проверки из других пакетов (метаданные, шаблоны вебхуков)
+func invalidInput(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &inputError{err: err}
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/eventstore"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// appendAttempts количество попыток дописать события при конкурентном изменении потока
+const appendAttempts = 3
+
+// rebuildPageSize размер страницы потоков при перестроении модели чтения
+const rebuildPageSize = 500
+
+type EventSourcingService interface {
+	RecordCreated(ctx context.Context, verification *model.Verification) error
+	RecordCompletion(ctx context.Context, completed *model.Verification, missing []model.VerificationDataType) error
+	RecordStatusChange(ctx context.Context, id string, status model.VerificationStatus) error
+	Rebuild(ctx context.Context) (int, error)
+}
+
+type eventSourcingService struct {
+	store         repository.EventStoreRepository
+	verifications repository.VerificationRepository
+	logger        *zap.Logger
+}
+
+func NewEventSourcingService(store repository.EventStoreRepository, verifications repository.VerificationRepository, logger *zap.Logger) EventSourcingService {
+	return &eventSourcingService{
+		store:         store,
+		verifications: verifications,
+		logger:        logger,
+	}
+}
+
+// RecordCreated начинает поток событий новой проверки
+func (s *eventSourcingService) RecordCreated(ctx context.Context, verification *model.Verification) error {
+	event, err := eventstore.NewEvent(verification.ID, eventstore.EventCreated, eventstore.CreatedPayload{
+		INN:                verification.Inn,
+		AuthorEmail:        verification.AuthorEmail,
+		RequestedDataTypes: verification.RequestedDataTypes,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.appendAndProject(ctx, verification.ID, func(*eventstore.State) ([]eventstore.Event, error) {
+		return []eventstore.Event{event}, nil
+	})
+}
+
+// RecordCompletion фиксирует поступившие данные и итоговый статус проверки
+func (s *eventSourcingService) RecordCompletion(ctx context.Context, completed *model.Verification, missing []model.VerificationDataType) error {
+	current, err := s.verifications.GetByID(ctx, completed.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get verification: %w", err)
+	}
+
+	status := completed.Status
+	if len(missing) > 0 {
+		status = model.VerificationStatusPartiallyCompleted
+	}
+
+	return s.appendAndProject(ctx, completed.ID, func(state *eventstore.State) ([]eventstore.Event, error) {
+		var events []eventstore.Event
+
+		// Проверки, созданные до включения режима, получают поток с события создания
+		if state == nil {
+			state = &eventstore.State{}
+			created, err := eventstore.NewEvent(current.ID, eventstore.EventCreated, eventstore.CreatedPayload{
+				INN:                current.Inn,
+				AuthorEmail:        current.AuthorEmail,
+				RequestedDataTypes: current.RequestedDataTypes,
+			})
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, created)
+		}
+
+		for _, data := range current.Data {
+			if state.HasDelivered(data.DataType) {
+				continue
+			}
+			received, err := eventstore.NewEvent(current.ID, eventstore.EventDataReceived, eventstore.DataReceivedPayload{DataType: data.DataType})
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, received)
+		}
+
+		riskChanged := completed.RiskLevel != nil && (state.RiskLevel == nil || *state.RiskLevel != *completed.RiskLevel)
+		if state.Status != status || riskChanged || !slices.Equal(state.MissingDataTypes, missing) {
+			changed, err := eventstore.NewEvent(current.ID, eventstore.EventStatusChanged, eventstore.StatusChangedPayload{
+				Status:           status,
+				RiskLevel:        completed.RiskLevel,
+				MissingDataTypes: missing,
+			})
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, changed)
+		}
+
+		return events, nil
+	})
+}
+
+// RecordStatusChange фиксирует смену статуса без изменения остальных полей
+func (s *eventSourcingService) RecordStatusChange(ctx context.Context, id string, status model.VerificationStatus) error {
+	return s.appendAndProject(ctx, id, func(state *eventstore.State) ([]eventstore.Event, error) {
+		if state == nil {
+			return nil, fmt.Errorf("event stream not found: %s", id)
+		}
+		if state.Status == status {
+			return nil, nil
+		}
+		event, err := eventstore.NewEvent(id, eventstore.EventStatusChanged, eventstore.StatusChangedPayload{
+			Status:           status,
+			MissingDataTypes: state.MissingDataTypes,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []eventstore.Event{event}, nil
+	})
+}
+
+// Rebuild перестраивает таблицу verifications по всем потокам событий
+func (s *eventSourcingService) Rebuild(ctx context.Context) (int, error) {
+	var rebuilt int
+	afterID := ""
+	for {
+		ids, err := s.store.ListStreamIDs(ctx, afterID, rebuildPageSize)
+		if err != nil {
+			return rebuilt, err
+		}
+		if len(ids) == 0 {
+			return rebuilt, nil
+		}
+
+		for _, id := range ids {
+			events, err := s.store.Load(ctx, id)
+			if err != nil {
+				return rebuilt, err
+			}
+			state, err := eventstore.Project(events)
+			if err != nil {
+				return rebuilt, err
+			}
+			if err := s.store.UpsertReadModel(ctx, state); err != nil {
+				return rebuilt, err
+			}
+			rebuilt++
+		}
+
+		afterID = ids[len(ids)-1]
+		s.logger.Info("read model rebuild progress", zap.Int("rebuilt", rebuilt))
+	}
+}
+
+// appendAndProject читает поток, дописывает события, построенные decide, и обновляет модель чтения.
+// При конкурентной записи в тот же поток попытка повторяется.
+func (s *eventSourcingService) appendAndProject(ctx context.Context, id string, decide func(*eventstore.State) ([]eventstore.Event, error)) error {
+	for attempt := 1; ; attempt++ {
+		events, err := s.store.Load(ctx, id)
+		if err != nil {
+			return err
+		}
+		state, err := eventstore.Project(events)
+		if err != nil {
+			return err
+		}
+
+		newEvents, err := decide(state)
+		if err != nil {
+			return err
+		}
+		if len(newEvents) == 0 {
+			return nil
+		}
+
+		version := 0
+		if state != nil {
+			version = state.Version
+		}
+
+		err = s.store.Append(ctx, id, version, newEvents)
+		if (errors.Is(err, eventstore.ErrVersionConflict) || errors.Is(err, repository.ErrSerialization)) && attempt < appendAttempts {
+			s.logger.Warn("event stream changed concurrently, retrying", zap.String("verification_id", id), zap.Int("attempt", attempt))
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		for i := range newEvents {
+			newEvents[i].Version = version + i + 1
+		}
+		projected, err := eventstore.Project(append(events, newEvents...))
+		if err != nil {
+			return err
+		}
+		return s.store.UpsertReadModel(ctx, projected)
+	}
+}
+
+// eventSourcedVerificationService записывает события при изменениях проверок, сделанных шлюзом
+type eventSourcedVerificationService struct {
+	VerificationService
+	events EventSourcingService
+	logger *zap.Logger
+}
+
+// NewEventSourcedVerificationService оборачивает сервис проверок для режима хранения событий
+func NewEventSourcedVerificationService(inner VerificationService, events EventSourcingService, logger *zap.Logger) VerificationService {
+	return &eventSourcedVerificationService{
+		VerificationService: inner,
+		events:              events,
+		logger:              logger,
+	}
+}
+
+func (s *eventSourcedVerificationService) CreateVerification(ctx context.Context, inn string, requestedTypes []model.VerificationDataType, authorEmail string) (*model.Verification, error) {
+	return s.CreateVerificationWithPriority(ctx, inn, requestedTypes, authorEmail, messaging.PriorityNormal)
+}
+
+func (s *eventSourcedVerificationService) CreateVerificationWithPriority(ctx context.Context, inn string, requestedTypes []model.VerificationDataType, authorEmail string, priority messaging.Priority) (*model.Verification, error) {
+	return s.CreateVerificationWithOptions(ctx, inn, requestedTypes, authorEmail, CreateOptions{Priority: priority})
+}
+
+func (s *eventSourcedVerificationService) CreateVerificationWithOptions(ctx context.Context, inn string, requestedTypes []model.VerificationDataType, authorEmail string, opts CreateOptions) (*model.Verification, error) {
+	verification, err := s.VerificationService.CreateVerificationWithOptions(ctx, inn, requestedTypes, authorEmail, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Запрос уже отправлен в очередь, поэтому ошибка записи события не отменяет создание
+	if err := s.events.RecordCreated(ctx, verification); err != nil {
+		s.logger.Error("failed to record verification created event", zap.Error(err), zap.String("verification_id", verification.ID))
+	}
+	return verification, nil
+}
+
+func (s *eventSourcedVerificationService) RetryMissingData(ctx context.Context, verification *model.Verification) error {
+	if err := s.VerificationService.RetryMissingData(ctx, verification); err != nil {
+		return err
+	}
+
+	if err := s.events.RecordStatusChange(ctx, verification.ID, model.VerificationStatusInProcess); err != nil {
+		s.logger.Error("failed to record status change event", zap.Error(err), zap.String("verification_id", verification.ID))
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/eventstore"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// In-memory реализация EventStoreRepository
+type memoryEventStore struct {
+	streams    map[string][]eventstore.Event
+	readModel  map[string]*eventstore.State
+	conflictOn int
+}
+
+func newMemoryEventStore() *memoryEventStore {
+	return &memoryEventStore{
+		streams:   make(map[string][]eventstore.Event),
+		readModel: make(map[string]*eventstore.State),
+	}
+}
+
+func (m *memoryEventStore) Append(ctx context.Context, verificationID string, expectedVersion int, events []eventstore.Event) error {
+	if m.conflictOn > 0 {
+		m.conflictOn--
+		return fmt.Errorf("append: %w", eventstore.ErrVersionConflict)
+	}
+	if len(m.streams[verificationID]) != expectedVersion {
+		return fmt.Errorf("append: %w", eventstore.ErrVersionConflict)
+	}
+	for i, event := range events {
+		event.Version = expectedVersion + i + 1
+		m.streams[verificationID] = append(m.streams[verificationID], event)
+	}
+	return nil
+}
+
+func (m *memoryEventStore) Load(ctx context.Context, verificationID string) ([]eventstore.Event, error) {
+	return append([]eventstore.Event(nil), m.streams[verificationID]...), nil
+}
+
+func (m *memoryEventStore) ListStreamIDs(ctx context.Context, afterID string, limit int) ([]string, error) {
+	var ids []string
+	for id := range m.streams {
+		if id > afterID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+func (m *memoryEventStore) UpsertReadModel(ctx context.Context, state *eventstore.State) error {
+	m.readModel[state.ID] = state
+	return nil
+}
+
+func TestEventSourcedVerificationLifecycle(t *testing.T) {
+	store := newMemoryEventStore()
+	high := model.RiskLevelHigh
+
+	repo := &mockVerificationRepository{
+		getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+			return &model.Verification{
+				ID:                 id,
+				Inn:                "1234567890",
+				AuthorEmail:        "test@example.com",
+				RequestedDataTypes: []model.VerificationDataType{model.VerificationDataTypeBasicInformation, model.VerificationDataTypeActivities},
+				Data:               []*model.VerificationData{{DataType: model.VerificationDataTypeBasicInformation}},
+			}, nil
+		},
+	}
+	events := NewEventSourcingService(store, repo, zaptest.NewLogger(t))
+	service := NewEventSourcedVerificationService(NewVerificationService(repo, &mockNATSClient{}, nil, zaptest.NewLogger(t)), events, zaptest.NewLogger(t))
+
+	verification, err := service.CreateVerification(context.Background(), "1234567890",
+		[]model.VerificationDataType{model.VerificationDataTypeBasicInformation, model.VerificationDataTypeActivities}, "test@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.streams[verification.ID]) != 1 {
+		t.Fatalf("expected created event, got %d events", len(store.streams[verification.ID]))
+	}
+
+	// Конфликт версий при записи повторяется
+	store.conflictOn = 1
+	missing := []model.VerificationDataType{model.VerificationDataTypeActivities}
+	completed := &model.Verification{ID: verification.ID, Status: model.VerificationStatusCompleted, RiskLevel: &high}
+	if err := events.RecordCompletion(context.Background(), completed, missing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stream := store.streams[verification.ID]
+	if len(stream) != 3 {
+		t.Fatalf("expected created, data_received and status_changed events, got %d", len(stream))
+	}
+	if stream[1].Type != eventstore.EventDataReceived || stream[2].Type != eventstore.EventStatusChanged {
+		t.Errorf("unexpected event types: %s, %s", stream[1].Type, stream[2].Type)
+	}
+
+	state := store.readModel[verification.ID]
+	if state.Status != model.VerificationStatusPartiallyCompleted {
+		t.Errorf("expected read model status '%s', but got '%s'", model.VerificationStatusPartiallyCompleted, state.Status)
+	}
+
+	// Повторная доставка того же сообщения не создает новых событий
+	if err := events.RecordCompletion(context.Background(), completed, missing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.streams[verification.ID]) != 3 {
+		t.Errorf("expected redelivery to be idempotent, got %d events", len(store.streams[verification.ID]))
+	}
+
+	if err := service.RetryMissingData(context.Background(), &model.Verification{ID: verification.ID, MissingDataTypes: missing}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.readModel[verification.ID].Status != model.VerificationStatusInProcess {
+		t.Errorf("expected status '%s' after retry, but got '%s'", model.VerificationStatusInProcess, store.readModel[verification.ID].Status)
+	}
+
+	store.readModel = make(map[string]*eventstore.State)
+	rebuilt, err := events.Rebuild(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rebuilt != 1 || store.readModel[verification.ID] == nil {
+		t.Errorf("expected read model to be rebuilt, got %d", rebuilt)
+	}
+}
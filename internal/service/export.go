@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// errExportStalled клиент не принял очередную проверку за отведенное время
+var errExportStalled = errors.New("export client stopped receiving")
+
+// ExportService потоковая выгрузка проверок через подписку. Проверки читаются из базы
+// по одной и передаются клиенту без буфера: следующая строка читается, только когда
+// клиент принял предыдущую, поэтому большая выгрузка не собирается в памяти.
+type ExportService interface {
+	StreamVerifications(ctx context.Context, filter *model.VerificationFilter, limit *int32, offset *int32) (<-chan *model.VerificationEdge, error)
+}
+
+type exportService struct {
+	repo        repository.VerificationRepository
+	maxRows     int32
+	sendTimeout time.Duration
+	logger      *zap.Logger
+}
+
+// NewExportService создает сервис выгрузки не более maxRows проверок за подписку.
+// Если клиент не принимает проверку дольше sendTimeout, выгрузка прекращается
+// и освобождает соединение с базой.
+func NewExportService(repo repository.VerificationRepository, maxRows int, sendTimeout time.Duration, logger *zap.Logger) ExportService {
+	return &exportService{
+		repo:        repo,
+		maxRows:     int32(maxRows),
+		sendTimeout: sendTimeout,
+		logger:      logger,
+	}
+}
+
+func (s *exportService) StreamVerifications(ctx context.Context, filter *model.VerificationFilter, limit *int32, offset *int32) (<-chan *model.VerificationEdge, error) {
+	rows := s.maxRows
+	if limit != nil {
+		if *limit < 0 {
+			return nil, invalidInputf("limit must be non-negative, got %d", *limit)
+		}
+		if *limit > s.maxRows {
+			return nil, invalidInputf("limit must not exceed %d rows, export in chunks using offset", s.maxRows)
+		}
+		rows = *limit
+	}
+	var start int32
+	if offset != nil {
+		if *offset < 0 {
+			return nil, invalidInputf("offset must be non-negative, got %d", *offset)
+		}
+		start = *offset
+	}
+
+	if err := auth.CheckOperation(ctx, auth.OperationRead); err != nil {
+		return nil, err
+	}
+
+	repoFilter, err := toRepositoryFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan *model.VerificationEdge)
+	go func() {
+		defer close(out)
+
+		cursor := start
+		err := s.repo.StreamAll(ctx, repoFilter, &rows, offset, func(v *model.Verification) error {
+			cursor++
+			return s.send(ctx, out, &model.VerificationEdge{Cursor: cursor, Node: restrictData(ctx, v)})
+		})
+		metrics.VerificationExportRows.Add(float64(cursor - start))
+		if err != nil && !errors.Is(err, context.Canceled) {
+			s.logger.Error("verification export stopped", zap.Error(err), zap.Int32("cursor", cursor))
+		}
+	}()
+	return out, nil
+}
+
+// send передает проверку клиенту, ожидая его не дольше sendTimeout
+func (s *exportService) send(ctx context.Context, out chan<- *model.VerificationEdge, edge *model.VerificationEdge) error {
+	timer := time.NewTimer(s.sendTimeout)
+	defer timer.Stop()
+
+	select {
+	case out <- edge:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return errExportStalled
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestStreamVerifications(t *testing.T) {
+	var requestedLimit, requestedOffset int32
+	read := make(chan string, 10)
+	repo := &mockVerificationRepository{
+		getAllFunc: func(ctx context.Context, filter repository.VerificationFilter, limit *int32, offset *int32) ([]*model.Verification, error) {
+			requestedLimit, requestedOffset = *limit, *offset
+			return []*model.Verification{{ID: "v-21"}, {ID: "v-22"}, {ID: "v-23"}}, nil
+		},
+	}
+	streaming := &readRecordingRepository{mockVerificationRepository: repo, read: read}
+	service := NewExportService(streaming, 100, time.Second, zaptest.NewLogger(t))
+
+	limit, offset := int32(3), int32(20)
+	edges, err := service.StreamVerifications(context.Background(), nil, &limit, &offset)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	first := <-edges
+	if first.Cursor != 21 || first.Node.ID != "v-21" {
+		t.Errorf("unexpected first edge %+v", first)
+	}
+	// Следующая строка читается только после того, как клиент принял предыдущую
+	time.Sleep(10 * time.Millisecond)
+	if len(read) != 2 {
+		t.Errorf("expected the stream to wait for the client after the second row, but %d rows were read", len(read))
+	}
+
+	var cursors []int32
+	for edge := range edges {
+		cursors = append(cursors, edge.Cursor)
+	}
+	if len(cursors) != 2 || cursors[0] != 22 || cursors[1] != 23 {
+		t.Errorf("unexpected cursors %v", cursors)
+	}
+	if requestedLimit != 3 || requestedOffset != 20 {
+		t.Errorf("expected limit 3 and offset 20, but got %d and %d", requestedLimit, requestedOffset)
+	}
+}
+
+func TestStreamVerificationsGuards(t *testing.T) {
+	service := NewExportService(&mockVerificationRepository{}, 100, time.Second, zaptest.NewLogger(t))
+
+	limit := int32(101)
+	if _, err := service.StreamVerifications(context.Background(), nil, &limit, nil); err == nil || !strings.HasPrefix(err.Error(), "limit must not exceed 100") {
+		t.Errorf("expected limit above max rows to be refused, but got %v", err)
+	}
+	noRead := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "partner@bank.ru", Scope: &auth.Scope{Operations: []auth.Operation{auth.OperationCreate}}})
+	if _, err := service.StreamVerifications(noRead, nil, nil, nil); err == nil {
+		t.Error("expected key without read access to be denied")
+	}
+}
+
+func TestStreamVerificationsStalledClient(t *testing.T) {
+	repo := &mockVerificationRepository{
+		getAllFunc: func(ctx context.Context, filter repository.VerificationFilter, limit *int32, offset *int32) ([]*model.Verification, error) {
+			return []*model.Verification{{ID: "v-1"}, {ID: "v-2"}}, nil
+		},
+	}
+	service := NewExportService(repo, 100, 20*time.Millisecond, zaptest.NewLogger(t))
+
+	edges, err := service.StreamVerifications(context.Background(), nil, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if _, ok := <-edges; ok {
+		t.Error("expected the export to stop when the client does not receive rows")
+	}
+}
+
+// readRecordingRepository отмечает каждую строку, прочитанную из базы
+type readRecordingRepository struct {
+	*mockVerificationRepository
+	read chan string
+}
+
+func (r *readRecordingRepository) StreamAll(ctx context.Context, filter repository.VerificationFilter, limit *int32, offset *int32, fn func(*model.Verification) error) error {
+	return r.mockVerificationRepository.StreamAll(ctx, filter, limit, offset, func(v *model.Verification) error {
+		r.read <- v.ID
+		return fn(v)
+	})
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/messaging"
+
+	"go.uber.org/zap"
+)
+
+// IdentityService показывает, кем шлюз считает клиента: разработчику интеграции - о себе, чтобы
+// разобраться в отказах доступа, поддержке - по токену или ключу клиента
+type IdentityService interface {
+	Me(ctx context.Context) (*model.Principal, error)
+	IntrospectToken(ctx context.Context, token string) (*model.TokenIntrospection, error)
+}
+
+// TenantLoadReader нагрузка арендатора и его лимит одновременных проверок
+type TenantLoadReader interface {
+	TenantLoad(ctx context.Context, tenant string, defaultLimit int) (*messaging.TenantLoad, error)
+}
+
+type identityService struct {
+	tokens      *auth.JWTVerifier
+	apiKeys     auth.APIKeyResolver
+	memberships auth.MembershipResolver
+	loads       TenantLoadReader
+	cfg         config.NATSConfig
+	logger      *zap.Logger
+}
+
+// NewIdentityService создает сервис. tokens nil - токены не принимаются, memberships nil -
+// учетные записи пользователей отключены.
+func NewIdentityService(tokens *auth.JWTVerifier, apiKeys auth.APIKeyResolver, memberships auth.MembershipResolver, loads TenantLoadReader, cfg config.NATSConfig, logger *zap.Logger) IdentityService {
+	return &identityService{
+		tokens:      tokens,
+		apiKeys:     apiKeys,
+		memberships: memberships,
+		loads:       loads,
+		cfg:         cfg,
+		logger:      logger,
+	}
+}
+
+// Me возвращает пользователя запроса в том виде, в котором его проверяют ограничения доступа
+func (s *identityService) Me(ctx context.Context) (*model.Principal, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || principal.Email == "" {
+		return nil, auth.ErrUnauthenticated
+	}
+	return s.principalToModel(ctx, principal)
+}
+
+// IntrospectToken проверяет JWT или ключ API так же, как при аутентификации запросов. Отклоненный
+// токен не ошибка: причина возвращается в reason.
+func (s *identityService) IntrospectToken(ctx context.Context, token string) (*model.TokenIntrospection, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+	admin, _ := auth.PrincipalFromContext(ctx)
+
+	token = strings.TrimSpace(token)
+	if scheme, credential, ok := strings.Cut(token, " "); ok && strings.EqualFold(scheme, "Bearer") {
+		token = strings.TrimSpace(credential)
+	}
+
+	principal, reason, err := s.authenticate(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if principal == nil {
+		s.logger.Info("token introspected", zap.String("admin", admin.Email), zap.String("reason", reason))
+		return &model.TokenIntrospection{Active: false, Reason: &reason}, nil
+	}
+
+	s.logger.Info("token introspected", zap.String("admin", admin.Email), zap.String("principal", principal.Email))
+	resolved, err := s.principalToModel(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+	return &model.TokenIntrospection{Active: true, Principal: resolved}, nil
+}
+
+// authenticate возвращает пользователя токена или причину отказа. JWT состоит из трех
+// частей через точку, остальные токены проверяются как ключи API.
+func (s *identityService) authenticate(ctx context.Context, token string) (*auth.Principal, string, error) {
+	if token == "" {
+		return nil, "empty token", nil
+	}
+
+	if strings.Count(token, ".") != 2 {
+		principal, err := s.apiKeys.ResolveAPIKey(ctx, token)
+		if err != nil {
+			return nil, "", err
+		}
+		if principal == nil {
+			return nil, "unknown or revoked api key", nil
+		}
+		return principal, "", nil
+	}
+
+	if s.tokens == nil {
+		return nil, "token authentication is not configured", nil
+	}
+	verified, err := s.tokens.Verify(token)
+	if err != nil {
+		if errors.Is(err, auth.ErrTokenExpired) {
+			return nil, err.Error(), nil
+		}
+		return nil, fmt.Sprintf("invalid token: %v", err), nil
+	}
+
+	principal := verified.Principal
+	if s.memberships != nil {
+		resolved, err := s.memberships.ResolveMembership(ctx, principal)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to resolve user account: %w", err)
+		}
+		if resolved == nil {
+			return nil, "account deactivated", nil
+		}
+		principal = resolved
+	}
+	return principal, "", nil
+}
+
+func (s *identityService) principalToModel(ctx context.Context, principal *auth.Principal) (*model.Principal, error) {
+	tenant := messaging.TenantOf(principal.Email)
+	load, err := s.loads.TenantLoad(ctx, tenant, s.cfg.TenantMaxInFlight)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.Principal{
+		ClientKind: model.ClientKindUser,
+		Email:      principal.Email,
+		Roles:      append([]string{}, principal.Roles...),
+		Tenant:     tenant,
+		Sandbox:    principal.Sandbox,
+		Quota: &model.TenantQuota{
+			InFlight: int32(load.InFlight),
+			Held:     int32(load.Held),
+		},
+	}
+	if principal.Organization != "" {
+		result.Organization = &principal.Organization
+	}
+	if scope := principal.Scope; scope != nil {
+		result.ClientKind = model.ClientKindAPIKey
+		result.KeyScope = &model.APIKeyScope{
+			KeyID:      scope.KeyID,
+			Operations: []model.AccessOperation{},
+			DataTypes:  append([]string{}, scope.DataTypes...),
+		}
+		for _, op := range scope.Operations {
+			result.KeyScope.Operations = append(result.KeyScope.Operations, model.AccessOperation(strings.ToUpper(string(op))))
+		}
+	}
+	if !principal.TokenExpiresAt.IsZero() {
+		expiresAt := principal.TokenExpiresAt.UTC().Format(time.RFC3339)
+		result.TokenExpiresAt = &expiresAt
+	}
+	if s.cfg.PublishRate > 0 {
+		rate, burst := s.cfg.PublishRate, int32(max(s.cfg.PublishBurst, 1))
+		result.Quota.PublishRate = &rate
+		result.Quota.PublishBurst = &burst
+	}
+	// Лимит организации действует, только если включено ограничение одновременных проверок
+	if s.cfg.TenantMaxInFlight > 0 && load.Limit > 0 {
+		limit := int32(load.Limit)
+		result.Quota.MaxInFlight = &limit
+	}
+	return result, nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/messaging"
+
+	"go.uber.org/zap"
+)
+
+type stubAPIKeyResolver struct {
+	keys map[string]*auth.Principal
+}
+
+func (r *stubAPIKeyResolver) ResolveAPIKey(ctx context.Context, key string) (*auth.Principal, error) {
+	return r.keys[key], nil
+}
+
+type stubMembershipResolver struct {
+	deactivated string
+}
+
+func (r *stubMembershipResolver) ResolveMembership(ctx context.Context, principal *auth.Principal) (*auth.Principal, error) {
+	if principal.Email == r.deactivated {
+		return nil, nil
+	}
+	resolved := *principal
+	resolved.Organization = "bank.ru"
+	resolved.Roles = append(append([]string{}, principal.Roles...), auth.RoleAnalyst)
+	return &resolved, nil
+}
+
+type stubTenantLoads struct {
+	load messaging.TenantLoad
+}
+
+func (s *stubTenantLoads) TenantLoad(ctx context.Context, tenant string, defaultLimit int) (*messaging.TenantLoad, error) {
+	load := s.load
+	load.Tenant = tenant
+	if load.Limit == 0 {
+		load.Limit = defaultLimit
+	}
+	return &load, nil
+}
+
+func signIdentityToken(secret, claims string) string {
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + base64.RawURLEncoding.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newTestIdentityService() IdentityService {
+	apiKeys := &stubAPIKeyResolver{keys: map[string]*auth.Principal{
+		"key-secret": {Email: "partner@fintech.ru", Scope: &auth.Scope{KeyID: "key-1", Operations: []auth.Operation{auth.OperationRead}}},
+	}}
+	cfg := config.NATSConfig{PublishRate: 5, TenantMaxInFlight: 50}
+	loads := &stubTenantLoads{load: messaging.TenantLoad{InFlight: 12, Held: 3}}
+	return NewIdentityService(auth.NewJWTVerifier("secret"), apiKeys, &stubMembershipResolver{deactivated: "former@bank.ru"}, loads, cfg, zap.NewNop())
+}
+
+func TestMe(t *testing.T) {
+	svc := newTestIdentityService()
+
+	if _, err := svc.Me(context.Background()); err == nil {
+		t.Error("expected an anonymous request to be rejected")
+	}
+
+	expiresAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{
+		Email:          "analyst@Bank.ru",
+		Roles:          []string{auth.RoleAnalyst},
+		Organization:   "bank.ru",
+		TokenExpiresAt: expiresAt,
+	})
+	me, err := svc.Me(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if me.ClientKind != model.ClientKindUser || me.Tenant != "bank.ru" || *me.Organization != "bank.ru" || me.KeyScope != nil {
+		t.Errorf("unexpected principal %+v", me)
+	}
+	if me.TokenExpiresAt == nil || *me.TokenExpiresAt != "2026-03-01T12:00:00Z" {
+		t.Errorf("expected the token expiry, but got %v", me.TokenExpiresAt)
+	}
+	quota := me.Quota
+	if *quota.PublishRate != 5 || *quota.PublishBurst != 1 || *quota.MaxInFlight != 50 || quota.InFlight != 12 || quota.Held != 3 {
+		t.Errorf("unexpected quota %+v", quota)
+	}
+}
+
+func TestIntrospectToken(t *testing.T) {
+	svc := newTestIdentityService()
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "support@gateway.local", Roles: []string{auth.RoleAdmin}})
+
+	if _, err := svc.IntrospectToken(auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@bank.ru"}), "key-secret"); err == nil {
+		t.Error("expected introspection to require the admin role")
+	}
+
+	exp := time.Now().Add(time.Hour).Unix()
+	tests := []struct {
+		name   string
+		token  string
+		reason string
+		email  string
+	}{
+		{name: "api_key", token: "key-secret", email: "partner@fintech.ru"},
+		{name: "unknown_api_key", token: "key-other", reason: "unknown or revoked api key"},
+		{name: "bearer_token", token: "Bearer " + signIdentityToken("secret", fmt.Sprintf(`{"email":"analyst@bank.ru","exp":%d}`, exp)), email: "analyst@bank.ru"},
+		{name: "expired_token", token: signIdentityToken("secret", fmt.Sprintf(`{"email":"analyst@bank.ru","exp":%d}`, time.Now().Add(-time.Minute).Unix())), reason: "token expired"},
+		{name: "foreign_signature", token: signIdentityToken("other", fmt.Sprintf(`{"email":"analyst@bank.ru","exp":%d}`, exp)), reason: "invalid token: invalid token signature"},
+		{name: "deactivated_account", token: signIdentityToken("secret", fmt.Sprintf(`{"email":"former@bank.ru","exp":%d}`, exp)), reason: "account deactivated"},
+		{name: "empty", token: " ", reason: "empty token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := svc.IntrospectToken(admin, tt.token)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.reason != "" {
+				if result.Active || result.Reason == nil || *result.Reason != tt.reason || result.Principal != nil {
+					t.Errorf("expected the token to be rejected with %q, but got %+v", tt.reason, result)
+				}
+				return
+			}
+			if !result.Active || result.Principal == nil || result.Principal.Email != tt.email {
+				t.Errorf("expected the token of %s to be active, but got %+v", tt.email, result)
+			}
+		})
+	}
+
+	result, _ := svc.IntrospectToken(admin, "key-secret")
+	if scope := result.Principal.KeyScope; scope == nil || scope.KeyID != "key-1" || !slices.Equal(scope.Operations, []model.AccessOperation{model.AccessOperationRead}) {
+		t.Errorf("expected the key scope, but got %+v", scope)
+	}
+	result, _ = svc.IntrospectToken(admin, signIdentityToken("secret", fmt.Sprintf(`{"email":"analyst@bank.ru","exp":%d}`, exp)))
+	if !slices.Equal(result.Principal.Roles, []string{auth.RoleAnalyst}) || result.Principal.TokenExpiresAt == nil {
+		t.Errorf("expected the roles of the user account and the token expiry, but got %+v", result.Principal)
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+type LatencyService interface {
+	RecordCompletion(ctx context.Context, verificationID string) error
+	GetLatencyReport(ctx context.Context, dataType *model.VerificationDataType, from, to string) ([]*model.LatencyReport, error)
+}
+
+type latencyService struct {
+	repo   repository.LatencyRepository
+	logger *zap.Logger
+}
+
+func NewLatencyService(repo repository.LatencyRepository, logger *zap.Logger) LatencyService {
+	return &latencyService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// RecordCompletion сохраняет время выполнения каждого запрошенного типа данных завершенной проверки
+// и учитывает его в гистограммах Prometheus
+func (s *latencyService) RecordCompletion(ctx context.Context, verificationID string) error {
+	samples, err := s.repo.RecordLatencies(ctx, verificationID)
+	if err != nil {
+		return err
+	}
+
+	for _, sample := range samples {
+		if sample.FirstDataSeconds != nil {
+			metrics.VerificationFirstDataSeconds.WithLabelValues(sample.DataType).Observe(*sample.FirstDataSeconds)
+		}
+		metrics.VerificationCompletionSeconds.WithLabelValues(sample.DataType).Observe(sample.CompletedSeconds)
+	}
+	return nil
+}
+
+// GetLatencyReport возвращает перцентили времени выполнения по типам данных за включительный период дней в UTC
+func (s *latencyService) GetLatencyReport(ctx context.Context, dataType *model.VerificationDataType, from, to string) ([]*model.LatencyReport, error) {
+	fromDay, err := time.Parse(time.DateOnly, from)
+	if err != nil {
+		return nil, invalidInputf("from must be a date in YYYY-MM-DD format: %w", err)
+	}
+
+	toDay, err := time.Parse(time.DateOnly, to)
+	if err != nil {
+		return nil, invalidInputf("to must be a date in YYYY-MM-DD format: %w", err)
+	}
+
+	if toDay.Before(fromDay) {
+		return nil, invalidInputf("to must not be before from")
+	}
+
+	if toDay.Sub(fromDay) > maxStatisticsRange {
+		return nil, invalidInputf("latency report range must not exceed 366 days")
+	}
+
+	var filter *string
+	if dataType != nil {
+		value := string(*dataType)
+		filter = &value
+	}
+
+	return s.repo.GetReport(ctx, filter, fromDay, toDay.AddDate(0, 0, 1))
+}
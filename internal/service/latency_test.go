@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type mockLatencyRepository struct {
+	repository.LatencyRepository
+	dataType *string
+	from, to time.Time
+}
+
+func (m *mockLatencyRepository) GetReport(ctx context.Context, dataType *string, from, to time.Time) ([]*model.LatencyReport, error) {
+	m.dataType, m.from, m.to = dataType, from, to
+	return []*model.LatencyReport{}, nil
+}
+
+func TestGetLatencyReport(t *testing.T) {
+	arbitrage := model.VerificationDataTypeArbitrageStatistics
+
+	tests := []struct {
+		name          string
+		dataType      *model.VerificationDataType
+		from, to      string
+		expectedFrom  time.Time
+		expectedTo    time.Time
+		expectedError string
+	}{
+		{
+			name:         "inclusive_range",
+			dataType:     &arbitrage,
+			from:         "2024-01-01",
+			to:           "2024-01-31",
+			expectedFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			expectedTo:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "invalid_from",
+			from:          "01.01.2024",
+			to:            "2024-01-31",
+			expectedError: "from must be a date in YYYY-MM-DD format",
+		},
+		{
+			name:          "reversed",
+			from:          "2024-02-01",
+			to:            "2024-01-01",
+			expectedError: "to must not be before from",
+		},
+		{
+			name:          "too_long",
+			from:          "2023-01-01",
+			to:            "2024-12-31",
+			expectedError: "latency report range must not exceed 366 days",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockLatencyRepository{}
+			service := NewLatencyService(repo, zaptest.NewLogger(t))
+
+			_, err := service.GetLatencyReport(context.Background(), tt.dataType, tt.from, tt.to)
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error %q, but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !repo.from.Equal(tt.expectedFrom) || !repo.to.Equal(tt.expectedTo) {
+				t.Errorf("expected range [%v, %v), but got [%v, %v)", tt.expectedFrom, tt.expectedTo, repo.from, repo.to)
+			}
+			if tt.dataType != nil && (repo.dataType == nil || *repo.dataType != string(*tt.dataType)) {
+				t.Errorf("expected data type filter %s, but got %v", *tt.dataType, repo.dataType)
+			}
+		})
+	}
+}
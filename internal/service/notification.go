@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/pubsub"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+type NotificationService interface {
+	NotifyVerificationCompleted(ctx context.Context, verificationID string) error
+	NotifySLABreaches(ctx context.Context, sla time.Duration) error
+	NotifyReviewAssigned(ctx context.Context, verification *model.Verification) error
+	NotifyReviewCompleted(ctx context.Context, verification *model.Verification) error
+	ListForUser(ctx context.Context, userEmail string, unreadOnly bool) ([]*model.Notification, error)
+	MarkRead(ctx context.Context, userEmail string, id string) (*model.Notification, error)
+	SubscribeUnreadCount(ctx context.Context, userEmail string) (<-chan int32, error)
+}
+
+// eventUnreadCountChanged событие Relay об изменении непрочитанных уведомлений, ключ - email пользователя
+const eventUnreadCountChanged = "unread_count_changed"
+
+type notificationService struct {
+	repo             repository.NotificationRepository
+	verificationRepo repository.VerificationRepository
+	audit            AuditService
+	unread           *pubsub.Broker[int32]
+	relay            pubsub.Relay
+	logger           *zap.Logger
+}
+
+// NewNotificationService создает сервис уведомлений. Подписчики unreadCount других экземпляров
+// узнают об изменениях через relay.
+func NewNotificationService(repo repository.NotificationRepository, verificationRepo repository.VerificationRepository, audit AuditService, relay pubsub.Relay, logger *zap.Logger) NotificationService {
+	s := &notificationService{
+		repo:             repo,
+		verificationRepo: verificationRepo,
+		audit:            audit,
+		unread:           pubsub.NewBroker[int32](),
+		relay:            relay,
+		logger:           logger,
+	}
+	relay.Handle(eventUnreadCountChanged, func(userEmail string) {
+		s.publishUnreadCount(context.Background(), userEmail)
+	})
+	return s
+}
+
+// NotifyVerificationCompleted уведомляет автора о завершении проверки, а всех,
+// кто проверял компанию ранее, - об изменении её уровня риска
+func (s *notificationService) NotifyVerificationCompleted(ctx context.Context, verificationID string) error {
+	verification, err := s.verificationRepo.GetByID(ctx, verificationID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get verification: %w", err)
+	}
+
+	message := fmt.Sprintf("Verification of INN %s finished with status %s", verification.Inn, verification.Status)
+	if err := s.notify(ctx, verification.AuthorEmail, model.NotificationKindVerificationCompleted, verification.ID, message); err != nil {
+		return err
+	}
+
+	previous, err := s.verificationRepo.GetPrevious(ctx, verification.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get previous verification: %w", err)
+	}
+	if previous == nil || !riskLevelChanged(previous.RiskLevel, verification.RiskLevel) {
+		return nil
+	}
+
+	authors, err := s.verificationRepo.GetAuthorsByINN(ctx, verification.Inn)
+	if err != nil {
+		return fmt.Errorf("failed to get company watchers: %w", err)
+	}
+
+	message = fmt.Sprintf("Risk level of INN %s changed from %s to %s", verification.Inn, riskLevelString(previous.RiskLevel), riskLevelString(verification.RiskLevel))
+	for _, author := range authors {
+		if err := s.notify(ctx, author, model.NotificationKindMonitoredCompanyChanged, verification.ID, message); err != nil {
+			s.logger.Error("failed to notify about company change", zap.Error(err), zap.String("user", author))
+		}
+	}
+
+	return nil
+}
+
+// NotifySLABreaches уведомляет авторов проверок, не завершившихся за время sla
+func (s *notificationService) NotifySLABreaches(ctx context.Context, sla time.Duration) error {
+	breaches, err := s.repo.GetSLABreaches(ctx, time.Now().Add(-sla), 100)
+	if err != nil {
+		return fmt.Errorf("failed to get SLA breaches: %w", err)
+	}
+
+	for _, v := range breaches {
+		message := fmt.Sprintf("Verification of INN %s is not completed within %s", v.Inn, sla)
+		if err := s.notify(ctx, v.AuthorEmail, model.NotificationKindSLABreached, v.ID, message); err != nil {
+			s.logger.Error("failed to notify about SLA breach", zap.Error(err), zap.String("verification_id", v.ID))
+		}
+	}
+
+	return nil
+}
+
+// NotifyReviewAssigned уведомляет аналитика о назначенной ему проверке
+func (s *notificationService) NotifyReviewAssigned(ctx context.Context, verification *model.Verification) error {
+	if verification.Assignee == nil {
+		return nil
+	}
+	message := fmt.Sprintf("Verification of INN %s is assigned to you for review", verification.Inn)
+	return s.notify(ctx, *verification.Assignee, model.NotificationKindReviewAssigned, verification.ID, message)
+}
+
+// NotifyReviewCompleted уведомляет автора проверки о решении аналитика
+func (s *notificationService) NotifyReviewCompleted(ctx context.Context, verification *model.Verification) error {
+	message := fmt.Sprintf("Verification of INN %s is reviewed: %s", verification.Inn, verification.ReviewState)
+	return s.notify(ctx, verification.AuthorEmail, model.NotificationKindReviewCompleted, verification.ID, message)
+}
+
+func (s *notificationService) ListForUser(ctx context.Context, userEmail string, unreadOnly bool) ([]*model.Notification, error) {
+	return s.repo.ListByUser(ctx, userEmail, unreadOnly)
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, userEmail string, id string) (*model.Notification, error) {
+	if id == "" {
+		return nil, invalidInputf("notification id cannot be empty")
+	}
+
+	notification, err := s.repo.MarkRead(ctx, userEmail, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.relay.Publish(ctx, eventUnreadCountChanged, userEmail)
+	return notification, nil
+}
+
+// SubscribeUnreadCount отдает текущее количество непрочитанных уведомлений и его последующие изменения
+func (s *notificationService) SubscribeUnreadCount(ctx context.Context, userEmail string) (<-chan int32, error) {
+	count, err := s.repo.CountUnread(ctx, userEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := s.unread.Subscribe(ctx, userEmail)
+	out := make(chan int32, 1)
+	out <- count
+
+	go func() {
+		defer close(out)
+		for count := range updates {
+			select {
+			case out <- count:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func (s *notificationService) notify(ctx context.Context, userEmail string, kind model.NotificationKind, verificationID string, message string) error {
+	_, err := s.repo.Create(ctx, userEmail, kind, &verificationID, message)
+	if errors.Is(err, repository.ErrConflict) {
+		// Уведомление уже было отправлено ранее
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	details := map[string]any{"kind": kind, "recipient": userEmail}
+	if err := s.audit.RecordEvent(ctx, verificationID, model.AuditEventTypeNotificationDelivered, "", details); err != nil {
+		s.logger.Warn("failed to record notification delivery", zap.Error(err), zap.String("verification_id", verificationID))
+	}
+
+	s.relay.Publish(ctx, eventUnreadCountChanged, userEmail)
+	return nil
+}
+
+// publishUnreadCount отправляет подписчикам этого экземпляра новое количество непрочитанных уведомлений
+func (s *notificationService) publishUnreadCount(ctx context.Context, userEmail string) {
+	if s.unread.Subscribers(userEmail) == 0 {
+		return
+	}
+
+	count, err := s.repo.CountUnread(ctx, userEmail)
+	if err != nil {
+		s.logger.Warn("failed to count unread notifications", zap.Error(err), zap.String("user", userEmail))
+		return
+	}
+	s.unread.Publish(userEmail, count)
+}
+
+func riskLevelChanged(previous, current *model.RiskLevel) bool {
+	if previous == nil || current == nil {
+		return false
+	}
+	return *previous != *current
+}
+
+func riskLevelString(level *model.RiskLevel) string {
+	if level == nil {
+		return "UNKNOWN"
+	}
+	return string(*level)
+}
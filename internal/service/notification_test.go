@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/pubsub"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для NotificationRepository, хранящий уведомления в памяти
+type mockNotificationRepository struct {
+	created     []*model.Notification
+	recipients  []string
+	unread      int32
+	slaBreaches []*model.Verification
+}
+
+func (m *mockNotificationRepository) Create(ctx context.Context, userEmail string, kind model.NotificationKind, verificationID *string, message string) (*model.Notification, error) {
+	notification := &model.Notification{ID: "n-1", Kind: kind, VerificationID: verificationID, Message: message}
+	m.created = append(m.created, notification)
+	m.recipients = append(m.recipients, userEmail)
+	m.unread++
+	return notification, nil
+}
+
+func (m *mockNotificationRepository) ListByUser(ctx context.Context, userEmail string, unreadOnly bool) ([]*model.Notification, error) {
+	return m.created, nil
+}
+
+func (m *mockNotificationRepository) MarkRead(ctx context.Context, userEmail string, id string) (*model.Notification, error) {
+	if id != "n-1" {
+		return nil, errNotFound("notification not found: %s", id)
+	}
+	m.unread--
+	return &model.Notification{ID: id, Read: true}, nil
+}
+
+func (m *mockNotificationRepository) CountUnread(ctx context.Context, userEmail string) (int32, error) {
+	return m.unread, nil
+}
+
+func (m *mockNotificationRepository) GetSLABreaches(ctx context.Context, startedBefore time.Time, limit int) ([]*model.Verification, error) {
+	return m.slaBreaches, nil
+}
+
+func riskLevelPtr(level model.RiskLevel) *model.RiskLevel {
+	return &level
+}
+
+func newTestNotificationService(t *testing.T, repo *mockNotificationRepository, verificationRepo *mockVerificationRepository) NotificationService {
+	logger := zaptest.NewLogger(t)
+	audit := NewAuditService(&mockAuditRepository{}, NewVerificationService(verificationRepo, &mockNATSClient{}, nil, logger), nil, logger)
+	return NewNotificationService(repo, verificationRepo, audit, pubsub.NewLocalRelay(), logger)
+}
+
+func TestNotifyVerificationCompleted(t *testing.T) {
+	tests := []struct {
+		name               string
+		previous           *model.Verification
+		currentRisk        *model.RiskLevel
+		expectedKinds      []model.NotificationKind
+		expectedRecipients []string
+	}{
+		{
+			name:               "first_verification",
+			expectedKinds:      []model.NotificationKind{model.NotificationKindVerificationCompleted},
+			expectedRecipients: []string{"author@example.com"},
+		},
+		{
+			name:               "risk_level_unchanged",
+			previous:           &model.Verification{ID: "prev", RiskLevel: riskLevelPtr(model.RiskLevelLow)},
+			currentRisk:        riskLevelPtr(model.RiskLevelLow),
+			expectedKinds:      []model.NotificationKind{model.NotificationKindVerificationCompleted},
+			expectedRecipients: []string{"author@example.com"},
+		},
+		{
+			name:        "risk_level_changed",
+			previous:    &model.Verification{ID: "prev", RiskLevel: riskLevelPtr(model.RiskLevelLow)},
+			currentRisk: riskLevelPtr(model.RiskLevelHigh),
+			expectedKinds: []model.NotificationKind{
+				model.NotificationKindVerificationCompleted,
+				model.NotificationKindMonitoredCompanyChanged,
+				model.NotificationKindMonitoredCompanyChanged,
+			},
+			expectedRecipients: []string{"author@example.com", "author@example.com", "watcher@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockNotificationRepository{}
+			verificationRepo := &mockVerificationRepository{
+				getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+					return &model.Verification{ID: id, Inn: "1234567890", Status: model.VerificationStatusCompleted, AuthorEmail: "author@example.com", RiskLevel: tt.currentRisk}, nil
+				},
+				getPreviousFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+					return tt.previous, nil
+				},
+				getAuthorsByINNFunc: func(ctx context.Context, inn string) ([]string, error) {
+					return []string{"author@example.com", "watcher@example.com"}, nil
+				},
+			}
+			service := newTestNotificationService(t, repo, verificationRepo)
+
+			if err := service.NotifyVerificationCompleted(context.Background(), "test-id"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(repo.created) != len(tt.expectedKinds) {
+				t.Fatalf("expected %d notifications, but got %d", len(tt.expectedKinds), len(repo.created))
+			}
+			for i, n := range repo.created {
+				if n.Kind != tt.expectedKinds[i] {
+					t.Errorf("expected notification %d kind '%s', but got '%s'", i, tt.expectedKinds[i], n.Kind)
+				}
+				if repo.recipients[i] != tt.expectedRecipients[i] {
+					t.Errorf("expected notification %d recipient '%s', but got '%s'", i, tt.expectedRecipients[i], repo.recipients[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNotifySLABreaches(t *testing.T) {
+	repo := &mockNotificationRepository{
+		slaBreaches: []*model.Verification{
+			{ID: "v-1", Inn: "1234567890", AuthorEmail: "author@example.com"},
+		},
+	}
+	service := newTestNotificationService(t, repo, &mockVerificationRepository{})
+
+	if err := service.NotifySLABreaches(context.Background(), 30*time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(repo.created) != 1 || repo.created[0].Kind != model.NotificationKindSLABreached {
+		t.Errorf("expected one SLA breach notification, but got %v", repo.created)
+	}
+}
+
+func TestMarkNotificationRead(t *testing.T) {
+	repo := &mockNotificationRepository{unread: 1}
+	service := newTestNotificationService(t, repo, &mockVerificationRepository{})
+
+	if _, err := service.MarkRead(context.Background(), "user@example.com", "missing"); err == nil || err.Error() != "notification not found: missing" {
+		t.Errorf("expected not found error, but got %v", err)
+	}
+
+	notification, err := service.MarkRead(context.Background(), "user@example.com", "n-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !notification.Read {
+		t.Error("expected notification to be read")
+	}
+}
+
+func TestSubscribeUnreadCount(t *testing.T) {
+	repo := &mockNotificationRepository{unread: 2}
+	service := newTestNotificationService(t, repo, &mockVerificationRepository{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	counts, err := service.SubscribeUnreadCount(ctx, "user@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if count := <-counts; count != 2 {
+		t.Errorf("expected initial unread count 2, but got %d", count)
+	}
+
+	if _, err := service.MarkRead(context.Background(), "user@example.com", "n-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case count := <-counts:
+		if count != 1 {
+			t.Errorf("expected unread count 1 after marking read, but got %d", count)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected unread count update")
+	}
+}
+
+func TestSubscribeUnreadCountAcrossInstances(t *testing.T) {
+	// Два экземпляра шлюза с общей базой и общим Relay: уведомление создает один, подписчик подключен к другому
+	repo := &mockNotificationRepository{unread: 1}
+	relay := pubsub.NewLocalRelay()
+	logger := zaptest.NewLogger(t)
+	verificationRepo := &mockVerificationRepository{}
+	audit := NewAuditService(&mockAuditRepository{}, NewVerificationService(verificationRepo, &mockNATSClient{}, nil, logger), nil, logger)
+	handling := NewNotificationService(repo, verificationRepo, audit, relay, logger)
+	serving := NewNotificationService(repo, verificationRepo, audit, relay, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	counts, err := serving.SubscribeUnreadCount(ctx, "user@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	<-counts
+
+	if _, err := handling.MarkRead(context.Background(), "user@example.com", "n-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case count := <-counts:
+		if count != 0 {
+			t.Errorf("expected unread count 0 from the other instance, but got %d", count)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected unread count update from the other instance")
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// DeliveryRecorder учитывает доставку уведомлений о завершении в показателях уровня обслуживания
+type DeliveryRecorder interface {
+	RecordDelivery(ok bool)
+}
+
+// NotificationJobService доставляет уведомления о завершении проверок из заданий, которые база
+// создает вместе с итоговым статусом: уведомление не теряется, если экземпляр упадет после
+// записи статуса, и доставляется хотя бы один раз
+type NotificationJobService interface {
+	// MarkReady разрешает доставку после того, как шлюз обработал завершение проверки:
+	// уведомления получают проверенные данные, итоговый статус и подпись
+	MarkReady(ctx context.Context, verificationID string) error
+	// DeliverPending выполняет попытку доставки готовых заданий и возвращает число доставленных
+	DeliverPending(ctx context.Context) (int, error)
+	ListJobs(ctx context.Context, verificationID string) ([]*model.NotificationJob, error)
+}
+
+type notificationJobService struct {
+	repo          repository.NotificationJobRepository
+	notifications NotificationService
+	webhooks      WebhookService
+	deliveries    DeliveryRecorder
+	cfg           config.NotificationsConfig
+	logger        *zap.Logger
+	now           func() time.Time
+}
+
+func NewNotificationJobService(repo repository.NotificationJobRepository, notifications NotificationService, webhooks WebhookService, deliveries DeliveryRecorder, cfg config.NotificationsConfig, logger *zap.Logger) NotificationJobService {
+	return &notificationJobService{
+		repo:          repo,
+		notifications: notifications,
+		webhooks:      webhooks,
+		deliveries:    deliveries,
+		cfg:           cfg,
+		logger:        logger,
+		now:           time.Now,
+	}
+}
+
+func (s *notificationJobService) MarkReady(ctx context.Context, verificationID string) error {
+	return s.repo.MarkReady(ctx, verificationID)
+}
+
+func (s *notificationJobService) DeliverPending(ctx context.Context) (int, error) {
+	now := s.now()
+	jobs, err := s.repo.ClaimPending(ctx, now.Add(s.cfg.DeliveryClaimTimeout), now.Add(-s.cfg.ReadyTimeout), s.cfg.DeliveryBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim notification jobs: %w", err)
+	}
+
+	delivered := 0
+	for _, job := range jobs {
+		attempt := &repository.NotificationJobAttempt{Attempt: job.Attempts, StartedAt: s.now()}
+		err := s.deliver(ctx, job)
+		attempt.FinishedAt = s.now()
+
+		status, nextAttemptAt := repository.NotificationJobDelivered, attempt.FinishedAt
+		if err != nil {
+			message := err.Error()
+			attempt.Error = &message
+			status, nextAttemptAt = s.retry(job, err, attempt.FinishedAt)
+			s.logger.Error("failed to deliver notification",
+				zap.Error(err),
+				zap.Int64("job_id", job.ID),
+				zap.String("channel", job.Channel),
+				zap.String("verification_id", job.VerificationID),
+				zap.Int("attempt", job.Attempts),
+				zap.String("status", status))
+		} else {
+			delivered++
+		}
+		if job.Channel == repository.NotificationChannelInApp {
+			s.deliveries.RecordDelivery(err == nil)
+		}
+
+		// Без записи попытки задание повторится после истечения захвата
+		if err := s.repo.RecordAttempt(ctx, job.ID, attempt, status, nextAttemptAt); err != nil {
+			s.logger.Error("failed to record notification attempt", zap.Error(err), zap.Int64("job_id", job.ID))
+		}
+	}
+	return delivered, nil
+}
+
+func (s *notificationJobService) deliver(ctx context.Context, job *repository.NotificationJob) error {
+	switch job.Channel {
+	case repository.NotificationChannelInApp:
+		return s.notifications.NotifyVerificationCompleted(ctx, job.VerificationID)
+	case repository.NotificationChannelWebhook:
+		if job.WebhookID == nil {
+			return fmt.Errorf("webhook deleted: %w", repository.ErrNotFound)
+		}
+		return s.webhooks.DeliverVerificationCompletedTo(ctx, *job.WebhookID, job.VerificationID)
+	case repository.NotificationChannelCompanyChange:
+		if job.WebhookID == nil {
+			return fmt.Errorf("webhook deleted: %w", repository.ErrNotFound)
+		}
+		return s.webhooks.DeliverCompanyChangedTo(ctx, *job.WebhookID, job.VerificationID)
+	}
+	return fmt.Errorf("unknown notification channel %q", job.Channel)
+}
+
+// retry возвращает статус задания после неудачной попытки и время следующей. Удаленный вебхук или
+// проверку повторять бессмысленно, остальные ошибки повторяются с удвоением паузы.
+func (s *notificationJobService) retry(job *repository.NotificationJob, err error, at time.Time) (string, time.Time) {
+	if errors.Is(err, repository.ErrNotFound) || job.Attempts >= s.cfg.MaxAttempts {
+		return repository.NotificationJobFailed, at
+	}
+	backoff := s.cfg.RetryBackoff
+	for i := 1; i < job.Attempts && backoff < s.cfg.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return repository.NotificationJobPending, at.Add(min(backoff, s.cfg.MaxRetryBackoff))
+}
+
+func (s *notificationJobService) ListJobs(ctx context.Context, verificationID string) ([]*model.NotificationJob, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if verificationID == "" {
+		return nil, invalidInputf("verification id cannot be empty")
+	}
+
+	jobs, err := s.repo.ListByVerification(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.NotificationJob, 0, len(jobs))
+	for _, job := range jobs {
+		result = append(result, toModelNotificationJob(job))
+	}
+	return result, nil
+}
+
+func toModelNotificationJob(job *repository.NotificationJob) *model.NotificationJob {
+	result := &model.NotificationJob{
+		ID:        strconv.FormatInt(job.ID, 10),
+		Channel:   model.NotificationChannel(job.Channel),
+		WebhookID: job.WebhookID,
+		Status:    model.NotificationJobStatus(job.Status),
+		Attempts:  int32(job.Attempts),
+		LastError: job.LastError,
+		CreatedAt: job.CreatedAt.Format(time.RFC3339),
+		History:   make([]*model.NotificationAttempt, 0, len(job.History)),
+	}
+	if job.Status == repository.NotificationJobPending {
+		next := job.NextAttemptAt.Format(time.RFC3339)
+		result.NextAttemptAt = &next
+	}
+	if job.FinishedAt != nil {
+		finished := job.FinishedAt.Format(time.RFC3339)
+		result.FinishedAt = &finished
+	}
+	for _, attempt := range job.History {
+		result.History = append(result.History, &model.NotificationAttempt{
+			Attempt:    int32(attempt.Attempt),
+			StartedAt:  attempt.StartedAt.Format(time.RFC3339),
+			FinishedAt: attempt.FinishedAt.Format(time.RFC3339),
+			Error:      attempt.Error,
+		})
+	}
+	return result
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/companychange"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/webhook"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type recordedAttempt struct {
+	jobID         int64
+	attempt       *repository.NotificationJobAttempt
+	status        string
+	nextAttemptAt time.Time
+}
+
+// Mock для NotificationJobRepository
+type mockNotificationJobRepository struct {
+	repository.NotificationJobRepository
+	jobs     []*repository.NotificationJob
+	attempts []recordedAttempt
+}
+
+func (m *mockNotificationJobRepository) ClaimPending(ctx context.Context, claimUntil, readyBefore time.Time, limit int) ([]*repository.NotificationJob, error) {
+	for _, job := range m.jobs {
+		job.Attempts++
+	}
+	return m.jobs, nil
+}
+
+func (m *mockNotificationJobRepository) RecordAttempt(ctx context.Context, jobID int64, attempt *repository.NotificationJobAttempt, status string, nextAttemptAt time.Time) error {
+	m.attempts = append(m.attempts, recordedAttempt{jobID: jobID, attempt: attempt, status: status, nextAttemptAt: nextAttemptAt})
+	return nil
+}
+
+// Mock для NotificationService
+type mockNotificationService struct {
+	NotificationService
+	err      error
+	notified []string
+}
+
+func (m *mockNotificationService) NotifyVerificationCompleted(ctx context.Context, verificationID string) error {
+	m.notified = append(m.notified, verificationID)
+	return m.err
+}
+
+type mockDeliveryRecorder struct {
+	ok, failed int
+}
+
+func (m *mockDeliveryRecorder) RecordDelivery(ok bool) {
+	if ok {
+		m.ok++
+	} else {
+		m.failed++
+	}
+}
+
+func TestDeliverPending(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	repo := &mockNotificationJobRepository{jobs: []*repository.NotificationJob{
+		{ID: 1, VerificationID: "v-1", Channel: repository.NotificationChannelInApp},
+		{ID: 2, VerificationID: "v-1", Channel: repository.NotificationChannelWebhook, WebhookID: stringPtr("wh-1")},
+		{ID: 3, VerificationID: "v-1", Channel: repository.NotificationChannelWebhook, WebhookID: stringPtr("wh-2"), Attempts: 2},
+		{ID: 4, VerificationID: "v-1", Channel: repository.NotificationChannelWebhook},
+		{ID: 5, VerificationID: "v-1", Channel: repository.NotificationChannelWebhook, WebhookID: stringPtr("wh-2"), Attempts: 4},
+	}}
+	webhooks := NewWebhookService(
+		&mockWebhookRepository{webhooks: []*model.Webhook{
+			{ID: "wh-1", URL: "https://events.example.com", Template: model.WebhookTemplateFull},
+			{ID: "wh-2", URL: "https://down.example.com", Template: model.WebhookTemplateFull},
+		}},
+		&mockVerificationRepository{
+			getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+				return &model.Verification{ID: id, Inn: "7707083893", Status: model.VerificationStatusCompleted}, nil
+			},
+		},
+		webhook.NewRenderer("/scoring"),
+		&mockWebhookSender{failURL: "https://down.example.com"},
+		companychange.NewDetector(nil, 0),
+		zaptest.NewLogger(t))
+	notifications := &mockNotificationService{}
+	deliveries := &mockDeliveryRecorder{}
+	cfg := config.NotificationsConfig{DeliveryBatchSize: 10, MaxAttempts: 5, RetryBackoff: time.Minute, MaxRetryBackoff: 5 * time.Minute}
+
+	service := NewNotificationJobService(repo, notifications, webhooks, deliveries, cfg, zaptest.NewLogger(t)).(*notificationJobService)
+	service.now = func() time.Time { return now }
+
+	delivered, err := service.DeliverPending(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if delivered != 2 {
+		t.Errorf("expected 2 delivered jobs, but got %d", delivered)
+	}
+	if len(notifications.notified) != 1 || deliveries.ok != 1 || deliveries.failed != 0 {
+		t.Errorf("expected one in-app delivery counted for SLO, but got %v and %+v", notifications.notified, deliveries)
+	}
+
+	expected := []struct {
+		status string
+		next   time.Time
+		failed bool
+	}{
+		{status: repository.NotificationJobDelivered, next: now},
+		{status: repository.NotificationJobDelivered, next: now},
+		// Третья попытка ждет удвоенную дважды паузу, но не дольше MaxRetryBackoff
+		{status: repository.NotificationJobPending, next: now.Add(4 * time.Minute), failed: true},
+		// Удаленный вебхук не повторяется
+		{status: repository.NotificationJobFailed, next: now, failed: true},
+		{status: repository.NotificationJobFailed, next: now, failed: true},
+	}
+	if len(repo.attempts) != len(expected) {
+		t.Fatalf("expected %d recorded attempts, but got %d", len(expected), len(repo.attempts))
+	}
+	for i, want := range expected {
+		got := repo.attempts[i]
+		if got.status != want.status || !got.nextAttemptAt.Equal(want.next) || (got.attempt.Error != nil) != want.failed {
+			t.Errorf("job %d: expected %s next at %s (failed %v), but got %s next at %s, error %v",
+				got.jobID, want.status, want.next, want.failed, got.status, got.nextAttemptAt, got.attempt.Error)
+		}
+		if got.attempt.Attempt != repo.jobs[i].Attempts {
+			t.Errorf("job %d: expected attempt %d, but got %d", got.jobID, repo.jobs[i].Attempts, got.attempt.Attempt)
+		}
+	}
+}
+
+func TestDeliverPendingInAppFailure(t *testing.T) {
+	repo := &mockNotificationJobRepository{jobs: []*repository.NotificationJob{
+		{ID: 1, VerificationID: "v-1", Channel: repository.NotificationChannelInApp},
+	}}
+	notifications := &mockNotificationService{err: errors.New("database is down")}
+	deliveries := &mockDeliveryRecorder{}
+	cfg := config.NotificationsConfig{DeliveryBatchSize: 10, MaxAttempts: 3, RetryBackoff: time.Minute, MaxRetryBackoff: time.Hour}
+	service := NewNotificationJobService(repo, notifications, nil, deliveries, cfg, zaptest.NewLogger(t))
+
+	if _, err := service.DeliverPending(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deliveries.failed != 1 {
+		t.Errorf("expected failed delivery counted for SLO, but got %+v", deliveries)
+	}
+	if len(repo.attempts) != 1 || repo.attempts[0].status != repository.NotificationJobPending || *repo.attempts[0].attempt.Error != "database is down" {
+		t.Errorf("expected the job to be retried with the error in its history, but got %+v", repo.attempts)
+	}
+}
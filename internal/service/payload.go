@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/payloads"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// rejectedSampleBytes сколько байт отклоненных данных сохраняется для разбора причины
+	rejectedSampleBytes = 4096
+
+	defaultRejectedPayloadsLimit = 100
+	maxRejectedPayloadsLimit     = 500
+)
+
+// PayloadStore объектное хранилище, в которое переносятся данные больше предела типа
+type PayloadStore interface {
+	Key(name string) string
+	Location(key string) string
+	Put(ctx context.Context, key, contentType string, body []byte) error
+}
+
+// PayloadStoreRouter хранилище, выбирающее бакет по проверке, когда данные арендаторов хранятся
+// в разных регионах. StoreFor возвращает nil, если в регионе проверки хранилища нет.
+type PayloadStoreRouter interface {
+	StoreFor(ctx context.Context, verificationID string) (PayloadStore, error)
+}
+
+// PayloadService проверка данных поставщиков при поступлении. Исправимые искажения кодировки
+// исправляются на месте, данные больше предела переносятся в объектное хранилище, а данные,
+// которые нельзя сохранить, отклоняются в rejected_payloads с описанием проблем.
+type PayloadService interface {
+	// Ingest проверяет данные проверки. Ошибки отдельных типов данных только пишутся в журнал.
+	Ingest(ctx context.Context, verificationID string) error
+	ListRejectedPayloads(ctx context.Context, verificationID *string, limit *int32) ([]*model.RejectedPayload, error)
+}
+
+type payloadService struct {
+	repo   repository.PayloadRepository
+	limits payloads.Limits
+	store  PayloadStore
+	logger *zap.Logger
+}
+
+// NewPayloadService создает сервис проверки данных. store может быть nil, тогда данные больше
+// предела отклоняются.
+func NewPayloadService(repo repository.PayloadRepository, limits payloads.Limits, store PayloadStore, logger *zap.Logger) PayloadService {
+	return &payloadService{
+		repo:   repo,
+		limits: limits,
+		store:  store,
+		logger: logger,
+	}
+}
+
+func (s *payloadService) Ingest(ctx context.Context, verificationID string) error {
+	stored, err := s.repo.ListByVerification(ctx, verificationID)
+	if err != nil {
+		return err
+	}
+
+	for _, payload := range stored {
+		log := s.logger.With(zap.String("verification_id", verificationID), zap.String("data_type", string(payload.DataType)))
+
+		result := payloads.Inspect(payload.Data, s.limits.MaxBytes(payload.DataType))
+		if result.Outcome == payloads.OutcomeValid {
+			continue
+		}
+		metrics.PayloadsInspected.WithLabelValues(string(payload.DataType), string(result.Outcome)).Inc()
+
+		switch result.Outcome {
+		case payloads.OutcomeNormalized:
+			err = s.replace(ctx, verificationID, payload, result.Data)
+		case payloads.OutcomeOversized:
+			err = s.offload(ctx, verificationID, payload, result)
+		case payloads.OutcomeRejected:
+			err = s.reject(ctx, verificationID, payload, repository.RejectedPayloadInvalid, result)
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			// Воркер заменил данные, пока они проверялись: новые данные проверит следующая поправка
+			log.Info("payload changed during inspection", zap.Error(err))
+			continue
+		}
+		if err != nil {
+			log.Error("failed to ingest payload", zap.Error(err), zap.String("outcome", string(result.Outcome)))
+			continue
+		}
+		log.Info("payload inspected",
+			zap.String("outcome", string(result.Outcome)),
+			zap.Int("size_bytes", result.Size),
+			zap.Strings("diagnostics", result.Diagnostics))
+	}
+	return nil
+}
+
+func (s *payloadService) replace(ctx context.Context, verificationID string, payload *repository.StoredPayload, data string) error {
+	_, err := s.repo.Replace(ctx, verificationID, payload.DataType, payload.DataHash, data)
+	return err
+}
+
+// offload переносит данные в объектное хранилище и сохраняет вместо них ссылку. Объект называется
+// по SHA-256 содержимого, поэтому повторная загрузка тех же данных его не меняет.
+func (s *payloadService) offload(ctx context.Context, verificationID string, payload *repository.StoredPayload, result payloads.Result) error {
+	store := s.store
+	if router, ok := store.(PayloadStoreRouter); ok {
+		var err error
+		if store, err = router.StoreFor(ctx, verificationID); err != nil {
+			return err
+		}
+	}
+	if store == nil {
+		return s.reject(ctx, verificationID, payload, repository.RejectedPayloadOversized, result)
+	}
+
+	sum := sha256.Sum256([]byte(result.Data))
+	digest := hex.EncodeToString(sum[:])
+	key := store.Key(string(payload.DataType) + "/" + digest + ".json")
+	if err := store.Put(ctx, key, "application/json", []byte(result.Data)); err != nil {
+		// Данные остаются в базе: лучше сохранить большой документ, чем потерять его
+		return fmt.Errorf("failed to offload payload: %w", err)
+	}
+
+	reference := payloads.Reference(payloads.Offloaded{
+		Location:  store.Location(key),
+		SizeBytes: result.Size,
+		SHA256:    digest,
+	})
+	return s.replace(ctx, verificationID, payload, reference)
+}
+
+func (s *payloadService) reject(ctx context.Context, verificationID string, payload *repository.StoredPayload, reason string, result payloads.Result) error {
+	return s.repo.Reject(ctx, &repository.RejectedPayload{
+		VerificationID: verificationID,
+		DataType:       payload.DataType,
+		DataHash:       payload.DataHash,
+		Reason:         reason,
+		Diagnostics:    result.Diagnostics,
+		SizeBytes:      result.Size,
+		Sample:         rejectedSample(payload.Data),
+	})
+}
+
+// rejectedSample начало данных, обрезанное по границе символа; байты, не образующие UTF-8,
+// заменяются на U+FFFD, чтобы образец можно было сохранить в TEXT
+func rejectedSample(data string) string {
+	if len(data) > rejectedSampleBytes {
+		cut := rejectedSampleBytes
+		for cut > 0 && !utf8.RuneStart(data[cut]) {
+			cut--
+		}
+		data = data[:cut]
+	}
+	return strings.ToValidUTF8(data, "\uFFFD")
+}
+
+func (s *payloadService) ListRejectedPayloads(ctx context.Context, verificationID *string, limit *int32) ([]*model.RejectedPayload, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+	n := defaultRejectedPayloadsLimit
+	if limit != nil {
+		if *limit < 0 || *limit > maxRejectedPayloadsLimit {
+			return nil, invalidInputf("limit must be between 0 and %d, got %d", maxRejectedPayloadsLimit, *limit)
+		}
+		n = int(*limit)
+	}
+
+	rejected, err := s.repo.ListRejected(ctx, verificationID, n)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.RejectedPayload, 0, len(rejected))
+	for _, payload := range rejected {
+		result = append(result, &model.RejectedPayload{
+			ID:             strconv.FormatInt(payload.ID, 10),
+			VerificationID: payload.VerificationID,
+			DataType:       payload.DataType,
+			DataHash:       payload.DataHash,
+			Reason:         model.RejectedPayloadReason(payload.Reason),
+			Diagnostics:    payload.Diagnostics,
+			SizeBytes:      int32(payload.SizeBytes),
+			Sample:         payload.Sample,
+			RejectedAt:     payload.RejectedAt.Format(time.RFC3339),
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/payloads"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для PayloadRepository
+type mockPayloadRepository struct {
+	repository.PayloadRepository
+	stored   []*repository.StoredPayload
+	replaced map[model.VerificationDataType]string
+	rejected []*repository.RejectedPayload
+	conflict model.VerificationDataType
+}
+
+func (m *mockPayloadRepository) ListByVerification(ctx context.Context, verificationID string) ([]*repository.StoredPayload, error) {
+	return m.stored, nil
+}
+
+func (m *mockPayloadRepository) Replace(ctx context.Context, verificationID string, dataType model.VerificationDataType, previousHash, data string) (string, error) {
+	if dataType == m.conflict {
+		return "", &repository.Error{Kind: repository.ErrConflict, Err: errors.New("changed")}
+	}
+	m.replaced[dataType] = data
+	return "new-hash", nil
+}
+
+func (m *mockPayloadRepository) Reject(ctx context.Context, rejected *repository.RejectedPayload) error {
+	m.rejected = append(m.rejected, rejected)
+	return nil
+}
+
+// Mock для PayloadStore
+type mockPayloadStore struct {
+	objects map[string]string
+	err     error
+}
+
+func (m *mockPayloadStore) Key(name string) string {
+	return "payloads/" + name
+}
+
+func (m *mockPayloadStore) Location(key string) string {
+	return "s3://bucket/" + key
+}
+
+func (m *mockPayloadStore) Put(ctx context.Context, key, contentType string, body []byte) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.objects[key] = string(body)
+	return nil
+}
+
+func TestIngestPayloads(t *testing.T) {
+	large := `{"cases": "` + strings.Repeat("x", 100) + `"}`
+	stored := func() []*repository.StoredPayload {
+		return []*repository.StoredPayload{
+			{DataType: model.VerificationDataTypeBasicInformation, DataHash: "h1", Data: `{"name": "Ромашка"}`},
+			{DataType: model.VerificationDataTypeActivities, DataHash: "h2", Data: "{\"name\": \"Р\u00a0РѕРјР°С€РєР°\"}"},
+			{DataType: model.VerificationDataTypeArbitrageStatistics, DataHash: "h3", Data: large},
+			{DataType: model.VerificationDataTypeAffiliatedCompanies, DataHash: "h4", Data: `{"scan": "PK\u0003\u0004"}`},
+		}
+	}
+	limits, err := payloads.ParseLimits(0, []string{"ARBITRAGE_STATISTICS=64"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("with_store", func(t *testing.T) {
+		repo := &mockPayloadRepository{stored: stored(), replaced: map[model.VerificationDataType]string{}}
+		store := &mockPayloadStore{objects: map[string]string{}}
+		service := NewPayloadService(repo, limits, store, zaptest.NewLogger(t))
+
+		if err := service.Ingest(context.Background(), "v-1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repo.replaced) != 2 || repo.replaced[model.VerificationDataTypeActivities] != `{"name":"Ромашка"}` {
+			t.Fatalf("unexpected replaced payloads %v", repo.replaced)
+		}
+		reference := repo.replaced[model.VerificationDataTypeArbitrageStatistics]
+		if !payloads.IsReference(reference) || !strings.Contains(reference, `"location":"s3://bucket/payloads/ARBITRAGE_STATISTICS/`) {
+			t.Errorf("expected a reference to the offloaded payload, but got %s", reference)
+		}
+		if len(store.objects) != 1 {
+			t.Errorf("expected 1 offloaded object, but got %d", len(store.objects))
+		}
+		if len(repo.rejected) != 1 || repo.rejected[0].DataType != model.VerificationDataTypeAffiliatedCompanies ||
+			repo.rejected[0].Reason != repository.RejectedPayloadInvalid || repo.rejected[0].DataHash != "h4" {
+			t.Fatalf("unexpected rejected payloads %+v", repo.rejected)
+		}
+	})
+
+	t.Run("without_store", func(t *testing.T) {
+		repo := &mockPayloadRepository{stored: stored(), replaced: map[model.VerificationDataType]string{},
+			conflict: model.VerificationDataTypeActivities}
+		service := NewPayloadService(repo, limits, nil, zaptest.NewLogger(t))
+
+		if err := service.Ingest(context.Background(), "v-1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repo.replaced) != 0 {
+			t.Errorf("expected no replaced payloads, but got %v", repo.replaced)
+		}
+		if len(repo.rejected) != 2 || repo.rejected[0].Reason != repository.RejectedPayloadOversized ||
+			repo.rejected[0].Diagnostics[0] != "payload of 113 bytes exceeds the limit of 64 bytes" {
+			t.Fatalf("unexpected rejected payloads %+v", repo.rejected)
+		}
+	})
+
+	t.Run("upload_failure", func(t *testing.T) {
+		repo := &mockPayloadRepository{stored: stored()[2:3], replaced: map[model.VerificationDataType]string{}}
+		store := &mockPayloadStore{err: errors.New("s3 returned 503")}
+		service := NewPayloadService(repo, limits, store, zaptest.NewLogger(t))
+
+		if err := service.Ingest(context.Background(), "v-1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repo.replaced) != 0 || len(repo.rejected) != 0 {
+			t.Errorf("expected the payload to be kept, but got replaced %v and rejected %+v", repo.replaced, repo.rejected)
+		}
+	})
+}
+
+func TestRejectedSample(t *testing.T) {
+	data := strings.Repeat("a", rejectedSampleBytes-1) + "Ж"
+	if sample := rejectedSample(data); sample != strings.Repeat("a", rejectedSampleBytes-1) {
+		t.Errorf("expected the sample to be cut before the split character, but got %d bytes", len(sample))
+	}
+	if sample := rejectedSample("{\"name\": \"\xcf\xee\xeb\"}"); sample != "{\"name\": \"\uFFFD\"}" {
+		t.Errorf("unexpected sample %q", sample)
+	}
+}
+
+func TestListRejectedPayloadsRequiresAdmin(t *testing.T) {
+	service := NewPayloadService(&mockPayloadRepository{}, payloads.Limits{}, nil, zaptest.NewLogger(t))
+	analyst := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@example.com"})
+	if _, err := service.ListRejectedPayloads(analyst, nil, nil); err == nil {
+		t.Fatal("expected an error for a non-admin caller")
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/persisted"
+	"scoring_api_gateway/internal/repository"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+	"go.uber.org/zap"
+)
+
+// PersistedOperationService управляет списками операций, разрешенных ключам сервисных аккаунтов
+type PersistedOperationService interface {
+	RegisterOperation(ctx context.Context, apiKey, document string, name *string) (*model.PersistedOperation, error)
+	ListOperations(ctx context.Context, apiKey string) ([]*model.PersistedOperation, error)
+	DeleteOperation(ctx context.Context, apiKey, hash string) error
+}
+
+type persistedOperationService struct {
+	repo   repository.PersistedOperationRepository
+	logger *zap.Logger
+}
+
+func NewPersistedOperationService(repo repository.PersistedOperationRepository, logger *zap.Logger) PersistedOperationService {
+	return &persistedOperationService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// RegisterOperation разрешает операцию ключу. Хэш считается от текста в том виде, в котором его
+// отправляет клиент, поэтому документ сохраняется без форматирования.
+func (s *persistedOperationService) RegisterOperation(ctx context.Context, apiKey, document string, name *string) (*model.PersistedOperation, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(apiKey) == "" {
+		return nil, invalidInputf("api key cannot be empty")
+	}
+	if _, err := parser.ParseQuery(&ast.Source{Input: document}); err != nil {
+		return nil, invalidInputf("invalid operation document: %v", err)
+	}
+
+	principal, _ := auth.PrincipalFromContext(ctx)
+	operation := &model.PersistedOperation{
+		Hash:      persisted.Hash(document),
+		Name:      name,
+		Document:  document,
+		CreatedBy: principal.Email,
+	}
+	if err := s.repo.Register(ctx, apiKey, operation); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("persisted operation registered",
+		zap.String("api_key", apiKey),
+		zap.String("hash", operation.Hash),
+		zap.String("created_by", principal.Email))
+	return operation, nil
+}
+
+func (s *persistedOperationService) ListOperations(ctx context.Context, apiKey string) ([]*model.PersistedOperation, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	operations, err := s.repo.List(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	if operations == nil {
+		operations = []*model.PersistedOperation{}
+	}
+	return operations, nil
+}
+
+func (s *persistedOperationService) DeleteOperation(ctx context.Context, apiKey, hash string) error {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, apiKey, hash); err != nil {
+		return err
+	}
+
+	s.logger.Info("persisted operation deleted", zap.String("api_key", apiKey), zap.String("hash", hash))
+	return nil
+}
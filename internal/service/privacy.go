@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+type PrivacyService interface {
+	AnonymizeExpired(ctx context.Context) (int, error)
+	SetLegalHold(ctx context.Context, id string, hold bool) (*model.Verification, error)
+}
+
+type privacyService struct {
+	repo         repository.PrivacyRepository
+	verification VerificationService
+	audit        AuditService
+	cfg          config.PrivacyConfig
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+func NewPrivacyService(repo repository.PrivacyRepository, verification VerificationService, audit AuditService, cfg config.PrivacyConfig, logger *zap.Logger) PrivacyService {
+	return &privacyService{
+		repo:         repo,
+		verification: verification,
+		audit:        audit,
+		cfg:          cfg,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// AnonymizeExpired обезличивает пачку проверок старше срока хранения и возвращает их количество
+func (s *privacyService) AnonymizeExpired(ctx context.Context) (int, error) {
+	if s.cfg.Salt == "" {
+		return 0, fmt.Errorf("anonymization salt is not configured")
+	}
+
+	ids, err := s.repo.GetAnonymizationCandidates(ctx, s.now().Add(-s.cfg.AnonymizeAfter), s.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	anonymized := 0
+	for _, id := range ids {
+		ok, err := s.repo.Anonymize(ctx, id, s.cfg.Salt)
+		if err != nil {
+			s.logger.Error("failed to anonymize verification", zap.Error(err), zap.String("verification_id", id))
+			continue
+		}
+		if !ok {
+			continue
+		}
+		anonymized++
+
+		if err := s.audit.RecordEvent(ctx, id, model.AuditEventTypeAnonymized, "", nil); err != nil {
+			s.logger.Error("failed to record anonymization", zap.Error(err), zap.String("verification_id", id))
+		}
+	}
+
+	if anonymized > 0 {
+		s.logger.Info("verifications anonymized", zap.Int("count", anonymized))
+	}
+	return anonymized, nil
+}
+
+// SetLegalHold ставит проверку на удержание, исключая ее из обезличивания, или снимает удержание
+func (s *privacyService) SetLegalHold(ctx context.Context, id string, hold bool) (*model.Verification, error) {
+	if id == "" {
+		return nil, invalidInputf("verification id cannot be empty")
+	}
+
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetLegalHold(ctx, id, hold); err != nil {
+		return nil, err
+	}
+
+	actor := ""
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		actor = principal.Email
+	}
+	if err := s.audit.RecordEvent(ctx, id, model.AuditEventTypeLegalHoldChanged, actor, map[string]any{"legal_hold": hold}); err != nil {
+		s.logger.Error("failed to record legal hold change", zap.Error(err), zap.String("verification_id", id))
+	}
+
+	return s.verification.GetVerification(ctx, id)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для PrivacyRepository
+type mockPrivacyRepository struct {
+	getCandidatesFunc func(ctx context.Context, createdBefore time.Time, limit int) ([]string, error)
+	anonymizeFunc     func(ctx context.Context, id, salt string) (bool, error)
+	setLegalHoldFunc  func(ctx context.Context, id string, hold bool) error
+}
+
+func (m *mockPrivacyRepository) GetAnonymizationCandidates(ctx context.Context, createdBefore time.Time, limit int) ([]string, error) {
+	if m.getCandidatesFunc != nil {
+		return m.getCandidatesFunc(ctx, createdBefore, limit)
+	}
+	return nil, nil
+}
+
+func (m *mockPrivacyRepository) Anonymize(ctx context.Context, id, salt string) (bool, error) {
+	if m.anonymizeFunc != nil {
+		return m.anonymizeFunc(ctx, id, salt)
+	}
+	return true, nil
+}
+
+func (m *mockPrivacyRepository) SetLegalHold(ctx context.Context, id string, hold bool) error {
+	if m.setLegalHoldFunc != nil {
+		return m.setLegalHoldFunc(ctx, id, hold)
+	}
+	return nil
+}
+
+func TestAnonymizeExpired(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	cfg := config.PrivacyConfig{AnonymizeAfter: 365 * 24 * time.Hour, Salt: "pepper", BatchSize: 10}
+
+	var createdBefore time.Time
+	var recorded []string
+	repo := &mockPrivacyRepository{
+		getCandidatesFunc: func(ctx context.Context, before time.Time, limit int) ([]string, error) {
+			createdBefore = before
+			return []string{"held-meanwhile", "failing", "old"}, nil
+		},
+		anonymizeFunc: func(ctx context.Context, id, salt string) (bool, error) {
+			if salt != "pepper" {
+				t.Errorf("expected configured salt, but got %q", salt)
+			}
+			switch id {
+			case "held-meanwhile":
+				return false, nil
+			case "failing":
+				return false, errors.New("database error")
+			}
+			return true, nil
+		},
+	}
+	auditRepo := &mockAuditRepository{
+		addEventFunc: func(ctx context.Context, verificationID string, eventType model.AuditEventType, actor string, details map[string]any) error {
+			if eventType == model.AuditEventTypeAnonymized {
+				recorded = append(recorded, verificationID)
+			}
+			return nil
+		},
+	}
+
+	logger := zaptest.NewLogger(t)
+	service := NewPrivacyService(repo, nil, NewAuditService(auditRepo, nil, nil, logger), cfg, logger).(*privacyService)
+	service.now = func() time.Time { return now }
+
+	count, err := service.AnonymizeExpired(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 anonymized verification, but got %d", count)
+	}
+	if !createdBefore.Equal(now.Add(-cfg.AnonymizeAfter)) {
+		t.Errorf("expected retention cutoff %v, but got %v", now.Add(-cfg.AnonymizeAfter), createdBefore)
+	}
+	if len(recorded) != 1 || recorded[0] != "old" {
+		t.Errorf("expected anonymization recorded only for 'old', but got %v", recorded)
+	}
+
+	cfg.Salt = ""
+	service = NewPrivacyService(repo, nil, NewAuditService(auditRepo, nil, nil, logger), cfg, logger).(*privacyService)
+	if _, err := service.AnonymizeExpired(context.Background()); err == nil || !containsError(err.Error(), "anonymization salt is not configured") {
+		t.Errorf("expected missing salt error, but got %v", err)
+	}
+}
+
+func TestSetLegalHold(t *testing.T) {
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "admin@example.com", Roles: []string{auth.RoleAdmin}})
+	analyst := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@example.com"})
+
+	var holdDetails map[string]any
+	var holdActor string
+	repo := &mockPrivacyRepository{
+		setLegalHoldFunc: func(ctx context.Context, id string, hold bool) error {
+			if id != "test-id" {
+				return errNotFound("verification not found: %s", id)
+			}
+			return nil
+		},
+	}
+	auditRepo := &mockAuditRepository{
+		addEventFunc: func(ctx context.Context, verificationID string, eventType model.AuditEventType, actor string, details map[string]any) error {
+			holdActor, holdDetails = actor, details
+			return nil
+		},
+	}
+	verificationRepo := &mockVerificationRepository{
+		getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+			return &model.Verification{ID: id, LegalHold: true}, nil
+		},
+	}
+
+	logger := zaptest.NewLogger(t)
+	verificationService := NewVerificationService(verificationRepo, &mockNATSClient{}, nil, logger)
+	service := NewPrivacyService(repo, verificationService, NewAuditService(auditRepo, verificationService, nil, logger), config.PrivacyConfig{}, logger)
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		id            string
+		expectedError string
+	}{
+		{name: "admin", ctx: admin, id: "test-id"},
+		{name: "not_admin", ctx: analyst, id: "test-id", expectedError: "access denied"},
+		{name: "not_found", ctx: admin, id: "missing-id", expectedError: "verification not found"},
+		{name: "empty_id", ctx: admin, id: "", expectedError: "verification id cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verification, err := service.SetLegalHold(tt.ctx, tt.id, true)
+
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing %q, but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !verification.LegalHold {
+				t.Errorf("expected verification on legal hold, but got %+v", verification)
+			}
+			if holdActor != "admin@example.com" || holdDetails["legal_hold"] != true {
+				t.Errorf("expected legal hold change recorded by admin, but got actor %q details %v", holdActor, holdDetails)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+
+	"scoring_api_gateway/graph/model"
+)
+
+// ReadTracker учитывает чтения данных компаний
+type ReadTracker interface {
+	RecordRead(inn string)
+}
+
+// readTrackingVerificationService учитывает, какие компании запрашивают пользователи,
+// чтобы данные популярных компаний обновлялись заранее
+type readTrackingVerificationService struct {
+	VerificationService
+	tracker ReadTracker
+}
+
+// NewReadTrackingVerificationService оборачивает сервис проверок учетом чтений по ИНН
+func NewReadTrackingVerificationService(inner VerificationService, tracker ReadTracker) VerificationService {
+	return &readTrackingVerificationService{
+		VerificationService: inner,
+		tracker:             tracker,
+	}
+}
+
+func (s *readTrackingVerificationService) GetVerification(ctx context.Context, id string) (*model.Verification, error) {
+	verification, err := s.VerificationService.GetVerification(ctx, id)
+	if err == nil && verification != nil {
+		s.tracker.RecordRead(verification.Inn)
+	}
+	return verification, err
+}
+
+func (s *readTrackingVerificationService) GetVerificationWithData(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.VerificationDataResult, error) {
+	result, err := s.VerificationService.GetVerificationWithData(ctx, id, dataTypes)
+	if err == nil && result != nil {
+		s.tracker.RecordRead(result.Verification.Inn)
+	}
+	return result, err
+}
+
+// GetAllVerifications учитывает только поиск по ИНН: списки без фильтра не говорят об интересе к компании
+func (s *readTrackingVerificationService) GetAllVerifications(ctx context.Context, filter *model.VerificationFilter, scope *model.VerificationScope, limit *int32, offset *int32) ([]*model.Verification, error) {
+	verifications, err := s.VerificationService.GetAllVerifications(ctx, filter, scope, limit, offset)
+	if err == nil && filter != nil && filter.Inn != nil && len(verifications) > 0 {
+		s.tracker.RecordRead(*filter.Inn)
+	}
+	return verifications, err
+}
+
+func (s *readTrackingVerificationService) GetCompanySnapshot(ctx context.Context, inn string, asOf string) (*model.CompanySnapshot, error) {
+	snapshot, err := s.VerificationService.GetCompanySnapshot(ctx, inn, asOf)
+	if err == nil && snapshot != nil {
+		s.tracker.RecordRead(inn)
+	}
+	return snapshot, err
+}
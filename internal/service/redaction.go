@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/signing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// redactedValue значение, которым REDACT заменяет данные по пути
+const redactedValue = `"[REDACTED]"`
+
+// DataRedactionService исправление и удаление значений в сохраненных данных проверок администратором.
+// Каждое изменение подписывается ключом шлюза и записывается в журнал аудита, а данные до изменения
+// сохраняются в неизменяемой истории.
+type DataRedactionService interface {
+	PatchData(ctx context.Context, verificationID string, dataType model.VerificationDataType, path []string, value, reason string) (*model.DataRedaction, error)
+	RedactData(ctx context.Context, verificationID string, dataType model.VerificationDataType, path []string, reason string) (*model.DataRedaction, error)
+	ListRedactions(ctx context.Context, verificationID string) ([]*model.DataRedaction, error)
+}
+
+type dataRedactionService struct {
+	repo       repository.DataRedactionRepository
+	audit      AuditService
+	signatures SignatureService
+	signer     *signing.Signer
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewDataRedactionService создает сервис изменения данных. signer может быть nil, тогда изменения
+// данных недоступны: неподписанное изменение нельзя было бы отличить от подделки.
+func NewDataRedactionService(repo repository.DataRedactionRepository, audit AuditService, signatures SignatureService, signer *signing.Signer, logger *zap.Logger) DataRedactionService {
+	return &dataRedactionService{
+		repo:       repo,
+		audit:      audit,
+		signatures: signatures,
+		signer:     signer,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+func (s *dataRedactionService) PatchData(ctx context.Context, verificationID string, dataType model.VerificationDataType, path []string, value, reason string) (*model.DataRedaction, error) {
+	if !json.Valid([]byte(value)) {
+		return nil, invalidInputf("value must be a JSON document")
+	}
+	return s.change(ctx, verificationID, dataType, model.DataRedactionOperationPatch, path, []byte(value), reason)
+}
+
+func (s *dataRedactionService) RedactData(ctx context.Context, verificationID string, dataType model.VerificationDataType, path []string, reason string) (*model.DataRedaction, error) {
+	return s.change(ctx, verificationID, dataType, model.DataRedactionOperationRedact, path, []byte(redactedValue), reason)
+}
+
+func (s *dataRedactionService) change(ctx context.Context, verificationID string, dataType model.VerificationDataType, operation model.DataRedactionOperation, path []string, value []byte, reason string) (*model.DataRedaction, error) {
+	if verificationID == "" {
+		return nil, invalidInputf("verification id cannot be empty")
+	}
+	if !dataType.IsValid() {
+		return nil, invalidInputf("invalid data type: %s", dataType)
+	}
+	if len(path) == 0 {
+		return nil, invalidInputf("path cannot be empty")
+	}
+	for _, key := range path {
+		if key == "" {
+			return nil, invalidInputf("path cannot contain empty keys")
+		}
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, invalidInputf("reason cannot be empty")
+	}
+
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if s.signer == nil {
+		return nil, fmt.Errorf("signing key is not configured")
+	}
+
+	actor := ""
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		actor = principal.Email
+	}
+
+	change := &repository.DataRedaction{
+		ID:             uuid.New().String(),
+		VerificationID: verificationID,
+		DataType:       dataType,
+		Operation:      operation,
+		Path:           path,
+		Reason:         reason,
+		Actor:          actor,
+		CreatedAt:      s.now().UTC().Truncate(time.Second),
+	}
+	err := s.repo.Apply(ctx, change, value, func(change *repository.DataRedaction) {
+		change.KeyID = s.signer.KeyID()
+		change.Signature = base64.StdEncoding.EncodeToString(s.signer.Sign(redactionContent(change)))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	eventType := model.AuditEventTypeDataPatched
+	if operation == model.DataRedactionOperationRedact {
+		eventType = model.AuditEventTypeDataRedacted
+	}
+	details := map[string]any{
+		"redaction_id":       change.ID,
+		"data_type":          dataType,
+		"path":               path,
+		"previous_data_hash": change.PreviousDataHash,
+		"data_hash":          change.DataHash,
+		"reason":             reason,
+	}
+	if err := s.audit.RecordEvent(ctx, verificationID, eventType, actor, details); err != nil {
+		s.logger.Error("failed to record data change", zap.Error(err), zap.String("verification_id", verificationID))
+	}
+
+	// Подпись итогов проверки содержит хэши данных и после изменения перестала бы сходиться
+	if _, err := s.signatures.SignVerification(ctx, verificationID); err != nil {
+		s.logger.Error("failed to re-sign changed verification", zap.Error(err), zap.String("verification_id", verificationID))
+	}
+
+	s.logger.Info("verification data changed",
+		zap.String("verification_id", verificationID),
+		zap.String("data_type", string(dataType)),
+		zap.String("operation", string(operation)),
+		zap.String("redaction_id", change.ID),
+		zap.String("actor", actor))
+
+	return toModelDataRedaction(change), nil
+}
+
+func (s *dataRedactionService) ListRedactions(ctx context.Context, verificationID string) ([]*model.DataRedaction, error) {
+	if verificationID == "" {
+		return nil, invalidInputf("verification id cannot be empty")
+	}
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	redactions, err := s.repo.ListByVerification(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.DataRedaction, 0, len(redactions))
+	for _, redaction := range redactions {
+		result = append(result, toModelDataRedaction(redaction))
+	}
+	return result, nil
+}
+
+// redactionContent возвращает подписываемый текст изменения
+func redactionContent(r *repository.DataRedaction) []byte {
+	return signing.DataRedaction{
+		ID:               r.ID,
+		VerificationID:   r.VerificationID,
+		DataType:         string(r.DataType),
+		Operation:        string(r.Operation),
+		Path:             r.Path,
+		PreviousDataHash: r.PreviousDataHash,
+		DataHash:         r.DataHash,
+		Actor:            r.Actor,
+		Reason:           r.Reason,
+		CreatedAt:        r.CreatedAt,
+	}.Content()
+}
+
+func toModelDataRedaction(r *repository.DataRedaction) *model.DataRedaction {
+	return &model.DataRedaction{
+		ID:               r.ID,
+		VerificationID:   r.VerificationID,
+		DataType:         r.DataType,
+		Operation:        r.Operation,
+		Path:             r.Path,
+		PreviousDataHash: r.PreviousDataHash,
+		DataHash:         r.DataHash,
+		Reason:           r.Reason,
+		Actor:            r.Actor,
+		CreatedAt:        r.CreatedAt.Format(time.RFC3339),
+		KeyID:            r.KeyID,
+		Content:          string(redactionContent(r)),
+		Signature:        r.Signature,
+	}
+}
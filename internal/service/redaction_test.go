@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/signing"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для DataRedactionRepository
+type mockDataRedactionRepository struct {
+	repository.DataRedactionRepository
+	applied []*repository.DataRedaction
+	values  []string
+}
+
+func (m *mockDataRedactionRepository) Apply(ctx context.Context, change *repository.DataRedaction, value []byte, sign func(*repository.DataRedaction)) error {
+	change.PreviousDataHash = strings.Repeat("a", 64)
+	change.DataHash = strings.Repeat("b", 64)
+	sign(change)
+	m.applied = append(m.applied, change)
+	m.values = append(m.values, string(value))
+	return nil
+}
+
+func TestChangeVerificationData(t *testing.T) {
+	signer, err := signing.NewSigner(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "admin@example.com", Roles: []string{auth.RoleAdmin}})
+	analyst := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@example.com"})
+	path := []string{"founders", "0", "passport"}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		signer        *signing.Signer
+		redact        bool
+		value         string
+		path          []string
+		reason        string
+		expectedValue string
+		expectedEvent model.AuditEventType
+		expectedError string
+	}{
+		{name: "patch", ctx: admin, signer: signer, value: `{"series": "4510"}`, path: path, reason: "provider correction",
+			expectedValue: `{"series": "4510"}`, expectedEvent: model.AuditEventTypeDataPatched},
+		{name: "redact", ctx: admin, signer: signer, redact: true, path: path, reason: " court order ",
+			expectedValue: `"[REDACTED]"`, expectedEvent: model.AuditEventTypeDataRedacted},
+		{name: "invalid_value", ctx: admin, signer: signer, value: `{"series"`, path: path, reason: "fix", expectedError: "value must be a JSON document"},
+		{name: "empty_path", ctx: admin, signer: signer, redact: true, reason: "fix", expectedError: "path cannot be empty"},
+		{name: "empty_key", ctx: admin, signer: signer, redact: true, path: []string{"founders", ""}, reason: "fix", expectedError: "path cannot contain empty keys"},
+		{name: "empty_reason", ctx: admin, signer: signer, redact: true, path: path, reason: "  ", expectedError: "reason cannot be empty"},
+		{name: "not_admin", ctx: analyst, signer: signer, redact: true, path: path, reason: "fix", expectedError: "access denied"},
+		{name: "signing_disabled", ctx: admin, redact: true, path: path, reason: "fix", expectedError: "signing key is not configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockDataRedactionRepository{}
+			var events []model.AuditEventType
+			var eventActor string
+			auditRepo := &mockAuditRepository{
+				addEventFunc: func(ctx context.Context, verificationID string, eventType model.AuditEventType, actor string, details map[string]any) error {
+					events = append(events, eventType)
+					eventActor = actor
+					return nil
+				},
+			}
+			signatureRepo := &mockSignatureRepository{
+				summary: &signing.VerificationSummary{VerificationID: "test-id", Status: string(model.VerificationStatusCompleted)},
+			}
+
+			logger := zaptest.NewLogger(t)
+			service := NewDataRedactionService(repo, NewAuditService(auditRepo, nil, nil, logger),
+				NewSignatureService(signatureRepo, nil, tt.signer, logger), tt.signer, logger)
+
+			var redaction *model.DataRedaction
+			var err error
+			if tt.redact {
+				redaction, err = service.RedactData(tt.ctx, "test-id", model.VerificationDataTypeBasicInformation, tt.path, tt.reason)
+			} else {
+				redaction, err = service.PatchData(tt.ctx, "test-id", model.VerificationDataTypeBasicInformation, tt.path, tt.value, tt.reason)
+			}
+
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing %q, but got %v", tt.expectedError, err)
+				}
+				if len(repo.applied) != 0 {
+					t.Errorf("expected payload unchanged, but got %d changes", len(repo.applied))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(repo.values) != 1 || repo.values[0] != tt.expectedValue {
+				t.Errorf("expected value %s, but got %v", tt.expectedValue, repo.values)
+			}
+			if redaction.Reason != strings.TrimSpace(tt.reason) || redaction.Actor != "admin@example.com" {
+				t.Errorf("expected trimmed reason and admin actor, but got %q by %q", redaction.Reason, redaction.Actor)
+			}
+			signature, err := base64.StdEncoding.DecodeString(redaction.Signature)
+			if err != nil || !signing.Verify(signer.PublicKey(), []byte(redaction.Content), signature) {
+				t.Errorf("expected content signed by the gateway key, but got signature %q", redaction.Signature)
+			}
+			if !strings.Contains(redaction.Content, "data_hash=sha256:"+strings.Repeat("b", 64)) {
+				t.Errorf("expected signed content to include the new payload hash, but got %q", redaction.Content)
+			}
+			if len(events) != 1 || events[0] != tt.expectedEvent || eventActor != "admin@example.com" {
+				t.Errorf("expected %s recorded by admin, but got %v by %q", tt.expectedEvent, events, eventActor)
+			}
+			if len(signatureRepo.saved) != 1 {
+				t.Errorf("expected verification re-signed after the change, but got %d signatures", len(signatureRepo.saved))
+			}
+			if _, err := time.Parse(time.RFC3339, redaction.CreatedAt); err != nil {
+				t.Errorf("expected RFC3339 creation time, but got %q", redaction.CreatedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/report"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/signing"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultReportJobsLimit = 20
+	maxReportJobsLimit     = 100
+
+	// maxReportAttempts сколько раз задание забирается снова после остановки экземпляра или
+	// ошибки загрузки, прежде чем считается неудачным
+	maxReportAttempts = 3
+	// expiredReportsBatch сколько просроченных отчетов удаляется за один запуск
+	expiredReportsBatch = 100
+)
+
+// ReportStore объектное хранилище готовых отчетов
+type ReportStore interface {
+	Key(name string) string
+	Put(ctx context.Context, key, contentType string, body []byte) error
+	Delete(ctx context.Context, key string) error
+	PresignGet(key string, expires time.Duration) (string, error)
+}
+
+// ReportService формирует отчеты по делам в фоне: запрос ставит задание в очередь и сразу
+// возвращается, экземпляры шлюза забирают задания с учетом предела организации, сохраняют
+// отчет в объектное хранилище и отдают ссылку на скачивание до истечения срока хранения
+type ReportService interface {
+	RequestCaseReport(ctx context.Context, caseID string, format model.ReportFormat) (*model.ReportJob, error)
+	GetReportJob(ctx context.Context, id string) (*model.ReportJob, error)
+	ListReportJobs(ctx context.Context, limit *int32) ([]*model.ReportJob, error)
+	// GeneratePending формирует отчеты, пока в очереди есть задания, которые можно забрать,
+	// и возвращает число сформированных
+	GeneratePending(ctx context.Context) (int, error)
+	// ExpireResults удаляет отчеты, срок хранения которых истек
+	ExpireResults(ctx context.Context) (int, error)
+}
+
+type reportService struct {
+	repo   repository.ReportJobRepository
+	cases  repository.CaseRepository
+	store  ReportStore
+	signer *signing.Signer
+	cfg    config.ReportsConfig
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewReportService создает сервис отчетов. store nil - формирование выключено, запросы отклоняются;
+// signer nil - отчеты не подписываются.
+func NewReportService(repo repository.ReportJobRepository, cases repository.CaseRepository, store ReportStore, signer *signing.Signer, cfg config.ReportsConfig, logger *zap.Logger) ReportService {
+	return &reportService{
+		repo:   repo,
+		cases:  cases,
+		store:  store,
+		signer: signer,
+		cfg:    cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func (s *reportService) RequestCaseReport(ctx context.Context, caseID string, format model.ReportFormat) (*model.ReportJob, error) {
+	if caseID == "" {
+		return nil, invalidInputf("case id cannot be empty")
+	}
+	if !format.IsValid() {
+		return nil, invalidInputf("invalid report format: %s", format)
+	}
+	if s.store == nil {
+		return nil, fmt.Errorf("report generation is not enabled")
+	}
+
+	if err := auth.CheckOperation(ctx, auth.OperationRead); err != nil {
+		return nil, err
+	}
+	email, err := auth.RequireEmail(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tenant := messaging.TenantOf(email)
+
+	// Дело другой организации не отличается от отсутствующего
+	if _, err := s.cases.GetByID(ctx, tenant, caseID); err != nil {
+		return nil, err
+	}
+
+	job := &repository.ReportJob{Tenant: tenant, RequestedBy: email, CaseID: caseID, Format: string(format)}
+	if err := s.repo.Create(ctx, job, s.cfg.MaxQueuedPerTenant); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("report requested",
+		zap.String("id", job.ID),
+		zap.String("case_id", caseID),
+		zap.String("format", job.Format),
+		zap.String("tenant", tenant))
+	return s.toModel(job), nil
+}
+
+func (s *reportService) GetReportJob(ctx context.Context, id string) (*model.ReportJob, error) {
+	if id == "" {
+		return nil, invalidInputf("report job id cannot be empty")
+	}
+	if err := auth.CheckOperation(ctx, auth.OperationRead); err != nil {
+		return nil, err
+	}
+
+	job, err := s.repo.GetByID(ctx, requestTenant(ctx), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.toModel(job), nil
+}
+
+func (s *reportService) ListReportJobs(ctx context.Context, limit *int32) ([]*model.ReportJob, error) {
+	n := defaultReportJobsLimit
+	if limit != nil {
+		if *limit < 0 || *limit > maxReportJobsLimit {
+			return nil, invalidInputf("limit must be between 0 and %d, got %d", maxReportJobsLimit, *limit)
+		}
+		n = int(*limit)
+	}
+	if err := auth.CheckOperation(ctx, auth.OperationRead); err != nil {
+		return nil, err
+	}
+
+	jobs, err := s.repo.List(ctx, requestTenant(ctx), n)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.ReportJob, 0, len(jobs))
+	for _, job := range jobs {
+		result = append(result, s.toModel(job))
+	}
+	return result, nil
+}
+
+func (s *reportService) GeneratePending(ctx context.Context) (int, error) {
+	if s.store == nil {
+		return 0, nil
+	}
+
+	var generated atomic.Int64
+	var wg sync.WaitGroup
+	errs := make(chan error, s.cfg.Workers)
+	for range s.cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				job, err := s.repo.Claim(ctx, s.now().Add(s.cfg.ClaimTimeout), s.cfg.MaxRunningPerTenant)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if job == nil {
+					return
+				}
+				if s.generate(ctx, job) {
+					generated.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	return int(generated.Load()), <-errs
+}
+
+// generate формирует отчет задания и сообщает, сохранен ли он. Задание, которое не удалось
+// загрузить, остается захваченным и после истечения захвата повторяется.
+func (s *reportService) generate(ctx context.Context, job *repository.ReportJob) bool {
+	log := s.logger.With(zap.String("report_job_id", job.ID), zap.String("case_id", job.CaseID), zap.String("format", job.Format))
+	start := s.now()
+
+	if job.Attempts > maxReportAttempts {
+		s.fail(ctx, log, job, fmt.Sprintf("report generation was interrupted %d times", job.Attempts-1))
+		return false
+	}
+
+	c, err := s.cases.GetByID(ctx, job.Tenant, job.CaseID)
+	if errors.Is(err, repository.ErrNotFound) {
+		s.fail(ctx, log, job, "case was deleted")
+		return false
+	}
+	if err != nil {
+		log.Error("failed to load case for report", zap.Error(err))
+		return false
+	}
+	c.Risk = caseRisk(c.Parties)
+	if !s.progress(ctx, log, job, 30) {
+		return false
+	}
+
+	content, fileName, contentType, err := renderCaseReport(c, model.ReportFormat(job.Format), s.now().UTC())
+	if err != nil {
+		s.fail(ctx, log, job, err.Error())
+		return false
+	}
+	if !s.progress(ctx, log, job, 70) {
+		return false
+	}
+
+	key := s.store.Key(job.ID + "/" + fileName)
+	if err := s.store.Put(ctx, key, contentType, content); err != nil {
+		log.Error("failed to upload report", zap.Error(err), zap.Int("attempt", job.Attempts))
+		if job.Attempts >= maxReportAttempts {
+			s.fail(ctx, log, job, "failed to upload the report")
+		}
+		return false
+	}
+
+	result := &repository.ReportResult{
+		ObjectKey: key,
+		SizeBytes: int64(len(content)),
+		ExpiresAt: s.now().Add(s.cfg.ResultTTL),
+	}
+	if s.signer != nil {
+		signature, keyID := base64.StdEncoding.EncodeToString(s.signer.Sign(content)), s.signer.KeyID()
+		result.Signature, result.KeyID = &signature, &keyID
+	}
+	if err := s.repo.Complete(ctx, job.ID, result); err != nil {
+		log.Error("failed to complete report job", zap.Error(err))
+		return false
+	}
+
+	metrics.ReportJobsFinished.WithLabelValues(job.Format, repository.ReportJobSucceeded).Inc()
+	metrics.ReportGenerationSeconds.WithLabelValues(job.Format).Observe(s.now().Sub(start).Seconds())
+	log.Info("report generated", zap.Int("size_bytes", len(content)), zap.Int("parties", len(c.Parties)))
+	return true
+}
+
+// progress сохраняет ход формирования и продлевает захват. false - задание забрал другой
+// экземпляр или его не удалось обновить, формирование прекращается.
+func (s *reportService) progress(ctx context.Context, log *zap.Logger, job *repository.ReportJob, progress int) bool {
+	if err := s.repo.Progress(ctx, job.ID, progress, s.now().Add(s.cfg.ClaimTimeout)); err != nil {
+		log.Warn("report generation abandoned", zap.Error(err), zap.Int("progress", progress))
+		return false
+	}
+	return true
+}
+
+func (s *reportService) fail(ctx context.Context, log *zap.Logger, job *repository.ReportJob, message string) {
+	log.Error("report generation failed", zap.String("reason", message))
+	if err := s.repo.Fail(ctx, job.ID, message); err != nil {
+		log.Error("failed to record report failure", zap.Error(err))
+		return
+	}
+	metrics.ReportJobsFinished.WithLabelValues(job.Format, repository.ReportJobFailed).Inc()
+}
+
+func (s *reportService) ExpireResults(ctx context.Context) (int, error) {
+	if s.store == nil {
+		return 0, nil
+	}
+
+	jobs, err := s.repo.ListExpired(ctx, expiredReportsBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, job := range jobs {
+		if job.ObjectKey != nil {
+			if err := s.store.Delete(ctx, *job.ObjectKey); err != nil {
+				s.logger.Error("failed to delete expired report", zap.Error(err), zap.String("report_job_id", job.ID))
+				continue
+			}
+		}
+		if err := s.repo.MarkExpired(ctx, job.ID); err != nil {
+			s.logger.Error("failed to mark report expired", zap.Error(err), zap.String("report_job_id", job.ID))
+			continue
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// renderCaseReport формирует отчет по делу в формате format
+func renderCaseReport(c *model.Case, format model.ReportFormat, generatedAt time.Time) ([]byte, string, string, error) {
+	switch format {
+	case model.ReportFormatPDF:
+		content := report.RenderTextPDF("Case report", caseReportLines(c, generatedAt))
+		return content, fmt.Sprintf("case-%s-report.pdf", c.ID), "application/pdf", nil
+	case model.ReportFormatXlsx:
+		content, err := report.RenderXLSX("Case "+c.Name, caseReportRows(c))
+		return content, fmt.Sprintf("case-%s-report.xlsx", c.ID), "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", err
+	}
+	return nil, "", "", fmt.Errorf("unsupported report format %q", format)
+}
+
+// toModel возвращает задание в представлении GraphQL; у готового отчета - со свежей ссылкой,
+// действующей не дольше срока хранения отчета
+func (s *reportService) toModel(job *repository.ReportJob) *model.ReportJob {
+	result := &model.ReportJob{
+		ID:          job.ID,
+		CaseID:      job.CaseID,
+		Format:      model.ReportFormat(job.Format),
+		Status:      model.ReportJobStatus(job.Status),
+		Progress:    int32(job.Progress),
+		RequestedBy: job.RequestedBy,
+		Error:       job.Error,
+		Signature:   job.Signature,
+		KeyID:       job.KeyID,
+		CreatedAt:   job.CreatedAt.Format(time.RFC3339),
+		StartedAt:   optionalRFC3339(job.StartedAt),
+		FinishedAt:  optionalRFC3339(job.FinishedAt),
+		ExpiresAt:   optionalRFC3339(job.ExpiresAt),
+	}
+	if job.SizeBytes != nil {
+		size := int32(*job.SizeBytes)
+		result.SizeBytes = &size
+	}
+
+	if job.Status != repository.ReportJobSucceeded || job.ObjectKey == nil || job.ExpiresAt == nil || s.store == nil {
+		return result
+	}
+	now := s.now()
+	ttl := min(s.cfg.LinkTTL, job.ExpiresAt.Sub(now))
+	if ttl < time.Second {
+		return result
+	}
+	link, err := s.store.PresignGet(*job.ObjectKey, ttl)
+	if err != nil {
+		s.logger.Error("failed to sign report link", zap.Error(err), zap.String("report_job_id", job.ID))
+		return result
+	}
+	linkExpiresAt := now.Add(ttl).UTC().Format(time.RFC3339)
+	result.DownloadURL, result.DownloadURLExpiresAt = &link, &linkExpiresAt
+	return result
+}
+
+func optionalRFC3339(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
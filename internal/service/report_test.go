@@ -0,0 +1,269 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// memoryReportJobRepository хранит задания в памяти; Claim не учитывает предел организации
+type memoryReportJobRepository struct {
+	mu   sync.Mutex
+	jobs []*repository.ReportJob
+}
+
+func (r *memoryReportJobRepository) Create(ctx context.Context, job *repository.ReportJob, maxQueued int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job.ID, job.Status, job.CreatedAt = fmt.Sprintf("job-%d", len(r.jobs)+1), repository.ReportJobQueued, time.Now()
+	r.jobs = append(r.jobs, job)
+	return nil
+}
+
+func (r *memoryReportJobRepository) GetByID(ctx context.Context, tenant, id string) (*repository.ReportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, job := range r.jobs {
+		if job.ID == id && job.Tenant == tenant {
+			copied := *job
+			return &copied, nil
+		}
+	}
+	return nil, errNotFound("report job not found: %s", id)
+}
+
+func (r *memoryReportJobRepository) List(ctx context.Context, tenant string, limit int) ([]*repository.ReportJob, error) {
+	return nil, nil
+}
+
+func (r *memoryReportJobRepository) Claim(ctx context.Context, claimUntil time.Time, maxRunningPerTenant int) (*repository.ReportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, job := range r.jobs {
+		if job.Status == repository.ReportJobQueued {
+			job.Status = repository.ReportJobRunning
+			job.Attempts++
+			copied := *job
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryReportJobRepository) update(id string, change func(job *repository.ReportJob)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, job := range r.jobs {
+		if job.ID == id {
+			change(job)
+			return nil
+		}
+	}
+	return errNotFound("report job not found: %s", id)
+}
+
+func (r *memoryReportJobRepository) Progress(ctx context.Context, id string, progress int, claimUntil time.Time) error {
+	return r.update(id, func(job *repository.ReportJob) { job.Progress = progress })
+}
+
+func (r *memoryReportJobRepository) Complete(ctx context.Context, id string, result *repository.ReportResult) error {
+	return r.update(id, func(job *repository.ReportJob) {
+		job.Status, job.Progress = repository.ReportJobSucceeded, 100
+		job.ObjectKey, job.SizeBytes, job.ExpiresAt = &result.ObjectKey, &result.SizeBytes, &result.ExpiresAt
+		job.Signature, job.KeyID = result.Signature, result.KeyID
+	})
+}
+
+func (r *memoryReportJobRepository) Fail(ctx context.Context, id, message string) error {
+	return r.update(id, func(job *repository.ReportJob) { job.Status, job.Error = repository.ReportJobFailed, &message })
+}
+
+func (r *memoryReportJobRepository) ListExpired(ctx context.Context, limit int) ([]*repository.ReportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var expired []*repository.ReportJob
+	for _, job := range r.jobs {
+		if job.Status == repository.ReportJobSucceeded && job.ExpiresAt.Before(time.Now()) {
+			expired = append(expired, job)
+		}
+	}
+	return expired, nil
+}
+
+func (r *memoryReportJobRepository) MarkExpired(ctx context.Context, id string) error {
+	return r.update(id, func(job *repository.ReportJob) { job.Status = repository.ReportJobExpired })
+}
+
+// memoryReportStore объектное хранилище в памяти
+type memoryReportStore struct {
+	mu        sync.Mutex
+	objects   map[string][]byte
+	putErr    error
+	presigned time.Duration
+}
+
+func (s *memoryReportStore) Key(name string) string {
+	return "reports/" + name
+}
+
+func (s *memoryReportStore) Put(ctx context.Context, key, contentType string, body []byte) error {
+	if s.putErr != nil {
+		return s.putErr
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = body
+	return nil
+}
+
+func (s *memoryReportStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memoryReportStore) PresignGet(key string, expires time.Duration) (string, error) {
+	s.presigned = expires
+	return "https://s3.example.com/bucket/" + key + "?X-Amz-Signature=sig", nil
+}
+
+var testReportsConfig = config.ReportsConfig{
+	Workers:             2,
+	MaxRunningPerTenant: 1,
+	MaxQueuedPerTenant:  10,
+	ClaimTimeout:        time.Minute,
+	ResultTTL:           time.Hour,
+	LinkTTL:             15 * time.Minute,
+}
+
+func newTestReportService(t *testing.T, store *memoryReportStore) (*reportService, *memoryReportJobRepository, context.Context) {
+	cases := newMemoryCaseRepository(&model.Verification{ID: "v-1", Inn: "7707083893", Status: model.VerificationStatusCompleted,
+		RiskLevel: riskLevel(model.RiskLevelHigh), RequestedDataTypes: []model.VerificationDataType{model.VerificationDataTypeBasicInformation}})
+	if err := cases.Create(context.Background(), "bank.ru", &model.Case{Name: "Holding"}, []repository.CaseParty{
+		{VerificationID: "v-1", Role: model.CasePartyRoleSubject},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	jobs := &memoryReportJobRepository{}
+	var reportStore ReportStore
+	if store != nil {
+		reportStore = store
+	}
+	service := NewReportService(jobs, cases, reportStore, nil, testReportsConfig, zaptest.NewLogger(t)).(*reportService)
+	return service, jobs, auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@bank.ru"})
+}
+
+func TestRequestCaseReport(t *testing.T) {
+	service, _, ctx := newTestReportService(t, &memoryReportStore{objects: map[string][]byte{}})
+
+	job, err := service.RequestCaseReport(ctx, "case-1", model.ReportFormatPDF)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Status != model.ReportJobStatusQueued || job.RequestedBy != "analyst@bank.ru" || job.DownloadURL != nil {
+		t.Errorf("unexpected report job %+v", job)
+	}
+
+	other := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@leasing.ru"})
+	if _, err := service.RequestCaseReport(other, "case-1", model.ReportFormatPDF); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected a case of another organization to be not found, but got %v", err)
+	}
+	if _, err := service.RequestCaseReport(ctx, "case-1", model.ReportFormat("DOCX")); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+
+	disabled, _, ctx := newTestReportService(t, nil)
+	if _, err := disabled.RequestCaseReport(ctx, "case-1", model.ReportFormatPDF); err == nil || !strings.Contains(err.Error(), "not enabled") {
+		t.Errorf("expected reports to be disabled without storage, but got %v", err)
+	}
+}
+
+func TestGenerateReports(t *testing.T) {
+	store := &memoryReportStore{objects: map[string][]byte{}}
+	service, _, ctx := newTestReportService(t, store)
+
+	pdf, _ := service.RequestCaseReport(ctx, "case-1", model.ReportFormatPDF)
+	xlsx, _ := service.RequestCaseReport(ctx, "case-1", model.ReportFormatXlsx)
+
+	generated, err := service.GeneratePending(context.Background())
+	if err != nil || generated != 2 {
+		t.Fatalf("expected 2 generated reports, but got %d and error %v", generated, err)
+	}
+	if !bytes.HasPrefix(store.objects["reports/"+pdf.ID+"/case-case-1-report.pdf"], []byte("%PDF-")) {
+		t.Error("expected the PDF report to be stored")
+	}
+	if !bytes.HasPrefix(store.objects["reports/"+xlsx.ID+"/case-case-1-report.xlsx"], []byte("PK")) {
+		t.Error("expected the XLSX report to be stored")
+	}
+
+	job, err := service.GetReportJob(ctx, pdf.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Status != model.ReportJobStatusSucceeded || job.Progress != 100 || job.DownloadURL == nil || job.DownloadURLExpiresAt == nil {
+		t.Fatalf("expected a succeeded report with a download link, but got %+v", job)
+	}
+	if store.presigned != testReportsConfig.LinkTTL {
+		t.Errorf("expected the link to expire in %s, but got %s", testReportsConfig.LinkTTL, store.presigned)
+	}
+}
+
+func TestGenerateReportUploadFailure(t *testing.T) {
+	store := &memoryReportStore{objects: map[string][]byte{}, putErr: errors.New("s3 returned 503")}
+	service, jobs, ctx := newTestReportService(t, store)
+
+	requested, _ := service.RequestCaseReport(ctx, "case-1", model.ReportFormatPDF)
+	if generated, err := service.GeneratePending(context.Background()); err != nil || generated != 0 {
+		t.Fatalf("expected no generated reports, but got %d and error %v", generated, err)
+	}
+	if job, _ := jobs.GetByID(ctx, "bank.ru", requested.ID); job.Status != repository.ReportJobRunning {
+		t.Errorf("expected the job to stay claimed for a retry, but got %s", job.Status)
+	}
+
+	// Последняя попытка завершает задание ошибкой
+	jobs.update(requested.ID, func(job *repository.ReportJob) {
+		job.Status, job.Attempts = repository.ReportJobQueued, maxReportAttempts-1
+	})
+	service.GeneratePending(context.Background())
+	if job, _ := jobs.GetByID(ctx, "bank.ru", requested.ID); job.Status != repository.ReportJobFailed || job.Error == nil {
+		t.Errorf("expected the job to fail after %d attempts, but got %+v", maxReportAttempts, job)
+	}
+}
+
+func TestExpireReports(t *testing.T) {
+	store := &memoryReportStore{objects: map[string][]byte{}}
+	service, jobs, ctx := newTestReportService(t, store)
+
+	requested, _ := service.RequestCaseReport(ctx, "case-1", model.ReportFormatPDF)
+	service.GeneratePending(context.Background())
+	jobs.update(requested.ID, func(job *repository.ReportJob) {
+		expired := time.Now().Add(-time.Minute)
+		job.ExpiresAt = &expired
+	})
+
+	expired, err := service.ExpireResults(context.Background())
+	if err != nil || expired != 1 {
+		t.Fatalf("expected 1 expired report, but got %d and error %v", expired, err)
+	}
+	if len(store.objects) != 0 {
+		t.Errorf("expected the report to be deleted, but got %d objects", len(store.objects))
+	}
+	job, _ := service.GetReportJob(ctx, requested.ID)
+	if job.Status != model.ReportJobStatusExpired || job.DownloadURL != nil {
+		t.Errorf("expected an expired report without a link, but got %+v", job)
+	}
+}
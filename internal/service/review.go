@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// ReviewService ручное ревью проверок после скоринга: назначение аналитика, решение и очередь аналитика
+type ReviewService interface {
+	Assign(ctx context.Context, id string, assignee string) (*model.Verification, error)
+	Claim(ctx context.Context, id string) (*model.Verification, error)
+	Review(ctx context.Context, id string, decision model.ReviewState, comment *string) (*model.Verification, error)
+	GetReviewQueue(ctx context.Context, state *model.ReviewState, limit *int32, offset *int32) ([]*model.Verification, error)
+}
+
+type reviewService struct {
+	repo          repository.ReviewRepository
+	verifications repository.VerificationRepository
+	users         repository.UserRepository
+	audit         AuditService
+	notifications NotificationService
+	logger        *zap.Logger
+}
+
+func NewReviewService(repo repository.ReviewRepository, verifications repository.VerificationRepository, users repository.UserRepository, audit AuditService, notifications NotificationService, logger *zap.Logger) ReviewService {
+	return &reviewService{
+		repo:          repo,
+		verifications: verifications,
+		users:         users,
+		audit:         audit,
+		notifications: notifications,
+		logger:        logger,
+	}
+}
+
+// Assign назначает проверку аналитику. Администратор организации назначает только участников своей организации.
+func (s *reviewService) Assign(ctx context.Context, id string, assignee string) (*model.Verification, error) {
+	if id == "" {
+		return nil, invalidInputf("verification id cannot be empty")
+	}
+	if assignee == "" {
+		return nil, invalidInputf("assignee cannot be empty")
+	}
+
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || principal.Email == "" {
+		return nil, auth.ErrUnauthenticated
+	}
+	if !principal.HasRole(auth.RoleAdmin) {
+		if !principal.HasRole(auth.RoleOrgAdmin) {
+			return nil, fmt.Errorf("%w: role %s or %s required", auth.ErrForbidden, auth.RoleAdmin, auth.RoleOrgAdmin)
+		}
+		if err := s.checkSameOrganization(ctx, principal, assignee); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Assign(ctx, id, assignee, false); err != nil {
+		return nil, err
+	}
+	return s.assigned(ctx, id, principal.Email)
+}
+
+// Claim назначает свободную проверку на пользователя запроса
+func (s *reviewService) Claim(ctx context.Context, id string) (*model.Verification, error) {
+	if id == "" {
+		return nil, invalidInputf("verification id cannot be empty")
+	}
+
+	email, err := requireReviewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Assign(ctx, id, email, true); err != nil {
+		return nil, err
+	}
+	return s.assigned(ctx, id, email)
+}
+
+// Review записывает решение аналитика, которому назначена проверка
+func (s *reviewService) Review(ctx context.Context, id string, decision model.ReviewState, comment *string) (*model.Verification, error) {
+	if id == "" {
+		return nil, invalidInputf("verification id cannot be empty")
+	}
+	if decision != model.ReviewStateApproved && decision != model.ReviewStateRejected {
+		return nil, invalidInputf("invalid review decision: %s, expected %s or %s", decision, model.ReviewStateApproved, model.ReviewStateRejected)
+	}
+
+	email, err := requireReviewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Decide(ctx, id, email, decision, comment); err != nil {
+		return nil, err
+	}
+
+	verification, err := s.getVerification(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]any{"decision": decision}
+	if comment != nil {
+		details["comment"] = *comment
+	}
+	s.recordEvent(ctx, id, model.AuditEventTypeReviewCompleted, email, details)
+	if err := s.notifications.NotifyReviewCompleted(ctx, verification); err != nil {
+		s.logger.Warn("failed to notify about review decision", zap.Error(err), zap.String("verification_id", id))
+	}
+
+	return verification, nil
+}
+
+// GetReviewQueue возвращает проверки, назначенные пользователю запроса, начиная с самых старых
+func (s *reviewService) GetReviewQueue(ctx context.Context, state *model.ReviewState, limit *int32, offset *int32) ([]*model.Verification, error) {
+	if limit != nil && *limit < 0 {
+		return nil, invalidInputf("limit must be non-negative, got %d", *limit)
+	}
+	if offset != nil && *offset < 0 {
+		return nil, invalidInputf("offset must be non-negative, got %d", *offset)
+	}
+
+	email, err := auth.RequireEmail(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewState := model.ReviewStateInReview
+	if state != nil {
+		if !state.IsValid() {
+			return nil, invalidInputf("invalid review state: %s", *state)
+		}
+		reviewState = *state
+	}
+
+	verifications, err := s.verifications.GetAll(ctx, repository.VerificationFilter{
+		Assignee:    &email,
+		ReviewState: &reviewState,
+		OldestFirst: true,
+	}, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if verifications == nil {
+		verifications = []*model.Verification{}
+	}
+	return restrictAll(ctx, verifications), nil
+}
+
+// assigned записывает событие назначения и уведомляет аналитика
+func (s *reviewService) assigned(ctx context.Context, id string, actor string) (*model.Verification, error) {
+	verification, err := s.getVerification(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, id, model.AuditEventTypeReviewAssigned, actor, map[string]any{"assignee": verification.Assignee})
+	// Аналитику, взявшему проверку самостоятельно, уведомление не нужно
+	if verification.Assignee != nil && *verification.Assignee != actor {
+		if err := s.notifications.NotifyReviewAssigned(ctx, verification); err != nil {
+			s.logger.Warn("failed to notify assignee", zap.Error(err), zap.String("verification_id", id))
+		}
+	}
+
+	return verification, nil
+}
+
+func (s *reviewService) getVerification(ctx context.Context, id string) (*model.Verification, error) {
+	verification, err := s.verifications.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verification: %w", err)
+	}
+	return restrictData(ctx, verification), nil
+}
+
+func (s *reviewService) recordEvent(ctx context.Context, id string, eventType model.AuditEventType, actor string, details map[string]any) {
+	if err := s.audit.RecordEvent(ctx, id, eventType, actor, details); err != nil {
+		s.logger.Warn("failed to record review event", zap.Error(err), zap.String("verification_id", id), zap.String("event_type", string(eventType)))
+	}
+}
+
+func (s *reviewService) checkSameOrganization(ctx context.Context, principal *auth.Principal, assignee string) error {
+	membership, err := s.users.GetMembership(ctx, assignee)
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("%w: %s is not a member of organization %s", auth.ErrForbidden, assignee, principal.Organization)
+	}
+	if err != nil {
+		return err
+	}
+	if membership.OrganizationID != principal.Organization || membership.Status == model.UserStatusDeactivated {
+		return fmt.Errorf("%w: %s is not a member of organization %s", auth.ErrForbidden, assignee, principal.Organization)
+	}
+	return nil
+}
+
+// requireReviewer возвращает email пользователя, который может брать проверки на ревью.
+// Наблюдатели и сервисные аккаунты ревью не выполняют.
+func requireReviewer(ctx context.Context) (string, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || principal.Email == "" {
+		return "", auth.ErrUnauthenticated
+	}
+	if principal.Scope != nil {
+		return "", fmt.Errorf("%w: api keys cannot review verifications", auth.ErrForbidden)
+	}
+	if err := auth.CheckOperation(ctx, auth.OperationCreate); err != nil {
+		return "", err
+	}
+	return principal.Email, nil
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/pubsub"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для ReviewRepository, изменяющий проверку в памяти
+type mockReviewRepository struct {
+	verification *model.Verification
+	assignErr    error
+}
+
+func (m *mockReviewRepository) Assign(ctx context.Context, id string, assignee string, onlyUnassigned bool) error {
+	if m.assignErr != nil {
+		return m.assignErr
+	}
+	m.verification.Assignee = &assignee
+	m.verification.ReviewState = model.ReviewStateInReview
+	return nil
+}
+
+func (m *mockReviewRepository) Decide(ctx context.Context, id string, reviewer string, decision model.ReviewState, comment *string) error {
+	m.verification.ReviewState = decision
+	m.verification.ReviewComment = comment
+	return nil
+}
+
+type reviewFixture struct {
+	service       ReviewService
+	repo          *mockReviewRepository
+	notifications *mockNotificationRepository
+	events        []model.AuditEventType
+}
+
+func newReviewFixture(t *testing.T, users *mockUserRepository) *reviewFixture {
+	logger := zaptest.NewLogger(t)
+	fixture := &reviewFixture{
+		repo: &mockReviewRepository{verification: &model.Verification{
+			ID: "v-1", Inn: "1234567890", Status: model.VerificationStatusCompleted,
+			AuthorEmail: "author@example.com", ReviewState: model.ReviewStateUnreviewed,
+		}},
+		notifications: &mockNotificationRepository{},
+	}
+
+	verificationRepo := &mockVerificationRepository{
+		getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+			verification := *fixture.repo.verification
+			return &verification, nil
+		},
+	}
+	auditRepo := &mockAuditRepository{
+		addEventFunc: func(ctx context.Context, verificationID string, eventType model.AuditEventType, actor string, details map[string]any) error {
+			fixture.events = append(fixture.events, eventType)
+			return nil
+		},
+	}
+	audit := NewAuditService(auditRepo, NewVerificationService(verificationRepo, &mockNATSClient{}, nil, logger), nil, logger)
+	notifications := NewNotificationService(fixture.notifications, verificationRepo, audit, pubsub.NewLocalRelay(), logger)
+
+	fixture.service = NewReviewService(fixture.repo, verificationRepo, users, audit, notifications, logger)
+	return fixture
+}
+
+func TestAssignVerification(t *testing.T) {
+	orgAdmin := &auth.Principal{Email: "boss@example.com", Roles: []string{auth.RoleOrgAdmin}, Organization: "example.com"}
+
+	tests := []struct {
+		name          string
+		principal     *auth.Principal
+		membership    *repository.Membership
+		assignErr     error
+		expectedError string
+	}{
+		{name: "gateway_admin", principal: &auth.Principal{Email: "admin@scoring.local", Roles: []string{auth.RoleAdmin}}},
+		{
+			name:       "org_admin",
+			principal:  orgAdmin,
+			membership: &repository.Membership{Status: model.UserStatusActive, OrganizationID: "example.com"},
+		},
+		{
+			name:          "assignee_in_other_organization",
+			principal:     orgAdmin,
+			membership:    &repository.Membership{Status: model.UserStatusActive, OrganizationID: "other.org"},
+			expectedError: "access denied",
+		},
+		{name: "assignee_without_account", principal: orgAdmin, expectedError: "access denied"},
+		{name: "analyst", principal: &auth.Principal{Email: "analyst@example.com", Roles: []string{auth.RoleAnalyst}}, expectedError: "access denied"},
+		{
+			name:          "already_reviewed",
+			principal:     &auth.Principal{Email: "admin@scoring.local", Roles: []string{auth.RoleAdmin}},
+			assignErr:     &repository.Error{Kind: repository.ErrConflict, Err: errors.New("verification v-1 is already reviewed: APPROVED")},
+			expectedError: "verification v-1 is already reviewed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newReviewFixture(t, &mockUserRepository{membership: tt.membership})
+			fixture.repo.assignErr = tt.assignErr
+
+			verification, err := fixture.service.Assign(auth.WithPrincipal(context.Background(), tt.principal), "v-1", "analyst@example.com")
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error %q, but got %v", tt.expectedError, err)
+				}
+				if len(fixture.events) != 0 {
+					t.Errorf("expected no audit events, but got %v", fixture.events)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if verification.ReviewState != model.ReviewStateInReview || *verification.Assignee != "analyst@example.com" {
+				t.Errorf("unexpected verification: %+v", verification)
+			}
+			if len(fixture.events) == 0 || fixture.events[0] != model.AuditEventTypeReviewAssigned {
+				t.Errorf("expected REVIEW_ASSIGNED event, but got %v", fixture.events)
+			}
+			if len(fixture.notifications.recipients) != 1 || fixture.notifications.recipients[0] != "analyst@example.com" {
+				t.Errorf("expected assignee to be notified, but got %v", fixture.notifications.recipients)
+			}
+		})
+	}
+}
+
+func TestClaimVerificationDoesNotNotifyClaimant(t *testing.T) {
+	fixture := newReviewFixture(t, &mockUserRepository{})
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@example.com", Roles: []string{auth.RoleAnalyst}})
+
+	verification, err := fixture.service.Claim(ctx, "v-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *verification.Assignee != "analyst@example.com" {
+		t.Errorf("expected verification to be assigned to the claimant, but got %v", verification.Assignee)
+	}
+	if len(fixture.notifications.created) != 0 {
+		t.Errorf("expected no notifications, but got %v", fixture.notifications.created)
+	}
+}
+
+func TestReviewVerification(t *testing.T) {
+	tests := []struct {
+		name          string
+		principal     *auth.Principal
+		decision      model.ReviewState
+		expectedError string
+	}{
+		{name: "approved", principal: &auth.Principal{Email: "analyst@example.com", Roles: []string{auth.RoleAnalyst}}, decision: model.ReviewStateApproved},
+		{name: "not_a_decision", principal: &auth.Principal{Email: "analyst@example.com", Roles: []string{auth.RoleAnalyst}}, decision: model.ReviewStateInReview, expectedError: "invalid review decision"},
+		{name: "viewer", principal: &auth.Principal{Email: "viewer@example.com", Roles: []string{auth.RoleViewer}}, decision: model.ReviewStateRejected, expectedError: "access denied"},
+		{name: "api_key", principal: &auth.Principal{Email: "partner@example.com", Scope: &auth.Scope{}}, decision: model.ReviewStateRejected, expectedError: "access denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newReviewFixture(t, &mockUserRepository{})
+			comment := "looks fine"
+
+			verification, err := fixture.service.Review(auth.WithPrincipal(context.Background(), tt.principal), "v-1", tt.decision, &comment)
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error %q, but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if verification.ReviewState != tt.decision || *verification.ReviewComment != comment {
+				t.Errorf("unexpected verification: %+v", verification)
+			}
+			if len(fixture.events) == 0 || fixture.events[0] != model.AuditEventTypeReviewCompleted {
+				t.Errorf("expected REVIEW_COMPLETED event, but got %v", fixture.events)
+			}
+			if len(fixture.notifications.created) != 1 || fixture.notifications.created[0].Kind != model.NotificationKindReviewCompleted || fixture.notifications.recipients[0] != "author@example.com" {
+				t.Errorf("expected author to be notified, but got %v", fixture.notifications.recipients)
+			}
+		})
+	}
+}
+
+func TestGetReviewQueue(t *testing.T) {
+	var filter repository.VerificationFilter
+	verificationRepo := &mockVerificationRepository{
+		getAllFunc: func(ctx context.Context, f repository.VerificationFilter, limit *int32, offset *int32) ([]*model.Verification, error) {
+			filter = f
+			return nil, nil
+		},
+	}
+	logger := zaptest.NewLogger(t)
+	service := NewReviewService(&mockReviewRepository{}, verificationRepo, &mockUserRepository{}, nil, nil, logger)
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@example.com", Roles: []string{auth.RoleAnalyst}})
+	queue, err := service.GetReviewQueue(ctx, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if queue == nil {
+		t.Error("expected empty queue, but got nil")
+	}
+	if *filter.Assignee != "analyst@example.com" || *filter.ReviewState != model.ReviewStateInReview || !filter.OldestFirst {
+		t.Errorf("unexpected filter: %+v", filter)
+	}
+
+	if _, err := service.GetReviewQueue(context.Background(), nil, nil, nil); err == nil {
+		t.Error("expected anonymous request to be rejected")
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// ScoringService хранит версионированные оценки проверок и пересчитывает их при смене правил скоринга.
+// Оценку считает скоринговый движок: шлюз отправляет ему запросы на пересчет по уже собранным данным
+// и сохраняет полученные оценки вместе с версией правил.
+type ScoringService interface {
+	RecordScore(ctx context.Context, score *repository.Score) error
+	RecalculateScores(ctx context.Context, filter *model.VerificationFilter) (*model.ScoreRecalculation, error)
+	GetRecalculation(ctx context.Context, id string) (*model.ScoreRecalculation, error)
+	GetScoreHistory(ctx context.Context, verificationID string) ([]*model.VerificationScore, error)
+	// PublishRecalculations отправляет движку следующую пачку проверок каждого незавершенного пересчета
+	PublishRecalculations(ctx context.Context, batchSize int) (int, error)
+}
+
+type scoringService struct {
+	repo   repository.ScoreRepository
+	nats   messaging.NATSClient
+	logger *zap.Logger
+}
+
+func NewScoringService(repo repository.ScoreRepository, nats messaging.NATSClient, logger *zap.Logger) ScoringService {
+	return &scoringService{
+		repo:   repo,
+		nats:   nats,
+		logger: logger,
+	}
+}
+
+// RecordScore сохраняет оценку, оставляя предыдущую в истории
+func (s *scoringService) RecordScore(ctx context.Context, score *repository.Score) error {
+	if score.VerificationID == "" {
+		return invalidInputf("verification id cannot be empty")
+	}
+	if !score.RiskLevel.IsValid() {
+		return invalidInputf("invalid risk level: %s", score.RiskLevel)
+	}
+
+	return s.repo.RecordScore(ctx, score)
+}
+
+// RecalculateScores создает пересчет оценок завершенных проверок, подходящих под фильтр.
+// Запросы движку отправляет задача score_recalculation.
+func (s *scoringService) RecalculateScores(ctx context.Context, filter *model.VerificationFilter) (*model.ScoreRecalculation, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	repoFilter, err := toRepositoryFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	principal, _ := auth.PrincipalFromContext(ctx)
+	recalculation, err := s.repo.CreateRecalculation(ctx, repoFilter, principal.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("score recalculation requested",
+		zap.String("recalculation_id", recalculation.ID),
+		zap.String("requested_by", principal.Email),
+		zap.Int32("total", recalculation.Total))
+	return recalculation, nil
+}
+
+func (s *scoringService) GetRecalculation(ctx context.Context, id string) (*model.ScoreRecalculation, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	recalculation, err := s.repo.GetRecalculation(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	return recalculation, err
+}
+
+func (s *scoringService) GetScoreHistory(ctx context.Context, verificationID string) ([]*model.VerificationScore, error) {
+	if verificationID == "" {
+		return nil, invalidInputf("verification id cannot be empty")
+	}
+	if err := auth.CheckOperation(ctx, auth.OperationRead); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetHistory(ctx, verificationID)
+}
+
+func (s *scoringService) PublishRecalculations(ctx context.Context, batchSize int) (int, error) {
+	recalculations, err := s.repo.GetActiveRecalculations(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, recalculation := range recalculations {
+		published, err := s.publishBatch(ctx, recalculation, batchSize)
+		total += published
+		if err != nil {
+			return total, err
+		}
+	}
+
+	if total > 0 {
+		s.logger.Info("rescore requests published", zap.Int("count", total))
+	}
+	return total, nil
+}
+
+// publishBatch отправляет следующую пачку проверок пересчета и сдвигает его позицию.
+// При ошибке публикации позиция сдвигается до последней отправленной проверки.
+func (s *scoringService) publishBatch(ctx context.Context, recalculation *repository.ScoreRecalculationState, batchSize int) (int, error) {
+	candidates, err := s.repo.GetRescoreCandidates(ctx, recalculation.Filter, recalculation.Cursor, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var last *repository.RescoreCandidate
+	var publishErr error
+	published := 0
+	for _, candidate := range candidates {
+		publishErr = s.nats.PublishRescoreRequest(ctx, &messaging.RescoreVerificationMessage{
+			VerificationID:  candidate.ID,
+			INN:             candidate.INN,
+			RecalculationID: recalculation.ID,
+		})
+		if publishErr != nil {
+			break
+		}
+		last = candidate
+		published++
+	}
+
+	done := publishErr == nil && len(candidates) < batchSize
+	if err := s.repo.AdvanceRecalculation(ctx, recalculation.ID, last, published, done); err != nil {
+		return published, err
+	}
+	if publishErr != nil {
+		return published, publishErr
+	}
+
+	if done {
+		s.logger.Info("score recalculation published", zap.String("recalculation_id", recalculation.ID))
+	}
+	return published, nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для ScoreRepository
+type mockScoreRepository struct {
+	repository.ScoreRepository
+	recorded       []*repository.Score
+	createdFilter  *repository.VerificationFilter
+	active         []*repository.ScoreRecalculationState
+	candidates     []*repository.RescoreCandidate
+	candidatesFrom *repository.RescoreCandidate
+	advanced       map[string]advanceCall
+}
+
+type advanceCall struct {
+	last      *repository.RescoreCandidate
+	published int
+	done      bool
+}
+
+func (m *mockScoreRepository) RecordScore(ctx context.Context, score *repository.Score) error {
+	m.recorded = append(m.recorded, score)
+	return nil
+}
+
+func (m *mockScoreRepository) CreateRecalculation(ctx context.Context, filter repository.VerificationFilter, requestedBy string) (*model.ScoreRecalculation, error) {
+	m.createdFilter = &filter
+	return &model.ScoreRecalculation{ID: "r-1", Status: model.ScoreRecalculationStatusPending, RequestedBy: requestedBy, Total: 3}, nil
+}
+
+func (m *mockScoreRepository) GetActiveRecalculations(ctx context.Context) ([]*repository.ScoreRecalculationState, error) {
+	return m.active, nil
+}
+
+func (m *mockScoreRepository) GetRescoreCandidates(ctx context.Context, filter repository.VerificationFilter, after *repository.RescoreCandidate, limit int) ([]*repository.RescoreCandidate, error) {
+	m.candidatesFrom = after
+	if len(m.candidates) > limit {
+		return m.candidates[:limit], nil
+	}
+	return m.candidates, nil
+}
+
+func (m *mockScoreRepository) AdvanceRecalculation(ctx context.Context, id string, last *repository.RescoreCandidate, published int, done bool) error {
+	if m.advanced == nil {
+		m.advanced = make(map[string]advanceCall)
+	}
+	m.advanced[id] = advanceCall{last: last, published: published, done: done}
+	return nil
+}
+
+func TestRecordScore(t *testing.T) {
+	repo := &mockScoreRepository{}
+	service := NewScoringService(repo, &mockNATSClient{}, zaptest.NewLogger(t))
+
+	if err := service.RecordScore(context.Background(), &repository.Score{VerificationID: "v-1", RiskLevel: "EXTREME"}); err == nil || err.Error() != "invalid risk level: EXTREME" {
+		t.Errorf("expected invalid risk level error, but got %v", err)
+	}
+	if err := service.RecordScore(context.Background(), &repository.Score{RiskLevel: model.RiskLevelLow}); err == nil {
+		t.Error("expected error for empty verification id")
+	}
+	if err := service.RecordScore(context.Background(), &repository.Score{VerificationID: "v-1", RiskLevel: model.RiskLevelHigh}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.recorded) != 1 {
+		t.Errorf("expected one score to be recorded, but got %d", len(repo.recorded))
+	}
+}
+
+func TestRecalculateScores(t *testing.T) {
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "admin@example.com", Roles: []string{auth.RoleAdmin}})
+	analyst := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@example.com"})
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		filter        *model.VerificationFilter
+		expectedError string
+	}{
+		{name: "admin", ctx: admin, filter: &model.VerificationFilter{Inn: stringPtr("7707083893"), CreatedFrom: stringPtr("2024-01-01")}},
+		{name: "without_filter", ctx: admin},
+		{name: "not_admin", ctx: analyst, expectedError: "access denied"},
+		{name: "invalid_filter", ctx: admin, filter: &model.VerificationFilter{CreatedFrom: stringPtr("yesterday")}, expectedError: "createdFrom must be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockScoreRepository{}
+			service := NewScoringService(repo, &mockNATSClient{}, zaptest.NewLogger(t))
+
+			recalculation, err := service.RecalculateScores(tt.ctx, tt.filter)
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error %q, but got %v", tt.expectedError, err)
+				}
+				if repo.createdFilter != nil {
+					t.Error("expected no recalculation to be created")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if recalculation.RequestedBy != "admin@example.com" {
+				t.Errorf("expected requestedBy admin@example.com, but got %s", recalculation.RequestedBy)
+			}
+			if tt.filter != nil && (repo.createdFilter.INN == nil || repo.createdFilter.CreatedFromDay == nil) {
+				t.Errorf("expected filter to be parsed, but got %+v", repo.createdFilter)
+			}
+		})
+	}
+}
+
+func TestPublishRecalculations(t *testing.T) {
+	cursor := &repository.RescoreCandidate{ID: "v-0"}
+	candidates := []*repository.RescoreCandidate{{ID: "v-1", INN: "7707083893"}, {ID: "v-2", INN: "7736050003"}, {ID: "v-3", INN: "7702070139"}}
+
+	tests := []struct {
+		name              string
+		batchSize         int
+		failOn            string
+		expectedPublished int
+		expectedLast      string
+		expectedDone      bool
+		expectError       bool
+	}{
+		{name: "last_batch", batchSize: 5, expectedPublished: 3, expectedLast: "v-3", expectedDone: true},
+		{name: "more_to_publish", batchSize: 2, expectedPublished: 2, expectedLast: "v-2"},
+		{name: "publish_failure_keeps_position", batchSize: 5, failOn: "v-2", expectedPublished: 1, expectedLast: "v-1", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockScoreRepository{
+				active:     []*repository.ScoreRecalculationState{{ID: "r-1", Cursor: cursor}},
+				candidates: candidates,
+			}
+			var requests []*messaging.RescoreVerificationMessage
+			nats := &mockNATSClient{
+				publishRescoreFunc: func(ctx context.Context, request *messaging.RescoreVerificationMessage) error {
+					if request.VerificationID == tt.failOn {
+						return errors.New("nats unavailable")
+					}
+					requests = append(requests, request)
+					return nil
+				},
+			}
+			service := NewScoringService(repo, nats, zaptest.NewLogger(t))
+
+			published, err := service.PublishRecalculations(context.Background(), tt.batchSize)
+			if tt.expectError != (err != nil) {
+				t.Fatalf("expected error %v, but got %v", tt.expectError, err)
+			}
+			if published != tt.expectedPublished || len(requests) != tt.expectedPublished {
+				t.Errorf("expected %d published, but got %d (%d requests)", tt.expectedPublished, published, len(requests))
+			}
+			if repo.candidatesFrom != cursor {
+				t.Errorf("expected candidates after the saved cursor, but got %v", repo.candidatesFrom)
+			}
+			for _, request := range requests {
+				if request.RecalculationID != "r-1" || request.INN == "" {
+					t.Errorf("unexpected rescore request: %+v", request)
+				}
+			}
+
+			call := repo.advanced["r-1"]
+			if call.last == nil || call.last.ID != tt.expectedLast || call.published != tt.expectedPublished || call.done != tt.expectedDone {
+				t.Errorf("unexpected advance: %+v", call)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/signing"
+
+	"go.uber.org/zap"
+)
+
+type SignatureService interface {
+	SignVerification(ctx context.Context, verificationID string) (*model.VerificationSignature, error)
+	GetSignature(ctx context.Context, verificationID string) (*model.VerificationSignature, error)
+}
+
+type signatureService struct {
+	repo         repository.SignatureRepository
+	verification VerificationService
+	signer       *signing.Signer
+	logger       *zap.Logger
+}
+
+// NewSignatureService создает сервис подписи итогов проверок. signer может быть nil, тогда проверки не подписываются.
+func NewSignatureService(repo repository.SignatureRepository, verification VerificationService, signer *signing.Signer, logger *zap.Logger) SignatureService {
+	return &signatureService{
+		repo:         repo,
+		verification: verification,
+		signer:       signer,
+		logger:       logger,
+	}
+}
+
+// SignVerification подписывает статус, время завершения и хэши данных завершенной проверки.
+// Возвращает nil, если подпись отключена или проверка еще не завершена.
+func (s *signatureService) SignVerification(ctx context.Context, verificationID string) (*model.VerificationSignature, error) {
+	if s.signer == nil {
+		return nil, nil
+	}
+
+	summary, err := s.repo.GetSummary(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := model.VerificationStatus(summary.Status)
+	if status != model.VerificationStatusCompleted && status != model.VerificationStatusPartiallyCompleted {
+		return nil, nil
+	}
+
+	content := summary.Content()
+	signature := &model.VerificationSignature{
+		Algorithm: signing.Algorithm,
+		KeyID:     s.signer.KeyID(),
+		Content:   string(content),
+		Digest:    signing.Digest(content),
+		Signature: base64.StdEncoding.EncodeToString(s.signer.Sign(content)),
+	}
+
+	if err := s.repo.SaveSignature(ctx, verificationID, signature); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("verification signed",
+		zap.String("verification_id", verificationID),
+		zap.String("key_id", signature.KeyID),
+		zap.String("digest", signature.Digest))
+
+	return signature, nil
+}
+
+func (s *signatureService) GetSignature(ctx context.Context, verificationID string) (*model.VerificationSignature, error) {
+	if verificationID == "" {
+		return nil, invalidInputf("verification id cannot be empty")
+	}
+
+	// Проверка доступа к самой проверке, в том числе изоляции арендаторов
+	if _, err := s.verification.GetVerification(ctx, verificationID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetLatestSignature(ctx, verificationID)
+}
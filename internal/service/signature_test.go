@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/signing"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для SignatureRepository
+type mockSignatureRepository struct {
+	repository.SignatureRepository
+	summary *signing.VerificationSummary
+	saved   []*model.VerificationSignature
+}
+
+func (m *mockSignatureRepository) GetSummary(ctx context.Context, verificationID string) (*signing.VerificationSummary, error) {
+	return m.summary, nil
+}
+
+func (m *mockSignatureRepository) SaveSignature(ctx context.Context, verificationID string, signature *model.VerificationSignature) error {
+	signature.SignedAt = "2024-01-01T10:05:00Z"
+	m.saved = append(m.saved, signature)
+	return nil
+}
+
+func TestSignVerification(t *testing.T) {
+	signer, err := signing.NewSigner(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		signer       *signing.Signer
+		status       model.VerificationStatus
+		expectSigned bool
+	}{
+		{name: "completed", signer: signer, status: model.VerificationStatusCompleted, expectSigned: true},
+		{name: "partially_completed", signer: signer, status: model.VerificationStatusPartiallyCompleted, expectSigned: true},
+		{name: "in_process", signer: signer, status: model.VerificationStatusInProcess},
+		{name: "signing_disabled", status: model.VerificationStatusCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockSignatureRepository{
+				summary: &signing.VerificationSummary{
+					VerificationID: "test-id",
+					Status:         string(tt.status),
+					CompletedAt:    time.Date(2024, 1, 1, 10, 5, 0, 0, time.UTC),
+					PayloadHashes:  map[string]string{"BASIC_INFORMATION": strings.Repeat("a", 64)},
+				},
+			}
+			service := NewSignatureService(repo, nil, tt.signer, zaptest.NewLogger(t))
+
+			signature, err := service.SignVerification(context.Background(), "test-id")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !tt.expectSigned {
+				if signature != nil || len(repo.saved) != 0 {
+					t.Errorf("expected verification not to be signed, but got %+v", signature)
+				}
+				return
+			}
+
+			if signature == nil || len(repo.saved) != 1 {
+				t.Fatalf("expected one saved signature, but got %d", len(repo.saved))
+			}
+			if signature.KeyID != signer.KeyID() || signature.Algorithm != signing.Algorithm {
+				t.Errorf("unexpected key: %s %s", signature.Algorithm, signature.KeyID)
+			}
+			if signature.Content != string(repo.summary.Content()) || signature.Digest != signing.Digest([]byte(signature.Content)) {
+				t.Errorf("signature does not cover the summary: %+v", signature)
+			}
+
+			raw, err := base64.StdEncoding.DecodeString(signature.Signature)
+			if err != nil {
+				t.Fatalf("failed to decode signature: %v", err)
+			}
+			if !signing.Verify(signer.PublicKey(), []byte(signature.Content), raw) {
+				t.Error("expected signature to be valid")
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// simulatedStatuses итоговые статусы, с которыми воркер завершает проверку. PARTIALLY_COMPLETED
+// выставляет сам шлюз, сверяя доставленные данные с запрошенными.
+var simulatedStatuses = []model.VerificationStatus{
+	model.VerificationStatusCompleted,
+	model.VerificationStatusError,
+	model.VerificationStatusCompanyNotFound,
+}
+
+// CompletionSimulationService завершение проверок администратором вместо воркеров, когда поставщик
+// недоступен, а данные получены другим путем. Завершение сохраняется так же, как его сохранил бы
+// воркер, и передается обычному конвейеру обработки завершений: журнал, проверка данных, подпись,
+// уведомления и вебхуки.
+type CompletionSimulationService interface {
+	SimulateCompletion(ctx context.Context, verificationID string, status model.VerificationStatus, payloads []*model.DataTypePayloadInput, reason string) (*model.Verification, error)
+}
+
+type completionSimulationService struct {
+	repo         repository.CompletionSimulationRepository
+	verification VerificationService
+	nats         messaging.NATSClient
+	completions  func(*domain.Verification)
+	audit        AuditService
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+// NewCompletionSimulationService возвращает сервис ручного завершения. completions - обработчик
+// завершений этого процесса, если он подписан на них: соединение NATS с NO_ECHO не получает
+// собственные публикации, поэтому завершение передается обработчику напрямую. Без обработчика
+// завершение публикуется для экземпляров-подписчиков.
+func NewCompletionSimulationService(repo repository.CompletionSimulationRepository, verification VerificationService, nats messaging.NATSClient, completions func(*domain.Verification), audit AuditService, logger *zap.Logger) CompletionSimulationService {
+	return &completionSimulationService{
+		repo:         repo,
+		verification: verification,
+		nats:         nats,
+		completions:  completions,
+		audit:        audit,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+func (s *completionSimulationService) SimulateCompletion(ctx context.Context, verificationID string, status model.VerificationStatus, payloads []*model.DataTypePayloadInput, reason string) (*model.Verification, error) {
+	if verificationID == "" {
+		return nil, invalidInputf("verification id cannot be empty")
+	}
+	if !slices.Contains(simulatedStatuses, status) {
+		return nil, invalidInputf("status must be one of %v", simulatedStatuses)
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, invalidInputf("reason cannot be empty")
+	}
+
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	verification, err := s.verification.GetVerification(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Время хранится в базе с точностью до микросекунд, и уведомление должно нести то же время
+	completedAt := s.now().UTC().Truncate(time.Microsecond)
+	data := make([]*domain.Data, 0, len(payloads))
+	dataTypes := make([]model.VerificationDataType, 0, len(payloads))
+	for _, payload := range payloads {
+		if !payload.DataType.IsValid() {
+			return nil, invalidInputf("invalid data type: %s", payload.DataType)
+		}
+		if !slices.Contains(verification.RequestedDataTypes, payload.DataType) {
+			return nil, invalidInputf("data type %s was not requested by the verification", payload.DataType)
+		}
+		if slices.Contains(dataTypes, payload.DataType) {
+			return nil, invalidInputf("duplicate payload of data type %s", payload.DataType)
+		}
+		if !json.Valid([]byte(payload.Payload)) {
+			return nil, invalidInputf("payload of data type %s must be a JSON document", payload.DataType)
+		}
+		dataTypes = append(dataTypes, payload.DataType)
+		data = append(data, &domain.Data{DataType: domain.DataType(payload.DataType), Payload: payload.Payload, CreatedAt: completedAt})
+	}
+
+	if err := s.repo.Complete(ctx, verificationID, domain.Status(status), data, completedAt); err != nil {
+		return nil, err
+	}
+
+	actor := ""
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		actor = principal.Email
+	}
+	details := map[string]any{
+		"status":     status,
+		"data_types": dataTypes,
+		"reason":     reason,
+	}
+	if err := s.audit.RecordEvent(ctx, verificationID, model.AuditEventTypeCompletionSimulated, actor, details); err != nil {
+		s.logger.Error("failed to record simulated completion", zap.Error(err), zap.String("verification_id", verificationID))
+	}
+
+	s.logger.Warn("verification completed manually",
+		zap.String("verification_id", verificationID),
+		zap.String("status", string(status)),
+		zap.Int("payloads", len(data)),
+		zap.String("actor", actor))
+
+	if s.completions != nil {
+		s.completions(&domain.Verification{ID: verificationID, Status: domain.Status(status), UpdatedAt: completedAt})
+		return s.verification.GetVerification(ctx, verificationID)
+	}
+
+	completed := &messaging.VerificationCompletedMessage{
+		VerificationID: verificationID,
+		Status:         string(status),
+		CompletedAt:    completedAt.Format(time.RFC3339Nano),
+	}
+	if err := s.nats.PublishVerificationCompleted(ctx, completed); err != nil {
+		// Статус и данные уже сохранены: уведомления будут доставлены после NOTIFICATIONS_READY_TIMEOUT,
+		// но остальные шаги конвейера не выполнятся
+		return nil, fmt.Errorf("verification %s completed, but the completion event was not published: %w", verificationID, err)
+	}
+
+	return s.verification.GetVerification(ctx, verificationID)
+}
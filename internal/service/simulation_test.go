@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для CompletionSimulationRepository
+type mockCompletionSimulationRepository struct {
+	status      domain.Status
+	data        []*domain.Data
+	completedAt time.Time
+	calls       int
+}
+
+func (m *mockCompletionSimulationRepository) Complete(ctx context.Context, id string, status domain.Status, data []*domain.Data, completedAt time.Time) error {
+	m.calls++
+	m.status, m.data, m.completedAt = status, data, completedAt
+	return nil
+}
+
+func TestSimulateCompletion(t *testing.T) {
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "admin@example.com", Roles: []string{auth.RoleAdmin}})
+	analyst := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@example.com"})
+	basic := &model.DataTypePayloadInput{DataType: model.VerificationDataTypeBasicInformation, Payload: `{"name": "ООО Ромашка"}`}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		status        model.VerificationStatus
+		payloads      []*model.DataTypePayloadInput
+		reason        string
+		publishErr    error
+		inProcess     bool
+		expectedError string
+	}{
+		{name: "completed", ctx: admin, status: model.VerificationStatusCompleted, payloads: []*model.DataTypePayloadInput{basic}, reason: "provider outage, data from the registry"},
+		{name: "error_without_data", ctx: admin, status: model.VerificationStatusError, reason: "provider outage"},
+		// Настройки по умолчанию: GATEWAY_MODE=all и NATS_NO_ECHO=true, собственная публикация до подписки не дошла бы
+		{name: "handled_in_process", ctx: admin, status: model.VerificationStatusCompleted, payloads: []*model.DataTypePayloadInput{basic}, reason: "outage", inProcess: true},
+		{name: "computed_status", ctx: admin, status: model.VerificationStatusPartiallyCompleted, reason: "outage", expectedError: "status must be one of"},
+		{name: "empty_reason", ctx: admin, status: model.VerificationStatusCompleted, reason: " ", expectedError: "reason cannot be empty"},
+		{name: "not_admin", ctx: analyst, status: model.VerificationStatusCompleted, reason: "outage", expectedError: "access denied"},
+		{name: "not_requested", ctx: admin, status: model.VerificationStatusCompleted, reason: "outage",
+			payloads: []*model.DataTypePayloadInput{{DataType: model.VerificationDataTypeActivities, Payload: `[]`}}, expectedError: "was not requested"},
+		{name: "duplicate", ctx: admin, status: model.VerificationStatusCompleted, reason: "outage",
+			payloads: []*model.DataTypePayloadInput{basic, basic}, expectedError: "duplicate payload"},
+		{name: "invalid_payload", ctx: admin, status: model.VerificationStatusCompleted, reason: "outage",
+			payloads: []*model.DataTypePayloadInput{{DataType: model.VerificationDataTypeBasicInformation, Payload: `{"name"`}}, expectedError: "must be a JSON document"},
+		{name: "publish_failed", ctx: admin, status: model.VerificationStatusCompleted, reason: "outage", publishErr: errors.New("nats: connection closed"),
+			expectedError: "completion event was not published"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockCompletionSimulationRepository{}
+			verificationRepo := &mockVerificationRepository{
+				getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+					return &model.Verification{
+						ID:                 id,
+						Status:             model.VerificationStatusInProcess,
+						RequestedDataTypes: []model.VerificationDataType{model.VerificationDataTypeBasicInformation},
+					}, nil
+				},
+			}
+			var published []*messaging.VerificationCompletedMessage
+			nats := &mockNATSClient{
+				publishCompletedFunc: func(ctx context.Context, completed *messaging.VerificationCompletedMessage) error {
+					published = append(published, completed)
+					return tt.publishErr
+				},
+			}
+			var events []model.AuditEventType
+			auditRepo := &mockAuditRepository{
+				addEventFunc: func(ctx context.Context, verificationID string, eventType model.AuditEventType, actor string, details map[string]any) error {
+					events = append(events, eventType)
+					return nil
+				},
+			}
+
+			var handled []*domain.Verification
+			var completions func(*domain.Verification)
+			if tt.inProcess {
+				completions = func(completed *domain.Verification) {
+					handled = append(handled, completed)
+				}
+			}
+
+			logger := zaptest.NewLogger(t)
+			service := NewCompletionSimulationService(repo, NewVerificationService(verificationRepo, nats, nil, logger), nats, completions,
+				NewAuditService(auditRepo, nil, nil, logger), logger)
+
+			_, err := service.SimulateCompletion(tt.ctx, "test-id", tt.status, tt.payloads, tt.reason)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing %q, but got %v", tt.expectedError, err)
+				}
+				if tt.publishErr == nil && repo.calls != 0 {
+					t.Error("expected the verification to stay unchanged")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if repo.status != domain.Status(tt.status) || len(repo.data) != len(tt.payloads) {
+				t.Errorf("expected %s with %d payloads saved, but got %s with %d", tt.status, len(tt.payloads), repo.status, len(repo.data))
+			}
+			if len(events) != 1 || events[0] != model.AuditEventTypeCompletionSimulated {
+				t.Errorf("expected the simulated completion to be audited, but got %v", events)
+			}
+			if tt.inProcess {
+				if len(published) != 0 {
+					t.Errorf("expected the completion to be handled without publishing, but got %+v", published)
+				}
+				if len(handled) != 1 || handled[0].Status != domain.Status(tt.status) || !handled[0].UpdatedAt.Equal(repo.completedAt) {
+					t.Errorf("expected the completion handler to receive the saved completion, but got %+v", handled)
+				}
+				return
+			}
+			if len(published) != 1 || published[0].Status != string(tt.status) || published[0].CompletedAt != repo.completedAt.Format(time.RFC3339Nano) {
+				t.Errorf("expected a completion event with the saved time, but got %+v", published)
+			}
+		})
+	}
+}
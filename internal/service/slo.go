@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"scoring_api_gateway/graph/model"
+)
+
+// CreateRecorder учитывает результаты создания проверок пользователями
+type CreateRecorder interface {
+	RecordCreate(err error)
+}
+
+// sloVerificationService учитывает доступность мутации createVerification.
+// Проверки, создаваемые фоновыми задачами, не учитываются.
+type sloVerificationService struct {
+	VerificationService
+	recorder CreateRecorder
+}
+
+func NewSLOVerificationService(inner VerificationService, recorder CreateRecorder) VerificationService {
+	return &sloVerificationService{
+		VerificationService: inner,
+		recorder:            recorder,
+	}
+}
+
+func (s *sloVerificationService) CreateVerificationWithOptions(ctx context.Context, inn string, requestedTypes []model.VerificationDataType, authorEmail string, opts CreateOptions) (*model.Verification, error) {
+	verification, err := s.VerificationService.CreateVerificationWithOptions(ctx, inn, requestedTypes, authorEmail, opts)
+	s.recorder.RecordCreate(err)
+	return verification, err
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// maxStatisticsRange ограничивает период одного запроса статистики
+const maxStatisticsRange = 366 * 24 * time.Hour
+
+type StatisticsService interface {
+	GetDailyStatistics(ctx context.Context, from, to string, organization *string, timeZone *string, includeSubsidiaries bool) ([]*model.DailyVerificationStats, error)
+	GetSpendReport(ctx context.Context, from, to string, organization *string, includeSubsidiaries bool) (*model.SpendReport, error)
+	Refresh(ctx context.Context) error
+}
+
+type statisticsService struct {
+	repo          repository.StatisticsRepository
+	organizations repository.OrganizationRepository
+	logger        *zap.Logger
+}
+
+func NewStatisticsService(repo repository.StatisticsRepository, organizations repository.OrganizationRepository, logger *zap.Logger) StatisticsService {
+	return &statisticsService{
+		repo:          repo,
+		organizations: organizations,
+		logger:        logger,
+	}
+}
+
+// GetDailyStatistics возвращает статистику проверок по дням в часовом поясе timeZone, статусам и организациям.
+// С includeSubsidiaries проверки дочерних организаций учитываются в строках организации organization.
+func (s *statisticsService) GetDailyStatistics(ctx context.Context, from, to string, organization *string, timeZone *string, includeSubsidiaries bool) ([]*model.DailyVerificationStats, error) {
+	fromDay, toDay, err := parseStatisticsRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	tz, err := resolveTimeZone(timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	if !includeSubsidiaries {
+		var organizations []string
+		if organization != nil {
+			organizations = []string{*organization}
+		}
+		return s.repo.GetDaily(ctx, fromDay, toDay, organizations, tz)
+	}
+
+	if organization == nil || *organization == "" {
+		return nil, invalidInputf("organization is required to include subsidiaries")
+	}
+	organizations, err := s.withSubsidiaries(ctx, *organization)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.GetDaily(ctx, fromDay, toDay, organizations, tz)
+	if err != nil {
+		return nil, err
+	}
+	return rollUpDailyStatistics(stats, *organization), nil
+}
+
+// GetSpendReport возвращает число проверок вне песочницы и запрошенных в них типов данных за дни [from, to] в UTC
+func (s *statisticsService) GetSpendReport(ctx context.Context, from, to string, organization *string, includeSubsidiaries bool) (*model.SpendReport, error) {
+	fromDay, toDay, err := parseStatisticsRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	target := ""
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		target = principal.Organization
+	}
+	if organization != nil {
+		target = *organization
+	}
+	if target == "" {
+		return nil, invalidInputf("organization cannot be empty")
+	}
+	if _, err := requireOrgAdmin(ctx, s.organizations, target); err != nil {
+		return nil, err
+	}
+
+	organizations := []string{target}
+	if includeSubsidiaries {
+		if organizations, err = s.withSubsidiaries(ctx, target); err != nil {
+			return nil, err
+		}
+	}
+
+	spend, err := s.repo.GetSpend(ctx, fromDay, toDay.AddDate(0, 0, 1), organizations)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.SpendReport{
+		Organization:  target,
+		From:          from,
+		To:            to,
+		DataTypes:     []*model.DataTypeSpend{},
+		Organizations: make([]*model.OrganizationSpend, 0, len(spend)),
+	}
+	totals := make(map[model.VerificationDataType]*model.DataTypeSpend)
+	for _, organizationSpend := range spend {
+		item := &model.OrganizationSpend{
+			Organization:  organizationSpend.Organization,
+			Verifications: int32(organizationSpend.Verifications),
+			DataTypes:     make([]*model.DataTypeSpend, 0, len(organizationSpend.DataTypes)),
+		}
+		report.Verifications += item.Verifications
+		for _, dataTypeSpend := range organizationSpend.DataTypes {
+			dataType := model.VerificationDataType(dataTypeSpend.DataType)
+			item.DataTypes = append(item.DataTypes, &model.DataTypeSpend{DataType: dataType, Count: int32(dataTypeSpend.Count)})
+
+			total, ok := totals[dataType]
+			if !ok {
+				total = &model.DataTypeSpend{DataType: dataType}
+				totals[dataType] = total
+				report.DataTypes = append(report.DataTypes, total)
+			}
+			total.Count += int32(dataTypeSpend.Count)
+		}
+
+		if item.Organization == target {
+			report.Organizations = append([]*model.OrganizationSpend{item}, report.Organizations...)
+		} else {
+			report.Organizations = append(report.Organizations, item)
+		}
+	}
+	return report, nil
+}
+
+// Refresh пересчитывает предварительно агрегированную статистику
+func (s *statisticsService) Refresh(ctx context.Context) error {
+	return s.repo.Refresh(ctx)
+}
+
+// withSubsidiaries возвращает организацию и все ее дочерние организации
+func (s *statisticsService) withSubsidiaries(ctx context.Context, organization string) ([]string, error) {
+	subsidiaries, err := s.organizations.GetSubsidiaries(ctx, organization)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{organization}, subsidiaries...), nil
+}
+
+func parseStatisticsRange(from, to string) (time.Time, time.Time, error) {
+	fromDay, err := time.Parse(time.DateOnly, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, invalidInputf("from must be a date in YYYY-MM-DD format: %w", err)
+	}
+
+	toDay, err := time.Parse(time.DateOnly, to)
+	if err != nil {
+		return time.Time{}, time.Time{}, invalidInputf("to must be a date in YYYY-MM-DD format: %w", err)
+	}
+
+	if toDay.Before(fromDay) {
+		return time.Time{}, time.Time{}, invalidInputf("to must not be before from")
+	}
+
+	if toDay.Sub(fromDay) > maxStatisticsRange {
+		return time.Time{}, time.Time{}, invalidInputf("statistics range must not exceed 366 days")
+	}
+	return fromDay, toDay, nil
+}
+
+// rollUpDailyStatistics объединяет строки организаций одного дня и статуса в строку organization.
+// Среднее время завершения взвешивается числом проверок: оно есть только у строк COMPLETED,
+// где каждая проверка завершена.
+func rollUpDailyStatistics(stats []*model.DailyVerificationStats, organization string) []*model.DailyVerificationStats {
+	type key struct {
+		day    string
+		status model.VerificationStatus
+	}
+	rolledUp := make([]*model.DailyVerificationStats, 0, len(stats))
+	rows := make(map[key]*model.DailyVerificationStats)
+	completionSeconds := make(map[key]float64)
+	timed := make(map[key]int32)
+
+	for _, stat := range stats {
+		k := key{day: stat.Day, status: stat.Status}
+		row, ok := rows[k]
+		if !ok {
+			row = &model.DailyVerificationStats{Day: stat.Day, Status: stat.Status, Organization: organization}
+			rows[k] = row
+			rolledUp = append(rolledUp, row)
+		}
+		row.Count += stat.Count
+		if stat.AvgCompletionSeconds != nil {
+			completionSeconds[k] += *stat.AvgCompletionSeconds * float64(stat.Count)
+			timed[k] += stat.Count
+		}
+	}
+
+	for k, row := range rows {
+		if timed[k] > 0 {
+			avg := completionSeconds[k] / float64(timed[k])
+			row.AvgCompletionSeconds = &avg
+		}
+	}
+	return rolledUp
+}
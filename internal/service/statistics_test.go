@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для StatisticsRepository
+type mockStatisticsRepository struct {
+	getDailyFunc func(ctx context.Context, from, to time.Time, organizations []string, timeZone string) ([]*model.DailyVerificationStats, error)
+	getSpendFunc func(ctx context.Context, from, to time.Time, organizations []string) ([]*repository.OrganizationSpend, error)
+}
+
+func (m *mockStatisticsRepository) GetDaily(ctx context.Context, from, to time.Time, organizations []string, timeZone string) ([]*model.DailyVerificationStats, error) {
+	if m.getDailyFunc != nil {
+		return m.getDailyFunc(ctx, from, to, organizations, timeZone)
+	}
+	return nil, nil
+}
+
+func (m *mockStatisticsRepository) GetSpend(ctx context.Context, from, to time.Time, organizations []string) ([]*repository.OrganizationSpend, error) {
+	if m.getSpendFunc != nil {
+		return m.getSpendFunc(ctx, from, to, organizations)
+	}
+	return nil, nil
+}
+
+func (m *mockStatisticsRepository) Refresh(ctx context.Context) error {
+	return nil
+}
+
+func TestGetDailyStatistics(t *testing.T) {
+	tests := []struct {
+		name             string
+		from             string
+		to               string
+		timeZone         *string
+		expectedTimeZone string
+		expectedError    string
+	}{
+		{
+			name:             "valid_range",
+			from:             "2024-01-01",
+			to:               "2024-01-31",
+			expectedTimeZone: "UTC",
+		},
+		{
+			name:             "single_day",
+			from:             "2024-01-01",
+			to:               "2024-01-01",
+			expectedTimeZone: "UTC",
+		},
+		{
+			name:             "caller_timezone",
+			from:             "2024-03-30",
+			to:               "2024-03-31",
+			timeZone:         stringPtr("Europe/Berlin"),
+			expectedTimeZone: "Europe/Berlin",
+		},
+		{
+			name:          "unknown_timezone",
+			from:          "2024-01-01",
+			to:            "2024-01-31",
+			timeZone:      stringPtr("Mars/Olympus"),
+			expectedError: "unknown timezone",
+		},
+		{
+			name:          "invalid_from",
+			from:          "01.01.2024",
+			to:            "2024-01-31",
+			expectedError: "from must be a date in YYYY-MM-DD format",
+		},
+		{
+			name:          "reversed_range",
+			from:          "2024-02-01",
+			to:            "2024-01-01",
+			expectedError: "to must not be before from",
+		},
+		{
+			name:          "range_too_long",
+			from:          "2022-01-01",
+			to:            "2024-01-01",
+			expectedError: "statistics range must not exceed 366 days",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requestedFrom time.Time
+			var requestedTimeZone string
+			repo := &mockStatisticsRepository{
+				getDailyFunc: func(ctx context.Context, from, to time.Time, organizations []string, timeZone string) ([]*model.DailyVerificationStats, error) {
+					requestedFrom = from
+					requestedTimeZone = timeZone
+					return []*model.DailyVerificationStats{{Day: tt.from, Status: model.VerificationStatusCompleted, Count: 1}}, nil
+				},
+			}
+			service := NewStatisticsService(repo, holding, zaptest.NewLogger(t))
+
+			stats, err := service.GetDailyStatistics(context.Background(), tt.from, tt.to, nil, tt.timeZone, false)
+
+			if tt.expectedError != "" {
+				if err == nil {
+					t.Errorf("expected error containing '%s', but got nil", tt.expectedError)
+					return
+				}
+				if !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', but got '%s'", tt.expectedError, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if len(stats) != 1 {
+				t.Errorf("expected 1 row, but got %d", len(stats))
+			}
+			if requestedFrom.Format(time.DateOnly) != tt.from {
+				t.Errorf("expected from '%s', but got '%s'", tt.from, requestedFrom.Format(time.DateOnly))
+			}
+			if requestedTimeZone != tt.expectedTimeZone {
+				t.Errorf("expected timezone '%s', but got '%s'", tt.expectedTimeZone, requestedTimeZone)
+			}
+		})
+	}
+}
+
+func TestGetDailyStatisticsIncludeSubsidiaries(t *testing.T) {
+	var requested []string
+	repo := &mockStatisticsRepository{
+		getDailyFunc: func(ctx context.Context, from, to time.Time, organizations []string, timeZone string) ([]*model.DailyVerificationStats, error) {
+			requested = organizations
+			return []*model.DailyVerificationStats{
+				{Day: "2024-01-01", Status: model.VerificationStatusCompleted, Organization: "example.com", Count: 1, AvgCompletionSeconds: float64Ptr(10)},
+				{Day: "2024-01-01", Status: model.VerificationStatusCompleted, Organization: "retail.example.com", Count: 3, AvgCompletionSeconds: float64Ptr(30)},
+				{Day: "2024-01-01", Status: model.VerificationStatusError, Organization: "shop.example.com", Count: 2},
+			}, nil
+		},
+	}
+	service := NewStatisticsService(repo, holding, zaptest.NewLogger(t))
+
+	if _, err := service.GetDailyStatistics(context.Background(), "2024-01-01", "2024-01-01", nil, nil, true); err == nil || err.Error() != "organization is required to include subsidiaries" {
+		t.Errorf("expected organization required error, but got %v", err)
+	}
+
+	stats, err := service.GetDailyStatistics(context.Background(), "2024-01-01", "2024-01-01", stringPtr("example.com"), nil, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"example.com", "retail.example.com", "shop.example.com"}; !reflect.DeepEqual(requested, want) {
+		t.Errorf("expected organizations %v, but got %v", want, requested)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 rows, but got %d", len(stats))
+	}
+	completed := stats[0]
+	if completed.Organization != "example.com" || completed.Count != 4 || completed.AvgCompletionSeconds == nil || *completed.AvgCompletionSeconds != 25 {
+		t.Errorf("expected 4 completed verifications of example.com in 25s on average, but got %+v", completed)
+	}
+	if failed := stats[1]; failed.Organization != "example.com" || failed.Count != 2 || failed.AvgCompletionSeconds != nil {
+		t.Errorf("expected 2 erroneous verifications of example.com, but got %+v", failed)
+	}
+}
+
+func TestGetSpendReport(t *testing.T) {
+	orgAdmin := &auth.Principal{Email: "boss@example.com", Roles: []string{auth.RoleOrgAdmin}, Organization: "example.com"}
+	subsidiaryAdmin := &auth.Principal{Email: "boss@retail.example.com", Roles: []string{auth.RoleOrgAdmin}, Organization: "retail.example.com"}
+
+	var requestedTo time.Time
+	var requested []string
+	repo := &mockStatisticsRepository{
+		getSpendFunc: func(ctx context.Context, from, to time.Time, organizations []string) ([]*repository.OrganizationSpend, error) {
+			requestedTo = to
+			requested = organizations
+			return []*repository.OrganizationSpend{
+				{Organization: "retail.example.com", Verifications: 2, DataTypes: []*repository.DataTypeSpend{
+					{DataType: "BASIC_INFORMATION", Count: 2},
+					{DataType: "ACTIVITIES", Count: 1},
+				}},
+				{Organization: "example.com", Verifications: 1, DataTypes: []*repository.DataTypeSpend{
+					{DataType: "BASIC_INFORMATION", Count: 1},
+				}},
+			}, nil
+		},
+	}
+	service := NewStatisticsService(repo, holding, zaptest.NewLogger(t))
+
+	report, err := service.GetSpendReport(auth.WithPrincipal(context.Background(), orgAdmin), "2024-01-01", "2024-01-31", nil, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"example.com", "retail.example.com", "shop.example.com"}; !reflect.DeepEqual(requested, want) {
+		t.Errorf("expected organizations %v, but got %v", want, requested)
+	}
+	if requestedTo.Format(time.DateOnly) != "2024-02-01" {
+		t.Errorf("expected the period to end before 2024-02-01, but got %s", requestedTo)
+	}
+	if report.Organization != "example.com" || report.Verifications != 3 {
+		t.Errorf("expected 3 verifications of example.com, but got %s: %d", report.Organization, report.Verifications)
+	}
+	if len(report.DataTypes) != 2 || report.DataTypes[0].DataType != model.VerificationDataTypeBasicInformation || report.DataTypes[0].Count != 3 {
+		t.Errorf("expected 3 BASIC_INFORMATION requests first, but got %+v", report.DataTypes)
+	}
+	if len(report.Organizations) != 2 || report.Organizations[0].Organization != "example.com" {
+		t.Errorf("expected the requested organization first, but got %+v", report.Organizations)
+	}
+
+	// Администратор дочерней организации не видит расходы холдинга
+	if _, err := service.GetSpendReport(auth.WithPrincipal(context.Background(), subsidiaryAdmin), "2024-01-01", "2024-01-31", stringPtr("example.com"), false); err == nil || !containsError(err.Error(), "access denied") {
+		t.Errorf("expected access denied, but got %v", err)
+	}
+	if _, err := service.GetSpendReport(auth.WithPrincipal(context.Background(), orgAdmin), "2024-01-01", "2024-01-31", stringPtr("shop.example.com"), false); err != nil {
+		t.Errorf("expected the holding admin to see subsidiary spend, but got %v", err)
+	}
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"strings"
+	"time"
+)
+
+// defaultTimeZone часовой пояс, в котором интерпретируются даты без явного timezone
+const defaultTimeZone = "UTC"
+
+// resolveTimeZone проверяет имя часового пояса IANA. Само преобразование выполняется в SQL,
+// чтобы переходы на летнее время учитывались для каждой даты, а индексы по created_at работали.
+func resolveTimeZone(tz *string) (string, error) {
+	if tz == nil || strings.TrimSpace(*tz) == "" {
+		return defaultTimeZone, nil
+	}
+
+	name := strings.TrimSpace(*tz)
+	if _, err := time.LoadLocation(name); err != nil {
+		return "", invalidInputf("unknown timezone %q", name)
+	}
+	return name, nil
+}
+
+// parseDateBound разбирает границу периода: RFC3339 или дату YYYY-MM-DD.
+// isDate сообщает, что граница - календарный день в часовом поясе запроса.
+func parseDateBound(field, value string) (bound time.Time, isDate bool, err error) {
+	if day, err := time.Parse(time.DateOnly, value); err == nil {
+		return day, true, nil
+	}
+
+	bound, err = time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, invalidInputf("%s must be RFC3339 timestamp or YYYY-MM-DD date: %w", field, err)
+	}
+	return bound, false, nil
+}
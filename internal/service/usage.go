@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/usage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultUsageHours       = 24
+	defaultClientUsageLimit = 50
+	maxClientUsageLimit     = 500
+)
+
+// UsageService отчеты об использовании API: клиенту о себе для самодиагностики интеграции,
+// администратору - по всем клиентам, чтобы замечать злоупотребления
+type UsageService interface {
+	MyUsage(ctx context.Context, hours *int32) (*model.ClientUsage, error)
+	ClientUsage(ctx context.Context, hours *int32, limit *int32) ([]*model.ClientUsage, error)
+}
+
+type usageService struct {
+	repo   repository.UsageRepository
+	cfg    config.UsageConfig
+	now    func() time.Time
+	logger *zap.Logger
+}
+
+func NewUsageService(repo repository.UsageRepository, cfg config.UsageConfig, logger *zap.Logger) UsageService {
+	return &usageService{
+		repo:   repo,
+		cfg:    cfg,
+		now:    time.Now,
+		logger: logger,
+	}
+}
+
+// MyUsage возвращает использование API ключом или пользователем запроса
+func (s *usageService) MyUsage(ctx context.Context, hours *int32) (*model.ClientUsage, error) {
+	since, err := s.windowStart(hours)
+	if err != nil {
+		return nil, err
+	}
+
+	principal, _ := auth.PrincipalFromContext(ctx)
+	kind, id, ok := usage.Client(principal)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+
+	clientUsage, err := s.repo.GetClientUsage(ctx, kind, id, since, s.cfg.TopOperations)
+	if err != nil {
+		return nil, err
+	}
+	if clientUsage.Email == "" {
+		clientUsage.Email = principal.Email
+	}
+	return toModelClientUsage(clientUsage, since), nil
+}
+
+// ClientUsage возвращает самых активных клиентов за период
+func (s *usageService) ClientUsage(ctx context.Context, hours *int32, limit *int32) ([]*model.ClientUsage, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+	since, err := s.windowStart(hours)
+	if err != nil {
+		return nil, err
+	}
+
+	n := defaultClientUsageLimit
+	if limit != nil {
+		if *limit < 0 || *limit > maxClientUsageLimit {
+			return nil, invalidInputf("limit must be between 0 and %d, got %d", maxClientUsageLimit, *limit)
+		}
+		n = int(*limit)
+	}
+
+	clients, err := s.repo.ListClientUsage(ctx, since, n, s.cfg.TopOperations)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.ClientUsage, 0, len(clients))
+	for _, client := range clients {
+		result = append(result, toModelClientUsage(client, since))
+	}
+	return result, nil
+}
+
+// windowStart начало периода отчета: hours последних часов, не больше срока хранения
+func (s *usageService) windowStart(hours *int32) (time.Time, error) {
+	window := time.Duration(defaultUsageHours) * time.Hour
+	if hours != nil {
+		window = time.Duration(*hours) * time.Hour
+		if *hours <= 0 || window > s.cfg.Retention {
+			return time.Time{}, invalidInputf("invalid hours: must be between 1 and %d, got %d", int(s.cfg.Retention.Hours()), *hours)
+		}
+	}
+	return s.now().UTC().Add(-window).Truncate(time.Hour), nil
+}
+
+func toModelClientUsage(u *repository.ClientUsage, since time.Time) *model.ClientUsage {
+	result := &model.ClientUsage{
+		ClientKind:    model.ClientKind(u.ClientKind),
+		ClientID:      u.ClientID,
+		Email:         u.Email,
+		Since:         since.Format(time.RFC3339),
+		Requests:      int32(u.Requests),
+		Errors:        int32(u.Errors),
+		ResponseBytes: float64(u.ResponseBytes),
+		TopOperations: make([]*model.OperationUsage, 0, len(u.TopOperations)),
+	}
+	if u.Requests > 0 {
+		result.ErrorRate = float64(u.Errors) / float64(u.Requests)
+	}
+	for _, op := range u.TopOperations {
+		result.TopOperations = append(result.TopOperations, &model.OperationUsage{
+			Operation: op.Operation,
+			Requests:  int32(op.Requests),
+			Errors:    int32(op.Errors),
+		})
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+type mockUsageRepository struct {
+	requestedKind  string
+	requestedID    string
+	requestedSince time.Time
+	requestedLimit int
+	usage          []*repository.ClientUsage
+}
+
+func (m *mockUsageRepository) AddUsage(ctx context.Context, records []*repository.UsageRecord) error {
+	return nil
+}
+
+func (m *mockUsageRepository) GetClientUsage(ctx context.Context, clientKind, clientID string, since time.Time, topOperations int) (*repository.ClientUsage, error) {
+	m.requestedKind, m.requestedID, m.requestedSince = clientKind, clientID, since
+	if len(m.usage) == 0 {
+		return &repository.ClientUsage{ClientKind: clientKind, ClientID: clientID}, nil
+	}
+	return m.usage[0], nil
+}
+
+func (m *mockUsageRepository) ListClientUsage(ctx context.Context, since time.Time, limit, topOperations int) ([]*repository.ClientUsage, error) {
+	m.requestedSince, m.requestedLimit = since, limit
+	return m.usage, nil
+}
+
+func (m *mockUsageRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newTestUsageService(repo *mockUsageRepository) *usageService {
+	svc := NewUsageService(repo, config.UsageConfig{Retention: 720 * time.Hour, TopOperations: 5}, zap.NewNop()).(*usageService)
+	svc.now = func() time.Time { return time.Date(2024, 3, 1, 10, 42, 0, 0, time.UTC) }
+	return svc
+}
+
+func TestMyUsage(t *testing.T) {
+	repo := &mockUsageRepository{}
+	svc := newTestUsageService(repo)
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "svc@bank.ru", Scope: &auth.Scope{KeyID: "key-1"}})
+	usage, err := svc.MyUsage(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.requestedKind != repository.ClientKindAPIKey || repo.requestedID != "key-1" {
+		t.Errorf("expected usage of the api key, but got %s %s", repo.requestedKind, repo.requestedID)
+	}
+	if usage.Email != "svc@bank.ru" || usage.Since != "2024-02-29T10:00:00Z" || usage.ErrorRate != 0 || usage.TopOperations == nil {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+
+	hours := int32(1000)
+	if _, err := svc.MyUsage(ctx, &hours); err == nil || !strings.Contains(err.Error(), "invalid hours") {
+		t.Errorf("expected window beyond retention to be refused, but got %v", err)
+	}
+	if _, err := svc.MyUsage(context.Background(), nil); err == nil || err.Error() != "unauthenticated" {
+		t.Errorf("expected anonymous request to be refused, but got %v", err)
+	}
+}
+
+func TestClientUsage(t *testing.T) {
+	repo := &mockUsageRepository{usage: []*repository.ClientUsage{{
+		ClientKind:    repository.ClientKindUser,
+		ClientID:      "user@bank.ru",
+		Email:         "user@bank.ru",
+		Requests:      40,
+		Errors:        10,
+		ResponseBytes: 4096,
+		TopOperations: []*repository.OperationUsage{{Operation: "GetVerification", Requests: 30, Errors: 2}},
+	}}}
+	svc := newTestUsageService(repo)
+
+	analyst := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@bank.ru"})
+	if _, err := svc.ClientUsage(analyst, nil, nil); err == nil || !strings.HasPrefix(err.Error(), "access denied") {
+		t.Fatalf("expected access denied, but got %v", err)
+	}
+
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "admin@scoring.ru", Roles: []string{auth.RoleAdmin}})
+	hours := int32(2)
+	clients, err := svc.ClientUsage(admin, &hours, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.requestedLimit != defaultClientUsageLimit || !repo.requestedSince.Equal(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected window %v, limit %d", repo.requestedSince, repo.requestedLimit)
+	}
+	if len(clients) != 1 || clients[0].ClientKind != model.ClientKindUser || clients[0].ErrorRate != 0.25 ||
+		clients[0].ResponseBytes != 4096 || len(clients[0].TopOperations) != 1 || clients[0].TopOperations[0].Requests != 30 {
+		t.Errorf("unexpected clients %+v", clients)
+	}
+
+	limit := int32(1000)
+	if _, err := svc.ClientUsage(admin, nil, &limit); err == nil || !strings.HasPrefix(err.Error(), "limit must") {
+		t.Errorf("expected limit to be refused, but got %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// UserService управляет пользователями организаций. Аутентификация остается за прокси,
+// а учетная запись в шлюзе задает роли пользователя и позволяет отключить доступ.
+type UserService interface {
+	auth.MembershipResolver
+	InviteUser(ctx context.Context, email string, roles []model.OrganizationRole, name *string) (*model.OrganizationMember, error)
+	SetUserRoles(ctx context.Context, email string, roles []model.OrganizationRole) (*model.OrganizationMember, error)
+	DeactivateUser(ctx context.Context, email string) (*model.OrganizationMember, error)
+	ListMembers(ctx context.Context, organization *string) ([]*model.OrganizationMember, error)
+}
+
+type userService struct {
+	repo          repository.UserRepository
+	organizations repository.OrganizationRepository
+	logger        *zap.Logger
+}
+
+func NewUserService(repo repository.UserRepository, organizations repository.OrganizationRepository, logger *zap.Logger) UserService {
+	return &userService{
+		repo:          repo,
+		organizations: organizations,
+		logger:        logger,
+	}
+}
+
+// ResolveMembership добавляет к ролям из заголовков роли участника организации.
+// Отключенный пользователь получает nil, пользователь без учетной записи остается как есть.
+// Роли из базы выдаются только пользователю, которого подтвердил прокси аутентификации.
+func (s *userService) ResolveMembership(ctx context.Context, principal *auth.Principal) (*auth.Principal, error) {
+	if !principal.Verified {
+		return principal, nil
+	}
+	membership, err := s.repo.GetMembership(ctx, principal.Email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return principal, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if membership.Status == model.UserStatusDeactivated {
+		s.logger.Warn("deactivated user rejected", zap.String("email", principal.Email))
+		return nil, nil
+	}
+
+	if err := s.repo.MarkSeen(ctx, membership.UserID); err != nil {
+		s.logger.Warn("failed to record user activity", zap.Error(err))
+	}
+
+	resolved := *principal
+	resolved.Organization = membership.OrganizationID
+	resolved.Roles = append(append([]string{}, principal.Roles...), membership.Roles...)
+	return &resolved, nil
+}
+
+func (s *userService) InviteUser(ctx context.Context, email string, roles []model.OrganizationRole, name *string) (*model.OrganizationMember, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !strings.Contains(email, "@") {
+		return nil, invalidInputf("invalid email: %s", email)
+	}
+	organization := messaging.TenantOf(email)
+
+	principal, err := requireOrgAdmin(ctx, s.organizations, organization)
+	if err != nil {
+		return nil, err
+	}
+	authRoles, err := toAuthRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.Invite(ctx, organization, email, name, authRoles, principal.Email)
+	if errors.Is(err, repository.ErrConflict) {
+		return nil, fmt.Errorf("user already exists: %s: %w", email, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("user invited",
+		zap.String("email", email),
+		zap.String("organization", organization),
+		zap.Strings("roles", authRoles),
+		zap.String("invited_by", principal.Email))
+	return member, nil
+}
+
+func (s *userService) SetUserRoles(ctx context.Context, email string, roles []model.OrganizationRole) (*model.OrganizationMember, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	organization := messaging.TenantOf(email)
+
+	principal, err := requireOrgAdmin(ctx, s.organizations, organization)
+	if err != nil {
+		return nil, err
+	}
+	authRoles, err := toAuthRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.SetRoles(ctx, organization, email, authRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("user roles changed",
+		zap.String("email", email),
+		zap.Strings("roles", authRoles),
+		zap.String("changed_by", principal.Email))
+	return member, nil
+}
+
+func (s *userService) DeactivateUser(ctx context.Context, email string) (*model.OrganizationMember, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	organization := messaging.TenantOf(email)
+
+	principal, err := requireOrgAdmin(ctx, s.organizations, organization)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(principal.Email, email) {
+		return nil, fmt.Errorf("cannot deactivate own account")
+	}
+
+	member, err := s.repo.Deactivate(ctx, organization, email)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("user deactivated", zap.String("email", email), zap.String("deactivated_by", principal.Email))
+	return member, nil
+}
+
+func (s *userService) ListMembers(ctx context.Context, organization *string) ([]*model.OrganizationMember, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+
+	target := principal.Organization
+	if organization != nil {
+		target = *organization
+	}
+	if target == "" {
+		return nil, invalidInputf("organization cannot be empty")
+	}
+	if _, err := requireOrgAdmin(ctx, s.organizations, target); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.ListMembers(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if members == nil {
+		members = []*model.OrganizationMember{}
+	}
+	return members, nil
+}
+
+// requireOrgAdmin пропускает администратора шлюза и администратора указанной организации
+// или одной из вышестоящих: холдинг управляет своими дочерними организациями
+func requireOrgAdmin(ctx context.Context, organizations repository.OrganizationRepository, organization string) (*auth.Principal, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || principal.Email == "" {
+		return nil, auth.ErrUnauthenticated
+	}
+	if principal.Scope != nil {
+		return nil, fmt.Errorf("%w: api keys cannot act as org admin", auth.ErrForbidden)
+	}
+	if principal.HasRole(auth.RoleAdmin) {
+		return principal, nil
+	}
+	denied := fmt.Errorf("%w: role %s in organization %s required", auth.ErrForbidden, auth.RoleOrgAdmin, organization)
+	if !principal.HasRole(auth.RoleOrgAdmin) || principal.Organization == "" {
+		return nil, denied
+	}
+	if principal.Organization == organization {
+		return principal, nil
+	}
+
+	ancestors, err := organizations.GetAncestors(ctx, organization)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(ancestors, principal.Organization) {
+		return nil, denied
+	}
+	return principal, nil
+}
+
+func toAuthRoles(roles []model.OrganizationRole) ([]string, error) {
+	authRoles := []string{}
+	seen := make(map[model.OrganizationRole]bool)
+	for _, role := range roles {
+		if !role.IsValid() {
+			return nil, invalidInputf("invalid role: %s", role)
+		}
+		if !seen[role] {
+			seen[role] = true
+			authRoles = append(authRoles, strings.ToLower(string(role)))
+		}
+	}
+	return authRoles, nil
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для UserRepository
+type mockUserRepository struct {
+	repository.UserRepository
+	membership  *repository.Membership
+	memberships []*repository.Membership
+	seen        []string
+	invited     []string
+}
+
+func (m *mockUserRepository) GetMembership(ctx context.Context, email string) (*repository.Membership, error) {
+	if m.membership == nil {
+		return nil, errNotFound("user not found: %s", email)
+	}
+	return m.membership, nil
+}
+
+func (m *mockUserRepository) ListMemberships(ctx context.Context) ([]*repository.Membership, error) {
+	return m.memberships, nil
+}
+
+func (m *mockUserRepository) MarkSeen(ctx context.Context, userID string) error {
+	m.seen = append(m.seen, userID)
+	return nil
+}
+
+func (m *mockUserRepository) Invite(ctx context.Context, organizationID, email string, name *string, roles []string, invitedBy string) (*model.OrganizationMember, error) {
+	m.invited = append(m.invited, email)
+	member := &model.OrganizationMember{Email: email, Organization: organizationID, Status: model.UserStatusInvited, InvitedBy: invitedBy}
+	for _, role := range roles {
+		member.Roles = append(member.Roles, model.OrganizationRole(role))
+	}
+	return member, nil
+}
+
+func (m *mockUserRepository) Deactivate(ctx context.Context, organizationID, email string) (*model.OrganizationMember, error) {
+	return &model.OrganizationMember{Email: email, Organization: organizationID, Status: model.UserStatusDeactivated}, nil
+}
+
+// Mock для OrganizationRepository: parents - вышестоящая организация каждой дочерней
+type mockOrganizationRepository struct {
+	repository.OrganizationRepository
+	parents map[string]string
+}
+
+func (m *mockOrganizationRepository) GetAncestors(ctx context.Context, id string) ([]string, error) {
+	var ancestors []string
+	for parent, ok := m.parents[id]; ok; parent, ok = m.parents[parent] {
+		ancestors = append(ancestors, parent)
+	}
+	return ancestors, nil
+}
+
+func (m *mockOrganizationRepository) GetSubsidiaries(ctx context.Context, id string) ([]string, error) {
+	var subsidiaries []string
+	for child := range m.parents {
+		ancestors, _ := m.GetAncestors(ctx, child)
+		if slices.Contains(ancestors, id) {
+			subsidiaries = append(subsidiaries, child)
+		}
+	}
+	slices.Sort(subsidiaries)
+	return subsidiaries, nil
+}
+
+// holding группа организаций: example.com владеет retail.example.com, а та - shop.example.com
+var holding = &mockOrganizationRepository{parents: map[string]string{
+	"retail.example.com": "example.com",
+	"shop.example.com":   "retail.example.com",
+}}
+
+func TestResolveMembership(t *testing.T) {
+	tests := []struct {
+		name          string
+		membership    *repository.Membership
+		unverified    bool
+		expectNil     bool
+		expectedRoles int
+		expectedOrg   string
+	}{
+		{name: "no_account", expectedRoles: 1},
+		{
+			name:          "member",
+			membership:    &repository.Membership{UserID: "u-1", Status: model.UserStatusActive, OrganizationID: "example.com", Roles: []string{auth.RoleOrgAdmin}},
+			expectedRoles: 2,
+			expectedOrg:   "example.com",
+		},
+		{
+			name:       "deactivated",
+			membership: &repository.Membership{UserID: "u-1", Status: model.UserStatusDeactivated, OrganizationID: "example.com"},
+			expectNil:  true,
+		},
+		{
+			name:          "unverified",
+			membership:    &repository.Membership{UserID: "u-1", Status: model.UserStatusActive, OrganizationID: "example.com", Roles: []string{auth.RoleOrgAdmin}},
+			unverified:    true,
+			expectedRoles: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockUserRepository{membership: tt.membership}
+			service := NewUserService(repo, holding, zaptest.NewLogger(t))
+
+			principal := &auth.Principal{Email: "user@example.com", Roles: []string{auth.RoleAnalyst}, Verified: !tt.unverified}
+			resolved, err := service.ResolveMembership(context.Background(), principal)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.expectNil {
+				if resolved != nil {
+					t.Errorf("expected deactivated user to be rejected, but got %+v", resolved)
+				}
+				return
+			}
+			if len(resolved.Roles) != tt.expectedRoles || resolved.Organization != tt.expectedOrg {
+				t.Errorf("unexpected principal: %+v", resolved)
+			}
+			if len(principal.Roles) != 1 {
+				t.Errorf("expected original principal to be unchanged, but got %v", principal.Roles)
+			}
+			if tt.membership != nil && !tt.unverified && len(repo.seen) != 1 {
+				t.Errorf("expected user activity to be recorded, but got %v", repo.seen)
+			}
+			if tt.unverified && len(repo.seen) != 0 {
+				t.Errorf("expected unverified user not to be resolved, but got %v", repo.seen)
+			}
+		})
+	}
+}
+
+func TestInviteUser(t *testing.T) {
+	orgAdmin := &auth.Principal{Email: "boss@example.com", Roles: []string{auth.RoleOrgAdmin}, Organization: "example.com"}
+	gatewayAdmin := &auth.Principal{Email: "admin@scoring.local", Roles: []string{auth.RoleAdmin}}
+
+	tests := []struct {
+		name          string
+		principal     *auth.Principal
+		email         string
+		roles         []model.OrganizationRole
+		expectedError string
+	}{
+		{name: "org_admin", principal: orgAdmin, email: "New.User@Example.com", roles: []model.OrganizationRole{model.OrganizationRoleAnalyst}},
+		{name: "gateway_admin", principal: gatewayAdmin, email: "user@other.org", roles: []model.OrganizationRole{model.OrganizationRoleOrgAdmin}},
+		{name: "other_organization", principal: orgAdmin, email: "user@other.org", expectedError: "access denied"},
+		{name: "subsidiary_of_org_admin", principal: orgAdmin, email: "user@retail.example.com", roles: []model.OrganizationRole{model.OrganizationRoleViewer}},
+		{name: "parent_of_org_admin", principal: &auth.Principal{Email: "boss@retail.example.com", Roles: []string{auth.RoleOrgAdmin}, Organization: "retail.example.com"}, email: "user@example.com", expectedError: "access denied"},
+		{name: "not_org_admin", principal: &auth.Principal{Email: "analyst@example.com", Roles: []string{auth.RoleAnalyst}, Organization: "example.com"}, email: "user@example.com", expectedError: "access denied"},
+		{name: "api_key", principal: &auth.Principal{Email: "partner@example.com", Roles: []string{auth.RoleAdmin}, Scope: &auth.Scope{}}, email: "user@example.com", expectedError: "access denied"},
+		{name: "invalid_role", principal: orgAdmin, email: "user@example.com", roles: []model.OrganizationRole{"OWNER"}, expectedError: "invalid role: OWNER"},
+		{name: "invalid_email", principal: orgAdmin, email: "user", expectedError: "invalid email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockUserRepository{}
+			service := NewUserService(repo, holding, zaptest.NewLogger(t))
+
+			member, err := service.InviteUser(auth.WithPrincipal(context.Background(), tt.principal), tt.email, tt.roles, nil)
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error %q, but got %v", tt.expectedError, err)
+				}
+				if len(repo.invited) != 0 {
+					t.Errorf("expected no user to be invited, but got %v", repo.invited)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if member.InvitedBy != tt.principal.Email {
+				t.Errorf("expected invitedBy %s, but got %s", tt.principal.Email, member.InvitedBy)
+			}
+			if tt.name == "org_admin" && (member.Email != "new.user@example.com" || member.Organization != "example.com") {
+				t.Errorf("expected normalized email in the email domain organization, but got %+v", member)
+			}
+		})
+	}
+}
+
+func TestDeactivateUser(t *testing.T) {
+	orgAdmin := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "boss@example.com", Roles: []string{auth.RoleOrgAdmin}, Organization: "example.com"})
+	service := NewUserService(&mockUserRepository{}, holding, zaptest.NewLogger(t))
+
+	if _, err := service.DeactivateUser(orgAdmin, "Boss@example.com"); err == nil || err.Error() != "cannot deactivate own account" {
+		t.Errorf("expected own account error, but got %v", err)
+	}
+
+	member, err := service.DeactivateUser(orgAdmin, "user@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if member.Status != model.UserStatusDeactivated {
+		t.Errorf("expected DEACTIVATED status, but got %s", member.Status)
+	}
+}
@@ -2,10 +2,18 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/catalog"
+	"scoring_api_gateway/internal/domain"
 	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/metadata"
 	"scoring_api_gateway/internal/repository"
 
 	"github.com/google/uuid"
@@ -14,109 +22,597 @@ import (
 
 type VerificationService interface {
 	CreateVerification(ctx context.Context, inn string, requestedTypes []model.VerificationDataType, authorEmail string) (*model.Verification, error)
+	CreateVerificationWithPriority(ctx context.Context, inn string, requestedTypes []model.VerificationDataType, authorEmail string, priority messaging.Priority) (*model.Verification, error)
+	CreateVerificationWithOptions(ctx context.Context, inn string, requestedTypes []model.VerificationDataType, authorEmail string, opts CreateOptions) (*model.Verification, error)
+	GetVerificationByExternalRef(ctx context.Context, system *string, ref string) (*model.Verification, error)
 	GetVerification(ctx context.Context, id string) (*model.Verification, error)
-	GetAllVerifications(ctx context.Context, limit *int32, offset *int32) ([]*model.Verification, error)
-	GetVerificationWithData(ctx context.Context, id string) (*model.VerificationDataResult, error)
+	GetCompanySnapshot(ctx context.Context, inn string, asOf string) (*model.CompanySnapshot, error)
+	GetVerificationStatuses(ctx context.Context, inns []string) ([]*model.CompanyVerificationStatus, error)
+	HasRecentVerification(ctx context.Context, inn string, maxAgeHours int32) (*model.RecentVerification, error)
+	// GetAllVerifications возвращает проверки области scope: по умолчанию - созданные пользователем
+	GetAllVerifications(ctx context.Context, filter *model.VerificationFilter, scope *model.VerificationScope, limit *int32, offset *int32) ([]*model.Verification, error)
+	// GetVerificationWithData возвращает проверку с данными типов dataTypes (nil - всех типов)
+	GetVerificationWithData(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.VerificationDataResult, error)
+	UpdateRiskLevel(ctx context.Context, id string, riskLevel model.RiskLevel) error
+	ReconcileDeliveredData(ctx context.Context, id string) ([]model.VerificationDataType, error)
+	RetryMissingData(ctx context.Context, verification *model.Verification) error
+	// UpdateMetadata добавляет или заменяет ключи set и удаляет ключи remove. Доступно автору проверки
+	// и администратору.
+	UpdateMetadata(ctx context.Context, id string, set []*model.MetadataEntryInput, remove []string) (*model.Verification, error)
+}
+
+// CreateOptions дополнительные параметры создания проверки
+type CreateOptions struct {
+	Priority    messaging.Priority
+	ExternalRef *model.ExternalRefInput
+	Metadata    []*model.MetadataEntryInput
+	// CustomDataTypes типы данных, объявленные в конфигурации шлюза
+	CustomDataTypes []string
 }
 
 type verificationService struct {
-	repo   repository.VerificationRepository
-	nats   messaging.NATSClient
-	logger *zap.Logger
+	repo     repository.VerificationRepository
+	nats     messaging.NATSClient
+	registry *catalog.Registry
+	logger   *zap.Logger
 }
 
-func NewVerificationService(repo repository.VerificationRepository, nats messaging.NATSClient, logger *zap.Logger) VerificationService {
+// NewVerificationService создает сервис проверок. registry определяет, какие объявленные в конфигурации
+// типы данных можно заказать; nil - только встроенные типы.
+func NewVerificationService(repo repository.VerificationRepository, nats messaging.NATSClient, registry *catalog.Registry, logger *zap.Logger) VerificationService {
 	return &verificationService{
-		repo:   repo,
-		nats:   nats,
-		logger: logger,
+		repo:     repo,
+		nats:     nats,
+		registry: registry,
+		logger:   logger,
 	}
 }
 
 func (s *verificationService) CreateVerification(ctx context.Context, inn string, requestedTypes []model.VerificationDataType, authorEmail string) (*model.Verification, error) {
+	return s.CreateVerificationWithPriority(ctx, inn, requestedTypes, authorEmail, messaging.PriorityNormal)
+}
+
+// CreateVerificationWithPriority создает проверку и отправляет её в очередь с указанным приоритетом
+func (s *verificationService) CreateVerificationWithPriority(ctx context.Context, inn string, requestedTypes []model.VerificationDataType, authorEmail string, priority messaging.Priority) (*model.Verification, error) {
+	return s.CreateVerificationWithOptions(ctx, inn, requestedTypes, authorEmail, CreateOptions{Priority: priority})
+}
+
+// CreateVerificationWithOptions создает проверку и отправляет её в очередь
+func (s *verificationService) CreateVerificationWithOptions(ctx context.Context, inn string, requestedTypes []model.VerificationDataType, authorEmail string, opts CreateOptions) (*model.Verification, error) {
 	if inn == "" {
-		return nil, fmt.Errorf("inn cannot be empty")
+		return nil, invalidInputf("inn cannot be empty")
 	}
 
-	if len(requestedTypes) == 0 {
-		return nil, fmt.Errorf("at least one data type must be requested")
+	if len(requestedTypes) == 0 && len(opts.CustomDataTypes) == 0 {
+		return nil, invalidInputf("at least one data type must be requested")
+	}
+	// Старые клиенты могут передавать устаревшие типы: воркеры получают актуальные
+	requestedTypes = catalog.DefaultDeprecations().CanonicalDataTypes(requestedTypes)
+	if len(opts.CustomDataTypes) > 0 {
+		custom, err := s.customDataTypes(opts.CustomDataTypes)
+		if err != nil {
+			return nil, err
+		}
+		requestedTypes = append(slices.Clip(requestedTypes), custom...)
 	}
 
 	if len(inn) != 10 && len(inn) != 12 {
-		return nil, fmt.Errorf("inn must be 10 or 12 digits, got %d", len(inn))
+		return nil, invalidInputf("inn must be 10 or 12 digits, got %d", len(inn))
+	}
+
+	externalRef, err := validateExternalRef(opts.ExternalRef)
+	if err != nil {
+		return nil, err
+	}
+
+	verificationMetadata, err := metadataFromInput(opts.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if verificationMetadata != nil {
+		if err := metadata.Validate(verificationMetadata); err != nil {
+			return nil, invalidInput(err)
+		}
+	}
+
+	if err := authorizeCreate(ctx, requestedTypes); err != nil {
+		return nil, err
+	}
+
+	priority := opts.Priority
+	if priority == "" {
+		priority = messaging.PriorityNormal
 	}
 
 	verificationID := uuid.New().String()
 
-	verification := &model.Verification{
+	verification := &domain.Verification{
 		ID:                 verificationID,
-		Inn:                inn,
-		Status:             model.VerificationStatusInProcess,
+		INN:                inn,
+		Status:             domain.StatusInProcess,
 		AuthorEmail:        authorEmail,
-		RequestedDataTypes: requestedTypes,
+		RequestedDataTypes: model.DataTypesToDomain(requestedTypes),
+		ReviewState:        domain.ReviewStateUnreviewed,
 	}
+	if externalRef != nil {
+		verification.ExternalRef = &domain.ExternalRef{System: externalRef.System, Ref: externalRef.Ref}
+	}
+	verification.Metadata = verificationMetadata
 
-	err := s.nats.PublishVerificationRequest(ctx, verification)
+	// Связь сохраняется до публикации, чтобы проверку можно было найти по внешнему идентификатору
+	// сразу после того, как обработчик ее создаст
+	if externalRef != nil {
+		if err := s.repo.SetExternalRef(ctx, verificationID, externalRef); err != nil {
+			s.logger.Error("failed to save external ref", zap.Error(err), zap.String("verification_id", verificationID))
+			return nil, fmt.Errorf("failed to save external ref: %w", err)
+		}
+	}
+	if verificationMetadata != nil {
+		if err := s.repo.SetMetadata(ctx, verificationID, verificationMetadata); err != nil {
+			s.logger.Error("failed to save metadata", zap.Error(err), zap.String("verification_id", verificationID))
+			return nil, fmt.Errorf("failed to save metadata: %w", err)
+		}
+	}
+
+	err = s.nats.PublishVerificationRequestWithPriority(ctx, verification, priority)
 	if err != nil {
 		s.logger.Error("failed to publish verification request", zap.Error(err), zap.String("verification_id", verificationID))
+		s.abandon(ctx, verificationID, err)
 		return nil, fmt.Errorf("failed to publish verification request: %w", err)
 	}
 
 	s.logger.Info("verification request published", zap.String("verification_id", verificationID), zap.String("inn", inn))
-	return verification, nil
+	// Публикация, отложенная ограничениями арендатора, заполняет позицию в очереди
+	return model.VerificationFromDomain(verification), nil
+}
+
+// abandon завершает ошибкой проверку, запрос которой не удалось опубликовать. Строка записывается
+// перед публикацией, и без этого проверка, которую клиент не получил, навсегда осталась бы IN_PROCESS
+// и занимала место в лимите арендатора. Строки может не быть, если запрос не дошел до ее записи.
+func (s *verificationService) abandon(ctx context.Context, id string, cause error) {
+	message := fmt.Sprintf("failed to publish verification request: %v", cause)
+	err := s.repo.UpdateStatus(ctx, id, model.VerificationStatusError, &message, time.Now())
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		s.logger.Error("failed to mark unpublished verification as failed", zap.Error(err), zap.String("verification_id", id))
+	}
+}
+
+// validateExternalRef нормализует идентификатор внешней системы
+func validateExternalRef(input *model.ExternalRefInput) (*model.ExternalRef, error) {
+	if input == nil {
+		return nil, nil
+	}
+
+	ref := strings.TrimSpace(input.Ref)
+	if ref == "" {
+		return nil, invalidInputf("external ref cannot be empty")
+	}
+	if len(ref) > 255 {
+		return nil, invalidInputf("external ref must not exceed 255 characters")
+	}
+
+	var system *string
+	if input.System != nil {
+		if trimmed := strings.TrimSpace(*input.System); trimmed != "" {
+			if len(trimmed) > 100 {
+				return nil, invalidInputf("external system must not exceed 100 characters")
+			}
+			system = &trimmed
+		}
+	}
+
+	return &model.ExternalRef{System: system, Ref: ref}, nil
+}
+
+// metadataFromInput собирает метаданные из пар API. Повторяющийся ключ - ошибка, пустой список - nil.
+func metadataFromInput(entries []*model.MetadataEntryInput) (map[string]string, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if _, ok := result[entry.Key]; ok {
+			return nil, invalidInputf("duplicate metadata key %q", entry.Key)
+		}
+		result[entry.Key] = entry.Value
+	}
+	return result, nil
+}
+
+// customDataTypes проверяет, что типы объявлены в конфигурации, доступны и не повторяются
+func (s *verificationService) customDataTypes(names []string) ([]model.VerificationDataType, error) {
+	custom := make([]model.VerificationDataType, 0, len(names))
+	for _, name := range names {
+		dataType := model.VerificationDataType(name)
+		var entry catalog.DataType
+		var ok bool
+		if s.registry != nil {
+			entry, ok = s.registry.Lookup(dataType)
+		}
+		if !ok || !entry.Custom {
+			return nil, invalidInputf("unknown custom data type %q", name)
+		}
+		if !entry.Available {
+			return nil, invalidInputf("custom data type %s is not available", name)
+		}
+		if slices.Contains(custom, dataType) {
+			return nil, invalidInputf("duplicate custom data type %s", name)
+		}
+		custom = append(custom, dataType)
+	}
+	return custom, nil
+}
+
+// authorizeCreate проверяет, что ключ сервисного аккаунта разрешает заказ запрошенных типов данных
+func authorizeCreate(ctx context.Context, requestedTypes []model.VerificationDataType) error {
+	if err := auth.CheckOperation(ctx, auth.OperationCreate); err != nil {
+		return err
+	}
+
+	dataTypes := make([]string, 0, len(requestedTypes))
+	for _, dataType := range requestedTypes {
+		dataTypes = append(dataTypes, string(dataType))
+	}
+	return auth.CheckDataTypes(ctx, dataTypes)
+}
+
+// restrictData возвращает проверку без данных типов, не разрешенных ключом сервисного аккаунта:
+// ни data, ни dataQuality, ни customData не должны раскрывать их. Исходная проверка не меняется.
+// Ответы, которые отдают проверку клиенту, проходят через эту функцию.
+func restrictData(ctx context.Context, verification *model.Verification) *model.Verification {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if verification == nil || !ok || principal.Scope == nil {
+		return verification
+	}
+
+	restricted := *verification
+	restricted.Data = nil
+	for _, data := range verification.Data {
+		if auth.DataTypeAllowed(ctx, string(data.DataType)) {
+			restricted.Data = append(restricted.Data, data)
+		}
+	}
+	restricted.DataQuality = nil
+	for _, quality := range verification.DataQuality {
+		if auth.DataTypeAllowed(ctx, string(quality.DataType)) {
+			restricted.DataQuality = append(restricted.DataQuality, quality)
+		}
+	}
+	restricted.CustomData = nil
+	for _, data := range verification.CustomData {
+		if auth.DataTypeAllowed(ctx, data.DataType) {
+			restricted.CustomData = append(restricted.CustomData, data)
+		}
+	}
+	// Пустой список остается пустым списком: null означает, что данные не загружались
+	if verification.Data != nil && restricted.Data == nil {
+		restricted.Data = []*model.VerificationData{}
+	}
+	if verification.DataQuality != nil && restricted.DataQuality == nil {
+		restricted.DataQuality = []*model.DataQuality{}
+	}
+	if verification.CustomData != nil && restricted.CustomData == nil {
+		restricted.CustomData = []*model.GenericVerificationData{}
+	}
+	return &restricted
+}
+
+// restrictAll применяет restrictData к каждой проверке списка
+func restrictAll(ctx context.Context, verifications []*model.Verification) []*model.Verification {
+	for i, verification := range verifications {
+		verifications[i] = restrictData(ctx, verification)
+	}
+	return verifications
 }
 
 func (s *verificationService) GetVerification(ctx context.Context, id string) (*model.Verification, error) {
 	if id == "" {
-		return nil, fmt.Errorf("verification id cannot be empty")
+		return nil, invalidInputf("verification id cannot be empty")
+	}
+
+	if err := auth.CheckOperation(ctx, auth.OperationRead); err != nil {
+		return nil, err
 	}
 
 	verification, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
 	if err != nil {
 		s.logger.Error("failed to get verification from repository", zap.Error(err), zap.String("id", id))
 		return nil, fmt.Errorf("failed to get verification: %w", err)
 	}
 
+	return restrictData(ctx, verification), nil
+}
+
+// GetVerificationByExternalRef возвращает последнюю проверку, связанную с идентификатором внешней системы
+func (s *verificationService) GetVerificationByExternalRef(ctx context.Context, system *string, ref string) (*model.Verification, error) {
+	externalRef, err := validateExternalRef(&model.ExternalRefInput{System: system, Ref: ref})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auth.CheckOperation(ctx, auth.OperationRead); err != nil {
+		return nil, err
+	}
+
+	id, err := s.repo.GetIDByExternalRef(ctx, externalRef.System, externalRef.Ref)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		s.logger.Error("failed to get verification by external ref", zap.Error(err), zap.String("ref", ref))
+		return nil, fmt.Errorf("failed to get verification: %w", err)
+	}
+
+	return s.GetVerification(ctx, id)
+}
+
+// GetCompanySnapshot восстанавливает данные о компании, известные на момент asOf
+func (s *verificationService) GetCompanySnapshot(ctx context.Context, inn string, asOf string) (*model.CompanySnapshot, error) {
+	if len(inn) != 10 && len(inn) != 12 {
+		return nil, invalidInputf("inn must be 10 or 12 digits, got %d", len(inn))
+	}
+
+	// Дата без времени - начало дня в UTC
+	moment, _, err := parseDateBound("asOf", asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auth.CheckOperation(ctx, auth.OperationRead); err != nil {
+		return nil, err
+	}
+
+	verification, err := s.repo.GetSnapshot(ctx, inn, moment)
+	if err != nil {
+		s.logger.Error("failed to get company snapshot", zap.Error(err), zap.String("inn", inn))
+		return nil, fmt.Errorf("failed to get company snapshot: %w", err)
+	}
 	if verification == nil {
-		return nil, fmt.Errorf("verification not found: %s", id)
+		return nil, nil
 	}
 
-	return verification, nil
+	verification = restrictData(ctx, verification)
+	data := make([]*model.VerificationData, 0, len(verification.Data))
+	data = append(data, verification.Data...)
+
+	return &model.CompanySnapshot{
+		Inn:          inn,
+		AsOf:         moment.UTC().Format(time.RFC3339),
+		Verification: verification,
+		Data:         data,
+	}, nil
 }
 
-func (s *verificationService) GetAllVerifications(ctx context.Context, limit *int32, offset *int32) ([]*model.Verification, error) {
+// maxStatusINNs ограничивает количество компаний в одном запросе статусов
+const maxStatusINNs = 5000
+
+// GetVerificationStatuses возвращает статус последней проверки каждой компании одним запросом.
+// Порядок результата совпадает с порядком ИНН, повторы отбрасываются; для компаний без
+// проверок возвращается запись только с ИНН.
+func (s *verificationService) GetVerificationStatuses(ctx context.Context, inns []string) ([]*model.CompanyVerificationStatus, error) {
+	if len(inns) > maxStatusINNs {
+		return nil, invalidInputf("at most %d inns can be requested at once, got %d", maxStatusINNs, len(inns))
+	}
+
+	unique := make([]string, 0, len(inns))
+	seen := make(map[string]bool, len(inns))
+	for _, inn := range inns {
+		if len(inn) != 10 && len(inn) != 12 {
+			return nil, invalidInputf("inn must be 10 or 12 digits, got %d", len(inn))
+		}
+		if !seen[inn] {
+			seen[inn] = true
+			unique = append(unique, inn)
+		}
+	}
+
+	if err := auth.CheckOperation(ctx, auth.OperationRead); err != nil {
+		return nil, err
+	}
+
+	if len(unique) == 0 {
+		return []*model.CompanyVerificationStatus{}, nil
+	}
+
+	latest, err := s.repo.GetLatestStatuses(ctx, unique)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verification statuses: %w", err)
+	}
+
+	byINN := make(map[string]*model.CompanyVerificationStatus, len(latest))
+	for _, status := range latest {
+		byINN[status.Inn] = status
+	}
+
+	statuses := make([]*model.CompanyVerificationStatus, 0, len(unique))
+	for _, inn := range unique {
+		status, ok := byINN[inn]
+		if !ok {
+			status = &model.CompanyVerificationStatus{Inn: inn}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// maxRecentVerificationAgeHours ограничивает период проверки существования годом
+const maxRecentVerificationAgeHours = 366 * 24
+
+// HasRecentVerification сообщает, есть ли у компании проверка не старше maxAgeHours часов, и возвращает
+// последнюю из них. Ответ строится одним запросом по индексу без данных и событий проверки.
+func (s *verificationService) HasRecentVerification(ctx context.Context, inn string, maxAgeHours int32) (*model.RecentVerification, error) {
+	if len(inn) != 10 && len(inn) != 12 {
+		return nil, invalidInputf("inn must be 10 or 12 digits, got %d", len(inn))
+	}
+	if maxAgeHours <= 0 || maxAgeHours > maxRecentVerificationAgeHours {
+		return nil, invalidInputf("maxAgeHours must be between 1 and %d, got %d", maxRecentVerificationAgeHours, maxAgeHours)
+	}
+
+	if err := auth.CheckOperation(ctx, auth.OperationRead); err != nil {
+		return nil, err
+	}
+
+	since := time.Now().Add(-time.Duration(maxAgeHours) * time.Hour)
+	recent, err := s.repo.GetLatestSince(ctx, inn, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check recent verification: %w", err)
+	}
+	return recent, nil
+}
+
+func (s *verificationService) GetAllVerifications(ctx context.Context, filter *model.VerificationFilter, scope *model.VerificationScope, limit *int32, offset *int32) ([]*model.Verification, error) {
 	if limit != nil && *limit < 0 {
-		return nil, fmt.Errorf("limit must be non-negative, got %d", *limit)
+		return nil, invalidInputf("limit must be non-negative, got %d", *limit)
 	}
 
 	if offset != nil && *offset < 0 {
-		return nil, fmt.Errorf("offset must be non-negative, got %d", *offset)
+		return nil, invalidInputf("offset must be non-negative, got %d", *offset)
+	}
+
+	if err := auth.CheckOperation(ctx, auth.OperationRead); err != nil {
+		return nil, err
+	}
+
+	repoFilter, err := toRepositoryFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyScope(ctx, scope, &repoFilter); err != nil {
+		return nil, err
+	}
+
+	verifications, err := s.repo.GetAll(ctx, repoFilter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return restrictAll(ctx, verifications), nil
+}
+
+// applyScope ограничивает список проверками пользователя или его организации. Организация -
+// та, участником которой пользователь зарегистрирован, иначе домен его email, как у арендатора.
+func applyScope(ctx context.Context, scope *model.VerificationScope, filter *repository.VerificationFilter) error {
+	target := model.VerificationScopeMine
+	if scope != nil {
+		target = *scope
+	}
+
+	switch target {
+	case model.VerificationScopeAll:
+		return auth.RequireRole(ctx, auth.RoleAdmin)
+	case model.VerificationScopeMine, model.VerificationScopeMyOrg:
+		email, err := auth.RequireEmail(ctx)
+		if err != nil {
+			return err
+		}
+		if target == model.VerificationScopeMine {
+			filter.Owner = &email
+			return nil
+		}
+		// Участник организации видит проверки других ее участников, а не всех авторов с доменом,
+		// совпадающим с идентификатором организации
+		if principal, _ := auth.PrincipalFromContext(ctx); principal.Organization != "" {
+			organization := principal.Organization
+			filter.Organization = &organization
+			return nil
+		}
+		tenant := messaging.TenantOf(email)
+		filter.Tenant = &tenant
+		return nil
+	}
+	return invalidInputf("invalid scope: %s", target)
+}
+
+// toRepositoryFilter проверяет фильтр из API и разбирает границы периода
+func toRepositoryFilter(filter *model.VerificationFilter) (repository.VerificationFilter, error) {
+	var result repository.VerificationFilter
+	if filter == nil {
+		return result, nil
+	}
+
+	if filter.Status != nil && !filter.Status.IsValid() {
+		return result, invalidInputf("invalid status: %s", *filter.Status)
+	}
+	if filter.ReviewState != nil && !filter.ReviewState.IsValid() {
+		return result, invalidInputf("invalid review state: %s", *filter.ReviewState)
+	}
+
+	if filter.Status != nil {
+		status := catalog.DefaultDeprecations().CanonicalStatus(*filter.Status)
+		result.Status = &status
+	}
+	result.INN = filter.Inn
+	result.AuthorEmail = filter.AuthorEmail
+	result.ExternalSystem = filter.ExternalSystem
+	result.ExternalRef = filter.ExternalRef
+	result.Assignee = filter.Assignee
+	result.ReviewState = filter.ReviewState
+	metadataFilter, err := metadataFromInput(filter.Metadata)
+	if err != nil {
+		return result, err
+	}
+	result.Metadata = metadataFilter
+
+	timeZone, err := resolveTimeZone(filter.Timezone)
+	if err != nil {
+		return result, err
 	}
+	result.TimeZone = timeZone
 
-	return s.repo.GetAll(ctx, limit, offset)
+	if filter.CreatedFrom != nil {
+		createdFrom, isDate, err := parseDateBound("createdFrom", *filter.CreatedFrom)
+		if err != nil {
+			return result, err
+		}
+		if isDate {
+			result.CreatedFromDay = &createdFrom
+		} else {
+			result.CreatedFrom = &createdFrom
+		}
+	}
+
+	if filter.CreatedTo != nil {
+		createdTo, isDate, err := parseDateBound("createdTo", *filter.CreatedTo)
+		if err != nil {
+			return result, err
+		}
+		if isDate {
+			result.CreatedToDay = &createdTo
+		} else {
+			result.CreatedTo = &createdTo
+		}
+	}
+
+	return result, nil
 }
 
-func (s *verificationService) GetVerificationWithData(ctx context.Context, id string) (*model.VerificationDataResult, error) {
+func (s *verificationService) GetVerificationWithData(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.VerificationDataResult, error) {
 	if id == "" {
-		return nil, fmt.Errorf("verification id cannot be empty")
+		return nil, invalidInputf("verification id cannot be empty")
 	}
 
-	verification, err := s.repo.GetByID(ctx, id)
+	if err := auth.CheckOperation(ctx, auth.OperationRead); err != nil {
+		return nil, err
+	}
+
+	// Данные читаются из кэша только для выбранных в запросе типов
+	verification, err := s.repo.GetByIDWithData(ctx, id, dataTypes)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
 	if err != nil {
 		s.logger.Error("failed to get verification from repository", zap.Error(err), zap.String("id", id))
 		return nil, fmt.Errorf("failed to get verification: %w", err)
 	}
 
-	if verification == nil {
-		return nil, fmt.Errorf("verification not found: %s", id)
-	}
-
-	// Создаем результат и маппим данные по типам
+	// Создаем результат и маппим данные по типам, уже без типов, не разрешенных ключом сервисного аккаунта
+	verification = restrictData(ctx, verification)
 	result := &model.VerificationDataResult{
 		Verification: verification,
 	}
 
-	// Маппим данные по типам
 	for _, data := range verification.Data {
 		switch data.DataType {
 		case model.VerificationDataTypeBasicInformation:
@@ -136,3 +632,144 @@ func (s *verificationService) GetVerificationWithData(ctx context.Context, id st
 
 	return result, nil
 }
+
+// UpdateRiskLevel сохраняет уровень риска компании из результата скоринга
+func (s *verificationService) UpdateRiskLevel(ctx context.Context, id string, riskLevel model.RiskLevel) error {
+	if id == "" {
+		return invalidInputf("verification id cannot be empty")
+	}
+
+	if !riskLevel.IsValid() {
+		return invalidInputf("invalid risk level: %s", riskLevel)
+	}
+
+	if err := s.repo.UpdateRiskLevel(ctx, id, riskLevel); err != nil {
+		s.logger.Error("failed to update risk level", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to update risk level: %w", err)
+	}
+
+	return nil
+}
+
+// ReconcileDeliveredData сравнивает запрошенные типы данных с доставленными и сохраняет недостающие.
+// Возвращает список типов, которые поставщики не доставили.
+func (s *verificationService) ReconcileDeliveredData(ctx context.Context, id string) ([]model.VerificationDataType, error) {
+	verification, err := s.GetVerification(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := missingDataTypes(verification)
+	if len(missing) == 0 && len(verification.MissingDataTypes) == 0 {
+		return nil, nil
+	}
+
+	if err := s.repo.SetMissingDataTypes(ctx, id, missing); err != nil {
+		s.logger.Error("failed to save missing data types", zap.Error(err), zap.String("id", id))
+		return nil, fmt.Errorf("failed to save missing data types: %w", err)
+	}
+
+	if len(missing) > 0 {
+		s.logger.Warn("verification completed without some requested data",
+			zap.String("id", id),
+			zap.Any("missing_data_types", missing))
+	}
+	return missing, nil
+}
+
+// RetryMissingData повторно запрашивает у поставщиков только недоставленные типы данных
+func (s *verificationService) RetryMissingData(ctx context.Context, verification *model.Verification) error {
+	if len(verification.MissingDataTypes) == 0 {
+		return nil
+	}
+
+	retry := &domain.Verification{
+		ID:                 verification.ID,
+		INN:                verification.Inn,
+		Status:             domain.StatusInProcess,
+		AuthorEmail:        verification.AuthorEmail,
+		RequestedDataTypes: model.DataTypesToDomain(verification.MissingDataTypes),
+	}
+
+	if err := s.nats.PublishVerificationRequestWithPriority(ctx, retry, messaging.PriorityNormal); err != nil {
+		s.logger.Error("failed to publish missing data retry", zap.Error(err), zap.String("id", verification.ID))
+		return fmt.Errorf("failed to publish missing data retry: %w", err)
+	}
+
+	if err := s.repo.MarkMissingDataRetried(ctx, verification.ID); err != nil {
+		s.logger.Error("failed to mark missing data retried", zap.Error(err), zap.String("id", verification.ID))
+		return fmt.Errorf("failed to mark missing data retried: %w", err)
+	}
+
+	s.logger.Info("missing data requested again",
+		zap.String("id", verification.ID),
+		zap.Any("data_types", verification.MissingDataTypes))
+	return nil
+}
+
+// UpdateMetadata меняет метаданные в транзакции, поэтому одновременные изменения разных ключей
+// не теряют друг друга
+func (s *verificationService) UpdateMetadata(ctx context.Context, id string, set []*model.MetadataEntryInput, remove []string) (*model.Verification, error) {
+	if id == "" {
+		return nil, invalidInputf("verification id cannot be empty")
+	}
+
+	changes, err := metadataFromInput(set)
+	if err != nil {
+		return nil, err
+	}
+	// Ключи и значения проверяются до транзакции, общие пределы - вместе с текущими метаданными
+	if err := metadata.Validate(changes); err != nil {
+		return nil, invalidInput(err)
+	}
+	for _, key := range remove {
+		if _, ok := changes[key]; ok {
+			return nil, invalidInputf("metadata key %q cannot be both set and removed", key)
+		}
+	}
+
+	if err := auth.CheckOperation(ctx, auth.OperationCreate); err != nil {
+		return nil, err
+	}
+	email, err := auth.RequireEmail(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	verification, err := s.GetVerification(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if principal, _ := auth.PrincipalFromContext(ctx); verification.AuthorEmail != email && !principal.HasRole(auth.RoleAdmin) {
+		return nil, fmt.Errorf("%w: only the author or %s can change verification metadata", auth.ErrForbidden, auth.RoleAdmin)
+	}
+
+	updated, err := s.repo.UpdateMetadata(ctx, id, func(current map[string]string) (map[string]string, error) {
+		merged := metadata.Merge(current, changes, remove)
+		return merged, invalidInput(metadata.Validate(merged))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	verification.Metadata = model.MetadataFromDomain(updated)
+	s.logger.Info("verification metadata updated", zap.String("id", id), zap.String("by", email),
+		zap.Int("set", len(changes)), zap.Int("removed", len(remove)))
+	return verification, nil
+}
+
+// missingDataTypes возвращает запрошенные типы, по которым нет данных
+func missingDataTypes(verification *model.Verification) []model.VerificationDataType {
+	delivered := make(map[model.VerificationDataType]bool, len(verification.Data))
+	for _, data := range verification.Data {
+		delivered[data.DataType] = true
+	}
+
+	var missing []model.VerificationDataType
+	for _, dataType := range verification.RequestedDataTypes {
+		if !delivered[dataType] {
+			missing = append(missing, dataType)
+		}
+	}
+	return missing
+}
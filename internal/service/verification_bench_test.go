@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/allocbudget"
+
+	"go.uber.org/zap"
+)
+
+// Бюджеты выделений памяти горячего пути. Если изменение осознанно их превышает,
+// бюджет поднимается в том же изменении с объяснением в описании.
+const (
+	createVerificationAllocs      = 8
+	getVerificationWithDataAllocs = 16
+)
+
+var benchRequestedTypes = []model.VerificationDataType{
+	model.VerificationDataTypeBasicInformation,
+	model.VerificationDataTypeActivities,
+	model.VerificationDataTypeArbitrageStatistics,
+}
+
+func benchVerificationWithData(ctx context.Context, id string) (*model.Verification, error) {
+	verification := &model.Verification{ID: id, Inn: "7707083893", Status: model.VerificationStatusCompleted}
+	for _, dataType := range model.AllVerificationDataType {
+		verification.Data = append(verification.Data, &model.VerificationData{DataType: dataType, Data: `{"name": "ПАО Сбербанк"}`})
+	}
+	return verification, nil
+}
+
+func newBenchVerificationService() VerificationService {
+	repo := &mockVerificationRepository{getByIDFunc: benchVerificationWithData}
+	return NewVerificationService(repo, &mockNATSClient{}, nil, zap.NewNop())
+}
+
+func BenchmarkCreateVerification(b *testing.B) {
+	service := newBenchVerificationService()
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if _, err := service.CreateVerification(ctx, "7707083893", benchRequestedTypes, "analyst@example.com"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetVerificationWithData(b *testing.B) {
+	service := newBenchVerificationService()
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if _, err := service.GetVerificationWithData(ctx, "v-1", nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestCreateVerificationAllocBudget(t *testing.T) {
+	service := newBenchVerificationService()
+	allocbudget.Check(t, createVerificationAllocs, func() {
+		if _, err := service.CreateVerification(context.Background(), "7707083893", benchRequestedTypes, "analyst@example.com"); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// Бюджет учитывает и сборку проверки в mock-репозитории, поэтому сравнивать его нужно только с самим собой
+func TestGetVerificationWithDataAllocBudget(t *testing.T) {
+	service := newBenchVerificationService()
+	allocbudget.Check(t, getVerificationWithDataAllocs, func() {
+		if _, err := service.GetVerificationWithData(context.Background(), "v-1", nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -3,54 +3,270 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/catalog"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/repository"
 
 	"go.uber.org/zap/zaptest"
 )
 
 // Mock для VerificationRepository
 type mockVerificationRepository struct {
-	getByIDFunc func(ctx context.Context, id string) (*model.Verification, error)
-	getAllFunc  func(ctx context.Context, limit *int32, offset *int32) ([]*model.Verification, error)
+	createFunc          func(ctx context.Context, verification *domain.Verification) error
+	getByIDFunc         func(ctx context.Context, id string) (*model.Verification, error)
+	getAllFunc          func(ctx context.Context, filter repository.VerificationFilter, limit *int32, offset *int32) ([]*model.Verification, error)
+	updateRiskLevelFunc func(ctx context.Context, id string, riskLevel model.RiskLevel) error
+	updateStatusFunc    func(ctx context.Context, id string, status model.VerificationStatus, errorMessage *string, changedAt time.Time) error
+	getPreviousFunc     func(ctx context.Context, id string) (*model.Verification, error)
+	getAuthorsByINNFunc func(ctx context.Context, inn string) ([]string, error)
+	setMissingFunc      func(ctx context.Context, id string, missing []model.VerificationDataType) error
+	markRetriedFunc     func(ctx context.Context, id string) error
+	setExternalRefFunc  func(ctx context.Context, id string, ref *model.ExternalRef) error
+	getIDByExternalFunc func(ctx context.Context, system *string, ref string) (string, error)
+	setValidationFunc   func(ctx context.Context, id string, dataType model.VerificationDataType, validationErrors []string) error
+	getSnapshotFunc     func(ctx context.Context, inn string, asOf time.Time) (*model.Verification, error)
+	getStatusesFunc     func(ctx context.Context, inns []string) ([]*model.CompanyVerificationStatus, error)
+	getLatestSinceFunc  func(ctx context.Context, inn string, since time.Time) (*model.RecentVerification, error)
+	setMetadataFunc     func(ctx context.Context, id string, metadata map[string]string) error
+	updateMetadataFunc  func(ctx context.Context, id string, update func(current map[string]string) (map[string]string, error)) (map[string]string, error)
+}
+
+func (m *mockVerificationRepository) Create(ctx context.Context, verification *domain.Verification) error {
+	if m.createFunc != nil {
+		return m.createFunc(ctx, verification)
+	}
+	return nil
+}
+
+func (m *mockVerificationRepository) CreateWithRequest(ctx context.Context, verification *domain.Verification, msg *messaging.OutboxMessage, claimedUntil time.Time) (bool, error) {
+	if verification != nil {
+		if err := m.Create(ctx, verification); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
 }
 
 func (m *mockVerificationRepository) GetByID(ctx context.Context, id string) (*model.Verification, error) {
 	if m.getByIDFunc != nil {
 		return m.getByIDFunc(ctx, id)
 	}
-	return nil, nil
+	return nil, errNotFound("verification not found: %s", id)
 }
 
-func (m *mockVerificationRepository) GetAll(ctx context.Context, limit *int32, offset *int32) ([]*model.Verification, error) {
+func (m *mockVerificationRepository) GetByIDWithData(ctx context.Context, id string, dataTypes []model.VerificationDataType) (*model.Verification, error) {
+	verification, err := m.GetByID(ctx, id)
+	if err != nil || verification == nil || dataTypes == nil {
+		return verification, err
+	}
+
+	filtered := *verification
+	filtered.Data = nil
+	for _, data := range verification.Data {
+		if slices.Contains(dataTypes, data.DataType) {
+			filtered.Data = append(filtered.Data, data)
+		}
+	}
+	return &filtered, nil
+}
+
+func (m *mockVerificationRepository) GetAll(ctx context.Context, filter repository.VerificationFilter, limit *int32, offset *int32) ([]*model.Verification, error) {
 	if m.getAllFunc != nil {
-		return m.getAllFunc(ctx, limit, offset)
+		return m.getAllFunc(ctx, filter, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *mockVerificationRepository) StreamAll(ctx context.Context, filter repository.VerificationFilter, limit *int32, offset *int32, fn func(*model.Verification) error) error {
+	verifications, err := m.GetAll(ctx, filter, limit, offset)
+	if err != nil {
+		return err
+	}
+	for _, v := range verifications {
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockVerificationRepository) GetPrevious(ctx context.Context, id string) (*model.Verification, error) {
+	if m.getPreviousFunc != nil {
+		return m.getPreviousFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *mockVerificationRepository) GetAuthorsByINN(ctx context.Context, inn string) ([]string, error) {
+	if m.getAuthorsByINNFunc != nil {
+		return m.getAuthorsByINNFunc(ctx, inn)
+	}
+	return nil, nil
+}
+
+func (m *mockVerificationRepository) UpdateRiskLevel(ctx context.Context, id string, riskLevel model.RiskLevel) error {
+	if m.updateRiskLevelFunc != nil {
+		return m.updateRiskLevelFunc(ctx, id, riskLevel)
+	}
+	return nil
+}
+
+func (m *mockVerificationRepository) UpdateStatus(ctx context.Context, id string, status model.VerificationStatus, errorMessage *string, changedAt time.Time) error {
+	if m.updateStatusFunc != nil {
+		return m.updateStatusFunc(ctx, id, status, errorMessage, changedAt)
+	}
+	return nil
+}
+
+func (m *mockVerificationRepository) GetMonitoringCandidates(ctx context.Context, olderThan time.Time, limit int) ([]*repository.MonitoringCandidate, error) {
+	return nil, nil
+}
+
+func (m *mockVerificationRepository) SetMissingDataTypes(ctx context.Context, id string, missing []model.VerificationDataType) error {
+	if m.setMissingFunc != nil {
+		return m.setMissingFunc(ctx, id, missing)
+	}
+	return nil
+}
+
+func (m *mockVerificationRepository) GetMissingDataRetryCandidates(ctx context.Context, updatedBefore time.Time, maxRetries int, limit int) ([]*model.Verification, error) {
+	return nil, nil
+}
+
+func (m *mockVerificationRepository) MarkMissingDataRetried(ctx context.Context, id string) error {
+	if m.markRetriedFunc != nil {
+		return m.markRetriedFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *mockVerificationRepository) SetDataValidation(ctx context.Context, id string, dataType model.VerificationDataType, validationErrors []string) error {
+	if m.setValidationFunc != nil {
+		return m.setValidationFunc(ctx, id, dataType, validationErrors)
+	}
+	return nil
+}
+
+func (m *mockVerificationRepository) SetExternalRef(ctx context.Context, id string, ref *model.ExternalRef) error {
+	if m.setExternalRefFunc != nil {
+		return m.setExternalRefFunc(ctx, id, ref)
+	}
+	return nil
+}
+
+func (m *mockVerificationRepository) SetMetadata(ctx context.Context, id string, metadata map[string]string) error {
+	if m.setMetadataFunc != nil {
+		return m.setMetadataFunc(ctx, id, metadata)
+	}
+	return nil
+}
+
+func (m *mockVerificationRepository) UpdateMetadata(ctx context.Context, id string, update func(current map[string]string) (map[string]string, error)) (map[string]string, error) {
+	if m.updateMetadataFunc != nil {
+		return m.updateMetadataFunc(ctx, id, update)
+	}
+	return update(map[string]string{})
+}
+
+func (m *mockVerificationRepository) GetIDByExternalRef(ctx context.Context, system *string, ref string) (string, error) {
+	if m.getIDByExternalFunc != nil {
+		return m.getIDByExternalFunc(ctx, system, ref)
+	}
+	return "", errNotFound("verification not found by external ref: %s", ref)
+}
+
+func (m *mockVerificationRepository) GetSnapshot(ctx context.Context, inn string, asOf time.Time) (*model.Verification, error) {
+	if m.getSnapshotFunc != nil {
+		return m.getSnapshotFunc(ctx, inn, asOf)
+	}
+	return nil, nil
+}
+
+func (m *mockVerificationRepository) GetLatestStatuses(ctx context.Context, inns []string) ([]*model.CompanyVerificationStatus, error) {
+	if m.getStatusesFunc != nil {
+		return m.getStatusesFunc(ctx, inns)
 	}
 	return nil, nil
 }
 
+func (m *mockVerificationRepository) GetLatestSince(ctx context.Context, inn string, since time.Time) (*model.RecentVerification, error) {
+	if m.getLatestSinceFunc != nil {
+		return m.getLatestSinceFunc(ctx, inn, since)
+	}
+	return &model.RecentVerification{}, nil
+}
+
 // Mock для NATSClient
 type mockNATSClient struct {
-	publishVerificationRequestFunc   func(ctx context.Context, verification *model.Verification) error
-	subscribeToVerificationCompleted func(ctx context.Context, handler func(*model.Verification)) error
+	publishVerificationRequestFunc   func(ctx context.Context, verification *domain.Verification) error
+	publishWithPriorityFunc          func(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error
+	subscribeToVerificationCompleted func(ctx context.Context, handler func(*domain.Verification)) error
+	publishRescoreFunc               func(ctx context.Context, request *messaging.RescoreVerificationMessage) error
+	publishAmendedFunc               func(ctx context.Context, amended *messaging.VerificationAmendedMessage) error
+	publishCompletedFunc             func(ctx context.Context, completed *messaging.VerificationCompletedMessage) error
 	closeFunc                        func()
 }
 
-func (m *mockNATSClient) PublishVerificationRequest(ctx context.Context, verification *model.Verification) error {
+func (m *mockNATSClient) PublishVerificationRequest(ctx context.Context, verification *domain.Verification) error {
 	if m.publishVerificationRequestFunc != nil {
 		return m.publishVerificationRequestFunc(ctx, verification)
 	}
 	return nil
 }
 
-func (m *mockNATSClient) SubscribeToVerificationCompleted(ctx context.Context, handler func(*model.Verification)) error {
+func (m *mockNATSClient) PublishVerificationRequestWithPriority(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+	if m.publishWithPriorityFunc != nil {
+		return m.publishWithPriorityFunc(ctx, verification, priority)
+	}
+	return m.PublishVerificationRequest(ctx, verification)
+}
+
+func (m *mockNATSClient) SubscribeToVerificationCompleted(ctx context.Context, handler func(*domain.Verification)) error {
 	if m.subscribeToVerificationCompleted != nil {
 		return m.subscribeToVerificationCompleted(ctx, handler)
 	}
 	return nil
 }
 
+func (m *mockNATSClient) PublishRescoreRequest(ctx context.Context, request *messaging.RescoreVerificationMessage) error {
+	if m.publishRescoreFunc != nil {
+		return m.publishRescoreFunc(ctx, request)
+	}
+	return nil
+}
+
+func (m *mockNATSClient) SubscribeToVerificationRescored(ctx context.Context, handler func(*messaging.VerificationRescoredMessage)) error {
+	return nil
+}
+
+func (m *mockNATSClient) PublishVerificationAmended(ctx context.Context, amended *messaging.VerificationAmendedMessage) error {
+	if m.publishAmendedFunc != nil {
+		return m.publishAmendedFunc(ctx, amended)
+	}
+	return nil
+}
+
+func (m *mockNATSClient) PublishVerificationCompleted(ctx context.Context, completed *messaging.VerificationCompletedMessage) error {
+	if m.publishCompletedFunc != nil {
+		return m.publishCompletedFunc(ctx, completed)
+	}
+	return nil
+}
+
+func (m *mockNATSClient) Status() messaging.ConnectionStatus {
+	return messaging.ConnectionStatus{Connected: true}
+}
+
 func (m *mockNATSClient) Close() {
 	if m.closeFunc != nil {
 		m.closeFunc()
@@ -65,6 +281,10 @@ func TestCreateVerification(t *testing.T) {
 		authorEmail    string
 		publishError   error
 		expectedError  string
+		// expectedInvalid - ошибка проверки аргументов, errors.Is(err, ErrInvalidInput)
+		expectedInvalid bool
+		// expectedFailed - проверка, запрос которой не опубликован, завершена ошибкой
+		expectedFailed bool
 	}{
 		{
 			name:           "successful_creation",
@@ -75,28 +295,31 @@ func TestCreateVerification(t *testing.T) {
 			expectedError:  "",
 		},
 		{
-			name:           "empty_inn",
-			inn:            "",
-			requestedTypes: []model.VerificationDataType{model.VerificationDataTypeBasicInformation},
-			authorEmail:    "test@example.com",
-			publishError:   nil,
-			expectedError:  "inn cannot be empty",
+			name:            "empty_inn",
+			inn:             "",
+			requestedTypes:  []model.VerificationDataType{model.VerificationDataTypeBasicInformation},
+			authorEmail:     "test@example.com",
+			publishError:    nil,
+			expectedError:   "inn cannot be empty",
+			expectedInvalid: true,
 		},
 		{
-			name:           "invalid_inn_length_short",
-			inn:            "123",
-			requestedTypes: []model.VerificationDataType{model.VerificationDataTypeBasicInformation},
-			authorEmail:    "test@example.com",
-			publishError:   nil,
-			expectedError:  "inn must be 10 or 12 digits, got 3",
+			name:            "invalid_inn_length_short",
+			inn:             "123",
+			requestedTypes:  []model.VerificationDataType{model.VerificationDataTypeBasicInformation},
+			authorEmail:     "test@example.com",
+			publishError:    nil,
+			expectedError:   "inn must be 10 or 12 digits, got 3",
+			expectedInvalid: true,
 		},
 		{
-			name:           "invalid_inn_length_long",
-			inn:            "12345678901234",
-			requestedTypes: []model.VerificationDataType{model.VerificationDataTypeBasicInformation},
-			authorEmail:    "test@example.com",
-			publishError:   nil,
-			expectedError:  "inn must be 10 or 12 digits, got 14",
+			name:            "invalid_inn_length_long",
+			inn:             "12345678901234",
+			requestedTypes:  []model.VerificationDataType{model.VerificationDataTypeBasicInformation},
+			authorEmail:     "test@example.com",
+			publishError:    nil,
+			expectedError:   "inn must be 10 or 12 digits, got 14",
+			expectedInvalid: true,
 		},
 		{
 			name:           "valid_inn_12_digits",
@@ -107,12 +330,13 @@ func TestCreateVerification(t *testing.T) {
 			expectedError:  "",
 		},
 		{
-			name:           "empty_requested_types",
-			inn:            "1234567890",
-			requestedTypes: []model.VerificationDataType{},
-			authorEmail:    "test@example.com",
-			publishError:   nil,
-			expectedError:  "at least one data type must be requested",
+			name:            "empty_requested_types",
+			inn:             "1234567890",
+			requestedTypes:  []model.VerificationDataType{},
+			authorEmail:     "test@example.com",
+			publishError:    nil,
+			expectedError:   "at least one data type must be requested",
+			expectedInvalid: true,
 		},
 		{
 			name:           "nats_publish_error",
@@ -121,22 +345,34 @@ func TestCreateVerification(t *testing.T) {
 			authorEmail:    "test@example.com",
 			publishError:   errors.New("nats connection failed"),
 			expectedError:  "failed to publish verification request",
+			expectedFailed: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockRepo := &mockVerificationRepository{}
+			var failed []string
+			mockRepo := &mockVerificationRepository{
+				updateStatusFunc: func(ctx context.Context, id string, status model.VerificationStatus, errorMessage *string, changedAt time.Time) error {
+					if status == model.VerificationStatusError && errorMessage != nil {
+						failed = append(failed, id)
+					}
+					return nil
+				},
+			}
 			mockNATS := &mockNATSClient{
-				publishVerificationRequestFunc: func(ctx context.Context, verification *model.Verification) error {
+				publishVerificationRequestFunc: func(ctx context.Context, verification *domain.Verification) error {
 					return tt.publishError
 				},
 			}
 			logger := zaptest.NewLogger(t)
 
-			service := NewVerificationService(mockRepo, mockNATS, logger)
+			service := NewVerificationService(mockRepo, mockNATS, nil, logger)
 
 			verification, err := service.CreateVerification(context.Background(), tt.inn, tt.requestedTypes, tt.authorEmail)
+			if (len(failed) == 1) != tt.expectedFailed {
+				t.Errorf("expected unpublished verification marked failed: %v, got %v", tt.expectedFailed, failed)
+			}
 
 			if tt.expectedError != "" {
 				if err == nil {
@@ -146,6 +382,9 @@ func TestCreateVerification(t *testing.T) {
 				if !containsError(err.Error(), tt.expectedError) {
 					t.Errorf("expected error containing '%s', but got '%s'", tt.expectedError, err.Error())
 				}
+				if invalid := errors.Is(err, ErrInvalidInput); invalid != tt.expectedInvalid {
+					t.Errorf("expected invalid input: %v, got %v", tt.expectedInvalid, invalid)
+				}
 				return
 			}
 
@@ -208,7 +447,7 @@ func TestGetVerification(t *testing.T) {
 			name:          "verification_not_found",
 			id:            "non-existent-id",
 			repoResult:    nil,
-			repoError:     nil,
+			repoError:     errNotFound("verification not found: non-existent-id"),
 			expectedError: "verification not found: non-existent-id",
 		},
 		{
@@ -230,7 +469,7 @@ func TestGetVerification(t *testing.T) {
 			mockNATS := &mockNATSClient{}
 			logger := zaptest.NewLogger(t)
 
-			service := NewVerificationService(mockRepo, mockNATS, logger)
+			service := NewVerificationService(mockRepo, mockNATS, nil, logger)
 
 			verification, err := service.GetVerification(context.Background(), tt.id)
 
@@ -263,13 +502,25 @@ func TestGetVerification(t *testing.T) {
 }
 
 func TestGetAllVerifications(t *testing.T) {
+	analyst := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@Bank.ru"})
+	member := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "analyst@bank.ru", Organization: "holding.ru"})
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "root@scoring.local", Roles: []string{auth.RoleAdmin}})
+	scope := func(s model.VerificationScope) *model.VerificationScope { return &s }
+
 	tests := []struct {
-		name          string
-		limit         *int32
-		offset        *int32
-		repoResult    []*model.Verification
-		repoError     error
-		expectedError string
+		name           string
+		ctx            context.Context
+		filter         *model.VerificationFilter
+		scope          *model.VerificationScope
+		expectedOwner  string
+		expectedTenant string
+		// expectedOrganization отбор по участникам организации
+		expectedOrganization string
+		limit                *int32
+		offset               *int32
+		repoResult           []*model.Verification
+		repoError            error
+		expectedError        string
 	}{
 		{
 			name:   "successful_get_all",
@@ -281,6 +532,7 @@ func TestGetAllVerifications(t *testing.T) {
 			},
 			repoError:     nil,
 			expectedError: "",
+			expectedOwner: "analyst@Bank.ru",
 		},
 		{
 			name:          "negative_limit",
@@ -299,27 +551,94 @@ func TestGetAllVerifications(t *testing.T) {
 			expectedError: "offset must be non-negative, got -5",
 		},
 		{
-			name:       "nil_limit_and_offset",
-			limit:      nil,
-			offset:     nil,
-			repoResult: []*model.Verification{},
-			repoError:  nil,
+			name:          "nil_limit_and_offset",
+			limit:         nil,
+			offset:        nil,
+			repoResult:    []*model.Verification{},
+			repoError:     nil,
+			expectedOwner: "analyst@Bank.ru",
+		},
+		{
+			name: "valid_filter",
+			filter: &model.VerificationFilter{
+				Inn:         stringPtr("1234567890"),
+				CreatedFrom: stringPtr("2024-01-01T00:00:00Z"),
+			},
+			repoResult:    []*model.Verification{{ID: "1", Inn: "1234567890"}},
+			expectedOwner: "analyst@Bank.ru",
+		},
+		{
+			name: "invalid_filter_date",
+			filter: &model.VerificationFilter{
+				CreatedTo: stringPtr("yesterday"),
+			},
+			expectedError: "createdTo must be RFC3339 timestamp",
+		},
+		{
+			name: "invalid_filter_status",
+			filter: &model.VerificationFilter{
+				Status: statusPtr(model.VerificationStatus("UNKNOWN")),
+			},
+			expectedError: "invalid status: UNKNOWN",
+		},
+		{
+			name: "invalid_filter_timezone",
+			filter: &model.VerificationFilter{
+				CreatedFrom: stringPtr("2024-01-01"),
+				Timezone:    stringPtr("Moscow"),
+			},
+			expectedError: "unknown timezone",
+		},
+		{
+			name:           "my_org",
+			scope:          scope(model.VerificationScopeMyOrg),
+			expectedTenant: "bank.ru",
+			repoResult:     []*model.Verification{{ID: "1"}},
+		},
+		{
+			name:                 "my_org_member",
+			ctx:                  member,
+			scope:                scope(model.VerificationScopeMyOrg),
+			expectedOrganization: "holding.ru",
+			repoResult:           []*model.Verification{{ID: "1"}},
+		},
+		{
+			name:       "all_admin",
+			ctx:        admin,
+			scope:      scope(model.VerificationScopeAll),
+			repoResult: []*model.Verification{{ID: "1"}, {ID: "2"}},
+		},
+		{
+			name:          "all_requires_admin",
+			scope:         scope(model.VerificationScopeAll),
+			expectedError: "access denied: role admin required",
+		},
+		{
+			name:          "anonymous",
+			ctx:           context.Background(),
+			expectedError: "unauthenticated",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			var scoped repository.VerificationFilter
 			mockRepo := &mockVerificationRepository{
-				getAllFunc: func(ctx context.Context, limit *int32, offset *int32) ([]*model.Verification, error) {
+				getAllFunc: func(ctx context.Context, filter repository.VerificationFilter, limit *int32, offset *int32) ([]*model.Verification, error) {
+					scoped = filter
 					return tt.repoResult, tt.repoError
 				},
 			}
 			mockNATS := &mockNATSClient{}
 			logger := zaptest.NewLogger(t)
 
-			service := NewVerificationService(mockRepo, mockNATS, logger)
+			service := NewVerificationService(mockRepo, mockNATS, nil, logger)
 
-			verifications, err := service.GetAllVerifications(context.Background(), tt.limit, tt.offset)
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = analyst
+			}
+			verifications, err := service.GetAllVerifications(ctx, tt.filter, tt.scope, tt.limit, tt.offset)
 
 			if tt.expectedError != "" {
 				if err == nil {
@@ -340,10 +659,56 @@ func TestGetAllVerifications(t *testing.T) {
 			if len(verifications) != len(tt.repoResult) {
 				t.Errorf("expected %d verifications, but got %d", len(tt.repoResult), len(verifications))
 			}
+			var owner, tenant, organization string
+			if scoped.Owner != nil {
+				owner = *scoped.Owner
+			}
+			if scoped.Tenant != nil {
+				tenant = *scoped.Tenant
+			}
+			if scoped.Organization != nil {
+				organization = *scoped.Organization
+			}
+			if owner != tt.expectedOwner || tenant != tt.expectedTenant || organization != tt.expectedOrganization {
+				t.Errorf("expected owner %q, tenant %q and organization %q, but got %q, %q and %q",
+					tt.expectedOwner, tt.expectedTenant, tt.expectedOrganization, owner, tenant, organization)
+			}
 		})
 	}
 }
 
+func TestToRepositoryFilterDates(t *testing.T) {
+	filter, err := toRepositoryFilter(&model.VerificationFilter{
+		CreatedFrom: stringPtr("2024-03-31"),
+		CreatedTo:   stringPtr("2024-04-01T00:00:00Z"),
+		Timezone:    stringPtr("Europe/Moscow"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if filter.TimeZone != "Europe/Moscow" {
+		t.Errorf("expected timezone Europe/Moscow, but got %q", filter.TimeZone)
+	}
+	if filter.CreatedFromDay == nil || filter.CreatedFromDay.Format(time.DateOnly) != "2024-03-31" {
+		t.Errorf("expected createdFrom to be a day bound, but got %v", filter.CreatedFromDay)
+	}
+	if filter.CreatedFrom != nil {
+		t.Errorf("expected no timestamp lower bound, but got %v", filter.CreatedFrom)
+	}
+	if filter.CreatedTo == nil || !filter.CreatedTo.Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected createdTo timestamp bound, but got %v", filter.CreatedTo)
+	}
+
+	filter, err = toRepositoryFilter(&model.VerificationFilter{CreatedTo: stringPtr("2024-03-31")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filter.TimeZone != "UTC" || filter.CreatedToDay == nil {
+		t.Errorf("expected UTC day bound, but got timezone %q and day %v", filter.TimeZone, filter.CreatedToDay)
+	}
+}
+
 func TestGetVerificationWithData(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -392,9 +757,9 @@ func TestGetVerificationWithData(t *testing.T) {
 			mockNATS := &mockNATSClient{}
 			logger := zaptest.NewLogger(t)
 
-			service := NewVerificationService(mockRepo, mockNATS, logger)
+			service := NewVerificationService(mockRepo, mockNATS, nil, logger)
 
-			result, err := service.GetVerificationWithData(context.Background(), tt.id)
+			result, err := service.GetVerificationWithData(context.Background(), tt.id, nil)
 
 			if tt.expectedError != "" {
 				if err == nil {
@@ -440,9 +805,960 @@ func TestGetVerificationWithData(t *testing.T) {
 	}
 }
 
-// Вспомогательная функция для создания указателя на int32
-func int32Ptr(i int32) *int32 {
-	return &i
+func TestCreateVerificationWithPriority(t *testing.T) {
+	var publishedPriority messaging.Priority
+	mockNATS := &mockNATSClient{
+		publishWithPriorityFunc: func(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+			publishedPriority = priority
+			return nil
+		},
+	}
+	service := NewVerificationService(&mockVerificationRepository{}, mockNATS, nil, zaptest.NewLogger(t))
+
+	_, err := service.CreateVerificationWithPriority(context.Background(), "1234567890",
+		[]model.VerificationDataType{model.VerificationDataTypeBasicInformation}, "test@example.com", messaging.PriorityHigh)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if publishedPriority != messaging.PriorityHigh {
+		t.Errorf("expected priority '%s', but got '%s'", messaging.PriorityHigh, publishedPriority)
+	}
+
+	_, err = service.CreateVerification(context.Background(), "1234567890",
+		[]model.VerificationDataType{model.VerificationDataTypeBasicInformation}, "test@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if publishedPriority != messaging.PriorityNormal {
+		t.Errorf("expected priority '%s', but got '%s'", messaging.PriorityNormal, publishedPriority)
+	}
+}
+
+func TestUpdateRiskLevel(t *testing.T) {
+	tests := []struct {
+		name          string
+		id            string
+		riskLevel     model.RiskLevel
+		repoError     error
+		expectedError string
+	}{
+		{
+			name:      "successful_update",
+			id:        "test-id",
+			riskLevel: model.RiskLevelHigh,
+		},
+		{
+			name:          "empty_id",
+			id:            "",
+			riskLevel:     model.RiskLevelHigh,
+			expectedError: "verification id cannot be empty",
+		},
+		{
+			name:          "invalid_risk_level",
+			id:            "test-id",
+			riskLevel:     model.RiskLevel("EXTREME"),
+			expectedError: "invalid risk level: EXTREME",
+		},
+		{
+			name:          "repository_error",
+			id:            "test-id",
+			riskLevel:     model.RiskLevelLow,
+			repoError:     errors.New("database connection failed"),
+			expectedError: "failed to update risk level",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &mockVerificationRepository{
+				updateRiskLevelFunc: func(ctx context.Context, id string, riskLevel model.RiskLevel) error {
+					return tt.repoError
+				},
+			}
+			service := NewVerificationService(mockRepo, &mockNATSClient{}, nil, zaptest.NewLogger(t))
+
+			err := service.UpdateRiskLevel(context.Background(), tt.id, tt.riskLevel)
+
+			if tt.expectedError != "" {
+				if err == nil {
+					t.Errorf("expected error containing '%s', but got nil", tt.expectedError)
+					return
+				}
+				if !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', but got '%s'", tt.expectedError, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestAPIKeyScopeEnforcement(t *testing.T) {
+	partner := func(scope *auth.Scope) context.Context {
+		return auth.WithPrincipal(context.Background(), &auth.Principal{Email: "partner@example.com", Scope: scope})
+	}
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		requestedTypes []model.VerificationDataType
+		expectedError  string
+	}{
+		{
+			name:           "contracted_data_type",
+			ctx:            partner(&auth.Scope{Operations: []auth.Operation{auth.OperationCreate}, DataTypes: []string{"BASIC_INFORMATION"}}),
+			requestedTypes: []model.VerificationDataType{model.VerificationDataTypeBasicInformation},
+		},
+		{
+			name:           "uncontracted_data_type",
+			ctx:            partner(&auth.Scope{DataTypes: []string{"BASIC_INFORMATION"}}),
+			requestedTypes: []model.VerificationDataType{model.VerificationDataTypeBasicInformation, model.VerificationDataTypeArbitrageStatistics},
+			expectedError:  "access denied: api key does not allow data types ARBITRAGE_STATISTICS",
+		},
+		{
+			name:           "read_only_key",
+			ctx:            partner(&auth.Scope{Operations: []auth.Operation{auth.OperationRead}}),
+			requestedTypes: []model.VerificationDataType{model.VerificationDataTypeBasicInformation},
+			expectedError:  "access denied: api key does not allow create operation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var published bool
+			mockNATS := &mockNATSClient{
+				publishVerificationRequestFunc: func(ctx context.Context, verification *domain.Verification) error {
+					published = true
+					return nil
+				},
+			}
+			service := NewVerificationService(&mockVerificationRepository{}, mockNATS, nil, zaptest.NewLogger(t))
+
+			_, err := service.CreateVerification(tt.ctx, "1234567890", tt.requestedTypes, "partner@example.com")
+
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', but got %v", tt.expectedError, err)
+				}
+				if published {
+					t.Error("denied request must not be published")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	// Ключ только на создание не может читать проверки
+	createOnly := partner(&auth.Scope{Operations: []auth.Operation{auth.OperationCreate}})
+	service := NewVerificationService(&mockVerificationRepository{}, &mockNATSClient{}, nil, zaptest.NewLogger(t))
+	if _, err := service.GetVerification(createOnly, "test-id"); err == nil {
+		t.Error("expected create-only key to be denied read, but got nil")
+	}
+}
+
+func TestGetVerificationWithDataHidesUncontractedTypes(t *testing.T) {
+	mockRepo := &mockVerificationRepository{
+		getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+			return &model.Verification{
+				ID: id,
+				Data: []*model.VerificationData{
+					{DataType: model.VerificationDataTypeBasicInformation, Data: `{}`},
+					{DataType: model.VerificationDataTypeArbitrageStatistics, Data: `{}`},
+				},
+			}, nil
+		},
+	}
+	service := NewVerificationService(mockRepo, &mockNATSClient{}, nil, zaptest.NewLogger(t))
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{
+		Email: "partner@example.com",
+		Scope: &auth.Scope{DataTypes: []string{"BASIC_INFORMATION"}},
+	})
+
+	result, err := service.GetVerificationWithData(ctx, "test-id", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.BasicInformation == nil {
+		t.Error("expected basic information to be returned")
+	}
+	if result.ArbitrageStatistics != nil {
+		t.Error("expected arbitrage statistics to be hidden")
+	}
+}
+
+func TestReadsHideUncontractedTypes(t *testing.T) {
+	stored := func() *model.Verification {
+		return &model.Verification{
+			ID:  "test-id",
+			Inn: "1234567890",
+			Data: []*model.VerificationData{
+				{DataType: model.VerificationDataTypeBasicInformation, Data: `{}`},
+				{DataType: model.VerificationDataTypeArbitrageStatistics, Data: `{}`},
+			},
+			DataQuality: []*model.DataQuality{
+				{DataType: model.VerificationDataTypeArbitrageStatistics, Valid: true},
+			},
+			CustomData: []*model.GenericVerificationData{{DataType: "CREDIT_RATING", Data: `{}`}},
+		}
+	}
+	mockRepo := &mockVerificationRepository{
+		getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+			return stored(), nil
+		},
+		getAllFunc: func(ctx context.Context, filter repository.VerificationFilter, limit *int32, offset *int32) ([]*model.Verification, error) {
+			return []*model.Verification{stored()}, nil
+		},
+		getSnapshotFunc: func(ctx context.Context, inn string, asOf time.Time) (*model.Verification, error) {
+			return stored(), nil
+		},
+	}
+	service := NewVerificationService(mockRepo, &mockNATSClient{}, nil, zaptest.NewLogger(t))
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{
+		Email: "partner@example.com",
+		Scope: &auth.Scope{DataTypes: []string{"BASIC_INFORMATION"}},
+	})
+
+	tests := []struct {
+		name string
+		read func() (*model.Verification, error)
+	}{
+		{name: "verification", read: func() (*model.Verification, error) {
+			return service.GetVerification(ctx, "test-id")
+		}},
+		{name: "verification_with_data", read: func() (*model.Verification, error) {
+			result, err := service.GetVerificationWithData(ctx, "test-id", nil)
+			if err != nil {
+				return nil, err
+			}
+			return result.Verification, nil
+		}},
+		{name: "verifications", read: func() (*model.Verification, error) {
+			verifications, err := service.GetAllVerifications(ctx, nil, nil, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+			return verifications[0], nil
+		}},
+		{name: "company_snapshot", read: func() (*model.Verification, error) {
+			snapshot, err := service.GetCompanySnapshot(ctx, "1234567890", "2024-01-01")
+			if err != nil {
+				return nil, err
+			}
+			return snapshot.Verification, nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verification, err := tt.read()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(verification.Data) != 1 || verification.Data[0].DataType != model.VerificationDataTypeBasicInformation {
+				t.Errorf("expected only basic information in data, but got %v", verification.Data)
+			}
+			if len(verification.DataQuality) != 0 {
+				t.Errorf("expected data quality of hidden types to be hidden, but got %v", verification.DataQuality)
+			}
+			if len(verification.CustomData) != 0 {
+				t.Errorf("expected custom data to be hidden, but got %v", verification.CustomData)
+			}
+		})
+	}
+}
+
+func TestReconcileDeliveredData(t *testing.T) {
+	tests := []struct {
+		name            string
+		verification    *model.Verification
+		expectedMissing []model.VerificationDataType
+		expectSave      bool
+	}{
+		{
+			name: "all_delivered",
+			verification: &model.Verification{
+				ID:                 "test-id",
+				RequestedDataTypes: []model.VerificationDataType{model.VerificationDataTypeBasicInformation},
+				Data:               []*model.VerificationData{{DataType: model.VerificationDataTypeBasicInformation}},
+			},
+		},
+		{
+			name: "missing_types",
+			verification: &model.Verification{
+				ID: "test-id",
+				RequestedDataTypes: []model.VerificationDataType{
+					model.VerificationDataTypeBasicInformation,
+					model.VerificationDataTypeArbitrageStatistics,
+				},
+				Data: []*model.VerificationData{{DataType: model.VerificationDataTypeBasicInformation}},
+			},
+			expectedMissing: []model.VerificationDataType{model.VerificationDataTypeArbitrageStatistics},
+			expectSave:      true,
+		},
+		{
+			name: "retry_delivered_previously_missing",
+			verification: &model.Verification{
+				ID:                 "test-id",
+				RequestedDataTypes: []model.VerificationDataType{model.VerificationDataTypeArbitrageStatistics},
+				MissingDataTypes:   []model.VerificationDataType{model.VerificationDataTypeArbitrageStatistics},
+				Data:               []*model.VerificationData{{DataType: model.VerificationDataTypeArbitrageStatistics}},
+			},
+			expectSave: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved bool
+			mockRepo := &mockVerificationRepository{
+				getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+					return tt.verification, nil
+				},
+				setMissingFunc: func(ctx context.Context, id string, missing []model.VerificationDataType) error {
+					saved = true
+					return nil
+				},
+			}
+			service := NewVerificationService(mockRepo, &mockNATSClient{}, nil, zaptest.NewLogger(t))
+
+			missing, err := service.ReconcileDeliveredData(context.Background(), "test-id")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(missing) != len(tt.expectedMissing) {
+				t.Fatalf("expected %d missing types, but got %d", len(tt.expectedMissing), len(missing))
+			}
+			for i := range missing {
+				if missing[i] != tt.expectedMissing[i] {
+					t.Errorf("expected missing type '%s', but got '%s'", tt.expectedMissing[i], missing[i])
+				}
+			}
+			if saved != tt.expectSave {
+				t.Errorf("expected save %t, but got %t", tt.expectSave, saved)
+			}
+		})
+	}
+}
+
+func TestRetryMissingData(t *testing.T) {
+	var published *domain.Verification
+	var retried bool
+	mockRepo := &mockVerificationRepository{
+		markRetriedFunc: func(ctx context.Context, id string) error {
+			retried = true
+			return nil
+		},
+	}
+	mockNATS := &mockNATSClient{
+		publishWithPriorityFunc: func(ctx context.Context, verification *domain.Verification, priority messaging.Priority) error {
+			published = verification
+			return nil
+		},
+	}
+	service := NewVerificationService(mockRepo, mockNATS, nil, zaptest.NewLogger(t))
+
+	err := service.RetryMissingData(context.Background(), &model.Verification{
+		ID:                 "test-id",
+		Inn:                "1234567890",
+		RequestedDataTypes: []model.VerificationDataType{model.VerificationDataTypeBasicInformation, model.VerificationDataTypeArbitrageStatistics},
+		MissingDataTypes:   []model.VerificationDataType{model.VerificationDataTypeArbitrageStatistics},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if published == nil || published.ID != "test-id" {
+		t.Fatal("expected retry to be published for the same verification")
+	}
+	if len(published.RequestedDataTypes) != 1 || published.RequestedDataTypes[0] != domain.DataTypeArbitrageStatistics {
+		t.Errorf("expected only missing types to be requested, but got %v", published.RequestedDataTypes)
+	}
+	if !retried {
+		t.Error("expected retry to be recorded")
+	}
+}
+
+func TestCreateVerificationWithExternalRef(t *testing.T) {
+	tests := []struct {
+		name           string
+		externalRef    *model.ExternalRefInput
+		expectedError  string
+		expectedSystem *string
+		expectedRef    string
+	}{
+		{
+			name:           "with_system",
+			externalRef:    &model.ExternalRefInput{System: stringPtr(" crm "), Ref: " CASE-42 "},
+			expectedSystem: stringPtr("crm"),
+			expectedRef:    "CASE-42",
+		},
+		{
+			name:        "blank_system_is_dropped",
+			externalRef: &model.ExternalRefInput{System: stringPtr(""), Ref: "CASE-42"},
+			expectedRef: "CASE-42",
+		},
+		{
+			name:          "empty_ref",
+			externalRef:   &model.ExternalRefInput{Ref: "  "},
+			expectedError: "external ref cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *model.ExternalRef
+			var published *domain.Verification
+			mockRepo := &mockVerificationRepository{
+				setExternalRefFunc: func(ctx context.Context, id string, ref *model.ExternalRef) error {
+					saved = ref
+					return nil
+				},
+			}
+			mockNATS := &mockNATSClient{
+				publishVerificationRequestFunc: func(ctx context.Context, verification *domain.Verification) error {
+					published = verification
+					return nil
+				},
+			}
+			service := NewVerificationService(mockRepo, mockNATS, nil, zaptest.NewLogger(t))
+
+			_, err := service.CreateVerificationWithOptions(context.Background(), "1234567890",
+				[]model.VerificationDataType{model.VerificationDataTypeBasicInformation}, "test@example.com",
+				CreateOptions{ExternalRef: tt.externalRef})
+
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if saved == nil || saved.Ref != tt.expectedRef {
+				t.Fatalf("expected external ref '%s' to be saved, but got %+v", tt.expectedRef, saved)
+			}
+			if (saved.System == nil) != (tt.expectedSystem == nil) || (saved.System != nil && *saved.System != *tt.expectedSystem) {
+				t.Errorf("expected system %v, but got %v", tt.expectedSystem, saved.System)
+			}
+			if published == nil || published.ExternalRef == nil || published.ExternalRef.Ref != saved.Ref || published.ExternalRef.System != saved.System {
+				t.Error("expected external ref to be published with the request")
+			}
+		})
+	}
+}
+
+func TestCreateVerificationWithMetadata(t *testing.T) {
+	tests := []struct {
+		name          string
+		metadata      []*model.MetadataEntryInput
+		expectedError string
+		expectedSaved map[string]string
+	}{
+		{
+			name:          "valid",
+			metadata:      []*model.MetadataEntryInput{{Key: "branch", Value: "msk-01"}, {Key: "deal.id", Value: "42"}},
+			expectedSaved: map[string]string{"branch": "msk-01", "deal.id": "42"},
+		},
+		{
+			name: "without_metadata",
+		},
+		{
+			name:          "duplicate_key",
+			metadata:      []*model.MetadataEntryInput{{Key: "branch", Value: "a"}, {Key: "branch", Value: "b"}},
+			expectedError: "duplicate metadata key",
+		},
+		{
+			name:          "invalid_key",
+			metadata:      []*model.MetadataEntryInput{{Key: "1branch", Value: "a"}},
+			expectedError: "invalid metadata key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved map[string]string
+			mockRepo := &mockVerificationRepository{
+				setMetadataFunc: func(ctx context.Context, id string, metadata map[string]string) error {
+					saved = metadata
+					return nil
+				},
+			}
+			service := NewVerificationService(mockRepo, &mockNATSClient{}, nil, zaptest.NewLogger(t))
+
+			verification, err := service.CreateVerificationWithOptions(context.Background(), "1234567890",
+				[]model.VerificationDataType{model.VerificationDataTypeBasicInformation}, "test@example.com",
+				CreateOptions{Metadata: tt.metadata})
+
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(saved, tt.expectedSaved) {
+				t.Errorf("expected metadata %v to be saved, but got %v", tt.expectedSaved, saved)
+			}
+			if len(verification.Metadata) != len(tt.expectedSaved) {
+				t.Errorf("expected %d metadata entries, but got %d", len(tt.expectedSaved), len(verification.Metadata))
+			}
+		})
+	}
+}
+
+func TestCreateVerificationWithCustomDataTypes(t *testing.T) {
+	registry := catalog.DefaultRegistry().With([]catalog.DataType{
+		{Type: "CREDIT_RATING", Name: "Кредитный рейтинг", Custom: true, Available: true, Subject: "credit.rating"},
+		{Type: "LICENSES", Name: "Лицензии", Custom: true, Subject: "licenses"},
+	})
+
+	tests := []struct {
+		name          string
+		requested     []model.VerificationDataType
+		custom        []string
+		expectedError string
+		expected      []domain.DataType
+	}{
+		{
+			name:      "built_in_and_custom",
+			requested: []model.VerificationDataType{model.VerificationDataTypeBasicInformation},
+			custom:    []string{"CREDIT_RATING"},
+			expected:  []domain.DataType{domain.DataTypeBasicInformation, "CREDIT_RATING"},
+		},
+		{
+			name:     "only_custom",
+			custom:   []string{"CREDIT_RATING"},
+			expected: []domain.DataType{"CREDIT_RATING"},
+		},
+		{
+			name:          "unknown",
+			custom:        []string{"RATING"},
+			expectedError: `unknown custom data type "RATING"`,
+		},
+		{
+			name:          "built_in_as_custom",
+			custom:        []string{string(model.VerificationDataTypeActivities)},
+			expectedError: `unknown custom data type "ACTIVITIES"`,
+		},
+		{
+			name:          "unavailable",
+			custom:        []string{"LICENSES"},
+			expectedError: "custom data type LICENSES is not available",
+		},
+		{
+			name:          "duplicate",
+			custom:        []string{"CREDIT_RATING", "CREDIT_RATING"},
+			expectedError: "duplicate custom data type CREDIT_RATING",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var published *domain.Verification
+			mockNATS := &mockNATSClient{
+				publishVerificationRequestFunc: func(ctx context.Context, verification *domain.Verification) error {
+					published = verification
+					return nil
+				},
+			}
+			service := NewVerificationService(&mockVerificationRepository{}, mockNATS, registry, zaptest.NewLogger(t))
+
+			verification, err := service.CreateVerificationWithOptions(context.Background(), "1234567890", tt.requested,
+				"test@example.com", CreateOptions{CustomDataTypes: tt.custom})
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error '%s', but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(published.RequestedDataTypes, tt.expected) {
+				t.Errorf("expected published data types %v, but got %v", tt.expected, published.RequestedDataTypes)
+			}
+			if !reflect.DeepEqual(verification.CustomDataTypes, []string{"CREDIT_RATING"}) {
+				t.Errorf("expected custom data types in the response, but got %v", verification.CustomDataTypes)
+			}
+		})
+	}
+
+	// Без реестра заказать можно только встроенные типы
+	service := NewVerificationService(&mockVerificationRepository{}, &mockNATSClient{}, nil, zaptest.NewLogger(t))
+	if _, err := service.CreateVerificationWithOptions(context.Background(), "1234567890", nil, "test@example.com",
+		CreateOptions{CustomDataTypes: []string{"CREDIT_RATING"}}); err == nil {
+		t.Error("expected an error for a custom data type without a registry")
+	}
+}
+
+func TestUpdateMetadata(t *testing.T) {
+	author := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "author@example.com"})
+	other := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "other@example.com"})
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "admin@example.com", Roles: []string{auth.RoleAdmin}})
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		set           []*model.MetadataEntryInput
+		remove        []string
+		expectedError string
+		expected      []*model.MetadataEntry
+	}{
+		{
+			name:     "author",
+			ctx:      author,
+			set:      []*model.MetadataEntryInput{{Key: "stage", Value: "approved"}},
+			remove:   []string{"branch"},
+			expected: []*model.MetadataEntry{{Key: "deal", Value: "42"}, {Key: "stage", Value: "approved"}},
+		},
+		{
+			name:     "admin",
+			ctx:      admin,
+			remove:   []string{"deal"},
+			expected: []*model.MetadataEntry{{Key: "branch", Value: "msk-01"}},
+		},
+		{
+			name:          "other_user",
+			ctx:           other,
+			remove:        []string{"deal"},
+			expectedError: "access denied",
+		},
+		{
+			name:          "set_and_remove",
+			ctx:           author,
+			set:           []*model.MetadataEntryInput{{Key: "deal", Value: "43"}},
+			remove:        []string{"deal"},
+			expectedError: `metadata key "deal" cannot be both set and removed`,
+		},
+		{
+			name:          "too_long_value",
+			ctx:           author,
+			set:           []*model.MetadataEntryInput{{Key: "note", Value: strings.Repeat("x", 513)}},
+			expectedError: `value of metadata key "note" must not exceed 512 characters`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &mockVerificationRepository{
+				getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+					return &model.Verification{ID: id, AuthorEmail: "author@example.com"}, nil
+				},
+				updateMetadataFunc: func(ctx context.Context, id string, update func(current map[string]string) (map[string]string, error)) (map[string]string, error) {
+					return update(map[string]string{"branch": "msk-01", "deal": "42"})
+				},
+			}
+			service := NewVerificationService(mockRepo, &mockNATSClient{}, nil, zaptest.NewLogger(t))
+
+			verification, err := service.UpdateMetadata(tt.ctx, "v-1", tt.set, tt.remove)
+
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(verification.Metadata, tt.expected) {
+				t.Errorf("expected metadata %v, but got %v", tt.expected, verification.Metadata)
+			}
+		})
+	}
+}
+
+func TestGetVerificationByExternalRef(t *testing.T) {
+	mockRepo := &mockVerificationRepository{
+		getIDByExternalFunc: func(ctx context.Context, system *string, ref string) (string, error) {
+			if ref == "CASE-42" && system != nil && *system == "crm" {
+				return "test-id", nil
+			}
+			return "", errNotFound("verification not found by external ref: %s", ref)
+		},
+		getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+			return &model.Verification{ID: id}, nil
+		},
+	}
+	service := NewVerificationService(mockRepo, &mockNATSClient{}, nil, zaptest.NewLogger(t))
+
+	verification, err := service.GetVerificationByExternalRef(context.Background(), stringPtr("crm"), "CASE-42")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verification == nil || verification.ID != "test-id" {
+		t.Errorf("expected verification 'test-id', but got %v", verification)
+	}
+
+	verification, err = service.GetVerificationByExternalRef(context.Background(), nil, "CASE-42")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verification != nil {
+		t.Errorf("expected no verification for a different system, but got %v", verification)
+	}
+}
+
+func TestGetCompanySnapshot(t *testing.T) {
+	var requestedAsOf time.Time
+	mockRepo := &mockVerificationRepository{
+		getSnapshotFunc: func(ctx context.Context, inn string, asOf time.Time) (*model.Verification, error) {
+			requestedAsOf = asOf
+			if inn != "7707083893" {
+				return nil, nil
+			}
+			return &model.Verification{
+				ID:  "test-id",
+				Inn: inn,
+				Data: []*model.VerificationData{
+					{DataType: model.VerificationDataTypeBasicInformation, Data: `{"name": "Test Company"}`},
+				},
+			}, nil
+		},
+	}
+	service := NewVerificationService(mockRepo, &mockNATSClient{}, nil, zaptest.NewLogger(t))
+
+	tests := []struct {
+		name          string
+		inn           string
+		asOf          string
+		expectedAsOf  time.Time
+		expectedID    string
+		expectedError string
+	}{
+		{
+			name:         "date_is_start_of_day_utc",
+			inn:          "7707083893",
+			asOf:         "2024-03-01",
+			expectedAsOf: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			expectedID:   "test-id",
+		},
+		{
+			name:         "rfc3339_timestamp",
+			inn:          "7707083893",
+			asOf:         "2024-03-01T12:00:00+03:00",
+			expectedAsOf: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+			expectedID:   "test-id",
+		},
+		{
+			name: "nothing_known",
+			inn:  "500100732259",
+			asOf: "2024-03-01",
+		},
+		{
+			name:          "invalid_inn",
+			inn:           "123",
+			asOf:          "2024-03-01",
+			expectedError: "inn must be 10 or 12 digits",
+		},
+		{
+			name:          "invalid_as_of",
+			inn:           "7707083893",
+			asOf:          "yesterday",
+			expectedError: "asOf must be RFC3339 timestamp or YYYY-MM-DD date",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot, err := service.GetCompanySnapshot(context.Background(), tt.inn, tt.asOf)
+
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing %q, but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.expectedID == "" {
+				if snapshot != nil {
+					t.Errorf("expected no snapshot, but got %+v", snapshot)
+				}
+				return
+			}
+			if snapshot == nil || snapshot.Verification.ID != tt.expectedID || len(snapshot.Data) != 1 {
+				t.Fatalf("expected snapshot of %s with data, but got %+v", tt.expectedID, snapshot)
+			}
+			if !requestedAsOf.Equal(tt.expectedAsOf) {
+				t.Errorf("expected lookup as of %v, but got %v", tt.expectedAsOf, requestedAsOf)
+			}
+			if snapshot.AsOf != tt.expectedAsOf.Format(time.RFC3339) {
+				t.Errorf("expected asOf %s, but got %s", tt.expectedAsOf.Format(time.RFC3339), snapshot.AsOf)
+			}
+		})
+	}
+}
+
+func TestGetVerificationStatuses(t *testing.T) {
+	var requested []string
+	mockRepo := &mockVerificationRepository{
+		getStatusesFunc: func(ctx context.Context, inns []string) ([]*model.CompanyVerificationStatus, error) {
+			requested = inns
+			status := model.VerificationStatusCompleted
+			risk := model.RiskLevelLow
+			return []*model.CompanyVerificationStatus{
+				{Inn: "7707083893", VerificationID: stringPtr("test-id"), Status: &status, RiskLevel: &risk},
+			}, nil
+		},
+	}
+	service := NewVerificationService(mockRepo, &mockNATSClient{}, nil, zaptest.NewLogger(t))
+
+	tooMany := make([]string, maxStatusINNs+1)
+	for i := range tooMany {
+		tooMany[i] = "7707083893"
+	}
+
+	tests := []struct {
+		name          string
+		inns          []string
+		expectedINNs  []string
+		expectedIDs   []string
+		expectedError string
+	}{
+		{
+			name:         "order_kept_and_duplicates_removed",
+			inns:         []string{"500100732259", "7707083893", "500100732259"},
+			expectedINNs: []string{"500100732259", "7707083893"},
+			expectedIDs:  []string{"", "test-id"},
+		},
+		{
+			name:         "empty_list",
+			inns:         []string{},
+			expectedINNs: []string{},
+			expectedIDs:  []string{},
+		},
+		{
+			name:          "invalid_inn",
+			inns:          []string{"7707083893", "123"},
+			expectedError: "inn must be 10 or 12 digits",
+		},
+		{
+			name:          "too_many_inns",
+			inns:          tooMany,
+			expectedError: "at most 5000 inns can be requested at once",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requested = nil
+			statuses, err := service.GetVerificationStatuses(context.Background(), tt.inns)
+
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing %q, but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(tt.expectedINNs) > 0 && !reflect.DeepEqual(requested, tt.expectedINNs) {
+				t.Errorf("expected repository lookup of %v, but got %v", tt.expectedINNs, requested)
+			}
+			if len(statuses) != len(tt.expectedINNs) {
+				t.Fatalf("expected %d statuses, but got %d", len(tt.expectedINNs), len(statuses))
+			}
+			for i, status := range statuses {
+				if status.Inn != tt.expectedINNs[i] {
+					t.Errorf("expected inn %s at position %d, but got %s", tt.expectedINNs[i], i, status.Inn)
+				}
+				id := ""
+				if status.VerificationID != nil {
+					id = *status.VerificationID
+				}
+				if id != tt.expectedIDs[i] {
+					t.Errorf("expected verification %q for %s, but got %q", tt.expectedIDs[i], status.Inn, id)
+				}
+			}
+		})
+	}
+}
+
+func TestHasRecentVerification(t *testing.T) {
+	var requestedSince time.Time
+	mockRepo := &mockVerificationRepository{
+		getLatestSinceFunc: func(ctx context.Context, inn string, since time.Time) (*model.RecentVerification, error) {
+			requestedSince = since
+			if inn != "7707083893" {
+				return &model.RecentVerification{Exists: false}, nil
+			}
+			status := model.VerificationStatusCompleted
+			return &model.RecentVerification{Exists: true, VerificationID: stringPtr("test-id"), Status: &status}, nil
+		},
+	}
+	service := NewVerificationService(mockRepo, &mockNATSClient{}, nil, zaptest.NewLogger(t))
+
+	tests := []struct {
+		name          string
+		inn           string
+		maxAgeHours   int32
+		expectExists  bool
+		expectedError string
+	}{
+		{name: "known_company", inn: "7707083893", maxAgeHours: 24, expectExists: true},
+		{name: "unknown_company", inn: "500100732259", maxAgeHours: 24},
+		{name: "invalid_inn", inn: "123", maxAgeHours: 24, expectedError: "inn must be 10 or 12 digits"},
+		{name: "zero_age", inn: "7707083893", maxAgeHours: 0, expectedError: "maxAgeHours must be between 1 and 8784"},
+		{name: "age_over_a_year", inn: "7707083893", maxAgeHours: 8785, expectedError: "maxAgeHours must be between 1 and 8784"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now()
+			recent, err := service.HasRecentVerification(context.Background(), tt.inn, tt.maxAgeHours)
+
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing %q, but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if recent.Exists != tt.expectExists {
+				t.Errorf("expected exists %v, but got %v", tt.expectExists, recent.Exists)
+			}
+			if age := before.Sub(requestedSince); age < time.Duration(tt.maxAgeHours)*time.Hour-time.Second || age > time.Duration(tt.maxAgeHours)*time.Hour+time.Second {
+				t.Errorf("expected lookup of the last %d hours, but got since %s", tt.maxAgeHours, requestedSince)
+			}
+		})
+	}
+}
+
+// Вспомогательная функция для создания указателя на int32
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+// Вспомогательная функция для создания указателя на строку
+func stringPtr(s string) *string {
+	return &s
+}
+
+// Вспомогательная функция для создания указателя на статус
+func statusPtr(s model.VerificationStatus) *model.VerificationStatus {
+	return &s
+}
+
+// Вспомогательная функция для создания ошибки отсутствующей записи
+func errNotFound(format string, args ...any) error {
+	return &repository.Error{Kind: repository.ErrNotFound, Err: fmt.Errorf(format, args...)}
 }
 
 // Вспомогательная функция для проверки содержания ошибки
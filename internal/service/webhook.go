@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/companychange"
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/webhook"
+
+	"go.uber.org/zap"
+)
+
+// WebhookService управляет вебхуками и доставляет им уведомления о завершении проверок
+// и изменениях данных компаний на мониторинге
+type WebhookService interface {
+	RegisterWebhook(ctx context.Context, input model.WebhookInput) (*model.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]*model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	PreviewWebhook(ctx context.Context, input model.WebhookInput, verificationID string) (*model.WebhookPreview, error)
+	DeliverVerificationCompleted(ctx context.Context, verificationID string) error
+	// DeliverVerificationCompletedTo отправляет уведомление о завершении проверки одному вебхуку
+	DeliverVerificationCompletedTo(ctx context.Context, webhookID, verificationID string) error
+	// DeliverCompanyChangedTo отправляет вебхуку существенные изменения данных компании, найденные
+	// проверкой verificationID относительно предыдущей проверки. Без изменений запрос не отправляется.
+	DeliverCompanyChangedTo(ctx context.Context, webhookID, verificationID string) error
+}
+
+type webhookService struct {
+	repo             repository.WebhookRepository
+	verificationRepo repository.VerificationRepository
+	renderer         *webhook.Renderer
+	sender           webhook.Sender
+	changes          *companychange.Detector
+	logger           *zap.Logger
+	now              func() time.Time
+}
+
+// NewWebhookService создает сервис вебхуков. changes определяет, какие изменения данных компании
+// существенны для события company.changed.
+func NewWebhookService(repo repository.WebhookRepository, verificationRepo repository.VerificationRepository, renderer *webhook.Renderer, sender webhook.Sender, changes *companychange.Detector, logger *zap.Logger) WebhookService {
+	return &webhookService{
+		repo:             repo,
+		verificationRepo: verificationRepo,
+		renderer:         renderer,
+		sender:           sender,
+		changes:          changes,
+		logger:           logger,
+		now:              time.Now,
+	}
+}
+
+// RegisterWebhook сохраняет вебхук. Шаблоны разбираются при регистрации, чтобы ошибка в них
+// была видна сразу, а не при первой доставке.
+func (s *webhookService) RegisterWebhook(ctx context.Context, input model.WebhookInput) (*model.Webhook, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if err := webhook.Validate(input); err != nil {
+		return nil, invalidInput(err)
+	}
+
+	principal, _ := auth.PrincipalFromContext(ctx)
+	hook := newWebhook(input)
+	hook.CreatedBy = principal.Email
+	if err := s.repo.Create(ctx, hook); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("webhook registered",
+		zap.String("id", hook.ID),
+		zap.String("url", hook.URL),
+		zap.String("template", string(hook.Template)),
+		zap.String("created_by", principal.Email))
+	return hook, nil
+}
+
+func (s *webhookService) ListWebhooks(ctx context.Context) ([]*model.Webhook, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	webhooks, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if webhooks == nil {
+		webhooks = []*model.Webhook{}
+	}
+	return webhooks, nil
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, id string) error {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return err
+	}
+	if id == "" {
+		return invalidInputf("webhook id cannot be empty")
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.logger.Info("webhook deleted", zap.String("id", id))
+	return nil
+}
+
+// PreviewWebhook формирует запрос, который получил бы вебхук с настройками input о завершении проверки
+func (s *webhookService) PreviewWebhook(ctx context.Context, input model.WebhookInput, verificationID string) (*model.WebhookPreview, error) {
+	if err := auth.RequireRole(ctx, auth.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if err := webhook.Validate(input); err != nil {
+		return nil, invalidInput(err)
+	}
+
+	verification, err := s.verificationRepo.GetByIDWithData(ctx, verificationID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.renderer.Render(newWebhook(input), s.payload(verification))
+}
+
+// DeliverVerificationCompleted отправляет всем вебхукам, подписанным на завершение проверок,
+// уведомление о завершении проверки. Ошибка доставки одному вебхуку не мешает остальным,
+// ошибки возвращаются вместе.
+func (s *webhookService) DeliverVerificationCompleted(ctx context.Context, verificationID string) error {
+	webhooks, err := s.repo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	webhooks = slices.DeleteFunc(webhooks, func(hook *model.Webhook) bool {
+		return !slices.Contains(hook.Events, model.WebhookEventVerificationCompleted)
+	})
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	verification, err := s.verificationRepo.GetByIDWithData(ctx, verificationID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get verification: %w", err)
+	}
+	payload := s.payload(verification)
+
+	var errs []error
+	for _, hook := range webhooks {
+		err := s.deliver(ctx, hook, payload)
+		outcome := "delivered"
+		if err != nil {
+			outcome = "failed"
+			s.logger.Error("failed to deliver webhook",
+				zap.Error(err),
+				zap.String("webhook_id", hook.ID),
+				zap.String("verification_id", verificationID))
+			errs = append(errs, fmt.Errorf("webhook %s: %w", hook.ID, err))
+		}
+		metrics.WebhookDeliveries.WithLabelValues(string(hook.Template), outcome).Inc()
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *webhookService) DeliverVerificationCompletedTo(ctx context.Context, webhookID, verificationID string) error {
+	hook, err := s.find(ctx, webhookID)
+	if err != nil {
+		return err
+	}
+
+	verification, err := s.verificationRepo.GetByIDWithData(ctx, verificationID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get verification: %w", err)
+	}
+
+	err = s.deliver(ctx, hook, s.payload(verification))
+	outcome := "delivered"
+	if err != nil {
+		outcome = "failed"
+	}
+	metrics.WebhookDeliveries.WithLabelValues(string(hook.Template), outcome).Inc()
+	return err
+}
+
+func (s *webhookService) DeliverCompanyChangedTo(ctx context.Context, webhookID, verificationID string) error {
+	hook, err := s.find(ctx, webhookID)
+	if err != nil {
+		return err
+	}
+
+	verification, err := s.verificationRepo.GetByIDWithData(ctx, verificationID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get verification: %w", err)
+	}
+	previous, err := s.verificationRepo.GetPrevious(ctx, verificationID)
+	if err != nil {
+		return fmt.Errorf("failed to get previous verification: %w", err)
+	}
+	if previous == nil {
+		return nil
+	}
+	previous, err = s.verificationRepo.GetByIDWithData(ctx, previous.ID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get previous verification: %w", err)
+	}
+
+	changes := s.changes.Compare(previous.Data, verification.Data)
+	if len(changes) == 0 {
+		s.logger.Debug("company data has not changed materially",
+			zap.String("webhook_id", webhookID),
+			zap.String("verification_id", verificationID),
+			zap.String("previous_verification_id", previous.ID))
+		return nil
+	}
+
+	payload := webhook.Payload{
+		Event:                  webhook.EventCompanyChanged,
+		OccurredAt:             s.now(),
+		Verification:           verification,
+		PreviousVerificationID: previous.ID,
+		Changes:                changes,
+	}
+	err = s.deliver(ctx, hook, payload)
+	outcome := "delivered"
+	if err != nil {
+		outcome = "failed"
+	}
+	metrics.WebhookDeliveries.WithLabelValues(string(hook.Template), outcome).Inc()
+	return err
+}
+
+func (s *webhookService) find(ctx context.Context, webhookID string) (*model.Webhook, error) {
+	webhooks, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	i := slices.IndexFunc(webhooks, func(hook *model.Webhook) bool { return hook.ID == webhookID })
+	if i < 0 {
+		return nil, fmt.Errorf("webhook not found: %s: %w", webhookID, repository.ErrNotFound)
+	}
+	return webhooks[i], nil
+}
+
+func (s *webhookService) deliver(ctx context.Context, hook *model.Webhook, payload webhook.Payload) error {
+	request, err := s.renderer.Render(hook, payload)
+	if err != nil {
+		return err
+	}
+	return s.sender.Send(ctx, hook.URL, request)
+}
+
+func (s *webhookService) payload(verification *model.Verification) webhook.Payload {
+	return webhook.Payload{
+		Event:        webhook.EventVerificationCompleted,
+		OccurredAt:   s.now(),
+		Verification: verification,
+	}
+}
+
+func newWebhook(input model.WebhookInput) *model.Webhook {
+	hook := &model.Webhook{
+		URL:            input.URL,
+		Template:       input.Template,
+		CustomTemplate: input.CustomTemplate,
+		Events:         []model.WebhookEvent{model.WebhookEventVerificationCompleted},
+		Headers:        make([]*model.WebhookHeader, 0, len(input.Headers)),
+	}
+	if len(input.Events) > 0 {
+		hook.Events = slices.Compact(slices.Sorted(slices.Values(input.Events)))
+	}
+	for _, header := range input.Headers {
+		hook.Headers = append(hook.Headers, &model.WebhookHeader{Name: http.CanonicalHeaderKey(header.Name), Value: header.Value})
+	}
+	return hook
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/companychange"
+	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/webhook"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для WebhookRepository
+type mockWebhookRepository struct {
+	repository.WebhookRepository
+	webhooks []*model.Webhook
+}
+
+func (m *mockWebhookRepository) Create(ctx context.Context, hook *model.Webhook) error {
+	hook.ID = "wh-1"
+	hook.CreatedAt = "2024-01-01T10:00:00Z"
+	m.webhooks = append(m.webhooks, hook)
+	return nil
+}
+
+func (m *mockWebhookRepository) List(ctx context.Context) ([]*model.Webhook, error) {
+	return m.webhooks, nil
+}
+
+type sentWebhook struct {
+	url     string
+	request *model.WebhookPreview
+}
+
+type mockWebhookSender struct {
+	sent    []sentWebhook
+	failURL string
+}
+
+func (m *mockWebhookSender) Send(ctx context.Context, url string, request *model.WebhookPreview) error {
+	if url == m.failURL {
+		return errors.New("connection refused")
+	}
+	m.sent = append(m.sent, sentWebhook{url: url, request: request})
+	return nil
+}
+
+func TestRegisterWebhook(t *testing.T) {
+	input := model.WebhookInput{
+		URL:      "https://crm.example.com/hooks",
+		Template: model.WebhookTemplateSummary,
+		Headers:  []*model.WebhookHeaderInput{{Name: "x-source", Value: "scoring"}},
+	}
+
+	tests := []struct {
+		name          string
+		principal     *auth.Principal
+		input         model.WebhookInput
+		expectedError string
+	}{
+		{name: "admin", principal: &auth.Principal{Email: "admin@scoring.local", Roles: []string{auth.RoleAdmin}}, input: input},
+		{name: "not_admin", principal: &auth.Principal{Email: "analyst@example.com"}, input: input, expectedError: "access denied"},
+		{
+			name:          "invalid_template",
+			principal:     &auth.Principal{Email: "admin@scoring.local", Roles: []string{auth.RoleAdmin}},
+			input:         model.WebhookInput{URL: input.URL, Template: model.WebhookTemplateCustom, CustomTemplate: stringPtr("{{.event")},
+			expectedError: "invalid template body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockWebhookRepository{}
+			service := NewWebhookService(repo, &mockVerificationRepository{}, webhook.NewRenderer(""), &mockWebhookSender{}, companychange.NewDetector(nil, 0), zaptest.NewLogger(t))
+
+			hook, err := service.RegisterWebhook(auth.WithPrincipal(context.Background(), tt.principal), tt.input)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, but got %v", tt.expectedError, err)
+				}
+				if len(repo.webhooks) != 0 {
+					t.Error("expected webhook not to be saved")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if hook.CreatedBy != tt.principal.Email || hook.Headers[0].Name != "X-Source" {
+				t.Errorf("unexpected webhook %+v %+v", hook, hook.Headers[0])
+			}
+		})
+	}
+}
+
+func TestDeliverVerificationCompleted(t *testing.T) {
+	completed := []model.WebhookEvent{model.WebhookEventVerificationCompleted}
+	repo := &mockWebhookRepository{webhooks: []*model.Webhook{
+		{ID: "wh-1", URL: "https://down.example.com", Template: model.WebhookTemplateFull, Events: completed},
+		{ID: "wh-2", URL: "https://events.example.com", Template: model.WebhookTemplateCloudevents, Events: completed},
+		{ID: "wh-3", URL: "https://chat.example.com", Template: model.WebhookTemplateCustom, CustomTemplate: stringPtr(`{"text": "{{.verification.inn}} {{.verification.status}}"}`), Events: completed},
+		{ID: "wh-4", URL: "https://risk.example.com", Template: model.WebhookTemplateFull, Events: []model.WebhookEvent{model.WebhookEventCompanyChanged}},
+	}}
+	verificationRepo := &mockVerificationRepository{
+		getByIDFunc: func(ctx context.Context, id string) (*model.Verification, error) {
+			return &model.Verification{ID: id, Inn: "7707083893", Status: model.VerificationStatusCompleted}, nil
+		},
+	}
+	sender := &mockWebhookSender{failURL: "https://down.example.com"}
+	service := NewWebhookService(repo, verificationRepo, webhook.NewRenderer("/scoring"), sender, companychange.NewDetector(nil, 0), zaptest.NewLogger(t))
+
+	err := service.DeliverVerificationCompleted(context.Background(), "v-1")
+	if err == nil || !strings.Contains(err.Error(), "webhook wh-1: connection refused") {
+		t.Fatalf("expected error of the failed webhook, but got %v", err)
+	}
+
+	if len(sender.sent) != 2 {
+		t.Fatalf("expected remaining webhooks to be delivered, but got %d", len(sender.sent))
+	}
+	if sender.sent[0].request.ContentType != "application/cloudevents+json" {
+		t.Errorf("unexpected content type %s", sender.sent[0].request.ContentType)
+	}
+	if body := sender.sent[1].request.Body; body != `{"text": "7707083893 COMPLETED"}` {
+		t.Errorf("unexpected custom body %s", body)
+	}
+}
@@ -0,0 +1,44 @@
+package signing
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DataRedaction изменение сохраненных данных проверки администратором, которое подписывает шлюз
+type DataRedaction struct {
+	ID             string
+	VerificationID string
+	DataType       string
+	Operation      string
+	// Path ключи и индексы массивов от корня данных до измененного значения
+	Path             []string
+	PreviousDataHash string
+	DataHash         string
+	Actor            string
+	Reason           string
+	CreatedAt        time.Time
+}
+
+// Content возвращает подписываемый текст по одному полю на строку. Путь и причина записываются
+// строками JSON, чтобы перевод строки в причине не подменял следующие поля.
+func (r DataRedaction) Content() []byte {
+	path, _ := json.Marshal(r.Path)
+	reason, _ := json.Marshal(r.Reason)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "redaction_id=%s\n", r.ID)
+	fmt.Fprintf(&b, "verification_id=%s\n", r.VerificationID)
+	fmt.Fprintf(&b, "data_type=%s\n", r.DataType)
+	fmt.Fprintf(&b, "operation=%s\n", r.Operation)
+	fmt.Fprintf(&b, "path=%s\n", path)
+	fmt.Fprintf(&b, "previous_data_hash=sha256:%s\n", r.PreviousDataHash)
+	fmt.Fprintf(&b, "data_hash=sha256:%s\n", r.DataHash)
+	fmt.Fprintf(&b, "actor=%s\n", r.Actor)
+	fmt.Fprintf(&b, "reason=%s\n", reason)
+	fmt.Fprintf(&b, "created_at=%s\n", r.CreatedAt.UTC().Format(time.RFC3339))
+
+	return []byte(b.String())
+}
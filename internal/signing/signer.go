@@ -0,0 +1,57 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+const Algorithm = "ed25519"
+
+// Signer подписывает документы ключом шлюза, чтобы получатели могли проверить их целостность
+type Signer struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// NewSigner создает подписчика из seed ключа Ed25519 в base64
+func NewSigner(seedBase64 string) (*Signer, error) {
+	seed, err := base64.StdEncoding.DecodeString(seedBase64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signing key: %w", err)
+	}
+
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+
+	key := ed25519.NewKeyFromSeed(seed)
+	fingerprint := sha256.Sum256(key.Public().(ed25519.PublicKey))
+
+	return &Signer{
+		key:   key,
+		keyID: hex.EncodeToString(fingerprint[:8]),
+	}, nil
+}
+
+// Sign возвращает подпись данных
+func (s *Signer) Sign(data []byte) []byte {
+	return ed25519.Sign(s.key, data)
+}
+
+// PublicKey возвращает открытый ключ для проверки подписей
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// KeyID возвращает короткий отпечаток открытого ключа
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// Verify проверяет подпись данных открытым ключом
+func Verify(publicKey ed25519.PublicKey, data, signature []byte) bool {
+	return ed25519.Verify(publicKey, data, signature)
+}
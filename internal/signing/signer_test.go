@@ -0,0 +1,131 @@
+package signing
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSeed() string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+}
+
+func TestNewSigner(t *testing.T) {
+	tests := []struct {
+		name          string
+		key           string
+		expectedError string
+	}{
+		{
+			name: "valid_key",
+			key:  testSeed(),
+		},
+		{
+			name:          "invalid_base64",
+			key:           "not base64!",
+			expectedError: "failed to decode signing key",
+		},
+		{
+			name:          "wrong_length",
+			key:           base64.StdEncoding.EncodeToString([]byte("short")),
+			expectedError: "signing key must be 32 bytes, got 5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := NewSigner(tt.key)
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', but got '%v'", tt.expectedError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(signer.KeyID()) != 16 {
+				t.Errorf("expected 16 character key id, but got '%s'", signer.KeyID())
+			}
+		})
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	signer, err := NewSigner(testSeed())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := []byte("document")
+	signature := signer.Sign(data)
+
+	if !Verify(signer.PublicKey(), data, signature) {
+		t.Error("expected signature to be valid")
+	}
+
+	if Verify(signer.PublicKey(), []byte("tampered"), signature) {
+		t.Error("expected signature of tampered data to be invalid")
+	}
+}
+
+func TestVerificationSummaryContent(t *testing.T) {
+	completedAt := time.Date(2024, 3, 1, 15, 4, 5, 0, time.FixedZone("MSK", 3*60*60))
+	summary := VerificationSummary{
+		VerificationID: "v-1",
+		Status:         "COMPLETED",
+		CompletedAt:    completedAt,
+		PayloadHashes: map[string]string{
+			"FINANCES":          "bb",
+			"BASIC_INFORMATION": "aa",
+		},
+	}
+
+	expected := "verification_id=v-1\n" +
+		"status=COMPLETED\n" +
+		"completed_at=2024-03-01T12:04:05Z\n" +
+		"data.BASIC_INFORMATION=sha256:aa\n" +
+		"data.FINANCES=sha256:bb\n"
+	if content := string(summary.Content()); content != expected {
+		t.Errorf("expected content %q, but got %q", expected, content)
+	}
+
+	tampered := summary
+	tampered.PayloadHashes = map[string]string{"FINANCES": "cc", "BASIC_INFORMATION": "aa"}
+	if Digest(tampered.Content()) == Digest(summary.Content()) {
+		t.Error("expected tampered payload to change the digest")
+	}
+}
+
+func TestDataRedactionContent(t *testing.T) {
+	redaction := DataRedaction{
+		ID:               "r-1",
+		VerificationID:   "v-1",
+		DataType:         "BASIC_INFORMATION",
+		Operation:        "REDACT",
+		Path:             []string{"founders", "0", "passport"},
+		PreviousDataHash: "aa",
+		DataHash:         "bb",
+		Actor:            "admin@example.com",
+		Reason:           "court order\ndata_hash=sha256:cc",
+		CreatedAt:        time.Date(2024, 3, 1, 15, 4, 5, 0, time.FixedZone("MSK", 3*60*60)),
+	}
+
+	expected := "redaction_id=r-1\n" +
+		"verification_id=v-1\n" +
+		"data_type=BASIC_INFORMATION\n" +
+		"operation=REDACT\n" +
+		`path=["founders","0","passport"]` + "\n" +
+		"previous_data_hash=sha256:aa\n" +
+		"data_hash=sha256:bb\n" +
+		"actor=admin@example.com\n" +
+		`reason="court order\ndata_hash=sha256:cc"` + "\n" +
+		"created_at=2024-03-01T12:04:05Z\n"
+	if content := string(redaction.Content()); content != expected {
+		t.Errorf("expected content %q, but got %q", expected, content)
+	}
+}
@@ -0,0 +1,45 @@
+package signing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// VerificationSummary итоги проверки, которые подписывает шлюз
+type VerificationSummary struct {
+	VerificationID string
+	Status         string
+	CompletedAt    time.Time
+	// PayloadHashes SHA-256 (hex) текста JSONB данных по типам
+	PayloadHashes map[string]string
+}
+
+// Content возвращает подписываемый текст: по одному полю на строку, данные отсортированы по типу,
+// поэтому аудитор может собрать тот же текст из полученных данных и проверить подпись
+func (s VerificationSummary) Content() []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "verification_id=%s\n", s.VerificationID)
+	fmt.Fprintf(&b, "status=%s\n", s.Status)
+	fmt.Fprintf(&b, "completed_at=%s\n", s.CompletedAt.UTC().Format(time.RFC3339))
+
+	dataTypes := make([]string, 0, len(s.PayloadHashes))
+	for dataType := range s.PayloadHashes {
+		dataTypes = append(dataTypes, dataType)
+	}
+	sort.Strings(dataTypes)
+	for _, dataType := range dataTypes {
+		fmt.Fprintf(&b, "data.%s=sha256:%s\n", dataType, s.PayloadHashes[dataType])
+	}
+
+	return []byte(b.String())
+}
+
+// Digest возвращает SHA-256 подписываемого текста в hex
+func Digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
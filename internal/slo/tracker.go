@@ -0,0 +1,201 @@
+// Package slo поддерживает готовые для алертинга показатели уровня обслуживания
+// за скользящие окна, чтобы правила алертов не зависели от запросов PromQL к сырым метрикам.
+package slo
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/metrics"
+)
+
+// bucketSize шаг, с которым события группируются в памяти
+const bucketSize = time.Minute
+
+type bucket struct {
+	start             time.Time
+	creates           int
+	createFailures    int
+	deliveries        int
+	deliveryFailures  int
+	completionSeconds []float64
+}
+
+// Tracker накапливает события по минутам и пересчитывает показатели за каждое окно.
+// События старше самого длинного окна отбрасываются.
+type Tracker struct {
+	windows []time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	buckets []*bucket
+}
+
+func NewTracker(windows []time.Duration) *Tracker {
+	return &Tracker{
+		windows: windows,
+		now:     time.Now,
+	}
+}
+
+// RecordCreate учитывает вызов мутации createVerification. Ошибки в аргументах и доступе
+// возвращаются сервисом без обертки и не считаются недоступностью.
+func (t *Tracker) RecordCreate(err error) {
+	t.record(func(b *bucket) {
+		b.creates++
+		if err != nil && errors.Unwrap(err) != nil {
+			b.createFailures++
+		}
+	})
+}
+
+// RecordCompletion учитывает время от создания до завершения проверки
+func (t *Tracker) RecordCompletion(duration time.Duration) {
+	t.record(func(b *bucket) {
+		b.completionSeconds = append(b.completionSeconds, duration.Seconds())
+	})
+}
+
+// RecordDelivery учитывает доставку уведомления о завершении проверки
+func (t *Tracker) RecordDelivery(ok bool) {
+	t.record(func(b *bucket) {
+		b.deliveries++
+		if !ok {
+			b.deliveryFailures++
+		}
+	})
+}
+
+func (t *Tracker) record(apply func(*bucket)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	start := t.now().Truncate(bucketSize)
+	if n := len(t.buckets); n == 0 || !t.buckets[n-1].start.Equal(start) {
+		t.buckets = append(t.buckets, &bucket{start: start})
+	}
+	apply(t.buckets[len(t.buckets)-1])
+}
+
+// CompletionTime время от создания проверки до ее последнего обновления воркером
+func CompletionTime(verification *model.Verification) (time.Duration, bool) {
+	created, err := time.Parse(time.RFC3339, verification.CreatedAt)
+	if err != nil {
+		return 0, false
+	}
+	updated, err := time.Parse(time.RFC3339, verification.UpdatedAt)
+	if err != nil || updated.Before(created) {
+		return 0, false
+	}
+	return updated.Sub(created), true
+}
+
+// Snapshot показатели за одно окно
+type Snapshot struct {
+	Window time.Duration
+	// CreateAvailability доля вызовов createVerification без ошибок шлюза, 1 при отсутствии вызовов
+	CreateAvailability float64
+	// CompletionP95 95-й перцентиль времени завершения проверок, 0 при отсутствии завершений
+	CompletionP95 time.Duration
+	// DeliverySuccessRate доля успешно доставленных уведомлений, 1 при отсутствии доставок
+	DeliverySuccessRate float64
+}
+
+// Snapshots возвращает показатели за каждое окно и отбрасывает устаревшие события
+func (t *Tracker) Snapshots() []Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.prune(now)
+
+	snapshots := make([]Snapshot, 0, len(t.windows))
+	for _, window := range t.windows {
+		since := now.Add(-window)
+		var creates, createFailures, deliveries, deliveryFailures int
+		var completions []float64
+		for _, b := range t.buckets {
+			// Минута, на которую приходится начало окна, учитывается целиком
+			if b.start.Add(bucketSize).Before(since) {
+				continue
+			}
+			creates += b.creates
+			createFailures += b.createFailures
+			deliveries += b.deliveries
+			deliveryFailures += b.deliveryFailures
+			completions = append(completions, b.completionSeconds...)
+		}
+
+		snapshots = append(snapshots, Snapshot{
+			Window:              window,
+			CreateAvailability:  ratio(creates-createFailures, creates),
+			CompletionP95:       time.Duration(percentile(completions, 0.95) * float64(time.Second)),
+			DeliverySuccessRate: ratio(deliveries-deliveryFailures, deliveries),
+		})
+	}
+	return snapshots
+}
+
+func (t *Tracker) prune(now time.Time) {
+	var longest time.Duration
+	for _, window := range t.windows {
+		longest = max(longest, window)
+	}
+
+	i := 0
+	for i < len(t.buckets) && t.buckets[i].start.Add(bucketSize).Before(now.Add(-longest)) {
+		i++
+	}
+	t.buckets = t.buckets[i:]
+}
+
+// Update публикует показатели в метрики scoring_gateway_slo_*
+func (t *Tracker) Update() {
+	for _, snapshot := range t.Snapshots() {
+		window := snapshot.Window.String()
+		metrics.SLOCreateAvailability.WithLabelValues(window).Set(snapshot.CreateAvailability)
+		metrics.SLOCompletionP95Seconds.WithLabelValues(window).Set(snapshot.CompletionP95.Seconds())
+		metrics.SLODeliverySuccessRatio.WithLabelValues(window).Set(snapshot.DeliverySuccessRate)
+	}
+}
+
+func ratio(good, total int) float64 {
+	if total == 0 {
+		return 1
+	}
+	return float64(good) / float64(total)
+}
+
+// percentile перцентиль по методу ближайшего ранга
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+// UpdateJob периодически публикует показатели
+type UpdateJob struct {
+	tracker *Tracker
+}
+
+func NewUpdateJob(tracker *Tracker) *UpdateJob {
+	return &UpdateJob{tracker: tracker}
+}
+
+func (j *UpdateJob) Name() string {
+	return "slo_update"
+}
+
+func (j *UpdateJob) Run(ctx context.Context) error {
+	j.tracker.Update()
+	return nil
+}
@@ -0,0 +1,101 @@
+package slo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+)
+
+func TestTrackerSnapshots(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 30, 0, time.UTC)
+	tracker := NewTracker([]time.Duration{5 * time.Minute, time.Hour})
+	tracker.now = func() time.Time { return now }
+
+	// Событие 30 минут назад попадает только в часовое окно
+	now = now.Add(-30 * time.Minute)
+	tracker.RecordCreate(fmt.Errorf("failed to publish verification request: %w", errors.New("nats: timeout")))
+	tracker.RecordCompletion(10 * time.Minute)
+	tracker.RecordDelivery(false)
+	now = now.Add(30 * time.Minute)
+
+	tracker.RecordCreate(nil)
+	tracker.RecordCreate(nil)
+	tracker.RecordCreate(errors.New("inn must be 10 or 12 digits, got 3"))
+	for i := 1; i <= 20; i++ {
+		tracker.RecordCompletion(time.Duration(i) * time.Second)
+	}
+	tracker.RecordDelivery(true)
+
+	snapshots := tracker.Snapshots()
+	if len(snapshots) != 2 {
+		t.Fatalf("expected 2 snapshots, got %d", len(snapshots))
+	}
+
+	short := snapshots[0]
+	if short.CreateAvailability != 1 {
+		t.Errorf("expected client errors not to affect availability, got %v", short.CreateAvailability)
+	}
+	if short.CompletionP95 != 19*time.Second {
+		t.Errorf("expected p95 of 19s, got %v", short.CompletionP95)
+	}
+	if short.DeliverySuccessRate != 1 {
+		t.Errorf("expected delivery rate 1, got %v", short.DeliverySuccessRate)
+	}
+
+	long := snapshots[1]
+	if long.CreateAvailability != 0.75 {
+		t.Errorf("expected availability 0.75, got %v", long.CreateAvailability)
+	}
+	if long.CompletionP95 != 20*time.Second {
+		t.Errorf("expected p95 of 20s, got %v", long.CompletionP95)
+	}
+	if long.DeliverySuccessRate != 0.5 {
+		t.Errorf("expected delivery rate 0.5, got %v", long.DeliverySuccessRate)
+	}
+
+	// После выхода событий из самого длинного окна показатели возвращаются к значениям без событий
+	now = now.Add(2 * time.Hour)
+	long = tracker.Snapshots()[1]
+	if long.CreateAvailability != 1 || long.CompletionP95 != 0 || long.DeliverySuccessRate != 1 {
+		t.Errorf("expected empty window defaults, got %+v", long)
+	}
+	if len(tracker.buckets) != 0 {
+		t.Errorf("expected expired buckets to be dropped, got %d", len(tracker.buckets))
+	}
+}
+
+func TestCompletionTime(t *testing.T) {
+	tests := []struct {
+		name         string
+		verification *model.Verification
+		expected     time.Duration
+		expectedOK   bool
+	}{
+		{
+			name:         "completed",
+			verification: &model.Verification{CreatedAt: "2025-03-01T12:00:00Z", UpdatedAt: "2025-03-01T12:04:30Z"},
+			expected:     4*time.Minute + 30*time.Second,
+			expectedOK:   true,
+		},
+		{
+			name:         "missing_updated_at",
+			verification: &model.Verification{CreatedAt: "2025-03-01T12:00:00Z"},
+		},
+		{
+			name:         "updated_before_created",
+			verification: &model.Verification{CreatedAt: "2025-03-01T12:00:00Z", UpdatedAt: "2025-03-01T11:00:00Z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			duration, ok := CompletionTime(tt.verification)
+			if ok != tt.expectedOK || duration != tt.expected {
+				t.Errorf("expected %v (%v), got %v (%v)", tt.expected, tt.expectedOK, duration, ok)
+			}
+		})
+	}
+}
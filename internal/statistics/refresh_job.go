@@ -0,0 +1,25 @@
+// Package statistics обновляет предварительно агрегированную статистику проверок.
+package statistics
+
+import (
+	"context"
+
+	"scoring_api_gateway/internal/service"
+)
+
+// RefreshJob пересчитывает материализованные представления статистики
+type RefreshJob struct {
+	service service.StatisticsService
+}
+
+func NewRefreshJob(service service.StatisticsService) *RefreshJob {
+	return &RefreshJob{service: service}
+}
+
+func (j *RefreshJob) Name() string {
+	return "statistics_refresh"
+}
+
+func (j *RefreshJob) Run(ctx context.Context) error {
+	return j.service.Refresh(ctx)
+}
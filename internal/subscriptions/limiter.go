@@ -0,0 +1,95 @@
+package subscriptions
+
+import (
+	"context"
+	"sync"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/metrics"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// CodeTooManySubscriptions код ошибки в extensions.code при превышении числа подписок
+const CodeTooManySubscriptions = "TOO_MANY_SUBSCRIPTIONS"
+
+// Limiter расширение gqlgen, ограничивающее число одновременных подписок пользователя на
+// экземпляре шлюза. Подписка занимает место, пока не завершится или не закроется подключение.
+type Limiter struct {
+	max int
+
+	mu     sync.Mutex
+	active map[string]int
+}
+
+var (
+	_ graphql.HandlerExtension     = (*Limiter)(nil)
+	_ graphql.OperationInterceptor = (*Limiter)(nil)
+)
+
+// NewLimiter создает ограничение; max 0 - без ограничения
+func NewLimiter(max int) *Limiter {
+	return &Limiter{max: max, active: make(map[string]int)}
+}
+
+func (l *Limiter) ExtensionName() string {
+	return "SubscriptionLimiter"
+}
+
+func (l *Limiter) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation занимает место для подписки и освобождает его по завершении операции
+func (l *Limiter) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	operation := graphql.GetOperationContext(ctx).Operation
+	if operation == nil || operation.Operation != ast.Subscription {
+		return next(ctx)
+	}
+
+	// Анонимные подписки отклоняют резолверы
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return next(ctx)
+	}
+	if !l.acquire(principal.Email) {
+		metrics.GraphQLSubscriptionRejections.WithLabelValues("limit").Inc()
+		return graphql.OneShot(&graphql.Response{Errors: gqlerror.List{{
+			Message:    "too many concurrent subscriptions",
+			Extensions: map[string]any{"code": CodeTooManySubscriptions, "limit": l.max},
+		}}})
+	}
+	context.AfterFunc(ctx, func() { l.release(principal.Email) })
+	return next(ctx)
+}
+
+func (l *Limiter) acquire(user string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.max > 0 && l.active[user] >= l.max {
+		return false
+	}
+	l.active[user]++
+	metrics.GraphQLSubscriptionsActive.Inc()
+	return true
+}
+
+func (l *Limiter) release(user string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[user] <= 1 {
+		delete(l.active, user)
+	} else {
+		l.active[user]--
+	}
+	metrics.GraphQLSubscriptionsActive.Dec()
+}
+
+// Active число подписок пользователя
+func (l *Limiter) Active(user string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.active[user]
+}
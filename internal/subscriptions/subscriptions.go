@@ -0,0 +1,143 @@
+// Package subscriptions обслуживает подключения WebSocket для GraphQL-подписок: проверяет
+// учетные данные из connection_init, привязывает подключение к пользователю и арендатору,
+// закрывает его по истечении токена и ограничивает число подписок пользователя.
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/repository"
+
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"go.uber.org/zap"
+)
+
+// APIKeyPayloadField поле connection_init с ключом API сервисного аккаунта
+const APIKeyPayloadField = "apiKey"
+
+// CloseReasonTokenExpired сообщение connection_error перед закрытием подключения с истекшим токеном
+const CloseReasonTokenExpired = "token expired"
+
+// Authenticator определяет пользователя подключения по connection_init. Поддерживаются
+// Authorization: Bearer <JWT>, ключ API в apiKey или X-API-Key и, если в сообщении нет
+// учетных данных, пользователь, определенный middleware по заголовкам запроса на подключение.
+type Authenticator struct {
+	tokens      *auth.JWTVerifier
+	apiKeys     auth.APIKeyResolver
+	memberships auth.MembershipResolver
+	logger      *zap.Logger
+}
+
+// NewAuthenticator создает проверку подключений. tokens nil - токены не принимаются,
+// memberships nil - учетные записи пользователей отключены.
+func NewAuthenticator(tokens *auth.JWTVerifier, apiKeys auth.APIKeyResolver, memberships auth.MembershipResolver, logger *zap.Logger) *Authenticator {
+	return &Authenticator{tokens: tokens, apiKeys: apiKeys, memberships: memberships, logger: logger}
+}
+
+type cancelKey struct{}
+
+// Init проверяет connection_init. Контекст подключения получает пользователя и арендатора,
+// а для токена - срок, по истечении которого подключение закрывается вместе с подписками.
+func (a *Authenticator) Init(ctx context.Context, payload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+	principal, expiresAt, err := a.authenticate(ctx, payload)
+	if err != nil {
+		metrics.GraphQLSubscriptionRejections.WithLabelValues("auth").Inc()
+		return ctx, nil, err
+	}
+
+	ctx = auth.WithPrincipal(ctx, principal)
+	ctx = repository.WithTenant(ctx, messaging.TenantOf(principal.Email))
+	if !expiresAt.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, expiresAt)
+		ctx = context.WithValue(ctx, cancelKey{}, cancel)
+		ctx = transport.AppendCloseReason(ctx, CloseReasonTokenExpired)
+	}
+	return ctx, nil, nil
+}
+
+// Close освобождает таймер срока токена закрытого подключения
+func (a *Authenticator) Close(ctx context.Context, closeCode int) {
+	if cancel, ok := ctx.Value(cancelKey{}).(context.CancelFunc); ok {
+		cancel()
+	}
+}
+
+func (a *Authenticator) authenticate(ctx context.Context, payload transport.InitPayload) (*auth.Principal, time.Time, error) {
+	if authorization := payload.Authorization(); authorization != "" {
+		return a.authenticateToken(ctx, authorization)
+	}
+
+	key := payload.GetString(APIKeyPayloadField)
+	if key == "" {
+		key = payload.GetString(auth.APIKeyHeader)
+	}
+	if key = strings.TrimSpace(key); key != "" {
+		principal, err := a.apiKeys.ResolveAPIKey(ctx, key)
+		if err != nil {
+			a.logger.Warn("Failed to verify subscription api key", zap.Error(err))
+			return nil, time.Time{}, fmt.Errorf("failed to verify api key")
+		}
+		if principal == nil {
+			return nil, time.Time{}, fmt.Errorf("invalid api key")
+		}
+		return principal, time.Time{}, nil
+	}
+
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || principal.Email == "" {
+		return nil, time.Time{}, auth.ErrUnauthenticated
+	}
+	return principal, time.Time{}, nil
+}
+
+func (a *Authenticator) authenticateToken(ctx context.Context, authorization string) (*auth.Principal, time.Time, error) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(authorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, time.Time{}, fmt.Errorf("unsupported authorization scheme")
+	}
+	if a.tokens == nil {
+		return nil, time.Time{}, fmt.Errorf("token authentication is not configured")
+	}
+
+	verified, err := a.tokens.Verify(strings.TrimSpace(token))
+	if err != nil {
+		if errors.Is(err, auth.ErrTokenExpired) {
+			return nil, time.Time{}, err
+		}
+		return nil, time.Time{}, fmt.Errorf("invalid token: %w", err)
+	}
+
+	principal := verified.Principal
+	if a.memberships != nil {
+		resolved, err := a.memberships.ResolveMembership(ctx, principal)
+		if err != nil {
+			a.logger.Warn("Failed to verify subscription user", zap.String("email", principal.Email), zap.Error(err))
+			return nil, time.Time{}, fmt.Errorf("failed to verify user")
+		}
+		if resolved == nil {
+			return nil, time.Time{}, fmt.Errorf("account deactivated")
+		}
+		principal = resolved
+	}
+	return principal, verified.ExpiresAt, nil
+}
+
+// NewTransport возвращает транспорт WebSocket с проверкой подключений и настроенными интервалами
+func NewTransport(cfg config.SubscriptionsConfig, authenticator *Authenticator) transport.Websocket {
+	return transport.Websocket{
+		InitFunc:              authenticator.Init,
+		CloseFunc:             authenticator.Close,
+		InitTimeout:           cfg.InitTimeout,
+		KeepAlivePingInterval: cfg.KeepAliveInterval,
+		PingPongInterval:      cfg.PingInterval,
+	}
+}
@@ -0,0 +1,191 @@
+package subscriptions
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/vektah/gqlparser/v2/ast"
+	"go.uber.org/zap"
+)
+
+type mockAPIKeyResolver struct {
+	principal *auth.Principal
+}
+
+func (m *mockAPIKeyResolver) ResolveAPIKey(ctx context.Context, key string) (*auth.Principal, error) {
+	if key != "valid-key" {
+		return nil, nil
+	}
+	return m.principal, nil
+}
+
+type mockMembershipResolver struct {
+	deactivated bool
+}
+
+func (m *mockMembershipResolver) ResolveMembership(ctx context.Context, principal *auth.Principal) (*auth.Principal, error) {
+	if m.deactivated {
+		return nil, nil
+	}
+	return &auth.Principal{Email: principal.Email, Roles: []string{auth.RoleAnalyst}, Organization: "example.com"}, nil
+}
+
+func signToken(secret, claims string) string {
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + base64.RawURLEncoding.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuthenticatorInit(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	validToken := signToken("secret", fmt.Sprintf(`{"email":"user@Example.com","exp":%d}`, expiresAt.Unix()))
+	expiredToken := signToken("secret", fmt.Sprintf(`{"email":"user@example.com","exp":%d}`, time.Now().Add(-time.Minute).Unix()))
+	apiKeys := &mockAPIKeyResolver{principal: &auth.Principal{Email: "partner@bank.ru", Scope: &auth.Scope{}}}
+	headerUser := auth.WithPrincipal(context.Background(), &auth.Principal{Email: "proxy@corp.ru"})
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		payload        transport.InitPayload
+		memberships    auth.MembershipResolver
+		expectedEmail  string
+		expectedTenant string
+		expectedExpiry bool
+		expectedError  string
+	}{
+		{name: "jwt", payload: transport.InitPayload{"Authorization": "Bearer " + validToken}, expectedEmail: "user@Example.com", expectedTenant: "example.com", expectedExpiry: true},
+		{name: "jwt_membership", payload: transport.InitPayload{"authorization": "bearer " + validToken}, memberships: &mockMembershipResolver{}, expectedEmail: "user@Example.com", expectedTenant: "example.com", expectedExpiry: true},
+		{name: "jwt_deactivated", payload: transport.InitPayload{"Authorization": "Bearer " + validToken}, memberships: &mockMembershipResolver{deactivated: true}, expectedError: "account deactivated"},
+		{name: "jwt_expired", payload: transport.InitPayload{"Authorization": "Bearer " + expiredToken}, expectedError: "token expired"},
+		{name: "jwt_invalid", payload: transport.InitPayload{"Authorization": "Bearer " + signToken("other", `{"email":"a@b.c","exp":9999999999}`)}, expectedError: "invalid token"},
+		{name: "basic_scheme", payload: transport.InitPayload{"Authorization": "Basic dXNlcjpwYXNz"}, expectedError: "unsupported authorization scheme"},
+		{name: "api_key", payload: transport.InitPayload{APIKeyPayloadField: "valid-key"}, expectedEmail: "partner@bank.ru", expectedTenant: "bank.ru"},
+		{name: "api_key_header_field", payload: transport.InitPayload{auth.APIKeyHeader: "valid-key"}, expectedEmail: "partner@bank.ru", expectedTenant: "bank.ru"},
+		{name: "invalid_api_key", payload: transport.InitPayload{APIKeyPayloadField: "wrong"}, expectedError: "invalid api key"},
+		{name: "upgrade_headers", ctx: headerUser, expectedEmail: "proxy@corp.ru", expectedTenant: "corp.ru"},
+		{name: "anonymous", expectedError: "unauthenticated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := NewAuthenticator(auth.NewJWTVerifier("secret"), apiKeys, tt.memberships, zap.NewNop())
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			ctx, _, err := authenticator.Init(ctx, tt.payload)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer authenticator.Close(ctx, 1000)
+
+			principal, ok := auth.PrincipalFromContext(ctx)
+			if !ok || principal.Email != tt.expectedEmail {
+				t.Errorf("unexpected principal %+v", principal)
+			}
+			if tenant, _ := repository.TenantFromContext(ctx); tenant != tt.expectedTenant {
+				t.Errorf("expected tenant %q, but got %q", tt.expectedTenant, tenant)
+			}
+			deadline, hasDeadline := ctx.Deadline()
+			if hasDeadline != tt.expectedExpiry || (hasDeadline && !deadline.Equal(expiresAt)) {
+				t.Errorf("unexpected deadline %v", deadline)
+			}
+		})
+	}
+}
+
+func TestAuthenticatorTokenNotConfigured(t *testing.T) {
+	authenticator := NewAuthenticator(nil, &mockAPIKeyResolver{}, nil, zap.NewNop())
+	_, _, err := authenticator.Init(context.Background(), transport.InitPayload{"Authorization": "Bearer token"})
+	if err == nil || !strings.Contains(err.Error(), "token authentication is not configured") {
+		t.Fatalf("expected token authentication to be refused, but got %v", err)
+	}
+}
+
+func TestAuthenticatorClosesOnExpiry(t *testing.T) {
+	authenticator := NewAuthenticator(auth.NewJWTVerifier("secret"), &mockAPIKeyResolver{}, nil, zap.NewNop())
+	token := signToken("secret", fmt.Sprintf(`{"email":"user@example.com","exp":%d}`, time.Now().Add(time.Second).Unix()))
+
+	ctx, _, err := authenticator.Init(context.Background(), transport.InitPayload{"Authorization": "Bearer " + token})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(3 * time.Second):
+		t.Fatal("expected the connection context to end when the token expires")
+	}
+}
+
+func subscriptionContext(email string) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(auth.WithPrincipal(context.Background(), &auth.Principal{Email: email}))
+	ctx = graphql.WithOperationContext(ctx, &graphql.OperationContext{
+		Operation: &ast.OperationDefinition{Operation: ast.Subscription},
+	})
+	return ctx, cancel
+}
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter(2)
+	next := func(ctx context.Context) graphql.ResponseHandler {
+		return graphql.OneShot(&graphql.Response{})
+	}
+
+	first, cancelFirst := subscriptionContext("user@example.com")
+	second, cancelSecond := subscriptionContext("user@example.com")
+	defer cancelSecond()
+	third, cancelThird := subscriptionContext("user@example.com")
+	defer cancelThird()
+	other, cancelOther := subscriptionContext("other@example.com")
+	defer cancelOther()
+
+	for _, ctx := range []context.Context{first, second, other} {
+		if response := limiter.InterceptOperation(ctx, next)(ctx); len(response.Errors) != 0 {
+			t.Fatalf("unexpected errors %v", response.Errors)
+		}
+	}
+
+	response := limiter.InterceptOperation(third, next)(third)
+	if len(response.Errors) != 1 || response.Errors[0].Extensions["code"] != CodeTooManySubscriptions {
+		t.Fatalf("expected subscription over the limit to be rejected, but got %v", response.Errors)
+	}
+
+	cancelFirst()
+	deadline := time.Now().Add(time.Second)
+	for limiter.Active("user@example.com") != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected finished subscription to release its place")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if response := limiter.InterceptOperation(third, next)(third); len(response.Errors) != 0 {
+		t.Errorf("expected subscription to fit after release, but got %v", response.Errors)
+	}
+
+	query := graphql.WithOperationContext(context.Background(), &graphql.OperationContext{
+		Operation: &ast.OperationDefinition{Operation: ast.Query},
+	})
+	query = auth.WithPrincipal(query, &auth.Principal{Email: "user@example.com"})
+	if response := limiter.InterceptOperation(query, next)(query); len(response.Errors) != 0 {
+		t.Errorf("expected queries not to be limited, but got %v", response.Errors)
+	}
+}
@@ -0,0 +1,232 @@
+// Package usage учитывает использование API клиентами: число операций, ошибок и объем
+// отданных данных по часам. Счетчики накапливаются в памяти и периодически сохраняются в базу.
+package usage
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// OperationInvalid операция запроса, который не удалось разобрать
+const OperationInvalid = "<invalid>"
+
+// maxOperationLength длина имени операции в учете; длинные имена обрезаются
+const maxOperationLength = 255
+
+type key struct {
+	clientKind string
+	clientID   string
+	hour       time.Time
+	operation  string
+}
+
+type counters struct {
+	email         string
+	requests      int64
+	errors        int64
+	responseBytes int64
+}
+
+// Recorder накапливает использование в памяти, чтобы запросы не ждали записи счетчиков
+type Recorder struct {
+	repo repository.UsageRepository
+	now  func() time.Time
+
+	mu      sync.Mutex
+	pending map[key]*counters
+}
+
+func NewRecorder(repo repository.UsageRepository) *Recorder {
+	return &Recorder{
+		repo:    repo,
+		now:     time.Now,
+		pending: make(map[key]*counters),
+	}
+}
+
+// Client вид и идентификатор клиента запроса; ok false для анонимных запросов
+func Client(principal *auth.Principal) (kind, id string, ok bool) {
+	if principal == nil || principal.Email == "" {
+		return "", "", false
+	}
+	if principal.Scope != nil && principal.Scope.KeyID != "" {
+		return repository.ClientKindAPIKey, principal.Scope.KeyID, true
+	}
+	return repository.ClientKindUser, strings.ToLower(principal.Email), true
+}
+
+// Record учитывает операцию клиента
+func (r *Recorder) Record(principal *auth.Principal, operation string, failed bool, responseBytes int) {
+	kind, id, ok := Client(principal)
+	if !ok {
+		return
+	}
+	if len(operation) > maxOperationLength {
+		operation = operation[:maxOperationLength]
+	}
+
+	k := key{clientKind: kind, clientID: id, hour: r.now().UTC().Truncate(time.Hour), operation: operation}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.pending[k]
+	if !ok {
+		c = &counters{}
+		r.pending[k] = c
+	}
+	c.email = principal.Email
+	c.requests++
+	if failed {
+		c.errors++
+	}
+	c.responseBytes += int64(responseBytes)
+}
+
+// Flush сохраняет накопленные счетчики. При ошибке они возвращаются в буфер
+// и будут сохранены следующим вызовом.
+func (r *Recorder) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = make(map[key]*counters)
+	r.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	records := make([]*repository.UsageRecord, 0, len(pending))
+	for k, c := range pending {
+		records = append(records, &repository.UsageRecord{
+			ClientKind:    k.clientKind,
+			ClientID:      k.clientID,
+			Email:         c.email,
+			Hour:          k.hour,
+			Operation:     k.operation,
+			Requests:      c.requests,
+			Errors:        c.errors,
+			ResponseBytes: c.responseBytes,
+		})
+	}
+	// Одинаковый порядок строк в пачках разных экземпляров исключает взаимные блокировки
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.ClientKind != b.ClientKind {
+			return a.ClientKind < b.ClientKind
+		}
+		if a.ClientID != b.ClientID {
+			return a.ClientID < b.ClientID
+		}
+		if !a.Hour.Equal(b.Hour) {
+			return a.Hour.Before(b.Hour)
+		}
+		return a.Operation < b.Operation
+	})
+
+	if err := r.repo.AddUsage(ctx, records); err != nil {
+		r.mu.Lock()
+		for k, c := range pending {
+			if current, ok := r.pending[k]; ok {
+				current.requests += c.requests
+				current.errors += c.errors
+				current.responseBytes += c.responseBytes
+			} else {
+				r.pending[k] = c
+			}
+		}
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Extension расширение gqlgen, учитывающее каждую операцию /query. Подписки не учитываются:
+// их ответы - события, а не запросы клиента.
+type Extension struct {
+	recorder *Recorder
+}
+
+var (
+	_ graphql.HandlerExtension    = (*Extension)(nil)
+	_ graphql.ResponseInterceptor = (*Extension)(nil)
+)
+
+func NewExtension(recorder *Recorder) *Extension {
+	return &Extension{recorder: recorder}
+}
+
+func (e *Extension) ExtensionName() string {
+	return "UsageAnalytics"
+}
+
+func (e *Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (e *Extension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || resp == nil {
+		return resp
+	}
+
+	operation := OperationInvalid
+	if graphql.HasOperationContext(ctx) {
+		if op := graphql.GetOperationContext(ctx); op.Operation != nil {
+			if op.Operation.Operation == ast.Subscription {
+				return resp
+			}
+			operation = operationName(op)
+		}
+	}
+
+	e.recorder.Record(principal, operation, len(resp.Errors) > 0, len(resp.Data))
+	return resp
+}
+
+// operationName имя операции, а для безымянных - тип и поля верхнего уровня ("query verification,webhooks")
+func operationName(op *graphql.OperationContext) string {
+	if op.OperationName != "" {
+		return op.OperationName
+	}
+	if op.Operation.Name != "" {
+		return op.Operation.Name
+	}
+
+	var fields []string
+	for _, selection := range op.Operation.SelectionSet {
+		if field, ok := selection.(*ast.Field); ok {
+			fields = append(fields, field.Name)
+		}
+	}
+	return string(op.Operation.Operation) + " " + strings.Join(fields, ",")
+}
+
+// FlushJob сохраняет накопленные счетчики и удаляет часы старше срока хранения
+type FlushJob struct {
+	recorder  *Recorder
+	retention time.Duration
+}
+
+func NewFlushJob(recorder *Recorder, retention time.Duration) *FlushJob {
+	return &FlushJob{recorder: recorder, retention: retention}
+}
+
+func (j *FlushJob) Name() string {
+	return "api_usage_flush"
+}
+
+func (j *FlushJob) Run(ctx context.Context) error {
+	if err := j.recorder.Flush(ctx); err != nil {
+		return err
+	}
+	_, err := j.recorder.repo.DeleteBefore(ctx, j.recorder.now().Add(-j.retention))
+	return err
+}
@@ -0,0 +1,154 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/repository"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+type mockUsageRepository struct {
+	addUsageFunc     func(ctx context.Context, records []*repository.UsageRecord) error
+	deletedBefore    time.Time
+	listedClientKind string
+}
+
+func (m *mockUsageRepository) AddUsage(ctx context.Context, records []*repository.UsageRecord) error {
+	return m.addUsageFunc(ctx, records)
+}
+
+func (m *mockUsageRepository) GetClientUsage(ctx context.Context, clientKind, clientID string, since time.Time, topOperations int) (*repository.ClientUsage, error) {
+	return nil, nil
+}
+
+func (m *mockUsageRepository) ListClientUsage(ctx context.Context, since time.Time, limit, topOperations int) ([]*repository.ClientUsage, error) {
+	return nil, nil
+}
+
+func (m *mockUsageRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	m.deletedBefore = before
+	return 0, nil
+}
+
+func TestClient(t *testing.T) {
+	tests := []struct {
+		name         string
+		principal    *auth.Principal
+		expectedKind string
+		expectedID   string
+	}{
+		{name: "anonymous"},
+		{name: "user", principal: &auth.Principal{Email: "User@Bank.ru"}, expectedKind: repository.ClientKindUser, expectedID: "user@bank.ru"},
+		{name: "api_key", principal: &auth.Principal{Email: "svc@bank.ru", Scope: &auth.Scope{KeyID: "key-1"}}, expectedKind: repository.ClientKindAPIKey, expectedID: "key-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, id, ok := Client(tt.principal)
+			if ok != (tt.expectedKind != "") || kind != tt.expectedKind || id != tt.expectedID {
+				t.Errorf("unexpected client %q %q %v", kind, id, ok)
+			}
+		})
+	}
+}
+
+func TestRecorderFlush(t *testing.T) {
+	var saved []*repository.UsageRecord
+	fail := true
+	repo := &mockUsageRepository{
+		addUsageFunc: func(ctx context.Context, records []*repository.UsageRecord) error {
+			if fail {
+				return errors.New("database unavailable")
+			}
+			saved = append(saved, records...)
+			return nil
+		},
+	}
+	recorder := NewRecorder(repo)
+	recorder.now = func() time.Time { return time.Date(2024, 3, 1, 10, 42, 0, 0, time.UTC) }
+
+	user := &auth.Principal{Email: "user@bank.ru"}
+	recorder.Record(user, "GetVerification", false, 100)
+	recorder.Record(user, "GetVerification", true, 20)
+	recorder.Record(nil, "GetVerification", false, 100)
+
+	if err := recorder.Flush(context.Background()); err == nil {
+		t.Fatal("expected flush error")
+	}
+	recorder.Record(user, "GetVerification", false, 30)
+
+	fail = false
+	if err := recorder.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(saved) != 1 {
+		t.Fatalf("expected 1 record, but got %d", len(saved))
+	}
+	record := saved[0]
+	if record.ClientKind != repository.ClientKindUser || record.ClientID != "user@bank.ru" || record.Operation != "GetVerification" ||
+		record.Requests != 3 || record.Errors != 1 || record.ResponseBytes != 150 || !record.Hour.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected record %+v", record)
+	}
+
+	job := NewFlushJob(recorder, 720*time.Hour)
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !repo.deletedBefore.Equal(time.Date(2024, 1, 31, 10, 42, 0, 0, time.UTC)) {
+		t.Errorf("unexpected retention cutoff %v", repo.deletedBefore)
+	}
+}
+
+func TestExtension(t *testing.T) {
+	var saved []*repository.UsageRecord
+	recorder := NewRecorder(&mockUsageRepository{addUsageFunc: func(ctx context.Context, records []*repository.UsageRecord) error {
+		saved = append(saved, records...)
+		return nil
+	}})
+	extension := NewExtension(recorder)
+	principal := &auth.Principal{Email: "svc@bank.ru", Scope: &auth.Scope{KeyID: "key-1"}}
+
+	intercept := func(op *graphql.OperationContext, resp *graphql.Response) {
+		ctx := auth.WithPrincipal(context.Background(), principal)
+		if op != nil {
+			ctx = graphql.WithOperationContext(ctx, op)
+		}
+		extension.InterceptResponse(ctx, func(ctx context.Context) *graphql.Response { return resp })
+	}
+
+	intercept(&graphql.OperationContext{OperationName: "Statuses", Operation: &ast.OperationDefinition{Operation: ast.Query}},
+		&graphql.Response{Data: []byte(`{"a":1}`)})
+	intercept(&graphql.OperationContext{Operation: &ast.OperationDefinition{
+		Operation:    ast.Mutation,
+		SelectionSet: ast.SelectionSet{&ast.Field{Name: "createVerification"}},
+	}}, &graphql.Response{Errors: gqlerror.List{{Message: "inn must be 10 or 12 digits"}}})
+	intercept(&graphql.OperationContext{Operation: &ast.OperationDefinition{Operation: ast.Subscription}}, &graphql.Response{Data: []byte(`1`)})
+	intercept(nil, &graphql.Response{Errors: gqlerror.List{{Message: "syntax error"}}})
+
+	if err := recorder.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	operations := make(map[string]*repository.UsageRecord)
+	for _, record := range saved {
+		operations[record.Operation] = record
+	}
+	if len(operations) != 3 {
+		t.Fatalf("expected 3 operations, but got %+v", operations)
+	}
+	if r := operations["Statuses"]; r == nil || r.ResponseBytes != 7 || r.Errors != 0 || r.ClientID != "key-1" {
+		t.Errorf("unexpected named operation %+v", r)
+	}
+	if r := operations["mutation createVerification"]; r == nil || r.Errors != 1 {
+		t.Errorf("unexpected anonymous operation %+v", r)
+	}
+	if r := operations[OperationInvalid]; r == nil || r.Errors != 1 {
+		t.Errorf("unexpected invalid operation %+v", r)
+	}
+}
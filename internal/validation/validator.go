@@ -0,0 +1,140 @@
+// Package validation проверяет данные, доставленные воркерами, по JSON Schema типа данных.
+package validation
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"scoring_api_gateway/graph/model"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Схемы лежат в schemas/<тип данных>.json
+//
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// Result результат проверки данных одного типа
+type Result struct {
+	DataType model.VerificationDataType
+	Valid    bool
+	Errors   []string
+}
+
+// Validator проверяет данные по скомпилированным схемам
+type Validator struct {
+	schemas map[model.VerificationDataType]*jsonschema.Schema
+	// sources исходные тексты схем для публикации в контрактах
+	sources map[model.VerificationDataType]json.RawMessage
+}
+
+// NewValidator компилирует встроенные схемы всех типов данных
+func NewValidator() (*Validator, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+
+	v := &Validator{
+		schemas: make(map[model.VerificationDataType]*jsonschema.Schema),
+		sources: make(map[model.VerificationDataType]json.RawMessage),
+	}
+	for _, dataType := range model.AllVerificationDataType {
+		name := "schemas/" + string(dataType) + ".json"
+		content, err := schemaFiles.ReadFile(name)
+		if err != nil {
+			// Для типа без схемы данные принимаются без проверки
+			continue
+		}
+		if err := compiler.AddResource(name, bytes.NewReader(content)); err != nil {
+			return nil, fmt.Errorf("failed to load schema %s: %w", name, err)
+		}
+		schema, err := compiler.Compile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile schema %s: %w", name, err)
+		}
+		v.schemas[dataType] = schema
+		v.sources[dataType] = content
+	}
+
+	return v, nil
+}
+
+// AddSchema компилирует схему типа данных, объявленного в конфигурации шлюза
+func (v *Validator) AddSchema(dataType model.VerificationDataType, schema []byte) error {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+
+	name := "custom/" + string(dataType) + ".json"
+	if err := compiler.AddResource(name, bytes.NewReader(schema)); err != nil {
+		return fmt.Errorf("failed to load schema of %s: %w", dataType, err)
+	}
+	compiled, err := compiler.Compile(name)
+	if err != nil {
+		return fmt.Errorf("failed to compile schema of %s: %w", dataType, err)
+	}
+	v.schemas[dataType] = compiled
+	v.sources[dataType] = schema
+	return nil
+}
+
+// Schemas возвращает исходные тексты схем по типам данных
+func (v *Validator) Schemas() map[model.VerificationDataType]json.RawMessage {
+	return v.sources
+}
+
+// Validate проверяет данные типа dataType. Некорректный JSON считается ошибкой валидации.
+func (v *Validator) Validate(dataType model.VerificationDataType, payload string) Result {
+	result := Result{DataType: dataType, Valid: true}
+
+	decoder := json.NewDecoder(strings.NewReader(payload))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		result.Valid = false
+		result.Errors = []string{"payload is not valid JSON"}
+		return result
+	}
+
+	schema, ok := v.schemas[dataType]
+	if !ok {
+		return result
+	}
+
+	if err := schema.Validate(doc); err != nil {
+		result.Valid = false
+		result.Errors = flatten(err)
+	}
+	return result
+}
+
+// flatten превращает дерево ошибок в список "путь: сообщение" по листовым ошибкам
+func flatten(err error) []string {
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return []string{err.Error()}
+	}
+
+	var messages []string
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			location := e.InstanceLocation
+			if location == "" {
+				location = "/"
+			}
+			messages = append(messages, location+": "+e.Message)
+			return
+		}
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(validationErr)
+
+	sort.Strings(messages)
+	return messages
+}
@@ -0,0 +1,111 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"scoring_api_gateway/graph/model"
+)
+
+func TestValidate(t *testing.T) {
+	validator, err := NewValidator()
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		dataType       model.VerificationDataType
+		payload        string
+		expectedValid  bool
+		expectedErrors []string
+	}{
+		{
+			name:          "valid_basic_information",
+			dataType:      model.VerificationDataTypeBasicInformation,
+			payload:       `{"name": "ПАО Сбербанк", "inn": "7707083893", "ogrn": "1027700132195"}`,
+			expectedValid: true,
+		},
+		{
+			name:           "invalid_inn",
+			dataType:       model.VerificationDataTypeBasicInformation,
+			payload:        `{"name": "ПАО Сбербанк", "inn": "77070838"}`,
+			expectedValid:  false,
+			expectedErrors: []string{"/inn: does not match pattern"},
+		},
+		{
+			name:           "wrong_root_type",
+			dataType:       model.VerificationDataTypeActivities,
+			payload:        `[]`,
+			expectedValid:  false,
+			expectedErrors: []string{"/: expected object, but got array"},
+		},
+		{
+			name:           "nested_item_error",
+			dataType:       model.VerificationDataTypeAffiliatedCompanies,
+			payload:        `{"companies": [{"inn": "7707083893", "share": 150}]}`,
+			expectedValid:  false,
+			expectedErrors: []string{"/companies/0/share: must be <= 100"},
+		},
+		{
+			name:           "malformed_json",
+			dataType:       model.VerificationDataTypeArbitrageStatistics,
+			payload:        `{"totalCases": `,
+			expectedValid:  false,
+			expectedErrors: []string{"payload is not valid JSON"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validator.Validate(tt.dataType, tt.payload)
+
+			if result.Valid != tt.expectedValid {
+				t.Fatalf("expected valid=%v, but got %v (errors: %v)", tt.expectedValid, result.Valid, result.Errors)
+			}
+			if len(result.Errors) != len(tt.expectedErrors) {
+				t.Fatalf("expected errors %v, but got %v", tt.expectedErrors, result.Errors)
+			}
+			for i, expected := range tt.expectedErrors {
+				if !strings.HasPrefix(result.Errors[i], expected) {
+					t.Errorf("expected error starting with %q, but got %q", expected, result.Errors[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEverySchemaCompiles(t *testing.T) {
+	validator, err := NewValidator()
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	for _, dataType := range model.AllVerificationDataType {
+		if _, ok := validator.schemas[dataType]; !ok {
+			t.Errorf("no JSON Schema for data type %s", dataType)
+		}
+	}
+}
+
+func TestAddSchema(t *testing.T) {
+	validator, err := NewValidator()
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	creditRating := model.VerificationDataType("CREDIT_RATING")
+	if err := validator.AddSchema(creditRating, []byte(`{"type": "object", "required": ["rating"]}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result := validator.Validate(creditRating, `{"rating": "ruAA"}`); !result.Valid {
+		t.Errorf("expected valid data, but got %v", result.Errors)
+	}
+	if result := validator.Validate(creditRating, `{}`); result.Valid || !strings.HasPrefix(result.Errors[0], "/: missing properties") {
+		t.Errorf("expected a missing property error, but got %v", result.Errors)
+	}
+
+	if err := validator.AddSchema("BROKEN", []byte(`{"type": 5}`)); err == nil {
+		t.Error("expected an error for an invalid schema")
+	}
+}
@@ -0,0 +1,83 @@
+package warehouse
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/metrics"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// ExportJob выгружает изменения после закладки пачками и сдвигает закладку только после того,
+// как приемник принял пачку. Сбой между записью и сохранением закладки приводит к повторной
+// отправке пачки (доставка хотя бы один раз).
+type ExportJob struct {
+	repo   repository.WarehouseRepository
+	sink   Sink
+	cfg    config.WarehouseConfig
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+func NewExportJob(repo repository.WarehouseRepository, sink Sink, cfg config.WarehouseConfig, logger *zap.Logger) *ExportJob {
+	return &ExportJob{
+		repo:   repo,
+		sink:   sink,
+		cfg:    cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func (j *ExportJob) Name() string {
+	return "warehouse_export"
+}
+
+// exporter имя закладки: у каждого приемника своя позиция в потоке
+func (j *ExportJob) exporter() string {
+	return "warehouse_" + j.sink.Name()
+}
+
+func (j *ExportJob) Run(ctx context.Context) error {
+	position, err := j.repo.GetBookmark(ctx, j.exporter())
+	if err != nil {
+		return err
+	}
+
+	settledBefore := j.now().Add(-j.cfg.SettleDelay)
+	var exported int
+	for ctx.Err() == nil {
+		changes, err := j.repo.GetChanges(ctx, position, settledBefore, j.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			break
+		}
+
+		if err := j.sink.Write(ctx, changes); err != nil {
+			return fmt.Errorf("failed to export warehouse changes after %d: %w", position, err)
+		}
+
+		last := changes[len(changes)-1].Seq
+		if err := j.repo.SaveBookmark(ctx, j.exporter(), last); err != nil {
+			return err
+		}
+		position = last
+		exported += len(changes)
+		metrics.WarehouseExportedChanges.WithLabelValues(j.sink.Name()).Add(float64(len(changes)))
+
+		if len(changes) < j.cfg.BatchSize {
+			break
+		}
+	}
+
+	if exported > 0 {
+		j.logger.Info("warehouse changes exported", zap.String("sink", j.sink.Name()), zap.Int("count", exported), zap.Int64("position", position))
+	}
+	return nil
+}
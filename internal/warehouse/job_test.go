@@ -0,0 +1,97 @@
+package warehouse
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/repository"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type memoryWarehouseRepository struct {
+	changes   []*repository.WarehouseChange
+	bookmarks map[string]int64
+}
+
+func (r *memoryWarehouseRepository) GetChanges(ctx context.Context, after int64, settledBefore time.Time, limit int) ([]*repository.WarehouseChange, error) {
+	var changes []*repository.WarehouseChange
+	for _, change := range r.changes {
+		if change.Seq > after && !change.RecordedAt.After(settledBefore) && len(changes) < limit {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+func (r *memoryWarehouseRepository) GetBookmark(ctx context.Context, exporter string) (int64, error) {
+	return r.bookmarks[exporter], nil
+}
+
+func (r *memoryWarehouseRepository) SaveBookmark(ctx context.Context, exporter string, position int64) error {
+	r.bookmarks[exporter] = position
+	return nil
+}
+
+type recordingSink struct {
+	batches [][]int64
+	failAt  int
+}
+
+func (s *recordingSink) Name() string {
+	return "test"
+}
+
+func (s *recordingSink) Write(ctx context.Context, changes []*repository.WarehouseChange) error {
+	if s.failAt > 0 && len(s.batches)+1 == s.failAt {
+		s.failAt = 0
+		return errors.New("sink unavailable")
+	}
+	var seqs []int64
+	for _, change := range changes {
+		seqs = append(seqs, change.Seq)
+	}
+	s.batches = append(s.batches, seqs)
+	return nil
+}
+
+func TestExportJob(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	repo := &memoryWarehouseRepository{bookmarks: map[string]int64{}}
+	for seq := int64(1); seq <= 5; seq++ {
+		repo.changes = append(repo.changes, &repository.WarehouseChange{Seq: seq, Type: "verification", VerificationID: "v1", RecordedAt: now.Add(-time.Minute)})
+	}
+	// Изменение моложе settle_delay ждет следующего запуска
+	repo.changes = append(repo.changes, &repository.WarehouseChange{Seq: 6, Type: "data_arrival", VerificationID: "v1", RecordedAt: now})
+
+	sink := &recordingSink{failAt: 2}
+	job := NewExportJob(repo, sink, config.WarehouseConfig{BatchSize: 2, SettleDelay: 5 * time.Second}, zaptest.NewLogger(t))
+	job.now = func() time.Time { return now }
+
+	if err := job.Run(context.Background()); err == nil {
+		t.Fatal("expected sink error, but got nil")
+	}
+	if got := repo.bookmarks["warehouse_test"]; got != 2 {
+		t.Fatalf("expected bookmark to stay at the last accepted batch 2, but got %d", got)
+	}
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := repo.bookmarks["warehouse_test"]; got != 5 {
+		t.Errorf("expected bookmark 5, but got %d", got)
+	}
+
+	expected := [][]int64{{1, 2}, {3, 4}, {5}}
+	if len(sink.batches) != len(expected) {
+		t.Fatalf("expected batches %v, but got %v", expected, sink.batches)
+	}
+	for i := range expected {
+		if len(sink.batches[i]) != len(expected[i]) || sink.batches[i][0] != expected[i][0] {
+			t.Errorf("batch %d: expected %v, but got %v", i, expected[i], sink.batches[i])
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Package warehouse выгружает поток изменений проверок в аналитическое хранилище.
+package warehouse
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/objectstore"
+	"scoring_api_gateway/internal/repository"
+)
+
+// Sink приемник пачек изменений. Write либо принимает пачку целиком, либо возвращает ошибку:
+// тогда пачка будет отправлена повторно, поэтому приемник должен допускать дубликаты по seq.
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, changes []*repository.WarehouseChange) error
+}
+
+// NewSink создает приемник, выбранный в конфигурации
+func NewSink(cfg config.WarehouseConfig) (Sink, error) {
+	switch cfg.Sink {
+	case config.WarehouseSinkKafka:
+		return NewKafkaSink(cfg.Kafka), nil
+	case config.WarehouseSinkS3:
+		return NewS3Sink(cfg.S3), nil
+	default:
+		return nil, fmt.Errorf("unknown warehouse sink %q", cfg.Sink)
+	}
+}
+
+type kafkaSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewKafkaSink публикует изменения в топик через Kafka REST Proxy (API v2).
+// Ключ сообщения - идентификатор проверки, поэтому изменения одной проверки попадают в одну партицию по порядку.
+func NewKafkaSink(cfg config.WarehouseKafkaConfig) Sink {
+	return &kafkaSink{
+		url:    strings.TrimRight(cfg.RestURL, "/") + "/topics/" + cfg.Topic,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (s *kafkaSink) Name() string {
+	return config.WarehouseSinkKafka
+}
+
+type kafkaRecord struct {
+	Key   string                      `json:"key"`
+	Value *repository.WarehouseChange `json:"value"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition int     `json:"partition"`
+		ErrorCode *int    `json:"error_code"`
+		Error     *string `json:"error"`
+	} `json:"offsets"`
+}
+
+func (s *kafkaSink) Write(ctx context.Context, changes []*repository.WarehouseChange) error {
+	records := make([]kafkaRecord, 0, len(changes))
+	for _, change := range changes {
+		records = append(records, kafkaRecord{Key: change.VerificationID, Value: change})
+	}
+	body, err := json.Marshal(map[string]any{"records": records})
+	if err != nil {
+		return fmt.Errorf("failed to marshal kafka records: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create kafka request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to produce to kafka: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("kafka rest proxy returned %d: %s", resp.StatusCode, strings.TrimSpace(string(text)))
+	}
+
+	// Прокси отвечает 200 и при частичной неудаче: ошибки указываются по каждой записи
+	var produced kafkaProduceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&produced); err != nil {
+		return fmt.Errorf("failed to decode kafka response: %w", err)
+	}
+	for i, offset := range produced.Offsets {
+		if offset.ErrorCode != nil || offset.Error != nil {
+			var message string
+			if offset.Error != nil {
+				message = *offset.Error
+			}
+			return fmt.Errorf("kafka rejected record %d: %s", i, message)
+		}
+	}
+	return nil
+}
+
+type s3Sink struct {
+	cfg    config.S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Sink сохраняет каждую пачку отдельным объектом JSON Lines в gzip. Имя объекта
+// определяется диапазоном seq, поэтому повторная отправка пачки перезаписывает тот же объект.
+// Объекты разложены по дням (dt=YYYY-MM-DD) для загрузки в хранилище партициями.
+func NewS3Sink(cfg config.S3Config) Sink {
+	return &s3Sink{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		now:    time.Now,
+	}
+}
+
+func (s *s3Sink) Name() string {
+	return config.WarehouseSinkS3
+}
+
+// objectKey имя объекта пачки
+func (s *s3Sink) objectKey(changes []*repository.WarehouseChange) string {
+	first, last := changes[0], changes[len(changes)-1]
+	key := fmt.Sprintf("dt=%s/%020d-%020d.jsonl.gz", first.RecordedAt.UTC().Format("2006-01-02"), first.Seq, last.Seq)
+	if prefix := strings.Trim(s.cfg.Prefix, "/"); prefix != "" {
+		key = prefix + "/" + key
+	}
+	return key
+}
+
+func (s *s3Sink) Write(ctx context.Context, changes []*repository.WarehouseChange) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+	for _, change := range changes {
+		if err := encoder.Encode(change); err != nil {
+			return fmt.Errorf("failed to encode warehouse change %d: %w", change.Seq, err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress warehouse batch: %w", err)
+	}
+
+	url := strings.TrimRight(s.cfg.Endpoint, "/") + "/" + s.cfg.Bucket + "/" + s.objectKey(changes)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return fmt.Errorf("failed to create s3 request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Content-Encoding", "gzip")
+	if s.cfg.AccessKeyID != "" {
+		objectstore.SignV4(req, buf.Bytes(), s.cfg, s.now().UTC())
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload warehouse batch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("s3 returned %d: %s", resp.StatusCode, strings.TrimSpace(string(text)))
+	}
+	return nil
+}
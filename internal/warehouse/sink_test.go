@@ -0,0 +1,112 @@
+package warehouse
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/repository"
+)
+
+func testChanges() []*repository.WarehouseChange {
+	recordedAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	return []*repository.WarehouseChange{
+		{Seq: 41, Type: "verification", VerificationID: "v1", Payload: json.RawMessage(`{"status":"IN_PROCESS"}`), RecordedAt: recordedAt},
+		{Seq: 42, Type: "data_arrival", VerificationID: "v1", Payload: json.RawMessage(`{"data_type":"BASIC_INFORMATION"}`), RecordedAt: recordedAt},
+	}
+}
+
+func TestKafkaSink(t *testing.T) {
+	tests := []struct {
+		name          string
+		response      string
+		expectedError string
+	}{
+		{name: "accepted", response: `{"offsets":[{"partition":0,"offset":10},{"partition":0,"offset":11}]}`},
+		{name: "record_rejected", response: `{"offsets":[{"partition":0,"offset":10},{"partition":null,"error_code":50002,"error":"broker unavailable"}]}`, expectedError: "kafka rejected record 1: broker unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body struct {
+				Records []kafkaRecord `json:"records"`
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/topics/scoring.verifications" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("failed to decode records: %v", err)
+				}
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			sink := NewKafkaSink(config.WarehouseKafkaConfig{RestURL: server.URL + "/", Topic: "scoring.verifications", Timeout: time.Second})
+			err := sink.Write(context.Background(), testChanges())
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Fatalf("expected error %q, but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(body.Records) != 2 || body.Records[0].Key != "v1" || body.Records[1].Value.Seq != 42 {
+				t.Errorf("unexpected records: %+v", body.Records)
+			}
+		})
+	}
+}
+
+func TestS3Sink(t *testing.T) {
+	var path, authorization string
+	var lines []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		authorization = r.Header.Get("Authorization")
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("expected gzip body: %v", err)
+			return
+		}
+		scanner := bufio.NewScanner(gz)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+	}))
+	defer server.Close()
+
+	sink := NewS3Sink(config.S3Config{
+		Endpoint:        server.URL,
+		Region:          "eu-central-1",
+		Bucket:          "analytics",
+		Prefix:          "/verifications/",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+		Timeout:         time.Second,
+	}).(*s3Sink)
+	sink.now = func() time.Time { return time.Date(2024, 1, 16, 8, 30, 0, 0, time.UTC) }
+
+	if err := sink.Write(context.Background(), testChanges()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := "/analytics/verifications/dt=2024-01-15/00000000000000000041-00000000000000000042.jsonl.gz"; path != expected {
+		t.Errorf("expected object %s, but got %s", expected, path)
+	}
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240116/eu-central-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") {
+		t.Errorf("unexpected authorization header %q", authorization)
+	}
+	if len(lines) != 2 || !strings.Contains(lines[1], `"seq":42`) {
+		t.Errorf("unexpected batch lines: %v", lines)
+	}
+}
@@ -0,0 +1,417 @@
+// Package webhook формирует и отправляет запросы вебхуков о завершении проверок и изменениях
+// данных компаний на мониторинге.
+// Тело запроса строится по шаблону регистрации, поэтому получатели принимают его в своем формате
+// без промежуточных преобразований.
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/companychange"
+)
+
+// События вебхуков
+const (
+	// EventVerificationCompleted событие завершения проверки
+	EventVerificationCompleted = "verification.completed"
+	// EventCompanyChanged повторная проверка мониторинга нашла существенные изменения данных компании
+	EventCompanyChanged = "company.changed"
+)
+
+// Ограничения пользовательских шаблонов
+const (
+	MaxTemplateBytes = 16 * 1024
+	MaxBodyBytes     = 1024 * 1024
+	MaxHeaderBytes   = 4 * 1024
+	MaxHeaders       = 20
+)
+
+const (
+	contentTypeJSON        = "application/json"
+	contentTypeCloudEvents = "application/cloudevents+json"
+	cloudEventsSpecVersion = "1.0"
+	cloudEventsTypePrefix  = "ru.scoring."
+)
+
+// Заголовки, которые формирует HTTP-клиент и которые нельзя задать в регистрации
+var reservedHeaders = map[string]bool{
+	"Host":              true,
+	"Content-Length":    true,
+	"Transfer-Encoding": true,
+	"Connection":        true,
+}
+
+// Payload событие, о котором уведомляется вебхук
+type Payload struct {
+	Event        string
+	OccurredAt   time.Time
+	Verification *model.Verification
+	// PreviousVerificationID и Changes заполняются для EventCompanyChanged: предыдущая проверка
+	// компании и существенные изменения данных относительно нее
+	PreviousVerificationID string
+	Changes                []companychange.Change
+}
+
+// Renderer формирует запросы вебхуков
+type Renderer struct {
+	source string
+}
+
+// NewRenderer создает формирователь запросов. source - атрибут source конвертов CloudEvents.
+func NewRenderer(source string) *Renderer {
+	return &Renderer{source: source}
+}
+
+// Validate проверяет регистрацию вебхука: адрес, шаблон тела и заголовки
+func Validate(input model.WebhookInput) error {
+	target, err := url.Parse(input.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("webhook url must be an absolute http or https url")
+	}
+
+	if !input.Template.IsValid() {
+		return fmt.Errorf("unknown webhook template %q", input.Template)
+	}
+	for _, event := range input.Events {
+		if !event.IsValid() {
+			return fmt.Errorf("unknown webhook event %q", event)
+		}
+	}
+	if input.Template == model.WebhookTemplateCustom {
+		if input.CustomTemplate == nil || strings.TrimSpace(*input.CustomTemplate) == "" {
+			return fmt.Errorf("customTemplate is required for the CUSTOM template")
+		}
+		if _, err := parseTemplate("body", *input.CustomTemplate); err != nil {
+			return err
+		}
+	} else if input.CustomTemplate != nil {
+		return fmt.Errorf("customTemplate is allowed only for the CUSTOM template")
+	}
+
+	if len(input.Headers) > MaxHeaders {
+		return fmt.Errorf("too many webhook headers: %d, at most %d", len(input.Headers), MaxHeaders)
+	}
+	for _, header := range input.Headers {
+		name := http.CanonicalHeaderKey(header.Name)
+		if !validHeaderName(name) {
+			return fmt.Errorf("invalid header name %q", header.Name)
+		}
+		if reservedHeaders[name] {
+			return fmt.Errorf("header %s cannot be set", name)
+		}
+		if _, err := parseTemplate(name, header.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Render формирует запрос вебхука о событии payload
+func (r *Renderer) Render(hook *model.Webhook, payload Payload) (*model.WebhookPreview, error) {
+	data, err := templateData(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &model.WebhookPreview{ContentType: contentTypeJSON, Headers: []*model.WebhookHeader{}}
+	var body any
+	switch hook.Template {
+	case model.WebhookTemplateFull:
+		body = data
+	case model.WebhookTemplateSummary:
+		body = summary(payload)
+	case model.WebhookTemplateCloudevents:
+		request.ContentType = contentTypeCloudEvents
+		body = r.cloudEvent(payload, cloudEventData(payload, data))
+	case model.WebhookTemplateCustom:
+		if hook.CustomTemplate == nil {
+			return nil, fmt.Errorf("webhook %s has no custom template", hook.ID)
+		}
+		rendered, err := execute("body", *hook.CustomTemplate, data, MaxBodyBytes)
+		if err != nil {
+			return nil, err
+		}
+		request.Body = rendered
+	default:
+		return nil, fmt.Errorf("unknown webhook template %q", hook.Template)
+	}
+
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal webhook body: %w", err)
+		}
+		request.Body = string(encoded)
+	}
+
+	for _, header := range hook.Headers {
+		name := http.CanonicalHeaderKey(header.Name)
+		value, err := execute(name, header.Value, data, MaxHeaderBytes)
+		if err != nil {
+			return nil, err
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("header %s rendered to a value with a line break", name)
+		}
+		// Получатели, ожидающие другой тип тела, указывают его заголовком
+		if name == "Content-Type" {
+			request.ContentType = value
+			continue
+		}
+		request.Headers = append(request.Headers, &model.WebhookHeader{Name: name, Value: value})
+	}
+
+	return request, nil
+}
+
+// templateData данные шаблонов: {event, occurredAt, verification}, для изменения компании еще
+// previousVerificationId и changes. Проверка передается в том же виде, что и в GraphQL API,
+// а доставленные данные - разобранным JSON, а не строкой.
+func templateData(payload Payload) (map[string]any, error) {
+	encoded, err := json.Marshal(payload.Verification)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal verification: %w", err)
+	}
+	var verification map[string]any
+	if err := json.Unmarshal(encoded, &verification); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal verification: %w", err)
+	}
+
+	if items, ok := verification["data"].([]any); ok {
+		for _, item := range items {
+			entry, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			raw, ok := entry["data"].(string)
+			if !ok {
+				continue
+			}
+			var parsed any
+			if err := json.Unmarshal([]byte(raw), &parsed); err == nil {
+				entry["data"] = parsed
+			}
+		}
+	}
+
+	data := map[string]any{
+		"event":        payload.Event,
+		"occurredAt":   payload.OccurredAt.UTC().Format(time.RFC3339),
+		"verification": verification,
+	}
+	if payload.Event == EventCompanyChanged {
+		changes, err := changesData(payload.Changes)
+		if err != nil {
+			return nil, err
+		}
+		data["previousVerificationId"] = payload.PreviousVerificationID
+		data["changes"] = changes
+	}
+	return data, nil
+}
+
+// changesData изменения в виде, доступном шаблонам: значения до и после - разобранный JSON
+func changesData(changes []companychange.Change) ([]any, error) {
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal company changes: %w", err)
+	}
+	result := []any{}
+	if err := json.Unmarshal(encoded, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal company changes: %w", err)
+	}
+	return result, nil
+}
+
+func summary(payload Payload) map[string]any {
+	v := payload.Verification
+	body := map[string]any{
+		"event":          payload.Event,
+		"occurredAt":     payload.OccurredAt.UTC().Format(time.RFC3339),
+		"verificationId": v.ID,
+		"inn":            v.Inn,
+		"status":         v.Status,
+		"riskLevel":      v.RiskLevel,
+	}
+	if payload.Event == EventCompanyChanged {
+		body["previousVerificationId"] = payload.PreviousVerificationID
+		body["changes"] = payload.Changes
+	}
+	return body
+}
+
+// cloudEventData данные конверта CloudEvents: проверка, а для изменения компании - проверка
+// вместе с изменениями
+func cloudEventData(payload Payload, data map[string]any) any {
+	if payload.Event != EventCompanyChanged {
+		return data["verification"]
+	}
+	return map[string]any{
+		"verification":           data["verification"],
+		"previousVerificationId": data["previousVerificationId"],
+		"changes":                data["changes"],
+	}
+}
+
+// cloudEvent конверт CloudEvents 1.0 в структурированном режиме. Идентификатор события
+// определяется проверкой и временем события, поэтому повторная доставка не создает новое событие.
+func (r *Renderer) cloudEvent(payload Payload, data any) map[string]any {
+	occurredAt := payload.OccurredAt.UTC().Format(time.RFC3339)
+	return map[string]any{
+		"specversion":     cloudEventsSpecVersion,
+		"id":              payload.Verification.ID + "/" + occurredAt,
+		"source":          r.source,
+		"type":            cloudEventsTypePrefix + payload.Event,
+		"subject":         payload.Verification.ID,
+		"time":            occurredAt,
+		"datacontenttype": contentTypeJSON,
+		"data":            data,
+	}
+}
+
+// funcs функции, доступные шаблонам. Встроенная call переопределена, чтобы шаблон
+// не мог вызывать функции из данных.
+var funcs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		encoded, err := json.Marshal(v)
+		return string(encoded), err
+	},
+	"default": func(fallback, v any) any {
+		if v == nil || v == "" {
+			return fallback
+		}
+		return v
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	"join": func(sep string, items []any) string {
+		parts := make([]string, 0, len(items))
+		for _, item := range items {
+			parts = append(parts, fmt.Sprint(item))
+		}
+		return strings.Join(parts, sep)
+	},
+	"formatTime": func(layout, value string) (string, error) {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return "", err
+		}
+		return parsed.Format(layout), nil
+	},
+	"call": func(any, ...any) (any, error) {
+		return nil, errors.New("call is not allowed in webhook templates")
+	},
+}
+
+// parseTemplate разбирает шаблон и отклоняет конструкции, время выполнения которых
+// не ограничено размером данных: вложенные шаблоны и range по вычисляемым значениям
+func parseTemplate(name, text string) (*template.Template, error) {
+	if len(text) > MaxTemplateBytes {
+		return nil, fmt.Errorf("template %s exceeds %d bytes", name, MaxTemplateBytes)
+	}
+	tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", name, err)
+	}
+	if len(tmpl.Templates()) > 1 {
+		return nil, fmt.Errorf("invalid template %s: define and block are not allowed", name)
+	}
+	if tmpl.Tree != nil {
+		if err := checkNode(tmpl.Tree.Root); err != nil {
+			return nil, fmt.Errorf("invalid template %s: %w", name, err)
+		}
+	}
+	return tmpl, nil
+}
+
+func checkNode(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkNode(child); err != nil {
+				return err
+			}
+		}
+	case *parse.TemplateNode:
+		return errors.New("template calls are not allowed")
+	case *parse.IfNode:
+		return checkBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return checkBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		if !rangesOverData(n.Pipe) {
+			return errors.New("range is allowed only over fields of the payload")
+		}
+		return checkBranch(&n.BranchNode)
+	}
+	return nil
+}
+
+func checkBranch(branch *parse.BranchNode) error {
+	if err := checkNode(branch.List); err != nil {
+		return err
+	}
+	return checkNode(branch.ElseList)
+}
+
+func rangesOverData(pipe *parse.PipeNode) bool {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	switch pipe.Cmds[0].Args[0].(type) {
+	case *parse.FieldNode, *parse.VariableNode, *parse.ChainNode, *parse.DotNode:
+		return true
+	}
+	return false
+}
+
+func execute(name, text string, data any, limit int) (string, error) {
+	tmpl, err := parseTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	out := &limitedBuffer{limit: limit}
+	if err := tmpl.Execute(out, data); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return out.String(), nil
+}
+
+// limitedBuffer прерывает выполнение шаблона, результат которого превысил limit байт
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, fmt.Errorf("rendered output exceeds %d bytes", b.limit)
+	}
+	return b.Buffer.Write(p)
+}
+
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if c > 127 || !(c == '-' || c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,257 @@
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/companychange"
+)
+
+func testPayload() Payload {
+	riskLevel := model.RiskLevelHigh
+	return Payload{
+		Event:      EventVerificationCompleted,
+		OccurredAt: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+		Verification: &model.Verification{
+			ID:        "v1",
+			Inn:       "7707083893",
+			Status:    model.VerificationStatusCompleted,
+			RiskLevel: &riskLevel,
+			Data: []*model.VerificationData{
+				{DataType: model.VerificationDataTypeBasicInformation, Data: `{"name":"ПАО Сбербанк"}`},
+			},
+		},
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestRender(t *testing.T) {
+	renderer := NewRenderer("/scoring-api-gateway")
+
+	tests := []struct {
+		name                string
+		hook                *model.Webhook
+		expectedContentType string
+		check               func(t *testing.T, body map[string]any)
+	}{
+		{
+			name:                "full",
+			hook:                &model.Webhook{Template: model.WebhookTemplateFull},
+			expectedContentType: "application/json",
+			check: func(t *testing.T, body map[string]any) {
+				verification := body["verification"].(map[string]any)
+				data := verification["data"].([]any)[0].(map[string]any)["data"].(map[string]any)
+				if body["event"] != EventVerificationCompleted || data["name"] != "ПАО Сбербанк" {
+					t.Errorf("unexpected body %v", body)
+				}
+			},
+		},
+		{
+			name:                "summary",
+			hook:                &model.Webhook{Template: model.WebhookTemplateSummary},
+			expectedContentType: "application/json",
+			check: func(t *testing.T, body map[string]any) {
+				if body["verificationId"] != "v1" || body["riskLevel"] != "HIGH" || body["verification"] != nil {
+					t.Errorf("unexpected body %v", body)
+				}
+			},
+		},
+		{
+			name:                "cloudevents",
+			hook:                &model.Webhook{Template: model.WebhookTemplateCloudevents},
+			expectedContentType: "application/cloudevents+json",
+			check: func(t *testing.T, body map[string]any) {
+				if body["specversion"] != "1.0" || body["type"] != "ru.scoring.verification.completed" ||
+					body["source"] != "/scoring-api-gateway" || body["id"] != "v1/2024-01-15T10:00:00Z" {
+					t.Errorf("unexpected envelope %v", body)
+				}
+				if data := body["data"].(map[string]any); data["inn"] != "7707083893" {
+					t.Errorf("unexpected data %v", data)
+				}
+			},
+		},
+		{
+			name: "custom",
+			hook: &model.Webhook{
+				Template:       model.WebhookTemplateCustom,
+				CustomTemplate: stringPtr(`{"text": {{json (printf "%s: %s" .verification.inn (lower .verification.status))}}, "risk": {{json .verification.riskLevel}}, "ref": {{json .verification.externalRef}}}`),
+				Headers:        []*model.WebhookHeader{{Name: "Content-Type", Value: "application/vnd.crm+json"}},
+			},
+			expectedContentType: "application/vnd.crm+json",
+			check: func(t *testing.T, body map[string]any) {
+				if body["text"] != "7707083893: completed" || body["risk"] != "HIGH" || body["ref"] != nil {
+					t.Errorf("unexpected body %v", body)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := renderer.Render(tt.hook, testPayload())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if request.ContentType != tt.expectedContentType {
+				t.Errorf("expected content type %s, but got %s", tt.expectedContentType, request.ContentType)
+			}
+
+			var body map[string]any
+			if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+				t.Fatalf("body is not JSON: %v\n%s", err, request.Body)
+			}
+			tt.check(t, body)
+		})
+	}
+}
+
+func TestRenderHeaders(t *testing.T) {
+	hook := &model.Webhook{
+		Template: model.WebhookTemplateSummary,
+		Headers: []*model.WebhookHeader{
+			{Name: "X-Event", Value: "{{.event}}"},
+			{Name: "X-Verification-Id", Value: "{{.verification.id}}"},
+		},
+	}
+
+	request, err := NewRenderer("").Render(hook, testPayload())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(request.Headers) != 2 || request.Headers[0].Value != EventVerificationCompleted || request.Headers[1].Value != "v1" {
+		t.Errorf("unexpected headers %+v %+v", request.Headers[0], request.Headers[1])
+	}
+
+	hook.Headers = []*model.WebhookHeader{{Name: "X-Inn", Value: "{{.verification.inn}}\r\nX-Injected: 1"}}
+	if _, err := NewRenderer("").Render(hook, testPayload()); err == nil {
+		t.Error("expected error for a header value with a line break")
+	}
+}
+
+func TestRenderCompanyChanged(t *testing.T) {
+	payload := testPayload()
+	payload.Event = EventCompanyChanged
+	payload.PreviousVerificationID = "v0"
+	payload.Changes = []companychange.Change{{
+		DataType: model.VerificationDataTypeBasicInformation,
+		Field:    "address.city",
+		Before:   json.RawMessage(`"Москва"`),
+		After:    json.RawMessage(`"Казань"`),
+	}}
+
+	tests := []struct {
+		name    string
+		hook    *model.Webhook
+		changes func(body map[string]any) any
+	}{
+		{name: "full", hook: &model.Webhook{Template: model.WebhookTemplateFull}, changes: func(body map[string]any) any { return body["changes"] }},
+		{name: "summary", hook: &model.Webhook{Template: model.WebhookTemplateSummary}, changes: func(body map[string]any) any { return body["changes"] }},
+		{
+			name: "cloudevents",
+			hook: &model.Webhook{Template: model.WebhookTemplateCloudevents},
+			changes: func(body map[string]any) any {
+				if body["type"] != "ru.scoring.company.changed" {
+					return nil
+				}
+				return body["data"].(map[string]any)["changes"]
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := NewRenderer("/scoring").Render(tt.hook, payload)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var body map[string]any
+			if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+				t.Fatalf("body is not JSON: %v\n%s", err, request.Body)
+			}
+
+			changes, ok := tt.changes(body).([]any)
+			if !ok || len(changes) != 1 {
+				t.Fatalf("expected one change, but got body %v", body)
+			}
+			change := changes[0].(map[string]any)
+			if change["field"] != "address.city" || change["before"] != "Москва" || change["after"] != "Казань" {
+				t.Errorf("unexpected change %v", change)
+			}
+		})
+	}
+
+	hook := &model.Webhook{
+		Template:       model.WebhookTemplateCustom,
+		CustomTemplate: stringPtr(`{{range .changes}}{{.field}}: {{.before}} -> {{.after}}{{end}}`),
+	}
+	request, err := NewRenderer("").Render(hook, payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if request.Body != "address.city: Москва -> Казань" {
+		t.Errorf("unexpected custom body %q", request.Body)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         model.WebhookInput
+		expectedError string
+	}{
+		{name: "full", input: model.WebhookInput{URL: "https://crm.example.com/hooks", Template: model.WebhookTemplateFull}},
+		{name: "custom", input: model.WebhookInput{URL: "http://crm.local/hooks", Template: model.WebhookTemplateCustom, CustomTemplate: stringPtr(`{{range .verification.data}}{{.dataType}}{{end}}`)}},
+		{name: "unknown_event", input: model.WebhookInput{URL: "https://crm.example.com/hooks", Template: model.WebhookTemplateFull, Events: []model.WebhookEvent{"COMPANY_DELETED"}}, expectedError: "unknown webhook event"},
+		{name: "relative_url", input: model.WebhookInput{URL: "/hooks", Template: model.WebhookTemplateFull}, expectedError: "absolute http or https url"},
+		{name: "custom_without_template", input: model.WebhookInput{URL: "https://crm.example.com", Template: model.WebhookTemplateCustom}, expectedError: "customTemplate is required"},
+		{name: "template_for_builtin", input: model.WebhookInput{URL: "https://crm.example.com", Template: model.WebhookTemplateSummary, CustomTemplate: stringPtr("{{.event}}")}, expectedError: "only for the CUSTOM template"},
+		{name: "syntax_error", input: model.WebhookInput{URL: "https://crm.example.com", Template: model.WebhookTemplateCustom, CustomTemplate: stringPtr("{{.event")}, expectedError: "invalid template body"},
+		{name: "unknown_function", input: model.WebhookInput{URL: "https://crm.example.com", Template: model.WebhookTemplateCustom, CustomTemplate: stringPtr(`{{env "DATABASE_PASSWORD"}}`)}, expectedError: `function "env" not defined`},
+		{name: "define", input: model.WebhookInput{URL: "https://crm.example.com", Template: model.WebhookTemplateCustom, CustomTemplate: stringPtr(`{{define "x"}}{{template "x"}}{{end}}{{template "x"}}`)}, expectedError: "not allowed"},
+		{name: "range_over_number", input: model.WebhookInput{URL: "https://crm.example.com", Template: model.WebhookTemplateCustom, CustomTemplate: stringPtr(`{{range 1000000000}}{{end}}`)}, expectedError: "range is allowed only over fields"},
+		{name: "reserved_header", input: model.WebhookInput{URL: "https://crm.example.com", Template: model.WebhookTemplateFull, Headers: []*model.WebhookHeaderInput{{Name: "host", Value: "evil"}}}, expectedError: "header Host cannot be set"},
+		{name: "invalid_header_name", input: model.WebhookInput{URL: "https://crm.example.com", Template: model.WebhookTemplateFull, Headers: []*model.WebhookHeaderInput{{Name: "X Bad", Value: "1"}}}, expectedError: "invalid header name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.input)
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Fatalf("expected error containing %q, but got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestRenderSandbox(t *testing.T) {
+	tests := []struct {
+		name          string
+		template      string
+		expectedError string
+	}{
+		{name: "call", template: `{{call .verification.id}}`, expectedError: "call is not allowed"},
+		{name: "output_limit", template: `{{range .verification.data}}{{printf "%2000000s" "x"}}{{end}}`, expectedError: "exceeds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := &model.Webhook{Template: model.WebhookTemplateCustom, CustomTemplate: stringPtr(tt.template)}
+			_, err := NewRenderer("").Render(hook, testPayload())
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Fatalf("expected error containing %q, but got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
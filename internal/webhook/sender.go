@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"scoring_api_gateway/graph/model"
+)
+
+// Sender отправляет сформированный запрос вебхука
+type Sender interface {
+	Send(ctx context.Context, url string, request *model.WebhookPreview) error
+}
+
+type httpSender struct {
+	client *http.Client
+}
+
+// NewSender создает отправителя, считающего доставку успешной при любом ответе 2xx
+func NewSender(timeout time.Duration) Sender {
+	return &httpSender{client: &http.Client{Timeout: timeout}}
+}
+
+func (s *httpSender) Send(ctx context.Context, url string, request *model.WebhookPreview) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(request.Body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", request.ContentType)
+	for _, header := range request.Headers {
+		req.Header.Add(header.Name, header.Value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook returned %d: %s", resp.StatusCode, strings.TrimSpace(string(text)))
+	}
+	return nil
+}
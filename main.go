@@ -2,65 +2,136 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
-	"path/filepath"
-	"sort"
-	"strings"
 	"syscall"
 	"time"
+	// База часовых поясов для фильтров по датам в образах без /usr/share/zoneinfo
+	_ "time/tzdata"
 
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/vektah/gqlparser/v2/ast"
 	"go.uber.org/zap"
 
 	"scoring_api_gateway/graph"
 	"scoring_api_gateway/graph/model"
+	"scoring_api_gateway/internal/abuse"
+	"scoring_api_gateway/internal/amendments"
+	"scoring_api_gateway/internal/auth"
+	"scoring_api_gateway/internal/buildinfo"
+	"scoring_api_gateway/internal/catalog"
+	"scoring_api_gateway/internal/companychange"
 	"scoring_api_gateway/internal/config"
+	"scoring_api_gateway/internal/contracts"
+	"scoring_api_gateway/internal/creation"
+	"scoring_api_gateway/internal/demo"
+	"scoring_api_gateway/internal/domain"
+	"scoring_api_gateway/internal/emailconfirm"
+	"scoring_api_gateway/internal/estimates"
+	"scoring_api_gateway/internal/faults"
+	"scoring_api_gateway/internal/fixtures"
+	"scoring_api_gateway/internal/freshness"
+	"scoring_api_gateway/internal/httpapi"
+	"scoring_api_gateway/internal/httpserver"
+	"scoring_api_gateway/internal/inbound"
+	"scoring_api_gateway/internal/inputlimits"
+	"scoring_api_gateway/internal/jobs"
 	"scoring_api_gateway/internal/logger"
+	"scoring_api_gateway/internal/mailer"
+	"scoring_api_gateway/internal/maintenance"
 	"scoring_api_gateway/internal/messaging"
+	"scoring_api_gateway/internal/migrations"
+	"scoring_api_gateway/internal/monitoring"
+	"scoring_api_gateway/internal/notfound"
+	"scoring_api_gateway/internal/notifications"
+	"scoring_api_gateway/internal/objectstore"
+	"scoring_api_gateway/internal/outbox"
+	"scoring_api_gateway/internal/payloads"
+	"scoring_api_gateway/internal/persisted"
+	"scoring_api_gateway/internal/prefetch"
+	"scoring_api_gateway/internal/privacy"
+	"scoring_api_gateway/internal/pubsub"
+	"scoring_api_gateway/internal/querylimits"
+	"scoring_api_gateway/internal/reconciliation"
+	"scoring_api_gateway/internal/reports"
 	"scoring_api_gateway/internal/repository"
+	"scoring_api_gateway/internal/residency"
+	"scoring_api_gateway/internal/resilience"
+	"scoring_api_gateway/internal/sandbox"
+	"scoring_api_gateway/internal/schemaguard"
+	"scoring_api_gateway/internal/schemamigration"
+	"scoring_api_gateway/internal/scoring"
 	"scoring_api_gateway/internal/service"
+	"scoring_api_gateway/internal/signing"
+	"scoring_api_gateway/internal/slo"
+	"scoring_api_gateway/internal/statistics"
+	"scoring_api_gateway/internal/subscriptions"
+	"scoring_api_gateway/internal/usage"
+	"scoring_api_gateway/internal/validation"
+	"scoring_api_gateway/internal/warehouse"
+	"scoring_api_gateway/internal/webhook"
 )
 
 func runMigrations(db *pgxpool.Pool, log *zap.Logger) error {
 	log.Info("Running database migrations")
 
-	migrationsDir := "migrations"
-	files, err := os.ReadDir(migrationsDir)
+	all, err := migrations.Load(os.DirFS("migrations"))
 	if err != nil {
-		return fmt.Errorf("failed to read migrations directory: %w", err)
+		return err
 	}
-
-	var migrationFiles []string
-	for _, file := range files {
-		if strings.HasSuffix(file.Name(), ".sql") {
-			migrationFiles = append(migrationFiles, file.Name())
-		}
+	if err := migrations.NewMigrator(db, log).Up(context.Background(), all); err != nil {
+		return err
 	}
 
-	sort.Strings(migrationFiles)
+	log.Info("All migrations completed successfully")
+	return nil
+}
 
-	for _, filename := range migrationFiles {
-		log.Info("Running migration", zap.String("file", filename))
+// checkSchemaCompatibility сравнивает схему с опубликованным снимком, чтобы ломающие изменения
+// не попали к клиентам без повышения graph.SchemaVersion
+// regionalRepository создает репозиторий основной базы, а при закреплении данных за регионами
+// направляет его запросы в базы регионов через wrap
+func regionalRepository[T any](db *pgxpool.Pool, regionDBs map[string]*pgxpool.Pool, router *residency.Router,
+	build func(*pgxpool.Pool) T, wrap func(T, map[string]T, *residency.Router) T) T {
+	home := build(db)
+	if router == nil {
+		return home
+	}
+	regional := make(map[string]T, len(regionDBs))
+	for region, regionDB := range regionDBs {
+		regional[region] = build(regionDB)
+	}
+	return wrap(home, regional, router)
+}
 
-		content, err := os.ReadFile(filepath.Join(migrationsDir, filename))
-		if err != nil {
-			return fmt.Errorf("failed to read migration file %s: %w", filename, err)
-		}
+func checkSchemaCompatibility(current *ast.Schema, cfg config.SchemaGuardConfig, log *zap.Logger) error {
+	if cfg.Mode == "off" {
+		return nil
+	}
 
-		_, err = db.Exec(context.Background(), string(content))
-		if err != nil {
-			return fmt.Errorf("failed to execute migration %s: %w", filename, err)
-		}
+	snapshot, err := schemaguard.ParseSnapshot(graph.PublishedSchemaSnapshot)
+	if err != nil {
+		return err
+	}
 
-		log.Info("Migration completed", zap.String("file", filename))
+	result := schemaguard.Check(snapshot, graph.SchemaVersion, current)
+	for _, change := range result.Changes {
+		log.Warn("Breaking GraphQL schema change", zap.String("path", change.Path), zap.String("change", change.Message))
 	}
 
-	log.Info("All migrations completed successfully")
+	if result.Blocking() && cfg.Mode == "fail" {
+		return fmt.Errorf("%d breaking changes without schema version bump (version %d)", len(result.Changes), graph.SchemaVersion)
+	}
 	return nil
 }
 
@@ -78,9 +149,27 @@ func main() {
 	}
 	defer log.Sync()
 
-	log.Info("Starting scoring API gateway")
+	started := time.Now()
+	build := buildinfo.Get(graph.SchemaVersion)
+	log.Info("Starting scoring API gateway",
+		zap.String("mode", cfg.Gateway.Mode),
+		zap.String("version", build.Version),
+		zap.String("git_sha", build.GitSHA),
+		zap.String("build_time", build.BuildTime),
+		zap.String("go_version", build.GoVersion),
+		zap.Int("schema_version", build.SchemaVersion),
+		zap.Bool("modified", build.Modified),
+	)
+
+	poolConfig, err := pgxpool.ParseConfig(cfg.DatabaseDSN())
+	if err != nil {
+		log.Fatal("Failed to parse database config", zap.Error(err))
+	}
+	// Запросы с одинаковым текстом подготавливаются один раз на соединение
+	poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	poolConfig.ConnConfig.StatementCacheCapacity = cfg.Database.StatementCacheCapacity
 
-	db, err := pgxpool.New(context.Background(), cfg.DatabaseDSN())
+	db, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		log.Fatal("Failed to connect to database", zap.Error(err))
 	}
@@ -92,61 +181,820 @@ func main() {
 		log.Fatal("Failed to run migrations", zap.Error(err))
 	}
 
-	natsClient, err := messaging.NewNATSClient(cfg.NATS.URL, log)
+	// Проверки арендаторов, закрепленных за регионом, хранятся в базе этого региона
+	var residencyRouter *residency.Router
+	regionDBs := make(map[string]*pgxpool.Pool)
+	if cfg.Residency.Enabled {
+		residencyRouter = residency.NewRouter(repository.NewResidencyRepository(db, log), cfg.Residency.HomeRegion, log)
+		if err := residencyRouter.Refresh(context.Background()); err != nil {
+			log.Error("Failed to load tenant regions", zap.Error(err))
+		}
+		dsns, err := residency.ParseTargets(cfg.Residency.HomeRegion, cfg.Residency.Databases)
+		if err != nil {
+			log.Fatal("Invalid residency databases", zap.Error(err))
+		}
+		for region, dsn := range dsns {
+			regionConfig, err := pgxpool.ParseConfig(dsn)
+			if err != nil {
+				log.Fatal("Failed to parse regional database config", zap.String("region", region), zap.Error(err))
+			}
+			regionConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+			regionConfig.ConnConfig.StatementCacheCapacity = cfg.Database.StatementCacheCapacity
+			regionDB, err := pgxpool.NewWithConfig(context.Background(), regionConfig)
+			if err != nil {
+				log.Fatal("Failed to connect to regional database", zap.String("region", region), zap.Error(err))
+			}
+			defer regionDB.Close()
+			if err := runMigrations(regionDB, log); err != nil {
+				log.Fatal("Failed to run regional database migrations", zap.String("region", region), zap.Error(err))
+			}
+			regionDBs[region] = regionDB
+		}
+		log.Info("Data residency enabled", zap.String("home_region", cfg.Residency.HomeRegion), zap.Int("regions", len(regionDBs)))
+	}
+
+	natsClient, err := messaging.NewNATSClient(cfg.NATS, log)
 	if err != nil {
 		log.Fatal("Failed to connect to NATS", zap.Error(err))
 	}
 	defer natsClient.Close()
 
 	log.Info("Connected to NATS")
+	// Отложенные уведомления о завершении хранятся в JetStream через прямое подключение
+	directNATS := natsClient
+
+	organizationRepo := repository.NewOrganizationRepository(db, log)
+
+	// Арендаторы с маршрутом в organizations получают собственные subject и учетные данные NATS
+	var tenantRouted messaging.TenantRoutedClient
+	if cfg.NATS.MultiTenant {
+		tenantRouted, err = messaging.NewTenantRoutedClient(natsClient, organizationRepo, cfg.NATS, log)
+		if err != nil {
+			log.Fatal("Failed to set up tenant routing", zap.Error(err))
+		}
+		if err := tenantRouted.RefreshRoutes(context.Background()); err != nil {
+			log.Error("Failed to load tenant routes", zap.Error(err))
+		}
+		natsClient = tenantRouted
+	}
+
+	// Фоновые запросы задач распределяются по воркерам пропорционально свободному месту в их очередях
+	var workerPool *messaging.WorkerPool
+	if cfg.NATS.WorkerDispatch {
+		workerPool = messaging.NewWorkerPool(cfg.NATS.WorkerHeartbeatTTL)
+		natsClient, err = messaging.NewDispatchClient(natsClient, workerPool)
+		if err != nil {
+			log.Fatal("Failed to set up worker dispatch", zap.Error(err))
+		}
+	}
+
+	// Типы данных из DATA_TYPES_CUSTOM_DIR заказываются у воркеров в собственных subject
+	registry := catalog.DefaultRegistry()
+	if cfg.DataTypes.CustomDir != "" {
+		custom, err := catalog.LoadCustom(cfg.DataTypes.CustomDir)
+		if err != nil {
+			log.Fatal("Failed to load custom data types", zap.Error(err))
+		}
+		registry = registry.With(custom)
+		routes := make(map[domain.DataType]string, len(custom))
+		declared := make([]domain.DataType, 0, len(custom))
+		for _, entry := range custom {
+			routes[domain.DataType(entry.Type)] = entry.Subject
+			declared = append(declared, domain.DataType(entry.Type))
+		}
+		if err := repository.DeclareDataTypes(context.Background(), db, declared); err != nil {
+			log.Fatal("Failed to declare custom data types", zap.Error(err))
+		}
+		natsClient, err = messaging.NewDataTypeRoutedClient(natsClient, directNATS, routes)
+		if err != nil {
+			log.Fatal("Failed to set up custom data type routing", zap.Error(err))
+		}
+		log.Info("Custom data types loaded", zap.Int("count", len(custom)), zap.String("dir", cfg.DataTypes.CustomDir))
+	}
+
+	// Внедрение сбоев включается только на стендах для проверки устойчивости
+	injector := faults.NewInjector(cfg.Faults.Enabled)
+	if injector.Enabled() {
+		log.Warn("Fault injection is enabled")
+		natsClient = faults.WrapNATSClient(natsClient, injector)
+	}
 
 	cacheRepo := repository.NewDataCacheRepository(db, log)
-	verificationRepo := repository.NewVerificationRepository(db, cacheRepo, log)
-	verificationService := service.NewVerificationService(verificationRepo, natsClient, log)
-
-	// Подписываемся на уведомления о завершении обработки
-	err = natsClient.SubscribeToVerificationCompleted(context.Background(), func(verification *model.Verification) {
-		log.Info("Received verification completed notification",
-			zap.String("verification_id", verification.ID),
-			zap.String("status", string(verification.Status)))
-	})
+	verificationV2Repo := repository.NewVerificationV2Repository(db, cacheRepo, log)
+	verificationRepo := faults.WrapVerificationRepository(schemamigration.WrapVerificationRepository(
+		repository.NewVerificationRepository(db, cacheRepo, log), verificationV2Repo, cfg.SchemaMigration, log), injector)
+
+	// Временные ошибки базы повторяются, а при ее недоступности проверки читаются из реплики и кэша.
+	// Внедренные сбои репозитория проходят через ту же защиту.
+	var dbBreaker *resilience.Breaker
+	if cfg.Database.BreakerEnabled {
+		dbBreaker = resilience.NewBreaker(cfg.Database.BreakerWindow, cfg.Database.BreakerMinRequests, cfg.Database.BreakerFailureRate, cfg.Database.BreakerOpenFor)
+	}
+	var replicaRepo repository.VerificationRepository
+	if replicaDSN := cfg.ReplicaDSN(); replicaDSN != "" {
+		replicaConfig, err := pgxpool.ParseConfig(replicaDSN)
+		if err != nil {
+			log.Fatal("Failed to parse database replica config", zap.Error(err))
+		}
+		replicaConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+		replicaConfig.ConnConfig.StatementCacheCapacity = cfg.Database.StatementCacheCapacity
+		replicaDB, err := pgxpool.NewWithConfig(context.Background(), replicaConfig)
+		if err != nil {
+			log.Fatal("Failed to set up database replica", zap.Error(err))
+		}
+		defer replicaDB.Close()
+		replicaRepo = repository.NewVerificationRepository(replicaDB, repository.NewDataCacheRepository(replicaDB, log), log)
+		log.Info("Database replica configured for degraded reads", zap.String("host", cfg.Database.ReplicaHost))
+	}
+	var readCache *resilience.ReadCache
+	if cfg.Database.ReadCacheSize > 0 {
+		readCache = resilience.NewReadCache(cfg.Database.ReadCacheSize, cfg.Database.ReadCacheTTL)
+	}
+	verificationRepo = resilience.WrapVerificationRepository(verificationRepo, replicaRepo, readCache,
+		resilience.NewGuard(dbBreaker, resilience.RetryPolicy{
+			Attempts:  cfg.Database.RetryAttempts,
+			BaseDelay: cfg.Database.RetryBaseDelay,
+			MaxDelay:  cfg.Database.RetryMaxDelay,
+		}), log)
+	if residencyRouter != nil {
+		regionalRepos := make(map[string]repository.VerificationRepository, len(regionDBs))
+		for region, regionDB := range regionDBs {
+			regionalRepos[region] = repository.NewVerificationRepository(regionDB, repository.NewDataCacheRepository(regionDB, log), log)
+		}
+		verificationRepo = residency.WrapVerificationRepository(verificationRepo, regionalRepos, residencyRouter)
+	}
+	if cfg.SchemaMigration.Mode != config.SchemaMigrationOff {
+		log.Info("Verifications schema migration enabled", zap.String("mode", cfg.SchemaMigration.Mode))
+	}
+
+	// Публикации сверх бюджета арендатора откладываются в outbox и отправляются задачей outbox_relay
+	outboxRepo := repository.NewOutboxRepository(db, log)
+	throttle := messaging.NewThrottle(cfg.NATS.PublishRate, cfg.NATS.PublishBurst)
+	// Проверки арендатора сверх лимита у воркеров удерживаются в outbox до завершения других его проверок
+	concurrencyLimited := cfg.NATS.TenantMaxInFlight > 0
+	// Отложенные запросы видны как PENDING, пока задача outbox_relay не отправит их воркерам
+	queuedAdmission := cfg.NATS.QueuedAdmission && (throttle.Enabled() || concurrencyLimited)
+
+	// Проверка записывается в verifications одной транзакцией с запросом в outbox до отправки
+	// воркерам, чтобы уведомление о завершении и запросы по идентификатору из ответа всегда
+	// находили строку, а сбой шлюза после записи не оставлял проверку без запроса
+	natsClient = creation.NewClient(natsClient, verificationRepo, outboxRepo, cfg.Outbox.ClaimTimeout, log)
+	// Без очереди PENDING отложенная проверка записывается вместе с сообщением в outbox
+	deferredOutbox := outboxRepo
+	if !queuedAdmission {
+		deferredOutbox = creation.WrapOutbox(outboxRepo, verificationRepo, log)
+	}
+
+	// Интеграционные тесты получают записанные ответы поставщиков вместо ответов воркеров
+	if cfg.Fixtures.Mode == config.FixturesModeReplay {
+		recorded, err := fixtures.Load(cfg.Fixtures.Dir)
+		if err != nil {
+			log.Fatal("Failed to load provider fixtures", zap.Error(err))
+		}
+		if len(recorded) == 0 {
+			log.Fatal("No provider fixtures to replay", zap.String("dir", cfg.Fixtures.Dir))
+		}
+		natsClient = fixtures.NewReplayClient(natsClient, repository.NewReplayRepository(db, log), recorded, log)
+		log.Warn("Replaying provider fixtures instead of publishing to workers", zap.Int("fixtures", len(recorded)))
+	}
+
+	// В режиме обслуживания новые публикации отклоняются, а начатые дожидаются завершения
+	maintenanceMode := maintenance.NewMode(cfg.Maintenance.Enabled, cfg.Maintenance.DrainTimeout)
+	if maintenanceMode.Enabled() {
+		log.Warn("Starting in maintenance mode")
+	}
+
+	// Без подключения к NATS запросы на проверку сохраняются в outbox или отклоняются
+	publisher := messaging.NewDegradedClient(natsClient, cfg.NATS.DegradedMode, deferredOutbox, cfg.NATS.QueuedAdmission, log)
+	publisher = messaging.NewThrottledClient(publisher, throttle, deferredOutbox, cfg.NATS.QueuedAdmission, log)
+	if concurrencyLimited {
+		publisher = messaging.NewConcurrencyLimitedClient(publisher, deferredOutbox, cfg.NATS.TenantMaxInFlight, cfg.NATS.QueuedAdmission, log)
+	}
+
+	// Регион новой проверки записывается до того, как запрос может быть отложен в outbox
+	if residencyRouter != nil {
+		publisher = residency.NewClient(publisher, residencyRouter, repository.NewVerificationRepository(db, repository.NewDataCacheRepository(db, log), log), log)
+	}
+
+	// Проверки ИНН, которые поставщики недавно не нашли, сразу завершаются статусом COMPANY_NOT_FOUND
+	var notFoundRecorder *notfound.Recorder
+	negativeCacheRepo := repository.NewNegativeCacheRepository(db, log)
+	if cfg.NegativeCache.Enabled {
+		publisher = notfound.NewClient(publisher, negativeCacheRepo, log)
+		notFoundRecorder = notfound.NewRecorder(negativeCacheRepo, cfg.NegativeCache.TTL, log)
+		log.Info("Negative cache enabled", zap.Duration("ttl", cfg.NegativeCache.TTL))
+	}
+
+	// Свежие и устаревшие данные компании отдаются из кэша, поставщики запрашиваются только за отсутствующими
+	if cfg.Freshness.Enabled {
+		policies, err := freshness.ParsePolicies(freshness.Policy{FreshFor: cfg.Freshness.FreshFor, StaleFor: cfg.Freshness.StaleFor}, cfg.Freshness.ByType, registry)
+		if err != nil {
+			log.Fatal("Invalid data freshness policies", zap.Error(err))
+		}
+		publisher = freshness.NewClient(publisher, repository.NewFreshnessRepository(db, log), policies, registry, cfg.Freshness, log)
+		log.Info("Data freshness cache enabled",
+			zap.Duration("fresh_for", cfg.Freshness.FreshFor),
+			zap.Duration("stale_for", cfg.Freshness.StaleFor))
+	}
+
+	// Запросы ключей и организаций песочницы не доходят до поставщиков и не расходуют бюджет арендатора
+	if cfg.Sandbox.Enabled {
+		publisher = sandbox.NewClient(publisher, repository.NewSandboxRepository(db, log), log)
+		log.Info("Sandbox mode enabled")
+	}
+
+	// Созданная проверка получает ожидаемое время завершения, которое уточняется по мере доставки данных
+	latencyRepo := repository.NewLatencyRepository(db, log)
+	var estimator *estimates.Estimator
+	if cfg.Estimates.Enabled {
+		estimator = estimates.NewEstimator(repository.NewEstimateRepository(db, log), latencyRepo, registry, workerPool, cfg.Estimates, log)
+		publisher = estimates.NewClient(publisher, estimator, log)
+	}
+
+	// Запросы пользователей с email, от которого еще не было проверок, ждут подтверждения email
+	var emailConfirmer *emailconfirm.Confirmer
+	emailConfirmationRepo := repository.NewEmailConfirmationRepository(db, log)
+	if cfg.EmailConfirmation.Enabled {
+		emailConfirmer = emailconfirm.NewConfirmer(emailConfirmationRepo, mailer.NewSender(cfg.SMTP, log), cfg.EmailConfirmation, log)
+		publisher = emailconfirm.NewClient(publisher, emailConfirmer, log)
+		log.Info("Author email confirmation enabled")
+	}
+	publisher = maintenance.TrackPublishes(publisher, maintenanceMode)
+
+	verificationService := service.NewVerificationService(verificationRepo, publisher, registry, log)
+
+	if queuedAdmission {
+		verificationService = service.NewQueuedAdmissionVerificationService(verificationService, outboxRepo, log)
+	}
+	if emailConfirmer != nil {
+		verificationService = service.NewEmailConfirmationVerificationService(verificationService, emailConfirmationRepo, log)
+	}
+
+	// В режиме хранения событий таблица verifications становится моделью чтения
+	var eventSourcing service.EventSourcingService
+	if cfg.EventStore.Enabled {
+		eventSourcing = service.NewEventSourcingService(repository.NewEventStoreRepository(db, log), verificationRepo, log)
+		verificationService = service.NewEventSourcedVerificationService(verificationService, eventSourcing, log)
+		log.Info("Event store mode enabled")
+	}
+
+	// Учет чтений по ИНН для заблаговременного обновления данных популярных компаний
+	var readTracker *prefetch.Tracker
+	popularityRepo := repository.NewPopularityRepository(db, log)
+	if cfg.Prefetch.Enabled {
+		readTracker = prefetch.NewTracker(popularityRepo)
+		verificationService = service.NewReadTrackingVerificationService(verificationService, readTracker)
+	}
+
+	// Показатели уровня обслуживания для алертов: доступность создания проверок,
+	// время завершения и доставка уведомлений
+	sloTracker := slo.NewTracker(cfg.SLO.Windows)
+	verificationService = service.NewSLOVerificationService(verificationService, sloTracker)
+
+	// События подписок: без общей шины о завершении проверки узнают только подписчики экземпляра,
+	// обработавшего уведомление; с postgres - всех экземпляров, подключенных к той же базе
+	relay := pubsub.NewLocalRelay()
+	if cfg.Subscriptions.Broadcast == config.SubscriptionsBroadcastPostgres {
+		postgresRelay := pubsub.NewPostgresRelay(db, cfg.Subscriptions.NotifyChannel, log)
+		go postgresRelay.Run(context.Background())
+		relay = postgresRelay
+	}
+
+	// createVerification(waitForCompletion: true) ждет завершения проверки в том же запросе
+	completionWaiter := service.NewCompletionWaiter(verificationRepo, relay, cfg.GraphQL.MaxCompletionWait, log)
+
+	var signer *signing.Signer
+	if cfg.Signing.Key != "" {
+		signer, err = signing.NewSigner(cfg.Signing.Key)
+		if err != nil {
+			log.Fatal("Failed to load signing key", zap.Error(err))
+		}
+		log.Info("Signing key loaded", zap.String("key_id", signer.KeyID()))
+	}
+
+	validator, err := validation.NewValidator()
+	if err != nil {
+		log.Fatal("Failed to load data schemas", zap.Error(err))
+	}
+	for _, entry := range registry.Custom() {
+		if err := validator.AddSchema(entry.Type, entry.Schema); err != nil {
+			log.Fatal("Failed to load data schemas", zap.Error(err))
+		}
+	}
+	dataQualityService := service.NewDataQualityService(verificationRepo, validator, registry, log)
+
+	apiKeyRepo := repository.NewAPIKeyRepository(db, log)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, log)
+	persistedOperationRepo := repository.NewPersistedOperationRepository(db, log)
+	userRepo := repository.NewUserRepository(db, log)
+	userService := service.NewUserService(userRepo, organizationRepo, log)
+	accessReviewService := service.NewAccessReviewService(userRepo, apiKeyRepo, organizationRepo, repository.NewSandboxRepository(db, log), cacheRepo, verificationRepo, log)
+
+	auditRepo := regionalRepository(db, regionDBs, residencyRouter, func(db *pgxpool.Pool) repository.AuditRepository {
+		return repository.NewAuditRepository(db, log)
+	}, residency.WrapAuditRepository)
+	auditService := service.NewAuditService(auditRepo, verificationService, signer, log)
+	caseRepo := repository.NewCaseRepository(db, log)
+	caseService := service.NewCaseService(caseRepo, verificationRepo, signer, log)
+	// Отчеты по делам формируются в фоне и хранятся в объектном хранилище до истечения срока
+	var reportStore service.ReportStore
+	if cfg.Reports.Enabled {
+		reportStore = objectstore.NewStore(cfg.Reports.Storage)
+	}
+	reportService := service.NewReportService(repository.NewReportJobRepository(db, log), caseRepo, reportStore, signer, cfg.Reports, log)
+
+	// Данные больше предела типа переносятся в объектное хранилище, без него - отклоняются
+	payloadLimits, err := payloads.ParseLimits(cfg.Payloads.MaxBytes, cfg.Payloads.MaxBytesByType)
+	if err != nil {
+		log.Fatal("Invalid payload size limits", zap.Error(err))
+	}
+	var payloadStore service.PayloadStore
+	if cfg.Payloads.Storage.Endpoint != "" {
+		payloadStore = objectstore.NewStore(cfg.Payloads.Storage)
+	}
+	payloadRepo := repository.NewPayloadRepository(db, log)
+	if residencyRouter != nil {
+		buckets, err := residency.ParseTargets(cfg.Residency.HomeRegion, cfg.Residency.PayloadBuckets)
+		if err != nil {
+			log.Fatal("Invalid residency payload buckets", zap.Error(err))
+		}
+		regionalStores := make(map[string]service.PayloadStore, len(buckets))
+		for region, bucket := range buckets {
+			if _, ok := regionDBs[region]; !ok || cfg.Payloads.Storage.Endpoint == "" {
+				log.Fatal("Residency payload bucket requires a regional database and payloads storage endpoint", zap.String("region", region))
+			}
+			storage := cfg.Payloads.Storage
+			storage.Bucket = bucket
+			regionalStores[region] = objectstore.NewStore(storage)
+		}
+		regionalPayloads := make(map[string]repository.PayloadRepository, len(regionDBs))
+		for region, regionDB := range regionDBs {
+			regionalPayloads[region] = repository.NewPayloadRepository(regionDB, log)
+		}
+		payloadRepo = residency.WrapPayloadRepository(payloadRepo, regionalPayloads, residencyRouter)
+		payloadStore = residency.NewPayloadStore(payloadStore, regionalStores, residencyRouter)
+	}
+	payloadService := service.NewPayloadService(payloadRepo, payloadLimits, payloadStore, log)
+	var payloadIngest service.PayloadService
+	if cfg.Payloads.Enabled {
+		payloadIngest = payloadService
+	}
+	amendmentRepo := regionalRepository(db, regionDBs, residencyRouter, func(db *pgxpool.Pool) repository.AmendmentRepository {
+		return repository.NewAmendmentRepository(db, log)
+	}, residency.WrapAmendmentRepository)
+	amendmentService := service.NewAmendmentService(amendmentRepo, verificationService, dataQualityService, payloadIngest, auditService, natsClient, log)
+
+	// Итоги проверок подписываются при завершении, чтобы получатели могли обнаружить их изменение
+	var verificationSigner *signing.Signer
+	if cfg.Signing.Verifications {
+		if signer == nil {
+			log.Fatal("SIGNING_KEY is required when verification signing is enabled")
+		}
+		verificationSigner = signer
+	}
+	signatureRepo := regionalRepository(db, regionDBs, residencyRouter, func(db *pgxpool.Pool) repository.SignatureRepository {
+		return repository.NewSignatureRepository(db, log)
+	}, residency.WrapSignatureRepository)
+	signatureService := service.NewSignatureService(signatureRepo, verificationService, verificationSigner, log)
+	dataRedactionRepo := regionalRepository(db, regionDBs, residencyRouter, func(db *pgxpool.Pool) repository.DataRedactionRepository {
+		return repository.NewDataRedactionRepository(db, log)
+	}, residency.WrapDataRedactionRepository)
+	dataRedactionService := service.NewDataRedactionService(dataRedactionRepo, auditService, signatureService, signer, log)
+
+	notificationRepo := repository.NewNotificationRepository(db, log)
+	notificationService := service.NewNotificationService(notificationRepo, verificationRepo, auditService, relay, log)
+	// Вебхуки получают уведомления о завершении проверок и изменениях компаний на мониторинге
+	// в формате, выбранном при регистрации
+	webhookSender := faults.WrapWebhookSender(webhook.NewSender(cfg.Webhooks.Timeout), injector)
+	changeRules, err := companychange.ParseRules(cfg.Monitoring.ChangeFields)
 	if err != nil {
-		log.Error("Failed to subscribe to verification completed", zap.Error(err))
+		log.Fatal("Invalid MONITORING_CHANGE_FIELDS", zap.Error(err))
 	}
+	changeDetector := companychange.NewDetector(changeRules, cfg.Monitoring.ChangeSnippetBytes)
+	webhookService := service.NewWebhookService(repository.NewWebhookRepository(db, log), verificationRepo, webhook.NewRenderer(cfg.Webhooks.Source), webhookSender, changeDetector, log)
+	notificationJobService := service.NewNotificationJobService(repository.NewNotificationJobRepository(db, log), notificationService, webhookService, sloTracker, cfg.Notifications, log)
+	reviewService := service.NewReviewService(repository.NewReviewRepository(db, log), verificationRepo, userRepo, auditService, notificationService, log)
+
+	statisticsService := service.NewStatisticsService(repository.NewStatisticsRepository(db, log), organizationRepo, log)
+	privacyService := service.NewPrivacyService(repository.NewPrivacyRepository(db, log), verificationService, auditService, cfg.Privacy, log)
+	if cfg.Privacy.Enabled && cfg.Privacy.Salt == "" {
+		log.Fatal("PRIVACY_SALT is required when anonymization is enabled")
+	}
+
+	// Оценки версионируются правилами скоринга и пересчитываются движком по уже собранным данным
+	latencyService := service.NewLatencyService(latencyRepo, log)
+	scoringService := service.NewScoringService(repository.NewScoreRepository(db, log), natsClient, log)
+
+	// Клиенты, раз за разом присылающие некорректные запросы, временно блокируются
+	abuseLimiter := abuse.NewLimiter(cfg.Abuse, repository.NewLockoutRepository(db, log), log)
 
-	// Внедряем зависимости в резолверы
-	resolver := &graph.Resolver{
-		VerificationService: verificationService,
-		Logger:              log,
+	scheduler := jobs.NewScheduler(log)
+	scheduler.PauseWhile(maintenanceMode.Enabled)
+	schedule := func(job jobs.Job, interval time.Duration) {
+		if err := scheduler.Register(job, interval); err != nil {
+			log.Fatal("Failed to schedule job", zap.Error(err))
+		}
+	}
+	if tenantRouted != nil {
+		schedule(messaging.NewRouteRefreshJob(tenantRouted), cfg.NATS.RouteRefreshInterval)
+	}
+	if residencyRouter != nil {
+		schedule(residency.NewRefreshJob(residencyRouter), cfg.Residency.RefreshInterval)
+	}
+	schedule(slo.NewUpdateJob(sloTracker), cfg.SLO.UpdateInterval)
+	if vault := cfg.VaultClient(); vault != nil {
+		schedule(config.NewVaultRenewJob(vault), cfg.Vault.RenewInterval)
+	}
+	if cfg.Abuse.Enabled && cfg.Gateway.ServesAPI() {
+		schedule(abuse.NewCleanupJob(abuseLimiter), cfg.Abuse.CleanupInterval)
 	}
+	if readTracker != nil {
+		schedule(prefetch.NewFlushJob(readTracker), cfg.Prefetch.FlushInterval)
+	}
+	usageRepo := repository.NewUsageRepository(db, log)
+	var usageRecorder *usage.Recorder
+	if cfg.Usage.Enabled && cfg.Gateway.ServesAPI() {
+		usageRecorder = usage.NewRecorder(usageRepo)
+		schedule(usage.NewFlushJob(usageRecorder, cfg.Usage.Retention), cfg.Usage.FlushInterval)
+	}
+
+	// Обработчик завершений процесса, подписанного на них; ручные завершения передаются ему напрямую
+	var handleCompletion func(*domain.Verification)
+
+	// В режиме consumer шлюз только обрабатывает уведомления NATS и выполняет фоновые задачи,
+	// поэтому обработку событий можно масштабировать отдельно от HTTP API
+	if cfg.Gateway.ConsumesEvents() {
+		// Уведомления сверх скорости обработки откладываются в JetStream, чтобы поток завершений
+		// после разбора очереди воркеров не перегружал базу
+		completions := natsClient
+		consumerName := cfg.NATS.CompletedQueueGroup
+		if consumerName == "" {
+			consumerName = cfg.NATS.ClientID
+		}
+		if consumerName == "" {
+			consumerName, _ = os.Hostname()
+		}
+		if cfg.NATS.CompletedDrainRate > 0 {
+			parking, err := messaging.NewJetStreamParking(context.Background(), directNATS, cfg.NATS.CompletedParkingStream, consumerName)
+			if err != nil {
+				log.Fatal("Failed to set up completion parking", zap.Error(err))
+			}
+			completions = messaging.NewRateLimitedCompletionsClient(natsClient, parking,
+				messaging.NewThrottle(cfg.NATS.CompletedDrainRate, cfg.NATS.CompletedDrainBurst), log)
+			log.Info("Completion processing rate limited", zap.Float64("rate", cfg.NATS.CompletedDrainRate))
+		}
+
+		// Уведомления одной проверки применяются по порядку, в том числе проверка их актуальности
+		if cfg.NATS.CompletedShards > 0 {
+			ordered := messaging.NewOrderedCompletionsClient(completions, cfg.NATS.CompletedShards, cfg.NATS.CompletedShardQueue, log)
+			defer ordered.Close()
+			completions = ordered
+			log.Info("Ordered completion processing enabled", zap.Int("shards", cfg.NATS.CompletedShards))
+		}
+
+		// Повторы, устаревшие и пришедшие не по порядку уведомления не меняют статус проверки
+		if cfg.InboundEvents.Enabled {
+			inboundRepo := repository.NewInboundEventRepository(db, log)
+			completions = inbound.NewClient(completions, inboundRepo, auditService, consumerName, cfg.InboundEvents, log)
+			schedule(inbound.NewCleanupJob(inboundRepo, cfg.InboundEvents), cfg.InboundEvents.CleanupInterval)
+			log.Info("Inbound event validation enabled",
+				zap.Duration("max_age", cfg.InboundEvents.MaxAge),
+				zap.String("action", cfg.InboundEvents.Action))
+		}
+
+		if cfg.Fixtures.Mode == config.FixturesModeRecord {
+			completions = fixtures.NewRecordingClient(completions, verificationRepo, cfg.Fixtures.Dir, fixtures.NewAnonymizer(cfg.Fixtures.Salt), log)
+			log.Info("Recording provider fixtures", zap.String("dir", cfg.Fixtures.Dir))
+		}
 
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
+		handleCompletion = func(message *domain.Verification) {
+			verification := model.VerificationFromDomain(message)
+			log.Info("Received verification completed notification",
+				zap.String("verification_id", verification.ID),
+				zap.String("status", string(verification.Status)))
+
+			// Статус сохраняется шлюзом, а не только воркером, до остальной обработки: она читает проверку из базы
+			if err := verificationRepo.UpdateStatus(context.Background(), verification.ID, verification.Status, message.ErrorMessage, message.UpdatedAt); err != nil {
+				log.Error("Failed to save verification status", zap.Error(err), zap.String("verification_id", verification.ID))
+			}
+
+			details := map[string]any{"status": verification.Status}
+			if verification.RiskLevel != nil {
+				details["risk_level"] = *verification.RiskLevel
+			}
+			if err := auditService.RecordEvent(context.Background(), verification.ID, model.AuditEventTypeStatusChanged, "", details); err != nil {
+				log.Error("Failed to record status change", zap.Error(err), zap.String("verification_id", verification.ID))
+			}
+
+			if verification.RiskLevel != nil {
+				score := &repository.Score{VerificationID: verification.ID, RiskLevel: *verification.RiskLevel, RulesetID: verification.RulesetID}
+				if err := scoringService.RecordScore(context.Background(), score); err != nil {
+					log.Error("Failed to save risk level", zap.Error(err), zap.String("verification_id", verification.ID))
+				}
+			}
+
+			if payloadIngest != nil {
+				if err := payloadIngest.Ingest(context.Background(), verification.ID); err != nil {
+					log.Error("Failed to ingest delivered data", zap.Error(err), zap.String("verification_id", verification.ID))
+				}
+			}
+
+			var missing []model.VerificationDataType
+			if verification.Status == model.VerificationStatusCompleted {
+				if _, err := dataQualityService.ValidateDeliveredData(context.Background(), verification.ID); err != nil {
+					log.Error("Failed to validate delivered data", zap.Error(err), zap.String("verification_id", verification.ID))
+				}
+
+				var err error
+				missing, err = verificationService.ReconcileDeliveredData(context.Background(), verification.ID)
+				if err != nil {
+					log.Error("Failed to reconcile delivered data", zap.Error(err), zap.String("verification_id", verification.ID))
+				} else if len(missing) > 0 {
+					details := map[string]any{"status": model.VerificationStatusPartiallyCompleted, "missing_data_types": missing}
+					if err := auditService.RecordEvent(context.Background(), verification.ID, model.AuditEventTypeStatusChanged, "", details); err != nil {
+						log.Error("Failed to record status change", zap.Error(err), zap.String("verification_id", verification.ID))
+					}
+				}
+			}
+
+			if eventSourcing != nil {
+				if err := eventSourcing.RecordCompletion(context.Background(), verification, missing); err != nil {
+					log.Error("Failed to record completion events", zap.Error(err), zap.String("verification_id", verification.ID))
+				}
+			}
+
+			if _, err := signatureService.SignVerification(context.Background(), verification.ID); err != nil {
+				log.Error("Failed to sign verification", zap.Error(err), zap.String("verification_id", verification.ID))
+			}
+
+			// Время выполнения по типам данных учитывается только для проверок, дошедших до поставщиков
+			if verification.Status == model.VerificationStatusCompleted {
+				if err := latencyService.RecordCompletion(context.Background(), verification.ID); err != nil {
+					log.Error("Failed to record verification latencies", zap.Error(err), zap.String("verification_id", verification.ID))
+				}
+			}
+
+			if completed, err := verificationRepo.GetByID(context.Background(), verification.ID); err == nil {
+				if duration, ok := slo.CompletionTime(completed); ok {
+					sloTracker.RecordCompletion(duration)
+				}
+				if notFoundRecorder != nil {
+					if err := notFoundRecorder.RecordCompletion(context.Background(), model.VerificationToDomain(completed)); err != nil {
+						log.Error("Failed to update negative cache", zap.Error(err), zap.String("verification_id", verification.ID))
+					}
+				}
+			}
+
+			// Итоговый статус и данные уже сохранены: ожидающие запросы этого экземпляра могут их прочитать
+			completionWaiter.Completed(verification.ID)
+
+			// Задания уведомлений созданы вместе с итоговым статусом, доставляет их задача notifications_delivery.
+			// Если отметка не сохранится, задания будут доставлены после NOTIFICATIONS_READY_TIMEOUT.
+			if err := notificationJobService.MarkReady(context.Background(), verification.ID); err != nil {
+				log.Error("Failed to release completion notifications", zap.Error(err), zap.String("verification_id", verification.ID))
+			}
+		}
 
-	schema := graph.NewExecutableSchema(graph.Config{Resolvers: resolver})
-	srv := handler.NewDefaultServer(schema)
+		// Подписываемся на уведомления о завершении обработки
+		err = completions.SubscribeToVerificationCompleted(context.Background(), handleCompletion)
+		if err != nil {
+			log.Error("Failed to subscribe to verification completed", zap.Error(err))
+		}
 
-	http.Handle("/query", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Info("GraphQL request received",
-			zap.String("method", r.Method),
-			zap.String("path", r.URL.Path),
-			zap.String("user_agent", r.UserAgent()),
-			zap.String("remote_addr", r.RemoteAddr))
-		srv.ServeHTTP(w, r)
-	}))
+		err = natsClient.SubscribeToVerificationRescored(context.Background(), func(rescored *messaging.VerificationRescoredMessage) {
+			score := &repository.Score{VerificationID: rescored.VerificationID, RiskLevel: model.RiskLevel(rescored.RiskLevel)}
+			if rescored.RulesetID != "" {
+				score.RulesetID = &rescored.RulesetID
+			}
+			if rescored.RecalculationID != "" {
+				score.RecalculationID = &rescored.RecalculationID
+			}
+			if err := scoringService.RecordScore(context.Background(), score); err != nil {
+				log.Error("Failed to save recalculated score", zap.Error(err), zap.String("verification_id", rescored.VerificationID))
+			}
+		})
+		if err != nil {
+			log.Error("Failed to subscribe to verification rescored", zap.Error(err))
+		}
 
-	http.Handle("/playground", playground.Handler("GraphQL playground", "/query"))
+		schedule(scoring.NewRecalculationJob(scoringService, cfg.Scoring.RecalculationBatchSize), cfg.Scoring.RecalculationInterval)
+		schedule(statistics.NewRefreshJob(statisticsService), cfg.Statistics.RefreshInterval)
+		if cfg.NegativeCache.Enabled {
+			schedule(notfound.NewCleanupJob(negativeCacheRepo), cfg.NegativeCache.CleanupInterval)
+		}
+		schedule(outbox.NewRelayJob(outboxRepo, maintenance.TrackPublishes(natsClient, maintenanceMode), cfg.Outbox, log), cfg.Outbox.RelayInterval)
+		if concurrencyLimited {
+			schedule(outbox.NewReleaseJob(outboxRepo, cfg.NATS.TenantMaxInFlight, cfg.Outbox.BatchSize, log), cfg.NATS.ConcurrencyReleaseInterval)
+		}
+		schedule(amendments.NewRelayJob(amendmentService, natsClient, cfg.Amendments), cfg.Amendments.RelayInterval)
+		if cfg.Monitoring.Enabled {
+			schedule(monitoring.NewJob(verificationRepo, repository.NewMonitoringRepository(db, log), verificationService, cfg.Monitoring, log), cfg.Monitoring.Interval)
+		}
+		if cfg.Reconciliation.AutoRetry {
+			schedule(reconciliation.NewRetryJob(verificationRepo, verificationService, cfg.Reconciliation, log), cfg.Reconciliation.CheckInterval)
+		}
+		schedule(notifications.NewSLAJob(notificationService, cfg.Notifications.SLA), cfg.Notifications.SLACheckInterval)
+		schedule(notifications.NewDeliveryJob(notificationJobService), cfg.Notifications.DeliveryInterval)
+		if estimator != nil {
+			schedule(estimates.NewRefreshJob(estimator), cfg.Estimates.RefreshInterval)
+		}
+		if emailConfirmer != nil {
+			schedule(emailconfirm.NewCleanupJob(emailConfirmer), cfg.EmailConfirmation.CleanupInterval)
+		}
+		if cfg.Privacy.Enabled {
+			schedule(privacy.NewJob(privacyService), cfg.Privacy.Interval)
+		}
+		if cfg.Reports.Enabled {
+			schedule(reports.NewGenerationJob(reportService), cfg.Reports.Interval)
+			schedule(reports.NewCleanupJob(reportService), cfg.Reports.CleanupInterval)
+		}
+		if cfg.Demo.Enabled {
+			schedule(demo.NewRefreshJob(demo.NewSeeder(repository.NewDemoRepository(db, log), cfg.Demo, log)), cfg.Demo.RefreshInterval)
+		}
+		if cfg.SchemaMigration.Mode != config.SchemaMigrationOff {
+			schedule(schemamigration.NewSyncJob(verificationV2Repo, cfg.SchemaMigration, log), cfg.SchemaMigration.SyncInterval)
+		}
+		if cfg.Warehouse.Enabled {
+			sink, err := warehouse.NewSink(cfg.Warehouse)
+			if err != nil {
+				log.Fatal("Failed to create warehouse sink", zap.Error(err))
+			}
+			schedule(warehouse.NewExportJob(repository.NewWarehouseRepository(db, log), sink, cfg.Warehouse, log), cfg.Warehouse.Interval)
+		}
+		if cfg.Prefetch.Enabled {
+			prefetchJob, err := prefetch.NewJob(popularityRepo, verificationService, registry, cfg.Prefetch, log)
+			if err != nil {
+				log.Fatal("Failed to create prefetch job", zap.Error(err))
+			}
+			schedule(prefetchJob, cfg.Prefetch.Interval)
+		}
+	}
+	scheduler.Start(context.Background())
+	defer scheduler.Stop()
 
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
-	log.Info("Starting server", zap.String("address", addr))
+	var server *http.Server
+	if cfg.Gateway.ServesAPI() {
+		serverInfo := &model.ServerInfo{
+			Version:       build.Version,
+			GitSha:        build.GitSHA,
+			BuildTime:     build.BuildTime,
+			GoVersion:     build.GoVersion,
+			SchemaVersion: int32(build.SchemaVersion),
+			Mode:          cfg.Gateway.Mode,
+		}
+
+		var memberships auth.MembershipResolver
+		if cfg.Users.Enabled {
+			memberships = userService
+		}
+		tokens := auth.NewJWTVerifier(cfg.Subscriptions.JWTSecret)
+		// Пользователь и роли из заголовков принимаются только от прокси аутентификации
+		authProxy, err := auth.NewTrustedProxy(cfg.Server.TrustedProxyCIDRs, cfg.Server.TrustedProxySecret)
+		if err != nil {
+			log.Fatal("Invalid trusted proxy settings", zap.Error(err))
+		}
+
+		// Данные проверок арендаторов, закрепленных за регионом, читаются и пишутся в базе региона
+		companyDataCache := regionalRepository(db, regionDBs, residencyRouter, func(db *pgxpool.Pool) repository.DataCacheRepository {
+			return repository.NewDataCacheRepository(db, log)
+		}, residency.WrapDataCacheRepository)
+		simulationRepo := regionalRepository(db, regionDBs, residencyRouter, func(db *pgxpool.Pool) repository.CompletionSimulationRepository {
+			return repository.NewCompletionSimulationRepository(db, log)
+		}, residency.WrapCompletionSimulationRepository)
+
+		// Внедряем зависимости в резолверы
+		resolver := &graph.Resolver{
+			VerificationService:       verificationService,
+			AuditService:              auditService,
+			NotificationService:       notificationService,
+			CatalogService:            service.NewCatalogService(registry, catalog.DefaultDeprecations()),
+			CompanyDataService:        service.NewCompanyDataService(companyDataCache, registry, cfg.Cache.ShareableDataTypes, log),
+			LatencyService:            latencyService,
+			StatisticsService:         statisticsService,
+			PrivacyService:            privacyService,
+			ScoringService:            scoringService,
+			SignatureService:          signatureService,
+			PersistedOperationService: service.NewPersistedOperationService(persistedOperationRepo, log),
+			UserService:               userService,
+			AccessReviewService:       accessReviewService,
+			ReviewService:             reviewService,
+			WebhookService:            webhookService,
+			NotificationJobService:    notificationJobService,
+			UsageService:              service.NewUsageService(usageRepo, cfg.Usage, log),
+			AmendmentService:          amendmentService,
+			DataRedactionService:      dataRedactionService,
+			PayloadService:            payloadService,
+			ExportService:             service.NewExportService(verificationRepo, cfg.GraphQL.ExportMaxRows, cfg.GraphQL.ExportSendTimeout, log),
+			CompletionWaiter:          completionWaiter,
+			CaseService:               caseService,
+			ReportService:             reportService,
+			CloneService:              service.NewCloneService(verificationService, log),
+			IdentityService:           service.NewIdentityService(tokens, apiKeyService, memberships, outboxRepo, cfg.NATS, log),
+			CompletionSimulationService: service.NewCompletionSimulationService(simulationRepo,
+				verificationService, natsClient, handleCompletion, auditService, log),
+			Maintenance: maintenanceMode,
+			Build:       serverInfo,
+			Logger:      log,
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/health", httpapi.NewHealthHandler(natsClient))
+		mux.Handle("/livez", httpapi.NewLiveHandler(build, started, cfg.Health.Verbosity))
+		mux.Handle("/readyz", httpapi.NewReadyHandler(db, natsClient, maintenanceMode, build, cfg.Health.Verbosity))
+		mux.Handle("/metrics", promhttp.Handler())
+		mux.Handle("GET /version", httpapi.NewVersionHandler(build))
+
+		schema := graph.NewExecutableSchema(graph.Config{Resolvers: resolver})
+		if err := checkSchemaCompatibility(schema.Schema(), cfg.SchemaGuard, log); err != nil {
+			log.Fatal("GraphQL schema check failed", zap.Error(err))
+		}
+		// Контракты собираются из той же схемы, что обслуживает /query, поэтому не расходятся с ней
+		contractsBundle, err := contracts.Build(build, schema.Schema(), validator.Schemas())
+		if err != nil {
+			log.Fatal("Failed to build contracts", zap.Error(err))
+		}
+		contractsHandler := httpapi.NewContractsHandler(contractsBundle)
+		mux.Handle("GET /contracts", contractsHandler)
+		mux.Handle("GET /contracts/", contractsHandler)
+		// Транспорты и расширения NewDefaultServer, но с проверкой подключений WebSocket
+		subscriptionAuth := subscriptions.NewAuthenticator(tokens, apiKeyService, memberships, log)
+		srv := handler.New(schema)
+		srv.AddTransport(subscriptions.NewTransport(cfg.Subscriptions, subscriptionAuth))
+		srv.AddTransport(transport.Options{})
+		srv.AddTransport(transport.GET{})
+		srv.AddTransport(transport.POST{})
+		srv.AddTransport(transport.MultipartForm{})
+		srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
+		srv.Use(extension.Introspection{})
+		srv.Use(extension.AutomaticPersistedQuery{Cache: lru.New[string](100)})
+		srv.Use(subscriptions.NewLimiter(cfg.Subscriptions.MaxPerUser))
+		srv.SetErrorPresenter(graph.ErrorPresenter)
+		srv.Use(graph.PartialErrors{})
+		srv.Use(querylimits.NewExtension(cfg.GraphQL.Limits))
+		srv.Use(inputlimits.NewExtension())
+		if cfg.GraphQL.PersistedOperations != config.PersistedOperationsOff {
+			srv.Use(persisted.NewExtension(persistedOperationRepo, cfg.GraphQL.PersistedOperations, log))
+		}
+		srv.Use(maintenance.NewGuard(maintenanceMode, "setMaintenanceMode"))
+		srv.Use(abuse.NewExtension(abuseLimiter))
+		if usageRecorder != nil {
+			srv.Use(usage.NewExtension(usageRecorder))
+		}
+		mux.Handle("/query", srv)
+
+		mux.Handle("GET /verifications/{id}/audit-trail.pdf", httpapi.NewAuditTrailHandler(auditService, log))
+		mux.Handle("GET /verifications/{id}/events", httpapi.NewVerificationEventsHandler(auditService, cfg.Subscriptions, log))
+		mux.Handle("GET /cases/{id}/report.pdf", httpapi.NewCaseReportHandler(caseService, log))
+		mux.Handle("GET /admin/access-review.csv", httpapi.NewAccessReviewHandler(accessReviewService, log))
+		if emailConfirmer != nil {
+			mux.Handle("GET /confirm-email", httpapi.NewEmailConfirmationHandler(emailConfirmer, log))
+		}
+		if signer != nil {
+			mux.Handle("GET /signing-key", httpapi.NewSigningKeyHandler(signer))
+		}
+
+		if injector.Enabled() {
+			mux.Handle("/admin/faults", auth.RequireRoleMiddleware(authProxy, auth.RoleAdmin, httpapi.NewFaultsHandler(injector, log)))
+		}
 
-	go func() {
-		if err := http.ListenAndServe(addr, nil); err != nil {
-			log.Fatal("Failed to start server", zap.Error(err))
+		var playgroundOptions []playground.GraphiqlConfigOption
+		if cfg.Demo.Enabled && cfg.Demo.APIKey != "" {
+			// Ключ демонстрационной организации только на чтение, чтобы примеры запросов работали сразу
+			playgroundOptions = append(playgroundOptions, playground.WithGraphiqlUiHeaders(map[string]string{auth.APIKeyHeader: cfg.Demo.APIKey}))
+		}
+		mux.Handle("/playground", playground.Handler("GraphQL playground", "/query", playgroundOptions...))
+
+		// Все маршруты проходят одну цепочку middleware, новые маршруты получают ее автоматически
+		stack := httpserver.Stack{
+			Recover:   httpserver.Recover(log),
+			RequestID: httpserver.Chain(httpapi.RequestID, httpapi.TraceContext),
+			Auth: func(next http.Handler) http.Handler {
+				if cfg.Users.Enabled {
+					// Роли и организация пользователя из базы, отключенные учетные записи не допускаются
+					next = auth.MembershipMiddleware(userService, next)
+				}
+				return httpapi.BlockAbusiveClients(abuseLimiter, auth.APIKeyMiddleware(apiKeyService, authProxy, next))
+			},
+			CORS: httpserver.CORS(cfg.Server.CORSAllowedOrigins),
+			RateLimit: func(next http.Handler) http.Handler {
+				return httpapi.BlockAbusiveCallers(abuseLimiter, next)
+			},
+			AccessLog: func(next http.Handler) http.Handler {
+				return httpapi.AccessLog(next, cfg.Log.Access, log)
+			},
+			Metrics: httpserver.Metrics(mux),
 		}
-	}()
+		server = &http.Server{Addr: addr, Handler: stack.Handler(mux)}
+
+		log.Info("Starting server", zap.String("address", addr))
+
+		go func() {
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal("Failed to start server", zap.Error(err))
+			}
+		}()
+	}
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -158,12 +1006,10 @@ func main() {
 	defer cancel()
 
 	// Graceful shutdown
-	server := &http.Server{
-		Addr: addr,
-	}
-
-	if err := server.Shutdown(ctx); err != nil {
-		log.Error("Server forced to shutdown", zap.Error(err))
+	if server != nil {
+		if err := server.Shutdown(ctx); err != nil {
+			log.Error("Server forced to shutdown", zap.Error(err))
+		}
 	}
 
 	log.Info("Server exited")
@@ -0,0 +1,4 @@
+-- Migration 001 down: Drop verifications and their data
+
+DROP TABLE IF EXISTS verification_data;
+DROP TABLE IF EXISTS verifications;
@@ -0,0 +1,4 @@
+-- Migration 002 down: Remove company_id from verifications
+
+DROP INDEX IF EXISTS idx_verifications_company_id;
+ALTER TABLE verifications DROP COLUMN IF EXISTS company_id;
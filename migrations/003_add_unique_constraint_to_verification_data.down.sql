@@ -0,0 +1,3 @@
+-- Migration 003 down: Allow several rows of one data type per verification
+
+ALTER TABLE verification_data DROP CONSTRAINT IF EXISTS verification_data_unique_verification_id_data_type;
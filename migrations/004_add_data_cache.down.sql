@@ -0,0 +1,9 @@
+-- Migration 004 down: Remove the hash-based data cache
+-- Run after 005 down, which copies payloads back into verification_data.data.
+-- pgcrypto is kept: the extension may be used outside the gateway schema.
+
+DROP FUNCTION IF EXISTS migrate_existing_verification_data();
+DROP INDEX IF EXISTS idx_verification_data_hash;
+DROP INDEX IF EXISTS idx_verification_data_cache_hash;
+ALTER TABLE verification_data DROP COLUMN IF EXISTS data_hash;
+DROP TABLE IF EXISTS verification_data_cache;
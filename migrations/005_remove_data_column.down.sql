@@ -0,0 +1,12 @@
+-- Migration 005 down: Restore the data column of verification_data from the cache
+-- The column is restored nullable: rows without a cached payload stay empty.
+
+ALTER TABLE verification_data ADD COLUMN IF NOT EXISTS data JSONB;
+
+UPDATE verification_data d
+SET data = c.data
+FROM verification_data_cache c
+WHERE c.data_hash = d.data_hash AND d.data IS NULL;
+
+COMMENT ON TABLE verification_data IS NULL;
+COMMENT ON COLUMN verification_data.data_hash IS NULL;
@@ -0,0 +1,4 @@
+-- Migration 006 down: Remove the risk level reported by the scoring engine
+
+DROP INDEX IF EXISTS idx_verifications_inn_created_at;
+ALTER TABLE verifications DROP COLUMN IF EXISTS risk_level;
@@ -0,0 +1,7 @@
+-- Migration 006: Store risk level reported by the scoring engine
+-- Used by the monitoring job to put re-verifications of risky companies into the priority lane
+
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS risk_level VARCHAR(20);
+
+-- Speeds up lookup of the latest verification per company
+CREATE INDEX IF NOT EXISTS idx_verifications_inn_created_at ON verifications(inn, created_at DESC);
@@ -0,0 +1,3 @@
+-- Migration 007 down: Drop the audit log of verification lifecycle events
+
+DROP TABLE IF EXISTS verification_events;
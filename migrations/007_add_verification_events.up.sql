@@ -0,0 +1,14 @@
+-- Migration 007: Audit log of verification lifecycle events
+-- Creation and data arrivals are derived from verifications/verification_data,
+-- everything else (status transitions, user actions, notification deliveries) is stored here
+
+CREATE TABLE IF NOT EXISTS verification_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    verification_id UUID NOT NULL REFERENCES verifications(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    actor VARCHAR(255),
+    details JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_verification_events_verification_id ON verification_events(verification_id, created_at);
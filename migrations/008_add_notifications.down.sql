@@ -0,0 +1,3 @@
+-- Migration 008 down: Drop the per-user notification inbox
+
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,16 @@
+-- Migration 008: Per-user notification inbox
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_email VARCHAR(255) NOT NULL,
+    kind VARCHAR(50) NOT NULL,
+    verification_id UUID REFERENCES verifications(id) ON DELETE CASCADE,
+    message TEXT NOT NULL,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- One notification of each kind per user and verification
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_user_kind_verification ON notifications(user_email, kind, verification_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created_at ON notifications(user_email, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread ON notifications(user_email) WHERE read_at IS NULL;
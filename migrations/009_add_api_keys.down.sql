@@ -0,0 +1,3 @@
+-- Migration 009 down: Drop service account API keys
+
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,14 @@
+-- Migration 009: Service account API keys restricted by operation and data type
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    -- SHA-256 of the key in hex, the key itself is never stored
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    service_email VARCHAR(255) NOT NULL,
+    -- Empty array means no restriction
+    allowed_operations TEXT[] NOT NULL DEFAULT '{}',
+    allowed_data_types TEXT[] NOT NULL DEFAULT '{}',
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
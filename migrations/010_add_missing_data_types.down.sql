@@ -0,0 +1,5 @@
+-- Migration 010 down: Stop tracking undelivered data types
+
+DROP INDEX IF EXISTS idx_verifications_partially_completed;
+ALTER TABLE verifications DROP COLUMN IF EXISTS missing_data_retries;
+ALTER TABLE verifications DROP COLUMN IF EXISTS missing_data_types;
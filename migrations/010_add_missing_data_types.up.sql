@@ -0,0 +1,7 @@
+-- Migration 010: Track requested data types that were not delivered on completion
+
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS missing_data_types TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS missing_data_retries INT NOT NULL DEFAULT 0;
+
+-- Used by the retry job to find partially completed verifications
+CREATE INDEX IF NOT EXISTS idx_verifications_partially_completed ON verifications(updated_at) WHERE status = 'PARTIALLY_COMPLETED';
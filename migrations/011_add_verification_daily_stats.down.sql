@@ -0,0 +1,4 @@
+-- Migration 011 down: Drop pre-aggregated daily statistics
+-- The view is derived from verifications and is rebuilt by applying the migration again
+
+DROP MATERIALIZED VIEW IF EXISTS verification_daily_stats;
@@ -0,0 +1,15 @@
+-- Migration 011: Pre-aggregated daily statistics, refreshed by the statistics job
+-- Organization is the author email domain until organizations are modelled explicitly
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS verification_daily_stats AS
+SELECT
+    (created_at AT TIME ZONE 'UTC')::date AS day,
+    status,
+    split_part(author_email, '@', 2) AS organization,
+    COUNT(*) AS total,
+    AVG(EXTRACT(EPOCH FROM updated_at - created_at)) FILTER (WHERE status = 'COMPLETED') AS avg_completion_seconds
+FROM verifications
+GROUP BY 1, 2, 3;
+
+-- Required for REFRESH MATERIALIZED VIEW CONCURRENTLY
+CREATE UNIQUE INDEX IF NOT EXISTS idx_verification_daily_stats_key ON verification_daily_stats(day, status, organization);
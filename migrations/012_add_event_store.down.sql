@@ -0,0 +1,3 @@
+-- Migration 012 down: Drop the event store
+
+DROP TABLE IF EXISTS verification_event_store;
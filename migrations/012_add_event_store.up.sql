@@ -0,0 +1,11 @@
+-- Migration 012: Append-only event store for the event-sourced mode
+-- Events are never updated or deleted, verifications is rebuilt from them on demand
+
+CREATE TABLE IF NOT EXISTS verification_event_store (
+    verification_id UUID NOT NULL,
+    version INT NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (verification_id, version)
+);
@@ -0,0 +1,3 @@
+-- Migration 013 down: Drop identifiers of verifications in external systems
+
+DROP TABLE IF EXISTS verification_external_refs;
@@ -0,0 +1,11 @@
+-- Migration 013: Identifiers of verifications in external case management systems
+-- Written by the gateway at creation time, before the worker inserts the verification itself
+
+CREATE TABLE IF NOT EXISTS verification_external_refs (
+    verification_id UUID PRIMARY KEY,
+    external_system VARCHAR(100),
+    external_ref VARCHAR(255) NOT NULL,
+    linked_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_verification_external_refs_ref ON verification_external_refs(external_ref, external_system);
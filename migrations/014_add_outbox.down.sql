@@ -0,0 +1,4 @@
+-- Migration 014 down: Drop the outbox of deferred verification requests
+-- Requests still waiting in the outbox are lost, drain it with the relay job first
+
+DROP TABLE IF EXISTS outbox_messages;
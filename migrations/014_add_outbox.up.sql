@@ -0,0 +1,17 @@
+-- Migration 014: Outbox for verification requests deferred by the publish throttle
+-- The relay job claims due rows with SKIP LOCKED so several gateway instances can share the queue
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id UUID PRIMARY KEY,
+    tenant VARCHAR(255) NOT NULL,
+    priority VARCHAR(20) NOT NULL DEFAULT 'normal',
+    payload JSONB NOT NULL,
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    claimed_until TIMESTAMP WITH TIME ZONE,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_pending ON outbox_messages(available_at) WHERE published_at IS NULL;
@@ -0,0 +1,3 @@
+-- Migration 015 down: Remove the index for date-range filters
+
+DROP INDEX IF EXISTS idx_verifications_created_at;
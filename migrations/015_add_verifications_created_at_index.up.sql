@@ -0,0 +1,4 @@
+-- Migration 015: Index for date-range filters
+-- Timezone-aware bounds are converted to timestamps in SQL, so the filters compare created_at directly
+
+CREATE INDEX IF NOT EXISTS idx_verifications_created_at ON verifications(created_at DESC);
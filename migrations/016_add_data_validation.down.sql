@@ -0,0 +1,4 @@
+-- Migration 016 down: Remove JSON Schema validation results
+
+ALTER TABLE verification_data DROP COLUMN IF EXISTS validated_at;
+ALTER TABLE verification_data DROP COLUMN IF EXISTS validation_errors;
@@ -0,0 +1,5 @@
+-- Migration 016: JSON Schema validation results for delivered data
+-- Invalid payloads are kept and annotated instead of being silently accepted
+
+ALTER TABLE verification_data ADD COLUMN IF NOT EXISTS validation_errors TEXT[];
+ALTER TABLE verification_data ADD COLUMN IF NOT EXISTS validated_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,3 @@
+-- Migration 017 down: Drop organizations and their NATS routes
+
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,15 @@
+-- Migration 017: Organizations with per-tenant NATS routing
+-- The organization id is the author email domain, the same key the publish throttle uses as tenant.
+-- A tenant with nats_subject_prefix gets its own subjects (<prefix>.verification.create etc.),
+-- which can be bound to a separate JetStream stream on the NATS side.
+
+CREATE TABLE IF NOT EXISTS organizations (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    nats_subject_prefix VARCHAR(255),
+    nats_credentials_file TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_nats_subject_prefix ON organizations(nats_subject_prefix) WHERE nats_subject_prefix IS NOT NULL;
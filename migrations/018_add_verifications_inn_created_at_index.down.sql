@@ -0,0 +1,2 @@
+-- Migration 018 down: Nothing to do
+-- idx_verifications_inn_created_at is also created by migration 006 and is dropped by its down script
@@ -0,0 +1,4 @@
+-- Migration 018: Index for point-in-time company lookups
+-- companySnapshot looks up the latest verification of an INN created before a given moment
+
+CREATE INDEX IF NOT EXISTS idx_verifications_inn_created_at ON verifications(inn, created_at DESC);
@@ -0,0 +1,7 @@
+-- Migration 019 down: Remove anonymization state and legal holds
+-- Already anonymized emails are not restored
+
+DROP FUNCTION IF EXISTS anonymize_email(TEXT, TEXT);
+DROP INDEX IF EXISTS idx_verifications_anonymization_candidates;
+ALTER TABLE verifications DROP COLUMN IF EXISTS anonymized_at;
+ALTER TABLE verifications DROP COLUMN IF EXISTS legal_hold;
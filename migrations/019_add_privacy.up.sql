@@ -0,0 +1,18 @@
+-- Migration 019: Anonymization of author emails after the retention period
+-- legal_hold exempts a verification under investigation, anonymized_at marks processed rows
+
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS legal_hold BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_verifications_anonymization_candidates ON verifications(created_at)
+    WHERE anonymized_at IS NULL AND legal_hold = false;
+
+-- Salted pseudonym of an email: the same address always maps to the same value, so analytics stay joinable,
+-- and the domain is kept for per-organization statistics. Already anonymized values are returned as is.
+CREATE OR REPLACE FUNCTION anonymize_email(email TEXT, salt TEXT) RETURNS TEXT AS $$
+    SELECT CASE
+        WHEN email IS NULL OR email = '' OR email LIKE 'anon-%' THEN email
+        ELSE 'anon-' || left(encode(digest(salt || lower(email), 'sha256'), 'hex'), 32) ||
+            CASE WHEN position('@' IN email) > 0 THEN '@' || split_part(email, '@', 2) ELSE '' END
+    END
+$$ LANGUAGE sql IMMUTABLE;
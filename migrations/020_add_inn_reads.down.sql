@@ -0,0 +1,3 @@
+-- Migration 020 down: Drop daily read counters
+
+DROP TABLE IF EXISTS inn_reads;
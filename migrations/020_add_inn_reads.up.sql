@@ -0,0 +1,11 @@
+-- Migration 020: Daily read counters per INN for cache prefetching
+-- The gateway buffers reads in memory and flushes them periodically, so a row is updated at most once per flush
+
+CREATE TABLE IF NOT EXISTS inn_reads (
+    inn VARCHAR(12) NOT NULL,
+    day DATE NOT NULL,
+    reads INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (inn, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_inn_reads_day ON inn_reads(day);
@@ -0,0 +1,3 @@
+-- Migration 021 down: Drop the audit log of caller lockouts
+
+DROP TABLE IF EXISTS caller_lockouts;
@@ -0,0 +1,14 @@
+-- Migration 021: Audit log of temporary blocks applied to callers sending repeated invalid requests
+-- Blocks themselves are kept in gateway memory; this table only records when and why they were applied
+
+CREATE TABLE IF NOT EXISTS caller_lockouts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    caller VARCHAR(255) NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    failures JSONB NOT NULL,
+    strike INT NOT NULL,
+    blocked_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_caller_lockouts_caller ON caller_lockouts(caller, created_at);
@@ -0,0 +1,6 @@
+-- Migration 022 down: Remove sandbox flags
+-- Sandbox verifications become indistinguishable from real ones, delete them first if needed
+
+ALTER TABLE verifications DROP COLUMN IF EXISTS sandbox;
+ALTER TABLE organizations DROP COLUMN IF EXISTS sandbox;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS sandbox;
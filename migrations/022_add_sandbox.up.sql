@@ -0,0 +1,7 @@
+-- Migration 022: Sandbox mode for API keys and organizations
+-- Verifications requested through a sandbox key or by a sandbox organization never reach the
+-- providers: the gateway stores them as completed with synthetic data and marks them sandbox.
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS sandbox BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS sandbox BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS sandbox BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,6 @@
+-- Migration 023 down: Drop score history and recalculations
+-- verifications.risk_level keeps the current score
+
+DROP TABLE IF EXISTS verification_scores;
+DROP TABLE IF EXISTS score_recalculations;
+ALTER TABLE verifications DROP COLUMN IF EXISTS risk_ruleset_id;
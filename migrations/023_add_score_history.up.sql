@@ -0,0 +1,34 @@
+-- Migration 023: Versioned risk scores and on-demand recalculation
+-- verifications.risk_level stays the current score; every score, including the one it replaced,
+-- is kept in verification_scores together with the scoring ruleset that produced it.
+
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS risk_ruleset_id VARCHAR(100);
+
+CREATE TABLE IF NOT EXISTS score_recalculations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    filter JSONB NOT NULL,
+    requested_by VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    total INT NOT NULL DEFAULT 0,
+    published INT NOT NULL DEFAULT 0,
+    rescored INT NOT NULL DEFAULT 0,
+    -- Keyset position of the last verification sent to the scoring engine
+    cursor_created_at TIMESTAMP WITH TIME ZONE,
+    cursor_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_score_recalculations_active ON score_recalculations(created_at) WHERE status <> 'COMPLETED';
+
+CREATE TABLE IF NOT EXISTS verification_scores (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    verification_id UUID NOT NULL REFERENCES verifications(id) ON DELETE CASCADE,
+    risk_level VARCHAR(20) NOT NULL,
+    ruleset_id VARCHAR(100),
+    recalculation_id UUID REFERENCES score_recalculations(id) ON DELETE SET NULL,
+    scored_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_verification_scores_verification_id ON verification_scores(verification_id, scored_at DESC);
@@ -0,0 +1,3 @@
+-- Migration 024 down: Drop signed verification results
+
+DROP TABLE IF EXISTS verification_signatures;
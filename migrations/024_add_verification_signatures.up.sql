@@ -0,0 +1,17 @@
+-- Migration 024: Signed verification results
+-- Each row signs the status, completion time and payload hashes of a verification with the gateway key.
+-- Rows are never updated: a re-signed verification gets a new row, the latest one is returned.
+
+CREATE TABLE IF NOT EXISTS verification_signatures (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    verification_id UUID NOT NULL REFERENCES verifications(id) ON DELETE CASCADE,
+    algorithm VARCHAR(20) NOT NULL,
+    key_id VARCHAR(64) NOT NULL,
+    -- Exact signed text, so auditors can verify it without knowing how the gateway builds it
+    content TEXT NOT NULL,
+    digest VARCHAR(64) NOT NULL,
+    signature TEXT NOT NULL,
+    signed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_verification_signatures_verification_id ON verification_signatures(verification_id, signed_at DESC);
@@ -0,0 +1,3 @@
+-- Migration 025 down: Drop persisted GraphQL operations
+
+DROP TABLE IF EXISTS persisted_operations;
@@ -0,0 +1,13 @@
+-- Migration 025: Persisted GraphQL operations allowed for each API key
+
+CREATE TABLE IF NOT EXISTS persisted_operations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    api_key_id UUID NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    -- SHA-256 of the document in hex, as sent in extensions.persistedQuery.sha256Hash
+    hash VARCHAR(64) NOT NULL,
+    name VARCHAR(255),
+    document TEXT NOT NULL,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT persisted_operations_unique_api_key_id_hash UNIQUE (api_key_id, hash)
+);
@@ -0,0 +1,4 @@
+-- Migration 026 down: Drop users and organization memberships
+
+DROP TABLE IF EXISTS memberships;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,6 @@
+-- Migration 027 down: Remove composite cache keys with provider schema versions
+
+DROP TRIGGER IF EXISTS verification_data_company_cache ON verification_data;
+DROP FUNCTION IF EXISTS refresh_company_data_cache();
+DROP TABLE IF EXISTS company_data_cache;
+ALTER TABLE verification_data DROP COLUMN IF EXISTS schema_version;
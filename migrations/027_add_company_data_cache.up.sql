@@ -0,0 +1,52 @@
+-- Migration 027: Composite cache keys with provider schema versions
+-- verification_data_cache stays content-addressed by data_hash. company_data_cache points each
+-- (inn, data type, provider schema version) to the latest payload, so a payload delivered in an
+-- old provider format is never returned to a client asking for the current one.
+-- Workers set verification_data.schema_version; rows written before this migration are version 1.
+
+ALTER TABLE verification_data ADD COLUMN IF NOT EXISTS schema_version INT NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS company_data_cache (
+    inn VARCHAR(12) NOT NULL,
+    data_type VARCHAR(50) NOT NULL,
+    schema_version INT NOT NULL,
+    data_hash VARCHAR(64) NOT NULL,
+    verification_id UUID NOT NULL REFERENCES verifications(id) ON DELETE CASCADE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (inn, data_type, schema_version)
+);
+
+CREATE OR REPLACE FUNCTION refresh_company_data_cache() RETURNS trigger AS $$
+BEGIN
+    IF NEW.data_hash IS NULL OR NEW.data_hash = '' THEN
+        RETURN NEW;
+    END IF;
+
+    -- Sandbox payloads are synthetic and must not be served as real company data
+    INSERT INTO company_data_cache (inn, data_type, schema_version, data_hash, verification_id, updated_at)
+    SELECT v.inn, NEW.data_type, NEW.schema_version, NEW.data_hash, v.id, NOW()
+    FROM verifications v
+    WHERE v.id = NEW.verification_id AND NOT v.sandbox
+    ON CONFLICT (inn, data_type, schema_version) DO UPDATE
+        SET data_hash = EXCLUDED.data_hash,
+            verification_id = EXCLUDED.verification_id,
+            updated_at = EXCLUDED.updated_at;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS verification_data_company_cache ON verification_data;
+CREATE TRIGGER verification_data_company_cache
+    AFTER INSERT OR UPDATE OF data_hash, schema_version ON verification_data
+    FOR EACH ROW EXECUTE FUNCTION refresh_company_data_cache();
+
+-- Backfill from the latest delivered payload of each company and data type
+INSERT INTO company_data_cache (inn, data_type, schema_version, data_hash, verification_id, updated_at)
+SELECT DISTINCT ON (v.inn, d.data_type, d.schema_version)
+    v.inn, d.data_type, d.schema_version, d.data_hash, v.id, d.created_at
+FROM verification_data d
+JOIN verifications v ON v.id = d.verification_id
+WHERE d.data_hash IS NOT NULL AND d.data_hash <> '' AND NOT v.sandbox
+ORDER BY v.inn, d.data_type, d.schema_version, d.created_at DESC
+ON CONFLICT (inn, data_type, schema_version) DO NOTHING;
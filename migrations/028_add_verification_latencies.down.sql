@@ -0,0 +1,3 @@
+-- Migration 028 down: Drop completion latencies of data types
+
+DROP TABLE IF EXISTS verification_latencies;
@@ -0,0 +1,13 @@
+-- Migration 028: Completion latency of each requested data type
+-- Recorded once when the verification completes; first_data_seconds is NULL for types that were not delivered
+
+CREATE TABLE IF NOT EXISTS verification_latencies (
+    verification_id UUID NOT NULL REFERENCES verifications(id) ON DELETE CASCADE,
+    data_type VARCHAR(50) NOT NULL,
+    first_data_seconds DOUBLE PRECISION,
+    completed_seconds DOUBLE PRECISION NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (verification_id, data_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_verification_latencies_completed_at ON verification_latencies(completed_at, data_type);
@@ -0,0 +1,10 @@
+-- Migration 029 down: Drop the change stream of the analytics warehouse export
+-- Changes not yet exported are lost
+
+DROP TRIGGER IF EXISTS verification_data_warehouse_changes ON verification_data;
+DROP FUNCTION IF EXISTS record_warehouse_data_arrival();
+DROP TRIGGER IF EXISTS verifications_warehouse_changes ON verifications;
+DROP FUNCTION IF EXISTS record_warehouse_verification_change();
+DROP FUNCTION IF EXISTS warehouse_verification_record(verifications);
+DROP TABLE IF EXISTS export_bookmarks;
+DROP TABLE IF EXISTS warehouse_changes;
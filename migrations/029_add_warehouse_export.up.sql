@@ -0,0 +1,81 @@
+-- Migration 029: Append-only change stream for the analytics warehouse export
+-- Triggers record every verification status change and data arrival with a monotonic sequence.
+-- The exporter reads changes after its bookmark and advances it only after the sink accepts a batch,
+-- so a crash between the two resends the batch (at-least-once delivery).
+-- Author emails are not exported: records carry only the tenant (email domain).
+-- Changes made before this migration are not backfilled.
+
+CREATE TABLE IF NOT EXISTS warehouse_changes (
+    seq BIGSERIAL PRIMARY KEY,
+    record_type VARCHAR(30) NOT NULL,
+    verification_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_warehouse_changes_created_at ON warehouse_changes(created_at);
+
+CREATE TABLE IF NOT EXISTS export_bookmarks (
+    exporter VARCHAR(100) PRIMARY KEY,
+    position BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE OR REPLACE FUNCTION warehouse_verification_record(v verifications) RETURNS JSONB AS $$
+    SELECT jsonb_build_object(
+        'verification_id', v.id,
+        'inn', v.inn,
+        'tenant', lower(split_part(v.author_email, '@', 2)),
+        'status', v.status,
+        'risk_level', v.risk_level,
+        'requested_data_types', to_jsonb(v.requested_data_types),
+        'missing_data_types', to_jsonb(v.missing_data_types),
+        'created_at', v.created_at,
+        'updated_at', v.updated_at
+    );
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION record_warehouse_verification_change() RETURNS trigger AS $$
+BEGIN
+    IF NEW.sandbox THEN
+        RETURN NEW;
+    END IF;
+    IF TG_OP = 'UPDATE' AND NEW.status IS NOT DISTINCT FROM OLD.status
+        AND NEW.risk_level IS NOT DISTINCT FROM OLD.risk_level
+        AND NEW.missing_data_types IS NOT DISTINCT FROM OLD.missing_data_types THEN
+        RETURN NEW;
+    END IF;
+
+    INSERT INTO warehouse_changes (record_type, verification_id, payload)
+    VALUES ('verification', NEW.id, warehouse_verification_record(NEW));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS verifications_warehouse_changes ON verifications;
+CREATE TRIGGER verifications_warehouse_changes
+    AFTER INSERT OR UPDATE ON verifications
+    FOR EACH ROW EXECUTE FUNCTION record_warehouse_verification_change();
+
+CREATE OR REPLACE FUNCTION record_warehouse_data_arrival() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO warehouse_changes (record_type, verification_id, payload)
+    SELECT 'data_arrival', v.id, jsonb_build_object(
+        'verification_id', v.id,
+        'inn', v.inn,
+        'tenant', lower(split_part(v.author_email, '@', 2)),
+        'data_type', NEW.data_type,
+        'schema_version', NEW.schema_version,
+        'data_hash', NEW.data_hash,
+        'received_at', NEW.created_at
+    )
+    FROM verifications v
+    WHERE v.id = NEW.verification_id AND NOT v.sandbox;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS verification_data_warehouse_changes ON verification_data;
+CREATE TRIGGER verification_data_warehouse_changes
+    AFTER INSERT ON verification_data
+    FOR EACH ROW EXECUTE FUNCTION record_warehouse_data_arrival();
@@ -0,0 +1,7 @@
+-- Migration 030 down: Remove manual review of verifications
+
+DROP INDEX IF EXISTS idx_verifications_assignee_review_state;
+ALTER TABLE verifications DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE verifications DROP COLUMN IF EXISTS review_comment;
+ALTER TABLE verifications DROP COLUMN IF EXISTS review_state;
+ALTER TABLE verifications DROP COLUMN IF EXISTS assignee;
@@ -0,0 +1,10 @@
+-- Migration 030: Manual review of scored verifications
+-- review_state: UNREVIEWED -> IN_REVIEW (assigned or claimed) -> APPROVED or REJECTED.
+-- Only the assignee decides, and only after the verification is completed.
+
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS assignee VARCHAR(255);
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS review_state VARCHAR(20) NOT NULL DEFAULT 'UNREVIEWED';
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS review_comment TEXT;
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_verifications_assignee_review_state ON verifications(assignee, review_state, created_at DESC) WHERE assignee IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Migration 031 down: Drop webhook registrations
+
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,13 @@
+-- Migration 031: Webhook registrations with payload templates
+-- template: FULL (verification JSON), SUMMARY, CLOUDEVENTS (1.0 structured envelope) or CUSTOM,
+-- rendered from custom_template. headers is an ordered list of {name, value}; values are templates too.
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url TEXT NOT NULL,
+    template VARCHAR(20) NOT NULL DEFAULT 'FULL',
+    custom_template TEXT,
+    headers JSONB NOT NULL DEFAULT '[]',
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
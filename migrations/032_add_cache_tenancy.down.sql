@@ -0,0 +1,34 @@
+-- Migration 032 down: Remove tenant scoping of the company data cache
+-- Global copies are dropped, and of the tenant rows only the latest per company key is kept.
+
+DROP TABLE IF EXISTS cache_sharing;
+
+DELETE FROM company_data_cache WHERE tenant_id = '*';
+DELETE FROM company_data_cache k
+USING company_data_cache newer
+WHERE newer.inn = k.inn AND newer.data_type = k.data_type AND newer.schema_version = k.schema_version
+  AND (newer.updated_at, newer.tenant_id) > (k.updated_at, k.tenant_id);
+
+ALTER TABLE company_data_cache DROP CONSTRAINT IF EXISTS company_data_cache_pkey;
+ALTER TABLE company_data_cache ADD PRIMARY KEY (inn, data_type, schema_version);
+ALTER TABLE company_data_cache DROP COLUMN IF EXISTS tenant_id;
+
+CREATE OR REPLACE FUNCTION refresh_company_data_cache() RETURNS trigger AS $$
+BEGIN
+    IF NEW.data_hash IS NULL OR NEW.data_hash = '' THEN
+        RETURN NEW;
+    END IF;
+
+    -- Sandbox payloads are synthetic and must not be served as real company data
+    INSERT INTO company_data_cache (inn, data_type, schema_version, data_hash, verification_id, updated_at)
+    SELECT v.inn, NEW.data_type, NEW.schema_version, NEW.data_hash, v.id, NOW()
+    FROM verifications v
+    WHERE v.id = NEW.verification_id AND NOT v.sandbox
+    ON CONFLICT (inn, data_type, schema_version) DO UPDATE
+        SET data_hash = EXCLUDED.data_hash,
+            verification_id = EXCLUDED.verification_id,
+            updated_at = EXCLUDED.updated_at;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
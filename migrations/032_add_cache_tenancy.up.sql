@@ -0,0 +1,47 @@
+-- Migration 032: Tenant scoping of the company data cache
+-- Each company_data_cache row belongs to the tenant of the verification author (the email domain,
+-- 'default' without one), and reads only see the caller's tenant. Rows with tenant_id '*' are the
+-- global copy, written for data types an admin has shared in cache_sharing and visible to every
+-- tenant while sharing stays enabled. verification_data_cache is content-addressed and not scoped.
+
+ALTER TABLE company_data_cache ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(255) NOT NULL DEFAULT 'default';
+
+UPDATE company_data_cache k
+SET tenant_id = COALESCE(NULLIF(lower(substring(v.author_email FROM '@([^@]+)$')), ''), 'default')
+FROM verifications v
+WHERE v.id = k.verification_id;
+
+ALTER TABLE company_data_cache DROP CONSTRAINT IF EXISTS company_data_cache_pkey;
+ALTER TABLE company_data_cache ADD PRIMARY KEY (tenant_id, inn, data_type, schema_version);
+
+CREATE TABLE IF NOT EXISTS cache_sharing (
+    data_type VARCHAR(50) PRIMARY KEY,
+    shared BOOLEAN NOT NULL DEFAULT false,
+    updated_by VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE OR REPLACE FUNCTION refresh_company_data_cache() RETURNS trigger AS $$
+BEGIN
+    IF NEW.data_hash IS NULL OR NEW.data_hash = '' THEN
+        RETURN NEW;
+    END IF;
+
+    -- Sandbox payloads are synthetic and must not be served as real company data
+    INSERT INTO company_data_cache (tenant_id, inn, data_type, schema_version, data_hash, verification_id, updated_at)
+    SELECT t.tenant_id, v.inn, NEW.data_type, NEW.schema_version, NEW.data_hash, v.id, NOW()
+    FROM verifications v
+    CROSS JOIN LATERAL (
+        SELECT COALESCE(NULLIF(lower(substring(v.author_email FROM '@([^@]+)$')), ''), 'default') AS tenant_id
+        UNION ALL
+        SELECT '*' WHERE EXISTS (SELECT 1 FROM cache_sharing s WHERE s.data_type = NEW.data_type AND s.shared)
+    ) t
+    WHERE v.id = NEW.verification_id AND NOT v.sandbox
+    ON CONFLICT (tenant_id, inn, data_type, schema_version) DO UPDATE
+        SET data_hash = EXCLUDED.data_hash,
+            verification_id = EXCLUDED.verification_id,
+            updated_at = EXCLUDED.updated_at;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,3 @@
+-- Migration 033 down: Drop hourly API usage
+
+DROP TABLE IF EXISTS api_usage;
@@ -0,0 +1,17 @@
+-- Migration 033: Hourly API usage per client
+-- client_id is the API key id for service accounts and the email for users.
+-- Rows older than USAGE_RETENTION are removed by the usage flush job.
+
+CREATE TABLE IF NOT EXISTS api_usage (
+    client_kind VARCHAR(10) NOT NULL,
+    client_id VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    hour TIMESTAMP WITH TIME ZONE NOT NULL,
+    operation VARCHAR(255) NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    errors BIGINT NOT NULL DEFAULT 0,
+    response_bytes BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (client_kind, client_id, hour, operation)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_usage_hour ON api_usage(hour);
@@ -0,0 +1,6 @@
+-- Migration 034 down: Drop verification amendments
+-- Amended payloads stay in verification_data; only the amendment history is lost.
+
+DROP TRIGGER IF EXISTS verification_data_amendments ON verification_data;
+DROP FUNCTION IF EXISTS record_verification_amendment();
+DROP TABLE IF EXISTS verification_amendments;
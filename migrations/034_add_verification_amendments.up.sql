@@ -0,0 +1,52 @@
+-- Migration 034: Amendments for data delivered after a verification was completed
+-- A payload written by a worker (new data type or changed data_hash) while the verification is
+-- COMPLETED or PARTIALLY_COMPLETED is recorded as an amendment and bumps verifications.updated_at.
+-- Sandbox verifications are stored completed together with their synthetic data and are skipped.
+-- The gateway publishes verification.amended for each amendment and sets published_at.
+
+CREATE TABLE IF NOT EXISTS verification_amendments (
+    id BIGSERIAL PRIMARY KEY,
+    verification_id UUID NOT NULL REFERENCES verifications(id) ON DELETE CASCADE,
+    data_type VARCHAR(50) NOT NULL,
+    data_hash VARCHAR(64),
+    previous_data_hash VARCHAR(64),
+    schema_version INT NOT NULL DEFAULT 1,
+    status_at_arrival VARCHAR(20) NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    claimed_until TIMESTAMP WITH TIME ZONE,
+    attempts INT NOT NULL DEFAULT 0,
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_verification_amendments_verification ON verification_amendments(verification_id, received_at);
+CREATE INDEX IF NOT EXISTS idx_verification_amendments_unpublished ON verification_amendments(id) WHERE published_at IS NULL;
+
+CREATE OR REPLACE FUNCTION record_verification_amendment() RETURNS trigger AS $$
+DECLARE
+    current_status VARCHAR(20);
+    is_sandbox BOOLEAN;
+BEGIN
+    IF TG_OP = 'UPDATE' AND OLD.data_hash IS NOT DISTINCT FROM NEW.data_hash THEN
+        RETURN NEW;
+    END IF;
+
+    SELECT status, sandbox INTO current_status, is_sandbox FROM verifications WHERE id = NEW.verification_id;
+    IF is_sandbox OR current_status NOT IN ('COMPLETED', 'PARTIALLY_COMPLETED') THEN
+        RETURN NEW;
+    END IF;
+
+    INSERT INTO verification_amendments (verification_id, data_type, data_hash, previous_data_hash, schema_version, status_at_arrival)
+    VALUES (
+        NEW.verification_id, NEW.data_type, NEW.data_hash,
+        CASE WHEN TG_OP = 'UPDATE' THEN OLD.data_hash END,
+        NEW.schema_version, current_status
+    );
+    UPDATE verifications SET updated_at = NOW() WHERE id = NEW.verification_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS verification_data_amendments ON verification_data;
+CREATE TRIGGER verification_data_amendments
+    AFTER INSERT OR UPDATE OF data_hash ON verification_data
+    FOR EACH ROW EXECUTE FUNCTION record_verification_amendment();
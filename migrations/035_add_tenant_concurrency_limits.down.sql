@@ -0,0 +1,9 @@
+-- Migration 035 down: Remove per-tenant in-flight limits
+-- Held outbox messages become ordinary deferred publishes and are sent by the relay job
+
+DROP INDEX IF EXISTS idx_verifications_in_flight_tenant;
+DROP INDEX IF EXISTS idx_outbox_messages_held;
+UPDATE outbox_messages SET available_at = NOW() WHERE held AND published_at IS NULL;
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS released_at;
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS held;
+ALTER TABLE organizations DROP COLUMN IF EXISTS max_in_flight;
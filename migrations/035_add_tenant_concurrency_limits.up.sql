@@ -0,0 +1,16 @@
+-- Migration 035: Per-tenant limits on verifications in flight toward the workers
+-- A creation over the tenant limit is stored in outbox_messages as held; the release job un-holds
+-- held rows (high priority first) as the tenant's IN_PROCESS verifications complete.
+-- organizations.max_in_flight overrides the gateway default, 0 means no limit.
+
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS max_in_flight INT;
+
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS held BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS released_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_held ON outbox_messages(tenant, created_at) WHERE held AND published_at IS NULL;
+
+-- The expression must match TenantInFlightExpr in the repository: the domain after the last '@'
+CREATE INDEX IF NOT EXISTS idx_verifications_in_flight_tenant
+    ON verifications ((COALESCE(lower(substring(author_email FROM '@([^@]+)$')), 'default')))
+    WHERE status IN ('IN_PROCESS', 'PROCESSING') AND NOT sandbox;
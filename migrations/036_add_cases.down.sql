@@ -0,0 +1,4 @@
+-- Migration 036 down: Remove cases
+
+DROP TABLE IF EXISTS case_verifications;
+DROP TABLE IF EXISTS cases;
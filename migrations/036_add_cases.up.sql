@@ -0,0 +1,23 @@
+-- Migration 036: Cases grouping verifications of related parties
+-- A case belongs to the tenant (email domain) of its author and holds verifications of that tenant.
+
+CREATE TABLE IF NOT EXISTS cases (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cases_tenant_updated ON cases(tenant, updated_at DESC);
+
+CREATE TABLE IF NOT EXISTS case_verifications (
+    case_id UUID NOT NULL REFERENCES cases(id) ON DELETE CASCADE,
+    verification_id UUID NOT NULL REFERENCES verifications(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL,
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (case_id, verification_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_case_verifications_verification ON case_verifications(verification_id);
@@ -0,0 +1,3 @@
+-- Migration 037 down: Remove the negative cache of unknown INNs
+
+DROP TABLE IF EXISTS inn_not_found;
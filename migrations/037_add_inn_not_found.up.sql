@@ -0,0 +1,12 @@
+-- Migration 037: Negative cache of INNs the providers did not find
+-- While expires_at is in the future, new verifications of the INN finish as COMPANY_NOT_FOUND
+-- without being sent to workers. hits counts such verifications.
+
+CREATE TABLE IF NOT EXISTS inn_not_found (
+    inn VARCHAR(12) PRIMARY KEY,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    hits BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_inn_not_found_expires ON inn_not_found(expires_at);
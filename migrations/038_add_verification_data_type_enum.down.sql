@@ -0,0 +1,5 @@
+-- Migration 038 down: Allow any text in requested and missing data types
+
+ALTER TABLE verifications DROP CONSTRAINT IF EXISTS verifications_missing_data_types_check;
+ALTER TABLE verifications DROP CONSTRAINT IF EXISTS verifications_requested_data_types_check;
+DROP TYPE IF EXISTS verification_data_type;
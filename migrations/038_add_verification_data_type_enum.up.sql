@@ -0,0 +1,27 @@
+-- Migration 038: Restrict requested and missing data types to the verification_data_type enum
+-- The enum mirrors model.VerificationDataType; values added to Go get a migration from cmd/enummigrate.
+-- The columns stay TEXT[] so that workers keep writing text arrays: the constraints only reject
+-- values outside the enum.
+
+DO $$
+BEGIN
+    CREATE TYPE verification_data_type AS ENUM (
+        'BASIC_INFORMATION',
+        'ACTIVITIES',
+        'ADDRESSES_BY_CREDINFORM',
+        'ADDRESSES_BY_UNIFIED_STATE_REGISTER',
+        'AFFILIATED_COMPANIES',
+        'ARBITRAGE_STATISTICS'
+    );
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END
+$$;
+
+ALTER TABLE verifications DROP CONSTRAINT IF EXISTS verifications_requested_data_types_check;
+ALTER TABLE verifications ADD CONSTRAINT verifications_requested_data_types_check
+    CHECK (requested_data_types::verification_data_type[] IS NOT NULL);
+
+ALTER TABLE verifications DROP CONSTRAINT IF EXISTS verifications_missing_data_types_check;
+ALTER TABLE verifications ADD CONSTRAINT verifications_missing_data_types_check
+    CHECK (missing_data_types::verification_data_type[] IS NOT NULL);
@@ -0,0 +1,6 @@
+-- Migration 039 down: Drop the history of data payload edits
+-- Edited payloads stay in verification_data; the original payloads and signatures are lost.
+
+DROP TRIGGER IF EXISTS verification_data_redactions_immutable ON verification_data_redactions;
+DROP FUNCTION IF EXISTS reject_verification_data_redaction_change();
+DROP TABLE IF EXISTS verification_data_redactions;
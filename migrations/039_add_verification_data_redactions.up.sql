@@ -0,0 +1,34 @@
+-- Migration 039: History of admin edits and redactions of stored data payloads
+-- An edit stores the changed payload in verification_data_cache and points verification_data at it,
+-- so the amendment trigger from migration 034 publishes verification.amended as usual. Each row keeps
+-- the payload as it was before the change and the gateway signature of the change. Rows cannot be
+-- updated or deleted, and a verification with history cannot be deleted.
+
+CREATE TABLE IF NOT EXISTS verification_data_redactions (
+    id UUID PRIMARY KEY,
+    verification_id UUID NOT NULL REFERENCES verifications(id),
+    data_type VARCHAR(50) NOT NULL,
+    operation VARCHAR(10) NOT NULL CHECK (operation IN ('PATCH', 'REDACT')),
+    path TEXT[] NOT NULL,
+    original_data JSONB NOT NULL,
+    previous_data_hash VARCHAR(64) NOT NULL,
+    data_hash VARCHAR(64) NOT NULL,
+    reason TEXT NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    key_id VARCHAR(64) NOT NULL,
+    signature TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_verification_data_redactions_verification ON verification_data_redactions(verification_id, created_at);
+
+CREATE OR REPLACE FUNCTION reject_verification_data_redaction_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'verification_data_redactions is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS verification_data_redactions_immutable ON verification_data_redactions;
+CREATE TRIGGER verification_data_redactions_immutable
+    BEFORE UPDATE OR DELETE ON verification_data_redactions
+    FOR EACH ROW EXECUTE FUNCTION reject_verification_data_redaction_change();
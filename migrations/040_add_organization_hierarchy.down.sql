@@ -0,0 +1,7 @@
+-- Migration 040 down: Remove parent organizations
+
+DROP TRIGGER IF EXISTS organizations_no_cycles ON organizations;
+DROP FUNCTION IF EXISTS reject_organization_cycle();
+DROP INDEX IF EXISTS idx_organizations_parent_id;
+ALTER TABLE organizations DROP CONSTRAINT IF EXISTS organizations_parent_not_self;
+ALTER TABLE organizations DROP COLUMN IF EXISTS parent_id;
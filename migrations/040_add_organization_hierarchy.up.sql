@@ -0,0 +1,34 @@
+-- Migration 040: Parent organizations (holding -> subsidiaries)
+-- A subsidiary without its own max_in_flight inherits the limit of the nearest ancestor that sets one,
+-- an org admin of an ancestor manages users of its subsidiaries, and statistics and spend reports
+-- can roll subsidiaries up into the parent. Cycles are rejected by a trigger.
+
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS parent_id VARCHAR(255) REFERENCES organizations(id) ON DELETE SET NULL;
+ALTER TABLE organizations DROP CONSTRAINT IF EXISTS organizations_parent_not_self;
+ALTER TABLE organizations ADD CONSTRAINT organizations_parent_not_self CHECK (parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_organizations_parent_id ON organizations(parent_id) WHERE parent_id IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION reject_organization_cycle() RETURNS trigger AS $$
+BEGIN
+    IF NEW.parent_id IS NULL THEN
+        RETURN NEW;
+    END IF;
+    IF EXISTS (
+        WITH RECURSIVE ancestors AS (
+            SELECT id, parent_id FROM organizations WHERE id = NEW.parent_id
+            UNION
+            SELECT o.id, o.parent_id FROM organizations o JOIN ancestors a ON o.id = a.parent_id
+        )
+        SELECT 1 FROM ancestors WHERE id = NEW.id
+    ) THEN
+        RAISE EXCEPTION 'organization % cannot be a subsidiary of its own subsidiary %', NEW.id, NEW.parent_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS organizations_no_cycles ON organizations;
+CREATE TRIGGER organizations_no_cycles
+    BEFORE INSERT OR UPDATE OF parent_id ON organizations
+    FOR EACH ROW EXECUTE FUNCTION reject_organization_cycle();
@@ -0,0 +1,7 @@
+-- Migration 041 down: Remove durable notification jobs
+-- Undelivered jobs and the delivery history are lost
+
+DROP TRIGGER IF EXISTS verifications_notification_jobs ON verifications;
+DROP FUNCTION IF EXISTS enqueue_notification_jobs();
+DROP TABLE IF EXISTS notification_job_attempts;
+DROP TABLE IF EXISTS notification_jobs;
@@ -0,0 +1,53 @@
+-- Migration 041: Durable notification jobs
+-- When a worker moves a verification to a final status, a trigger enqueues an in-app notification job
+-- and one job per registered webhook in the same transaction as the status update. The gateway marks
+-- the jobs ready once it has processed the completion, or picks them up after a timeout if the
+-- completion message never arrives, and delivers them at least once with per-attempt history.
+-- Sandbox verifications are stored completed and are skipped, as before.
+
+CREATE TABLE IF NOT EXISTS notification_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    verification_id UUID NOT NULL REFERENCES verifications(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('IN_APP', 'WEBHOOK')),
+    webhook_id UUID REFERENCES webhooks(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'DELIVERED', 'FAILED')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ready_at TIMESTAMP WITH TIME ZONE,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    claimed_until TIMESTAMP WITH TIME ZONE,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_jobs_pending ON notification_jobs(next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_notification_jobs_verification ON notification_jobs(verification_id, id);
+
+CREATE TABLE IF NOT EXISTS notification_job_attempts (
+    job_id BIGINT NOT NULL REFERENCES notification_jobs(id) ON DELETE CASCADE,
+    attempt INT NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    error TEXT,
+    PRIMARY KEY (job_id, attempt)
+);
+
+CREATE OR REPLACE FUNCTION enqueue_notification_jobs() RETURNS trigger AS $$
+BEGIN
+    IF NEW.sandbox
+        OR OLD.status NOT IN ('PENDING', 'IN_PROCESS', 'PROCESSING')
+        OR NEW.status IN ('PENDING', 'IN_PROCESS', 'PROCESSING') THEN
+        RETURN NEW;
+    END IF;
+
+    INSERT INTO notification_jobs (verification_id, channel) VALUES (NEW.id, 'IN_APP');
+    INSERT INTO notification_jobs (verification_id, channel, webhook_id)
+    SELECT NEW.id, 'WEBHOOK', id FROM webhooks;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS verifications_notification_jobs ON verifications;
+CREATE TRIGGER verifications_notification_jobs
+    AFTER UPDATE OF status ON verifications
+    FOR EACH ROW EXECUTE FUNCTION enqueue_notification_jobs();
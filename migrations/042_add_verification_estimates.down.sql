@@ -0,0 +1,3 @@
+-- Migration 042 down: Remove estimated completion times
+
+DROP TABLE IF EXISTS verification_estimates;
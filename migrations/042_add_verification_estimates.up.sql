@@ -0,0 +1,10 @@
+-- Migration 042: Estimated completion time of verifications
+-- Written by the gateway when a verification is published, before the worker inserts the verification
+-- itself, and refreshed by the completion_estimates job as data arrives
+
+CREATE TABLE IF NOT EXISTS verification_estimates (
+    verification_id UUID PRIMARY KEY,
+    processing_start_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    estimated_completion_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
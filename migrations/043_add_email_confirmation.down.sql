@@ -0,0 +1,6 @@
+-- Migration 043 down: Remove author email confirmation
+-- Held verification requests are lost
+
+DROP INDEX IF EXISTS idx_verifications_author_email;
+DROP TABLE IF EXISTS email_confirmation_holds;
+DROP TABLE IF EXISTS email_confirmations;
@@ -0,0 +1,26 @@
+-- Migration 043: Confirmation of author emails before their first verification
+-- Verification requests of a user whose email has no verifications yet are held until the author follows
+-- the emailed link; confirmation moves them to outbox_messages, and outbox_relay publishes them
+
+CREATE TABLE IF NOT EXISTS email_confirmations (
+    email VARCHAR(255) PRIMARY KEY,
+    token_hash VARCHAR(64) UNIQUE,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS email_confirmation_holds (
+    id UUID PRIMARY KEY,
+    author_email VARCHAR(255) NOT NULL,
+    tenant VARCHAR(255) NOT NULL,
+    priority VARCHAR(20) NOT NULL DEFAULT 'normal',
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_confirmation_holds_author ON email_confirmation_holds(author_email);
+
+-- An email that already has verifications does not need confirmation
+CREATE INDEX IF NOT EXISTS idx_verifications_author_email ON verifications(author_email);
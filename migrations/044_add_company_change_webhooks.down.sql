@@ -0,0 +1,26 @@
+-- Migration 044 down: Remove company change webhooks
+-- Pending COMPANY_CHANGE jobs are dropped; webhooks subscribed only to company changes start
+-- receiving completions again
+
+CREATE OR REPLACE FUNCTION enqueue_notification_jobs() RETURNS trigger AS $$
+BEGIN
+    IF NEW.sandbox
+        OR OLD.status NOT IN ('PENDING', 'IN_PROCESS', 'PROCESSING')
+        OR NEW.status IN ('PENDING', 'IN_PROCESS', 'PROCESSING') THEN
+        RETURN NEW;
+    END IF;
+
+    INSERT INTO notification_jobs (verification_id, channel) VALUES (NEW.id, 'IN_APP');
+    INSERT INTO notification_jobs (verification_id, channel, webhook_id)
+    SELECT NEW.id, 'WEBHOOK', id FROM webhooks;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DELETE FROM notification_jobs WHERE channel = 'COMPANY_CHANGE';
+ALTER TABLE notification_jobs DROP CONSTRAINT IF EXISTS notification_jobs_channel_check;
+ALTER TABLE notification_jobs ADD CONSTRAINT notification_jobs_channel_check
+    CHECK (channel IN ('IN_APP', 'WEBHOOK'));
+
+DROP TABLE IF EXISTS monitoring_verifications;
+ALTER TABLE webhooks DROP COLUMN IF EXISTS events;
@@ -0,0 +1,37 @@
+-- Migration 044: Company change webhooks
+-- A webhook subscribes to verification.completed (VERIFICATION_COMPLETED), company.changed
+-- (COMPANY_CHANGED) or both; existing registrations keep receiving completions only.
+-- Monitoring records the re-verifications it schedules in monitoring_verifications. When one of them
+-- reaches a final status, the trigger enqueues a COMPANY_CHANGE job per subscribed webhook; the gateway
+-- compares its data with the previous verification of the company and delivers the material changes.
+
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS events TEXT[] NOT NULL DEFAULT '{VERIFICATION_COMPLETED}';
+
+CREATE TABLE IF NOT EXISTS monitoring_verifications (
+    verification_id UUID PRIMARY KEY REFERENCES verifications(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE notification_jobs DROP CONSTRAINT IF EXISTS notification_jobs_channel_check;
+ALTER TABLE notification_jobs ADD CONSTRAINT notification_jobs_channel_check
+    CHECK (channel IN ('IN_APP', 'WEBHOOK', 'COMPANY_CHANGE'));
+
+CREATE OR REPLACE FUNCTION enqueue_notification_jobs() RETURNS trigger AS $$
+BEGIN
+    IF NEW.sandbox
+        OR OLD.status NOT IN ('PENDING', 'IN_PROCESS', 'PROCESSING')
+        OR NEW.status IN ('PENDING', 'IN_PROCESS', 'PROCESSING') THEN
+        RETURN NEW;
+    END IF;
+
+    INSERT INTO notification_jobs (verification_id, channel) VALUES (NEW.id, 'IN_APP');
+    INSERT INTO notification_jobs (verification_id, channel, webhook_id)
+    SELECT NEW.id, 'WEBHOOK', id FROM webhooks WHERE 'VERIFICATION_COMPLETED' = ANY(events);
+
+    IF EXISTS (SELECT 1 FROM monitoring_verifications WHERE verification_id = NEW.id) THEN
+        INSERT INTO notification_jobs (verification_id, channel, webhook_id)
+        SELECT NEW.id, 'COMPANY_CHANGE', id FROM webhooks WHERE 'COMPANY_CHANGED' = ANY(events);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,4 @@
+-- Migration 045 down: Remove dead letters of rejected provider payloads
+-- Diagnostics of rejected payloads are lost; the payloads themselves were already removed
+
+DROP TABLE IF EXISTS rejected_payloads;
@@ -0,0 +1,20 @@
+-- Migration 045: Dead letters of provider payloads rejected on ingest
+-- The gateway inspects payloads when a verification completes or an amendment is published. A payload
+-- that cannot be stored as is (not JSON, text lost by a lossy decoding, binary data, or over the size
+-- limit of its data type with no object storage configured) is moved here with diagnostics and a sample,
+-- and its verification_data row is removed: the data type counts as missing and can be requested again.
+
+CREATE TABLE IF NOT EXISTS rejected_payloads (
+    id BIGSERIAL PRIMARY KEY,
+    verification_id UUID NOT NULL REFERENCES verifications(id) ON DELETE CASCADE,
+    data_type VARCHAR(50) NOT NULL,
+    data_hash VARCHAR(64) NOT NULL,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('INVALID', 'OVERSIZED')),
+    diagnostics TEXT[] NOT NULL,
+    size_bytes INT NOT NULL,
+    sample TEXT NOT NULL,
+    rejected_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rejected_payloads_verification ON rejected_payloads(verification_id, rejected_at);
+CREATE INDEX IF NOT EXISTS idx_rejected_payloads_rejected_at ON rejected_payloads(rejected_at);
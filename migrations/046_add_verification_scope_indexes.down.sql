@@ -0,0 +1,4 @@
+-- Migration 046 down: Remove indexes for listing verifications by scope
+
+DROP INDEX IF EXISTS idx_verifications_tenant_created_at;
+DROP INDEX IF EXISTS idx_verifications_author_email_created_at;
@@ -0,0 +1,8 @@
+-- Migration 046: Indexes for listing verifications by scope
+-- The verifications query lists the caller's own verifications (MINE) or those of the caller's
+-- organization (MY_ORG), newest first. The tenant expression must match tenantExpr in the repository.
+
+CREATE INDEX IF NOT EXISTS idx_verifications_author_email_created_at ON verifications(author_email, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_verifications_tenant_created_at
+    ON verifications ((COALESCE(lower(substring(author_email FROM '@([^@]+)$')), 'default')), created_at DESC);
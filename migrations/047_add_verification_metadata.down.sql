@@ -0,0 +1,3 @@
+-- Migration 047 down: Remove integrator metadata of verifications
+
+DROP TABLE IF EXISTS verification_metadata;
@@ -0,0 +1,12 @@
+-- Migration 047: Integrator metadata of verifications
+-- Key/value pairs (order ids, branch codes, workflow hints) validated by the gateway. Like external refs,
+-- the row is written at creation time, before the worker inserts the verification itself.
+-- The GIN index serves containment filters (metadata @> '{"branch": "077"}').
+
+CREATE TABLE IF NOT EXISTS verification_metadata (
+    verification_id UUID PRIMARY KEY,
+    metadata JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_verification_metadata_metadata ON verification_metadata USING GIN (metadata jsonb_path_ops);
@@ -0,0 +1,3 @@
+-- Migration 048 down: Remove the report generation queue
+
+DROP TABLE IF EXISTS report_jobs;
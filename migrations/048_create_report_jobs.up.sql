@@ -0,0 +1,30 @@
+-- Migration 048: Queue of asynchronous report generation
+-- A client requests a case report and polls the job for progress. Gateway instances claim queued jobs,
+-- no more than the configured number running per tenant at once, render the report, upload it to object
+-- storage and hand out expiring download links until the result expires. A job whose instance stopped
+-- reporting progress before claimed_until is claimed again.
+
+CREATE TABLE IF NOT EXISTS report_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant VARCHAR(255) NOT NULL,
+    requested_by VARCHAR(255) NOT NULL,
+    case_id UUID NOT NULL REFERENCES cases(id) ON DELETE CASCADE,
+    format VARCHAR(10) NOT NULL CHECK (format IN ('PDF', 'XLSX')),
+    status VARCHAR(20) NOT NULL DEFAULT 'QUEUED' CHECK (status IN ('QUEUED', 'RUNNING', 'SUCCEEDED', 'FAILED', 'EXPIRED')),
+    progress INT NOT NULL DEFAULT 0 CHECK (progress BETWEEN 0 AND 100),
+    attempts INT NOT NULL DEFAULT 0,
+    claimed_until TIMESTAMP WITH TIME ZONE,
+    object_key TEXT,
+    size_bytes BIGINT,
+    signature TEXT,
+    key_id VARCHAR(100),
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_jobs_tenant_created ON report_jobs(tenant, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_report_jobs_pending ON report_jobs(created_at) WHERE status IN ('QUEUED', 'RUNNING');
+CREATE INDEX IF NOT EXISTS idx_report_jobs_expires ON report_jobs(expires_at) WHERE status = 'SUCCEEDED';
@@ -0,0 +1,5 @@
+-- Migration 049 down: Remove the next version of the verifications schema
+
+DROP INDEX IF EXISTS idx_verifications_updated_at_id;
+DROP TABLE IF EXISTS verifications_v2_sync;
+DROP TABLE IF EXISTS verifications_v2;
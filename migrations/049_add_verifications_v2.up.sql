@@ -0,0 +1,34 @@
+-- Migration 049: Next version of the verifications schema
+-- verifications_v2 keeps a verification as a JSONB document with the tenant in its own column and is
+-- partitioned by created_at. It is filled without downtime while the gateway runs in the dual_write
+-- migration mode or later: writes of the gateway are mirrored immediately, the sync job copies rows
+-- changed by workers in (updated_at, id) order and walks the whole table by id to repair drift left by
+-- writes that do not touch updated_at. verifications stays the source of truth until the cutover mode.
+-- Monthly partitions are attached by operators; rows outside them land in the default partition.
+
+CREATE TABLE IF NOT EXISTS verifications_v2 (
+    id UUID NOT NULL,
+    tenant VARCHAR(255) NOT NULL,
+    inn VARCHAR(12) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    document JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    synced_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE TABLE IF NOT EXISTS verifications_v2_default PARTITION OF verifications_v2 DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_verifications_v2_id ON verifications_v2(id);
+CREATE INDEX IF NOT EXISTS idx_verifications_v2_tenant_created ON verifications_v2(tenant, created_at DESC);
+
+-- Positions of the sync job passes: changed (by updated_at, id) and verify (by id)
+CREATE TABLE IF NOT EXISTS verifications_v2_sync (
+    pass VARCHAR(20) PRIMARY KEY,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    verification_id UUID NOT NULL,
+    saved_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_verifications_updated_at_id ON verifications(updated_at, id);
@@ -0,0 +1,30 @@
+-- Migration 050 down: Remove freshness tiers of the company data cache
+
+DROP TABLE IF EXISTS cache_refreshes;
+
+CREATE OR REPLACE FUNCTION refresh_company_data_cache() RETURNS trigger AS $$
+BEGIN
+    IF NEW.data_hash IS NULL OR NEW.data_hash = '' THEN
+        RETURN NEW;
+    END IF;
+
+    -- Sandbox payloads are synthetic and must not be served as real company data
+    INSERT INTO company_data_cache (tenant_id, inn, data_type, schema_version, data_hash, verification_id, updated_at)
+    SELECT t.tenant_id, v.inn, NEW.data_type, NEW.schema_version, NEW.data_hash, v.id, NOW()
+    FROM verifications v
+    CROSS JOIN LATERAL (
+        SELECT COALESCE(NULLIF(lower(substring(v.author_email FROM '@([^@]+)$')), ''), 'default') AS tenant_id
+        UNION ALL
+        SELECT '*' WHERE EXISTS (SELECT 1 FROM cache_sharing s WHERE s.data_type = NEW.data_type AND s.shared)
+    ) t
+    WHERE v.id = NEW.verification_id AND NOT v.sandbox
+    ON CONFLICT (tenant_id, inn, data_type, schema_version) DO UPDATE
+        SET data_hash = EXCLUDED.data_hash,
+            verification_id = EXCLUDED.verification_id,
+            updated_at = EXCLUDED.updated_at;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE verification_data DROP COLUMN IF EXISTS freshness;
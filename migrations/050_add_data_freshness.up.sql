@@ -0,0 +1,47 @@
+-- Migration 050: Freshness tiers of the company data cache
+-- Data of a company younger than the fresh age of its type is served from company_data_cache without
+-- asking providers; data younger than the stale age is served immediately and refreshed in the
+-- background. verification_data.freshness marks rows served this way: they point to the cached payload
+-- and keep its delivery time, and the trigger no longer treats them as deliveries.
+-- cache_refreshes holds the last background refresh of each company key, so concurrent requests for a
+-- stale company trigger one refresh per cooldown.
+
+ALTER TABLE verification_data ADD COLUMN IF NOT EXISTS freshness VARCHAR(10) CHECK (freshness IN ('FRESH', 'STALE'));
+
+CREATE OR REPLACE FUNCTION refresh_company_data_cache() RETURNS trigger AS $$
+BEGIN
+    IF NEW.data_hash IS NULL OR NEW.data_hash = '' THEN
+        RETURN NEW;
+    END IF;
+
+    -- Data served from the cache is not a new delivery and must not make the cache look fresh
+    IF NEW.freshness IS NOT NULL THEN
+        RETURN NEW;
+    END IF;
+
+    -- Sandbox payloads are synthetic and must not be served as real company data
+    INSERT INTO company_data_cache (tenant_id, inn, data_type, schema_version, data_hash, verification_id, updated_at)
+    SELECT t.tenant_id, v.inn, NEW.data_type, NEW.schema_version, NEW.data_hash, v.id, NOW()
+    FROM verifications v
+    CROSS JOIN LATERAL (
+        SELECT COALESCE(NULLIF(lower(substring(v.author_email FROM '@([^@]+)$')), ''), 'default') AS tenant_id
+        UNION ALL
+        SELECT '*' WHERE EXISTS (SELECT 1 FROM cache_sharing s WHERE s.data_type = NEW.data_type AND s.shared)
+    ) t
+    WHERE v.id = NEW.verification_id AND NOT v.sandbox
+    ON CONFLICT (tenant_id, inn, data_type, schema_version) DO UPDATE
+        SET data_hash = EXCLUDED.data_hash,
+            verification_id = EXCLUDED.verification_id,
+            updated_at = EXCLUDED.updated_at;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TABLE IF NOT EXISTS cache_refreshes (
+    tenant_id VARCHAR(255) NOT NULL,
+    inn VARCHAR(12) NOT NULL,
+    data_type VARCHAR(50) NOT NULL,
+    requested_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, inn, data_type)
+);
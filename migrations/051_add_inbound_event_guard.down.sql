@@ -0,0 +1,4 @@
+-- Migration 051 down: Remove replay protection of verification completed events
+
+DROP TABLE IF EXISTS parked_completion_events;
+DROP TABLE IF EXISTS completion_events_seen;
@@ -0,0 +1,31 @@
+-- Migration 051: Replay protection of verification completed events
+-- completion_events_seen remembers the events a consumer has applied, per event id, for as long as
+-- events are accepted at all (the max age window), so a replayed event is not applied twice.
+-- The consumer is the NATS queue group, or the instance when every instance receives all events.
+-- parked_completion_events keeps rejected events for investigation instead of dropping them.
+-- verification_id is not a foreign key: events of unknown verifications are parked as well.
+
+CREATE TABLE IF NOT EXISTS completion_events_seen (
+    consumer VARCHAR(255) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    verification_id UUID NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (consumer, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_completion_events_seen_received_at ON completion_events_seen(received_at);
+
+CREATE TABLE IF NOT EXISTS parked_completion_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id VARCHAR(255),
+    verification_id VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    risk_level VARCHAR(20),
+    ruleset_id VARCHAR(255),
+    completed_at TIMESTAMP WITH TIME ZONE,
+    reason VARCHAR(50) NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_parked_completion_events_received_at ON parked_completion_events(received_at);
+CREATE INDEX IF NOT EXISTS idx_parked_completion_events_verification_id ON parked_completion_events(verification_id);
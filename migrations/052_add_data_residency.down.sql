@@ -0,0 +1,5 @@
+-- Migration 052 down: Remove data residency
+-- Verifications already stored in regional databases stay there and are no longer reachable
+
+DROP TABLE IF EXISTS verification_regions;
+ALTER TABLE organizations DROP COLUMN IF EXISTS storage_region;
@@ -0,0 +1,14 @@
+-- Migration 052: Data residency of tenants
+-- organizations.storage_region pins a tenant to a regional database: its verifications, data and
+-- metadata are stored there instead of the gateway's home database. NULL keeps the home region.
+-- verification_regions lives in the home database and maps the verifications of pinned tenants
+-- to their region, so requests by verification id reach the right database. It holds no company data.
+-- Regional databases run the same migrations.
+
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS storage_region VARCHAR(64);
+
+CREATE TABLE IF NOT EXISTS verification_regions (
+    verification_id UUID PRIMARY KEY,
+    region VARCHAR(64) NOT NULL,
+    assigned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
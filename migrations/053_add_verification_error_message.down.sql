@@ -0,0 +1,3 @@
+-- Migration 053 down: Remove error message of failed verifications
+
+ALTER TABLE verifications DROP COLUMN IF EXISTS error_message;
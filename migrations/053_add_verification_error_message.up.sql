@@ -0,0 +1,5 @@
+-- Migration 053: Error message of failed verifications
+-- The gateway stores the status, error and time of change from verification.completed messages
+-- instead of relying on the scoring worker to update its database. NULL - no error was reported.
+
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS error_message TEXT;
@@ -0,0 +1,3 @@
+-- Migration 054 down: Remove the time of the last status change
+
+ALTER TABLE verifications DROP COLUMN IF EXISTS status_changed_at;
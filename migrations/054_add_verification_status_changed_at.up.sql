@@ -0,0 +1,7 @@
+-- Migration 054: Time of the last status change
+-- verification.completed messages are applied in the order of their completed_at. updated_at cannot
+-- be used for that: the gateway also sets it when it changes the risk level, retries missing data or
+-- creates the verification. NULL - the status has not been changed by a message since the verification
+-- was created, or it was written by a worker.
+
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP WITH TIME ZONE;